@@ -15,8 +15,12 @@ import (
 	"devops-cd/internal/api/router"
 	"devops-cd/internal/core"
 	"devops-cd/internal/pkg/config"
+	"devops-cd/internal/pkg/crypto"
 	"devops-cd/internal/pkg/database"
+	"devops-cd/internal/pkg/leader"
 	"devops-cd/internal/pkg/logger"
+	"devops-cd/internal/pkg/secrets"
+	"devops-cd/internal/pkg/tracing"
 	"devops-cd/internal/scheduler"
 
 	_ "devops-cd/docs" // Swagger docs
@@ -97,6 +101,22 @@ func main() {
 
 	logger.Info(fmt.Sprintf("服务 %s 启动中...", appName), zap.String("version", appVersion))
 
+	// 初始化凭据加密 provider
+	if err := crypto.Init(&cfg.Crypto); err != nil {
+		logger.Fatal("初始化 crypto provider 失败", zap.Error(err))
+	}
+
+	// 初始化外部密钥后端（credential_ref=vault:/aws_sm:）
+	if err := secrets.Init(&cfg.Secrets); err != nil {
+		logger.Fatal("初始化外部密钥后端失败", zap.Error(err))
+	}
+
+	// 初始化链路追踪
+	shutdownTracing, err := tracing.Init(&cfg.Tracing, cfg.Server.Name)
+	if err != nil {
+		logger.Fatal("初始化链路追踪失败", zap.Error(err))
+	}
+
 	// 初始化数据库
 	if err := database.Init(&cfg.Database); err != nil {
 		logger.Fatal("初始化数据库失败", zap.Error(err))
@@ -110,8 +130,11 @@ func main() {
 	// 注入数据库连接到配置
 	cfg.DB = database.GetDB()
 
+	// 多副本部署：选举主节点，只有主节点运行引擎扫描与定时任务
+	elector, stopElector := startElector(&cfg.Core.HA)
+
 	// 初始化Core引擎（状态机）
-	coreEngine := core.NewCoreEngine(database.GetDB(), logger.Log, &cfg.Core)
+	coreEngine := core.NewCoreEngine(database.GetDB(), logger.Log, &cfg.Core, core.WithLeader(elector))
 
 	// 解析扫描间隔
 	scanInterval, err := time.ParseDuration(cfg.Core.ScanInterval)
@@ -125,7 +148,7 @@ func main() {
 	logger.Info("Core引擎启动成功", zap.Duration("scan_interval", scanInterval))
 
 	// 初始化并启动定时任务调度器
-	taskScheduler := scheduler.NewScheduler(database.GetDB(), logger.Log, cfg)
+	taskScheduler := scheduler.NewScheduler(database.GetDB(), logger.Log, cfg, coreEngine, coreEngine, elector)
 	if err := taskScheduler.Start(cfg); err != nil {
 		logger.Warn("定时任务调度器启动失败", zap.Error(err))
 	}
@@ -162,10 +185,13 @@ func main() {
 	taskScheduler.Stop()
 	logger.Info("定时任务调度器已停止")
 
-	// 关闭Core引擎
+	// 关闭Core引擎：不再启动新的处理，等待进行中的批次处理与部署任务（最长 core.drain_timeout）
 	coreEngine.Stop()
 	logger.Info("Core引擎已停止")
 
+	// 释放主节点锁，其他副本立即接管
+	stopElector()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -173,6 +199,11 @@ func main() {
 		logger.Error("服务器关闭异常", zap.Error(err))
 	}
 
+	// 刷新未导出的 span
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Warn("关闭链路追踪异常", zap.Error(err))
+	}
+
 	logger.Info("服务已关闭")
 }
 
@@ -203,3 +234,37 @@ func getConfigSource() string {
 	}
 	return "默认配置"
 }
+
+// startElector 启用 core.ha 时启动主节点选举，返回的 stop 用于退出时释放锁；未启用时返回 nil（单实例，始终是主节点）
+func startElector(cfg *config.HAConfig) (leader.Leader, func()) {
+	if !cfg.Enabled {
+		return nil, func() {}
+	}
+	db := database.GetDB()
+	sqlDB, err := db.DB()
+	if err != nil {
+		logger.Fatal("获取数据库实例失败", zap.Error(err))
+	}
+	var interval time.Duration
+	if cfg.RenewInterval != "" {
+		if interval, err = time.ParseDuration(cfg.RenewInterval); err != nil {
+			logger.Warn("解析 core.ha.renew_interval 失败，使用默认值", zap.String("value", cfg.RenewInterval), zap.Error(err))
+		}
+	}
+	elector, err := leader.NewElector(sqlDB, db.Dialector.Name(), cfg.LockName, interval, logger.Log)
+	if err != nil {
+		logger.Fatal("创建主节点选举器失败", zap.Error(err))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		elector.Run(ctx)
+	}()
+	logger.Info("主节点选举已启动", zap.String("lock_name", cfg.LockName))
+	return elector, func() {
+		cancel()
+		<-done
+	}
+}
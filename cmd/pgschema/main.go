@@ -0,0 +1,60 @@
+// pgschema 由 scripts/*.sql（MySQL）生成 scripts/postgres/*.sql（PostgreSQL），新增或修改迁移脚本后执行:
+//
+//	go run ./cmd/pgschema
+//	go run ./cmd/pgschema -check # 只校验生成结果是否最新（CI 使用）
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"devops-cd/internal/pkg/database/migrate"
+)
+
+var (
+	scriptsDir = flag.String("scripts", "scripts", "MySQL 迁移脚本目录")
+	check      = flag.Bool("check", false, "只校验 PostgreSQL 脚本是否与 MySQL 脚本一致，不写文件")
+)
+
+func main() {
+	flag.Parse()
+
+	scripts, err := migrate.Load(*scriptsDir)
+	if err != nil {
+		fmt.Printf("读取迁移脚本失败: %v\n", err)
+		os.Exit(1)
+	}
+	pg, err := migrate.PostgresScripts(scripts)
+	if err != nil {
+		fmt.Printf("翻译迁移脚本失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	outDir := filepath.Join(*scriptsDir, migrate.PostgresDir)
+	stale := 0
+	for _, s := range pg {
+		path := filepath.Join(outDir, s.Name)
+		if current, err := os.ReadFile(path); err == nil && string(current) == s.SQL {
+			continue
+		}
+		stale++
+		if *check {
+			fmt.Printf("需要重新生成: %s\n", path)
+			continue
+		}
+		if err := os.MkdirAll(outDir, 0o755); err != nil {
+			fmt.Printf("创建目录失败: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(path, []byte(s.SQL), 0o644); err != nil {
+			fmt.Printf("写入 %s 失败: %v\n", path, err)
+			os.Exit(1)
+		}
+		fmt.Printf("已生成: %s\n", path)
+	}
+	if *check && stale > 0 {
+		os.Exit(1)
+	}
+}
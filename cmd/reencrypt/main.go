@@ -0,0 +1,90 @@
+// reencrypt 将存量密文迁移到当前 crypto.provider（信封加密）：
+// config_credentials 凭据、webhooks 签名密钥、repo_sources 访问令牌。
+//
+// 可在服务运行期间执行：逐条 CAS 更新，迁移期间被修改的记录会跳过，重复执行直至各表 skipped=0 即可。
+//
+//	go run ./cmd/reencrypt -config=configs/base.yaml -dry-run
+//	go run ./cmd/reencrypt -config=configs/base.yaml
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/pkg/config"
+	"devops-cd/internal/pkg/crypto"
+	"devops-cd/internal/pkg/database"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/internal/repository"
+	"devops-cd/internal/service"
+)
+
+var (
+	configFile = flag.String("config", "configs/config.yaml", "配置文件路径")
+	batchSize  = flag.Int("batch-size", 100, "每批处理的记录数")
+	dryRun     = flag.Bool("dry-run", false, "仅校验可解密，不写库")
+)
+
+func main() {
+	flag.Parse()
+
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		fmt.Printf("加载配置失败: %v\n", err)
+		os.Exit(1)
+	}
+	if err := logger.Init(&cfg.Log); err != nil {
+		fmt.Printf("初始化日志失败: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() {
+		_ = logger.Close()
+	}()
+
+	if err := crypto.Init(&cfg.Crypto); err != nil {
+		logger.Fatal("初始化 crypto provider 失败", zap.Error(err))
+	}
+	if err := database.Init(&cfg.Database); err != nil {
+		logger.Fatal("初始化数据库失败", zap.Error(err))
+	}
+	defer func() {
+		_ = database.Close()
+	}()
+
+	db := database.GetDB()
+	// 按表依次迁移；输出为 表名 → 迁移结果
+	targets := []struct {
+		table string
+		run   func(batchSize int, dryRun bool) (*dto.CredentialReencryptResult, error)
+	}{
+		{"config_credentials", service.NewCredentialService(repository.NewCredentialRepository(db)).Reencrypt},
+		// 重新加密不投递，不需要 dispatcher
+		{"webhooks", service.NewWebhookService(repository.NewWebhookRepository(db), repository.NewProjectRepository(db), nil).Reencrypt},
+		{"repo_sources", service.NewRepoSourceService(repository.NewRepoSyncSourceRepository(db), repository.NewTeamRepository(db)).Reencrypt},
+	}
+	results := make(map[string]*dto.CredentialReencryptResult, len(targets))
+	failed := false
+	var runErr error
+	for _, t := range targets {
+		result, err := t.run(*batchSize, *dryRun)
+		results[t.table] = result
+		if err != nil {
+			runErr = fmt.Errorf("%s: %w", t.table, err)
+			break
+		}
+		failed = failed || len(result.FailedIDs) > 0
+	}
+	out, _ := json.MarshalIndent(results, "", "  ")
+	fmt.Println(string(out))
+	if runErr != nil {
+		logger.Fatal("重新加密失败", zap.Error(runErr))
+	}
+	if failed {
+		os.Exit(2)
+	}
+}
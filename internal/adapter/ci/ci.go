@@ -0,0 +1,171 @@
+// Package ci 将外部 CI（GitLab CI、GitHub Actions、Jenkins 等）的 Webhook payload 转换为标准构建通知（dto.BuildNotifyRequest），
+// 转换后与 Drone 的 /build/notify 走相同的处理流程
+package ci
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"devops-cd/internal/dto"
+)
+
+// ErrIgnored 事件无需处理（未完成的流水线、无匹配应用的 job 等），接口返回成功以免 CI 重试
+var ErrIgnored = errors.New("事件无需处理")
+
+// 构建状态（与 /build/notify 的 build_status 一致）
+const (
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+	StatusError   = "error"
+	StatusKilled  = "killed"
+)
+
+// 触发事件（与 /build/notify 的 build_event 一致）
+const (
+	EventPush        = "push"
+	EventTag         = "tag"
+	EventPullRequest = "pull_request"
+)
+
+// shortSHALength 默认镜像 tag 使用的 commit 短 SHA 长度
+const shortSHALength = 8
+
+// Pipeline 各 CI 适配器解析出的流水线信息
+type Pipeline struct {
+	Repo   string // 代码库 namespace/name
+	Number int64  // 构建号
+	Status string // success/failure/error/killed
+	Event  string // push/tag/pull_request
+
+	Ref    string // 完整 ref，如 refs/tags/v1.0.0
+	Branch string
+	Tag    string
+	SHA    string
+	Before string
+
+	Link          string
+	CommitLink    string
+	CommitMessage string
+	AuthorName    string
+	AuthorEmail   string
+
+	Created  time.Time
+	Started  time.Time
+	Finished time.Time
+
+	Jobs map[string]string // 流水线事件：job 名称 → 状态
+	Job  string            // job 事件：完成的 job 名称
+}
+
+// Notify 按规则将流水线转换为构建通知；没有匹配的应用时返回 ErrIgnored
+func (r *Rules) Notify(p *Pipeline) (*dto.BuildNotifyRequest, error) {
+	return p.notify(r.matchApps(p))
+}
+
+// notify 将流水线及匹配出的应用转换为构建通知
+func (p *Pipeline) notify(apps []ruleApp) (*dto.BuildNotifyRequest, error) {
+	if len(apps) == 0 {
+		return nil, ErrIgnored
+	}
+
+	data := p.templateData()
+	req := &dto.BuildNotifyRequest{
+		Repo:              p.Repo,
+		BuildNumber:       p.Number,
+		BuildStatus:       p.Status,
+		BuildCreated:      unix(p.Created, p.Started, p.Finished),
+		BuildStarted:      unix(p.Started, p.Created, p.Finished),
+		BuildFinished:     unix(p.Finished, p.Started, p.Created),
+		BuildLink:         p.Link,
+		BuildEvent:        p.Event,
+		CommitAuthorName:  p.AuthorName,
+		CommitAuthorEmail: p.AuthorEmail,
+		CommitRef:         p.Ref,
+		CommitID:          p.SHA,
+		CommitBranch:      p.Branch,
+		CommitAfter:       p.SHA,
+		CommitMessage:     p.CommitMessage,
+		CommitLink:        p.CommitLink,
+	}
+	req.RepoNamespace, req.RepoName = splitRepo(p.Repo)
+	req.RepoOwner = req.RepoNamespace
+	if p.Before != "" {
+		before := p.Before
+		req.CommitBefore = &before
+	}
+
+	for _, app := range apps {
+		data["app"] = app.rule.Name
+		tagTpl := app.rule.ImageTag
+		if tagTpl == "" {
+			tagTpl = "{{ if .tag }}{{ .tag }}{{ else }}{{ .short_sha }}{{ end }}"
+		}
+		tag, err := render(tagTpl, data)
+		if err != nil {
+			return nil, fmt.Errorf("应用 %s image_tag 渲染失败: %w", app.rule.Name, err)
+		}
+		success := app.success
+		item := dto.BuildNotifyApp{Name: app.rule.Name, ImageTag: tag, BuildSuccess: &success}
+		if app.rule.Image != "" {
+			image, err := render(app.rule.Image, data)
+			if err != nil {
+				return nil, fmt.Errorf("应用 %s image 渲染失败: %w", app.rule.Name, err)
+			}
+			item.Image = &image
+		}
+		req.Apps = append(req.Apps, item)
+	}
+	return req, nil
+}
+
+// templateData 镜像 tag / 镜像地址模板变量
+func (p *Pipeline) templateData() map[string]interface{} {
+	namespace, name := splitRepo(p.Repo)
+	shortSHA := p.SHA
+	if len(shortSHA) > shortSHALength {
+		shortSHA = shortSHA[:shortSHALength]
+	}
+	return map[string]interface{}{
+		"repo":           p.Repo,
+		"repo_namespace": namespace,
+		"repo_name":      name,
+		"build_number":   strconv.FormatInt(p.Number, 10),
+		"event":          p.Event,
+		"ref":            p.Ref,
+		"branch":         p.Branch,
+		"tag":            p.Tag,
+		"sha":            p.SHA,
+		"short_sha":      shortSHA,
+	}
+}
+
+func splitRepo(repo string) (string, string) {
+	for i := len(repo) - 1; i >= 0; i-- {
+		if repo[i] == '/' {
+			return repo[:i], repo[i+1:]
+		}
+	}
+	return "", repo
+}
+
+// unix 取第一个非零时间的 Unix 秒；全部为零时取当前时间
+func unix(times ...time.Time) int64 {
+	for _, t := range times {
+		if !t.IsZero() {
+			return t.Unix()
+		}
+	}
+	return time.Now().Unix()
+}
+
+// parseTime 解析 CI payload 中的时间，兼容 RFC3339 与 "2006-01-02 15:04:05 UTC"
+func parseTime(value string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,180 @@
+package ci
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// GitHub Webhook 事件（请求头 X-GitHub-Event）
+const (
+	GitHubEventWorkflowRun = "workflow_run"
+	GitHubEventWorkflowJob = "workflow_job"
+)
+
+// DefaultGitHubTagPattern 默认按 head_branch 识别 tag 触发的运行（workflow_run / workflow_job 不携带 ref 类型）
+const DefaultGitHubTagPattern = `^v?[0-9]+(\.[0-9]+)+`
+
+// githubConclusions GitHub 运行结论 → 构建状态；skipped、neutral 等不产生构建
+var githubConclusions = map[string]string{
+	"success":         StatusSuccess,
+	"failure":         StatusFailure,
+	"timed_out":       StatusFailure,
+	"startup_failure": StatusError,
+	"cancelled":       StatusKilled,
+}
+
+type githubRepository struct {
+	FullName string `json:"full_name"`
+	HTMLURL  string `json:"html_url"`
+}
+
+type githubWorkflowRunEvent struct {
+	Action      string `json:"action"`
+	WorkflowRun struct {
+		ID           int64  `json:"id"`
+		Name         string `json:"name"`
+		Event        string `json:"event"`
+		Conclusion   string `json:"conclusion"`
+		HeadBranch   string `json:"head_branch"`
+		HeadSHA      string `json:"head_sha"`
+		HTMLURL      string `json:"html_url"`
+		CreatedAt    string `json:"created_at"`
+		RunStartedAt string `json:"run_started_at"`
+		UpdatedAt    string `json:"updated_at"`
+		HeadCommit   struct {
+			Message string `json:"message"`
+			Author  struct {
+				Name  string `json:"name"`
+				Email string `json:"email"`
+			} `json:"author"`
+		} `json:"head_commit"`
+	} `json:"workflow_run"`
+	Repository githubRepository `json:"repository"`
+}
+
+type githubWorkflowJobEvent struct {
+	Action      string `json:"action"`
+	WorkflowJob struct {
+		RunID       int64  `json:"run_id"`
+		Name        string `json:"name"`
+		Conclusion  string `json:"conclusion"`
+		HeadBranch  string `json:"head_branch"`
+		HeadSHA     string `json:"head_sha"`
+		HTMLURL     string `json:"html_url"`
+		CreatedAt   string `json:"created_at"`
+		StartedAt   string `json:"started_at"`
+		CompletedAt string `json:"completed_at"`
+	} `json:"workflow_job"`
+	Repository githubRepository `json:"repository"`
+}
+
+// VerifyGitHubSignature 校验 X-Hub-Signature-256（sha256=<HMAC-SHA256(secret, payload) 十六进制>）
+func VerifyGitHubSignature(secret string, payload []byte, signature string) bool {
+	sig, ok := strings.CutPrefix(strings.TrimSpace(signature), "sha256=")
+	if !ok || secret == "" {
+		return false
+	}
+	expected, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// ParseGitHub 解析 GitHub Actions workflow_run / workflow_job 事件，仅处理 action=completed
+//
+// 构建号取运行 ID（job 事件取所属运行 ID）；workflow_run 事件以工作流名称作为 job 名称参与规则匹配，
+// 即 pipeline 规则的 jobs 填工作流名称，job 规则的 jobs 填 job 名称；head_branch 匹配 tagPattern 时视为 tag 触发
+func ParseGitHub(event string, payload []byte, tagPattern *regexp.Regexp) (*Pipeline, error) {
+	switch event {
+	case GitHubEventWorkflowRun:
+		return parseGitHubRun(payload, tagPattern)
+	case GitHubEventWorkflowJob:
+		return parseGitHubJob(payload, tagPattern)
+	default:
+		// ping 等其他事件
+		return nil, ErrIgnored
+	}
+}
+
+func parseGitHubRun(payload []byte, tagPattern *regexp.Regexp) (*Pipeline, error) {
+	var ev githubWorkflowRunEvent
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		return nil, fmt.Errorf("解析 GitHub workflow_run 事件失败: %w", err)
+	}
+	run := ev.WorkflowRun
+	status, ok := githubConclusions[run.Conclusion]
+	if ev.Action != "completed" || !ok {
+		return nil, ErrIgnored
+	}
+
+	p := &Pipeline{
+		Repo:          ev.Repository.FullName,
+		Number:        run.ID,
+		Status:        status,
+		SHA:           run.HeadSHA,
+		Link:          run.HTMLURL,
+		CommitMessage: run.HeadCommit.Message,
+		AuthorName:    run.HeadCommit.Author.Name,
+		AuthorEmail:   run.HeadCommit.Author.Email,
+		Created:       parseTime(run.CreatedAt),
+		Started:       parseTime(run.RunStartedAt),
+		Finished:      parseTime(run.UpdatedAt),
+		Jobs:          map[string]string{run.Name: status},
+	}
+	setGitHubRef(p, run.HeadBranch, run.Event, ev.Repository.HTMLURL, tagPattern)
+	return p, nil
+}
+
+func parseGitHubJob(payload []byte, tagPattern *regexp.Regexp) (*Pipeline, error) {
+	var ev githubWorkflowJobEvent
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		return nil, fmt.Errorf("解析 GitHub workflow_job 事件失败: %w", err)
+	}
+	job := ev.WorkflowJob
+	status, ok := githubConclusions[job.Conclusion]
+	if ev.Action != "completed" || !ok {
+		return nil, ErrIgnored
+	}
+
+	p := &Pipeline{
+		Repo:     ev.Repository.FullName,
+		Number:   job.RunID,
+		Status:   status,
+		SHA:      job.HeadSHA,
+		Link:     job.HTMLURL,
+		Created:  parseTime(job.CreatedAt),
+		Started:  parseTime(job.StartedAt),
+		Finished: parseTime(job.CompletedAt),
+		Job:      job.Name,
+	}
+	setGitHubRef(p, job.HeadBranch, "", ev.Repository.HTMLURL, tagPattern)
+	return p, nil
+}
+
+func setGitHubRef(p *Pipeline, headBranch, event, repoURL string, tagPattern *regexp.Regexp) {
+	switch {
+	case event == "pull_request" || event == "pull_request_target":
+		p.Event = EventPullRequest
+		p.Branch = headBranch
+		p.Ref = headBranch
+	case event == "release" || (tagPattern != nil && tagPattern.MatchString(headBranch)):
+		p.Event = EventTag
+		p.Tag = headBranch
+		p.Ref = "refs/tags/" + headBranch
+	default:
+		p.Event = EventPush
+		p.Branch = headBranch
+		p.Ref = "refs/heads/" + headBranch
+	}
+	if repoURL != "" && p.SHA != "" {
+		p.CommitLink = fmt.Sprintf("%s/commit/%s", repoURL, p.SHA)
+	}
+}
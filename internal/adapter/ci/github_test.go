@@ -0,0 +1,49 @@
+package ci
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestVerifyGitHubSignature(t *testing.T) {
+	const secret = "It's a Secret to Everybody"
+	payload := []byte("Hello, World!")
+	// GitHub 文档中的示例签名
+	const docSig = "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"
+
+	sign := func(key string, body []byte) string {
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write(body)
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	cases := []struct {
+		name      string
+		secret    string
+		payload   []byte
+		signature string
+		want      bool
+	}{
+		{name: "文档示例", secret: secret, payload: payload, signature: docSig, want: true},
+		{name: "前后空白", secret: secret, payload: payload, signature: "  " + docSig + "\n", want: true},
+		{name: "十六进制大写", secret: secret, payload: payload, signature: "sha256=" + strings.ToUpper(strings.TrimPrefix(docSig, "sha256=")), want: true},
+		{name: "payload 被篡改", secret: secret, payload: []byte("Hello, World?"), signature: docSig, want: false},
+		{name: "secret 不一致", secret: "other", payload: payload, signature: docSig, want: false},
+		{name: "未配置 secret", secret: "", payload: payload, signature: sign("", payload), want: false},
+		{name: "缺少签名", secret: secret, payload: payload, signature: "", want: false},
+		{name: "sha1 签名", secret: secret, payload: payload, signature: "sha1=" + strings.TrimPrefix(docSig, "sha256="), want: false},
+		{name: "非十六进制", secret: secret, payload: payload, signature: "sha256=zz", want: false},
+		{name: "签名被截断", secret: secret, payload: payload, signature: docSig[:len(docSig)-2], want: false},
+		{name: "空 payload", secret: secret, payload: nil, signature: sign(secret, nil), want: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := VerifyGitHubSignature(c.secret, c.payload, c.signature); got != c.want {
+				t.Errorf("VerifyGitHubSignature() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
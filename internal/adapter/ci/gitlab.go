@@ -0,0 +1,215 @@
+package ci
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// GitLab Webhook 事件（请求头 X-Gitlab-Event）
+const (
+	GitLabEventPipeline = "Pipeline Hook"
+	GitLabEventJob      = "Job Hook"
+)
+
+// gitlabStatuses GitLab 流水线 / job 终态 → 构建状态；其余状态（running、pending、manual 等）忽略
+var gitlabStatuses = map[string]string{
+	"success":  StatusSuccess,
+	"failed":   StatusFailure,
+	"canceled": StatusKilled,
+}
+
+type gitlabProject struct {
+	PathWithNamespace string `json:"path_with_namespace"`
+	WebURL            string `json:"web_url"`
+}
+
+type gitlabCommit struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+	URL     string `json:"url"`
+	Author  struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"author"`
+}
+
+type gitlabPipelineEvent struct {
+	ObjectKind       string `json:"object_kind"`
+	ObjectAttributes struct {
+		ID         int64  `json:"id"`
+		Ref        string `json:"ref"`
+		Tag        bool   `json:"tag"`
+		SHA        string `json:"sha"`
+		BeforeSHA  string `json:"before_sha"`
+		Source     string `json:"source"`
+		Status     string `json:"status"`
+		CreatedAt  string `json:"created_at"`
+		FinishedAt string `json:"finished_at"`
+		URL        string `json:"url"`
+	} `json:"object_attributes"`
+	Project gitlabProject `json:"project"`
+	Commit  gitlabCommit  `json:"commit"`
+	Builds  []struct {
+		Name      string `json:"name"`
+		Status    string `json:"status"`
+		StartedAt string `json:"started_at"`
+	} `json:"builds"`
+}
+
+type gitlabJobEvent struct {
+	ObjectKind      string        `json:"object_kind"`
+	Ref             string        `json:"ref"`
+	Tag             bool          `json:"tag"`
+	SHA             string        `json:"sha"`
+	BeforeSHA       string        `json:"before_sha"`
+	BuildID         int64         `json:"build_id"`
+	BuildName       string        `json:"build_name"`
+	BuildStatus     string        `json:"build_status"`
+	BuildCreatedAt  string        `json:"build_created_at"`
+	BuildStartedAt  string        `json:"build_started_at"`
+	BuildFinishedAt string        `json:"build_finished_at"`
+	PipelineID      int64         `json:"pipeline_id"`
+	Project         gitlabProject `json:"project"`
+	Commit          struct {
+		Message     string `json:"message"`
+		AuthorName  string `json:"author_name"`
+		AuthorEmail string `json:"author_email"`
+	} `json:"commit"`
+}
+
+// ParseGitLab 解析 GitLab Pipeline Hook / Job Hook，event 为空时按 object_kind 判断
+//
+// 构建号取流水线 ID（job 事件取所属流水线 ID），未到终态的事件返回 ErrIgnored
+func ParseGitLab(event string, payload []byte) (*Pipeline, error) {
+	if event == "" {
+		var probe struct {
+			ObjectKind string `json:"object_kind"`
+		}
+		if err := json.Unmarshal(payload, &probe); err != nil {
+			return nil, fmt.Errorf("payload 不是合法的 JSON: %w", err)
+		}
+		switch probe.ObjectKind {
+		case "pipeline":
+			event = GitLabEventPipeline
+		case "build":
+			event = GitLabEventJob
+		}
+	}
+
+	switch event {
+	case GitLabEventPipeline:
+		return parseGitLabPipeline(payload)
+	case GitLabEventJob:
+		return parseGitLabJob(payload)
+	default:
+		return nil, ErrIgnored
+	}
+}
+
+func parseGitLabPipeline(payload []byte) (*Pipeline, error) {
+	var ev gitlabPipelineEvent
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		return nil, fmt.Errorf("解析 GitLab Pipeline 事件失败: %w", err)
+	}
+	attrs := ev.ObjectAttributes
+	status, ok := gitlabStatuses[attrs.Status]
+	if !ok {
+		return nil, ErrIgnored
+	}
+
+	p := &Pipeline{
+		Repo:          gitlabRepo(ev.Project.PathWithNamespace),
+		Number:        attrs.ID,
+		Status:        status,
+		SHA:           attrs.SHA,
+		Before:        gitlabBefore(attrs.BeforeSHA),
+		Link:          attrs.URL,
+		CommitLink:    ev.Commit.URL,
+		CommitMessage: ev.Commit.Message,
+		AuthorName:    ev.Commit.Author.Name,
+		AuthorEmail:   ev.Commit.Author.Email,
+		Created:       parseTime(attrs.CreatedAt),
+		Finished:      parseTime(attrs.FinishedAt),
+		Jobs:          make(map[string]string, len(ev.Builds)),
+	}
+	setGitLabRef(p, attrs.Ref, attrs.Tag, attrs.Source == "merge_request_event")
+	if p.Link == "" && ev.Project.WebURL != "" {
+		p.Link = fmt.Sprintf("%s/-/pipelines/%d", ev.Project.WebURL, attrs.ID)
+	}
+	for _, b := range ev.Builds {
+		if s, ok := gitlabStatuses[b.Status]; ok {
+			p.Jobs[b.Name] = s
+		}
+		if started := parseTime(b.StartedAt); !started.IsZero() && (p.Started.IsZero() || started.Before(p.Started)) {
+			p.Started = started
+		}
+	}
+	return p, nil
+}
+
+func parseGitLabJob(payload []byte) (*Pipeline, error) {
+	var ev gitlabJobEvent
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		return nil, fmt.Errorf("解析 GitLab Job 事件失败: %w", err)
+	}
+	status, ok := gitlabStatuses[ev.BuildStatus]
+	if !ok {
+		return nil, ErrIgnored
+	}
+
+	p := &Pipeline{
+		Repo:          gitlabRepo(ev.Project.PathWithNamespace),
+		Number:        ev.PipelineID,
+		Status:        status,
+		SHA:           ev.SHA,
+		Before:        gitlabBefore(ev.BeforeSHA),
+		CommitMessage: ev.Commit.Message,
+		AuthorName:    ev.Commit.AuthorName,
+		AuthorEmail:   ev.Commit.AuthorEmail,
+		Created:       parseTime(ev.BuildCreatedAt),
+		Started:       parseTime(ev.BuildStartedAt),
+		Finished:      parseTime(ev.BuildFinishedAt),
+		Job:           ev.BuildName,
+	}
+	setGitLabRef(p, ev.Ref, ev.Tag, false)
+	if ev.Project.WebURL != "" {
+		p.Link = fmt.Sprintf("%s/-/jobs/%d", ev.Project.WebURL, ev.BuildID)
+		p.CommitLink = fmt.Sprintf("%s/-/commit/%s", ev.Project.WebURL, ev.SHA)
+	}
+	return p, nil
+}
+
+func setGitLabRef(p *Pipeline, ref string, tag, mergeRequest bool) {
+	switch {
+	case tag:
+		p.Event = EventTag
+		p.Tag = ref
+		p.Ref = "refs/tags/" + ref
+	case mergeRequest:
+		p.Event = EventPullRequest
+		p.Branch = ref
+		p.Ref = ref
+	default:
+		p.Event = EventPush
+		p.Branch = ref
+		p.Ref = "refs/heads/" + ref
+	}
+}
+
+// gitlabRepo path_with_namespace 取最后两级（子组项目与代码库同步结果一致：namespace 为直属组）
+func gitlabRepo(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) > 2 {
+		parts = parts[len(parts)-2:]
+	}
+	return strings.Join(parts, "/")
+}
+
+// gitlabBefore 新分支/tag 的 before_sha 为全 0，视为无
+func gitlabBefore(sha string) string {
+	if strings.Trim(sha, "0") == "" {
+		return ""
+	}
+	return sha
+}
@@ -0,0 +1,129 @@
+package ci
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+)
+
+// Harbor Webhook 事件类型
+const (
+	HarborEventPushArtifact      = "PUSH_ARTIFACT"      // Artifact pushed
+	HarborEventScanningCompleted = "SCANNING_COMPLETED" // Scanning finished
+	HarborEventScanningFailed    = "SCANNING_FAILED"    // Scanning failed
+)
+
+type harborEvent struct {
+	Type      string `json:"type"`
+	OccurAt   int64  `json:"occur_at"`
+	EventData struct {
+		Resources []struct {
+			Digest       string                        `json:"digest"`
+			Tag          string                        `json:"tag"`
+			ResourceURL  string                        `json:"resource_url"`
+			ScanOverview map[string]harborScanOverview `json:"scan_overview"`
+		} `json:"resources"`
+		Repository struct {
+			Name         string `json:"name"`
+			Namespace    string `json:"namespace"`
+			RepoFullName string `json:"repo_full_name"`
+		} `json:"repository"`
+	} `json:"event_data"`
+}
+
+// harborScanOverview 扫描概要（scan_overview 以报告 MIME 类型为键，漏洞报告如 application/vnd.security.vulnerability.report; version=1.1）
+type harborScanOverview struct {
+	ReportID   string `json:"report_id"`
+	ScanStatus string `json:"scan_status"`
+	EndTime    string `json:"end_time"`
+	Scanner    struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"scanner"`
+	Summary struct {
+		Summary map[string]int `json:"summary"` // Critical/High/Medium/Low/Unknown → 漏洞数
+	} `json:"summary"`
+}
+
+// HarborEvent 解析出的 Harbor 事件：镜像推送或扫描结果
+type HarborEvent struct {
+	Pushes []dto.ImagePushArtifact
+	Scans  []dto.ImageScanReport
+}
+
+// ParseHarbor 解析 Harbor Webhook（HTTP 类型，默认 payload 格式），处理 PUSH_ARTIFACT 与 SCANNING_COMPLETED/SCANNING_FAILED；
+// 无 tag 的镜像（仅 digest）忽略
+func ParseHarbor(payload []byte) (*HarborEvent, error) {
+	var ev harborEvent
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		return nil, fmt.Errorf("解析 Harbor 事件失败: %w", err)
+	}
+	switch ev.Type {
+	case HarborEventPushArtifact, HarborEventScanningCompleted, HarborEventScanningFailed:
+	default:
+		return nil, ErrIgnored
+	}
+
+	occurAt := time.Now()
+	if ev.OccurAt > 0 {
+		occurAt = time.Unix(ev.OccurAt, 0)
+	}
+	out := &HarborEvent{}
+	for _, res := range ev.EventData.Resources {
+		if res.Tag == "" || res.ResourceURL == "" {
+			continue
+		}
+		image := strings.TrimSuffix(strings.TrimSuffix(res.ResourceURL, "@"+res.Digest), ":"+res.Tag)
+		if ev.Type == HarborEventPushArtifact {
+			out.Pushes = append(out.Pushes, dto.ImagePushArtifact{Image: image, Tag: res.Tag, Digest: res.Digest, PushedAt: occurAt})
+			continue
+		}
+		out.Scans = append(out.Scans, harborScanReport(ev.Type, image, res.Tag, res.Digest, res.ScanOverview, occurAt))
+	}
+	if len(out.Pushes) == 0 && len(out.Scans) == 0 {
+		return nil, ErrIgnored
+	}
+	return out, nil
+}
+
+// harborScanReport 将扫描概要转换为扫描结果；SCANNING_FAILED 或扫描状态非 Success 时记为失败
+func harborScanReport(eventType, image, tag, digest string, overviews map[string]harborScanOverview, occurAt time.Time) dto.ImageScanReport {
+	report := dto.ImageScanReport{Image: image, Tag: tag, Digest: digest, Status: model.ImageScanStatusFailed, ScannedAt: occurAt}
+	for mimeType, overview := range overviews {
+		if !strings.Contains(mimeType, "vulnerability") {
+			continue
+		}
+		report.Scanner = strings.TrimSpace(overview.Scanner.Name + " " + overview.Scanner.Version)
+		if t := parseTime(overview.EndTime); !t.IsZero() {
+			report.ScannedAt = t
+		}
+		if eventType == HarborEventScanningCompleted && strings.EqualFold(overview.ScanStatus, "Success") {
+			report.Status = model.ImageScanStatusSuccess
+		}
+		for severity, count := range overview.Summary.Summary {
+			addSeverity(&report, severity, count)
+		}
+		break
+	}
+	return report
+}
+
+// addSeverity 按漏洞级别累加（Harbor 为 Critical/High/...，Trivy 为 CRITICAL/HIGH/...）
+func addSeverity(report *dto.ImageScanReport, severity string, count int) {
+	switch strings.ToUpper(severity) {
+	case "CRITICAL":
+		report.Critical += count
+	case "HIGH":
+		report.High += count
+	case "MEDIUM":
+		report.Medium += count
+	case "LOW":
+		report.Low += count
+	default:
+		report.Unknown += count
+	}
+}
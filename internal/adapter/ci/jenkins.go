@@ -0,0 +1,165 @@
+package ci
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"devops-cd/internal/dto"
+)
+
+// DefaultJenkinsPhase 默认处理的 Notification 插件阶段（QUEUED / STARTED / COMPLETED / FINALIZED）
+const DefaultJenkinsPhase = "FINALIZED"
+
+// Jenkins 任务参数约定
+const (
+	JenkinsParamRepo     = "REPO"      // 代码库 namespace/name，未传时按 scm.url 解析
+	JenkinsParamApps     = "APPS"      // 本次构建的应用，逗号分隔（也可用 APP），未传时按 ci.rules_file 映射
+	JenkinsParamApp      = "APP"       // 同 APPS
+	JenkinsParamImageTag = "IMAGE_TAG" // 镜像 tag（支持模板），未传时 tag 构建取 tag，其余取 short_sha
+	JenkinsParamImage    = "IMAGE"     // 完整镜像地址（支持模板，可选）
+	JenkinsParamTag      = "TAG"       // 非空时视为 tag 构建
+)
+
+// jenkinsStatuses Jenkins 构建结果 → 构建状态；NOT_BUILT 等不产生构建
+var jenkinsStatuses = map[string]string{
+	"SUCCESS":  StatusSuccess,
+	"FAILURE":  StatusFailure,
+	"UNSTABLE": StatusFailure,
+	"ABORTED":  StatusKilled,
+}
+
+// jenkinsNotification Notification 插件（JSON 格式）payload
+type jenkinsNotification struct {
+	Name  string `json:"name"`
+	URL   string `json:"url"`
+	Build struct {
+		FullURL   string `json:"full_url"`
+		Number    int64  `json:"number"`
+		Phase     string `json:"phase"`
+		Status    string `json:"status"`
+		Timestamp int64  `json:"timestamp"` // 开始时间（毫秒）
+		Duration  int64  `json:"duration"`  // 耗时（毫秒）
+		SCM       struct {
+			URL    string `json:"url"`
+			Branch string `json:"branch"`
+			Commit string `json:"commit"`
+		} `json:"scm"`
+		Parameters map[string]interface{} `json:"parameters"`
+	} `json:"build"`
+}
+
+// JenkinsBuild 解析出的 Jenkins 构建
+type JenkinsBuild struct {
+	Pipeline *Pipeline
+	Params   map[string]string
+}
+
+// ParseJenkins 解析 Notification 插件 payload，仅处理 phase 阶段（为空取 FINALIZED）且已有结果的构建
+//
+// 代码库取参数 REPO，未传时取 scm.url 路径的最后两级；任务全名作为 job 名称参与规则匹配（pipeline 规则的 jobs 填任务名）
+func ParseJenkins(payload []byte, phase string) (*JenkinsBuild, error) {
+	var ev jenkinsNotification
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		return nil, fmt.Errorf("解析 Jenkins 构建通知失败: %w", err)
+	}
+	if phase == "" {
+		phase = DefaultJenkinsPhase
+	}
+	build := ev.Build
+	status, ok := jenkinsStatuses[strings.ToUpper(build.Status)]
+	if !strings.EqualFold(build.Phase, phase) || !ok {
+		return nil, ErrIgnored
+	}
+
+	params := make(map[string]string, len(build.Parameters))
+	for k, v := range build.Parameters {
+		if v != nil {
+			params[k] = strings.TrimSpace(fmt.Sprint(v))
+		}
+	}
+	repo := params[JenkinsParamRepo]
+	if repo == "" {
+		repo = RepoFromGitURL(build.SCM.URL)
+	}
+	if repo == "" {
+		return nil, fmt.Errorf("无法确定代码库：未传参数 %s 且 scm.url 为空", JenkinsParamRepo)
+	}
+
+	p := &Pipeline{
+		Repo:   repo,
+		Number: build.Number,
+		Status: status,
+		SHA:    build.SCM.Commit,
+		Link:   build.FullURL,
+		Jobs:   map[string]string{ev.Name: status},
+	}
+	if build.Timestamp > 0 {
+		p.Started = time.UnixMilli(build.Timestamp)
+		p.Created = p.Started
+		if build.Duration > 0 {
+			p.Finished = p.Started.Add(time.Duration(build.Duration) * time.Millisecond)
+		}
+	}
+	setJenkinsRef(p, build.SCM.Branch, params[JenkinsParamTag])
+	return &JenkinsBuild{Pipeline: p, Params: params}, nil
+}
+
+// Notify 转换为构建通知：传了 APPS/APP 参数时按参数确定应用，否则按规则映射
+func (b *JenkinsBuild) Notify(rules *Rules) (*dto.BuildNotifyRequest, error) {
+	names := b.Params[JenkinsParamApps]
+	if names == "" {
+		names = b.Params[JenkinsParamApp]
+	}
+	if names == "" {
+		return rules.Notify(b.Pipeline)
+	}
+
+	var apps []ruleApp
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		apps = append(apps, ruleApp{
+			rule:    AppRule{Name: name, ImageTag: b.Params[JenkinsParamImageTag], Image: b.Params[JenkinsParamImage]},
+			success: b.Pipeline.Status == StatusSuccess,
+		})
+	}
+	return b.Pipeline.notify(apps)
+}
+
+// setJenkinsRef 按 scm.branch（如 origin/main、refs/tags/v1.0.0）与 TAG 参数确定触发事件
+func setJenkinsRef(p *Pipeline, branch, tag string) {
+	branch = strings.TrimPrefix(branch, "origin/")
+	if t, ok := strings.CutPrefix(branch, "refs/tags/"); ok && tag == "" {
+		tag = t
+	}
+	if tag != "" {
+		p.Event = EventTag
+		p.Tag = tag
+		p.Ref = "refs/tags/" + tag
+		return
+	}
+	branch = strings.TrimPrefix(branch, "refs/heads/")
+	p.Event = EventPush
+	p.Branch = branch
+	p.Ref = "refs/heads/" + branch
+}
+
+// RepoFromGitURL 从 Git 地址解析代码库 namespace/name（路径最后两级），兼容 https、ssh 与 scp 形式（git@host:group/repo.git）
+func RepoFromGitURL(gitURL string) string {
+	gitURL = strings.TrimSpace(gitURL)
+	path := gitURL
+	if u, err := url.Parse(gitURL); err == nil && u.Scheme != "" && u.Host != "" {
+		path = u.Path
+	} else if i := strings.Index(gitURL, ":"); i >= 0 {
+		path = gitURL[i+1:]
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if path == "" {
+		return ""
+	}
+	return gitlabRepo(path)
+}
@@ -0,0 +1,182 @@
+package ci
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 规则触发事件
+const (
+	RuleEventPipeline = "pipeline" // 整条流水线完成时通知（默认）
+	RuleEventJob      = "job"      // 应用对应的 job 完成时通知
+)
+
+// Rules 外部 CI 构建通知的应用映射规则（ci.rules_file）
+//
+//	rules:
+//	  - repo: group/monorepo              # 代码库 namespace/name（与代码库同步结果一致，GitLab 子组取最后一级）
+//	    event: pipeline                   # pipeline（默认）/ job
+//	    apps:
+//	      - name: api                     # 应用名（需属于该代码库）
+//	        jobs: [build-api]             # 构建该应用的 job（GitHub pipeline 规则填工作流名称），全部成功视为构建成功；流水线中没有这些 job 时不产生构建
+//	        image_tag: "{{ .tag }}"       # 镜像 tag 模板，默认 tag 流水线取 tag，其余取 short_sha
+//	        image: "registry/api:{{ .short_sha }}" # 完整镜像地址模板（可选）
+//
+// 未配置规则的代码库视为与仓库同名的单应用，按整条流水线状态通知
+type Rules struct {
+	Rules []RepoRule `yaml:"rules"`
+}
+
+// RepoRule 单个代码库的规则
+type RepoRule struct {
+	Repo  string    `yaml:"repo"`
+	Event string    `yaml:"event"`
+	Apps  []AppRule `yaml:"apps"`
+}
+
+// AppRule 代码库内单个应用的规则
+type AppRule struct {
+	Name     string   `yaml:"name"`
+	Jobs     []string `yaml:"jobs"`
+	ImageTag string   `yaml:"image_tag"`
+	Image    string   `yaml:"image"`
+}
+
+// LoadRules 读取规则文件，path 为空时返回空规则
+func LoadRules(path string) (*Rules, error) {
+	rules := &Rules{}
+	if strings.TrimSpace(path) == "" {
+		return rules, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 CI 规则文件失败: %w", err)
+	}
+	if err := yaml.Unmarshal(data, rules); err != nil {
+		return nil, fmt.Errorf("解析 CI 规则文件失败: %w", err)
+	}
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Validate 校验规则：repo / 应用名必填，事件合法，模板可解析
+func (r *Rules) Validate() error {
+	seen := make(map[string]bool, len(r.Rules))
+	for i, rule := range r.Rules {
+		repo := strings.ToLower(strings.TrimSpace(rule.Repo))
+		if repo == "" {
+			return fmt.Errorf("rules[%d]: repo 不能为空", i)
+		}
+		if seen[repo] {
+			return fmt.Errorf("rules[%d]: repo 重复: %s", i, rule.Repo)
+		}
+		seen[repo] = true
+		if rule.Event != "" && rule.Event != RuleEventPipeline && rule.Event != RuleEventJob {
+			return fmt.Errorf("%s: 不支持的 event: %s", rule.Repo, rule.Event)
+		}
+		if len(rule.Apps) == 0 {
+			return fmt.Errorf("%s: apps 不能为空", rule.Repo)
+		}
+		for j, app := range rule.Apps {
+			if strings.TrimSpace(app.Name) == "" {
+				return fmt.Errorf("%s: apps[%d].name 不能为空", rule.Repo, j)
+			}
+			if rule.Event == RuleEventJob && len(app.Jobs) == 0 {
+				return fmt.Errorf("%s: event=job 时 apps[%d].jobs 不能为空", rule.Repo, j)
+			}
+			for _, text := range []string{app.ImageTag, app.Image} {
+				if _, err := template.New("").Option("missingkey=error").Parse(text); err != nil {
+					return fmt.Errorf("%s: 应用 %s 模板不合法: %w", rule.Repo, app.Name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// find 按 namespace/name 查找规则（不区分大小写）
+func (r *Rules) find(repo string) *RepoRule {
+	if r == nil {
+		return nil
+	}
+	for i := range r.Rules {
+		if strings.EqualFold(strings.TrimSpace(r.Rules[i].Repo), repo) {
+			return &r.Rules[i]
+		}
+	}
+	return nil
+}
+
+// ruleApp 规则匹配出的应用
+type ruleApp struct {
+	rule    AppRule
+	success bool
+}
+
+// matchApps 按规则确定本次通知涉及的应用及各自是否构建成功
+func (r *Rules) matchApps(p *Pipeline) []ruleApp {
+	rule := r.find(p.Repo)
+	if rule == nil {
+		// 无规则：同名单应用，仅处理流水线事件
+		if p.Job != "" {
+			return nil
+		}
+		_, name := splitRepo(p.Repo)
+		return []ruleApp{{rule: AppRule{Name: name}, success: p.Status == StatusSuccess}}
+	}
+
+	jobEvent := p.Job != ""
+	if jobEvent != (rule.Event == RuleEventJob) {
+		return nil
+	}
+
+	var apps []ruleApp
+	for _, app := range rule.Apps {
+		if jobEvent {
+			if slices.Contains(app.Jobs, p.Job) {
+				apps = append(apps, ruleApp{rule: app, success: p.Status == StatusSuccess})
+			}
+			continue
+		}
+		if len(app.Jobs) == 0 {
+			apps = append(apps, ruleApp{rule: app, success: p.Status == StatusSuccess})
+			continue
+		}
+		ran, success := false, true
+		for _, job := range app.Jobs {
+			status, ok := p.Jobs[job]
+			if !ok {
+				continue
+			}
+			ran = true
+			if status != StatusSuccess {
+				success = false
+			}
+		}
+		if ran {
+			apps = append(apps, ruleApp{rule: app, success: success})
+		}
+	}
+	return apps
+}
+
+// render 渲染镜像 tag / 镜像地址模板
+func render(text string, data map[string]interface{}) (string, error) {
+	tpl, err := template.New("").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,82 @@
+package ci
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+)
+
+// trivyReport trivy image --format json 输出（SchemaVersion 2）
+type trivyReport struct {
+	ArtifactName string `json:"ArtifactName"`
+	ArtifactType string `json:"ArtifactType"`
+	CreatedAt    string `json:"CreatedAt"`
+	Trivy        struct {
+		Version string `json:"Version"`
+	} `json:"Trivy"` // 新版本 Trivy 输出
+	Metadata struct {
+		RepoDigests []string `json:"RepoDigests"`
+	} `json:"Metadata"`
+	Results []struct {
+		Vulnerabilities []struct {
+			VulnerabilityID string `json:"VulnerabilityID"`
+			PkgName         string `json:"PkgName"`
+			Severity        string `json:"Severity"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+// ParseTrivy 解析 Trivy 镜像扫描报告（JSON 格式），ArtifactName 需为带 tag 的镜像地址（如 registry/app:v1.0.0）；
+// 同一漏洞在多个目标中出现时按 漏洞ID + 包名 去重计数
+func ParseTrivy(payload []byte) (*dto.ImageScanReport, error) {
+	var r trivyReport
+	if err := json.Unmarshal(payload, &r); err != nil {
+		return nil, fmt.Errorf("解析 Trivy 报告失败: %w", err)
+	}
+	if r.ArtifactType != "" && r.ArtifactType != "container_image" {
+		return nil, fmt.Errorf("仅支持镜像扫描报告: ArtifactType=%s", r.ArtifactType)
+	}
+
+	name := strings.TrimSpace(r.ArtifactName)
+	if i := strings.Index(name, "@"); i >= 0 {
+		name = name[:i]
+	}
+	i := strings.LastIndex(name, ":")
+	if i <= strings.LastIndex(name, "/") {
+		return nil, fmt.Errorf("ArtifactName 需包含镜像 tag: %q", r.ArtifactName)
+	}
+
+	report := &dto.ImageScanReport{
+		Image:     name[:i],
+		Tag:       name[i+1:],
+		Scanner:   strings.TrimSpace("Trivy " + r.Trivy.Version),
+		Status:    model.ImageScanStatusSuccess,
+		ScannedAt: parseTime(r.CreatedAt),
+	}
+	if report.ScannedAt.IsZero() {
+		report.ScannedAt = time.Now()
+	}
+	for _, d := range r.Metadata.RepoDigests {
+		if _, digest, ok := strings.Cut(d, "@"); ok {
+			report.Digest = digest
+			break
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, result := range r.Results {
+		for _, v := range result.Vulnerabilities {
+			key := v.VulnerabilityID + "/" + v.PkgName
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			addSeverity(report, v.Severity, 1)
+		}
+	}
+	return report, nil
+}
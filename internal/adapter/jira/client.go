@@ -0,0 +1,127 @@
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+// Client Jira REST API（v2）客户端，仅实现 issue 流转
+type Client struct {
+	baseURL string
+	cred    map[string]string // 凭据明文（_type: basic_auth / token）
+	client  *http.Client
+}
+
+// NewClient 创建 Jira 客户端；cred 为空时匿名访问
+func NewClient(baseURL string, cred map[string]string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		cred:    cred,
+		client: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}
+}
+
+type transition struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	To   struct {
+		Name string `json:"name"`
+	} `json:"to"`
+}
+
+// Transition 将 issue 按流转名称或目标状态名流转；issue 已处于目标状态时返回 done=false 且不报错
+func (c *Client) Transition(ctx context.Context, key, name string) (bool, error) {
+	var issue struct {
+		Fields struct {
+			Status struct {
+				Name string `json:"name"`
+			} `json:"status"`
+		} `json:"fields"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/rest/api/2/issue/"+neturl.PathEscape(key)+"?fields=status", nil, &issue); err != nil {
+		return false, err
+	}
+	if strings.EqualFold(issue.Fields.Status.Name, name) {
+		return false, nil
+	}
+
+	var out struct {
+		Transitions []transition `json:"transitions"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/rest/api/2/issue/"+neturl.PathEscape(key)+"/transitions", nil, &out); err != nil {
+		return false, err
+	}
+	var target *transition
+	for i := range out.Transitions {
+		t := &out.Transitions[i]
+		if strings.EqualFold(t.Name, name) || strings.EqualFold(t.To.Name, name) {
+			target = t
+			break
+		}
+	}
+	if target == nil {
+		return false, fmt.Errorf("issue %s 当前状态 %s 没有可用的流转 %s", key, issue.Fields.Status.Name, name)
+	}
+
+	body := map[string]interface{}{"transition": map[string]string{"id": target.ID}}
+	if err := c.do(ctx, http.MethodPost, "/rest/api/2/issue/"+neturl.PathEscape(key)+"/transitions", body, nil); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("序列化请求失败: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.setAuth(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求 Jira 失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("Jira 返回错误 (状态码: %d): %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// setAuth basic_auth（Jira Cloud 为邮箱 + API token）或 token（Data Center PAT）
+func (c *Client) setAuth(req *http.Request) {
+	switch c.cred["_type"] {
+	case "basic_auth":
+		req.SetBasicAuth(c.cred["username"], c.cred["password"])
+	case "token":
+		if t := strings.TrimSpace(c.cred["token"]); t != "" {
+			req.Header.Set("Authorization", "Bearer "+t)
+		}
+	}
+}
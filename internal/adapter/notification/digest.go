@@ -0,0 +1,303 @@
+package notification
+
+import (
+	"context"
+	"devops-cd/internal/model"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ============= 批次通知汇总 =============
+
+// DefaultDigestMaxWait 汇总最长等待时间：阶段迟迟未结束（如部分应用卡住）时也会发出已收集的结果
+const DefaultDigestMaxWait = 30 * time.Minute
+
+// 汇总卡片中成功应用最多列出的个数
+const digestMaxSuccessApps = 50
+
+// DigestStore 汇总所需的项目配置与批次信息
+type DigestStore interface {
+	// Enabled 批次所属项目是否开启部署结果汇总（projects.notify_digest）
+	Enabled(ctx context.Context, batchID int64) (bool, error)
+	// Batch 查询批次（超时发送汇总时构建消息）
+	Batch(ctx context.Context, batchID int64) (*model.Batch, error)
+}
+
+// DigestEntry 汇总中的单个应用部署结果
+type DigestEntry struct {
+	AppID   int64
+	AppName string
+	Failed  bool
+	Message string
+}
+
+type digestKey struct {
+	batchID int64
+	stage   string
+}
+
+type digestBuffer struct {
+	entries map[int64]*DigestEntry // app_id → 最后一次结果（重试成功覆盖之前的失败）
+	timer   *time.Timer
+}
+
+// Digest 按批次、阶段（pre/prod/rollback）收集应用部署结果，阶段结束时发送一张汇总卡片，代替逐个应用的通知
+//
+//   - 只对开启 notify_digest 的项目生效，其余项目仍逐个应用发送
+//   - 批次进入阶段结束状态（部署完成/失败、回滚完成/失败、取消、完成）时发送该阶段汇总，失败应用置顶并附失败原因
+//   - 收集超过 maxWait 仍未结束的阶段先发送已收集的结果，之后的结果重新收集
+//   - 汇总保存在内存中，进程重启前未发送的结果会丢失
+type Digest struct {
+	base     Notifier
+	store    DigestStore
+	logger   *zap.Logger
+	maxWait  time.Duration
+	schedule func(task func(ctx context.Context) error) // 超时发送交给调用方的通知队列，保证与其他通知顺序一致
+
+	mu      sync.Mutex
+	buffers map[digestKey]*digestBuffer
+}
+
+// NewDigest 创建汇总器，maxWait <= 0 时使用 DefaultDigestMaxWait
+func NewDigest(base Notifier, store DigestStore, maxWait time.Duration, schedule func(task func(ctx context.Context) error), logger *zap.Logger) *Digest {
+	if maxWait <= 0 {
+		maxWait = DefaultDigestMaxWait
+	}
+	return &Digest{
+		base:     base,
+		store:    store,
+		logger:   logger,
+		maxWait:  maxWait,
+		schedule: schedule,
+		buffers:  make(map[digestKey]*digestBuffer),
+	}
+}
+
+// Add 收集应用部署结果；项目未开启汇总时返回 false，由调用方按原方式逐个发送
+func (d *Digest) Add(ctx context.Context, batchID int64, stage string, entry DigestEntry) (bool, error) {
+	if batchID == 0 {
+		return false, nil
+	}
+	key := digestKey{batchID: batchID, stage: stage}
+
+	d.mu.Lock()
+	buf, ok := d.buffers[key]
+	d.mu.Unlock()
+	if !ok {
+		enabled, err := d.store.Enabled(ctx, batchID)
+		if err != nil {
+			return false, fmt.Errorf("查询项目通知汇总配置失败: %w", err)
+		}
+		if !enabled {
+			return false, nil
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if buf, ok = d.buffers[key]; !ok {
+		buf = &digestBuffer{entries: make(map[int64]*DigestEntry)}
+		buf.timer = time.AfterFunc(d.maxWait, func() { d.expire(key, buf) })
+		d.buffers[key] = buf
+	}
+	buf.entries[entry.AppID] = &entry
+	return true, nil
+}
+
+// Flush 发送批次指定阶段的汇总，stages 为空时发送该批次全部阶段
+func (d *Digest) Flush(ctx context.Context, batch *model.Batch, stages ...string) error {
+	var lastErr error
+	for _, stage := range d.take(batch.ID, stages) {
+		if err := d.send(ctx, batch, stage.name, stage.entries); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// FlushAll 发送全部未发送的汇总（引擎停止时调用）
+func (d *Digest) FlushAll(ctx context.Context) {
+	d.mu.Lock()
+	batchIDs := make(map[int64]struct{}, len(d.buffers))
+	for key := range d.buffers {
+		batchIDs[key.batchID] = struct{}{}
+	}
+	d.mu.Unlock()
+
+	for batchID := range batchIDs {
+		batch, err := d.store.Batch(ctx, batchID)
+		if err != nil {
+			d.logger.Warn("查询批次失败，丢弃未发送的部署汇总", zap.Int64("batch_id", batchID), zap.Error(err))
+			d.take(batchID, nil)
+			continue
+		}
+		if err := d.Flush(ctx, batch); err != nil {
+			d.logger.Warn("发送部署汇总失败", zap.Int64("batch_id", batchID), zap.Error(err))
+		}
+	}
+}
+
+type digestStage struct {
+	name    string
+	entries []*DigestEntry
+}
+
+// take 取出并移除批次的汇总缓冲
+func (d *Digest) take(batchID int64, stages []string) []digestStage {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var out []digestStage
+	for key, buf := range d.buffers {
+		if key.batchID != batchID || (len(stages) > 0 && !slices.Contains(stages, key.stage)) {
+			continue
+		}
+		buf.timer.Stop()
+		delete(d.buffers, key)
+		entries := make([]*DigestEntry, 0, len(buf.entries))
+		for _, e := range buf.entries {
+			entries = append(entries, e)
+		}
+		out = append(out, digestStage{name: key.stage, entries: entries})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].name < out[j].name })
+	return out
+}
+
+// expire 收集超时：缓冲仍是同一个时（未被阶段结束取走）交给通知队列发送
+func (d *Digest) expire(key digestKey, buf *digestBuffer) {
+	d.mu.Lock()
+	current, ok := d.buffers[key]
+	d.mu.Unlock()
+	if !ok || current != buf {
+		return
+	}
+	d.schedule(func(ctx context.Context) error {
+		batch, err := d.store.Batch(ctx, key.batchID)
+		if err != nil {
+			return fmt.Errorf("查询批次失败: %w", err)
+		}
+		return d.Flush(ctx, batch, key.stage)
+	})
+}
+
+func (d *Digest) send(ctx context.Context, batch *model.Batch, stage string, entries []*DigestEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return d.base.Send(ctx, DigestMessage(batch, stage, entries))
+}
+
+// 汇总阶段名称
+var digestStageNames = map[string]string{
+	"pre":      "预发布",
+	"prod":     "生产",
+	"rollback": "回滚",
+}
+
+// DigestMessage 构建批次阶段部署汇总消息：有失败应用时类型为 deploy_failed，否则为 deploy_success
+func DigestMessage(batch *model.Batch, stage string, entries []*DigestEntry) *NotificationMessage {
+	var failed, succeeded []*DigestEntry
+	for _, e := range entries {
+		if e.Failed {
+			failed = append(failed, e)
+		} else {
+			succeeded = append(succeeded, e)
+		}
+	}
+	byName := func(list []*DigestEntry) {
+		sort.Slice(list, func(i, j int) bool { return list[i].AppName < list[j].AppName })
+	}
+	byName(failed)
+	byName(succeeded)
+
+	stageName := digestStageNames[stage]
+	if stageName == "" {
+		stageName = stage
+	}
+	notifyType, title, color := NotifyDeploySuccess, fmt.Sprintf("✅ %s部署汇总", stageName), "green"
+	if len(failed) > 0 {
+		notifyType, title, color = NotifyDeployFailed, fmt.Sprintf("❌ %s部署汇总（%d 个应用失败）", stageName, len(failed)), "red"
+	}
+
+	summary := fmt.Sprintf("成功 %d / 失败 %d / 共 %d", len(succeeded), len(failed), len(entries))
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "**批次编号**: %s\n**发起人**: %s\n**结果**: %s", batch.BatchNumber, batch.Initiator, summary)
+	if len(failed) > 0 {
+		sb.WriteString("\n\n**失败应用**:")
+		for _, e := range failed {
+			fmt.Fprintf(&sb, "\n- ❌ **%s**: %s", e.AppName, strings.ReplaceAll(e.Message, "\n", " "))
+		}
+	}
+	if len(succeeded) > 0 {
+		names := make([]string, 0, digestMaxSuccessApps)
+		for i, e := range succeeded {
+			if i == digestMaxSuccessApps {
+				break
+			}
+			names = append(names, e.AppName)
+		}
+		line := strings.Join(names, ", ")
+		if len(succeeded) > digestMaxSuccessApps {
+			line += fmt.Sprintf(" 等 %d 个", len(succeeded))
+		}
+		sb.WriteString("\n\n**成功应用**: " + line)
+	}
+
+	failedApps := make([]string, 0, len(failed))
+	for _, e := range failed {
+		failedApps = append(failedApps, e.AppName)
+	}
+	return &NotificationMessage{
+		Type:      notifyType,
+		Title:     title,
+		Content:   sb.String(),
+		Timestamp: time.Now(),
+		Extra: map[string]interface{}{
+			"batch_id":      batch.ID,
+			"batch_number":  batch.BatchNumber,
+			"project_id":    batch.ProjectID,
+			"initiator":     batch.Initiator,
+			"stage":         stage,
+			"success_count": len(succeeded),
+			"failed_count":  len(failed),
+			"failed_apps":   failedApps,
+			"message":       summary,
+			"color":         color,
+		},
+	}
+}
+
+// ============= 汇总配置存储 =============
+
+// dbDigestStore 按 release_batches.project_id → projects.notify_digest 判断是否汇总
+type dbDigestStore struct {
+	db *gorm.DB
+}
+
+// NewDBDigestStore 创建基于数据库的汇总配置存储
+func NewDBDigestStore(db *gorm.DB) DigestStore {
+	return &dbDigestStore{db: db}
+}
+
+func (s *dbDigestStore) Enabled(ctx context.Context, batchID int64) (bool, error) {
+	var project model.Project
+	err := s.db.WithContext(ctx).Select("projects.id", "projects.notify_digest").
+		Joins("JOIN release_batches ON release_batches.project_id = projects.id").
+		Where("release_batches.id = ?", batchID).Limit(1).Find(&project).Error
+	return project.NotifyDigest, err
+}
+
+func (s *dbDigestStore) Batch(ctx context.Context, batchID int64) (*model.Batch, error) {
+	var batch model.Batch
+	if err := s.db.WithContext(ctx).First(&batch, batchID).Error; err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
@@ -0,0 +1,130 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"devops-cd/internal/model"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ============= 钉钉通知适配器 =============
+
+// DingTalkNotifier 钉钉自定义机器人通知器（markdown 消息）
+type DingTalkNotifier struct {
+	webhookURL string
+	secret     string // 加签密钥（机器人安全设置选择"加签"时配置）
+	logger     *zap.Logger
+	client     *http.Client
+}
+
+// NewDingTalkNotifier 创建钉钉通知器
+func NewDingTalkNotifier(webhookURL, secret string, logger *zap.Logger) *DingTalkNotifier {
+	return &DingTalkNotifier{
+		webhookURL: webhookURL,
+		secret:     secret,
+		logger:     logger,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Send 发送通知
+func (n *DingTalkNotifier) Send(ctx context.Context, msg *NotificationMessage) error {
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]interface{}{
+			"title": msg.Title,
+			"text":  dingTalkMarkdown(msg),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("序列化消息失败: %w", err)
+	}
+
+	target, err := n.signedURL(time.Now())
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("钉钉 API返回错误状态码: %d", resp.StatusCode)
+	}
+	// 业务错误（签名错误、关键词不匹配、限流等）以 200 + errcode 返回
+	var result struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("解析钉钉响应失败: %w", err)
+	}
+	if result.ErrCode != 0 {
+		return fmt.Errorf("钉钉 API 返回错误: errcode=%d errmsg=%s", result.ErrCode, result.ErrMsg)
+	}
+
+	n.logger.Info("钉钉通知发送成功",
+		zap.String("type", string(msg.Type)),
+		zap.String("title", msg.Title))
+	return nil
+}
+
+// SendBatchNotification 发送批次通知
+func (n *DingTalkNotifier) SendBatchNotification(ctx context.Context, batch *model.Batch, notifyType NotificationType, message string) error {
+	return n.Send(ctx, BatchMessage(batch, notifyType, message))
+}
+
+// SendAppDeployNotification 发送应用部署通知
+func (n *DingTalkNotifier) SendAppDeployNotification(ctx context.Context, batchID int64, appID int64, appName string, notifyType NotificationType, message string) error {
+	return n.Send(ctx, AppDeployMessage(batchID, appID, appName, notifyType, message))
+}
+
+// signedURL 配置加签密钥时在 Webhook 上追加 timestamp 与 sign：
+// sign = urlencode(base64(HMAC-SHA256(secret, timestamp + "\n" + secret)))
+func (n *DingTalkNotifier) signedURL(now time.Time) (string, error) {
+	if n.secret == "" {
+		return n.webhookURL, nil
+	}
+	u, err := url.Parse(n.webhookURL)
+	if err != nil {
+		return "", fmt.Errorf("钉钉 Webhook 地址非法: %w", err)
+	}
+	timestamp := strconv.FormatInt(now.UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(n.secret))
+	mac.Write([]byte(timestamp + "\n" + n.secret))
+	q := u.Query()
+	q.Set("timestamp", timestamp)
+	q.Set("sign", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// dingTalkMarkdown 钉钉 markdown 正文：标题 + 内容 + 时间，单个换行需行尾两个空格才会换行
+func dingTalkMarkdown(msg *NotificationMessage) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### %s\n\n", msg.Title)
+	sb.WriteString(strings.ReplaceAll(msg.Content, "\n", "  \n"))
+	fmt.Fprintf(&sb, "\n\n> 时间: %s", msg.Timestamp.Format("2006-01-02 15:04:05"))
+	return sb.String()
+}
@@ -0,0 +1,70 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// ============= 直接通知（个人订阅） =============
+
+// 直接通知渠道
+const (
+	ChannelLark  = "lark"  // Lark 应用机器人私聊（按邮箱）
+	ChannelEmail = "email" // SMTP 邮件
+)
+
+// DirectChannels 支持的直接通知渠道
+var DirectChannels = []string{ChannelLark, ChannelEmail}
+
+// Recipient 直接通知接收人
+type Recipient struct {
+	Username string
+	Email    string
+}
+
+// DirectNotifier 发送给指定用户的通知（订阅了批次/应用的用户）
+type DirectNotifier interface {
+	// Channel 渠道标识（ChannelLark / ChannelEmail）
+	Channel() string
+	// SendTo 发送给指定用户
+	SendTo(ctx context.Context, to *Recipient, msg *NotificationMessage) error
+}
+
+// LarkDirectNotifier Lark 应用机器人私聊，按用户邮箱发送（需机器人可见范围包含该用户）
+type LarkDirectNotifier struct {
+	app    *larkApp
+	logger *zap.Logger
+}
+
+// NewLarkDirectNotifier 创建 Lark 私聊通知器（只使用 APIBase / AppID / AppSecret）
+func NewLarkDirectNotifier(cfg LarkAppConfig, logger *zap.Logger) *LarkDirectNotifier {
+	return &LarkDirectNotifier{app: newLarkApp(cfg), logger: logger}
+}
+
+// Channel 直接通知渠道
+func (n *LarkDirectNotifier) Channel() string {
+	return ChannelLark
+}
+
+// SendTo 私聊发送给指定用户
+func (n *LarkDirectNotifier) SendTo(ctx context.Context, to *Recipient, msg *NotificationMessage) error {
+	if to.Email == "" {
+		return fmt.Errorf("用户 %s 未设置邮箱", to.Username)
+	}
+	content, err := json.Marshal(larkCard(msg))
+	if err != nil {
+		return fmt.Errorf("序列化消息失败: %w", err)
+	}
+	if _, err := n.app.call(ctx, "/open-apis/im/v1/messages?receive_id_type=email", map[string]interface{}{
+		"receive_id": to.Email,
+		"msg_type":   "interactive",
+		"content":    string(content),
+	}); err != nil {
+		return err
+	}
+	n.logger.Debug("Lark私聊通知发送成功", zap.String("user", to.Username), zap.String("title", msg.Title))
+	return nil
+}
@@ -0,0 +1,203 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"devops-cd/internal/model"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ============= 邮件通知适配器 =============
+
+// SMTPConfig SMTP 邮件配置
+type SMTPConfig struct {
+	Host     string
+	Port     int // 默认 587（STARTTLS），TLS=true 时默认 465
+	Username string
+	Password string
+	From     string   // 发件人，如 "DevOps CD <cd@example.com>"，默认 Username
+	To       []string // 收件人
+	TLS      bool     // 隐式 TLS（SMTPS，465 端口）；否则服务器支持时使用 STARTTLS
+}
+
+// EmailNotifier SMTP 邮件通知器（纯文本邮件）
+type EmailNotifier struct {
+	cfg     SMTPConfig
+	logger  *zap.Logger
+	timeout time.Duration
+}
+
+// NewEmailNotifier 创建邮件通知器
+func NewEmailNotifier(cfg SMTPConfig, logger *zap.Logger) *EmailNotifier {
+	if cfg.Port == 0 {
+		cfg.Port = 587
+		if cfg.TLS {
+			cfg.Port = 465
+		}
+	}
+	if cfg.From == "" {
+		cfg.From = cfg.Username
+	}
+	return &EmailNotifier{cfg: cfg, logger: logger, timeout: 30 * time.Second}
+}
+
+// Send 发送通知到配置的收件人
+func (n *EmailNotifier) Send(ctx context.Context, msg *NotificationMessage) error {
+	to := make([]*mail.Address, 0, len(n.cfg.To))
+	for _, s := range n.cfg.To {
+		addr, err := mail.ParseAddress(s)
+		if err != nil {
+			return fmt.Errorf("收件人地址非法: %w", err)
+		}
+		to = append(to, addr)
+	}
+	if len(to) == 0 {
+		n.logger.Warn("邮件收件人未配置")
+		return nil
+	}
+	return n.send(ctx, to, msg)
+}
+
+// Channel 直接通知渠道
+func (n *EmailNotifier) Channel() string {
+	return ChannelEmail
+}
+
+// SendTo 发送邮件给指定用户
+func (n *EmailNotifier) SendTo(ctx context.Context, to *Recipient, msg *NotificationMessage) error {
+	if to.Email == "" {
+		return fmt.Errorf("用户 %s 未设置邮箱", to.Username)
+	}
+	return n.send(ctx, []*mail.Address{{Name: to.Username, Address: to.Email}}, msg)
+}
+
+func (n *EmailNotifier) send(ctx context.Context, to []*mail.Address, msg *NotificationMessage) error {
+	from, err := mail.ParseAddress(n.cfg.From)
+	if err != nil {
+		return fmt.Errorf("发件人地址非法: %w", err)
+	}
+	body, err := emailMessage(from, to, msg)
+	if err != nil {
+		return err
+	}
+	if err := n.deliver(ctx, from.Address, to, body); err != nil {
+		return err
+	}
+
+	n.logger.Info("邮件通知发送成功",
+		zap.String("type", string(msg.Type)),
+		zap.String("title", msg.Title),
+		zap.Int("recipients", len(to)))
+	return nil
+}
+
+// SendBatchNotification 发送批次通知
+func (n *EmailNotifier) SendBatchNotification(ctx context.Context, batch *model.Batch, notifyType NotificationType, message string) error {
+	return n.Send(ctx, BatchMessage(batch, notifyType, message))
+}
+
+// SendAppDeployNotification 发送应用部署通知
+func (n *EmailNotifier) SendAppDeployNotification(ctx context.Context, batchID int64, appID int64, appName string, notifyType NotificationType, message string) error {
+	return n.Send(ctx, AppDeployMessage(batchID, appID, appName, notifyType, message))
+}
+
+// deliver 连接 SMTP 服务器投递（net/smtp 不支持 context，以连接 deadline 控制超时）
+func (n *EmailNotifier) deliver(ctx context.Context, from string, to []*mail.Address, body []byte) error {
+	addr := net.JoinHostPort(n.cfg.Host, strconv.Itoa(n.cfg.Port))
+	tlsConfig := &tls.Config{ServerName: n.cfg.Host}
+	dialer := &net.Dialer{Timeout: n.timeout}
+
+	var conn net.Conn
+	var err error
+	if n.cfg.TLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("连接 SMTP 服务器失败: %w", err)
+	}
+	deadline := time.Now().Add(n.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, n.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("连接 SMTP 服务器失败: %w", err)
+	}
+	defer c.Close()
+
+	if !n.cfg.TLS {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("SMTP STARTTLS 失败: %w", err)
+			}
+		}
+	}
+	if n.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, n.cfg.Host)); err != nil {
+			return fmt.Errorf("SMTP 认证失败: %w", err)
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM 失败: %w", err)
+	}
+	for _, addr := range to {
+		if err := c.Rcpt(addr.Address); err != nil {
+			return fmt.Errorf("SMTP RCPT TO %s 失败: %w", addr.Address, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA 失败: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("写入邮件内容失败: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP 投递失败: %w", err)
+	}
+	return c.Quit()
+}
+
+// emailMessage 构建 UTF-8 纯文本邮件（quoted-printable），正文去掉 markdown 粗体标记
+func emailMessage(from *mail.Address, to []*mail.Address, msg *NotificationMessage) ([]byte, error) {
+	recipients := make([]string, 0, len(to))
+	for _, addr := range to {
+		recipients = append(recipients, addr.String())
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", msg.Title))
+	fmt.Fprintf(&buf, "Date: %s\r\n", msg.Timestamp.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	text := larkBold.ReplaceAllString(msg.Content, "$1")
+	text += fmt.Sprintf("\n\n时间: %s\n", msg.Timestamp.Format("2006-01-02 15:04:05"))
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(strings.ReplaceAll(text, "\n", "\r\n"))); err != nil {
+		return nil, fmt.Errorf("编码邮件内容失败: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return nil, fmt.Errorf("编码邮件内容失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
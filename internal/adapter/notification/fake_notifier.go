@@ -0,0 +1,63 @@
+//go:build integration
+
+package notification
+
+import (
+	"context"
+	"sync"
+
+	"devops-cd/internal/model"
+)
+
+// FakeNotifier 内存通知器，记录所有通知供集成测试断言
+type FakeNotifier struct {
+	mu       sync.Mutex
+	messages []*NotificationMessage
+}
+
+// NewFakeNotifier 创建内存通知器
+func NewFakeNotifier() *FakeNotifier {
+	return &FakeNotifier{}
+}
+
+// Messages 返回已发送通知的副本
+func (n *FakeNotifier) Messages() []*NotificationMessage {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	out := make([]*NotificationMessage, len(n.messages))
+	copy(out, n.messages)
+	return out
+}
+
+// Send 记录通知
+func (n *FakeNotifier) Send(ctx context.Context, msg *NotificationMessage) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.messages = append(n.messages, msg)
+	return nil
+}
+
+// SendBatchNotification 记录批次通知
+func (n *FakeNotifier) SendBatchNotification(ctx context.Context, batch *model.Batch, notifyType NotificationType, message string) error {
+	return n.Send(ctx, &NotificationMessage{
+		Type:    notifyType,
+		Content: message,
+		Extra: map[string]interface{}{
+			"batch_id":     batch.ID,
+			"batch_number": batch.BatchNumber,
+		},
+	})
+}
+
+// SendAppDeployNotification 记录应用部署通知
+func (n *FakeNotifier) SendAppDeployNotification(ctx context.Context, batchID int64, appID int64, appName string, notifyType NotificationType, message string) error {
+	return n.Send(ctx, &NotificationMessage{
+		Type:    notifyType,
+		Content: message,
+		Extra: map[string]interface{}{
+			"batch_id": batchID,
+			"app_id":   appID,
+			"app_name": appName,
+		},
+	})
+}
@@ -0,0 +1,295 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"devops-cd/internal/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ============= Lark 话题通知适配器 =============
+
+const DefaultLarkAPIBase = "https://open.feishu.cn"
+
+// LarkAppConfig Lark 应用机器人配置（自定义机器人 Webhook 不支持话题回复，需使用应用机器人）
+type LarkAppConfig struct {
+	APIBase   string // 默认 https://open.feishu.cn，国际版为 https://open.larksuite.com
+	AppID     string
+	AppSecret string
+	ChatID    string // 通知群 chat_id
+}
+
+// ThreadStore 批次话题存储
+type ThreadStore interface {
+	// GetThread 返回批次话题根消息 ID，未创建时返回空
+	GetThread(ctx context.Context, batchID int64) (string, error)
+	// SaveThread 将话题根消息由 oldID 替换为 newID，返回最终生效的 ID（并发创建时以先写入者为准）
+	SaveThread(ctx context.Context, batchID int64, oldID, newID string) (string, error)
+}
+
+// LarkThreadNotifier 每个批次一个 Lark 话题：首条批次相关通知作为话题根消息发送，
+// 之后的批次/应用通知均以话题回复发送，根消息 ID 记录在 batch.lark_thread_id
+type LarkThreadNotifier struct {
+	cfg    LarkAppConfig
+	app    *larkApp
+	store  ThreadStore
+	logger *zap.Logger
+
+	// 串行化发送，避免同一批次并发创建多个话题
+	mu sync.Mutex
+}
+
+// NewLarkThreadNotifier 创建 Lark 话题通知器
+func NewLarkThreadNotifier(cfg LarkAppConfig, store ThreadStore, logger *zap.Logger) *LarkThreadNotifier {
+	app := newLarkApp(cfg)
+	return &LarkThreadNotifier{
+		cfg:    app.cfg,
+		app:    app,
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Send 发送通知：带 batch_id 的消息发送到批次话题，其余直接发送到群
+func (n *LarkThreadNotifier) Send(ctx context.Context, msg *NotificationMessage) error {
+	batchID, _ := msg.Extra["batch_id"].(int64)
+	if batchID == 0 {
+		_, err := n.sendToChat(ctx, msg)
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	rootID, err := n.store.GetThread(ctx, batchID)
+	if err != nil {
+		return fmt.Errorf("查询批次话题失败: %w", err)
+	}
+	if rootID != "" {
+		err = n.reply(ctx, rootID, msg)
+		if err == nil {
+			return nil
+		}
+		// 根消息被删除/撤回等：重新创建话题
+		n.logger.Warn("Lark 话题回复失败，重新创建话题", zap.Int64("batch_id", batchID), zap.String("root_id", rootID), zap.Error(err))
+	}
+
+	newID, err := n.sendToChat(ctx, n.rootMessage(msg))
+	if err != nil {
+		return err
+	}
+	if _, err := n.store.SaveThread(ctx, batchID, rootID, newID); err != nil {
+		n.logger.Warn("保存批次话题失败", zap.Int64("batch_id", batchID), zap.Error(err))
+	}
+	return nil
+}
+
+// SendBatchNotification 发送批次通知
+func (n *LarkThreadNotifier) SendBatchNotification(ctx context.Context, batch *model.Batch, notifyType NotificationType, message string) error {
+	return n.Send(ctx, BatchMessage(batch, notifyType, message))
+}
+
+// SendAppDeployNotification 发送应用部署通知（回复到批次话题）
+func (n *LarkThreadNotifier) SendAppDeployNotification(ctx context.Context, batchID int64, appID int64, appName string, notifyType NotificationType, message string) error {
+	return n.Send(ctx, AppDeployMessage(batchID, appID, appName, notifyType, message))
+}
+
+// rootMessage 话题根消息：在首条通知前加上批次标识，便于在群内识别话题
+func (n *LarkThreadNotifier) rootMessage(msg *NotificationMessage) *NotificationMessage {
+	root := *msg
+	if number, ok := msg.Extra["batch_number"].(string); ok && number != "" {
+		root.Title = fmt.Sprintf("📦 批次「%s」| %s", number, msg.Title)
+	} else {
+		root.Title = fmt.Sprintf("📦 批次 %v | %s", msg.Extra["batch_id"], msg.Title)
+	}
+	return &root
+}
+
+// sendToChat 发送到群，返回 message_id
+func (n *LarkThreadNotifier) sendToChat(ctx context.Context, msg *NotificationMessage) (string, error) {
+	content, err := json.Marshal(larkCard(msg))
+	if err != nil {
+		return "", fmt.Errorf("序列化消息失败: %w", err)
+	}
+	return n.app.call(ctx, "/open-apis/im/v1/messages?receive_id_type=chat_id", map[string]interface{}{
+		"receive_id": n.cfg.ChatID,
+		"msg_type":   "interactive",
+		"content":    string(content),
+	})
+}
+
+// reply 以话题形式回复根消息
+func (n *LarkThreadNotifier) reply(ctx context.Context, rootID string, msg *NotificationMessage) error {
+	content, err := json.Marshal(larkCard(msg))
+	if err != nil {
+		return fmt.Errorf("序列化消息失败: %w", err)
+	}
+	_, err = n.app.call(ctx, "/open-apis/im/v1/messages/"+rootID+"/reply", map[string]interface{}{
+		"msg_type":        "interactive",
+		"content":         string(content),
+		"reply_in_thread": true,
+	})
+	return err
+}
+
+// ============= Lark 应用机器人 OpenAPI =============
+
+// larkApp 应用机器人 OpenAPI 客户端，缓存 tenant_access_token
+type larkApp struct {
+	cfg    LarkAppConfig
+	client *http.Client
+
+	tokenMu     sync.Mutex
+	token       string
+	tokenExpire time.Time
+}
+
+func newLarkApp(cfg LarkAppConfig) *larkApp {
+	if cfg.APIBase == "" {
+		cfg.APIBase = DefaultLarkAPIBase
+	}
+	cfg.APIBase = strings.TrimRight(cfg.APIBase, "/")
+	return &larkApp{
+		cfg: cfg,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// call 调用消息接口，返回 data.message_id
+func (n *larkApp) call(ctx context.Context, path string, body interface{}) (string, error) {
+	token, err := n.tenantToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Data struct {
+			MessageID string `json:"message_id"`
+		} `json:"data"`
+	}
+	if err := n.post(ctx, path, token, body, &resp); err != nil {
+		return "", err
+	}
+	if resp.Code != 0 {
+		return "", fmt.Errorf("Lark API 返回错误: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	return resp.Data.MessageID, nil
+}
+
+// tenantToken 获取并缓存 tenant_access_token（提前 5 分钟刷新）
+func (n *larkApp) tenantToken(ctx context.Context) (string, error) {
+	n.tokenMu.Lock()
+	defer n.tokenMu.Unlock()
+
+	if n.token != "" && time.Now().Before(n.tokenExpire) {
+		return n.token, nil
+	}
+
+	var resp struct {
+		Code              int    `json:"code"`
+		Msg               string `json:"msg"`
+		TenantAccessToken string `json:"tenant_access_token"`
+		Expire            int    `json:"expire"`
+	}
+	if err := n.post(ctx, "/open-apis/auth/v3/tenant_access_token/internal", "", map[string]string{
+		"app_id":     n.cfg.AppID,
+		"app_secret": n.cfg.AppSecret,
+	}, &resp); err != nil {
+		return "", err
+	}
+	if resp.Code != 0 || resp.TenantAccessToken == "" {
+		return "", fmt.Errorf("获取 Lark tenant_access_token 失败: code=%d msg=%s", resp.Code, resp.Msg)
+	}
+	n.token = resp.TenantAccessToken
+	n.tokenExpire = time.Now().Add(time.Duration(resp.Expire)*time.Second - 5*time.Minute)
+	return n.token, nil
+}
+
+func (n *larkApp) post(ctx context.Context, path, token string, body, out interface{}) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("序列化请求失败: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.cfg.APIBase+path, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Lark 业务错误也可能返回非 200，优先解析 code/msg
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("Lark API返回错误状态码: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ============= 批次话题存储 =============
+
+// batchThreadStore 话题根消息 ID 保存在 release_batches.lark_thread_id
+type batchThreadStore struct {
+	db *gorm.DB
+}
+
+// NewBatchThreadStore 创建基于 batches 表的话题存储
+func NewBatchThreadStore(db *gorm.DB) ThreadStore {
+	return &batchThreadStore{db: db}
+}
+
+func (s *batchThreadStore) GetThread(ctx context.Context, batchID int64) (string, error) {
+	var b model.Batch
+	if err := s.db.WithContext(ctx).Select("id", "lark_thread_id").First(&b, batchID).Error; err != nil {
+		return "", err
+	}
+	if b.LarkThreadID == nil {
+		return "", nil
+	}
+	return *b.LarkThreadID, nil
+}
+
+func (s *batchThreadStore) SaveThread(ctx context.Context, batchID int64, oldID, newID string) (string, error) {
+	// 字段在模型中只读，按表名更新
+	q := s.db.WithContext(ctx).Table(model.BatchTableName).Where("id = ?", batchID)
+	if oldID == "" {
+		q = q.Where("lark_thread_id IS NULL OR lark_thread_id = ''")
+	} else {
+		q = q.Where("lark_thread_id = ?", oldID)
+	}
+	result := q.UpdateColumn("lark_thread_id", newID)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if result.RowsAffected > 0 {
+		return newID, nil
+	}
+	// 已被其他实例写入
+	current, err := s.GetThread(ctx, batchID)
+	if err != nil {
+		return "", err
+	}
+	if current == "" {
+		return "", errors.New("批次话题写入失败")
+	}
+	return current, nil
+}
@@ -23,9 +23,61 @@ const (
 	NotifyDeployFailed     NotificationType = "deploy_failed"      // 部署失败
 	NotifyAppDeploySuccess NotificationType = "app_deploy_success" // 应用部署成功
 	NotifyAppDeployFailed  NotificationType = "app_deploy_failed"  // 应用部署失败
+	NotifyAppAutoRollback  NotificationType = "app_auto_rollback"  // 应用集群自动回滚
+	NotifyReleaseDrift     NotificationType = "release_drift"      // 集群中 release 与最近一次部署不一致（配置漂移）
+	NotifyAppDecommission  NotificationType = "app_decommission"   // 应用下线（卸载 release 并删除应用）
+	NotifyDeploySlow       NotificationType = "deploy_slow"        // 部署运行时长超过历史耗时（可能卡住）
 	NotifyStateTransition  NotificationType = "state_transition"   // 状态转换
 )
 
+// AllTypes 全部通知类型（通知路由规则配置时供选择）
+var AllTypes = []NotificationType{
+	NotifyBatchStart,
+	NotifyBatchComplete,
+	NotifyBatchFailed,
+	NotifyDeployStart,
+	NotifyDeploySuccess,
+	NotifyDeployFailed,
+	NotifyAppDeploySuccess,
+	NotifyAppDeployFailed,
+	NotifyAppAutoRollback,
+	NotifyReleaseDrift,
+	NotifyAppDecommission,
+	NotifyDeploySlow,
+	NotifyStateTransition,
+}
+
+// 通知严重级别
+const (
+	SeverityInfo    = "info"
+	SeverityWarning = "warning"
+	SeverityError   = "error"
+)
+
+// Severities 严重级别（由低到高）
+var Severities = []string{SeverityInfo, SeverityWarning, SeverityError}
+
+// Severity 通知类型对应的严重级别：失败为 error，自动回滚、配置漂移、应用下线、部署耗时异常为 warning，其余为 info
+func (t NotificationType) Severity() string {
+	switch t {
+	case NotifyBatchFailed, NotifyDeployFailed, NotifyAppDeployFailed:
+		return SeverityError
+	case NotifyAppAutoRollback, NotifyReleaseDrift, NotifyAppDecommission, NotifyDeploySlow:
+		return SeverityWarning
+	}
+	return SeverityInfo
+}
+
+// SeverityRank 严重级别排序值，未知级别按 info 处理
+func SeverityRank(severity string) int {
+	for i, s := range Severities {
+		if s == severity {
+			return i
+		}
+	}
+	return 0
+}
+
 // NotificationMessage 通知消息
 type NotificationMessage struct {
 	Type      NotificationType       `json:"type"`
@@ -116,8 +168,17 @@ func (n *LarkNotifier) Send(ctx context.Context, msg *NotificationMessage) error
 
 // SendBatchNotification 发送批次通知
 func (n *LarkNotifier) SendBatchNotification(ctx context.Context, batch *model.Batch, notifyType NotificationType, message string) error {
-	var title, content string
-	var color string
+	return n.Send(ctx, BatchMessage(batch, notifyType, message))
+}
+
+// SendAppDeployNotification 发送应用部署通知
+func (n *LarkNotifier) SendAppDeployNotification(ctx context.Context, batchID int64, appID int64, appName string, notifyType NotificationType, message string) error {
+	return n.Send(ctx, AppDeployMessage(batchID, appID, appName, notifyType, message))
+}
+
+// BatchMessage 构建批次通知消息
+func BatchMessage(batch *model.Batch, notifyType NotificationType, message string) *NotificationMessage {
+	var title, color string
 
 	switch notifyType {
 	case NotifyBatchStart:
@@ -137,10 +198,10 @@ func (n *LarkNotifier) SendBatchNotification(ctx context.Context, batch *model.B
 		color = "grey"
 	}
 
-	content = fmt.Sprintf("**批次编号**: %s\n**发起人**: %s\n**消息**: %s",
+	content := fmt.Sprintf("**批次编号**: %s\n**发起人**: %s\n**消息**: %s",
 		batch.BatchNumber, batch.Initiator, message)
 
-	msg := &NotificationMessage{
+	return &NotificationMessage{
 		Type:      notifyType,
 		Title:     title,
 		Content:   content,
@@ -148,17 +209,17 @@ func (n *LarkNotifier) SendBatchNotification(ctx context.Context, batch *model.B
 		Extra: map[string]interface{}{
 			"batch_id":     batch.ID,
 			"batch_number": batch.BatchNumber,
+			"project_id":   batch.ProjectID,
+			"initiator":    batch.Initiator,
+			"message":      message,
 			"color":        color,
 		},
 	}
-
-	return n.Send(ctx, msg)
 }
 
-// SendAppDeployNotification 发送应用部署通知
-func (n *LarkNotifier) SendAppDeployNotification(ctx context.Context, batchID int64, appID int64, appName string, notifyType NotificationType, message string) error {
-	var title string
-	var color string
+// AppDeployMessage 构建应用部署通知消息
+func AppDeployMessage(batchID int64, appID int64, appName string, notifyType NotificationType, message string) *NotificationMessage {
+	var title, color string
 
 	switch notifyType {
 	case NotifyAppDeploySuccess:
@@ -167,15 +228,31 @@ func (n *LarkNotifier) SendAppDeployNotification(ctx context.Context, batchID in
 	case NotifyAppDeployFailed:
 		title = "❌ 应用部署失败"
 		color = "red"
+	case NotifyAppAutoRollback:
+		title = "⏪ 应用自动回滚"
+		color = "orange"
+	case NotifyReleaseDrift:
+		title = "⚠️ 配置漂移"
+		color = "orange"
+	case NotifyAppDecommission:
+		title = "🗑️ 应用下线"
+		color = "orange"
+	case NotifyDeploySlow:
+		title = "🐢 部署耗时异常"
+		color = "orange"
 	default:
 		title = "📢 应用部署通知"
 		color = "grey"
 	}
 
+	// 临时部署不属于批次（batchID 为 0），不展示批次ID
 	content := fmt.Sprintf("**应用**: %s (ID: %d)\n**批次ID**: %d\n**消息**: %s",
 		appName, appID, batchID, message)
+	if batchID == 0 {
+		content = fmt.Sprintf("**应用**: %s (ID: %d)\n**消息**: %s", appName, appID, message)
+	}
 
-	msg := &NotificationMessage{
+	return &NotificationMessage{
 		Type:      notifyType,
 		Title:     title,
 		Content:   content,
@@ -184,15 +261,22 @@ func (n *LarkNotifier) SendAppDeployNotification(ctx context.Context, batchID in
 			"batch_id": batchID,
 			"app_id":   appID,
 			"app_name": appName,
+			"message":  message,
 			"color":    color,
 		},
 	}
-
-	return n.Send(ctx, msg)
 }
 
 // buildLarkMessage 构建Lark消息格式
 func (n *LarkNotifier) buildLarkMessage(msg *NotificationMessage) map[string]interface{} {
+	return map[string]interface{}{
+		"msg_type": "interactive",
+		"card":     larkCard(msg),
+	}
+}
+
+// larkCard Lark 消息卡片
+func larkCard(msg *NotificationMessage) map[string]interface{} {
 	color := "grey"
 	if c, ok := msg.Extra["color"].(string); ok {
 		color = c
@@ -200,29 +284,26 @@ func (n *LarkNotifier) buildLarkMessage(msg *NotificationMessage) map[string]int
 
 	// Lark富文本消息格式
 	return map[string]interface{}{
-		"msg_type": "interactive",
-		"card": map[string]interface{}{
-			"header": map[string]interface{}{
-				"title": map[string]interface{}{
-					"tag":     "plain_text",
-					"content": msg.Title,
-				},
-				"template": color,
+		"header": map[string]interface{}{
+			"title": map[string]interface{}{
+				"tag":     "plain_text",
+				"content": msg.Title,
 			},
-			"elements": []interface{}{
-				map[string]interface{}{
-					"tag": "div",
-					"text": map[string]interface{}{
-						"tag":     "lark_md",
-						"content": msg.Content,
-					},
+			"template": color,
+		},
+		"elements": []interface{}{
+			map[string]interface{}{
+				"tag": "div",
+				"text": map[string]interface{}{
+					"tag":     "lark_md",
+					"content": msg.Content,
 				},
-				map[string]interface{}{
-					"tag": "div",
-					"text": map[string]interface{}{
-						"tag":     "plain_text",
-						"content": fmt.Sprintf("时间: %s", msg.Timestamp.Format("2006-01-02 15:04:05")),
-					},
+			},
+			map[string]interface{}{
+				"tag": "div",
+				"text": map[string]interface{}{
+					"tag":     "plain_text",
+					"content": fmt.Sprintf("时间: %s", msg.Timestamp.Format("2006-01-02 15:04:05")),
 				},
 			},
 		},
@@ -0,0 +1,295 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"devops-cd/internal/model"
+	"fmt"
+	"text/template"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ============= 通知路由规则 =============
+
+// Scope 通知所属的项目/团队，由 RuleStore 按消息中的 batch_id / app_id 解析
+type Scope struct {
+	ProjectID   int64
+	TeamID      *int64
+	TeamName    string
+	LeaderName  string // 团队负责人（users.username）
+	LeaderEmail string
+}
+
+// RuleStore 通知路由规则存储
+type RuleStore interface {
+	// ListRules 返回启用的规则
+	ListRules(ctx context.Context) ([]*model.NotificationRule, error)
+	// Scope 解析消息所属的项目/团队
+	Scope(ctx context.Context, msg *NotificationMessage) (*Scope, error)
+}
+
+// TemplateData 规则模板可用的字段，如 {{.AppName}}、{{.Message}}、{{index .Extra "color"}}
+type TemplateData struct {
+	Type        string
+	Severity    string
+	Title       string // 默认标题
+	Content     string // 默认正文
+	Message     string // 状态说明 / 失败原因
+	Timestamp   time.Time
+	ProjectID   int64
+	BatchID     int64
+	BatchNumber string
+	Initiator   string
+	AppID       int64
+	AppName     string
+	TeamName    string
+	Leader      string
+	Extra       map[string]interface{}
+}
+
+// RuleNotifier 按通知路由规则发送：匹配到规则时发送到规则的 Webhook，未匹配任何规则时交给默认通知器
+//
+//   - 规则按项目、团队（仅应用级通知）、通知类型、最低严重级别匹配，可同时命中多条
+//   - 免打扰时段内只发送 error 级别通知（仍视为已匹配，不回落到默认通知器）
+//   - mention_leader：error 级别的应用通知 @ 应用所属团队负责人
+type RuleNotifier struct {
+	base   Notifier
+	store  RuleStore
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewRuleNotifier 创建规则通知器
+func NewRuleNotifier(base Notifier, store RuleStore, logger *zap.Logger) *RuleNotifier {
+	return &RuleNotifier{base: base, store: store, logger: logger, now: time.Now}
+}
+
+// Send 按规则发送通知
+func (n *RuleNotifier) Send(ctx context.Context, msg *NotificationMessage) error {
+	rules, err := n.store.ListRules(ctx)
+	if err != nil {
+		n.logger.Warn("查询通知路由规则失败，使用默认通知", zap.Error(err))
+		return n.base.Send(ctx, msg)
+	}
+	if len(rules) == 0 {
+		return n.base.Send(ctx, msg)
+	}
+
+	scope, err := n.store.Scope(ctx, msg)
+	if err != nil {
+		n.logger.Warn("解析通知所属项目失败，使用默认通知", zap.String("type", string(msg.Type)), zap.Error(err))
+		return n.base.Send(ctx, msg)
+	}
+
+	severity := msg.Type.Severity()
+	matched := make([]*model.NotificationRule, 0, len(rules))
+	for _, rule := range rules {
+		if matchRule(rule, msg, scope, severity) {
+			matched = append(matched, rule)
+		}
+	}
+	if len(matched) == 0 {
+		return n.base.Send(ctx, msg)
+	}
+
+	var lastErr error
+	for _, rule := range matched {
+		if severity != SeverityError && rule.QuietHours.Contains(n.now()) {
+			n.logger.Debug("免打扰时段，跳过通知", zap.Int64("rule_id", rule.ID), zap.String("type", string(msg.Type)))
+			continue
+		}
+		out := n.render(rule, msg, scope, severity)
+		if err := NewLarkNotifier(rule.Webhook, true, n.logger).Send(ctx, out); err != nil {
+			n.logger.Error("按规则发送通知失败", zap.Int64("rule_id", rule.ID), zap.String("rule", rule.Name), zap.Error(err))
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// SendBatchNotification 发送批次通知
+func (n *RuleNotifier) SendBatchNotification(ctx context.Context, batch *model.Batch, notifyType NotificationType, message string) error {
+	return n.Send(ctx, BatchMessage(batch, notifyType, message))
+}
+
+// SendAppDeployNotification 发送应用部署通知
+func (n *RuleNotifier) SendAppDeployNotification(ctx context.Context, batchID int64, appID int64, appName string, notifyType NotificationType, message string) error {
+	return n.Send(ctx, AppDeployMessage(batchID, appID, appName, notifyType, message))
+}
+
+func matchRule(rule *model.NotificationRule, msg *NotificationMessage, scope *Scope, severity string) bool {
+	if !rule.Subscribed(string(msg.Type)) || SeverityRank(severity) < SeverityRank(rule.MinSeverity) {
+		return false
+	}
+	if rule.ProjectID != nil && *rule.ProjectID != scope.ProjectID {
+		return false
+	}
+	if rule.TeamID != nil && (scope.TeamID == nil || *rule.TeamID != *scope.TeamID) {
+		return false
+	}
+	return true
+}
+
+// render 按规则模板渲染消息，模板渲染失败时使用默认标题/正文
+func (n *RuleNotifier) render(rule *model.NotificationRule, msg *NotificationMessage, scope *Scope, severity string) *NotificationMessage {
+	out := *msg
+	data := newTemplateData(msg, scope, severity)
+	if rule.TitleTemplate != "" {
+		if title, err := RenderTemplate(rule.TitleTemplate, data); err != nil {
+			n.logger.Warn("渲染通知标题模板失败", zap.Int64("rule_id", rule.ID), zap.Error(err))
+		} else {
+			out.Title = title
+		}
+	}
+	if rule.ContentTemplate != "" {
+		if content, err := RenderTemplate(rule.ContentTemplate, data); err != nil {
+			n.logger.Warn("渲染通知正文模板失败", zap.Int64("rule_id", rule.ID), zap.Error(err))
+		} else {
+			out.Content = content
+		}
+	}
+	if rule.MentionLeader && severity == SeverityError && scope.LeaderName != "" {
+		out.Content += "\n" + mention(scope)
+	}
+	return &out
+}
+
+func newTemplateData(msg *NotificationMessage, scope *Scope, severity string) *TemplateData {
+	data := &TemplateData{
+		Type:      string(msg.Type),
+		Severity:  severity,
+		Title:     msg.Title,
+		Content:   msg.Content,
+		Timestamp: msg.Timestamp,
+		ProjectID: scope.ProjectID,
+		TeamName:  scope.TeamName,
+		Leader:    scope.LeaderName,
+		Extra:     msg.Extra,
+	}
+	data.Message, _ = msg.Extra["message"].(string)
+	data.BatchID, _ = msg.Extra["batch_id"].(int64)
+	data.BatchNumber, _ = msg.Extra["batch_number"].(string)
+	data.Initiator, _ = msg.Extra["initiator"].(string)
+	data.AppID, _ = msg.Extra["app_id"].(int64)
+	data.AppName, _ = msg.Extra["app_name"].(string)
+	return data
+}
+
+// RenderTemplate 渲染 Go text/template 模板
+func RenderTemplate(text string, data interface{}) (string, error) {
+	tpl, err := ParseTemplate(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// ParseTemplate 解析模板（保存规则时校验语法）
+func ParseTemplate(text string) (*template.Template, error) {
+	return template.New("notification").Option("missingkey=zero").Parse(text)
+}
+
+// SampleTemplateData 示例数据（保存规则时试渲染模板）
+func SampleTemplateData() *TemplateData {
+	return &TemplateData{
+		Type:        string(NotifyAppDeployFailed),
+		Severity:    SeverityError,
+		Title:       "❌ 应用部署失败",
+		Content:     "**应用**: demo (ID: 1)\n**批次ID**: 1\n**消息**: [prod] v1.0.0",
+		Message:     "[prod] v1.0.0",
+		Timestamp:   time.Now(),
+		ProjectID:   1,
+		BatchID:     1,
+		BatchNumber: "R20240101-1",
+		Initiator:   "admin",
+		AppID:       1,
+		AppName:     "demo",
+		TeamName:    "demo-team",
+		Leader:      "admin",
+		Extra:       map[string]interface{}{},
+	}
+}
+
+// mention Lark 卡片 @ 用户：有邮箱时使用 <at email=...>，否则以文本提示
+func mention(scope *Scope) string {
+	if scope.LeaderEmail != "" {
+		return fmt.Sprintf("<at email=%s></at>", scope.LeaderEmail)
+	}
+	return "@" + scope.LeaderName
+}
+
+// ============= 通知路由规则存储 =============
+
+// dbRuleStore 规则保存在 notification_rules，项目/团队按 release_batches / applications 解析
+type dbRuleStore struct {
+	db *gorm.DB
+}
+
+// NewDBRuleStore 创建基于数据库的规则存储
+func NewDBRuleStore(db *gorm.DB) RuleStore {
+	return &dbRuleStore{db: db}
+}
+
+func (s *dbRuleStore) ListRules(ctx context.Context) ([]*model.NotificationRule, error) {
+	var rules []*model.NotificationRule
+	if err := s.db.WithContext(ctx).Where("enabled = ?", true).Order("id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (s *dbRuleStore) Scope(ctx context.Context, msg *NotificationMessage) (*Scope, error) {
+	scope := &Scope{}
+	if appID, _ := msg.Extra["app_id"].(int64); appID != 0 {
+		// 含已删除的应用（应用下线完成的通知）
+		var app model.Application
+		if err := s.db.WithContext(ctx).Unscoped().Select("id", "project_id", "repo_id", "team_id").
+			Preload("Team").Preload("Repository.Team").First(&app, appID).Error; err != nil {
+			return nil, fmt.Errorf("查询应用失败: %w", err)
+		}
+		scope.ProjectID = app.ProjectID
+		// 应用未设置团队时取代码库团队
+		team := app.Team
+		if team == nil && app.Repository != nil {
+			team = app.Repository.Team
+		}
+		if team != nil {
+			scope.TeamID = &team.ID
+			scope.TeamName = team.Name
+			if team.LeaderName != nil {
+				scope.LeaderName = *team.LeaderName
+				scope.LeaderEmail = s.leaderEmail(ctx, scope.LeaderName)
+			}
+		}
+		return scope, nil
+	}
+
+	if projectID, _ := msg.Extra["project_id"].(int64); projectID != 0 {
+		scope.ProjectID = projectID
+		return scope, nil
+	}
+	if batchID, _ := msg.Extra["batch_id"].(int64); batchID != 0 {
+		var batch model.Batch
+		if err := s.db.WithContext(ctx).Select("id", "project_id").First(&batch, batchID).Error; err != nil {
+			return nil, fmt.Errorf("查询批次失败: %w", err)
+		}
+		scope.ProjectID = batch.ProjectID
+	}
+	return scope, nil
+}
+
+func (s *dbRuleStore) leaderEmail(ctx context.Context, username string) string {
+	var user model.User
+	if err := s.db.WithContext(ctx).Select("id", "email").Where("username = ?", username).
+		Limit(1).Find(&user).Error; err != nil || user.Email == nil {
+		return ""
+	}
+	return *user.Email
+}
@@ -0,0 +1,263 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"devops-cd/internal/model"
+)
+
+type stubRuleStore struct {
+	rules    []*model.NotificationRule
+	scope    *Scope
+	rulesErr error
+	scopeErr error
+}
+
+func (s *stubRuleStore) ListRules(context.Context) ([]*model.NotificationRule, error) {
+	return s.rules, s.rulesErr
+}
+
+func (s *stubRuleStore) Scope(context.Context, *NotificationMessage) (*Scope, error) {
+	return s.scope, s.scopeErr
+}
+
+// recordNotifier 记录默认通知器收到的消息
+type recordNotifier struct {
+	msgs []*NotificationMessage
+}
+
+func (n *recordNotifier) Send(_ context.Context, msg *NotificationMessage) error {
+	n.msgs = append(n.msgs, msg)
+	return nil
+}
+
+func (n *recordNotifier) SendBatchNotification(ctx context.Context, batch *model.Batch, notifyType NotificationType, message string) error {
+	return n.Send(ctx, BatchMessage(batch, notifyType, message))
+}
+
+func (n *recordNotifier) SendAppDeployNotification(ctx context.Context, batchID int64, appID int64, appName string, notifyType NotificationType, message string) error {
+	return n.Send(ctx, AppDeployMessage(batchID, appID, appName, notifyType, message))
+}
+
+// larkCapture 记录各规则 Webhook（按路径区分）收到的卡片标题与正文
+type larkCapture struct {
+	mu    sync.Mutex
+	cards map[string][2]string
+}
+
+func (c *larkCapture) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Card struct {
+			Header struct {
+				Title struct {
+					Content string `json:"content"`
+				} `json:"title"`
+			} `json:"header"`
+			Elements []struct {
+				Text struct {
+					Content string `json:"content"`
+				} `json:"text"`
+			} `json:"elements"`
+		} `json:"card"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cards[r.URL.Path] = [2]string{body.Card.Header.Title.Content, body.Card.Elements[0].Text.Content}
+}
+
+func (c *larkCapture) paths() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	paths := make([]string, 0, len(c.cards))
+	for p := range c.cards {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func int64Ptr(v int64) *int64 { return &v }
+
+func TestRuleNotifierSend(t *testing.T) {
+	capture := &larkCapture{}
+	srv := httptest.NewServer(capture)
+	defer srv.Close()
+
+	// 2026-01-02 23:30 UTC
+	now := time.Date(2026, 1, 2, 23, 30, 0, 0, time.UTC)
+	night := &model.QuietHours{Start: "22:00", End: "08:00", Timezone: "UTC"}
+	appScope := &Scope{ProjectID: 1, TeamID: int64Ptr(10), TeamName: "payments", LeaderName: "alice", LeaderEmail: "alice@example.com"}
+	batchScope := &Scope{ProjectID: 1}
+	appFailed := AppDeployMessage(7, 3, "user-api", NotifyAppDeployFailed, "[prod] v1.2.0")
+	appSuccess := AppDeployMessage(7, 3, "user-api", NotifyAppDeploySuccess, "[prod] v1.2.0")
+	batchStart := BatchMessage(&model.Batch{BaseModel: model.BaseModel{ID: 7}, ProjectID: 1, BatchNumber: "R1"}, NotifyBatchStart, "")
+
+	rule := func(name string, mutate func(r *model.NotificationRule)) *model.NotificationRule {
+		r := &model.NotificationRule{Name: name, Events: model.StringList{"*"}, MinSeverity: SeverityInfo, Webhook: srv.URL + "/" + name}
+		if mutate != nil {
+			mutate(r)
+		}
+		return r
+	}
+
+	cases := []struct {
+		name      string
+		store     *stubRuleStore
+		msg       *NotificationMessage
+		wantBase  bool
+		wantHooks []string
+	}{
+		{name: "无规则", store: &stubRuleStore{scope: appScope}, msg: appFailed, wantBase: true},
+		{name: "查询规则失败", store: &stubRuleStore{rules: []*model.NotificationRule{rule("r1", nil)}, rulesErr: errors.New("db down")}, msg: appFailed, wantBase: true},
+		{name: "解析项目失败", store: &stubRuleStore{rules: []*model.NotificationRule{rule("r1", nil)}, scopeErr: errors.New("not found")}, msg: appFailed, wantBase: true},
+		{name: "匹配全部", store: &stubRuleStore{rules: []*model.NotificationRule{rule("r1", nil)}, scope: appScope}, msg: appFailed, wantHooks: []string{"/r1"}},
+		{
+			name:      "按项目匹配",
+			store:     &stubRuleStore{rules: []*model.NotificationRule{rule("p1", func(r *model.NotificationRule) { r.ProjectID = int64Ptr(1) }), rule("p2", func(r *model.NotificationRule) { r.ProjectID = int64Ptr(2) })}, scope: appScope},
+			msg:       appFailed,
+			wantHooks: []string{"/p1"},
+		},
+		{
+			name:     "团队规则不匹配批次通知",
+			store:    &stubRuleStore{rules: []*model.NotificationRule{rule("t10", func(r *model.NotificationRule) { r.TeamID = int64Ptr(10) })}, scope: batchScope},
+			msg:      batchStart,
+			wantBase: true,
+		},
+		{
+			name:      "按团队匹配",
+			store:     &stubRuleStore{rules: []*model.NotificationRule{rule("t10", func(r *model.NotificationRule) { r.TeamID = int64Ptr(10) }), rule("t11", func(r *model.NotificationRule) { r.TeamID = int64Ptr(11) })}, scope: appScope},
+			msg:       appSuccess,
+			wantHooks: []string{"/t10"},
+		},
+		{
+			name:     "低于最低严重级别",
+			store:    &stubRuleStore{rules: []*model.NotificationRule{rule("w", func(r *model.NotificationRule) { r.MinSeverity = SeverityWarning })}, scope: appScope},
+			msg:      appSuccess,
+			wantBase: true,
+		},
+		{
+			name:     "未订阅的类型",
+			store:    &stubRuleStore{rules: []*model.NotificationRule{rule("e", func(r *model.NotificationRule) { r.Events = model.StringList{string(NotifyBatchFailed)} })}, scope: appScope},
+			msg:      appFailed,
+			wantBase: true,
+		},
+		{
+			// 免打扰时段内跳过 info 通知，但已匹配规则，不回落到默认通知器
+			name:  "免打扰时段跳过 info",
+			store: &stubRuleStore{rules: []*model.NotificationRule{rule("q", func(r *model.NotificationRule) { r.QuietHours = night })}, scope: appScope},
+			msg:   appSuccess,
+		},
+		{
+			name:      "免打扰时段仍发送 error",
+			store:     &stubRuleStore{rules: []*model.NotificationRule{rule("q", func(r *model.NotificationRule) { r.QuietHours = night })}, scope: appScope},
+			msg:       appFailed,
+			wantHooks: []string{"/q"},
+		},
+		{
+			name: "同时命中多条规则",
+			store: &stubRuleStore{rules: []*model.NotificationRule{
+				rule("a", nil),
+				rule("b", func(r *model.NotificationRule) { r.MinSeverity = SeverityError }),
+				rule("c", func(r *model.NotificationRule) { r.QuietHours = night }),
+			}, scope: appScope},
+			msg:       appSuccess,
+			wantHooks: []string{"/a"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			capture.cards = map[string][2]string{}
+			base := &recordNotifier{}
+			n := NewRuleNotifier(base, c.store, zap.NewNop())
+			n.now = func() time.Time { return now }
+
+			if err := n.Send(context.Background(), c.msg); err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+			if got := len(base.msgs) == 1; got != c.wantBase {
+				t.Errorf("默认通知器收到 %d 条, want base=%v", len(base.msgs), c.wantBase)
+			}
+			if got := capture.paths(); strings.Join(got, ",") != strings.Join(c.wantHooks, ",") {
+				t.Errorf("规则 Webhook = %v, want %v", got, c.wantHooks)
+			}
+		})
+	}
+}
+
+func TestRuleNotifierRender(t *testing.T) {
+	capture := &larkCapture{cards: map[string][2]string{}}
+	srv := httptest.NewServer(capture)
+	defer srv.Close()
+
+	scope := &Scope{ProjectID: 1, TeamID: int64Ptr(10), TeamName: "payments", LeaderName: "alice", LeaderEmail: "alice@example.com"}
+	cases := []struct {
+		name        string
+		rule        *model.NotificationRule
+		msg         *NotificationMessage
+		wantTitle   string
+		wantContent []string
+		notContent  []string
+	}{
+		{
+			name: "模板与 @ 负责人",
+			rule: &model.NotificationRule{
+				TitleTemplate:   "[{{.Severity}}] {{.AppName}} 部署失败",
+				ContentTemplate: "团队 {{.TeamName}}，批次 {{.BatchID}}：{{.Message}}",
+				MentionLeader:   true,
+			},
+			msg:         AppDeployMessage(7, 3, "user-api", NotifyAppDeployFailed, "镜像拉取失败"),
+			wantTitle:   "[error] user-api 部署失败",
+			wantContent: []string{"团队 payments，批次 7：镜像拉取失败", "<at email=alice@example.com></at>"},
+		},
+		{
+			name:        "非 error 不 @ 负责人",
+			rule:        &model.NotificationRule{MentionLeader: true},
+			msg:         AppDeployMessage(7, 3, "user-api", NotifyAppDeploySuccess, "ok"),
+			wantTitle:   "✅ 应用部署成功",
+			wantContent: []string{"user-api"},
+			notContent:  []string{"<at"},
+		},
+		{
+			name:        "模板语法错误时使用默认标题",
+			rule:        &model.NotificationRule{TitleTemplate: "{{.AppName"},
+			msg:         AppDeployMessage(7, 3, "user-api", NotifyAppDeployFailed, "x"),
+			wantTitle:   "❌ 应用部署失败",
+			wantContent: []string{"user-api"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.rule.Name, c.rule.Events, c.rule.MinSeverity, c.rule.Webhook = "r", model.StringList{"*"}, SeverityInfo, srv.URL+"/r"
+			n := NewRuleNotifier(&recordNotifier{}, &stubRuleStore{rules: []*model.NotificationRule{c.rule}, scope: scope}, zap.NewNop())
+			if err := n.Send(context.Background(), c.msg); err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+			card := capture.cards["/r"]
+			if card[0] != c.wantTitle {
+				t.Errorf("title = %q, want %q", card[0], c.wantTitle)
+			}
+			for _, s := range c.wantContent {
+				if !strings.Contains(card[1], s) {
+					t.Errorf("content = %q, want 包含 %q", card[1], s)
+				}
+			}
+			for _, s := range c.notContent {
+				if strings.Contains(card[1], s) {
+					t.Errorf("content = %q, 不应包含 %q", card[1], s)
+				}
+			}
+		})
+	}
+}
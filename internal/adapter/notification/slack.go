@@ -0,0 +1,134 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"devops-cd/internal/model"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ============= Slack 通知适配器 =============
+
+// 卡片颜色 → Slack attachment 色条
+var slackColors = map[string]string{
+	"blue":   "#3370FF",
+	"green":  "#2EA121",
+	"red":    "#F54A45",
+	"orange": "#FF8800",
+	"grey":   "#8F959E",
+}
+
+// larkBold Lark/DingTalk 的 **粗体**，Slack mrkdwn 为 *粗体*
+var larkBold = regexp.MustCompile(`\*\*([^*\n]+)\*\*`)
+
+// SlackNotifier Slack Incoming Webhook 通知器（Block Kit）
+type SlackNotifier struct {
+	webhookURL string
+	logger     *zap.Logger
+	client     *http.Client
+}
+
+// NewSlackNotifier 创建 Slack 通知器
+func NewSlackNotifier(webhookURL string, logger *zap.Logger) *SlackNotifier {
+	return &SlackNotifier{
+		webhookURL: webhookURL,
+		logger:     logger,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// Send 发送通知
+func (n *SlackNotifier) Send(ctx context.Context, msg *NotificationMessage) error {
+	jsonData, err := json.Marshal(slackMessage(msg))
+	if err != nil {
+		return fmt.Errorf("序列化消息失败: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 成功时返回 200 "ok"，失败时返回 4xx 与错误说明（如 invalid_blocks）
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("Slack API返回错误状态码: %d %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	n.logger.Info("Slack通知发送成功",
+		zap.String("type", string(msg.Type)),
+		zap.String("title", msg.Title))
+	return nil
+}
+
+// SendBatchNotification 发送批次通知
+func (n *SlackNotifier) SendBatchNotification(ctx context.Context, batch *model.Batch, notifyType NotificationType, message string) error {
+	return n.Send(ctx, BatchMessage(batch, notifyType, message))
+}
+
+// SendAppDeployNotification 发送应用部署通知
+func (n *SlackNotifier) SendAppDeployNotification(ctx context.Context, batchID int64, appID int64, appName string, notifyType NotificationType, message string) error {
+	return n.Send(ctx, AppDeployMessage(batchID, appID, appName, notifyType, message))
+}
+
+// slackMessage Block Kit 消息：标题 + 正文 + 时间，放在带色条的 attachment 中
+func slackMessage(msg *NotificationMessage) map[string]interface{} {
+	color := slackColors["grey"]
+	if c, ok := msg.Extra["color"].(string); ok && slackColors[c] != "" {
+		color = slackColors[c]
+	}
+	return map[string]interface{}{
+		// 通知栏预览文本
+		"text": msg.Title,
+		"attachments": []interface{}{
+			map[string]interface{}{
+				"color": color,
+				"blocks": []interface{}{
+					map[string]interface{}{
+						"type": "header",
+						"text": map[string]interface{}{"type": "plain_text", "text": msg.Title, "emoji": true},
+					},
+					map[string]interface{}{
+						"type": "section",
+						"text": map[string]interface{}{"type": "mrkdwn", "text": slackMarkdown(msg.Content)},
+					},
+					map[string]interface{}{
+						"type": "context",
+						"elements": []interface{}{
+							map[string]interface{}{
+								"type": "plain_text",
+								"text": fmt.Sprintf("时间: %s", msg.Timestamp.Format("2006-01-02 15:04:05")),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// slackMarkdown 将通知正文（Lark markdown）转换为 Slack mrkdwn，section 文本上限 3000 字符
+func slackMarkdown(content string) string {
+	text := larkBold.ReplaceAllString(content, "*$1*")
+	if r := []rune(text); len(r) > 3000 {
+		text = string(r[:2990]) + "\n…"
+	}
+	return text
+}
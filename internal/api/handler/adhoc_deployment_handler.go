@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"net/http"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
+	"devops-cd/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdhocDeploymentHandler 临时部署（批次外手动部署）处理器
+type AdhocDeploymentHandler struct {
+	svc *service.AdhocDeploymentService
+}
+
+// NewAdhocDeploymentHandler 创建临时部署处理器
+func NewAdhocDeploymentHandler(svc *service.AdhocDeploymentService) *AdhocDeploymentHandler {
+	return &AdhocDeploymentHandler{svc: svc}
+}
+
+// Create 发起临时部署
+// @Summary 发起临时部署
+// @Description 不组建批次，将应用的指定构建部署到 pre/prod 的指定集群（紧急修复）；prod 需要 prod_operator 角色，pre 需要 batch:action 权限
+// @Tags 临时部署
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateAdhocDeploymentRequest true "临时部署请求"
+// @Success 200 {object} responses.Response{data=dto.AdhocDeploymentResponse}
+// @Security BearerAuth
+// @Router /api/v1/adhoc_deployments [post]
+func (h *AdhocDeploymentHandler) Create(c *gin.Context, canAccess func(username string, projectId int64, env string) bool) {
+	var req dto.CreateAdhocDeploymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	username := c.GetString("username")
+	resp, err := h.svc.Create(&req, username, func(projectID int64, env string) bool {
+		return canAccess(username, projectID, env)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// Get 临时部署详情
+// @Summary 临时部署详情（含各集群 Deployment）
+// @Tags 临时部署
+// @Produce json
+// @Param id path int true "临时部署ID"
+// @Success 200 {object} responses.Response{data=dto.AdhocDeploymentResponse}
+// @Security BearerAuth
+// @Router /api/v1/adhoc_deployments/{id} [get]
+func (h *AdhocDeploymentHandler) Get(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	username := c.GetString("username")
+	resp, err := h.svc.Get(id, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// List 临时部署列表
+// @Summary 项目的临时部署列表
+// @Tags 临时部署
+// @Produce json
+// @Param project_id query int true "项目ID"
+// @Param app_id query int false "应用ID"
+// @Param env query string false "环境 pre/prod"
+// @Param status query string false "状态 running/success/failed"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PageResponse
+// @Security BearerAuth
+// @Router /api/v1/adhoc_deployments [get]
+func (h *AdhocDeploymentHandler) List(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	var req dto.AdhocDeploymentListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+	username := c.GetString("username")
+	list, total, err := h.svc.List(&req, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, dto.NewPageResponse(list, total, req.Page, req.PageSize))
+}
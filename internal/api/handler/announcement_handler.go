@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"devops-cd/internal/dto"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
+	"devops-cd/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnnouncementHandler struct {
+	svc service.AnnouncementService
+}
+
+func NewAnnouncementHandler(svc service.AnnouncementService) *AnnouncementHandler {
+	return &AnnouncementHandler{svc: svc}
+}
+
+// Active 当前生效的公告
+// @Summary 当前生效的公告（UI banner）
+// @Tags Announcement
+// @Produce json
+// @Success 200 {object} responses.Response{data=[]dto.AnnouncementResponse}
+// @Router /api/v1/announcements [get]
+func (h *AnnouncementHandler) Active(c *gin.Context) {
+	list, err := h.svc.ListActive()
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, list)
+}
+
+// Create 创建公告
+// @Summary 创建公告
+// @Tags Announcement
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateAnnouncementRequest true "创建公告请求"
+// @Success 200 {object} responses.Response{data=dto.AnnouncementResponse}
+// @Router /api/v1/admin/announcements [post]
+func (h *AnnouncementHandler) Create(c *gin.Context) {
+	var req dto.CreateAnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	resp, err := h.svc.Create(&req, c.GetString("username"))
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// List 公告列表
+// @Summary 公告列表（管理端，含未生效/已过期）
+// @Tags Announcement
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PageResponse
+// @Router /api/v1/admin/announcements [get]
+func (h *AnnouncementHandler) List(c *gin.Context) {
+	var req dto.AnnouncementListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+	list, total, err := h.svc.List(&req)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, dto.NewPageResponse(list, total, req.Page, req.PageSize))
+}
+
+// Update 更新公告
+// @Summary 更新公告
+// @Tags Announcement
+// @Accept json
+// @Produce json
+// @Param id path int true "公告ID"
+// @Param request body dto.UpdateAnnouncementRequest true "更新公告请求"
+// @Success 200 {object} responses.Response{data=dto.AnnouncementResponse}
+// @Router /api/v1/admin/announcements/{id} [put]
+func (h *AnnouncementHandler) Update(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	var req dto.UpdateAnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	resp, err := h.svc.Update(id, &req, c.GetString("username"))
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// Delete 删除公告
+// @Summary 删除公告
+// @Tags Announcement
+// @Produce json
+// @Param id path int true "公告ID"
+// @Success 200 {object} responses.Response
+// @Router /api/v1/admin/announcements/{id} [delete]
+func (h *AnnouncementHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	if err := h.svc.Delete(id); err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, nil)
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"devops-cd/internal/dto"
+	"devops-cd/internal/pkg/auth"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
+	"devops-cd/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APITokenHandler struct {
+	svc service.APITokenService
+}
+
+func NewAPITokenHandler(svc service.APITokenService) *APITokenHandler {
+	return &APITokenHandler{svc: svc}
+}
+
+// Scopes 可授予 API Token 的权限
+// @Summary API Token 可选 scope（只读权限）
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} responses.Response{data=[]string}
+// @Router /api/v1/admin/api_tokens/scopes [get]
+func (h *APITokenHandler) Scopes(c *gin.Context) {
+	responses.Success(c, auth.APITokenScopes)
+}
+
+// Create 创建 API Token
+// @Summary 创建集成方只读 API Token
+// @Description 明文 token 只在本次响应中返回；请求时使用 Authorization: Bearer <token>，只允许 GET/HEAD
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateAPITokenRequest true "创建请求"
+// @Success 200 {object} responses.Response{data=dto.CreateAPITokenResponse}
+// @Router /api/v1/admin/api_tokens [post]
+func (h *APITokenHandler) Create(c *gin.Context) {
+	var req dto.CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	resp, err := h.svc.Create(&req, c.GetString("username"))
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// Revoke 吊销 API Token
+// @Summary 吊销 API Token（立即失效，记录保留）
+// @Tags Admin
+// @Produce json
+// @Param id path int true "Token ID"
+// @Success 200 {object} responses.Response{data=dto.APITokenResponse}
+// @Router /api/v1/admin/api_tokens/{id}/revoke [post]
+func (h *APITokenHandler) Revoke(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	resp, err := h.svc.Revoke(id, c.GetString("username"))
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// List API Token 列表
+// @Summary API Token 列表（含最近使用时间与来源）
+// @Tags Admin
+// @Produce json
+// @Param include_revoked query bool false "是否包含已吊销"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PageResponse
+// @Router /api/v1/admin/api_tokens [get]
+func (h *APITokenHandler) List(c *gin.Context) {
+	var req dto.APITokenListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+	list, total, err := h.svc.List(&req)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, dto.NewPageResponse(list, total, req.Page, req.PageSize))
+}
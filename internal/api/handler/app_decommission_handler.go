@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
+	"devops-cd/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AppDecommissionHandler 应用下线处理器
+type AppDecommissionHandler struct {
+	svc *service.AppDecommissionService
+}
+
+// NewAppDecommissionHandler 创建应用下线处理器
+func NewAppDecommissionHandler(svc *service.AppDecommissionService) *AppDecommissionHandler {
+	return &AppDecommissionHandler{svc: svc}
+}
+
+// Create 发起应用下线
+// @Summary 发起应用下线
+// @Description 可选卸载应用在各环境/集群的 helm release，随后归档构建记录并删除应用；应用在未结束的批次中或存在进行中的部署时不允许；涉及 prod 时需要 prod_operator 角色
+// @Tags 应用下线
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateAppDecommissionRequest true "应用下线请求"
+// @Success 200 {object} responses.Response{data=dto.AppDecommissionResponse}
+// @Security BearerAuth
+// @Router /api/v1/app_decommissions [post]
+func (h *AppDecommissionHandler) Create(c *gin.Context, canAccess func(username string, projectId int64, env string) bool) {
+	var req dto.CreateAppDecommissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	username := c.GetString("username")
+	resp, err := h.svc.Create(&req, username, func(projectID int64, env string) bool {
+		return canAccess(username, projectID, env)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// Get 应用下线详情
+// @Summary 应用下线详情（含各 release 卸载结果）
+// @Tags 应用下线
+// @Produce json
+// @Param id path int true "应用下线ID"
+// @Success 200 {object} responses.Response{data=dto.AppDecommissionResponse}
+// @Security BearerAuth
+// @Router /api/v1/app_decommissions/{id} [get]
+func (h *AppDecommissionHandler) Get(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	username := c.GetString("username")
+	resp, err := h.svc.Get(id, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// List 应用下线列表
+// @Summary 项目的应用下线记录
+// @Tags 应用下线
+// @Produce json
+// @Param project_id query int true "项目ID"
+// @Param app_id query int false "应用ID"
+// @Param status query string false "状态 running/success/failed"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PageResponse
+// @Security BearerAuth
+// @Router /api/v1/app_decommissions [get]
+func (h *AppDecommissionHandler) List(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	var req dto.AppDecommissionListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+	username := c.GetString("username")
+	list, total, err := h.svc.List(&req, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, dto.NewPageResponse(list, total, req.Page, req.PageSize))
+}
@@ -203,6 +203,7 @@ func (h *ApplicationHandler) GetAppTypes(c *gin.Context) {
 // @Param team_id query int false "按团队ID过滤"
 // @Param app_type query string false "按应用类型过滤"
 // @Param status query int false "按状态过滤（0/1）"
+// @Param q query string false "查询语句，如 team:payments type:java,go deployed_tag~1.4 status:enabled -project:legacy（: 精确匹配，~ 模糊匹配，- 取反）"
 // @Success 200 {object} responses.Response{data=dto.PageResponse}
 // @Router /api/v1/application_builds [get]
 func (h *ApplicationHandler) SearchWithBuilds(c *gin.Context) {
@@ -245,6 +246,29 @@ func (h *ApplicationHandler) GetDependencies(c *gin.Context) {
 	responses.Success(c, resp)
 }
 
+// GetDeployDurations 获取应用历史部署耗时
+// @Summary 获取应用各环境历史部署耗时（最近成功部署的平均/P95，部署耗时异常告警的基准）
+// @Tags Application
+// @Produce json
+// @Param id path int true "应用ID"
+// @Success 200 {object} responses.Response{data=dto.AppDeployDurationResponse}
+// @Router /api/v1/application/{id}/deploy-durations [get]
+func (h *ApplicationHandler) GetDeployDurations(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, responses.CodeBadRequest, "应用ID无效", c.Param("id"))
+		return
+	}
+
+	resp, err := h.service.GetDeployDurations(id)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+
+	responses.Success(c, resp)
+}
+
 // UpdateDependencies 更新应用默认依赖
 // @Summary 更新应用默认依赖
 // @Tags Application
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"devops-cd/internal/core"
+	"devops-cd/pkg/responses"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ArtifactCacheHandler struct {
+	coreEngine *core.CoreEngine
+}
+
+func NewArtifactCacheHandler(coreEngine *core.CoreEngine) *ArtifactCacheHandler {
+	return &ArtifactCacheHandler{coreEngine: coreEngine}
+}
+
+// Stats 共享制品缓存命中统计
+// @Summary 远端 values/chart 共享缓存命中统计（进程启动以来）
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} responses.Response{data=artifactcache.Stats}
+// @Router /api/v1/admin/engine/artifact-cache [get]
+func (h *ArtifactCacheHandler) Stats(c *gin.Context) {
+	stats, err := h.coreEngine.ArtifactCacheStats()
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, stats)
+}
@@ -1,10 +1,14 @@
 package handler
 
 import (
+	"net/http"
+	"strings"
+
 	"devops-cd/pkg/responses"
 	"github.com/gin-gonic/gin"
 
 	"devops-cd/internal/dto"
+	"devops-cd/internal/pkg/oidc"
 	"devops-cd/internal/service"
 	"devops-cd/pkg/utils"
 )
@@ -106,3 +110,61 @@ func (h *AuthHandler) Verify(c *gin.Context) {
 
 	responses.Success(c, userInfo)
 }
+
+// OIDCAuthorize OIDC 授权地址
+// @Summary OIDC 登录：获取授权地址
+// @Description 返回 IdP 授权地址与 state，浏览器跳转到 auth_url 完成登录；同时下发 HttpOnly Cookie 将 state 绑定到当前浏览器
+// @Tags 认证
+// @Produce json
+// @Success 200 {object} dto.OIDCAuthorizeResponse
+// @Router /api/v1/auth/oidc/authorize [get]
+func (h *AuthHandler) OIDCAuthorize(c *gin.Context) {
+	resp, err := h.authService.OIDCAuthorize(c.Request.Context())
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+
+	setOIDCStateCookie(c, oidc.StateDigest(resp.State), int(oidc.StateTTL.Seconds()))
+	responses.Success(c, resp)
+}
+
+// OIDCCallback OIDC 回调登录
+// @Summary OIDC 登录：回调换取 Token
+// @Description 前端回调页将 IdP 返回的 code/state 提交到该接口，完成登录并返回 Token；state 须与发起授权的浏览器 Cookie 一致
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body dto.OIDCCallbackRequest true "回调参数"
+// @Success 200 {object} dto.LoginResponse
+// @Router /api/v1/auth/oidc/callback [post]
+func (h *AuthHandler) OIDCCallback(c *gin.Context) {
+	var req dto.OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, 400, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	// state 一次性使用：无论校验结果如何都清除 Cookie
+	digest, _ := c.Cookie(oidc.StateCookieName)
+	setOIDCStateCookie(c, "", -1)
+	if !oidc.VerifyStateDigest(req.State, digest) {
+		responses.Error(c, responses.New(responses.CodeAuthError, "state 与发起登录的浏览器不匹配，请重新登录"))
+		return
+	}
+
+	resp, err := h.authService.OIDCLogin(c.Request.Context(), &req)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+
+	responses.Success(c, resp)
+}
+
+// setOIDCStateCookie 写入（maxAge < 0 时清除）state 摘要 Cookie；HTTPS（含反向代理终止 TLS）时设置 Secure
+func setOIDCStateCookie(c *gin.Context, value string, maxAge int) {
+	secure := c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https")
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidc.StateCookieName, value, maxAge, oidc.StateCookiePath, "", secure, true)
+}
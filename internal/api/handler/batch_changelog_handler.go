@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// BatchChangelogHandler 批次变更日志处理器
+type BatchChangelogHandler struct {
+	changelogService *service.BatchChangelogService
+}
+
+// NewBatchChangelogHandler 创建批次变更日志处理器
+func NewBatchChangelogHandler(changelogService *service.BatchChangelogService) *BatchChangelogHandler {
+	return &BatchChangelogHandler{changelogService: changelogService}
+}
+
+// Get 生成批次变更日志
+// @Summary 批次变更日志
+// @Description 各应用 previous_deployed_tag → target_tag（未封板时为当前部署版本 → 所选构建）之间的提交，从代码库所属仓库源 Git API 拉取，按团队分组；format=markdown 时以 Markdown 文件返回
+// @Tags 批次管理
+// @Produce json
+// @Produce text/markdown
+// @Param id path int true "批次ID"
+// @Param format query string false "导出格式: json（默认）/markdown"
+// @Success 200 {object} responses.Response{data=changelog.Changelog}
+// @Router /api/v1/batch/{id}/changelog [get]
+func (h *BatchChangelogHandler) Get(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	batchID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "批次ID无效", c.Param("id"))
+		return
+	}
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", "json")))
+	if format != "json" && format != "markdown" && format != "md" {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", "format 仅支持 json/markdown")
+		return
+	}
+
+	username := c.GetString("username")
+	out, err := h.changelogService.Generate(c.Request.Context(), batchID, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		logger.Error("生成批次变更日志失败", zap.Int64("batch_id", batchID), zap.Error(err))
+		responses.Error(c, err)
+		return
+	}
+
+	if format == "json" {
+		responses.Success(c, out)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="changelog-%d.md"`, batchID))
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(out.Markdown(0)))
+}
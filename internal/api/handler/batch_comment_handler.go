@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"net/http"
+
+	"devops-cd/internal/dto"
+	"devops-cd/pkg/responses"
+	"devops-cd/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListComments 批次评论
+// @Summary 批次评论列表（按时间正序）
+// @Tags 批次管理
+// @Produce json
+// @Param id path int true "批次ID"
+// @Param release_app_id query int false "只看某个发布应用的评论"
+// @Success 200 {object} responses.Response{data=[]dto.BatchCommentResponse}
+// @Router /api/v1/batch/{id}/comments [get]
+func (h *BatchHandler) ListComments(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	batchID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "批次ID无效", c.Param("id"))
+		return
+	}
+	var req dto.BatchCommentListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	username := c.GetString("username")
+	list, err := h.batchService.ListBatchComments(batchID, &req, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, list)
+}
+
+// CreateComment 发表批次评论
+// @Summary 发表批次评论
+// @Description 指定 release_app_id 时评论挂在该发布应用上（须属于该批次），否则为批次级评论
+// @Tags 批次管理
+// @Accept json
+// @Produce json
+// @Param id path int true "批次ID"
+// @Param request body dto.CreateBatchCommentRequest true "评论内容"
+// @Success 200 {object} responses.Response{data=dto.BatchCommentResponse}
+// @Router /api/v1/batch/{id}/comments [post]
+func (h *BatchHandler) CreateComment(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	batchID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "批次ID无效", c.Param("id"))
+		return
+	}
+	var req dto.CreateBatchCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	username := c.GetString("username")
+	response, err := h.batchService.CreateBatchComment(batchID, &req, username, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, response)
+}
+
+// UpdateComment 编辑批次评论
+// @Summary 编辑批次评论（仅作者）
+// @Tags 批次管理
+// @Accept json
+// @Produce json
+// @Param id path int true "批次ID"
+// @Param comment_id path int true "评论ID"
+// @Param request body dto.UpdateBatchCommentRequest true "评论内容"
+// @Success 200 {object} responses.Response{data=dto.BatchCommentResponse}
+// @Router /api/v1/batch/{id}/comments/{comment_id} [put]
+func (h *BatchHandler) UpdateComment(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	batchID, ok1 := parseIDParam(c.Param("id"))
+	commentID, ok2 := parseIDParam(c.Param("comment_id"))
+	if !ok1 || !ok2 {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "ID无效", c.Request.URL.Path)
+		return
+	}
+	var req dto.UpdateBatchCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	username := c.GetString("username")
+	response, err := h.batchService.UpdateBatchComment(batchID, commentID, &req, username, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, response)
+}
+
+// DeleteComment 删除批次评论
+// @Summary 删除批次评论（仅作者）
+// @Tags 批次管理
+// @Produce json
+// @Param id path int true "批次ID"
+// @Param comment_id path int true "评论ID"
+// @Success 200 {object} responses.Response
+// @Router /api/v1/batch/{id}/comments/{comment_id} [delete]
+func (h *BatchHandler) DeleteComment(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	batchID, ok1 := parseIDParam(c.Param("id"))
+	commentID, ok2 := parseIDParam(c.Param("comment_id"))
+	if !ok1 || !ok2 {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "ID无效", c.Request.URL.Path)
+		return
+	}
+
+	username := c.GetString("username")
+	if err := h.batchService.DeleteBatchComment(batchID, commentID, username, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	}); err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, nil)
+}
+
+// GetActivity 批次动态
+// @Summary 批次动态
+// @Description 按时间正序合并评论（comment）、审批（approval）、批次状态变更（transition）与手动操作（action：暂停/恢复、手动部署操作）
+// @Tags 批次管理
+// @Produce json
+// @Param id path int true "批次ID"
+// @Success 200 {object} responses.Response{data=[]dto.BatchActivityItem}
+// @Router /api/v1/batch/{id}/activity [get]
+func (h *BatchHandler) GetActivity(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	batchID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "批次ID无效", c.Param("id"))
+		return
+	}
+
+	username := c.GetString("username")
+	items, err := h.batchService.GetBatchActivity(batchID, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, items)
+}
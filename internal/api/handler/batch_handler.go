@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"net/http"
+	"time"
 
 	"devops-cd/internal/core"
 	"devops-cd/internal/dto"
@@ -15,31 +16,36 @@ import (
 	"devops-cd/pkg/utils"
 )
 
+// watchHeartbeatInterval SSE 心跳间隔，避免代理因空闲断开连接
+const watchHeartbeatInterval = 15 * time.Second
+
 // BatchHandler 批次处理器
 type BatchHandler struct {
-	coreEngine   *core.CoreEngine
-	batchService *service.BatchService
+	coreEngine          *core.CoreEngine
+	batchService        *service.BatchService
+	announcementService service.AnnouncementService
 }
 
 // NewBatchHandler 创建批次处理器
-func NewBatchHandler(coreEngine *core.CoreEngine, batchService *service.BatchService) *BatchHandler {
+func NewBatchHandler(coreEngine *core.CoreEngine, batchService *service.BatchService, announcementService service.AnnouncementService) *BatchHandler {
 	return &BatchHandler{
-		coreEngine:   coreEngine,
-		batchService: batchService,
+		coreEngine:          coreEngine,
+		batchService:        batchService,
+		announcementService: announcementService,
 	}
 }
 
 // ProcessActionRequest 处理批次操作请求
 type ProcessActionRequest struct {
 	BatchID  int64  `json:"batch_id" binding:"required"` // 批次ID
-	Action   string `json:"action" binding:"required"`   // 操作类型: seal/start_pre_deploy/finish_pre_deploy/start_prod_deploy/finish_prod_deploy/complete/cancel
-	Operator string `json:"operator" binding:"required"` // 操作人
+	Action   string `json:"action" binding:"required"`   // 操作类型: seal/start_pre_deploy/finish_pre_deploy/start_prod_deploy/finish_prod_deploy/complete/cancel/pause/resume
+	Operator string `json:"operator"`                    // 操作人（已废弃：一律取当前登录用户，请求体中的值被忽略）
 	Reason   string `json:"reason"`                      // 原因（可选）
 }
 
 // ProcessAction 处理批次状态操作
 // @Summary 批次状态操作
-// @Description 处理批次的各种状态操作，如封板、部署、验收等。支持的action: seal(封板)/start_pre_deploy(开始预发布)/finish_pre_deploy(完成预发布)/start_prod_deploy(开始生产部署)/finish_prod_deploy(完成生产部署)/complete(最终验收)/cancel(取消)
+// @Description 处理批次的各种状态操作，如封板、部署、验收等。支持的action: seal(封板)/start_pre_deploy(开始预发布)/finish_pre_deploy(完成预发布)/start_prod_deploy(开始生产部署)/finish_prod_deploy(完成生产部署)/complete(最终验收)/cancel(取消)/pause(暂停推进)/resume(恢复推进)
 // @Tags 批次管理
 // @Accept json
 // @Produce json
@@ -49,12 +55,30 @@ type ProcessActionRequest struct {
 // @Failure 500 {object} map[string]interface{} "操作失败"
 // @Security BearerAuth
 // @Router /api/v1/batch/action [post]
-func (h *BatchHandler) ProcessAction(c *gin.Context) {
+func (h *BatchHandler) ProcessAction(c *gin.Context, canProdOperate func(username string, projectId int64) bool) {
 	var req ProcessActionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
 		return
 	}
+	// 操作人取登录用户（双人原则等按操作人校验，不能由请求体指定）
+	req.Operator = c.GetString("username")
+	if req.Operator == "" {
+		responses.ErrorWithCode(c, http.StatusUnauthorized, "未登录")
+		return
+	}
+
+	// 开始生产部署、暂停/恢复需要 prod 操作权限
+	if req.Action == constants.BatchActionStartProd || req.Action == constants.BatchActionPause || req.Action == constants.BatchActionResume {
+		projectID, err := h.batchService.BatchProjectID(req.BatchID)
+		if err != nil {
+			responses.Error(c, err)
+			return
+		}
+		if !h.checkProdOperate(c, canProdOperate, projectID) {
+			return
+		}
+	}
 
 	// 处理操作
 	if err := h.coreEngine.ProcessBatchEvent(req.BatchID, req.Action, req.Operator, req.Reason); err != nil {
@@ -68,10 +92,19 @@ func (h *BatchHandler) ProcessAction(c *gin.Context) {
 	})
 }
 
+// checkProdOperate 校验当前用户在项目下的 prod 操作权限（env:prod:operate），无权限时直接返回 403
+func (h *BatchHandler) checkProdOperate(c *gin.Context, canProdOperate func(username string, projectId int64) bool, projectID int64) bool {
+	if canProdOperate(c.GetString("username"), projectID) {
+		return true
+	}
+	responses.ErrorWithCode(c, http.StatusForbidden, "无生产环境操作权限（需要 prod_operator 角色）")
+	return false
+}
+
 // ApproveRequest 审核通过请求
 type ApproveRequest struct {
 	BatchID  int64  `json:"batch_id" binding:"required"` // 批次ID
-	Operator string `json:"operator" binding:"required"` // 审核人
+	Operator string `json:"operator"`                    // 审核人（已废弃：一律取当前登录用户）
 	Reason   string `json:"reason"`                      // 审核意见
 }
 
@@ -94,6 +127,13 @@ func (h *BatchHandler) Approve(c *gin.Context) {
 		return
 	}
 
+	// 审批人取登录用户：审批人名单与 N-of-M 计数都按该身份校验
+	req.Operator = c.GetString("username")
+	if req.Operator == "" {
+		responses.ErrorWithCode(c, http.StatusUnauthorized, "未登录")
+		return
+	}
+
 	// 调用 service 层处理审批
 	if err := h.batchService.ApproveBatch(req.BatchID, req.Operator, req.Reason); err != nil {
 		responses.ErrorWithCode(c, http.StatusInternalServerError, err.Error())
@@ -106,7 +146,7 @@ func (h *BatchHandler) Approve(c *gin.Context) {
 // RejectRequest 拒绝请求
 type RejectRequest struct {
 	BatchID  int64  `json:"batch_id" binding:"required"` // 批次ID
-	Operator string `json:"operator" binding:"required"` // 审核人
+	Operator string `json:"operator"`                    // 审核人（已废弃：一律取当前登录用户）
 	Reason   string `json:"reason" binding:"required"`   // 拒绝原因
 }
 
@@ -129,6 +169,12 @@ func (h *BatchHandler) Reject(c *gin.Context) {
 		return
 	}
 
+	req.Operator = c.GetString("username")
+	if req.Operator == "" {
+		responses.ErrorWithCode(c, http.StatusUnauthorized, "未登录")
+		return
+	}
+
 	// 调用 service 层处理拒绝
 	if err := h.batchService.RejectBatch(req.BatchID, req.Operator, req.Reason); err != nil {
 		responses.ErrorWithCode(c, http.StatusInternalServerError, err.Error())
@@ -138,6 +184,47 @@ func (h *BatchHandler) Reject(c *gin.Context) {
 	responses.Success(c, gin.H{"message": "已拒绝"})
 }
 
+// GetApprovals 查询批次审批进度
+// @Summary 批次审批进度
+// @Description 项目配置多人/分阶段审批策略时返回各阶段审批人、通过人数与审批记录
+// @Tags 批次管理
+// @Produce json
+// @Param id path int true "批次ID"
+// @Success 200 {object} responses.Response{data=dto.BatchApprovalResponse}
+// @Router /api/v1/batch/{id}/approvals [get]
+func (h *BatchHandler) GetApprovals(c *gin.Context) {
+	batchID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "批次ID无效", c.Param("id"))
+		return
+	}
+
+	resp, err := h.batchService.GetBatchApprovals(batchID)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+
+	responses.Success(c, resp)
+}
+
+// PendingApprovals 查询等待当前用户审批的批次
+// @Summary 待我审批的批次
+// @Tags 批次管理
+// @Produce json
+// @Success 200 {object} responses.Response{data=[]dto.PendingApprovalItem}
+// @Router /api/v1/approvals/pending [get]
+func (h *BatchHandler) PendingApprovals(c *gin.Context) {
+	items, err := h.batchService.ListPendingApprovals(c.GetString("username"))
+	if err != nil {
+		logger.Error("查询待审批批次失败", zap.String("username", c.GetString("username")), zap.Error(err))
+		responses.ErrorWithCode(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.Success(c, items)
+}
+
 // ============== 批次管理接口（新增） ==============
 
 // Create 创建批次
@@ -176,40 +263,7 @@ func (h *BatchHandler) Create(c *gin.Context, canAccess func(username string, pr
 	if err != nil {
 		// 处理应用冲突错误
 		if conflictErr, ok := err.(*service.AppConflictError); ok {
-			conflicts := make([]gin.H, 0)
-			for appID, conflictBatch := range conflictErr.Conflicts {
-				app := conflictErr.AppMap[appID]
-				appName := ""
-				appProject := ""
-				if app != nil {
-					appName = app.Name
-					// 从 Repository 获取 namespace 作为 project
-					if app.Repository != nil {
-						appProject = app.Repository.Namespace
-					}
-				}
-
-				statusName := getStatusName(conflictBatch.Status)
-
-				conflicts = append(conflicts, gin.H{
-					"app_id":            appID,
-					"app_name":          appName,
-					"app_project":       appProject,
-					"batch_id":          conflictBatch.ID,
-					"batch_number":      conflictBatch.BatchNumber,
-					"batch_status":      conflictBatch.Status,
-					"batch_status_name": statusName,
-				})
-			}
-
-			// 只返回一次响应，包含详细冲突信息
-			c.JSON(http.StatusConflict, gin.H{
-				"code":    http.StatusConflict,
-				"message": fmt.Sprintf("存在应用冲突，有 %d 个应用已在其他批次中", len(conflicts)),
-				"data": gin.H{
-					"conflicts": conflicts,
-				},
-			})
+			respondAppConflict(c, conflictErr)
 			return
 		}
 
@@ -218,10 +272,18 @@ func (h *BatchHandler) Create(c *gin.Context, canAccess func(username string, pr
 		return
 	}
 
+	// 附带当前生效的公告（如发布冻结通知），查询失败不影响创建结果
+	announcements, err := h.announcementService.ListActive()
+	if err != nil {
+		logger.Warn("查询生效公告失败", zap.Error(err))
+		announcements = []*dto.AnnouncementResponse{}
+	}
+
 	responses.Success(c, gin.H{
-		"batch_id":     batch.ID,
-		"batch_number": batch.BatchNumber,
-		"message":      "批次创建成功",
+		"batch_id":      batch.ID,
+		"batch_number":  batch.BatchNumber,
+		"message":       "批次创建成功",
+		"announcements": announcements,
 	})
 }
 
@@ -255,14 +317,10 @@ func (h *BatchHandler) Update(c *gin.Context, canAccess func(username string, pr
 		// 处理批次已封板错误
 		if sealedErr, ok := err.(*service.BatchSealedError); ok {
 			statusName := getStatusName(sealedErr.Status)
-			c.JSON(http.StatusForbidden, gin.H{
-				"code":    http.StatusForbidden,
-				"message": "批次已封板，不允许修改",
-				"data": gin.H{
-					"batch_id":    sealedErr.BatchID,
-					"status":      sealedErr.Status,
-					"status_name": statusName,
-				},
+			responses.ErrorWithData(c, http.StatusForbidden, "批次已封板，不允许修改", gin.H{
+				"batch_id":    sealedErr.BatchID,
+				"status":      sealedErr.Status,
+				"status_name": statusName,
 			})
 			return
 		}
@@ -295,12 +353,8 @@ func (h *BatchHandler) Update(c *gin.Context, canAccess func(username string, pr
 				})
 			}
 
-			c.JSON(http.StatusConflict, gin.H{
-				"code":    http.StatusConflict,
-				"message": fmt.Sprintf("存在应用冲突，有 %d 个应用已在其他批次中", len(conflicts)),
-				"data": gin.H{
-					"conflicts": conflicts,
-				},
+			responses.ErrorWithData(c, http.StatusConflict, fmt.Sprintf("存在应用冲突，有 %d 个应用已在其他批次中", len(conflicts)), gin.H{
+				"conflicts": conflicts,
 			})
 			return
 		}
@@ -375,6 +429,246 @@ func (h *BatchHandler) Get(c *gin.Context) {
 	responses.Success(c, response)
 }
 
+// GetImpact 获取批次部署影响
+// @Summary 获取批次部署影响
+// @Description 批次完成后各应用在各集群的副本数、CPU/内存 requests/limits 部署前后对比及差值
+// @Tags 批次管理
+// @Accept json
+// @Produce json
+// @Param id path int true "批次ID"
+// @Success 200 {object} responses.Response{data=dto.BatchImpactResponse}
+// @Router /api/v1/batch/{id}/impact [get]
+func (h *BatchHandler) GetImpact(c *gin.Context) {
+	batchID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "批次ID无效", c.Param("id"))
+		return
+	}
+
+	response, err := h.batchService.GetBatchImpact(batchID)
+	if err != nil {
+		logger.Error("获取批次部署影响失败", zap.Int64("batch_id", batchID), zap.Error(err))
+		responses.ErrorWithCode(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.Success(c, response)
+}
+
+// Plan 预览批次部署计划
+// @Summary 预览批次部署计划（dry-run）
+// @Description 不修改任何状态，解析各应用的构建、目标集群、deployment 名称、合并后的 values（敏感字段脱敏）及依赖顺序，用于封板前发现模板/配置错误
+// @Tags 批次管理
+// @Produce json
+// @Param id path int true "批次ID"
+// @Success 200 {object} responses.Response{data=dto.BatchPlanResponse}
+// @Router /api/v1/batch/{id}/plan [post]
+func (h *BatchHandler) Plan(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	batchID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "批次ID无效", c.Param("id"))
+		return
+	}
+
+	username := c.GetString("username")
+	response, err := h.batchService.PlanBatch(c.Request.Context(), batchID, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		logger.Error("预览批次部署计划失败", zap.Int64("batch_id", batchID), zap.Error(err))
+		responses.Error(c, err)
+		return
+	}
+
+	responses.Success(c, response)
+}
+
+// Split 拆分批次
+// @Summary 拆分草稿批次
+// @Description 将选中的发布应用移入新建的草稿批次，应用级发布说明、临时依赖、所选构建保持不变；原批次至少保留一个应用
+// @Tags 批次管理
+// @Accept json
+// @Produce json
+// @Param id path int true "批次ID"
+// @Param request body dto.SplitBatchRequest true "拆分请求"
+// @Success 200 {object} responses.Response{data=dto.SplitBatchResponse}
+// @Failure 403 {object} map[string]interface{} "批次已封板"
+// @Router /api/v1/batch/{id}/split [post]
+func (h *BatchHandler) Split(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	batchID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "批次ID无效", c.Param("id"))
+		return
+	}
+	var req dto.SplitBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	username := c.GetString("username")
+	response, err := h.batchService.SplitBatch(batchID, &req, username, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		if sealedErr, ok := err.(*service.BatchSealedError); ok {
+			respondBatchSealed(c, sealedErr)
+			return
+		}
+		logger.Error("拆分批次失败", zap.Int64("batch_id", batchID), zap.Error(err))
+		responses.Error(c, err)
+		return
+	}
+
+	responses.Success(c, response)
+}
+
+// Merge 合并批次
+// @Summary 合并草稿批次
+// @Description 将同项目另一个草稿批次的发布应用并入当前批次并删除被合并批次，应用级发布说明、临时依赖、所选构建保持不变；两个批次包含相同应用时拒绝
+// @Tags 批次管理
+// @Accept json
+// @Produce json
+// @Param id path int true "批次ID"
+// @Param request body dto.MergeBatchRequest true "合并请求"
+// @Success 200 {object} responses.Response{data=dto.MergeBatchResponse}
+// @Failure 403 {object} map[string]interface{} "批次已封板"
+// @Router /api/v1/batch/{id}/merge [post]
+func (h *BatchHandler) Merge(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	batchID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "批次ID无效", c.Param("id"))
+		return
+	}
+	var req dto.MergeBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	username := c.GetString("username")
+	response, err := h.batchService.MergeBatch(batchID, &req, username, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		if sealedErr, ok := err.(*service.BatchSealedError); ok {
+			respondBatchSealed(c, sealedErr)
+			return
+		}
+		logger.Error("合并批次失败", zap.Int64("batch_id", batchID), zap.Int64("source_batch_id", req.SourceBatchID), zap.Error(err))
+		responses.Error(c, err)
+		return
+	}
+
+	responses.Success(c, response)
+}
+
+// Export 导出批次
+// @Summary 导出已封板批次
+// @Description 导出批次的应用、所选构建、依赖、values 覆盖与 chart/清单锁定引用为 JSON 导出包，应用按名称引用，可导入其他实例（灾备站点、平台副本）
+// @Tags 批次管理
+// @Produce json
+// @Param id path int true "批次ID"
+// @Success 200 {object} responses.Response{data=dto.BatchBundle}
+// @Router /api/v1/batch/{id}/export [get]
+func (h *BatchHandler) Export(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	batchID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "批次ID无效", c.Param("id"))
+		return
+	}
+
+	username := c.GetString("username")
+	bundle, err := h.batchService.ExportBatch(batchID, username, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		logger.Error("导出批次失败", zap.Int64("batch_id", batchID), zap.Error(err))
+		responses.Error(c, err)
+		return
+	}
+
+	responses.Success(c, bundle)
+}
+
+// Import 导入批次
+// @Summary 导入批次导出包
+// @Description 将导出包导入为目标项目的草稿批次：应用按名称映射，构建按构建号/镜像 tag/commit 匹配，create_builds=true 时缺少的构建按导出包创建；chart/清单锁定在封板时重新解析
+// @Tags 批次管理
+// @Accept json
+// @Produce json
+// @Param request body dto.ImportBatchRequest true "导入请求"
+// @Success 200 {object} responses.Response{data=dto.ImportBatchResponse}
+// @Router /api/v1/batch/import [post]
+func (h *BatchHandler) Import(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	var req dto.ImportBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	username := c.GetString("username")
+	response, err := h.batchService.ImportBatch(&req, username, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		logger.Error("导入批次失败", zap.Int64("project_id", req.ProjectID), zap.String("batch_number", req.Bundle.BatchNumber), zap.Error(err))
+		responses.Error(c, err)
+		return
+	}
+
+	responses.Success(c, response)
+}
+
+// Watch 以 SSE 推送批次状态变更，替代轮询 GetStatus
+// 连接建立后先推送一次 snapshot（同 /batch/status），之后推送 batch/release_app/deployment 状态变更（event: status），
+// 每 15 秒发送一次心跳注释；推送缓冲溢出时客户端应重新拉取状态
+// @Summary 订阅批次状态变更
+// @Tags 批次管理
+// @Produce text/event-stream
+// @Param id path int true "批次ID"
+// @Param app_page query int false "snapshot 应用列表页码"
+// @Param app_page_size query int false "snapshot 应用列表每页数量"
+// @Router /api/v1/batch/{id}/watch [get]
+func (h *BatchHandler) Watch(c *gin.Context) {
+	batchID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "批次ID无效", c.Param("id"))
+		return
+	}
+	req := dto.BatchStatusRequest{ID: batchID}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	// 先订阅再查询 snapshot，避免两者之间的变更丢失
+	events, cancel := h.coreEngine.Watch().Subscribe(batchID)
+	defer cancel()
+
+	snapshot, err := h.batchService.GetBatchStatus(batchID, req.GetAppPage(), req.GetAppPageSize())
+	if err != nil {
+		logger.Error("获取批次状态失败", zap.Int64("batch_id", batchID), zap.Error(err))
+		responses.ErrorWithCode(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	sendSSE(c, "snapshot", snapshot)
+
+	heartbeat := time.NewTicker(watchHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event := <-events:
+			sendSSE(c, "status", event)
+		case <-heartbeat.C:
+			_, _ = fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
 // List 查询批次列表
 // @Summary 查询批次列表
 // @Description 分页查询批次列表，支持状态、发起人、审批状态、时间范围、关键字过滤。status支持多值，例如：?status=1&status=2&status=3
@@ -394,7 +688,7 @@ func (h *BatchHandler) Get(c *gin.Context) {
 // @Failure 500 {object} map[string]interface{} "服务器错误"
 // @Security BearerAuth
 // @Router /api/v1/batches [get]
-func (h *BatchHandler) List(c *gin.Context) {
+func (h *BatchHandler) List(c *gin.Context, projectIDs []int64) {
 	var req dto.BatchListQuery
 
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -403,6 +697,7 @@ func (h *BatchHandler) List(c *gin.Context) {
 	}
 
 	param := req.ToParam()
+	param.ProjectIDs = projectIDs
 	response, total, err := h.batchService.ListBatches(param)
 	if err != nil {
 		logger.Error("查询批次列表失败", zap.Error(err))
@@ -462,9 +757,320 @@ func getStatusName(status int8) string {
 		return "生产已部署"
 	case constants.BatchStatusCompleted:
 		return "已完成"
+	case constants.BatchStatusRollingBack:
+		return "回滚中"
+	case constants.BatchStatusRolledBack:
+		return "已回滚"
+	case constants.BatchStatusRollbackFailed:
+		return "回滚失败"
 	case constants.BatchStatusCancelled:
 		return "已取消"
 	default:
 		return "未知状态"
 	}
 }
+
+// GetRetro 获取批次复盘数据
+// @Summary 获取批次复盘数据（发布后故障与回滚记录）
+// @Tags 批次管理
+// @Produce json
+// @Param id path int true "批次ID"
+// @Success 200 {object} responses.Response{data=dto.BatchRetroResponse}
+// @Router /api/v1/batch/{id}/retro [get]
+func (h *BatchHandler) GetRetro(c *gin.Context) {
+	batchID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "批次ID无效", c.Param("id"))
+		return
+	}
+
+	response, err := h.batchService.GetBatchRetro(batchID)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, response)
+}
+
+// CreateIncident 记录批次发布后故障
+// @Summary 记录批次发布后故障（仅已完成批次）
+// @Tags 批次管理
+// @Accept json
+// @Produce json
+// @Param id path int true "批次ID"
+// @Param request body dto.CreateBatchIncidentRequest true "故障信息"
+// @Success 200 {object} responses.Response{data=dto.BatchIncidentResponse}
+// @Router /api/v1/batch/{id}/incidents [post]
+func (h *BatchHandler) CreateIncident(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	batchID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "批次ID无效", c.Param("id"))
+		return
+	}
+	var req dto.CreateBatchIncidentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	username := c.GetString("username")
+	response, err := h.batchService.CreateBatchIncident(batchID, &req, username, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, response)
+}
+
+// DeleteIncident 删除故障记录
+// @Summary 删除批次故障记录
+// @Tags 批次管理
+// @Produce json
+// @Param id path int true "批次ID"
+// @Param incident_id path int true "故障记录ID"
+// @Success 200 {object} responses.Response
+// @Router /api/v1/batch/{id}/incidents/{incident_id} [delete]
+func (h *BatchHandler) DeleteIncident(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	batchID, ok1 := parseIDParam(c.Param("id"))
+	incidentID, ok2 := parseIDParam(c.Param("incident_id"))
+	if !ok1 || !ok2 {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "ID无效", c.Request.URL.Path)
+		return
+	}
+
+	username := c.GetString("username")
+	if err := h.batchService.DeleteBatchIncident(batchID, incidentID, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	}); err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, nil)
+}
+
+// CreateRollback 记录批次发布后回滚
+// @Summary 记录批次发布后回滚（仅已完成批次，from_tag/to_tag 默认取批次目标版本/发布前版本）
+// @Tags 批次管理
+// @Accept json
+// @Produce json
+// @Param id path int true "批次ID"
+// @Param request body dto.CreateBatchRollbackRequest true "回滚信息"
+// @Success 200 {object} responses.Response{data=dto.BatchRollbackResponse}
+// @Router /api/v1/batch/{id}/rollbacks [post]
+func (h *BatchHandler) CreateRollback(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	batchID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "批次ID无效", c.Param("id"))
+		return
+	}
+	var req dto.CreateBatchRollbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	username := c.GetString("username")
+	response, err := h.batchService.CreateBatchRollback(batchID, &req, username, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, response)
+}
+
+// DeleteRollback 删除回滚记录
+// @Summary 删除批次回滚记录
+// @Tags 批次管理
+// @Produce json
+// @Param id path int true "批次ID"
+// @Param rollback_id path int true "回滚记录ID"
+// @Success 200 {object} responses.Response
+// @Router /api/v1/batch/{id}/rollbacks/{rollback_id} [delete]
+func (h *BatchHandler) DeleteRollback(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	batchID, ok1 := parseIDParam(c.Param("id"))
+	rollbackID, ok2 := parseIDParam(c.Param("rollback_id"))
+	if !ok1 || !ok2 {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "ID无效", c.Request.URL.Path)
+		return
+	}
+
+	username := c.GetString("username")
+	if err := h.batchService.DeleteBatchRollback(batchID, rollbackID, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	}); err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, nil)
+}
+
+// SetSchedule 设置批次定时部署
+// @Summary 设置批次定时开始预发布/生产部署（到达计划时间后自动触发，生产需 prod 操作权限）
+// @Tags 批次管理
+// @Accept json
+// @Produce json
+// @Param id path int true "批次ID"
+// @Param request body dto.SetBatchScheduleRequest true "定时部署"
+// @Success 200 {object} responses.Response{data=dto.BatchScheduleResponse}
+// @Security BearerAuth
+// @Router /api/v1/batch/{id}/schedule [put]
+func (h *BatchHandler) SetSchedule(c *gin.Context, canProdOperate func(username string, projectId int64) bool) {
+	batchID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "批次ID无效", c.Param("id"))
+		return
+	}
+	var req dto.SetBatchScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	username := c.GetString("username")
+	response, err := h.batchService.SetBatchSchedule(batchID, &req, username, func(projectID int64) bool {
+		return canProdOperate(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, response)
+}
+
+// CancelSchedule 取消批次定时部署
+// @Summary 取消批次定时预发布/生产部署
+// @Tags 批次管理
+// @Produce json
+// @Param id path int true "批次ID"
+// @Param env query string true "pre/prod"
+// @Success 200 {object} responses.Response{data=dto.BatchScheduleResponse}
+// @Security BearerAuth
+// @Router /api/v1/batch/{id}/schedule [delete]
+func (h *BatchHandler) CancelSchedule(c *gin.Context, canProdOperate func(username string, projectId int64) bool) {
+	batchID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "批次ID无效", c.Param("id"))
+		return
+	}
+	var query dto.CancelBatchScheduleQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	username := c.GetString("username")
+	response, err := h.batchService.CancelBatchSchedule(batchID, query.Env, username, func(projectID int64) bool {
+		return canProdOperate(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, response)
+}
+
+// SetVulnOverride 豁免批次漏洞门禁
+// @Summary 豁免批次镜像漏洞门禁（需 security:vuln:override 权限），豁免后封板/生产部署不再按项目漏洞策略拦截
+// @Tags 批次管理
+// @Accept json
+// @Produce json
+// @Param id path int true "批次ID"
+// @Param request body dto.BatchVulnOverrideRequest true "豁免原因"
+// @Success 200 {object} responses.Response{data=dto.BatchVulnOverrideResponse}
+// @Security BearerAuth
+// @Router /api/v1/batch/{id}/vuln_override [post]
+func (h *BatchHandler) SetVulnOverride(c *gin.Context, canOverride func(username string, projectId int64) bool) {
+	batchID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "批次ID无效", c.Param("id"))
+		return
+	}
+	var req dto.BatchVulnOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	username := c.GetString("username")
+	response, err := h.batchService.SetBatchVulnOverride(batchID, &req, username, func(projectID int64) bool {
+		return canOverride(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, response)
+}
+
+// CancelVulnOverride 取消批次漏洞门禁豁免
+// @Summary 取消批次镜像漏洞门禁豁免
+// @Tags 批次管理
+// @Produce json
+// @Param id path int true "批次ID"
+// @Success 200 {object} responses.Response{data=dto.BatchVulnOverrideResponse}
+// @Security BearerAuth
+// @Router /api/v1/batch/{id}/vuln_override [delete]
+func (h *BatchHandler) CancelVulnOverride(c *gin.Context, canOverride func(username string, projectId int64) bool) {
+	batchID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "批次ID无效", c.Param("id"))
+		return
+	}
+
+	username := c.GetString("username")
+	response, err := h.batchService.CancelBatchVulnOverride(batchID, username, func(projectID int64) bool {
+		return canOverride(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, response)
+}
+
+// respondBatchSealed 返回批次已封板（非草稿）错误
+func respondBatchSealed(c *gin.Context, sealedErr *service.BatchSealedError) {
+	responses.ErrorWithData(c, http.StatusForbidden, "批次已封板，不允许修改", gin.H{
+		"batch_id":    sealedErr.BatchID,
+		"status":      sealedErr.Status,
+		"status_name": getStatusName(sealedErr.Status),
+	})
+}
+
+// respondAppConflict 返回应用冲突明细（应用已在其他未完成批次中）
+func respondAppConflict(c *gin.Context, conflictErr *service.AppConflictError) {
+	conflicts := make([]gin.H, 0)
+	for appID, conflictBatch := range conflictErr.Conflicts {
+		app := conflictErr.AppMap[appID]
+		appName := ""
+		appProject := ""
+		if app != nil {
+			appName = app.Name
+			// 从 Repository 获取 namespace 作为 project
+			if app.Repository != nil {
+				appProject = app.Repository.Namespace
+			}
+		}
+
+		statusName := getStatusName(conflictBatch.Status)
+
+		conflicts = append(conflicts, gin.H{
+			"app_id":            appID,
+			"app_name":          appName,
+			"app_project":       appProject,
+			"batch_id":          conflictBatch.ID,
+			"batch_number":      conflictBatch.BatchNumber,
+			"batch_status":      conflictBatch.Status,
+			"batch_status_name": statusName,
+		})
+	}
+
+	// 只返回一次响应，包含详细冲突信息
+	responses.ErrorWithData(c, http.StatusConflict, fmt.Sprintf("存在应用冲突，有 %d 个应用已在其他批次中", len(conflicts)), gin.H{
+		"conflicts": conflicts,
+	})
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"devops-cd/internal/dto"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
+	"devops-cd/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// BatchTemplateHandler 批次模板处理器
+type BatchTemplateHandler struct {
+	batchService *service.BatchService
+}
+
+// NewBatchTemplateHandler 创建批次模板处理器
+func NewBatchTemplateHandler(batchService *service.BatchService) *BatchTemplateHandler {
+	return &BatchTemplateHandler{batchService: batchService}
+}
+
+// List 查询项目下的批次模板
+// @Summary 批次模板列表
+// @Tags 批次模板
+// @Produce json
+// @Param project_id query int true "项目ID"
+// @Success 200 {object} responses.Response{data=[]dto.BatchTemplateResponse}
+// @Router /api/v1/batch_templates [get]
+func (h *BatchTemplateHandler) List(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	var query dto.BatchTemplateQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	if !canAccess(c.GetString("username"), query.ProjectID) {
+		responses.Error(c, responses.ErrForbidden)
+		return
+	}
+
+	items, err := h.batchService.ListBatchTemplates(query.ProjectID)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, items)
+}
+
+// Get 查询批次模板详情
+// @Summary 批次模板详情
+// @Tags 批次模板
+// @Produce json
+// @Param id path int true "模板ID"
+// @Success 200 {object} responses.Response{data=dto.BatchTemplateResponse}
+// @Router /api/v1/batch_templates/{id} [get]
+func (h *BatchTemplateHandler) Get(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "模板ID无效", c.Param("id"))
+		return
+	}
+
+	username := c.GetString("username")
+	resp, err := h.batchService.GetBatchTemplate(id, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// Create 创建批次模板
+// @Summary 创建批次模板（固定周期发布的应用集合、默认依赖、发布说明脚手架）
+// @Tags 批次模板
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateBatchTemplateRequest true "模板信息"
+// @Success 200 {object} responses.Response{data=dto.BatchTemplateResponse}
+// @Router /api/v1/batch_templates [post]
+func (h *BatchTemplateHandler) Create(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	var req dto.CreateBatchTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	username := c.GetString("username")
+	if !canAccess(username, req.ProjectID) {
+		responses.Error(c, responses.ErrForbidden)
+		return
+	}
+
+	resp, err := h.batchService.CreateBatchTemplate(&req, username)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// Update 更新批次模板
+// @Summary 更新批次模板
+// @Tags 批次模板
+// @Accept json
+// @Produce json
+// @Param id path int true "模板ID"
+// @Param request body dto.UpdateBatchTemplateRequest true "模板信息"
+// @Success 200 {object} responses.Response{data=dto.BatchTemplateResponse}
+// @Router /api/v1/batch_templates/{id} [put]
+func (h *BatchTemplateHandler) Update(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "模板ID无效", c.Param("id"))
+		return
+	}
+	var req dto.UpdateBatchTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	username := c.GetString("username")
+	resp, err := h.batchService.UpdateBatchTemplate(id, &req, username, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// Delete 删除批次模板
+// @Summary 删除批次模板
+// @Tags 批次模板
+// @Produce json
+// @Param id path int true "模板ID"
+// @Success 200 {object} responses.Response
+// @Router /api/v1/batch_templates/{id} [delete]
+func (h *BatchTemplateHandler) Delete(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "模板ID无效", c.Param("id"))
+		return
+	}
+
+	username := c.GetString("username")
+	if err := h.batchService.DeleteBatchTemplate(id, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	}); err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, gin.H{"message": "删除成功"})
+}
+
+// CreateBatch 根据模板创建草稿批次
+// @Summary 根据模板创建草稿批次
+// @Description 批次编号/发布说明未指定时按模板渲染；模板应用按部署后最新构建填充，并设置模板中的临时依赖
+// @Tags 批次模板
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateBatchFromTemplateRequest true "创建请求"
+// @Success 200 {object} responses.Response{data=dto.CreateBatchFromTemplateResponse}
+// @Failure 409 {object} map[string]interface{} "应用冲突"
+// @Router /api/v1/batch/from-template [post]
+func (h *BatchTemplateHandler) CreateBatch(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	var req dto.CreateBatchFromTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	username := c.GetString("username")
+	resp, err := h.batchService.CreateBatchFromTemplate(&req, username, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		if conflictErr, ok := err.(*service.AppConflictError); ok {
+			respondAppConflict(c, conflictErr)
+			return
+		}
+		logger.Error("根据模板创建批次失败", zap.Int64("template_id", req.TemplateID), zap.Error(err))
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
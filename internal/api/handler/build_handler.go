@@ -41,11 +41,16 @@ func (h *BuildHandler) Notify(c *gin.Context) {
 		return
 	}
 
+	processBuildNotify(c, h.buildService, &req)
+}
+
+// processBuildNotify 处理构建通知并写响应（/build/notify 与通用 CI 来源共用）
+func processBuildNotify(c *gin.Context, buildService service.BuildService, req *dto.BuildNotifyRequest) {
 	log := logger.Log.Sugar().With(zap.String("repo", req.Repo))
 	log.Infof("BuildHandler.Notify %s: %s, app num: %v, build_number: %v", req.Repo, req.BuildStatus, len(req.Apps), req.BuildNumber)
 
 	// 处理构建通知
-	if err := h.buildService.ProcessNotify(&req); err != nil {
+	if err := buildService.ProcessNotify(req); err != nil {
 		// 部分成功的情况也返回成功，但在响应中说明
 		if err.(*responses.AppError).Code == responses.CodePartialSuccess {
 			logger.Warn("构建通知部分处理成功", zap.Error(err))
@@ -0,0 +1,285 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"regexp"
+	"strings"
+
+	"devops-cd/internal/adapter/ci"
+	"devops-cd/internal/dto"
+	"devops-cd/internal/pkg/config"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
+	"devops-cd/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"go.uber.org/zap"
+)
+
+// CIHandler 外部 CI（GitLab CI、GitHub Actions、Jenkins 等）构建通知适配处理器
+type CIHandler struct {
+	buildService      service.BuildService
+	repositoryService service.RepositoryService
+	imageScanService  service.ImageScanService
+	rules             *ci.Rules
+	cfg               config.CIConfig
+	githubTag         *regexp.Regexp
+}
+
+func NewCIHandler(buildService service.BuildService, repositoryService service.RepositoryService, imageScanService service.ImageScanService, rules *ci.Rules, cfg config.CIConfig) *CIHandler {
+	h := &CIHandler{buildService: buildService, repositoryService: repositoryService, imageScanService: imageScanService, rules: rules, cfg: cfg}
+	pattern := cfg.GitHub.TagPattern
+	if pattern == "" {
+		pattern = ci.DefaultGitHubTagPattern
+	}
+	tagPattern, err := regexp.Compile(pattern)
+	if err != nil {
+		logger.Warn("ci.github.tag_pattern 不合法，使用默认值", zap.String("tag_pattern", pattern), zap.Error(err))
+		tagPattern = regexp.MustCompile(ci.DefaultGitHubTagPattern)
+	}
+	h.githubTag = tagPattern
+
+	for key, missing := range map[string]bool{
+		"ci.gitlab.token":       cfg.GitLab.Enabled && cfg.GitLab.Token == "",
+		"ci.harbor.auth_header": cfg.Harbor.Enabled && cfg.Harbor.AuthHeader == "",
+		"ci.trivy.token":        cfg.Trivy.Enabled && cfg.Trivy.Token == "",
+	} {
+		if missing {
+			logger.Warn("构建通知接收器已启用但未配置校验 token，所有请求将被拒绝", zap.String("config", key))
+		}
+	}
+	return h
+}
+
+// verifyWebhookToken 校验接收器 token；未配置 token 时一律拒绝（401），避免伪造通知/扫描报告，不匹配时返回 403
+func verifyWebhookToken(c *gin.Context, configKey, expected, got string) bool {
+	if expected == "" {
+		responses.ErrorWithDetail(c, responses.CodeUnauthorized, "接收器未配置校验 token，拒绝请求", configKey+" 为空")
+		return false
+	}
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(got)) != 1 {
+		responses.Error(c, responses.ErrForbidden)
+		return false
+	}
+	return true
+}
+
+// GitLab 接收 GitLab CI 构建通知
+// @Summary 接收 GitLab CI 构建通知（Pipeline Hook / Job Hook）
+// @Description 流水线/job 到达终态（success/failed/canceled）后按 ci.rules_file 映射为应用构建；校验请求头 X-Gitlab-Token（须配置 ci.gitlab.token）
+// @Tags Build
+// @Accept json
+// @Produce json
+// @Param X-Gitlab-Event header string false "事件类型"
+// @Param X-Gitlab-Token header string true "Webhook Secret token"
+// @Success 200 {object} responses.Response "成功响应"
+// @Router /build/notify/gitlab [post]
+func (h *CIHandler) GitLab(c *gin.Context) {
+	if !h.cfg.GitLab.Enabled {
+		responses.ErrorWithDetail(c, responses.CodeNotFound, "GitLab CI 构建通知未启用", "ci.gitlab.enabled=false")
+		return
+	}
+	if !verifyWebhookToken(c, "ci.gitlab.token", h.cfg.GitLab.Token, c.GetHeader("X-Gitlab-Token")) {
+		return
+	}
+	payload, err := c.GetRawData()
+	if err != nil {
+		responses.ErrorWithDetail(c, responses.CodeBadRequest, "读取请求体失败", err.Error())
+		return
+	}
+
+	pipeline, err := ci.ParseGitLab(c.GetHeader("X-Gitlab-Event"), payload)
+	h.notify(c, "gitlab", pipeline, err)
+}
+
+// GitHub 接收 GitHub Actions 构建通知
+// @Summary 接收 GitHub Actions 构建通知（workflow_run / workflow_job）
+// @Description 校验 X-Hub-Signature-256 后，运行/job 完成（action=completed）时按 ci.rules_file 映射为应用构建
+// @Tags Build
+// @Accept json
+// @Produce json
+// @Param X-GitHub-Event header string true "事件类型"
+// @Param X-Hub-Signature-256 header string true "payload 签名"
+// @Success 200 {object} responses.Response "成功响应"
+// @Router /build/notify/github [post]
+func (h *CIHandler) GitHub(c *gin.Context) {
+	if !h.cfg.GitHub.Enabled {
+		responses.ErrorWithDetail(c, responses.CodeNotFound, "GitHub Actions 构建通知未启用", "ci.github.enabled=false")
+		return
+	}
+	payload, err := c.GetRawData()
+	if err != nil {
+		responses.ErrorWithDetail(c, responses.CodeBadRequest, "读取请求体失败", err.Error())
+		return
+	}
+	if !ci.VerifyGitHubSignature(h.cfg.GitHub.Secret, payload, c.GetHeader("X-Hub-Signature-256")) {
+		logger.Warn("GitHub 构建通知签名校验失败", zap.String("delivery", c.GetHeader("X-GitHub-Delivery")))
+		responses.Error(c, responses.ErrForbidden)
+		return
+	}
+
+	pipeline, err := ci.ParseGitHub(c.GetHeader("X-GitHub-Event"), payload, h.githubTag)
+	h.notify(c, "github", pipeline, err)
+}
+
+// Jenkins 接收 Jenkins 构建通知
+// @Summary 接收 Jenkins 构建通知（Notification 插件 JSON 格式）
+// @Description 仅处理 ci.jenkins.phase 阶段（默认 FINALIZED）；代码库取参数 REPO 或 scm.url，token 需与代码库配置的 ci_token 一致；
+// @Description 应用取参数 APPS/APP（逗号分隔，镜像 tag 取 IMAGE_TAG），未传时按 ci.rules_file 映射
+// @Tags Build
+// @Accept json
+// @Produce json
+// @Param X-Webhook-Token header string true "代码库 Jenkins token（不接受 query 参数，避免写入访问日志）"
+// @Success 200 {object} responses.Response "成功响应"
+// @Router /build/notify/jenkins [post]
+func (h *CIHandler) Jenkins(c *gin.Context) {
+	if !h.cfg.Jenkins.Enabled {
+		responses.ErrorWithDetail(c, responses.CodeNotFound, "Jenkins 构建通知未启用", "ci.jenkins.enabled=false")
+		return
+	}
+	payload, err := c.GetRawData()
+	if err != nil {
+		responses.ErrorWithDetail(c, responses.CodeBadRequest, "读取请求体失败", err.Error())
+		return
+	}
+
+	build, err := ci.ParseJenkins(payload, h.cfg.Jenkins.Phase)
+	if err != nil {
+		h.process(c, "jenkins", nil, err)
+		return
+	}
+	namespace, name, _ := strings.Cut(build.Pipeline.Repo, "/")
+	if err := h.repositoryService.AuthenticateCI(namespace, name, c.GetHeader("X-Webhook-Token")); err != nil {
+		logger.Warn("Jenkins 构建通知校验失败", zap.String("repo", build.Pipeline.Repo), zap.Error(err))
+		responses.Error(c, err)
+		return
+	}
+
+	req, err := build.Notify(h.rules)
+	h.process(c, "jenkins", req, err)
+}
+
+// Harbor 接收 Harbor 镜像推送/扫描通知
+// @Summary 接收 Harbor 镜像推送通知（PUSH_ARTIFACT）与扫描结果（SCANNING_COMPLETED / SCANNING_FAILED）
+// @Description 推送：已有构建（镜像地址 + tag 一致）标记镜像可用；开启 ci.harbor.create_builds 时为没有构建的 tag 按该镜像地址历史构建创建构建
+// @Description 扫描：记录各级别漏洞数，供项目漏洞门禁（vuln_policy）使用
+// @Tags Build
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Webhook 策略的 Auth Header（须与 ci.harbor.auth_header 一致）"
+// @Success 200 {object} responses.Response{data=dto.ImagePushResult} "成功响应（扫描事件返回 dto.ImageScanResult）"
+// @Router /build/notify/harbor [post]
+func (h *CIHandler) Harbor(c *gin.Context) {
+	if !h.cfg.Harbor.Enabled {
+		responses.ErrorWithDetail(c, responses.CodeNotFound, "Harbor 镜像推送通知未启用", "ci.harbor.enabled=false")
+		return
+	}
+	if !verifyWebhookToken(c, "ci.harbor.auth_header", h.cfg.Harbor.AuthHeader, c.GetHeader("Authorization")) {
+		return
+	}
+	payload, err := c.GetRawData()
+	if err != nil {
+		responses.ErrorWithDetail(c, responses.CodeBadRequest, "读取请求体失败", err.Error())
+		return
+	}
+
+	event, err := ci.ParseHarbor(payload)
+	if errors.Is(err, ci.ErrIgnored) {
+		responses.Success(c, gin.H{"message": "事件无需处理，已忽略", "status": "ignored"})
+		return
+	}
+	if err != nil {
+		logger.Warn("Harbor 通知解析失败", zap.Error(err))
+		responses.ErrorWithDetail(c, responses.CodeBadRequest, "镜像推送通知解析失败", err.Error())
+		return
+	}
+
+	if len(event.Scans) > 0 {
+		result, err := h.imageScanService.Record("harbor", event.Scans)
+		if err != nil {
+			responses.Error(c, err)
+			return
+		}
+		responses.Success(c, result)
+		return
+	}
+	result, err := h.buildService.ProcessImagePush(&dto.ImagePushRequest{
+		Source:       "harbor",
+		Artifacts:    event.Pushes,
+		CreateBuilds: h.cfg.Harbor.CreateBuilds,
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, result)
+}
+
+// Trivy 接收 CI 上报的 Trivy 镜像扫描报告
+// @Summary 上报 Trivy 镜像扫描报告（trivy image --format json 输出）
+// @Description ArtifactName 需为带 tag 的镜像地址；记录各级别漏洞数（同一镜像 tag 覆盖为最近一次），供项目漏洞门禁（vuln_policy）使用；校验请求头 X-Webhook-Token（须配置 ci.trivy.token）
+// @Tags Build
+// @Accept json
+// @Produce json
+// @Param X-Webhook-Token header string true "上报 token"
+// @Success 200 {object} responses.Response{data=dto.ImageScanResult} "成功响应"
+// @Router /build/notify/trivy [post]
+func (h *CIHandler) Trivy(c *gin.Context) {
+	if !h.cfg.Trivy.Enabled {
+		responses.ErrorWithDetail(c, responses.CodeNotFound, "Trivy 扫描报告上报未启用", "ci.trivy.enabled=false")
+		return
+	}
+	if !verifyWebhookToken(c, "ci.trivy.token", h.cfg.Trivy.Token, c.GetHeader("X-Webhook-Token")) {
+		return
+	}
+	payload, err := c.GetRawData()
+	if err != nil {
+		responses.ErrorWithDetail(c, responses.CodeBadRequest, "读取请求体失败", err.Error())
+		return
+	}
+
+	report, err := ci.ParseTrivy(payload)
+	if err != nil {
+		logger.Warn("Trivy 扫描报告解析失败", zap.Error(err))
+		responses.ErrorWithDetail(c, responses.CodeBadRequest, "扫描报告解析失败", err.Error())
+		return
+	}
+	result, err := h.imageScanService.Record("trivy", []dto.ImageScanReport{*report})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, result)
+}
+
+// notify 按规则将流水线转换为构建通知并处理；无需处理的事件返回成功
+func (h *CIHandler) notify(c *gin.Context, provider string, pipeline *ci.Pipeline, err error) {
+	var req *dto.BuildNotifyRequest
+	if err == nil {
+		req, err = h.rules.Notify(pipeline)
+	}
+	h.process(c, provider, req, err)
+}
+
+// process 校验并处理转换后的构建通知；无需处理的事件返回成功
+func (h *CIHandler) process(c *gin.Context, provider string, req *dto.BuildNotifyRequest, err error) {
+	if errors.Is(err, ci.ErrIgnored) {
+		responses.Success(c, gin.H{"message": "事件无需处理，已忽略", "status": "ignored"})
+		return
+	}
+	if err != nil {
+		logger.Warn("CI 构建通知解析失败", zap.String("provider", provider), zap.Error(err))
+		responses.ErrorWithDetail(c, responses.CodeBadRequest, "构建通知解析失败", err.Error())
+		return
+	}
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		logger.Warn("CI 构建通知校验失败", zap.String("provider", provider), zap.String("repo", req.Repo), zap.Error(err))
+		responses.ErrorWithDetail(c, responses.CodeBadRequest, "构建通知校验失败", utils.FormatValidationError(err))
+		return
+	}
+
+	processBuildNotify(c, h.buildService, req)
+}
@@ -152,3 +152,37 @@ func (h *ClusterHandler) Delete(c *gin.Context) {
 
 	responses.Success(c, gin.H{"message": "删除成功"})
 }
+
+// Preflight 集群连通性预检
+// @Summary 集群连通性预检
+// @Description 校验 kubeconfig 认证、namespace 存在性、chart repo 可达性及镜像拉取（dry-run），返回逐项检查报告
+// @Tags 集群管理
+// @Accept json
+// @Produce json
+// @Param id path int true "集群ID"
+// @Param request body dto.ClusterPreflightRequest false "预检请求"
+// @Success 200 {object} dto.ClusterPreflightResponse
+// @Router /api/v1/clusters/{id}/preflight [post]
+func (h *ClusterHandler) Preflight(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		responses.ErrorWithCode(c, 400, "无效的集群ID")
+		return
+	}
+
+	var req dto.ClusterPreflightRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			responses.ErrorWithCode(c, 400, "请求参数错误: "+err.Error())
+			return
+		}
+	}
+
+	resp, err := h.clusterService.Preflight(c.Request.Context(), id, &req)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+
+	responses.Success(c, resp)
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"devops-cd/internal/dto"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
+	"devops-cd/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConsistencyHandler struct {
+	svc service.ConsistencyService
+}
+
+func NewConsistencyHandler(svc service.ConsistencyService) *ConsistencyHandler {
+	return &ConsistencyHandler{svc: svc}
+}
+
+// Check 数据一致性检查
+// @Summary 数据一致性检查（孤儿记录、悬挂的 deployed_tag、发布状态不一致）
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} responses.Response{data=dto.ConsistencyReport}
+// @Router /api/v1/admin/consistency [get]
+func (h *ConsistencyHandler) Check(c *gin.Context) {
+	report, err := h.svc.Check(c.Request.Context())
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, report)
+}
+
+// Repair 数据一致性修复
+// @Summary 数据一致性修复（默认 dry_run，需显式传 dry_run=false 才会修改数据）
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body dto.ConsistencyRepairRequest true "修复请求"
+// @Success 200 {object} responses.Response{data=dto.ConsistencyRepairResult}
+// @Router /api/v1/admin/consistency/repair [post]
+func (h *ConsistencyHandler) Repair(c *gin.Context) {
+	var req dto.ConsistencyRepairRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	result, err := h.svc.Repair(c.Request.Context(), &req, c.GetString("username"))
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, result)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+
+	"devops-cd/internal/core"
+	"devops-cd/pkg/responses"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CoreHandler struct {
+	coreEngine *core.CoreEngine
+}
+
+func NewCoreHandler(coreEngine *core.CoreEngine) *CoreHandler {
+	return &CoreHandler{coreEngine: coreEngine}
+}
+
+// Status 核心引擎运行状态
+// @Summary 核心引擎运行状态（主节点、队列深度、上次扫描耗时、各状态机处理统计，本副本进程启动以来）
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} responses.Response{data=core.EngineStatus}
+// @Router /api/v1/admin/core [get]
+func (h *CoreHandler) Status(c *gin.Context) {
+	status, err := h.coreEngine.Status(c.Request.Context())
+	if err != nil {
+		responses.ErrorWithCode(c, http.StatusInternalServerError, "查询引擎状态失败: "+err.Error())
+		return
+	}
+	responses.Success(c, status)
+}
+
+// Scan 立即执行一轮扫描
+// @Summary 立即执行一轮扫描并唤醒进行中的批次处理（仅主节点）
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} responses.Response{data=core.EngineStatus}
+// @Failure 409 {object} responses.Response
+// @Router /api/v1/admin/core/scan [post]
+func (h *CoreHandler) Scan(c *gin.Context) {
+	// 引擎未运行或不是主节点（ErrEngineNotRunning / ErrNotLeader）
+	if err := h.coreEngine.ForceScan(); err != nil {
+		responses.ErrorWithCode(c, http.StatusConflict, err.Error())
+		return
+	}
+	h.Status(c)
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DashboardHandler 首页汇总处理器
+type DashboardHandler struct {
+	batchService *service.BatchService
+}
+
+// NewDashboardHandler 创建首页汇总处理器
+func NewDashboardHandler(batchService *service.BatchService) *DashboardHandler {
+	return &DashboardHandler{batchService: batchService}
+}
+
+// Get 首页汇总
+// @Summary 首页汇总：进行中的批次（按状态）、进行中的部署（按集群）、最近 7 天失败的部署、待我审批的批次
+// @Tags 报表
+// @Produce json
+// @Success 200 {object} responses.Response{data=dto.DashboardResponse}
+// @Router /api/v1/dashboard [get]
+func (h *DashboardHandler) Get(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	username := c.GetString("username")
+	resp, err := h.batchService.Dashboard(username, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
@@ -23,19 +23,23 @@ func NewDeploymentHandler(batchService *service.BatchService) *DeploymentHandler
 
 // Retry 手动重试 deployment（仅 failed 可重试）
 // @Summary 手动重试 deployment
+// @Description 置回 pending 后按 core.deploy.retry_backoff 退避执行；指定 clusters 时只重试同一发布应用、同环境下这些集群的 failed deployment
 // @Tags Deployment
 // @Accept json
 // @Produce json
 // @Param id path int true "Deployment ID"
 // @Param body body dto.RetryDeploymentRequest true "重试请求"
-// @Success 200 {object} responses.Response{data=map[string]string}
+// @Success 200 {object} responses.Response{data=dto.RetryDeploymentResponse}
 // @Router /api/v1/deployment/{id}/retry [post]
-func (h *DeploymentHandler) Retry(c *gin.Context) {
+func (h *DeploymentHandler) Retry(c *gin.Context, canAccess func(username string, projectId int64, env string) bool) {
 	deploymentID, ok := parseIDParam(c.Param("id"))
 	if !ok {
 		responses.ErrorWithDetail(c, http.StatusBadRequest, "deployment_id 无效", c.Param("id"))
 		return
 	}
+	if !h.checkDeploymentAccess(c, canAccess, deploymentID) {
+		return
+	}
 
 	var req dto.RetryDeploymentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -43,11 +47,315 @@ func (h *DeploymentHandler) Retry(c *gin.Context) {
 		return
 	}
 
-	if err := h.batchService.RetryDeployment(deploymentID, req.Operator, req.Reason); err != nil {
+	ids, err := h.batchService.RetryDeployment(deploymentID, req.Clusters, req.Operator, req.Reason)
+	if err != nil {
 		logger.Error("手动重试 deployment 失败", zap.Int64("deployment_id", deploymentID), zap.Error(err))
 		responses.ErrorWithCode(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	responses.Success(c, gin.H{"message": "已触发重试"})
+	responses.Success(c, dto.RetryDeploymentResponse{DeploymentIDs: ids, Message: "已触发重试"})
+}
+
+// RedeployConfigChart 单独重新部署 config chart（仅 config 类型且已结束的 deployment）
+// @Summary 重新部署 config chart
+// @Tags Deployment
+// @Accept json
+// @Produce json
+// @Param id path int true "Deployment ID"
+// @Param body body dto.RedeployConfigChartRequest true "重新部署请求"
+// @Success 200 {object} responses.Response{data=dto.DeploymentResponse}
+// @Router /api/v1/deployment/{id}/redeploy [post]
+func (h *DeploymentHandler) RedeployConfigChart(c *gin.Context, canAccess func(username string, projectId int64, env string) bool) {
+	deploymentID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "deployment_id 无效", c.Param("id"))
+		return
+	}
+	if !h.checkDeploymentAccess(c, canAccess, deploymentID) {
+		return
+	}
+
+	var req dto.RedeployConfigChartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	resp, err := h.batchService.RedeployConfigChart(deploymentID, req.Operator, req.Reason)
+	if err != nil {
+		logger.Error("重新部署 config chart 失败", zap.Int64("deployment_id", deploymentID), zap.Error(err))
+		responses.ErrorWithCode(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	responses.Success(c, resp)
+}
+
+// GetDiff 查询 prod 部署前计算的 manifest diff（对比集群中当前 release revision）
+// @Summary 查询部署 diff
+// @Tags Deployment
+// @Produce json
+// @Param id path int true "Deployment ID"
+// @Success 200 {object} responses.Response{data=dto.DeploymentDiffResponse}
+// @Router /api/v1/deployment/{id}/diff [get]
+func (h *DeploymentHandler) GetDiff(c *gin.Context, canAccess func(username string, projectId int64, env string) bool) {
+	deploymentID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "deployment_id 无效", c.Param("id"))
+		return
+	}
+	if !h.checkDeploymentAccess(c, canAccess, deploymentID) {
+		return
+	}
+
+	resp, err := h.batchService.GetDeploymentDiff(deploymentID)
+	if err != nil {
+		responses.ErrorWithCode(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	responses.Success(c, resp)
+}
+
+// PreviewDiff 按需计算部署 diff：渲染待部署的 chart+values 并与集群中当前 release 对比（不保存，可在审批前预览）
+// @Summary 预览部署 diff
+// @Tags Deployment
+// @Produce json
+// @Param id path int true "Deployment ID"
+// @Success 200 {object} responses.Response{data=dto.DeploymentDiffPreviewResponse}
+// @Router /api/v1/deployment/{id}/diff/preview [get]
+func (h *DeploymentHandler) PreviewDiff(c *gin.Context, canAccess func(username string, projectId int64, env string) bool) {
+	deploymentID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "deployment_id 无效", c.Param("id"))
+		return
+	}
+	if !h.checkDeploymentAccess(c, canAccess, deploymentID) {
+		return
+	}
+
+	resp, err := h.batchService.PreviewDeploymentDiff(c.Request.Context(), deploymentID)
+	if err != nil {
+		logger.Warn("预览部署 diff 失败", zap.Int64("deployment_id", deploymentID), zap.Error(err))
+		responses.ErrorWithCode(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	responses.Success(c, resp)
+}
+
+// AckDiff 确认 prod 部署 diff（diff 涉及 PDB/PVC/CRD 且开启确认时，确认后才会执行部署）
+// @Summary 确认部署 diff
+// @Tags Deployment
+// @Accept json
+// @Produce json
+// @Param id path int true "Deployment ID"
+// @Param body body dto.AckDeploymentDiffRequest true "确认请求"
+// @Success 200 {object} responses.Response{data=dto.DeploymentDiffResponse}
+// @Router /api/v1/deployment/{id}/diff/ack [post]
+func (h *DeploymentHandler) AckDiff(c *gin.Context, canAccess func(username string, projectId int64, env string) bool) {
+	deploymentID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "deployment_id 无效", c.Param("id"))
+		return
+	}
+	if !h.checkDeploymentAccess(c, canAccess, deploymentID) {
+		return
+	}
+
+	var req dto.AckDeploymentDiffRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	resp, err := h.batchService.AckDeploymentDiff(deploymentID, req.Digest, c.GetString("username"))
+	if err != nil {
+		logger.Error("确认部署 diff 失败", zap.Int64("deployment_id", deploymentID), zap.Error(err))
+		responses.ErrorWithCode(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	responses.Success(c, resp)
+}
+
+// Timeline 查询 Deployment 执行时间线
+// @Summary Deployment 时间线
+// @Description 按时间正序返回状态变更、chart 渲染完成、开始部署、就绪检查原因变化等步骤
+// @Tags Deployment
+// @Produce json
+// @Param id path int true "Deployment ID"
+// @Success 200 {object} responses.Response{data=dto.DeploymentTimelineResponse}
+// @Router /api/v1/deployment/{id}/timeline [get]
+func (h *DeploymentHandler) Timeline(c *gin.Context, canAccess func(username string, projectId int64, env string) bool) {
+	deploymentID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "deployment_id 无效", c.Param("id"))
+		return
+	}
+	if !h.checkDeploymentAccess(c, canAccess, deploymentID) {
+		return
+	}
+
+	resp, err := h.batchService.GetDeploymentTimeline(deploymentID)
+	if err != nil {
+		responses.ErrorWithCode(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	responses.Success(c, resp)
+}
+
+// ConfigChartDrift 核对 config chart 版本与 app chart 期望是否一致
+// @Summary config chart 版本核对
+// @Tags Deployment
+// @Produce json
+// @Param app_id query int true "应用ID"
+// @Param env query string true "环境(pre/prod)"
+// @Success 200 {object} responses.Response{data=dto.ConfigChartDriftResponse}
+// @Router /api/v1/deployment/config_chart/drift [get]
+func (h *DeploymentHandler) ConfigChartDrift(c *gin.Context) {
+	var query dto.ConfigChartDriftQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	resp, err := h.batchService.CheckConfigChartDrift(c.Request.Context(), query.AppID, query.Env)
+	if err != nil {
+		logger.Error("核对 config chart 版本失败", zap.Int64("app_id", query.AppID), zap.String("env", query.Env), zap.Error(err))
+		responses.Error(c, err)
+		return
+	}
+
+	responses.Success(c, resp)
+}
+
+// Logs 查询部署 workload 的 pod 日志；follow=true 时以 SSE 持续推送（event: log / error / end）
+// @Summary 部署 pod 日志
+// @Tags Deployment
+// @Produce json
+// @Produce text/event-stream
+// @Param id path int true "Deployment ID"
+// @Param pod query string false "pod 名称"
+// @Param container query string false "容器名称"
+// @Param tail_lines query int false "每个容器返回的行数（默认 200）"
+// @Param since_seconds query int false "仅返回最近 N 秒的日志"
+// @Param previous query bool false "读取上一次容器的日志"
+// @Param follow query bool false "以 SSE 持续推送"
+// @Success 200 {object} responses.Response{data=dto.DeploymentLogsResponse}
+// @Router /api/v1/deployment/{id}/logs [get]
+func (h *DeploymentHandler) Logs(c *gin.Context, canAccess func(username string, projectId int64, env string) bool) {
+	deploymentID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "deployment_id 无效", c.Param("id"))
+		return
+	}
+	if !h.checkDeploymentAccess(c, canAccess, deploymentID) {
+		return
+	}
+
+	var query dto.DeploymentLogsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	if query.Follow {
+		err := h.batchService.StreamDeploymentLogs(c.Request.Context(), deploymentID, &query, func(line *dto.DeploymentLogLine) {
+			sendSSE(c, "log", line)
+		})
+		finishSSE(c, deploymentID, err)
+		return
+	}
+
+	resp, err := h.batchService.GetDeploymentLogs(c.Request.Context(), deploymentID, &query)
+	if err != nil {
+		responses.ErrorWithCode(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	responses.Success(c, resp)
+}
+
+// Events 查询部署 workload 相关的 Kubernetes 事件；follow=true 时以 SSE 持续推送（event: event / error / end）
+// @Summary 部署 Kubernetes 事件
+// @Tags Deployment
+// @Produce json
+// @Produce text/event-stream
+// @Param id path int true "Deployment ID"
+// @Param follow query bool false "以 SSE 持续推送"
+// @Success 200 {object} responses.Response{data=dto.DeploymentEventsResponse}
+// @Router /api/v1/deployment/{id}/events [get]
+func (h *DeploymentHandler) Events(c *gin.Context, canAccess func(username string, projectId int64, env string) bool) {
+	deploymentID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "deployment_id 无效", c.Param("id"))
+		return
+	}
+	if !h.checkDeploymentAccess(c, canAccess, deploymentID) {
+		return
+	}
+
+	var query dto.DeploymentEventsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	if query.Follow {
+		err := h.batchService.StreamDeploymentEvents(c.Request.Context(), deploymentID, func(event *dto.DeploymentEvent) {
+			sendSSE(c, "event", event)
+		})
+		finishSSE(c, deploymentID, err)
+		return
+	}
+
+	resp, err := h.batchService.GetDeploymentEvents(c.Request.Context(), deploymentID)
+	if err != nil {
+		responses.ErrorWithCode(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	responses.Success(c, resp)
+}
+
+// checkDeploymentAccess 按 Deployment 所属项目与环境校验权限（prod 需要生产环境操作权限），无权限时直接返回 403
+func (h *DeploymentHandler) checkDeploymentAccess(c *gin.Context, canAccess func(username string, projectId int64, env string) bool, deploymentID int64) bool {
+	projectID, env, err := h.batchService.DeploymentScope(deploymentID)
+	if err != nil {
+		responses.Error(c, err)
+		return false
+	}
+	if !canAccess(c.GetString("username"), projectID, env) {
+		responses.Error(c, responses.ErrForbidden)
+		return false
+	}
+	return true
+}
+
+func sendSSE(c *gin.Context, event string, data any) {
+	if !c.Writer.Written() {
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+	}
+	c.SSEvent(event, data)
+	c.Writer.Flush()
+}
+
+// finishSSE 结束 SSE 流；尚未推送任何数据时（如 deployment 未开始部署）按普通错误响应返回
+func finishSSE(c *gin.Context, deploymentID int64, err error) {
+	if err != nil && !c.Writer.Written() {
+		responses.ErrorWithCode(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		logger.Warn("部署日志/事件推送中断", zap.Int64("deployment_id", deploymentID), zap.Error(err))
+		sendSSE(c, "error", gin.H{"message": err.Error()})
+	}
+	if c.Request.Context().Err() == nil {
+		sendSSE(c, "end", gin.H{"deployment_id": deploymentID})
+	}
 }
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"devops-cd/internal/dto"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
+	"devops-cd/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EnginePauseHandler struct {
+	svc service.EnginePauseService
+}
+
+func NewEnginePauseHandler(svc service.EnginePauseService) *EnginePauseHandler {
+	return &EnginePauseHandler{svc: svc}
+}
+
+// Pause 暂停项目/批次的引擎处理
+// @Summary 暂停核心引擎对项目/批次/集群的处理（不影响其他批次/集群）
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateEnginePauseRequest true "暂停请求"
+// @Success 200 {object} responses.Response{data=dto.EnginePauseResponse}
+// @Router /api/v1/admin/engine/pauses [post]
+func (h *EnginePauseHandler) Pause(c *gin.Context) {
+	var req dto.CreateEnginePauseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	resp, err := h.svc.Pause(&req, c.GetString("username"))
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// Resume 恢复引擎处理
+// @Summary 恢复核心引擎对项目/批次/集群的处理
+// @Tags Admin
+// @Produce json
+// @Param id path int true "暂停记录ID"
+// @Success 200 {object} responses.Response{data=dto.EnginePauseResponse}
+// @Router /api/v1/admin/engine/pauses/{id}/resume [post]
+func (h *EnginePauseHandler) Resume(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	resp, err := h.svc.Resume(id, c.GetString("username"))
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// List 暂停记录列表
+// @Summary 暂停记录列表（active_only=true 仅返回生效中的暂停）
+// @Tags Admin
+// @Produce json
+// @Param active_only query bool false "仅生效中"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PageResponse
+// @Router /api/v1/admin/engine/pauses [get]
+func (h *EnginePauseHandler) List(c *gin.Context) {
+	var req dto.EnginePauseListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+	list, total, err := h.svc.List(&req)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, dto.NewPageResponse(list, total, req.Page, req.PageSize))
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"devops-cd/pkg/responses"
+	"github.com/gin-gonic/gin"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/utils"
+)
+
+// ImageScanHandler 镜像漏洞扫描结果处理器
+type ImageScanHandler struct {
+	imageScanService service.ImageScanService
+}
+
+// NewImageScanHandler 创建镜像扫描结果处理器
+func NewImageScanHandler(imageScanService service.ImageScanService) *ImageScanHandler {
+	return &ImageScanHandler{imageScanService: imageScanService}
+}
+
+// List 获取镜像扫描结果列表
+// @Summary 获取镜像漏洞扫描结果列表（同一镜像 tag 仅保留最近一次）
+// @Tags Build
+// @Produce json
+// @Param page query int false "页码"
+// @Param page_size query int false "每页数量"
+// @Param build_id query int false "构建ID（查询该构建镜像的扫描结果）"
+// @Param image query string false "镜像地址（不含 tag）"
+// @Param tag query string false "镜像 tag"
+// @Success 200 {object} responses.Response{data=dto.PageResponse}
+// @Router /api/v1/image_scans [get]
+func (h *ImageScanHandler) List(c *gin.Context) {
+	var query dto.ImageScanListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		responses.ErrorWithDetail(c, responses.CodeBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	data, total, err := h.imageScanService.List(&query)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, dto.NewPageResponse(data, total, query.GetPage(), query.GetPageSize()))
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"devops-cd/internal/dto"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
+	"devops-cd/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JobHandler struct {
+	svc *service.JobService
+}
+
+func NewJobHandler(svc *service.JobService) *JobHandler {
+	return &JobHandler{svc: svc}
+}
+
+// List 异步任务列表
+// @Summary 异步任务列表（Deployment 执行等，按状态/类型/批次/Deployment 过滤）
+// @Tags Admin
+// @Produce json
+// @Param status query string false "状态" Enums(pending, running, succeeded, dead)
+// @Param type query string false "任务类型" example(deployment.execute)
+// @Param batch_id query int false "批次ID"
+// @Param deployment_id query int false "Deployment ID"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PageResponse
+// @Router /api/v1/jobs [get]
+func (h *JobHandler) List(c *gin.Context) {
+	var req dto.JobListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+	list, total, err := h.svc.List(&req)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, dto.NewPageResponse(list, total, req.Page, req.PageSize))
+}
+
+// Get 异步任务详情
+// @Summary 异步任务详情
+// @Tags Admin
+// @Produce json
+// @Param id path int true "任务ID"
+// @Success 200 {object} responses.Response{data=dto.JobResponse}
+// @Router /api/v1/jobs/{id} [get]
+func (h *JobHandler) Get(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	resp, err := h.svc.Get(id)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// Retry 死信任务重新入队
+// @Summary 将死信任务重新入队（重置执行次数）
+// @Tags Admin
+// @Produce json
+// @Param id path int true "任务ID"
+// @Success 200 {object} responses.Response{data=dto.JobResponse}
+// @Router /api/v1/jobs/{id}/retry [post]
+func (h *JobHandler) Retry(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	resp, err := h.svc.Retry(id, c.GetString("username"))
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
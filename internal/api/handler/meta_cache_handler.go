@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"devops-cd/internal/core"
+	"devops-cd/pkg/responses"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MetaCacheHandler struct {
+	coreEngine *core.CoreEngine
+}
+
+func NewMetaCacheHandler(coreEngine *core.CoreEngine) *MetaCacheHandler {
+	return &MetaCacheHandler{coreEngine: coreEngine}
+}
+
+// Stats 元数据缓存命中统计
+// @Summary 应用/项目环境配置/集群元数据缓存命中统计（进程启动或上次清空以来）
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} responses.Response{data=metacache.Stats}
+// @Router /api/v1/admin/engine/meta-cache [get]
+func (h *MetaCacheHandler) Stats(c *gin.Context) {
+	responses.Success(c, h.coreEngine.MetaCacheStats())
+}
+
+// Flush 清空元数据缓存
+// @Summary 清空元数据缓存并重置统计（直接修改数据库后使用）
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} responses.Response{data=metacache.Stats}
+// @Router /api/v1/admin/engine/meta-cache/flush [post]
+func (h *MetaCacheHandler) Flush(c *gin.Context) {
+	h.coreEngine.FlushMetaCache()
+	responses.Success(c, h.coreEngine.MetaCacheStats())
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"devops-cd/internal/dto"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
+	"devops-cd/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationRuleHandler 通知路由规则处理器
+type NotificationRuleHandler struct {
+	svc service.NotificationRuleService
+}
+
+func NewNotificationRuleHandler(svc service.NotificationRuleService) *NotificationRuleHandler {
+	return &NotificationRuleHandler{svc: svc}
+}
+
+// Options 可选通知类型、严重级别与模板字段
+// @Summary 通知路由规则可选项
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} responses.Response{data=dto.NotificationRuleOptionsResponse}
+// @Router /api/v1/admin/notification_rules/options [get]
+func (h *NotificationRuleHandler) Options(c *gin.Context) {
+	responses.Success(c, service.NotificationRuleOptions())
+}
+
+// Create 创建规则
+// @Summary 创建通知路由规则
+// @Description 匹配到规则的通知发送到规则的 Lark Webhook（不再发送到默认渠道）；模板为 Go text/template，如 {{.AppName}} {{.Message}}
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateNotificationRuleRequest true "创建请求"
+// @Success 200 {object} responses.Response{data=dto.NotificationRuleResponse}
+// @Router /api/v1/admin/notification_rules [post]
+func (h *NotificationRuleHandler) Create(c *gin.Context) {
+	var req dto.CreateNotificationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	resp, err := h.svc.Create(&req, c.GetString("username"))
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// Update 更新规则
+// @Summary 更新通知路由规则
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "规则ID"
+// @Param request body dto.UpdateNotificationRuleRequest true "更新请求"
+// @Success 200 {object} responses.Response{data=dto.NotificationRuleResponse}
+// @Router /api/v1/admin/notification_rules/{id} [put]
+func (h *NotificationRuleHandler) Update(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	var req dto.UpdateNotificationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	resp, err := h.svc.Update(id, &req, c.GetString("username"))
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// Delete 删除规则
+// @Summary 删除通知路由规则
+// @Tags Admin
+// @Produce json
+// @Param id path int true "规则ID"
+// @Success 200 {object} responses.Response
+// @Router /api/v1/admin/notification_rules/{id} [delete]
+func (h *NotificationRuleHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	if err := h.svc.Delete(id); err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, nil)
+}
+
+// Get 规则详情
+// @Summary 通知路由规则详情
+// @Tags Admin
+// @Produce json
+// @Param id path int true "规则ID"
+// @Success 200 {object} responses.Response{data=dto.NotificationRuleResponse}
+// @Router /api/v1/admin/notification_rules/{id} [get]
+func (h *NotificationRuleHandler) Get(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	resp, err := h.svc.Get(id)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// List 规则列表
+// @Summary 通知路由规则列表
+// @Tags Admin
+// @Produce json
+// @Param project_id query int false "项目ID"
+// @Param team_id query int false "团队ID"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PageResponse
+// @Router /api/v1/admin/notification_rules [get]
+func (h *NotificationRuleHandler) List(c *gin.Context) {
+	var req dto.NotificationRuleListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+	list, total, err := h.svc.List(&req)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, dto.NewPageResponse(list, total, req.Page, req.PageSize))
+}
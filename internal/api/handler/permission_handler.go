@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/pkg/auth"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
+	"devops-cd/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/samber/lo"
+)
+
+type PermissionHandler struct {
+	authz service.AuthorizationService
+}
+
+func NewPermissionHandler(authz service.AuthorizationService) *PermissionHandler {
+	return &PermissionHandler{authz: authz}
+}
+
+// Mine 当前用户在项目下的有效权限（前端据此控制 prod 操作按钮等）
+// @Summary 当前用户权限
+// @Tags 用户
+// @Produce json
+// @Param project_id query int true "项目ID"
+// @Success 200 {object} responses.Response{data=dto.PermissionsResponse}
+// @Security BearerAuth
+// @Router /api/v1/permissions [get]
+func (h *PermissionHandler) Mine(c *gin.Context) {
+	var query dto.PermissionsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	roles, perms := h.authz.ProjectPermissions(c.GetString("username"), c.GetString("auth_type"), query.ProjectID)
+	responses.Success(c, dto.PermissionsResponse{
+		ProjectID:    query.ProjectID,
+		Roles:        roles,
+		Permissions:  lo.Map(perms, func(p auth.Permission, _ int) string { return string(p) }),
+		ProdOperator: lo.Contains(perms, auth.PermProdOperate),
+	})
+}
@@ -111,18 +111,23 @@ func (h *ProjectHandler) List(c *gin.Context) {
 
 // Update 更新项目
 // @Summary 更新项目
+// @Description 修改审批策略、双人原则、漏洞门禁、自动回滚、自动建批、Jira 关联需要该项目的 project:policy:update 权限
 // @Tags Project
 // @Accept json
 // @Produce json
 // @Param request body dto.UpdateProjectRequest true "更新项目请求"
 // @Success 200 {object} responses.Response{data=dto.ProjectResponse}
 // @Router /api/v1/projects [put]
-func (h *ProjectHandler) Update(c *gin.Context) {
+func (h *ProjectHandler) Update(c *gin.Context, canChangePolicy func(username string, projectId int64) bool) {
 	var req dto.UpdateProjectRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", err.Error())
 		return
 	}
+	if req.ChangesPolicy() && !canChangePolicy(c.GetString("username"), req.ID) {
+		responses.Error(c, responses.ErrForbidden)
+		return
+	}
 
 	project, err := h.projectService.Update(req.ID, &req)
 	if err != nil {
@@ -11,6 +11,7 @@ import (
 
 	"devops-cd/internal/dto"
 	"devops-cd/internal/service"
+	"devops-cd/pkg/constants"
 	"devops-cd/pkg/utils"
 )
 
@@ -120,6 +121,43 @@ func (h *ReleaseAppHandler) UpdateDependencies(c *gin.Context) {
 	responses.Success(c, resp)
 }
 
+// UpdateValuesOverride 设置发布应用 values 覆盖
+// @Summary 设置发布应用 values 覆盖
+// @Description 封板前为发布应用设置一次性的 values 覆盖（YAML/JSON object），部署时在 app_chart values 最后合并；内容为空表示清除
+// @Tags ReleaseApp
+// @Accept json
+// @Produce json
+// @Param id path int true "ReleaseApp ID"
+// @Param body body dto.UpdateReleaseValuesOverrideRequest true "values 覆盖"
+// @Success 200 {object} responses.Response{data=dto.ReleaseValuesOverrideResponse}
+// @Security BearerAuth
+// @Router /api/v1/release_app/{id}/values_override [put]
+func (h *ReleaseAppHandler) UpdateValuesOverride(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	releaseID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "发布应用ID无效", c.Param("id"))
+		return
+	}
+
+	var req dto.UpdateReleaseValuesOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	req.ReleaseAppID = releaseID
+	req.Operator = c.GetString("username")
+
+	resp, err := h.batchService.UpdateReleaseValuesOverride(&req, func(projectID int64) bool {
+		return canAccess(req.Operator, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+
+	responses.Success(c, resp)
+}
+
 // SwitchVersion 切换版本(更新版本)
 // @Summary 切换版本
 // @Tags ReleaseApp
@@ -128,13 +166,23 @@ func (h *ReleaseAppHandler) UpdateDependencies(c *gin.Context) {
 // @Param request body dto.SwitchVersionRequest true "切换请求"
 // @Success 200 {object} responses.Response{data=string}
 // @Router /api/v1/release_app/trigger_deploy [post]
-func (h *BatchHandler) SwitchVersion(c *gin.Context) {
+func (h *BatchHandler) SwitchVersion(c *gin.Context, canProdOperate func(username string, projectId int64) bool) {
 	var req dto.SwitchVersionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
 		return
 	}
 
+	// 跳过 Pre 的应用切换版本会直接触发 Prod 部署
+	projectID, prod, err := h.batchService.SwitchVersionScope(req.ReleaseAppID)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	if prod && !h.checkProdOperate(c, canProdOperate, projectID) {
+		return
+	}
+
 	resp, err := h.coreEngine.SwitchVersion(&req)
 	if err != nil {
 		responses.Error(c, err)
@@ -144,13 +192,24 @@ func (h *BatchHandler) SwitchVersion(c *gin.Context) {
 	responses.Success(c, resp)
 }
 
-func (h *BatchHandler) ManualDeploy(c *gin.Context) {
+func (h *BatchHandler) ManualDeploy(c *gin.Context, canProdOperate func(username string, projectId int64) bool) {
 	var req dto.ManualDeployRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
 		return
 	}
 
+	if req.Action == constants.ReleaseAppActionManualTriggerProd {
+		projectID, _, err := h.batchService.SwitchVersionScope(req.ReleaseAppID)
+		if err != nil {
+			responses.Error(c, err)
+			return
+		}
+		if !h.checkProdOperate(c, canProdOperate, projectID) {
+			return
+		}
+	}
+
 	resp, err := h.coreEngine.ManualDeploy(&req)
 	if err != nil {
 		responses.Error(c, err)
@@ -159,3 +218,146 @@ func (h *BatchHandler) ManualDeploy(c *gin.Context) {
 
 	responses.Success(c, resp)
 }
+
+// Rollback 回滚批次
+// @Summary 回滚批次（批次内已部署生产的应用回滚到发布前版本）
+// @Tags 批次管理
+// @Accept json
+// @Produce json
+// @Param request body dto.RollbackBatchRequest true "回滚请求"
+// @Success 200 {object} responses.Response{data=dto.RollbackResponse}
+// @Security BearerAuth
+// @Router /api/v1/batch/rollback [post]
+func (h *BatchHandler) Rollback(c *gin.Context, canProdOperate func(username string, projectId int64) bool) {
+	var req dto.RollbackBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	req.Operator = c.GetString("username")
+
+	projectID, err := h.batchService.BatchProjectID(req.BatchID)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	if !h.checkProdOperate(c, canProdOperate, projectID) {
+		return
+	}
+
+	resp, err := h.coreEngine.RollbackBatch(&req)
+	if err != nil {
+		if resp != nil {
+			responses.ErrorWithData(c, http.StatusBadRequest, err.Error(), resp)
+			return
+		}
+		responses.ErrorWithCode(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	responses.Success(c, resp)
+}
+
+// RollbackReleaseApp 回滚单个发布应用
+// @Summary 回滚发布应用到发布前版本
+// @Tags ReleaseApp
+// @Accept json
+// @Produce json
+// @Param request body dto.RollbackReleaseAppRequest true "回滚请求"
+// @Success 200 {object} responses.Response{data=dto.RollbackResponse}
+// @Security BearerAuth
+// @Router /api/v1/release_app/rollback [post]
+func (h *BatchHandler) RollbackReleaseApp(c *gin.Context, canProdOperate func(username string, projectId int64) bool) {
+	var req dto.RollbackReleaseAppRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	req.Operator = c.GetString("username")
+
+	projectID, _, err := h.batchService.SwitchVersionScope(req.ReleaseAppID)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	if !h.checkProdOperate(c, canProdOperate, projectID) {
+		return
+	}
+
+	resp, err := h.coreEngine.RollbackReleaseApp(&req)
+	if err != nil {
+		responses.ErrorWithCode(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	responses.Success(c, resp)
+}
+
+// RolloutReleaseApp 生产灰度 promote/abort
+// @Summary 生产灰度放行下一阶段/中止后续阶段
+// @Tags ReleaseApp
+// @Accept json
+// @Produce json
+// @Param request body dto.ReleaseAppRolloutRequest true "灰度操作请求"
+// @Success 200 {object} responses.Response
+// @Security BearerAuth
+// @Router /api/v1/release_app/rollout [post]
+func (h *BatchHandler) RolloutReleaseApp(c *gin.Context, canProdOperate func(username string, projectId int64) bool) {
+	var req dto.ReleaseAppRolloutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	req.Operator = c.GetString("username")
+
+	projectID, _, err := h.batchService.SwitchVersionScope(req.ReleaseAppID)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	if !h.checkProdOperate(c, canProdOperate, projectID) {
+		return
+	}
+
+	if err := h.coreEngine.RolloutReleaseApp(&req); err != nil {
+		responses.ErrorWithCode(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	responses.Success(c, nil)
+}
+
+// RedeployClusters 按集群重新部署发布应用
+// @Summary 按集群重新部署发布应用
+// @Description 为选中集群创建新的 Deployment（替代当前记录），其余集群不变；发布应用需处于该环境已完成/失败，批次处于该环境部署中
+// @Tags ReleaseApp
+// @Accept json
+// @Produce json
+// @Param request body dto.ReleaseAppRedeployRequest true "重新部署请求"
+// @Success 200 {object} responses.Response
+// @Security BearerAuth
+// @Router /api/v1/release_app/redeploy [post]
+func (h *BatchHandler) RedeployClusters(c *gin.Context, canProdOperate func(username string, projectId int64) bool) {
+	var req dto.ReleaseAppRedeployRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	req.Operator = c.GetString("username")
+
+	projectID, _, err := h.batchService.SwitchVersionScope(req.ReleaseAppID)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	if !h.checkProdOperate(c, canProdOperate, projectID) {
+		return
+	}
+
+	if err := h.coreEngine.RedeployReleaseAppClusters(&req); err != nil {
+		responses.ErrorWithCode(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	responses.Success(c, nil)
+}
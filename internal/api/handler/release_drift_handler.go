@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"net/http"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
+	"devops-cd/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReleaseDriftHandler 配置漂移处理器
+type ReleaseDriftHandler struct {
+	svc *service.ReleaseDriftService
+}
+
+// NewReleaseDriftHandler 创建配置漂移处理器
+func NewReleaseDriftHandler(svc *service.ReleaseDriftService) *ReleaseDriftHandler {
+	return &ReleaseDriftHandler{svc: svc}
+}
+
+// List 配置漂移列表
+// @Summary 项目的配置漂移检测结果
+// @Description 定时对比集群中 helm release（revision/values/manifest，可选资源对象）与最近一次部署写入的 release，每个应用/环境/集群/部署类型一条
+// @Tags 配置漂移
+// @Produce json
+// @Param project_id query int true "项目ID"
+// @Param app_id query int false "应用ID"
+// @Param env query string false "环境 pre/prod"
+// @Param status query string false "状态 ok/drift/missing/error"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PageResponse
+// @Security BearerAuth
+// @Router /api/v1/release_drifts [get]
+func (h *ReleaseDriftHandler) List(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	var req dto.ReleaseDriftListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+	username := c.GetString("username")
+	list, total, err := h.svc.List(&req, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, dto.NewPageResponse(list, total, req.Page, req.PageSize))
+}
+
+// Get 配置漂移详情
+// @Summary 配置漂移检测结果详情（含漂移项）
+// @Tags 配置漂移
+// @Produce json
+// @Param id path int true "配置漂移ID"
+// @Success 200 {object} responses.Response{data=dto.ReleaseDriftResponse}
+// @Security BearerAuth
+// @Router /api/v1/release_drifts/{id} [get]
+func (h *ReleaseDriftHandler) Get(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	username := c.GetString("username")
+	resp, err := h.svc.Get(id, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// Check 立即重新检测
+// @Summary 立即重新检测配置漂移
+// @Description 以最近一次写入 release 的 Deployment 为基准重新检测（只读取集群，不修改）；该集群存在进行中的部署时不允许
+// @Tags 配置漂移
+// @Produce json
+// @Param id path int true "配置漂移ID"
+// @Success 200 {object} responses.Response{data=dto.ReleaseDriftResponse}
+// @Security BearerAuth
+// @Router /api/v1/release_drifts/{id}/check [post]
+func (h *ReleaseDriftHandler) Check(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	username := c.GetString("username")
+	resp, err := h.svc.Check(c.Request.Context(), id, username, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"bytes"
+	"devops-cd/internal/dto"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
+	"devops-cd/pkg/utils"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReportHandler 报表处理器
+type ReportHandler struct {
+	batchService *service.BatchService
+}
+
+// NewReportHandler 创建报表处理器
+func NewReportHandler(batchService *service.BatchService) *ReportHandler {
+	return &ReportHandler{batchService: batchService}
+}
+
+// Quality 发布质量报表
+// @Summary 按项目汇总发布质量（变更失败率、故障、回滚、MTTR）及趋势
+// @Tags 报表
+// @Produce json
+// @Param project_id query int false "项目ID，为空时返回有权限的所有项目"
+// @Param start query string false "开始日期 YYYY-MM-DD，默认 90 天前"
+// @Param end query string false "结束日期 YYYY-MM-DD（含），默认今天"
+// @Param interval query string false "趋势粒度: week/month，默认 week"
+// @Success 200 {object} responses.Response{data=dto.QualityReportResponse}
+// @Router /api/v1/reports/quality [get]
+func (h *ReportHandler) Quality(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	var query dto.QualityReportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	username := c.GetString("username")
+	report, err := h.batchService.QualityReport(&query, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, report)
+}
+
+// Adoption 平台使用情况报表
+// @Summary 按项目/团队汇总平台使用情况（批次、应用接入、手动/自动化动作、审批时延）
+// @Tags 报表
+// @Produce json
+// @Param project_id query int false "项目ID，为空时返回有权限的所有项目"
+// @Param team_id query int false "团队ID，指定时仅返回该团队"
+// @Param start query string false "开始日期 YYYY-MM-DD，默认 90 天前"
+// @Param end query string false "结束日期 YYYY-MM-DD（含），默认今天"
+// @Success 200 {object} responses.Response{data=dto.AdoptionReportResponse}
+// @Router /api/v1/reports/adoption [get]
+func (h *ReportHandler) Adoption(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	var query dto.AdoptionReportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	username := c.GetString("username")
+	report, err := h.batchService.AdoptionReport(&query, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, report)
+}
+
+// Deployments 部署统计报表
+// @Summary 按项目/团队/应用汇总 DORA 部署指标（部署频率、交付时长、成功率、MTTR），支持导出 CSV
+// @Tags 报表
+// @Produce json
+// @Produce text/csv
+// @Param project_id query int false "项目ID，为空时返回有权限的所有项目"
+// @Param team_id query int false "团队ID，只统计该团队的应用"
+// @Param app_id query int false "应用ID，只统计该应用"
+// @Param start query string false "开始日期 YYYY-MM-DD，默认 90 天前"
+// @Param end query string false "结束日期 YYYY-MM-DD（含），默认今天"
+// @Param group_by query string false "分组维度: project/team/app，默认 app"
+// @Param format query string false "json/csv，默认 json"
+// @Success 200 {object} responses.Response{data=dto.DeploymentReportResponse}
+// @Router /api/v1/reports/deployments [get]
+func (h *ReportHandler) Deployments(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	var query dto.DeploymentReportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	username := c.GetString("username")
+	report, err := h.batchService.DeploymentReport(&query, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	if query.Format != "csv" {
+		responses.Success(c, report)
+		return
+	}
+
+	data, err := deploymentReportCSV(report)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="deployments-%s-%s.csv"`, report.Start, report.End))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
+
+// deploymentReportCSV 分组明细导出为 CSV（带 UTF-8 BOM，Excel 可直接打开中文）
+func deploymentReportCSV(report *dto.DeploymentReportResponse) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\xEF\xBB\xBF")
+	w := csv.NewWriter(&buf)
+
+	header := []string{"project_id", "project_name"}
+	switch report.GroupBy {
+	case service.DeploymentReportGroupTeam:
+		header = append(header, "team_id", "team_name")
+	case service.DeploymentReportGroupApp:
+		header = append(header, "team_id", "team_name", "app_id", "app_name")
+	}
+	header = append(header,
+		"deployments", "successful", "failed", "success_rate", "deployment_frequency", "active_days",
+		"lead_times", "lead_time_avg_minutes", "lead_time_p50_minutes", "lead_time_p90_minutes",
+		"recoveries", "mttr_minutes")
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	num := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	for _, item := range report.Items {
+		row := []string{strconv.FormatInt(item.ProjectID, 10), item.ProjectName}
+		teamID := ""
+		if item.TeamID != nil {
+			teamID = strconv.FormatInt(*item.TeamID, 10)
+		}
+		switch report.GroupBy {
+		case service.DeploymentReportGroupTeam:
+			row = append(row, teamID, item.TeamName)
+		case service.DeploymentReportGroupApp:
+			row = append(row, teamID, item.TeamName, strconv.FormatInt(item.AppID, 10), item.AppName)
+		}
+		row = append(row,
+			strconv.Itoa(item.Deployments), strconv.Itoa(item.Successful), strconv.Itoa(item.Failed),
+			num(item.SuccessRate), num(item.DeploymentFrequency), strconv.Itoa(item.ActiveDays),
+			strconv.Itoa(item.LeadTimes), num(item.LeadTimeAvgMinutes), num(item.LeadTimeP50Minutes), num(item.LeadTimeP90Minutes),
+			strconv.Itoa(item.Recoveries), num(item.MTTRMinutes))
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
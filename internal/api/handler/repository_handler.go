@@ -10,12 +10,14 @@ import (
 )
 
 type RepositoryHandler struct {
-	service service.RepositoryService
+	service     service.RepositoryService
+	syncService *service.RepoSyncService
 }
 
-func NewRepositoryHandler(service service.RepositoryService) *RepositoryHandler {
+func NewRepositoryHandler(service service.RepositoryService, syncService *service.RepoSyncService) *RepositoryHandler {
 	return &RepositoryHandler{
-		service: service,
+		service:     service,
+		syncService: syncService,
 	}
 }
 
@@ -144,3 +146,76 @@ func (h *RepositoryHandler) Delete(c *gin.Context) {
 
 	responses.Success(c, nil)
 }
+
+// GetManifest 获取代码库 devops-cd.yaml 最近一次同步报告
+// @Summary 获取代码库清单同步报告
+// @Tags Repository
+// @Accept json
+// @Produce json
+// @Param id query int true "代码库ID"
+// @Success 200 {object} responses.Response{data=dto.ManifestSyncReport}
+// @Router /api/v1/repository/manifest [get]
+func (h *RepositoryHandler) GetManifest(c *gin.Context) {
+	var req dto.GetRepositoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		responses.ErrorWithDetail(c, responses.CodeBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	report, err := h.syncService.GetRepositoryManifestReport(req.ID)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+
+	responses.Success(c, report)
+}
+
+// SyncManifest 按代码库 devops-cd.yaml 同步应用配置
+// @Summary 按清单同步应用配置
+// @Tags Repository
+// @Accept json
+// @Produce json
+// @Param body body dto.RepositoryManifestSyncRequest true "同步请求（dry_run=true 只计算漂移）"
+// @Success 200 {object} responses.Response{data=dto.ManifestSyncReport}
+// @Router /api/v1/repository/manifest/sync [post]
+func (h *RepositoryHandler) SyncManifest(c *gin.Context) {
+	var req dto.RepositoryManifestSyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, responses.CodeBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	report, err := h.syncService.SyncRepositoryManifest(req.ID, req.DryRun)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+
+	responses.Success(c, report)
+}
+
+// ValidateManifest 校验代码库 devops-cd.yaml
+// @Summary 校验清单
+// @Description 校验指定 ref（或请求中给出的清单内容）并计算与平台配置的漂移，不修改配置、不保存同步报告
+// @Tags Repository
+// @Accept json
+// @Produce json
+// @Param body body dto.RepositoryManifestValidateRequest true "校验请求"
+// @Success 200 {object} responses.Response{data=dto.ManifestSyncReport}
+// @Router /api/v1/repository/manifest/validate [post]
+func (h *RepositoryHandler) ValidateManifest(c *gin.Context) {
+	var req dto.RepositoryManifestValidateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, responses.CodeBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	report, err := h.syncService.ValidateRepositoryManifest(&req)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+
+	responses.Success(c, report)
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"devops-cd/internal/dto"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
+	"devops-cd/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleHandler 角色/权限管理处理器
+type RoleHandler struct {
+	svc service.RoleService
+}
+
+func NewRoleHandler(svc service.RoleService) *RoleHandler {
+	return &RoleHandler{svc: svc}
+}
+
+// List 角色列表
+// @Summary 角色列表（内置角色 + 自定义角色）
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} responses.Response{data=[]dto.RoleResponse}
+// @Router /api/v1/admin/roles [get]
+func (h *RoleHandler) List(c *gin.Context) {
+	list, err := h.svc.List()
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, list)
+}
+
+// Permissions 已知权限点
+// @Summary 已知权限点（自定义角色也可使用通配符，如 batch:*、*:view）
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} responses.Response{data=[]string}
+// @Router /api/v1/admin/roles/permissions [get]
+func (h *RoleHandler) Permissions(c *gin.Context) {
+	responses.Success(c, h.svc.Permissions())
+}
+
+// Get 自定义角色详情
+// @Summary 自定义角色详情
+// @Tags Admin
+// @Produce json
+// @Param id path int true "角色ID"
+// @Success 200 {object} responses.Response{data=dto.RoleResponse}
+// @Router /api/v1/admin/roles/{id} [get]
+func (h *RoleHandler) Get(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	resp, err := h.svc.Get(id)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// Create 创建自定义角色
+// @Summary 创建自定义角色
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateRoleRequest true "创建请求"
+// @Success 200 {object} responses.Response{data=dto.RoleResponse}
+// @Router /api/v1/admin/roles [post]
+func (h *RoleHandler) Create(c *gin.Context) {
+	var req dto.CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	resp, err := h.svc.Create(&req, c.GetString("username"))
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// Update 更新自定义角色
+// @Summary 更新自定义角色（permissions 非空时整体替换）
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "角色ID"
+// @Param request body dto.UpdateRoleRequest true "更新请求"
+// @Success 200 {object} responses.Response{data=dto.RoleResponse}
+// @Router /api/v1/admin/roles/{id} [put]
+func (h *RoleHandler) Update(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	var req dto.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	resp, err := h.svc.Update(id, &req, c.GetString("username"))
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// Delete 删除自定义角色
+// @Summary 删除自定义角色（仍被分配时拒绝）
+// @Tags Admin
+// @Produce json
+// @Param id path int true "角色ID"
+// @Success 200 {object} responses.Response
+// @Router /api/v1/admin/roles/{id} [delete]
+func (h *RoleHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	if err := h.svc.Delete(id); err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, nil)
+}
+
+// AssignUserRoles 设置用户系统角色
+// @Summary 设置用户系统角色（users.system_roles，整体替换）
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param request body dto.AssignRolesRequest true "角色列表"
+// @Success 200 {object} responses.Response{data=dto.UserRolesResponse}
+// @Router /api/v1/admin/users/{id}/roles [put]
+func (h *RoleHandler) AssignUserRoles(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	var req dto.AssignRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	resp, err := h.svc.AssignUserRoles(id, req.Roles)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// AssignTeamRoles 设置团队角色
+// @Summary 设置团队角色（团队所有成员额外拥有，整体替换）
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "团队ID"
+// @Param request body dto.AssignRolesRequest true "角色列表"
+// @Success 200 {object} responses.Response{data=dto.TeamRolesResponse}
+// @Router /api/v1/admin/teams/{id}/roles [put]
+func (h *RoleHandler) AssignTeamRoles(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	var req dto.AssignRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	resp, err := h.svc.AssignTeamRoles(id, req.Roles)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
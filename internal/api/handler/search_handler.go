@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"devops-cd/internal/dto"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
+	"devops-cd/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchHandler 统一搜索处理器
+type SearchHandler struct {
+	searchService *service.SearchService
+}
+
+// NewSearchHandler 创建统一搜索处理器
+func NewSearchHandler(searchService *service.SearchService) *SearchHandler {
+	return &SearchHandler{searchService: searchService}
+}
+
+// Search 统一搜索
+// @Summary 跨构建、批次、部署搜索（批次号、commit SHA/message、镜像 tag、deployment 名、错误信息），按创建时间倒序游标分页
+// @Tags 搜索
+// @Produce json
+// @Param q query string false "全文关键字"
+// @Param types query string false "build,batch,deployment（逗号分隔），默认全部"
+// @Param project_id query int false "项目ID，为空时搜索有权限的所有项目"
+// @Param app_id query int false "应用ID"
+// @Param commit query string false "commit SHA 前缀"
+// @Param image_tag query string false "镜像 tag"
+// @Param env query string false "部署环境 pre/prod（只作用于部署）"
+// @Param since query string false "创建时间起 YYYY-MM-DD"
+// @Param until query string false "创建时间止 YYYY-MM-DD（含）"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param limit query int false "每页条数，默认 20，最大 100"
+// @Success 200 {object} responses.Response{data=dto.SearchResponse}
+// @Router /api/v1/search [get]
+func (h *SearchHandler) Search(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	var req dto.SearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	username := c.GetString("username")
+	resp, err := h.searchService.Search(&req, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
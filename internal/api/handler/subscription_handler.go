@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"net/http"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
+	"devops-cd/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SubscriptionHandler 批次/应用订阅处理器
+type SubscriptionHandler struct {
+	svc *service.SubscriptionService
+}
+
+// NewSubscriptionHandler 创建订阅处理器
+func NewSubscriptionHandler(svc *service.SubscriptionService) *SubscriptionHandler {
+	return &SubscriptionHandler{svc: svc}
+}
+
+// Create 订阅批次/应用
+// @Summary 订阅批次/应用
+// @Description 订阅后批次状态变更（batch）或应用发布状态变更（app）时通过 Lark 私聊 / 邮件通知当前用户；已订阅时更新通知渠道
+// @Tags 订阅
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateSubscriptionRequest true "订阅请求"
+// @Success 200 {object} responses.Response{data=dto.SubscriptionResponse}
+// @Router /api/v1/subscriptions [post]
+func (h *SubscriptionHandler) Create(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	var req dto.CreateSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	username := c.GetString("username")
+	resp, err := h.svc.Subscribe(&req, username, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// Delete 取消订阅
+// @Summary 取消订阅
+// @Tags 订阅
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Success 200 {object} responses.Response
+// @Router /api/v1/subscriptions/{id} [delete]
+func (h *SubscriptionHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	if err := h.svc.Unsubscribe(id, c.GetString("username")); err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, nil)
+}
+
+// List 我的订阅
+// @Summary 当前用户的订阅列表
+// @Tags 订阅
+// @Produce json
+// @Param target_type query string false "订阅类型" Enums(batch, app)
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PageResponse
+// @Router /api/v1/subscriptions [get]
+func (h *SubscriptionHandler) List(c *gin.Context) {
+	var req dto.SubscriptionListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+	list, total, err := h.svc.List(&req, c.GetString("username"))
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, dto.NewPageResponse(list, total, req.Page, req.PageSize))
+}
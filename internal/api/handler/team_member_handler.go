@@ -21,15 +21,15 @@ func NewTeamMemberHandler(service service.TeamMemberService) *TeamMemberHandler
 	}
 }
 
-// AddMember 添加团队成员
-func (h *TeamMemberHandler) AddMember(c *gin.Context) {
+// AddMember 添加团队成员（授予 prod_operator 等生产环境操作角色需授予人拥有团队所属项目的 env:prod:operate）
+func (h *TeamMemberHandler) AddMember(c *gin.Context, canProdOperate func(username string, projectId int64) bool) {
 	var req dto.TeamMemberAddRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", err.Error())
 		return
 	}
 
-	member, err := h.service.Add(&req)
+	member, err := h.service.Add(&req, grantChecker(c, canProdOperate))
 	if err != nil {
 		responses.Error(c, err)
 		return
@@ -55,8 +55,8 @@ func (h *TeamMemberHandler) ListMembers(c *gin.Context) {
 	responses.Success(c, dto.NewPageResponse(members, total, req.GetPage(), req.GetPageSize()))
 }
 
-// UpdateRole 更新团队成员角色
-func (h *TeamMemberHandler) UpdateRole(c *gin.Context) {
+// UpdateRole 更新团队成员角色（生产环境操作角色的授予校验同 AddMember）
+func (h *TeamMemberHandler) UpdateRole(c *gin.Context, canProdOperate func(username string, projectId int64) bool) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
 		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的成员ID", err.Error())
@@ -69,7 +69,7 @@ func (h *TeamMemberHandler) UpdateRole(c *gin.Context) {
 		return
 	}
 
-	member, err := h.service.UpdateRole(id, &req)
+	member, err := h.service.UpdateRole(id, &req, grantChecker(c, canProdOperate))
 	if err != nil {
 		responses.Error(c, err)
 		return
@@ -93,3 +93,11 @@ func (h *TeamMemberHandler) DeleteMember(c *gin.Context) {
 
 	responses.Success(c, nil)
 }
+
+// grantChecker 当前用户在项目下的生产环境操作权限
+func grantChecker(c *gin.Context, canProdOperate func(username string, projectId int64) bool) func(projectID int64) bool {
+	username := c.GetString("username")
+	return func(projectID int64) bool {
+		return canProdOperate(username, projectID)
+	}
+}
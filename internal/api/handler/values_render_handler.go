@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
+	"devops-cd/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ValuesRenderHandler values 渲染预览处理器
+type ValuesRenderHandler struct {
+	valuesRenderService *service.ValuesRenderService
+}
+
+// NewValuesRenderHandler 创建 values 渲染预览处理器
+func NewValuesRenderHandler(valuesRenderService *service.ValuesRenderService) *ValuesRenderHandler {
+	return &ValuesRenderHandler{valuesRenderService: valuesRenderService}
+}
+
+// Render 渲染应用在项目环境下的 values
+// @Summary 预览 values 渲染结果
+// @Description 与部署时相同的 values 合并规则（ParseValuesV1），返回各层解析内容、合并顺序与最终 values（敏感字段脱敏），不访问集群、不修改状态
+// @Tags Project
+// @Accept json
+// @Produce json
+// @Param id path int64 true "项目ID"
+// @Param env path string true "环境"
+// @Param request body dto.RenderValuesRequest true "应用与构建"
+// @Success 200 {object} responses.Response{data=dto.RenderValuesResponse}
+// @Router /api/v1/project/{id}/env/{env}/render-values [post]
+func (h *ValuesRenderHandler) Render(c *gin.Context, canAccess func(username string, projectId int64) bool) {
+	projectID, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的项目ID", c.Param("id"))
+		return
+	}
+	env := strings.TrimSpace(c.Param("env"))
+	var req dto.RenderValuesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, responses.CodeBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+
+	username := c.GetString("username")
+	resp, err := h.valuesRenderService.Render(c.Request.Context(), projectID, env, &req, func(projectID int64) bool {
+		return canAccess(username, projectID)
+	})
+	if err != nil {
+		logger.Warn("渲染 values 失败", zap.Int64("project_id", projectID), zap.String("env", env), zap.Error(err))
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"devops-cd/internal/dto"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/constants"
+	"devops-cd/pkg/responses"
+	"devops-cd/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler 出站 Webhook 事件订阅处理器
+type WebhookHandler struct {
+	svc service.WebhookService
+}
+
+func NewWebhookHandler(svc service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{svc: svc}
+}
+
+// Events 可订阅的事件列表
+// @Summary 出站 Webhook 可订阅事件
+// @Tags Admin
+// @Produce json
+// @Success 200 {object} responses.Response{data=[]string}
+// @Router /api/v1/admin/webhooks/events [get]
+func (h *WebhookHandler) Events(c *gin.Context) {
+	responses.Success(c, constants.WebhookEvents)
+}
+
+// Create 创建订阅
+// @Summary 创建出站 Webhook 订阅
+// @Description 事件发生后引擎 POST 签名 JSON：X-DevOps-CD-Signature = sha256=hex(HMAC-SHA256(secret, X-DevOps-CD-Timestamp + "." + body))
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateWebhookRequest true "创建请求"
+// @Success 200 {object} responses.Response{data=dto.WebhookResponse}
+// @Router /api/v1/admin/webhooks [post]
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req dto.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	resp, err := h.svc.Create(&req, c.GetString("username"))
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// Update 更新订阅
+// @Summary 更新出站 Webhook 订阅
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Param request body dto.UpdateWebhookRequest true "更新请求"
+// @Success 200 {object} responses.Response{data=dto.WebhookResponse}
+// @Router /api/v1/admin/webhooks/{id} [put]
+func (h *WebhookHandler) Update(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	var req dto.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	resp, err := h.svc.Update(id, &req, c.GetString("username"))
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// Delete 删除订阅
+// @Summary 删除出站 Webhook 订阅（同时删除投递记录）
+// @Tags Admin
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Success 200 {object} responses.Response
+// @Router /api/v1/admin/webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	if err := h.svc.Delete(id); err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, nil)
+}
+
+// Get 订阅详情
+// @Summary 出站 Webhook 订阅详情
+// @Tags Admin
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Success 200 {object} responses.Response{data=dto.WebhookResponse}
+// @Router /api/v1/admin/webhooks/{id} [get]
+func (h *WebhookHandler) Get(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	resp, err := h.svc.Get(id)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// List 订阅列表
+// @Summary 出站 Webhook 订阅列表
+// @Tags Admin
+// @Produce json
+// @Param project_id query int false "项目ID"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PageResponse
+// @Router /api/v1/admin/webhooks [get]
+func (h *WebhookHandler) List(c *gin.Context) {
+	var req dto.WebhookListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+	list, total, err := h.svc.List(&req)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, dto.NewPageResponse(list, total, req.Page, req.PageSize))
+}
+
+// Test 测试投递
+// @Summary 出站 Webhook 测试投递（同步发送 ping 事件，失败不重试）
+// @Tags Admin
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Success 200 {object} responses.Response{data=dto.WebhookDeliveryResponse}
+// @Router /api/v1/admin/webhooks/{id}/test [post]
+func (h *WebhookHandler) Test(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	resp, err := h.svc.Test(id, c.GetString("username"))
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// ListDeliveries 投递记录
+// @Summary 出站 Webhook 投递记录
+// @Tags Admin
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Param status query string false "投递状态" Enums(pending, success, failed)
+// @Param event query string false "事件"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PageResponse
+// @Router /api/v1/admin/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	var req dto.WebhookDeliveryListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+	list, total, err := h.svc.ListDeliveries(id, &req)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, dto.NewPageResponse(list, total, req.Page, req.PageSize))
+}
+
+// Redeliver 重新投递
+// @Summary 出站 Webhook 重新投递（重置为待投递，由引擎下一轮扫描发送）
+// @Tags Admin
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Param delivery_id path int true "投递记录ID"
+// @Success 200 {object} responses.Response{data=dto.WebhookDeliveryResponse}
+// @Router /api/v1/admin/webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	deliveryID, ok := parseIDParam(c.Param("delivery_id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的投递记录 ID", c.Param("delivery_id"))
+		return
+	}
+	resp, err := h.svc.Redeliver(id, deliveryID)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
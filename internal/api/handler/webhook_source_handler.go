@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"devops-cd/internal/dto"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
+	"devops-cd/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// WebhookSourceHandler 通用 CI 构建通知来源（字段映射）处理器
+type WebhookSourceHandler struct {
+	svc          service.WebhookSourceService
+	buildService service.BuildService
+}
+
+func NewWebhookSourceHandler(svc service.WebhookSourceService, buildService service.BuildService) *WebhookSourceHandler {
+	return &WebhookSourceHandler{svc: svc, buildService: buildService}
+}
+
+// Notify 接收通用 CI 构建通知
+// @Summary 接收通用 CI 构建通知（按来源的字段映射转换后处理）
+// @Description Jenkins、TeamCity 等任意 CI 按来源配置的 JSONPath 映射转换为标准构建通知；配置了 token 时需携带请求头 X-Webhook-Token
+// @Tags Build
+// @Accept json
+// @Produce json
+// @Param source path string true "来源标识"
+// @Param X-Webhook-Token header string false "来源 token"
+// @Success 200 {object} responses.Response "成功响应"
+// @Router /build/notify/{source} [post]
+func (h *WebhookSourceHandler) Notify(c *gin.Context) {
+	source := c.Param("source")
+	payload, err := c.GetRawData()
+	if err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "读取请求体失败", err.Error())
+		return
+	}
+
+	req, err := h.svc.Resolve(source, c.GetHeader("X-Webhook-Token"), payload)
+	if err != nil {
+		logger.Warn("通用 CI 构建通知映射失败", zap.String("source", source), zap.Error(err))
+		responses.Error(c, err)
+		return
+	}
+
+	processBuildNotify(c, h.buildService, req)
+}
+
+// Create 创建构建通知来源
+// @Summary 创建通用 CI 构建通知来源
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body dto.CreateWebhookSourceRequest true "创建请求"
+// @Success 200 {object} responses.Response{data=dto.WebhookSourceResponse}
+// @Router /api/v1/admin/webhook_sources [post]
+func (h *WebhookSourceHandler) Create(c *gin.Context) {
+	var req dto.CreateWebhookSourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	resp, err := h.svc.Create(&req, c.GetString("username"))
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// Update 更新构建通知来源
+// @Summary 更新通用 CI 构建通知来源
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "来源ID"
+// @Param request body dto.UpdateWebhookSourceRequest true "更新请求"
+// @Success 200 {object} responses.Response{data=dto.WebhookSourceResponse}
+// @Router /api/v1/admin/webhook_sources/{id} [put]
+func (h *WebhookSourceHandler) Update(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	var req dto.UpdateWebhookSourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	resp, err := h.svc.Update(id, &req, c.GetString("username"))
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// Delete 删除构建通知来源
+// @Summary 删除通用 CI 构建通知来源
+// @Tags Admin
+// @Produce json
+// @Param id path int true "来源ID"
+// @Success 200 {object} responses.Response
+// @Router /api/v1/admin/webhook_sources/{id} [delete]
+func (h *WebhookSourceHandler) Delete(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	if err := h.svc.Delete(id); err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, nil)
+}
+
+// Get 构建通知来源详情
+// @Summary 通用 CI 构建通知来源详情
+// @Tags Admin
+// @Produce json
+// @Param id path int true "来源ID"
+// @Success 200 {object} responses.Response{data=dto.WebhookSourceResponse}
+// @Router /api/v1/admin/webhook_sources/{id} [get]
+func (h *WebhookSourceHandler) Get(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	resp, err := h.svc.Get(id)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// List 构建通知来源列表
+// @Summary 通用 CI 构建通知来源列表
+// @Tags Admin
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} dto.PageResponse
+// @Router /api/v1/admin/webhook_sources [get]
+func (h *WebhookSourceHandler) List(c *gin.Context) {
+	var req dto.WebhookSourceListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = 10
+	}
+	list, total, err := h.svc.List(&req)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, dto.NewPageResponse(list, total, req.Page, req.PageSize))
+}
+
+// Preview 使用已保存的映射预览 payload 转换结果
+// @Summary 构建通知映射预览（payload 为空时使用 sample_payload）
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path int true "来源ID"
+// @Param request body dto.WebhookPreviewRequest false "预览请求"
+// @Success 200 {object} responses.Response{data=dto.WebhookPreviewResponse}
+// @Router /api/v1/admin/webhook_sources/{id}/preview [post]
+func (h *WebhookSourceHandler) Preview(c *gin.Context) {
+	id, ok := parseIDParam(c.Param("id"))
+	if !ok {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "无效的 ID", c.Param("id"))
+		return
+	}
+	var req dto.WebhookPreviewRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+			return
+		}
+	}
+	resp, err := h.svc.Preview(id, req.Payload)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
+
+// PreviewMapping 预览未保存的映射
+// @Summary 构建通知映射预览（未保存的映射配置）
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param request body dto.WebhookMappingPreviewRequest true "预览请求"
+// @Success 200 {object} responses.Response{data=dto.WebhookPreviewResponse}
+// @Router /api/v1/admin/webhook_sources/preview [post]
+func (h *WebhookSourceHandler) PreviewMapping(c *gin.Context) {
+	var req dto.WebhookMappingPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		responses.ErrorWithDetail(c, http.StatusBadRequest, "请求参数错误", utils.FormatValidationError(err))
+		return
+	}
+	resp, err := h.svc.PreviewMapping(&req)
+	if err != nil {
+		responses.Error(c, err)
+		return
+	}
+	responses.Success(c, resp)
+}
@@ -2,17 +2,37 @@ package middleware
 
 import (
 	"devops-cd/pkg/responses"
+	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 
 	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
 	"devops-cd/internal/pkg/jwt"
 	"devops-cd/pkg/constants"
 )
 
-// AuthMiddleware JWT认证中间件
-func AuthMiddleware() gin.HandlerFunc {
+// ContextKeyAPIToken 使用 API Token 认证时，context 中保存的 *model.APIToken
+const ContextKeyAPIToken = "api_token"
+
+// APITokenAuthenticator API Token 校验（service.APITokenService 实现）
+type APITokenAuthenticator interface {
+	Authenticate(token, clientIP string) (*model.APIToken, error)
+}
+
+// APITokenFromContext 请求使用 API Token 认证时返回该 token
+func APITokenFromContext(c *gin.Context) (*model.APIToken, bool) {
+	v, ok := c.Get(ContextKeyAPIToken)
+	if !ok {
+		return nil, false
+	}
+	token, ok := v.(*model.APIToken)
+	return token, ok
+}
+
+// AuthMiddleware 认证中间件：用户 JWT，或 dcd_ 前缀的 API Token（只读，仅允许 GET/HEAD）
+func AuthMiddleware(tokens APITokenAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// 获取Authorization header
 		authHeader := c.GetHeader(constants.HeaderAuthorization)
@@ -31,6 +51,10 @@ func AuthMiddleware() gin.HandlerFunc {
 
 		// 提取Token
 		token := strings.TrimPrefix(authHeader, constants.HeaderBearerPrefix)
+		if strings.HasPrefix(token, constants.APITokenPrefix) {
+			authenticateAPIToken(c, tokens, token)
+			return
+		}
 
 		// 验证Token
 		claims, err := jwt.ValidateToken(token)
@@ -65,3 +89,26 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// authenticateAPIToken API Token 认证：校验 token 并以 api-token:<name> 作为用户名
+func authenticateAPIToken(c *gin.Context, tokens APITokenAuthenticator, token string) {
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		responses.ErrorWithCode(c, http.StatusForbidden, "API Token 只允许只读请求")
+		c.Abort()
+		return
+	}
+	apiToken, err := tokens.Authenticate(token, c.ClientIP())
+	if err != nil {
+		responses.Error(c, err)
+		c.Abort()
+		return
+	}
+
+	username := "api-token:" + apiToken.Name
+	c.Set("user", &dto.UserInfo{Username: username, DisplayName: apiToken.Name, AuthType: constants.AuthTypeAPIToken})
+	c.Set("username", username)
+	c.Set("auth_type", constants.AuthTypeAPIToken)
+	c.Set(ContextKeyAPIToken, apiToken)
+
+	c.Next()
+}
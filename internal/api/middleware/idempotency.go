@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/pkg/responses"
+)
+
+const (
+	// HeaderIdempotencyKey 客户端为一次写操作生成的唯一键（如 UUID），重试时保持不变
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed 响应为首次请求结果的重放时返回 true
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 128
+)
+
+// IdempotencyStore 幂等记录存储（service.IdempotencyService）
+type IdempotencyStore interface {
+	Acquire(username, key, method, path, fingerprint string) (*model.IdempotencyKey, bool, error)
+	Complete(id int64, statusCode int, contentType string, body []byte) error
+	Release(id int64) error
+}
+
+// Idempotency POST 请求携带 Idempotency-Key 头时按「用户 + 键」去重，需放在 AuthMiddleware 之后
+//
+//   - 首次请求正常处理，成功（HTTP 2xx 且业务码为成功）时保存响应；失败时删除记录，可使用同一个键重试
+//   - 重试请求直接返回首次请求的响应，并附加 Idempotent-Replayed: true
+//   - 首次请求仍在处理中时返回冲突；同一个键用于不同的接口或请求体时返回参数错误
+func Idempotency(store IdempotencyStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader(HeaderIdempotencyKey))
+		if key == "" || c.Request.Method != http.MethodPost {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			responses.ErrorWithCode(c, responses.CodeBadRequest, "Idempotency-Key 长度不能超过 "+strconv.Itoa(maxIdempotencyKeyLength))
+			c.Abort()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			raw, err := io.ReadAll(c.Request.Body)
+			_ = c.Request.Body.Close()
+			if err != nil {
+				responses.ErrorWithCode(c, responses.CodeBadRequest, "读取请求体失败")
+				c.Abort()
+				return
+			}
+			body = raw
+			c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+		}
+
+		path := c.Request.URL.RequestURI()
+		sum := sha256.Sum256([]byte(c.Request.Method + "\n" + path + "\n" + string(body)))
+		fingerprint := hex.EncodeToString(sum[:])
+
+		rec, acquired, err := store.Acquire(c.GetString("username"), key, c.Request.Method, path, fingerprint)
+		if err != nil {
+			responses.Error(c, err)
+			c.Abort()
+			return
+		}
+		if !acquired {
+			switch {
+			case rec.Fingerprint != fingerprint:
+				responses.ErrorWithDetail(c, responses.CodeBadRequest, "Idempotency-Key 已用于其他请求", rec.Method+" "+rec.Path)
+			case rec.Status != model.IdempotencyStatusCompleted:
+				responses.ErrorWithCode(c, responses.CodeConflict, "相同 Idempotency-Key 的请求正在处理中")
+			default:
+				c.Header(HeaderIdempotentReplayed, "true")
+				c.Data(rec.StatusCode, rec.ContentType, rec.ResponseBody)
+			}
+			c.Abort()
+			return
+		}
+
+		w := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		defer func() {
+			// handler panic 时释放键后继续向外抛出，由 Recovery 处理
+			if r := recover(); r != nil {
+				releaseIdempotencyKey(store, rec)
+				panic(r)
+			}
+		}()
+
+		c.Next()
+
+		if !succeeded(w.Status(), w.body.Bytes()) {
+			releaseIdempotencyKey(store, rec)
+			return
+		}
+		if err := store.Complete(rec.ID, w.Status(), w.Header().Get("Content-Type"), w.body.Bytes()); err != nil {
+			logger.Warn("保存幂等记录失败", zap.String("key", key), zap.String("path", path), zap.Error(err))
+			releaseIdempotencyKey(store, rec)
+		}
+	}
+}
+
+func releaseIdempotencyKey(store IdempotencyStore, rec *model.IdempotencyKey) {
+	if err := store.Release(rec.ID); err != nil {
+		logger.Warn("删除幂等记录失败", zap.String("key", rec.Key), zap.Error(err))
+	}
+}
+
+// succeeded HTTP 2xx 且响应体业务码为成功（v1 错误也返回 HTTP 200）
+func succeeded(status int, body []byte) bool {
+	if status < 200 || status >= 300 {
+		return false
+	}
+	var resp struct {
+		Code *int `json:"code"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Code == nil {
+		return true // 非统一响应结构，按 HTTP 状态判断
+	}
+	return *resp.Code == responses.CodeSuccess || *resp.Code == responses.CodePartialSuccess
+}
+
+// recordingWriter 在写出响应的同时记录响应体
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
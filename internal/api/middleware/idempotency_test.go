@@ -0,0 +1,185 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"devops-cd/internal/model"
+	"devops-cd/pkg/responses"
+)
+
+// memIdempotencyStore 按「用户 + 键」保存记录的内存实现，语义与 service.IdempotencyService 一致
+type memIdempotencyStore struct {
+	seq  int64
+	recs map[string]*model.IdempotencyKey
+}
+
+func newMemIdempotencyStore() *memIdempotencyStore {
+	return &memIdempotencyStore{recs: map[string]*model.IdempotencyKey{}}
+}
+
+func (s *memIdempotencyStore) Acquire(username, key, method, path, fingerprint string) (*model.IdempotencyKey, bool, error) {
+	if rec, ok := s.recs[username+"\x00"+key]; ok {
+		return rec, false, nil
+	}
+	s.seq++
+	rec := &model.IdempotencyKey{
+		Username: username, Key: key, Method: method, Path: path,
+		Fingerprint: fingerprint, Status: model.IdempotencyStatusProcessing,
+	}
+	rec.ID = s.seq
+	s.recs[username+"\x00"+key] = rec
+	return rec, true, nil
+}
+
+func (s *memIdempotencyStore) Complete(id int64, statusCode int, contentType string, body []byte) error {
+	for _, rec := range s.recs {
+		if rec.ID == id {
+			rec.Status, rec.StatusCode, rec.ContentType, rec.ResponseBody = model.IdempotencyStatusCompleted, statusCode, contentType, body
+		}
+	}
+	return nil
+}
+
+func (s *memIdempotencyStore) Release(id int64) error {
+	for k, rec := range s.recs {
+		if rec.ID == id {
+			delete(s.recs, k)
+		}
+	}
+	return nil
+}
+
+type idemRequest struct {
+	method, user, key, body string
+}
+
+type idemResult struct {
+	code     int  // 响应体业务码
+	replayed bool // Idempotent-Replayed
+}
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	long := strings.Repeat("k", maxIdempotencyKeyLength+1)
+
+	cases := []struct {
+		name     string
+		preset   *model.IdempotencyKey // 预置的记录（username/key 取自第一条请求）
+		failing  int                   // handler 前 failing 次调用返回业务错误
+		requests []idemRequest
+		want     []idemResult
+		calls    int // handler 实际执行次数
+	}{
+		{
+			name:     "重试返回首次响应",
+			requests: []idemRequest{{http.MethodPost, "alice", "k1", `{"a":1}`}, {http.MethodPost, "alice", "k1", `{"a":1}`}},
+			want:     []idemResult{{responses.CodeSuccess, false}, {responses.CodeSuccess, true}},
+			calls:    1,
+		},
+		{
+			name:     "同一个键用于不同请求体",
+			requests: []idemRequest{{http.MethodPost, "alice", "k1", `{"a":1}`}, {http.MethodPost, "alice", "k1", `{"a":2}`}},
+			want:     []idemResult{{responses.CodeSuccess, false}, {responses.CodeBadRequest, false}},
+			calls:    1,
+		},
+		{
+			name:     "按用户区分键",
+			requests: []idemRequest{{http.MethodPost, "alice", "k1", `{"a":1}`}, {http.MethodPost, "bob", "k1", `{"a":1}`}},
+			want:     []idemResult{{responses.CodeSuccess, false}, {responses.CodeSuccess, false}},
+			calls:    2,
+		},
+		{
+			name:     "失败后可用同一个键重试",
+			failing:  1,
+			requests: []idemRequest{{http.MethodPost, "alice", "k1", `{}`}, {http.MethodPost, "alice", "k1", `{}`}, {http.MethodPost, "alice", "k1", `{}`}},
+			want:     []idemResult{{responses.CodeInternalError, false}, {responses.CodeSuccess, false}, {responses.CodeSuccess, true}},
+			calls:    2,
+		},
+		{
+			name:     "首次请求处理中",
+			preset:   &model.IdempotencyKey{Status: model.IdempotencyStatusProcessing},
+			requests: []idemRequest{{http.MethodPost, "alice", "k1", `{}`}},
+			want:     []idemResult{{responses.CodeConflict, false}},
+			calls:    0,
+		},
+		{
+			name:     "无键或非 POST 不去重",
+			requests: []idemRequest{{http.MethodPost, "alice", "", `{}`}, {http.MethodPost, "alice", "", `{}`}, {http.MethodPut, "alice", "k1", `{}`}, {http.MethodPut, "alice", "k1", `{}`}},
+			want:     []idemResult{{responses.CodeSuccess, false}, {responses.CodeSuccess, false}, {responses.CodeSuccess, false}, {responses.CodeSuccess, false}},
+			calls:    4,
+		},
+		{
+			name:     "键过长",
+			requests: []idemRequest{{http.MethodPost, "alice", long, `{}`}},
+			want:     []idemResult{{responses.CodeBadRequest, false}},
+			calls:    0,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newMemIdempotencyStore()
+			if tc.preset != nil {
+				first := tc.requests[0]
+				tc.preset.Username, tc.preset.Key = first.user, first.key
+				tc.preset.Method, tc.preset.Path = first.method, "/x"
+				sum := sha256.Sum256([]byte(first.method + "\n/x\n" + first.body))
+				tc.preset.Fingerprint = hex.EncodeToString(sum[:])
+				store.recs[first.user+"\x00"+first.key] = tc.preset
+			}
+
+			calls := 0
+			handler := func(c *gin.Context) {
+				calls++
+				if calls <= tc.failing {
+					responses.Error(c, responses.ErrInternalError)
+					return
+				}
+				responses.Success(c, gin.H{"n": calls})
+			}
+			r := gin.New()
+			r.Use(func(c *gin.Context) { c.Set("username", c.GetHeader("X-User")) }, Idempotency(store))
+			r.POST("/x", handler)
+			r.PUT("/x", handler)
+
+			var firstBody string
+			for i, req := range tc.requests {
+				httpReq := httptest.NewRequest(req.method, "/x", strings.NewReader(req.body))
+				httpReq.Header.Set("X-User", req.user)
+				if req.key != "" {
+					httpReq.Header.Set(HeaderIdempotencyKey, req.key)
+				}
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, httpReq)
+
+				var resp struct {
+					Code int `json:"code"`
+				}
+				if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("请求 %d: 响应不是 JSON: %s", i, w.Body.String())
+				}
+				got := idemResult{code: resp.Code, replayed: w.Header().Get(HeaderIdempotentReplayed) == "true"}
+				if got != tc.want[i] {
+					t.Errorf("请求 %d: got %+v, want %+v（%s）", i, got, tc.want[i], w.Body.String())
+				}
+				if got.replayed && w.Body.String() != firstBody {
+					t.Errorf("请求 %d: 重放响应 %s 与首次成功响应 %s 不一致", i, w.Body.String(), firstBody)
+				}
+				if resp.Code == responses.CodeSuccess && firstBody == "" {
+					firstBody = w.Body.String()
+				}
+			}
+			if calls != tc.calls {
+				t.Errorf("handler 执行 %d 次, want %d", calls, tc.calls)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -10,12 +12,22 @@ import (
 	"devops-cd/internal/pkg/logger"
 )
 
+// sensitiveQueryKeys 访问日志中脱敏的 query 参数（小写）
+var sensitiveQueryKeys = map[string]bool{
+	"token":        true,
+	"access_token": true,
+	"api_key":      true,
+	"secret":       true,
+	"password":     true,
+	"code":         true,
+}
+
 // LoggerMiddleware 日志中间件
 func LoggerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 		path := c.Request.URL.Path
-		query := c.Request.URL.RawQuery
+		query := redactQuery(c.Request.URL.RawQuery)
 
 		c.Next()
 
@@ -28,3 +40,25 @@ func LoggerMiddleware() gin.HandlerFunc {
 		)
 	}
 }
+
+// redactQuery 将 token 等敏感参数的值替换为 ***，其余参数原样保留（解析失败时整体隐藏）
+func redactQuery(raw string) string {
+	if raw == "" {
+		return raw
+	}
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return "***"
+	}
+	redacted := false
+	for key := range values {
+		if sensitiveQueryKeys[strings.ToLower(key)] {
+			values[key] = []string{"***"}
+			redacted = true
+		}
+	}
+	if !redacted {
+		return raw
+	}
+	return values.Encode()
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"devops-cd/pkg/responses"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeaderAPIVersion 请求/响应中的 API 版本头
+const HeaderAPIVersion = "X-API-Version"
+
+// acceptVersionPattern Accept 头中的版本媒体类型，如 application/vnd.devops-cd.v2+json
+var acceptVersionPattern = regexp.MustCompile(`application/vnd\.devops-cd\.v(\d+)\+json`)
+
+// APIVersionMiddleware 协商本次请求的 API 版本
+//
+// 默认取路由组版本（/api/v1 -> 1，/api/v2 -> 2）；客户端可通过 X-API-Version 头或
+// Accept: application/vnd.devops-cd.vN+json 显式指定更高版本，低于路由组版本或超出 LatestAPIVersion 的值忽略
+// （/api/v2 下不能降级到 v1 行为）。协商结果写入 context 并在响应头 X-API-Version 中回显。
+func APIVersionMiddleware(defaultVersion int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		version := defaultVersion
+		if v, ok := requestedAPIVersion(c); ok && v >= defaultVersion {
+			version = v
+		}
+		setAPIVersion(c, version)
+		c.Next()
+	}
+}
+
+// Versioned 按协商版本选择 handler：取不高于请求版本的最高实现
+//
+// 用于单个接口在不同版本间行为不同的场景，未变化的接口无需使用。
+func Versioned(handlers map[int]gin.HandlerFunc) gin.HandlerFunc {
+	versions := make([]int, 0, len(handlers))
+	for v := range handlers {
+		versions = append(versions, v)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+	return func(c *gin.Context) {
+		requested := responses.APIVersion(c)
+		for _, v := range versions {
+			if v <= requested {
+				if v != requested {
+					// 接口未提供请求版本的实现，按实际处理版本回显
+					setAPIVersion(c, v)
+				}
+				handlers[v](c)
+				return
+			}
+		}
+		responses.ErrorWithCode(c, responses.CodeBadRequest, "接口不支持 API 版本 v"+strconv.Itoa(requested))
+	}
+}
+
+// Deprecated 标记 v1 中计划变更的接口：按 v1 处理的请求附加 Deprecation 头，
+// 并通过 Link 指向对应的 /api/v2 路径；sunset 非空时附加 Sunset 头（HTTP-date）
+func Deprecated(sunset string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if responses.APIVersion(c) < responses.APIV2 {
+			c.Header("Deprecation", "true")
+			if sunset != "" {
+				c.Header("Sunset", sunset)
+			}
+			if path := c.Request.URL.Path; strings.HasPrefix(path, "/api/v1/") {
+				c.Header("Link", "</api/v2/"+strings.TrimPrefix(path, "/api/v1/")+`>; rel="successor-version"`)
+			}
+		}
+		c.Next()
+	}
+}
+
+func requestedAPIVersion(c *gin.Context) (int, bool) {
+	raw := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(c.GetHeader(HeaderAPIVersion))), "v")
+	if raw == "" {
+		if m := acceptVersionPattern.FindStringSubmatch(c.GetHeader("Accept")); m != nil {
+			raw = m[1]
+		}
+	}
+	if raw == "" {
+		return 0, false
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < responses.APIV1 || v > responses.LatestAPIVersion {
+		return 0, false
+	}
+	return v, true
+}
+
+func setAPIVersion(c *gin.Context, version int) {
+	c.Set(responses.APIVersionKey, version)
+	c.Header(HeaderAPIVersion, strconv.Itoa(version))
+}
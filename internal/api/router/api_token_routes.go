@@ -0,0 +1,123 @@
+package router
+
+import (
+	"strconv"
+	"strings"
+
+	"devops-cd/internal/api/middleware"
+	"devops-cd/internal/pkg/auth"
+	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiTokenRoute API Token 可访问的接口：所需 scope，以及按请求解析资源所属项目
+type apiTokenRoute struct {
+	permission auth.Permission
+	// project 解析资源所属项目，限定项目的 token 必须命中；nil 表示接口已按项目校验（ProjectAuthWrapper）或按 project_ids 过滤结果
+	project func(c *gin.Context) (int64, error)
+}
+
+// apiTokenRoutes API Token 白名单（key: 方法 + 去掉 /api/vN 前缀的路由模板），未登记的接口一律拒绝
+func apiTokenRoutes(batchService *service.BatchService) map[string]apiTokenRoute {
+	batchByQuery := func(c *gin.Context) (int64, error) {
+		id, _ := strconv.ParseInt(c.Query("id"), 10, 64)
+		return batchService.BatchProjectID(id)
+	}
+	batchByParam := func(c *gin.Context) (int64, error) {
+		id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+		return batchService.BatchProjectID(id)
+	}
+	deploymentByParam := func(c *gin.Context) (int64, error) {
+		id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+		projectID, _, err := batchService.DeploymentScope(id)
+		return projectID, err
+	}
+	view := apiTokenRoute{permission: auth.PermBatchView}
+
+	return map[string]apiTokenRoute{
+		// 批次状态
+		"GET /batches":               view,
+		"GET /batch":                 {permission: auth.PermBatchView, project: batchByQuery},
+		"GET /batch/status":          {permission: auth.PermBatchView, project: batchByQuery},
+		"GET /batch/:id/watch":       {permission: auth.PermBatchView, project: batchByParam},
+		"GET /batch/:id/approvals":   {permission: auth.PermBatchView, project: batchByParam},
+		"GET /batch/:id/changelog":   view,
+		"GET /batch/:id/export":      view,
+		"GET /batch/:id/comments":    view,
+		"GET /batch/:id/activity":    view,
+		"GET /batch_templates":       view,
+		"GET /batch_templates/:id":   view,
+		"GET /dashboard":             view,
+		"GET /search":                view,
+		"GET /reports/quality":       view,
+		"GET /reports/adoption":      view,
+		"GET /reports/deployments":   view,
+		"GET /adhoc_deployments":     view,
+		"GET /adhoc_deployments/:id": view,
+		"GET /release_drifts":        view,
+		"GET /release_drifts/:id":    view,
+		"GET /app_decommissions":     view,
+		"GET /app_decommissions/:id": view,
+		// 部署状态
+		"GET /deployment/:id/logs":     view,
+		"GET /deployment/:id/events":   view,
+		"GET /deployment/:id/timeline": {permission: auth.PermBatchView, project: deploymentByParam},
+	}
+}
+
+// APITokenRouteGuard API Token 默认拒绝：只允许访问白名单中的接口，并校验 scope 与资源所属项目；用户 JWT 不受影响
+func APITokenRouteGuard(routes map[string]apiTokenRoute) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := middleware.APITokenFromContext(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		route, ok := routes[c.Request.Method+" "+versionlessPath(c.FullPath())]
+		if !ok || !apiTokenAllow(token, route.permission) {
+			responses.Error(c, responses.ErrForbidden)
+			c.Abort()
+			return
+		}
+		if route.project != nil && len(token.ProjectIDs) > 0 {
+			projectID, err := route.project(c)
+			if err != nil {
+				responses.Error(c, err)
+				c.Abort()
+				return
+			}
+			if !token.HasProject(projectID) {
+				responses.Error(c, responses.ErrForbidden)
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// ProjectScopeWrapper 列表接口的项目范围：API Token 限定项目时为其 project_ids，其余情况为 nil（不额外过滤）
+func ProjectScopeWrapper(handler func(c *gin.Context, projectIDs []int64)) func(*gin.Context) {
+	return func(context *gin.Context) {
+		var projectIDs []int64
+		if token, ok := middleware.APITokenFromContext(context); ok {
+			projectIDs = []int64(token.ProjectIDs)
+		}
+		handler(context, projectIDs)
+	}
+}
+
+// versionlessPath 去掉路由模板中的 /api/vN 前缀
+func versionlessPath(fullPath string) string {
+	rest, ok := strings.CutPrefix(fullPath, "/api/")
+	if !ok {
+		return fullPath
+	}
+	if i := strings.Index(rest, "/"); i >= 0 {
+		return rest[i:]
+	}
+	return ""
+}
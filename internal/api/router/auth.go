@@ -1,7 +1,11 @@
 package router
 
 import (
+	"devops-cd/internal/api/middleware"
+	"devops-cd/internal/model"
 	"devops-cd/internal/pkg/auth"
+	"devops-cd/pkg/constants"
+	"devops-cd/pkg/responses"
 	"github.com/gin-gonic/gin"
 
 	"devops-cd/internal/service"
@@ -9,19 +13,60 @@ import (
 
 var authz service.AuthorizationService
 
+// apiTokenAllow API Token 按 scopes 判断权限（不关联用户角色）
+func apiTokenAllow(token *model.APIToken, permission auth.Permission) bool {
+	scopes := make([]auth.Permission, 0, len(token.Scopes))
+	for _, scope := range token.Scopes {
+		scopes = append(scopes, auth.Permission(scope))
+	}
+	return auth.AllowPermissions(scopes, permission)
+}
+
 func ProjectAuthWrapper(handler func(c *gin.Context, canAccess func(username string, projectId int64) bool), permission auth.Permission) func(*gin.Context) {
 	return func(context *gin.Context) {
-		username := context.GetString("username")
-		authProvider := context.GetString("auth_type")
+		handler(context, projectAccess(context, permission))
+	}
+}
 
-		handler(context, func(_ string, projectID int64) bool {
-			return authz.CanAccessProject(username, authProvider, projectID, permission)
+// ProjectEnvAuthWrapper 按目标环境校验项目权限：prod 需要 env:prod:operate，其他环境需要 permission
+func ProjectEnvAuthWrapper(handler func(c *gin.Context, canAccess func(username string, projectId int64, env string) bool), permission auth.Permission) func(*gin.Context) {
+	return func(context *gin.Context) {
+		canAccess := projectAccess(context, permission)
+		canProdOperate := projectAccess(context, auth.PermProdOperate)
+		handler(context, func(username string, projectID int64, env string) bool {
+			if env == constants.EnvTypeProd {
+				return canProdOperate(username, projectID)
+			}
+			return canAccess(username, projectID)
 		})
 	}
 }
 
+// projectAccess 当前请求（用户或 API Token）对项目的权限校验
+func projectAccess(context *gin.Context, permission auth.Permission) func(username string, projectID int64) bool {
+	if token, ok := middleware.APITokenFromContext(context); ok {
+		return func(_ string, projectID int64) bool {
+			return token.HasProject(projectID) && apiTokenAllow(token, permission)
+		}
+	}
+
+	username := context.GetString("username")
+	authProvider := context.GetString("auth_type")
+
+	return func(_ string, projectID int64) bool {
+		return authz.CanAccessProject(username, authProvider, projectID, permission)
+	}
+}
+
 func TeamAuthWrapper(handler func(c *gin.Context, canAccess func(username string, teamID int64) bool), permission auth.Permission) func(*gin.Context) {
 	return func(context *gin.Context) {
+		if token, ok := middleware.APITokenFromContext(context); ok {
+			handler(context, func(_ string, _ int64) bool {
+				return len(token.ProjectIDs) == 0 && apiTokenAllow(token, permission)
+			})
+			return
+		}
+
 		username := context.GetString("username")
 		authProvider := context.GetString("auth_type")
 
@@ -31,3 +76,24 @@ func TeamAuthWrapper(handler func(c *gin.Context, canAccess func(username string
 		})
 	}
 }
+
+// SystemAuthMiddleware 系统级权限校验（仅看 users.system_roles），用于 /admin 等平台管理接口；API Token 不可访问
+func SystemAuthMiddleware(permission auth.Permission) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		if _, ok := middleware.APITokenFromContext(context); ok {
+			responses.Error(context, responses.ErrForbidden)
+			context.Abort()
+			return
+		}
+
+		username := context.GetString("username")
+		authProvider := context.GetString("auth_type")
+
+		if !authz.HasSystemPermission(username, authProvider, permission) {
+			responses.Error(context, responses.ErrForbidden)
+			context.Abort()
+			return
+		}
+		context.Next()
+	}
+}
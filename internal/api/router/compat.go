@@ -0,0 +1,61 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"devops-cd/pkg/responses"
+
+	"github.com/gin-gonic/gin"
+)
+
+// v1 -> v2 兼容层
+//
+// v2 接口复用 v1 handler，差异在此处或 responses 中按协商版本处理：
+//   - operator：所有版本一律取当前登录用户，请求体中的值被忽略（v1 仍接受该字段以兼容旧客户端）
+//   - 错误结构：v1 统一 HTTP 200（部分接口为 HTTP 状态码 + 原始 code）；v2 HTTP 状态码与 7 位业务码一致（见 responses.writeError）
+
+// operatorCompat 请求体携带 operator 的接口：任何版本都以登录用户覆盖 operator，
+// 避免客户端通过 X-API-Version / Accept 协商到旧版本后以请求体冒充操作人
+func operatorCompat(h gin.HandlerFunc) gin.HandlerFunc {
+	return operatorFromToken(h)
+}
+
+// operatorFromToken 将 JSON 请求体中的 operator 改写为当前登录用户后交给 h
+func operatorFromToken(h gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username := c.GetString("username")
+		if username == "" {
+			responses.ErrorWithCode(c, responses.CodeUnauthorized, "未登录")
+			return
+		}
+		if c.Request.Body == nil {
+			h(c)
+			return
+		}
+
+		raw, err := io.ReadAll(c.Request.Body)
+		_ = c.Request.Body.Close()
+		if err != nil {
+			responses.ErrorWithCode(c, responses.CodeBadRequest, "读取请求体失败")
+			return
+		}
+
+		body := map[string]json.RawMessage{}
+		if len(bytes.TrimSpace(raw)) > 0 {
+			if err := json.Unmarshal(raw, &body); err != nil {
+				// 非 JSON 对象交由 handler 按原逻辑报参数错误
+				c.Request.Body = io.NopCloser(bytes.NewReader(raw))
+				h(c)
+				return
+			}
+		}
+		body["operator"], _ = json.Marshal(username)
+		rewritten, _ := json.Marshal(body)
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(rewritten))
+		c.Request.ContentLength = int64(len(rewritten))
+		h(c)
+	}
+}
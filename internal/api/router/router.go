@@ -1,6 +1,10 @@
 package router
 
 import (
+	"net/http"
+	"time"
+
+	"devops-cd/internal/adapter/ci"
 	"devops-cd/internal/api/handler"
 	"devops-cd/internal/api/middleware"
 	"devops-cd/internal/core"
@@ -8,6 +12,7 @@ import (
 	"devops-cd/internal/pkg/config"
 	"devops-cd/internal/repository"
 	"devops-cd/internal/service"
+	"devops-cd/pkg/responses"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -27,6 +32,7 @@ func Setup(cfg *config.Config, coreEngine *core.CoreEngine, logger *zap.Logger)
 
 	// 全局中间件
 	r.Use(gin.Recovery())
+	r.Use(middleware.TracingMiddleware())
 	r.Use(middleware.LoggerMiddleware())
 	r.Use(middleware.CORSMiddleware())
 
@@ -53,76 +59,230 @@ func Setup(cfg *config.Config, coreEngine *core.CoreEngine, logger *zap.Logger)
 	teamRepo := repository.NewTeamRepository(db)
 	teamMemberRepo := repository.NewTeamMemberRepository(db)
 	credentialRepo := repository.NewCredentialRepository(db)
-	authz = service.NewAuthorizationService(userRepo, teamMemberRepo)
+	announcementRepo := repository.NewAnnouncementRepository(db)
+	enginePauseRepo := repository.NewEnginePauseRepository(db)
+	webhookSourceRepo := repository.NewWebhookSourceRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	notificationRuleRepo := repository.NewNotificationRuleRepository(db)
+	apiTokenRepo := repository.NewAPITokenRepository(db)
+	imageScanRepo := repository.NewImageScanRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	roleCache := service.NewRoleCache(roleRepo)
+	authz = service.NewAuthorizationService(userRepo, roleCache)
 
 	// 初始化Service
 	ldapService := service.NewLDAPService(&cfg.Auth.LDAP)
-	authService := service.NewAuthService(&cfg.Auth, userRepo, ldapService)
-	userService := service.NewUserService(userRepo)
+	oidcService := service.NewOIDCService(&cfg.Auth.OIDC, cfg.Auth.JWT.Secret)
+	authService := service.NewAuthService(&cfg.Auth, userRepo, teamRepo, teamMemberRepo, ldapService, oidcService)
+	userService := service.NewUserService(userRepo, roleRepo)
 	projectService := service.NewProjectService(projectRepo, teamRepo, projectEnvConfigRepo)
 	teamService := service.NewTeamService(teamRepo, projectRepo)
-	teamMemberService := service.NewTeamMemberService(logger, teamMemberRepo, teamRepo, userRepo)
+	teamMemberService := service.NewTeamMemberService(logger, teamMemberRepo, teamRepo, userRepo, roleCache)
 	repositoryService := service.NewRepositoryService(repositoryRepo, applicationRepo)
-	repoSourceService := service.NewRepoSourceService(repoSyncSourceRepo, teamRepo, cfg.Crypto.AESKey)
-	repoSyncService := service.NewRepoSyncService(db, logger, cfg.Crypto.AESKey)
+	repoSourceService := service.NewRepoSourceService(repoSyncSourceRepo, teamRepo)
+	repoSyncService := service.NewRepoSyncService(db, logger)
 	applicationService := service.NewApplicationService(applicationRepo, repositoryRepo, db, logger)
 	appEnvConfigService := service.NewAppEnvConfigService(appEnvConfigRepo, applicationRepo, db)
 	clusterService := service.NewClusterService(db)
+	valuesRenderService := service.NewValuesRenderService(db)
+	batchChangelogService := service.NewBatchChangelogService(db)
+	subscriptionService := service.NewSubscriptionService(db)
+	adhocDeploymentService := service.NewAdhocDeploymentService(db)
+	releaseDriftService := service.NewReleaseDriftService(db, cfg.Core.DriftCheck.LiveObjects)
+	appDecommissionService := service.NewAppDecommissionService(db)
+	idempotencyService := service.NewIdempotencyService(db, time.Duration(cfg.Server.IdempotencyTTL)*time.Second)
 	batchService := service.NewBatchService(db)
-	buildService := service.NewBuildService(buildRepo, repositoryRepo, applicationRepo, coreEngine)
+	batchService.SetBatchTrigger(coreEngine)
+	jobService := service.NewJobService(db)
+	searchService := service.NewSearchService(db)
+	if q := coreEngine.Jobs(); q != nil {
+		jobService.SetNotifier(q)
+	}
+	buildService := service.NewBuildService(buildRepo, repositoryRepo, applicationRepo, coreEngine, repoSyncService, batchService, cfg.Repo.BuildProvenance)
 	credentialService := service.NewCredentialService(credentialRepo)
+	announcementService := service.NewAnnouncementService(announcementRepo)
+	consistencyService := service.NewConsistencyService(db)
+	enginePauseService := service.NewEnginePauseService(db, enginePauseRepo)
+	webhookSourceService := service.NewWebhookSourceService(webhookSourceRepo)
+	webhookService := service.NewWebhookService(webhookRepo, projectRepo, coreEngine.Webhooks())
+	notificationRuleService := service.NewNotificationRuleService(notificationRuleRepo, projectRepo, teamRepo)
+	apiTokenService := service.NewAPITokenService(apiTokenRepo, projectRepo)
+	roleService := service.NewRoleService(roleRepo, userRepo, teamRepo, roleCache)
+	imageScanService := service.NewImageScanService(imageScanRepo, buildRepo)
 
 	// 初始化Handler
 	authHandler := handler.NewAuthHandler(authService)
 	userHandler := handler.NewUserHandler(userService)
+	permissionHandler := handler.NewPermissionHandler(authz)
 	projectHandler := handler.NewProjectHandler(projectService)
+	valuesRenderHandler := handler.NewValuesRenderHandler(valuesRenderService)
+	batchChangelogHandler := handler.NewBatchChangelogHandler(batchChangelogService)
+	subscriptionHandler := handler.NewSubscriptionHandler(subscriptionService)
+	adhocDeploymentHandler := handler.NewAdhocDeploymentHandler(adhocDeploymentService)
+	releaseDriftHandler := handler.NewReleaseDriftHandler(releaseDriftService)
+	appDecommissionHandler := handler.NewAppDecommissionHandler(appDecommissionService)
 	teamHandler := handler.NewTeamHandler(teamService)
 	teamMemberHandler := handler.NewTeamMemberHandler(teamMemberService)
-	repositoryHandler := handler.NewRepositoryHandler(repositoryService)
+	repositoryHandler := handler.NewRepositoryHandler(repositoryService, repoSyncService)
 	repoSourceHandler := handler.NewRepoSourceHandler(repoSourceService, repoSyncService)
 	applicationHandler := handler.NewApplicationHandler(applicationService)
 	appEnvConfigHandler := handler.NewAppEnvConfigHandler(appEnvConfigService)
 	clusterHandler := handler.NewClusterHandler(clusterService)
-	batchHandler := handler.NewBatchHandler(coreEngine, batchService)
+	batchHandler := handler.NewBatchHandler(coreEngine, batchService, announcementService)
 	buildHandler := handler.NewBuildHandler(buildService, batchService)
 	releaseAppHandler := handler.NewReleaseAppHandler(batchService)
 	credentialHandler := handler.NewCredentialHandler(credentialService)
 	deploymentHandler := handler.NewDeploymentHandler(batchService)
+	announcementHandler := handler.NewAnnouncementHandler(announcementService)
+	consistencyHandler := handler.NewConsistencyHandler(consistencyService)
+	enginePauseHandler := handler.NewEnginePauseHandler(enginePauseService)
+	jobHandler := handler.NewJobHandler(jobService)
+	artifactCacheHandler := handler.NewArtifactCacheHandler(coreEngine)
+	metaCacheHandler := handler.NewMetaCacheHandler(coreEngine)
+	coreHandler := handler.NewCoreHandler(coreEngine)
+	webhookSourceHandler := handler.NewWebhookSourceHandler(webhookSourceService, buildService)
+	ciHandler := handler.NewCIHandler(buildService, repositoryService, imageScanService, loadCIRules(cfg.CI.RulesFile, logger), cfg.CI)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+	notificationRuleHandler := handler.NewNotificationRuleHandler(notificationRuleService)
+	apiTokenHandler := handler.NewAPITokenHandler(apiTokenService)
+	roleHandler := handler.NewRoleHandler(roleService)
+	reportHandler := handler.NewReportHandler(batchService)
+	dashboardHandler := handler.NewDashboardHandler(batchService)
+	searchHandler := handler.NewSearchHandler(searchService)
+	batchTemplateHandler := handler.NewBatchTemplateHandler(batchService)
+	imageScanHandler := handler.NewImageScanHandler(imageScanService)
+
+	// v1 中计划变更的接口（operator 由请求体传入、错误结构不统一）标记为废弃，新客户端使用 /api/v2
+	deprecated := middleware.Deprecated(v1SunsetHeader(cfg.Server.V1Sunset, logger))
+
+	// API Token 可访问的接口（默认拒绝）
+	tokenRoutes := apiTokenRoutes(batchService)
 
 	// API v1
-	v1 := r.Group("/api/v1")
+	v1 := r.Group("/api/v1", middleware.APIVersionMiddleware(responses.APIV1))
 	{
 		// 认证相关(无需token)
 		authGroup := v1.Group("/auth")
 		{
 			authGroup.POST("/login", authHandler.Login)
 			authGroup.POST("/refresh", authHandler.Refresh)
+			authGroup.GET("/oidc/authorize", authHandler.OIDCAuthorize)
+			authGroup.POST("/oidc/callback", authHandler.OIDCCallback)
 		}
 
 		// 需要认证的路由
 		authed := v1.Group("")
-		authed.Use(middleware.AuthMiddleware())
+		authed.Use(middleware.AuthMiddleware(apiTokenService), APITokenRouteGuard(tokenRoutes), middleware.Idempotency(idempotencyService))
 		{
 			// 认证信息
 			authed.GET("/auth/me", authHandler.GetMe)
 			authed.GET("/auth/verify", authHandler.Verify)
 			authed.GET("/users/search", userHandler.Search)
 			authed.GET("/roles", userHandler.ListRoles)
+			authed.GET("/permissions", permissionHandler.Mine)       // 当前用户在项目下的有效权限（query: project_id）
+			authed.GET("/announcements", announcementHandler.Active) // 当前生效的公告（UI banner）
+
+			// 平台管理（仅系统级角色）
+			adminGroup := authed.Group("/admin")
+			{
+				adminAnnouncements := adminGroup.Group("/announcements", SystemAuthMiddleware(auth.PermAnnouncementManage))
+				adminAnnouncements.POST("", announcementHandler.Create)
+				adminAnnouncements.GET("", announcementHandler.List)
+				adminAnnouncements.PUT("/:id", announcementHandler.Update)
+				adminAnnouncements.DELETE("/:id", announcementHandler.Delete)
+
+				adminConsistency := adminGroup.Group("/consistency", SystemAuthMiddleware(auth.PermConsistencyManage))
+				adminConsistency.GET("", consistencyHandler.Check)
+				adminConsistency.POST("/repair", consistencyHandler.Repair)
+
+				adminEngine := adminGroup.Group("/engine", SystemAuthMiddleware(auth.PermEngineManage))
+				adminEngine.GET("/pauses", enginePauseHandler.List)
+				adminEngine.POST("/pauses", enginePauseHandler.Pause)
+				adminEngine.POST("/pauses/:id/resume", enginePauseHandler.Resume)
+				adminEngine.GET("/artifact-cache", artifactCacheHandler.Stats)
+				adminEngine.GET("/meta-cache", metaCacheHandler.Stats)
+				adminEngine.POST("/meta-cache/flush", metaCacheHandler.Flush)
+
+				// 引擎运行状态与手动扫描；事故期间按批次/集群暂停处理（与 /engine/pauses 相同）
+				adminCore := adminGroup.Group("/core", SystemAuthMiddleware(auth.PermEngineManage))
+				adminCore.GET("", coreHandler.Status)
+				adminCore.POST("/scan", coreHandler.Scan)
+				adminCore.GET("/pauses", enginePauseHandler.List)
+				adminCore.POST("/pauses", enginePauseHandler.Pause)
+				adminCore.POST("/pauses/:id/resume", enginePauseHandler.Resume)
+
+				adminWebhook := adminGroup.Group("/webhook_sources", SystemAuthMiddleware(auth.PermWebhookManage))
+				adminWebhook.GET("", webhookSourceHandler.List)
+				adminWebhook.POST("", webhookSourceHandler.Create)
+				adminWebhook.POST("/preview", webhookSourceHandler.PreviewMapping) // 未保存的映射预览
+				adminWebhook.GET("/:id", webhookSourceHandler.Get)
+				adminWebhook.PUT("/:id", webhookSourceHandler.Update)
+				adminWebhook.DELETE("/:id", webhookSourceHandler.Delete)
+				adminWebhook.POST("/:id/preview", webhookSourceHandler.Preview)
+
+				// 出站 Webhook 事件订阅
+				adminWebhooks := adminGroup.Group("/webhooks", SystemAuthMiddleware(auth.PermWebhookManage))
+				adminWebhooks.GET("", webhookHandler.List)
+				adminWebhooks.POST("", webhookHandler.Create)
+				adminWebhooks.GET("/events", webhookHandler.Events)
+				adminWebhooks.GET("/:id", webhookHandler.Get)
+				adminWebhooks.PUT("/:id", webhookHandler.Update)
+				adminWebhooks.DELETE("/:id", webhookHandler.Delete)
+				adminWebhooks.POST("/:id/test", webhookHandler.Test)
+				adminWebhooks.GET("/:id/deliveries", webhookHandler.ListDeliveries)
+				adminWebhooks.POST("/:id/deliveries/:delivery_id/redeliver", webhookHandler.Redeliver)
+
+				// 通知路由规则（按项目/团队、类型与严重级别路由到不同 Lark 群）
+				adminNotificationRules := adminGroup.Group("/notification_rules", SystemAuthMiddleware(auth.PermNotificationManage))
+				adminNotificationRules.GET("", notificationRuleHandler.List)
+				adminNotificationRules.POST("", notificationRuleHandler.Create)
+				adminNotificationRules.GET("/options", notificationRuleHandler.Options)
+				adminNotificationRules.GET("/:id", notificationRuleHandler.Get)
+				adminNotificationRules.PUT("/:id", notificationRuleHandler.Update)
+				adminNotificationRules.DELETE("/:id", notificationRuleHandler.Delete)
+
+				// 角色/权限管理（自定义角色与用户、团队角色分配）
+				adminRoles := adminGroup.Group("/roles", SystemAuthMiddleware(auth.PermRoleManage))
+				adminRoles.GET("", roleHandler.List)
+				adminRoles.POST("", roleHandler.Create)
+				adminRoles.GET("/permissions", roleHandler.Permissions)
+				adminRoles.GET("/:id", roleHandler.Get)
+				adminRoles.PUT("/:id", roleHandler.Update)
+				adminRoles.DELETE("/:id", roleHandler.Delete)
+				adminGroup.PUT("/users/:id/roles", SystemAuthMiddleware(auth.PermRoleManage), roleHandler.AssignUserRoles)
+				adminGroup.PUT("/teams/:id/roles", SystemAuthMiddleware(auth.PermRoleManage), roleHandler.AssignTeamRoles)
+
+				// 集成方只读 API Token
+				adminAPITokens := adminGroup.Group("/api_tokens", SystemAuthMiddleware(auth.PermAPITokenManage))
+				adminAPITokens.GET("", apiTokenHandler.List)
+				adminAPITokens.POST("", apiTokenHandler.Create)
+				adminAPITokens.GET("/scopes", apiTokenHandler.Scopes)
+				adminAPITokens.POST("/:id/revoke", apiTokenHandler.Revoke)
+			}
+
+			// 异步任务（Deployment 执行等）：查看与死信重新入队
+			jobs := authed.Group("/jobs", SystemAuthMiddleware(auth.PermJobManage))
+			{
+				jobs.GET("", jobHandler.List)
+				jobs.GET("/:id", jobHandler.Get)
+				jobs.POST("/:id/retry", jobHandler.Retry)
+			}
 
 			// 项目管理
 			groupProject := authed.Group("/project")
 			groupProjects := authed.Group("/projects")
 			{
-				groupProject.POST("", projectHandler.Create)                                         // 创建项目
-				groupProjects.GET("", projectHandler.List)                                           // 列表查询（无参数返回全部，有分页参数返回分页数据）
-				groupProject.GET("", projectHandler.GetByID)                                         // 获取详情（支持 with_teams 参数）
-				groupProject.PUT("", projectHandler.Update)                                          // 更新项目
-				groupProject.DELETE("/:id", projectHandler.Delete)                                   // 删除项目
-				groupProjects.GET("/available-env-clusters", projectHandler.GetAvailableEnvClusters) // 获取项目可用的环境集群配置
+				groupProject.POST("", projectHandler.Create)                                            // 创建项目
+				groupProjects.GET("", projectHandler.List)                                              // 列表查询（无参数返回全部，有分页参数返回分页数据）
+				groupProject.GET("", projectHandler.GetByID)                                            // 获取详情（支持 with_teams 参数）
+				groupProject.PUT("", ProjectAuthWrapper(projectHandler.Update, auth.PermProjectPolicy)) // 更新项目（策略字段需要 project:policy:update）
+				groupProject.DELETE("/:id", projectHandler.Delete)                                      // 删除项目
+				groupProjects.GET("/available-env-clusters", projectHandler.GetAvailableEnvClusters)    // 获取项目可用的环境集群配置
 
 				// 项目环境配置管理（作为项目的附属资源）
-				groupProject.GET("/:id/env", projectHandler.GetEnvConfigs)    // 获取项目的环境配置
-				groupProject.PUT("/:id/env", projectHandler.UpdateEnvConfigs) // 批量更新项目的环境配置
+				groupProject.GET("/:id/env", projectHandler.GetEnvConfigs)                                                           // 获取项目的环境配置
+				groupProject.PUT("/:id/env", projectHandler.UpdateEnvConfigs)                                                        // 批量更新项目的环境配置
+				groupProject.POST("/:id/env/:env/render-values", ProjectAuthWrapper(valuesRenderHandler.Render, auth.PermBatchView)) // 预览 values 渲染结果
 			}
 
 			// 凭据管理（系统内加密存储；不回传明文）
@@ -148,21 +308,24 @@ func Setup(cfg *config.Config, coreEngine *core.CoreEngine, logger *zap.Logger)
 
 			teamMemberGroup := authed.Group("/team_members")
 			{
-				teamMemberGroup.POST("", teamMemberHandler.AddMember)          // 添加成员
-				teamMemberGroup.GET("", teamMemberHandler.ListMembers)         // 成员列表
-				teamMemberGroup.PUT("/:id/role", teamMemberHandler.UpdateRole) // 更新角色
-				teamMemberGroup.DELETE("/:id", teamMemberHandler.DeleteMember) // 移除成员
+				teamMemberGroup.POST("", ProjectAuthWrapper(teamMemberHandler.AddMember, auth.PermProdOperate))          // 添加成员
+				teamMemberGroup.GET("", teamMemberHandler.ListMembers)                                                   // 成员列表
+				teamMemberGroup.PUT("/:id/role", ProjectAuthWrapper(teamMemberHandler.UpdateRole, auth.PermProdOperate)) // 更新角色
+				teamMemberGroup.DELETE("/:id", teamMemberHandler.DeleteMember)                                           // 移除成员
 			}
 
 			// 代码库管理
 			groupRepository := authed.Group("/repository")
 			groupRepositories := authed.Group("/repositories")
 			{
-				groupRepository.POST("", repositoryHandler.Create)        // 创建代码库
-				groupRepositories.GET("", repositoryHandler.List)         // 列表查询
-				groupRepository.GET("", repositoryHandler.GetByID)        // 获取详情（query参数id，包含应用列表）
-				groupRepository.PUT("", repositoryHandler.Update)         // 更新代码库（JSON包含id）
-				groupRepository.POST("/delete", repositoryHandler.Delete) // 删除代码库（软删除，JSON包含id）
+				groupRepository.POST("", repositoryHandler.Create)                             // 创建代码库
+				groupRepositories.GET("", repositoryHandler.List)                              // 列表查询
+				groupRepository.GET("", repositoryHandler.GetByID)                             // 获取详情（query参数id，包含应用列表）
+				groupRepository.PUT("", repositoryHandler.Update)                              // 更新代码库（JSON包含id）
+				groupRepository.POST("/delete", repositoryHandler.Delete)                      // 删除代码库（软删除，JSON包含id）
+				groupRepository.GET("/manifest", repositoryHandler.GetManifest)                // devops-cd.yaml 最近一次同步报告（query参数id）
+				groupRepository.POST("/manifest/sync", repositoryHandler.SyncManifest)         // 按 devops-cd.yaml 同步（JSON包含id、dry_run）
+				groupRepository.POST("/manifest/validate", repositoryHandler.ValidateManifest) // 校验 devops-cd.yaml（JSON包含id、ref、content），不落库
 			}
 
 			// 仓库源管理
@@ -180,16 +343,17 @@ func Setup(cfg *config.Config, coreEngine *core.CoreEngine, logger *zap.Logger)
 			groupApplication := authed.Group("/application")
 			groupApplications := authed.Group("/applications")
 			{
-				groupApplication.POST("", applicationHandler.Create)                             // 创建应用
-				groupApplications.GET("", applicationHandler.List)                               // 列表查询
-				groupApplication.GET("", applicationHandler.GetByID)                             // 获取详情（query参数id）
-				groupApplication.PUT("", applicationHandler.Update)                              // 更新应用（JSON包含id）
-				groupApplication.POST("/delete", applicationHandler.Delete)                      // 删除应用（软删除，JSON包含id）
-				groupApplication.GET("/builds", applicationHandler.GetBuilds)                    // 获取构建历史（query参数id）
-				groupApplication.GET("/types", applicationHandler.GetAppTypes)                   // 获取应用类型列表
-				groupApplication.GET("/:id/dependencies", applicationHandler.GetDependencies)    // 获取默认依赖
-				groupApplication.PUT("/:id/dependencies", applicationHandler.UpdateDependencies) // 更新默认依赖
-				authed.GET("/application_builds", applicationHandler.SearchWithBuilds)           // 搜索应用（包含构建信息，支持模糊查询）
+				groupApplication.POST("", applicationHandler.Create)                                 // 创建应用
+				groupApplications.GET("", applicationHandler.List)                                   // 列表查询
+				groupApplication.GET("", applicationHandler.GetByID)                                 // 获取详情（query参数id）
+				groupApplication.PUT("", applicationHandler.Update)                                  // 更新应用（JSON包含id）
+				groupApplication.POST("/delete", applicationHandler.Delete)                          // 删除应用（软删除，JSON包含id）
+				groupApplication.GET("/builds", applicationHandler.GetBuilds)                        // 获取构建历史（query参数id）
+				groupApplication.GET("/types", applicationHandler.GetAppTypes)                       // 获取应用类型列表
+				groupApplication.GET("/:id/dependencies", applicationHandler.GetDependencies)        // 获取默认依赖
+				groupApplication.PUT("/:id/dependencies", applicationHandler.UpdateDependencies)     // 更新默认依赖
+				groupApplication.GET("/:id/deploy-durations", applicationHandler.GetDeployDurations) // 历史部署耗时（平均/P95）
+				authed.GET("/application_builds", applicationHandler.SearchWithBuilds)               // 搜索应用（包含构建信息，支持模糊查询）
 			}
 
 			// 应用环境配置管理
@@ -206,11 +370,12 @@ func Setup(cfg *config.Config, coreEngine *core.CoreEngine, logger *zap.Logger)
 			// 集群管理
 			clusterGroup := authed.Group("/clusters")
 			{
-				clusterGroup.POST("", clusterHandler.Create)       // 创建集群
-				clusterGroup.GET("", clusterHandler.List)          // 查询集群列表
-				clusterGroup.GET("/:id", clusterHandler.Get)       // 获取集群详情
-				clusterGroup.PUT("/:id", clusterHandler.Update)    // 更新集群
-				clusterGroup.DELETE("/:id", clusterHandler.Delete) // 删除集群
+				clusterGroup.POST("", clusterHandler.Create)                  // 创建集群
+				clusterGroup.GET("", clusterHandler.List)                     // 查询集群列表
+				clusterGroup.GET("/:id", clusterHandler.Get)                  // 获取集群详情
+				clusterGroup.PUT("/:id", clusterHandler.Update)               // 更新集群
+				clusterGroup.DELETE("/:id", clusterHandler.Delete)            // 删除集群
+				clusterGroup.POST("/:id/preflight", clusterHandler.Preflight) // 集群连通性预检
 			}
 
 			// 批次管理
@@ -218,37 +383,133 @@ func Setup(cfg *config.Config, coreEngine *core.CoreEngine, logger *zap.Logger)
 			groupBatches := authed.Group("/batches")
 			{
 				// 写操作（POST/PUT）
-				groupBatch.POST("", ProjectAuthWrapper(batchHandler.Create, auth.PermBatchCreate)) // 创建批次
-				groupBatch.PUT("", ProjectAuthWrapper(batchHandler.Update, auth.PermBatchUpdate))  // 更新批次
-				groupBatch.POST("/delete", batchHandler.Delete)                                    // 软删除
-				groupBatch.PUT("/release_app", releaseAppHandler.UpdateBuilds)                     // 更新发布应用（构建版本等）
+				groupBatch.POST("", deprecated, ProjectAuthWrapper(batchHandler.Create, auth.PermBatchCreate))                // 创建批次
+				groupBatch.PUT("", deprecated, ProjectAuthWrapper(batchHandler.Update, auth.PermBatchUpdate))                 // 更新批次
+				groupBatch.POST("/delete", deprecated, operatorCompat(batchHandler.Delete))                                   // 软删除
+				groupBatch.PUT("/release_app", deprecated, operatorCompat(releaseAppHandler.UpdateBuilds))                    // 更新发布应用（构建版本等）
+				groupBatch.POST("/from-template", ProjectAuthWrapper(batchTemplateHandler.CreateBatch, auth.PermBatchCreate)) // 根据模板创建草稿批次
+				groupBatch.POST("/:id/plan", ProjectAuthWrapper(batchHandler.Plan, auth.PermBatchView))                       // 预览部署计划（不修改状态）
+				groupBatch.POST("/:id/split", ProjectAuthWrapper(batchHandler.Split, auth.PermBatchUpdate))                   // 拆分草稿批次（选中应用移入新批次）
+				groupBatch.POST("/:id/merge", ProjectAuthWrapper(batchHandler.Merge, auth.PermBatchUpdate))                   // 合并草稿批次（并入另一批次的应用）
+				groupBatch.POST("/import", ProjectAuthWrapper(batchHandler.Import, auth.PermBatchCreate))                     // 导入批次导出包为草稿批次（应用按名称映射）
 
 				// 读操作（GET）
-				groupBatch.GET("", batchHandler.Get)              // 获取详情（query: id）
-				groupBatch.GET("/status", batchHandler.GetStatus) // 获取批次状态（轻量级，用于轮询）
-				groupBatches.GET("", batchHandler.List)           // 列表查询（query: page, page_size, status, initiator）
+				groupBatch.GET("", batchHandler.Get)                                                                // 获取详情（query: id）
+				groupBatch.GET("/status", batchHandler.GetStatus)                                                   // 获取批次状态（轻量级，用于轮询）
+				groupBatch.GET("/:id/impact", batchHandler.GetImpact)                                               // 批次部署影响（部署前后资源/副本差值）
+				groupBatch.GET("/:id/retro", batchHandler.GetRetro)                                                 // 批次复盘数据（故障/回滚记录）
+				groupBatch.GET("/:id/watch", batchHandler.Watch)                                                    // SSE 推送批次/应用/部署状态变更
+				groupBatch.GET("/:id/approvals", batchHandler.GetApprovals)                                         // 审批进度（多人/分阶段审批）
+				groupBatch.GET("/:id/changelog", ProjectAuthWrapper(batchChangelogHandler.Get, auth.PermBatchView)) // 批次变更日志（query: format=json/markdown）
+				groupBatch.GET("/:id/export", ProjectAuthWrapper(batchHandler.Export, auth.PermBatchView))          // 导出已封板批次（JSON 导出包）
+				groupBatches.GET("", ProjectScopeWrapper(batchHandler.List))                                        // 列表查询（query: page, page_size, status, initiator）
 
 				// 审批操作
-				groupBatch.POST("/approve", batchHandler.Approve) // 审批通过
-				groupBatch.POST("/reject", batchHandler.Reject)   // 审批拒绝
+				groupBatch.POST("/approve", deprecated, operatorCompat(batchHandler.Approve)) // 审批通过
+				groupBatch.POST("/reject", deprecated, operatorCompat(batchHandler.Reject))   // 审批拒绝
+				authed.GET("/approvals/pending", batchHandler.PendingApprovals)               // 待当前用户审批的批次
 
 				// 状态操作
-				groupBatch.POST("/action", batchHandler.ProcessAction) // 状态流转
+				groupBatch.POST("/action", deprecated, operatorCompat(ProjectAuthWrapper(batchHandler.ProcessAction, auth.PermProdOperate))) // 状态流转
+				groupBatch.POST("/rollback", ProjectAuthWrapper(batchHandler.Rollback, auth.PermProdOperate))                                // 回滚批次到发布前版本
+				groupBatch.PUT("/:id/schedule", ProjectAuthWrapper(batchHandler.SetSchedule, auth.PermProdOperate))                          // 设置定时预发布/生产部署
+				groupBatch.DELETE("/:id/schedule", ProjectAuthWrapper(batchHandler.CancelSchedule, auth.PermProdOperate))                    // 取消定时部署（query: env）
+				groupBatch.POST("/:id/vuln_override", ProjectAuthWrapper(batchHandler.SetVulnOverride, auth.PermVulnOverride))               // 豁免镜像漏洞门禁
+				groupBatch.DELETE("/:id/vuln_override", ProjectAuthWrapper(batchHandler.CancelVulnOverride, auth.PermVulnOverride))          // 取消漏洞门禁豁免
+
+				// 复盘记录（仅已完成批次）
+				groupBatch.POST("/:id/incidents", ProjectAuthWrapper(batchHandler.CreateIncident, auth.PermBatchUpdate))                // 记录发布后故障
+				groupBatch.DELETE("/:id/incidents/:incident_id", ProjectAuthWrapper(batchHandler.DeleteIncident, auth.PermBatchUpdate)) // 删除故障记录
+				groupBatch.POST("/:id/rollbacks", ProjectAuthWrapper(batchHandler.CreateRollback, auth.PermBatchUpdate))                // 记录发布后回滚
+				groupBatch.DELETE("/:id/rollbacks/:rollback_id", ProjectAuthWrapper(batchHandler.DeleteRollback, auth.PermBatchUpdate)) // 删除回滚记录
+
+				// 评论与动态（评论仅作者可编辑/删除）
+				groupBatch.GET("/:id/comments", ProjectAuthWrapper(batchHandler.ListComments, auth.PermBatchView))                 // 批次评论（query: release_app_id）
+				groupBatch.POST("/:id/comments", ProjectAuthWrapper(batchHandler.CreateComment, auth.PermBatchView))               // 发表批次/发布应用评论
+				groupBatch.PUT("/:id/comments/:comment_id", ProjectAuthWrapper(batchHandler.UpdateComment, auth.PermBatchView))    // 编辑评论
+				groupBatch.DELETE("/:id/comments/:comment_id", ProjectAuthWrapper(batchHandler.DeleteComment, auth.PermBatchView)) // 删除评论
+				groupBatch.GET("/:id/activity", ProjectAuthWrapper(batchHandler.GetActivity, auth.PermBatchView))                  // 批次动态（评论、审批、状态变更、手动操作）
+			}
+
+			// 批次/应用订阅（状态变更私聊/邮件通知订阅人）
+			subscriptionGroup := authed.Group("/subscriptions")
+			{
+				subscriptionGroup.GET("", subscriptionHandler.List)                                            // 我的订阅（query: target_type）
+				subscriptionGroup.POST("", ProjectAuthWrapper(subscriptionHandler.Create, auth.PermBatchView)) // 订阅批次/应用
+				subscriptionGroup.DELETE("/:id", subscriptionHandler.Delete)                                   // 取消订阅
+			}
+
+			// 批次模板
+			batchTemplateGroup := authed.Group("/batch_templates")
+			{
+				batchTemplateGroup.GET("", ProjectAuthWrapper(batchTemplateHandler.List, auth.PermBatchView))            // 模板列表（query: project_id）
+				batchTemplateGroup.GET("/:id", ProjectAuthWrapper(batchTemplateHandler.Get, auth.PermBatchView))         // 模板详情
+				batchTemplateGroup.POST("", ProjectAuthWrapper(batchTemplateHandler.Create, auth.PermBatchCreate))       // 创建模板
+				batchTemplateGroup.PUT("/:id", ProjectAuthWrapper(batchTemplateHandler.Update, auth.PermBatchCreate))    // 更新模板
+				batchTemplateGroup.DELETE("/:id", ProjectAuthWrapper(batchTemplateHandler.Delete, auth.PermBatchCreate)) // 删除模板
+			}
+
+			// 首页汇总（进行中的批次/部署、最近失败、待我审批）
+			authed.GET("/dashboard", ProjectAuthWrapper(dashboardHandler.Get, auth.PermBatchView))
+			authed.GET("/search", ProjectAuthWrapper(searchHandler.Search, auth.PermBatchView))
+
+			// 报表
+			reportGroup := authed.Group("/reports")
+			{
+				reportGroup.GET("/quality", ProjectAuthWrapper(reportHandler.Quality, auth.PermBatchView))         // 发布质量（变更失败率/故障/回滚趋势）
+				reportGroup.GET("/adoption", ProjectAuthWrapper(reportHandler.Adoption, auth.PermBatchView))       // 平台使用情况（批次/应用接入/自动化率/审批时延）
+				reportGroup.GET("/deployments", ProjectAuthWrapper(reportHandler.Deployments, auth.PermBatchView)) // 部署统计（部署频率/交付时长/成功率/MTTR，支持 CSV 导出）
 			}
 
 			// 发布应用配置
 			releaseAppGroup := authed.Group("/release_app")
 			{
 				releaseAppGroup.GET("", releaseAppHandler.GetByID) // 获取发布应用详情
-				releaseAppGroup.PUT(":id/dependencies", releaseAppHandler.UpdateDependencies)
-				releaseAppGroup.POST("/switch_version", batchHandler.SwitchVersion) // 切换版本
-				releaseAppGroup.POST("/manual_deploy", batchHandler.ManualDeploy)   // 手动部署
+				releaseAppGroup.PUT(":id/dependencies", deprecated, operatorCompat(releaseAppHandler.UpdateDependencies))
+				releaseAppGroup.PUT("/:id/values_override", ProjectAuthWrapper(releaseAppHandler.UpdateValuesOverride, auth.PermBatchUpdate))             // values 覆盖（封板前）
+				releaseAppGroup.POST("/switch_version", deprecated, operatorCompat(ProjectAuthWrapper(batchHandler.SwitchVersion, auth.PermProdOperate))) // 切换版本
+				releaseAppGroup.POST("/manual_deploy", deprecated, operatorCompat(ProjectAuthWrapper(batchHandler.ManualDeploy, auth.PermProdOperate)))   // 手动部署
+				releaseAppGroup.POST("/rollback", ProjectAuthWrapper(batchHandler.RollbackReleaseApp, auth.PermProdOperate))                              // 回滚到发布前版本
+				releaseAppGroup.POST("/rollout", ProjectAuthWrapper(batchHandler.RolloutReleaseApp, auth.PermProdOperate))                                // 生产灰度 promote/abort
+				releaseAppGroup.POST("/redeploy", ProjectAuthWrapper(batchHandler.RedeployClusters, auth.PermProdOperate))                                // 按集群重新部署
+			}
+
+			// 临时部署（批次外手动部署，prod 需要 prod_operator）
+			adhocGroup := authed.Group("/adhoc_deployments")
+			{
+				adhocGroup.GET("", ProjectAuthWrapper(adhocDeploymentHandler.List, auth.PermBatchView))       // 临时部署列表（query: project_id）
+				adhocGroup.GET("/:id", ProjectAuthWrapper(adhocDeploymentHandler.Get, auth.PermBatchView))    // 临时部署详情
+				adhocGroup.POST("", ProjectEnvAuthWrapper(adhocDeploymentHandler.Create, auth.PermBatchFlow)) // 发起临时部署
+			}
+
+			// 配置漂移（集群中 helm release 与最近一次部署不一致）
+			releaseDriftGroup := authed.Group("/release_drifts")
+			{
+				releaseDriftGroup.GET("", ProjectAuthWrapper(releaseDriftHandler.List, auth.PermBatchView))             // 配置漂移列表（query: project_id）
+				releaseDriftGroup.GET("/:id", ProjectAuthWrapper(releaseDriftHandler.Get, auth.PermBatchView))          // 配置漂移详情
+				releaseDriftGroup.POST("/:id/check", ProjectAuthWrapper(releaseDriftHandler.Check, auth.PermBatchView)) // 立即重新检测（只读取集群）
+			}
+
+			// 应用下线（可选卸载 release，归档构建并删除应用；涉及 prod 需要 prod_operator）
+			appDecommissionGroup := authed.Group("/app_decommissions")
+			{
+				appDecommissionGroup.GET("", ProjectAuthWrapper(appDecommissionHandler.List, auth.PermBatchView))       // 应用下线列表（query: project_id）
+				appDecommissionGroup.GET("/:id", ProjectAuthWrapper(appDecommissionHandler.Get, auth.PermBatchView))    // 应用下线详情
+				appDecommissionGroup.POST("", ProjectEnvAuthWrapper(appDecommissionHandler.Create, auth.PermBatchFlow)) // 发起应用下线
 			}
 
 			// Deployment 任务管理
 			deploymentGroup := authed.Group("/deployment")
 			{
-				deploymentGroup.POST("/:id/retry", deploymentHandler.Retry) // 手动重试 deployment
+				deploymentGroup.POST("/:id/retry", deprecated, operatorCompat(ProjectEnvAuthWrapper(deploymentHandler.Retry, auth.PermProdOperate)))                  // 手动重试 deployment
+				deploymentGroup.POST("/:id/redeploy", deprecated, operatorCompat(ProjectEnvAuthWrapper(deploymentHandler.RedeployConfigChart, auth.PermProdOperate))) // 单独重新部署 config chart
+				deploymentGroup.GET("/config_chart/drift", deploymentHandler.ConfigChartDrift)                                                                        // config chart 版本核对
+				deploymentGroup.GET("/:id/diff", ProjectEnvAuthWrapper(deploymentHandler.GetDiff, auth.PermBatchView))                                                // prod 部署前 manifest diff
+				deploymentGroup.POST("/:id/diff/ack", ProjectEnvAuthWrapper(deploymentHandler.AckDiff, auth.PermProdOperate))                                         // 确认 diff（涉及受保护资源时）
+				deploymentGroup.GET("/:id/diff/preview", ProjectEnvAuthWrapper(deploymentHandler.PreviewDiff, auth.PermBatchView))                                    // 按需计算 diff（不保存）
+				deploymentGroup.GET("/:id/logs", ProjectEnvAuthWrapper(deploymentHandler.Logs, auth.PermBatchView))                                                   // pod 日志（follow=true 时 SSE 推送）
+				deploymentGroup.GET("/:id/events", ProjectEnvAuthWrapper(deploymentHandler.Events, auth.PermBatchView))                                               // Kubernetes 事件（follow=true 时 SSE 推送）
+				deploymentGroup.GET("/:id/timeline", ProjectEnvAuthWrapper(deploymentHandler.Timeline, auth.PermBatchView))                                           // 执行时间线
 			}
 
 			// 构建记录管理
@@ -258,12 +519,78 @@ func Setup(cfg *config.Config, coreEngine *core.CoreEngine, logger *zap.Logger)
 				groupBuilds.GET("", buildHandler.List)                 // 列表查询
 				groupBuild.GET("", buildHandler.GetByID)               // 获取详情（query参数id）
 				groupBuild.GET("/app", buildHandler.GetByAppAndNumber) // 按应用和构建号查询
+				authed.GET("/image_scans", imageScanHandler.List)      // 镜像漏洞扫描结果（query: build_id / image / tag）
 			}
 		}
 
 		// 构建通知（无需认证，由Drone调用）
 		v1.POST("/build/notify", buildHandler.Notify)
+		// GitLab CI 构建通知（按 ci.rules_file 映射应用）
+		v1.POST("/build/notify/gitlab", ciHandler.GitLab)
+		// GitHub Actions 构建通知（校验 X-Hub-Signature-256）
+		v1.POST("/build/notify/github", ciHandler.GitHub)
+		// Jenkins 构建通知（Notification 插件，按代码库 token 校验）
+		v1.POST("/build/notify/jenkins", ciHandler.Jenkins)
+		// Harbor 镜像推送/扫描通知（标记构建镜像可用 / 创建构建 / 记录扫描结果）
+		v1.POST("/build/notify/harbor", ciHandler.Harbor)
+		// CI 上报 Trivy 镜像扫描报告（漏洞门禁数据来源）
+		v1.POST("/build/notify/trivy", ciHandler.Trivy)
+		// 通用 CI 构建通知（按来源字段映射，来源可配置 token）
+		v1.POST("/build/notify/:source", webhookSourceHandler.Notify)
+	}
+
+	// API v2：仅包含相对 v1 有不兼容变更的接口，其余接口继续使用 /api/v1
+	//   - operator 取当前登录用户，不再由请求体传入
+	//   - 错误响应 HTTP 状态码与业务码一致（见 responses.writeError）
+	v2 := r.Group("/api/v2", middleware.APIVersionMiddleware(responses.APIV2), middleware.AuthMiddleware(apiTokenService), APITokenRouteGuard(tokenRoutes), middleware.Idempotency(idempotencyService))
+	{
+		v2Batch := v2.Group("/batch")
+		{
+			v2Batch.POST("", ProjectAuthWrapper(batchHandler.Create, auth.PermBatchCreate))
+			v2Batch.PUT("", ProjectAuthWrapper(batchHandler.Update, auth.PermBatchUpdate))
+			v2Batch.POST("/delete", operatorCompat(batchHandler.Delete))
+			v2Batch.PUT("/release_app", operatorCompat(releaseAppHandler.UpdateBuilds))
+			v2Batch.POST("/approve", operatorCompat(batchHandler.Approve))
+			v2Batch.POST("/reject", operatorCompat(batchHandler.Reject))
+			v2Batch.POST("/action", operatorCompat(ProjectAuthWrapper(batchHandler.ProcessAction, auth.PermProdOperate)))
+		}
+
+		v2ReleaseApp := v2.Group("/release_app")
+		{
+			v2ReleaseApp.PUT("/:id/dependencies", operatorCompat(releaseAppHandler.UpdateDependencies))
+			v2ReleaseApp.POST("/switch_version", operatorCompat(ProjectAuthWrapper(batchHandler.SwitchVersion, auth.PermProdOperate)))
+			v2ReleaseApp.POST("/manual_deploy", operatorCompat(ProjectAuthWrapper(batchHandler.ManualDeploy, auth.PermProdOperate)))
+		}
+
+		v2Deployment := v2.Group("/deployment")
+		{
+			v2Deployment.POST("/:id/retry", operatorCompat(ProjectEnvAuthWrapper(deploymentHandler.Retry, auth.PermProdOperate)))
+			v2Deployment.POST("/:id/redeploy", operatorCompat(ProjectEnvAuthWrapper(deploymentHandler.RedeployConfigChart, auth.PermProdOperate)))
+		}
 	}
 
 	return r
 }
+
+// v1SunsetHeader 将配置的 v1 下线日期（YYYY-MM-DD）转换为 Sunset 头使用的 HTTP-date，未配置或格式错误时返回空
+func v1SunsetHeader(date string, logger *zap.Logger) string {
+	if date == "" {
+		return ""
+	}
+	t, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		logger.Warn("server.v1_sunset 格式错误，忽略 Sunset 头", zap.String("v1_sunset", date), zap.Error(err))
+		return ""
+	}
+	return t.UTC().Format(http.TimeFormat)
+}
+
+// loadCIRules 加载 CI 构建通知映射规则，失败时记录日志并使用空规则（代码库视为同名单应用）
+func loadCIRules(path string, logger *zap.Logger) *ci.Rules {
+	rules, err := ci.LoadRules(path)
+	if err != nil {
+		logger.Error("加载 ci.rules_file 失败，使用默认映射", zap.String("rules_file", path), zap.Error(err))
+		return &ci.Rules{}
+	}
+	return rules
+}
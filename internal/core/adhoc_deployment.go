@@ -0,0 +1,170 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"devops-cd/internal/adapter/notification"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+)
+
+// 临时部署（批次外手动部署）
+//
+// - 临时部署的 Deployment 不属于批次（batch_id/release_id 为 0），不经过 batchWork，由定时扫描推进
+// - 与批次 Deployment 共用状态机、并发额度与项目/集群暂停；不做灰度、不拆分 config Deployment、不自动回滚
+// - Deployment 全部结束后汇总临时部署状态并发送应用部署通知；prod 部署覆盖应用全部生产集群且成功时更新应用 deployed_tag
+
+// scanAdhocDeployments 推进进行中的临时部署
+func (e *CoreEngine) scanAdhocDeployments() {
+	var adhocs []model.AdhocDeployment
+	if err := e.db.Where("status = ?", constants.AdhocDeploymentStatusRunning).Order("id").Find(&adhocs).Error; err != nil {
+		e.logger.Error(fmt.Sprintf("[Adhoc] 查询进行中的临时部署失败: %v", err))
+		return
+	}
+	if len(adhocs) == 0 {
+		return
+	}
+	// 查询暂停记录失败时按暂停处理
+	pauses, err := e.loadEnginePauses(e.workCtx)
+	if err != nil {
+		return
+	}
+	for i := range adhocs {
+		a := &adhocs[i]
+		if model.FindEnginePause(pauses, a.ProjectID, 0) != nil {
+			continue
+		}
+		e.processAdhocDeployment(e.workCtx, a)
+	}
+}
+
+// processAdhocDeployment 处理临时部署中未结束的 Deployment，全部结束后汇总状态
+func (e *CoreEngine) processAdhocDeployment(ctx context.Context, a *model.AdhocDeployment) {
+	deps, err := e.adhocDeployments(ctx, a.ID)
+	if err != nil {
+		return
+	}
+	active := lo.Filter(deps, func(dep *model.Deployment, _ int) bool {
+		return dep.Status == constants.DeploymentStatusPending || dep.Status == constants.DeploymentStatusRunning
+	})
+	if len(active) > 0 {
+		// 集群被暂停时该集群的 Deployment 保持进行中，临时部署不会汇总结束
+		active = e.gateClusterPause(ctx, active)
+	}
+	if len(active) > 0 {
+		allowed, release := e.gateConcurrency(ctx, active)
+		if len(allowed) > 0 {
+			var lines []string
+			for i, err := range e.runDeployments(ctx, allowed) {
+				if err != nil {
+					lines = append(lines, fmt.Sprintf("[%s/%s] %v", allowed[i].Env, allowed[i].ClusterName, err))
+				}
+			}
+			if len(lines) > 0 {
+				e.logger.Error(fmt.Sprintf("[Adhoc] 临时部署:%d 本轮 %d/%d 个集群部署出错", a.ID, len(lines), len(allowed)),
+					zap.Int64("adhoc_id", a.ID), zap.Int64("app_id", a.AppID), zap.Strings("errors", lines))
+			}
+		}
+		release()
+		if deps, err = e.adhocDeployments(ctx, a.ID); err != nil {
+			return
+		}
+	}
+	e.finishAdhocDeployment(ctx, a, deps)
+}
+
+func (e *CoreEngine) adhocDeployments(ctx context.Context, adhocID int64) ([]*model.Deployment, error) {
+	var deps []*model.Deployment
+	if err := e.db.WithContext(ctx).Where("adhoc_id = ? AND superseded_by IS NULL", adhocID).Order("id").Find(&deps).Error; err != nil {
+		e.logger.Error("[Adhoc] 查询临时部署 Deployment 失败", zap.Int64("adhoc_id", adhocID), zap.Error(err))
+		return nil, err
+	}
+	return deps, nil
+}
+
+// finishAdhocDeployment Deployment 全部结束时汇总为 success/failed，并发送通知
+func (e *CoreEngine) finishAdhocDeployment(ctx context.Context, a *model.AdhocDeployment, deps []*model.Deployment) {
+	var failed []string
+	for _, dep := range deps {
+		switch {
+		case dep.Status == constants.DeploymentStatusPending || dep.Status == constants.DeploymentStatusRunning:
+			return
+		case constants.IsDeploymentFailed(dep.Status):
+			msg := dep.Status
+			if dep.ErrorMessage != nil && *dep.ErrorMessage != "" {
+				msg = *dep.ErrorMessage
+			}
+			failed = append(failed, fmt.Sprintf("[%s/%s] %s", dep.Env, dep.ClusterName, msg))
+		}
+	}
+	if len(deps) == 0 {
+		failed = append(failed, "临时部署没有 Deployment")
+	}
+
+	status := constants.AdhocDeploymentStatusSuccess
+	var errorMessage *string
+	if len(failed) > 0 {
+		status = constants.AdhocDeploymentStatusFailed
+		msg := strings.Join(failed, "\n")
+		errorMessage = &msg
+	}
+	now := time.Now()
+	// 按状态条件更新，多副本/重复扫描时只汇总一次
+	res := e.db.WithContext(ctx).Model(&model.AdhocDeployment{}).
+		Where("id = ? AND status = ?", a.ID, constants.AdhocDeploymentStatusRunning).
+		Updates(map[string]any{"status": status, "error_message": errorMessage, "finished_at": &now})
+	if res.Error != nil {
+		e.logger.Error("[Adhoc] 更新临时部署状态失败", zap.Int64("adhoc_id", a.ID), zap.Error(res.Error))
+		return
+	}
+	if res.RowsAffected == 0 {
+		return
+	}
+
+	if status == constants.AdhocDeploymentStatusSuccess && a.Env == constants.EnvTypeProd {
+		e.updateAdhocDeployedTag(ctx, a)
+	}
+	e.logger.Info(fmt.Sprintf("[Adhoc] 临时部署:%d 已结束: %s", a.ID, status),
+		zap.Int64("adhoc_id", a.ID), zap.Int64("app_id", a.AppID), zap.String("env", a.Env),
+		zap.String("image_tag", a.ImageTag), zap.String("operator", a.CreatedBy))
+
+	typ := notification.NotifyAppDeploySuccess
+	message := fmt.Sprintf("[临时部署 %s] %s 集群 %s (by %s)\n原因: %s",
+		a.Env, a.ImageTag, strings.Join(a.Clusters, ","), a.CreatedBy, a.Reason)
+	if status == constants.AdhocDeploymentStatusFailed {
+		typ = notification.NotifyAppDeployFailed
+		message += "\n" + strings.Join(failed, "\n")
+	}
+	e.enqueueNotify(func(ctx context.Context) error {
+		var app model.Application
+		if err := e.db.WithContext(ctx).Select("id", "name").First(&app, a.AppID).Error; err != nil {
+			return fmt.Errorf("查询应用失败: %w", err)
+		}
+		return e.notifier.SendAppDeployNotification(ctx, 0, app.ID, app.Name, typ, message)
+	})
+}
+
+// updateAdhocDeployedTag prod 临时部署覆盖应用全部启用的生产集群时，更新应用已部署版本（下个批次的发布前版本以此为准）
+func (e *CoreEngine) updateAdhocDeployedTag(ctx context.Context, a *model.AdhocDeployment) {
+	var clusters []string
+	if err := e.db.WithContext(ctx).Model(&model.AppEnvConfig{}).
+		Where("app_id = ? AND env = ? AND status = 1", a.AppID, constants.EnvTypeProd).
+		Pluck("cluster", &clusters).Error; err != nil {
+		e.logger.Error("[Adhoc] 查询应用生产集群失败", zap.Int64("adhoc_id", a.ID), zap.Error(err))
+		return
+	}
+	if missing, _ := lo.Difference(clusters, a.Clusters); len(missing) > 0 {
+		e.logger.Info(fmt.Sprintf("[Adhoc] 临时部署:%d 未覆盖生产集群 %v，不更新应用 deployed_tag", a.ID, missing))
+		return
+	}
+	if err := e.db.WithContext(ctx).Model(&model.Application{}).
+		Where("id = ?", a.AppID).Update("deployed_tag", a.ImageTag).Error; err != nil {
+		e.logger.Error("[Adhoc] 更新应用部署版本失败", zap.Int64("adhoc_id", a.ID), zap.Error(err))
+	}
+}
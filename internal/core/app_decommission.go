@@ -0,0 +1,188 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"devops-cd/internal/adapter/notification"
+	"devops-cd/internal/core/common/metacache"
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 应用下线
+//
+// - 由定时扫描推进（项目暂停时跳过）：逐个卸载下线记录中 pending 的 release（helm uninstall，不保留历史），非 helm driver 的目标已标记为 skipped
+// - 卸载前再次检查应用不在未结束的批次中、没有进行中的部署（发起后被加入批次/触发部署时直接失败）
+// - 全部卸载成功（或无需卸载）后在同一事务内归档构建记录、清理配置漂移记录、软删除应用；任一失败时应用保留，可再次发起
+
+// decommissionUninstallTimeout 单个 release 的卸载超时
+const decommissionUninstallTimeout = 2 * time.Minute
+
+// scanAppDecommissions 推进进行中的应用下线
+func (e *CoreEngine) scanAppDecommissions() {
+	var list []model.AppDecommission
+	if err := e.db.Where("status = ?", constants.AppDecommissionStatusRunning).Order("id").Find(&list).Error; err != nil {
+		e.logger.Error(fmt.Sprintf("[Decommission] 查询进行中的应用下线失败: %v", err))
+		return
+	}
+	if len(list) == 0 {
+		return
+	}
+	// 查询暂停记录失败时按暂停处理
+	pauses, err := e.loadEnginePauses(e.workCtx)
+	if err != nil {
+		return
+	}
+	for i := range list {
+		if model.FindEnginePause(pauses, list[i].ProjectID, 0) != nil {
+			continue
+		}
+		e.processAppDecommission(e.workCtx, &list[i])
+	}
+}
+
+func (e *CoreEngine) processAppDecommission(ctx context.Context, d *model.AppDecommission) {
+	log := e.logger.With(zap.Int64("decommission_id", d.ID), zap.Int64("app_id", d.AppID))
+
+	if reason, err := e.decommissionBlocker(ctx, d.AppID); err != nil {
+		log.Error("[Decommission] 检查应用状态失败", zap.Error(err))
+		return
+	} else if reason != "" {
+		e.finishAppDecommission(ctx, d, []string{reason})
+		return
+	}
+
+	var failed []string
+	for i := range d.Targets {
+		t := &d.Targets[i]
+		if t.Status == constants.DecommissionTargetPending {
+			e.uninstallDecommissionTarget(ctx, t)
+			log.Info("[Decommission] 卸载 release",
+				zap.String("env", t.Env), zap.String("cluster", t.ClusterName),
+				zap.String("release", t.Namespace+"/"+t.ReleaseName), zap.String("status", t.Status), zap.String("message", t.Message))
+		}
+		if t.Status == constants.DecommissionTargetFailed {
+			failed = append(failed, fmt.Sprintf("[%s/%s] %s: %s", t.Env, t.ClusterName, t.ReleaseName, t.Message))
+		}
+	}
+	e.finishAppDecommission(ctx, d, failed)
+}
+
+// decommissionBlocker 应用在未结束的批次中或存在进行中的部署时返回原因
+func (e *CoreEngine) decommissionBlocker(ctx context.Context, appID int64) (string, error) {
+	db := e.db.WithContext(ctx)
+	batches, err := model.AppActiveBatchNumbers(db, appID)
+	if err != nil {
+		return "", err
+	}
+	if len(batches) > 0 {
+		return fmt.Sprintf("应用在未结束的批次中（%s）", strings.Join(batches, ", ")), nil
+	}
+	var active int64
+	if err := db.Model(&model.Deployment{}).
+		Where("app_id = ? AND superseded_by IS NULL AND status IN ?",
+			appID, []string{constants.DeploymentStatusPending, constants.DeploymentStatusRunning}).
+		Count(&active).Error; err != nil {
+		return "", err
+	}
+	if active > 0 {
+		return "应用存在进行中的部署", nil
+	}
+	return "", nil
+}
+
+func (e *CoreEngine) uninstallDecommissionTarget(ctx context.Context, t *model.DecommissionTarget) {
+	cluster, err := metacache.Cluster(ctx, e.db, t.ClusterName)
+	if err != nil {
+		t.Status, t.Message = constants.DecommissionTargetFailed, fmt.Sprintf("查询集群失败: %v", err)
+		return
+	}
+	found, err := helmDriver.UninstallRelease(cluster, t.Namespace, t.ReleaseName, decommissionUninstallTimeout)
+	switch {
+	case err != nil:
+		t.Status, t.Message = constants.DecommissionTargetFailed, err.Error()
+	case !found:
+		t.Status, t.Message = constants.DecommissionTargetNotFound, ""
+	default:
+		t.Status, t.Message = constants.DecommissionTargetUninstalled, ""
+	}
+}
+
+// finishAppDecommission 有失败时置为 failed；否则在同一事务内归档构建、清理配置漂移记录、删除应用并置为 success
+func (e *CoreEngine) finishAppDecommission(ctx context.Context, d *model.AppDecommission, failed []string) {
+	now := time.Now()
+	status := constants.AppDecommissionStatusSuccess
+	var errorMessage *string
+	if len(failed) > 0 {
+		status = constants.AppDecommissionStatusFailed
+		msg := strings.Join(failed, "\n")
+		errorMessage = &msg
+	}
+
+	errAlreadyFinished := errors.New("decommission already finished")
+	err := e.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 按状态条件更新，多副本/重复扫描时只结束一次
+		res := tx.Model(&model.AppDecommission{}).
+			Where("id = ? AND status = ?", d.ID, constants.AppDecommissionStatusRunning).
+			Updates(map[string]any{"status": status, "targets": d.Targets, "error_message": errorMessage, "finished_at": &now})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errAlreadyFinished
+		}
+		if status != constants.AppDecommissionStatusSuccess {
+			return nil
+		}
+
+		archived := tx.Model(&model.Build{}).
+			Where("app_id = ? AND archived_at IS NULL", d.AppID).
+			Update("archived_at", &now)
+		if archived.Error != nil {
+			return archived.Error
+		}
+		d.ArchivedBuilds = archived.RowsAffected
+		if err := tx.Model(&model.AppDecommission{}).Where("id = ?", d.ID).
+			Update("archived_builds", d.ArchivedBuilds).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("app_id = ?", d.AppID).Delete(&model.ReleaseDrift{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Application{}, d.AppID).Error
+	})
+	if errors.Is(err, errAlreadyFinished) {
+		return
+	}
+	if err != nil {
+		e.logger.Error("[Decommission] 更新应用下线状态失败", zap.Int64("decommission_id", d.ID), zap.Error(err))
+		return
+	}
+
+	e.logger.Info(fmt.Sprintf("[Decommission] 应用下线:%d 已结束: %s", d.ID, status),
+		zap.Int64("decommission_id", d.ID), zap.Int64("app_id", d.AppID), zap.String("app_name", d.AppName),
+		zap.Int64("archived_builds", d.ArchivedBuilds), zap.String("operator", d.CreatedBy))
+
+	message := fmt.Sprintf("[应用下线] %s (by %s)\n原因: %s", status, d.CreatedBy, d.Reason)
+	if uninstalled := lo.CountBy(d.Targets, func(t model.DecommissionTarget) bool { return t.Status == constants.DecommissionTargetUninstalled }); uninstalled > 0 {
+		message += fmt.Sprintf("\n已卸载 %d 个 release", uninstalled)
+	}
+	if skipped := lo.CountBy(d.Targets, func(t model.DecommissionTarget) bool { return t.Status == constants.DecommissionTargetSkipped }); skipped > 0 {
+		message += fmt.Sprintf("\n%d 个非 helm 部署需手动清理", skipped)
+	}
+	if len(failed) > 0 {
+		message += "\n" + strings.Join(failed, "\n")
+	}
+	e.enqueueNotify(func(ctx context.Context) error {
+		return e.notifier.SendAppDeployNotification(ctx, 0, d.AppID, d.AppName, notification.NotifyAppDecommission, message)
+	})
+}
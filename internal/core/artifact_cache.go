@@ -0,0 +1,49 @@
+package core
+
+import (
+	"devops-cd/internal/core/common/artifactcache"
+	"devops-cd/internal/pkg/config"
+	"fmt"
+	"time"
+)
+
+// configureArtifactCache 按 core.artifact_cache 配置初始化共享制品缓存（values 层、压缩包解压结果与 chart 仓库共用）
+func (e *CoreEngine) configureArtifactCache(coreCfg *config.CoreConfig) {
+	opts := artifactcache.Options{}
+	if coreCfg != nil {
+		cfg := coreCfg.ArtifactCache
+		opts.Dir = cfg.Dir
+		opts.TTL = e.parseCacheDuration("ttl", cfg.TTL)
+		opts.Timeout = e.parseCacheDuration("timeout", cfg.Timeout)
+		opts.MaxSize = int64(cfg.MaxObjectSize) << 20
+		opts.MaxTotalSize = int64(cfg.MaxTotalSize) << 20
+		opts.Concurrency = cfg.MaxConcurrent
+		opts.AllowHosts = cfg.AllowHosts
+		opts.DenyHosts = cfg.DenyHosts
+		opts.BlockPrivate = cfg.BlockPrivate
+	}
+	if err := artifactcache.Configure(opts); err != nil {
+		e.logger.Warn(fmt.Sprintf("[ArtifactCache] 初始化失败, 使用默认配置: %v", err))
+	}
+}
+
+func (e *CoreEngine) parseCacheDuration(name, v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		e.logger.Warn(fmt.Sprintf("[ArtifactCache] %s 配置无效, 使用默认值: %v", name, err))
+		return 0
+	}
+	return d
+}
+
+// ArtifactCacheStats 共享制品缓存命中统计
+func (e *CoreEngine) ArtifactCacheStats() (artifactcache.Stats, error) {
+	c, err := artifactcache.Default()
+	if err != nil {
+		return artifactcache.Stats{}, err
+	}
+	return c.Stats(), nil
+}
@@ -0,0 +1,57 @@
+package core
+
+import (
+	"context"
+	"devops-cd/internal/adapter/notification"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// registerAutoRollbackListener 生产 Deployment 就绪检查/部署后验证失败时，按应用（或项目）自动回滚策略回滚该集群
+func (e *CoreEngine) registerAutoRollbackListener() {
+	e.deploymentSM.OnStatusChange(e.autoRollback)
+}
+
+// autoRollback 只处理 running → failed/verify_failed（helm 已执行、就绪或验证未通过）的生产 app Deployment，
+// 渲染/提交阶段的失败（pending → failed）集群未变更，不回滚；回滚 Deployment 自身失败、临时部署失败也不再回滚
+func (e *CoreEngine) autoRollback(dep model.Deployment, from, to string) {
+	if dep.Env != constants.EnvTypeProd || dep.Kind != constants.DeploymentKindApp || dep.RollbackBuildID != nil || dep.AdhocID != nil ||
+		from != constants.DeploymentStatusRunning || !constants.IsDeploymentFailed(to) {
+		return
+	}
+
+	ctx := context.TODO()
+	var app model.Application
+	if err := e.db.WithContext(ctx).Preload("Project").Select("id", "name", "project_id", "auto_rollback").First(&app, dep.AppID).Error; err != nil {
+		e.logger.Error("[AutoRollback] 查询应用失败", zap.Int64("app_id", dep.AppID), zap.Error(err))
+		return
+	}
+	if !app.AutoRollbackEnabled() {
+		return
+	}
+
+	log := e.logger.With(zap.Int64("batch_id", dep.BatchID), zap.Int64("release_id", dep.ReleaseID),
+		zap.Int64("deployment_id", dep.ID), zap.String("cluster", dep.ClusterName))
+	reason := ""
+	if dep.ErrorMessage != nil {
+		reason = *dep.ErrorMessage
+	}
+
+	toTag, err := e.releaseSM.AutoRollbackCluster(ctx, &dep)
+	if err != nil {
+		log.Warn("[AutoRollback] 自动回滚未执行", zap.Error(err))
+		e.notifyAutoRollback(&dep, &app, fmt.Sprintf("[prod/%s] %s\n自动回滚未执行: %v", dep.ClusterName, reason, err))
+		return
+	}
+	log.Info("[AutoRollback] 已创建回滚 Deployment", zap.String("to_tag", toTag))
+	e.notifyAutoRollback(&dep, &app, fmt.Sprintf("[prod/%s] %s\n已自动回滚到发布前版本 %s", dep.ClusterName, reason, toTag))
+}
+
+func (e *CoreEngine) notifyAutoRollback(dep *model.Deployment, app *model.Application, message string) {
+	e.enqueueNotify(func(ctx context.Context) error {
+		return e.notifier.SendAppDeployNotification(ctx, dep.BatchID, app.ID, app.Name, notification.NotifyAppAutoRollback, message)
+	})
+}
@@ -0,0 +1,34 @@
+package batch
+
+import (
+	"devops-cd/internal/core/batch/transitions"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+)
+
+// newBatchEvent 构造状态变更审计事件：显式指定的触发方式优先，否则外部调用视为手动、内部推进视为自动
+func newBatchEvent(batch *model.Batch, from, to, source int8, option *transitions.TransitionOptions) *model.BatchEvent {
+	trigger := option.Trigger()
+	if trigger == "" {
+		if source == transitions.SourceOutside {
+			trigger = constants.BatchEventTriggerManual
+		} else {
+			trigger = constants.BatchEventTriggerAuto
+		}
+	}
+
+	event := &model.BatchEvent{
+		BatchID:    batch.ID,
+		ProjectID:  batch.ProjectID,
+		FromStatus: from,
+		ToStatus:   to,
+		Trigger:    trigger,
+	}
+	if operator := option.Operator(); operator != "" {
+		event.Operator = &operator
+	}
+	if reason := option.Reason(); reason != "" {
+		event.Reason = &reason
+	}
+	return event
+}
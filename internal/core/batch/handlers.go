@@ -28,6 +28,7 @@ func (sm *StateMachine) registerHandlers() {
 	sm.handlers[constants.BatchStatusProdWaiting] = StateHandlerFunc(sm.HandleProdWaiting)
 	sm.handlers[constants.BatchStatusProdDeploying] = StateHandlerFunc(sm.HandleProdDeploying)
 	sm.handlers[constants.BatchStatusProdDeployed] = StateHandlerFunc(sm.HandleProdDeployed)
+	sm.handlers[constants.BatchStatusRollingBack] = StateHandlerFunc(sm.HandleRollingBack)
 }
 
 // all handlers
@@ -112,17 +113,23 @@ func (sm *StateMachine) HandleProdWaiting(ctx context.Context, batch *model.Batc
 
 	// 2. 更新经过 pre 的应用: PreDeployed → ProdWaiting
 	result2 := sm.db.Model(&model.ReleaseApp{}).
-		Where("batch_id = ? AND skip_pre_env = ?", batch.ID, false).
+		Where("batch_id = ? AND skip_pre_env = ? AND skip_prod_env = ?", batch.ID, false, false).
 		Where("status = ?", constants.ReleaseAppStatusPreAccepted).
 		Update("status", constants.ReleaseAppStatusProdWaiting)
 
-	if result1.Error != nil || result2.Error != nil {
+	// 3. 跳过生产的应用（pre_only 或未配置生产环境）: PreAccepted → PreOnlyCompleted (不进入 prod)
+	result3 := sm.db.Model(&model.ReleaseApp{}).
+		Where("batch_id = ? AND skip_prod_env = ?", batch.ID, true).
+		Where("status = ?", constants.ReleaseAppStatusPreAccepted).
+		Update("status", constants.ReleaseAppStatusPreOnlyCompleted)
+
+	if result1.Error != nil || result2.Error != nil || result3.Error != nil {
 		return 0, nil, fmt.Errorf("更新发布记录状态失败")
 	}
 
 	total := result1.RowsAffected + result2.RowsAffected
-	sm.logger.Info(fmt.Sprintf("Batch:%s -> %d 条release_app记录更新为 ProdWaiting (跳过pre:%d, 经过pre:%d)",
-		batchName, total, result1.RowsAffected, result2.RowsAffected))
+	sm.logger.Info(fmt.Sprintf("Batch:%s -> %d 条release_app记录更新为 ProdWaiting (跳过pre:%d, 经过pre:%d), %d 条跳过生产记录完成",
+		batchName, total, result1.RowsAffected, result2.RowsAffected, result3.RowsAffected))
 	return constants.BatchStatusProdDeploying, nil, nil
 }
 
@@ -163,6 +170,45 @@ func (sm *StateMachine) HandleProdDeployed(ctx context.Context, batch *model.Bat
 	return 0, nil, nil
 }
 
+// HandleRollingBack handle StatusRollingBack:51
+// When all rollback apps success -> StatusRolledBack:53
+// When any failed (and none in progress) -> StatusRollbackFailed:54
+func (sm *StateMachine) HandleRollingBack(ctx context.Context, batch *model.Batch) (int8, func(*model.Batch), error) {
+	batchName := fmt.Sprintf("%s[%v]", batch.BatchNumber, batch.ID)
+
+	type statusCount struct {
+		Status int8
+		Count  int64
+	}
+	var counts []statusCount
+	if err := sm.db.Model(&model.ReleaseApp{}).Select("status, COUNT(*) AS count").
+		Where("batch_id = ?", batch.ID).Scopes(StatusIn(constants.ReleaseAppStatusRollbackCanTrigger)).
+		Group("status").Scan(&counts).Error; err != nil {
+		return 0, nil, fmt.Errorf("[db] 统计回滚应用时失败: %w", err)
+	}
+
+	var inProgress, failed, total int64
+	for _, c := range counts {
+		total += c.Count
+		switch c.Status {
+		case constants.ReleaseAppStatusRolledBack:
+		case constants.ReleaseAppStatusRollbackFailed:
+			failed += c.Count
+		default:
+			inProgress += c.Count
+		}
+	}
+	if inProgress > 0 {
+		sm.logger.Debug(fmt.Sprintf("[Batch SM] Batch:%s -> RollingBack 进行中，剩余 %d 条", batchName, inProgress))
+		return 0, nil, nil
+	}
+	if total == 0 || failed > 0 {
+		sm.logger.Warn(fmt.Sprintf("[Batch SM] Batch:%s -> 回滚失败: %d/%d 个应用回滚失败", batchName, failed, total))
+		return constants.BatchStatusRollbackFailed, nil, nil
+	}
+	return constants.BatchStatusRolledBack, nil, nil
+}
+
 // ---- common functions -----
 
 // StatusIn 批量查询指定范围内的状态, 左闭右开区间
@@ -30,17 +30,26 @@ func (sm *StateMachine) initActions() {
 }
 
 // ProcessStateChange 触发状态更新, 外部调用层
-func (sm *StateMachine) ProcessStateChange(batchID int64, event string, operator, reason string) error {
+func (sm *StateMachine) ProcessStateChange(batchID int64, event string, operator, reason string, opts ...transitions.TransitionOption) error {
+	// 暂停/恢复不改变批次状态
+	switch event {
+	case constants.BatchActionPause:
+		return sm.Pause(batchID, operator, reason)
+	case constants.BatchActionResume:
+		return sm.Resume(batchID, operator, reason)
+	}
+
 	e, ok := events[event]
 	if !ok {
 		return fmt.Errorf("无效的状态转换事件: %s", event)
 	}
 
 	// 事务更新
-	if err := sm.ChangeStatus(context.TODO(), &model.Batch{BaseModel: model.BaseModel{ID: batchID}}, e.To, transitions.SourceOutside,
+	opts = append([]transitions.TransitionOption{
 		transitions.WithOperator(operator),
 		transitions.WithReason(reason),
-	); err != nil {
+	}, opts...)
+	if err := sm.ChangeStatus(context.TODO(), &model.Batch{BaseModel: model.BaseModel{ID: batchID}}, e.To, transitions.SourceOutside, opts...); err != nil {
 		sm.logger.Sugar().Errorf("处理批次操作：%v失败: %v", event, err)
 		return err
 	}
@@ -48,3 +57,31 @@ func (sm *StateMachine) ProcessStateChange(batchID int64, event string, operator
 	sm.logger.Sugar().Infof("处理批次操作: %v by %v 成功", event, operator)
 	return nil
 }
+
+// StartRollback 批次进入回滚中（已处于回滚中时忽略）
+// 不作为 ProcessStateChange 事件暴露：回滚需由调用方同时触发具体应用的回滚
+func (sm *StateMachine) StartRollback(batchID int64, operator, reason string) error {
+	batch := &model.Batch{BaseModel: model.BaseModel{ID: batchID}}
+	if err := sm.db.First(batch, batchID).Error; err != nil {
+		return err
+	}
+	if batch.Status == constants.BatchStatusRollingBack {
+		return nil
+	}
+	if err := sm.ChangeStatus(context.TODO(), batch, constants.BatchStatusRollingBack, transitions.SourceOutside,
+		transitions.WithOperator(operator),
+		transitions.WithReason(reason),
+	); err != nil {
+		sm.logger.Sugar().Errorf("批次 %d 触发回滚失败: %v", batchID, err)
+		return err
+	}
+	return nil
+}
+
+// TimeoutCancel 批次超过 batch_timeout 时由引擎自动取消
+func (sm *StateMachine) TimeoutCancel(batchID int64, reason string) error {
+	return sm.ChangeStatus(context.TODO(), &model.Batch{BaseModel: model.BaseModel{ID: batchID}}, constants.BatchStatusCancelled, transitions.SourceInside,
+		transitions.WithOperator(constants.BatchTimeoutOperator),
+		transitions.WithReason(reason),
+	)
+}
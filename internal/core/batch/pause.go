@@ -0,0 +1,120 @@
+package batch
+
+import (
+	"context"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// pausable 批次是否处于引擎推进中（封板后、完成前，或回滚中）
+func pausable(status int8) bool {
+	return status > constants.BatchStatusDraft && status < constants.BatchStatusCompleted ||
+		status == constants.BatchStatusRollingBack
+}
+
+// Pause 暂停批次：引擎不再推进批次、发布应用与部署，状态冻结在当前（已触发的 helm 操作不会被中断）
+//
+// 以批次级 engine_pauses 记录实现，与管理员暂停共用；批次状态不变，审计事件记录 from=to
+func (sm *StateMachine) Pause(batchID int64, operator, reason string) error {
+	return sm.db.WithContext(context.TODO()).Transaction(func(tx *gorm.DB) error {
+		var batch model.Batch
+		if err := tx.First(&batch, batchID).Error; err != nil {
+			return err
+		}
+		if !pausable(batch.Status) {
+			return fmt.Errorf("当前状态 %s 不允许暂停", constants.BatchStatusToString(batch.Status))
+		}
+
+		var active model.EnginePause
+		err := tx.Scopes(model.ActiveEnginePause).
+			Where("scope = ? AND scope_id = ?", constants.EnginePauseScopeBatch, batch.ID).
+			First(&active).Error
+		if err == nil {
+			return fmt.Errorf("批次已处于暂停状态（%s 暂停于 %s）", active.PausedBy, active.CreatedAt.Format(time.DateTime))
+		}
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
+
+		if err := tx.Create(&model.EnginePause{
+			Scope:    constants.EnginePauseScopeBatch,
+			ScopeID:  batch.ID,
+			Reason:   reason,
+			PausedBy: operator,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(newPauseEvent(&batch, operator, "暂停", reason)).Error; err != nil {
+			return err
+		}
+		sm.logger.Sugar().Warnf("[Batch SM: %d] 批次已暂停 by %s: %s", batch.ID, operator, reason)
+		return nil
+	})
+}
+
+// Resume 恢复批次暂停，引擎下一轮扫描继续推进；项目级暂停需由管理员恢复
+func (sm *StateMachine) Resume(batchID int64, operator, reason string) error {
+	return sm.db.WithContext(context.TODO()).Transaction(func(tx *gorm.DB) error {
+		var batch model.Batch
+		if err := tx.First(&batch, batchID).Error; err != nil {
+			return err
+		}
+
+		var pauses []model.EnginePause
+		if err := tx.Scopes(model.ActiveEnginePause).
+			Where("(scope = ? AND scope_id = ?) OR (scope = ? AND scope_id = ?)",
+				constants.EnginePauseScopeBatch, batch.ID, constants.EnginePauseScopeProject, batch.ProjectID).
+			Find(&pauses).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		resumed := 0
+		for _, p := range pauses {
+			if p.Scope != constants.EnginePauseScopeBatch {
+				continue
+			}
+			if err := tx.Model(&model.EnginePause{}).Where("id = ? AND resumed_at IS NULL", p.ID).
+				Updates(map[string]any{"resumed_at": now, "resumed_by": operator}).Error; err != nil {
+				return err
+			}
+			resumed++
+		}
+		if resumed == 0 {
+			if len(pauses) > 0 {
+				return fmt.Errorf("批次所属项目已被暂停，需由管理员恢复")
+			}
+			return fmt.Errorf("批次未处于暂停状态")
+		}
+
+		if err := tx.Create(newPauseEvent(&batch, operator, "恢复", reason)).Error; err != nil {
+			return err
+		}
+		sm.logger.Sugar().Infof("[Batch SM: %d] 批次已恢复 by %s: %s", batch.ID, operator, reason)
+		return nil
+	})
+}
+
+// newPauseEvent 暂停/恢复审计事件（状态不变）
+func newPauseEvent(batch *model.Batch, operator, action, reason string) *model.BatchEvent {
+	msg := action
+	if reason != "" {
+		msg = fmt.Sprintf("%s: %s", action, reason)
+	}
+	event := &model.BatchEvent{
+		BatchID:    batch.ID,
+		ProjectID:  batch.ProjectID,
+		FromStatus: batch.Status,
+		ToStatus:   batch.Status,
+		Trigger:    constants.BatchEventTriggerManual,
+		Reason:     &msg,
+	}
+	if operator != "" {
+		event.Operator = &operator
+	}
+	return event
+}
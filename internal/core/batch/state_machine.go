@@ -20,12 +20,33 @@ type StateMachine struct {
 
 	// 状态转换
 	transitions map[int8]map[int8]transitions2.StateTransition
+
+	// 状态变更监听（事务提交后调用，如出站 Webhook）
+	listeners []StatusListener
+
+	// 封板时计算部署波次
+	waves transitions2.WavePlanner
+	// 封板时固化 chart lock
+	charts transitions2.ChartLocker
+	// 封板时固化 manifest lock（构建提交中的代码库清单）
+	manifests transitions2.ManifestLocker
+}
+
+// StatusListener 批次状态变更监听
+type StatusListener func(batch model.Batch, from, to int8)
+
+// OnStatusChange 注册状态变更监听，需在引擎启动前调用
+func (sm *StateMachine) OnStatusChange(l StatusListener) {
+	sm.listeners = append(sm.listeners, l)
 }
 
-func NewBatchStateMachine(db *gorm.DB, logger *zap.Logger) *StateMachine {
+func NewBatchStateMachine(db *gorm.DB, logger *zap.Logger, waves transitions2.WavePlanner, charts transitions2.ChartLocker, manifests transitions2.ManifestLocker) *StateMachine {
 	sm := &StateMachine{
 		db:          db,
 		logger:      logger,
+		waves:       waves,
+		charts:      charts,
+		manifests:   manifests,
 		handlers:    make(map[int8]StateHandler),
 		transitions: make(map[int8]map[int8]transitions2.StateTransition),
 	}
@@ -113,6 +134,11 @@ func (sm *StateMachine) ChangeStatus(ctx context.Context, batch *model.Batch, to
 			return fmt.Errorf("update failed: status conflict")
 		}
 
+		// 6. 审计事件，与状态变更同事务
+		if err := tx.Create(newBatchEvent(batch, from, to, source, option)).Error; err != nil {
+			return err
+		}
+
 		log.Infof("[Batch SM: %d] 状态变更成功: %v -> %v", batch.ID, from, to)
 		// batch.NextCheckAt = time.Now().Add(30 * time.Second) // 根据层级调整
 		return nil
@@ -122,6 +148,11 @@ func (sm *StateMachine) ChangeStatus(ctx context.Context, batch *model.Batch, to
 	if err == nil && afterHandler != nil {
 		afterHandler()
 	}
+	if err == nil {
+		for _, l := range sm.listeners {
+			l(*batch, from, to)
+		}
+	}
 	return err
 }
 
@@ -144,7 +175,7 @@ func (sm *StateMachine) canTransition(from, to int8, source int8) (transitions2.
 }
 
 func (sm *StateMachine) registerTransitions() {
-	trans := transitions2.AllTransitions(sm.db)
+	trans := transitions2.AllTransitions(sm.db, sm.waves, sm.charts, sm.manifests)
 
 	for _, t := range trans {
 		if sm.transitions[t.From] == nil {
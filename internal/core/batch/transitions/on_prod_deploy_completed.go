@@ -2,6 +2,7 @@ package transitions
 
 import (
 	"devops-cd/internal/model"
+	"devops-cd/internal/repository"
 	"fmt"
 	"gorm.io/gorm"
 	"time"
@@ -13,13 +14,8 @@ type OnProdDeployCompletedTransition struct {
 }
 
 func (h OnProdDeployCompletedTransition) Handle(batch *model.Batch, from, to int8, options *TransitionOptions) error {
-	// 同步更新 applications.deployed_tag 为 target_tag（部署成功后的版本）
-	if err := h.db.Exec(`
-		UPDATE applications a
-		JOIN release_apps ra ON a.id = ra.app_id
-		SET a.deployed_tag = ra.target_tag
-		WHERE ra.batch_id = ? AND ra.target_tag IS NOT NULL
-	`, batch.ID).Error; err != nil {
+	// 同步更新 applications.deployed_tag 为 target_tag（部署成功后的版本），跳过生产的应用未发布生产，不更新
+	if err := repository.NewApplicationRepository(h.db).SyncDeployedTagsByBatch(batch.ID); err != nil {
 		return fmt.Errorf("更新应用部署版本失败: %w", err)
 	}
 
@@ -0,0 +1,33 @@
+package transitions
+
+import (
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// PredecessorBlockReason 前置批次未完成时返回阻塞原因；无前置批次或前置批次已完成时返回空
+func PredecessorBlockReason(db *gorm.DB, batch *model.Batch) (string, error) {
+	if batch.DependsOnBatchID == nil {
+		return "", nil
+	}
+
+	var pred model.Batch
+	if err := db.Select("id", "batch_number", "status").Limit(1).Find(&pred, *batch.DependsOnBatchID).Error; err != nil {
+		return "", fmt.Errorf("查询前置批次失败: %w", err)
+	}
+	if pred.ID == 0 {
+		return fmt.Sprintf("前置批次 %d 不存在", *batch.DependsOnBatchID), nil
+	}
+
+	switch pred.Status {
+	case constants.BatchStatusCompleted:
+		return "", nil
+	case constants.BatchStatusCancelled:
+		return fmt.Sprintf("前置批次 %d「%s」已取消，请修改或移除前置批次", pred.ID, pred.BatchNumber), nil
+	default:
+		return fmt.Sprintf("等待前置批次 %d「%s」完成（当前状态: %s）", pred.ID, pred.BatchNumber, constants.BatchStatusToString(pred.Status)), nil
+	}
+}
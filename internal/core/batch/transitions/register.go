@@ -7,13 +7,13 @@ import (
 	"gorm.io/gorm"
 )
 
-func AllTransitions(db *gorm.DB) []StateTransition {
+func AllTransitions(db *gorm.DB, waves WavePlanner, charts ChartLocker, manifests ManifestLocker) []StateTransition {
 	var transitions = []StateTransition{
 		// 草稿 -> 已封板
 		{
 			From:        constants.BatchStatusDraft,
 			To:          constants.BatchStatusSealed,
-			Handler:     TriggerSealTransition{db: db, logger: logger.Sugar()},
+			Handler:     TriggerSealTransition{db: db, logger: logger.Sugar(), waves: waves, charts: charts, manifests: manifests},
 			AllowSource: SourceOutside,
 		},
 		// 已封板 -> 触发预发布（需要检查审批状态）
@@ -73,6 +73,50 @@ func AllTransitions(db *gorm.DB) []StateTransition {
 			AllowSource: SourceOutside,
 		},
 
+		// 生产部署开始后 -> 回滚中（已回滚/回滚失败后可继续回滚其他应用或重试）
+		{
+			From:        constants.BatchStatusProdDeploying,
+			To:          constants.BatchStatusRollingBack,
+			Handler:     TriggerRollbackTransition{db: db},
+			AllowSource: SourceOutside,
+		},
+		{
+			From:        constants.BatchStatusProdDeployed,
+			To:          constants.BatchStatusRollingBack,
+			Handler:     TriggerRollbackTransition{db: db},
+			AllowSource: SourceOutside,
+		},
+		{
+			From:        constants.BatchStatusProdFailed,
+			To:          constants.BatchStatusRollingBack,
+			Handler:     TriggerRollbackTransition{db: db},
+			AllowSource: SourceOutside,
+		},
+		{
+			From:        constants.BatchStatusProdAccepted,
+			To:          constants.BatchStatusRollingBack,
+			Handler:     TriggerRollbackTransition{db: db},
+			AllowSource: SourceOutside,
+		},
+		{
+			From:        constants.BatchStatusCompleted,
+			To:          constants.BatchStatusRollingBack,
+			Handler:     TriggerRollbackTransition{db: db},
+			AllowSource: SourceOutside,
+		},
+		{
+			From:        constants.BatchStatusRolledBack,
+			To:          constants.BatchStatusRollingBack,
+			Handler:     TriggerRollbackTransition{db: db},
+			AllowSource: SourceOutside,
+		},
+		{
+			From:        constants.BatchStatusRollbackFailed,
+			To:          constants.BatchStatusRollingBack,
+			Handler:     TriggerRollbackTransition{db: db},
+			AllowSource: SourceOutside,
+		},
+
 		// 草稿 -> 取消
 		{
 			From:        constants.BatchStatusDraft,
@@ -94,6 +138,31 @@ func AllTransitions(db *gorm.DB) []StateTransition {
 			Handler:     TriggerCancelTransition{db: db},
 			AllowSource: SourceOutside,
 		},
+		// 部署中超时 -> 取消（仅引擎按 batch_timeout 自动取消）
+		{
+			From:        constants.BatchStatusPreWaiting,
+			To:          constants.BatchStatusCancelled,
+			Handler:     TriggerCancelTransition{db: db},
+			AllowSource: SourceInside,
+		},
+		{
+			From:        constants.BatchStatusPreDeploying,
+			To:          constants.BatchStatusCancelled,
+			Handler:     TriggerCancelTransition{db: db},
+			AllowSource: SourceInside,
+		},
+		{
+			From:        constants.BatchStatusProdWaiting,
+			To:          constants.BatchStatusCancelled,
+			Handler:     TriggerCancelTransition{db: db},
+			AllowSource: SourceInside,
+		},
+		{
+			From:        constants.BatchStatusProdDeploying,
+			To:          constants.BatchStatusCancelled,
+			Handler:     TriggerCancelTransition{db: db},
+			AllowSource: SourceInside,
+		},
 	}
 
 	return transitions
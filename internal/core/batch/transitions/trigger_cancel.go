@@ -2,6 +2,8 @@ package transitions
 
 import (
 	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+	"fmt"
 	"gorm.io/gorm"
 	"time"
 )
@@ -16,6 +18,36 @@ func (h TriggerCancelTransition) Handle(batch *model.Batch, from, to int8, optio
 	batch.CancelledAt = &now
 	batch.CancelledBy = &options.operator
 	batch.CancelReason = &options.reason
+
+	// 部署中取消：尚未开始的 Deployment 标记失败，不再触发（已触发的 helm 操作不会被中断）
+	if from != constants.BatchStatusDraft && from != constants.BatchStatusSealed {
+		var pending []model.Deployment
+		if err := h.db.Where("batch_id = ? AND status = ?", batch.ID, constants.DeploymentStatusPending).Find(&pending).Error; err != nil {
+			return fmt.Errorf("查询未开始的 Deployment 失败: %w", err)
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		msg := fmt.Sprintf("批次已取消: %s", options.reason)
+		ids := make([]int64, 0, len(pending))
+		events := make([]*model.DeploymentEvent, 0, len(pending))
+		for i := range pending {
+			ids = append(ids, pending[i].ID)
+			events = append(events, model.NewDeploymentStatusEvent(&pending[i], constants.DeploymentStatusPending, constants.DeploymentStatusFailed, options.operator, msg))
+		}
+		if err := h.db.Model(&model.Deployment{}).
+			Where("id IN ? AND status = ?", ids, constants.DeploymentStatusPending).
+			Updates(map[string]interface{}{
+				"status":        constants.DeploymentStatusFailed,
+				"error_message": msg,
+				"finished_at":   now,
+			}).Error; err != nil {
+			return fmt.Errorf("取消未开始的 Deployment 失败: %w", err)
+		}
+		if err := h.db.Create(&events).Error; err != nil {
+			return fmt.Errorf("写入 Deployment 事件失败: %w", err)
+		}
+	}
 	return nil
 }
 
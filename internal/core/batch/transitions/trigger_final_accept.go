@@ -14,7 +14,7 @@ type FinalAcceptTransition struct {
 }
 
 func (h FinalAcceptTransition) Handle(batch *model.Batch, from, to int8, options *TransitionOptions) error {
-	// 最终验收前：必须全部 ProdAccepted
+	// 最终验收前：必须全部 ProdAccepted（跳过生产的应用为 PreOnlyCompleted）
 	var totalCount int64
 	if err := h.db.Model(&model.ReleaseApp{}).
 		Where("batch_id = ?", batch.ID).
@@ -26,11 +26,17 @@ func (h FinalAcceptTransition) Handle(batch *model.Batch, from, to int8, options
 	}
 	var prodAcceptedCount int64
 	if err := h.db.Model(&model.ReleaseApp{}).
-		Where("batch_id = ? AND status = ?", batch.ID, constants.ReleaseAppStatusProdAccepted).
+		Where("batch_id = ? AND skip_prod_env = ? AND status = ?", batch.ID, false, constants.ReleaseAppStatusProdAccepted).
 		Count(&prodAcceptedCount).Error; err != nil {
 		return err
 	}
-	if prodAcceptedCount != totalCount {
+	var preOnlyCompletedCount int64
+	if err := h.db.Model(&model.ReleaseApp{}).
+		Where("batch_id = ? AND skip_prod_env = ? AND status = ?", batch.ID, true, constants.ReleaseAppStatusPreOnlyCompleted).
+		Count(&preOnlyCompletedCount).Error; err != nil {
+		return err
+	}
+	if prodAcceptedCount+preOnlyCompletedCount != totalCount {
 		return fmt.Errorf("有未验收的生产环境，无法最终验收")
 	}
 
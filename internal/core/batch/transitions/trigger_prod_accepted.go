@@ -30,9 +30,9 @@ func (h *TriggerProdAccepted) Handle(batch *model.Batch, from, to int8, options
 		return fmt.Errorf("批次无发布记录，无法进行生产验收(数据可能异常, 请联系管理员)")
 	}
 
-	// 检查未部署的应用count (未部署/未验证)
+	// 检查未部署的应用count (未部署/未验证), 跳过生产的应用不参与生产发布
 	var prodDeployedCount int64
-	if err := h.db.Model(&model.ReleaseApp{}).Where("batch_id = ?", batch.ID).
+	if err := h.db.Model(&model.ReleaseApp{}).Where("batch_id = ? AND skip_prod_env = ?", batch.ID, false).
 		Where("status NOT IN ?", []int8{constants.ReleaseAppStatusProdDeployed, constants.ReleaseAppStatusProdFailed}).
 		Count(&prodDeployedCount).Error; err != nil {
 		return err
@@ -3,6 +3,7 @@ package transitions
 import (
 	"devops-cd/internal/model"
 	"devops-cd/pkg/constants"
+	"errors"
 	"fmt"
 	"time"
 
@@ -15,6 +16,18 @@ type TriggerProdDeployTransition struct {
 }
 
 func (h TriggerProdDeployTransition) Handle(batch *model.Batch, from, to int8, options *TransitionOptions) error {
+	// 前置批次需已完成
+	if reason, err := PredecessorBlockReason(h.db, batch); err != nil {
+		return err
+	} else if reason != "" {
+		return errors.New(reason)
+	}
+	// 双人原则：触发人不能是批次发起人或审批人
+	if reason, err := TwoPersonBlockReason(h.db, batch, options.operator); err != nil {
+		return err
+	} else if reason != "" {
+		return errors.New(reason)
+	}
 
 	if batch.Status == constants.BatchStatusPreAccepted {
 		// 当前为预发布验收完成状态: 检查所有预发布已验收
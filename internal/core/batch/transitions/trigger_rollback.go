@@ -0,0 +1,22 @@
+package transitions
+
+import (
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// TriggerRollbackTransition 处理批次回滚：生产部署开始后（含已完成/已回滚）进入回滚中
+// 具体回滚哪些应用由调用方逐个触发 ReleaseApp 回滚，批次只负责汇总回滚结果
+type TriggerRollbackTransition struct {
+	db *gorm.DB
+}
+
+func (h TriggerRollbackTransition) Handle(batch *model.Batch, from, to int8, options *TransitionOptions) error {
+	return nil
+}
+
+func (h TriggerRollbackTransition) After(batch *model.Batch, from, to int8, options *TransitionOptions) {
+	logger.Sugar().Infof("[Batch SM: %d] 回滚触发 by %s: %s", batch.ID, options.operator, options.reason)
+}
@@ -1,7 +1,10 @@
 package transitions
 
 import (
+	"context"
+	"devops-cd/internal/core/common/vulngate"
 	"devops-cd/internal/model"
+	"devops-cd/internal/repository"
 	"devops-cd/pkg/constants"
 	"fmt"
 	"time"
@@ -10,10 +13,28 @@ import (
 	"gorm.io/gorm"
 )
 
+// WavePlanner 计算批次内应用的部署波次（app_id -> wave，从 1 开始）
+type WavePlanner interface {
+	PlanWaves(ctx context.Context, batchID int64) (map[int64]int, error)
+}
+
+// ChartLocker 解析并固化批次内发布应用的 chart 版本/digest（chart lock）
+type ChartLocker interface {
+	LockCharts(ctx context.Context, batchID int64) error
+}
+
+// ManifestLocker 读取构建对应提交中的代码库清单并固化到发布应用（manifest lock）
+type ManifestLocker interface {
+	LockManifests(ctx context.Context, batchID int64) error
+}
+
 // TriggerSealTransition 处理封板
 type TriggerSealTransition struct {
-	db     *gorm.DB
-	logger *zap.SugaredLogger
+	db        *gorm.DB
+	logger    *zap.SugaredLogger
+	waves     WavePlanner
+	charts    ChartLocker
+	manifests ManifestLocker
 }
 
 func (h TriggerSealTransition) Handle(batch *model.Batch, from, to int8, options *TransitionOptions) error {
@@ -39,23 +60,59 @@ func (h TriggerSealTransition) Handle(batch *model.Batch, from, to int8, options
 		return fmt.Errorf("封板失败: 以下应用没有构建记录，不允许封板: %v", appsWithoutBuild)
 	}
 
+	// 3.1 manifest lock：构建提交中的代码库清单声明了该应用时，部署环境/集群、values 与依赖以清单为准
+	if h.manifests != nil {
+		if err := h.manifests.LockManifests(context.Background(), batch.ID); err != nil {
+			return fmt.Errorf("封板失败: %w", err)
+		}
+	}
+	envInfos, err := h.releaseAppEnvs(batch.ID)
+	if err != nil {
+		return err
+	}
+
+	// 4. pre_only 应用必须配置预发布环境
+	var invalidPreOnly []int64
+	for _, info := range envInfos {
+		if info.PreOnly && !info.HasPre {
+			invalidPreOnly = append(invalidPreOnly, info.AppID)
+		}
+	}
+	if len(invalidPreOnly) > 0 {
+		return fmt.Errorf("封板失败: 以下应用标记为仅预发布(pre_only)但未配置预发布环境: %v", invalidPreOnly)
+	}
+
+	// 4.1 至少配置一个部署环境（跳过预发布且跳过生产的应用无法发布）
+	var invalidNoEnv []int64
+	for _, info := range envInfos {
+		if !info.HasPre && !info.HasProd {
+			invalidNoEnv = append(invalidNoEnv, info.AppID)
+		}
+	}
+	if len(invalidNoEnv) > 0 {
+		return fmt.Errorf("封板失败: 以下应用未配置预发布及生产环境: %v", invalidNoEnv)
+	}
+
+	// 5. 镜像漏洞门禁（项目策略 stage=seal，批次豁免后跳过）
+	if err := h.checkVulnGate(batch, releaseApps); err != nil {
+		return err
+	}
+
+	// 6. chart lock：解析 chart 确切版本/digest，pre 与 prod 部署使用同一份 chart（仓库不可达或版本不存在时拒绝封板）
+	if h.charts != nil {
+		if err := h.charts.LockCharts(context.Background(), batch.ID); err != nil {
+			return fmt.Errorf("封板失败: %w", err)
+		}
+	}
+
 	// 1. 记录部署前版本（从 applications.deployed_tag 获取）
-	if err := h.db.Exec(`
-		UPDATE release_apps ra
-		JOIN applications a ON ra.app_id = a.id
-		SET ra.previous_deployed_tag = COALESCE(a.deployed_tag, '')
-		WHERE ra.batch_id = ? AND ra.is_locked = false
-	`, batch.ID).Error; err != nil {
+	releaseAppRepo := repository.NewReleaseAppRepository(h.db)
+	if err := releaseAppRepo.SnapshotPreviousDeployedTags(batch.ID); err != nil {
 		return fmt.Errorf("记录部署前版本失败: %w", err)
 	}
 
 	// 2. 记录目标版本（从 build.image_tag 获取并固定）todo
-	if err := h.db.Exec(`
-		UPDATE release_apps ra
-		JOIN builds b ON ra.build_id = b.id
-		SET ra.target_tag = b.image_tag
-		WHERE ra.batch_id = ? AND ra.build_id IS NOT NULL
-	`, batch.ID).Error; err != nil {
+	if err := releaseAppRepo.PinTargetTags(batch.ID); err != nil {
 		return fmt.Errorf("记录目标版本失败: %w", err)
 	}
 
@@ -66,43 +123,21 @@ func (h TriggerSealTransition) Handle(batch *model.Batch, from, to int8, options
 		return fmt.Errorf("锁定应用记录失败: %w", err)
 	}
 
-	// 4. 计算并固化 skip_pre_env 标记
-	type ReleaseAppEnvInfo struct {
-		ReleaseAppID int64
-		AppID        int64
-		SkipPreEnv   bool
-	}
-
-	var releaseAppEnvInfos []ReleaseAppEnvInfo
-	err := h.db.Raw(`
-		SELECT 
-			ra.id as release_app_id,
-			ra.app_id,
-			NOT EXISTS(
-				SELECT 1 FROM app_env_configs 
-				WHERE app_id = ra.app_id 
-				AND env = 'pre' 
-				AND status = 1
-				AND deleted_at IS NULL
-			) as skip_pre_env
-		FROM release_apps ra
-		WHERE ra.batch_id = ?
-	`, batch.ID).Scan(&releaseAppEnvInfos).Error
-
-	if err != nil {
-		return fmt.Errorf("查询应用环境配置失败: %w", err)
-	}
-
-	// 批量更新 skip_pre_env
-	for _, info := range releaseAppEnvInfos {
+	// 4. 固化 skip_pre_env / skip_prod_env 标记
+	for _, info := range envInfos {
 		if err := h.db.Model(&model.ReleaseApp{}).
 			Where("id = ?", info.ReleaseAppID).
-			Update("skip_pre_env", info.SkipPreEnv).Error; err != nil {
-			return fmt.Errorf("更新 skip_pre_env 失败: %w", err)
+			Updates(map[string]interface{}{"skip_pre_env": !info.HasPre, "skip_prod_env": info.PreOnly || !info.HasProd}).Error; err != nil {
+			return fmt.Errorf("更新 skip_pre_env/skip_prod_env 失败: %w", err)
 		}
 	}
 
-	h.logger.Infof("Batch:%d 封板完成,计算了 %d 个应用的环境配置", batch.ID, len(releaseAppEnvInfos))
+	// 5. 按依赖计算并固化部署波次（循环依赖时拒绝封板）
+	if err := h.sealWaves(batch.ID); err != nil {
+		return err
+	}
+
+	h.logger.Infof("Batch:%d 封板完成,计算了 %d 个应用的环境配置", batch.ID, len(envInfos))
 
 	// 记录时间/操作人
 	now := time.Now()
@@ -112,6 +147,99 @@ func (h TriggerSealTransition) Handle(batch *model.Batch, from, to int8, options
 	return nil
 }
 
+// releaseAppEnvInfo 发布应用是否有可部署的预发布/生产集群
+type releaseAppEnvInfo struct {
+	ReleaseAppID int64
+	AppID        int64
+	PreOnly      bool
+	HasPre       bool
+	HasProd      bool
+}
+
+// releaseAppEnvs 按启用的应用环境配置计算发布应用的部署环境（manifest lock 生效时只计清单声明的集群）
+func (h TriggerSealTransition) releaseAppEnvs(batchID int64) ([]releaseAppEnvInfo, error) {
+	var releaseApps []model.ReleaseApp
+	if err := h.db.Select("id", "app_id", "build_id", "pre_only", "manifest_lock").
+		Where("batch_id = ?", batchID).Find(&releaseApps).Error; err != nil {
+		return nil, fmt.Errorf("查询%s失败: %w", model.ReleaseApp{}.TableName(), err)
+	}
+	appIDs := make([]int64, 0, len(releaseApps))
+	for _, ra := range releaseApps {
+		appIDs = append(appIDs, ra.AppID)
+	}
+	var configs []model.AppEnvConfig
+	if err := h.db.Where("app_id IN ? AND env IN ? AND status = 1", appIDs, []string{constants.EnvTypePre, constants.EnvTypeProd}).
+		Find(&configs).Error; err != nil {
+		return nil, fmt.Errorf("查询应用环境配置失败: %w", err)
+	}
+	byAppEnv := make(map[string][]model.AppEnvConfig, len(configs))
+	for _, cfg := range configs {
+		key := fmt.Sprintf("%d/%s", cfg.AppID, cfg.Env)
+		byAppEnv[key] = append(byAppEnv[key], cfg)
+	}
+
+	infos := make([]releaseAppEnvInfo, 0, len(releaseApps))
+	for _, ra := range releaseApps {
+		infos = append(infos, releaseAppEnvInfo{
+			ReleaseAppID: ra.ID,
+			AppID:        ra.AppID,
+			PreOnly:      ra.PreOnly,
+			HasPre:       len(ra.EnvClusters(byAppEnv[fmt.Sprintf("%d/%s", ra.AppID, constants.EnvTypePre)], constants.EnvTypePre)) > 0,
+			HasProd:      len(ra.EnvClusters(byAppEnv[fmt.Sprintf("%d/%s", ra.AppID, constants.EnvTypeProd)], constants.EnvTypeProd)) > 0,
+		})
+	}
+	return infos, nil
+}
+
+// checkVulnGate 按项目漏洞策略检查批次内构建镜像，未通过时拒绝封板
+func (h TriggerSealTransition) checkVulnGate(batch *model.Batch, releaseApps []model.ReleaseApp) error {
+	policy, err := vulngate.Policy(h.db, batch, model.VulnGateStageSeal)
+	if err != nil || policy == nil {
+		return err
+	}
+	buildIDs := make([]int64, 0, len(releaseApps))
+	for _, app := range releaseApps {
+		buildIDs = append(buildIDs, *app.BuildID)
+	}
+	var builds []model.Build
+	if err := h.db.Where("id IN ?", buildIDs).Find(&builds).Error; err != nil {
+		return fmt.Errorf("查询构建记录失败: %w", err)
+	}
+	violations, err := vulngate.Evaluate(h.db, policy, builds)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("封板失败: %s（可由具备漏洞豁免权限的用户豁免后重试）", vulngate.Summary(violations))
+	}
+	return nil
+}
+
+// sealWaves 写入 release_apps.wave，同一波次批量更新
+func (h TriggerSealTransition) sealWaves(batchID int64) error {
+	if h.waves == nil {
+		return nil
+	}
+	waves, err := h.waves.PlanWaves(context.Background(), batchID)
+	if err != nil {
+		return fmt.Errorf("封板失败: %w", err)
+	}
+
+	appsByWave := make(map[int][]int64)
+	for appID, wave := range waves {
+		appsByWave[wave] = append(appsByWave[wave], appID)
+	}
+	for wave, appIDs := range appsByWave {
+		if err := h.db.Model(&model.ReleaseApp{}).
+			Where("batch_id = ? AND app_id IN ?", batchID, appIDs).
+			Update("wave", wave).Error; err != nil {
+			return fmt.Errorf("更新部署波次失败: %w", err)
+		}
+	}
+	h.logger.Infof("Batch:%d 部署波次计算完成，共 %d 波", batchID, len(appsByWave))
+	return nil
+}
+
 func (h TriggerSealTransition) After(batch *model.Batch, from, to int8, options *TransitionOptions) {
 	// todo: send notification
 }
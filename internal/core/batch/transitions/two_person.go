@@ -0,0 +1,41 @@
+package transitions
+
+import (
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// TwoPersonBlockReason 项目开启生产部署双人原则（projects.prod_two_person_rule）时，
+// 触发人与批次发起人或审批人（单人审批的 approved_by 及多人审批中已通过的审批人）相同则返回阻塞原因；未开启或不冲突时返回空
+func TwoPersonBlockReason(db *gorm.DB, batch *model.Batch, operator string) (string, error) {
+	var project model.Project
+	if err := db.Select("id", "prod_two_person_rule").Limit(1).Find(&project, batch.ProjectID).Error; err != nil {
+		return "", fmt.Errorf("查询项目失败: %w", err)
+	}
+	if !project.ProdTwoPersonRule {
+		return "", nil
+	}
+	if operator == "" {
+		return "项目开启了生产部署双人原则，触发人不能为空", nil
+	}
+
+	if operator == batch.Initiator {
+		return fmt.Sprintf("项目开启了生产部署双人原则：%s 是批次发起人，不能触发生产部署，请由其他人操作", operator), nil
+	}
+	if batch.ApprovedBy != nil && *batch.ApprovedBy == operator {
+		return fmt.Sprintf("项目开启了生产部署双人原则：%s 是批次审批人，不能触发生产部署，请由其他人操作", operator), nil
+	}
+	var approved int64
+	if err := db.Model(&model.BatchApproval{}).
+		Where("batch_id = ? AND approver = ? AND decision = ?", batch.ID, operator, constants.ApprovalStatusApproved).
+		Count(&approved).Error; err != nil {
+		return "", fmt.Errorf("查询批次审批记录失败: %w", err)
+	}
+	if approved > 0 {
+		return fmt.Sprintf("项目开启了生产部署双人原则：%s 是批次审批人，不能触发生产部署，请由其他人操作", operator), nil
+	}
+	return "", nil
+}
@@ -40,6 +40,7 @@ type TransitionOption func(*TransitionOptions)
 type TransitionOptions struct {
 	operator string
 	reason   string
+	trigger  string // 审计触发方式，见 constants.BatchEventTrigger*
 	// data       map[string]interface{}
 	SideEffect func(b *model.Batch)
 }
@@ -53,3 +54,10 @@ func WithOperator(operator string) TransitionOption {
 func WithReason(reason string) TransitionOption {
 	return func(o *TransitionOptions) { o.reason = reason }
 }
+func WithTrigger(trigger string) TransitionOption {
+	return func(o *TransitionOptions) { o.trigger = trigger }
+}
+
+func (o *TransitionOptions) Operator() string { return o.operator }
+func (o *TransitionOptions) Reason() string   { return o.reason }
+func (o *TransitionOptions) Trigger() string  { return o.trigger }
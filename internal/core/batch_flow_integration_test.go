@@ -0,0 +1,164 @@
+//go:build integration
+
+package core_test
+
+import (
+	"testing"
+
+	"devops-cd/internal/model"
+	"devops-cd/internal/testutil/enginetest"
+	"devops-cd/pkg/constants"
+)
+
+const maxSteps = 30
+
+// seedFlow 一个项目两个应用：web 配置 pre+prod，worker 只配置 prod（跳过预发布）；各有一条成功构建
+func seedFlow(h *enginetest.Harness) (web, worker *model.Application, batch *model.Batch) {
+	h.SeedCluster("it-pre", "")
+	h.SeedCluster("it-prod", "")
+	project := h.SeedProject("it-pre", "it-prod")
+	web = h.SeedApp(project.ID, map[string][]string{
+		constants.EnvTypePre:  {"it-pre"},
+		constants.EnvTypeProd: {"it-prod"},
+	})
+	worker = h.SeedApp(project.ID, map[string][]string{
+		constants.EnvTypeProd: {"it-prod"},
+	})
+	h.SeedBuild(web, "web-v1")
+	h.SeedBuild(worker, "worker-v1")
+	batch = h.CreateBatch(project.ID, web, worker)
+	return web, worker, batch
+}
+
+// approve 审批不在本测试范围内，直接置为已通过
+func approve(t *testing.T, h *enginetest.Harness, batchID int64) {
+	t.Helper()
+	if err := h.DB.Model(&model.Batch{}).Where("id = ?", batchID).
+		Update("approval_status", constants.ApprovalStatusApproved).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func releaseApps(t *testing.T, h *enginetest.Harness, batchID int64) map[int64]model.ReleaseApp {
+	t.Helper()
+	var list []model.ReleaseApp
+	if err := h.DB.Where("batch_id = ?", batchID).Find(&list).Error; err != nil {
+		t.Fatal(err)
+	}
+	out := make(map[int64]model.ReleaseApp, len(list))
+	for _, ra := range list {
+		out[ra.AppID] = ra
+	}
+	return out
+}
+
+func statusIs(status int8) func(*model.Batch) bool {
+	return func(b *model.Batch) bool { return b.Status == status }
+}
+
+// TestBatchSealDeployFlow 批次 封板 → 预发布 → 验收 → 生产 → 完成，部署由 fake driver 执行
+func TestBatchSealDeployFlow(t *testing.T) {
+	h := enginetest.New(t)
+	web, worker, batch := seedFlow(h)
+
+	h.Action(batch.ID, constants.BatchActionSeal)
+	if got := h.Batch(batch.ID); got.Status != constants.BatchStatusSealed {
+		t.Fatalf("封板后 status=%d, want %d", got.Status, constants.BatchStatusSealed)
+	}
+	apps := releaseApps(t, h, batch.ID)
+	for app, tag := range map[*model.Application]string{web: "web-v1", worker: "worker-v1"} {
+		ra := apps[app.ID]
+		if !ra.IsLocked || ra.TargetTag == nil || *ra.TargetTag != tag {
+			t.Errorf("%s 封板后 is_locked=%v target_tag=%v, want locked %s", app.Name, ra.IsLocked, ra.TargetTag, tag)
+		}
+	}
+	if !apps[worker.ID].SkipPreEnv || apps[web.ID].SkipPreEnv {
+		t.Errorf("skip_pre_env: web=%v worker=%v, want false/true", apps[web.ID].SkipPreEnv, apps[worker.ID].SkipPreEnv)
+	}
+
+	// 预发布：只部署 web
+	approve(t, h, batch.ID)
+	h.Action(batch.ID, constants.BatchActionStartPre)
+	h.RunUntil(batch.ID, statusIs(constants.BatchStatusPreDeployed), maxSteps)
+	pre := h.Deployments(batch.ID)
+	if len(pre) != 1 || pre[0].AppID != web.ID || pre[0].Env != constants.EnvTypePre ||
+		pre[0].ClusterName != "it-pre" || pre[0].Status != constants.DeploymentStatusSuccess {
+		t.Fatalf("预发布部署记录不符合预期: %+v", pre)
+	}
+
+	// 生产：两个应用都部署，完成后回写 deployed_tag
+	h.Action(batch.ID, constants.BatchActionAcceptPre)
+	h.Action(batch.ID, constants.BatchActionStartProd)
+	h.RunUntil(batch.ID, statusIs(constants.BatchStatusProdDeployed), maxSteps)
+	prod := map[int64]model.Deployment{}
+	for _, dep := range h.Deployments(batch.ID) {
+		if dep.Env == constants.EnvTypeProd {
+			prod[dep.AppID] = dep
+		}
+	}
+	for _, app := range []*model.Application{web, worker} {
+		dep, ok := prod[app.ID]
+		if !ok || dep.ClusterName != "it-prod" || dep.Status != constants.DeploymentStatusSuccess {
+			t.Errorf("%s 生产部署记录不符合预期: %+v", app.Name, dep)
+		}
+		var got model.Application
+		if err := h.DB.First(&got, app.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		if want := *releaseApps(t, h, batch.ID)[app.ID].TargetTag; got.DeployedTag == nil || *got.DeployedTag != want {
+			t.Errorf("%s deployed_tag=%v, want %s", app.Name, got.DeployedTag, want)
+		}
+	}
+
+	// fake driver 按封板固定的版本部署
+	calls := map[int64]int{}
+	for _, call := range h.Driver.Calls() {
+		want := map[int64]string{web.ID: "web-v1", worker.ID: "worker-v1"}[call.AppID]
+		if call.ImageTag != want {
+			t.Errorf("driver 调用 app=%d env=%s image_tag=%s, want %s", call.AppID, call.Env, call.ImageTag, want)
+		}
+		calls[call.AppID]++
+	}
+	if calls[web.ID] == 0 || calls[worker.ID] == 0 {
+		t.Errorf("driver 调用次数 web=%d worker=%d", calls[web.ID], calls[worker.ID])
+	}
+
+	h.Action(batch.ID, constants.BatchActionAcceptProd)
+	h.Action(batch.ID, constants.BatchActionComplete)
+	if got := h.Batch(batch.ID); got.Status != constants.BatchStatusCompleted {
+		t.Fatalf("完成后 status=%d, want %d", got.Status, constants.BatchStatusCompleted)
+	}
+}
+
+// TestBatchProdDeployFailure 生产集群部署失败：应用标记为生产失败，批次不进入已部署，deployed_tag 不回写
+func TestBatchProdDeployFailure(t *testing.T) {
+	h := enginetest.New(t)
+	_, worker, batch := seedFlow(h)
+	h.Driver.FailCluster("it-prod", "fake failure")
+
+	h.Action(batch.ID, constants.BatchActionSeal)
+	approve(t, h, batch.ID)
+	h.Action(batch.ID, constants.BatchActionStartPre)
+	h.RunUntil(batch.ID, statusIs(constants.BatchStatusPreDeployed), maxSteps)
+	h.Action(batch.ID, constants.BatchActionAcceptPre)
+	h.Action(batch.ID, constants.BatchActionStartProd)
+
+	failed := false
+	for i := 0; i < maxSteps && !failed; i++ {
+		h.Step(batch.ID)
+		failed = releaseApps(t, h, batch.ID)[worker.ID].Status == constants.ReleaseAppStatusProdFailed
+	}
+	if !failed {
+		t.Fatalf("%d 次处理后 worker 未进入生产失败", maxSteps)
+	}
+	if got := h.Batch(batch.ID); got.Status == constants.BatchStatusProdDeployed {
+		t.Errorf("生产部署失败时批次不应进入 ProdDeployed")
+	}
+	var got model.Application
+	if err := h.DB.First(&got, worker.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.DeployedTag != nil && *got.DeployedTag != "" {
+		t.Errorf("生产部署失败时 deployed_tag 不应回写, got %s", *got.DeployedTag)
+	}
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"devops-cd/internal/adapter/notification"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/config"
+	"devops-cd/pkg/constants"
+
+	"go.uber.org/zap"
+)
+
+// batchTimeout 批次部署阶段超时控制（core.deploy.batch_timeout）
+type batchTimeout struct {
+	timeout time.Duration
+	action  string // notify / cancel
+
+	// 已通知的批次阶段（batch_id/phase），同一阶段只通知一次
+	notified sync.Map
+}
+
+func newBatchTimeout(coreCfg *config.CoreConfig, logger *zap.Logger) *batchTimeout {
+	if coreCfg == nil || coreCfg.Deploy.BatchTimeout == "" {
+		return nil
+	}
+	timeout, err := time.ParseDuration(coreCfg.Deploy.BatchTimeout)
+	if err != nil || timeout <= 0 {
+		logger.Warn("batch_timeout 无效，不检查批次超时", zap.String("value", coreCfg.Deploy.BatchTimeout), zap.Error(err))
+		return nil
+	}
+	action := coreCfg.Deploy.BatchTimeoutAction
+	if action != constants.BatchTimeoutActionCancel {
+		action = constants.BatchTimeoutActionNotify
+	}
+	return &batchTimeout{timeout: timeout, action: action}
+}
+
+// batchPhase 批次当前所处的部署阶段及开始时间，非部署中返回空
+func batchPhase(b *model.Batch) (string, *time.Time) {
+	switch b.Status {
+	case constants.BatchStatusPreWaiting, constants.BatchStatusPreDeploying:
+		return constants.EnvTypePre, b.PreStartedAt
+	case constants.BatchStatusProdWaiting, constants.BatchStatusProdDeploying:
+		return constants.EnvTypeProd, b.ProdStartedAt
+	}
+	return "", nil
+}
+
+// checkBatchTimeout 批次部署阶段（预发布/生产分别计时）超过 batch_timeout 时通知，配置 cancel 时自动取消批次
+func (e *CoreEngine) checkBatchTimeout(ctx context.Context, b *model.Batch) {
+	bt := e.batchTimeout
+	if bt == nil {
+		return
+	}
+	phase, startedAt := batchPhase(b)
+	if phase == "" || startedAt == nil {
+		return
+	}
+	elapsed := time.Since(*startedAt)
+	if elapsed <= bt.timeout {
+		return
+	}
+	key := fmt.Sprintf("%d/%s", b.ID, phase)
+	if _, done := bt.notified.LoadOrStore(key, struct{}{}); done {
+		return
+	}
+
+	reason := fmt.Sprintf("批次%s部署超时：已运行 %s（上限 %s）", envLabel(phase), elapsed.Round(time.Second), bt.timeout)
+	e.logger.Warn(fmt.Sprintf("[BatchScaner] Batch:%d %s", b.ID, reason), zap.Int64("batch_id", b.ID), zap.String("action", bt.action))
+
+	message := reason
+	if bt.action == constants.BatchTimeoutActionCancel {
+		if err := e.batchSM.TimeoutCancel(b.ID, reason); err != nil {
+			e.logger.Error("批次超时自动取消失败", zap.Int64("batch_id", b.ID), zap.Error(err))
+			message += fmt.Sprintf("，自动取消失败: %v", err)
+		} else {
+			message += "，已自动取消"
+		}
+	}
+
+	batch := *b
+	e.enqueueNotify(func(ctx context.Context) error {
+		return e.notifier.SendBatchNotification(ctx, &batch, notification.NotifyBatchFailed, message)
+	})
+}
+
+func envLabel(env string) string {
+	if env == constants.EnvTypeProd {
+		return "生产"
+	}
+	return "预发布"
+}
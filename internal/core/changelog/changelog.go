@@ -0,0 +1,335 @@
+package changelog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/crypto"
+	"devops-cd/internal/pkg/git"
+	"devops-cd/internal/pkg/git/api"
+
+	"gorm.io/gorm"
+)
+
+// 批次变更日志
+//
+// - 每个发布应用对比 previous_deployed_tag 与 target_tag（未封板时为 applications.deployed_tag 与所选构建）之间的提交
+// - tag 优先按构建记录解析为 commit SHA，找不到构建时直接作为 git ref 对比
+// - 提交通过应用代码库所属仓库源（平台与命名空间一致）的 Git API 拉取，单个应用失败只记录错误，不影响其他应用
+// - 按应用所属团队分组（应用未设置团队时取代码库团队）
+
+// ErrBatchNotFound 批次不存在
+var ErrBatchNotFound = errors.New("批次不存在")
+
+// UnassignedTeam 未设置团队的应用分组名
+const UnassignedTeam = "未分配团队"
+
+// maxCommitsPerApp 单个应用最多保留的提交数
+const maxCommitsPerApp = 200
+
+// Changelog 批次变更日志
+type Changelog struct {
+	BatchID     int64            `json:"batch_id"`
+	BatchNumber string           `json:"batch_number"`
+	ProjectID   int64            `json:"project_id"`
+	GeneratedAt time.Time        `json:"generated_at"`
+	Teams       []*TeamChangelog `json:"teams"`
+}
+
+// TeamChangelog 团队变更日志
+type TeamChangelog struct {
+	TeamID   *int64          `json:"team_id"`
+	TeamName string          `json:"team_name"`
+	Apps     []*AppChangelog `json:"apps"`
+}
+
+// AppChangelog 应用变更日志
+type AppChangelog struct {
+	AppID        int64            `json:"app_id"`
+	AppName      string           `json:"app_name"`
+	Repo         string           `json:"repo"` // namespace/name
+	ReleaseNotes *string          `json:"release_notes,omitempty"`
+	FromTag      string           `json:"from_tag"`
+	ToTag        string           `json:"to_tag"`
+	FromRef      string           `json:"from_ref"` // 实际对比的 git ref（commit SHA 或 tag）
+	ToRef        string           `json:"to_ref"`
+	Commits      []api.CommitInfo `json:"commits"`
+	Truncated    bool             `json:"truncated"`       // 提交数超过上限被截断
+	Error        string           `json:"error,omitempty"` // 无法生成时的原因（首次发布、仓库源缺失、Git API 失败等）
+}
+
+// Generator 变更日志生成器
+type Generator struct {
+	db *gorm.DB
+}
+
+func NewGenerator(db *gorm.DB) *Generator {
+	return &Generator{db: db}
+}
+
+// Generate 生成批次变更日志
+func (g *Generator) Generate(ctx context.Context, batchID int64) (*Changelog, error) {
+	var batch model.Batch
+	if err := g.db.WithContext(ctx).First(&batch, batchID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBatchNotFound
+		}
+		return nil, fmt.Errorf("查询批次失败: %w", err)
+	}
+
+	var releases []*model.ReleaseApp
+	if err := g.db.WithContext(ctx).
+		Preload("Application.Team").Preload("Application.Repository.Team").Preload("Build").
+		Where("batch_id = ?", batch.ID).Order("id").Find(&releases).Error; err != nil {
+		return nil, fmt.Errorf("查询发布应用失败: %w", err)
+	}
+
+	var sources []*model.RepoSource
+	if err := g.db.WithContext(ctx).Where("enabled = ?", true).Find(&sources).Error; err != nil {
+		return nil, fmt.Errorf("查询仓库源失败: %w", err)
+	}
+
+	r := &run{g: g, ctx: ctx, sources: sources, clients: map[int64]*git.Client{}, compares: map[string]compareResult{}}
+	out := &Changelog{
+		BatchID:     batch.ID,
+		BatchNumber: batch.BatchNumber,
+		ProjectID:   batch.ProjectID,
+		GeneratedAt: time.Now(),
+		Teams:       []*TeamChangelog{},
+	}
+	teams := map[string]*TeamChangelog{}
+	for _, ra := range releases {
+		if ra.Application == nil {
+			continue
+		}
+		team := teamOf(ra.Application)
+		key := team.TeamName
+		if teams[key] == nil {
+			teams[key] = team
+			out.Teams = append(out.Teams, team)
+		}
+		teams[key].Apps = append(teams[key].Apps, r.appChangelog(ra))
+	}
+	// 团队按名称排序，未分配团队排在最后
+	sort.SliceStable(out.Teams, func(i, j int) bool {
+		a, b := out.Teams[i], out.Teams[j]
+		if (a.TeamID == nil) != (b.TeamID == nil) {
+			return b.TeamID == nil
+		}
+		return a.TeamName < b.TeamName
+	})
+	return out, nil
+}
+
+func teamOf(app *model.Application) *TeamChangelog {
+	switch {
+	case app.Team != nil:
+		return &TeamChangelog{TeamID: &app.Team.ID, TeamName: app.Team.Name}
+	case app.Repository != nil && app.Repository.Team != nil:
+		return &TeamChangelog{TeamID: &app.Repository.Team.ID, TeamName: app.Repository.Team.Name}
+	}
+	return &TeamChangelog{TeamName: UnassignedTeam}
+}
+
+type compareResult struct {
+	commits []api.CommitInfo
+	err     error
+}
+
+// run 单次生成的上下文：同一仓库源复用 Git 客户端，同一对比区间只请求一次
+type run struct {
+	g        *Generator
+	ctx      context.Context
+	sources  []*model.RepoSource
+	clients  map[int64]*git.Client
+	compares map[string]compareResult
+}
+
+func (r *run) appChangelog(ra *model.ReleaseApp) *AppChangelog {
+	app := ra.Application
+	item := &AppChangelog{
+		AppID:        app.ID,
+		AppName:      app.Name,
+		ReleaseNotes: ra.ReleaseNotes,
+		Commits:      []api.CommitInfo{},
+	}
+	if app.Repository != nil {
+		item.Repo = app.Repository.Namespace + "/" + app.Repository.Name
+	}
+
+	// 封板后使用固化的版本，封板前使用应用当前部署版本与所选构建
+	switch {
+	case ra.PreviousDeployedTag != nil:
+		item.FromTag = *ra.PreviousDeployedTag
+	case ra.TargetTag == nil && app.DeployedTag != nil:
+		item.FromTag = *app.DeployedTag
+	}
+	switch {
+	case ra.TargetTag != nil:
+		item.ToTag = *ra.TargetTag
+	case ra.Build != nil:
+		item.ToTag = ra.Build.ImageTag
+	}
+
+	if item.ToTag == "" {
+		item.Error = "未选择构建"
+		return item
+	}
+	if item.FromTag == "" {
+		item.Error = "首次发布，无可对比的历史版本"
+		return item
+	}
+	if app.Repository == nil {
+		item.Error = "应用未关联代码库"
+		return item
+	}
+
+	item.FromRef = r.resolveRef(app.ID, item.FromTag, nil)
+	item.ToRef = r.resolveRef(app.ID, item.ToTag, ra.Build)
+	if item.FromRef == item.ToRef {
+		return item
+	}
+
+	commits, err := r.compare(app.Repository, item.FromRef, item.ToRef)
+	if err != nil {
+		item.Error = err.Error()
+		return item
+	}
+	if len(commits) > maxCommitsPerApp {
+		commits, item.Truncated = commits[len(commits)-maxCommitsPerApp:], true
+	}
+	item.Commits = commits
+	return item
+}
+
+// resolveRef 将镜像 tag 解析为构建 commit SHA（所选构建的 tag 一致时直接使用），找不到构建时返回 tag 本身
+func (r *run) resolveRef(appID int64, tag string, build *model.Build) string {
+	if build != nil && build.ImageTag == tag && build.CommitSHA != "" {
+		return build.CommitSHA
+	}
+	var b model.Build
+	if err := r.g.db.WithContext(r.ctx).Select("id", "commit_sha").
+		Where("app_id = ? AND image_tag = ?", appID, tag).
+		Order("id DESC").Limit(1).Find(&b).Error; err == nil && b.CommitSHA != "" {
+		return b.CommitSHA
+	}
+	return tag
+}
+
+func (r *run) compare(repo *model.Repository, base, head string) ([]api.CommitInfo, error) {
+	key := fmt.Sprintf("%d|%s|%s", repo.ID, base, head)
+	if res, ok := r.compares[key]; ok {
+		return res.commits, res.err
+	}
+
+	commits, err := func() ([]api.CommitInfo, error) {
+		client, err := r.client(repo)
+		if err != nil {
+			return nil, err
+		}
+		commits, err := client.CompareCommits(repo.Namespace, repo.Name, base, head)
+		if errors.Is(err, api.ErrRefNotFound) {
+			return nil, fmt.Errorf("代码库 %s/%s 中未找到 %s 或 %s", repo.Namespace, repo.Name, base, head)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("查询提交失败: %w", err)
+		}
+		return commits, nil
+	}()
+	r.compares[key] = compareResult{commits: commits, err: err}
+	return commits, err
+}
+
+// client 代码库所属的启用仓库源（平台与命名空间一致）的 Git 客户端
+func (r *run) client(repo *model.Repository) (*git.Client, error) {
+	for _, source := range r.sources {
+		if source.Platform != repo.GitType || source.Namespace != repo.Namespace {
+			continue
+		}
+		if c, ok := r.clients[source.ID]; ok {
+			return c, nil
+		}
+		token, err := crypto.DecryptWithKeyID(source.AuthTokenEnc, source.AuthTokenKeyID)
+		if err != nil {
+			return nil, fmt.Errorf("解密仓库源 token 失败: %w", err)
+		}
+		c, err := git.NewClient(source.BaseURL, token, source.Platform)
+		if err != nil {
+			return nil, err
+		}
+		r.clients[source.ID] = c
+		return c, nil
+	}
+	return nil, fmt.Errorf("未找到代码库 %s/%s 对应的启用仓库源", repo.Namespace, repo.Name)
+}
+
+// Markdown 导出 Markdown；maxCommits > 0 时每个应用最多列出 maxCommits 个提交（用于通知）
+func (c *Changelog) Markdown(maxCommits int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# 批次 %s 变更日志\n", c.BatchNumber)
+	for _, team := range c.Teams {
+		fmt.Fprintf(&sb, "\n## %s\n", team.TeamName)
+		for _, app := range team.Apps {
+			sb.WriteString("\n")
+			sb.WriteString(app.markdown(maxCommits))
+		}
+	}
+	return sb.String()
+}
+
+func (a *AppChangelog) markdown(maxCommits int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### %s", a.AppName)
+	if a.Repo != "" {
+		fmt.Fprintf(&sb, " (%s)", a.Repo)
+	}
+	if a.FromTag != "" || a.ToTag != "" {
+		fmt.Fprintf(&sb, " `%s` → `%s`", orDash(a.FromTag), orDash(a.ToTag))
+	}
+	sb.WriteString("\n")
+	if a.ReleaseNotes != nil && strings.TrimSpace(*a.ReleaseNotes) != "" {
+		fmt.Fprintf(&sb, "> %s\n", strings.ReplaceAll(strings.TrimSpace(*a.ReleaseNotes), "\n", "\n> "))
+	}
+	switch {
+	case a.Error != "":
+		fmt.Fprintf(&sb, "- （%s）\n", a.Error)
+		return sb.String()
+	case len(a.Commits) == 0:
+		sb.WriteString("- （无新增提交）\n")
+		return sb.String()
+	}
+
+	commits := a.Commits
+	if maxCommits > 0 && len(commits) > maxCommits {
+		commits = commits[len(commits)-maxCommits:]
+	}
+	// 最新提交在前
+	for i := len(commits) - 1; i >= 0; i-- {
+		commit := commits[i]
+		sha := commit.SHA
+		if len(sha) > 8 {
+			sha = sha[:8]
+		}
+		if commit.URL != "" {
+			fmt.Fprintf(&sb, "- [`%s`](%s) %s — %s\n", sha, commit.URL, commit.Title, commit.Author)
+		} else {
+			fmt.Fprintf(&sb, "- `%s` %s — %s\n", sha, commit.Title, commit.Author)
+		}
+	}
+	if len(commits) < len(a.Commits) || a.Truncated {
+		sb.WriteString("- …… 另有更早的提交未列出\n")
+	}
+	return sb.String()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,32 @@
+package changelog
+
+import (
+	"regexp"
+	"strings"
+)
+
+// issueKeyPattern Jira 风格的 issue key（PROJ-123），项目 key 为大写字母开头的大写字母/数字/下划线
+var issueKeyPattern = regexp.MustCompile(`\b([A-Z][A-Z0-9_]+)-([1-9][0-9]*)\b`)
+
+// IssueKeys 从应用本次发布的提交说明中解析 issue key（去重，按首次出现顺序）；projectKeys 非空时只保留这些项目的 key
+func (a *AppChangelog) IssueKeys(projectKeys []string) []string {
+	allowed := make(map[string]bool, len(projectKeys))
+	for _, k := range projectKeys {
+		allowed[strings.ToUpper(k)] = true
+	}
+
+	keys := []string{}
+	seen := map[string]bool{}
+	for _, commit := range a.Commits {
+		for _, m := range issueKeyPattern.FindAllStringSubmatch(commit.Message, -1) {
+			if len(allowed) > 0 && !allowed[m[1]] {
+				continue
+			}
+			if !seen[m[0]] {
+				seen[m[0]] = true
+				keys = append(keys, m[0])
+			}
+		}
+	}
+	return keys
+}
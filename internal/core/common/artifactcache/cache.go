@@ -0,0 +1,693 @@
+package artifactcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// artifactcache 服务端共享的远端制品缓存（values 层文件/压缩包及解压结果、git values 层、chart index、chart 包）
+//
+// 设计：
+//  1. 内容寻址：制品内容按 sha256 存放在 blobs/ 下，相同内容只存一份
+//  2. 索引：URL（+认证指纹）或自定义来源 key -> {digest, ETag, Last-Modified, fetched_at}，持久化到 index/，进程重启后仍可复用
+//  3. TTL 内直接命中；过期后携带 If-None-Match / If-Modified-Since 条件请求，304 仅刷新 fetched_at；
+//     自定义来源（Load）自行校验版本（如 git ls-remote 对比 commit）
+//  4. 同一 key 串行化拉取，大批次并发部署时同一制品只会下载一次
+//  5. 上游失败但本地有旧内容时返回旧内容（stale），降低对外部仓库可用性的依赖
+//  6. 大小限制：单个制品超过 MaxSize 拒绝缓存并返回错误；缓存总大小超过 MaxTotalSize 时按 fetched_at 淘汰最旧的条目
+//  7. 回源限制：主机访问策略（allow/deny/内网地址，见 hostPolicy，连接时按实际 IP 校验）、全局回源并发上限，排队时间计入单次回源超时
+
+const (
+	DefaultTTL     = 30 * time.Minute
+	DefaultTimeout = 60 * time.Second
+	DefaultMaxSize = 64 << 20
+
+	// maxErrorBody 上游错误响应最多读取的字节数
+	maxErrorBody = 4 << 10
+)
+
+// ErrTooLarge 制品超过单个制品大小上限
+var ErrTooLarge = errors.New("制品超过缓存大小上限")
+
+// Options 缓存配置
+type Options struct {
+	Dir          string        // 缓存根目录，为空时使用用户缓存目录
+	TTL          time.Duration // 命中有效期，过期后条件请求校验
+	Timeout      time.Duration // 单次上游请求超时
+	MaxSize      int64         // 单个制品大小上限（字节），<= 0 时使用 DefaultMaxSize
+	MaxTotalSize int64         // 缓存总大小上限（字节），超过时淘汰最旧的条目，<= 0 不限制
+	AllowHosts   []string      // 回源主机白名单（主机名 / *.域名 / IP / CIDR），为空不限制
+	DenyHosts    []string      // 回源主机黑名单，优先于白名单
+	BlockPrivate bool          // 拒绝解析到内网/回环/链路本地地址的主机（白名单中的主机除外）
+	Concurrency  int           // 同时回源的最大数量（所有来源共享），<= 0 不限制
+}
+
+// Request 拉取请求
+type Request struct {
+	URL       string
+	Auth      func(*http.Request) // 可选：注入认证头
+	Immutable bool                // 内容不可变（如带版本号的 chart 包），命中后不再校验
+}
+
+// Source 自定义来源（如 git values 层、压缩包解压结果），与 URL 请求共用存储、TTL、并发合并与 stale 兜底
+type Source struct {
+	Key       string // 缓存 key，需包含决定内容的全部输入（如仓库、ref、路径、凭据指纹）
+	Label     string // 来源描述（不含凭据），记录在 Entry.URL
+	URL       string // 回源地址，用于主机访问策略校验；本地来源（如压缩包解压）为空
+	Immutable bool   // 内容不可变（如 commit 固定的 git 内容），命中后不再校验
+	// Fetch 回源；cached 非空时可据 cached.ETag 判断内容未变化并返回 NotModified
+	Fetch func(ctx context.Context, cached *Entry) (*Upstream, error)
+}
+
+// Upstream 回源结果
+type Upstream struct {
+	NotModified  bool          // 内容未变化（cached 非空时有效），只刷新 fetched_at
+	Body         io.ReadCloser // 新内容，NotModified 时为空
+	Size         int64         // 已知的内容长度，超过 MaxSize 时直接拒绝；未知时为 -1
+	ETag         string        // 版本标识：HTTP ETag、git commit 等
+	LastModified string
+}
+
+// Entry 缓存条目（索引记录）
+type Entry struct {
+	URL          string    `json:"url"`
+	Digest       string    `json:"digest"` // sha256 hex
+	Size         int64     `json:"size"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+
+	// Path blob 本地路径（不落盘）
+	Path string `json:"-"`
+}
+
+// Stats 命中统计
+type Stats struct {
+	Requests    int64   `json:"requests"`
+	Hits        int64   `json:"hits"`        // TTL 内直接命中
+	Revalidated int64   `json:"revalidated"` // 过期后上游返回 304
+	Misses      int64   `json:"misses"`      // 从上游下载了新内容
+	Stale       int64   `json:"stale"`       // 上游失败，返回旧内容
+	Errors      int64   `json:"errors"`      // 上游失败且无可用缓存
+	HitRate     float64 `json:"hit_rate"`    // (hits+revalidated+stale)/requests
+	BytesServed int64   `json:"bytes_served"`
+	BytesLoaded int64   `json:"bytes_loaded"` // 从上游下载的字节数
+	TooLarge    int64   `json:"too_large"`    // 超过单个制品大小上限被拒绝
+	Evicted     int64   `json:"evicted"`      // 超过总大小上限被淘汰的条目
+	Denied      int64   `json:"denied"`       // 被主机访问策略拒绝
+	Throttled   int64   `json:"throttled"`    // 达到回源并发上限后排队等待的次数
+	Inflight    int64   `json:"inflight"`     // 正在回源的数量
+	Entries     int     `json:"entries"`
+	Dir         string  `json:"dir"`
+	TTL         string  `json:"ttl"`
+	MaxSize     int64   `json:"max_size"`
+	MaxTotal    int64   `json:"max_total_size"`
+	Concurrency int     `json:"max_concurrent"`
+}
+
+// Cache 内容寻址的远端制品缓存，并发安全
+type Cache struct {
+	dir      string
+	ttl      time.Duration
+	timeout  time.Duration
+	maxSize  int64
+	maxTotal int64
+	client   *http.Client
+	policy   *hostPolicy
+	sem      chan struct{} // 回源并发上限，nil 不限制
+
+	mu      sync.Mutex
+	entries map[string]*Entry // key -> entry（index 的内存视图）
+	locks   sync.Map          // key -> *sync.Mutex
+	pruneMu sync.Mutex
+
+	requests, hits, revalidated, misses, stale, errors atomic.Int64
+	bytesServed, bytesLoaded, tooLarge, evicted        atomic.Int64
+	denied, throttled, inflight                        atomic.Int64
+}
+
+// New 创建缓存实例
+func New(opts Options) (*Cache, error) {
+	dir := strings.TrimSpace(opts.Dir)
+	if dir == "" {
+		d, err := defaultDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = d
+	}
+	for _, sub := range []string{"blobs", "index"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("创建缓存目录失败: %w", err)
+		}
+	}
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	maxSize := opts.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	policy, err := newHostPolicy(opts.AllowHosts, opts.DenyHosts, opts.BlockPrivate)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cache{
+		dir:      dir,
+		ttl:      ttl,
+		timeout:  timeout,
+		maxSize:  maxSize,
+		maxTotal: max(opts.MaxTotalSize, 0),
+		policy:   policy,
+		entries:  make(map[string]*Entry),
+	}
+	if opts.Concurrency > 0 {
+		c.sem = make(chan struct{}, opts.Concurrency)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = c.policy.dialContext
+	c.client = &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		// 重定向目标同样校验访问策略，避免经公网地址跳转到内网
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return c.policy.check(req.Context(), req.URL.String())
+		},
+	}
+	return c, nil
+}
+
+var (
+	defaultMu    sync.Mutex
+	defaultCache *Cache
+)
+
+// Configure 设置全局缓存（由 core 引擎启动时按配置调用）
+func Configure(opts Options) error {
+	c, err := New(opts)
+	if err != nil {
+		return err
+	}
+	defaultMu.Lock()
+	defaultCache = c
+	defaultMu.Unlock()
+	return nil
+}
+
+// Default 返回全局缓存；未配置时按默认参数懒加载
+func Default() (*Cache, error) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultCache != nil {
+		return defaultCache, nil
+	}
+	c, err := New(Options{})
+	if err != nil {
+		return nil, err
+	}
+	defaultCache = c
+	return c, nil
+}
+
+// Fetch 使用全局缓存拉取 URL 内容
+func Fetch(ctx context.Context, req Request) ([]byte, error) {
+	c, err := Default()
+	if err != nil {
+		return nil, err
+	}
+	return c.Fetch(ctx, req)
+}
+
+// Get 使用全局缓存拉取 URL，返回缓存条目（Path 指向本地 blob）
+func Get(ctx context.Context, req Request) (*Entry, error) {
+	c, err := Default()
+	if err != nil {
+		return nil, err
+	}
+	return c.Get(ctx, req)
+}
+
+// Load 使用全局缓存加载自定义来源，返回内容
+func Load(ctx context.Context, src Source) ([]byte, error) {
+	c, err := Default()
+	if err != nil {
+		return nil, err
+	}
+	e, err := c.Load(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(e.Path)
+}
+
+// Fetch 拉取 URL 内容
+func (c *Cache) Fetch(ctx context.Context, req Request) ([]byte, error) {
+	e, err := c.Get(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(e.Path)
+}
+
+// Get 拉取 URL，返回缓存条目
+func (c *Cache) Get(ctx context.Context, req Request) (*Entry, error) {
+	u := strings.TrimSpace(req.URL)
+	if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+		return nil, fmt.Errorf("仅支持 http(s) URL: %s", u)
+	}
+	return c.load(ctx, cacheKey(u, req.Auth), Source{
+		Label:     u,
+		URL:       u,
+		Immutable: req.Immutable,
+		Fetch: func(ctx context.Context, cached *Entry) (*Upstream, error) {
+			return c.fetchHTTP(ctx, u, req.Auth, cached)
+		},
+	})
+}
+
+// Load 加载自定义来源，返回缓存条目
+func (c *Cache) Load(ctx context.Context, src Source) (*Entry, error) {
+	if strings.TrimSpace(src.Key) == "" || src.Fetch == nil {
+		return nil, fmt.Errorf("缓存来源缺少 key 或 Fetch")
+	}
+	return c.load(ctx, sha("source|"+src.Key), src)
+}
+
+func (c *Cache) load(ctx context.Context, key string, src Source) (*Entry, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	c.requests.Add(1)
+	if err := c.policy.checkHost(src.URL); err != nil {
+		c.denied.Add(1)
+		c.errors.Add(1)
+		return nil, err
+	}
+
+	unlock := c.lock(key)
+	defer unlock()
+
+	cached := c.lookup(key)
+	if cached != nil && (src.Immutable || time.Since(cached.FetchedAt) < c.ttl) {
+		c.hits.Add(1)
+		return c.served(cached), nil
+	}
+
+	e, revalidated, err := c.fetchUpstream(ctx, src, cached)
+	if err != nil {
+		// 超过大小上限、被访问策略拒绝视为配置问题，不用旧内容兜底
+		if errors.Is(err, ErrTooLarge) {
+			c.tooLarge.Add(1)
+		} else if errors.Is(err, ErrHostDenied) {
+			c.denied.Add(1)
+		} else if cached != nil {
+			c.stale.Add(1)
+			return c.served(cached), nil
+		}
+		c.errors.Add(1)
+		return nil, err
+	}
+	if revalidated {
+		c.revalidated.Add(1)
+	} else {
+		c.misses.Add(1)
+		c.bytesLoaded.Add(e.Size)
+	}
+	c.store(key, e)
+	if !revalidated {
+		c.prune(key)
+	}
+	return c.served(e), nil
+}
+
+// Stats 返回命中统计
+func (c *Cache) Stats() Stats {
+	s := Stats{
+		Requests:    c.requests.Load(),
+		Hits:        c.hits.Load(),
+		Revalidated: c.revalidated.Load(),
+		Misses:      c.misses.Load(),
+		Stale:       c.stale.Load(),
+		Errors:      c.errors.Load(),
+		BytesServed: c.bytesServed.Load(),
+		BytesLoaded: c.bytesLoaded.Load(),
+		TooLarge:    c.tooLarge.Load(),
+		Evicted:     c.evicted.Load(),
+		Denied:      c.denied.Load(),
+		Throttled:   c.throttled.Load(),
+		Inflight:    c.inflight.Load(),
+		Dir:         c.dir,
+		TTL:         c.ttl.String(),
+		MaxSize:     c.maxSize,
+		MaxTotal:    c.maxTotal,
+		Concurrency: cap(c.sem),
+	}
+	if s.Requests > 0 {
+		s.HitRate = float64(s.Hits+s.Revalidated+s.Stale) / float64(s.Requests)
+	}
+	c.mu.Lock()
+	s.Entries = len(c.entries)
+	c.mu.Unlock()
+	return s
+}
+
+// fetchUpstream 回源（超时为 Options.Timeout，含排队时间）；cached 非空且来源返回 NotModified 时 revalidated=true
+func (c *Cache) fetchUpstream(ctx context.Context, src Source, cached *Entry) (*Entry, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	if src.URL != "" {
+		if err := c.policy.check(ctx, src.URL); err != nil {
+			return nil, false, err
+		}
+	}
+	release, err := c.acquire(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: 等待回源并发名额超时: %w", src.Label, err)
+	}
+	defer release()
+
+	up, err := src.Fetch(ctx, cached)
+	if err != nil {
+		return nil, false, err
+	}
+	if up.Body != nil {
+		defer up.Body.Close()
+	}
+	if up.NotModified && cached != nil {
+		e := *cached
+		e.FetchedAt = time.Now()
+		if up.ETag != "" {
+			e.ETag = up.ETag
+		}
+		if up.LastModified != "" {
+			e.LastModified = up.LastModified
+		}
+		return &e, true, nil
+	}
+	if up.Body == nil {
+		return nil, false, fmt.Errorf("%s: 来源未返回内容", src.Label)
+	}
+	if up.Size > c.maxSize {
+		return nil, false, fmt.Errorf("%s: %w（%d > %d 字节）", src.Label, ErrTooLarge, up.Size, c.maxSize)
+	}
+
+	digest, size, err := c.writeBlob(up.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", src.Label, err)
+	}
+	return &Entry{
+		URL:          src.Label,
+		Digest:       digest,
+		Size:         size,
+		ETag:         up.ETag,
+		LastModified: up.LastModified,
+		FetchedAt:    time.Now(),
+		Path:         c.blobPath(digest),
+	}, false, nil
+}
+
+// fetchHTTP 请求 URL；cached 非空时发送条件请求
+func (c *Cache) fetchHTTP(ctx context.Context, u string, auth func(*http.Request), cached *Entry) (*Upstream, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if auth != nil {
+		auth(httpReq)
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			httpReq.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			httpReq.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	up := &Upstream{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		resp.Body.Close()
+		up.NotModified = true
+		return up, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	up.Body = resp.Body
+	up.Size = resp.ContentLength
+	return up, nil
+}
+
+// writeBlob 边下载边计算 sha256，落盘到 blobs/<digest[:2]>/<digest>；超过 maxSize 时返回 ErrTooLarge
+func (c *Cache) writeBlob(r io.Reader) (string, int64, error) {
+	tmp, err := os.CreateTemp(filepath.Join(c.dir, "blobs"), "download-*.tmp")
+	if err != nil {
+		return "", 0, err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	h := sha256.New()
+	size, copyErr := io.Copy(io.MultiWriter(tmp, h), io.LimitReader(r, c.maxSize+1))
+	closeErr := tmp.Close()
+	if copyErr != nil {
+		return "", 0, copyErr
+	}
+	if closeErr != nil {
+		return "", 0, closeErr
+	}
+	if size > c.maxSize {
+		return "", 0, fmt.Errorf("%w（超过 %d 字节）", ErrTooLarge, c.maxSize)
+	}
+
+	digest := hex.EncodeToString(h.Sum(nil))
+	dest := c.blobPath(digest)
+	if _, err := os.Stat(dest); err == nil {
+		// 相同内容已存在，直接复用
+		return digest, size, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return "", 0, err
+	}
+	if err := os.Rename(tmpName, dest); err != nil {
+		return "", 0, err
+	}
+	return digest, size, nil
+}
+
+// lookup 查询 key 对应的条目（内存 -> index 文件），blob 丢失视为未命中
+func (c *Cache) lookup(key string) *Entry {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	if !ok {
+		b, err := os.ReadFile(c.indexPath(key))
+		if err != nil {
+			return nil
+		}
+		var loaded Entry
+		if err := json.Unmarshal(b, &loaded); err != nil || loaded.Digest == "" {
+			return nil
+		}
+		loaded.Path = c.blobPath(loaded.Digest)
+		e = &loaded
+		c.mu.Lock()
+		c.entries[key] = e
+		c.mu.Unlock()
+	}
+	if _, err := os.Stat(e.Path); err != nil {
+		return nil
+	}
+	return e
+}
+
+// store 更新内存索引并持久化；index 写失败不影响本次返回
+func (c *Cache) store(key string, e *Entry) {
+	c.mu.Lock()
+	c.entries[key] = e
+	c.mu.Unlock()
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	dest := c.indexPath(key)
+	tmp := dest + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		_ = os.Remove(tmp)
+	}
+}
+
+// prune 缓存总大小超过 maxTotal 时按 fetched_at 从旧到新淘汰索引条目（keep 为本次写入的条目，不淘汰），
+// blob 不再被任何条目引用时删除
+func (c *Cache) prune(keep string) {
+	if c.maxTotal <= 0 {
+		return
+	}
+	c.pruneMu.Lock()
+	defer c.pruneMu.Unlock()
+
+	type indexed struct {
+		key   string
+		entry Entry
+	}
+	files, err := os.ReadDir(filepath.Join(c.dir, "index"))
+	if err != nil {
+		return
+	}
+	var all []indexed
+	refs := make(map[string]int)
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(c.dir, "index", name))
+		if err != nil {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(b, &e); err != nil || e.Digest == "" {
+			continue
+		}
+		all = append(all, indexed{key: strings.TrimSuffix(name, ".json"), entry: e})
+		refs[e.Digest]++
+	}
+
+	sizes := make(map[string]int64, len(refs))
+	var total int64
+	for digest := range refs {
+		if fi, err := os.Stat(c.blobPath(digest)); err == nil {
+			sizes[digest] = fi.Size()
+			total += fi.Size()
+		}
+	}
+	if total <= c.maxTotal {
+		return
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].entry.FetchedAt.Before(all[j].entry.FetchedAt) })
+	for _, it := range all {
+		if total <= c.maxTotal {
+			break
+		}
+		if it.key == keep {
+			continue
+		}
+		_ = os.Remove(c.indexPath(it.key))
+		c.mu.Lock()
+		delete(c.entries, it.key)
+		c.mu.Unlock()
+		c.evicted.Add(1)
+
+		digest := it.entry.Digest
+		if refs[digest]--; refs[digest] == 0 {
+			_ = os.Remove(c.blobPath(digest))
+			total -= sizes[digest]
+		}
+	}
+}
+
+// acquire 占用一个回源名额，达到并发上限时等待（受 ctx 超时限制）
+func (c *Cache) acquire(ctx context.Context) (func(), error) {
+	if c.sem != nil {
+		select {
+		case c.sem <- struct{}{}:
+		default:
+			c.throttled.Add(1)
+			select {
+			case c.sem <- struct{}{}:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+	c.inflight.Add(1)
+	return func() {
+		c.inflight.Add(-1)
+		if c.sem != nil {
+			<-c.sem
+		}
+	}, nil
+}
+
+func (c *Cache) served(e *Entry) *Entry {
+	c.bytesServed.Add(e.Size)
+	cp := *e
+	return &cp
+}
+
+func (c *Cache) lock(key string) func() {
+	v, _ := c.locks.LoadOrStore(key, &sync.Mutex{})
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+func (c *Cache) blobPath(digest string) string {
+	return filepath.Join(c.dir, "blobs", digest[:2], digest)
+}
+
+func (c *Cache) indexPath(key string) string {
+	return filepath.Join(c.dir, "index", key+".json")
+}
+
+// cacheKey URL + 认证指纹：不同凭据访问同一 URL 可能得到不同内容，索引分开；blob 仍按内容共享
+func cacheKey(u string, auth func(*http.Request)) string {
+	fingerprint := ""
+	if auth != nil {
+		probe, err := http.NewRequest(http.MethodGet, u, nil)
+		if err == nil {
+			auth(probe)
+			fingerprint = probe.Header.Get("Authorization")
+		}
+	}
+	return sha(u + "|" + fingerprint)
+}
+
+func defaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil || strings.TrimSpace(dir) == "" {
+		dir = os.TempDir()
+	}
+	if strings.TrimSpace(dir) == "" {
+		return "", fmt.Errorf("无法确定缓存目录")
+	}
+	return filepath.Join(dir, "devops-cd", "artifact-cache"), nil
+}
+
+func sha(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,126 @@
+package artifactcache
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// 主机访问策略在建立连接时强制执行:
+//
+//   - check 在回源前解析并校验，只用于尽早给出明确的错误信息
+//   - 真正的连接由 dialContext 建立：解析一次主机名、校验全部 IP 后直接连接校验通过的 IP，
+//     不再由 HTTP transport / go-git 自行解析，避免校验与连接之间 DNS 结果变化（DNS rebinding）
+//   - HTTP 回源与 go-git http(s) 使用 NewTransport；go-git ssh 通过 PolicyProxyURL 这个伪代理接入同一拨号逻辑
+//   - 配置了 HTTP(S)_PROXY 时连接的是代理地址（按代理主机校验），目标主机只在 check 中校验
+
+// PolicyProxyURL go-git ProxyOptions.URL 使用的伪代理地址：ssh 连接经全局缓存的主机访问策略拨号
+const PolicyProxyURL = policyProxyScheme + "://artifactcache"
+
+const policyProxyScheme = "devops-cd-hostpolicy"
+
+var baseDialer = &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+
+func init() {
+	proxy.RegisterDialerType(policyProxyScheme, func(*url.URL, proxy.Dialer) (proxy.Dialer, error) {
+		return policyDialer{}, nil
+	})
+}
+
+// policyDialer 实现 proxy.Dialer / proxy.ContextDialer
+type policyDialer struct{}
+
+func (policyDialer) Dial(network, addr string) (net.Conn, error) {
+	return DialContext(context.Background(), network, addr)
+}
+
+func (policyDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return DialContext(ctx, network, addr)
+}
+
+// DialContext 按全局缓存的主机访问策略建立连接
+func DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	c, err := Default()
+	if err != nil {
+		return nil, err
+	}
+	return c.policy.dialContext(ctx, network, addr)
+}
+
+// Enforced 全局缓存是否配置了主机访问策略（go-git 的 git:// 协议无法接入拨号校验，启用策略时应拒绝）
+func Enforced() bool {
+	c, err := Default()
+	return err != nil || c.policy.enabled()
+}
+
+// NewTransport 按全局缓存主机访问策略拨号的 HTTP transport（供 go-git 等不经缓存的 HTTP 客户端使用）
+func NewTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DialContext = DialContext
+	return t
+}
+
+// dialContext 解析 addr 中的主机名并校验每个 IP，之后直接连接校验通过的 IP；
+// 按主机名列入 allow_hosts 的主机不校验 IP，与 check 一致
+func (p *hostPolicy) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if !p.enabled() {
+		return baseDialer.DialContext(ctx, network, addr)
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if err := p.checkHost("tcp://" + addr); err != nil {
+		return nil, err
+	}
+	for _, r := range p.allow {
+		if r.matchHost(host) {
+			return baseDialer.DialContext(ctx, network, addr)
+		}
+	}
+
+	ips, err := p.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range ips {
+		if err := p.checkIP(host, ip); err != nil {
+			return nil, err
+		}
+	}
+	var lastErr error
+	for _, ip := range ips {
+		conn, err := baseDialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("连接 %s 失败: %w", addr, lastErr)
+}
+
+// resolve 解析主机名（IP 直接返回）
+func (p *hostPolicy) resolve(ctx context.Context, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	addrs, err := p.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("解析主机 %s 失败: %w", host, err)
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("解析主机 %s 失败: 无可用地址", host)
+	}
+	ips := make([]net.IP, 0, len(addrs))
+	for _, a := range addrs {
+		ips = append(ips, a.IP)
+	}
+	return ips, nil
+}
@@ -0,0 +1,186 @@
+package artifactcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ErrHostDenied 回源地址被主机访问策略拒绝
+var ErrHostDenied = errors.New("回源地址被访问策略拒绝")
+
+// hostPolicy 回源主机访问控制（SSRF 防护）
+//
+//   - deny_hosts 优先：命中即拒绝
+//   - allow_hosts 非空时只允许命中的主机；显式允许的主机不受 block_private 限制（内网 Git/制品库需加入 allow_hosts）
+//   - block_private 拒绝解析到回环、私有、链路本地（含云厂商元数据地址 169.254.169.254）、CGNAT 等地址的主机
+//   - 规则可以是主机名（精确匹配）、*.example.com / .example.com（匹配子域名）、IP 或 CIDR
+type hostPolicy struct {
+	allow        []hostRule
+	deny         []hostRule
+	blockPrivate bool
+	resolver     *net.Resolver
+}
+
+type hostRule struct {
+	host   string     // 精确主机名
+	suffix string     // 子域名后缀（.example.com）
+	cidr   *net.IPNet // IP / CIDR
+}
+
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func newHostPolicy(allow, deny []string, blockPrivate bool) (*hostPolicy, error) {
+	p := &hostPolicy{blockPrivate: blockPrivate, resolver: net.DefaultResolver}
+	var err error
+	if p.allow, err = parseHostRules(allow); err != nil {
+		return nil, fmt.Errorf("allow_hosts: %w", err)
+	}
+	if p.deny, err = parseHostRules(deny); err != nil {
+		return nil, fmt.Errorf("deny_hosts: %w", err)
+	}
+	return p, nil
+}
+
+func parseHostRules(list []string) ([]hostRule, error) {
+	rules := make([]hostRule, 0, len(list))
+	for _, raw := range list {
+		s := strings.ToLower(strings.TrimSpace(raw))
+		switch {
+		case s == "":
+			continue
+		case strings.Contains(s, "/"):
+			_, cidr, err := net.ParseCIDR(s)
+			if err != nil {
+				return nil, fmt.Errorf("无效的 CIDR: %s", raw)
+			}
+			rules = append(rules, hostRule{cidr: cidr})
+		case net.ParseIP(s) != nil:
+			ip := net.ParseIP(s)
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			rules = append(rules, hostRule{cidr: &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}})
+		case strings.HasPrefix(s, "*."):
+			rules = append(rules, hostRule{suffix: s[1:]})
+		case strings.HasPrefix(s, "."):
+			rules = append(rules, hostRule{suffix: s})
+		default:
+			rules = append(rules, hostRule{host: strings.TrimSuffix(s, ".")})
+		}
+	}
+	return rules, nil
+}
+
+func (r hostRule) matchHost(host string) bool {
+	switch {
+	case r.host != "":
+		return host == r.host
+	case r.suffix != "":
+		return strings.HasSuffix(host, r.suffix)
+	}
+	return false
+}
+
+func (r hostRule) matchIP(ip net.IP) bool {
+	return r.cidr != nil && r.cidr.Contains(ip)
+}
+
+func (p *hostPolicy) enabled() bool {
+	return p != nil && (len(p.allow) > 0 || len(p.deny) > 0 || p.blockPrivate)
+}
+
+// checkHost 只按主机名匹配规则（不解析 DNS），用于每次请求（含缓存命中）前的快速校验
+func (p *hostPolicy) checkHost(rawURL string) error {
+	if !p.enabled() {
+		return nil
+	}
+	host := hostOf(rawURL)
+	if host == "" {
+		return fmt.Errorf("%w: 无法识别主机 %s", ErrHostDenied, rawURL)
+	}
+	ip := net.ParseIP(host)
+	for _, r := range p.deny {
+		if r.matchHost(host) || (ip != nil && r.matchIP(ip)) {
+			return fmt.Errorf("%w: %s 在 deny_hosts 中", ErrHostDenied, host)
+		}
+	}
+	return nil
+}
+
+// check 回源前完整校验：主机名规则 + 解析出的每个 IP（deny CIDR、allow 规则、私有地址限制）；
+// 只用于尽早返回明确的错误，连接时由 dialContext 再次按实际连接的 IP 校验（见 dial.go）
+func (p *hostPolicy) check(ctx context.Context, rawURL string) error {
+	if err := p.checkHost(rawURL); err != nil || !p.enabled() {
+		return err
+	}
+	host := hostOf(rawURL)
+	for _, r := range p.allow {
+		if r.matchHost(host) {
+			return nil
+		}
+	}
+
+	ips, err := p.resolve(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, ip := range ips {
+		if err := p.checkIP(host, ip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *hostPolicy) checkIP(host string, ip net.IP) error {
+	for _, r := range p.deny {
+		if r.matchIP(ip) {
+			return fmt.Errorf("%w: %s（%s）在 deny_hosts 中", ErrHostDenied, host, ip)
+		}
+	}
+	for _, r := range p.allow {
+		if r.matchIP(ip) {
+			return nil
+		}
+	}
+	if len(p.allow) > 0 {
+		return fmt.Errorf("%w: %s 不在 allow_hosts 中", ErrHostDenied, host)
+	}
+	if p.blockPrivate && isPrivateIP(ip) {
+		return fmt.Errorf("%w: %s 解析到内网地址 %s", ErrHostDenied, host, ip)
+	}
+	return nil
+}
+
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		cgnat.Contains(ip)
+}
+
+// hostOf 提取主机名（小写）：支持 scheme://host/... 与 git scp 风格 user@host:path
+func hostOf(raw string) string {
+	raw = strings.TrimSpace(raw)
+	var host string
+	if strings.Contains(raw, "://") {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return ""
+		}
+		host = u.Hostname()
+	} else {
+		s := raw
+		if i := strings.Index(s, "@"); i >= 0 {
+			s = s[i+1:]
+		}
+		if i := strings.Index(s, ":"); i > 0 {
+			host = s[:i]
+		}
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
@@ -0,0 +1,121 @@
+package artifactcache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestHostPolicyCheck(t *testing.T) {
+	type policy struct {
+		allow, deny  []string
+		blockPrivate bool
+	}
+	cases := []struct {
+		name       string
+		policy     policy
+		url        string
+		wantDenied bool
+	}{
+		{name: "未配置策略", policy: policy{}, url: "http://127.0.0.1/x"},
+		{name: "回环地址", policy: policy{blockPrivate: true}, url: "http://127.0.0.1:8080/x", wantDenied: true},
+		{name: "私有地址", policy: policy{blockPrivate: true}, url: "https://10.1.2.3/charts", wantDenied: true},
+		{name: "云厂商元数据地址", policy: policy{blockPrivate: true}, url: "http://169.254.169.254/latest/meta-data", wantDenied: true},
+		{name: "CGNAT", policy: policy{blockPrivate: true}, url: "http://100.64.0.1/", wantDenied: true},
+		{name: "未指定地址", policy: policy{blockPrivate: true}, url: "http://0.0.0.0/", wantDenied: true},
+		{name: "IPv6 回环", policy: policy{blockPrivate: true}, url: "http://[::1]/", wantDenied: true},
+		{name: "IPv6 链路本地", policy: policy{blockPrivate: true}, url: "http://[fe80::1]/", wantDenied: true},
+		{name: "IPv4 映射的回环地址", policy: policy{blockPrivate: true}, url: "http://[::ffff:127.0.0.1]/", wantDenied: true},
+		{name: "主机名解析到回环地址", policy: policy{blockPrivate: true}, url: "http://localhost/", wantDenied: true},
+		{name: "scp 风格 Git 地址", policy: policy{blockPrivate: true}, url: "git@127.0.0.1:org/repo.git", wantDenied: true},
+		{name: "公网地址", policy: policy{blockPrivate: true}, url: "https://8.8.8.8/index.yaml"},
+		{name: "无法识别主机", policy: policy{blockPrivate: true}, url: "not a url", wantDenied: true},
+
+		{name: "按主机名允许内网主机", policy: policy{allow: []string{"git.corp.internal"}, blockPrivate: true}, url: "https://git.corp.internal/org/repo.git"},
+		{name: "按 CIDR 允许内网地址", policy: policy{allow: []string{"10.0.0.0/8"}, blockPrivate: true}, url: "https://10.1.2.3/charts"},
+		{name: "公网地址不在 allow_hosts 中", policy: policy{allow: []string{"10.0.0.0/8"}}, url: "https://8.8.8.8/", wantDenied: true},
+		{name: "内网地址不在 allow_hosts 中", policy: policy{allow: []string{"192.168.0.0/16"}}, url: "https://10.1.2.3/", wantDenied: true},
+
+		{name: "deny 子域名", policy: policy{deny: []string{"*.evil.example"}}, url: "https://a.b.evil.example/x", wantDenied: true},
+		{name: "deny 子域名不含自身", policy: policy{deny: []string{".evil.example"}, allow: []string{"evil.example"}}, url: "https://evil.example/x"},
+		{name: "deny 优先于 allow", policy: policy{allow: []string{"charts.example"}, deny: []string{"charts.example"}}, url: "https://CHARTS.example./x", wantDenied: true},
+		{name: "deny CIDR", policy: policy{deny: []string{"8.8.8.0/24"}}, url: "https://8.8.8.8/", wantDenied: true},
+		{name: "deny IP", policy: policy{deny: []string{"::1"}}, url: "http://[::1]/", wantDenied: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p, err := newHostPolicy(c.policy.allow, c.policy.deny, c.policy.blockPrivate)
+			if err != nil {
+				t.Fatalf("newHostPolicy: %v", err)
+			}
+			err = p.check(context.Background(), c.url)
+			if c.wantDenied != errors.Is(err, ErrHostDenied) {
+				t.Errorf("check(%q) = %v, want denied=%v", c.url, err, c.wantDenied)
+			}
+			if !c.wantDenied && err != nil {
+				t.Errorf("check(%q): %v", c.url, err)
+			}
+		})
+	}
+}
+
+func TestNewHostPolicyInvalidRule(t *testing.T) {
+	if _, err := newHostPolicy([]string{"10.0.0.0/33"}, nil, false); err == nil {
+		t.Error("无效的 allow CIDR 应返回错误")
+	}
+	if _, err := newHostPolicy(nil, []string{"a/b"}, false); err == nil {
+		t.Error("无效的 deny CIDR 应返回错误")
+	}
+}
+
+// TestHostPolicyDialContext 连接时按实际连接的 IP 校验，与回源前的 check 一致
+func TestHostPolicyDialContext(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+
+	cases := []struct {
+		name       string
+		allow      []string
+		deny       []string
+		block      bool
+		addr       string
+		wantDenied bool
+	}{
+		{name: "未配置策略", addr: "127.0.0.1:" + port},
+		{name: "拒绝回环地址", block: true, addr: "127.0.0.1:" + port, wantDenied: true},
+		{name: "拒绝解析到回环地址的主机名", block: true, addr: "localhost:" + port, wantDenied: true},
+		{name: "按 IP 允许", allow: []string{"127.0.0.1"}, block: true, addr: "127.0.0.1:" + port},
+		{name: "按主机名允许", allow: []string{"localhost"}, block: true, addr: "localhost:" + port},
+		{name: "deny 主机名", deny: []string{"localhost"}, addr: "localhost:" + port, wantDenied: true},
+		{name: "deny CIDR", deny: []string{"127.0.0.0/8"}, addr: "localhost:" + port, wantDenied: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p, err := newHostPolicy(c.allow, c.deny, c.block)
+			if err != nil {
+				t.Fatalf("newHostPolicy: %v", err)
+			}
+			conn, err := p.dialContext(context.Background(), "tcp", c.addr)
+			if conn != nil {
+				conn.Close()
+			}
+			if c.wantDenied != errors.Is(err, ErrHostDenied) || (!c.wantDenied && err != nil) {
+				t.Errorf("dialContext(%s) = %v, want denied=%v", c.addr, err, c.wantDenied)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package metacache
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// metacache 进程内元数据缓存（应用、项目环境配置、集群），供核心引擎在每次扫描中重复读取的热点路径使用
+//
+// 设计：
+//  1. 按类型（kind）分区，每条记录在 TTL 内直接命中，过期后回源
+//  2. 写入 applications / projects / repositories / project_env_configs / clusters 时通过 gorm 回调整类失效（见 RegisterInvalidation）
+//  3. 每个类型维护代数（generation），回源期间发生失效时不写回，避免并发写入后缓存旧数据
+//  4. 事务内的写入在提交前即失效，提交前并发回源的旧值最长保留 TTL
+//  5. 多副本时其他副本的写入无法通知到本进程，最长在 TTL 后生效
+
+const DefaultTTL = 30 * time.Second
+
+// Options 缓存配置
+type Options struct {
+	Enabled bool
+	TTL     time.Duration // 命中有效期，默认 30s
+}
+
+// KindStats 单个类型的命中统计
+type KindStats struct {
+	Kind          string  `json:"kind"`
+	Hits          int64   `json:"hits"`
+	Misses        int64   `json:"misses"`
+	Invalidations int64   `json:"invalidations"` // 写入触发的失效次数
+	Entries       int     `json:"entries"`
+	HitRate       float64 `json:"hit_rate"` // hits/(hits+misses)
+}
+
+// Stats 命中统计（进程启动或上次清空以来）
+type Stats struct {
+	Enabled     bool         `json:"enabled"`
+	TTL         string       `json:"ttl"`
+	Hits        int64        `json:"hits"`
+	Misses      int64        `json:"misses"`
+	HitRate     float64      `json:"hit_rate"`
+	Entries     int          `json:"entries"`
+	Kinds       []*KindStats `json:"kinds"`
+	LastFlushAt *time.Time   `json:"last_flush_at,omitempty"`
+}
+
+type entry struct {
+	value     any
+	expiresAt time.Time
+}
+
+type kindCache struct {
+	mu         sync.Mutex
+	entries    map[string]*entry
+	generation uint64
+
+	hits, misses, invalidations atomic.Int64
+}
+
+// Cache 按类型分区的 TTL 缓存，并发安全
+type Cache struct {
+	enabled bool
+	ttl     time.Duration
+
+	mu          sync.Mutex
+	kinds       map[string]*kindCache
+	lastFlushAt *time.Time
+}
+
+// New 创建缓存实例；Enabled=false 时 Get 直接回源
+func New(opts Options) *Cache {
+	ttl := opts.TTL
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Cache{enabled: opts.Enabled, ttl: ttl, kinds: make(map[string]*kindCache)}
+}
+
+var (
+	defaultMu    sync.Mutex
+	defaultCache *Cache
+)
+
+// Configure 设置全局缓存（由 core 引擎启动时按配置调用）
+func Configure(opts Options) {
+	c := New(opts)
+	defaultMu.Lock()
+	defaultCache = c
+	defaultMu.Unlock()
+}
+
+// Default 返回全局缓存；未配置时返回未启用的缓存（直接回源）
+func Default() *Cache {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultCache == nil {
+		defaultCache = New(Options{})
+	}
+	return defaultCache
+}
+
+func (c *Cache) kind(kind string) *kindCache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	k, ok := c.kinds[kind]
+	if !ok {
+		k = &kindCache{entries: make(map[string]*entry)}
+		c.kinds[kind] = k
+	}
+	return k
+}
+
+// Get 读取 kind/key，未命中或已过期时调用 load 回源并写回
+func (c *Cache) Get(kind, key string, load func() (any, error)) (any, error) {
+	if !c.enabled {
+		return load()
+	}
+	k := c.kind(kind)
+
+	k.mu.Lock()
+	if e, ok := k.entries[key]; ok && time.Now().Before(e.expiresAt) {
+		k.mu.Unlock()
+		k.hits.Add(1)
+		return e.value, nil
+	}
+	generation := k.generation
+	k.mu.Unlock()
+
+	k.misses.Add(1)
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	k.mu.Lock()
+	if k.generation == generation {
+		k.entries[key] = &entry{value: value, expiresAt: time.Now().Add(c.ttl)}
+	}
+	k.mu.Unlock()
+	return value, nil
+}
+
+// Invalidate 使 kind 下的全部记录失效
+func (c *Cache) Invalidate(kinds ...string) {
+	if !c.enabled {
+		return
+	}
+	for _, kind := range kinds {
+		k := c.kind(kind)
+		k.mu.Lock()
+		k.entries = make(map[string]*entry)
+		k.generation++
+		k.mu.Unlock()
+		k.invalidations.Add(1)
+	}
+}
+
+// Flush 清空全部记录并重置统计
+func (c *Cache) Flush() {
+	now := time.Now()
+	c.mu.Lock()
+	kinds := make([]*kindCache, 0, len(c.kinds))
+	for _, k := range c.kinds {
+		kinds = append(kinds, k)
+	}
+	c.lastFlushAt = &now
+	c.mu.Unlock()
+
+	for _, k := range kinds {
+		k.mu.Lock()
+		k.entries = make(map[string]*entry)
+		k.generation++
+		k.mu.Unlock()
+		k.hits.Store(0)
+		k.misses.Store(0)
+		k.invalidations.Store(0)
+	}
+}
+
+// Stats 命中统计
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	names := make([]string, 0, len(c.kinds))
+	for name := range c.kinds {
+		names = append(names, name)
+	}
+	stats := Stats{Enabled: c.enabled, TTL: c.ttl.String(), Kinds: []*KindStats{}, LastFlushAt: c.lastFlushAt}
+	c.mu.Unlock()
+	sort.Strings(names)
+
+	now := time.Now()
+	for _, name := range names {
+		k := c.kind(name)
+		ks := &KindStats{
+			Kind:          name,
+			Hits:          k.hits.Load(),
+			Misses:        k.misses.Load(),
+			Invalidations: k.invalidations.Load(),
+		}
+		k.mu.Lock()
+		for _, e := range k.entries {
+			if now.Before(e.expiresAt) {
+				ks.Entries++
+			}
+		}
+		k.mu.Unlock()
+		ks.HitRate = hitRate(ks.Hits, ks.Misses)
+
+		stats.Hits += ks.Hits
+		stats.Misses += ks.Misses
+		stats.Entries += ks.Entries
+		stats.Kinds = append(stats.Kinds, ks)
+	}
+	stats.HitRate = hitRate(stats.Hits, stats.Misses)
+	return stats
+}
+
+func hitRate(hits, misses int64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
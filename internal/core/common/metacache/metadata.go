@@ -0,0 +1,128 @@
+package metacache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"devops-cd/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// 缓存的元数据类型
+const (
+	KindApplication      = "application"        // 应用（含 Project、Repository）
+	KindProjectEnvConfig = "project_env_config" // 项目环境配置（含不存在的记录）
+	KindCluster          = "cluster"            // 集群
+)
+
+// invalidateTables 表 -> 写入时需要失效的类型
+var invalidateTables = map[string][]string{
+	model.ApplicationTableName:      {KindApplication},
+	model.ProjectTableName:          {KindApplication},
+	model.RepositoryTableName:       {KindApplication},
+	model.ProjectEnvConfigTableName: {KindProjectEnvConfig},
+	model.ClusterTableName:          {KindCluster},
+}
+
+// Application 按 ID 读取应用（预加载 Project、Repository），返回副本
+func Application(ctx context.Context, db *gorm.DB, id int64) (*model.Application, error) {
+	v, err := Default().Get(KindApplication, fmt.Sprint(id), func() (any, error) {
+		var app model.Application
+		if err := db.WithContext(ctx).Preload("Project").Preload("Repository").First(&app, id).Error; err != nil {
+			return nil, err
+		}
+		return &app, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	app := *v.(*model.Application)
+	return &app, nil
+}
+
+// ProjectEnvConfig 读取项目在 env 下的环境配置，返回副本；不存在时返回 gorm.ErrRecordNotFound
+func ProjectEnvConfig(ctx context.Context, db *gorm.DB, projectID int64, env string) (*model.ProjectEnvConfig, error) {
+	v, err := Default().Get(KindProjectEnvConfig, fmt.Sprintf("%d/%s", projectID, env), func() (any, error) {
+		var cfg model.ProjectEnvConfig
+		err := db.WithContext(ctx).Where("project_id = ? AND env = ?", projectID, env).First(&cfg).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return (*model.ProjectEnvConfig)(nil), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &cfg, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	cached := v.(*model.ProjectEnvConfig)
+	if cached == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cfg := *cached
+	return &cfg, nil
+}
+
+// Cluster 按名称读取集群，返回副本
+func Cluster(ctx context.Context, db *gorm.DB, name string) (*model.Cluster, error) {
+	v, err := Default().Get(KindCluster, name, func() (any, error) {
+		var cluster model.Cluster
+		if err := db.WithContext(ctx).Where("name = ?", name).First(&cluster).Error; err != nil {
+			return nil, err
+		}
+		return &cluster, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	cluster := *v.(*model.Cluster)
+	return &cluster, nil
+}
+
+const invalidateCallback = "metacache:invalidate"
+
+// writeSQL Exec 执行的写语句及目标表
+var writeSQL = regexp.MustCompile("(?is)^\\s*(?:insert\\s+(?:ignore\\s+)?into|replace\\s+into|update|delete\\s+from)\\s+`?(\\w+)`?")
+
+// RegisterInvalidation 在 db 上注册写入回调：相关表发生 create/update/delete（含 Exec 写语句）后使对应类型失效
+//
+// 服务层与核心引擎共用同一个 *gorm.DB，重复注册时跳过
+func RegisterInvalidation(db *gorm.DB) error {
+	if db.Callback().Create().Get(invalidateCallback) != nil {
+		return nil
+	}
+	invalidate := func(tx *gorm.DB) {
+		if tx.Error != nil {
+			return
+		}
+		invalidateTable(tx.Statement.Table)
+	}
+	if err := db.Callback().Create().After("gorm:create").Register(invalidateCallback, invalidate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register(invalidateCallback, invalidate); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().After("gorm:delete").Register(invalidateCallback, invalidate); err != nil {
+		return err
+	}
+	return db.Callback().Raw().After("gorm:raw").Register(invalidateCallback, func(tx *gorm.DB) {
+		if tx.Error != nil {
+			return
+		}
+		if m := writeSQL.FindStringSubmatch(tx.Statement.SQL.String()); m != nil {
+			invalidateTable(m[1])
+		}
+	})
+}
+
+func invalidateTable(table string) {
+	if kinds, ok := invalidateTables[strings.ToLower(strings.Trim(table, "`"))]; ok {
+		Default().Invalidate(kinds...)
+	}
+}
@@ -0,0 +1,280 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// registry 镜像仓库 Docker Registry v2 API 客户端（Harbor、Docker Hub、自建 registry 均兼容）
+//
+// 仅用于校验镜像 tag / digest 是否存在：HEAD /v2/<repository>/manifests/<reference>，
+// 401 时按 WWW-Authenticate 质询换取 Bearer token（或直接使用 Basic 认证）后重试
+
+const (
+	DefaultTimeout = 10 * time.Second
+
+	dockerHubHost     = "docker.io"
+	dockerHubEndpoint = "registry-1.docker.io"
+)
+
+// manifestAccept 同时接受单架构 manifest 与多架构 index，避免 registry 因类型不匹配返回 404
+var manifestAccept = strings.Join([]string{
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.oci.image.index.v1+json",
+}, ", ")
+
+// ErrUnauthorized 凭据缺失或无权访问
+var ErrUnauthorized = errors.New("镜像仓库认证失败")
+
+// Reference 解析后的镜像地址
+type Reference struct {
+	Host       string // registry 地址（含端口），Docker Hub 为 docker.io
+	Repository string // 仓库路径，如 library/nginx、project/app
+	Reference  string // tag 或 digest（sha256:...）
+}
+
+// String 还原为镜像地址
+func (r Reference) String() string {
+	sep := ":"
+	if strings.Contains(r.Reference, ":") {
+		sep = "@"
+	}
+	return r.Host + "/" + r.Repository + sep + r.Reference
+}
+
+// ParseReference 解析镜像地址（host/repo:tag、host/repo@sha256:...），未带 tag 时为 latest；
+// 首段不含 "." / ":" 且不是 localhost 时视为 Docker Hub
+func ParseReference(image string) (Reference, error) {
+	image = strings.TrimSpace(image)
+	if image == "" {
+		return Reference{}, fmt.Errorf("镜像地址为空")
+	}
+
+	ref := Reference{}
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, ref.Reference = name[:i], name[i+1:]
+	} else if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.Reference = name[:i], name[i+1:]
+	}
+	if ref.Reference == "" {
+		ref.Reference = "latest"
+	}
+
+	first, rest, found := strings.Cut(name, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		ref.Host, ref.Repository = first, rest
+	} else {
+		ref.Host, ref.Repository = dockerHubHost, name
+		if !found {
+			ref.Repository = "library/" + name
+		}
+	}
+	if ref.Repository == "" {
+		return Reference{}, fmt.Errorf("镜像地址不合法: %s", image)
+	}
+	return ref, nil
+}
+
+// Credentials 镜像仓库凭据：Token 非空时直接作为 Bearer token，否则使用用户名/密码
+type Credentials struct {
+	Username string
+	Password string
+	Token    string
+}
+
+// Host 单个 registry 的访问配置
+type Host struct {
+	Insecure    bool // 使用 http
+	Credentials *Credentials
+}
+
+// Client Registry v2 客户端
+type Client struct {
+	http *http.Client
+	// hosts 按 registry 地址返回访问配置，未配置的 registry 匿名访问
+	hosts func(host string) (Host, error)
+}
+
+// NewClient 创建客户端，timeout 为 0 时使用 DefaultTimeout
+func NewClient(timeout time.Duration, hosts func(host string) (Host, error)) *Client {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Client{http: &http.Client{Timeout: timeout}, hosts: hosts}
+}
+
+// ManifestExists 镜像 tag / digest 是否存在；404 返回 false，其余非 2xx 返回错误
+func (c *Client) ManifestExists(ctx context.Context, ref Reference) (bool, error) {
+	host := Host{}
+	if c.hosts != nil {
+		h, err := c.hosts(ref.Host)
+		if err != nil {
+			return false, err
+		}
+		host = h
+	}
+
+	scheme, endpoint := "https", ref.Host
+	if host.Insecure {
+		scheme = "http"
+	}
+	if endpoint == dockerHubHost {
+		endpoint = dockerHubEndpoint
+	}
+	manifestURL := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", scheme, endpoint, ref.Repository, ref.Reference)
+
+	resp, err := c.head(ctx, manifestURL, "")
+	if err != nil {
+		return false, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		auth, err := c.authorize(ctx, resp.Header.Get("WWW-Authenticate"), host.Credentials)
+		if err != nil {
+			return false, err
+		}
+		if resp, err = c.head(ctx, manifestURL, auth); err != nil {
+			return false, err
+		}
+	}
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return true, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return false, fmt.Errorf("%w: %s 返回 %d", ErrUnauthorized, ref.Host, resp.StatusCode)
+	default:
+		return false, fmt.Errorf("查询镜像 manifest 失败: %s 返回 %d", ref.Host, resp.StatusCode)
+	}
+}
+
+func (c *Client) head(ctx context.Context, rawURL, auth string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", manifestAccept)
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求镜像仓库失败: %w", err)
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// authorize 按质询生成 Authorization 头：Basic 质询直接使用用户名/密码，Bearer 质询向 realm 换取 token
+func (c *Client) authorize(ctx context.Context, challenge string, cred *Credentials) (string, error) {
+	if cred != nil && cred.Token != "" {
+		return "Bearer " + cred.Token, nil
+	}
+	scheme, params := parseChallenge(challenge)
+	switch scheme {
+	case "basic":
+		if cred == nil {
+			return "", fmt.Errorf("%w: 需要用户名/密码", ErrUnauthorized)
+		}
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(cred.Username, cred.Password)
+		return req.Header.Get("Authorization"), nil
+	case "bearer":
+		token, err := c.fetchToken(ctx, params, cred)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+	default:
+		return "", fmt.Errorf("%w: 不支持的认证质询 %q", ErrUnauthorized, challenge)
+	}
+}
+
+// fetchToken 向 token 服务换取拉取权限的 token（Docker token 认证规范）
+func (c *Client) fetchToken(ctx context.Context, params map[string]string, cred *Credentials) (string, error) {
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("%w: Bearer 质询缺少 realm", ErrUnauthorized)
+	}
+	u, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("%w: realm 不合法: %v", ErrUnauthorized, err)
+	}
+	q := u.Query()
+	for _, k := range []string{"service", "scope"} {
+		if v := params[k]; v != "" {
+			q.Set(k, v)
+		}
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if cred != nil && (cred.Username != "" || cred.Password != "") {
+		req.SetBasicAuth(cred.Username, cred.Password)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("请求镜像仓库 token 失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: token 服务返回 %d", ErrUnauthorized, resp.StatusCode)
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&body); err != nil {
+		return "", fmt.Errorf("解析镜像仓库 token 失败: %w", err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", fmt.Errorf("%w: token 服务未返回 token", ErrUnauthorized)
+}
+
+// parseChallenge 解析 WWW-Authenticate，如 Bearer realm="https://auth/token",service="registry",scope="repository:a/b:pull"
+func parseChallenge(header string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	params := make(map[string]string)
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimLeft(strings.TrimSpace(rest), ",") {
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				params[key] = value[1:]
+				break
+			}
+			params[key], rest = value[1:end+1], value[end+2:]
+			continue
+		}
+		if i := strings.Index(value, ","); i >= 0 {
+			params[key], rest = value[:i], value[i:]
+			continue
+		}
+		params[key], rest = value, ""
+	}
+	return strings.ToLower(scheme), params
+}
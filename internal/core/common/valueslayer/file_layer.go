@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"archive/zip"
 	"compress/gzip"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -12,25 +13,19 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"time"
-)
 
-const DefaultCacheTTL = 30 * time.Minute
+	"devops-cd/internal/core/common/artifactcache"
+)
 
 // LoadFileLayer 支持两种模式：
 // 1) 本地文件：baseURL==""，path 为相对路径（相对本包的 local root）
 // 2) URL 压缩包：baseURL!=""，baseURL 为压缩包 URL，path 为压缩包内目标文件路径
 //
 // 注意：render 是已绑定 ctx 的模板渲染函数。
-func LoadFileLayer(baseURLTemplate string, pathTemplate string, render func(string) (string, error), applyAuth func(*http.Request)) ([]byte, error) {
+func LoadFileLayer(ctx context.Context, baseURLTemplate string, pathTemplate string, render func(string) (string, error), applyAuth func(*http.Request)) ([]byte, error) {
 	baseURLTemplate = strings.TrimSpace(baseURLTemplate)
 	pathTemplate = strings.TrimSpace(pathTemplate)
 
-	cacheRoot, err := cacheRootDir()
-	if err != nil {
-		return nil, err
-	}
-
 	if baseURLTemplate == "" {
 		if pathTemplate == "" {
 			return nil, fmt.Errorf("path_template 为空")
@@ -54,7 +49,10 @@ func LoadFileLayer(baseURLTemplate string, pathTemplate string, render func(stri
 		if clean == "." || strings.HasPrefix(clean, ".."+string(os.PathSeparator)) || clean == ".." {
 			return nil, fmt.Errorf("file.path_template 非法: %s", p)
 		}
-		localRoot := filepath.Join(cacheRoot, "local")
+		localRoot, err := localRootDir()
+		if err != nil {
+			return nil, err
+		}
 		abs := filepath.Join(localRoot, clean)
 		// 防止路径穿越
 		if !strings.HasPrefix(abs, localRoot+string(os.PathSeparator)) && abs != localRoot {
@@ -98,34 +96,25 @@ func LoadFileLayer(baseURLTemplate string, pathTemplate string, render func(stri
 		return nil, err
 	}
 
-	downloadsDir := filepath.Join(cacheRoot, "downloads")
-	extractedDir := filepath.Join(cacheRoot, "extracted")
-	if err := os.MkdirAll(downloadsDir, 0o755); err != nil {
-		return nil, err
-	}
-	if err := os.MkdirAll(extractedDir, 0o755); err != nil {
-		return nil, err
-	}
-
-	archiveKey := sha(u)
-	archivePath := filepath.Join(downloadsDir, archiveKey+archiveKind.fileExt())
-	archiveMod, err := ensureCachedDownload(u, archivePath, applyAuth)
+	// 压缩包经共享制品缓存拉取（内容寻址 + TTL + 条件请求）
+	archive, err := artifactcache.Get(ctx, artifactcache.Request{URL: u, Auth: applyAuth})
 	if err != nil {
 		return nil, err
 	}
 
-	exKey := sha(u + "|" + innerClean)
-	extractedPath := filepath.Join(extractedDir, exKey)
-	if ok, err := canUseCache(extractedPath, archiveMod); err != nil {
-		return nil, err
-	} else if ok {
-		return os.ReadFile(extractedPath)
-	}
-
-	if err := extractSingleFile(archivePath, archiveKind, innerClean, extractedPath); err != nil {
-		return nil, err
-	}
-	return os.ReadFile(extractedPath)
+	// 解压结果同样存入共享缓存：按压缩包内容摘要 + 包内路径寻址，内容不可变，同样受单对象大小限制
+	return artifactcache.Load(ctx, artifactcache.Source{
+		Key:       "extract|" + archive.Digest + "|" + innerClean,
+		Label:     u + "#" + innerClean,
+		Immutable: true,
+		Fetch: func(ctx context.Context, _ *artifactcache.Entry) (*artifactcache.Upstream, error) {
+			pr, pw := io.Pipe()
+			go func() {
+				pw.CloseWithError(extractSingleFile(archive.Path, archiveKind, innerClean, pw))
+			}()
+			return &artifactcache.Upstream{Body: pr, Size: -1}, nil
+		},
+	})
 }
 
 type archiveKind int
@@ -136,19 +125,6 @@ const (
 	archiveTgz
 )
 
-func (k archiveKind) fileExt() string {
-	switch k {
-	case archiveZip:
-		return ".zip"
-	case archiveTar:
-		return ".tar"
-	case archiveTgz:
-		return ".tgz"
-	default:
-		return ""
-	}
-}
-
 func detectArchiveKind(url string) (archiveKind, error) {
 	u := strings.ToLower(strings.TrimSpace(url))
 	switch {
@@ -163,7 +139,8 @@ func detectArchiveKind(url string) (archiveKind, error) {
 	}
 }
 
-func cacheRootDir() (string, error) {
+// localRootDir 本地文件模式的根目录
+func localRootDir() (string, error) {
 	dir, err := os.UserCacheDir()
 	if err != nil || strings.TrimSpace(dir) == "" {
 		dir = os.TempDir()
@@ -171,7 +148,7 @@ func cacheRootDir() (string, error) {
 	if strings.TrimSpace(dir) == "" {
 		return "", fmt.Errorf("无法确定缓存目录")
 	}
-	return filepath.Join(dir, "devops-cd", "values-layer-cache"), nil
+	return filepath.Join(dir, "devops-cd", "values-layer-cache", "local"), nil
 }
 
 func sha(s string) string {
@@ -179,85 +156,6 @@ func sha(s string) string {
 	return hex.EncodeToString(sum[:])
 }
 
-func canUseCache(path string, upstreamMod time.Time) (bool, error) {
-	st, err := os.Stat(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return false, nil
-		}
-		return false, err
-	}
-	// 过期或上游更新 -> 不复用
-	if time.Since(st.ModTime()) > DefaultCacheTTL {
-		return false, nil
-	}
-	if upstreamMod.After(st.ModTime()) {
-		return false, nil
-	}
-	return true, nil
-}
-
-func ensureCachedDownload(url string, dest string, applyAuth func(*http.Request)) (time.Time, error) {
-	if ok, err := canUseCache(dest, time.Time{}); err != nil {
-		return time.Time{}, err
-	} else if ok {
-		st, err := os.Stat(dest)
-		if err != nil {
-			return time.Time{}, err
-		}
-		return st.ModTime(), nil
-	}
-
-	tmp := dest + ".tmp"
-	_ = os.Remove(tmp)
-
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		return time.Time{}, err
-	}
-	if applyAuth != nil {
-		applyAuth(req)
-	}
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return time.Time{}, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		b, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
-		return time.Time{}, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
-	}
-
-	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
-		return time.Time{}, err
-	}
-	f, err := os.Create(tmp)
-	if err != nil {
-		return time.Time{}, err
-	}
-	_, copyErr := io.Copy(f, resp.Body)
-	closeErr := f.Close()
-	if copyErr != nil {
-		_ = os.Remove(tmp)
-		return time.Time{}, copyErr
-	}
-	if closeErr != nil {
-		_ = os.Remove(tmp)
-		return time.Time{}, closeErr
-	}
-	// 原子替换
-	if err := os.Rename(tmp, dest); err != nil {
-		// 并发场景：如果另一个 goroutine 已经写好了，直接复用现成的
-		_ = os.Remove(tmp)
-	}
-
-	st, err := os.Stat(dest)
-	if err != nil {
-		return time.Time{}, err
-	}
-	return st.ModTime(), nil
-}
-
 func sanitizeArchivePath(p string) (string, error) {
 	p = strings.TrimSpace(p)
 	if p == "" {
@@ -275,20 +173,21 @@ func sanitizeArchivePath(p string) (string, error) {
 	return p, nil
 }
 
-func extractSingleFile(archivePath string, kind archiveKind, innerPath string, outPath string) error {
+// extractSingleFile 将压缩包内的单个文件写入 w
+func extractSingleFile(archivePath string, kind archiveKind, innerPath string, w io.Writer) error {
 	switch kind {
 	case archiveZip:
-		return extractZipSingle(archivePath, innerPath, outPath)
+		return extractZipSingle(archivePath, innerPath, w)
 	case archiveTar:
-		return extractTarSingle(archivePath, innerPath, outPath, false)
+		return extractTarSingle(archivePath, innerPath, w, false)
 	case archiveTgz:
-		return extractTarSingle(archivePath, innerPath, outPath, true)
+		return extractTarSingle(archivePath, innerPath, w, true)
 	default:
 		return fmt.Errorf("unknown archive kind")
 	}
 }
 
-func extractZipSingle(archivePath string, innerPath string, outPath string) error {
+func extractZipSingle(archivePath string, innerPath string, w io.Writer) error {
 	r, err := zip.OpenReader(archivePath)
 	if err != nil {
 		return err
@@ -312,12 +211,13 @@ func extractZipSingle(archivePath string, innerPath string, outPath string) erro
 			return err
 		}
 		defer rc.Close()
-		return writeAtomically(outPath, rc)
+		_, err = io.Copy(w, rc)
+		return err
 	}
 	return fmt.Errorf("压缩包内未找到文件: %s", innerPath)
 }
 
-func extractTarSingle(archivePath string, innerPath string, outPath string, gz bool) error {
+func extractTarSingle(archivePath string, innerPath string, w io.Writer, gz bool) error {
 	f, err := os.Open(archivePath)
 	if err != nil {
 		return err
@@ -353,38 +253,11 @@ func extractTarSingle(archivePath string, innerPath string, outPath string, gz b
 		}
 		switch h.Typeflag {
 		case tar.TypeReg, tar.TypeRegA:
-			return writeAtomically(outPath, tr)
+			_, err := io.Copy(w, tr)
+			return err
 		default:
 			return fmt.Errorf("archive entry is not a regular file: %s", innerPath)
 		}
 	}
 	return fmt.Errorf("压缩包内未找到文件: %s", innerPath)
 }
-
-func writeAtomically(dest string, r io.Reader) error {
-	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
-		return err
-	}
-	tmp := dest + ".tmp"
-	_ = os.Remove(tmp)
-
-	f, err := os.Create(tmp)
-	if err != nil {
-		return err
-	}
-	_, copyErr := io.Copy(f, r)
-	closeErr := f.Close()
-	if copyErr != nil {
-		_ = os.Remove(tmp)
-		return copyErr
-	}
-	if closeErr != nil {
-		_ = os.Remove(tmp)
-		return closeErr
-	}
-	if err := os.Rename(tmp, dest); err != nil {
-		_ = os.Remove(tmp)
-		return err
-	}
-	return nil
-}
@@ -0,0 +1,278 @@
+package valueslayer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"devops-cd/internal/core/common/artifactcache"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	gitclient "github.com/go-git/go-git/v5/plumbing/transport/client"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"golang.org/x/crypto/ssh"
+)
+
+// GitLayer 已渲染模板的 git values 层
+type GitLayer struct {
+	RepoURL    string            // 仓库地址（不含凭据）
+	Ref        string            // branch / tag / commit，为空时使用 main
+	Path       string            // 仓库内文件路径
+	Credential map[string]string // 凭据（v1：basic_auth/token/ssh_key），只在内存中交给 go-git，不拼进 URL
+}
+
+// fetchRef 拉取到内存仓库中的本地 ref
+const fetchRef = "refs/devops-cd/values"
+
+var commitSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// go-git http(s) 连接按制品缓存的主机访问策略拨号（连接时校验实际 IP，见 artifactcache.DialContext）
+func init() {
+	client := githttp.NewClient(&http.Client{Transport: artifactcache.NewTransport()})
+	gitclient.InstallProtocol("https", client)
+	gitclient.InstallProtocol("http", client)
+}
+
+// LoadGitLayer 经共享制品缓存读取 git 仓库中的文件（go-git，不依赖系统 git）
+//
+//   - 缓存 key 为 仓库 + ref + 路径 + 凭据指纹，版本标识（ETag）为 commit
+//   - TTL 内直接命中；过期后 ls-remote 对比 ref 当前 commit，未变化只刷新时间，变化时 depth=1 拉取到内存并只读取目标文件
+//   - ref 为完整 commit SHA 时内容不可变，命中后不再校验
+//   - 凭据只以 AuthMethod 形式在内存中使用，错误信息中的凭据与 URL userinfo 会被脱敏
+//   - git 服务不可用时返回旧内容（stale）；git 操作受 artifact_cache.timeout 限制
+func LoadGitLayer(ctx context.Context, layer GitLayer) ([]byte, error) {
+	repo := strings.TrimSpace(layer.RepoURL)
+	if repo == "" {
+		return nil, fmt.Errorf("repo_url 为空")
+	}
+	ref := strings.TrimSpace(layer.Ref)
+	if ref == "" {
+		ref = "main"
+	}
+	relPath := filepath.ToSlash(filepath.Clean(strings.TrimSpace(layer.Path)))
+	if relPath == "." || path.IsAbs(relPath) || relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return nil, fmt.Errorf("path_template 非法: %s", layer.Path)
+	}
+	auth, err := gitAuth(repo, layer.Credential)
+	if err != nil {
+		return nil, err
+	}
+	proxyOpts, err := gitDialProxy(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := artifactcache.Load(ctx, artifactcache.Source{
+		Key:       "git|" + repo + "|" + ref + "|" + relPath + "|" + credentialFingerprint(layer.Credential),
+		Label:     fmt.Sprintf("git %s@%s:%s", redactURL(repo), ref, relPath),
+		URL:       repo,
+		Immutable: commitSHA.MatchString(ref),
+		Fetch: func(ctx context.Context, cached *artifactcache.Entry) (*artifactcache.Upstream, error) {
+			st := memory.NewStorage()
+			remote := git.NewRemote(st, &gitconfig.RemoteConfig{Name: "origin", URLs: []string{repo}})
+			target, err := resolveRef(ctx, remote, ref, auth, proxyOpts)
+			if err != nil {
+				return nil, err
+			}
+			if cached != nil && cached.ETag == target.commit.String() {
+				return &artifactcache.Upstream{NotModified: true}, nil
+			}
+			f, err := fetchFile(ctx, st, remote, target, relPath, auth, proxyOpts)
+			if err != nil {
+				return nil, err
+			}
+			r, err := f.Reader()
+			if err != nil {
+				return nil, err
+			}
+			return &artifactcache.Upstream{Body: r, Size: f.Size, ETag: target.commit.String()}, nil
+		},
+	})
+	if err != nil {
+		return nil, scrubGitError(err, layer.Credential)
+	}
+	return b, nil
+}
+
+// gitTarget ref 解析结果：src 为远端 ref 名（commit SHA 时为 SHA 本身），commit 为最终指向的 commit
+type gitTarget struct {
+	src    string
+	commit plumbing.Hash
+}
+
+// resolveRef 解析 ref 当前指向的 commit：完整 commit SHA 直接使用，否则按 原名 → 分支 → tag（附注 tag 取其指向的 commit）查找
+func resolveRef(ctx context.Context, remote *git.Remote, ref string, auth transport.AuthMethod, proxyOpts transport.ProxyOptions) (*gitTarget, error) {
+	if commitSHA.MatchString(ref) {
+		return &gitTarget{src: ref, commit: plumbing.NewHash(ref)}, nil
+	}
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: auth, PeelingOption: git.AppendPeeled, ProxyOptions: proxyOpts})
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]plumbing.Hash, len(refs))
+	for _, r := range refs {
+		if r.Type() == plumbing.HashReference {
+			byName[r.Name().String()] = r.Hash()
+		}
+	}
+	candidates := []string{ref}
+	if !strings.HasPrefix(ref, "refs/") {
+		candidates = append(candidates, "refs/heads/"+ref, "refs/tags/"+ref)
+	}
+	for _, name := range candidates {
+		h, ok := byName[name]
+		if !ok {
+			continue
+		}
+		if peeled, ok := byName[name+"^{}"]; ok {
+			h = peeled
+		}
+		return &gitTarget{src: name, commit: h}, nil
+	}
+	return nil, fmt.Errorf("远端仓库中未找到 ref: %s", ref)
+}
+
+// fetchFile depth=1 拉取 target 到内存存储并读取单个文件（不检出工作区、不落盘）
+func fetchFile(ctx context.Context, st *memory.Storage, remote *git.Remote, target *gitTarget, relPath string, auth transport.AuthMethod, proxyOpts transport.ProxyOptions) (*object.File, error) {
+	opts := &git.FetchOptions{
+		RefSpecs:     []gitconfig.RefSpec{gitconfig.RefSpec(target.src + ":" + fetchRef)},
+		Depth:        1,
+		Auth:         auth,
+		Tags:         git.NoTags,
+		ProxyOptions: proxyOpts,
+	}
+	err := remote.FetchContext(ctx, opts)
+	if errors.Is(err, git.ErrExactSHA1NotSupported) {
+		// 服务端不允许按 SHA 拉取时，退回拉取全部分支历史再查找 commit
+		opts.RefSpecs = []gitconfig.RefSpec{"+refs/heads/*:refs/remotes/origin/*"}
+		opts.Depth = 0
+		err = remote.FetchContext(ctx, opts)
+	}
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, err
+	}
+
+	commit, err := object.GetCommit(st, target.commit)
+	if err != nil {
+		return nil, fmt.Errorf("读取 commit %s 失败: %w", target.commit, err)
+	}
+	f, err := commit.File(relPath)
+	if err != nil {
+		if errors.Is(err, object.ErrFileNotFound) {
+			return nil, fmt.Errorf("仓库中不存在文件 %s（commit %s）", relPath, target.commit)
+		}
+		return nil, err
+	}
+	if !f.Mode.IsFile() {
+		return nil, fmt.Errorf("path_template 不是普通文件: %s", relPath)
+	}
+	return f, nil
+}
+
+// gitDialProxy ssh 连接经伪代理接入制品缓存的主机访问策略（http(s) 已在 init 中替换 transport）；
+// git:// 与 file:// 无法在连接时校验，启用访问策略时拒绝
+func gitDialProxy(repoURL string) (transport.ProxyOptions, error) {
+	ep, err := transport.NewEndpoint(repoURL)
+	if err != nil {
+		return transport.ProxyOptions{}, fmt.Errorf("repo_url 无法解析: %s", redactURL(repoURL))
+	}
+	switch ep.Protocol {
+	case "http", "https":
+		return transport.ProxyOptions{}, nil
+	case "ssh":
+		return transport.ProxyOptions{URL: artifactcache.PolicyProxyURL}, nil
+	}
+	if artifactcache.Enforced() {
+		return transport.ProxyOptions{}, fmt.Errorf("%w: 启用主机访问策略时不支持 %s 协议", artifactcache.ErrHostDenied, ep.Protocol)
+	}
+	return transport.ProxyOptions{}, nil
+}
+
+// gitAuth 基于凭据构造 go-git 认证（v1：basic_auth/token/ssh_key），凭据不写入 URL、环境变量或磁盘
+func gitAuth(repoURL string, cred map[string]string) (transport.AuthMethod, error) {
+	if cred == nil {
+		return nil, nil
+	}
+	https := strings.HasPrefix(repoURL, "https://")
+	switch cred["_type"] {
+	case "token":
+		if tok := strings.TrimSpace(cred["token"]); tok != "" && https {
+			return &githttp.BasicAuth{Username: tok}, nil
+		}
+	case "basic_auth":
+		if u, p := cred["username"], cred["password"]; (u != "" || p != "") && https {
+			return &githttp.BasicAuth{Username: u, Password: p}, nil
+		}
+	case "ssh_key":
+		key := strings.TrimSpace(cred["private_key"])
+		if key == "" {
+			return nil, fmt.Errorf("ssh_key.private_key 为空")
+		}
+		user := "git"
+		if ep, err := transport.NewEndpoint(repoURL); err == nil && ep.User != "" {
+			user = ep.User
+		}
+		keys, err := gitssh.NewPublicKeys(user, []byte(key), cred["passphrase"])
+		if err != nil {
+			return nil, fmt.Errorf("ssh_key.private_key 解析失败: %w", err)
+		}
+		// 与此前 StrictHostKeyChecking=no 一致：不校验 host key
+		keys.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+		return keys, nil
+	}
+	return nil, nil
+}
+
+// credentialFingerprint 凭据指纹（sha256），凭据更新后缓存自动失效
+func credentialFingerprint(cred map[string]string) string {
+	if len(cred) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(cred))
+	for k := range cred {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k + "=" + cred[k] + "\n")
+	}
+	return sha(sb.String())
+}
+
+var urlUserInfo = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://)[^/@\s]+@`)
+
+// redactURL 去掉 URL 中的 userinfo（repo_url 中直接写了凭据时）
+func redactURL(s string) string {
+	return urlUserInfo.ReplaceAllString(s, "${1}***@")
+}
+
+// scrubGitError 错误信息脱敏：去掉 URL userinfo 并替换凭据明文
+func scrubGitError(err error, cred map[string]string) error {
+	msg := redactURL(err.Error())
+	for _, k := range []string{"token", "password", "passphrase"} {
+		v := strings.TrimSpace(cred[k])
+		if len(v) < 4 {
+			continue
+		}
+		msg = strings.ReplaceAll(msg, v, "***")
+		msg = strings.ReplaceAll(msg, url.QueryEscape(v), "***")
+	}
+	if msg == err.Error() {
+		return err
+	}
+	return errors.New(msg)
+}
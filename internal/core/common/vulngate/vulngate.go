@@ -0,0 +1,84 @@
+// Package vulngate 镜像漏洞门禁：按项目漏洞策略（projects.vuln_policy）与最近一次镜像扫描结果（image_scans）检查批次内构建，
+// 封板（batch 状态机）与触发生产部署（release_app 状态机）共用
+package vulngate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"devops-cd/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// Violation 未通过门禁的构建镜像
+type Violation struct {
+	AppID  int64
+	Image  string
+	Reason string
+}
+
+func (v Violation) String() string {
+	if v.Image == "" {
+		return fmt.Sprintf("应用 %d: %s", v.AppID, v.Reason)
+	}
+	return fmt.Sprintf("%s: %s", v.Image, v.Reason)
+}
+
+// Policy 批次在 stage 阶段生效的漏洞策略；项目未配置、拦截阶段不符或批次已豁免时返回 nil
+func Policy(db *gorm.DB, batch *model.Batch, stage string) (*model.VulnPolicy, error) {
+	if batch.VulnOverrideAt != nil {
+		return nil, nil
+	}
+	var project model.Project
+	if err := db.Select("id", "vuln_policy").First(&project, batch.ProjectID).Error; err != nil {
+		return nil, fmt.Errorf("查询项目漏洞策略失败: %w", err)
+	}
+	policy := project.VulnPolicy
+	if !policy.IsEnabled() {
+		return nil, nil
+	}
+	policy.Normalize()
+	if policy.Stage != stage {
+		return nil, nil
+	}
+	return policy, nil
+}
+
+// Evaluate 按各镜像最近一次扫描结果检查构建，返回未通过的镜像
+func Evaluate(db *gorm.DB, policy *model.VulnPolicy, builds []model.Build) ([]Violation, error) {
+	var violations []Violation
+	for i := range builds {
+		build := &builds[i]
+		image, tag := build.ScanImage()
+		var scan *model.ImageScan
+		if image != "" {
+			var s model.ImageScan
+			err := db.Where("image = ? AND tag = ?", image, tag).First(&s).Error
+			switch {
+			case err == nil:
+				scan = &s
+			case !errors.Is(err, gorm.ErrRecordNotFound):
+				return nil, fmt.Errorf("查询镜像扫描结果失败: %w", err)
+			}
+		}
+		if reason := policy.Check(scan); reason != "" {
+			ref := ""
+			if image != "" {
+				ref = image + ":" + tag
+			}
+			violations = append(violations, Violation{AppID: build.AppID, Image: ref, Reason: reason})
+		}
+	}
+	return violations, nil
+}
+
+// Summary 拦截原因
+func Summary(violations []Violation) string {
+	items := make([]string, 0, len(violations))
+	for _, v := range violations {
+		items = append(items, v.String())
+	}
+	return "镜像漏洞门禁未通过: " + strings.Join(items, "; ")
+}
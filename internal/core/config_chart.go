@@ -0,0 +1,82 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+)
+
+// gateConfigCharts app Deployment 等待同 release/env/cluster 的 config Deployment 成功后再部署
+func (e *CoreEngine) gateConfigCharts(ctx context.Context, releaseID int64, deps []*model.Deployment) []*model.Deployment {
+	var configs []model.Deployment
+	if err := e.db.WithContext(ctx).Select("id", "env", "cluster", "status").
+		Where("release_id = ? AND kind = ? AND superseded_by IS NULL", releaseID, constants.DeploymentKindConfig).
+		Find(&configs).Error; err != nil {
+		e.logger.Error("查询 config Deployment 失败", zap.Int64("release_id", releaseID), zap.Error(err))
+		return nil
+	}
+	if len(configs) == 0 {
+		return deps
+	}
+	configStatus := make(map[string]string, len(configs))
+	for _, c := range configs {
+		configStatus[c.Env+"/"+c.ClusterName] = c.Status
+	}
+
+	allowed := make([]*model.Deployment, 0, len(deps))
+	for _, dep := range deps {
+		status, ok := configStatus[dep.Env+"/"+dep.ClusterName]
+		if dep.Kind == constants.DeploymentKindConfig || dep.Status != constants.DeploymentStatusPending || !ok || status == constants.DeploymentStatusSuccess {
+			allowed = append(allowed, dep)
+			continue
+		}
+		if status == constants.DeploymentStatusFailed {
+			e.holdDeployment(ctx, dep, "config chart 部署失败，重试或重新部署 config chart 后继续")
+		} else {
+			e.holdDeployment(ctx, dep, "等待 config chart 部署完成")
+		}
+	}
+	return allowed
+}
+
+// scanConfigDeployments 处理已完成批次中单独重新部署的 config Deployment（批次完成后不再有 batchWork）
+func (e *CoreEngine) scanConfigDeployments() {
+	var deps []model.Deployment
+	if err := e.db.Model(&model.Deployment{}).
+		Joins("JOIN release_batches ON release_batches.id = deployments.batch_id").
+		Where("release_batches.status = ? AND release_batches.updated_at > ?", constants.BatchStatusCompleted, time.Now().Add(-time.Hour*24*30)).
+		Where("deployments.kind = ? AND deployments.superseded_by IS NULL AND deployments.status IN ?",
+			constants.DeploymentKindConfig, []string{constants.DeploymentStatusPending, constants.DeploymentStatusRunning}).
+		Find(&deps).Error; err != nil {
+		e.logger.Error(fmt.Sprintf("[ConfigChart] 查询待处理 config Deployment 失败: %v", err))
+		return
+	}
+
+	batchIDs := make([]int64, 0, len(deps))
+	for _, dep := range deps {
+		batchIDs = append(batchIDs, dep.BatchID)
+	}
+	paused := e.pausedBatchIDs(e.workCtx, lo.Uniq(batchIDs))
+
+	groups := make(map[int64][]*model.Deployment)
+	var releaseIDs []int64
+	for i := range deps {
+		dep := &deps[i]
+		if e.batchRunning(dep.BatchID) || paused[dep.BatchID] {
+			continue
+		}
+		if _, ok := groups[dep.ReleaseID]; !ok {
+			releaseIDs = append(releaseIDs, dep.ReleaseID)
+		}
+		groups[dep.ReleaseID] = append(groups[dep.ReleaseID], dep)
+	}
+	for _, releaseID := range releaseIDs {
+		e.processReleaseDeployments(e.workCtx, releaseID, groups[releaseID])
+	}
+}
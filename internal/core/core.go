@@ -4,16 +4,27 @@ import (
 	"context"
 	"devops-cd/internal/adapter/notification"
 	"devops-cd/internal/core/batch"
+	"devops-cd/internal/core/changelog"
 	"devops-cd/internal/core/deployment"
+	"devops-cd/internal/core/jobs"
 	"devops-cd/internal/core/release_app"
+	"devops-cd/internal/core/watch"
+	"devops-cd/internal/core/webhook"
 	"devops-cd/internal/model"
 	"devops-cd/internal/pkg/config"
+	"devops-cd/internal/pkg/leader"
+	"devops-cd/internal/pkg/tracing"
 	"devops-cd/pkg/constants"
+	"errors"
 	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/samber/lo"
 
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -24,18 +35,106 @@ type CoreEngine struct {
 	notifier notification.Notifier
 	logger   *zap.Logger
 
+	// 订阅通知（私聊/邮件），未配置渠道时为空
+	directNotifiers []notification.DirectNotifier
+
 	running  bool
 	stopChan chan struct{}
 
+	// 优雅停止：workers 跟踪扫描、批次处理、任务队列等进行中的处理；draining 后不再启动新的批次处理，
+	// 等待超过 drainTimeout 时取消 workCtx 中断剩余处理
+	workers      sync.WaitGroup
+	draining     atomic.Bool
+	workCtx      context.Context
+	abortWork    context.CancelFunc
+	drainTimeout time.Duration
+	notifyStop   chan struct{}
+	notifyDone   chan struct{}
+
 	batchSM      *batch.StateMachine
 	releaseSM    *release_app.ReleaseStateMachine
 	deploymentSM *deployment.StateMachine
 
-	batchTask map[int64]context.CancelFunc
+	// 批次处理任务（每个进行中的批次一个 batchWork），由 taskMu 保护
+	taskMu    sync.Mutex
+	batchTask map[int64]*batchTask
+	// 待立即处理的批次（TriggerBatch），由扫描 goroutine 分发
+	triggers chan int64
+	// 批次处理兜底间隔（未收到触发时按该间隔推进）
+	sweepInterval time.Duration
+
+	// 单应用多集群 Deployment 的并发上限
+	clusterConcurrency int
+	// 全局/项目/集群部署并发控制
+	limiter *deployLimiter
+	// 批次部署阶段超时（未配置时为 nil）
+	batchTimeout *batchTimeout
+	// 部署运行时长异常告警（未配置 slow_factor 时为 nil）
+	slowDeploy *slowDeploy
+
+	deploymentOpts []deployment.Option
+
+	// 状态变更通知（异步发送）
+	notifyQueue chan func(ctx context.Context) error
+	// 开启 notify_digest 的项目按批次阶段汇总应用部署结果
+	digest *notification.Digest
+	// 变更日志（批次完成通知附带、issue 关联），未加载全局配置时为 nil
+	changelog       *changelog.Generator
+	attachChangelog bool
+
+	// 出站 Webhook
+	webhooks          *webhook.Dispatcher
+	webhookDelivering atomic.Bool
+
+	// 批次状态变更推送
+	watchHub *watch.Hub
+
+	// 多副本部署时的主节点选举，为 nil 时始终运行扫描
+	elector leader.Leader
+
+	// 扫描间隔（Start 时设置）、手动扫描请求与进程内处理统计（见 engine_status.go）
+	scanInterval time.Duration
+	scanNow      chan struct{}
+	stats        engineStats
+
+	// 异步任务队列（Deployment 执行），未启用时为 nil
+	jobs *jobs.Queue
+
+	// 启动恢复检查的发布应用停留阈值，为 0 时不执行启动恢复（见 reconcile）
+	reconcileStaleAfter time.Duration
+
+	// 配置漂移检测是否对比集群中的资源对象（core.drift_check.live_objects）
+	driftLiveObjects bool
+}
+
+const defaultClusterConcurrency = 4
+
+// Option 定制 CoreEngine（集成测试注入 fake driver / notifier 等）
+type Option func(*CoreEngine)
+
+// WithNotifier 替换通知器（默认按 core.notification 配置创建）
+func WithNotifier(n notification.Notifier) Option {
+	return func(e *CoreEngine) {
+		e.notifier = n
+	}
+}
+
+// WithLeader 多副本部署时只有主节点运行扫描（批次推进、部署触发、Webhook 投递）
+func WithLeader(l leader.Leader) Option {
+	return func(e *CoreEngine) {
+		e.elector = l
+	}
+}
+
+// WithDeploymentOptions 透传 Deployment 状态机选项（如 deployment.WithRegistry）
+func WithDeploymentOptions(opts ...deployment.Option) Option {
+	return func(e *CoreEngine) {
+		e.deploymentOpts = append(e.deploymentOpts, opts...)
+	}
 }
 
 // NewCoreEngine 创建核心引擎
-func NewCoreEngine(db *gorm.DB, logger *zap.Logger, coreCfg *config.CoreConfig) *CoreEngine {
+func NewCoreEngine(db *gorm.DB, logger *zap.Logger, coreCfg *config.CoreConfig, opts ...Option) *CoreEngine {
 
 	// 创建部署服务
 	//deployService := deploy.NewMockDeployer()
@@ -56,18 +155,85 @@ func NewCoreEngine(db *gorm.DB, logger *zap.Logger, coreCfg *config.CoreConfig)
 	}
 	resolver := release_app.NewResolver(db, logger, depCfg)
 
-	return &CoreEngine{
+	clusterConcurrency := defaultClusterConcurrency
+	if coreCfg != nil && coreCfg.Deploy.ConcurrentClusters > 0 {
+		clusterConcurrency = coreCfg.Deploy.ConcurrentClusters
+	}
+
+	workCtx, abortWork := context.WithCancel(context.Background())
+	e := &CoreEngine{
 		db:       db,
-		notifier: notification.NewLogNotifier(logger),
+		notifier: newNotifier(db, logger, coreCfg),
 		logger:   logger,
 		stopChan: make(chan struct{}),
 
-		batchSM:      batch.NewBatchStateMachine(db, logger),
-		releaseSM:    release_app.NewReleaseStateMachine(db, logger, resolver),
-		deploymentSM: deployment.NewDeploymentStateMachine(db, logger),
+		workCtx:      workCtx,
+		abortWork:    abortWork,
+		drainTimeout: drainTimeoutFromConfig(coreCfg, logger),
+		notifyStop:   make(chan struct{}),
+		notifyDone:   make(chan struct{}),
+
+		directNotifiers: newDirectNotifiers(logger, coreCfg),
+
+		notifyQueue:     make(chan func(ctx context.Context) error, notifyQueueSize),
+		changelog:       newChangelogGenerator(db),
+		attachChangelog: coreCfg != nil && coreCfg.Notification.Enabled && coreCfg.Notification.AttachChangelog,
+
+		batchSM:   batch.NewBatchStateMachine(db, logger, resolver, deployment.NewChartLocker(db), deployment.NewManifestLocker(db)),
+		releaseSM: release_app.NewReleaseStateMachine(db, logger, resolver),
+
+		batchTask:     make(map[int64]*batchTask, 10),
+		triggers:      make(chan int64, triggerQueueSize),
+		scanNow:       make(chan struct{}, 1),
+		sweepInterval: sweepIntervalFromConfig(coreCfg, logger),
+
+		clusterConcurrency: clusterConcurrency,
+		limiter:            newDeployLimiter(deployLimitsFromConfig(coreCfg)),
+		batchTimeout:       newBatchTimeout(coreCfg, logger),
+		slowDeploy:         newSlowDeploy(coreCfg, logger),
 
-		batchTask: make(map[int64]context.CancelFunc, 10),
+		reconcileStaleAfter: reconcileStaleAfterFromConfig(coreCfg, logger),
+		driftLiveObjects:    coreCfg != nil && coreCfg.DriftCheck.LiveObjects,
+
+		watchHub: watch.NewHub(),
+	}
+	if coreCfg != nil && coreCfg.Deploy.DiffAckProtected {
+		e.deploymentOpts = append(e.deploymentOpts, deployment.WithDiffAck())
+	}
+	if coreCfg != nil && coreCfg.Deploy.SingleAppTimeout != "" {
+		if timeout, err := time.ParseDuration(coreCfg.Deploy.SingleAppTimeout); err != nil {
+			logger.Warn("single_app_timeout 解析失败，不检查部署超时", zap.String("value", coreCfg.Deploy.SingleAppTimeout), zap.Error(err))
+		} else {
+			e.deploymentOpts = append(e.deploymentOpts, deployment.WithRunningTimeout(timeout))
+		}
+	}
+	if coreCfg != nil && coreCfg.Deploy.RetryBackoff != "" {
+		base, err := time.ParseDuration(coreCfg.Deploy.RetryBackoffBase)
+		if err != nil && coreCfg.Deploy.RetryBackoffBase != "" {
+			logger.Warn("retry_backoff_base 解析失败，使用默认值", zap.String("value", coreCfg.Deploy.RetryBackoffBase), zap.Error(err))
+		}
+		e.deploymentOpts = append(e.deploymentOpts, deployment.WithRetryBackoff(coreCfg.Deploy.RetryBackoff, base))
 	}
+	if e.jobs = newJobQueue(e, coreCfg); e.jobs != nil {
+		e.deploymentOpts = append(e.deploymentOpts, deployment.WithJobQueue(e.jobs))
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.digest = notification.NewDigest(e.notifier, notification.NewDBDigestStore(db), digestMaxWaitFromConfig(coreCfg, logger), e.enqueueNotify, logger)
+	e.deploymentSM = deployment.NewDeploymentStateMachine(db, logger, e.deploymentOpts...)
+	e.configureArtifactCache(coreCfg)
+	e.configureMetaCache(coreCfg)
+	e.configureImageVerifier(coreCfg)
+	e.webhooks = newWebhookDispatcher(e, coreCfg)
+	e.registerWebhookListeners()
+	e.registerNotifyListeners()
+	e.registerSubscriptionListeners()
+	e.registerWatchListeners()
+	e.registerAutoRollbackListener()
+	e.registerIssueLinkListener()
+	e.registerTriggerListeners()
+	return e
 }
 
 // Start 启动核心引擎
@@ -78,22 +244,22 @@ func (e *CoreEngine) Start(scanInterval time.Duration) {
 	}
 
 	e.running = true
+	e.scanInterval = scanInterval
 	e.logger.Info("CoreEngine starting...", zap.Duration("scan_interval", scanInterval))
 
 	// 启动定时扫描
-	go e.runScanner(scanInterval)
+	e.goWork(func() { e.runScanner(scanInterval) })
+	go e.runNotifier()
+	if e.jobs != nil {
+		e.goWork(e.runJobs)
+	}
 }
 
-// Stop 停止核心引擎
+// Stop 停止核心引擎，最多等待 core.drain_timeout 让进行中的处理完成（见 Shutdown）
 func (e *CoreEngine) Stop() {
-	if !e.running {
-		return
-	}
-
-	e.logger.Info("正在停止核心引擎...")
-	close(e.stopChan)
-	e.running = false
-	e.logger.Info("核心引擎已停止")
+	ctx, cancel := context.WithTimeout(context.Background(), e.drainTimeout)
+	defer cancel()
+	_ = e.Shutdown(ctx)
 }
 
 // runScanner 运行扫描器
@@ -101,21 +267,62 @@ func (e *CoreEngine) runScanner(interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
+	// 启动或成为主节点后先执行启动恢复，再开始扫描
+	leading := false
+	scan := func() {
+		if !leader.IsLeader(e.elector) {
+			leading = false
+			e.stopBatchTasks()
+			return
+		}
+		if !leading {
+			leading = true
+			e.reconcile(e.workCtx)
+		}
+		e.ScanBatches()
+	}
+	scan()
+
 	for {
 		select {
 		case <-ticker.C:
-			e.ScanBatches()
+			scan()
+		case <-e.scanNow:
+			e.stats.observeForcedScan()
+			scan()
+			e.wakeBatchTasks()
+		case batchID := <-e.triggers:
+			if leader.IsLeader(e.elector) {
+				e.dispatchTrigger(batchID)
+			}
 		case <-e.stopChan:
 			return
 		}
 	}
 }
 
+// stopBatchTasks 失去主节点身份后停止本副本的批次处理，由新的主节点接管
+func (e *CoreEngine) stopBatchTasks() {
+	e.taskMu.Lock()
+	tasks := lo.Values(e.batchTask)
+	e.taskMu.Unlock()
+	if len(tasks) == 0 {
+		return
+	}
+	// batchWork 退出时会从 batchTask 中删除自身
+	e.logger.Warn("[BatchScaner] 当前副本不是主节点，停止批次处理", zap.Int("batches", len(tasks)))
+	for _, t := range tasks {
+		t.cancel()
+	}
+}
+
 func (e *CoreEngine) ScanBatches() {
+	start := time.Now()
 	var batches []model.Batch
-	// 查询 Sealed < status < Completed 并且 create_at < 30 Days
-	if err := e.db.Where("status > ? AND status < ?", constants.BatchStatusDraft, constants.BatchStatusCompleted).
-		Where("created_at > ?", time.Now().Add(-time.Hour*24*30)).
+	defer func() { e.stats.observeScan(start, len(batches)) }()
+	// 查询 Sealed < status < Completed（及回滚中）并且 create_at < 30 Days
+	if err := e.db.Where("(status > ? AND status < ?) OR status = ?", constants.BatchStatusDraft, constants.BatchStatusCompleted, constants.BatchStatusRollingBack).
+		Where("created_at > ?", time.Now().Add(-batchActiveWindow)).
 		Order("id DESC").Find(&batches).Error; err != nil {
 		e.logger.Error(fmt.Sprintf("[BatchScaner] 查询批次失败: %v", err))
 		return
@@ -127,62 +334,98 @@ func (e *CoreEngine) ScanBatches() {
 	e.logger.Debug(fmt.Sprintf("[BatchScaner] 待处理的Batch %d个: %+v", len(batches), list))
 
 	for _, b := range batches {
-		if cancel, exists := e.batchTask[b.ID]; exists {
-			// 如果已经存在, 检查是否需要结束
-			if b.Status == constants.BatchStatusCompleted || b.Status == constants.BatchStatusCancelled {
-				cancel()
-				delete(e.batchTask, b.ID)
-			}
+		e.startBatchWork(b.ID)
+	}
+
+	e.collectPendingImpacts()
+	e.scanConfigDeployments()
+	e.scanAdhocDeployments()
+	e.scanSlowDeployments()
+	e.scanAppDecommissions()
+	e.deliverWebhooks()
+}
+
+// collectBatchImpact 批次完成后采集各应用集群资源现状（部署前 vs 部署后）
+func (e *CoreEngine) collectBatchImpact(ctx context.Context, batchID int64) {
+	if err := deployment.CollectImpactAfter(ctx, e.db, batchID); err != nil {
+		e.logger.Warn(fmt.Sprintf("[BatchImpact] Batch:%d 采集部署影响失败: %v", batchID, err), zap.Int64("batch_id", batchID))
+		return
+	}
+	e.logger.Info(fmt.Sprintf("[BatchImpact] Batch:%d 部署影响采集完成", batchID), zap.Int64("batch_id", batchID))
+}
+
+// collectPendingImpacts 补采已完成但未采集 after 的批次（如批次完成时服务重启）
+func (e *CoreEngine) collectPendingImpacts() {
+	var batchIDs []int64
+	if err := e.db.Model(&model.BatchAppImpact{}).
+		Joins("JOIN release_batches ON release_batches.id = batch_app_impacts.batch_id").
+		Where("release_batches.status = ? AND release_batches.updated_at > ?", constants.BatchStatusCompleted, time.Now().Add(-time.Hour*24*30)).
+		Where("batch_app_impacts.after_collected_at IS NULL AND batch_app_impacts.error_message IS NULL").
+		Distinct().Limit(5).Pluck("batch_app_impacts.batch_id", &batchIDs).Error; err != nil {
+		e.logger.Error(fmt.Sprintf("[BatchImpact] 查询待采集批次失败: %v", err))
+		return
+	}
+	for _, id := range batchIDs {
+		if e.batchRunning(id) {
 			continue
 		}
-
-		ctx, cancel := context.WithCancel(context.TODO())
-		e.batchTask[b.ID] = cancel
-		go e.batchWork(ctx, b.ID)
+		e.collectBatchImpact(e.workCtx, id)
 	}
 }
 
-func (e *CoreEngine) batchWork(ctx context.Context, batchId int64) {
-	ticker := time.NewTicker(10 * time.Second)
-	defer func() {
-		defer ticker.Stop()
-		delete(e.batchTask, batchId)
-	}()
+// RunBatchOnce 同步执行一轮批次处理（Batch → ReleaseApp → Deployment），批次已完成时返回 true
+// batchWork 按固定间隔调用；集成测试可直接调用以逐轮驱动状态机
+func (e *CoreEngine) RunBatchOnce(ctx context.Context, batchId int64) bool {
+	ctx, span := tracing.Start(ctx, "core.RunBatchOnce", attribute.Int64("batch.id", batchId))
+	defer span.End()
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
+	// 0. 每次重新查询batch状态
+	b := model.Batch{}
+	if err := e.db.First(&b, batchId).Error; err != nil {
+		e.logger.Error(fmt.Sprintf("[BatchScaner] 查询批次失败: %v", err))
+	}
+	span.SetAttributes(attribute.Int64("project.id", b.ProjectID), attribute.String("batch.status", constants.BatchStatusToString(b.Status)))
 
-		case <-ticker.C:
-			// 0. 每次重新查询batch状态
-			b := model.Batch{}
-			if err := e.db.First(&b, batchId).Error; err != nil {
-				e.logger.Error(fmt.Sprintf("[BatchScaner] 查询批次失败: %v", err))
-			}
+	// 项目/批次被暂停：本轮不推进批次、发布应用与部署
+	if e.batchPaused(ctx, &b) {
+		return false
+	}
 
-			// 1. 执行Batch
-			e.batchSM.Process(ctx, &b)
+	// 1. 执行Batch（部署阶段超时先检查，超时自动取消后本轮不再推进）
+	e.checkBatchTimeout(ctx, &b)
+	start := time.Now()
+	e.batchSM.Process(ctx, &b)
+	e.stats.observe(smBatch, start, nil)
 
-			// 2. 执行 releases
-			var releases []model.ReleaseApp
-			if err := e.db.Where("batch_id = ? AND status > ?", b.ID, constants.ReleaseAppStatusPending).Find(&releases).Error; err != nil {
-				e.logger.Error("查询 ReleaseApp 失败", zap.Error(err))
-				continue
-			}
-			for i := range releases {
-				e.releaseSM.Process(ctx, &releases[i])
-			}
+	// 2. 执行 releases（回滚期间仅推进回滚中的应用，其余应用冻结在当前状态）
+	releaseQuery := e.db.Where("batch_id = ? AND status > ?", b.ID, constants.ReleaseAppStatusPending)
+	if b.Status == constants.BatchStatusRollingBack {
+		releaseQuery = releaseQuery.Scopes(batch.StatusIn(constants.ReleaseAppStatusRollbackCanTrigger))
+	}
+	var releases []model.ReleaseApp
+	if err := releaseQuery.Find(&releases).Error; err != nil {
+		e.logger.Error("查询 ReleaseApp 失败", zap.Error(err))
+		return false
+	}
+	for i := range releases {
+		start := time.Now()
+		e.releaseSM.Process(ctx, &releases[i])
+		e.stats.observe(smReleaseApp, start, nil)
+	}
 
-			// 3. 执行 deployments
-			e.scamDeployment(ctx, b.ID)
+	// 3. 执行 deployments
+	e.scamDeployment(ctx, b.ID)
 
-			// 4. completed -> cancel
-			if b.Status == constants.BatchStatusCompleted {
-				return
-			}
-		}
+	// 4. completed -> 采集部署影响后 cancel
+	if b.Status == constants.BatchStatusCompleted {
+		e.collectBatchImpact(ctx, b.ID)
+		return true
+	}
+	// 回滚结束（成功/失败）、已取消
+	if b.Status == constants.BatchStatusRolledBack || b.Status == constants.BatchStatusRollbackFailed || b.Status == constants.BatchStatusCancelled {
+		return true
 	}
+	return false
 }
 
 func (e *CoreEngine) scamDeployment(ctx context.Context, batchID int64) {
@@ -193,10 +436,97 @@ func (e *CoreEngine) scamDeployment(ctx context.Context, batchID int64) {
 		return
 	}
 
+	// 按 ReleaseApp 分组：同一应用的多集群 Deployment 并发执行，应用之间串行
+	groups := make(map[int64][]*model.Deployment)
+	var releaseIDs []int64
 	for i := range deps {
 		dep := &deps[i]
-		e.deploymentSM.Process(ctx, dep)
+		if _, ok := groups[dep.ReleaseID]; !ok {
+			releaseIDs = append(releaseIDs, dep.ReleaseID)
+		}
+		groups[dep.ReleaseID] = append(groups[dep.ReleaseID], dep)
+	}
+
+	for _, releaseID := range releaseIDs {
+		e.processReleaseDeployments(ctx, releaseID, groups[releaseID])
+	}
+}
+
+// processReleaseDeployments 有界并发处理单个应用的所有集群 Deployment，并汇总错误
+func (e *CoreEngine) processReleaseDeployments(ctx context.Context, releaseID int64, deps []*model.Deployment) {
+	if deps = e.gateClusterPause(ctx, deps); len(deps) == 0 {
+		return
+	}
+	if deps = e.gateConfigCharts(ctx, releaseID, deps); len(deps) == 0 {
+		return
+	}
+	if deps = e.gateRegionRollout(ctx, releaseID, deps); len(deps) == 0 {
+		return
+	}
+	deps, release := e.gateConcurrency(ctx, deps)
+	defer release()
+	if len(deps) == 0 {
+		return
+	}
+	errs := e.runDeployments(ctx, deps)
+
+	var lines, failedLines []string
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		line := fmt.Sprintf("[%s/%s] %v", deps[i].Env, deps[i].ClusterName, err)
+		lines = append(lines, line)
+		if errors.Is(err, deployment.ErrDeploymentFailed) {
+			failedLines = append(failedLines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	e.logger.Error(fmt.Sprintf("[Deployment] ReleaseApp:%d 本轮 %d/%d 个集群部署出错", releaseID, len(lines), len(deps)),
+		zap.Int64("batch_id", deps[0].BatchID), zap.Int64("release_id", releaseID), zap.Int64("app_id", deps[0].AppID),
+		zap.Strings("errors", lines))
+
+	// 部署失败写入 ReleaseApp.reason，便于在批次详情中查看
+	if len(failedLines) > 0 {
+		var release model.ReleaseApp
+		if err := e.db.WithContext(ctx).First(&release, releaseID).Error; err != nil {
+			e.logger.Error("查询 ReleaseApp 失败", zap.Int64("release_id", releaseID), zap.Error(err))
+			return
+		}
+		release.AppendReasonf("%d 个集群部署失败:\n%s", len(failedLines), strings.Join(failedLines, "\n"))
+		if err := e.db.WithContext(ctx).Model(&model.ReleaseApp{}).Where("id = ?", releaseID).
+			Update("reason", release.Reason).Error; err != nil {
+			e.logger.Error("更新 ReleaseApp 失败原因失败", zap.Int64("release_id", releaseID), zap.Error(err))
+		}
+	}
+}
+
+// runDeployments 以 clusterConcurrency 为上限并发执行 deps 的一轮状态机处理，返回与 deps 对应的错误
+func (e *CoreEngine) runDeployments(ctx context.Context, deps []*model.Deployment) []error {
+	errs := make([]error, len(deps))
+	sem := make(chan struct{}, e.clusterConcurrency)
+	var wg sync.WaitGroup
+	for i, dep := range deps {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, dep *model.Deployment) {
+			start := time.Now()
+			defer func() {
+				if r := recover(); r != nil {
+					errs[i] = fmt.Errorf("panic: %v", r)
+				}
+				e.stats.observe(smDeployment, start, errs[i])
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = e.deploymentSM.Process(ctx, dep)
+		}(i, dep)
 	}
+	wg.Wait()
+	return errs
 }
 
 // updateBatchBuilds 更新批次中的构建记录 todo: 是否需要转移
@@ -228,7 +558,7 @@ func (e *CoreEngine) updateBatchBuilds(batchID int64) error {
 
 		// 查询该应用的最新成功构建
 		var latestBuild model.Build
-		err := e.db.Where("app_id = ? AND build_status = ?",
+		err := e.db.Scopes(model.TrustedBuilds).Where("app_id = ? AND build_status = ?",
 			release.AppID, "success").
 			Order("created_at DESC").
 			First(&latestBuild).Error
@@ -0,0 +1,273 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"devops-cd/internal/core/common/metacache"
+	"devops-cd/internal/core/deployment"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/config"
+	"devops-cd/pkg/constants"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+)
+
+// waitTicketTTL 等待中的 Deployment 超过该时间未再被扫描到（已取消/被替代/批次暂停）即移出队列，避免阻塞后续排队
+const waitTicketTTL = time.Minute
+
+// deployLimits 部署并发上限（0 表示不限制）
+type deployLimits struct {
+	total      int // 全局
+	perProject int // 单项目
+	perCluster int // 单集群
+}
+
+func deployLimitsFromConfig(coreCfg *config.CoreConfig) deployLimits {
+	if coreCfg == nil {
+		return deployLimits{}
+	}
+	return deployLimits{
+		total:      coreCfg.Deploy.MaxDeployments,
+		perProject: coreCfg.Deploy.MaxDeploymentsPerProject,
+		perCluster: coreCfg.Deploy.MaxDeploymentsPerCluster,
+	}
+}
+
+func (l deployLimits) enabled() bool {
+	return l.total > 0 || l.perProject > 0 || l.perCluster > 0
+}
+
+// deployScope Deployment 占用并发额度的范围
+type deployScope struct {
+	projectID int64
+	cluster   string
+}
+
+// waitTicket 排队中的 Deployment
+type waitTicket struct {
+	deployScope
+	batchID  int64
+	seq      uint64    // 首次排队顺序
+	lastSeen time.Time // 最近一次被扫描到的时间
+	rank     int       // 在本批次排队中的位置（公平排序用）
+}
+
+// deployLimiter 部署并发控制：限制全局/项目/集群同时进行的 Deployment 数量
+//
+// 占用 = DB 中 running 及已入队执行的 Deployment + 已放行、本轮尚未处理完的 Pending（inflight）。
+// 额度不足的 Pending 进入等待队列，按「批次内排队位置 → 首次排队顺序」公平放行：
+// 各批次轮流获得额度，大批次不会独占集群，先排队的不会被后来者插队
+type deployLimiter struct {
+	limits deployLimits
+
+	mu       sync.Mutex
+	seq      uint64
+	inflight map[int64]deployScope
+	waiting  map[int64]*waitTicket
+}
+
+func newDeployLimiter(limits deployLimits) *deployLimiter {
+	return &deployLimiter{
+		limits:   limits,
+		inflight: make(map[int64]deployScope),
+		waiting:  make(map[int64]*waitTicket),
+	}
+}
+
+// deployUsage 各范围当前占用
+type deployUsage struct {
+	total     int
+	byProject map[int64]int
+	byCluster map[string]int
+}
+
+func (u *deployUsage) add(s deployScope, n int) {
+	u.total += n
+	u.byProject[s.projectID] += n
+	u.byCluster[s.cluster] += n
+}
+
+// blockedReason 按 usage 判断 s 是否还有额度，不足时返回等待原因
+func (l deployLimits) blockedReason(u *deployUsage, s deployScope) string {
+	switch {
+	case l.perCluster > 0 && u.byCluster[s.cluster] >= l.perCluster:
+		return fmt.Sprintf("等待并发额度：集群 %s 已有 %d 个部署进行中（上限 %d）", s.cluster, u.byCluster[s.cluster], l.perCluster)
+	case l.perProject > 0 && u.byProject[s.projectID] >= l.perProject:
+		return fmt.Sprintf("等待并发额度：项目已有 %d 个部署进行中（上限 %d）", u.byProject[s.projectID], l.perProject)
+	case l.total > 0 && u.total >= l.total:
+		return fmt.Sprintf("等待并发额度：全局已有 %d 个部署进行中（上限 %d）", u.total, l.total)
+	}
+	return ""
+}
+
+// gateConcurrency 过滤超出并发额度的 Pending Deployment（Running 只轮询状态，不受限制），被拦下的写入等待原因
+//
+// 返回放行列表及释放函数，调用方在本轮 Process 结束后调用释放 inflight 额度
+func (e *CoreEngine) gateConcurrency(ctx context.Context, deps []*model.Deployment) ([]*model.Deployment, func()) {
+	l := e.limiter
+	noop := func() {}
+	if l == nil || !l.limits.enabled() {
+		return deps, noop
+	}
+
+	var pending []*model.Deployment
+	for _, dep := range deps {
+		if dep.Status == constants.DeploymentStatusPending {
+			pending = append(pending, dep)
+		}
+	}
+	// 已入队执行的 Pending 已占用额度（计入 loadDeployUsage），直接放行跟踪任务状态
+	queued := e.queuedDeployments(ctx, pending)
+	pending = lo.Filter(pending, func(dep *model.Deployment, _ int) bool { return !queued[dep.ID] })
+	if len(pending) == 0 {
+		return deps, noop
+	}
+
+	// 按应用所属项目统计（临时部署的 Deployment 不属于批次）；deps 为同一应用的 Deployment
+	app, err := metacache.Application(ctx, e.db, deps[0].AppID)
+	if err != nil {
+		e.logger.Error("查询应用项目失败", zap.Int64("app_id", deps[0].AppID), zap.Error(err))
+		return nil, noop
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// 在锁内统计 running，保证已释放的 inflight 一定已计入 DB
+	usage, err := e.loadDeployUsage(ctx)
+	if err != nil {
+		e.logger.Error("统计进行中的 Deployment 失败", zap.Error(err))
+		return nil, noop
+	}
+	for _, s := range l.inflight {
+		usage.add(s, 1)
+	}
+
+	now := time.Now()
+	for _, dep := range pending {
+		t, ok := l.waiting[dep.ID]
+		if !ok {
+			l.seq++
+			t = &waitTicket{deployScope: deployScope{projectID: app.ProjectID, cluster: dep.ClusterName}, batchID: dep.BatchID, seq: l.seq}
+			l.waiting[dep.ID] = t
+		}
+		t.lastSeen = now
+	}
+	queue := l.fairQueue(now)
+
+	// 按公平顺序模拟分配：排在前面但额度不足的不占用额度，轮到本批次的 Pending 且有额度时放行
+	candidates := make(map[int64]bool, len(pending))
+	for _, dep := range pending {
+		candidates[dep.ID] = true
+	}
+	admitted := make(map[int64]deployScope)
+	reasons := make(map[int64]string)
+	for _, item := range queue {
+		if reason := l.limits.blockedReason(usage, item.deployScope); reason != "" {
+			if candidates[item.id] {
+				reasons[item.id] = reason
+			}
+			continue
+		}
+		// 其他批次的排队项预留额度，由其所在批次的扫描放行
+		usage.add(item.deployScope, 1)
+		if candidates[item.id] {
+			admitted[item.id] = item.deployScope
+		}
+	}
+
+	allowed := make([]*model.Deployment, 0, len(deps))
+	var released []int64
+	for _, dep := range deps {
+		if dep.Status != constants.DeploymentStatusPending || queued[dep.ID] {
+			allowed = append(allowed, dep)
+			continue
+		}
+		if s, ok := admitted[dep.ID]; ok {
+			delete(l.waiting, dep.ID)
+			l.inflight[dep.ID] = s
+			released = append(released, dep.ID)
+			allowed = append(allowed, dep)
+			continue
+		}
+		reason := reasons[dep.ID]
+		if reason == "" {
+			reason = "等待并发额度：排队中"
+		}
+		e.holdDeployment(ctx, dep, reason)
+	}
+
+	return allowed, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for _, id := range released {
+			delete(l.inflight, id)
+		}
+	}
+}
+
+type queueItem struct {
+	id int64
+	*waitTicket
+}
+
+// fairQueue 清理过期排队项，并按「批次内排队位置 → 首次排队顺序」排序
+func (l *deployLimiter) fairQueue(now time.Time) []queueItem {
+	byBatch := make(map[int64][]queueItem)
+	for id, t := range l.waiting {
+		if now.Sub(t.lastSeen) > waitTicketTTL {
+			delete(l.waiting, id)
+			continue
+		}
+		byBatch[t.batchID] = append(byBatch[t.batchID], queueItem{id: id, waitTicket: t})
+	}
+
+	queue := make([]queueItem, 0, len(l.waiting))
+	for _, items := range byBatch {
+		sort.Slice(items, func(i, j int) bool { return items[i].seq < items[j].seq })
+		for i := range items {
+			items[i].rank = i
+		}
+		queue = append(queue, items...)
+	}
+	sort.Slice(queue, func(i, j int) bool {
+		if queue[i].rank != queue[j].rank {
+			return queue[i].rank < queue[j].rank
+		}
+		return queue[i].seq < queue[j].seq
+	})
+	return queue
+}
+
+// loadDeployUsage 统计 DB 中 running 及已入队执行的 pending Deployment（按项目/集群）
+func (e *CoreEngine) loadDeployUsage(ctx context.Context) (*deployUsage, error) {
+	type row struct {
+		ProjectID int64
+		Cluster   string
+		Cnt       int
+	}
+	var rows []row
+	query := e.db.WithContext(ctx).Table(model.DeploymentTableName+" AS d").
+		Select("a.project_id, d.cluster, COUNT(*) AS cnt").
+		Joins("JOIN "+model.ApplicationTableName+" AS a ON a.id = d.app_id").
+		Where("d.status = ?", constants.DeploymentStatusRunning)
+	if e.jobs != nil {
+		query = query.Or("d.status = ? AND EXISTS (SELECT 1 FROM "+model.JobTableName+" AS j WHERE j.deployment_id = d.id AND j.type = ? AND j.status IN ?)",
+			constants.DeploymentStatusPending, deployment.JobTypeExecute, constants.JobActiveStatuses)
+	}
+	if err := query.
+		Group("a.project_id, d.cluster").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	usage := &deployUsage{byProject: make(map[int64]int), byCluster: make(map[string]int)}
+	for _, r := range rows {
+		usage.add(deployScope{projectID: r.ProjectID, cluster: r.Cluster}, r.Cnt)
+	}
+	return usage, nil
+}
@@ -0,0 +1,107 @@
+package deployment
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+
+	"gorm.io/gorm"
+)
+
+// ChartLocker 封板时为批次内的发布应用解析并固化 chart 版本/digest（chart lock）
+//
+// chart 版本模板按环境解析，pre 与 prod 可能得到不同版本（如模板为空取仓库最新版本）；
+// 封板时按首个部署环境（有预发布时为 pre）解析出确切版本与 digest，pre/prod 部署均使用锁定结果。
+type ChartLocker struct {
+	db *gorm.DB
+}
+
+func NewChartLocker(db *gorm.DB) *ChartLocker {
+	return &ChartLocker{db: db}
+}
+
+// LockCharts 解析批次内全部发布应用的 chart lock；任一应用解析失败时不写入并返回错误
+func (l *ChartLocker) LockCharts(ctx context.Context, batchID int64) error {
+	var releaseApps []model.ReleaseApp
+	if err := l.db.WithContext(ctx).Preload("Build").Where("batch_id = ?", batchID).Find(&releaseApps).Error; err != nil {
+		return fmt.Errorf("查询发布应用失败: %w", err)
+	}
+
+	locks := make(map[int64]*model.ChartLock, len(releaseApps))
+	for i := range releaseApps {
+		ra := &releaseApps[i]
+		if ra.Build == nil {
+			continue
+		}
+		lock, err := l.resolve(ctx, ra)
+		if err != nil {
+			return fmt.Errorf("应用 %d 解析 chart lock 失败: %w", ra.AppID, err)
+		}
+		locks[ra.ID] = lock
+	}
+
+	for id, lock := range locks {
+		if err := l.db.WithContext(ctx).Model(&model.ReleaseApp{}).Where("id = ?", id).
+			Update("chart_lock", lock).Error; err != nil {
+			return fmt.Errorf("记录 chart lock 失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// resolve 按首个部署环境的第一个集群解析各阶段 chart；无 helm/gitops 阶段时返回 nil
+func (l *ChartLocker) resolve(ctx context.Context, ra *model.ReleaseApp) (*model.ChartLock, error) {
+	var cfg model.AppEnvConfig
+	for _, env := range []string{constants.EnvTypePre, constants.EnvTypeProd} {
+		var configs []model.AppEnvConfig
+		if err := l.db.WithContext(ctx).Where("app_id = ? AND env = ? AND status = 1", ra.AppID, env).
+			Order("id").Find(&configs).Error; err != nil {
+			return nil, fmt.Errorf("查询应用环境配置失败: %w", err)
+		}
+		// manifest lock 生效时只取清单声明的集群
+		if configs = ra.EnvClusters(configs, env); len(configs) > 0 {
+			cfg = configs[0]
+			break
+		}
+	}
+	if cfg.ID == 0 {
+		return nil, nil
+	}
+
+	dep := &model.Deployment{AppID: ra.AppID, Env: cfg.Env, ClusterName: cfg.Cluster, Cluster: &model.Cluster{Name: cfg.Cluster}}
+	sc, err := loadStageContext(ctx, l.db, dep, ra.Build)
+	if err != nil {
+		return nil, err
+	}
+	payload := sc.helmPayload(dep)
+
+	lock := &model.ChartLock{
+		BuildID:  ra.Build.ID,
+		Env:      cfg.Env,
+		LockedAt: time.Now(),
+		Charts:   map[string]*model.ChartLockEntry{},
+	}
+	for _, stage := range []*model.StageSpecV1{sc.arts.ConfigChart, sc.arts.AppChart} {
+		if stage == nil || !stage.Enabled {
+			continue
+		}
+		if t := strings.TrimSpace(stage.Type); t != "helm" && t != "gitops" {
+			continue
+		}
+		name := sc.stageName(stage)
+		entry, err := helmDriver.New(l.db).ResolveChartLock(ctx, payload, stage, name)
+		if err != nil {
+			return nil, err
+		}
+		lock.Charts[name] = entry
+	}
+	if len(lock.Charts) == 0 {
+		return nil, nil
+	}
+	return lock, nil
+}
@@ -0,0 +1,143 @@
+package deployment
+
+import (
+	"context"
+	"fmt"
+
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/pkg/constants"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ConfigChartDrift 单个集群的 config chart 版本对账结果
+type ConfigChartDrift struct {
+	ClusterName        string
+	Namespace          string
+	ReleaseName        string
+	AppDeploymentID    int64
+	ConfigDeploymentID int64
+	ExpectedVersion    string // 按当前 app 生效版本渲染 chart_version_template 得到的期望版本
+	RecordedVersion    string // 最近一次成功的 config Deployment 记录的版本
+	LiveVersion        string // 集群中 helm release 的实际版本
+	Status             string // constants.ConfigChartDrift*
+	Message            string
+}
+
+// CheckConfigChartDrift 对比应用在 env 下各集群的 config chart 版本与 app chart 期望的版本
+// 以各集群最近一次成功的 app Deployment 为基准；未启用 config_chart 时返回空
+func CheckConfigChartDrift(ctx context.Context, db *gorm.DB, appID int64, env string) ([]*ConfigChartDrift, error) {
+	var appDeps []*model.Deployment
+	if err := db.WithContext(ctx).Preload("Cluster").
+		Where("app_id = ? AND env = ? AND kind = ? AND status = ?", appID, env, constants.DeploymentKindApp, constants.DeploymentStatusSuccess).
+		Order("id DESC").Find(&appDeps).Error; err != nil {
+		return nil, fmt.Errorf("query app deployments failed: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	results := make([]*ConfigChartDrift, 0)
+	for _, dep := range appDeps {
+		if seen[dep.ClusterName] {
+			continue
+		}
+		seen[dep.ClusterName] = true
+
+		item, err := checkClusterConfigChart(ctx, db, dep)
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			continue
+		}
+		if item.Status != constants.ConfigChartDriftOK {
+			logger.Warn("config chart 版本与 app chart 期望不一致",
+				zap.Int64("app_id", appID),
+				zap.String("env", env),
+				zap.String("cluster", item.ClusterName),
+				zap.String("expected", item.ExpectedVersion),
+				zap.String("recorded", item.RecordedVersion),
+				zap.String("live", item.LiveVersion),
+				zap.String("status", item.Status))
+		}
+		results = append(results, item)
+	}
+	return results, nil
+}
+
+func checkClusterConfigChart(ctx context.Context, db *gorm.DB, appDep *model.Deployment) (*ConfigChartDrift, error) {
+	build, err := deploymentBuild(ctx, db, appDep)
+	if err != nil {
+		return nil, err
+	}
+	sc, err := loadStageContext(ctx, db, appDep, build)
+	if err != nil {
+		return nil, err
+	}
+	if sc.arts.ConfigChart == nil || !sc.arts.ConfigChart.Enabled {
+		return nil, nil
+	}
+
+	item := &ConfigChartDrift{
+		ClusterName:     appDep.ClusterName,
+		Namespace:       sc.namespace,
+		AppDeploymentID: appDep.ID,
+	}
+	item.ReleaseName, item.ExpectedVersion = sc.stageRelease(sc.arts.ConfigChart)
+
+	var configDep model.Deployment
+	if err := db.WithContext(ctx).
+		Where("app_id = ? AND env = ? AND cluster = ? AND kind = ? AND status = ?",
+			appDep.AppID, appDep.Env, appDep.ClusterName, constants.DeploymentKindConfig, constants.DeploymentStatusSuccess).
+		Order("id DESC").Limit(1).Find(&configDep).Error; err != nil {
+		return nil, fmt.Errorf("query config deployment failed: %w", err)
+	}
+	if configDep.ID > 0 {
+		item.ConfigDeploymentID = configDep.ID
+		item.RecordedVersion = derefOr(configDep.ChartVersion, "")
+		if configDep.DeploymentName != "" {
+			item.ReleaseName = configDep.DeploymentName
+		}
+	}
+
+	if item.ReleaseName == "" || appDep.Cluster == nil {
+		item.Status = constants.ConfigChartDriftUnknown
+		item.Message = "无法确定 config chart release 或集群不存在"
+		return item, nil
+	}
+	kubeconfig, err := helmDriver.ClusterKubeconfig(appDep.Cluster)
+	if err != nil {
+		item.Status = constants.ConfigChartDriftUnknown
+		item.Message = err.Error()
+		return item, nil
+	}
+	live, found, err := helmDriver.GetReleaseChartVersion(kubeconfig, sc.namespace, item.ReleaseName)
+	if err != nil {
+		item.Status = constants.ConfigChartDriftUnknown
+		item.Message = "查询 helm release 失败: " + err.Error()
+		return item, nil
+	}
+	if !found {
+		item.Status = constants.ConfigChartDriftMissing
+		item.Message = fmt.Sprintf("config chart release %s 不存在", item.ReleaseName)
+		return item, nil
+	}
+	item.LiveVersion = live
+
+	switch {
+	case item.ExpectedVersion == "":
+		// 未配置 chart_version_template：无法判断期望版本，只要 release 存在即视为一致
+		item.Status = constants.ConfigChartDriftOK
+	case item.LiveVersion != item.ExpectedVersion:
+		item.Status = constants.ConfigChartDriftDrift
+		item.Message = fmt.Sprintf("集群版本 %s，期望 %s", item.LiveVersion, item.ExpectedVersion)
+	case item.RecordedVersion != "" && item.RecordedVersion != item.ExpectedVersion:
+		item.Status = constants.ConfigChartDriftDrift
+		item.Message = fmt.Sprintf("最近部署记录版本 %s，期望 %s", item.RecordedVersion, item.ExpectedVersion)
+	default:
+		item.Status = constants.ConfigChartDriftOK
+	}
+	return item, nil
+}
@@ -0,0 +1,159 @@
+package deployment
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"devops-cd/internal/core/deployment/plan/drivers"
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// errDiffAckRequired prod diff 涉及受保护资源，等待人工确认
+var errDiffAckRequired = errors.New("diff 等待确认")
+
+// manifestDiffer 支持部署前 diff 的 driver（目前仅 helm）
+type manifestDiffer interface {
+	Diff(ctx context.Context, namespace string, p *helmDriver.ExecutePayload) (*helmDriver.ManifestDiff, error)
+}
+
+// diffAckPending deployment 已有等待确认的 diff（确认前不重复渲染）
+func (sm *StateMachine) diffAckPending(ctx context.Context, deploymentID int64) (*model.DeploymentDiff, error) {
+	var diff model.DeploymentDiff
+	if err := sm.db.WithContext(ctx).Where("deployment_id = ?", deploymentID).Limit(1).Find(&diff).Error; err != nil {
+		return nil, fmt.Errorf("query deployment diff failed: %w", err)
+	}
+	if diff.ID == 0 || !diff.PendingAck() {
+		return nil, nil
+	}
+	return &diff, nil
+}
+
+// refreshDiff prod main 阶段执行前计算并保存 manifest diff
+// 开启确认且 diff 涉及受保护资源（或 diff 计算失败）时，未确认当前 digest 则返回 errDiffAckRequired
+func (sm *StateMachine) refreshDiff(ctx context.Context, dep *model.Deployment, dv drivers.Driver, namespace, releaseName string, payload *helmDriver.ExecutePayload) error {
+	differ, ok := dv.(manifestDiffer)
+	if !ok {
+		return nil
+	}
+
+	var existing model.DeploymentDiff
+	if err := sm.db.WithContext(ctx).Where("deployment_id = ?", dep.ID).Limit(1).Find(&existing).Error; err != nil {
+		return fmt.Errorf("query deployment diff failed: %w", err)
+	}
+
+	diff := model.DeploymentDiff{
+		DeploymentID: dep.ID,
+		Namespace:    namespace,
+		ReleaseName:  releaseName,
+		Changes:      model.ManifestChanges{},
+	}
+	res, err := differ.Diff(ctx, namespace, payload)
+	if err != nil {
+		msg := err.Error()
+		sum := sha256.Sum256([]byte(msg))
+		diff.Status = constants.DeploymentDiffStatusFailed
+		diff.Digest = hex.EncodeToString(sum[:])
+		diff.ErrorMessage = &msg
+		diff.RequiresAck = sm.diffAck
+		sm.logger.Warn(fmt.Sprintf("[Deployment SM] Batch:%v ReleaseApp:%v %s/%s 计算 diff 失败", dep.BatchID, dep.ReleaseID, dep.Env, dep.ClusterName),
+			zap.Int64("deployment_id", dep.ID), zap.Error(err))
+	} else {
+		diff.Status = constants.DeploymentDiffStatusReady
+		diff.BaseRevision = res.BaseRevision
+		diff.Changes = res.Changes
+		diff.Diff = res.Diff
+		diff.Digest = res.Digest()
+		diff.RequiresAck = sm.diffAck && res.TouchesProtected()
+	}
+
+	// 保留已有确认：digest 未变化时确认仍然有效
+	if existing.ID != 0 {
+		diff.ID = existing.ID
+		diff.CreatedAt = existing.CreatedAt
+		diff.AckedDigest = existing.AckedDigest
+		diff.AckedBy = existing.AckedBy
+		diff.AckedAt = existing.AckedAt
+	}
+	if err := sm.db.WithContext(ctx).Save(&diff).Error; err != nil {
+		return fmt.Errorf("save deployment diff failed: %w", err)
+	}
+
+	if diff.PendingAck() {
+		return errDiffAckRequired
+	}
+	return nil
+}
+
+// diffAckReason 等待确认时写入 deployment.error_message 的说明
+func diffAckReason(diff *model.DeploymentDiff) string {
+	if diff == nil {
+		return "prod diff 等待确认"
+	}
+	if diff.Status == constants.DeploymentDiffStatusFailed {
+		return "prod diff 计算失败，确认后继续部署"
+	}
+	kinds := make(map[string]bool)
+	for _, c := range diff.Changes {
+		if c.Protected {
+			kinds[c.Kind] = true
+		}
+	}
+	names := make([]string, 0, len(kinds))
+	for k := range kinds {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("prod diff 涉及受保护资源（%s），确认后继续部署", strings.Join(names, ", "))
+}
+
+// DiffPreview 按需计算的 manifest diff（不落库、不影响确认状态）
+type DiffPreview struct {
+	*helmDriver.ManifestDiff
+	Namespace   string
+	ReleaseName string
+}
+
+// PreviewDiff 以 dry-run 方式渲染 deployment 待部署的 chart+values，并与集群中当前 release 对比（仅 helm driver）
+//
+// 与 prod Pending 阶段的 refreshDiff 相同的渲染规则，可用于任意环境、审批前预览变更
+func PreviewDiff(ctx context.Context, db *gorm.DB, deploymentID int64) (*DiffPreview, error) {
+	var dep model.Deployment
+	if err := db.WithContext(ctx).Preload("Cluster").First(&dep, deploymentID).Error; err != nil {
+		return nil, fmt.Errorf("deployment 不存在: %w", err)
+	}
+	if !dep.Cluster.HasCredentials() {
+		return nil, fmt.Errorf("集群 %s 未配置连接凭据", dep.ClusterName)
+	}
+	build, err := deploymentBuild(ctx, db, &dep)
+	if err != nil {
+		return nil, err
+	}
+
+	sc, err := loadStageContext(ctx, db, &dep, build)
+	if err != nil {
+		return nil, err
+	}
+	if t := strings.TrimSpace(sc.arts.AppChart.Type); t != "helm" {
+		return nil, fmt.Errorf("driver %s 不支持 diff 预览（仅 helm）", t)
+	}
+
+	res, err := helmDriver.New(db).Diff(ctx, sc.namespace, sc.helmPayload(&dep))
+	if err != nil {
+		return nil, err
+	}
+	releaseName, _ := sc.stageRelease(sc.arts.AppChart)
+	if releaseName == "" {
+		releaseName = sc.app.Name
+	}
+	return &DiffPreview{ManifestDiff: res, Namespace: sc.namespace, ReleaseName: releaseName}, nil
+}
@@ -0,0 +1,55 @@
+package deployment
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+
+	"gorm.io/gorm"
+)
+
+// DurationHistory 计算历史耗时时取最近成功部署的条数
+const DurationHistory = 20
+
+// DurationStats 应用在某环境的历史部署耗时（started_at → finished_at，含部署后验证）
+type DurationStats struct {
+	Samples int
+	Avg     time.Duration
+	P95     time.Duration
+}
+
+// DurationBaseline 统计应用在 env 下最近 history 次成功的 app Deployment 耗时，没有样本时 Samples 为 0
+func DurationBaseline(ctx context.Context, db *gorm.DB, appID int64, env string, history int) (*DurationStats, error) {
+	var rows []struct {
+		StartedAt  time.Time
+		FinishedAt time.Time
+	}
+	if err := db.WithContext(ctx).Model(&model.Deployment{}).Select("started_at", "finished_at").
+		Where("app_id = ? AND env = ? AND kind = ? AND status = ?", appID, env, constants.DeploymentKindApp, constants.DeploymentStatusSuccess).
+		Where("started_at IS NOT NULL AND finished_at IS NOT NULL").
+		Order("id DESC").Limit(history).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	durations := make([]time.Duration, 0, len(rows))
+	var total time.Duration
+	for _, r := range rows {
+		if d := r.FinishedAt.Sub(r.StartedAt); d >= 0 {
+			durations = append(durations, d)
+			total += d
+		}
+	}
+	stats := &DurationStats{Samples: len(durations)}
+	if len(durations) == 0 {
+		return stats, nil
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	stats.Avg = total / time.Duration(len(durations))
+	// nearest-rank
+	stats.P95 = durations[int(math.Ceil(0.95*float64(len(durations))))-1]
+	return stats, nil
+}
@@ -0,0 +1,43 @@
+package deployment
+
+import (
+	"context"
+
+	"devops-cd/internal/model"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// recordEvent 写入 Deployment 时间线事件；写入失败只记录日志，不影响部署流程
+func (sm *StateMachine) recordEvent(ctx context.Context, dep *model.Deployment, eventType, message string) {
+	if err := sm.db.WithContext(ctx).Create(model.NewDeploymentEvent(dep, eventType, message)).Error; err != nil {
+		sm.logger.Warn("写入 Deployment 时间线事件失败", zap.Int64("deployment_id", dep.ID), zap.String("type", eventType), zap.Error(err))
+	}
+}
+
+// progressRecorder driver 执行进度（chart 渲染完成、开始部署）写入 dep 的时间线
+func (sm *StateMachine) progressRecorder(ctx context.Context, dep *model.Deployment) func(event, message string) {
+	return func(event, message string) {
+		sm.recordEvent(ctx, dep, event, message)
+	}
+}
+
+// recordIfChanged message 与同类型最近一次记录不同时才写入（就绪检查、部署后验证），避免每轮轮询重复记录
+func (sm *StateMachine) recordIfChanged(ctx context.Context, dep *model.Deployment, eventType, message string) {
+	if message == "" {
+		return
+	}
+	var last model.DeploymentEvent
+	err := sm.db.WithContext(ctx).Select("message").
+		Where("deployment_id = ? AND type = ?", dep.ID, eventType).
+		Order("id DESC").Take(&last).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		sm.logger.Warn("查询 Deployment 时间线事件失败", zap.Int64("deployment_id", dep.ID), zap.String("type", eventType), zap.Error(err))
+		return
+	}
+	if err == nil && last.Message != nil && *last.Message == message {
+		return
+	}
+	sm.recordEvent(ctx, dep, eventType, message)
+}
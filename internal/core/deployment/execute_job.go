@@ -0,0 +1,122 @@
+package deployment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"devops-cd/internal/core/jobs"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/tracing"
+	"devops-cd/pkg/constants"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// JobTypeExecute Deployment 执行任务：preflight、Chart/制品拉取、pre/main 阶段 install/upgrade
+const JobTypeExecute = "deployment.execute"
+
+// executePayload 任务参数；retry_count 区分同一 Deployment 的多次执行（重试后为新任务）
+type executePayload struct {
+	DeploymentID int64 `json:"deployment_id"`
+	RetryCount   int   `json:"retry_count"`
+}
+
+func executeJobKey(dep *model.Deployment) string {
+	return fmt.Sprintf("%s:%d:%d", JobTypeExecute, dep.ID, dep.RetryCount)
+}
+
+// enqueueExecute 为 Pending 的 Deployment 入队执行任务，任务未结束时保持 pending
+//
+// 执行结果由 worker 直接写回（running / failed）；任务进入死信时在这里置为 failed，交给发布应用的重试逻辑处理
+func (sm *StateMachine) enqueueExecute(ctx context.Context, dep *model.Deployment, now time.Time) (string, func(*model.Deployment), error) {
+	key := executeJobKey(dep)
+	job, err := sm.jobs.Latest(ctx, key)
+	if err != nil {
+		return "", nil, err
+	}
+	if job != nil {
+		switch job.Status {
+		case constants.JobStatusPending, constants.JobStatusRunning:
+			return dep.Status, holdWithReason(dep, executeHoldReason(job)), nil
+		case constants.JobStatusDead:
+			return constants.DeploymentStatusFailed, func(d *model.Deployment) {
+				setErrorMessage(d, fmt.Sprintf("部署任务 #%d 执行失败: %s", job.ID, job.LastError))
+				d.RetryCount++
+				if d.StartedAt == nil {
+					d.StartedAt = &now
+				}
+				d.FinishedAt = &now
+				d.LastFailedAt = &now
+			}, nil
+		}
+		// succeeded 但仍为 pending（如等待 diff 确认后已确认）：重新入队
+	}
+
+	job, _, err = sm.jobs.Enqueue(ctx, &jobs.EnqueueRequest{
+		Type:         JobTypeExecute,
+		Key:          key,
+		Payload:      executePayload{DeploymentID: dep.ID, RetryCount: dep.RetryCount},
+		BatchID:      &dep.BatchID,
+		DeploymentID: &dep.ID,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return dep.Status, holdWithReason(dep, executeHoldReason(job)), nil
+}
+
+func executeHoldReason(job *model.Job) string {
+	if job.Status == constants.JobStatusRunning {
+		return fmt.Sprintf("部署任务 #%d 执行中", job.ID)
+	}
+	if job.Attempts > 0 && job.LastError != "" {
+		return fmt.Sprintf("部署任务 #%d 等待重试（第 %d 次失败: %s）", job.ID, job.Attempts, job.LastError)
+	}
+	return fmt.Sprintf("部署任务 #%d 排队中", job.ID)
+}
+
+// runExecuteJob worker 执行 Pending Deployment 的 pre/main 阶段并写回结果
+//
+// 部署本身的失败写入 Deployment（failed），不作为任务失败重试；只有读写 DB 失败时返回错误由队列重试
+func (sm *StateMachine) runExecuteJob(ctx context.Context, job *model.Job) (err error) {
+	var p executePayload
+	if err := json.Unmarshal(job.Payload, &p); err != nil {
+		return fmt.Errorf("解析任务参数失败: %w", err)
+	}
+
+	var dep model.Deployment
+	if err := sm.db.WithContext(ctx).First(&dep, p.DeploymentID).Error; err != nil {
+		return fmt.Errorf("查询 Deployment 失败: %w", err)
+	}
+	// 已被取消/重试或由其他任务推进
+	if dep.Status != constants.DeploymentStatusPending || dep.RetryCount != p.RetryCount {
+		sm.logger.Info(fmt.Sprintf("[Deployment SM] Deployment:%v 状态已变为 %v，跳过部署任务 #%d", dep.ID, dep.Status, job.ID),
+			zap.Int64("deployment_id", dep.ID), zap.Int64("job_id", job.ID))
+		return nil
+	}
+
+	ctx, span := tracing.Start(ctx, "deployment.execute",
+		attribute.Int64("deployment.id", dep.ID), attribute.Int64("batch.id", dep.BatchID), attribute.Int64("release_app.id", dep.ReleaseID),
+		attribute.String("deployment.env", dep.Env), attribute.String("deployment.cluster", dep.ClusterName), attribute.Int64("job.id", job.ID))
+	defer func() { tracing.End(span, err) }()
+
+	startedAt := time.Now()
+	res, execErr := sm.executeStages(ctx, dep.ID)
+	nextStatus, updateFunc, err := sm.stageOutcome(ctx, &dep, res, execErr, startedAt)
+	if err != nil {
+		return err
+	}
+
+	// 结果写回不随 ctx 取消（停止时被中断的执行写入等待原因，重启后重新入队）
+	applied, err := sm.updateIf(context.WithoutCancel(ctx), dep.ID, constants.DeploymentStatusPending, nextStatus, updateFunc)
+	if err != nil {
+		return err
+	}
+	if applied && nextStatus != dep.Status {
+		sm.notifyStatusChange(&dep, nextStatus, updateFunc)
+	}
+	return nil
+}
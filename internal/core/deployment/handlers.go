@@ -2,9 +2,9 @@ package deployment
 
 import (
 	"context"
-	"devops-cd/internal/core/deployment/helpers/tpl"
 	"devops-cd/internal/core/deployment/plan/drivers"
 	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -38,150 +38,290 @@ func (sm *StateMachine) registerHandlers() {
 func (sm *StateMachine) HandlePending(ctx context.Context, dep *model.Deployment) (string, func(*model.Deployment), error) {
 	startedAt := time.Now()
 
-	// 1. 执行 pre/main 两阶段（当前按同步闭环执行，避免引入 stage 落库字段）
-	namespace, deploymentName, mainDriverType, err := sm.executeStages(ctx, dep.ID)
+	// 0. 灰度阶段未放行：保持 pending，等待 promote
+	if reason, err := sm.rolloutHoldReason(ctx, dep); err != nil {
+		return "", nil, err
+	} else if reason != "" {
+		return dep.Status, holdWithReason(dep, reason), nil
+	}
+
+	// 0.1 prod diff 等待确认：保持 pending，不重复渲染
+	if diff, err := sm.diffAckPending(ctx, dep.ID); err != nil {
+		return "", nil, err
+	} else if diff != nil {
+		return dep.Status, holdWithReason(dep, diffAckReason(diff)), nil
+	}
+
+	// 0.2 失败重试仍在退避期：保持 pending
+	if reason := sm.retryHoldReason(dep, startedAt); reason != "" {
+		return dep.Status, holdWithReason(dep, reason), nil
+	}
+
+	// 1. 配置了任务队列时由 worker 异步执行，这里只负责入队与跟踪任务状态
+	if sm.jobs != nil {
+		return sm.enqueueExecute(ctx, dep, startedAt)
+	}
+
+	// 2. 执行 pre/main 两阶段（同步闭环执行，避免引入 stage 落库字段）；kind=config 只执行 pre
+	res, err := sm.executeStages(ctx, dep.ID)
+	return sm.stageOutcome(ctx, dep, res, err, startedAt)
+}
+
+// interruptedReason 执行被中断的 Deployment 的等待原因
+const interruptedReason = "服务停止，部署执行被中断，将重新执行"
+
+// stageOutcome 根据 executeStages 的结果决定 Pending 的去向：等待 diff 确认 / failed / running
+func (sm *StateMachine) stageOutcome(ctx context.Context, dep *model.Deployment, res *stageResult, err error, startedAt time.Time) (string, func(*model.Deployment), error) {
+	if errors.Is(err, errDiffAckRequired) {
+		diff, qerr := sm.diffAckPending(ctx, dep.ID)
+		if qerr != nil {
+			return "", nil, qerr
+		}
+		return dep.Status, holdWithReason(dep, diffAckReason(diff)), nil
+	}
+	// 服务停止 / 失去主节点身份时被取消：保持 pending 且不计入重试，由重启后的（或新的主节点）重新执行
+	if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+		sm.logger.Warn(fmt.Sprintf("[Deployment SM] Batch:%v ReleaseApp:%v Deployment:%v 执行被中断，保持 pending: %v", dep.BatchID, dep.ReleaseID, dep.ID, err),
+			zap.Int64("deployment_id", dep.ID))
+		return dep.Status, holdWithReason(dep, interruptedReason), nil
+	}
 	if err != nil {
 		return constants.DeploymentStatusFailed, func(d *model.Deployment) {
+			if res != nil {
+				res.dryRun.apply(d)
+			}
 			setErrorMessage(d, err.Error())
 			d.RetryCount++
 			d.StartedAt = &startedAt
 			finishedAt := time.Now()
 			d.FinishedAt = &finishedAt
+			d.LastFailedAt = &finishedAt
 		}, nil
 	}
 
-	// 2. pre 已同步完成，main 已触发：进入 Running（FinishedAt 不应在此处写入）
+	// pre 已同步完成，main 已触发：进入 Running（FinishedAt 不应在此处写入）
 	return constants.DeploymentStatusRunning, func(d *model.Deployment) {
-		d.Namespace = namespace
-		d.DeploymentName = deploymentName
-		mt := mainDriverType
-		d.DriverType = &mt
+		d.Namespace = res.namespace
+		d.DeploymentName = res.deploymentName
+		dt := res.driverType
+		d.DriverType = &dt
+		d.ChartVersion = nil
+		if res.chartVersion != "" {
+			cv := res.chartVersion
+			d.ChartVersion = &cv
+		}
+		res.dryRun.apply(d)
 		d.StartedAt = &startedAt
 		d.FinishedAt = nil
+		d.VerifyStartedAt = nil
 		setErrorMessage(d, "")
 	}, nil
 }
 
-// executeStages:
-// - pre 阶段（config_chart）同步执行，失败直接返回错误
-// - main 阶段（app_chart）触发一次 Deploy，并返回 main driver_type（供 Running 阶段 CheckStatus 使用）
-func (sm *StateMachine) executeStages(ctx context.Context, deploymentID int64) (namespace string, deploymentName string, mainDriverType string, err error) {
-	var dep model.Deployment
-	if err := sm.db.WithContext(ctx).Where("id = ?", deploymentID).Preload("Cluster").First(&dep).Error; err != nil {
-		return "", "", "", err
+// rolloutHoldReason Deployment 所在灰度阶段尚未放行时返回等待原因
+func (sm *StateMachine) rolloutHoldReason(ctx context.Context, dep *model.Deployment) (string, error) {
+	if dep.RolloutStage <= 1 {
+		return "", nil
 	}
-
-	// 加载 ReleaseApp / Build
 	var rel model.ReleaseApp
-	if err := sm.db.WithContext(ctx).Preload("Build").First(&rel, dep.ReleaseID).Error; err != nil {
-		return "", "", "", fmt.Errorf("load release_app failed: %w", err)
+	if err := sm.db.WithContext(ctx).Select("id", "rollout_stage", "rollout_stages").First(&rel, dep.ReleaseID).Error; err != nil {
+		return "", fmt.Errorf("load release_app failed: %w", err)
 	}
-	if rel.Build == nil {
-		return "", "", "", fmt.Errorf("load Build failed when load ReleaseApp")
+	if dep.RolloutStage <= rel.RolloutStage {
+		return "", nil
 	}
+	return fmt.Sprintf("灰度阶段 %d/%d 等待 promote（当前阶段 %d）", dep.RolloutStage, rel.RolloutStages, rel.RolloutStage), nil
+}
 
-	// Load App / ProjectEnvConfig
-	var app model.Application
-	if err := sm.db.WithContext(ctx).Preload("Project").Preload("Repository").First(&app, dep.AppID).Error; err != nil {
-		return "", "", "", fmt.Errorf("load app failed: %w", err)
+// holdWithReason 保持 pending 并记录等待原因（原因未变化时不更新）
+func holdWithReason(dep *model.Deployment, reason string) func(*model.Deployment) {
+	if dep.ErrorMessage != nil && *dep.ErrorMessage == reason {
+		return nil
 	}
-	var projectCfg model.ProjectEnvConfig
-	if err := sm.db.WithContext(ctx).Where("project_id = ? AND env = ?", app.ProjectID, dep.Env).First(&projectCfg).Error; err != nil {
-		return "", "", "", fmt.Errorf("load project_env_config failed: %w", err)
+	return func(d *model.Deployment) {
+		setErrorMessage(d, reason)
 	}
+}
 
-	// repo.app_count：当前 project 下，该 repo 关联的应用数（排除 deleted）
-	var repoAppCount int64
-	if err := sm.db.WithContext(ctx).
-		Model(&model.Application{}).
-		Where("project_id = ? AND repo_id = ?", app.ProjectID, app.RepoID).
-		Count(&repoAppCount).Error; err != nil {
-		return "", "", "", fmt.Errorf("count repo apps failed: %w", err)
-	}
-	tplOpts := &tpl.ContextOptions{
-		Repo:         app.Repository,
-		RepoAppCount: &repoAppCount,
+// stageResult Pending 阶段执行结果，回填到 deployment 供 Running 阶段 CheckStatus 使用
+type stageResult struct {
+	namespace      string
+	deploymentName string // 被检查状态的 release 名称（app: app_chart；config: config_chart）
+	driverType     string
+	chartVersion   string
+	dryRun         *dryRunOutcome // prod 部署前 server dry-run 结果（未执行时为 nil）
+}
+
+// executeStages:
+// - pre 阶段（config_chart）同步执行，失败直接返回错误；已有独立的 config Deployment 时由其负责，这里跳过
+// - main 阶段（app_chart）触发一次 Deploy，并返回 main driver_type（供 Running 阶段 CheckStatus 使用）
+// - kind=config 的 Deployment 只执行 pre 阶段，并以 config chart release 作为状态检查对象
+func (sm *StateMachine) executeStages(ctx context.Context, deploymentID int64) (*stageResult, error) {
+	var dep model.Deployment
+	if err := loadDeploymentWithCluster(ctx, sm.db, deploymentID, &dep); err != nil {
+		return nil, err
 	}
 
-	// 解析 artifacts_json
-	arts, err := model.LoadArtifactsV1(projectCfg.ArtifactsJSON)
+	// 加载 Build（自动回滚的 Deployment 使用发布前版本的构建）
+	build, err := deploymentBuild(ctx, sm.db, &dep)
 	if err != nil {
-		return "", "", "", err
-	}
-	if arts.AppChart == nil || !arts.AppChart.Enabled {
-		return "", "", "", fmt.Errorf("app_chart 未启用")
-	}
-	if strings.TrimSpace(arts.AppChart.Type) == "" {
-		return "", "", "", fmt.Errorf("app_chart.type 为空")
-	}
-	if arts.ConfigChart != nil && arts.ConfigChart.Enabled && strings.TrimSpace(arts.ConfigChart.Type) == "" {
-		return "", "", "", fmt.Errorf("config_chart.type 为空")
+		return nil, err
 	}
 
-	// 1) namespace：由 deployment 层统一计算（driver 外部），并传入各 stage
-	nsTpl := strings.TrimSpace(arts.NamespaceTemplate)
-	if nsTpl == "" {
-		return "", "", "", fmt.Errorf("namespace_template 为空")
-	}
-	renderCtx := tpl.RenderTemplateContext(&app, rel.Build, dep.Env, dep.ClusterName, tplOpts)
-	ns, err := tpl.ParseTemplate(nsTpl, renderCtx)
+	sc, err := loadStageContext(ctx, sm.db, &dep, build)
 	if err != nil {
-		return "", "", "", fmt.Errorf("namespace_template 解析失败: %w", err)
-	}
-	if strings.TrimSpace(ns) == "" {
-		return "", "", "", fmt.Errorf("namespace_template 解析结果为空")
+		return nil, err
 	}
+	arts := sc.arts
+	ns := sc.namespace
+	result := &stageResult{namespace: ns}
 
-	helmPayload := &helmDriver.ExecutePayload{
-		Deployment: &dep,
-		App:        &app,
-		Build:      rel.Build,
-		ProjectCfg: &projectCfg,
-		Artifacts:  arts,
-		TplOptions: tplOpts,
+	// 同一批次首次部署到该集群时，先做连通性预检
+	if err := sm.preflightIfFirst(ctx, &dep, ns, arts, build); err != nil {
+		return result, err
 	}
 
+	helmPayload := sc.helmPayload(&dep)
+
 	// 2) Pre: config chart
-	if arts.ConfigChart != nil && arts.ConfigChart.Enabled {
-		dv, ok := sm.registry.Get(arts.ConfigChart.Type)
+	if dep.Kind == constants.DeploymentKindConfig {
+		if arts.ConfigChart == nil || !arts.ConfigChart.Enabled {
+			return result, fmt.Errorf("config_chart 未启用")
+		}
+		preType := strings.TrimSpace(arts.ConfigChart.Type)
+		dv, ok := sm.registry.Get(preType)
 		if !ok {
-			return "", "", "", fmt.Errorf("driver not found: %s", arts.ConfigChart.Type)
+			return result, fmt.Errorf("driver not found: %s", preType)
+		}
+		result.driverType = preType
+		result.deploymentName, result.chartVersion = sc.stageRelease(arts.ConfigChart)
+		if result.deploymentName == "" {
+			return result, fmt.Errorf("config_chart: release_name_template 为空或解析失败")
+		}
+		if _, err = dv.Execute(ctx, &drivers.ExecuteRequest{Stage: drivers.StagePre, Namespace: ns, Payload: helmPayload, Progress: sm.progressRecorder(ctx, &dep)}); err != nil {
+			return result, err
 		}
+		return result, nil
+	}
 
-		if _, err = dv.Execute(ctx, &drivers.ExecuteRequest{Stage: drivers.StagePre, Namespace: ns, Payload: helmPayload}); err != nil {
-			return ns, "", "", err
+	if arts.ConfigChart != nil && arts.ConfigChart.Enabled {
+		configDep, err := currentConfigDeployment(ctx, sm.db, &dep)
+		if err != nil {
+			return result, err
+		}
+		if configDep == nil {
+			// 兼容：未拆分 config Deployment 的旧记录，仍随 app 同步执行
+			dv, ok := sm.registry.Get(arts.ConfigChart.Type)
+			if !ok {
+				return result, fmt.Errorf("driver not found: %s", arts.ConfigChart.Type)
+			}
+			if _, err = dv.Execute(ctx, &drivers.ExecuteRequest{Stage: drivers.StagePre, Namespace: ns, Payload: helmPayload, Progress: sm.progressRecorder(ctx, &dep)}); err != nil {
+				return result, err
+			}
+		} else if _, expected := sc.stageRelease(arts.ConfigChart); expected != "" && (configDep.ChartVersion == nil || *configDep.ChartVersion != expected) {
+			sm.logger.Warn(fmt.Sprintf("[Deployment SM] Batch:%v ReleaseApp:%v %s/%s config chart 版本 %s 与期望 %s 不一致",
+				dep.BatchID, dep.ReleaseID, dep.Env, dep.ClusterName, derefOr(configDep.ChartVersion, "-"), expected),
+				zap.Int64("deployment_id", dep.ID), zap.Int64("config_deployment_id", configDep.ID))
 		}
 	}
+
 	// 3) Main: app chart
 	mainType := strings.TrimSpace(arts.AppChart.Type)
 	dv, ok := sm.registry.Get(mainType)
 	if !ok {
-		return "", "", "", fmt.Errorf("driver not found: %s", mainType)
-	}
-	if _, err = dv.Execute(ctx, &drivers.ExecuteRequest{Stage: drivers.StageMain, Namespace: ns, Payload: helmPayload}); err != nil {
-		return ns, "", mainType, err
+		return result, fmt.Errorf("driver not found: %s", mainType)
 	}
+	result.driverType = mainType
 
 	// main 的 deployment_name：由 deployment 层根据 app_chart.data.release_name_template 计算并回填
 	// 当前先复用 helm driver 的 config 解析（因为 driver_type=helm）
-	deploymentName = app.Name
-	if mainType == "helm" && arts.AppChart != nil {
-		if cfg, err2 := helmDriver.DecodeConfig(arts.AppChart.Data); err2 == nil && strings.TrimSpace(cfg.ReleaseNameTemplate) != "" {
-			if dn, err3 := tpl.ParseTemplate(cfg.ReleaseNameTemplate, renderCtx); err3 == nil && strings.TrimSpace(dn) != "" {
-				deploymentName = dn
+	result.deploymentName = sc.app.Name
+	releaseName, chartVersion := sc.stageRelease(arts.AppChart)
+	if releaseName != "" {
+		result.deploymentName = releaseName
+	}
+	result.chartVersion = chartVersion
+
+	// prod 部署前 diff（对比集群中当前 release revision）
+	if dep.Env == constants.EnvTypeProd {
+		if err := sm.refreshDiff(ctx, &dep, dv, ns, result.deploymentName, helmPayload); err != nil {
+			return result, err
+		}
+		// 真正 install/upgrade 前 server-side dry-run，准入拒绝/不可变字段冲突直接失败
+		if sm.clusterChecks {
+			if result.dryRun, err = sm.serverDryRun(ctx, &dep, dv, ns, helmPayload); err != nil {
+				return result, err
 			}
 		}
 	}
-	return ns, deploymentName, mainType, nil
+
+	// 部署前资源基线（用于批次完成后的影响统计，临时部署不统计）
+	if mainType == "helm" && sm.clusterChecks && dep.AdhocID == nil {
+		sm.captureImpactBefore(ctx, &dep, ns, result.deploymentName)
+	}
+
+	if _, err = dv.Execute(ctx, &drivers.ExecuteRequest{Stage: drivers.StageMain, Namespace: ns, Payload: helmPayload, Progress: sm.progressRecorder(ctx, &dep)}); err != nil {
+		return result, err
+	}
+	return result, nil
 }
 
-// HandleRunning handle Running → Success / Failed
+// preflightIfFirst 批次（或临时部署）内该集群尚无已启动的 deployment 时执行 preflight，未通过则返回错误
+func (sm *StateMachine) preflightIfFirst(ctx context.Context, dep *model.Deployment, namespace string, arts *model.ArtifactsV1, build *model.Build) error {
+	if !sm.clusterChecks {
+		return nil
+	}
+	// 临时部署按临时部署记录判断（batch_id 均为 0）
+	query := sm.db.WithContext(ctx).Model(&model.Deployment{}).Where("batch_id = ?", dep.BatchID)
+	if dep.AdhocID != nil {
+		query = query.Where("adhoc_id = ?", *dep.AdhocID)
+	}
+	var started int64
+	if err := query.Where("cluster = ? AND id <> ? AND started_at IS NOT NULL", dep.ClusterName, dep.ID).
+		Count(&started).Error; err != nil {
+		return fmt.Errorf("count started deployments failed: %w", err)
+	}
+	if started > 0 {
+		return nil
+	}
+	if dep.Cluster == nil {
+		return fmt.Errorf("preflight: cluster %s 不存在", dep.ClusterName)
+	}
+
+	kubeconfig, err := helmDriver.ClusterKubeconfig(dep.Cluster)
+	if err != nil {
+		return fmt.Errorf("preflight: %w", err)
+	}
+
+	param := &helmDriver.PreflightParam{
+		Kubeconfig: kubeconfig,
+		Namespace:  namespace,
+		Image:      helmDriver.ImageRef(build.ImageURL, build.ImageTag),
+	}
+	if strings.TrimSpace(arts.AppChart.Type) == "helm" {
+		if cfg, err := helmDriver.DecodeConfig(arts.AppChart.Data); err == nil {
+			param.ChartRepoURL = cfg.RepoURL
+			if strings.TrimSpace(cfg.CredentialRef) != "" {
+				if u, p, err := helmDriver.New(sm.db).ResolveBasicAuth(cfg.CredentialRef); err == nil {
+					param.ChartUsername, param.ChartPassword = u, p
+				}
+			}
+		}
+	}
+
+	report := helmDriver.Preflight(ctx, param)
+	sm.logger.Info(fmt.Sprintf("[Deployment SM] Batch:%v Cluster:%v preflight passed=%v", dep.BatchID, dep.ClusterName, report.Passed),
+		zap.Int64("deployment_id", dep.ID), zap.Any("checks", report.Checks))
+	return report.Err()
+}
+
+// HandleRunning handle Running → Success / Failed / VerifyFailed
 func (sm *StateMachine) HandleRunning(ctx context.Context, dep *model.Deployment) (string, func(*model.Deployment), error) {
 	log := sm.logger.With(zap.Int64("deployment_id", dep.ID)).Sugar()
 
 	// 重新加载 deployment + cluster（用于获取 kubeconfig 做状态检查）
 	var full model.Deployment
-	if err := sm.db.WithContext(ctx).Where("id = ?", dep.ID).Preload("Cluster").First(&full).Error; err != nil {
+	if err := loadDeploymentWithCluster(ctx, sm.db, dep.ID, &full); err != nil {
 		return "", nil, err
 	}
 
@@ -210,29 +350,61 @@ func (sm *StateMachine) HandleRunning(ctx context.Context, dep *model.Deployment
 		Payload:   &full, // driver 可按需断言使用
 	})
 	if err != nil {
+		if reason := sm.runningTimeoutReason(&full); reason != "" {
+			return constants.DeploymentStatusFailed, timeoutFailed(reason + ": " + err.Error()), nil
+		}
 		return "", nil, fmt.Errorf("check status failed: %w", err)
 	}
 
 	switch res.Status {
 	case drivers.StatusSuccess:
-		return constants.DeploymentStatusSuccess, func(d *model.Deployment) {
-			now := time.Now()
-			d.FinishedAt = &now
-			setErrorMessage(d, "")
-		}, nil
+		// workload 已就绪：配置了部署后验证时通过验证才置为 success
+		return sm.verifyAfterReady(ctx, &full)
 	case drivers.StatusFailed:
 		return constants.DeploymentStatusFailed, func(d *model.Deployment) {
 			setErrorMessage(d, res.Message)
 			d.RetryCount++
 			now := time.Now()
 			d.FinishedAt = &now
+			d.LastFailedAt = &now
 		}, nil
 	default:
+		if reason := sm.runningTimeoutReason(&full); reason != "" {
+			if res.Message != "" {
+				reason += ": " + res.Message
+			}
+			log.Warnf("[Deployment SM: %d-%d-%d] %s", full.BatchID, full.ReleaseID, full.ID, reason)
+			return constants.DeploymentStatusFailed, timeoutFailed(reason), nil
+		}
+		sm.recordIfChanged(ctx, &full, constants.DeploymentEventReadiness, res.Message)
 		log.Debugf("[Deployment SM: %d-%d-%d] 部署进行中: status: %v", full.BatchID, full.ReleaseID, full.ID, res.Status)
 		return "", nil, nil // 继续等待
 	}
 }
 
+// runningTimeoutReason Running 超过 single_app_timeout 仍未结束时返回超时原因
+func (sm *StateMachine) runningTimeoutReason(dep *model.Deployment) string {
+	if sm.runningTimeout <= 0 || dep.StartedAt == nil {
+		return ""
+	}
+	elapsed := time.Since(*dep.StartedAt)
+	if elapsed <= sm.runningTimeout {
+		return ""
+	}
+	return fmt.Sprintf("部署超时：已运行 %s（上限 %s）", elapsed.Round(time.Second), sm.runningTimeout)
+}
+
+// timeoutFailed 超时失败的字段更新（与检查失败一致，计入重试次数）
+func timeoutFailed(reason string) func(*model.Deployment) {
+	return func(d *model.Deployment) {
+		setErrorMessage(d, reason)
+		d.RetryCount++
+		now := time.Now()
+		d.FinishedAt = &now
+		d.LastFailedAt = &now
+	}
+}
+
 func setErrorMessage(dep *model.Deployment, msg string) {
 	if msg == "" {
 		dep.ErrorMessage = nil
@@ -0,0 +1,115 @@
+package deployment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"devops-cd/internal/core/common/metacache"
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+	"devops-cd/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const impactCollectTimeout = 30 * time.Second
+
+// captureImpactBefore 在 main 阶段首次执行前采集该 env/cluster 的资源基线
+// 同一批次同一应用同一集群只记录第一次（重试、切换版本不覆盖），采集失败不影响部署
+func (sm *StateMachine) captureImpactBefore(ctx context.Context, dep *model.Deployment, namespace, releaseName string) {
+	var exists int64
+	if err := sm.db.WithContext(ctx).Model(&model.BatchAppImpact{}).
+		Where("batch_id = ? AND app_id = ? AND env = ? AND cluster = ?", dep.BatchID, dep.AppID, dep.Env, dep.ClusterName).
+		Count(&exists).Error; err != nil || exists > 0 {
+		return
+	}
+
+	impact := &model.BatchAppImpact{
+		BatchID:     dep.BatchID,
+		AppID:       dep.AppID,
+		Env:         dep.Env,
+		ClusterName: dep.ClusterName,
+		Namespace:   namespace,
+		ReleaseName: releaseName,
+	}
+	if dep.Cluster == nil {
+		setImpactError(impact, fmt.Sprintf("cluster %s 不存在", dep.ClusterName))
+	} else if kubeconfig, err := helmDriver.ClusterKubeconfig(dep.Cluster); err != nil {
+		setImpactError(impact, "before: "+err.Error())
+	} else {
+		cctx, cancel := context.WithTimeout(ctx, impactCollectTimeout)
+		usage, err := helmDriver.CollectReleaseResources(cctx, kubeconfig, namespace, releaseName)
+		cancel()
+		if err != nil {
+			setImpactError(impact, "before: "+err.Error())
+		} else {
+			now := time.Now()
+			impact.Before = usage
+			impact.BeforeCollectedAt = &now
+		}
+	}
+
+	if err := sm.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(impact).Error; err != nil {
+		sm.logger.Warn(fmt.Sprintf("[Deployment SM] 记录部署前资源基线失败: %v", err))
+	}
+}
+
+// CollectImpactAfter 批次完成后采集各应用集群的资源现状，仅处理尚未采集 after 的记录
+func CollectImpactAfter(ctx context.Context, db *gorm.DB, batchID int64) error {
+	var impacts []*model.BatchAppImpact
+	if err := db.WithContext(ctx).Where("batch_id = ? AND after_collected_at IS NULL", batchID).Find(&impacts).Error; err != nil {
+		return err
+	}
+	if len(impacts) == 0 {
+		return nil
+	}
+
+	kubeconfigs := map[string]string{}
+	var errs []error
+	for _, impact := range impacts {
+		kubeconfig, ok := kubeconfigs[impact.ClusterName]
+		if !ok {
+			cluster, err := metacache.Cluster(ctx, db, impact.ClusterName)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: 查询集群失败: %w", impact.ClusterName, err))
+				continue
+			}
+			if kubeconfig, err = helmDriver.ClusterKubeconfig(cluster); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", impact.ClusterName, err))
+				continue
+			}
+			kubeconfigs[impact.ClusterName] = kubeconfig
+		}
+
+		cctx, cancel := context.WithTimeout(ctx, impactCollectTimeout)
+		usage, err := helmDriver.CollectReleaseResources(cctx, kubeconfig, impact.Namespace, impact.ReleaseName)
+		cancel()
+
+		updates := map[string]interface{}{}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("app %d %s/%s: %w", impact.AppID, impact.Env, impact.ClusterName, err))
+			updates["error_message"] = joinImpactError(impact.ErrorMessage, "after: "+err.Error())
+		} else {
+			updates["after"] = usage
+			updates["after_collected_at"] = time.Now()
+		}
+		if err := db.WithContext(ctx).Model(&model.BatchAppImpact{}).Where("id = ?", impact.ID).Updates(updates).Error; err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func setImpactError(impact *model.BatchAppImpact, msg string) {
+	impact.ErrorMessage = &msg
+}
+
+func joinImpactError(prev *string, msg string) string {
+	if prev == nil || strings.TrimSpace(*prev) == "" {
+		return msg
+	}
+	return *prev + "; " + msg
+}
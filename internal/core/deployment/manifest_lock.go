@@ -0,0 +1,297 @@
+package deployment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/crypto"
+	"devops-cd/internal/pkg/git"
+	"devops-cd/internal/pkg/git/api"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/internal/pkg/manifest"
+	"devops-cd/pkg/constants"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ManifestLocker 封板时从构建对应提交读取代码库清单（devops-cd.yaml），为清单中声明了该应用的发布应用固化 manifest lock
+//
+// 平台配置可能已被默认分支的清单同步或手动修改，与要部署的版本不一致；封板时以构建提交中的清单为准：
+//   - 部署环境/集群、app_chart 的应用 values 层与依赖使用清单中的定义
+//   - 清单声明的 pre/prod 集群必须已在平台配置并启用（集群的部署策略等仍取平台配置），平台多出的集群本次不部署
+//   - 与平台配置不一致的项记录在 lock 的 conflicts 中；代码库无清单、清单未声明该应用或找不到仓库源时不生成 lock
+type ManifestLocker struct {
+	db *gorm.DB
+}
+
+func NewManifestLocker(db *gorm.DB) *ManifestLocker {
+	return &ManifestLocker{db: db}
+}
+
+// manifestFile 代码库某个提交的清单（不存在时 m 为 nil）
+type manifestFile struct {
+	m    *manifest.Manifest
+	name string
+	err  error
+}
+
+// manifestLockRun 单次封板的仓库源、Git 客户端与清单缓存（同一代码库同一提交只读取一次）
+type manifestLockRun struct {
+	l       *ManifestLocker
+	ctx     context.Context
+	sources []*model.RepoSource
+	clients map[int64]*git.Client
+	files   map[string]*manifestFile
+}
+
+// LockManifests 解析批次内全部发布应用的 manifest lock；任一应用的清单读取失败、不合法或与平台数据不兼容时不写入并返回错误
+func (l *ManifestLocker) LockManifests(ctx context.Context, batchID int64) error {
+	var releaseApps []model.ReleaseApp
+	if err := l.db.WithContext(ctx).Preload("Build").Preload("Application.Repository").
+		Where("batch_id = ?", batchID).Find(&releaseApps).Error; err != nil {
+		return fmt.Errorf("查询发布应用失败: %w", err)
+	}
+	run := &manifestLockRun{l: l, ctx: ctx, clients: map[int64]*git.Client{}, files: map[string]*manifestFile{}}
+	if err := l.db.WithContext(ctx).Where("enabled = ?", true).Find(&run.sources).Error; err != nil {
+		return fmt.Errorf("查询仓库源失败: %w", err)
+	}
+
+	locks := make(map[int64]*model.ManifestLock, len(releaseApps))
+	for i := range releaseApps {
+		ra := &releaseApps[i]
+		if ra.Build == nil || ra.Application == nil || ra.Application.Repository == nil {
+			continue
+		}
+		lock, err := run.resolve(ra)
+		if err != nil {
+			return fmt.Errorf("应用 %s 解析代码库清单失败: %w", ra.Application.Name, err)
+		}
+		locks[ra.ID] = lock
+		if lock != nil && len(lock.Conflicts) > 0 {
+			logger.Warn("代码库清单与平台配置不一致，封板以清单为准",
+				zap.Int64("batch_id", batchID),
+				zap.Int64("release_id", ra.ID),
+				zap.String("app", ra.Application.Name),
+				zap.String("ref", lock.Ref),
+				zap.Int("conflicts", len(lock.Conflicts)))
+		}
+	}
+
+	for i := range releaseApps {
+		// 未生成 lock 的发布应用清空上次封板的结果
+		if err := l.db.WithContext(ctx).Model(&model.ReleaseApp{}).Where("id = ?", releaseApps[i].ID).
+			Update("manifest_lock", locks[releaseApps[i].ID]).Error; err != nil {
+			return fmt.Errorf("记录 manifest lock 失败: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *manifestLockRun) resolve(ra *model.ReleaseApp) (*model.ManifestLock, error) {
+	app, build := ra.Application, ra.Build
+	ref := build.CommitSHA
+	if ref == "" {
+		ref = build.ImageTag
+	}
+	file, err := r.read(app.Repository, ref)
+	if err != nil || file == nil || file.m == nil {
+		return nil, err
+	}
+	item := file.m.App(app.Name)
+	if item == nil {
+		return nil, nil
+	}
+
+	lock := &model.ManifestLock{
+		BuildID:   build.ID,
+		Ref:       ref,
+		File:      file.name,
+		LockedAt:  time.Now(),
+		AppType:   item.AppType,
+		DependsOn: append([]string{}, item.DependsOn...),
+		DependIDs: model.Int64List{},
+		Envs:      map[string][]model.ManifestLockCluster{},
+	}
+	for env, clusters := range item.Envs {
+		for _, c := range clusters {
+			cluster := model.ManifestLockCluster{Cluster: c.Cluster, Replicas: c.Replicas, DeploymentName: c.DeploymentName}
+			for _, layer := range c.Values {
+				cluster.Values = append(cluster.Values, layer.ToModel())
+			}
+			lock.Envs[env] = append(lock.Envs[env], cluster)
+		}
+	}
+
+	if err := r.resolveDependsOn(app, lock); err != nil {
+		return nil, err
+	}
+	if err := r.compareEnvConfigs(app, lock); err != nil {
+		return nil, err
+	}
+	if app.AppType != lock.AppType {
+		lock.Conflicts = append(lock.Conflicts, model.ManifestConflict{Field: "app_type", Platform: app.AppType, Manifest: lock.AppType})
+	}
+	return lock, nil
+}
+
+// resolveDependsOn 按项目内应用名解析依赖，依赖的应用不存在时拒绝封板
+func (r *manifestLockRun) resolveDependsOn(app *model.Application, lock *model.ManifestLock) error {
+	var apps []model.Application
+	if err := r.l.db.WithContext(r.ctx).Select("id", "name").
+		Where("project_id = ? AND (name IN ? OR id IN ?)", app.ProjectID, lock.DependsOn, []int64(app.DefaultDependsOn)).
+		Find(&apps).Error; err != nil {
+		return fmt.Errorf("查询依赖应用失败: %w", err)
+	}
+	ids, names := make(map[string]int64, len(apps)), make(map[int64]string, len(apps))
+	for _, a := range apps {
+		ids[a.Name], names[a.ID] = a.ID, a.Name
+	}
+
+	var missing []string
+	for _, name := range lock.DependsOn {
+		if id, ok := ids[name]; ok {
+			lock.DependIDs = append(lock.DependIDs, id)
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s 中依赖的应用不存在: %s", lock.File, strings.Join(missing, ", "))
+	}
+
+	platform := make([]string, 0, len(app.DefaultDependsOn))
+	for _, id := range app.DefaultDependsOn {
+		if name, ok := names[id]; ok {
+			platform = append(platform, name)
+		} else {
+			platform = append(platform, fmt.Sprintf("#%d", id))
+		}
+	}
+	want := slices.Sorted(slices.Values(lock.DependsOn))
+	sort.Strings(platform)
+	if !slices.Equal(want, platform) {
+		lock.Conflicts = append(lock.Conflicts, model.ManifestConflict{Field: "depends_on", Platform: strings.Join(platform, ","), Manifest: strings.Join(want, ",")})
+	}
+	return nil
+}
+
+// compareEnvConfigs 对比清单与平台的 pre/prod 集群配置；清单声明但平台未配置或未启用的集群拒绝封板
+func (r *manifestLockRun) compareEnvConfigs(app *model.Application, lock *model.ManifestLock) error {
+	var configs []model.AppEnvConfig
+	if err := r.l.db.WithContext(r.ctx).
+		Where("app_id = ? AND env IN ? AND status = 1", app.ID, []string{constants.EnvTypePre, constants.EnvTypeProd}).
+		Order("env, cluster").Find(&configs).Error; err != nil {
+		return fmt.Errorf("查询应用环境配置失败: %w", err)
+	}
+	configured := make(map[string]*model.AppEnvConfig, len(configs))
+	for i := range configs {
+		cfg := &configs[i]
+		configured[cfg.Env+"/"+cfg.Cluster] = cfg
+		if lock.Cluster(cfg.Env, cfg.Cluster) == nil {
+			lock.Conflicts = append(lock.Conflicts, model.ManifestConflict{Env: cfg.Env, Cluster: cfg.Cluster, Field: "env_cluster", Platform: "configured"})
+		}
+	}
+
+	var missing []string
+	for _, env := range []string{constants.EnvTypePre, constants.EnvTypeProd} {
+		for _, cluster := range lock.Clusters(env) {
+			cfg := configured[env+"/"+cluster]
+			if cfg == nil {
+				missing = append(missing, env+"/"+cluster)
+				continue
+			}
+			c := lock.Cluster(env, cluster)
+			if c.Replicas > 0 && c.Replicas != cfg.Replicas {
+				lock.Conflicts = append(lock.Conflicts, model.ManifestConflict{Env: env, Cluster: cluster, Field: "replicas", Platform: fmt.Sprint(cfg.Replicas), Manifest: fmt.Sprint(c.Replicas)})
+			}
+			if current := derefOr(cfg.DeploymentNameOverride, ""); current != c.DeploymentName {
+				lock.Conflicts = append(lock.Conflicts, model.ManifestConflict{Env: env, Cluster: cluster, Field: "deployment_name", Platform: current, Manifest: c.DeploymentName})
+			}
+			data, err := cfg.ParseConfigData()
+			if err != nil {
+				return fmt.Errorf("%s/%s config_data 解析失败: %w", env, cluster, err)
+			}
+			if !sameValuesLayers(data.Values, c.Values) {
+				platform, _ := json.Marshal(data.Values)
+				manifested, _ := json.Marshal(c.Values)
+				lock.Conflicts = append(lock.Conflicts, model.ManifestConflict{Env: env, Cluster: cluster, Field: "values", Platform: string(platform), Manifest: string(manifested)})
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s 中声明的集群未在平台配置或未启用: %s（可先按清单同步代码库）", lock.File, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// read 读取代码库 ref 下的清单文件；没有清单或找不到仓库源时返回 nil
+func (r *manifestLockRun) read(repo *model.Repository, ref string) (*manifestFile, error) {
+	key := fmt.Sprintf("%d|%s", repo.ID, ref)
+	if file, ok := r.files[key]; ok {
+		return file, file.err
+	}
+	file := &manifestFile{}
+	r.files[key] = file
+
+	client, err := r.client(repo)
+	if err != nil || client == nil {
+		file.err = err
+		return file, err
+	}
+	for _, name := range manifest.FileNames {
+		content, err := client.GetFileContent(repo.Namespace, repo.Name, ref, name)
+		if errors.Is(err, api.ErrFileNotFound) {
+			continue
+		}
+		if err != nil {
+			file.err = fmt.Errorf("读取 %s@%s 失败: %w", name, ref, err)
+			return file, file.err
+		}
+		file.name = name
+		if file.m, err = manifest.Parse(content); err != nil {
+			file.err = fmt.Errorf("%s@%s 不合法: %w", name, ref, err)
+		}
+		return file, file.err
+	}
+	return file, nil
+}
+
+// client 代码库所属的启用仓库源（平台与命名空间一致）的 Git 客户端，没有对应仓库源时返回 nil
+func (r *manifestLockRun) client(repo *model.Repository) (*git.Client, error) {
+	for _, source := range r.sources {
+		if source.Platform != repo.GitType || source.Namespace != repo.Namespace {
+			continue
+		}
+		if c, ok := r.clients[source.ID]; ok {
+			return c, nil
+		}
+		token, err := crypto.DecryptWithKeyID(source.AuthTokenEnc, source.AuthTokenKeyID)
+		if err != nil {
+			return nil, fmt.Errorf("解密仓库源 token 失败: %w", err)
+		}
+		c, err := git.NewClient(source.BaseURL, token, source.Platform)
+		if err != nil {
+			return nil, err
+		}
+		r.clients[source.ID] = c
+		return c, nil
+	}
+	return nil, nil
+}
+
+// sameValuesLayers 比较 values 层（nil 与空列表视为相同）
+func sameValuesLayers(a, b []model.ValuesLayer) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,240 @@
+package deployment
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+	manifestDriver "devops-cd/internal/core/deployment/plan/drivers/manifest"
+	"devops-cd/internal/model"
+
+	"gorm.io/gorm"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Workloads 部署对应的 workload（从已部署的 manifest 中提取），用于查看 pod 日志与 Kubernetes 事件
+type Workloads struct {
+	Deployment *model.Deployment
+	Refs       []helmDriver.WorkloadRef
+
+	clientset kubernetes.Interface
+}
+
+// LoadWorkloads 按 driver 类型读取已部署的 manifest 并提取 workload：helm 取 release 当前 revision，manifest 取已应用清单
+func LoadWorkloads(ctx context.Context, db *gorm.DB, deploymentID int64) (*Workloads, error) {
+	var dep model.Deployment
+	if err := db.WithContext(ctx).Preload("Cluster").First(&dep, deploymentID).Error; err != nil {
+		return nil, fmt.Errorf("load deployment failed: %w", err)
+	}
+	if dep.DriverType == nil || strings.TrimSpace(dep.DeploymentName) == "" {
+		return nil, fmt.Errorf("deployment 尚未开始部署")
+	}
+	if !dep.Cluster.HasCredentials() {
+		return nil, fmt.Errorf("cluster %s 不存在或未配置连接凭据", dep.ClusterName)
+	}
+	kubeconfig, err := helmDriver.ClusterKubeconfig(dep.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest string
+	switch driverType := strings.TrimSpace(*dep.DriverType); driverType {
+	case "helm":
+		m, found, err := helmDriver.GetReleaseManifest(kubeconfig, dep.Namespace, dep.DeploymentName)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, fmt.Errorf("release %s/%s 不存在", dep.Namespace, dep.DeploymentName)
+		}
+		manifest = m
+	case "manifest":
+		m, err := manifestDriver.AppliedManifest(ctx, kubeconfig, dep.Namespace, dep.DeploymentName)
+		if err != nil {
+			return nil, err
+		}
+		manifest = m
+	default:
+		return nil, fmt.Errorf("driver %s 不支持查看日志与事件", driverType)
+	}
+
+	refs, err := helmDriver.ExtractWorkloadsFromManifest(manifest, dep.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	restClientGetter, err := helmDriver.NewRESTClientGetter(kubeconfig, dep.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	restConfig, err := restClientGetter.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &Workloads{Deployment: &dep, Refs: refs, clientset: clientset}, nil
+}
+
+// Pods 所有 workload 的 pod（按名称排序）
+func (w *Workloads) Pods(ctx context.Context) ([]corev1.Pod, error) {
+	var pods []corev1.Pod
+	seen := make(map[string]bool)
+	for _, ref := range w.Refs {
+		items, err := helmDriver.WorkloadPods(ctx, w.clientset, ref)
+		if err != nil {
+			return nil, fmt.Errorf("查询 %s/%s 的 pod 失败: %w", ref.Kind, ref.Name, err)
+		}
+		for _, pod := range items {
+			key := pod.Namespace + "/" + pod.Name
+			if !seen[key] {
+				seen[key] = true
+				pods = append(pods, pod)
+			}
+		}
+	}
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	return pods, nil
+}
+
+// PodLogs 读取容器日志
+func (w *Workloads) PodLogs(ctx context.Context, pod *corev1.Pod, opts *corev1.PodLogOptions) (string, error) {
+	raw, err := w.clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, opts).DoRaw(ctx)
+	if err != nil {
+		return "", err
+	}
+	return string(raw), nil
+}
+
+// FollowPodLogs 持续读取容器日志（opts.Follow），每行回调一次，直到 ctx 取消或容器退出
+func (w *Workloads) FollowPodLogs(ctx context.Context, pod *corev1.Pod, opts *corev1.PodLogOptions, fn func(line string)) error {
+	stream, err := w.clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, opts).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fn(scanner.Text())
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	return scanner.Err()
+}
+
+// Events workload 及其 ReplicaSet / Pod 的事件（按最近发生时间排序）
+func (w *Workloads) Events(ctx context.Context) ([]corev1.Event, error) {
+	namespaces := w.namespaces()
+	var events []corev1.Event
+	for _, ns := range namespaces {
+		list, err := w.clientset.CoreV1().Events(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("查询事件失败: %w", err)
+		}
+		for _, event := range list.Items {
+			if w.relevant(&event) {
+				events = append(events, event)
+			}
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return EventTime(&events[i]).Time.Before(EventTime(&events[j]).Time) })
+	return events, nil
+}
+
+// WatchEvents 持续推送新事件，直到 ctx 取消
+func (w *Workloads) WatchEvents(ctx context.Context, fn func(event *corev1.Event)) error {
+	results := make(chan *corev1.Event)
+	errs := make(chan error, len(w.namespaces()))
+	for _, ns := range w.namespaces() {
+		watcher, err := w.clientset.CoreV1().Events(ns).Watch(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("监听事件失败: %w", err)
+		}
+		go func(watcher watch.Interface) {
+			defer watcher.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case e, ok := <-watcher.ResultChan():
+					if !ok {
+						errs <- fmt.Errorf("事件监听已断开")
+						return
+					}
+					if e.Type != watch.Added && e.Type != watch.Modified {
+						continue
+					}
+					if event, ok := e.Object.(*corev1.Event); ok && w.relevant(event) {
+						select {
+						case results <- event:
+						case <-ctx.Done():
+							return
+						}
+					}
+				}
+			}
+		}(watcher)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			return err
+		case event := <-results:
+			fn(event)
+		}
+	}
+}
+
+func (w *Workloads) namespaces() []string {
+	seen := map[string]bool{w.Deployment.Namespace: true}
+	namespaces := []string{w.Deployment.Namespace}
+	for _, ref := range w.Refs {
+		if !seen[ref.Namespace] {
+			seen[ref.Namespace] = true
+			namespaces = append(namespaces, ref.Namespace)
+		}
+	}
+	return namespaces
+}
+
+// relevant 事件对象为 workload 本身，或以 workload 名称为前缀的 ReplicaSet / Pod（如 app-5d9f7-xk2p1）
+func (w *Workloads) relevant(event *corev1.Event) bool {
+	obj := event.InvolvedObject
+	for _, ref := range w.Refs {
+		if obj.Namespace != "" && obj.Namespace != ref.Namespace {
+			continue
+		}
+		if obj.Name == ref.Name && obj.Kind == ref.Kind {
+			return true
+		}
+		if (obj.Kind == "Pod" || obj.Kind == "ReplicaSet") && strings.HasPrefix(obj.Name, ref.Name+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// EventTime 事件最近发生时间（兼容 events.k8s.io 写入的 eventTime）
+func EventTime(event *corev1.Event) metav1.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp
+	}
+	if !event.EventTime.IsZero() {
+		return metav1.Time{Time: event.EventTime.Time}
+	}
+	return event.CreationTimestamp
+}
@@ -0,0 +1,118 @@
+package deployment
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+
+	"gorm.io/gorm"
+)
+
+// PlanItem 单个阶段（app_chart / config_chart）的部署计划
+type PlanItem struct {
+	Kind         string                 // app / config
+	DriverType   string                 // helm / gitops / manifest ...
+	Namespace    string                 // 解析后的 namespace
+	ReleaseName  string                 // 解析后的 release 名称（即 deployment_name）
+	ChartName    string                 // 仅 helm/gitops
+	ChartVersion string                 // 仅 helm/gitops
+	Values       map[string]interface{} // 合并后的 values（敏感字段已脱敏），仅 helm/gitops
+}
+
+// redactedValue 脱敏后的占位值
+const redactedValue = "******"
+
+// sensitiveKeyPattern values 中需脱敏的 key
+var sensitiveKeyPattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credential|dsn)`)
+
+// PlanDeployment 按 Pending 阶段相同的规则解析 dep 的部署计划（namespace、release、chart、合并后的 values），不访问集群、不修改状态
+//
+// dep 无需落库，只需 AppID / Env / ClusterName；启用 config chart 时额外返回 kind=config 的计划
+func PlanDeployment(ctx context.Context, db *gorm.DB, dep *model.Deployment, build *model.Build) ([]*PlanItem, error) {
+	sc, err := loadStageContext(ctx, db, dep, build)
+	if err != nil {
+		return nil, err
+	}
+	if dep.Cluster == nil {
+		// values 解析只读取 cluster 名称，kubeconfig 留空
+		dep.Cluster = &model.Cluster{Name: dep.ClusterName}
+	}
+	payload := sc.helmPayload(dep)
+
+	var items []*PlanItem
+	if sc.arts.ConfigChart != nil && sc.arts.ConfigChart.Enabled {
+		item, err := sc.planStage(ctx, db, payload, sc.arts.ConfigChart, constants.DeploymentKindConfig, "config_chart")
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	item, err := sc.planStage(ctx, db, payload, sc.arts.AppChart, constants.DeploymentKindApp, "app_chart")
+	if err != nil {
+		return nil, err
+	}
+	return append(items, item), nil
+}
+
+func (sc *stageContext) planStage(ctx context.Context, db *gorm.DB, payload *helmDriver.ExecutePayload, stage *model.StageSpecV1, kind, stageName string) (*PlanItem, error) {
+	item := &PlanItem{
+		Kind:       kind,
+		DriverType: strings.TrimSpace(stage.Type),
+		Namespace:  sc.namespace,
+	}
+	item.ReleaseName, item.ChartVersion = sc.stageRelease(stage)
+	if item.ReleaseName == "" {
+		if kind == constants.DeploymentKindConfig {
+			return nil, fmt.Errorf("config_chart: release_name_template 为空或解析失败")
+		}
+		item.ReleaseName = sc.app.Name
+	}
+
+	// values 仅 helm（及内嵌 helm 配置的 gitops）driver 计算
+	if item.DriverType != "helm" && item.DriverType != "gitops" {
+		return item, nil
+	}
+	param, err := helmDriver.New(db).ResolveDeploymentParam(ctx, sc.namespace, payload, stage, stageName)
+	if err != nil {
+		return nil, err
+	}
+	item.ChartName = param.ChartName
+	item.ChartVersion = param.ChartVersion
+	item.Values = redactValues(param.Values)
+	return item, nil
+}
+
+// redactValues 复制 values 并将敏感 key 对应的值替换为占位符
+func redactValues(values map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if sensitiveKeyPattern.MatchString(k) {
+			if _, nested := v.(map[string]interface{}); !nested {
+				out[k] = redactedValue
+				continue
+			}
+		}
+		out[k] = redactValue(v)
+	}
+	return out
+}
+
+func redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return redactValues(val)
+	case []interface{}:
+		list := make([]interface{}, len(val))
+		for i, item := range val {
+			list[i] = redactValue(item)
+		}
+		return list
+	default:
+		return v
+	}
+}
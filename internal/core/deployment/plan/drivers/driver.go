@@ -27,6 +27,16 @@ type ExecuteRequest struct {
 
 	// Payload 由调用方组装（为了避免 driver 依赖 deployment state machine 的内部细节）
 	Payload interface{}
+
+	// Progress 执行过程中的关键步骤回调（event 见 constants.DeploymentEvent*），可为空
+	Progress func(event, message string)
+}
+
+// Report 上报执行进度，未设置 Progress 时忽略
+func (r *ExecuteRequest) Report(event, message string) {
+	if r != nil && r.Progress != nil {
+		r.Progress(event, message)
+	}
 }
 
 type ExecuteResult struct {
@@ -0,0 +1,140 @@
+//go:build integration
+
+// Package fake 内存 driver，用于无集群的集成测试（go test -tags integration）
+package fake
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"devops-cd/internal/core/deployment/plan/drivers"
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+	"devops-cd/internal/model"
+)
+
+// Call 一次 Execute 调用记录
+type Call struct {
+	Stage        drivers.Stage
+	Namespace    string
+	DeploymentID int64
+	AppID        int64
+	Env          string
+	Cluster      string
+	ImageTag     string
+}
+
+// Outcome 部署结果：Execute 报错，或 CheckStatus 返回 failed/running
+type Outcome struct {
+	ExecuteErr    error  // Execute 直接返回的错误
+	Failed        string // 非空时 CheckStatus 返回 failed 及该消息
+	RunningChecks int    // CheckStatus 返回 running 的次数，之后按 Failed 决定结果
+}
+
+// Driver 记录调用并按规则返回结果的内存 driver；默认 Execute 成功、首次 CheckStatus 即 success
+type Driver struct {
+	name string
+
+	mu       sync.Mutex
+	calls    []Call
+	outcomes []rule
+	checks   map[int64]int
+}
+
+type rule struct {
+	match   func(*model.Deployment) bool
+	outcome Outcome
+}
+
+// New 创建 fake driver；name 需与 artifacts_json 中 chart 的 type 一致（通常为 helm）
+func New(name string) *Driver {
+	return &Driver{name: name, checks: make(map[int64]int)}
+}
+
+// Registry 返回只包含该 driver 的注册表，配合 deployment.WithRegistry 使用
+func (d *Driver) Registry() drivers.Registry {
+	return drivers.StaticRegistry{d.name: d}
+}
+
+func (d *Driver) Name() string { return d.name }
+
+// On 为匹配的 Deployment 设置结果，后设置的规则优先
+func (d *Driver) On(match func(*model.Deployment) bool, outcome Outcome) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.outcomes = append(d.outcomes, rule{match: match, outcome: outcome})
+}
+
+// FailCluster 指定集群的部署在状态检查时失败
+func (d *Driver) FailCluster(cluster, message string) {
+	d.On(func(dep *model.Deployment) bool { return dep.ClusterName == cluster }, Outcome{Failed: message})
+}
+
+// Calls 返回 Execute 调用记录副本
+func (d *Driver) Calls() []Call {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := make([]Call, len(d.calls))
+	copy(out, d.calls)
+	return out
+}
+
+// Reset 清空调用记录与结果规则
+func (d *Driver) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls = nil
+	d.outcomes = nil
+	d.checks = make(map[int64]int)
+}
+
+func (d *Driver) Execute(ctx context.Context, req *drivers.ExecuteRequest) (*drivers.ExecuteResult, error) {
+	p, ok := req.Payload.(*helmDriver.ExecutePayload)
+	if !ok || p == nil || p.Deployment == nil || p.Build == nil {
+		return nil, fmt.Errorf("fake driver: invalid payload")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls = append(d.calls, Call{
+		Stage:        req.Stage,
+		Namespace:    req.Namespace,
+		DeploymentID: p.Deployment.ID,
+		AppID:        p.Deployment.AppID,
+		Env:          p.Deployment.Env,
+		Cluster:      p.Deployment.ClusterName,
+		ImageTag:     p.Build.ImageTag,
+	})
+	if outcome := d.outcomeLocked(p.Deployment); outcome.ExecuteErr != nil {
+		return nil, outcome.ExecuteErr
+	}
+	return drivers.Success(), nil
+}
+
+func (d *Driver) CheckStatus(ctx context.Context, req *drivers.ExecuteRequest) (*drivers.ExecuteResult, error) {
+	dep, ok := req.Payload.(*model.Deployment)
+	if !ok || dep == nil {
+		return nil, fmt.Errorf("fake driver: payload must be deployment")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	outcome := d.outcomeLocked(dep)
+	d.checks[dep.ID]++
+	if d.checks[dep.ID] <= outcome.RunningChecks {
+		return drivers.Running("fake: running"), nil
+	}
+	if outcome.Failed != "" {
+		return drivers.Failed(outcome.Failed), nil
+	}
+	return drivers.Success(), nil
+}
+
+func (d *Driver) outcomeLocked(dep *model.Deployment) Outcome {
+	for i := len(d.outcomes) - 1; i >= 0; i-- {
+		if d.outcomes[i].match(dep) {
+			return d.outcomes[i].outcome
+		}
+	}
+	return Outcome{}
+}
@@ -0,0 +1,108 @@
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+)
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+// argoApplication Argo CD Application 中用于判断同步结果的字段
+type argoApplication struct {
+	Status struct {
+		Sync struct {
+			Status   string `json:"status"` // Synced / OutOfSync / Unknown
+			Revision string `json:"revision"`
+		} `json:"sync"`
+		Health struct {
+			Status  string `json:"status"` // Healthy / Progressing / Degraded / Suspended / Missing / Unknown
+			Message string `json:"message"`
+		} `json:"health"`
+		ReconciledAt   *time.Time `json:"reconciledAt"`
+		OperationState *struct {
+			Phase      string `json:"phase"` // Running / Succeeded / Failed / Error / Terminating
+			Message    string `json:"message"`
+			SyncResult *struct {
+				Revision string `json:"revision"`
+			} `json:"syncResult"`
+		} `json:"operationState"`
+	} `json:"status"`
+}
+
+// argoGetApplication 查询 Application（refresh=normal 促使 Argo 尽快发现新提交）
+func argoGetApplication(ctx context.Context, cfg *ArgoConfig, cred map[string]string, name string) (*argoApplication, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/applications/%s?refresh=normal", strings.TrimRight(cfg.ServerURL, "/"), url.PathEscape(name))
+	body, err := doJSON(ctx, http.MethodGet, endpoint, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("查询 Argo Application %s 失败: %w", name, err)
+	}
+	var app argoApplication
+	if err := json.Unmarshal(body, &app); err != nil {
+		return nil, fmt.Errorf("解析 Argo Application %s 失败: %w", name, err)
+	}
+	return &app, nil
+}
+
+// argoSync 触发 Application 同步到指定 revision
+func argoSync(ctx context.Context, cfg *ArgoConfig, cred map[string]string, name, revision string) error {
+	endpoint := fmt.Sprintf("%s/api/v1/applications/%s/sync", strings.TrimRight(cfg.ServerURL, "/"), url.PathEscape(name))
+	payload, _ := json.Marshal(map[string]interface{}{"revision": revision})
+	if _, err := doJSON(ctx, http.MethodPost, endpoint, cred, payload); err != nil {
+		return fmt.Errorf("触发 Argo Application %s 同步失败: %w", name, err)
+	}
+	return nil
+}
+
+// commitState 查询 commit combined status（GitHub/Gitea 兼容），返回 success/pending/failure/error
+func commitState(ctx context.Context, statusURL string, cred map[string]string) (string, error) {
+	body, err := doJSON(ctx, http.MethodGet, statusURL, cred, nil)
+	if err != nil {
+		return "", fmt.Errorf("查询 commit status 失败: %w", err)
+	}
+	var status struct {
+		State string `json:"state"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return "", fmt.Errorf("解析 commit status 失败: %w", err)
+	}
+	return status.State, nil
+}
+
+func doJSON(ctx context.Context, method, endpoint string, cred map[string]string, payload []byte) ([]byte, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	helmDriver.ApplyHTTPAuth(req, cred)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("http %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
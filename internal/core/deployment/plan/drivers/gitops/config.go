@@ -0,0 +1,90 @@
+package gitops
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+)
+
+const (
+	// ModeValues 提交合并后的 values.yaml，由 Argo Application 的 helm source 引用 chart 渲染
+	ModeValues = "values"
+	// ModeManifests 提交 helm template 渲染后的 manifests.yaml，Argo Application 直接同步目录
+	ModeManifests = "manifests"
+
+	defaultBranch       = "main"
+	defaultPathTemplate = "{{.env}}/{{.cluster}}/{{.release_name}}"
+	defaultAuthorName   = "devops-cd"
+	defaultAuthorEmail  = "devops-cd@localhost"
+)
+
+// Config 是 gitops driver 的私有配置（对应 artifacts_json.*_chart.data）。
+//
+// 内嵌 helm driver 配置：chart / release_name_template / values layers 含义不变，
+// release 名称同时作为 Argo Application 名称。
+type Config struct {
+	helmDriver.Config
+
+	Mode string      `json:"mode,omitempty"` // values（默认）| manifests
+	Git  GitConfig   `json:"git"`
+	Argo *ArgoConfig `json:"argo,omitempty"`
+}
+
+// GitConfig 写回的 Git 仓库
+type GitConfig struct {
+	RepoURL       string `json:"repo_url"`
+	CredentialRef string `json:"credential_ref,omitempty"` // basic_auth / token / ssh_key，需有 push 权限
+	Branch        string `json:"branch,omitempty"`         // 默认 main
+	PathTemplate  string `json:"path_template,omitempty"`  // 仓库内目录，默认 {{.env}}/{{.cluster}}/{{.release_name}}
+
+	AuthorName  string `json:"author_name,omitempty"`
+	AuthorEmail string `json:"author_email,omitempty"`
+
+	// 未配置 argo 时，通过 commit status 跟踪同步结果（GitHub/Gitea 兼容的 combined status 接口）
+	// 仅支持 {{.sha}} 变量，例如 https://api.github.com/repos/org/deploy/commits/{{.sha}}/status
+	StatusURLTemplate string `json:"status_url_template,omitempty"`
+}
+
+// ArgoConfig Argo CD API，用于跟踪 Application 同步与健康状态
+type ArgoConfig struct {
+	ServerURL     string `json:"server_url"`
+	CredentialRef string `json:"credential_ref,omitempty"` // token 类型（Argo CD API token）
+	// Sync 提交后主动触发同步（Application 未开启自动同步时使用）
+	Sync bool `json:"sync,omitempty"`
+}
+
+func DecodeConfig(raw json.RawMessage) (*Config, error) {
+	var c Config
+	if len(raw) > 0 && strings.TrimSpace(string(raw)) != "" && strings.TrimSpace(string(raw)) != "null" {
+		if err := json.Unmarshal(raw, &c); err != nil {
+			return nil, fmt.Errorf("gitops config decode failed: %w", err)
+		}
+	}
+	if c.Mode == "" {
+		c.Mode = ModeValues
+	}
+	if c.Mode != ModeValues && c.Mode != ModeManifests {
+		return nil, fmt.Errorf("gitops config: 不支持的 mode: %s", c.Mode)
+	}
+	if strings.TrimSpace(c.Git.RepoURL) == "" {
+		return nil, fmt.Errorf("gitops config: git.repo_url 为空")
+	}
+	if c.Git.Branch == "" {
+		c.Git.Branch = defaultBranch
+	}
+	if c.Git.PathTemplate == "" {
+		c.Git.PathTemplate = defaultPathTemplate
+	}
+	if c.Git.AuthorName == "" {
+		c.Git.AuthorName = defaultAuthorName
+	}
+	if c.Git.AuthorEmail == "" {
+		c.Git.AuthorEmail = defaultAuthorEmail
+	}
+	if c.Argo != nil && strings.TrimSpace(c.Argo.ServerURL) == "" {
+		return nil, fmt.Errorf("gitops config: argo.server_url 为空")
+	}
+	return &c, nil
+}
@@ -0,0 +1,267 @@
+// Package gitops 将渲染结果写回 Git 仓库（由 Argo CD 等 GitOps 工具同步到集群），替代直接 helm install
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"devops-cd/internal/core/common/metacache"
+	"devops-cd/internal/core/deployment/helpers/tpl"
+	"devops-cd/internal/core/deployment/plan/drivers"
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/pkg/constants"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+type Driver struct {
+	db   *gorm.DB
+	helm *helmDriver.Driver
+}
+
+func New(db *gorm.DB) *Driver {
+	return &Driver{db: db, helm: helmDriver.New(db)}
+}
+
+func (d *Driver) Name() string { return "gitops" }
+
+// Execute 计算 values（或渲染 manifests），提交到配置的 Git 仓库目录
+func (d *Driver) Execute(ctx context.Context, req *drivers.ExecuteRequest) (*drivers.ExecuteResult, error) {
+	p, ok := req.Payload.(*helmDriver.ExecutePayload)
+	if !ok || p == nil || p.Deployment == nil || p.App == nil || p.Build == nil || p.Artifacts == nil {
+		return nil, fmt.Errorf("gitops driver: invalid payload")
+	}
+
+	var stage *model.StageSpecV1
+	var kind string
+	switch req.Stage {
+	case drivers.StagePre:
+		stage, kind = p.Artifacts.ConfigChart, "config_chart"
+	case drivers.StageMain:
+		stage, kind = p.Artifacts.AppChart, "app_chart"
+	default:
+		return nil, fmt.Errorf("gitops driver: unknown stage: %s", req.Stage)
+	}
+	if stage == nil || !stage.Enabled {
+		return drivers.Success(), nil
+	}
+
+	if err := d.commit(ctx, req, p, stage, kind); err != nil {
+		return drivers.Failed(err.Error()), err
+	}
+	return drivers.Success(), nil
+}
+
+// commit 提交并记录 deployment.git_revision，供 CheckStatus 跟踪同步
+func (d *Driver) commit(ctx context.Context, req *drivers.ExecuteRequest, p *helmDriver.ExecutePayload, stage *model.StageSpecV1, kind string) error {
+	dep := p.Deployment
+	if dep.Cluster == nil {
+		return fmt.Errorf("%s: cluster %s 不存在", kind, dep.ClusterName)
+	}
+	cfg, err := DecodeConfig(stage.Data)
+	if err != nil {
+		return err
+	}
+	param, err := d.helm.ResolveDeploymentParam(ctx, req.Namespace, p, stage, kind)
+	if err != nil {
+		return err
+	}
+	if param.ReleaseName == "" {
+		param.ReleaseName = p.App.Name
+	}
+
+	files := make(map[string][]byte, 1)
+	if cfg.Mode == ModeManifests {
+		manifest, err := helmDriver.NewHelmDeployer(nil).Template(ctx, param)
+		if err != nil {
+			return fmt.Errorf("%s: %w", kind, err)
+		}
+		files["manifests.yaml"] = []byte(manifest)
+	} else {
+		values, err := yaml.Marshal(param.Values)
+		if err != nil {
+			return fmt.Errorf("%s: values 序列化失败: %w", kind, err)
+		}
+		files["values.yaml"] = values
+	}
+
+	req.Report(constants.DeploymentEventChartRendered, fmt.Sprintf("%s: %s 渲染完成（mode=%s，release %s）", kind, param.ChartName, cfg.Mode, param.ReleaseName))
+
+	tplCtx := tpl.RenderTemplateContext(p.App, p.Build, dep.Env, dep.ClusterName, p.TplOptions)
+	tplCtx["release_name"] = param.ReleaseName
+	dir, err := tpl.ParseTemplate(cfg.Git.PathTemplate, tplCtx)
+	if err != nil {
+		return fmt.Errorf("%s: git.path_template 解析失败: %w", kind, err)
+	}
+	dir = strings.Trim(strings.TrimSpace(dir), "/")
+	if dir == "" {
+		return fmt.Errorf("%s: git.path_template 解析结果为空", kind)
+	}
+
+	cred, err := helmDriver.ResolveCredentialData(d.db, cfg.Git.CredentialRef)
+	if err != nil {
+		return err
+	}
+	repoURL, env, cleanup, err := helmDriver.PrepareGitAuth(cfg.Git.RepoURL, cred)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	req.Report(constants.DeploymentEventDeployStarted, fmt.Sprintf("%s: 提交 %s@%s:%s", kind, cfg.Git.RepoURL, cfg.Git.Branch, dir))
+	unlock := lockBranch(cfg.Git.RepoURL, cfg.Git.Branch)
+	sha, changed, err := commitAndPush(ctx, &commitRequest{
+		RepoURL: repoURL,
+		Env:     env,
+		Branch:  cfg.Git.Branch,
+		Dir:     dir,
+		Files:   files,
+		Message: fmt.Sprintf("deploy %s %s to %s/%s\n\nbatch: %d\ndeployment: %d",
+			p.App.Name, p.Build.ImageTag, dep.Env, dep.ClusterName, dep.BatchID, dep.ID),
+		Author: cfg.Git.AuthorName,
+		Email:  cfg.Git.AuthorEmail,
+	})
+	unlock()
+	if err != nil {
+		return fmt.Errorf("%s: 提交 Git 失败: %w", kind, err)
+	}
+
+	if err := d.db.WithContext(ctx).Table(model.DeploymentTableName).
+		Where("id = ?", dep.ID).Update("git_revision", sha).Error; err != nil {
+		return fmt.Errorf("记录 git_revision 失败: %w", err)
+	}
+	logger.Info("gitops 提交完成", zap.Int64("deployment_id", dep.ID), zap.String("repo", cfg.Git.RepoURL),
+		zap.String("path", dir), zap.String("revision", sha), zap.Bool("changed", changed))
+
+	if cfg.Argo != nil && cfg.Argo.Sync {
+		argoCred, err := helmDriver.ResolveCredentialData(d.db, cfg.Argo.CredentialRef)
+		if err != nil {
+			return err
+		}
+		if err := argoSync(ctx, cfg.Argo, argoCred, param.ReleaseName, sha); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckStatus 跟踪同步结果：配置 argo 时查询 Application 同步/健康状态，否则查询 commit status；
+// 两者都未配置时提交即视为成功（由 GitOps 工具自行同步）
+func (d *Driver) CheckStatus(ctx context.Context, req *drivers.ExecuteRequest) (*drivers.ExecuteResult, error) {
+	dep, ok := req.Payload.(*model.Deployment)
+	if !ok || dep == nil {
+		return nil, fmt.Errorf("gitops CheckStatus: payload must be deployment")
+	}
+	if dep.GitRevision == nil || *dep.GitRevision == "" {
+		return drivers.Failed("未找到 gitops 提交记录（git_revision 为空）"), nil
+	}
+	revision := *dep.GitRevision
+
+	cfg, err := d.loadConfig(ctx, dep)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case cfg.Argo != nil:
+		return d.checkArgo(ctx, cfg.Argo, dep, revision)
+	case strings.TrimSpace(cfg.Git.StatusURLTemplate) != "":
+		return d.checkCommitStatus(ctx, &cfg.Git, revision)
+	default:
+		return drivers.Success(), nil
+	}
+}
+
+func (d *Driver) checkArgo(ctx context.Context, argo *ArgoConfig, dep *model.Deployment, revision string) (*drivers.ExecuteResult, error) {
+	cred, err := helmDriver.ResolveCredentialData(d.db, argo.CredentialRef)
+	if err != nil {
+		return nil, err
+	}
+	app, err := argoGetApplication(ctx, argo, cred, dep.DeploymentName)
+	if err != nil {
+		// Argo 暂不可用：继续等待
+		return drivers.Running(err.Error()), nil
+	}
+	st := app.Status
+
+	if op := st.OperationState; op != nil && (op.Phase == "Failed" || op.Phase == "Error") &&
+		op.SyncResult != nil && op.SyncResult.Revision == revision {
+		return drivers.Failed(fmt.Sprintf("Argo 同步失败: %s", op.Message)), nil
+	}
+
+	// 同一分支上后续提交（其他应用的部署）会推进 Argo 的 revision：部署开始后完成过对账即视为已包含本次提交
+	synced := st.Sync.Status == "Synced" && (st.Sync.Revision == revision ||
+		(st.ReconciledAt != nil && dep.StartedAt != nil && st.ReconciledAt.After(*dep.StartedAt)))
+	if !synced {
+		return drivers.Running(fmt.Sprintf("Argo 同步中: sync=%s revision=%s，期望 %s",
+			st.Sync.Status, shortSHA(st.Sync.Revision), shortSHA(revision))), nil
+	}
+
+	switch st.Health.Status {
+	case "Healthy":
+		return drivers.Success(), nil
+	case "Degraded":
+		return drivers.Failed(fmt.Sprintf("Argo 健康检查失败: %s", st.Health.Message)), nil
+	default:
+		return drivers.Running(fmt.Sprintf("Argo health=%s", st.Health.Status)), nil
+	}
+}
+
+func (d *Driver) checkCommitStatus(ctx context.Context, git *GitConfig, revision string) (*drivers.ExecuteResult, error) {
+	statusURL, err := tpl.ParseTemplate(git.StatusURLTemplate, map[string]interface{}{"sha": revision})
+	if err != nil {
+		return nil, fmt.Errorf("git.status_url_template 解析失败: %w", err)
+	}
+	cred, err := helmDriver.ResolveCredentialData(d.db, git.CredentialRef)
+	if err != nil {
+		return nil, err
+	}
+	state, err := commitState(ctx, statusURL, cred)
+	if err != nil {
+		return drivers.Running(err.Error()), nil
+	}
+	switch state {
+	case "success":
+		return drivers.Success(), nil
+	case "failure", "error":
+		return drivers.Failed(fmt.Sprintf("commit %s status=%s", shortSHA(revision), state)), nil
+	default:
+		return drivers.Running(fmt.Sprintf("commit %s status=%s", shortSHA(revision), state)), nil
+	}
+}
+
+// loadConfig 按 deployment 所属项目环境重新读取 gitops 配置（CheckStatus 只拿到 deployment）
+func (d *Driver) loadConfig(ctx context.Context, dep *model.Deployment) (*Config, error) {
+	app, err := metacache.Application(ctx, d.db, dep.AppID)
+	if err != nil {
+		return nil, fmt.Errorf("load app failed: %w", err)
+	}
+	projectCfg, err := metacache.ProjectEnvConfig(ctx, d.db, app.ProjectID, dep.Env)
+	if err != nil {
+		return nil, fmt.Errorf("load project_env_config failed: %w", err)
+	}
+	arts, err := model.LoadArtifactsV1(projectCfg.ArtifactsJSON)
+	if err != nil {
+		return nil, err
+	}
+	stage := arts.AppChart
+	if dep.Kind == constants.DeploymentKindConfig {
+		stage = arts.ConfigChart
+	}
+	if stage == nil {
+		return nil, fmt.Errorf("gitops CheckStatus: 阶段配置不存在")
+	}
+	return DecodeConfig(stage.Data)
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
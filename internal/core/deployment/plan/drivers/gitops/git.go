@@ -0,0 +1,135 @@
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"devops-cd/internal/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const pushAttempts = 3
+
+var (
+	repoLocks   = make(map[string]*sync.Mutex)
+	repoLocksMu sync.Mutex
+)
+
+// lockBranch 同一仓库分支串行提交，减少并发部署时的 push 冲突
+func lockBranch(repoURL, branch string) func() {
+	key := repoURL + "#" + branch
+	repoLocksMu.Lock()
+	mu, ok := repoLocks[key]
+	if !ok {
+		mu = &sync.Mutex{}
+		repoLocks[key] = mu
+	}
+	repoLocksMu.Unlock()
+	mu.Lock()
+	return mu.Unlock
+}
+
+type commitRequest struct {
+	RepoURL string   // 已注入凭据的地址
+	Env     []string // 凭据相关环境变量（如 GIT_SSH_COMMAND）
+	Branch  string
+	Dir     string            // 仓库内目录
+	Files   map[string][]byte // 目录下的文件名 -> 内容
+	Message string
+	Author  string
+	Email   string
+}
+
+// commitAndPush 浅克隆分支，写入文件并提交推送；内容无变化时不提交，返回当前 HEAD
+// push 被拒绝（其他部署并发推送）时重新克隆重试
+func commitAndPush(ctx context.Context, req *commitRequest) (sha string, changed bool, err error) {
+	ctx, span := tracing.Start(ctx, "git.push", attribute.String("git.branch", req.Branch), attribute.String("git.dir", req.Dir))
+	defer func() {
+		span.SetAttributes(attribute.String("git.sha", sha), attribute.Bool("git.changed", changed))
+		tracing.End(span, err)
+	}()
+
+	for attempt := 1; attempt <= pushAttempts; attempt++ {
+		sha, changed, err = commitOnce(ctx, req)
+		if err == nil {
+			span.SetAttributes(attribute.Int("git.attempts", attempt))
+			return sha, changed, nil
+		}
+	}
+	return "", false, err
+}
+
+func commitOnce(ctx context.Context, req *commitRequest) (string, bool, error) {
+	base, err := os.MkdirTemp("", "devops-cd-gitops-*")
+	if err != nil {
+		return "", false, err
+	}
+	defer func() { _ = os.RemoveAll(base) }()
+
+	run := func(args ...string) (string, error) {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = base
+		// 避免 git 交互
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		cmd.Env = append(cmd.Env, req.Env...)
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("git %s failed: %w; output=%s", args[0], err, strings.TrimSpace(out.String()))
+		}
+		return strings.TrimSpace(out.String()), nil
+	}
+
+	if _, err := run("init", "-q"); err != nil {
+		return "", false, err
+	}
+	if _, err := run("remote", "add", "origin", req.RepoURL); err != nil {
+		return "", false, err
+	}
+	if _, err := run("fetch", "--depth", "1", "origin", "refs/heads/"+req.Branch); err != nil {
+		return "", false, err
+	}
+	if _, err := run("checkout", "-q", "-B", req.Branch, "FETCH_HEAD"); err != nil {
+		return "", false, err
+	}
+
+	dir := filepath.Join(base, filepath.Clean(req.Dir))
+	// 防止路径穿越：要求最终路径在仓库目录下
+	if !strings.HasPrefix(dir, base+string(os.PathSeparator)) {
+		return "", false, fmt.Errorf("path_template 非法: %s", req.Dir)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", false, err
+	}
+	for name, content := range req.Files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			return "", false, err
+		}
+	}
+	if _, err := run("add", "-A", "--", dir); err != nil {
+		return "", false, err
+	}
+	if status, err := run("status", "--porcelain", "--", dir); err != nil {
+		return "", false, err
+	} else if status == "" {
+		head, err := run("rev-parse", "HEAD")
+		return head, false, err
+	}
+
+	if _, err := run("-c", "user.name="+req.Author, "-c", "user.email="+req.Email, "commit", "-q", "-m", req.Message); err != nil {
+		return "", false, err
+	}
+	if _, err := run("push", "-q", "origin", "HEAD:refs/heads/"+req.Branch); err != nil {
+		return "", false, err
+	}
+	head, err := run("rev-parse", "HEAD")
+	return head, true, err
+}
@@ -0,0 +1,102 @@
+package helm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+
+	"devops-cd/internal/core/common/artifactcache"
+
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// locateChartCached 经共享制品缓存解析 http(s) chart 仓库，返回本地 chart 包路径与 index 中的 chart 包 digest
+//
+// index.yaml 按 TTL + 条件请求校验；chart 包按版本视为不可变，命中后不再回源，
+// 仅当 index 中的 digest 与缓存内容不一致时重新拉取。digest 非空（chart lock / 已记录的 digest）时，
+// index 中的 digest 必须与之一致，防止同一版本被覆盖发布后部署到不同内容。
+func locateChartCached(repoURL, username, password, chartName, version, digest string) (string, string, error) {
+	repoURL = strings.TrimRight(strings.TrimSpace(repoURL), "/")
+	repoAuth := basicAuth(username, password)
+
+	cv, err := lookupChartVersion(repoURL, username, password, chartName, version)
+	if err != nil {
+		return "", "", err
+	}
+	if digest != "" && cv.Digest != "" && cv.Digest != digest {
+		return "", "", fmt.Errorf("chart %s 版本 %s digest 已变化（锁定=%s, index=%s），仓库中的 chart 可能被覆盖发布", chartName, cv.Version, digest, cv.Digest)
+	}
+	chartURL, err := repo.ResolveReferenceURL(repoURL, cv.URLs[0])
+	if err != nil {
+		return "", "", err
+	}
+
+	// 与 helm 默认行为一致：凭据只发送给仓库同域地址
+	var chartAuth func(*http.Request)
+	if sameHost(repoURL, chartURL) {
+		chartAuth = repoAuth
+	}
+	req := artifactcache.Request{URL: chartURL, Auth: chartAuth, Immutable: true}
+	entry, err := artifactcache.Get(context.Background(), req)
+	if err != nil {
+		return "", "", fmt.Errorf("下载 chart 包失败: %w", err)
+	}
+	if cv.Digest != "" && entry.Digest != cv.Digest {
+		// 同版本被覆盖发布：按普通资源回源校验一次
+		req.Immutable = false
+		if entry, err = artifactcache.Get(context.Background(), req); err != nil {
+			return "", "", fmt.Errorf("下载 chart 包失败: %w", err)
+		}
+		if entry.Digest != cv.Digest {
+			return "", "", fmt.Errorf("chart 包 digest 不匹配: index=%s, 实际=%s", cv.Digest, entry.Digest)
+		}
+	}
+	return entry.Path, cv.Digest, nil
+}
+
+// lookupChartVersion 查询 http(s) chart 仓库 index 中满足版本（或 semver 约束，空为最新）的 chart
+func lookupChartVersion(repoURL, username, password, chartName, version string) (*repo.ChartVersion, error) {
+	repoURL = strings.TrimRight(strings.TrimSpace(repoURL), "/")
+	indexEntry, err := artifactcache.Get(context.Background(), artifactcache.Request{
+		URL:  repoURL + "/index.yaml",
+		Auth: basicAuth(username, password),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("下载 chart 仓库 index 失败: %w", err)
+	}
+	index, err := repo.LoadIndexFile(indexEntry.Path)
+	if err != nil {
+		return nil, fmt.Errorf("解析 chart 仓库 index 失败: %w", err)
+	}
+	cv, err := index.Get(chartName, version)
+	if err != nil {
+		return nil, fmt.Errorf("chart %s 版本 %s 未找到: %w", chartName, version, err)
+	}
+	if len(cv.URLs) == 0 {
+		return nil, fmt.Errorf("chart %s 版本 %s 没有下载地址", chartName, cv.Version)
+	}
+	return cv, nil
+}
+
+func basicAuth(username, password string) func(*http.Request) {
+	if username == "" && password == "" {
+		return nil
+	}
+	return func(req *http.Request) {
+		req.SetBasicAuth(username, password)
+	}
+}
+
+func sameHost(a, b string) bool {
+	ua, err := neturl.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := neturl.Parse(b)
+	if err != nil {
+		return false
+	}
+	return ua.Host == ub.Host
+}
@@ -11,7 +11,10 @@ import (
 type Config struct {
 	ReleaseNameTemplate string `json:"release_name_template,omitempty"`
 
+	// chart 来源：repo（默认，http(s) 仓库）/ oci（repo_url 为 oci://host/path，chart 引用为 <repo_url>/<chart_name>）
+	ChartSourceType      string `json:"chart_source_type,omitempty"`
 	RepoURL              string `json:"repo_url,omitempty"`
+	PlainHTTP            bool   `json:"plain_http,omitempty"` // 仅 oci：registry 使用 http 访问
 	CredentialRef        string `json:"credential_ref,omitempty"`
 	ChartNameTemplate    string `json:"chart_name_template,omitempty"`
 	ChartVersionTemplate string `json:"chart_version_template,omitempty"`
@@ -49,4 +52,9 @@ type DeploymentParam struct {
 	ChartUsername string // 可选（basic auth）
 	ChartPassword string // 可选（basic auth）
 
+	// OCI chart 来源
+	ChartSourceType string // repo / oci
+	ChartPlainHTTP  bool
+	// chart digest：非空时 OCI 按 digest 拉取、http(s) 仓库校验 index digest（来自 deployment 已记录的 digest 或 chart lock）；拉取后回填实际 digest
+	ChartDigest string
 }
@@ -0,0 +1,232 @@
+package helm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/pkg/constants"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// ProtectedKinds 变更需要人工确认的资源类型（误改可能导致数据丢失或集群级影响）
+var ProtectedKinds = map[string]bool{
+	"PodDisruptionBudget":      true,
+	"PersistentVolumeClaim":    true,
+	"CustomResourceDefinition": true,
+}
+
+// ManifestDiff 渲染结果与当前 release revision 的差异
+type ManifestDiff struct {
+	BaseRevision int // 0 表示 release 不存在（首次安装）
+	Changes      model.ManifestChanges
+	Diff         string
+}
+
+// Digest diff 内容摘要
+func (d *ManifestDiff) Digest() string {
+	sum := sha256.Sum256([]byte(d.Diff))
+	return hex.EncodeToString(sum[:])
+}
+
+// TouchesProtected 是否涉及受保护资源
+func (d *ManifestDiff) TouchesProtected() bool {
+	for _, c := range d.Changes {
+		if c.Protected {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff 以 dry-run 方式渲染 app chart，并与集群中当前 release revision 的 manifest 对比（等价于 helm diff upgrade）
+func (d *Driver) Diff(ctx context.Context, namespace string, p *ExecutePayload) (*ManifestDiff, error) {
+	if p == nil || p.Deployment == nil || p.App == nil || p.Build == nil || p.Artifacts == nil {
+		return nil, fmt.Errorf("helm driver: invalid payload")
+	}
+	param, err := d.ResolveDeploymentParam(ctx, namespace, p, p.Artifacts.AppChart, "app_chart")
+	if err != nil {
+		return nil, err
+	}
+	return NewHelmDeployer(nil).Diff(ctx, param)
+}
+
+// Diff 渲染 chart（dry-run，不写入集群）并与当前 release 对比
+func (d *HelmDeployer) Diff(ctx context.Context, param *DeploymentParam) (*ManifestDiff, error) {
+	current, rendered, err := d.render(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ManifestDiff{}
+	oldManifest := ""
+	if current != nil {
+		result.BaseRevision = current.Version
+		oldManifest = current.Manifest
+	}
+	result.Changes, result.Diff, err = DiffManifests(oldManifest, rendered.Manifest, param.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// render 以 client dry-run 渲染 chart，返回集群中当前 release（不存在时为 nil）与渲染结果
+func (d *HelmDeployer) render(ctx context.Context, param *DeploymentParam) (current, rendered *release.Release, err error) {
+	restClientGetter, err := NewRESTClientGetter(param.Kubeconfig, param.Namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	actionConfig := new(action.Configuration)
+	if err = actionConfig.Init(restClientGetter, param.Namespace, "secret", logger.Sugar().Debugf); err != nil {
+		return nil, nil, err
+	}
+
+	ch, err := d.loadChart(param)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	historyClient := action.NewHistory(actionConfig)
+	historyClient.Max = 1
+	versions, err := historyClient.Run(param.ReleaseName)
+	if err != nil && err != driver.ErrReleaseNotFound {
+		return nil, nil, err
+	}
+	if len(versions) > 0 && versions[len(versions)-1].Info.Status != release.StatusUninstalled {
+		current = versions[len(versions)-1]
+	}
+
+	if current == nil {
+		client := action.NewInstall(actionConfig)
+		client.Namespace = param.Namespace
+		client.ReleaseName = param.ReleaseName
+		client.DryRun = true
+		client.DryRunOption = "client"
+		rendered, err = client.RunWithContext(ctx, ch, param.Values)
+	} else {
+		client := action.NewUpgrade(actionConfig)
+		client.Namespace = param.Namespace
+		client.DryRun = true
+		client.DryRunOption = "client"
+		rendered, err = client.RunWithContext(ctx, param.ReleaseName, ch, param.Values)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("helm dry-run 失败: %w", err)
+	}
+	return current, rendered, nil
+}
+
+// DiffManifests 按资源（kind/namespace/name）对比两份 manifest，返回资源变更与 unified diff
+func DiffManifests(oldManifest, newManifest, defaultNamespace string) (model.ManifestChanges, string, error) {
+	oldRes, err := splitResources(oldManifest, defaultNamespace)
+	if err != nil {
+		return nil, "", fmt.Errorf("解析当前 release manifest 失败: %w", err)
+	}
+	newRes, err := splitResources(newManifest, defaultNamespace)
+	if err != nil {
+		return nil, "", fmt.Errorf("解析渲染 manifest 失败: %w", err)
+	}
+
+	keys := make([]string, 0, len(oldRes)+len(newRes))
+	for k := range oldRes {
+		keys = append(keys, k)
+	}
+	for k := range newRes {
+		if _, ok := oldRes[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := model.ManifestChanges{}
+	var sb strings.Builder
+	for _, k := range keys {
+		o, hasOld := oldRes[k]
+		n, hasNew := newRes[k]
+		var action string
+		var ref manifestResource
+		switch {
+		case !hasOld:
+			action, ref = constants.ManifestChangeAdded, n
+		case !hasNew:
+			action, ref = constants.ManifestChangeRemoved, o
+		case o.content != n.content:
+			action, ref = constants.ManifestChangeChanged, n
+		default:
+			continue
+		}
+		changes = append(changes, model.ManifestChange{
+			Kind:      ref.kind,
+			Namespace: ref.namespace,
+			Name:      ref.name,
+			Action:    action,
+			Protected: ProtectedKinds[ref.kind],
+		})
+
+		text, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        o.lines(),
+			B:        n.lines(),
+			FromFile: k,
+			ToFile:   k,
+			Context:  3,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+		sb.WriteString(text)
+	}
+	return changes, sb.String(), nil
+}
+
+type manifestResource struct {
+	kind      string
+	namespace string
+	name      string
+	content   string
+}
+
+func (r manifestResource) lines() []string {
+	if r.content == "" {
+		return nil
+	}
+	return difflib.SplitLines(r.content)
+}
+
+// splitResources 拆分 manifest 为资源，key 为 kind/namespace/name
+func splitResources(manifest, defaultNamespace string) (map[string]manifestResource, error) {
+	out := make(map[string]manifestResource)
+	for _, doc := range releaseutil.SplitManifests(manifest) {
+		var head struct {
+			Kind     string `yaml:"kind"`
+			Metadata struct {
+				Name      string `yaml:"name"`
+				Namespace string `yaml:"namespace"`
+			} `yaml:"metadata"`
+		}
+		if err := yaml.Unmarshal([]byte(doc), &head); err != nil {
+			return nil, err
+		}
+		if head.Kind == "" {
+			continue
+		}
+		ns := head.Metadata.Namespace
+		if ns == "" && head.Kind != "CustomResourceDefinition" {
+			ns = defaultNamespace
+		}
+		key := fmt.Sprintf("%s/%s/%s", head.Kind, ns, head.Metadata.Name)
+		out[key] = manifestResource{kind: head.Kind, namespace: ns, name: head.Metadata.Name, content: strings.TrimSpace(doc)}
+	}
+	return out, nil
+}
@@ -5,14 +5,13 @@ import (
 	"devops-cd/internal/core/deployment/helpers/tpl"
 	"devops-cd/internal/core/deployment/plan/drivers"
 	"devops-cd/internal/pkg/logger"
-	"encoding/json"
 	"fmt"
-	"strconv"
 	"strings"
 
 	"devops-cd/internal/model"
-	"devops-cd/internal/pkg/crypto"
+	"devops-cd/pkg/constants"
 
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/release"
@@ -25,6 +24,10 @@ type ExecutePayload struct {
 	ProjectCfg *model.ProjectEnvConfig
 	Artifacts  *model.ArtifactsV1
 	TplOptions *tpl.ContextOptions
+	ChartLock  *model.ChartLock // 发布应用的 chart lock（自动回滚、预览等场景为空）
+
+	ManifestLock   *model.ManifestLock // 发布应用生效的 manifest lock，app_chart 的应用 values 层以清单为准（自动回滚、临时部署等场景为空）
+	ValuesOverride string              // 发布应用的 values 覆盖，仅合并到 app_chart（自动回滚、临时部署等场景为空）
 }
 
 type Driver struct {
@@ -45,9 +48,9 @@ func (d *Driver) Execute(ctx context.Context, req *drivers.ExecuteRequest) (*dri
 
 	switch req.Stage {
 	case drivers.StagePre:
-		return d.execChart(ctx, req.Namespace, p, p.Artifacts.ConfigChart, "config_chart")
+		return d.execChart(ctx, req, p, p.Artifacts.ConfigChart, "config_chart")
 	case drivers.StageMain:
-		return d.execChart(ctx, req.Namespace, p, p.Artifacts.AppChart, "app_chart")
+		return d.execChart(ctx, req, p, p.Artifacts.AppChart, "app_chart")
 	default:
 		return nil, fmt.Errorf("helm driver: unknown stage: %s", req.Stage)
 	}
@@ -68,11 +71,11 @@ func (d *Driver) CheckStatus(ctx context.Context, req *drivers.ExecuteRequest) (
 	if strings.TrimSpace(dep.Namespace) == "" || strings.TrimSpace(dep.DeploymentName) == "" {
 		return nil, fmt.Errorf("helm CheckStatus: namespace/deployment_name 为空")
 	}
-	if dep.Cluster == nil || strings.TrimSpace(dep.Cluster.Kubeconfig) == "" {
-		return nil, fmt.Errorf("helm CheckStatus: cluster/kubeconfig 为空（需要 Preload Cluster）")
+	if !dep.Cluster.HasCredentials() {
+		return nil, fmt.Errorf("helm CheckStatus: cluster/连接凭据为空（需要 Preload Cluster）")
 	}
 
-	restClientGetter, err := NewRESTClientGetter(dep.Cluster.Kubeconfig, dep.Namespace)
+	restClientGetter, err := NewClusterRESTClientGetter(dep.Cluster, dep.Namespace)
 	if err != nil {
 		return nil, err
 	}
@@ -109,11 +112,70 @@ func (d *Driver) CheckStatus(ctx context.Context, req *drivers.ExecuteRequest) (
 	}
 }
 
-func (d *Driver) execChart(ctx context.Context, namespace string, p *ExecutePayload, stage *model.StageSpecV1, kind string) (*drivers.ExecuteResult, error) {
+func (d *Driver) execChart(ctx context.Context, req *drivers.ExecuteRequest, p *ExecutePayload, stage *model.StageSpecV1, kind string) (*drivers.ExecuteResult, error) {
 	if stage == nil || !stage.Enabled {
 		return drivers.Success(), nil
 	}
 
+	param, err := d.ResolveDeploymentParam(ctx, req.Namespace, p, stage, kind)
+	if err != nil {
+		return nil, err
+	}
+	req.Report(constants.DeploymentEventChartRendered, fmt.Sprintf("%s: chart %s %s 渲染完成（values %d 项）", kind, param.ChartName, param.ChartVersion, len(param.Values)))
+	req.Report(constants.DeploymentEventDeployStarted, fmt.Sprintf("%s: helm install/upgrade release %s（namespace %s）", kind, param.ReleaseName, param.Namespace))
+	rel, err := NewHelmDeployer(nil).Deploy(ctx, param)
+	if err := d.recordChartDigest(ctx, p.Deployment, param.ChartDigest); err != nil {
+		logger.Warn("记录 chart_digest 失败", zap.Int64("deployment_id", p.Deployment.ID), zap.Error(err))
+	}
+	if err != nil {
+		return drivers.Failed(err.Error()), err
+	}
+	// 只记录与 Deployment 类型对应的 chart（app Deployment 兼容执行的 config chart 不记录）
+	if (kind == "config_chart") == (p.Deployment.Kind == constants.DeploymentKindConfig) {
+		if err := d.recordReleaseSnapshot(ctx, p.Deployment, rel); err != nil {
+			logger.Warn("记录 release 快照失败", zap.Int64("deployment_id", p.Deployment.ID), zap.Error(err))
+		}
+	}
+	return drivers.Success(), nil
+}
+
+// recordReleaseSnapshot 记录写入的 release revision 与 values/manifest 摘要（按列更新），作为配置漂移检测的基准
+func (d *Driver) recordReleaseSnapshot(ctx context.Context, dep *model.Deployment, rel *release.Release) error {
+	if rel == nil || dep.ID == 0 {
+		return nil
+	}
+	valuesDigest, err := ValuesDigest(rel.Config)
+	if err != nil {
+		return err
+	}
+	manifestDigest := ManifestDigest(rel.Manifest)
+	if err := d.db.WithContext(ctx).Table(model.DeploymentTableName).Where("id = ?", dep.ID).
+		Updates(map[string]any{"release_revision": rel.Version, "values_digest": valuesDigest, "manifest_digest": manifestDigest}).Error; err != nil {
+		return err
+	}
+	dep.ReleaseRevision, dep.ValuesDigest, dep.ManifestDigest = &rel.Version, &valuesDigest, &manifestDigest
+	return nil
+}
+
+// recordChartDigest 记录部署的 chart digest（按列更新，与 git_revision 一致避免整行 Save 覆盖）
+func (d *Driver) recordChartDigest(ctx context.Context, dep *model.Deployment, digest string) error {
+	if digest == "" || dep.ID == 0 || (dep.ChartDigest != nil && *dep.ChartDigest == digest) {
+		return nil
+	}
+	if err := d.db.WithContext(ctx).Table(model.DeploymentTableName).
+		Where("id = ?", dep.ID).Update("chart_digest", digest).Error; err != nil {
+		return err
+	}
+	dep.ChartDigest = &digest
+	return nil
+}
+
+// ResolveDeploymentParam 解析 chart/release/values，生成 helm 部署参数
+func (d *Driver) ResolveDeploymentParam(ctx context.Context, namespace string, p *ExecutePayload, stage *model.StageSpecV1, kind string) (*DeploymentParam, error) {
+	if stage == nil || !stage.Enabled {
+		return nil, fmt.Errorf("%s 未启用", kind)
+	}
+
 	dep := p.Deployment
 	app := p.App
 	build := p.Build
@@ -125,29 +187,37 @@ func (d *Driver) execChart(ctx context.Context, namespace string, p *ExecutePayl
 		return nil, err
 	}
 
-	chartNameTpl := cfg.ChartNameTemplate
-	if chartNameTpl == "" {
-		chartNameTpl = "{{.app_type}}"
-	}
-	chartName, err := tpl.ParseTemplate(chartNameTpl, tplCtx)
+	chartName, chartVersion, err := ResolveChart(cfg, tplCtx, kind)
 	if err != nil {
-		return nil, fmt.Errorf("%s: chart_name_template 解析失败: %w", kind, err)
+		return nil, err
 	}
-
-	var chartVersion string
-	if strings.TrimSpace(cfg.ChartVersionTemplate) != "" {
-		chartVersion, err = tpl.ParseTemplate(cfg.ChartVersionTemplate, tplCtx)
-		if err != nil {
-			return nil, fmt.Errorf("%s: chart_version_template 解析失败: %w", kind, err)
-		}
+	// chart lock：使用封板时锁定的版本，保证 pre 与 prod 部署同一份 chart
+	lock := p.ChartLock.Entry(build.ID, kind)
+	if lock.Matches(cfg.RepoURL, chartName) {
+		chartVersion = lock.Version
+	} else {
+		lock = nil
 	}
 
 	// values：由 helm driver 运行时计算（不落库）
-	valuesMap, err := ParseValuesV1(d.db, app, build, dep.Env, dep.ClusterName, cfg.Values, p.TplOptions)
+	layers, err := d.StageValuesLayers(app.ID, dep.Env, dep.ClusterName, cfg, kind, p.ManifestLock)
+	if err != nil {
+		return nil, err
+	}
+	var override string
+	if kind == "app_chart" {
+		override = p.ValuesOverride
+	}
+	valuesMap, err := ParseValuesV1(ctx, d.db, app, build, dep.Env, dep.ClusterName, layers, override, p.TplOptions)
 	if err != nil {
 		return nil, fmt.Errorf("%s: values 计算失败: %w", kind, err)
 	}
 
+	kubeconfig, err := ClusterKubeconfig(dep.Cluster)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", kind, err)
+	}
+
 	// release name
 	var releaseName string
 	if releaseName, err = tpl.ParseTemplate(cfg.ReleaseNameTemplate, tplCtx); err != nil {
@@ -163,51 +233,122 @@ func (d *Driver) execChart(ctx context.Context, namespace string, p *ExecutePayl
 		Env:         dep.Env,
 		Namespace:   namespace,
 
-		Kubeconfig: dep.Cluster.Kubeconfig,
+		Kubeconfig: kubeconfig,
 
 		ChartName:    chartName,
 		ChartVersion: chartVersion,
 		ChartRepoURL: cfg.RepoURL,
+
+		ChartSourceType: cfg.ChartSourceType,
+		ChartPlainHTTP:  cfg.PlainHTTP,
+	}
+	// 重试同一 deployment 时按已记录的 digest 拉取，其次使用 chart lock 的 digest，避免同一版本被覆盖推送后部署内容不一致
+	if dep.ChartDigest != nil && *dep.ChartDigest != "" {
+		param.ChartDigest = *dep.ChartDigest
+	} else if lock != nil {
+		param.ChartDigest = lock.Digest
 	}
 
 	// chart repo 认证（v1：仅 basic_auth；credential_ref 支持 "id:123" 或 "123"）
 	if strings.TrimSpace(cfg.CredentialRef) != "" {
-		if u, p, err := d.resolveBasicAuth(cfg.CredentialRef); err == nil {
+		if u, p, err := d.ResolveBasicAuth(cfg.CredentialRef); err == nil {
 			param.ChartUsername = u
 			param.ChartPassword = p
 		}
 	}
 
-	if err := NewHelmDeployer(nil).Deploy(ctx, &param); err != nil {
-		return drivers.Failed(err.Error()), err
+	return &param, nil
+}
+
+// ResolveChart 解析 chart 名称与版本模板（chart_name_template 默认 {{.app_type}}，版本模板为空时返回空）
+func ResolveChart(cfg *Config, tplCtx map[string]interface{}, kind string) (chartName, chartVersion string, err error) {
+	chartNameTpl := cfg.ChartNameTemplate
+	if chartNameTpl == "" {
+		chartNameTpl = "{{.app_type}}"
 	}
-	return drivers.Success(), nil
+	if chartName, err = tpl.ParseTemplate(chartNameTpl, tplCtx); err != nil {
+		return "", "", fmt.Errorf("%s: chart_name_template 解析失败: %w", kind, err)
+	}
+	if strings.TrimSpace(cfg.ChartVersionTemplate) != "" {
+		if chartVersion, err = tpl.ParseTemplate(cfg.ChartVersionTemplate, tplCtx); err != nil {
+			return "", "", fmt.Errorf("%s: chart_version_template 解析失败: %w", kind, err)
+		}
+	}
+	return chartName, chartVersion, nil
 }
 
-func (d *Driver) resolveBasicAuth(ref string) (string, string, error) {
-	ref = strings.TrimSpace(ref)
-	if ref == "" {
-		return "", "", nil
+// ResolveChartLock 解析 stage 的 chart 并查询仓库得到确切版本与 digest（封板时固化为 chart lock）
+func (d *Driver) ResolveChartLock(ctx context.Context, p *ExecutePayload, stage *model.StageSpecV1, kind string) (*model.ChartLockEntry, error) {
+	cfg, err := DecodeConfig(stage.Data)
+	if err != nil {
+		return nil, err
+	}
+	tplCtx := tpl.RenderTemplateContext(p.App, p.Build, p.Deployment.Env, p.Deployment.ClusterName, p.TplOptions)
+	chartName, chartVersion, err := ResolveChart(cfg, tplCtx, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	param := &DeploymentParam{
+		AppType:         p.App.AppType,
+		ChartName:       chartName,
+		ChartVersion:    chartVersion,
+		ChartRepoURL:    cfg.RepoURL,
+		ChartSourceType: cfg.ChartSourceType,
+		ChartPlainHTTP:  cfg.PlainHTTP,
 	}
-	idStr := ref
-	if strings.HasPrefix(ref, "id:") {
-		idStr = strings.TrimPrefix(ref, "id:")
+	if strings.TrimSpace(cfg.CredentialRef) != "" {
+		if param.ChartUsername, param.ChartPassword, err = d.ResolveBasicAuth(cfg.CredentialRef); err != nil {
+			return nil, fmt.Errorf("%s: 解析 chart 仓库凭据失败: %w", kind, err)
+		}
 	}
-	id, err := strconv.ParseInt(idStr, 10, 64)
+	version, digest, err := resolveChartVersion(param)
 	if err != nil {
-		return "", "", err
+		return nil, fmt.Errorf("%s: %w", kind, err)
 	}
-	var c model.Credential
-	if err := d.db.First(&c, id).Error; err != nil {
+	return &model.ChartLockEntry{RepoURL: cfg.RepoURL, ChartName: chartName, Version: version, Digest: digest}, nil
+}
+
+// ResolveBasicAuth 解析 credential_ref（"id:123"、"123" 或外部密钥引用）对应的 basic auth 凭证
+func (d *Driver) ResolveBasicAuth(ref string) (string, string, error) {
+	cred, err := ResolveCredentialData(d.db, ref)
+	if err != nil || cred == nil {
 		return "", "", err
 	}
-	plain, err := crypto.Decrypt(c.EncryptedData)
+	return cred["username"], cred["password"], nil
+}
+
+// StageValuesLayers 阶段的 values 层（按合并顺序）：artifacts 中配置的层，app_chart 额外追加应用环境配置的层
+//
+// manifestLock 不为空且声明了 env/cluster 时，应用环境配置的层取清单中的定义
+func (d *Driver) StageValuesLayers(appID int64, env, cluster string, cfg *Config, kind string, manifestLock *model.ManifestLock) ([]model.ValuesLayer, error) {
+	if kind != "app_chart" {
+		return cfg.Values, nil
+	}
+	if c := manifestLock.Cluster(env, cluster); c != nil {
+		return append(append([]model.ValuesLayer{}, cfg.Values...), c.Values...), nil
+	}
+	appLayers, err := d.appEnvValuesLayers(appID, env, cluster)
 	if err != nil {
-		return "", "", err
+		return nil, fmt.Errorf("%s: 读取应用环境 values 失败: %w", kind, err)
 	}
-	var m map[string]string
-	if err := json.Unmarshal([]byte(plain), &m); err != nil {
-		return "", "", err
+	return append(append([]model.ValuesLayer{}, cfg.Values...), appLayers...), nil
+}
+
+// appEnvValuesLayers 应用在 env/cluster 上的附加 values 层（app_env_configs.config_data.values）
+func (d *Driver) appEnvValuesLayers(appID int64, env, cluster string) ([]model.ValuesLayer, error) {
+	var cfg model.AppEnvConfig
+	err := d.db.Where("app_id = ? AND env = ? AND cluster = ? AND deleted_at IS NULL", appID, env, cluster).
+		Limit(1).Find(&cfg).Error
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ID == 0 {
+		return nil, nil
+	}
+	data, err := cfg.ParseConfigData()
+	if err != nil {
+		return nil, fmt.Errorf("config_data 解析失败: %w", err)
 	}
-	return m["username"], m["password"], nil
+	return data.Values, nil
 }
@@ -3,10 +3,14 @@ package helm
 import (
 	"context"
 	"devops-cd/internal/pkg/logger"
+	"devops-cd/internal/pkg/tracing"
 	"devops-cd/internal/repository"
 	"fmt"
 	"hash/crc32"
+	"strings"
+	"sync"
 
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
@@ -28,42 +32,56 @@ const ConfigKeyChartRepoPassword = "helm.repo.password"
 
 var settings = cli.New()
 
+// repoLocks 按 repo url 串行化 index/chart 缓存文件的下载，避免多集群并发部署时互相覆盖
+var repoLocks sync.Map
+
+func lockRepo(url string) func() {
+	v, _ := repoLocks.LoadOrStore(url, &sync.Mutex{})
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
 func NewHelmDeployer(configRepository *repository.ConfigRepository) *HelmDeployer {
 	return &HelmDeployer{
 		configRepository: configRepository,
 	}
 }
 
-// Deploy install or upgrade a chart to kubernetes, 不处理chart的依赖关系
-func (d *HelmDeployer) Deploy(ctx context.Context, param *DeploymentParam) error {
+// Deploy install or upgrade a chart to kubernetes, 不处理chart的依赖关系；成功时返回写入的 release
+func (d *HelmDeployer) Deploy(ctx context.Context, param *DeploymentParam) (rel *release.Release, err error) {
+	ctx, span := tracing.Start(ctx, "helm.deploy",
+		attribute.String("helm.release", param.ReleaseName), attribute.String("k8s.namespace.name", param.Namespace),
+		attribute.String("helm.chart", param.ChartName), attribute.String("helm.chart_version", param.ChartVersion))
+	defer func() { tracing.End(span, err) }()
+
 	restClientGetter, err := NewRESTClientGetter(param.Kubeconfig, param.Namespace)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// 1. 初始化action config
 	actionConfig := new(action.Configuration)
 	if err = actionConfig.Init(restClientGetter, param.Namespace, "secret", logger.Sugar().Debugf); err != nil {
-		return err
+		return nil, err
 	}
 
 	// 2.1 加载chart
 	ch, err := d.loadChart(param)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// 2.2 取已合并后的values.yaml
 	vals := param.Values
 
 	// 3. upgrade or install
-	var rel *release.Release
-
 	historyClient := action.NewHistory(actionConfig)
 	historyClient.Max = 1
 	versions, err := historyClient.Run(param.ReleaseName)
 	if err == driver.ErrReleaseNotFound || (len(versions) > 0 && versions[len(versions)-1].Info.Status == release.StatusUninstalled) {
 		// If a release does not exist, we need to install it.
+		span.SetAttributes(attribute.String("helm.action", "install"))
 		client := action.NewInstall(actionConfig)
 		client.Namespace = param.Namespace
 		client.ReleaseName = param.ReleaseName
@@ -71,17 +89,18 @@ func (d *HelmDeployer) Deploy(ctx context.Context, param *DeploymentParam) error
 
 		rel, err = client.RunWithContext(ctx, ch, vals)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	} else {
 		// else, upgrade it
+		span.SetAttributes(attribute.String("helm.action", "upgrade"))
 		client := action.NewUpgrade(actionConfig)
 		client.Namespace = param.Namespace
 		//client.Atomic = true
 
 		rel, err = client.RunWithContext(ctx, param.ReleaseName, ch, vals)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -89,7 +108,7 @@ func (d *HelmDeployer) Deploy(ctx context.Context, param *DeploymentParam) error
 		zap.Any("manifest", rel.Manifest))
 	log.Debugf("Helm 部署成功! Release %s has been upgraded. Revision: %d Status:%v", rel.Name, rel.Version, rel.Info.Status)
 
-	return nil
+	return rel, nil
 }
 
 func (d *HelmDeployer) CheckStatus(ctx context.Context, param *DeploymentParam) (string, error) {
@@ -114,23 +133,47 @@ func (d *HelmDeployer) loadChart(param *DeploymentParam) (*chart.Chart, error) {
 		password = p
 	}
 
+	chartName := param.ChartName
+	if chartName == "" {
+		chartName = param.AppType
+	}
+
+	// OCI registry：按 tag（或已记录的 digest）拉取，回填 manifest digest 供 deployment 记录
+	if IsOCISource(param.ChartSourceType, url) {
+		ch, digest, err := pullOCIChart(url, username, password, chartName, param.ChartVersion, param.ChartDigest, param.ChartPlainHTTP)
+		if err != nil {
+			return nil, err
+		}
+		param.ChartDigest = digest
+		return ch, nil
+	}
+
+	// http(s) 仓库：index 与 chart 包经共享制品缓存拉取，大批次部署时不再重复下载
+	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+		chartPath, digest, err := locateChartCached(url, username, password, chartName, param.ChartVersion, param.ChartDigest)
+		if err != nil {
+			return nil, err
+		}
+		param.ChartDigest = digest
+		return loader.Load(chartPath)
+	}
+
 	chartPathOptions := action.ChartPathOptions{
-		RepoURL:  param.ChartRepoURL,
-		Username: param.ChartUsername,
-		Password: param.ChartPassword,
+		RepoURL:  url,
+		Username: username,
+		Password: password,
 		Version:  param.ChartVersion,
 	}
 
+	unlock := lockRepo(url)
+	defer unlock()
+
 	// 更新 repo
-	if _, err := d.updateRepo(param.ChartRepoURL, param.ChartUsername, param.ChartPassword); err != nil {
+	if _, err := d.updateRepo(url, username, password); err != nil {
 		return nil, err
 	}
 
 	// 加载 chart
-	chartName := param.ChartName
-	if chartName == "" {
-		chartName = param.AppType
-	}
 	chartPath, err := chartPathOptions.LocateChart(chartName, settings)
 	if err != nil {
 		return nil, err
@@ -142,6 +185,32 @@ func (d *HelmDeployer) loadChart(param *DeploymentParam) (*chart.Chart, error) {
 	return ch, nil
 }
 
+// resolveChartVersion 查询 chart 仓库得到确切版本与 digest（版本为空或为 semver 约束时取满足条件的最高版本）；
+// 仅支持 OCI 与 http(s) 仓库，其他来源（如未配置 repo_url 使用全局仓库）按模板版本返回，不记录 digest
+func resolveChartVersion(param *DeploymentParam) (string, string, error) {
+	url := strings.TrimSpace(param.ChartRepoURL)
+	chartName := param.ChartName
+	if chartName == "" {
+		chartName = param.AppType
+	}
+	switch {
+	case IsOCISource(param.ChartSourceType, url):
+		ch, digest, err := pullOCIChart(url, param.ChartUsername, param.ChartPassword, chartName, param.ChartVersion, "", param.ChartPlainHTTP)
+		if err != nil {
+			return "", "", err
+		}
+		return ch.Metadata.Version, digest, nil
+	case strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://"):
+		cv, err := lookupChartVersion(url, param.ChartUsername, param.ChartPassword, chartName, param.ChartVersion)
+		if err != nil {
+			return "", "", err
+		}
+		return cv.Version, cv.Digest, nil
+	default:
+		return param.ChartVersion, "", nil
+	}
+}
+
 func (d *HelmDeployer) updateRepo(url, username, password string) (string, error) {
 	if url == "" {
 		return "", fmt.Errorf("chart repo url 为空")
@@ -0,0 +1,99 @@
+package helm
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/registry"
+)
+
+// chart 来源类型（config.chart_source_type）
+const (
+	ChartSourceRepo = "repo" // 默认：http(s) chart 仓库（index.yaml）
+	ChartSourceOCI  = "oci"  // OCI registry（repo_url 形如 oci://harbor.example.com/charts）
+)
+
+// IsOCISource 是否从 OCI registry 拉取 chart：显式配置 chart_source_type=oci 或 repo_url 为 oci:// 前缀
+func IsOCISource(sourceType, repoURL string) bool {
+	return strings.TrimSpace(sourceType) == ChartSourceOCI || registry.IsOCI(strings.TrimSpace(repoURL))
+}
+
+// pullOCIChart 从 OCI registry 拉取 chart，返回 chart 与 manifest digest
+//
+// - 引用为 <repo_url>/<chart_name>，repo_url 可省略 oci:// 前缀
+// - digest 非空时按 digest 拉取（忽略 version），保证重试/重放使用同一份 chart
+// - version 为空或为 semver 约束时，按 registry tag 列表取满足条件的最高版本
+// - username/password 非空时以 basic auth 登录 registry（仅作用于本次拉取，不写入 helm 凭据文件）
+func pullOCIChart(repoURL, username, password, chartName, version, digest string, plainHTTP bool) (*chart.Chart, string, error) {
+	base := strings.TrimRight(strings.TrimPrefix(strings.TrimSpace(repoURL), registry.OCIScheme+"://"), "/")
+	if base == "" {
+		return nil, "", fmt.Errorf("oci chart 来源缺少 repo_url")
+	}
+	ref := base + "/" + chartName
+
+	opts := []registry.ClientOption{registry.ClientOptEnableCache(true)}
+	if username != "" || password != "" {
+		opts = append(opts, registry.ClientOptBasicAuth(username, password))
+	}
+	if plainHTTP {
+		opts = append(opts, registry.ClientOptPlainHTTP())
+	}
+	client, err := registry.NewClient(opts...)
+	if err != nil {
+		return nil, "", fmt.Errorf("初始化 OCI registry 客户端失败: %w", err)
+	}
+
+	if digest = strings.TrimSpace(digest); digest != "" {
+		ref += "@" + digest
+	} else {
+		tag, err := resolveOCITag(client, ref, version)
+		if err != nil {
+			return nil, "", err
+		}
+		// OCI tag 不支持 "+"，helm push 时以 "_" 代替
+		ref += ":" + strings.ReplaceAll(tag, "+", "_")
+	}
+
+	res, err := client.Pull(ref, registry.PullOptWithChart(true))
+	if err != nil {
+		return nil, "", fmt.Errorf("拉取 OCI chart %s 失败: %w", ref, err)
+	}
+	if res.Chart == nil || len(res.Chart.Data) == 0 {
+		return nil, "", fmt.Errorf("OCI chart %s 不包含 chart 层", ref)
+	}
+	ch, err := loader.LoadArchive(bytes.NewReader(res.Chart.Data))
+	if err != nil {
+		return nil, "", fmt.Errorf("解析 OCI chart %s 失败: %w", ref, err)
+	}
+	if res.Manifest == nil {
+		return ch, "", nil
+	}
+	return ch, res.Manifest.Digest, nil
+}
+
+// resolveOCITag 解析 chart tag：精确版本直接使用，否则按 tag 列表匹配
+func resolveOCITag(client *registry.Client, ref, version string) (string, error) {
+	version = strings.TrimSpace(version)
+	tags, err := client.Tags(ref)
+	if err != nil {
+		if version != "" {
+			// 无 tag 列表权限时按给定版本直接拉取
+			return version, nil
+		}
+		return "", fmt.Errorf("查询 OCI chart %s 版本失败: %w", ref, err)
+	}
+	if len(tags) == 0 {
+		if version != "" {
+			return version, nil
+		}
+		return "", fmt.Errorf("OCI chart %s 没有可用版本", ref)
+	}
+	tag, err := registry.GetTagMatchingVersionOrConstraint(tags, version)
+	if err != nil {
+		return "", fmt.Errorf("OCI chart %s 版本 %s 未找到: %w", ref, version, err)
+	}
+	return tag, nil
+}
@@ -0,0 +1,208 @@
+package helm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	authv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	PreflightCheckKubeconfigAuth = "kubeconfig_auth"
+	PreflightCheckNamespace      = "namespace"
+	PreflightCheckChartRepo      = "chart_repo"
+	PreflightCheckRegistryPull   = "registry_pull"
+
+	preflightCheckTimeout = 15 * time.Second
+)
+
+// PreflightParam 预检参数，为空的项跳过对应检查
+type PreflightParam struct {
+	Kubeconfig string
+	Namespace  string
+
+	ChartRepoURL  string
+	ChartUsername string
+	ChartPassword string
+
+	Image string // 完整镜像地址（含 tag），用于 dry-run 创建 Pod 校验拉取配置
+}
+
+// PreflightCheck 单项检查结果
+type PreflightCheck struct {
+	Name       string `json:"name"`
+	Passed     bool   `json:"passed"`
+	Skipped    bool   `json:"skipped"`
+	Message    string `json:"message,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// PreflightReport 预检报告
+type PreflightReport struct {
+	Passed bool             `json:"passed"`
+	Checks []PreflightCheck `json:"checks"`
+}
+
+// Err 汇总未通过的检查项，全部通过时返回 nil
+func (r *PreflightReport) Err() error {
+	var failed []string
+	for _, c := range r.Checks {
+		if !c.Passed && !c.Skipped {
+			failed = append(failed, fmt.Sprintf("%s: %s", c.Name, c.Message))
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("preflight 未通过: %s", strings.Join(failed, "; "))
+}
+
+// Preflight 部署前连通性预检：kubeconfig 认证、namespace 存在性、chart repo 可达性、镜像拉取（server dry-run）
+func Preflight(ctx context.Context, param *PreflightParam) *PreflightReport {
+	report := &PreflightReport{Passed: true}
+	add := func(c PreflightCheck) {
+		if !c.Passed && !c.Skipped {
+			report.Passed = false
+		}
+		report.Checks = append(report.Checks, c)
+	}
+
+	// 1. kubeconfig 认证
+	var cs *kubernetes.Clientset
+	add(runCheck(ctx, PreflightCheckKubeconfigAuth, func(ctx context.Context) error {
+		var err error
+		cs, err = newClientset(param.Kubeconfig, param.Namespace)
+		if err != nil {
+			return err
+		}
+		if _, err = cs.Discovery().ServerVersion(); err != nil {
+			return fmt.Errorf("访问 apiserver 失败: %w", err)
+		}
+		if strings.TrimSpace(param.Namespace) == "" {
+			return nil
+		}
+		// helm 使用 secret 存储 release，需具备 namespace 内 secret 的创建权限
+		review := &authv1.SelfSubjectAccessReview{
+			Spec: authv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authv1.ResourceAttributes{Namespace: param.Namespace, Verb: "create", Resource: "secrets"},
+			},
+		}
+		res, err := cs.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("权限检查失败: %w", err)
+		}
+		if !res.Status.Allowed {
+			return fmt.Errorf("无权在 namespace %s 中创建 secrets: %s", param.Namespace, res.Status.Reason)
+		}
+		return nil
+	}))
+
+	clusterOK := cs != nil && report.Passed
+
+	// 2. namespace 存在性
+	switch {
+	case strings.TrimSpace(param.Namespace) == "":
+		add(skipped(PreflightCheckNamespace, "未指定 namespace"))
+	case !clusterOK:
+		add(skipped(PreflightCheckNamespace, "kubeconfig 认证未通过"))
+	default:
+		add(runCheck(ctx, PreflightCheckNamespace, func(ctx context.Context) error {
+			if _, err := cs.CoreV1().Namespaces().Get(ctx, param.Namespace, metav1.GetOptions{}); err != nil {
+				return fmt.Errorf("namespace %s 不可用: %w", param.Namespace, err)
+			}
+			return nil
+		}))
+	}
+
+	// 3. chart repo 可达性
+	if strings.TrimSpace(param.ChartRepoURL) == "" {
+		add(skipped(PreflightCheckChartRepo, "未指定 chart repo"))
+	} else {
+		add(runCheck(ctx, PreflightCheckChartRepo, func(ctx context.Context) error {
+			unlock := lockRepo(param.ChartRepoURL)
+			defer unlock()
+			_, err := NewHelmDeployer(nil).updateRepo(param.ChartRepoURL, param.ChartUsername, param.ChartPassword)
+			return err
+		}))
+	}
+
+	// 4. 镜像拉取：server dry-run 创建 Pod，校验准入策略/镜像地址，不会真正拉取镜像
+	switch {
+	case strings.TrimSpace(param.Image) == "":
+		add(skipped(PreflightCheckRegistryPull, "未指定镜像"))
+	case !clusterOK || strings.TrimSpace(param.Namespace) == "":
+		add(skipped(PreflightCheckRegistryPull, "集群或 namespace 不可用"))
+	default:
+		add(runCheck(ctx, PreflightCheckRegistryPull, func(ctx context.Context) error {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{GenerateName: "devops-cd-preflight-", Namespace: param.Namespace},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:            "preflight",
+						Image:           param.Image,
+						ImagePullPolicy: corev1.PullAlways,
+					}},
+				},
+			}
+			_, err := cs.CoreV1().Pods(param.Namespace).Create(ctx, pod, metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}})
+			if err != nil {
+				return fmt.Errorf("dry-run 创建 Pod 失败: %w", err)
+			}
+			return nil
+		}))
+	}
+
+	return report
+}
+
+func newClientset(kubeconfig, namespace string) (*kubernetes.Clientset, error) {
+	if strings.TrimSpace(kubeconfig) == "" {
+		return nil, fmt.Errorf("kubeconfig 为空")
+	}
+	getter, err := NewRESTClientGetter(kubeconfig, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("解析 kubeconfig 失败: %w", err)
+	}
+	restConfig, err := getter.ToRESTConfig()
+	if err != nil {
+		return nil, fmt.Errorf("解析 kubeconfig 失败: %w", err)
+	}
+	restConfig.Timeout = preflightCheckTimeout
+	return kubernetes.NewForConfig(restConfig)
+}
+
+func runCheck(ctx context.Context, name string, fn func(ctx context.Context) error) PreflightCheck {
+	ctx, cancel := context.WithTimeout(ctx, preflightCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := fn(ctx)
+	c := PreflightCheck{Name: name, Passed: err == nil, DurationMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		c.Message = err.Error()
+	}
+	return c
+}
+
+func skipped(name, reason string) PreflightCheck {
+	return PreflightCheck{Name: name, Skipped: true, Message: reason}
+}
+
+// ImageRef 拼接镜像地址与 tag；imageURL 已带 tag/digest 时原样返回
+func ImageRef(imageURL, tag string) string {
+	imageURL = strings.TrimSpace(imageURL)
+	tag = strings.TrimSpace(tag)
+	if imageURL == "" || tag == "" || strings.Contains(imageURL, "@") {
+		return imageURL
+	}
+	if strings.Contains(imageURL[strings.LastIndex(imageURL, "/")+1:], ":") {
+		return imageURL
+	}
+	return imageURL + ":" + tag
+}
@@ -0,0 +1,419 @@
+package helm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/pkg/constants"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	releaseDriftTimeout = 60 * time.Second
+
+	// 每个资源最多记录的字段数 / values 最多记录的路径数，避免整体替换时结果过大
+	maxLiveDriftPaths   = 10
+	maxValuesDriftPaths = 20
+)
+
+// ReleaseSnapshot 部署时记录的 release 快照
+type ReleaseSnapshot struct {
+	Revision       int
+	ValuesDigest   string
+	ManifestDigest string
+}
+
+// ValuesDigest release values（user-supplied）的摘要，json 序列化时 map key 有序
+func ValuesDigest(values map[string]interface{}) (string, error) {
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ManifestDigest release manifest 的摘要
+func ManifestDigest(manifest string) string {
+	sum := sha256.Sum256([]byte(manifest))
+	return hex.EncodeToString(sum[:])
+}
+
+// DetectReleaseDrift 对比部署时的 release 快照与集群中当前 release（helm get values/manifest），live 为 true 时再逐个资源对比集群中的对象
+//
+// release 不存在（或已卸载）时 found=false；部署时的 revision 仍在 release 历史中时给出 values 路径与资源级差异，否则只按摘要判断
+func DetectReleaseDrift(ctx context.Context, cluster *model.Cluster, namespace, releaseName string, snap ReleaseSnapshot, live bool) (found bool, findings model.DriftFindings, err error) {
+	restClientGetter, err := NewClusterRESTClientGetter(cluster, namespace)
+	if err != nil {
+		return false, nil, err
+	}
+	actionConfig := new(action.Configuration)
+	if err = actionConfig.Init(restClientGetter, namespace, "secret", logger.Sugar().Debugf); err != nil {
+		return false, nil, err
+	}
+
+	current, err := action.NewStatus(actionConfig).Run(releaseName)
+	if err != nil {
+		if strings.Contains(err.Error(), driver.ErrReleaseNotFound.Error()) {
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+	if current.Info != nil && current.Info.Status == release.StatusUninstalled {
+		return false, nil, nil
+	}
+
+	base := current
+	if current.Version != snap.Revision {
+		actual := strconv.Itoa(current.Version)
+		if current.Info != nil {
+			actual = fmt.Sprintf("%d (%s)", current.Version, current.Info.Status)
+		}
+		findings = append(findings, model.DriftFinding{
+			Type:     constants.ReleaseDriftFindingRevision,
+			Expected: strconv.Itoa(snap.Revision),
+			Actual:   actual,
+		})
+		get := action.NewGet(actionConfig)
+		get.Version = snap.Revision
+		if base, err = get.Run(releaseName); err != nil {
+			// 部署时的 revision 已超出 release 历史保留数
+			base = nil
+		}
+	}
+
+	if base != nil && base != current {
+		for _, path := range diffValuePaths("", base.Config, current.Config, maxValuesDriftPaths) {
+			findings = append(findings, model.DriftFinding{Type: constants.ReleaseDriftFindingValues, Path: path})
+		}
+		changes, _, err := DiffManifests(base.Manifest, current.Manifest, namespace)
+		if err != nil {
+			return true, nil, err
+		}
+		for _, c := range changes {
+			findings = append(findings, model.DriftFinding{
+				Type:     constants.ReleaseDriftFindingManifest,
+				Resource: resourceName(c.Kind, c.Namespace, c.Name),
+				Actual:   c.Action,
+			})
+		}
+	} else {
+		if digest, err := ValuesDigest(current.Config); err != nil {
+			return true, nil, err
+		} else if digest != snap.ValuesDigest {
+			findings = append(findings, model.DriftFinding{Type: constants.ReleaseDriftFindingValues, Expected: snap.ValuesDigest, Actual: digest})
+		}
+		if digest := ManifestDigest(current.Manifest); digest != snap.ManifestDigest {
+			findings = append(findings, model.DriftFinding{Type: constants.ReleaseDriftFindingManifest, Expected: snap.ManifestDigest, Actual: digest})
+		}
+	}
+
+	if live {
+		liveFindings, err := liveDrift(ctx, restClientGetter, current.Manifest, namespace)
+		if err != nil {
+			return true, nil, err
+		}
+		findings = append(findings, liveFindings...)
+	}
+	return true, findings, nil
+}
+
+// diffValuePaths 两份 values 中不一致的叶子路径（只返回路径，不回传值，避免泄露敏感配置）
+func diffValuePaths(prefix string, a, b map[string]interface{}, limit int) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var paths []string
+	for _, k := range keys {
+		if len(paths) >= limit {
+			break
+		}
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		av, aok := a[k]
+		bv, bok := b[k]
+		am, amap := av.(map[string]interface{})
+		bm, bmap := bv.(map[string]interface{})
+		switch {
+		case aok && bok && amap && bmap:
+			paths = append(paths, diffValuePaths(path, am, bm, limit-len(paths))...)
+		case !aok || !bok || !jsonEqual(av, bv):
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+func jsonEqual(a, b interface{}) bool {
+	ab, err1 := json.Marshal(a)
+	bb, err2 := json.Marshal(b)
+	return err1 == nil && err2 == nil && string(ab) == string(bb)
+}
+
+// liveDrift 逐个资源对比 release manifest 与集群中的对象：manifest 中声明的字段在集群中被修改/删除即为漂移
+//
+// 只对比 manifest 声明的字段（apiserver 默认值、控制器追加的字段不算漂移）；Secret 不读取；
+// manifest 中存在 HorizontalPodAutoscaler 时忽略 spec.replicas
+func liveDrift(ctx context.Context, getter *RESTClientGetter, manifest, namespace string) (model.DriftFindings, error) {
+	restConfig, err := getter.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	dyn, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := getter.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+
+	var objs []*unstructured.Unstructured
+	hasHPA := false
+	for _, doc := range releaseutil.SplitManifests(manifest) {
+		raw, err := utilyaml.ToJSON([]byte(doc))
+		if err != nil {
+			return nil, fmt.Errorf("解析 release manifest 失败: %w", err)
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(raw); err != nil || obj.GetKind() == "" {
+			continue
+		}
+		if obj.GetKind() == "HorizontalPodAutoscaler" {
+			hasHPA = true
+		}
+		objs = append(objs, obj)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, releaseDriftTimeout)
+	defer cancel()
+
+	var findings model.DriftFindings
+	for _, obj := range objs {
+		gvk := obj.GroupVersionKind()
+		if gvk.Kind == "Secret" {
+			continue
+		}
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, err
+		}
+		var ri dynamic.ResourceInterface = dyn.Resource(mapping.Resource)
+		ns := ""
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			if ns = obj.GetNamespace(); ns == "" {
+				ns = namespace
+			}
+			ri = dyn.Resource(mapping.Resource).Namespace(ns)
+		}
+		name := resourceName(gvk.Kind, ns, obj.GetName())
+
+		liveObj, err := ri.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if err != nil {
+			switch {
+			case apierrors.IsNotFound(err):
+				findings = append(findings, model.DriftFinding{Type: constants.ReleaseDriftFindingLive, Resource: name, Actual: "资源不存在"})
+			case apierrors.IsForbidden(err):
+				// 集群凭据无权读取该资源，跳过
+			default:
+				return nil, fmt.Errorf("读取 %s 失败: %w", name, err)
+			}
+			continue
+		}
+
+		var paths []string
+		for k, expected := range obj.Object {
+			switch k {
+			case "apiVersion", "kind", "status":
+				continue
+			case "metadata":
+				expectedMeta, _ := expected.(map[string]interface{})
+				liveMeta, _ := liveObj.Object["metadata"].(map[string]interface{})
+				for _, field := range []string{"labels", "annotations"} {
+					if v, ok := expectedMeta[field]; ok {
+						compareSubset("metadata."+field, v, liveMeta[field], &paths)
+					}
+				}
+				continue
+			}
+			compareSubset(k, expected, liveObj.Object[k], &paths)
+		}
+		sort.Strings(paths)
+		recorded := 0
+		for _, path := range paths {
+			if hasHPA && path == "spec.replicas" {
+				continue
+			}
+			if recorded >= maxLiveDriftPaths {
+				break
+			}
+			recorded++
+			findings = append(findings, model.DriftFinding{
+				Type:     constants.ReleaseDriftFindingLive,
+				Resource: name,
+				Path:     path,
+				Expected: scalarString(fieldAt(obj.Object, path)),
+				Actual:   scalarString(fieldAt(liveObj.Object, path)),
+			})
+		}
+	}
+	return findings, nil
+}
+
+// compareSubset expected 中声明的字段在 actual 中不一致时记录路径
+func compareSubset(path string, expected, actual interface{}, paths *[]string) {
+	switch ev := expected.(type) {
+	case map[string]interface{}:
+		av, ok := actual.(map[string]interface{})
+		if !ok {
+			if len(ev) > 0 {
+				*paths = append(*paths, path)
+			}
+			return
+		}
+		for k, v := range ev {
+			sub := path + "." + k
+			a, ok := av[k]
+			if !ok {
+				if !isEmptyValue(v) {
+					*paths = append(*paths, sub)
+				}
+				continue
+			}
+			compareSubset(sub, v, a, paths)
+		}
+	case []interface{}:
+		av, ok := actual.([]interface{})
+		if !ok || len(av) != len(ev) {
+			if len(ev) > 0 || len(av) > 0 {
+				*paths = append(*paths, path)
+			}
+			return
+		}
+		for i := range ev {
+			compareSubset(fmt.Sprintf("%s[%d]", path, i), ev[i], av[i], paths)
+		}
+	default:
+		if !scalarEqual(expected, actual) {
+			*paths = append(*paths, path)
+		}
+	}
+}
+
+// scalarEqual 标量比较：按字符串比较（兼容 int/float/字符串形式的数字），资源数量按 Quantity 比较（0.5 与 500m 相等）
+func scalarEqual(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return isEmptyValue(a) && isEmptyValue(b)
+	}
+	as, bs := fmt.Sprint(a), fmt.Sprint(b)
+	if as == bs {
+		return true
+	}
+	aq, err1 := resource.ParseQuantity(as)
+	bq, err2 := resource.ParseQuantity(bs)
+	return err1 == nil && err2 == nil && aq.Cmp(bq) == 0
+}
+
+// isEmptyValue apiserver 序列化时省略的零值
+func isEmptyValue(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return val == ""
+	case bool:
+		return !val
+	case int64:
+		return val == 0
+	case float64:
+		return val == 0
+	case map[string]interface{}:
+		return len(val) == 0
+	case []interface{}:
+		return len(val) == 0
+	}
+	return false
+}
+
+// fieldAt 按 compareSubset 生成的路径读取字段，路径不存在时返回 nil
+func fieldAt(obj map[string]interface{}, path string) interface{} {
+	var cur interface{} = obj
+	for _, part := range strings.Split(path, ".") {
+		name, indexes := part, []int(nil)
+		if i := strings.Index(part, "["); i >= 0 {
+			name = part[:i]
+			for _, idx := range strings.Split(strings.TrimSuffix(part[i+1:], "]"), "][") {
+				n, err := strconv.Atoi(idx)
+				if err != nil {
+					return nil
+				}
+				indexes = append(indexes, n)
+			}
+		}
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur = m[name]
+		for _, n := range indexes {
+			list, ok := cur.([]interface{})
+			if !ok || n >= len(list) {
+				return nil
+			}
+			cur = list[n]
+		}
+	}
+	return cur
+}
+
+// scalarString 漂移项展示的值：标量直接展示，map/list 不展示（内容较大）
+func scalarString(v interface{}) string {
+	switch v.(type) {
+	case nil, map[string]interface{}, []interface{}:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func resourceName(kind, namespace, name string) string {
+	if namespace == "" {
+		return kind + "/" + name
+	}
+	return kind + "/" + namespace + "/" + name
+}
@@ -0,0 +1,85 @@
+package helm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"devops-cd/internal/pkg/logger"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// GetReleaseManifest 查询 release 当前 revision 的 manifest；release 不存在时 found=false
+func GetReleaseManifest(kubeconfig, namespace, releaseName string) (manifest string, found bool, err error) {
+	if strings.TrimSpace(kubeconfig) == "" {
+		return "", false, fmt.Errorf("kubeconfig 为空")
+	}
+	restClientGetter, err := NewRESTClientGetter(kubeconfig, namespace)
+	if err != nil {
+		return "", false, err
+	}
+	actionConfig := new(action.Configuration)
+	if err = actionConfig.Init(restClientGetter, namespace, "secret", logger.Sugar().Debugf); err != nil {
+		return "", false, err
+	}
+
+	rel, err := action.NewStatus(actionConfig).Run(releaseName)
+	if err != nil {
+		if strings.Contains(err.Error(), driver.ErrReleaseNotFound.Error()) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return rel.Manifest, true, nil
+}
+
+// WorkloadPods 按 workload 的 selector 列出其 pod
+func WorkloadPods(ctx context.Context, clientset kubernetes.Interface, ref WorkloadRef) ([]corev1.Pod, error) {
+	var selector *metav1.LabelSelector
+	switch ref.Kind {
+	case "Deployment":
+		obj, err := clientset.AppsV1().Deployments(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		selector = obj.Spec.Selector
+	case "StatefulSet":
+		obj, err := clientset.AppsV1().StatefulSets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		selector = obj.Spec.Selector
+	case "DaemonSet":
+		obj, err := clientset.AppsV1().DaemonSets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		selector = obj.Spec.Selector
+	case "Job":
+		obj, err := clientset.BatchV1().Jobs(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		selector = obj.Spec.Selector
+	default:
+		return nil, fmt.Errorf("不支持的 workload 类型: %s", ref.Kind)
+	}
+	if selector == nil {
+		return nil, nil
+	}
+
+	sel, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := clientset.CoreV1().Pods(ref.Namespace).List(ctx, metav1.ListOptions{LabelSelector: sel.String()})
+	if err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
@@ -8,6 +8,9 @@ import (
 	"sort"
 	"strings"
 
+	"devops-cd/internal/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -105,6 +108,12 @@ type WorkloadCheckResult struct {
 // - anyFailed: at least one workload is in a terminal failed state
 // - message: aggregated status / reasons for non-ready or failed workloads
 func CheckReleaseWorkloadsReady(ctx context.Context, restClientGetter *RESTClientGetter, manifest string, defaultNamespace string) (allReady bool, anyFailed bool, message string, err error) {
+	ctx, span := tracing.Start(ctx, "k8s.readiness", attribute.String("k8s.namespace.name", defaultNamespace))
+	defer func() {
+		span.SetAttributes(attribute.Bool("readiness.ready", allReady), attribute.Bool("readiness.failed", anyFailed), attribute.String("readiness.message", message))
+		tracing.End(span, err)
+	}()
+
 	refs, err := ExtractWorkloadsFromManifest(manifest, defaultNamespace)
 	if err != nil {
 		return false, false, "", err
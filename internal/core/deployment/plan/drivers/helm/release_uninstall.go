@@ -0,0 +1,34 @@
+package helm
+
+import (
+	"strings"
+	"time"
+
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// UninstallRelease 卸载 release（不保留历史，不等待资源删除完成）；release 不存在时 found=false
+func UninstallRelease(cluster *model.Cluster, namespace, releaseName string, timeout time.Duration) (found bool, err error) {
+	restClientGetter, err := NewClusterRESTClientGetter(cluster, namespace)
+	if err != nil {
+		return false, err
+	}
+	actionConfig := new(action.Configuration)
+	if err = actionConfig.Init(restClientGetter, namespace, "secret", logger.Sugar().Debugf); err != nil {
+		return false, err
+	}
+
+	uninstall := action.NewUninstall(actionConfig)
+	uninstall.Timeout = timeout
+	if _, err = uninstall.Run(releaseName); err != nil {
+		if strings.Contains(err.Error(), driver.ErrReleaseNotFound.Error()) {
+			return false, nil
+		}
+		return true, err
+	}
+	return true, nil
+}
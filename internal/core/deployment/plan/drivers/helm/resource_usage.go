@@ -0,0 +1,141 @@
+package helm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CollectReleaseResources 汇总 helm release 中工作负载的副本数与 requests/limits
+// release 不存在时返回零值（视为首次安装）
+func CollectReleaseResources(ctx context.Context, kubeconfig, namespace, releaseName string) (*model.ResourceUsage, error) {
+	if strings.TrimSpace(kubeconfig) == "" {
+		return nil, fmt.Errorf("kubeconfig 为空")
+	}
+	restClientGetter, err := NewRESTClientGetter(kubeconfig, namespace)
+	if err != nil {
+		return nil, err
+	}
+	actionConfig := new(action.Configuration)
+	if err = actionConfig.Init(restClientGetter, namespace, "secret", logger.Sugar().Debugf); err != nil {
+		return nil, err
+	}
+
+	usage := &model.ResourceUsage{}
+	rel, err := action.NewStatus(actionConfig).Run(releaseName)
+	if err != nil {
+		if strings.Contains(err.Error(), driver.ErrReleaseNotFound.Error()) {
+			return usage, nil
+		}
+		return nil, err
+	}
+
+	refs, err := ExtractWorkloadsFromManifest(rel.Manifest, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if len(refs) == 0 {
+		return usage, nil
+	}
+
+	restCfg, err := restClientGetter.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ref := range refs {
+		replicas, spec, err := workloadPodSpec(ctx, clientset, ref)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", ref.Kind, ref.Name, err)
+		}
+		if spec == nil {
+			continue
+		}
+		usage.Workloads++
+		usage.Replicas += replicas
+		for _, c := range spec.Containers {
+			usage.CPURequestMilli += c.Resources.Requests.Cpu().MilliValue() * replicas
+			usage.CPULimitMilli += c.Resources.Limits.Cpu().MilliValue() * replicas
+			usage.MemoryRequestBytes += c.Resources.Requests.Memory().Value() * replicas
+			usage.MemoryLimitBytes += c.Resources.Limits.Memory().Value() * replicas
+		}
+	}
+	return usage, nil
+}
+
+// workloadPodSpec 返回工作负载的期望副本数与 Pod 模板（Job 不计入常驻资源）
+func workloadPodSpec(ctx context.Context, clientset *kubernetes.Clientset, ref WorkloadRef) (int64, *corev1.PodSpec, error) {
+	switch ref.Kind {
+	case "Deployment":
+		obj, err := clientset.AppsV1().Deployments(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return 0, nil, err
+		}
+		return int64(replicasOrDefault(obj.Spec.Replicas)), &obj.Spec.Template.Spec, nil
+	case "StatefulSet":
+		obj, err := clientset.AppsV1().StatefulSets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return 0, nil, err
+		}
+		return int64(replicasOrDefault(obj.Spec.Replicas)), &obj.Spec.Template.Spec, nil
+	case "DaemonSet":
+		obj, err := clientset.AppsV1().DaemonSets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return 0, nil, err
+		}
+		return int64(obj.Status.DesiredNumberScheduled), &obj.Spec.Template.Spec, nil
+	default:
+		return 0, nil, nil
+	}
+}
+
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// GetReleaseChartVersion 查询 helm release 当前的 chart 版本，release 不存在时 found=false
+func GetReleaseChartVersion(kubeconfig, namespace, releaseName string) (version string, found bool, err error) {
+	if strings.TrimSpace(kubeconfig) == "" {
+		return "", false, fmt.Errorf("kubeconfig 为空")
+	}
+	restClientGetter, err := NewRESTClientGetter(kubeconfig, namespace)
+	if err != nil {
+		return "", false, err
+	}
+	actionConfig := new(action.Configuration)
+	if err = actionConfig.Init(restClientGetter, namespace, "secret", logger.Sugar().Debugf); err != nil {
+		return "", false, err
+	}
+
+	rel, err := action.NewStatus(actionConfig).Run(releaseName)
+	if err != nil {
+		if strings.Contains(err.Error(), driver.ErrReleaseNotFound.Error()) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	if rel.Chart == nil || rel.Chart.Metadata == nil {
+		return "", true, nil
+	}
+	return rel.Chart.Metadata.Version, true, nil
+}
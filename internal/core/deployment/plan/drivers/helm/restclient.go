@@ -1,6 +1,11 @@
 package helm
 
 import (
+	"fmt"
+	"sort"
+
+	"devops-cd/internal/model"
+
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/discovery/cached/memory"
@@ -34,6 +39,77 @@ func NewRESTClientGetter(kubeconfig string, namespace string) (*RESTClientGetter
 	return &RESTClientGetter{clientconfig}, nil
 }
 
+// ClusterKubeconfig 按集群认证方式得到 kubeconfig：kubeconfig 方式原样返回，
+// token/exec 方式由 server + CA + 凭据生成单 context 的 kubeconfig（exec 插件不允许交互）
+func ClusterKubeconfig(cluster *model.Cluster) (string, error) {
+	if cluster == nil {
+		return "", fmt.Errorf("集群未配置")
+	}
+	if cluster.Auth() == model.ClusterAuthKubeconfig {
+		return cluster.Kubeconfig, nil
+	}
+	if err := cluster.ValidateAuth(); err != nil {
+		return "", fmt.Errorf("集群 %s 认证配置无效: %w", cluster.Name, err)
+	}
+
+	authInfo := api.NewAuthInfo()
+	switch cluster.Auth() {
+	case model.ClusterAuthToken:
+		authInfo.Token = cluster.Token
+	case model.ClusterAuthExec:
+		execCfg := cluster.Exec
+		apiVersion := execCfg.APIVersion
+		if apiVersion == "" {
+			apiVersion = model.DefaultExecAPIVersion
+		}
+		authInfo.Exec = &api.ExecConfig{
+			APIVersion:      apiVersion,
+			Command:         execCfg.Command,
+			Args:            execCfg.Args,
+			InteractiveMode: api.NeverExecInteractiveMode,
+		}
+		names := make([]string, 0, len(execCfg.Env))
+		for name := range execCfg.Env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			authInfo.Exec.Env = append(authInfo.Exec.Env, api.ExecEnvVar{Name: name, Value: execCfg.Env[name]})
+		}
+	}
+
+	clusterInfo := api.NewCluster()
+	clusterInfo.Server = cluster.Server
+	clusterInfo.CertificateAuthorityData = []byte(cluster.CAData)
+	clusterInfo.InsecureSkipTLSVerify = cluster.InsecureSkipTLSVerify
+
+	const name = "devops-cd"
+	kubeContext := api.NewContext()
+	kubeContext.Cluster = name
+	kubeContext.AuthInfo = name
+
+	config := api.NewConfig()
+	config.Clusters[name] = clusterInfo
+	config.AuthInfos[name] = authInfo
+	config.Contexts[name] = kubeContext
+	config.CurrentContext = name
+
+	data, err := clientcmd.Write(*config)
+	if err != nil {
+		return "", fmt.Errorf("生成集群 %s kubeconfig 失败: %w", cluster.Name, err)
+	}
+	return string(data), nil
+}
+
+// NewClusterRESTClientGetter 按集群认证方式创建 RESTClientGetter
+func NewClusterRESTClientGetter(cluster *model.Cluster, namespace string) (*RESTClientGetter, error) {
+	kubeconfig, err := ClusterKubeconfig(cluster)
+	if err != nil {
+		return nil, err
+	}
+	return NewRESTClientGetter(kubeconfig, namespace)
+}
+
 func (r *RESTClientGetter) ToRESTConfig() (*rest.Config, error) {
 	return r.clientconfig.ClientConfig()
 }
@@ -0,0 +1,149 @@
+package helm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	serverDryRunTimeout      = 60 * time.Second
+	serverDryRunFieldManager = "devops-cd-dry-run"
+)
+
+// ServerDryRunFailure 单个资源被 apiserver 拒绝的原因
+type ServerDryRunFailure struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Message   string `json:"message"`
+}
+
+// ServerDryRunResult 渲染结果逐个资源 server-side apply（dryRun=All）的结果
+type ServerDryRunResult struct {
+	Resources int                   `json:"resources"`
+	Skipped   []string              `json:"skipped,omitempty"` // 集群尚无对应 API（如同 chart 内新增的 CRD 实例）
+	Failures  []ServerDryRunFailure `json:"failures,omitempty"`
+}
+
+// Passed 是否全部通过
+func (r *ServerDryRunResult) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Message 汇总结果；失败时为准入 webhook / apiserver 返回的原因
+func (r *ServerDryRunResult) Message() string {
+	if r.Passed() {
+		msg := fmt.Sprintf("server dry-run 通过（%d 个资源）", r.Resources)
+		if len(r.Skipped) > 0 {
+			msg += fmt.Sprintf("，跳过: %s", strings.Join(r.Skipped, ", "))
+		}
+		return msg
+	}
+	parts := make([]string, 0, len(r.Failures))
+	for _, f := range r.Failures {
+		parts = append(parts, fmt.Sprintf("%s/%s: %s", f.Kind, f.Name, f.Message))
+	}
+	return fmt.Sprintf("server dry-run 未通过: %s", strings.Join(parts, "; "))
+}
+
+// ServerDryRun 渲染 app chart，并对目标集群做 server-side dry-run apply
+func (d *Driver) ServerDryRun(ctx context.Context, namespace string, p *ExecutePayload) (*ServerDryRunResult, error) {
+	if p == nil || p.Deployment == nil || p.App == nil || p.Build == nil || p.Artifacts == nil {
+		return nil, fmt.Errorf("helm driver: invalid payload")
+	}
+	param, err := d.ResolveDeploymentParam(ctx, namespace, p, p.Artifacts.AppChart, "app_chart")
+	if err != nil {
+		return nil, err
+	}
+	return NewHelmDeployer(nil).ServerDryRun(ctx, param)
+}
+
+// ServerDryRun 逐个资源以 dryRun=All 调用 server-side apply：
+// 经过完整的准入链（mutating/validating webhook、策略引擎）与字段校验，可提前发现 webhook 拒绝、不可变字段冲突，不写入集群
+func (d *HelmDeployer) ServerDryRun(ctx context.Context, param *DeploymentParam) (*ServerDryRunResult, error) {
+	_, rendered, err := d.render(ctx, param)
+	if err != nil {
+		return nil, err
+	}
+
+	restClientGetter, err := NewRESTClientGetter(param.Kubeconfig, param.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	restConfig, err := restClientGetter.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	dyn, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := restClientGetter.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, serverDryRunTimeout)
+	defer cancel()
+
+	result := &ServerDryRunResult{}
+	for _, doc := range releaseutil.SplitManifests(rendered.Manifest) {
+		raw, err := utilyaml.ToJSON([]byte(doc))
+		if err != nil {
+			return nil, fmt.Errorf("解析渲染 manifest 失败: %w", err)
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(raw); err != nil {
+			// 空文档（仅注释）等
+			continue
+		}
+		gvk := obj.GroupVersionKind()
+		if gvk.Kind == "" {
+			continue
+		}
+		result.Resources++
+
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			if meta.IsNoMatchError(err) {
+				result.Skipped = append(result.Skipped, fmt.Sprintf("%s/%s", gvk.Kind, obj.GetName()))
+				continue
+			}
+			return nil, err
+		}
+
+		var ri dynamic.ResourceInterface = dyn.Resource(mapping.Resource)
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			ns := obj.GetNamespace()
+			if ns == "" {
+				ns = param.Namespace
+				obj.SetNamespace(ns)
+			}
+			ri = dyn.Resource(mapping.Resource).Namespace(ns)
+		}
+		// Force: helm 以 client-side apply 管理字段，忽略 field manager 冲突，只关注准入与校验错误
+		_, err = ri.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{
+			FieldManager: serverDryRunFieldManager,
+			Force:        true,
+			DryRun:       []string{metav1.DryRunAll},
+		})
+		if err != nil {
+			result.Failures = append(result.Failures, ServerDryRunFailure{
+				Kind:      gvk.Kind,
+				Namespace: obj.GetNamespace(),
+				Name:      obj.GetName(),
+				Message:   err.Error(),
+			})
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,32 @@
+package helm
+
+import (
+	"context"
+	"fmt"
+
+	"devops-cd/internal/pkg/logger"
+
+	"helm.sh/helm/v3/pkg/action"
+)
+
+// Template 本地渲染 chart（等价于 helm template），不访问集群
+func (d *HelmDeployer) Template(ctx context.Context, param *DeploymentParam) (string, error) {
+	ch, err := d.loadChart(param)
+	if err != nil {
+		return "", err
+	}
+
+	client := action.NewInstall(&action.Configuration{Log: logger.Sugar().Debugf})
+	client.Namespace = param.Namespace
+	client.ReleaseName = param.ReleaseName
+	client.DryRun = true
+	client.DryRunOption = "client"
+	client.ClientOnly = true
+	client.Replace = true
+	client.IncludeCRDs = true
+	rel, err := client.RunWithContext(ctx, ch, param.Values)
+	if err != nil {
+		return "", fmt.Errorf("helm template 失败: %w", err)
+	}
+	return rel.Manifest, nil
+}
@@ -1,66 +1,129 @@
 package helm
 
 import (
-	"bytes"
+	"context"
+	"devops-cd/internal/core/common/artifactcache"
 	"devops-cd/internal/core/common/valueslayer"
 	"devops-cd/internal/core/deployment/helpers/tpl"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
 
 	"devops-cd/internal/model"
 	"devops-cd/internal/pkg/crypto"
+	"devops-cd/internal/pkg/secrets"
+	"devops-cd/internal/pkg/tracing"
 
+	"go.opentelemetry.io/otel/attribute"
 	"gopkg.in/yaml.v3"
 	"gorm.io/gorm"
 )
 
 // ParseValuesV1 根据 artifacts_json 中 values[] 生成最终 values map（后者覆盖前者）
-func ParseValuesV1(db *gorm.DB, app *model.Application, build *model.Build, env string, cluster string, layers []model.ValuesLayer, tplOpts *tpl.ContextOptions) (map[string]interface{}, error) {
-	ctx := tpl.RenderTemplateContext(app, build, env, cluster, tplOpts)
+//
+// override 为发布应用的 values 覆盖（YAML/JSON），在运行时注入的 image.tag 之后最后合并；为空表示无覆盖
+func ParseValuesV1(ctx context.Context, db *gorm.DB, app *model.Application, build *model.Build, env string, cluster string, layers []model.ValuesLayer, override string, tplOpts *tpl.ContextOptions) (map[string]interface{}, error) {
+	merged, _, err := RenderValuesV1(ctx, db, app, build, env, cluster, layers, override, tplOpts)
+	return merged, err
+}
+
+// LayerValues 单层 values 的解析结果（render-values 预览用）
+type LayerValues struct {
+	Index    int                    // 在 layers 中的下标；运行时注入层为 len(layers)
+	Runtime  bool                   // 运行时注入（image.tag），非配置的层
+	Override bool                   // 发布应用的 values 覆盖，下标为 len(layers)+1
+	Empty    bool                   // 内容为空，不参与合并
+	Values   map[string]interface{} // 该层解析后的内容
+}
+
+// RenderValuesV1 与 ParseValuesV1 相同的合并过程，额外返回各层解析结果；
+// 某层失败时返回已解析的层与错误，merged 为 nil
+func RenderValuesV1(ctx context.Context, db *gorm.DB, app *model.Application, build *model.Build, env string, cluster string, layers []model.ValuesLayer, override string, tplOpts *tpl.ContextOptions) (_ map[string]interface{}, results []LayerValues, err error) {
+	ctx, span := tracing.Start(ctx, "values.resolve", attribute.String("deployment.env", env), attribute.String("deployment.cluster", cluster),
+		attribute.Int("values.layers", len(layers)))
+	defer func() { tracing.End(span, err) }()
+
+	tplCtx := tpl.RenderTemplateContext(app, build, env, cluster, tplOpts)
 
 	merged := map[string]interface{}{}
 	for idx, layer := range layers {
-		content, err := loadValuesLayerContent(db, ctx, layer)
+		content, err := LoadLayerContent(ctx, db, tplCtx, layer)
 		if err != nil {
-			return nil, fmt.Errorf("values[%d] 加载失败: %w", idx, err)
+			return nil, results, fmt.Errorf("values[%d] 加载失败: %w", idx, err)
 		}
 		if strings.TrimSpace(string(content)) == "" {
+			results = append(results, LayerValues{Index: idx, Empty: true})
 			continue
 		}
 
 		var obj interface{}
 		if err := yaml.Unmarshal(content, &obj); err != nil {
-			return nil, fmt.Errorf("values[%d] YAML 解析失败: %w", idx, err)
+			return nil, results, fmt.Errorf("values[%d] YAML 解析失败: %w", idx, err)
 		}
 		m, ok := normalizeYAMLToStringMap(obj).(map[string]interface{})
 		if !ok {
-			return nil, fmt.Errorf("values[%d] YAML 顶层必须是 map/object", idx)
+			return nil, results, fmt.Errorf("values[%d] YAML 顶层必须是 map/object", idx)
+		}
+		layerValues, err := marshalMeta(m)
+		if err != nil {
+			return nil, results, fmt.Errorf("values[%d] 转换失败: %w", idx, err)
 		}
+		results = append(results, LayerValues{Index: idx, Values: layerValues})
 		merged = deepMerge(merged, m)
 	}
 
 	// 运行时注入 image.tag（保持旧逻辑）
 	if build != nil && build.ImageTag != "" {
-		merged = deepMerge(merged, map[string]interface{}{
+		runtime := map[string]interface{}{
 			"image": map[string]interface{}{
 				"tag": build.ImageTag,
 			},
-		})
+		}
+		merged = deepMerge(merged, runtime)
+		results = append(results, LayerValues{Index: len(layers), Runtime: true, Values: runtime})
 	}
 
-	return marshalMeta(merged)
+	// 发布应用的 values 覆盖（封板前设置），最后合并
+	if strings.TrimSpace(override) != "" {
+		m, err := ParseValuesOverride(override)
+		if err != nil {
+			return nil, results, fmt.Errorf("values 覆盖: %w", err)
+		}
+		overrideValues, err := marshalMeta(m)
+		if err != nil {
+			return nil, results, fmt.Errorf("values 覆盖转换失败: %w", err)
+		}
+		results = append(results, LayerValues{Index: len(layers) + 1, Override: true, Values: overrideValues})
+		merged = deepMerge(merged, m)
+	}
+
+	merged, err = marshalMeta(merged)
+	return merged, results, err
 }
 
-// loadValuesLayerContent 加载某一层 values 的 YAML 内容
-func loadValuesLayerContent(db *gorm.DB, ctx map[string]interface{}, layer model.ValuesLayer) ([]byte, error) {
-	cred, err := resolveCredentialData(db, layer.CredentialRef)
+// ParseValuesOverride 解析发布应用的 values 覆盖（YAML，JSON 亦为合法 YAML），顶层必须是 map/object
+func ParseValuesOverride(content string) (map[string]interface{}, error) {
+	var obj interface{}
+	if err := yaml.Unmarshal([]byte(content), &obj); err != nil {
+		return nil, fmt.Errorf("YAML/JSON 解析失败: %w", err)
+	}
+	m, ok := normalizeYAMLToStringMap(obj).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("顶层必须是 map/object")
+	}
+	return m, nil
+}
+
+// LoadLayerContent 加载某一层（values / manifest 来源）的原始内容
+func LoadLayerContent(ctx context.Context, db *gorm.DB, tplCtx map[string]interface{}, layer model.ValuesLayer) (_ []byte, err error) {
+	ctx, span := tracing.Start(ctx, "values.layer", attribute.String("values.layer.type", layer.Type))
+	defer func() { tracing.End(span, err) }()
+
+	cred, err := ResolveCredentialData(db, layer.CredentialRef)
 	if err != nil {
 		return nil, err
 	}
@@ -73,7 +136,7 @@ func loadValuesLayerContent(db *gorm.DB, ctx map[string]interface{}, layer model
 		if baseTpl == "" {
 			return nil, fmt.Errorf("base_url_template 为空")
 		}
-		base, err := tpl.ParseTemplate(baseTpl, ctx)
+		base, err := tpl.ParseTemplate(baseTpl, tplCtx)
 		if err != nil {
 			return nil, err
 		}
@@ -85,27 +148,27 @@ func loadValuesLayerContent(db *gorm.DB, ctx map[string]interface{}, layer model
 		// 兼容无 path_template：base_url_template 既可作为 base，也可直接写完整 URL
 		pathTpl := strings.TrimSpace(layer.PathTemplate)
 		if pathTpl == "" {
-			return httpGet(base, cred)
+			return httpGet(ctx, base, cred)
 		}
-		p, err := tpl.ParseTemplate(pathTpl, ctx)
+		p, err := tpl.ParseTemplate(pathTpl, tplCtx)
 		if err != nil {
 			return nil, err
 		}
 		p = strings.TrimSpace(p)
 		if p == "" {
-			return httpGet(base, cred)
+			return httpGet(ctx, base, cred)
 		}
 		if strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://") {
 			return nil, fmt.Errorf("path_template 不允许是完整 URL")
 		}
 
 		finalURL := strings.TrimRight(base, "/") + "/" + strings.TrimLeft(p, "/")
-		return httpGet(finalURL, cred)
+		return httpGet(ctx, finalURL, cred)
 	case "file":
-		return valueslayer.LoadFileLayer(layer.BaseURLTemplate, layer.PathTemplate, func(t string) (string, error) {
-			return tpl.ParseTemplate(t, ctx)
+		return valueslayer.LoadFileLayer(ctx, layer.BaseURLTemplate, layer.PathTemplate, func(t string) (string, error) {
+			return tpl.ParseTemplate(t, tplCtx)
 		}, func(req *http.Request) {
-			applyHTTPAuth(req, cred)
+			ApplyHTTPAuth(req, cred)
 		})
 	case "git":
 		repo := strings.TrimSpace(layer.RepoURL)
@@ -116,7 +179,7 @@ func loadValuesLayerContent(db *gorm.DB, ctx map[string]interface{}, layer model
 		if ref == "" {
 			ref = "main"
 		} else {
-			r, err := tpl.ParseTemplate(ref, ctx)
+			r, err := tpl.ParseTemplate(ref, tplCtx)
 			if err != nil {
 				return nil, err
 			}
@@ -126,94 +189,29 @@ func loadValuesLayerContent(db *gorm.DB, ctx map[string]interface{}, layer model
 		if pathTpl == "" {
 			return nil, fmt.Errorf("path_template 为空")
 		}
-		relPath, err := tpl.ParseTemplate(pathTpl, ctx)
+		relPath, err := tpl.ParseTemplate(pathTpl, tplCtx)
 		if err != nil {
 			return nil, err
 		}
 
-		repoURL, env, cleanupKey, err := prepareGitAuth(repo, cred)
-		if err != nil {
-			return nil, err
-		}
-		defer cleanupKey()
-
-		dir, cleanup, err := gitCheckoutToTemp(repoURL, ref, env)
-		if err != nil {
-			return nil, err
-		}
-		defer cleanup()
-
-		abs := filepath.Join(dir, filepath.Clean(relPath))
-		// 防止路径穿越：要求最终路径仍在 dir 下
-		if !strings.HasPrefix(abs, dir+string(os.PathSeparator)) && abs != dir {
-			return nil, fmt.Errorf("path_template 非法: %s", relPath)
-		}
-		return os.ReadFile(abs)
+		span.SetAttributes(attribute.String("git.repo", repo), attribute.String("git.ref", ref))
+		return valueslayer.LoadGitLayer(ctx, valueslayer.GitLayer{
+			RepoURL:    repo,
+			Ref:        ref,
+			Path:       relPath,
+			Credential: cred,
+		})
 	default:
 		return nil, fmt.Errorf("不支持的 values type: %s", layer.Type)
 	}
 }
 
-func httpGet(url string, cred map[string]string) ([]byte, error) {
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
-	}
-	applyHTTPAuth(req, cred)
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		b, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
-	}
-	return io.ReadAll(resp.Body)
-}
-
-// gitCheckoutToTemp 以最小依赖方式调用系统 git 拉取指定 ref 到临时目录
-func gitCheckoutToTemp(repoURL, ref string, extraEnv []string) (dir string, cleanup func(), err error) {
-	base, err := os.MkdirTemp("", "devops-cd-values-*")
-	if err != nil {
-		return "", nil, err
-	}
-	cleanup = func() { _ = os.RemoveAll(base) }
-
-	run := func(args ...string) error {
-		cmd := exec.Command("git", args...)
-		cmd.Dir = base
-		// 避免 git 交互
-		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
-		if len(extraEnv) > 0 {
-			cmd.Env = append(cmd.Env, extraEnv...)
-		}
-		var out bytes.Buffer
-		cmd.Stdout = &out
-		cmd.Stderr = &out
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("git %s failed: %w; output=%s", strings.Join(args, " "), err, strings.TrimSpace(out.String()))
-		}
-		return nil
-	}
-
-	if err := run("init"); err != nil {
-		return "", cleanup, err
-	}
-	if err := run("remote", "add", "origin", repoURL); err != nil {
-		return "", cleanup, err
-	}
-	// depth=1 拉取 ref（branch/tag/commit 都尝试）
-	if err := run("fetch", "--depth", "1", "origin", ref); err != nil {
-		// fallback: ref 可能是分支名，尝试 refs/heads/
-		if err2 := run("fetch", "--depth", "1", "origin", "refs/heads/"+ref); err2 != nil {
-			return "", cleanup, err
-		}
-	}
-	if err := run("checkout", "--detach", "FETCH_HEAD"); err != nil {
-		return "", cleanup, err
-	}
-	return base, cleanup, nil
+// httpGet 经共享制品缓存拉取 http_file 层（TTL 内命中，过期后条件请求校验）
+func httpGet(ctx context.Context, url string, cred map[string]string) ([]byte, error) {
+	return artifactcache.Fetch(ctx, artifactcache.Request{
+		URL:  url,
+		Auth: func(req *http.Request) { ApplyHTTPAuth(req, cred) },
+	})
 }
 
 func normalizeYAMLToStringMap(v interface{}) interface{} {
@@ -272,13 +270,16 @@ func marshalMeta(m map[string]interface{}) (map[string]interface{}, error) {
 	return out, nil
 }
 
-// resolveCredentialData 根据 credential_ref 取出明文（map），不回传给 API，仅内部使用
-// 当前支持 credential_ref=纯数字（id）或 "id:123"
-func resolveCredentialData(db *gorm.DB, ref string) (map[string]string, error) {
+// ResolveCredentialData 根据 credential_ref 取出明文（map），不回传给 API，仅内部使用
+// 支持 credential_ref=纯数字（id）、"id:123"，以及外部密钥 "vault:<path>" / "aws_sm:<secret-id>"
+func ResolveCredentialData(db *gorm.DB, ref string) (map[string]string, error) {
 	ref = strings.TrimSpace(ref)
 	if ref == "" {
 		return nil, nil
 	}
+	if secrets.IsExternalRef(ref) {
+		return secrets.Resolve(context.Background(), ref)
+	}
 	if db == nil {
 		return nil, fmt.Errorf("db is nil, cannot resolve credential_ref")
 	}
@@ -295,7 +296,7 @@ func resolveCredentialData(db *gorm.DB, ref string) (map[string]string, error) {
 	if err := db.First(&c, id).Error; err != nil {
 		return nil, fmt.Errorf("credential_ref=%s 查询失败: %w", ref, err)
 	}
-	plain, err := crypto.Decrypt(c.EncryptedData)
+	plain, err := crypto.DecryptWithKeyID(c.EncryptedData, c.KeyID)
 	if err != nil {
 		return nil, fmt.Errorf("credential_ref=%s 解密失败: %w", ref, err)
 	}
@@ -307,7 +308,7 @@ func resolveCredentialData(db *gorm.DB, ref string) (map[string]string, error) {
 	return raw, nil
 }
 
-func applyHTTPAuth(req *http.Request, cred map[string]string) {
+func ApplyHTTPAuth(req *http.Request, cred map[string]string) {
 	if req == nil || cred == nil {
 		return
 	}
@@ -325,8 +326,8 @@ func applyHTTPAuth(req *http.Request, cred map[string]string) {
 	}
 }
 
-// prepareGitAuth 基于凭据生成 git clone 所需的 repoURL/env（v1：basic_auth/token/ssh_key）
-func prepareGitAuth(repoURL string, cred map[string]string) (finalURL string, env []string, cleanup func(), err error) {
+// PrepareGitAuth 基于凭据生成 git clone 所需的 repoURL/env（v1：basic_auth/token/ssh_key）
+func PrepareGitAuth(repoURL string, cred map[string]string) (finalURL string, env []string, cleanup func(), err error) {
 	finalURL = repoURL
 	cleanup = func() {}
 	if cred == nil {
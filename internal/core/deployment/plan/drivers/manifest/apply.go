@@ -0,0 +1,226 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+	"devops-cd/internal/pkg/logger"
+
+	"go.uber.org/zap"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	applyTimeout = 2 * time.Minute
+	fieldManager = "devops-cd"
+
+	managedByLabel = "app.kubernetes.io/managed-by"
+	releaseLabel   = "devops-cd/release"
+
+	// 已应用清单记录在命名空间内的 ConfigMap 中（类似 helm release secret），供状态检查与 prune 使用
+	stateConfigMapPrefix = "devops-cd-manifest-"
+	stateManifestKey     = "manifest"
+)
+
+type applier struct {
+	namespace string
+	getter    *helmDriver.RESTClientGetter
+	dyn       dynamic.Interface
+	mapper    meta.RESTMapper
+	clientset kubernetes.Interface
+}
+
+func newApplier(kubeconfig, namespace string) (*applier, error) {
+	getter, err := helmDriver.NewRESTClientGetter(kubeconfig, namespace)
+	if err != nil {
+		return nil, err
+	}
+	restConfig, err := getter.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	dyn, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	mapper, err := getter.ToRESTMapper()
+	if err != nil {
+		return nil, err
+	}
+	return &applier{namespace: namespace, getter: getter, dyn: dyn, mapper: mapper, clientset: clientset}, nil
+}
+
+// apply 按安装顺序逐个资源 server-side apply；全部成功后记录清单，并按需 prune 上次清单中已移除的资源
+func (a *applier) apply(ctx context.Context, releaseName, manifest string, prune bool) error {
+	ctx, cancel := context.WithTimeout(ctx, applyTimeout)
+	defer cancel()
+
+	objs, err := parseResources(manifest)
+	if err != nil {
+		return err
+	}
+	if len(objs) == 0 {
+		return fmt.Errorf("清单中没有资源")
+	}
+
+	applied := make(map[string]bool, len(objs))
+	var failures []string
+	for _, obj := range objs {
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[managedByLabel] = fieldManager
+		labels[releaseLabel] = releaseName
+		obj.SetLabels(labels)
+
+		ri, err := a.resourceClient(obj)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s/%s: %v", obj.GetKind(), obj.GetName(), err))
+			continue
+		}
+		// Force: 接管其他 field manager（如之前 kubectl apply）管理的字段
+		if _, err := ri.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{FieldManager: fieldManager, Force: true}); err != nil {
+			failures = append(failures, fmt.Sprintf("%s/%s: %v", obj.GetKind(), obj.GetName(), err))
+			continue
+		}
+		applied[resourceKey(obj)] = true
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("server-side apply 失败: %s", strings.Join(failures, "; "))
+	}
+
+	previous, err := a.loadState(ctx, releaseName)
+	if err != nil {
+		return err
+	}
+	if prune && previous != "" {
+		a.prune(ctx, releaseName, previous, applied)
+	}
+	return a.saveState(ctx, releaseName, manifest)
+}
+
+// prune 删除上次清单中存在、本次未应用的资源（失败只记录日志，不影响本次部署）
+func (a *applier) prune(ctx context.Context, releaseName, previous string, applied map[string]bool) {
+	objs, err := parseResources(previous)
+	if err != nil {
+		logger.Warn("解析上次应用的清单失败，跳过 prune", zap.String("release", releaseName), zap.Error(err))
+		return
+	}
+	propagation := metav1.DeletePropagationBackground
+	for i := len(objs) - 1; i >= 0; i-- {
+		obj := objs[i]
+		ri, err := a.resourceClient(obj)
+		if err != nil || applied[resourceKey(obj)] {
+			continue
+		}
+		err = ri.Delete(ctx, obj.GetName(), metav1.DeleteOptions{PropagationPolicy: &propagation})
+		if err != nil && !apierrors.IsNotFound(err) {
+			logger.Warn("prune 资源失败", zap.String("release", releaseName),
+				zap.String("resource", resourceKey(obj)), zap.Error(err))
+			continue
+		}
+		logger.Info("prune 资源", zap.String("release", releaseName), zap.String("resource", resourceKey(obj)))
+	}
+}
+
+// resourceClient 解析资源对应的 API，命名空间级资源未指定 namespace 时使用部署 namespace
+func (a *applier) resourceClient(obj *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := a.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		return a.dyn.Resource(mapping.Resource), nil
+	}
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(a.namespace)
+	}
+	return a.dyn.Resource(mapping.Resource).Namespace(obj.GetNamespace()), nil
+}
+
+func (a *applier) loadState(ctx context.Context, releaseName string) (string, error) {
+	cm, err := a.clientset.CoreV1().ConfigMaps(a.namespace).Get(ctx, stateConfigMapPrefix+releaseName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("读取已应用清单失败: %w", err)
+	}
+	return cm.Data[stateManifestKey], nil
+}
+
+func (a *applier) saveState(ctx context.Context, releaseName, manifest string) error {
+	cm := corev1ac.ConfigMap(stateConfigMapPrefix+releaseName, a.namespace).
+		WithLabels(map[string]string{managedByLabel: fieldManager, releaseLabel: releaseName}).
+		WithData(map[string]string{stateManifestKey: manifest})
+	if _, err := a.clientset.CoreV1().ConfigMaps(a.namespace).Apply(ctx, cm, metav1.ApplyOptions{FieldManager: fieldManager, Force: true}); err != nil {
+		return fmt.Errorf("记录已应用清单失败: %w", err)
+	}
+	return nil
+}
+
+// parseResources 拆分多文档 YAML，并按 helm 安装顺序（Namespace、CRD、ConfigMap ... Deployment）排序
+func parseResources(manifest string) ([]*unstructured.Unstructured, error) {
+	split := releaseutil.SplitManifests(manifest)
+	keys := make([]string, 0, len(split))
+	for k := range split {
+		keys = append(keys, k)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	objs := make([]*unstructured.Unstructured, 0, len(keys))
+	for _, k := range keys {
+		raw, err := utilyaml.ToJSON([]byte(split[k]))
+		if err != nil {
+			return nil, fmt.Errorf("解析清单失败: %w", err)
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(raw); err != nil {
+			// 空文档（仅注释）等
+			continue
+		}
+		if obj.GetKind() == "" {
+			continue
+		}
+		if obj.GetName() == "" {
+			return nil, fmt.Errorf("清单中 %s 缺少 metadata.name", obj.GetKind())
+		}
+		objs = append(objs, obj)
+	}
+
+	order := make(map[string]int, len(releaseutil.InstallOrder))
+	for i, kind := range releaseutil.InstallOrder {
+		order[kind] = i
+	}
+	rank := func(kind string) int {
+		if i, ok := order[kind]; ok {
+			return i
+		}
+		return len(order)
+	}
+	sort.SliceStable(objs, func(i, j int) bool { return rank(objs[i].GetKind()) < rank(objs[j].GetKind()) })
+	return objs, nil
+}
+
+func resourceKey(obj *unstructured.Unstructured) string {
+	gvk := obj.GroupVersionKind()
+	return fmt.Sprintf("%s/%s/%s/%s", gvk.Group, gvk.Kind, obj.GetNamespace(), obj.GetName())
+}
@@ -0,0 +1,37 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"devops-cd/internal/model"
+)
+
+// Config 是 manifest driver 的私有配置（对应 artifacts_json.*_chart.data）。
+type Config struct {
+	// 资源归属名称（deployment_name），用于标记资源与记录已应用清单，默认 app_name
+	ReleaseNameTemplate string `json:"release_name_template,omitempty"`
+
+	// 清单来源：与 values 层相同的类型（git / http_file / file / inline_yaml），按顺序拼接为多文档 YAML
+	Sources []model.ValuesLayer `json:"sources"`
+	// 模板 values：合并后以 .Values 提供给清单模板（同 helm driver，运行时注入 image.tag）
+	Values []model.ValuesLayer `json:"values,omitempty"`
+
+	// Prune 删除上次应用、本次清单中已不存在的资源
+	Prune bool `json:"prune,omitempty"`
+}
+
+func DecodeConfig(raw json.RawMessage) (*Config, error) {
+	if len(raw) == 0 || strings.TrimSpace(string(raw)) == "" || strings.TrimSpace(string(raw)) == "null" {
+		return nil, fmt.Errorf("manifest config: sources 为空")
+	}
+	var c Config
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, fmt.Errorf("manifest config decode failed: %w", err)
+	}
+	if len(c.Sources) == 0 {
+		return nil, fmt.Errorf("manifest config: sources 为空")
+	}
+	return &c, nil
+}
@@ -0,0 +1,160 @@
+// Package manifest 渲染原生 Kubernetes YAML 清单并以 server-side apply 部署（非 helm chart 的服务）
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"devops-cd/internal/core/deployment/helpers/tpl"
+	"devops-cd/internal/core/deployment/plan/drivers"
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/tracing"
+	"devops-cd/pkg/constants"
+
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+)
+
+type Driver struct {
+	db *gorm.DB
+}
+
+func New(db *gorm.DB) *Driver {
+	return &Driver{db: db}
+}
+
+func (d *Driver) Name() string { return "manifest" }
+
+func (d *Driver) Execute(ctx context.Context, req *drivers.ExecuteRequest) (*drivers.ExecuteResult, error) {
+	p, ok := req.Payload.(*helmDriver.ExecutePayload)
+	if !ok || p == nil || p.Deployment == nil || p.App == nil || p.Build == nil || p.Artifacts == nil {
+		return nil, fmt.Errorf("manifest driver: invalid payload")
+	}
+
+	var stage *model.StageSpecV1
+	var kind string
+	switch req.Stage {
+	case drivers.StagePre:
+		stage, kind = p.Artifacts.ConfigChart, "config_chart"
+	case drivers.StageMain:
+		stage, kind = p.Artifacts.AppChart, "app_chart"
+	default:
+		return nil, fmt.Errorf("manifest driver: unknown stage: %s", req.Stage)
+	}
+	if stage == nil || !stage.Enabled {
+		return drivers.Success(), nil
+	}
+
+	if err := d.apply(ctx, req, p, stage, kind); err != nil {
+		return drivers.Failed(err.Error()), err
+	}
+	return drivers.Success(), nil
+}
+
+func (d *Driver) apply(ctx context.Context, req *drivers.ExecuteRequest, p *helmDriver.ExecutePayload, stage *model.StageSpecV1, kind string) error {
+	dep := p.Deployment
+	if dep.Cluster == nil {
+		return fmt.Errorf("%s: cluster %s 不存在", kind, dep.ClusterName)
+	}
+	cfg, err := DecodeConfig(stage.Data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", kind, err)
+	}
+
+	releaseName := p.App.Name
+	if strings.TrimSpace(cfg.ReleaseNameTemplate) != "" {
+		tplCtx := tpl.RenderTemplateContext(p.App, p.Build, dep.Env, dep.ClusterName, p.TplOptions)
+		if releaseName, err = tpl.ParseTemplate(cfg.ReleaseNameTemplate, tplCtx); err != nil {
+			return fmt.Errorf("%s: release_name_template 解析失败: %w", kind, err)
+		}
+		releaseName = strings.TrimSpace(releaseName)
+	}
+
+	manifest, err := render(ctx, d.db, cfg, &renderParam{
+		App:         p.App,
+		Build:       p.Build,
+		Env:         dep.Env,
+		Cluster:     dep.ClusterName,
+		Namespace:   req.Namespace,
+		ReleaseName: releaseName,
+		TplOptions:  p.TplOptions,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", kind, err)
+	}
+
+	req.Report(constants.DeploymentEventChartRendered, fmt.Sprintf("%s: 清单渲染完成（%d 个来源）", kind, len(cfg.Sources)))
+	req.Report(constants.DeploymentEventDeployStarted, fmt.Sprintf("%s: server-side apply release %s（namespace %s）", kind, releaseName, req.Namespace))
+	kubeconfig, err := helmDriver.ClusterKubeconfig(dep.Cluster)
+	if err != nil {
+		return fmt.Errorf("%s: %w", kind, err)
+	}
+	return applyManifest(ctx, kubeconfig, req.Namespace, releaseName, manifest, cfg.Prune, kind)
+}
+
+func applyManifest(ctx context.Context, kubeconfig, namespace, releaseName, manifest string, prune bool, kind string) (err error) {
+	ctx, span := tracing.Start(ctx, "manifest.apply", attribute.String("manifest.release", releaseName), attribute.String("k8s.namespace.name", namespace))
+	defer func() { tracing.End(span, err) }()
+
+	a, err := newApplier(kubeconfig, namespace)
+	if err != nil {
+		return err
+	}
+	if err := a.apply(ctx, releaseName, manifest, prune); err != nil {
+		return fmt.Errorf("%s: %w", kind, err)
+	}
+	return nil
+}
+
+// CheckStatus 读取已应用清单，复用 helm driver 的 workload readiness 检查
+func (d *Driver) CheckStatus(ctx context.Context, req *drivers.ExecuteRequest) (*drivers.ExecuteResult, error) {
+	dep, ok := req.Payload.(*model.Deployment)
+	if !ok || dep == nil {
+		return nil, fmt.Errorf("manifest CheckStatus: payload must be deployment")
+	}
+	if strings.TrimSpace(dep.Namespace) == "" || strings.TrimSpace(dep.DeploymentName) == "" {
+		return nil, fmt.Errorf("manifest CheckStatus: namespace/deployment_name 为空")
+	}
+	if !dep.Cluster.HasCredentials() {
+		return nil, fmt.Errorf("manifest CheckStatus: cluster/连接凭据为空（需要 Preload Cluster）")
+	}
+	kubeconfig, err := helmDriver.ClusterKubeconfig(dep.Cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := newApplier(kubeconfig, dep.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := a.loadState(ctx, dep.DeploymentName)
+	if err != nil {
+		return nil, err
+	}
+	if manifest == "" {
+		return drivers.Running("尚未记录已应用清单"), nil
+	}
+
+	allReady, anyFailed, msg, err := helmDriver.CheckReleaseWorkloadsReady(ctx, a.getter, manifest, dep.Namespace)
+	if err != nil {
+		return drivers.Failed(fmt.Sprintf("manifest readiness check error: %v", err)), nil
+	}
+	if anyFailed {
+		return drivers.Failed(msg), nil
+	}
+	if !allReady {
+		return drivers.Running(msg), nil
+	}
+	return drivers.Success(), nil
+}
+
+// AppliedManifest 查询 manifest driver 最近一次应用的清单；未应用过时返回空
+func AppliedManifest(ctx context.Context, kubeconfig, namespace, releaseName string) (string, error) {
+	a, err := newApplier(kubeconfig, namespace)
+	if err != nil {
+		return "", err
+	}
+	return a.loadState(ctx, releaseName)
+}
@@ -0,0 +1,66 @@
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"devops-cd/internal/core/deployment/helpers/tpl"
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+	"devops-cd/internal/model"
+
+	"github.com/Masterminds/sprig/v3"
+	"gorm.io/gorm"
+)
+
+// renderParam 渲染清单所需的上下文
+type renderParam struct {
+	App         *model.Application
+	Build       *model.Build
+	Env         string
+	Cluster     string
+	Namespace   string
+	ReleaseName string
+	TplOptions  *tpl.ContextOptions
+}
+
+// render 加载所有来源并以 Go template（含 sprig 函数）渲染，返回多文档 YAML
+//
+// 模板变量：.Values（合并后的 values）、.Release.Name / .Release.Namespace，以及 app_name / env / cluster 等通用模板变量
+func render(ctx context.Context, db *gorm.DB, cfg *Config, p *renderParam) (string, error) {
+	values, err := helmDriver.ParseValuesV1(ctx, db, p.App, p.Build, p.Env, p.Cluster, cfg.Values, "", p.TplOptions)
+	if err != nil {
+		return "", fmt.Errorf("values 计算失败: %w", err)
+	}
+
+	tplCtx := tpl.RenderTemplateContext(p.App, p.Build, p.Env, p.Cluster, p.TplOptions)
+	data := make(map[string]interface{}, len(tplCtx)+2)
+	for k, v := range tplCtx {
+		data[k] = v
+	}
+	data["Values"] = values
+	data["Release"] = map[string]interface{}{"Name": p.ReleaseName, "Namespace": p.Namespace}
+
+	docs := make([]string, 0, len(cfg.Sources))
+	for idx, source := range cfg.Sources {
+		content, err := helmDriver.LoadLayerContent(ctx, db, tplCtx, source)
+		if err != nil {
+			return "", fmt.Errorf("sources[%d] 加载失败: %w", idx, err)
+		}
+		t, err := template.New(fmt.Sprintf("sources[%d]", idx)).Funcs(sprig.TxtFuncMap()).
+			Option("missingkey=zero").Parse(string(content))
+		if err != nil {
+			return "", fmt.Errorf("sources[%d] 模板解析失败: %w", idx, err)
+		}
+		var out bytes.Buffer
+		if err := t.Execute(&out, data); err != nil {
+			return "", fmt.Errorf("sources[%d] 模板渲染失败: %w", idx, err)
+		}
+		if s := strings.TrimSpace(out.String()); s != "" {
+			docs = append(docs, s)
+		}
+	}
+	return strings.Join(docs, "\n---\n"), nil
+}
@@ -0,0 +1,132 @@
+package deployment
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"devops-cd/internal/core/common/metacache"
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+
+	"gorm.io/gorm"
+)
+
+// ReleaseDriftTargets 配置漂移检测的基准：各应用/环境/集群/部署类型最近一次写入 release 的 Deployment（release_revision 仅由 helm driver 记录）
+//
+// 之后存在进行中的部署时跳过（release 即将被覆盖）；已删除的应用不检测
+func ReleaseDriftTargets(ctx context.Context, db *gorm.DB) ([]*model.Deployment, error) {
+	latest := db.Model(&model.Deployment{}).
+		Select("MAX(id)").
+		Where("release_revision IS NOT NULL").
+		Group("app_id, env, cluster, kind")
+	busy := db.Model(&model.Deployment{}).
+		Select("1").
+		Where("busy.app_id = deployments.app_id AND busy.env = deployments.env AND busy.cluster = deployments.cluster AND busy.kind = deployments.kind").
+		Where("busy.id > deployments.id AND busy.superseded_by IS NULL AND busy.status IN ?",
+			[]string{constants.DeploymentStatusPending, constants.DeploymentStatusRunning})
+
+	var deps []*model.Deployment
+	if err := db.WithContext(ctx).
+		Joins("JOIN applications ON applications.id = deployments.app_id AND applications.deleted_at IS NULL").
+		Where("deployments.id IN (?)", latest).
+		Where("NOT EXISTS (?)", busy.Table("deployments AS busy")).
+		Order("deployments.id").
+		Find(&deps).Error; err != nil {
+		return nil, fmt.Errorf("query release drift targets failed: %w", err)
+	}
+	return deps, nil
+}
+
+// RecordReleaseDrift 以 dep 写入的 release 为基准检测配置漂移并写入 release_drifts（每个应用/环境/集群/部署类型一条）
+//
+// 检测失败记录为 error 状态；恢复为 ok 时清空 notified_digest，其余情况保留，由调用方决定是否通知
+func RecordReleaseDrift(ctx context.Context, db *gorm.DB, dep *model.Deployment, live bool) (*model.ReleaseDrift, error) {
+	if dep.ReleaseRevision == nil {
+		return nil, fmt.Errorf("deployment %d has no release snapshot", dep.ID)
+	}
+	app, err := metacache.Application(ctx, db, dep.AppID)
+	if err != nil {
+		return nil, fmt.Errorf("load application failed: %w", err)
+	}
+
+	var drift model.ReleaseDrift
+	if err := db.WithContext(ctx).
+		Where("app_id = ? AND env = ? AND cluster = ? AND kind = ?", dep.AppID, dep.Env, dep.ClusterName, dep.Kind).
+		Limit(1).Find(&drift).Error; err != nil {
+		return nil, fmt.Errorf("query release drift failed: %w", err)
+	}
+	drift.ProjectID = app.ProjectID
+	drift.AppID = dep.AppID
+	drift.Env = dep.Env
+	drift.ClusterName = dep.ClusterName
+	drift.Kind = dep.Kind
+	drift.DeploymentID = dep.ID
+	drift.Namespace = dep.Namespace
+	drift.ReleaseName = dep.DeploymentName
+	drift.CheckedAt = time.Now()
+	drift.ErrorMessage = nil
+
+	status, findings, err := detectReleaseDrift(ctx, db, dep, live)
+	if err != nil {
+		// 检测出错时保留上次的漂移项，便于排查
+		msg := err.Error()
+		drift.Status = constants.ReleaseDriftStatusError
+		drift.ErrorMessage = &msg
+	} else {
+		drift.Status = status
+		drift.Findings = findings
+	}
+	switch drift.Status {
+	case constants.ReleaseDriftStatusOK:
+		drift.Findings = model.DriftFindings{}
+		drift.Digest = ""
+		drift.DetectedAt = nil
+		drift.NotifiedDigest = nil
+	case constants.ReleaseDriftStatusDrift, constants.ReleaseDriftStatusMissing:
+		drift.Digest = releaseDriftDigest(drift.Status, drift.Findings)
+		if drift.DetectedAt == nil {
+			drift.DetectedAt = &drift.CheckedAt
+		}
+	}
+
+	if err := db.WithContext(ctx).Save(&drift).Error; err != nil {
+		return nil, fmt.Errorf("save release drift failed: %w", err)
+	}
+	return &drift, nil
+}
+
+func detectReleaseDrift(ctx context.Context, db *gorm.DB, dep *model.Deployment, live bool) (string, model.DriftFindings, error) {
+	cluster, err := metacache.Cluster(ctx, db, dep.ClusterName)
+	if err != nil {
+		return "", nil, fmt.Errorf("load cluster failed: %w", err)
+	}
+	snap := helmDriver.ReleaseSnapshot{
+		Revision:       *dep.ReleaseRevision,
+		ValuesDigest:   derefOr(dep.ValuesDigest, ""),
+		ManifestDigest: derefOr(dep.ManifestDigest, ""),
+	}
+	found, findings, err := helmDriver.DetectReleaseDrift(ctx, cluster, dep.Namespace, dep.DeploymentName, snap, live)
+	if err != nil {
+		return "", nil, err
+	}
+	switch {
+	case !found:
+		return constants.ReleaseDriftStatusMissing, model.DriftFindings{}, nil
+	case len(findings) > 0:
+		return constants.ReleaseDriftStatusDrift, findings, nil
+	default:
+		return constants.ReleaseDriftStatusOK, model.DriftFindings{}, nil
+	}
+}
+
+// releaseDriftDigest 漂移内容摘要：内容不变时不重复通知
+func releaseDriftDigest(status string, findings model.DriftFindings) string {
+	b, _ := json.Marshal(findings)
+	sum := sha256.Sum256(append([]byte(status+"\n"), b...))
+	return hex.EncodeToString(sum[:])
+}
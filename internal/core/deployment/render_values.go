@@ -0,0 +1,114 @@
+package deployment
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+
+	"gorm.io/gorm"
+)
+
+// 层来源
+const (
+	ValuesSourceArtifacts = "artifacts" // 项目环境 artifacts_json 中配置的层
+	ValuesSourceAppEnv    = "app_env"   // 应用环境配置（app_env_configs.config_data.values）追加的层
+	ValuesSourceManifest  = "manifest"  // 发布应用 manifest lock 中代码库清单声明的层（替代应用环境配置的层）
+	ValuesSourceRuntime   = "runtime"   // 运行时注入（image.tag）
+	ValuesSourceOverride  = "override"  // 发布应用的 values 覆盖（release_apps.values_override）
+)
+
+// ValuesRender values 渲染预览：各层解析内容、合并顺序与最终 values（均已脱敏）
+type ValuesRender struct {
+	Kind        string // app / config
+	DriverType  string
+	Namespace   string
+	ReleaseName string
+	Layers      []*ValuesRenderLayer // 按合并顺序，后者覆盖前者
+	Values      map[string]interface{}
+	Error       string // 某层加载/解析失败时的错误，Layers 为失败前已解析的层
+}
+
+// ValuesRenderLayer 单层 values
+type ValuesRenderLayer struct {
+	Index  int
+	Source string
+	Layer  *model.ValuesLayer // 层配置（inline_yaml 内容不回传），运行时注入层与 values 覆盖为空
+	Empty  bool
+	Values map[string]interface{}
+}
+
+// RenderValues 按部署时相同的规则（helm ParseValuesV1）渲染 dep 某一阶段的 values，不访问集群、不修改状态
+//
+// dep 无需落库，只需 AppID / Env / ClusterName；kind 为 app（app_chart）或 config（config_chart）
+func RenderValues(ctx context.Context, db *gorm.DB, dep *model.Deployment, build *model.Build, kind string) (*ValuesRender, error) {
+	sc, err := loadStageContext(ctx, db, dep, build)
+	if err != nil {
+		return nil, err
+	}
+
+	stage, stageName := sc.arts.AppChart, "app_chart"
+	if kind == constants.DeploymentKindConfig {
+		stage, stageName = sc.arts.ConfigChart, "config_chart"
+		if stage == nil || !stage.Enabled {
+			return nil, fmt.Errorf("config_chart 未启用")
+		}
+	}
+	out := &ValuesRender{
+		Kind:       kind,
+		DriverType: strings.TrimSpace(stage.Type),
+		Namespace:  sc.namespace,
+	}
+	out.ReleaseName, _ = sc.stageRelease(stage)
+	// values 仅 helm（及内嵌 helm 配置的 gitops）driver 计算
+	if out.DriverType != "helm" && out.DriverType != "gitops" {
+		return nil, fmt.Errorf("%s driver %s 不使用 values 层", stageName, out.DriverType)
+	}
+
+	cfg, err := helmDriver.DecodeConfig(stage.Data)
+	if err != nil {
+		return nil, err
+	}
+	layers, err := helmDriver.New(db).StageValuesLayers(sc.app.ID, dep.Env, dep.ClusterName, cfg, stageName, sc.manifestLock)
+	if err != nil {
+		return nil, err
+	}
+	var override string
+	if stageName == "app_chart" {
+		override = sc.valuesOverride
+	}
+	merged, results, err := helmDriver.RenderValuesV1(ctx, db, sc.app, build, dep.Env, dep.ClusterName, layers, override, sc.tplOpts)
+	if err != nil {
+		out.Error = err.Error()
+	} else {
+		out.Values = redactValues(merged)
+	}
+
+	out.Layers = make([]*ValuesRenderLayer, 0, len(results))
+	for _, r := range results {
+		item := &ValuesRenderLayer{Index: r.Index, Empty: r.Empty, Source: ValuesSourceRuntime}
+		if r.Values != nil {
+			item.Values = redactValues(r.Values)
+		}
+		if r.Override {
+			item.Source = ValuesSourceOverride
+		}
+		if !r.Runtime && !r.Override {
+			item.Source = ValuesSourceArtifacts
+			if r.Index >= len(cfg.Values) {
+				item.Source = ValuesSourceAppEnv
+				if stageName == "app_chart" && sc.manifestLock.Cluster(dep.Env, dep.ClusterName) != nil {
+					item.Source = ValuesSourceManifest
+				}
+			}
+			layer := layers[r.Index]
+			layer.Content = ""
+			item.Layer = &layer
+		}
+		out.Layers = append(out.Layers, item)
+	}
+	return out, nil
+}
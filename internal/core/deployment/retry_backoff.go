@@ -0,0 +1,69 @@
+package deployment
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"devops-cd/internal/model"
+)
+
+// 失败重试退避策略（core.deploy.retry_backoff）
+const (
+	RetryBackoffExponential = "exponential" // base·2^(n-1)
+	RetryBackoffLinear      = "linear"      // base·n
+)
+
+const (
+	DefaultRetryBackoffBase = 30 * time.Second
+	maxRetryBackoff         = 30 * time.Minute
+)
+
+// WithRetryBackoff 重试后的 Deployment 在 last_failed_at + 退避间隔之前保持 pending
+//
+// policy 为空或未知时不退避（立即执行）；base <= 0 时使用 DefaultRetryBackoffBase
+func WithRetryBackoff(policy string, base time.Duration) Option {
+	return func(sm *StateMachine) {
+		sm.retryBackoff = strings.ToLower(strings.TrimSpace(policy))
+		sm.retryBackoffBase = base
+		if sm.retryBackoffBase <= 0 {
+			sm.retryBackoffBase = DefaultRetryBackoffBase
+		}
+	}
+}
+
+// RetryDelay 第 retries 次重试前的退避间隔，上限 30m
+func RetryDelay(policy string, base time.Duration, retries int) time.Duration {
+	if retries <= 0 || base <= 0 {
+		return 0
+	}
+	var wait time.Duration
+	switch policy {
+	case RetryBackoffLinear:
+		wait = base * time.Duration(retries)
+	case RetryBackoffExponential:
+		wait = base
+		for i := 1; i < retries && wait < maxRetryBackoff; i++ {
+			wait *= 2
+		}
+	default:
+		return 0
+	}
+	return min(wait, maxRetryBackoff)
+}
+
+// retryHoldReason 重试的 Deployment 仍在退避期内时返回等待原因
+func (sm *StateMachine) retryHoldReason(dep *model.Deployment, now time.Time) string {
+	if dep.LastFailedAt == nil {
+		return ""
+	}
+	delay := RetryDelay(sm.retryBackoff, sm.retryBackoffBase, dep.RetryCount)
+	if delay <= 0 {
+		return ""
+	}
+	next := dep.LastFailedAt.Add(delay)
+	if !now.Before(next) {
+		return ""
+	}
+	return fmt.Sprintf("重试退避中（%s，第 %d 次），将于 %s 后执行", sm.retryBackoff, dep.RetryCount, next.Format(time.DateTime))
+}
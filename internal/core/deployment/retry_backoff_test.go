@@ -0,0 +1,71 @@
+package deployment
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"devops-cd/internal/model"
+)
+
+func TestRetryDelay(t *testing.T) {
+	const base = 30 * time.Second
+	cases := []struct {
+		policy  string
+		base    time.Duration
+		retries int
+		want    time.Duration
+	}{
+		{policy: RetryBackoffExponential, base: base, retries: 0, want: 0},
+		{policy: RetryBackoffExponential, base: base, retries: 1, want: 30 * time.Second},
+		{policy: RetryBackoffExponential, base: base, retries: 2, want: time.Minute},
+		{policy: RetryBackoffExponential, base: base, retries: 4, want: 4 * time.Minute},
+		{policy: RetryBackoffExponential, base: base, retries: 7, want: 30 * time.Minute}, // 32m 截断为上限
+		{policy: RetryBackoffExponential, base: base, retries: 100, want: 30 * time.Minute},
+		{policy: RetryBackoffLinear, base: base, retries: 1, want: 30 * time.Second},
+		{policy: RetryBackoffLinear, base: base, retries: 3, want: 90 * time.Second},
+		{policy: RetryBackoffLinear, base: base, retries: 61, want: 30 * time.Minute},
+		{policy: "", base: base, retries: 3, want: 0},
+		{policy: "fibonacci", base: base, retries: 3, want: 0},
+		{policy: RetryBackoffLinear, base: 0, retries: 3, want: 0},
+		{policy: RetryBackoffLinear, base: base, retries: -1, want: 0},
+	}
+	for _, c := range cases {
+		if got := RetryDelay(c.policy, c.base, c.retries); got != c.want {
+			t.Errorf("RetryDelay(%q, %s, %d) = %s, want %s", c.policy, c.base, c.retries, got, c.want)
+		}
+	}
+}
+
+func TestRetryHoldReason(t *testing.T) {
+	failedAt := time.Date(2026, 1, 2, 10, 0, 0, 0, time.Local)
+	cases := []struct {
+		name     string
+		opt      Option
+		retries  int
+		failedAt *time.Time
+		now      time.Time
+		wantHold bool
+	}{
+		{name: "退避期内", opt: WithRetryBackoff("exponential", 0), retries: 2, failedAt: &failedAt, now: failedAt.Add(59 * time.Second), wantHold: true},
+		{name: "退避期满", opt: WithRetryBackoff("exponential", 0), retries: 2, failedAt: &failedAt, now: failedAt.Add(time.Minute)},
+		{name: "策略大小写与空白", opt: WithRetryBackoff(" Linear ", time.Minute), retries: 2, failedAt: &failedAt, now: failedAt.Add(119 * time.Second), wantHold: true},
+		{name: "未配置策略", opt: WithRetryBackoff("", 0), retries: 2, failedAt: &failedAt, now: failedAt},
+		{name: "首次部署", opt: WithRetryBackoff("linear", 0), retries: 0, failedAt: nil, now: failedAt},
+		{name: "未记录失败时间", opt: WithRetryBackoff("linear", 0), retries: 1, failedAt: nil, now: failedAt},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sm := &StateMachine{}
+			c.opt(sm)
+			dep := &model.Deployment{RetryCount: c.retries, LastFailedAt: c.failedAt}
+			reason := sm.retryHoldReason(dep, c.now)
+			if (reason != "") != c.wantHold {
+				t.Fatalf("retryHoldReason = %q, want hold=%v", reason, c.wantHold)
+			}
+			if c.wantHold && !strings.Contains(reason, "重试退避中") {
+				t.Errorf("reason = %q", reason)
+			}
+		})
+	}
+}
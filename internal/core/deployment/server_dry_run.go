@@ -0,0 +1,63 @@
+package deployment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"devops-cd/internal/core/deployment/plan/drivers"
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+
+	"go.uber.org/zap"
+)
+
+// serverDryRunner 支持 server-side dry-run apply 的 driver（目前仅 helm）
+type serverDryRunner interface {
+	ServerDryRun(ctx context.Context, namespace string, p *helmDriver.ExecutePayload) (*helmDriver.ServerDryRunResult, error)
+}
+
+// dryRunOutcome server dry-run 结果，随 Pending 处理结果写回 deployment
+type dryRunOutcome struct {
+	status  string
+	message string
+	at      time.Time
+}
+
+func (o *dryRunOutcome) apply(d *model.Deployment) {
+	if o == nil {
+		return
+	}
+	status, message, at := o.status, o.message, o.at
+	d.DryRunStatus = &status
+	d.DryRunMessage = &message
+	d.DryRunAt = &at
+}
+
+// serverDryRun prod main 阶段 helm install/upgrade 前，对渲染结果做 server-side dry-run apply
+// 准入 webhook 拒绝、不可变字段冲突等在真正部署前失败，错误信息为 apiserver 返回的原因
+func (sm *StateMachine) serverDryRun(ctx context.Context, dep *model.Deployment, dv drivers.Driver, namespace string, payload *helmDriver.ExecutePayload) (*dryRunOutcome, error) {
+	runner, ok := dv.(serverDryRunner)
+	if !ok {
+		return nil, nil
+	}
+
+	outcome := &dryRunOutcome{at: time.Now()}
+	res, err := runner.ServerDryRun(ctx, namespace, payload)
+	if err != nil {
+		outcome.status = constants.DeploymentDryRunFailed
+		outcome.message = fmt.Sprintf("server dry-run 执行失败: %v", err)
+		return outcome, errors.New(outcome.message)
+	}
+	outcome.message = res.Message()
+	if !res.Passed() {
+		outcome.status = constants.DeploymentDryRunFailed
+		sm.logger.Warn(fmt.Sprintf("[Deployment SM] Batch:%v ReleaseApp:%v %s/%s server dry-run 未通过", dep.BatchID, dep.ReleaseID, dep.Env, dep.ClusterName),
+			zap.Int64("deployment_id", dep.ID), zap.Any("failures", res.Failures))
+		return outcome, errors.New(outcome.message)
+	}
+	outcome.status = constants.DeploymentDryRunPassed
+	return outcome, nil
+}
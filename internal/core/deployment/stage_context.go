@@ -0,0 +1,247 @@
+package deployment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"devops-cd/internal/core/common/metacache"
+	"devops-cd/internal/core/deployment/helpers/tpl"
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+
+	"gorm.io/gorm"
+)
+
+// stageContext 执行 pre/main 阶段所需的应用、项目环境配置与模板上下文
+type stageContext struct {
+	app        *model.Application
+	projectCfg *model.ProjectEnvConfig
+	arts       *model.ArtifactsV1
+	tplOpts    *tpl.ContextOptions
+	renderCtx  map[string]interface{}
+	namespace  string
+	build      *model.Build
+	chartLock  *model.ChartLock // 发布应用封板时锁定的 chart（自动回滚的 Deployment 不使用）
+
+	manifestLock *model.ManifestLock // 发布应用封板时锁定的代码库清单（构建已变更或自动回滚的 Deployment 为空）
+
+	valuesOverride string // 发布应用的 values 覆盖（自动回滚的 Deployment 不使用）
+}
+
+// deploymentBuild dep 要部署的构建：自动回滚的 Deployment 使用 rollback_build_id，临时部署使用临时部署记录中的构建，否则为发布应用当前构建
+func deploymentBuild(ctx context.Context, db *gorm.DB, dep *model.Deployment) (*model.Build, error) {
+	if dep.AdhocID != nil {
+		var adhoc model.AdhocDeployment
+		if err := db.WithContext(ctx).Select("id", "build_id").First(&adhoc, *dep.AdhocID).Error; err != nil {
+			return nil, fmt.Errorf("load adhoc deployment failed: %w", err)
+		}
+		var build model.Build
+		if err := db.WithContext(ctx).First(&build, adhoc.BuildID).Error; err != nil {
+			return nil, fmt.Errorf("load adhoc build failed: %w", err)
+		}
+		return &build, nil
+	}
+	if dep.RollbackBuildID != nil {
+		var build model.Build
+		if err := db.WithContext(ctx).First(&build, *dep.RollbackBuildID).Error; err != nil {
+			return nil, fmt.Errorf("load rollback build failed: %w", err)
+		}
+		return &build, nil
+	}
+	var rel model.ReleaseApp
+	if err := db.WithContext(ctx).Preload("Build").First(&rel, dep.ReleaseID).Error; err != nil {
+		return nil, fmt.Errorf("load release_app failed: %w", err)
+	}
+	if rel.Build == nil {
+		return nil, fmt.Errorf("发布应用未选择构建版本")
+	}
+	return rel.Build, nil
+}
+
+// loadDeploymentWithCluster 读取 Deployment 及其集群（集群走元数据缓存，不存在时 Cluster 为 nil，同 Preload）
+func loadDeploymentWithCluster(ctx context.Context, db *gorm.DB, id int64, dep *model.Deployment) error {
+	if err := db.WithContext(ctx).First(dep, id).Error; err != nil {
+		return err
+	}
+	cluster, err := metacache.Cluster(ctx, db, dep.ClusterName)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("load cluster failed: %w", err)
+	}
+	dep.Cluster = cluster
+	return nil
+}
+
+// loadStageContext 加载应用/项目环境配置并解析 artifacts_json 与 namespace
+func loadStageContext(ctx context.Context, db *gorm.DB, dep *model.Deployment, build *model.Build) (*stageContext, error) {
+	// Load App / ProjectEnvConfig（元数据缓存）
+	app, err := metacache.Application(ctx, db, dep.AppID)
+	if err != nil {
+		return nil, fmt.Errorf("load app failed: %w", err)
+	}
+	projectCfg, err := metacache.ProjectEnvConfig(ctx, db, app.ProjectID, dep.Env)
+	if err != nil {
+		return nil, fmt.Errorf("load project_env_config failed: %w", err)
+	}
+
+	// repo.app_count：当前 project 下，该 repo 关联的应用数（排除 deleted）
+	var repoAppCount int64
+	if err := db.WithContext(ctx).
+		Model(&model.Application{}).
+		Where("project_id = ? AND repo_id = ?", app.ProjectID, app.RepoID).
+		Count(&repoAppCount).Error; err != nil {
+		return nil, fmt.Errorf("count repo apps failed: %w", err)
+	}
+	tplOpts := &tpl.ContextOptions{
+		Repo:         app.Repository,
+		RepoAppCount: &repoAppCount,
+	}
+
+	// 解析 artifacts_json
+	arts, err := model.LoadArtifactsV1(projectCfg.ArtifactsJSON)
+	if err != nil {
+		return nil, err
+	}
+	if arts.AppChart == nil || !arts.AppChart.Enabled {
+		return nil, fmt.Errorf("app_chart 未启用")
+	}
+	if strings.TrimSpace(arts.AppChart.Type) == "" {
+		return nil, fmt.Errorf("app_chart.type 为空")
+	}
+	if arts.ConfigChart != nil && arts.ConfigChart.Enabled && strings.TrimSpace(arts.ConfigChart.Type) == "" {
+		return nil, fmt.Errorf("config_chart.type 为空")
+	}
+
+	// namespace：由 deployment 层统一计算（driver 外部），并传入各 stage
+	nsTpl := strings.TrimSpace(arts.NamespaceTemplate)
+	if nsTpl == "" {
+		return nil, fmt.Errorf("namespace_template 为空")
+	}
+	renderCtx := tpl.RenderTemplateContext(app, build, dep.Env, dep.ClusterName, tplOpts)
+	ns, err := tpl.ParseTemplate(nsTpl, renderCtx)
+	if err != nil {
+		return nil, fmt.Errorf("namespace_template 解析失败: %w", err)
+	}
+	if strings.TrimSpace(ns) == "" {
+		return nil, fmt.Errorf("namespace_template 解析结果为空")
+	}
+
+	rel, err := releaseStageOptions(ctx, db, dep)
+	if err != nil {
+		return nil, err
+	}
+
+	sc := &stageContext{
+		app:        app,
+		projectCfg: projectCfg,
+		arts:       arts,
+		tplOpts:    tplOpts,
+		renderCtx:  renderCtx,
+		namespace:  ns,
+		build:      build,
+		chartLock:  rel.ChartLock,
+
+		valuesOverride: derefOr(rel.ValuesOverride, ""),
+	}
+	if rel.ManifestLock.Active(build.ID) {
+		sc.manifestLock = rel.ManifestLock
+	}
+	return sc, nil
+}
+
+// releaseStageOptions 查询 dep 所属发布应用的 chart lock、manifest lock 与 values 覆盖；预览（未落库）、临时部署与自动回滚的 Deployment 返回空记录
+func releaseStageOptions(ctx context.Context, db *gorm.DB, dep *model.Deployment) (*model.ReleaseApp, error) {
+	var rel model.ReleaseApp
+	if dep.ReleaseID == 0 || dep.RollbackBuildID != nil {
+		return &rel, nil
+	}
+	if err := db.WithContext(ctx).Select("id", "chart_lock", "manifest_lock", "values_override").First(&rel, dep.ReleaseID).Error; err != nil {
+		return nil, fmt.Errorf("load release_app stage options failed: %w", err)
+	}
+	return &rel, nil
+}
+
+// helmPayload helm（及 gitops）driver 的执行参数
+func (sc *stageContext) helmPayload(dep *model.Deployment) *helmDriver.ExecutePayload {
+	return &helmDriver.ExecutePayload{
+		Deployment: dep,
+		App:        sc.app,
+		Build:      sc.build,
+		ProjectCfg: sc.projectCfg,
+		Artifacts:  sc.arts,
+		TplOptions: sc.tplOpts,
+		ChartLock:  sc.chartLock,
+
+		ManifestLock:   sc.manifestLock,
+		ValuesOverride: sc.valuesOverride,
+	}
+}
+
+// stageName 阶段在 artifacts_json 中的名称（app_chart / config_chart），与 chart lock 的 key 一致
+func (sc *stageContext) stageName(stage *model.StageSpecV1) string {
+	if stage == sc.arts.ConfigChart {
+		return "config_chart"
+	}
+	return "app_chart"
+}
+
+// stageRelease 解析阶段的 release 名称与 chart 版本（仅 helm；模板为空或解析失败时返回空）
+func (sc *stageContext) stageRelease(stage *model.StageSpecV1) (releaseName, chartVersion string) {
+	if stage == nil {
+		return "", ""
+	}
+	// gitops driver 的配置内嵌 helm 配置；manifest driver 同样使用 release_name_template
+	if t := strings.TrimSpace(stage.Type); t != "helm" && t != "gitops" && t != "manifest" {
+		return "", ""
+	}
+	cfg, err := helmDriver.DecodeConfig(stage.Data)
+	if err != nil {
+		return "", ""
+	}
+	if strings.TrimSpace(cfg.ReleaseNameTemplate) != "" {
+		if name, err := tpl.ParseTemplate(cfg.ReleaseNameTemplate, sc.renderCtx); err == nil {
+			releaseName = strings.TrimSpace(name)
+		}
+	}
+	if strings.TrimSpace(cfg.ChartVersionTemplate) != "" {
+		if version, err := tpl.ParseTemplate(cfg.ChartVersionTemplate, sc.renderCtx); err == nil {
+			chartVersion = strings.TrimSpace(version)
+		}
+	}
+	// chart lock 生效时以锁定版本为准（与 helm driver 实际部署的版本一致）
+	if sc.chartLock != nil {
+		lock := sc.chartLock.Entry(sc.build.ID, sc.stageName(stage))
+		if chartName, _, err := helmDriver.ResolveChart(cfg, sc.renderCtx, sc.stageName(stage)); err == nil && lock.Matches(cfg.RepoURL, chartName) {
+			chartVersion = lock.Version
+		}
+	}
+	return releaseName, chartVersion
+}
+
+// currentConfigDeployment 查询与 app Deployment 同 release/env/cluster 的当前 config Deployment，不存在时返回 nil
+// 临时部署不拆分 config Deployment，返回 nil（config chart 随 app 同步执行）
+func currentConfigDeployment(ctx context.Context, db *gorm.DB, dep *model.Deployment) (*model.Deployment, error) {
+	if dep.AdhocID != nil {
+		return nil, nil
+	}
+	var configDep model.Deployment
+	if err := db.WithContext(ctx).
+		Where("release_id = ? AND env = ? AND cluster = ? AND kind = ? AND superseded_by IS NULL",
+			dep.ReleaseID, dep.Env, dep.ClusterName, constants.DeploymentKindConfig).
+		Order("id DESC").Limit(1).Find(&configDep).Error; err != nil {
+		return nil, fmt.Errorf("query config deployment failed: %w", err)
+	}
+	if configDep.ID == 0 {
+		return nil, nil
+	}
+	return &configDep, nil
+}
+
+func derefOr(s *string, def string) string {
+	if s == nil || *s == "" {
+		return def
+	}
+	return *s
+}
@@ -3,10 +3,18 @@ package deployment
 import (
 	"context"
 	"devops-cd/internal/core/deployment/plan/drivers"
+	gitopsDriver "devops-cd/internal/core/deployment/plan/drivers/gitops"
 	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+	manifestDriver "devops-cd/internal/core/deployment/plan/drivers/manifest"
+	"devops-cd/internal/core/jobs"
 	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/tracing"
+	"devops-cd/pkg/constants"
+	"errors"
 	"fmt"
+	"time"
 
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -16,50 +24,196 @@ type StateMachine struct {
 	logger   *zap.Logger
 	registry drivers.Registry
 	handlers map[string]Handler
+
+	// 是否直连集群做 preflight / 资源基线采集（集成测试使用 fake driver 时关闭）
+	clusterChecks bool
+	// prod diff 涉及受保护资源时是否需要人工确认后再部署
+	diffAck bool
+	// 失败重试退避策略（exponential/linear，为空不退避）及基础间隔
+	retryBackoff     string
+	retryBackoffBase time.Duration
+	// Running 超过该时长仍未结束判定为失败（0 不限制）
+	runningTimeout time.Duration
+	// Pending 的执行（install/upgrade 等）交给任务队列，为 nil 时在 HandlePending 中同步执行
+	jobs *jobs.Queue
+
+	// 状态变更监听（更新提交后调用，如批次状态推送）
+	listeners []StatusListener
+}
+
+// StatusListener Deployment 状态变更监听，dep 为变更后的记录
+type StatusListener func(dep model.Deployment, from, to string)
+
+// OnStatusChange 注册状态变更监听，需在引擎启动前调用
+func (sm *StateMachine) OnStatusChange(l StatusListener) {
+	sm.listeners = append(sm.listeners, l)
+}
+
+// Option 定制 StateMachine（如集成测试替换 driver）
+type Option func(*StateMachine)
+
+// WithRegistry 替换 driver 注册表（默认注册 helm、gitops、manifest）
+func WithRegistry(reg drivers.Registry) Option {
+	return func(sm *StateMachine) {
+		sm.registry = reg
+	}
+}
+
+// WithoutClusterChecks 跳过 preflight 与部署前资源基线采集（无真实集群时使用）
+func WithoutClusterChecks() Option {
+	return func(sm *StateMachine) {
+		sm.clusterChecks = false
+	}
+}
+
+// WithDiffAck prod diff 涉及受保护资源（PDB/PVC/CRD）时，需确认后才执行部署
+func WithDiffAck() Option {
+	return func(sm *StateMachine) {
+		sm.diffAck = true
+	}
 }
 
-func NewDeploymentStateMachine(db *gorm.DB, logger *zap.Logger) *StateMachine {
+// WithRunningTimeout Running 超过 timeout（从 started_at 起算）仍未成功时置为 failed 并记录超时原因
+func WithRunningTimeout(timeout time.Duration) Option {
+	return func(sm *StateMachine) {
+		sm.runningTimeout = timeout
+	}
+}
+
+// WithJobQueue Pending 的执行由任务队列的 worker 异步完成，HandlePending 只负责入队
+func WithJobQueue(q *jobs.Queue) Option {
+	return func(sm *StateMachine) {
+		sm.jobs = q
+	}
+}
+
+func NewDeploymentStateMachine(db *gorm.DB, logger *zap.Logger, opts ...Option) *StateMachine {
 	reg := drivers.StaticRegistry{
-		"helm": helmDriver.New(db),
+		"helm":     helmDriver.New(db),
+		"gitops":   gitopsDriver.New(db),
+		"manifest": manifestDriver.New(db),
+	}
+	sm := &StateMachine{db: db, logger: logger, registry: reg, handlers: make(map[string]Handler), clusterChecks: true}
+	for _, opt := range opts {
+		opt(sm)
 	}
-	sm := &StateMachine{db: db, logger: logger, registry: reg, handlers: make(map[string]Handler)}
 	sm.registerHandlers()
+	if sm.jobs != nil {
+		sm.jobs.Register(JobTypeExecute, sm.runExecuteJob)
+	}
 	return sm
 }
 
-func (sm *StateMachine) Process(ctx context.Context, dep *model.Deployment) {
+// ErrDeploymentFailed 本轮处理后 Deployment 进入 failed / verify_failed
+var ErrDeploymentFailed = errors.New("部署失败")
+
+// Process 处理一次 Deployment 状态流转；返回处理错误，或本轮进入 failed / verify_failed 时返回 ErrDeploymentFailed
+func (sm *StateMachine) Process(ctx context.Context, dep *model.Deployment) (err error) {
 	handler, ok := sm.handlers[dep.Status]
 	if !ok {
 		sm.logger.Warn("未知 Deployment 状态", zap.Int64("id", dep.ID), zap.String("status", dep.Status))
-		return
+		return nil
 	}
 
+	ctx, span := tracing.Start(ctx, "deployment."+dep.Status,
+		attribute.Int64("deployment.id", dep.ID), attribute.Int64("batch.id", dep.BatchID), attribute.Int64("release_app.id", dep.ReleaseID),
+		attribute.String("deployment.env", dep.Env), attribute.String("deployment.cluster", dep.ClusterName))
+	defer func() { tracing.End(span, err) }()
+
 	nextStatus, updateFunc, err := handler.Handle(ctx, dep)
 	if err != nil {
 		sm.logger.Error("处理失败", zap.Error(err))
-		return
+		return err
 	}
 
+	// 结果写回不随 ctx 取消：停止时已完成的 install/upgrade 仍需落库，被中断的执行需写入等待原因
+	writeCtx := context.WithoutCancel(ctx)
 	if nextStatus != "" && nextStatus != dep.Status {
-		if err := sm.UnifiedUpdate(ctx, dep.ID, nextStatus, updateFunc); err != nil {
+		span.SetAttributes(attribute.String("deployment.next_status", nextStatus))
+		if err := sm.UnifiedUpdate(writeCtx, dep.ID, nextStatus, updateFunc); err != nil {
 			sm.logger.Error("更新失败", zap.Error(err))
+			return err
+		}
+		sm.notifyStatusChange(dep, nextStatus, updateFunc)
+		if constants.IsDeploymentFailed(nextStatus) {
+			return failedError(dep, updateFunc)
 		}
 	} else if updateFunc != nil {
-		// 状态不变但有字段更新
-		if err := sm.UnifiedUpdate(ctx, dep.ID, dep.Status, updateFunc); err != nil {
+		// 状态不变但有字段更新（期间状态已被其他流程改变时放弃，如任务队列的 worker 已推进）
+		if _, err := sm.updateIf(writeCtx, dep.ID, dep.Status, dep.Status, updateFunc); err != nil {
 			sm.logger.Error("字段更新失败", zap.Error(err))
+			return err
 		}
 	}
+	return nil
+}
+
+// notifyStatusChange 以变更后的副本通知监听者，不影响调用方的 dep
+func (sm *StateMachine) notifyStatusChange(dep *model.Deployment, to string, updateFunc func(*model.Deployment)) {
+	if len(sm.listeners) == 0 {
+		return
+	}
+	d := *dep
+	if updateFunc != nil {
+		updateFunc(&d)
+	}
+	d.Status = to
+	for _, l := range sm.listeners {
+		l(d, dep.Status, to)
+	}
+}
+
+// failedError 从 updateFunc 中取出失败原因（作用于副本，不影响调用方的 dep）
+func failedError(dep *model.Deployment, updateFunc func(*model.Deployment)) error {
+	d := *dep
+	if updateFunc != nil {
+		updateFunc(&d)
+	}
+	if d.ErrorMessage == nil || *d.ErrorMessage == "" {
+		return ErrDeploymentFailed
+	}
+	return fmt.Errorf("%w: %s", ErrDeploymentFailed, *d.ErrorMessage)
 }
 
 func (sm *StateMachine) UnifiedUpdate(ctx context.Context, dep_id int64, to string, updateFunc func(*model.Deployment)) error {
-	return sm.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+	_, err := sm.updateIf(ctx, dep_id, "", to, updateFunc)
+	return err
+}
+
+// Fail 将仍处于 dep.Status 的 Deployment 置为 failed（计入重试次数，交给发布应用的重试逻辑处理），状态已变化时返回 false
+func (sm *StateMachine) Fail(ctx context.Context, dep *model.Deployment, reason string) (bool, error) {
+	now := time.Now()
+	updateFunc := func(d *model.Deployment) {
+		setErrorMessage(d, reason)
+		d.RetryCount++
+		if d.StartedAt == nil {
+			d.StartedAt = &now
+		}
+		d.FinishedAt = &now
+		d.LastFailedAt = &now
+	}
+	applied, err := sm.updateIf(ctx, dep.ID, dep.Status, constants.DeploymentStatusFailed, updateFunc)
+	if err != nil || !applied {
+		return false, err
+	}
+	sm.notifyStatusChange(dep, constants.DeploymentStatusFailed, updateFunc)
+	return true, nil
+}
+
+// updateIf 同 UnifiedUpdate；expect 不为空时仅在当前状态为 expect 时更新，否则返回 false
+func (sm *StateMachine) updateIf(ctx context.Context, dep_id int64, expect, to string, updateFunc func(*model.Deployment)) (applied bool, err error) {
+	err = sm.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var dep model.Deployment
 		if err := tx.First(&dep, dep_id).Error; err != nil {
 			return err
 		}
 
 		old := dep.Status
+		if expect != "" && old != expect {
+			sm.logger.Debug(fmt.Sprintf("[Deployment SM] Deployment:%v 状态已变为 %v（期望 %v），跳过更新", dep.ID, old, expect),
+				zap.Int64("deployment_id", dep.ID))
+			return nil
+		}
 		if updateFunc != nil {
 			updateFunc(&dep)
 		}
@@ -79,10 +233,21 @@ func (sm *StateMachine) UnifiedUpdate(ctx context.Context, dep_id int64, to stri
 		if result.Error != nil {
 			return fmt.Errorf("update failed: %w", result.Error)
 		}
+		if old != to {
+			var msg string
+			if constants.IsDeploymentFailed(to) && dep.ErrorMessage != nil {
+				msg = *dep.ErrorMessage
+			}
+			if err := tx.Create(model.NewDeploymentStatusEvent(&dep, old, to, "", msg)).Error; err != nil {
+				return fmt.Errorf("写入状态变更事件失败: %w", err)
+			}
+		}
 
 		sm.logger.Info(fmt.Sprintf("[Deployment SM] Batch:%v ReleaseApp:%v Deployment:%v 状态变更成功: %v -> %v", dep.BatchID, dep.ReleaseID, dep.ID, old, to),
 			zap.Int64("batch_id", dep.BatchID), zap.Int64("release_id", dep.ReleaseID), zap.Int64("deployment_id", dep.ID))
 
+		applied = true
 		return nil
 	})
+	return applied, err
 }
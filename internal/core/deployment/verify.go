@@ -0,0 +1,257 @@
+package deployment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"devops-cd/internal/core/common/metacache"
+	"devops-cd/internal/core/deployment/helpers/tpl"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/tracing"
+	"devops-cd/pkg/constants"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// verifyCheckTimeout 单个验证项的请求超时
+	verifyCheckTimeout = 10 * time.Second
+	// verifyBodyLimit 读取响应体的上限（expect_body 匹配、错误信息截取）
+	verifyBodyLimit = 64 << 10
+)
+
+var verifyHTTPClient = &http.Client{Timeout: verifyCheckTimeout}
+
+// verifyAfterReady workload 就绪后执行应用配置的部署后验证
+//
+//   - 未配置验证（或 kind=config）：直接 success
+//   - 全部通过：success
+//   - 未通过且仍在验证窗口内：保持 running，等待下一轮扫描重新验证
+//   - 超过验证窗口（从 verify_started_at 起算）：verify_failed
+func (sm *StateMachine) verifyAfterReady(ctx context.Context, dep *model.Deployment) (string, func(*model.Deployment), error) {
+	succeed := func(d *model.Deployment) {
+		now := time.Now()
+		d.FinishedAt = &now
+		setErrorMessage(d, "")
+	}
+	if dep.Kind == constants.DeploymentKindConfig {
+		return constants.DeploymentStatusSuccess, succeed, nil
+	}
+
+	app, err := metacache.Application(ctx, sm.db, dep.AppID)
+	if err != nil {
+		return "", nil, fmt.Errorf("load app failed: %w", err)
+	}
+	checks := app.DeployVerify.ChecksFor(dep.Env)
+	if len(checks) == 0 {
+		return constants.DeploymentStatusSuccess, succeed, nil
+	}
+
+	build, err := deploymentBuild(ctx, sm.db, dep)
+	if err != nil {
+		return "", nil, err
+	}
+	data := tpl.RenderTemplateContext(app, build, dep.Env, dep.ClusterName, nil)
+	data["namespace"] = dep.Namespace
+	data["release_name"] = dep.DeploymentName
+
+	now := time.Now()
+	startedAt := now
+	if dep.VerifyStartedAt != nil {
+		startedAt = *dep.VerifyStartedAt
+	}
+
+	failures := runVerifyChecks(ctx, checks, data)
+	if len(failures) == 0 {
+		sm.recordIfChanged(ctx, dep, constants.DeploymentEventVerification, fmt.Sprintf("部署后验证通过（%d 项）", len(checks)))
+		return constants.DeploymentStatusSuccess, func(d *model.Deployment) {
+			succeed(d)
+			d.VerifyStartedAt = &startedAt
+		}, nil
+	}
+
+	message := "部署后验证未通过: " + strings.Join(failures, "; ")
+	sm.recordIfChanged(ctx, dep, constants.DeploymentEventVerification, message)
+
+	timeout := time.Duration(app.DeployVerify.Timeout()) * time.Second
+	if elapsed := now.Sub(startedAt); elapsed >= timeout {
+		reason := fmt.Sprintf("%s（已验证 %s，上限 %s）", message, elapsed.Round(time.Second), timeout)
+		return constants.DeploymentStatusVerifyFailed, func(d *model.Deployment) {
+			setErrorMessage(d, reason)
+			d.VerifyStartedAt = &startedAt
+			d.RetryCount++
+			d.FinishedAt = &now
+			d.LastFailedAt = &now
+		}, nil
+	}
+
+	// 验证窗口内：保持 running，原因写入 error_message 便于查看
+	if dep.VerifyStartedAt != nil && dep.ErrorMessage != nil && *dep.ErrorMessage == message {
+		return "", nil, nil
+	}
+	return "", func(d *model.Deployment) {
+		setErrorMessage(d, message)
+		d.VerifyStartedAt = &startedAt
+	}, nil
+}
+
+// runVerifyChecks 依次执行验证项，返回未通过项的原因（全部通过时为空）
+func runVerifyChecks(ctx context.Context, checks []model.DeployVerifyCheck, data map[string]interface{}) []string {
+	var failures []string
+	for i := range checks {
+		c := &checks[i]
+		name := c.Name
+		if name == "" {
+			name = fmt.Sprintf("%s#%d", c.Type, i+1)
+		}
+		if err := runVerifyCheck(ctx, c, data); err != nil {
+			failures = append(failures, fmt.Sprintf("[%s] %v", name, err))
+		}
+	}
+	return failures
+}
+
+func runVerifyCheck(ctx context.Context, c *model.DeployVerifyCheck, data map[string]interface{}) (err error) {
+	ctx, span := tracing.Start(ctx, "deployment.verify", attribute.String("verify.type", c.Type), attribute.String("verify.name", c.Name))
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, verifyCheckTimeout)
+	defer cancel()
+
+	switch c.Type {
+	case model.DeployVerifyTypeHTTP:
+		return verifyHTTP(ctx, c, data)
+	case model.DeployVerifyTypePrometheus:
+		return verifyPrometheus(ctx, c, data)
+	default:
+		return fmt.Errorf("不支持的验证类型: %s", c.Type)
+	}
+}
+
+// verifyHTTP 探测 url，校验状态码与响应体
+func verifyHTTP(ctx context.Context, c *model.DeployVerifyCheck, data map[string]interface{}) error {
+	target, err := tpl.ParseTemplate(c.URL, data)
+	if err != nil {
+		return fmt.Errorf("url 模板解析失败: %w", err)
+	}
+	method := strings.ToUpper(strings.TrimSpace(c.Method))
+	if method == "" {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, nil)
+	if err != nil {
+		return fmt.Errorf("构造请求失败: %w", err)
+	}
+	resp, err := verifyHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求 %s 失败: %w", target, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, verifyBodyLimit))
+
+	expect := c.ExpectStatus
+	if expect == 0 {
+		expect = http.StatusOK
+	}
+	if resp.StatusCode != expect {
+		return fmt.Errorf("%s 返回 %d（期望 %d）", target, resp.StatusCode, expect)
+	}
+	if c.ExpectBody != "" && !strings.Contains(string(body), c.ExpectBody) {
+		return fmt.Errorf("%s 响应体不包含 %q", target, c.ExpectBody)
+	}
+	return nil
+}
+
+// promQueryResponse Prometheus /api/v1/query 响应（只解析 vector / scalar）
+type promQueryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// verifyPrometheus 即时查询 query，取第一条样本值与 threshold 比较
+func verifyPrometheus(ctx context.Context, c *model.DeployVerifyCheck, data map[string]interface{}) error {
+	base, err := tpl.ParseTemplate(c.PrometheusURL, data)
+	if err != nil {
+		return fmt.Errorf("prometheus_url 模板解析失败: %w", err)
+	}
+	query, err := tpl.ParseTemplate(c.Query, data)
+	if err != nil {
+		return fmt.Errorf("query 模板解析失败: %w", err)
+	}
+
+	endpoint := strings.TrimRight(base, "/") + "/api/v1/query?" + url.Values{"query": {query}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("构造请求失败: %w", err)
+	}
+	resp, err := verifyHTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("查询 Prometheus 失败: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, verifyBodyLimit))
+
+	var out promQueryResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return fmt.Errorf("解析 Prometheus 响应失败（HTTP %d）: %w", resp.StatusCode, err)
+	}
+	if out.Status != "success" {
+		return fmt.Errorf("Prometheus 查询失败: %s", out.Error)
+	}
+
+	value, ok, err := promFirstValue(out.Data.ResultType, out.Data.Result)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("查询无数据: %s", query)
+	}
+	if !c.Compare(value) {
+		return fmt.Errorf("指标值 %g 不满足 %s %g", value, c.Operator, c.Threshold)
+	}
+	return nil
+}
+
+// promFirstValue 取 vector 第一条样本或 scalar 的值；ok=false 表示无数据
+func promFirstValue(resultType string, raw json.RawMessage) (float64, bool, error) {
+	var sample []interface{}
+	switch resultType {
+	case "vector":
+		var vector []struct {
+			Value []interface{} `json:"value"`
+		}
+		if err := json.Unmarshal(raw, &vector); err != nil {
+			return 0, false, fmt.Errorf("解析 vector 结果失败: %w", err)
+		}
+		if len(vector) == 0 {
+			return 0, false, nil
+		}
+		sample = vector[0].Value
+	case "scalar":
+		if err := json.Unmarshal(raw, &sample); err != nil {
+			return 0, false, fmt.Errorf("解析 scalar 结果失败: %w", err)
+		}
+	default:
+		return 0, false, fmt.Errorf("不支持的结果类型: %s（query 需返回 vector 或 scalar）", resultType)
+	}
+	if len(sample) != 2 {
+		return 0, false, fmt.Errorf("样本格式不合法")
+	}
+	s, _ := sample[1].(string)
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("样本值不合法: %v", sample[1])
+	}
+	return value, true, nil
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"devops-cd/internal/pkg/config"
+
+	"go.uber.org/zap"
+)
+
+// 优雅停止
+//
+// - 停止扫描与触发分发，不再启动新的批次处理；任务队列不再领取新任务
+// - 进行中的批次处理完成当前一轮后退出，执行中的部署任务（Chart 拉取、Helm install/upgrade）继续执行到结束
+// - 最多等待 drain_timeout，超时后取消剩余处理：被中断的 Pending Deployment 保持 pending（不计入重试）、
+//   被中断的任务放回队列，重启后（或由新的主节点）重新执行；状态写回不随取消中断
+// - 最后发送已入队的通知
+
+const (
+	defaultDrainTimeout = 30 * time.Second
+	// abortGrace 取消剩余处理后等待其写回结果的时间
+	abortGrace = 5 * time.Second
+)
+
+// goWork 启动计入优雅停止等待的处理
+func (e *CoreEngine) goWork(fn func()) {
+	e.workers.Add(1)
+	go func() {
+		defer e.workers.Done()
+		fn()
+	}()
+}
+
+// Shutdown 优雅停止核心引擎：等待进行中的处理完成，ctx 到期后取消剩余处理并返回 ctx.Err()
+func (e *CoreEngine) Shutdown(ctx context.Context) error {
+	if !e.running {
+		return nil
+	}
+	e.running = false
+
+	e.logger.Info("正在停止核心引擎，等待进行中的处理完成...")
+	e.taskMu.Lock()
+	e.draining.Store(true)
+	batches := len(e.batchTask)
+	e.taskMu.Unlock()
+	close(e.stopChan)
+	e.logger.Info("已停止接收新的处理", zap.Int("batches", batches))
+
+	done := make(chan struct{})
+	go func() {
+		e.workers.Wait()
+		close(done)
+	}()
+
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+		e.logger.Warn("等待进行中的处理超时，取消剩余处理（被中断的部署重启后重新执行）")
+		e.abortWork()
+		if e.jobs != nil {
+			e.jobs.Abort()
+		}
+		select {
+		case <-done:
+		case <-time.After(abortGrace):
+			e.logger.Error("仍有处理未退出，强制停止")
+		}
+	}
+
+	close(e.notifyStop)
+	select {
+	case <-e.notifyDone:
+	case <-time.After(abortGrace):
+		e.logger.Warn("发送剩余通知超时")
+	}
+
+	e.logger.Info("核心引擎已停止")
+	return err
+}
+
+// drainTimeoutFromConfig core.drain_timeout，未配置或格式错误时使用默认值
+func drainTimeoutFromConfig(coreCfg *config.CoreConfig, logger *zap.Logger) time.Duration {
+	if coreCfg == nil || coreCfg.DrainTimeout == "" {
+		return defaultDrainTimeout
+	}
+	d, err := time.ParseDuration(coreCfg.DrainTimeout)
+	if err != nil || d <= 0 {
+		logger.Warn("drain_timeout 解析失败，使用默认值", zap.String("value", coreCfg.DrainTimeout), zap.Error(err))
+		return defaultDrainTimeout
+	}
+	return d
+}
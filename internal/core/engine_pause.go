@@ -0,0 +1,114 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+)
+
+// loadEnginePauses 查询生效中的暂停记录；查询失败时返回 error，调用方按“暂停”处理，避免事故期间误推进
+func (e *CoreEngine) loadEnginePauses(ctx context.Context) ([]*model.EnginePause, error) {
+	var pauses []*model.EnginePause
+	if err := e.db.WithContext(ctx).Scopes(model.ActiveEnginePause).Find(&pauses).Error; err != nil {
+		e.logger.Error("查询引擎暂停记录失败", zap.Error(err))
+		return nil, err
+	}
+	return pauses, nil
+}
+
+// batchPaused 批次（或其所属项目）是否被暂停
+func (e *CoreEngine) batchPaused(ctx context.Context, b *model.Batch) bool {
+	pauses, err := e.loadEnginePauses(ctx)
+	if err != nil {
+		return true
+	}
+	pause := model.FindEnginePause(pauses, b.ProjectID, b.ID)
+	if pause == nil {
+		return false
+	}
+	e.logger.Debug(fmt.Sprintf("[BatchScaner] Batch:%d 已暂停(%s:%d by %s): %s", b.ID, pause.Scope, pause.ScopeID, pause.PausedBy, pause.Reason))
+	return true
+}
+
+// pausedBatchIDs 返回 batchIDs 中被暂停的批次（用于不经过 batchWork 的扫描）
+func (e *CoreEngine) pausedBatchIDs(ctx context.Context, batchIDs []int64) map[int64]bool {
+	paused := make(map[int64]bool)
+	if len(batchIDs) == 0 {
+		return paused
+	}
+	pauses, err := e.loadEnginePauses(ctx)
+	if err != nil {
+		for _, id := range batchIDs {
+			paused[id] = true
+		}
+		return paused
+	}
+	if len(pauses) == 0 {
+		return paused
+	}
+
+	var batches []model.Batch
+	if err := e.db.WithContext(ctx).Select("id", "project_id").Where("id IN ?", batchIDs).Find(&batches).Error; err != nil {
+		e.logger.Error("查询批次失败", zap.Error(err))
+		for _, id := range batchIDs {
+			paused[id] = true
+		}
+		return paused
+	}
+	for _, b := range batches {
+		if model.FindEnginePause(pauses, b.ProjectID, b.ID) != nil {
+			paused[b.ID] = true
+		}
+	}
+	return paused
+}
+
+// pausedClusterNames 返回被暂停的集群名称（Deployment 以集群名关联），查询失败时返回 error
+func (e *CoreEngine) pausedClusterNames(ctx context.Context) (map[string]bool, error) {
+	pauses, err := e.loadEnginePauses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var clusterIDs []int64
+	for _, p := range pauses {
+		if p.Scope == constants.EnginePauseScopeCluster {
+			clusterIDs = append(clusterIDs, p.ScopeID)
+		}
+	}
+	paused := make(map[string]bool, len(clusterIDs))
+	if len(clusterIDs) == 0 {
+		return paused, nil
+	}
+	var names []string
+	if err := e.db.WithContext(ctx).Model(&model.Cluster{}).Where("id IN ?", clusterIDs).Pluck("name", &names).Error; err != nil {
+		e.logger.Error("查询暂停集群失败", zap.Error(err))
+		return nil, err
+	}
+	for _, name := range names {
+		paused[name] = true
+	}
+	return paused, nil
+}
+
+// gateClusterPause 过滤掉被暂停集群上的 Deployment（不触发、不检查状态）；查询暂停记录失败时全部跳过
+func (e *CoreEngine) gateClusterPause(ctx context.Context, deps []*model.Deployment) []*model.Deployment {
+	paused, err := e.pausedClusterNames(ctx)
+	if err != nil {
+		return nil
+	}
+	if len(paused) == 0 {
+		return deps
+	}
+	return lo.Filter(deps, func(dep *model.Deployment, _ int) bool {
+		if paused[dep.ClusterName] {
+			e.logger.Debug(fmt.Sprintf("[Deployment] Deployment:%d 所在集群 %s 已暂停", dep.ID, dep.ClusterName))
+			return false
+		}
+		return true
+	})
+}
@@ -0,0 +1,210 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/leader"
+	"devops-cd/pkg/constants"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+)
+
+// 引擎运行状态与手动扫描（/admin/core）
+//
+// - 扫描与各状态机处理统计为本副本进程内数据，重启后清零；多副本部署时只有主节点运行扫描与状态机
+// - ForceScan 立即执行一轮扫描并唤醒所有进行中的批次处理，不必等待扫描间隔/兜底间隔
+
+var (
+	ErrEngineNotRunning = errors.New("核心引擎未运行或正在停止")
+	ErrNotLeader        = errors.New("当前副本不是主节点，请在主节点上执行")
+)
+
+// 状态机名称（EngineStatus.StateMachines 的 key）
+const (
+	smBatch      = "batch"
+	smReleaseApp = "release_app"
+	smDeployment = "deployment"
+)
+
+// EngineStatus 核心引擎运行状态
+type EngineStatus struct {
+	Running       bool                    `json:"running"`
+	Leader        bool                    `json:"leader"`   // 当前副本是否为主节点（非主节点不扫描，统计不再增长）
+	Draining      bool                    `json:"draining"` // 正在优雅停止
+	ScanInterval  string                  `json:"scan_interval"`
+	ActiveBatches []int64                 `json:"active_batches"` // 本副本正在处理的批次（batchWork）
+	Queues        EngineQueues            `json:"queues"`
+	Scan          ScanStats               `json:"scan"`
+	StateMachines map[string]ProcessStats `json:"state_machines"` // batch / release_app / deployment
+}
+
+// EngineQueues 引擎队列深度
+type EngineQueues struct {
+	Triggers         int    `json:"triggers"` // 待分发的批次触发
+	TriggersCap      int    `json:"triggers_cap"`
+	Notifications    int    `json:"notifications"` // 待发送的通知
+	NotificationsCap int    `json:"notifications_cap"`
+	Jobs             *int64 `json:"jobs,omitempty"` // 待执行/执行中的异步任务（未启用任务队列时为空）
+}
+
+// ScanStats 扫描统计
+type ScanStats struct {
+	Count          int64      `json:"count"`
+	Forced         int64      `json:"forced"` // 其中手动触发的次数
+	LastStartedAt  *time.Time `json:"last_started_at,omitempty"`
+	LastDurationMs int64      `json:"last_duration_ms"`
+	LastBatches    int        `json:"last_batches"` // 上次扫描到的进行中批次数
+}
+
+// ProcessStats 状态机处理统计（每处理一个对象计一次）
+type ProcessStats struct {
+	Processed       int64      `json:"processed"`
+	Errors          int64      `json:"errors"` // 处理返回错误或 panic 的次数（批次/发布应用状态机内部记录错误日志，不计入）
+	AvgDurationMs   float64    `json:"avg_duration_ms"`
+	MaxDurationMs   int64      `json:"max_duration_ms"`
+	LastProcessedAt *time.Time `json:"last_processed_at,omitempty"`
+}
+
+// engineStats 进程内统计，零值可用
+type engineStats struct {
+	mu   sync.Mutex
+	scan ScanStats
+	sm   map[string]*processStats
+}
+
+type processStats struct {
+	processed int64
+	errors    int64
+	total     time.Duration
+	max       time.Duration
+	last      time.Time
+}
+
+// observeScan 记录一次扫描
+func (s *engineStats) observeScan(start time.Time, batches int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scan.Count++
+	s.scan.LastStartedAt = &start
+	s.scan.LastDurationMs = time.Since(start).Milliseconds()
+	s.scan.LastBatches = batches
+}
+
+// observeForcedScan 记录一次手动触发的扫描
+func (s *engineStats) observeForcedScan() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scan.Forced++
+}
+
+// observe 记录状态机处理一个对象
+func (s *engineStats) observe(name string, start time.Time, err error) {
+	elapsed := time.Since(start)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sm == nil {
+		s.sm = make(map[string]*processStats, 3)
+	}
+	p, ok := s.sm[name]
+	if !ok {
+		p = &processStats{}
+		s.sm[name] = p
+	}
+	p.processed++
+	if err != nil {
+		p.errors++
+	}
+	p.total += elapsed
+	p.max = max(p.max, elapsed)
+	p.last = start.Add(elapsed)
+}
+
+func (s *engineStats) snapshot() (ScanStats, map[string]ProcessStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]ProcessStats, 3)
+	for _, name := range []string{smBatch, smReleaseApp, smDeployment} {
+		p, ok := s.sm[name]
+		if !ok {
+			out[name] = ProcessStats{}
+			continue
+		}
+		last := p.last
+		out[name] = ProcessStats{
+			Processed:       p.processed,
+			Errors:          p.errors,
+			AvgDurationMs:   float64(p.total.Milliseconds()) / float64(p.processed),
+			MaxDurationMs:   p.max.Milliseconds(),
+			LastProcessedAt: &last,
+		}
+	}
+	return s.scan, out
+}
+
+// Status 核心引擎运行状态（队列深度、扫描与状态机处理统计）
+func (e *CoreEngine) Status(ctx context.Context) (*EngineStatus, error) {
+	e.taskMu.Lock()
+	active := lo.Keys(e.batchTask)
+	e.taskMu.Unlock()
+	slices.Sort(active)
+
+	scan, sm := e.stats.snapshot()
+	status := &EngineStatus{
+		Running:       e.running,
+		Leader:        leader.IsLeader(e.elector),
+		Draining:      e.draining.Load(),
+		ScanInterval:  e.scanInterval.String(),
+		ActiveBatches: active,
+		Queues: EngineQueues{
+			Triggers:         len(e.triggers),
+			TriggersCap:      cap(e.triggers),
+			Notifications:    len(e.notifyQueue),
+			NotificationsCap: cap(e.notifyQueue),
+		},
+		Scan:          scan,
+		StateMachines: sm,
+	}
+	if e.jobs != nil {
+		var jobs int64
+		if err := e.db.WithContext(ctx).Model(&model.Job{}).Where("status IN ?", constants.JobActiveStatuses).Count(&jobs).Error; err != nil {
+			return nil, err
+		}
+		status.Queues.Jobs = &jobs
+	}
+	return status, nil
+}
+
+// ForceScan 请求立即执行一轮扫描（异步，由扫描 goroutine 执行）；已有待执行的手动扫描时合并
+func (e *CoreEngine) ForceScan() error {
+	if !e.running || e.draining.Load() {
+		return ErrEngineNotRunning
+	}
+	if !leader.IsLeader(e.elector) {
+		return ErrNotLeader
+	}
+	select {
+	case e.scanNow <- struct{}{}:
+	default:
+	}
+	e.logger.Info("[BatchScaner] 收到手动扫描请求")
+	return nil
+}
+
+// wakeBatchTasks 唤醒所有进行中的批次处理，立即执行一轮
+func (e *CoreEngine) wakeBatchTasks() {
+	e.taskMu.Lock()
+	defer e.taskMu.Unlock()
+	for _, t := range e.batchTask {
+		select {
+		case t.wake <- struct{}{}:
+		default: // 已有待处理的唤醒
+		}
+	}
+	e.logger.Debug("[BatchScaner] 已唤醒进行中的批次处理", zap.Int("batches", len(e.batchTask)))
+}
@@ -0,0 +1,80 @@
+package core
+
+import (
+	"context"
+	"devops-cd/internal/core/common/registry"
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/config"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// registryImageVerifier 通过 Registry v2 API 校验构建镜像是否存在
+type registryImageVerifier struct {
+	db     *gorm.DB
+	hosts  []config.RegistryHostConfig
+	client *registry.Client
+}
+
+// configureImageVerifier 按 core.registry 配置启用部署前镜像校验
+func (e *CoreEngine) configureImageVerifier(coreCfg *config.CoreConfig) {
+	if coreCfg == nil || !coreCfg.Registry.VerifyImage {
+		return
+	}
+	cfg := coreCfg.Registry
+	var timeout time.Duration
+	if cfg.Timeout != "" {
+		d, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			e.logger.Warn("core.registry.timeout 解析失败，使用默认值", zap.String("value", cfg.Timeout), zap.Error(err))
+		}
+		timeout = d
+	}
+	v := &registryImageVerifier{db: e.db, hosts: cfg.Hosts}
+	v.client = registry.NewClient(timeout, v.host)
+	e.releaseSM.SetImageVerifier(v)
+}
+
+func (v *registryImageVerifier) VerifyImage(ctx context.Context, build *model.Build) (string, bool, error) {
+	image := helmDriver.ImageRef(build.ImageURL, build.ImageTag)
+	if image == "" {
+		// 构建未记录镜像地址，无法校验
+		return "", true, nil
+	}
+	ref, err := registry.ParseReference(image)
+	if err != nil {
+		return image, false, err
+	}
+	exists, err := v.client.ManifestExists(ctx, ref)
+	return image, exists, err
+}
+
+// host 返回 registry 访问配置，凭据每次从 config_credentials 解密（凭据轮换后无需重启）
+func (v *registryImageVerifier) host(host string) (registry.Host, error) {
+	for _, h := range v.hosts {
+		if !strings.EqualFold(strings.TrimSpace(h.Host), host) {
+			continue
+		}
+		out := registry.Host{Insecure: h.Insecure}
+		cred, err := helmDriver.ResolveCredentialData(v.db, h.CredentialRef)
+		if err != nil {
+			return out, fmt.Errorf("registry %s 凭据解析失败: %w", host, err)
+		}
+		switch cred["_type"] {
+		case string(model.CredentialTypeBasicAuth):
+			out.Credentials = &registry.Credentials{Username: cred["username"], Password: cred["password"]}
+		case string(model.CredentialTypeToken):
+			out.Credentials = &registry.Credentials{Token: cred["token"]}
+		case "":
+		default:
+			return out, fmt.Errorf("registry %s 凭据类型不支持: %s", host, cred["_type"])
+		}
+		return out, nil
+	}
+	return registry.Host{}, nil
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"devops-cd/internal/adapter/jira"
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+
+	"go.uber.org/zap"
+)
+
+// issueLinkTimeout 单次 issue 关联（拉取提交 / 流转 issue）的超时
+const issueLinkTimeout = 5 * time.Minute
+
+// registerIssueLinkListener 批次封板后解析发布应用关联的 issue，完成后按项目 Jira 配置流转 issue
+//
+// 均为异步执行：Git / Jira 接口失败只记录日志，不影响批次状态流转
+func (e *CoreEngine) registerIssueLinkListener() {
+	e.batchSM.OnStatusChange(e.linkIssues)
+}
+
+func (e *CoreEngine) linkIssues(b model.Batch, from, to int8) {
+	if from == to {
+		return
+	}
+	switch to {
+	case constants.BatchStatusSealed:
+		if e.changelog == nil {
+			return
+		}
+		go e.recordIssueKeys(b.ID, b.ProjectID)
+	case constants.BatchStatusCompleted:
+		go e.transitionIssues(b.ID, b.ProjectID)
+	}
+}
+
+// recordIssueKeys 从各应用 previous_deployed_tag → target_tag 之间的提交说明解析 issue key，写入 release_apps.issue_keys
+func (e *CoreEngine) recordIssueKeys(batchID, projectID int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), issueLinkTimeout)
+	defer cancel()
+	log := e.logger.With(zap.Int64("batch_id", batchID))
+
+	cfg, err := e.jiraConfig(ctx, projectID)
+	if err != nil {
+		log.Warn("[IssueLink] 查询项目 Jira 配置失败", zap.Error(err))
+		return
+	}
+	var projectKeys []string
+	if cfg.IsEnabled() {
+		projectKeys = cfg.ProjectKeys
+	}
+
+	out, err := e.changelog.Generate(ctx, batchID)
+	if err != nil {
+		log.Warn("[IssueLink] 生成变更日志失败", zap.Error(err))
+		return
+	}
+	total := 0
+	for _, team := range out.Teams {
+		for _, app := range team.Apps {
+			if app.Error != "" {
+				log.Debug("[IssueLink] 应用无法解析 issue", zap.Int64("app_id", app.AppID), zap.String("reason", app.Error))
+				continue
+			}
+			keys := app.IssueKeys(projectKeys)
+			// 字段在模型中只读，按表名更新
+			if err := e.db.WithContext(ctx).Table(model.BatchReleaseAppTableName).
+				Where("batch_id = ? AND app_id = ?", batchID, app.AppID).
+				Update("issue_keys", model.StringList(keys)).Error; err != nil {
+				log.Warn("[IssueLink] 记录关联 issue 失败", zap.Int64("app_id", app.AppID), zap.Error(err))
+				continue
+			}
+			total += len(keys)
+		}
+	}
+	log.Info("[IssueLink] 已记录关联 issue", zap.Int("issues", total))
+}
+
+// transitionIssues 批次完成后将关联 issue 流转到项目配置的状态（默认 Released）
+func (e *CoreEngine) transitionIssues(batchID, projectID int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), issueLinkTimeout)
+	defer cancel()
+	log := e.logger.With(zap.Int64("batch_id", batchID))
+
+	cfg, err := e.jiraConfig(ctx, projectID)
+	if err != nil {
+		log.Warn("[IssueLink] 查询项目 Jira 配置失败", zap.Error(err))
+		return
+	}
+	if !cfg.IsEnabled() || !cfg.TransitionOnComplete {
+		return
+	}
+
+	var releases []model.ReleaseApp
+	if err := e.db.WithContext(ctx).Select("id", "app_id", "issue_keys").
+		Where("batch_id = ?", batchID).Find(&releases).Error; err != nil {
+		log.Warn("[IssueLink] 查询发布应用失败", zap.Error(err))
+		return
+	}
+	seen := map[string]bool{}
+	var keys []string
+	for _, r := range releases {
+		for _, k := range r.IssueKeys {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+
+	cred, err := helmDriver.ResolveCredentialData(e.db, cfg.CredentialRef)
+	if err != nil {
+		log.Warn("[IssueLink] 解析 Jira 凭据失败", zap.Error(err))
+		return
+	}
+	client := jira.NewClient(cfg.BaseURL, cred)
+
+	transitioned, failed := 0, 0
+	for _, key := range keys {
+		done, err := client.Transition(ctx, key, cfg.Transition)
+		if err != nil {
+			failed++
+			log.Warn("[IssueLink] 流转 issue 失败", zap.String("issue", key), zap.Error(err))
+			continue
+		}
+		if done {
+			transitioned++
+		}
+	}
+	log.Info("[IssueLink] 关联 issue 流转完成", zap.String("transition", cfg.Transition),
+		zap.Int("issues", len(keys)), zap.Int("transitioned", transitioned), zap.Int("failed", failed))
+}
+
+func (e *CoreEngine) jiraConfig(ctx context.Context, projectID int64) (*model.JiraConfig, error) {
+	var project model.Project
+	if err := e.db.WithContext(ctx).Select("id", "jira_config").First(&project, projectID).Error; err != nil {
+		return nil, fmt.Errorf("查询项目失败: %w", err)
+	}
+	return project.JiraConfig, nil
+}
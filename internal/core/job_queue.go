@@ -0,0 +1,82 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"devops-cd/internal/core/deployment"
+	"devops-cd/internal/core/jobs"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/config"
+	"devops-cd/pkg/constants"
+
+	"go.uber.org/zap"
+)
+
+// newJobQueue core.jobs.enabled 时创建任务队列，未启用时返回 nil（Deployment 在状态机中同步执行）
+func newJobQueue(e *CoreEngine, coreCfg *config.CoreConfig) *jobs.Queue {
+	if coreCfg == nil || !coreCfg.Jobs.Enabled {
+		return nil
+	}
+	cfg := coreCfg.Jobs
+	opts := jobs.Options{Workers: cfg.Workers, MaxAttempts: cfg.MaxAttempts}
+	for _, d := range []struct {
+		name  string
+		value string
+		out   *time.Duration
+	}{
+		{"poll_interval", cfg.PollInterval, &opts.PollInterval},
+		{"timeout", cfg.Timeout, &opts.Timeout},
+	} {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil {
+			e.logger.Warn("[Jobs] "+d.name+" 配置无效，使用默认值", zap.String("value", d.value), zap.Error(err))
+			continue
+		}
+		*d.out = v
+	}
+	return jobs.NewQueue(e.db, e.logger, opts)
+}
+
+// Jobs 异步任务队列（供管理接口重新入队后唤醒 worker），未启用时为 nil
+func (e *CoreEngine) Jobs() *jobs.Queue {
+	return e.jobs
+}
+
+// runJobs 运行任务队列 worker，引擎停止时等待执行中的任务返回
+//
+// 任务通过 DB 乐观锁领取，多副本时各副本均运行 worker（与主节点选举无关）
+func (e *CoreEngine) runJobs() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-e.stopChan
+		cancel()
+	}()
+	e.jobs.Run(ctx)
+}
+
+// queuedDeployments deps 中已入队执行（任务未结束）的 Deployment
+func (e *CoreEngine) queuedDeployments(ctx context.Context, deps []*model.Deployment) map[int64]bool {
+	queued := map[int64]bool{}
+	if e.jobs == nil || len(deps) == 0 {
+		return queued
+	}
+	ids := make([]int64, 0, len(deps))
+	for _, dep := range deps {
+		ids = append(ids, dep.ID)
+	}
+	var active []int64
+	if err := e.db.WithContext(ctx).Model(&model.Job{}).
+		Where("type = ? AND deployment_id IN ? AND status IN ?", deployment.JobTypeExecute, ids, constants.JobActiveStatuses).
+		Pluck("deployment_id", &active).Error; err != nil {
+		e.logger.Warn("[Jobs] 查询已入队的 Deployment 失败", zap.Error(err))
+		return queued
+	}
+	for _, id := range active {
+		queued[id] = true
+	}
+	return queued
+}
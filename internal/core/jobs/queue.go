@@ -0,0 +1,381 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// 异步任务队列
+//
+// - Enqueue 只写入 pending 记录；同一 dedup_key 同时只有一个未结束任务（active_key 唯一索引）
+// - worker 以乐观锁领取到期任务并写入租约，执行超时或进程退出后租约过期，由其他 worker（可在其他副本）重新领取
+// - 失败按 10s·2^(n-1)（上限 10m）退避重试，attempts 达到 max_attempts 后进入死信（dead）
+
+const (
+	DefaultWorkers      = 4
+	DefaultPollInterval = 2 * time.Second
+	DefaultMaxAttempts  = 3
+	DefaultTimeout      = 30 * time.Minute
+
+	baseBackoff    = 10 * time.Second
+	maxBackoff     = 10 * time.Minute
+	claimBatchSize = 10
+	// leaseGrace 租约在执行超时之外的余量（结果写回 DB 的时间）
+	leaseGrace = time.Minute
+)
+
+// Handler 任务处理函数：返回错误时按退避重试，超过最大次数进入死信
+type Handler func(ctx context.Context, job *model.Job) error
+
+// Options 队列参数，<= 0 时使用默认值
+type Options struct {
+	Workers      int
+	PollInterval time.Duration
+	MaxAttempts  int
+	Timeout      time.Duration // 单次执行超时
+}
+
+// Queue 基于数据库的任务队列与 worker 池
+type Queue struct {
+	db     *gorm.DB
+	logger *zap.Logger
+	opts   Options
+	owner  string    // 租约持有者标识（hostname:pid）
+	since  time.Time // 队列创建时间，早于该时间领取的 running 任务不属于本进程
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+
+	wake chan struct{}
+
+	// 执行中任务的 ctx，Abort 时取消
+	execCtx context.Context
+	abort   context.CancelFunc
+}
+
+func NewQueue(db *gorm.DB, logger *zap.Logger, opts Options) *Queue {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWorkers
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	host, _ := os.Hostname()
+	execCtx, abort := context.WithCancel(context.Background())
+	return &Queue{
+		db:       db,
+		logger:   logger,
+		opts:     opts,
+		owner:    fmt.Sprintf("%s:%d", host, os.Getpid()),
+		since:    time.Now(),
+		handlers: make(map[string]Handler),
+		wake:     make(chan struct{}, 1),
+		execCtx:  execCtx,
+		abort:    abort,
+	}
+}
+
+// Register 注册任务类型的处理函数，需在 Run 之前调用
+func (q *Queue) Register(jobType string, h Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[jobType] = h
+}
+
+func (q *Queue) handler(jobType string) (Handler, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	h, ok := q.handlers[jobType]
+	return h, ok
+}
+
+// EnqueueRequest 入队请求
+type EnqueueRequest struct {
+	Type         string
+	Key          string      // 去重键，为空不去重
+	Payload      interface{} // JSON 序列化后保存
+	MaxAttempts  int         // <= 0 时使用队列默认值
+	BatchID      *int64
+	DeploymentID *int64
+}
+
+// Enqueue 写入 pending 任务；去重键已有未结束的任务时返回该任务，created 为 false
+func (q *Queue) Enqueue(ctx context.Context, req *EnqueueRequest) (job *model.Job, created bool, err error) {
+	body, err := json.Marshal(req.Payload)
+	if err != nil {
+		return nil, false, fmt.Errorf("序列化任务参数失败: %w", err)
+	}
+	job = &model.Job{
+		Type:         req.Type,
+		DedupKey:     req.Key,
+		Payload:      datatypes.JSON(body),
+		Status:       constants.JobStatusPending,
+		MaxAttempts:  req.MaxAttempts,
+		RunAt:        time.Now(),
+		BatchID:      req.BatchID,
+		DeploymentID: req.DeploymentID,
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = q.opts.MaxAttempts
+	}
+	if req.Key != "" {
+		key := req.Key
+		job.ActiveKey = &key
+	}
+
+	if err := q.db.WithContext(ctx).Create(job).Error; err != nil {
+		if req.Key == "" || !isDuplicate(err) {
+			return nil, false, fmt.Errorf("写入任务失败: %w", err)
+		}
+		var existing model.Job
+		if err := q.db.WithContext(ctx).Where("active_key = ?", req.Key).First(&existing).Error; err != nil {
+			return nil, false, fmt.Errorf("查询未结束的任务失败: %w", err)
+		}
+		return &existing, false, nil
+	}
+	q.Notify()
+	return job, true, nil
+}
+
+// Latest 去重键最近的一个任务，不存在时返回 nil
+func (q *Queue) Latest(ctx context.Context, key string) (*model.Job, error) {
+	var job model.Job
+	if err := q.db.WithContext(ctx).Where("dedup_key = ?", key).Order("id DESC").Limit(1).Find(&job).Error; err != nil {
+		return nil, fmt.Errorf("查询任务失败: %w", err)
+	}
+	if job.ID == 0 {
+		return nil, nil
+	}
+	return &job, nil
+}
+
+// Notify 唤醒空闲 worker 立即领取任务（如管理接口重新入队后）
+func (q *Queue) Notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run 启动 worker 池；ctx 取消后不再领取新任务，等待执行中的任务返回（执行中的任务不随 ctx 取消，见 Abort）
+func (q *Queue) Run(ctx context.Context) {
+	q.logger.Info("[Jobs] 任务队列启动", zap.Int("workers", q.opts.Workers), zap.String("owner", q.owner))
+	var wg sync.WaitGroup
+	for i := 0; i < q.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx)
+		}()
+	}
+	wg.Wait()
+	q.logger.Info("[Jobs] 任务队列已停止")
+}
+
+// Abort 取消执行中的任务（停止时等待超时后调用），被中断的任务放回 pending 且不计入执行次数
+func (q *Queue) Abort() {
+	q.abort()
+}
+
+// ReleaseOrphans 将本进程启动前领取、仍为 running 的任务放回 pending（立即可重新领取，本次不计入执行次数）
+//
+// 只能在单副本部署时调用（进程重启后这些任务的执行者已不存在）；多副本时由租约过期后重新领取
+func (q *Queue) ReleaseOrphans(ctx context.Context) (int64, error) {
+	result := q.db.WithContext(ctx).Model(&model.Job{}).
+		Where("status = ? AND started_at < ?", constants.JobStatusRunning, q.since).
+		Updates(map[string]interface{}{
+			"status":       constants.JobStatusPending,
+			"attempts":     gorm.Expr("CASE WHEN attempts > 0 THEN attempts - 1 ELSE 0 END"),
+			"last_error":   "服务重启，执行被中断",
+			"run_at":       time.Now(),
+			"locked_until": nil,
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("释放中断的任务失败: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		q.Notify()
+	}
+	return result.RowsAffected, nil
+}
+
+func (q *Queue) work(ctx context.Context) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		if job := q.claim(ctx); job != nil {
+			q.execute(ctx, job)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		case <-time.After(q.opts.PollInterval):
+		}
+	}
+}
+
+// claim 领取一个到期任务：pending 且 run_at 已到，或 running 但租约已过期
+func (q *Queue) claim(ctx context.Context) *model.Job {
+	now := time.Now()
+	var due []model.Job
+	if err := q.db.WithContext(ctx).Select("id").
+		Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_until < ?)",
+			constants.JobStatusPending, now, constants.JobStatusRunning, now).
+		Order("run_at").Limit(claimBatchSize).Find(&due).Error; err != nil {
+		if ctx.Err() == nil {
+			q.logger.Error(fmt.Sprintf("[Jobs] 查询待执行任务失败: %v", err))
+		}
+		return nil
+	}
+
+	lease := now.Add(q.opts.Timeout + leaseGrace)
+	for i := range due {
+		result := q.db.WithContext(ctx).Model(&model.Job{}).
+			Where("id = ?", due[i].ID).
+			Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_until < ?)",
+				constants.JobStatusPending, now, constants.JobStatusRunning, now).
+			Updates(map[string]interface{}{
+				"status":       constants.JobStatusRunning,
+				"attempts":     gorm.Expr("attempts + 1"),
+				"locked_by":    q.owner,
+				"locked_until": lease,
+				"started_at":   now,
+			})
+		if result.Error != nil {
+			q.logger.Error(fmt.Sprintf("[Jobs] 领取任务失败 job=%d: %v", due[i].ID, result.Error))
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		var job model.Job
+		if err := q.db.WithContext(ctx).First(&job, due[i].ID).Error; err != nil {
+			q.logger.Error(fmt.Sprintf("[Jobs] 查询已领取任务失败 job=%d: %v", due[i].ID, err))
+			continue
+		}
+		return &job
+	}
+	return nil
+}
+
+// execute 执行任务并写回结果
+func (q *Queue) execute(ctx context.Context, job *model.Job) {
+	log := q.logger.With(zap.Int64("job_id", job.ID), zap.String("type", job.Type), zap.Int("attempts", job.Attempts))
+
+	// 租约过期被重新领取（上次执行超时或进程退出）且已超过最大次数
+	if job.Attempts > job.MaxAttempts {
+		q.finish(job, errors.New("执行超时或 worker 退出，已超过最大重试次数"), true, log)
+		return
+	}
+
+	h, ok := q.handler(job.Type)
+	if !ok {
+		q.finish(job, fmt.Errorf("未注册的任务类型: %s", job.Type), true, log)
+		return
+	}
+
+	log.Debug("[Jobs] 开始执行任务")
+	runCtx, cancel := context.WithTimeout(q.execCtx, q.opts.Timeout)
+	defer cancel()
+	err := safeRun(runCtx, h, job)
+	q.finish(job, err, false, log)
+}
+
+func safeRun(ctx context.Context, h Handler, job *model.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	return h(ctx, job)
+}
+
+// finish 写回执行结果（giveUp 为 true 时失败直接进入死信）；租约已被其他 worker 接管时不覆盖
+func (q *Queue) finish(job *model.Job, runErr error, giveUp bool, log *zap.Logger) {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"locked_until": nil,
+	}
+	switch {
+	case runErr == nil:
+		updates["status"] = constants.JobStatusSucceeded
+		updates["active_key"] = nil
+		updates["last_error"] = ""
+		updates["finished_at"] = now
+	case q.execCtx.Err() != nil:
+		// 停止时被中断：立即可重新领取，本次不计入执行次数
+		updates["status"] = constants.JobStatusPending
+		updates["attempts"] = gorm.Expr("attempts - 1")
+		updates["last_error"] = "服务停止，执行被中断: " + runErr.Error()
+		updates["run_at"] = now
+	case giveUp || job.Attempts >= job.MaxAttempts:
+		updates["status"] = constants.JobStatusDead
+		updates["active_key"] = nil
+		updates["last_error"] = runErr.Error()
+		updates["finished_at"] = now
+	default:
+		updates["status"] = constants.JobStatusPending
+		updates["last_error"] = runErr.Error()
+		updates["run_at"] = now.Add(Backoff(job.Attempts))
+	}
+
+	// 使用独立的 ctx：停止时执行中的任务仍需写回结果
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result := q.db.WithContext(ctx).Model(&model.Job{}).
+		Where("id = ? AND status = ? AND locked_by = ? AND attempts = ?", job.ID, constants.JobStatusRunning, q.owner, job.Attempts).
+		Updates(updates)
+	if result.Error != nil {
+		log.Error("[Jobs] 写回任务结果失败", zap.Error(result.Error))
+		return
+	}
+	if result.RowsAffected == 0 {
+		log.Warn("[Jobs] 任务租约已被接管，丢弃本次结果")
+		return
+	}
+
+	switch updates["status"] {
+	case constants.JobStatusSucceeded:
+		log.Debug("[Jobs] 任务执行成功")
+	case constants.JobStatusDead:
+		log.Error("[Jobs] 任务失败且超过最大重试次数，进入死信", zap.Error(runErr))
+	default:
+		log.Warn("[Jobs] 任务执行失败，等待重试", zap.Error(runErr), zap.Any("run_at", updates["run_at"]))
+	}
+}
+
+// Backoff 第 attempts 次失败后的重试间隔：10s·2^(attempts-1)，上限 10m
+func Backoff(attempts int) time.Duration {
+	wait := baseBackoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxBackoff)
+}
+
+func isDuplicate(err error) bool {
+	return errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "Duplicate entry")
+}
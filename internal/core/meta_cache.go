@@ -0,0 +1,46 @@
+package core
+
+import (
+	"devops-cd/internal/core/common/metacache"
+	"devops-cd/internal/pkg/config"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// configureMetaCache 按 core.meta_cache 配置初始化元数据缓存，并在 db 上注册写入失效回调
+func (e *CoreEngine) configureMetaCache(coreCfg *config.CoreConfig) {
+	opts := metacache.Options{}
+	if coreCfg != nil {
+		cfg := coreCfg.MetaCache
+		opts.Enabled = cfg.Enabled
+		if cfg.TTL != "" {
+			ttl, err := time.ParseDuration(cfg.TTL)
+			if err != nil {
+				e.logger.Warn("[MetaCache] ttl 配置无效, 使用默认值", zap.String("value", cfg.TTL), zap.Error(err))
+			}
+			opts.TTL = ttl
+		}
+	}
+	if !opts.Enabled {
+		metacache.Configure(opts)
+		return
+	}
+	if err := metacache.RegisterInvalidation(e.db); err != nil {
+		// 无法感知写入时不启用缓存，避免读到旧数据
+		e.logger.Error("[MetaCache] 注册失效回调失败, 不启用元数据缓存", zap.Error(err))
+		opts.Enabled = false
+	}
+	metacache.Configure(opts)
+}
+
+// MetaCacheStats 元数据缓存命中统计
+func (e *CoreEngine) MetaCacheStats() metacache.Stats {
+	return metacache.Default().Stats()
+}
+
+// FlushMetaCache 清空元数据缓存（直接修改数据库后使用）
+func (e *CoreEngine) FlushMetaCache() {
+	metacache.Default().Flush()
+	e.logger.Info("[MetaCache] 已清空")
+}
@@ -0,0 +1,286 @@
+package core
+
+import (
+	"context"
+	"devops-cd/internal/adapter/notification"
+	"devops-cd/internal/core/changelog"
+	"devops-cd/internal/core/webhook"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/config"
+	"devops-cd/pkg/constants"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const notifyQueueSize = 256
+
+// 批次完成通知附带的变更日志：每个应用最多列出的提交数、总长度上限（Lark 消息体积有限）
+const (
+	notifyChangelogCommits = 10
+	notifyChangelogMaxLen  = 8000
+)
+
+// newNotifier 按 core.notification 配置创建通知器，未启用时使用 LogNotifier；
+// 启用时按通知路由规则（notification_rules）分发，未匹配规则的通知发送到配置的默认渠道
+func newNotifier(db *gorm.DB, logger *zap.Logger, coreCfg *config.CoreConfig) notification.Notifier {
+	if coreCfg == nil || !coreCfg.Notification.Enabled {
+		return notification.NewLogNotifier(logger)
+	}
+	return notification.NewRuleNotifier(newDefaultNotifier(db, logger, coreCfg), notification.NewDBRuleStore(db), logger)
+}
+
+// newDefaultNotifier 按 provider 创建默认通知器，多个渠道时同时发送；渠道配置不完整时跳过，均不可用时使用 LogNotifier
+func newDefaultNotifier(db *gorm.DB, logger *zap.Logger, coreCfg *config.CoreConfig) notification.Notifier {
+	cfg := coreCfg.Notification
+	var notifiers []notification.Notifier
+	for _, provider := range strings.Split(cfg.Provider, ",") {
+		if n := newProviderNotifier(db, logger, strings.TrimSpace(provider), cfg); n != nil {
+			notifiers = append(notifiers, n)
+		}
+	}
+	switch len(notifiers) {
+	case 0:
+		return notification.NewLogNotifier(logger)
+	case 1:
+		return notifiers[0]
+	}
+	return notification.NewMultiNotifier(logger, notifiers...)
+}
+
+func newProviderNotifier(db *gorm.DB, logger *zap.Logger, provider string, cfg config.NotificationConfig) notification.Notifier {
+	switch provider {
+	case "lark":
+		if cfg.LarkWebhook == "" {
+			logger.Warn("[Notify] lark_webhook 未配置，跳过 lark 通知")
+			return nil
+		}
+		return notification.NewLarkNotifier(cfg.LarkWebhook, true, logger)
+	case "lark_thread":
+		if cfg.LarkAppID == "" || cfg.LarkAppSecret == "" || cfg.LarkChatID == "" {
+			logger.Warn("[Notify] lark_app_id/lark_app_secret/lark_chat_id 未配置，跳过 lark_thread 通知")
+			return nil
+		}
+		return notification.NewLarkThreadNotifier(notification.LarkAppConfig{
+			APIBase:   cfg.LarkAPIBase,
+			AppID:     cfg.LarkAppID,
+			AppSecret: cfg.LarkAppSecret,
+			ChatID:    cfg.LarkChatID,
+		}, notification.NewBatchThreadStore(db), logger)
+	case "slack":
+		if cfg.SlackWebhook == "" {
+			logger.Warn("[Notify] slack_webhook 未配置，跳过 slack 通知")
+			return nil
+		}
+		return notification.NewSlackNotifier(cfg.SlackWebhook, logger)
+	case "dingtalk":
+		if cfg.DingTalkWebhook == "" {
+			logger.Warn("[Notify] dingtalk_webhook 未配置，跳过 dingtalk 通知")
+			return nil
+		}
+		return notification.NewDingTalkNotifier(cfg.DingTalkWebhook, cfg.DingTalkSecret, logger)
+	case "email":
+		if cfg.SMTPHost == "" || len(cfg.EmailTo) == 0 || (cfg.SMTPFrom == "" && cfg.SMTPUsername == "") {
+			logger.Warn("[Notify] smtp_host/smtp_from/email_to 未配置，跳过 email 通知")
+			return nil
+		}
+		return notification.NewEmailNotifier(notification.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+			To:       cfg.EmailTo,
+			TLS:      cfg.SMTPTLS,
+		}, logger)
+	case "log", "":
+		return nil
+	}
+	logger.Warn("[Notify] 不支持的通知渠道", zap.String("provider", provider))
+	return nil
+}
+
+// newChangelogGenerator 创建变更日志生成器（key_id 为空的旧仓库源 token 需全局配置中的 AES Key 解密）
+func newChangelogGenerator(db *gorm.DB) *changelog.Generator {
+	if config.GlobalConfig == nil {
+		return nil
+	}
+	return changelog.NewGenerator(db)
+}
+
+// 批次状态 → 通知类型与说明
+var batchNotifies = map[int8]struct {
+	typ     notification.NotificationType
+	message string
+}{
+	constants.BatchStatusSealed:         {notification.NotifyStateTransition, "批次已封板"},
+	constants.BatchStatusPreWaiting:     {notification.NotifyBatchStart, "预发布部署开始"},
+	constants.BatchStatusPreDeployed:    {notification.NotifyStateTransition, "预发布部署完成，等待验收"},
+	constants.BatchStatusPreFailed:      {notification.NotifyBatchFailed, "预发布部署失败"},
+	constants.BatchStatusProdWaiting:    {notification.NotifyDeployStart, "生产部署开始"},
+	constants.BatchStatusProdDeployed:   {notification.NotifyStateTransition, "生产部署完成，等待验收"},
+	constants.BatchStatusProdFailed:     {notification.NotifyBatchFailed, "生产部署失败"},
+	constants.BatchStatusCompleted:      {notification.NotifyBatchComplete, "批次已完成"},
+	constants.BatchStatusRollingBack:    {notification.NotifyDeployStart, "开始回滚"},
+	constants.BatchStatusRolledBack:     {notification.NotifyStateTransition, "回滚完成"},
+	constants.BatchStatusRollbackFailed: {notification.NotifyBatchFailed, "回滚失败"},
+	constants.BatchStatusCancelled:      {notification.NotifyStateTransition, "批次已取消"},
+}
+
+// registerNotifyListeners 批次/发布应用状态变更后发送通知（lark_thread 下同一批次的通知归入同一话题）
+func (e *CoreEngine) registerNotifyListeners() {
+	e.batchSM.OnStatusChange(e.notifyBatch)
+	e.releaseSM.OnStatusChange(e.notifyRelease)
+}
+
+// 批次状态 → 结束的部署阶段，进入该状态时发送阶段的应用部署汇总（nil 表示全部阶段）
+var digestFlushStages = map[int8][]string{
+	constants.BatchStatusPreDeployed:    {"pre"},
+	constants.BatchStatusPreFailed:      {"pre"},
+	constants.BatchStatusProdDeployed:   {"prod"},
+	constants.BatchStatusProdFailed:     {"prod"},
+	constants.BatchStatusRolledBack:     {"rollback"},
+	constants.BatchStatusRollbackFailed: {"rollback"},
+	constants.BatchStatusCompleted:      nil,
+	constants.BatchStatusCancelled:      nil,
+}
+
+func (e *CoreEngine) notifyBatch(b model.Batch, from, to int8) {
+	n, ok := batchNotifies[to]
+	if !ok || from == to {
+		return
+	}
+	e.enqueueNotify(func(ctx context.Context) error {
+		// 阶段汇总先于批次状态通知发送
+		if stages, ok := digestFlushStages[to]; ok {
+			if err := e.digest.Flush(ctx, &b, stages...); err != nil {
+				e.logger.Warn("[Notify] 发送部署汇总失败", zap.Int64("batch_id", b.ID), zap.Error(err))
+			}
+		}
+		message := n.message
+		if to == constants.BatchStatusCompleted && e.attachChangelog && e.changelog != nil {
+			message += "\n\n" + e.changelogMessage(ctx, b.ID)
+		}
+		return e.notifier.SendBatchNotification(ctx, &b, n.typ, message)
+	})
+}
+
+// changelogMessage 批次变更日志（Markdown），生成失败时返回说明，不阻断完成通知
+func (e *CoreEngine) changelogMessage(ctx context.Context, batchID int64) string {
+	out, err := e.changelog.Generate(ctx, batchID)
+	if err != nil {
+		e.logger.Warn("[Notify] 生成批次变更日志失败", zap.Int64("batch_id", batchID), zap.Error(err))
+		return "变更日志生成失败"
+	}
+	md := out.Markdown(notifyChangelogCommits)
+	if len(md) > notifyChangelogMaxLen {
+		cut := strings.LastIndex(md[:notifyChangelogMaxLen], "\n")
+		if cut < 0 {
+			cut = notifyChangelogMaxLen
+		}
+		md = md[:cut] + "\n……（变更日志过长已截断，完整内容见 /api/v1/batch/" + strconv.FormatInt(batchID, 10) + "/changelog）"
+	}
+	return md
+}
+
+func (e *CoreEngine) notifyRelease(r model.ReleaseApp, from, to int8) {
+	typ, message, ok := releaseNotify(r, from, to)
+	if !ok {
+		return
+	}
+	_, stage, _ := webhook.ReleaseEvent(to)
+	e.enqueueNotify(func(ctx context.Context) error {
+		var app model.Application
+		if err := e.db.WithContext(ctx).Select("id", "name").First(&app, r.AppID).Error; err != nil {
+			return fmt.Errorf("查询应用失败: %w", err)
+		}
+		// 项目开启汇总时只收集结果，阶段结束时统一发送
+		collected, err := e.digest.Add(ctx, r.BatchID, stage, notification.DigestEntry{
+			AppID:   app.ID,
+			AppName: app.Name,
+			Failed:  typ == notification.NotifyAppDeployFailed,
+			Message: message,
+		})
+		if err != nil {
+			e.logger.Warn("[Notify] 收集部署汇总失败，逐个发送", zap.Int64("batch_id", r.BatchID), zap.Error(err))
+		}
+		if collected {
+			return nil
+		}
+		return e.notifier.SendAppDeployNotification(ctx, r.BatchID, app.ID, app.Name, typ, message)
+	})
+}
+
+// releaseNotify 发布应用状态 → 通知类型与说明（部署成功/失败）
+func releaseNotify(r model.ReleaseApp, from, to int8) (notification.NotificationType, string, bool) {
+	event, stage, ok := webhook.ReleaseEvent(to)
+	if !ok || from == to {
+		return "", "", false
+	}
+	typ := notification.NotifyAppDeploySuccess
+	if event == constants.WebhookEventDeployFailed {
+		typ = notification.NotifyAppDeployFailed
+	}
+	tag := ""
+	if r.TargetTag != nil {
+		tag = *r.TargetTag
+	}
+	message := fmt.Sprintf("[%s] %s", stage, tag)
+	if typ == notification.NotifyAppDeployFailed && r.Reason != "" {
+		message += "\n" + r.Reason
+	}
+	return typ, message, true
+}
+
+// enqueueNotify 通知异步按顺序发送，队列满时丢弃，避免外部通知阻塞状态机
+func (e *CoreEngine) enqueueNotify(task func(ctx context.Context) error) {
+	select {
+	case e.notifyQueue <- task:
+	default:
+		e.logger.Warn("[Notify] 通知队列已满，丢弃通知")
+	}
+}
+
+// runNotifier 通知发送 worker
+func (e *CoreEngine) runNotifier() {
+	defer close(e.notifyDone)
+	send := func(task func(ctx context.Context) error) {
+		if err := task(context.TODO()); err != nil {
+			e.logger.Warn(fmt.Sprintf("[Notify] 发送通知失败: %v", err))
+		}
+	}
+	for {
+		select {
+		case task := <-e.notifyQueue:
+			send(task)
+		case <-e.notifyStop:
+			// 停止时发送已入队的通知（含排空阶段产生的状态变更通知）与未发送的部署汇总
+			for {
+				select {
+				case task := <-e.notifyQueue:
+					send(task)
+				default:
+					e.digest.FlushAll(context.TODO())
+					return
+				}
+			}
+		}
+	}
+}
+
+// digestMaxWaitFromConfig core.notification.digest_max_wait，未配置或格式错误时使用默认值
+func digestMaxWaitFromConfig(coreCfg *config.CoreConfig, logger *zap.Logger) time.Duration {
+	if coreCfg == nil || coreCfg.Notification.DigestMaxWait == "" {
+		return notification.DefaultDigestMaxWait
+	}
+	d, err := time.ParseDuration(coreCfg.Notification.DigestMaxWait)
+	if err != nil || d <= 0 {
+		logger.Warn("digest_max_wait 解析失败，使用默认值", zap.String("value", coreCfg.Notification.DigestMaxWait), zap.Error(err))
+		return notification.DefaultDigestMaxWait
+	}
+	return d
+}
@@ -0,0 +1,225 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"devops-cd/internal/core/release_app"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/config"
+	"devops-cd/pkg/constants"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+)
+
+// 启动恢复
+//
+// 引擎启动（或副本成为主节点）后、第一次扫描前执行一次，处理重启前中断、之后不会再被推进的记录：
+//
+// - 单副本部署时，重启前领取、仍为 running 的任务放回队列（多副本时由租约过期后重新领取）
+// - running 的 Deployment 所属批次已不再处理（已结束/已取消/超出处理窗口）时重新检查一次 Helm 状态，仍为 running 则置为失败
+// - 停留在已触发状态超过 stale_after 的发布应用，所属批次已不再处理时重新检查一次，仍未结束则置为失败
+//
+// 批次仍在进行中的记录由随后的扫描正常推进；暂停中的批次与已完成批次的 config Deployment 不处理
+
+const defaultReconcileStaleAfter = 30 * time.Minute
+
+// reconcileTriggered 需要恢复检查的发布应用状态
+var reconcileTriggered = []int8{
+	constants.ReleaseAppStatusPreTriggered,
+	constants.ReleaseAppStatusProdTriggered,
+	constants.ReleaseAppStatusRollbackTriggered,
+}
+
+// reconcileStaleAfterFromConfig core.reconcile，未启用时返回 0
+func reconcileStaleAfterFromConfig(coreCfg *config.CoreConfig, logger *zap.Logger) time.Duration {
+	if coreCfg == nil || !coreCfg.Reconcile.Enabled {
+		return 0
+	}
+	if coreCfg.Reconcile.StaleAfter == "" {
+		return defaultReconcileStaleAfter
+	}
+	d, err := time.ParseDuration(coreCfg.Reconcile.StaleAfter)
+	if err != nil || d <= 0 {
+		logger.Warn("reconcile.stale_after 解析失败，使用默认值", zap.String("value", coreCfg.Reconcile.StaleAfter), zap.Error(err))
+		return defaultReconcileStaleAfter
+	}
+	return d
+}
+
+// reconcile 执行一次启动恢复
+func (e *CoreEngine) reconcile(ctx context.Context) {
+	if e.reconcileStaleAfter <= 0 {
+		return
+	}
+	start := time.Now()
+	e.logger.Info("[Reconcile] 开始启动恢复检查")
+
+	var jobs int64
+	if e.jobs != nil && e.elector == nil {
+		n, err := e.jobs.ReleaseOrphans(ctx)
+		if err != nil {
+			e.logger.Error("[Reconcile] 释放中断的任务失败", zap.Error(err))
+		}
+		jobs = n
+	}
+	depResumed, depFailed := e.reconcileDeployments(ctx)
+	relResumed, relFailed := e.reconcileReleases(ctx)
+
+	e.logger.Info("[Reconcile] 启动恢复检查完成",
+		zap.Int64("jobs_released", jobs),
+		zap.Int("deployments_resumed", depResumed), zap.Int("deployments_failed", depFailed),
+		zap.Int("releases_resumed", relResumed), zap.Int("releases_failed", relFailed),
+		zap.Duration("elapsed", time.Since(start)))
+}
+
+// reconcileDeployments 所属批次已不再处理的 running Deployment：重新检查 Helm 状态，仍为 running 时置为失败
+func (e *CoreEngine) reconcileDeployments(ctx context.Context) (resumed, failed int) {
+	var deps []model.Deployment
+	if err := e.db.WithContext(ctx).Where("status = ?", constants.DeploymentStatusRunning).Find(&deps).Error; err != nil {
+		e.logger.Error(fmt.Sprintf("[Reconcile] 查询 running Deployment 失败: %v", err))
+		return 0, 0
+	}
+	if len(deps) == 0 {
+		return 0, 0
+	}
+	batches, paused, err := e.reconcileBatches(ctx, lo.Map(deps, func(d model.Deployment, _ int) int64 { return d.BatchID }))
+	if err != nil {
+		e.logger.Error(fmt.Sprintf("[Reconcile] 查询批次失败: %v", err))
+		return 0, 0
+	}
+	pausedClusters, err := e.pausedClusterNames(ctx)
+	if err != nil {
+		return 0, 0
+	}
+
+	for i := range deps {
+		dep := &deps[i]
+		// 临时部署的 Deployment 由 scanAdhocDeployments 推进
+		if dep.AdhocID != nil {
+			continue
+		}
+		b := batches[dep.BatchID]
+		if (b != nil && batchActive(b)) || paused[dep.BatchID] || pausedClusters[dep.ClusterName] {
+			continue
+		}
+		// 已完成批次的 config Deployment 由 scanConfigDeployments 推进
+		if b != nil && b.Status == constants.BatchStatusCompleted && dep.Kind == constants.DeploymentKindConfig {
+			continue
+		}
+		log := e.logger.With(zap.Int64("batch_id", dep.BatchID), zap.Int64("deployment_id", dep.ID))
+
+		// 失败时 Process 返回 ErrDeploymentFailed，以重新加载的状态为准
+		_ = e.deploymentSM.Process(ctx, dep)
+		var cur model.Deployment
+		if err := e.db.WithContext(ctx).First(&cur, dep.ID).Error; err != nil {
+			log.Error("[Reconcile] 重新加载 Deployment 失败", zap.Error(err))
+			continue
+		}
+		if cur.Status != constants.DeploymentStatusRunning {
+			log.Info(fmt.Sprintf("[Reconcile] Deployment:%d 重新检查后状态为 %s", cur.ID, cur.Status))
+			resumed++
+			continue
+		}
+		reason := fmt.Sprintf("服务重启恢复：所属批次已不再处理（%s），无法继续跟踪部署状态", reconcileBatchState(b))
+		ok, err := e.deploymentSM.Fail(ctx, &cur, reason)
+		if err != nil {
+			log.Error("[Reconcile] 更新 Deployment 为失败状态失败", zap.Error(err))
+			continue
+		}
+		if ok {
+			log.Warn(fmt.Sprintf("[Reconcile] Deployment:%d %s，已置为失败", cur.ID, reason))
+			failed++
+		}
+	}
+	return resumed, failed
+}
+
+// reconcileReleases 停留在已触发状态超过 stale_after、所属批次已不再处理的发布应用：重新检查一次，仍未结束时置为失败
+func (e *CoreEngine) reconcileReleases(ctx context.Context) (resumed, failed int) {
+	var releases []model.ReleaseApp
+	if err := e.db.WithContext(ctx).
+		Where("status IN ? AND updated_at < ?", reconcileTriggered, time.Now().Add(-e.reconcileStaleAfter)).
+		Find(&releases).Error; err != nil {
+		e.logger.Error(fmt.Sprintf("[Reconcile] 查询已触发的发布应用失败: %v", err))
+		return 0, 0
+	}
+	if len(releases) == 0 {
+		return 0, 0
+	}
+	batches, paused, err := e.reconcileBatches(ctx, lo.Map(releases, func(r model.ReleaseApp, _ int) int64 { return r.BatchID }))
+	if err != nil {
+		e.logger.Error(fmt.Sprintf("[Reconcile] 查询批次失败: %v", err))
+		return 0, 0
+	}
+
+	for i := range releases {
+		rel := &releases[i]
+		b := batches[rel.BatchID]
+		if (b != nil && batchActive(b)) || paused[rel.BatchID] {
+			continue
+		}
+		log := e.logger.With(zap.Int64("batch_id", rel.BatchID), zap.Int64("release_id", rel.ID))
+
+		e.releaseSM.Process(ctx, rel)
+		var cur model.ReleaseApp
+		if err := e.db.WithContext(ctx).Select("id", "status").First(&cur, rel.ID).Error; err != nil {
+			log.Error("[Reconcile] 重新加载发布应用失败", zap.Error(err))
+			continue
+		}
+		if !lo.Contains(reconcileTriggered, cur.Status) {
+			log.Info(fmt.Sprintf("[Reconcile] ReleaseApp:%d 重新检查后状态为 %v", cur.ID, cur.Status))
+			resumed++
+			continue
+		}
+
+		reason := fmt.Sprintf("服务重启恢复：所属批次已不再处理（%s），部署未完成", reconcileBatchState(b))
+		// 检查期间状态已被推进时不更新
+		err := e.releaseSM.UpdateStatus(ctx, rel.ID,
+			release_app.WithToFunc(func(r model.ReleaseApp) int8 {
+				if !lo.Contains(reconcileTriggered, r.Status) {
+					return r.Status
+				}
+				return model.ToFailed(r.Status)
+			}),
+			release_app.WithModelEffects(func(r *model.ReleaseApp) {
+				if lo.Contains(reconcileTriggered, r.Status) {
+					r.AppendReasonf("%s", reason)
+				}
+			}))
+		if err != nil {
+			log.Error("[Reconcile] 更新发布应用为失败状态失败", zap.Error(err))
+			continue
+		}
+		log.Warn(fmt.Sprintf("[Reconcile] ReleaseApp:%d %s，已置为失败", rel.ID, reason))
+		failed++
+	}
+	return resumed, failed
+}
+
+// reconcileBatches 查询记录所属批次及其中暂停的批次
+func (e *CoreEngine) reconcileBatches(ctx context.Context, batchIDs []int64) (map[int64]*model.Batch, map[int64]bool, error) {
+	batchIDs = lo.Uniq(batchIDs)
+	var list []model.Batch
+	if err := e.db.WithContext(ctx).Select("id", "status", "created_at").Where("id IN ?", batchIDs).Find(&list).Error; err != nil {
+		return nil, nil, err
+	}
+	batches := make(map[int64]*model.Batch, len(list))
+	for i := range list {
+		batches[list[i].ID] = &list[i]
+	}
+	return batches, e.pausedBatchIDs(ctx, batchIDs), nil
+}
+
+// reconcileBatchState 批次不再处理的原因
+func reconcileBatchState(b *model.Batch) string {
+	if b == nil {
+		return "批次不存在"
+	}
+	if b.CreatedAt.Before(time.Now().Add(-batchActiveWindow)) {
+		return "批次已超出处理窗口"
+	}
+	return "批次状态: " + constants.BatchStatusToString(b.Status)
+}
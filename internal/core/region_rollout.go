@@ -0,0 +1,137 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"devops-cd/internal/core/common/metacache"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+
+	"go.uber.org/zap"
+)
+
+// gateRegionRollout 多 region 协调：应用开启 region_rollout 时，过滤掉尚未轮到的 Pending Deployment
+//
+// 同一环境内按 region 顺序推进，前一 region 未全部成功、有失败或未到间隔时间时，后续 region 的 Pending 保持等待，
+// 等待原因写入 deployment.error_message 便于查看（开始部署时会被清空）
+func (e *CoreEngine) gateRegionRollout(ctx context.Context, releaseID int64, deps []*model.Deployment) []*model.Deployment {
+	app, err := metacache.Application(ctx, e.db, deps[0].AppID)
+	if err != nil {
+		e.logger.Error("查询应用 region_rollout 失败", zap.Int64("app_id", deps[0].AppID), zap.Error(err))
+		return deps
+	}
+	if !app.RegionRollout.IsEnabled() {
+		return deps
+	}
+
+	type currentDeployment struct {
+		ID         int64
+		Env        string
+		Cluster    string
+		Status     string
+		FinishedAt *time.Time
+		Region     *string
+
+		RollbackBuildID *int64
+	}
+	var rows []currentDeployment
+	if err := e.db.WithContext(ctx).Table(model.DeploymentTableName+" AS d").
+		Select("d.id, d.env, d.cluster, d.status, d.finished_at, d.rollback_build_id, c.region").
+		Joins("LEFT JOIN "+model.ClusterTableName+" AS c ON c.name = d.cluster").
+		Where("d.release_id = ? AND d.superseded_by IS NULL", releaseID).
+		Scan(&rows).Error; err != nil {
+		e.logger.Error("查询 region 部署状态失败", zap.Int64("release_id", releaseID), zap.Error(err))
+		return nil
+	}
+
+	type regionState struct {
+		total, success, failed int
+		lastFinished           time.Time
+	}
+	states := make(map[string]map[string]*regionState) // env -> region -> state
+	depRegion := make(map[int64]string, len(rows))
+	for _, row := range rows {
+		region := ""
+		if row.Region != nil {
+			region = *row.Region
+		}
+		depRegion[row.ID] = region
+		if states[row.Env] == nil {
+			states[row.Env] = make(map[string]*regionState)
+		}
+		st := states[row.Env][region]
+		if st == nil {
+			st = &regionState{}
+			states[row.Env][region] = st
+		}
+		st.total++
+		switch {
+		case constants.IsDeploymentFailed(row.Status) || row.RollbackBuildID != nil:
+			// 已自动回滚的集群视同失败，暂停后续 region
+			st.failed++
+		case row.Status == constants.DeploymentStatusSuccess:
+			st.success++
+			if row.FinishedAt != nil && row.FinishedAt.After(st.lastFinished) {
+				st.lastFinished = *row.FinishedAt
+			}
+		}
+	}
+
+	// env+region -> 等待原因（空表示可以部署）
+	stagger := time.Duration(app.RegionRollout.StaggerSeconds) * time.Second
+	gates := make(map[string]string)
+	for env, regions := range states {
+		names := make([]string, 0, len(regions))
+		for region := range regions {
+			names = append(names, region)
+		}
+
+		gate := ""
+		for _, region := range app.RegionRollout.SortRegions(names) {
+			gates[env+"/"+region] = gate
+			if gate != "" {
+				continue
+			}
+			st := regions[region]
+			switch {
+			case st.failed > 0:
+				gate = fmt.Sprintf("region %s 有 %d 个集群部署失败，已暂停后续 region", regionLabel(region), st.failed)
+			case st.success < st.total:
+				gate = fmt.Sprintf("等待 region %s 部署完成", regionLabel(region))
+			case stagger > 0 && time.Since(st.lastFinished) < stagger:
+				gate = fmt.Sprintf("region %s 已完成，%s 后开始下一 region", regionLabel(region), st.lastFinished.Add(stagger).Format("15:04:05"))
+			}
+		}
+	}
+
+	allowed := make([]*model.Deployment, 0, len(deps))
+	for _, dep := range deps {
+		gate := gates[dep.Env+"/"+depRegion[dep.ID]]
+		if dep.Status != constants.DeploymentStatusPending || gate == "" {
+			allowed = append(allowed, dep)
+			continue
+		}
+		e.holdDeployment(ctx, dep, gate)
+	}
+	return allowed
+}
+
+// holdDeployment Pending Deployment 本轮不执行，等待原因写入 error_message（开始部署时会被清空）
+func (e *CoreEngine) holdDeployment(ctx context.Context, dep *model.Deployment, reason string) {
+	if dep.ErrorMessage == nil || *dep.ErrorMessage != reason {
+		if err := e.db.WithContext(ctx).Model(&model.Deployment{}).Where("id = ? AND status = ?", dep.ID, constants.DeploymentStatusPending).
+			Update("error_message", reason).Error; err != nil {
+			e.logger.Warn("更新 Deployment 等待原因失败", zap.Int64("deployment_id", dep.ID), zap.Error(err))
+		}
+	}
+	e.logger.Debug(fmt.Sprintf("[Deployment] ReleaseApp:%d %s/%s %s", dep.ReleaseID, dep.Env, dep.ClusterName, reason))
+}
+
+func regionLabel(region string) string {
+	if region == "" {
+		return "(未设置)"
+	}
+	return region
+}
@@ -0,0 +1,77 @@
+package release_app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"devops-cd/internal/core/common/metacache"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+
+	"gorm.io/gorm"
+)
+
+// configChartEnabled 项目在该环境是否启用 config chart
+func (sm *ReleaseStateMachine) configChartEnabled(ctx context.Context, projectID int64, env string) (bool, error) {
+	projectCfg, err := metacache.ProjectEnvConfig(ctx, sm.db, projectID, env)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("查询项目环境配置失败: %w", err)
+	}
+	arts, err := model.LoadArtifactsV1(projectCfg.ArtifactsJSON)
+	if err != nil {
+		return false, err
+	}
+	return arts.ConfigChart != nil && arts.ConfigChart.Enabled, nil
+}
+
+// createDeployment 创建 release 在 env/cluster 上的 Deployment，并替代同 release/env/cluster/kind 下的旧记录
+//
+// v2+：允许同一 release/env/cluster 多次创建 deployment（例如 v1 -> v2）
+// 用 superseded_by 表示旧记录已被新记录替代，查询 current 时只取 superseded_by IS NULL
+func createDeployment(tx *gorm.DB, release *model.ReleaseApp, app *model.Application, env, cluster, kind string, stage int) (*model.Deployment, error) {
+	dep := &model.Deployment{
+		BatchID:   release.BatchID,
+		AppID:     release.AppID,
+		ReleaseID: release.ID,
+
+		Kind:        kind,
+		Env:         env,
+		ClusterName: cluster,
+
+		// namespace/deployment_name 由 deployment 层在 Pending 阶段根据 artifacts_json 统一计算并回填
+		// todo: 是否删除这几个字段
+		Namespace:      "default",
+		DeploymentName: app.Name,
+
+		Status:       constants.DeploymentStatusPending,
+		RetryCount:   0,
+		RolloutStage: stage,
+	}
+	if err := tx.Create(dep).Error; err != nil {
+		return nil, err
+	}
+
+	// 简化策略：将同一 release/env/cluster/kind 下所有 current 记录（若有脏数据可能 >1 条）标记为被替代
+	if err := tx.Model(&model.Deployment{}).
+		Where("release_id = ? AND env = ? AND cluster = ? AND kind = ?", release.ID, env, cluster, kind).
+		Where("superseded_by IS NULL AND id <> ?", dep.ID).
+		Update("superseded_by", dep.ID).Error; err != nil {
+		return nil, err
+	}
+	return dep, nil
+}
+
+// createClusterDeployments 为单个集群创建 app（及启用时的 config）Deployment，stage 为灰度阶段
+func createClusterDeployments(tx *gorm.DB, release *model.ReleaseApp, app *model.Application, env, cluster string, stage int, withConfig bool) error {
+	if withConfig {
+		if _, err := createDeployment(tx, release, app, env, cluster, constants.DeploymentKindConfig, stage); err != nil {
+			return err
+		}
+	}
+	_, err := createDeployment(tx, release, app, env, cluster, constants.DeploymentKindApp, stage)
+	return err
+}
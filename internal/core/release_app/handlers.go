@@ -92,6 +92,15 @@ func (sm *ReleaseStateMachine) registerHandlers() {
 	sm.handlers[constants.ReleaseAppStatusProdDeployed] = HandlerFunc(sm.HandleProdDeployed)
 	sm.handlers[constants.ReleaseAppStatusProdFailed] = HandlerFunc(sm.HandleProdFailed)
 	sm.handlers[constants.ReleaseAppStatusProdAccepted] = HandlerFunc(sm.HandleProdAccepted)
+
+	// PreOnly
+	sm.handlers[constants.ReleaseAppStatusPreOnlyCompleted] = HandlerFunc(sm.HandlePreOnlyCompleted)
+
+	// Rollback
+	sm.handlers[constants.ReleaseAppStatusRollbackCanTrigger] = HandlerFunc(sm.HandleRollbackCanTrigger)
+	sm.handlers[constants.ReleaseAppStatusRollbackTriggered] = HandlerFunc(sm.HandleRollbackTriggered)
+	sm.handlers[constants.ReleaseAppStatusRolledBack] = HandlerFunc(sm.HandleRolledBack)
+	sm.handlers[constants.ReleaseAppStatusRollbackFailed] = HandlerFunc(sm.HandleRollbackFailed)
 }
 
 // handlers
@@ -146,6 +155,9 @@ func (sm *ReleaseStateMachine) HandlePreCanTrigger(ctx context.Context, release
 	if build.BuildStatus != constants.BuildStatusSuccess {
 		return 0, nil, fmt.Errorf("build status: %v", build.BuildStatus)
 	}
+	if status, updateFunc, ok := sm.verifyImage(ctx, release, &build); !ok {
+		return status, updateFunc, nil
+	}
 
 	// 2. 加载 App
 	var app model.Application
@@ -159,44 +171,22 @@ func (sm *ReleaseStateMachine) HandlePreCanTrigger(ctx context.Context, release
 		Find(&configs).Error; err != nil {
 		return 0, nil, fmt.Errorf("查询 Pre 环境配置失败: %w", err)
 	}
+	// manifest lock 生效时只部署清单声明的集群
+	configs = release.EnvClusters(configs, constants.EnvTypePre)
 	if len(configs) == 0 {
 		return 0, nil, fmt.Errorf("应用未配置 Pre 环境")
 	}
 
-	// 4. 为每个集群创建 Deployment
+	withConfig, err := sm.configChartEnabled(ctx, app.ProjectID, constants.EnvTypePre)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// 4. 为每个集群创建 Deployment（启用 config chart 时额外创建 kind=config 的 Deployment）
 	var failed []string
 	for _, config := range configs {
-		// v2+：允许同一 release/env/cluster 多次创建 deployment（例如 v1 -> v2）
-		// 用 superseded_by 表示旧记录已被新记录替代，查询 current 时只取 superseded_by IS NULL
 		err := sm.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-			// 1) 创建新 deployment
-			dep := model.Deployment{
-				BatchID:   release.BatchID,
-				AppID:     release.AppID,
-				ReleaseID: release.ID,
-
-				Env:         constants.EnvTypePre,
-				ClusterName: config.Cluster,
-
-				// namespace/deployment_name 由 deployment 层在 Pending 阶段根据 artifacts_json 统一计算并回填
-				// todo: 是否删除这几个字段
-				Namespace:      "default",
-				DeploymentName: app.Name,
-
-				Status:     constants.DeploymentStatusPending,
-				RetryCount: 0,
-			}
-			if err := tx.Create(&dep).Error; err != nil {
-				return err
-			}
-
-			// 2) 简化策略：将同一 release/env/cluster 下所有 current 记录（若有脏数据可能 >1 条）
-			if err := tx.Model(&model.Deployment{}).Where("release_id = ? AND env = ? AND cluster = ?", release.ID, constants.EnvTypePre, config.Cluster).
-				Where("superseded_by IS NULL AND id <> ?", dep.ID).Update("superseded_by", dep.ID).Error; err != nil {
-				return err
-			}
-
-			return nil
+			return createClusterDeployments(tx, release, &app, constants.EnvTypePre, config.Cluster, 1, withConfig)
 		})
 		if err != nil {
 			failed = append(failed, config.Cluster)
@@ -228,14 +218,20 @@ func (sm *ReleaseStateMachine) HandlePreTriggered(ctx context.Context, release *
 		return 0, nil, fmt.Errorf("no deployments found")
 	}
 
-	// 2. 统计状态
+	// 2. 统计状态（只统计当前生效记录：按集群重新部署/重试后，旧记录已被替代或已置回 pending）
 	var successCount, failedCount, pendingCount int
+	var failedClusters, verifyFailedClusters []string
 	for _, dep := range deployments {
 		switch dep.Status {
 		case constants.DeploymentStatusSuccess:
 			successCount++
 		case constants.DeploymentStatusFailed:
 			failedCount++
+			failedClusters = appendCluster(failedClusters, dep.ClusterName)
+		case constants.DeploymentStatusVerifyFailed:
+			failedCount++
+			failedClusters = appendCluster(failedClusters, dep.ClusterName)
+			verifyFailedClusters = appendCluster(verifyFailedClusters, dep.ClusterName)
 		default:
 			pendingCount++
 		}
@@ -248,7 +244,7 @@ func (sm *ReleaseStateMachine) HandlePreTriggered(ctx context.Context, release *
 	if failedCount > 0 {
 		// 有失败 → ReleaseApp 失败
 		return constants.ReleaseAppStatusPreFailed, func(r *model.ReleaseApp) {
-			r.Reason = fmt.Sprintf("预发布失败: %d 个 Deployment 失败", failedCount)
+			r.Reason = fmt.Sprintf("预发布失败: %d 个 Deployment 失败（集群 %s）%s，可按集群重新部署", failedCount, strings.Join(failedClusters, ","), verifyFailedNote(verifyFailedClusters))
 		}, nil
 	}
 	if successCount == total {
@@ -308,7 +304,23 @@ func (sm *ReleaseStateMachine) HandleProdWaiting(ctx context.Context, release *m
 }
 
 // HandleProdCanTrigger handle ProdCanTrigger:21 -> ProdTriggered:22, gen deployments record
+// 部署策略为 canary/staged 时按阶段创建 Deployment，首阶段之外的 Deployment 等待 promote
+// 项目配置了生产阶段漏洞门禁时，镜像未通过则保持等待（回滚部署的是发布前版本，不经过门禁）
 func (sm *ReleaseStateMachine) HandleProdCanTrigger(ctx context.Context, release *model.ReleaseApp) (int8, func(*model.ReleaseApp), error) {
+	reason, err := sm.checkVulnGate(ctx, release)
+	if err != nil {
+		return 0, nil, err
+	}
+	if reason != "" {
+		return 0, func(r *model.ReleaseApp) {
+			r.Reason = reason
+		}, nil
+	}
+	return sm.triggerProdDeployments(ctx, release, true)
+}
+
+// triggerProdDeployments 为 Prod 集群创建 Deployment，staged=false 时所有集群同一阶段（如回滚）
+func (sm *ReleaseStateMachine) triggerProdDeployments(ctx context.Context, release *model.ReleaseApp, staged bool) (int8, func(*model.ReleaseApp), error) {
 	log := sm.logger.With(zap.Int64("release_id", release.ID)).Sugar()
 
 	// 1. 校验 Build
@@ -322,6 +334,9 @@ func (sm *ReleaseStateMachine) HandleProdCanTrigger(ctx context.Context, release
 	if build.BuildStatus != constants.BuildStatusSuccess {
 		return 0, nil, fmt.Errorf("build status: %v", build.BuildStatus)
 	}
+	if status, updateFunc, ok := sm.verifyImage(ctx, release, &build); !ok {
+		return status, updateFunc, nil
+	}
 
 	// 2. 加载 App
 	var app model.Application
@@ -335,55 +350,49 @@ func (sm *ReleaseStateMachine) HandleProdCanTrigger(ctx context.Context, release
 		Find(&configs).Error; err != nil {
 		return 0, nil, fmt.Errorf("查询 Prod 环境配置失败: %w", err)
 	}
+	// manifest lock 生效时只部署清单声明的集群
+	configs = release.EnvClusters(configs, constants.EnvTypeProd)
 	if len(configs) == 0 {
 		return 0, nil, fmt.Errorf("应用未配置生产环境")
 	}
 
-	// 4. 为每个集群创建 Deployment（namespace/deployment_name 由 deployment 层在 Pending 阶段计算）
+	withConfig, err := sm.configChartEnabled(ctx, app.ProjectID, constants.EnvTypeProd)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// 4. 按部署策略计算各集群阶段
+	stages, totalStages := map[string]int{}, 1
+	if staged {
+		if stages, totalStages, err = model.PlanRolloutStages(configs); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	// 5. 为每个集群创建 Deployment（namespace/deployment_name 由 deployment 层在 Pending 阶段计算，启用 config chart 时额外创建 kind=config 的 Deployment）
 	var failed []string
 	for _, config := range configs {
+		stage := max(stages[config.Cluster], 1)
 		if err := sm.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-			dep := model.Deployment{
-				BatchID:   release.BatchID,
-				AppID:     release.AppID,
-				ReleaseID: release.ID,
-
-				Env:         constants.EnvTypeProd,
-				ClusterName: config.Cluster,
-
-				// namespace/deployment_name 由 deployment 层在 Pending 阶段根据 artifacts_json 统一计算并回填
-				// todo: 是否删除这几个字段
-				Namespace:      "default",
-				DeploymentName: app.Name,
-
-				Status:     constants.DeploymentStatusPending,
-				RetryCount: 0,
-			}
-			if err := tx.Create(&dep).Error; err != nil {
-				return err
-			}
-			if err := tx.Model(&model.Deployment{}).
-				Where("release_id = ? AND env = ? AND cluster = ? AND superseded_by IS NULL AND id <> ?",
-					release.ID, constants.EnvTypeProd, config.Cluster, dep.ID).
-				Update("superseded_by", dep.ID).Error; err != nil {
-				return err
-			}
-			return nil
+			return createClusterDeployments(tx, release, &app, constants.EnvTypeProd, config.Cluster, stage, withConfig)
 		}); err != nil {
 			failed = append(failed, config.Cluster)
 			log.With(zap.String("cluster", config.Cluster)).Errorf("创建/替代 Deployment 失败, %v", err)
 		}
 	}
 
-	// 5. 记录失败信息
+	// 6. 记录失败信息
 	if len(failed) > 0 {
 		return 0, func(r *model.ReleaseApp) {
 			r.Reason = fmt.Sprintf("生产部署触发失败: %v", failed)
 		}, fmt.Errorf("部分集群创建失败")
 	}
 
-	log.Info(fmt.Sprintf("ProdDeploy 触发成功,创建了 %d 个集群的 Deployment", len(configs)), zap.String("image", build.ImageTag))
-	return constants.ReleaseAppStatusProdTriggered, nil, nil
+	log.Info(fmt.Sprintf("ProdDeploy 触发成功,创建了 %d 个集群的 Deployment, 共 %d 个阶段", len(configs), totalStages), zap.String("image", build.ImageTag))
+	return constants.ReleaseAppStatusProdTriggered, func(r *model.ReleaseApp) {
+		r.RolloutStage = 1
+		r.RolloutStages = totalStages
+	}, nil
 }
 
 // HandleProdTriggered handle ProdTriggered:22 -> ProdDeployed:23, check deployments record
@@ -400,14 +409,20 @@ func (sm *ReleaseStateMachine) HandleProdTriggered(ctx context.Context, release
 		return 0, nil, fmt.Errorf("no deployments found")
 	}
 
-	// 2. 统计状态
+	// 2. 统计状态（只统计当前生效记录：按集群重新部署/重试后，旧记录已被替代或已置回 pending）
 	var successCount, failedCount, pendingCount int
+	var failedClusters, verifyFailedClusters []string
 	for _, dep := range deployments {
 		switch dep.Status {
 		case constants.DeploymentStatusSuccess:
 			successCount++
 		case constants.DeploymentStatusFailed:
 			failedCount++
+			failedClusters = appendCluster(failedClusters, dep.ClusterName)
+		case constants.DeploymentStatusVerifyFailed:
+			failedCount++
+			failedClusters = appendCluster(failedClusters, dep.ClusterName)
+			verifyFailedClusters = appendCluster(verifyFailedClusters, dep.ClusterName)
 		default:
 			pendingCount++
 		}
@@ -419,14 +434,30 @@ func (sm *ReleaseStateMachine) HandleProdTriggered(ctx context.Context, release
 	// 3. 判断下一步
 	if failedCount > 0 {
 		return constants.ReleaseAppStatusProdFailed, func(r *model.ReleaseApp) {
-			r.Reason = fmt.Sprintf("生产部署失败: %d 个 Deployment 失败", failedCount)
+			r.Reason = fmt.Sprintf("生产部署失败: %d 个 Deployment 失败（集群 %s）%s，可按集群重新部署", failedCount, strings.Join(failedClusters, ","), verifyFailedNote(verifyFailedClusters))
 		}, nil
 	}
 	if successCount == total {
+		// 有集群已自动回滚到发布前版本：发布未完成，进入 ProdFailed（不更新应用 deployed_tag）
+		if rolledBack := rolledBackClusters(deployments); len(rolledBack) > 0 {
+			return constants.ReleaseAppStatusProdFailed, func(r *model.ReleaseApp) {
+				r.Reason = fmt.Sprintf("生产部署失败: 集群 %s 已自动回滚到发布前版本 %s，可按集群重新部署或回滚应用", strings.Join(rolledBack, ","), derefTag(release.PreviousDeployedTag))
+			}, nil
+		}
 		return constants.ReleaseAppStatusProdDeployed, nil, nil
 	}
 
-	// 4. 还有进行中的 → 继续等待
+	// 4. 当前灰度阶段已全部完成 → 暂停等待 promote
+	if waiting := rolloutWaitingReason(release, deployments); waiting != "" {
+		if release.Reason == waiting {
+			return 0, nil, nil
+		}
+		return 0, func(r *model.ReleaseApp) {
+			r.Reason = waiting
+		}, nil
+	}
+
+	// 5. 还有进行中的 → 继续等待
 	log.Debugf("[ReleaseApp SM] Batch:%v ReleaseApp:%v 生产部署进行中，等待所有 Deployment 完成", release.BatchID, release.ID)
 	return 0, nil, nil
 }
@@ -441,6 +472,11 @@ func (sm *ReleaseStateMachine) HandleProdAccepted(ctx context.Context, release *
 	return 0, nil, nil
 }
 
+// HandlePreOnlyCompleted handle PreOnlyCompleted:40, pre_only 应用的终态
+func (sm *ReleaseStateMachine) HandlePreOnlyCompleted(ctx context.Context, release *model.ReleaseApp) (int8, func(*model.ReleaseApp), error) {
+	return 0, nil, nil
+}
+
 func (sm *ReleaseStateMachine) HandleEmpty(ctx context.Context, release *model.ReleaseApp) (int8, func(*model.ReleaseApp), error) {
 	// todo
 	return 0, nil, nil
@@ -472,19 +508,24 @@ type depAgg struct {
 	success int
 	failed  int
 	pending int
+
+	rolledBack int // 自动回滚的 Deployment
 }
 
 func (sm *ReleaseStateMachine) aggregateDeployments(ctx context.Context, releaseID int64, env string) (*depAgg, error) {
 	var deployments []model.Deployment
-	if err := sm.db.WithContext(ctx).Select("status").Where("release_id = ? AND env = ? AND superseded_by IS NULL", releaseID, env).Find(&deployments).Error; err != nil {
+	if err := sm.db.WithContext(ctx).Select("status", "rollback_build_id").Where("release_id = ? AND env = ? AND superseded_by IS NULL", releaseID, env).Find(&deployments).Error; err != nil {
 		return nil, err
 	}
 	agg := &depAgg{total: len(deployments)}
 	for _, dep := range deployments {
+		if dep.RollbackBuildID != nil {
+			agg.rolledBack++
+		}
 		switch dep.Status {
 		case constants.DeploymentStatusSuccess:
 			agg.success++
-		case constants.DeploymentStatusFailed:
+		case constants.DeploymentStatusFailed, constants.DeploymentStatusVerifyFailed:
 			agg.failed++
 		default:
 			agg.pending++
@@ -560,6 +601,10 @@ func (sm *ReleaseStateMachine) HandleProdFailed(ctx context.Context, release *mo
 	}
 
 	if agg.total > 0 {
+		// 自动回滚的集群需人工按集群重新部署或回滚应用，不自动回升
+		if agg.rolledBack > 0 {
+			return 0, nil, nil
+		}
 		if agg.failed == 0 && agg.pending == 0 && agg.success == agg.total {
 			return constants.ReleaseAppStatusProdTriggered, func(r *model.ReleaseApp) { r.Reason = "" }, nil
 		}
@@ -588,3 +633,21 @@ func (sm *ReleaseStateMachine) HandleProdFailed(ctx context.Context, release *mo
 		}
 	}, nil
 }
+
+// rolledBackClusters 已自动回滚的集群
+func rolledBackClusters(deployments []model.Deployment) []string {
+	var clusters []string
+	for _, dep := range deployments {
+		if dep.RollbackBuildID != nil {
+			clusters = appendCluster(clusters, dep.ClusterName)
+		}
+	}
+	return clusters
+}
+
+func derefTag(tag *string) string {
+	if tag == nil || *tag == "" {
+		return "-"
+	}
+	return *tag
+}
@@ -1,17 +1,15 @@
 package helpers
 
 import (
-	"bytes"
+	"context"
+	"devops-cd/internal/core/common/artifactcache"
 	"devops-cd/internal/core/common/valueslayer"
 	"devops-cd/internal/model"
 	"devops-cd/internal/pkg/crypto"
+	"devops-cd/internal/pkg/secrets"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
-	"os"
-	"os/exec"
-	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -134,7 +132,7 @@ func loadValuesLayerContent(db *gorm.DB, ctx map[string]interface{}, layer model
 		finalURL := strings.TrimRight(base, "/") + "/" + strings.TrimLeft(p, "/")
 		return httpGet(finalURL, cred)
 	case "file":
-		return valueslayer.LoadFileLayer(layer.BaseURLTemplate, layer.PathTemplate, func(t string) (string, error) {
+		return valueslayer.LoadFileLayer(context.Background(), layer.BaseURLTemplate, layer.PathTemplate, func(t string) (string, error) {
 			return parseTemplate(t, ctx)
 		}, func(req *http.Request) {
 			applyHTTPAuth(req, cred)
@@ -163,92 +161,23 @@ func loadValuesLayerContent(db *gorm.DB, ctx map[string]interface{}, layer model
 			return nil, err
 		}
 
-		repo, env, cleanupKey, err := prepareGitAuth(repo, cred)
-		if err != nil {
-			return nil, err
-		}
-		defer cleanupKey()
-
-		dir, cleanup, err := gitCheckoutToTemp(repo, ref, env)
-		if err != nil {
-			return nil, err
-		}
-		defer cleanup()
-
-		abs := filepath.Join(dir, filepath.Clean(relPath))
-		// 防止路径穿越：要求最终路径仍在 dir 下
-		if !strings.HasPrefix(abs, dir+string(os.PathSeparator)) && abs != dir {
-			return nil, fmt.Errorf("path_template 非法: %s", relPath)
-		}
-		return os.ReadFile(abs)
+		return valueslayer.LoadGitLayer(context.Background(), valueslayer.GitLayer{
+			RepoURL:    repo,
+			Ref:        ref,
+			Path:       relPath,
+			Credential: cred,
+		})
 	default:
 		return nil, fmt.Errorf("不支持的 values type: %s", layer.Type)
 	}
 }
 
+// httpGet 经共享制品缓存拉取 http_file 层（TTL 内命中，过期后条件请求校验）
 func httpGet(url string, cred map[string]string) ([]byte, error) {
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
-	}
-	applyHTTPAuth(req, cred)
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		b, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
-	}
-	return io.ReadAll(resp.Body)
-}
-
-// gitCheckoutToTemp 以最小依赖方式调用系统 git 拉取指定 ref 到临时目录
-func gitCheckoutToTemp(repoURL, ref string, extraEnv []string) (dir string, cleanup func(), err error) {
-	base, err := os.MkdirTemp("", "devops-cd-values-*")
-	if err != nil {
-		return "", nil, err
-	}
-	cleanup = func() { _ = os.RemoveAll(base) }
-
-	// git init && git remote add origin && git fetch && git checkout
-	run := func(args ...string) error {
-		cmd := exec.Command("git", args...)
-		cmd.Dir = base
-		// 避免 git 交互
-		cmd.Env = append(os.Environ(),
-			"GIT_TERMINAL_PROMPT=0",
-		)
-		if len(extraEnv) > 0 {
-			cmd.Env = append(cmd.Env, extraEnv...)
-		}
-		var out bytes.Buffer
-		cmd.Stdout = &out
-		cmd.Stderr = &out
-		if err := cmd.Run(); err != nil {
-			return fmt.Errorf("git %s failed: %w; output=%s", strings.Join(args, " "), err, strings.TrimSpace(out.String()))
-		}
-		return nil
-	}
-
-	if err := run("init"); err != nil {
-		return "", cleanup, err
-	}
-	if err := run("remote", "add", "origin", repoURL); err != nil {
-		return "", cleanup, err
-	}
-	// depth=1 拉取 ref（branch/tag/commit 都尝试）
-	if err := run("fetch", "--depth", "1", "origin", ref); err != nil {
-		// fallback: ref 可能是分支名，尝试 refs/heads/
-		if err2 := run("fetch", "--depth", "1", "origin", "refs/heads/"+ref); err2 != nil {
-			return "", cleanup, err
-		}
-	}
-	if err := run("checkout", "--detach", "FETCH_HEAD"); err != nil {
-		return "", cleanup, err
-	}
-	return base, cleanup, nil
+	return artifactcache.Fetch(context.Background(), artifactcache.Request{
+		URL:  url,
+		Auth: func(req *http.Request) { applyHTTPAuth(req, cred) },
+	})
 }
 
 // normalizeYAMLToStringMap 将 YAML 解析出来的 map[interface{}]interface{} 递归转换成 map[string]interface{}
@@ -310,12 +239,15 @@ func marshalMeta(m map[string]interface{}) (map[string]interface{}, error) {
 }
 
 // resolveCredentialData 根据 credential_ref 取出明文（map），不回传给 API，仅内部使用
-// 当前支持 credential_ref=纯数字（id）或 "id:123"
+// 支持 credential_ref=纯数字（id）、"id:123"，以及外部密钥 "vault:<path>" / "aws_sm:<secret-id>"
 func resolveCredentialData(db *gorm.DB, ref string) (map[string]string, error) {
 	ref = strings.TrimSpace(ref)
 	if ref == "" {
 		return nil, nil
 	}
+	if secrets.IsExternalRef(ref) {
+		return secrets.Resolve(context.Background(), ref)
+	}
 	if db == nil {
 		return nil, fmt.Errorf("db is nil, cannot resolve credential_ref")
 	}
@@ -339,7 +271,7 @@ func resolveCredentialData(db *gorm.DB, ref string) (map[string]string, error) {
 	if err := db.First(&c, id).Error; err != nil {
 		return nil, fmt.Errorf("credential_ref=%s 查询失败: %w", ref, err)
 	}
-	plain, err := crypto.Decrypt(c.EncryptedData)
+	plain, err := crypto.DecryptWithKeyID(c.EncryptedData, c.KeyID)
 	if err != nil {
 		return nil, fmt.Errorf("credential_ref=%s 解密失败: %w", ref, err)
 	}
@@ -368,61 +300,3 @@ func applyHTTPAuth(req *http.Request, cred map[string]string) {
 		}
 	}
 }
-
-// prepareGitAuth 基于凭据生成 git clone 所需的 repoURL/env（v1：basic_auth/token/ssh_key）
-func prepareGitAuth(repoURL string, cred map[string]string) (finalURL string, env []string, cleanup func(), err error) {
-	finalURL = repoURL
-	cleanup = func() {}
-	if cred == nil {
-		return
-	}
-
-	switch cred["_type"] {
-	case "token":
-		// v1: 仅对 https URL 进行 userinfo 注入（避免交互）
-		tok := strings.TrimSpace(cred["token"])
-		if tok != "" && strings.HasPrefix(repoURL, "https://") {
-			finalURL = injectUserInfo(repoURL, tok, "")
-		}
-	case "basic_auth":
-		u := cred["username"]
-		p := cred["password"]
-		if (u != "" || p != "") && strings.HasPrefix(repoURL, "https://") {
-			finalURL = injectUserInfo(repoURL, u, p)
-		}
-	case "ssh_key":
-		key := strings.TrimSpace(cred["private_key"])
-		if key == "" {
-			return finalURL, env, cleanup, fmt.Errorf("ssh_key.private_key 为空")
-		}
-		dir, err := os.MkdirTemp("", "devops-cd-ssh-*")
-		if err != nil {
-			return finalURL, env, cleanup, err
-		}
-		cleanup = func() { _ = os.RemoveAll(dir) }
-		keyPath := filepath.Join(dir, "id_rsa")
-		if err := os.WriteFile(keyPath, []byte(key), 0600); err != nil {
-			return finalURL, env, cleanup, err
-		}
-		// 简化：跳过 known_hosts 校验（后续可扩展）
-		env = append(env, fmt.Sprintf("GIT_SSH_COMMAND=ssh -i %s -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null", keyPath))
-	}
-	return
-}
-
-func injectUserInfo(rawURL, user, pass string) string {
-	// 仅处理 https://host/.. 形式：https:// + user[:pass]@ + rest
-	s := strings.TrimPrefix(rawURL, "https://")
-	if pass == "" {
-		return "https://" + urlEscapeUser(user) + "@" + s
-	}
-	return "https://" + urlEscapeUser(user) + ":" + urlEscapeUser(pass) + "@" + s
-}
-
-func urlEscapeUser(s string) string {
-	// 最小实现：避免引入 net/url，先替换最危险的字符
-	s = strings.ReplaceAll(s, "@", "%40")
-	s = strings.ReplaceAll(s, ":", "%3A")
-	s = strings.ReplaceAll(s, "/", "%2F")
-	return s
-}
@@ -0,0 +1,43 @@
+package release_app
+
+import (
+	"context"
+	"devops-cd/internal/model"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// ImageVerifier 创建 Deployment 前校验构建镜像是否存在于镜像仓库
+type ImageVerifier interface {
+	// VerifyImage 返回校验的镜像地址及是否存在；无法确定镜像地址时返回 exists=true 跳过校验
+	VerifyImage(ctx context.Context, build *model.Build) (image string, exists bool, err error)
+}
+
+// SetImageVerifier 启用部署前镜像校验，需在引擎启动前调用
+func (sm *ReleaseStateMachine) SetImageVerifier(v ImageVerifier) {
+	sm.imageVerifier = v
+}
+
+// verifyImage 校验构建镜像：不存在时直接失败（不等 Helm/K8s 拉取报错），镜像仓库不可用时保持当前状态下次重试
+//
+// ok=false 时调用方直接返回 status / updateFunc
+func (sm *ReleaseStateMachine) verifyImage(ctx context.Context, release *model.ReleaseApp, build *model.Build) (status int8, updateFunc func(*model.ReleaseApp), ok bool) {
+	if sm.imageVerifier == nil {
+		return 0, nil, true
+	}
+	image, exists, err := sm.imageVerifier.VerifyImage(ctx, build)
+	if err != nil {
+		sm.logger.Warn("镜像校验失败，稍后重试", zap.Int64("release_id", release.ID), zap.String("image", image), zap.Error(err))
+		return 0, func(r *model.ReleaseApp) {
+			r.Reason = fmt.Sprintf("镜像校验失败，稍后重试: %v", err)
+		}, false
+	}
+	if !exists {
+		sm.logger.Warn("镜像不存在", zap.Int64("release_id", release.ID), zap.String("image", image))
+		return model.ToFailed(release.Status), func(r *model.ReleaseApp) {
+			r.Reason = fmt.Sprintf("镜像不存在: %s（构建 %d）", image, build.ID)
+		}, false
+	}
+	return 0, nil, true
+}
@@ -13,8 +13,8 @@ type Event struct {
 }
 
 var events = map[string]Event{
-	"manual_trigger_pre":  {To: constants.ReleaseAppStatusPreCanTrigger},
-	"manual_trigger_prod": {To: constants.ReleaseAppStatusProdCanTrigger},
+	constants.ReleaseAppActionManualTriggerPre:  {To: constants.ReleaseAppStatusPreCanTrigger},
+	constants.ReleaseAppActionManualTriggerProd: {To: constants.ReleaseAppStatusProdCanTrigger},
 }
 
 // ManualDeploy 手动触发部署
@@ -0,0 +1,153 @@
+package release_app
+
+import (
+	"context"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// RedeployClusters 按集群重新部署：为选中集群创建新的 Deployment 替代当前记录，发布应用回到 Triggered 等待重新完成
+//
+// 仅发布应用处于该环境 Deployed/Failed、批次处于该环境部署中时允许；选中集群的当前 Deployment 必须已结束（success/failed）
+func (sm *ReleaseStateMachine) RedeployClusters(releaseAppID int64, env string, clusters []string, operator, reason string) error {
+	var to int8
+	switch env {
+	case constants.EnvTypePre:
+		to = constants.ReleaseAppStatusPreTriggered
+	case constants.EnvTypeProd:
+		to = constants.ReleaseAppStatusProdTriggered
+	default:
+		return fmt.Errorf("无效的环境: %s", env)
+	}
+	return sm.UpdateStatus(context.TODO(), releaseAppID,
+		WithStatus(&to),
+		WithSource(TransitionSourceOutside),
+		WithOperatorAndReason(operator, reason),
+		WithData("env", env),
+		WithData("clusters", clusters),
+	)
+}
+
+// RedeployClustersTransition 按集群重新部署
+type RedeployClustersTransition struct {
+	sm *ReleaseStateMachine
+}
+
+func (h RedeployClustersTransition) Handle(release *model.ReleaseApp, from int8, options *transitionOptions) error {
+	env, _ := options.data["env"].(string)
+	clusters, _ := options.data["clusters"].([]string)
+	names := normalizeClusters(clusters)
+	if len(names) == 0 {
+		return fmt.Errorf("未指定重新部署的集群")
+	}
+
+	var batch model.Batch
+	if err := h.sm.db.Select("id", "status").First(&batch, release.BatchID).Error; err != nil {
+		return fmt.Errorf("查询批次失败: %w", err)
+	}
+	deploying := constants.BatchStatusPreDeploying
+	if env == constants.EnvTypeProd {
+		deploying = constants.BatchStatusProdDeploying
+	}
+	if batch.Status != deploying {
+		return fmt.Errorf("当前批次状态 %s 不允许按集群重新部署", constants.BatchStatusToString(batch.Status))
+	}
+
+	var current []model.Deployment
+	if err := h.sm.db.Where("release_id = ? AND env = ? AND superseded_by IS NULL AND cluster IN ?", release.ID, env, names).
+		Find(&current).Error; err != nil {
+		return fmt.Errorf("查询Deployment失败: %w", err)
+	}
+
+	// 按集群汇总当前记录：app 的灰度阶段、是否带 config chart
+	type clusterDeps struct {
+		app        *model.Deployment
+		withConfig bool
+	}
+	byCluster := make(map[string]*clusterDeps, len(names))
+	for i := range current {
+		dep := &current[i]
+		if dep.Status != constants.DeploymentStatusSuccess && !constants.IsDeploymentFailed(dep.Status) {
+			return fmt.Errorf("集群 %s 的 Deployment(%d) 仍在进行中（%s），不能重新部署", dep.ClusterName, dep.ID, dep.Status)
+		}
+		cd := byCluster[dep.ClusterName]
+		if cd == nil {
+			cd = &clusterDeps{}
+			byCluster[dep.ClusterName] = cd
+		}
+		switch dep.Kind {
+		case constants.DeploymentKindApp:
+			cd.app = dep
+		case constants.DeploymentKindConfig:
+			cd.withConfig = true
+		}
+	}
+	for _, name := range names {
+		if cd := byCluster[name]; cd == nil || cd.app == nil {
+			return fmt.Errorf("集群 %s 下不存在该应用的 %s Deployment", name, env)
+		}
+	}
+
+	var app model.Application
+	if err := h.sm.db.First(&app, release.AppID).Error; err != nil {
+		return fmt.Errorf("查询应用失败: %w", err)
+	}
+
+	if err := h.sm.db.Transaction(func(tx *gorm.DB) error {
+		for _, name := range names {
+			cd := byCluster[name]
+			if err := createClusterDeployments(tx, release, &app, env, name, cd.app.RolloutStage, cd.withConfig); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("创建Deployment失败: %w", err)
+	}
+
+	release.Reason = ""
+	release.AppendReasonf("%s 重新部署 %s 集群 %s %s", options.operator, env, strings.Join(names, ","), options.operationExplain)
+	return nil
+}
+
+func (h RedeployClustersTransition) After(release *model.ReleaseApp, from int8, options *transitionOptions) {
+}
+
+// normalizeClusters 去空白、去重，保持原有顺序
+func normalizeClusters(clusters []string) []string {
+	names := make([]string, 0, len(clusters))
+	seen := make(map[string]struct{}, len(clusters))
+	for _, name := range clusters {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	return names
+}
+
+// appendCluster 追加集群名（同一集群的 app/config 记录只保留一次）
+func appendCluster(clusters []string, name string) []string {
+	if slices.Contains(clusters, name) {
+		return clusters
+	}
+	return append(clusters, name)
+}
+
+// verifyFailedNote 失败原因中标注部署后验证未通过的集群
+func verifyFailedNote(clusters []string) string {
+	if len(clusters) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("，其中部署后验证未通过: %s", strings.Join(clusters, ","))
+}
@@ -145,6 +145,10 @@ func (r *Resolver) CheckRelease(ctx context.Context, release *model.ReleaseApp,
 			// 依赖不在本批次，视为已满足
 			continue
 		}
+		if rel.SkipProdEnv && stage == constants.EnvTypeProd {
+			// 跳过生产的应用（pre_only 或未配置生产环境）不发布生产，不参与生产依赖
+			continue
+		}
 
 		releaseID := rel.ID
 		status.ReleaseID = &releaseID
@@ -193,7 +197,8 @@ func (r *Resolver) collectDependencies(ctx context.Context, release *model.Relea
 		entry.addSource(source)
 	}
 
-	defaultIDs := app.DefaultDependsOn
+	// manifest lock 生效时以清单中的依赖为准
+	defaultIDs := release.DependsOn(app)
 
 	for _, id := range defaultIDs {
 		add(id, "default")
@@ -347,6 +352,8 @@ func releaseStatusText(status int8) string {
 		return "ProdDeployed"
 	case constants.ReleaseAppStatusProdFailed:
 		return "ProdFailed"
+	case constants.ReleaseAppStatusPreOnlyCompleted:
+		return "PreOnlyCompleted"
 	default:
 		return fmt.Sprintf("Unknown(%d)", status)
 	}
@@ -0,0 +1,268 @@
+package release_app
+
+import (
+	"context"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+	"devops-cd/pkg/utils"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RollbackAllowedBatchStatus 批次处于这些状态时允许回滚应用（生产部署开始后）
+var RollbackAllowedBatchStatus = map[int8]bool{
+	constants.BatchStatusProdDeploying:  true,
+	constants.BatchStatusProdDeployed:   true,
+	constants.BatchStatusProdFailed:     true,
+	constants.BatchStatusProdAccepted:   true,
+	constants.BatchStatusCompleted:      true,
+	constants.BatchStatusRollingBack:    true,
+	constants.BatchStatusRolledBack:     true,
+	constants.BatchStatusRollbackFailed: true,
+}
+
+// Rollback 回滚应用到 previous_deployed_tag，复用 Prod Deployment 流程重新部署
+func (sm *ReleaseStateMachine) Rollback(releaseAppID int64, operator, reason string) error {
+	return sm.UpdateStatus(context.TODO(), releaseAppID,
+		WithStatus(utils.CopyInt8(constants.ReleaseAppStatusRollbackCanTrigger)),
+		WithSource(TransitionSourceOutside),
+		WithOperatorAndReason(operator, reason),
+	)
+}
+
+// AutoRollbackCluster 生产 Deployment 就绪/验证失败后，只为该集群创建部署发布前版本的 Deployment（替代失败记录）
+//
+// 发布应用保持 ProdTriggered，回滚 Deployment 完成后进入 ProdFailed（不更新应用 deployed_tag），返回回滚到的版本
+func (sm *ReleaseStateMachine) AutoRollbackCluster(ctx context.Context, failed *model.Deployment) (string, error) {
+	var toTag string
+	err := sm.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var release model.ReleaseApp
+		if err := tx.First(&release, failed.ReleaseID).Error; err != nil {
+			return fmt.Errorf("查询发布应用失败: %w", err)
+		}
+		if release.Status != constants.ReleaseAppStatusProdTriggered {
+			return fmt.Errorf("发布应用状态 %d 不允许自动回滚", release.Status)
+		}
+		if release.PreviousDeployedTag == nil || *release.PreviousDeployedTag == "" {
+			return fmt.Errorf("应用无发布前版本（首次发布），无法自动回滚")
+		}
+		toTag = *release.PreviousDeployedTag
+		if release.TargetTag != nil && *release.TargetTag == toTag {
+			return fmt.Errorf("目标版本与发布前版本 %s 一致，无需回滚", toTag)
+		}
+
+		var build model.Build
+		if err := tx.Scopes(model.TrustedBuilds).
+			Where("app_id = ? AND image_tag = ? AND build_status = ?", release.AppID, toTag, constants.BuildStatusSuccess).
+			Order("id DESC").First(&build).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("未找到发布前版本 %s 的构建记录", toTag)
+			}
+			return fmt.Errorf("查询Build记录失败: %w", err)
+		}
+
+		var app model.Application
+		if err := tx.First(&app, release.AppID).Error; err != nil {
+			return fmt.Errorf("查询应用失败: %w", err)
+		}
+
+		// 失败记录必须仍是当前生效记录（未被重新部署/重试替代）
+		var current model.Deployment
+		if err := tx.Select("id", "status", "superseded_by").First(&current, failed.ID).Error; err != nil {
+			return err
+		}
+		if current.SupersededBy != nil || !constants.IsDeploymentFailed(current.Status) {
+			return fmt.Errorf("deployment=%d 已被替代或重试", failed.ID)
+		}
+
+		dep, err := createDeployment(tx, &release, &app, failed.Env, failed.ClusterName, constants.DeploymentKindApp, failed.RolloutStage)
+		if err != nil {
+			return fmt.Errorf("创建回滚 Deployment 失败: %w", err)
+		}
+		if err := tx.Model(dep).Update("rollback_build_id", build.ID).Error; err != nil {
+			return err
+		}
+		dep.RollbackBuildID = &build.ID
+		msg := fmt.Sprintf("自动回滚: Deployment(%d) 失败，回滚到发布前版本 %s", failed.ID, toTag)
+		if err := tx.Create(model.NewDeploymentEvent(dep, constants.DeploymentEventAutoRollback, msg)).Error; err != nil {
+			return err
+		}
+
+		release.AppendReasonf("集群 %s 就绪/验证失败，自动回滚到发布前版本 %s", failed.ClusterName, toTag)
+		return tx.Model(&model.ReleaseApp{}).Where("id = ?", release.ID).Update("reason", release.Reason).Error
+	})
+	return toTag, err
+}
+
+// ================== handlers ==================
+
+// HandleRollbackCanTrigger handle RollbackCanTrigger:51 -> RollbackTriggered:52, 按 Prod 集群配置创建回滚版本的 Deployment
+func (sm *ReleaseStateMachine) HandleRollbackCanTrigger(ctx context.Context, release *model.ReleaseApp) (int8, func(*model.ReleaseApp), error) {
+	// 回滚不分阶段，所有集群同时部署
+	next, updateFunc, err := sm.triggerProdDeployments(ctx, release, false)
+	if err != nil || next != constants.ReleaseAppStatusProdTriggered {
+		// 未触发（等待重试）或回滚版本镜像不存在（RollbackFailed）
+		return next, updateFunc, err
+	}
+	return constants.ReleaseAppStatusRollbackTriggered, updateFunc, nil
+}
+
+// HandleRollbackTriggered handle RollbackTriggered:52 -> RolledBack:53 / RollbackFailed:54
+func (sm *ReleaseStateMachine) HandleRollbackTriggered(ctx context.Context, release *model.ReleaseApp) (int8, func(*model.ReleaseApp), error) {
+	agg, err := sm.aggregateDeployments(ctx, release.ID, constants.EnvTypeProd)
+	if err != nil {
+		return 0, nil, fmt.Errorf("聚合Deployment失败: %w", err)
+	}
+	if agg.total == 0 {
+		return 0, nil, fmt.Errorf("no deployments found")
+	}
+	if agg.failed > 0 {
+		return constants.ReleaseAppStatusRollbackFailed, func(r *model.ReleaseApp) {
+			r.AppendReasonf("回滚失败: %d 个 Deployment 失败", agg.failed)
+		}, nil
+	}
+	if agg.success == agg.total {
+		return constants.ReleaseAppStatusRolledBack, nil, nil
+	}
+
+	sm.logger.Sugar().Debugf("[ReleaseApp SM] Batch:%v ReleaseApp:%v 回滚进行中 %d/%d", release.BatchID, release.ID, agg.success, agg.total)
+	return 0, nil, nil
+}
+
+// HandleRolledBack 回滚完成（终态）
+func (sm *ReleaseStateMachine) HandleRolledBack(ctx context.Context, release *model.ReleaseApp) (int8, func(*model.ReleaseApp), error) {
+	return 0, nil, nil
+}
+
+// HandleRollbackFailed 回滚失败，等待人工重试（再次调用回滚接口）
+func (sm *ReleaseStateMachine) HandleRollbackFailed(ctx context.Context, release *model.ReleaseApp) (int8, func(*model.ReleaseApp), error) {
+	return 0, nil, nil
+}
+
+// ================== transitions ==================
+
+// TriggerRollback 触发回滚：目标版本切换为 previous_deployed_tag 对应的构建
+type TriggerRollback struct {
+	sm *ReleaseStateMachine
+}
+
+func (h TriggerRollback) Handle(release *model.ReleaseApp, from int8, options *transitionOptions) error {
+	var batch model.Batch
+	if err := h.sm.db.First(&batch, release.BatchID).Error; err != nil {
+		return err
+	}
+	if !RollbackAllowedBatchStatus[batch.Status] {
+		return fmt.Errorf("当前批次状态 %s 不允许回滚", constants.BatchStatusToString(batch.Status))
+	}
+	if release.SkipProdEnv {
+		return fmt.Errorf("跳过生产环境的应用未部署生产环境，无需回滚")
+	}
+	if release.PreviousDeployedTag == nil || *release.PreviousDeployedTag == "" {
+		return fmt.Errorf("应用无发布前版本（首次发布），无法回滚")
+	}
+	prevTag := *release.PreviousDeployedTag
+	if from != constants.ReleaseAppStatusRollbackFailed && release.TargetTag != nil && *release.TargetTag == prevTag {
+		return fmt.Errorf("目标版本与发布前版本 %s 一致，无需回滚", prevTag)
+	}
+
+	var build model.Build
+	if err := h.sm.db.Scopes(model.TrustedBuilds).
+		Where("app_id = ? AND image_tag = ? AND build_status = ?", release.AppID, prevTag, constants.BuildStatusSuccess).
+		Order("id DESC").First(&build).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("未找到发布前版本 %s 的构建记录，无法回滚", prevTag)
+		}
+		return fmt.Errorf("查询Build记录失败: %w", err)
+	}
+
+	if options.data == nil {
+		options.data = make(map[string]interface{})
+	}
+	options.data["project_id"] = batch.ProjectID
+	options.data["from_tag"] = release.TargetTag
+
+	release.BuildID = &build.ID
+	release.TargetTag = &build.ImageTag
+	release.AppendReasonf("回滚到发布前版本 %s (by %s)", prevTag, options.operator)
+	return nil
+}
+
+// After 写入回滚记录（重试时沿用上一次失败记录的回滚前版本）
+func (h TriggerRollback) After(release *model.ReleaseApp, from int8, options *transitionOptions) {
+	fromTag, _ := options.data["from_tag"].(*string)
+	if from == constants.ReleaseAppStatusRollbackFailed {
+		var last model.BatchRollback
+		if err := h.sm.db.Where("release_id = ?", release.ID).Order("id DESC").Limit(1).Find(&last).Error; err == nil && last.ID != 0 {
+			fromTag = last.FromTag
+		}
+	}
+	projectID, _ := options.data["project_id"].(int64)
+
+	record := &model.BatchRollback{
+		BatchID:      release.BatchID,
+		ProjectID:    projectID,
+		AppID:        release.AppID,
+		ReleaseID:    &release.ID,
+		Env:          constants.EnvTypeProd,
+		FromTag:      fromTag,
+		ToTag:        release.TargetTag,
+		RolledBackAt: time.Now(),
+		CreatedBy:    options.operator,
+		Status:       constants.BatchRollbackStatusRunning,
+	}
+	if options.operationExplain != "" {
+		record.Reason = &options.operationExplain
+	}
+	if err := h.sm.db.Create(record).Error; err != nil {
+		h.sm.logger.Sugar().Errorf("[ReleaseApp SM: %d-%d] 保存回滚记录失败: %v", release.BatchID, release.ID, err)
+	}
+}
+
+// OnRollbackCompleted 回滚完成：更新应用已部署版本
+type OnRollbackCompleted struct {
+	sm *ReleaseStateMachine
+}
+
+func (h OnRollbackCompleted) Handle(release *model.ReleaseApp, from int8, options *transitionOptions) error {
+	if release.TargetTag == nil {
+		return fmt.Errorf("目标版本为空, 无法更新应用部署版本")
+	}
+	if err := h.sm.db.Model(&model.Application{}).
+		Where("id = ?", release.AppID).Update("deployed_tag", release.TargetTag).Error; err != nil {
+		return fmt.Errorf("更新应用部署版本失败: %w", err)
+	}
+	return nil
+}
+
+func (h OnRollbackCompleted) After(release *model.ReleaseApp, from int8, options *transitionOptions) {
+	h.sm.finishRollbackRecord(release, constants.BatchRollbackStatusSuccess, nil)
+}
+
+// OnRollbackFailed 回滚失败：记录失败原因
+type OnRollbackFailed struct {
+	sm *ReleaseStateMachine
+}
+
+func (h OnRollbackFailed) Handle(release *model.ReleaseApp, from int8, options *transitionOptions) error {
+	return nil
+}
+
+func (h OnRollbackFailed) After(release *model.ReleaseApp, from int8, options *transitionOptions) {
+	var reason *string
+	if lines := release.GetRecentReason(1); len(lines) > 0 && lines[0] != "" {
+		reason = &lines[0]
+	}
+	h.sm.finishRollbackRecord(release, constants.BatchRollbackStatusFailed, reason)
+}
+
+// finishRollbackRecord 结束进行中的回滚记录
+func (sm *ReleaseStateMachine) finishRollbackRecord(release *model.ReleaseApp, status string, errorMessage *string) {
+	now := time.Now()
+	if err := sm.db.Model(&model.BatchRollback{}).
+		Where("release_id = ? AND status = ?", release.ID, constants.BatchRollbackStatusRunning).
+		Updates(map[string]interface{}{"status": status, "finished_at": &now, "error_message": errorMessage}).Error; err != nil {
+		sm.logger.Sugar().Errorf("[ReleaseApp SM: %d-%d] 更新回滚记录失败: %v", release.BatchID, release.ID, err)
+	}
+}
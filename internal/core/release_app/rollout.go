@@ -0,0 +1,133 @@
+package release_app
+
+import (
+	"context"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+	"devops-cd/pkg/utils"
+	"fmt"
+	"time"
+)
+
+// PromoteRollout 灰度进入下一阶段：当前阶段的 Deployment 全部成功后才允许
+func (sm *ReleaseStateMachine) PromoteRollout(releaseAppID int64, operator, reason string) error {
+	return sm.UpdateStatus(context.TODO(), releaseAppID,
+		WithStatus(utils.CopyInt8(constants.ReleaseAppStatusProdTriggered)),
+		WithSource(TransitionSourceOutside),
+		WithOperatorAndReason(operator, reason),
+	)
+}
+
+// AbortRollout 中止灰度：后续阶段未执行的 Deployment 标记失败，应用进入生产部署失败（可回滚或切换版本重新发布）
+func (sm *ReleaseStateMachine) AbortRollout(releaseAppID int64, operator, reason string) error {
+	return sm.UpdateStatus(context.TODO(), releaseAppID,
+		WithStatus(utils.CopyInt8(constants.ReleaseAppStatusProdFailed)),
+		WithSource(TransitionSourceOutside),
+		WithOperatorAndReason(operator, reason),
+	)
+}
+
+// rolloutWaitingReason 当前阶段 Deployment 已全部成功、仍有后续阶段时返回等待 promote 的说明
+func rolloutWaitingReason(release *model.ReleaseApp, deployments []model.Deployment) string {
+	if release.RolloutStages <= 1 || release.RolloutStage >= release.RolloutStages {
+		return ""
+	}
+	for _, dep := range deployments {
+		if dep.RolloutStage <= release.RolloutStage && dep.Status != constants.DeploymentStatusSuccess {
+			return ""
+		}
+	}
+	return fmt.Sprintf("灰度阶段 %d/%d 已完成，等待 promote", release.RolloutStage, release.RolloutStages)
+}
+
+// checkRolloutPending 发布应用处于生产部署中且仍有未放行的灰度阶段
+func checkRolloutPending(release *model.ReleaseApp, from int8) error {
+	if from != constants.ReleaseAppStatusProdTriggered {
+		return fmt.Errorf("当前状态 %v 不在生产部署中", from)
+	}
+	if release.RolloutStages <= 1 {
+		return fmt.Errorf("应用未配置灰度/分阶段部署策略")
+	}
+	if release.RolloutStage >= release.RolloutStages {
+		return fmt.Errorf("已处于最后一个阶段（%d/%d）", release.RolloutStage, release.RolloutStages)
+	}
+	return nil
+}
+
+// ================== transitions ==================
+
+// PromoteRolloutTransition 放行下一灰度阶段
+type PromoteRolloutTransition struct {
+	sm *ReleaseStateMachine
+}
+
+func (h PromoteRolloutTransition) Handle(release *model.ReleaseApp, from int8, options *transitionOptions) error {
+	if err := checkRolloutPending(release, from); err != nil {
+		return err
+	}
+
+	var unfinished int64
+	if err := h.sm.db.Model(&model.Deployment{}).
+		Where("release_id = ? AND env = ? AND superseded_by IS NULL", release.ID, constants.EnvTypeProd).
+		Where("rollout_stage <= ? AND status <> ?", release.RolloutStage, constants.DeploymentStatusSuccess).
+		Count(&unfinished).Error; err != nil {
+		return fmt.Errorf("查询灰度阶段部署状态失败: %w", err)
+	}
+	if unfinished > 0 {
+		return fmt.Errorf("灰度阶段 %d 还有 %d 个 Deployment 未成功，不能 promote", release.RolloutStage, unfinished)
+	}
+
+	release.RolloutStage++
+	release.Reason = ""
+	release.AppendReasonf("%s promote 到灰度阶段 %d/%d %s", options.operator, release.RolloutStage, release.RolloutStages, options.operationExplain)
+	return nil
+}
+
+func (h PromoteRolloutTransition) After(release *model.ReleaseApp, from int8, options *transitionOptions) {
+}
+
+// AbortRolloutTransition 中止灰度
+type AbortRolloutTransition struct {
+	sm *ReleaseStateMachine
+}
+
+func (h AbortRolloutTransition) Handle(release *model.ReleaseApp, from int8, options *transitionOptions) error {
+	if err := checkRolloutPending(release, from); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	msg := fmt.Sprintf("灰度已中止（%s）", options.operator)
+	var pending []model.Deployment
+	if err := h.sm.db.Where("release_id = ? AND env = ? AND superseded_by IS NULL", release.ID, constants.EnvTypeProd).
+		Where("rollout_stage > ? AND status = ?", release.RolloutStage, constants.DeploymentStatusPending).
+		Find(&pending).Error; err != nil {
+		return fmt.Errorf("查询后续阶段 Deployment 失败: %w", err)
+	}
+	if len(pending) > 0 {
+		ids := make([]int64, 0, len(pending))
+		events := make([]*model.DeploymentEvent, 0, len(pending))
+		for i := range pending {
+			ids = append(ids, pending[i].ID)
+			events = append(events, model.NewDeploymentStatusEvent(&pending[i], constants.DeploymentStatusPending, constants.DeploymentStatusFailed, options.operator, msg))
+		}
+		if err := h.sm.db.Model(&model.Deployment{}).
+			Where("id IN ? AND status = ?", ids, constants.DeploymentStatusPending).
+			Updates(map[string]interface{}{
+				"status":        constants.DeploymentStatusFailed,
+				"error_message": msg,
+				"finished_at":   now,
+			}).Error; err != nil {
+			return fmt.Errorf("中止后续阶段 Deployment 失败: %w", err)
+		}
+		if err := h.sm.db.Create(&events).Error; err != nil {
+			return fmt.Errorf("写入 Deployment 事件失败: %w", err)
+		}
+	}
+
+	release.AppendReasonf("%s 中止灰度（已完成阶段 %d/%d） %s", options.operator, release.RolloutStage, release.RolloutStages, options.operationExplain)
+	return nil
+}
+
+func (h AbortRolloutTransition) After(release *model.ReleaseApp, from int8, options *transitionOptions) {
+}
@@ -17,6 +17,20 @@ type ReleaseStateMachine struct {
 	resolver *Resolver
 
 	transitions map[int8]map[int8]StateTransition
+
+	// 状态变更监听（事务提交后调用，如出站 Webhook）
+	listeners []StatusListener
+
+	// 部署前镜像校验（未配置时不校验）
+	imageVerifier ImageVerifier
+}
+
+// StatusListener 发布应用状态变更监听
+type StatusListener func(release model.ReleaseApp, from, to int8)
+
+// OnStatusChange 注册状态变更监听，需在引擎启动前调用
+func (sm *ReleaseStateMachine) OnStatusChange(l StatusListener) {
+	sm.listeners = append(sm.listeners, l)
 }
 
 func NewReleaseStateMachine(db *gorm.DB, logger *zap.Logger, resolver *Resolver) *ReleaseStateMachine {
@@ -164,6 +178,7 @@ func (sm *ReleaseStateMachine) UpdateStatus(ctx context.Context, releaseAppID in
 	var old int8
 	var to int8
 	var afterHandler func()
+	var changed *model.ReleaseApp
 
 	err := sm.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		// 1. 重新加载最新状态
@@ -224,6 +239,9 @@ func (sm *ReleaseStateMachine) UpdateStatus(ctx context.Context, releaseAppID in
 				log.Warn("no update or record not found")
 			}
 			log.Infof("[ReleaseApp SM: %v-%v] 状态变更成功: %v -> %v", rel.BatchID, rel.ID, old, to)
+			if option.toFunc != nil && result.RowsAffected > 0 {
+				changed = &rel
+			}
 		}
 		return nil
 	})
@@ -231,5 +249,10 @@ func (sm *ReleaseStateMachine) UpdateStatus(ctx context.Context, releaseAppID in
 	if err == nil && afterHandler != nil {
 		afterHandler()
 	}
+	if err == nil && changed != nil {
+		for _, l := range sm.listeners {
+			l(*changed, old, to)
+		}
+	}
 	return err
 }
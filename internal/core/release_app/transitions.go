@@ -66,6 +66,58 @@ func (sm *ReleaseStateMachine) registerTransitions() {
 			Handler:     ManualTriggerProdDeploy{sm: sm},
 			AllowSource: TransitionSourceOutside,
 		},
+		// 回滚: 生产已触发/完成后回滚到 previous_deployed_tag（回滚失败可重试）
+		{
+			From: []int8{
+				constants.ReleaseAppStatusProdTriggered, constants.ReleaseAppStatusProdDeployed,
+				constants.ReleaseAppStatusProdFailed, constants.ReleaseAppStatusProdAccepted,
+				constants.ReleaseAppStatusRollbackFailed,
+			},
+			To:          constants.ReleaseAppStatusRollbackCanTrigger,
+			Handler:     TriggerRollback{sm: sm},
+			AllowSource: TransitionSourceOutside,
+		},
+		// 回滚完成
+		{
+			From:        []int8{constants.ReleaseAppStatusRollbackTriggered},
+			To:          constants.ReleaseAppStatusRolledBack,
+			Handler:     OnRollbackCompleted{sm: sm},
+			AllowSource: TransitionSourceInside,
+		},
+		// 回滚失败
+		{
+			From:        []int8{constants.ReleaseAppStatusRollbackCanTrigger, constants.ReleaseAppStatusRollbackTriggered},
+			To:          constants.ReleaseAppStatusRollbackFailed,
+			Handler:     OnRollbackFailed{sm: sm},
+			AllowSource: TransitionSourceInside,
+		},
+		// 灰度: 放行下一阶段
+		{
+			From:        []int8{constants.ReleaseAppStatusProdTriggered},
+			To:          constants.ReleaseAppStatusProdTriggered,
+			Handler:     PromoteRolloutTransition{sm: sm},
+			AllowSource: TransitionSourceOutside,
+		},
+		// 灰度: 中止后续阶段
+		{
+			From:        []int8{constants.ReleaseAppStatusProdTriggered},
+			To:          constants.ReleaseAppStatusProdFailed,
+			Handler:     AbortRolloutTransition{sm: sm},
+			AllowSource: TransitionSourceOutside,
+		},
+		// 按集群重新部署: 部分集群失败或需要重新执行时，只为选中集群创建新的 Deployment
+		{
+			From:        []int8{constants.ReleaseAppStatusPreDeployed, constants.ReleaseAppStatusPreFailed},
+			To:          constants.ReleaseAppStatusPreTriggered,
+			Handler:     RedeployClustersTransition{sm: sm},
+			AllowSource: TransitionSourceOutside,
+		},
+		{
+			From:        []int8{constants.ReleaseAppStatusProdDeployed, constants.ReleaseAppStatusProdFailed},
+			To:          constants.ReleaseAppStatusProdTriggered,
+			Handler:     RedeployClustersTransition{sm: sm},
+			AllowSource: TransitionSourceOutside,
+		},
 		// 生产完成
 		{
 			From:        []int8{constants.ReleaseAppStatusProdTriggered},
@@ -239,6 +291,10 @@ func (h ManualTriggerProdDeploy) Handle(release *model.ReleaseApp, from int8, op
 		return fmt.Errorf("目标版本为空, 无法进行[Prod]发布")
 	}
 
+	if release.SkipProdEnv {
+		return fmt.Errorf("[Prod]发布失败: 应用跳过生产环境（仅预发布或未配置生产环境）")
+	}
+
 	if release.Status == constants.ReleaseAppStatusTagged {
 		// -> 无预发布情况
 		// 检查该应用 是否无预发布环境
@@ -0,0 +1,40 @@
+package release_app
+
+import (
+	"context"
+	"devops-cd/internal/core/common/vulngate"
+	"devops-cd/internal/model"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// checkVulnGate 按项目漏洞策略（stage=prod）检查构建镜像，未通过时返回拦截原因；
+// 调用方保持当前状态，镜像重新扫描通过或批次被豁免后下次处理自动放行
+func (sm *ReleaseStateMachine) checkVulnGate(ctx context.Context, release *model.ReleaseApp) (string, error) {
+	db := sm.db.WithContext(ctx)
+	var batch model.Batch
+	if err := db.First(&batch, release.BatchID).Error; err != nil {
+		return "", fmt.Errorf("batch record not found: %w", err)
+	}
+	policy, err := vulngate.Policy(db, &batch, model.VulnGateStageProd)
+	if err != nil || policy == nil {
+		return "", err
+	}
+
+	if release.BuildID == nil {
+		return "", fmt.Errorf("build_id 为空")
+	}
+	var build model.Build
+	if err := db.First(&build, release.BuildID).Error; err != nil {
+		return "", fmt.Errorf("build record not found: %w", err)
+	}
+	violations, err := vulngate.Evaluate(db, policy, []model.Build{build})
+	if err != nil || len(violations) == 0 {
+		return "", err
+	}
+
+	reason := vulngate.Summary(violations)
+	sm.logger.Debug("生产部署被漏洞门禁拦截", zap.Int64("release_id", release.ID), zap.String("reason", reason))
+	return reason, nil
+}
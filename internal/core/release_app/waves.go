@@ -0,0 +1,129 @@
+package release_app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"devops-cd/internal/model"
+)
+
+// PlanWaves 计算批次内应用的部署波次（app_id -> wave，从 1 开始）
+//
+// 依赖来源与 CheckRelease 一致（default / temporary / app_type），只保留本批次内的依赖：
+// 无依赖的应用为第 1 波，其余应用的波次为其依赖的最大波次 + 1；存在循环依赖时返回错误
+func (r *Resolver) PlanWaves(ctx context.Context, batchID int64) (map[int64]int, error) {
+	var releases []model.ReleaseApp
+	if err := r.db.WithContext(ctx).
+		Select("id", "app_id", "build_id", "temp_depends_on", "manifest_lock").
+		Where("batch_id = ?", batchID).
+		Find(&releases).Error; err != nil {
+		return nil, fmt.Errorf("查询批次应用失败: %w", err)
+	}
+	if len(releases) == 0 {
+		return map[int64]int{}, nil
+	}
+
+	appIDs := make([]int64, 0, len(releases))
+	for _, rel := range releases {
+		appIDs = append(appIDs, rel.AppID)
+	}
+	var apps []model.Application
+	if err := r.db.WithContext(ctx).
+		Select("id", "name", "app_type", "default_depends_on").
+		Where("id IN ?", appIDs).
+		Find(&apps).Error; err != nil {
+		return nil, fmt.Errorf("查询应用信息失败: %w", err)
+	}
+	appInfos := make(map[int64]*model.Application, len(apps))
+	for i := range apps {
+		appInfos[apps[i].ID] = &apps[i]
+	}
+
+	graph := make(map[int64][]int64, len(releases))
+	for _, rel := range releases {
+		inBatch := func(id int64) bool {
+			_, ok := appInfos[id]
+			return ok && id != rel.AppID
+		}
+		var deps []int64
+		app, ok := appInfos[rel.AppID]
+		if ok {
+			for _, id := range rel.DependsOn(app) {
+				if inBatch(id) {
+					deps = append(deps, id)
+				}
+			}
+			if types := r.cfg.AppTypeDepends[app.AppType]; len(types) > 0 {
+				for id, other := range appInfos {
+					if inBatch(id) && slices.Contains(types, other.AppType) {
+						deps = append(deps, id)
+					}
+				}
+			}
+		}
+		for _, id := range rel.TempDependsOn {
+			if inBatch(id) {
+				deps = append(deps, id)
+			}
+		}
+		graph[rel.AppID] = normalizeIDs(deps)
+	}
+
+	waves, cyclic := levelGraph(graph)
+	if len(cyclic) > 0 {
+		names := make([]string, 0, len(cyclic))
+		for _, id := range cyclic {
+			if app, ok := appInfos[id]; ok {
+				names = append(names, app.Name)
+			} else {
+				names = append(names, fmt.Sprintf("ID=%d", id))
+			}
+		}
+		return nil, fmt.Errorf("批次内应用依赖存在循环: %s", strings.Join(names, ", "))
+	}
+	return waves, nil
+}
+
+// levelGraph 按拓扑层级为 graph（节点 -> 依赖）分配波次；无法分配的节点（处于或依赖于环）按 ID 升序返回
+func levelGraph(graph map[int64][]int64) (map[int64]int, []int64) {
+	waves := make(map[int64]int, len(graph))
+	for len(waves) < len(graph) {
+		progressed := false
+		for id, deps := range graph {
+			if _, done := waves[id]; done {
+				continue
+			}
+			wave := 1
+			ready := true
+			for _, dep := range deps {
+				w, ok := waves[dep]
+				if !ok {
+					ready = false
+					break
+				}
+				if w+1 > wave {
+					wave = w + 1
+				}
+			}
+			if ready {
+				waves[id] = wave
+				progressed = true
+			}
+		}
+		if !progressed {
+			break
+		}
+	}
+
+	var cyclic []int64
+	for id := range graph {
+		if _, ok := waves[id]; !ok {
+			cyclic = append(cyclic, id)
+		}
+	}
+	sort.Slice(cyclic, func(i, j int) bool { return cyclic[i] < cyclic[j] })
+	return waves, cyclic
+}
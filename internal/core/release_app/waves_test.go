@@ -0,0 +1,66 @@
+package release_app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLevelGraph(t *testing.T) {
+	cases := []struct {
+		name       string
+		graph      map[int64][]int64
+		wantWaves  map[int64]int
+		wantCyclic []int64
+	}{
+		{
+			name:      "空批次",
+			graph:     map[int64][]int64{},
+			wantWaves: map[int64]int{},
+		},
+		{
+			name:      "无依赖均为第 1 波",
+			graph:     map[int64][]int64{1: nil, 2: nil, 3: {}},
+			wantWaves: map[int64]int{1: 1, 2: 1, 3: 1},
+		},
+		{
+			name:      "链式依赖",
+			graph:     map[int64][]int64{3: {2}, 2: {1}, 1: nil},
+			wantWaves: map[int64]int{1: 1, 2: 2, 3: 3},
+		},
+		{
+			// 4 依赖第 1 波的 1 与第 2 波的 3，取最大波次 + 1
+			name:      "取依赖的最大波次",
+			graph:     map[int64][]int64{1: nil, 2: nil, 3: {2}, 4: {1, 3}},
+			wantWaves: map[int64]int{1: 1, 2: 1, 3: 2, 4: 3},
+		},
+		{
+			name:      "菱形依赖",
+			graph:     map[int64][]int64{1: nil, 2: {1}, 3: {1}, 4: {2, 3}},
+			wantWaves: map[int64]int{1: 1, 2: 2, 3: 2, 4: 3},
+		},
+		{
+			// 依赖于环的节点同样无法分配
+			name:       "循环依赖",
+			graph:      map[int64][]int64{1: nil, 2: {3}, 3: {2}, 4: {3}, 5: {1}},
+			wantWaves:  map[int64]int{1: 1, 5: 2},
+			wantCyclic: []int64{2, 3, 4},
+		},
+		{
+			name:       "自依赖",
+			graph:      map[int64][]int64{7: {7}},
+			wantWaves:  map[int64]int{},
+			wantCyclic: []int64{7},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			waves, cyclic := levelGraph(c.graph)
+			if !reflect.DeepEqual(waves, c.wantWaves) {
+				t.Errorf("waves = %v, want %v", waves, c.wantWaves)
+			}
+			if !reflect.DeepEqual(cyclic, c.wantCyclic) {
+				t.Errorf("cyclic = %v, want %v", cyclic, c.wantCyclic)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"devops-cd/internal/adapter/notification"
+	"devops-cd/internal/core/common/metacache"
+	"devops-cd/internal/core/deployment"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+
+	"go.uber.org/zap"
+)
+
+// 配置漂移检测
+//
+// 由调度器按 core.drift_check.cron 调用：以各应用/环境/集群最近一次写入 release 的 Deployment 为基准，
+// 对比集群中当前的 helm release（revision、values、manifest），开启 live_objects 时再对比集群中的资源对象；
+// 结果写入 release_drifts，漂移内容变化后通知一次（恢复后再次漂移重新通知）
+
+// releaseDriftTimeout 单个 release 的检测超时
+const releaseDriftTimeout = 2 * time.Minute
+
+// releaseDriftNotifyFindings 通知中最多列出的漂移项
+const releaseDriftNotifyFindings = 5
+
+// CheckReleaseDrifts 执行一次配置漂移检测
+func (e *CoreEngine) CheckReleaseDrifts() {
+	ctx := e.workCtx
+	targets, err := deployment.ReleaseDriftTargets(ctx, e.db)
+	if err != nil {
+		e.logger.Error("[ReleaseDrift] 查询检测目标失败", zap.Error(err))
+		return
+	}
+
+	start := time.Now()
+	counts := make(map[string]int)
+	for _, dep := range targets {
+		if ctx.Err() != nil {
+			return
+		}
+		checkCtx, cancel := context.WithTimeout(ctx, releaseDriftTimeout)
+		drift, err := deployment.RecordReleaseDrift(checkCtx, e.db, dep, e.driftLiveObjects)
+		cancel()
+		if err != nil {
+			e.logger.Warn("[ReleaseDrift] 检测失败", zap.Int64("deployment_id", dep.ID), zap.Error(err))
+			continue
+		}
+		counts[drift.Status]++
+		if drift.Status == constants.ReleaseDriftStatusError {
+			e.logger.Warn("[ReleaseDrift] 检测失败", zap.Int64("deployment_id", dep.ID),
+				zap.String("cluster", dep.ClusterName), zap.Stringp("error", drift.ErrorMessage))
+		}
+		e.notifyReleaseDrift(drift)
+	}
+	e.logger.Info("[ReleaseDrift] 检测完成",
+		zap.Int("targets", len(targets)),
+		zap.Int("drift", counts[constants.ReleaseDriftStatusDrift]),
+		zap.Int("missing", counts[constants.ReleaseDriftStatusMissing]),
+		zap.Int("error", counts[constants.ReleaseDriftStatusError]),
+		zap.Duration("elapsed", time.Since(start)))
+}
+
+// notifyReleaseDrift 漂移/release 缺失且内容与上次通知不同时通知，发送成功后记录 notified_digest
+func (e *CoreEngine) notifyReleaseDrift(drift *model.ReleaseDrift) {
+	if drift.Status != constants.ReleaseDriftStatusDrift && drift.Status != constants.ReleaseDriftStatusMissing {
+		return
+	}
+	if drift.NotifiedDigest != nil && *drift.NotifiedDigest == drift.Digest {
+		return
+	}
+	app, err := metacache.Application(e.workCtx, e.db, drift.AppID)
+	if err != nil {
+		e.logger.Warn("[ReleaseDrift] 查询应用失败", zap.Int64("app_id", drift.AppID), zap.Error(err))
+		return
+	}
+
+	id, digest, message := drift.ID, drift.Digest, releaseDriftMessage(drift)
+	e.enqueueNotify(func(ctx context.Context) error {
+		if err := e.notifier.SendAppDeployNotification(ctx, 0, app.ID, app.Name, notification.NotifyReleaseDrift, message); err != nil {
+			return err
+		}
+		return e.db.WithContext(ctx).Model(&model.ReleaseDrift{}).Where("id = ?", id).Update("notified_digest", digest).Error
+	})
+}
+
+func releaseDriftMessage(drift *model.ReleaseDrift) string {
+	head := fmt.Sprintf("[%s/%s] %s release %s/%s", drift.Env, drift.ClusterName, drift.Kind, drift.Namespace, drift.ReleaseName)
+	if drift.Status == constants.ReleaseDriftStatusMissing {
+		return head + fmt.Sprintf(" 不存在（最近一次部署 #%d 写入后被删除）", drift.DeploymentID)
+	}
+
+	lines := []string{fmt.Sprintf("%s 与最近一次部署 #%d 不一致（%d 项）:", head, drift.DeploymentID, len(drift.Findings))}
+	for i, f := range drift.Findings {
+		if i == releaseDriftNotifyFindings {
+			lines = append(lines, fmt.Sprintf("... 其余 %d 项见配置漂移详情", len(drift.Findings)-i))
+			break
+		}
+		lines = append(lines, "- "+releaseDriftFindingText(f))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func releaseDriftFindingText(f model.DriftFinding) string {
+	switch f.Type {
+	case constants.ReleaseDriftFindingRevision:
+		return fmt.Sprintf("release revision %s → %s（存在平台外的 helm upgrade/rollback）", f.Expected, f.Actual)
+	case constants.ReleaseDriftFindingValues:
+		if f.Path == "" {
+			return "values 已变更"
+		}
+		return "values " + f.Path + " 已变更"
+	case constants.ReleaseDriftFindingManifest:
+		if f.Resource == "" {
+			return "manifest 已变更"
+		}
+		return fmt.Sprintf("manifest %s %s", f.Resource, f.Actual)
+	default:
+		if f.Path == "" {
+			return fmt.Sprintf("%s %s", f.Resource, f.Actual)
+		}
+		if f.Expected != "" || f.Actual != "" {
+			return fmt.Sprintf("%s %s: %s → %s", f.Resource, f.Path, f.Expected, f.Actual)
+		}
+		return fmt.Sprintf("%s %s 已变更", f.Resource, f.Path)
+	}
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"devops-cd/internal/core/batch/transitions"
 	"devops-cd/internal/core/release_app"
 	"devops-cd/internal/dto"
 	"devops-cd/internal/model"
@@ -16,6 +17,12 @@ func (e *CoreEngine) ProcessBatchEvent(batchID int64, event string, operator, re
 	return e.batchSM.ProcessStateChange(batchID, event, operator, reason)
 }
 
+// ProcessScheduledBatchEvent 处理定时部署触发的批次事件，审计记录为 scheduled
+func (e *CoreEngine) ProcessScheduledBatchEvent(batchID int64, event string, operator, reason string) error {
+	return e.batchSM.ProcessStateChange(batchID, event, operator, reason,
+		transitions.WithTrigger(constants.BatchEventTriggerScheduled))
+}
+
 // GetBatchStatus 获取批次状态
 func (e *CoreEngine) GetBatchStatus(batchID int64) (map[string]interface{}, error) {
 	var batch model.Batch
@@ -38,6 +45,23 @@ func (e *CoreEngine) GetBatchStatus(batchID int64) (map[string]interface{}, erro
 func (e *CoreEngine) NewTag(appID int64, build *model.Build) {
 	log := e.logger.Sugar().With(zap.Int64("app_id", appID), zap.Int64("build_id", build.ID))
 
+	// 来源校验不一致的构建不进入批次
+	if build.IsProvenanceMismatch() {
+		log.Warnf("构建 %s 来源校验不一致，忽略", build.ImageTag)
+		return
+	}
+
+	// 按应用构建过滤规则过滤（如只接受 v* tag）
+	var app model.Application
+	if err := e.db.Select("id", "tag_filter").First(&app, appID).Error; err != nil {
+		log.Errorf("查询应用失败: %v", err)
+		return
+	}
+	if !app.TagFilter.MatchBuild(build) {
+		log.Infof("构建 %s(分支:%s) 不满足应用构建过滤规则，忽略", build.ImageTag, build.CommitBranch)
+		return
+	}
+
 	// 查看该app在哪个开放的batch中 status < BatchStatusFinalAccepted
 	var releases []model.ReleaseApp
 	if err := e.db.Debug().InnerJoins("Join release_batches ON release_apps.batch_id = release_batches.id AND release_batches.status < ?", constants.BatchStatusFinalAccepted).
@@ -56,6 +80,7 @@ func (e *CoreEngine) NewTag(appID int64, build *model.Build) {
 	}
 	release := releases[0]
 	log = log.With(zap.Int64("batch_id", release.BatchID), zap.Int64("release_id", release.ID))
+	defer e.TriggerBatch(release.BatchID)
 
 	if release.Batch.Status < constants.BatchStatusSealed { // 1. 如果批次未封板
 
@@ -95,3 +120,75 @@ func (e *CoreEngine) SwitchVersion(req *dto.SwitchVersionRequest) (*string, erro
 func (e *CoreEngine) ManualDeploy(req *dto.ManualDeployRequest) (string, error) {
 	return "ok", e.releaseSM.ManualDeploy(req.ReleaseAppID, req.Action, req.Operator, req.Reason)
 }
+
+// RollbackBatch 回滚批次内已部署生产的应用到 previous_deployed_tag
+//
+// 先逐个触发应用回滚，再将批次置为回滚中（保证批次进入回滚中时已有回滚应用，避免被误判为回滚失败）；
+// 无发布前版本等不可回滚的应用跳过并在结果中说明，全部不可回滚时返回错误且不改变批次状态
+func (e *CoreEngine) RollbackBatch(req *dto.RollbackBatchRequest) (*dto.RollbackResponse, error) {
+	var b model.Batch
+	if err := e.db.First(&b, req.BatchID).Error; err != nil {
+		return nil, fmt.Errorf("查询批次失败: %w", err)
+	}
+	if !release_app.RollbackAllowedBatchStatus[b.Status] {
+		return nil, fmt.Errorf("当前批次状态 %s 不允许回滚", constants.BatchStatusToString(b.Status))
+	}
+
+	var releases []model.ReleaseApp
+	if err := e.db.Where("batch_id = ? AND skip_prod_env = ?", b.ID, false).Order("id").Find(&releases).Error; err != nil {
+		return nil, fmt.Errorf("查询发布应用失败: %w", err)
+	}
+
+	resp := &dto.RollbackResponse{BatchID: b.ID, Triggered: []int64{}, Skipped: []dto.RollbackSkipped{}}
+	for _, r := range releases {
+		if r.Status >= constants.ReleaseAppStatusRollbackCanTrigger && r.Status < constants.ReleaseAppStatusRollbackFailed {
+			resp.Skipped = append(resp.Skipped, dto.RollbackSkipped{ReleaseAppID: r.ID, AppID: r.AppID, Reason: "已回滚或回滚中"})
+			continue
+		}
+		if err := e.releaseSM.Rollback(r.ID, req.Operator, req.Reason); err != nil {
+			resp.Skipped = append(resp.Skipped, dto.RollbackSkipped{ReleaseAppID: r.ID, AppID: r.AppID, Reason: err.Error()})
+			continue
+		}
+		resp.Triggered = append(resp.Triggered, r.ID)
+	}
+	if len(resp.Triggered) == 0 {
+		return resp, fmt.Errorf("批次内无可回滚的应用")
+	}
+
+	if err := e.batchSM.StartRollback(b.ID, req.Operator, req.Reason); err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
+
+// RollbackReleaseApp 回滚单个发布应用到 previous_deployed_tag，批次随之进入回滚中
+func (e *CoreEngine) RollbackReleaseApp(req *dto.RollbackReleaseAppRequest) (*dto.RollbackResponse, error) {
+	var r model.ReleaseApp
+	if err := e.db.First(&r, req.ReleaseAppID).Error; err != nil {
+		return nil, fmt.Errorf("查询发布应用失败: %w", err)
+	}
+	if err := e.releaseSM.Rollback(r.ID, req.Operator, req.Reason); err != nil {
+		return nil, err
+	}
+	if err := e.batchSM.StartRollback(r.BatchID, req.Operator, req.Reason); err != nil {
+		return nil, err
+	}
+	return &dto.RollbackResponse{BatchID: r.BatchID, Triggered: []int64{r.ID}, Skipped: []dto.RollbackSkipped{}}, nil
+}
+
+// RolloutReleaseApp 生产灰度 promote/abort
+func (e *CoreEngine) RolloutReleaseApp(req *dto.ReleaseAppRolloutRequest) error {
+	switch req.Action {
+	case constants.ReleaseAppRolloutActionPromote:
+		return e.releaseSM.PromoteRollout(req.ReleaseAppID, req.Operator, req.Reason)
+	case constants.ReleaseAppRolloutActionAbort:
+		return e.releaseSM.AbortRollout(req.ReleaseAppID, req.Operator, req.Reason)
+	default:
+		return fmt.Errorf("无效的灰度动作: %s", req.Action)
+	}
+}
+
+// RedeployReleaseAppClusters 按集群重新部署发布应用（其余集群的 Deployment 保持不变）
+func (e *CoreEngine) RedeployReleaseAppClusters(req *dto.ReleaseAppRedeployRequest) error {
+	return e.releaseSM.RedeployClusters(req.ReleaseAppID, req.Env, req.Clusters, req.Operator, req.Reason)
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"devops-cd/internal/adapter/notification"
+	"devops-cd/internal/core/deployment"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/config"
+	"devops-cd/pkg/constants"
+
+	"go.uber.org/zap"
+)
+
+// 部署运行时长异常告警（core.deploy.slow_factor）
+//
+// - 每次扫描检查 running 的 app Deployment：运行时长超过该应用同环境最近成功部署耗时 P95 的 slow_factor 倍
+//   （且不低于 slow_min_duration）时，写入 slow_alerted_at / expected_duration_sec、记录时间线事件并发送 deploy_slow 通知
+// - 只告警不改变状态，部署仍由 single_app_timeout 判定超时失败；同一次运行只告警一次，重试后重新计时
+// - 历史成功部署少于 slow_min_samples 次的应用不检查
+
+const (
+	defaultSlowMinSamples  = 5
+	defaultSlowMinDuration = 2 * time.Minute
+)
+
+type slowDeploy struct {
+	factor      float64
+	minSamples  int
+	minDuration time.Duration
+}
+
+func newSlowDeploy(coreCfg *config.CoreConfig, logger *zap.Logger) *slowDeploy {
+	if coreCfg == nil || coreCfg.Deploy.SlowFactor <= 0 {
+		return nil
+	}
+	sd := &slowDeploy{factor: coreCfg.Deploy.SlowFactor, minSamples: coreCfg.Deploy.SlowMinSamples, minDuration: defaultSlowMinDuration}
+	if sd.minSamples <= 0 {
+		sd.minSamples = defaultSlowMinSamples
+	}
+	if coreCfg.Deploy.SlowMinDuration != "" {
+		if d, err := time.ParseDuration(coreCfg.Deploy.SlowMinDuration); err != nil || d < 0 {
+			logger.Warn("slow_min_duration 解析失败，使用默认值", zap.String("value", coreCfg.Deploy.SlowMinDuration), zap.Error(err))
+		} else {
+			sd.minDuration = d
+		}
+	}
+	return sd
+}
+
+// scanSlowDeployments 检查运行时长异常的 Deployment
+func (e *CoreEngine) scanSlowDeployments() {
+	sd := e.slowDeploy
+	if sd == nil {
+		return
+	}
+	ctx := e.workCtx
+	var deps []model.Deployment
+	if err := e.db.WithContext(ctx).
+		Where("status = ? AND kind = ? AND superseded_by IS NULL", constants.DeploymentStatusRunning, constants.DeploymentKindApp).
+		Where("started_at IS NOT NULL AND started_at < ?", time.Now().Add(-sd.minDuration)).
+		Where("slow_alerted_at IS NULL OR slow_alerted_at < started_at").
+		Find(&deps).Error; err != nil {
+		e.logger.Error(fmt.Sprintf("[SlowDeploy] 查询运行中的 Deployment 失败: %v", err))
+		return
+	}
+
+	type baselineKey struct {
+		appID int64
+		env   string
+	}
+	baselines := make(map[baselineKey]*deployment.DurationStats)
+	for i := range deps {
+		dep := &deps[i]
+		key := baselineKey{appID: dep.AppID, env: dep.Env}
+		stats, ok := baselines[key]
+		if !ok {
+			var err error
+			if stats, err = deployment.DurationBaseline(ctx, e.db, dep.AppID, dep.Env, deployment.DurationHistory); err != nil {
+				e.logger.Error("[SlowDeploy] 统计历史部署耗时失败", zap.Int64("app_id", dep.AppID), zap.String("env", dep.Env), zap.Error(err))
+				continue
+			}
+			baselines[key] = stats
+		}
+		if stats.Samples < sd.minSamples {
+			continue
+		}
+		threshold := max(time.Duration(float64(stats.P95)*sd.factor), sd.minDuration)
+		elapsed := time.Since(*dep.StartedAt)
+		if elapsed <= threshold {
+			continue
+		}
+		e.alertSlowDeployment(ctx, dep, stats, threshold, elapsed)
+	}
+}
+
+// alertSlowDeployment 标记 Deployment 并通知；条件更新保证同一次运行只告警一次（多副本切换主节点时也不重复）
+func (e *CoreEngine) alertSlowDeployment(ctx context.Context, dep *model.Deployment, stats *deployment.DurationStats, threshold, elapsed time.Duration) {
+	log := e.logger.With(zap.Int64("batch_id", dep.BatchID), zap.Int64("deployment_id", dep.ID), zap.String("cluster", dep.ClusterName))
+	res := e.db.WithContext(ctx).Model(&model.Deployment{}).
+		Where("id = ? AND status = ? AND (slow_alerted_at IS NULL OR slow_alerted_at < started_at)", dep.ID, constants.DeploymentStatusRunning).
+		Updates(map[string]any{"slow_alerted_at": time.Now(), "expected_duration_sec": int(stats.P95.Seconds())})
+	if res.Error != nil {
+		log.Error("[SlowDeploy] 标记部署耗时异常失败", zap.Error(res.Error))
+		return
+	}
+	if res.RowsAffected == 0 {
+		return
+	}
+
+	message := fmt.Sprintf("[%s/%s] 已运行 %s，超过历史耗时 P95 %s 的 %g 倍（阈值 %s，近 %d 次成功部署平均 %s），可能卡住",
+		dep.Env, dep.ClusterName, elapsed.Round(time.Second), stats.P95.Round(time.Second), e.slowDeploy.factor,
+		threshold.Round(time.Second), stats.Samples, stats.Avg.Round(time.Second))
+	log.Warn("[SlowDeploy] " + message)
+	if err := e.db.WithContext(ctx).Create(model.NewDeploymentEvent(dep, constants.DeploymentEventSlow, message)).Error; err != nil {
+		log.Warn("写入 Deployment 时间线事件失败", zap.Error(err))
+	}
+
+	var app model.Application
+	if err := e.db.WithContext(ctx).Select("id", "name").First(&app, dep.AppID).Error; err != nil {
+		log.Error("[SlowDeploy] 查询应用失败", zap.Int64("app_id", dep.AppID), zap.Error(err))
+		return
+	}
+	batchID := dep.BatchID
+	e.enqueueNotify(func(ctx context.Context) error {
+		return e.notifier.SendAppDeployNotification(ctx, batchID, app.ID, app.Name, notification.NotifyDeploySlow, message)
+	})
+}
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"devops-cd/internal/adapter/notification"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/config"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+)
+
+// newDirectNotifiers 订阅通知渠道：配置了 Lark 应用机器人时私聊，配置了 SMTP 时发邮件（与默认群通知 provider 无关）
+func newDirectNotifiers(logger *zap.Logger, coreCfg *config.CoreConfig) []notification.DirectNotifier {
+	if coreCfg == nil || !coreCfg.Notification.Enabled {
+		return nil
+	}
+	cfg := coreCfg.Notification
+	var notifiers []notification.DirectNotifier
+	if cfg.LarkAppID != "" && cfg.LarkAppSecret != "" {
+		notifiers = append(notifiers, notification.NewLarkDirectNotifier(notification.LarkAppConfig{
+			APIBase:   cfg.LarkAPIBase,
+			AppID:     cfg.LarkAppID,
+			AppSecret: cfg.LarkAppSecret,
+		}, logger))
+	}
+	if cfg.SMTPHost != "" && (cfg.SMTPFrom != "" || cfg.SMTPUsername != "") {
+		notifiers = append(notifiers, notification.NewEmailNotifier(notification.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+			TLS:      cfg.SMTPTLS,
+		}, logger))
+	}
+	return notifiers
+}
+
+// registerSubscriptionListeners 批次/发布应用状态变更后通知订阅人（批次订阅：批次状态；应用订阅：该应用的部署成功/失败）
+func (e *CoreEngine) registerSubscriptionListeners() {
+	if len(e.directNotifiers) == 0 {
+		return
+	}
+	e.batchSM.OnStatusChange(e.notifyBatchSubscribers)
+	e.releaseSM.OnStatusChange(e.notifyAppSubscribers)
+}
+
+func (e *CoreEngine) notifyBatchSubscribers(b model.Batch, from, to int8) {
+	n, ok := batchNotifies[to]
+	if !ok || from == to {
+		return
+	}
+	e.enqueueNotify(func(ctx context.Context) error {
+		return e.notifySubscribers(ctx, model.SubscriptionTargetBatch, b.ID, notification.BatchMessage(&b, n.typ, n.message))
+	})
+}
+
+func (e *CoreEngine) notifyAppSubscribers(r model.ReleaseApp, from, to int8) {
+	typ, message, ok := releaseNotify(r, from, to)
+	if !ok {
+		return
+	}
+	e.enqueueNotify(func(ctx context.Context) error {
+		var app model.Application
+		if err := e.db.WithContext(ctx).Select("id", "name").First(&app, r.AppID).Error; err != nil {
+			return fmt.Errorf("查询应用失败: %w", err)
+		}
+		msg := notification.AppDeployMessage(r.BatchID, app.ID, app.Name, typ, message)
+		return e.notifySubscribers(ctx, model.SubscriptionTargetApp, app.ID, msg)
+	})
+}
+
+// notifySubscribers 按订阅渠道逐个发送，单个订阅人/渠道失败只记录日志
+func (e *CoreEngine) notifySubscribers(ctx context.Context, targetType string, targetID int64, msg *notification.NotificationMessage) error {
+	var subs []model.Subscription
+	if err := e.db.WithContext(ctx).Where("target_type = ? AND target_id = ?", targetType, targetID).
+		Find(&subs).Error; err != nil {
+		return fmt.Errorf("查询订阅失败: %w", err)
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	var users []model.User
+	if err := e.db.WithContext(ctx).Select("id", "username", "email").
+		Where("username IN ?", lo.Uniq(lo.Map(subs, func(s model.Subscription, _ int) string { return s.Username }))).
+		Find(&users).Error; err != nil {
+		return fmt.Errorf("查询订阅用户失败: %w", err)
+	}
+	emails := make(map[string]string, len(users))
+	for _, u := range users {
+		if u.Email != nil && emails[u.Username] == "" {
+			emails[u.Username] = *u.Email
+		}
+	}
+
+	for _, sub := range subs {
+		to := &notification.Recipient{Username: sub.Username, Email: emails[sub.Username]}
+		for _, n := range e.directNotifiers {
+			if len(sub.Channels) > 0 && !lo.Contains(sub.Channels, n.Channel()) {
+				continue
+			}
+			if err := n.SendTo(ctx, to, msg); err != nil {
+				e.logger.Warn("[Notify] 发送订阅通知失败",
+					zap.String("user", sub.Username), zap.String("channel", n.Channel()),
+					zap.String("target_type", targetType), zap.Int64("target_id", targetID), zap.Error(err))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/config"
+	"devops-cd/pkg/constants"
+
+	"go.uber.org/zap"
+)
+
+// 事件驱动的批次处理
+//
+// - API 操作、构建通知以及批次/发布应用/部署状态变更调用 TriggerBatch，批次立即处理一轮，不必等待下一次扫描
+// - 触发经扫描 goroutine 分发：批次已有 batchWork 时唤醒它，否则为进行中的批次启动 batchWork
+// - 定时扫描（scan_interval）与 batchWork 的兜底间隔（sweep_interval）仍然保留，覆盖直接改库、队列溢出、非主节点副本上的操作等情况
+
+const (
+	// triggerQueueSize 待分发的触发队列长度，队列满时丢弃（由兜底扫描处理）
+	triggerQueueSize = 256
+	// defaultSweepInterval batchWork 未收到触发时的兜底处理间隔
+	defaultSweepInterval = 10 * time.Second
+	// minBatchRunGap 同一批次两轮处理的最小间隔，避免状态连续变更时空转
+	minBatchRunGap = time.Second
+	// batchActiveWindow 只处理该时间内创建的批次
+	batchActiveWindow = 30 * 24 * time.Hour
+)
+
+// batchTask 批次处理任务
+type batchTask struct {
+	cancel context.CancelFunc
+	wake   chan struct{}
+}
+
+// TriggerBatch 将批次加入立即处理队列，不阻塞调用方
+func (e *CoreEngine) TriggerBatch(batchID int64) {
+	if batchID <= 0 {
+		return
+	}
+	select {
+	case e.triggers <- batchID:
+	default:
+		e.logger.Debug("[Trigger] 触发队列已满，等待兜底扫描", zap.Int64("batch_id", batchID))
+	}
+}
+
+// registerTriggerListeners 批次/发布应用/部署状态变更后立即处理所属批次，推进下一步
+func (e *CoreEngine) registerTriggerListeners() {
+	e.batchSM.OnStatusChange(func(b model.Batch, _, _ int8) {
+		e.TriggerBatch(b.ID)
+	})
+	e.releaseSM.OnStatusChange(func(r model.ReleaseApp, _, _ int8) {
+		e.TriggerBatch(r.BatchID)
+	})
+	e.deploymentSM.OnStatusChange(func(dep model.Deployment, _, _ string) {
+		e.TriggerBatch(dep.BatchID)
+	})
+}
+
+// dispatchTrigger 唤醒批次的 batchWork；批次尚无 batchWork 且处于进行中时启动
+func (e *CoreEngine) dispatchTrigger(batchID int64) {
+	e.taskMu.Lock()
+	t, ok := e.batchTask[batchID]
+	e.taskMu.Unlock()
+	if ok {
+		select {
+		case t.wake <- struct{}{}:
+		default: // 已有待处理的唤醒
+		}
+		return
+	}
+
+	var b model.Batch
+	if err := e.db.Select("id", "status", "created_at").First(&b, batchID).Error; err != nil {
+		e.logger.Debug("[Trigger] 查询批次失败", zap.Int64("batch_id", batchID), zap.Error(err))
+		return
+	}
+	if !batchActive(&b) {
+		return
+	}
+	e.startBatchWork(b.ID)
+}
+
+// batchActive 与 ScanBatches 的查询条件一致：已封板未完成（及回滚中），且在 batchActiveWindow 内创建
+func batchActive(b *model.Batch) bool {
+	inProgress := (b.Status > constants.BatchStatusDraft && b.Status < constants.BatchStatusCompleted) ||
+		b.Status == constants.BatchStatusRollingBack
+	return inProgress && b.CreatedAt.After(time.Now().Add(-batchActiveWindow))
+}
+
+// startBatchWork 为批次启动 batchWork（已存在或引擎停止中时忽略），启动后立即处理一轮
+func (e *CoreEngine) startBatchWork(batchID int64) {
+	e.taskMu.Lock()
+	defer e.taskMu.Unlock()
+	if _, exists := e.batchTask[batchID]; exists || e.draining.Load() {
+		return
+	}
+	ctx, cancel := context.WithCancel(e.workCtx)
+	t := &batchTask{cancel: cancel, wake: make(chan struct{}, 1)}
+	t.wake <- struct{}{}
+	e.batchTask[batchID] = t
+	e.goWork(func() { e.batchWork(ctx, batchID, t) })
+}
+
+// batchRunning 批次是否有正在运行的 batchWork
+func (e *CoreEngine) batchRunning(batchID int64) bool {
+	e.taskMu.Lock()
+	defer e.taskMu.Unlock()
+	_, ok := e.batchTask[batchID]
+	return ok
+}
+
+func (e *CoreEngine) batchWork(ctx context.Context, batchId int64, t *batchTask) {
+	ticker := time.NewTicker(e.sweepInterval)
+	defer func() {
+		ticker.Stop()
+		t.cancel()
+		e.taskMu.Lock()
+		if e.batchTask[batchId] == t {
+			delete(e.batchTask, batchId)
+		}
+		e.taskMu.Unlock()
+	}()
+
+	// 引擎停止时完成当前一轮后退出
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.stopChan:
+			return
+		case <-ticker.C:
+		case <-t.wake:
+		}
+
+		if done := e.RunBatchOnce(ctx, batchId); done {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.stopChan:
+			return
+		case <-time.After(minBatchRunGap):
+		}
+	}
+}
+
+// sweepIntervalFromConfig core.sweep_interval，未配置或格式错误时使用默认值
+func sweepIntervalFromConfig(coreCfg *config.CoreConfig, logger *zap.Logger) time.Duration {
+	if coreCfg == nil || coreCfg.SweepInterval == "" {
+		return defaultSweepInterval
+	}
+	d, err := time.ParseDuration(coreCfg.SweepInterval)
+	if err != nil || d <= 0 {
+		logger.Warn("sweep_interval 解析失败，使用默认值", zap.String("value", coreCfg.SweepInterval), zap.Error(err))
+		return defaultSweepInterval
+	}
+	return d
+}
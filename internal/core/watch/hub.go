@@ -0,0 +1,80 @@
+package watch
+
+import (
+	"sync"
+	"time"
+)
+
+// 事件对象类型
+const (
+	KindBatch      = "batch"
+	KindReleaseApp = "release_app"
+	KindDeployment = "deployment"
+)
+
+// subscriberBuffer 单个订阅者的缓冲，消费过慢时丢弃新事件（客户端可重新拉取状态）
+const subscriberBuffer = 64
+
+// Event 批次内对象的状态变更
+type Event struct {
+	Kind       string    `json:"kind"` // batch/release_app/deployment
+	BatchID    int64     `json:"batch_id"`
+	ID         int64     `json:"id"` // 对象 ID（batch 时同 batch_id）
+	AppID      int64     `json:"app_id,omitempty"`
+	Env        string    `json:"env,omitempty"`
+	Cluster    string    `json:"cluster,omitempty"`
+	FromStatus string    `json:"from_status"`
+	Status     string    `json:"status"`
+	StatusName string    `json:"status_name,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Hub 进程内按批次分发状态变更事件
+type Hub struct {
+	mu   sync.RWMutex
+	subs map[int64]map[chan Event]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: make(map[int64]map[chan Event]struct{})}
+}
+
+// Subscribe 订阅批次事件，返回事件通道与取消函数（取消后通道关闭）
+func (h *Hub) Subscribe(batchID int64) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	h.mu.Lock()
+	if h.subs[batchID] == nil {
+		h.subs[batchID] = make(map[chan Event]struct{})
+	}
+	h.subs[batchID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subs[batchID], ch)
+			if len(h.subs[batchID]) == 0 {
+				delete(h.subs, batchID)
+			}
+			h.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish 非阻塞发布事件，订阅者缓冲已满时丢弃
+func (h *Hub) Publish(e Event) {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for ch := range h.subs[e.BatchID] {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"devops-cd/internal/core/watch"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+	"strconv"
+)
+
+// Watch 批次状态变更推送（供 SSE 接口订阅）
+func (e *CoreEngine) Watch() *watch.Hub {
+	return e.watchHub
+}
+
+// registerWatchListeners 批次/发布应用/Deployment 状态变更后推送给订阅该批次的客户端
+func (e *CoreEngine) registerWatchListeners() {
+	e.batchSM.OnStatusChange(e.publishBatchWatch)
+	e.releaseSM.OnStatusChange(e.publishReleaseWatch)
+	e.deploymentSM.OnStatusChange(e.publishDeploymentWatch)
+}
+
+func (e *CoreEngine) publishBatchWatch(b model.Batch, from, to int8) {
+	if from == to {
+		return
+	}
+	e.watchHub.Publish(watch.Event{
+		Kind:       watch.KindBatch,
+		BatchID:    b.ID,
+		ID:         b.ID,
+		FromStatus: strconv.Itoa(int(from)),
+		Status:     strconv.Itoa(int(to)),
+		StatusName: constants.BatchStatusToString(to),
+	})
+}
+
+func (e *CoreEngine) publishReleaseWatch(r model.ReleaseApp, from, to int8) {
+	if from == to {
+		return
+	}
+	e.watchHub.Publish(watch.Event{
+		Kind:       watch.KindReleaseApp,
+		BatchID:    r.BatchID,
+		ID:         r.ID,
+		AppID:      r.AppID,
+		FromStatus: strconv.Itoa(int(from)),
+		Status:     strconv.Itoa(int(to)),
+		Reason:     r.Reason,
+	})
+}
+
+func (e *CoreEngine) publishDeploymentWatch(dep model.Deployment, from, to string) {
+	event := watch.Event{
+		Kind:       watch.KindDeployment,
+		BatchID:    dep.BatchID,
+		ID:         dep.ID,
+		AppID:      dep.AppID,
+		Env:        dep.Env,
+		Cluster:    dep.ClusterName,
+		FromStatus: from,
+		Status:     to,
+	}
+	if dep.ErrorMessage != nil {
+		event.Reason = *dep.ErrorMessage
+	}
+	e.watchHub.Publish(event)
+}
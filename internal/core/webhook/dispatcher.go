@@ -0,0 +1,251 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/crypto"
+	"devops-cd/pkg/constants"
+
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// 请求头
+const (
+	HeaderEvent     = "X-DevOps-CD-Event"
+	HeaderDelivery  = "X-DevOps-CD-Delivery"
+	HeaderTimestamp = "X-DevOps-CD-Timestamp"
+	HeaderSignature = "X-DevOps-CD-Signature"
+)
+
+const (
+	DefaultMaxAttempts = 6
+	DefaultTimeout     = 10 * time.Second
+
+	baseBackoff     = 30 * time.Second
+	maxBackoff      = time.Hour
+	dueBatchSize    = 50
+	maxResponseBody = 2048
+)
+
+// Payload 投递内容
+type Payload struct {
+	Event      string      `json:"event"`
+	ProjectID  int64       `json:"project_id"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// Dispatcher 出站 Webhook 投递器
+//
+// Emit 只写入 pending 投递记录，实际发送由 DeliverDue 在引擎扫描周期中完成；
+// 失败按 30s·2^(n-1)（上限 1h）退避重试，超过最大次数后标记 failed
+type Dispatcher struct {
+	db          *gorm.DB
+	logger      *zap.Logger
+	client      *http.Client
+	maxAttempts int
+}
+
+// NewDispatcher maxAttempts/timeout <= 0 时使用默认值
+func NewDispatcher(db *gorm.DB, logger *zap.Logger, maxAttempts int, timeout time.Duration) *Dispatcher {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Dispatcher{
+		db:          db,
+		logger:      logger,
+		client:      &http.Client{Timeout: timeout},
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Emit 为订阅了该事件的启用中 Webhook 写入投递记录
+func (d *Dispatcher) Emit(ctx context.Context, event string, projectID int64, data interface{}) {
+	var hooks []model.Webhook
+	if err := d.db.WithContext(ctx).Where("enabled = ? AND (project_id IS NULL OR project_id = ?)", true, projectID).
+		Find(&hooks).Error; err != nil {
+		d.logger.Error(fmt.Sprintf("[Webhook] 查询订阅失败 event=%s: %v", event, err))
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(Payload{Event: event, ProjectID: projectID, OccurredAt: time.Now(), Data: data})
+	if err != nil {
+		d.logger.Error(fmt.Sprintf("[Webhook] 序列化事件失败 event=%s: %v", event, err))
+		return
+	}
+
+	now := time.Now()
+	for i := range hooks {
+		if !hooks[i].Subscribed(event) {
+			continue
+		}
+		delivery := &model.WebhookDelivery{
+			WebhookID:     hooks[i].ID,
+			Event:         event,
+			Payload:       datatypes.JSON(body),
+			Status:        constants.WebhookDeliveryPending,
+			NextAttemptAt: &now,
+		}
+		if err := d.db.WithContext(ctx).Create(delivery).Error; err != nil {
+			d.logger.Error(fmt.Sprintf("[Webhook] 写入投递记录失败 webhook=%d event=%s: %v", hooks[i].ID, event, err))
+		}
+	}
+}
+
+// DeliverDue 投递到期的 pending 记录
+func (d *Dispatcher) DeliverDue(ctx context.Context) {
+	var due []model.WebhookDelivery
+	if err := d.db.WithContext(ctx).Where("status = ? AND next_attempt_at <= ?", constants.WebhookDeliveryPending, time.Now()).
+		Order("next_attempt_at").Limit(dueBatchSize).Find(&due).Error; err != nil {
+		d.logger.Error(fmt.Sprintf("[Webhook] 查询待投递记录失败: %v", err))
+		return
+	}
+
+	for i := range due {
+		if !d.claim(ctx, &due[i]) {
+			continue
+		}
+		var hook model.Webhook
+		if err := d.db.WithContext(ctx).First(&hook, due[i].WebhookID).Error; err != nil {
+			// 订阅已删除：不再重试
+			d.finish(ctx, &due[i], 0, "", fmt.Sprintf("webhook 不存在: %v", err), true)
+			continue
+		}
+		_ = d.deliver(ctx, &hook, &due[i], true)
+	}
+}
+
+// DeliverOnce 立即投递一次（测试投递），失败不重试
+func (d *Dispatcher) DeliverOnce(ctx context.Context, hook *model.Webhook, delivery *model.WebhookDelivery) error {
+	return d.deliver(ctx, hook, delivery, false)
+}
+
+// deliver 执行一次投递并更新记录；retry 为 true 时失败按退避策略安排重试
+func (d *Dispatcher) deliver(ctx context.Context, hook *model.Webhook, delivery *model.WebhookDelivery, retry bool) error {
+	secret := ""
+	if hook.EncryptedSecret != nil && *hook.EncryptedSecret != "" {
+		plain, err := crypto.DecryptWithKeyID(*hook.EncryptedSecret, hook.SecretKeyID)
+		if err != nil {
+			d.finish(ctx, delivery, 0, "", fmt.Sprintf("解密签名密钥失败: %v", err), true)
+			return err
+		}
+		secret = plain
+	}
+
+	code, body, err := d.post(ctx, hook.URL, secret, delivery)
+	if err == nil && (code < 200 || code >= 300) {
+		err = fmt.Errorf("HTTP %d", code)
+	}
+	if err != nil {
+		d.finish(ctx, delivery, code, body, err.Error(), !retry)
+		d.logger.Warn(fmt.Sprintf("[Webhook] 投递失败 webhook=%d delivery=%d event=%s attempts=%d: %v",
+			hook.ID, delivery.ID, delivery.Event, delivery.Attempts, err))
+		return err
+	}
+	d.finish(ctx, delivery, code, body, "", false)
+	return nil
+}
+
+func (d *Dispatcher) post(ctx context.Context, url, secret string, delivery *model.WebhookDelivery) (int, string, error) {
+	body := []byte(delivery.Payload)
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, ts)
+	if secret != "" {
+		req.Header.Set(HeaderSignature, Sign(secret, ts, body))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	return resp.StatusCode, string(respBody), nil
+}
+
+// claim 乐观锁占用投递记录（推迟 next_attempt_at），避免多实例重复投递
+func (d *Dispatcher) claim(ctx context.Context, delivery *model.WebhookDelivery) bool {
+	lease := time.Now().Add(d.client.Timeout + baseBackoff)
+	result := d.db.WithContext(ctx).Model(&model.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at = ?", delivery.ID, constants.WebhookDeliveryPending, delivery.NextAttemptAt).
+		Update("next_attempt_at", lease)
+	if result.Error != nil {
+		d.logger.Error(fmt.Sprintf("[Webhook] 占用投递记录失败 delivery=%d: %v", delivery.ID, result.Error))
+		return false
+	}
+	if result.RowsAffected == 0 {
+		return false
+	}
+	delivery.NextAttemptAt = &lease
+	return true
+}
+
+// finish 记录本次投递结果；giveUp 为 true 时直接标记 failed
+func (d *Dispatcher) finish(ctx context.Context, delivery *model.WebhookDelivery, code int, body, errMsg string, giveUp bool) {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.ResponseCode = code
+	delivery.ResponseBody = body
+	delivery.Error = errMsg
+	switch {
+	case errMsg == "":
+		delivery.Status = constants.WebhookDeliverySuccess
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+	case giveUp || delivery.Attempts >= d.maxAttempts:
+		delivery.Status = constants.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+	default:
+		delivery.Status = constants.WebhookDeliveryPending
+		next := now.Add(Backoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+	}
+	if err := d.db.WithContext(ctx).Save(delivery).Error; err != nil {
+		d.logger.Error(fmt.Sprintf("[Webhook] 更新投递记录失败 delivery=%d: %v", delivery.ID, err))
+	}
+}
+
+// Backoff 第 attempts 次失败后的重试间隔：30s·2^(attempts-1)，上限 1h
+func Backoff(attempts int) time.Duration {
+	wait := baseBackoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxBackoff)
+}
+
+// Sign 计算签名：sha256=hex(HMAC-SHA256(secret, timestamp + "." + body))
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
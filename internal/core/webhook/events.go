@@ -0,0 +1,39 @@
+package webhook
+
+import "devops-cd/pkg/constants"
+
+// 批次状态 → 事件
+var batchEvents = map[int8]string{
+	constants.BatchStatusSealed:         constants.WebhookEventBatchSealed,
+	constants.BatchStatusPreDeployed:    constants.WebhookEventBatchPreDeployed,
+	constants.BatchStatusProdWaiting:    constants.WebhookEventBatchProdStarted,
+	constants.BatchStatusProdDeployed:   constants.WebhookEventBatchProdDeployed,
+	constants.BatchStatusCompleted:      constants.WebhookEventBatchCompleted,
+	constants.BatchStatusCancelled:      constants.WebhookEventBatchCancelled,
+	constants.BatchStatusRolledBack:     constants.WebhookEventBatchRolledBack,
+	constants.BatchStatusPreFailed:      constants.WebhookEventBatchFailed,
+	constants.BatchStatusProdFailed:     constants.WebhookEventBatchFailed,
+	constants.BatchStatusRollbackFailed: constants.WebhookEventBatchFailed,
+}
+
+// BatchEvent 批次进入 to 状态时触发的事件
+func BatchEvent(to int8) (string, bool) {
+	event, ok := batchEvents[to]
+	return event, ok
+}
+
+// 发布应用状态 → 部署事件与阶段
+var releaseEvents = map[int8][2]string{
+	constants.ReleaseAppStatusPreDeployed:    {constants.WebhookEventDeploySuccess, "pre"},
+	constants.ReleaseAppStatusPreFailed:      {constants.WebhookEventDeployFailed, "pre"},
+	constants.ReleaseAppStatusProdDeployed:   {constants.WebhookEventDeploySuccess, "prod"},
+	constants.ReleaseAppStatusProdFailed:     {constants.WebhookEventDeployFailed, "prod"},
+	constants.ReleaseAppStatusRolledBack:     {constants.WebhookEventDeploySuccess, "rollback"},
+	constants.ReleaseAppStatusRollbackFailed: {constants.WebhookEventDeployFailed, "rollback"},
+}
+
+// ReleaseEvent 发布应用进入 to 状态时触发的部署事件，stage 为 pre/prod/rollback
+func ReleaseEvent(to int8) (event, stage string, ok bool) {
+	e, ok := releaseEvents[to]
+	return e[0], e[1], ok
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"context"
+	"devops-cd/internal/core/webhook"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/config"
+	"devops-cd/pkg/constants"
+	"fmt"
+	"time"
+)
+
+// newWebhookDispatcher 按 core.webhook 配置创建出站 Webhook 投递器
+func newWebhookDispatcher(e *CoreEngine, coreCfg *config.CoreConfig) *webhook.Dispatcher {
+	maxAttempts := 0
+	var timeout time.Duration
+	if coreCfg != nil {
+		maxAttempts = coreCfg.Webhook.MaxAttempts
+		if coreCfg.Webhook.Timeout != "" {
+			d, err := time.ParseDuration(coreCfg.Webhook.Timeout)
+			if err != nil {
+				e.logger.Warn(fmt.Sprintf("[Webhook] timeout 配置无效, 使用默认值: %v", err))
+			}
+			timeout = d
+		}
+	}
+	return webhook.NewDispatcher(e.db, e.logger, maxAttempts, timeout)
+}
+
+// Webhooks 出站 Webhook 投递器（供管理接口测试投递）
+func (e *CoreEngine) Webhooks() *webhook.Dispatcher {
+	return e.webhooks
+}
+
+// registerWebhookListeners 批次/发布应用状态变更后写入出站 Webhook 投递记录
+func (e *CoreEngine) registerWebhookListeners() {
+	e.batchSM.OnStatusChange(e.emitBatchEvent)
+	e.releaseSM.OnStatusChange(e.emitReleaseEvent)
+}
+
+func (e *CoreEngine) emitBatchEvent(b model.Batch, from, to int8) {
+	event, ok := webhook.BatchEvent(to)
+	if !ok || from == to {
+		return
+	}
+	e.webhooks.Emit(context.TODO(), event, b.ProjectID, map[string]interface{}{
+		"batch_id":     b.ID,
+		"batch_number": b.BatchNumber,
+		"initiator":    b.Initiator,
+		"from_status":  constants.BatchStatusToString(from),
+		"status":       constants.BatchStatusToString(to),
+	})
+}
+
+func (e *CoreEngine) emitReleaseEvent(r model.ReleaseApp, from, to int8) {
+	event, stage, ok := webhook.ReleaseEvent(to)
+	if !ok || from == to {
+		return
+	}
+	var b model.Batch
+	if err := e.db.Select("id", "batch_number", "project_id").First(&b, r.BatchID).Error; err != nil {
+		e.logger.Error(fmt.Sprintf("[Webhook] 查询批次失败 batch=%d: %v", r.BatchID, err))
+		return
+	}
+	var app model.Application
+	if err := e.db.Select("id", "name").First(&app, r.AppID).Error; err != nil {
+		e.logger.Error(fmt.Sprintf("[Webhook] 查询应用失败 app=%d: %v", r.AppID, err))
+		return
+	}
+	e.webhooks.Emit(context.TODO(), event, b.ProjectID, map[string]interface{}{
+		"batch_id":              b.ID,
+		"batch_number":          b.BatchNumber,
+		"release_app_id":        r.ID,
+		"app_id":                app.ID,
+		"app_name":              app.Name,
+		"stage":                 stage,
+		"target_tag":            r.TargetTag,
+		"previous_deployed_tag": r.PreviousDeployedTag,
+		"reason":                r.Reason,
+	})
+}
+
+// deliverWebhooks 异步投递到期记录；上一轮未结束时跳过，避免慢端点阻塞扫描
+func (e *CoreEngine) deliverWebhooks() {
+	if !e.webhookDelivering.CompareAndSwap(false, true) {
+		return
+	}
+	e.goWork(func() {
+		defer e.webhookDelivering.Store(false)
+		e.webhooks.DeliverDue(e.workCtx)
+	})
+}
@@ -0,0 +1,40 @@
+package dto
+
+// CreateAdhocDeploymentRequest 发起临时部署请求（build_id 与 image_tag 二选一，同时指定时以 build_id 为准）
+type CreateAdhocDeploymentRequest struct {
+	AppID    int64    `json:"app_id" binding:"required,gt=0" example:"1"`
+	Env      string   `json:"env" binding:"required,oneof=pre prod" example:"prod"`
+	Clusters []string `json:"clusters"` // 为空表示应用在该环境启用的全部集群
+	BuildID  int64    `json:"build_id" example:"100"`
+	ImageTag string   `json:"image_tag" binding:"max=100" example:"v1.2.3-hotfix"`
+	Reason   string   `json:"reason" binding:"required,max=500" example:"线上故障紧急修复"`
+}
+
+// AdhocDeploymentListRequest 临时部署列表请求
+type AdhocDeploymentListRequest struct {
+	ProjectID int64  `form:"project_id" binding:"required,gt=0" example:"1"`
+	AppID     int64  `form:"app_id" example:"1"`
+	Env       string `form:"env" binding:"omitempty,oneof=pre prod"`
+	Status    string `form:"status" binding:"omitempty,oneof=running success failed"`
+	Page      int    `form:"page" example:"1"`
+	PageSize  int    `form:"page_size" example:"10"`
+}
+
+// AdhocDeploymentResponse 临时部署响应
+type AdhocDeploymentResponse struct {
+	ID           int64                `json:"id"`
+	ProjectID    int64                `json:"project_id"`
+	AppID        int64                `json:"app_id"`
+	AppName      string               `json:"app_name"`
+	Env          string               `json:"env"`
+	BuildID      int64                `json:"build_id"`
+	ImageTag     string               `json:"image_tag"`
+	Clusters     []string             `json:"clusters"`
+	Status       string               `json:"status"` // running/success/failed
+	Reason       string               `json:"reason"`
+	ErrorMessage *string              `json:"error_message,omitempty"`
+	CreatedBy    string               `json:"created_by"`
+	CreatedAt    string               `json:"created_at"`
+	FinishedAt   *string              `json:"finished_at,omitempty"`
+	Deployments  []DeploymentResponse `json:"deployments,omitempty"` // 仅详情/创建时返回
+}
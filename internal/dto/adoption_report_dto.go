@@ -0,0 +1,56 @@
+package dto
+
+import "time"
+
+// AdoptionReportQuery 平台使用情况报表查询
+type AdoptionReportQuery struct {
+	ProjectID *int64     `form:"project_id"`                     // 为空时返回有权限的所有项目
+	TeamID    *int64     `form:"team_id"`                        // 指定团队时仅返回该团队所属项目及该团队
+	Start     *time.Time `form:"start" time_format:"2006-01-02"` // 默认 90 天前
+	End       *time.Time `form:"end" time_format:"2006-01-02"`   // 默认今天（含）
+}
+
+// AdoptionStats 平台使用指标
+type AdoptionStats struct {
+	BatchesCreated   int `json:"batches_created"`   // 区间内创建的批次数
+	BatchesCompleted int `json:"batches_completed"` // 区间内最终验收的批次数
+	AppsOnboarded    int `json:"apps_onboarded"`    // 区间内新接入的应用数
+	AppsTotal        int `json:"apps_total"`        // 当前接入的应用总数
+
+	// 批次状态变更动作（来自 batch_events 审计）
+	ManualActions    int     `json:"manual_actions"`    // 用户通过 API 操作
+	ScheduledActions int     `json:"scheduled_actions"` // 定时部署触发
+	AutoActions      int     `json:"auto_actions"`      // 引擎自动推进
+	AutomationRate   float64 `json:"automation_rate"`   // (scheduled + auto) / 全部动作
+
+	ActiveUsers    int        `json:"active_users"` // 发起批次或手动操作的去重用户数
+	LastActivityAt *time.Time `json:"last_activity_at"`
+
+	// 审批时延：区间内创建且已通过审批的批次，审批时间 - 创建时间
+	Approvals                 int     `json:"approvals"`
+	ApprovalLatencyAvgMinutes float64 `json:"approval_latency_avg_minutes"`
+	ApprovalLatencyP50Minutes float64 `json:"approval_latency_p50_minutes"`
+	ApprovalLatencyP90Minutes float64 `json:"approval_latency_p90_minutes"`
+}
+
+// TeamAdoptionReport 单个团队的使用情况（批次按包含该团队应用归属，一个批次可计入多个团队）
+type TeamAdoptionReport struct {
+	TeamID   int64  `json:"team_id"`
+	TeamName string `json:"team_name"`
+	AdoptionStats
+}
+
+// ProjectAdoptionReport 单个项目的使用情况
+type ProjectAdoptionReport struct {
+	ProjectID   int64  `json:"project_id"`
+	ProjectName string `json:"project_name"`
+	AdoptionStats
+	Teams []*TeamAdoptionReport `json:"teams"`
+}
+
+// AdoptionReportResponse 平台使用情况报表
+type AdoptionReportResponse struct {
+	Start    string                   `json:"start"`
+	End      string                   `json:"end"`
+	Projects []*ProjectAdoptionReport `json:"projects"`
+}
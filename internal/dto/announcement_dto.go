@@ -0,0 +1,43 @@
+package dto
+
+import "time"
+
+// CreateAnnouncementRequest 创建公告请求
+type CreateAnnouncementRequest struct {
+	Level    string     `json:"level" binding:"required,oneof=info warning freeze" example:"freeze"`
+	Title    string     `json:"title" binding:"required,max=200" example:"双十一发布冻结"`
+	Content  string     `json:"content" example:"11-10 ~ 11-12 期间禁止生产发布"`
+	Enabled  *bool      `json:"enabled" example:"true"`
+	StartsAt *time.Time `json:"starts_at" example:"2025-11-10T00:00:00+08:00"`
+	EndsAt   *time.Time `json:"ends_at" example:"2025-11-13T00:00:00+08:00"`
+}
+
+// UpdateAnnouncementRequest 更新公告请求
+type UpdateAnnouncementRequest struct {
+	Level    *string    `json:"level" binding:"omitempty,oneof=info warning freeze"`
+	Title    *string    `json:"title" binding:"omitempty,max=200"`
+	Content  *string    `json:"content"`
+	Enabled  *bool      `json:"enabled"`
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+}
+
+// AnnouncementListRequest 公告列表请求（管理端）
+type AnnouncementListRequest struct {
+	Page     int `form:"page" example:"1"`
+	PageSize int `form:"page_size" example:"10"`
+}
+
+// AnnouncementResponse 公告响应
+type AnnouncementResponse struct {
+	ID        int64      `json:"id"`
+	Level     string     `json:"level"`
+	Title     string     `json:"title"`
+	Content   string     `json:"content"`
+	Enabled   bool       `json:"enabled"`
+	StartsAt  *time.Time `json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at"`
+	CreatedBy string     `json:"created_by"`
+	CreatedAt string     `json:"created_at"`
+	UpdatedAt string     `json:"updated_at"`
+}
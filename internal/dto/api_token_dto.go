@@ -0,0 +1,43 @@
+package dto
+
+import "time"
+
+// CreateAPITokenRequest 创建 API Token 请求
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name" binding:"required,max=100" example:"release-dashboard"`
+	Description   string   `json:"description" binding:"max=500"`
+	Scopes        []string `json:"scopes" binding:"required,min=1" example:"batch:view"` // 只读权限，见 GET /api/v1/admin/api_tokens/scopes
+	ProjectIDs    []int64  `json:"project_ids"`                                          // 限定项目，为空表示不限
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=3650"`   // 有效天数，为空表示不过期
+}
+
+// APITokenListRequest API Token 列表请求
+type APITokenListRequest struct {
+	IncludeRevoked bool `form:"include_revoked"`
+	Page           int  `form:"page" example:"1"`
+	PageSize       int  `form:"page_size" example:"10"`
+}
+
+// APITokenResponse API Token（不含明文）
+type APITokenResponse struct {
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	TokenPrefix string     `json:"token_prefix"`
+	Scopes      []string   `json:"scopes"`
+	ProjectIDs  []int64    `json:"project_ids"`
+	Status      string     `json:"status"` // active / expired / revoked
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	LastUsedIP  string     `json:"last_used_ip"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+	RevokedBy   string     `json:"revoked_by"`
+	CreatedBy   string     `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CreateAPITokenResponse 创建结果，token 明文只返回这一次
+type CreateAPITokenResponse struct {
+	APITokenResponse
+	Token string `json:"token"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+import "devops-cd/internal/model"
+
+// CreateAppDecommissionRequest 发起应用下线请求
+type CreateAppDecommissionRequest struct {
+	AppID     int64  `json:"app_id" binding:"required,gt=0" example:"1"`
+	Uninstall bool   `json:"uninstall" example:"true"` // 卸载应用在各环境/集群的 release，false 时仅归档构建并删除应用
+	Reason    string `json:"reason" binding:"required,max=500" example:"服务合并，下线旧应用"`
+}
+
+// AppDecommissionListRequest 应用下线记录列表请求
+type AppDecommissionListRequest struct {
+	ProjectID int64  `form:"project_id" binding:"required,gt=0" example:"1"`
+	AppID     int64  `form:"app_id" example:"1"`
+	Status    string `form:"status" binding:"omitempty,oneof=running success failed"`
+	Page      int    `form:"page" example:"1"`
+	PageSize  int    `form:"page_size" example:"10"`
+}
+
+// AppDecommissionResponse 应用下线响应
+type AppDecommissionResponse struct {
+	ID             int64                     `json:"id"`
+	ProjectID      int64                     `json:"project_id"`
+	AppID          int64                     `json:"app_id"`
+	AppName        string                    `json:"app_name"`
+	Uninstall      bool                      `json:"uninstall"`
+	Targets        model.DecommissionTargets `json:"targets"` // 需要卸载的 release 及卸载结果
+	Status         string                    `json:"status"`  // running/success/failed
+	ArchivedBuilds int64                     `json:"archived_builds"`
+	Reason         string                    `json:"reason"`
+	ErrorMessage   *string                   `json:"error_message,omitempty"`
+	CreatedBy      string                    `json:"created_by"`
+	CreatedAt      string                    `json:"created_at"`
+	FinishedAt     *string                   `json:"finished_at,omitempty"`
+}
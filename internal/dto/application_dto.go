@@ -1,30 +1,44 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"devops-cd/internal/model"
+)
 
 // CreateApplicationRequest 创建应用请求
 type CreateApplicationRequest struct {
-	Name        string              `json:"name" binding:"required,max=100"`
-	DisplayName *string             `json:"display_name"`
-	Description *string             `json:"description"`
-	ProjectID   *int64              `json:"project_id"` // 可选：如果不指定则从 Repository 继承
-	RepoID      int64               `json:"repo_id" binding:"required"`
-	AppType     string              `json:"app_type" binding:"required,oneof=static node java go py"`
-	TeamID      *int64              `json:"team_id"`
-	EnvClusters map[string][]string `json:"env_clusters,omitempty"` // 环境集群配置，用于初始化 app_env_configs 表
+	Name          string               `json:"name" binding:"required,max=100"`
+	DisplayName   *string              `json:"display_name"`
+	Description   *string              `json:"description"`
+	ProjectID     *int64               `json:"project_id"` // 可选：如果不指定则从 Repository 继承
+	RepoID        int64                `json:"repo_id" binding:"required"`
+	AppType       string               `json:"app_type" binding:"required,oneof=static node java go py"`
+	TeamID        *int64               `json:"team_id"`
+	EnvClusters   map[string][]string  `json:"env_clusters,omitempty"`   // 环境集群配置，用于初始化 app_env_configs 表
+	TagFilter     *model.TagFilter     `json:"tag_filter,omitempty"`     // 构建过滤规则（正则），为空表示接受所有成功构建
+	PathFilter    *model.PathFilter    `json:"path_filter,omitempty"`    // 单仓多应用路径映射（glob），为空表示任意变更都匹配
+	RegionRollout *model.RegionRollout `json:"region_rollout,omitempty"` // 多 region 部署协调，为空表示所有集群并行部署
+	DeployVerify  *model.DeployVerify  `json:"deploy_verify,omitempty"`  // 部署后验证，为空表示 workload 就绪即成功
+	AutoRollback  *bool                `json:"auto_rollback,omitempty"`  // 生产就绪/验证失败自动回滚失败集群，为空继承项目配置
 }
 
 // UpdateApplicationRequest 更新应用请求
 type UpdateApplicationRequest struct {
-	ID          int64               `json:"id" binding:"required"` // 必填：应用ID
-	Name        *string             `json:"name" binding:"omitempty,max=100"`
-	DisplayName *string             `json:"display_name"`
-	Description *string             `json:"description"`
-	AppType     *string             `json:"app_type" binding:"omitempty,oneof=static node java go py"`
-	TeamID      *int64              `json:"team_id"`
-	DeployedTag *string             `json:"deployed_tag"`           // 当前部署的镜像标签
-	EnvClusters map[string][]string `json:"env_clusters,omitempty"` // 环境集群配置，用于同步更新 app_env_configs 表
-	Status      *int8               `json:"status" binding:"omitempty,oneof=0 1"`
+	ID            int64                `json:"id" binding:"required"` // 必填：应用ID
+	Name          *string              `json:"name" binding:"omitempty,max=100"`
+	DisplayName   *string              `json:"display_name"`
+	Description   *string              `json:"description"`
+	AppType       *string              `json:"app_type" binding:"omitempty,oneof=static node java go py"`
+	TeamID        *int64               `json:"team_id"`
+	DeployedTag   *string              `json:"deployed_tag"`                                                     // 当前部署的镜像标签
+	EnvClusters   map[string][]string  `json:"env_clusters,omitempty"`                                           // 环境集群配置，用于同步更新 app_env_configs 表
+	TagFilter     *model.TagFilter     `json:"tag_filter,omitempty"`                                             // 构建过滤规则，传 {} 表示清空
+	PathFilter    *model.PathFilter    `json:"path_filter,omitempty"`                                            // 单仓多应用路径映射，传 {} 表示清空
+	RegionRollout *model.RegionRollout `json:"region_rollout,omitempty"`                                         // 多 region 部署协调，enabled=false 表示关闭
+	DeployVerify  *model.DeployVerify  `json:"deploy_verify,omitempty"`                                          // 部署后验证，enabled=false 表示关闭
+	AutoRollback  *string              `json:"auto_rollback" binding:"omitempty,oneof=inherit enabled disabled"` // 自动回滚：inherit 继承项目配置 / enabled / disabled
+	Status        *int8                `json:"status" binding:"omitempty,oneof=0 1"`
 }
 
 // GetApplicationRequest 获取应用详情请求
@@ -45,22 +59,27 @@ type GetApplicationBuildsRequest struct {
 
 // ApplicationResponse 应用响应
 type ApplicationResponse struct {
-	ID               int64               `json:"id"`
-	Name             string              `json:"name"`
-	Description      *string             `json:"description"`
-	ProjectID        int64               `json:"project_id"`             // 关联的项目ID
-	ProjectName      *string             `json:"project_name,omitempty"` // 项目名称
-	RepoID           int64               `json:"repo_id"`
-	RepoName         *string             `json:"repo_name,omitempty"` // Repository的namespace/name
-	TeamID           *int64              `json:"team_id"`
-	TeamName         *string             `json:"team_name,omitempty"` // 团队名称
-	AppType          string              `json:"app_type"`
-	DeployedTag      *string             `json:"deployed_tag"` // 当前部署的镜像标签
-	DefaultDependsOn []int64             `json:"default_depends_on"`
-	EnvClusters      map[string][]string `json:"env_clusters,omitempty"` // 环境集群配置，从 app_env_configs 表查询得出
-	Status           int8                `json:"status"`
-	CreatedAt        string              `json:"created_at"`
-	UpdatedAt        string              `json:"updated_at"`
+	ID               int64                `json:"id"`
+	Name             string               `json:"name"`
+	Description      *string              `json:"description"`
+	ProjectID        int64                `json:"project_id"`             // 关联的项目ID
+	ProjectName      *string              `json:"project_name,omitempty"` // 项目名称
+	RepoID           int64                `json:"repo_id"`
+	RepoName         *string              `json:"repo_name,omitempty"` // Repository的namespace/name
+	TeamID           *int64               `json:"team_id"`
+	TeamName         *string              `json:"team_name,omitempty"` // 团队名称
+	AppType          string               `json:"app_type"`
+	DeployedTag      *string              `json:"deployed_tag"` // 当前部署的镜像标签
+	DefaultDependsOn []int64              `json:"default_depends_on"`
+	EnvClusters      map[string][]string  `json:"env_clusters,omitempty"` // 环境集群配置，从 app_env_configs 表查询得出
+	TagFilter        *model.TagFilter     `json:"tag_filter"`             // 构建过滤规则
+	PathFilter       *model.PathFilter    `json:"path_filter"`            // 单仓多应用路径映射
+	RegionRollout    *model.RegionRollout `json:"region_rollout"`         // 多 region 部署协调
+	DeployVerify     *model.DeployVerify  `json:"deploy_verify"`          // 部署后验证
+	AutoRollback     *bool                `json:"auto_rollback"`          // 自动回滚，为空继承项目配置
+	Status           int8                 `json:"status"`
+	CreatedAt        string               `json:"created_at"`
+	UpdatedAt        string               `json:"updated_at"`
 }
 
 // ApplicationListQuery 应用列表查询参数
@@ -135,6 +154,22 @@ type UpdateAppDependenciesRequest struct {
 	Dependencies []int64 `json:"dependencies"`
 }
 
+// AppDeployDurationResponse 应用历史部署耗时（最近成功的 app Deployment，started_at → finished_at）
+type AppDeployDurationResponse struct {
+	AppID   int64                   `json:"app_id"`
+	AppName string                  `json:"app_name"`
+	History int                     `json:"history"` // 每个环境统计的最近成功部署条数上限
+	Envs    []AppDeployDurationItem `json:"envs"`
+}
+
+// AppDeployDurationItem 单个环境的历史部署耗时
+type AppDeployDurationItem struct {
+	Env        string `json:"env"`
+	Samples    int    `json:"samples"`
+	AvgSeconds int    `json:"avg_seconds"`
+	P95Seconds int    `json:"p95_seconds"`
+}
+
 // ApplicationDependenciesResponse 应用默认依赖响应
 type ApplicationDependenciesResponse struct {
 	AppID        int64   `json:"app_id"`
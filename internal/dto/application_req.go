@@ -8,6 +8,7 @@ type ApplicationSearchQuery struct {
 	RepoID    *int64   `form:"repo_id"`    // 可选：按代码库ID过滤
 	TeamIDs   []int64  `form:"team_ids"`   // 可选：按团队ID过滤（多选）
 	AppTypes  []string `form:"app_types"`  // 可选：按应用类型过滤（多选）
+	Query     string   `form:"q"`          // 可选：查询语句，如 team:payments type:java deployed_tag~1.4 status:enabled
 }
 
 type ApplicationSearchParam struct {
@@ -19,6 +20,7 @@ type ApplicationSearchParam struct {
 	ProjectID *int64
 	TeamIDs   []int64
 	AppTypes  []string
+	Query     string
 }
 
 func (q *ApplicationSearchQuery) ToParam() ApplicationSearchParam {
@@ -31,5 +33,6 @@ func (q *ApplicationSearchQuery) ToParam() ApplicationSearchParam {
 		ProjectID: q.ProjectID,
 		TeamIDs:   q.TeamIDs,
 		AppTypes:  q.AppTypes,
+		Query:     q.Query,
 	}
 }
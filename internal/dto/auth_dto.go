@@ -29,3 +29,15 @@ type UserInfo struct {
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" binding:"required"`
 }
+
+// OIDCAuthorizeResponse OIDC 授权地址
+type OIDCAuthorizeResponse struct {
+	AuthURL string `json:"auth_url"` // 浏览器跳转到该地址完成 IdP 登录
+	State   string `json:"state"`    // 前端暂存，回调时比对后原样提交
+}
+
+// OIDCCallbackRequest OIDC 回调请求（前端回调页取到的 code/state）
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
@@ -0,0 +1,92 @@
+package dto
+
+import (
+	"time"
+
+	"devops-cd/internal/model"
+)
+
+// BatchBundleVersion 批次导出包格式版本
+const BatchBundleVersion = 1
+
+// BatchBundle 批次导出包：已封板批次的应用、所选构建、依赖与 values 引用，按名称引用应用，可导入其他实例
+type BatchBundle struct {
+	Version      int              `json:"version" binding:"required,eq=1"`
+	ExportedAt   time.Time        `json:"exported_at"`
+	ExportedBy   string           `json:"exported_by"`
+	Project      string           `json:"project"` // 源项目名（仅供参考，导入时以请求中的项目为准）
+	BatchID      int64            `json:"batch_id"`
+	BatchNumber  string           `json:"batch_number" binding:"required"`
+	ReleaseNotes *string          `json:"release_notes"`
+	SealedAt     *time.Time       `json:"sealed_at"`
+	Apps         []BatchBundleApp `json:"apps" binding:"required,min=1,dive"`
+}
+
+// BatchBundleApp 导出包中的发布应用
+type BatchBundleApp struct {
+	Name             string            `json:"name" binding:"required"` // 应用名（导入时在目标项目内按名称映射）
+	Repo             string            `json:"repo"`                    // 代码库 namespace/name
+	ReleaseNotes     *string           `json:"release_notes"`
+	PreOnly          bool              `json:"pre_only"`
+	TempDependsOn    []string          `json:"temp_depends_on"`    // 批次内临时依赖（应用名）
+	DefaultDependsOn []string          `json:"default_depends_on"` // 源实例的配置级依赖（应用名，仅用于导入时比对）
+	Build            *BatchBundleBuild `json:"build" binding:"required"`
+	ValuesOverride   *string           `json:"values_override"` // 发布应用 values 覆盖
+
+	// values 引用（仅供参考，导入后封板时按目标实例重新解析）
+	Charts      map[string]*model.ChartLockEntry `json:"charts,omitempty"`       // 封板时锁定的 chart 版本/digest
+	ManifestRef *BatchBundleManifestRef          `json:"manifest_ref,omitempty"` // 封板时锁定的代码库清单
+}
+
+// BatchBundleBuild 导出包中的构建（导入时按构建号/镜像 tag/commit 匹配目标实例的构建）
+type BatchBundleBuild struct {
+	BuildNumber   int        `json:"build_number" binding:"required"`
+	BuildStatus   string     `json:"build_status"`
+	BuildEvent    string     `json:"build_event"`
+	BuildLink     string     `json:"build_link"`
+	CommitSHA     string     `json:"commit_sha" binding:"required"`
+	CommitRef     string     `json:"commit_ref"`
+	CommitBranch  string     `json:"commit_branch"`
+	CommitMessage string     `json:"commit_message"`
+	CommitLink    string     `json:"commit_link"`
+	CommitAuthor  string     `json:"commit_author"`
+	BuildCreated  time.Time  `json:"build_created"`
+	BuildStarted  time.Time  `json:"build_started"`
+	BuildFinished time.Time  `json:"build_finished"`
+	ImageTag      string     `json:"image_tag" binding:"required"`
+	ImageURL      string     `json:"image_url"`
+	ImageDigest   *string    `json:"image_digest"`
+	ImagePushedAt *time.Time `json:"image_pushed_at"`
+}
+
+// BatchBundleManifestRef 封板时锁定的代码库清单引用
+type BatchBundleManifestRef struct {
+	Ref  string `json:"ref"`
+	File string `json:"file"`
+}
+
+// ImportBatchRequest 导入批次导出包为目标项目的草稿批次
+type ImportBatchRequest struct {
+	ProjectID        int64       `json:"project_id" binding:"required"` // 目标项目
+	BatchNumber      *string     `json:"batch_number"`                  // 为空时使用导出包中的批次编号
+	DependsOnBatchID *int64      `json:"depends_on_batch_id"`           // 前置批次ID（可选）
+	CreateBuilds     bool        `json:"create_builds"`                 // 目标实例没有对应构建时按导出包创建构建记录
+	Bundle           BatchBundle `json:"bundle"`
+}
+
+// ImportBatchApp 导入结果中的应用映射
+type ImportBatchApp struct {
+	Name         string `json:"name"`
+	AppID        int64  `json:"app_id"`
+	BuildID      int64  `json:"build_id"`
+	ImageTag     string `json:"image_tag"`
+	BuildCreated bool   `json:"build_created"` // 是否按导出包新建了构建记录
+}
+
+// ImportBatchResponse 导入批次结果
+type ImportBatchResponse struct {
+	BatchID     int64            `json:"batch_id"`
+	BatchNumber string           `json:"batch_number"`
+	Apps        []ImportBatchApp `json:"apps"`
+	Warnings    []string         `json:"warnings"` // 与源实例不一致但不影响导入的项（如配置级依赖不同）
+}
@@ -0,0 +1,48 @@
+package dto
+
+import "time"
+
+// CreateBatchCommentRequest 发表批次评论请求
+type CreateBatchCommentRequest struct {
+	ReleaseAppID *int64 `json:"release_app_id"` // 评论发布应用时传入（需属于该批次）
+	Content      string `json:"content" binding:"required,max=5000" example:"payments-api 因数据库迁移暂缓发布"`
+}
+
+// UpdateBatchCommentRequest 编辑批次评论请求
+type UpdateBatchCommentRequest struct {
+	Content string `json:"content" binding:"required,max=5000"`
+}
+
+// BatchCommentListRequest 批次评论列表请求
+type BatchCommentListRequest struct {
+	ReleaseAppID *int64 `form:"release_app_id"` // 只看某个发布应用的评论
+}
+
+// BatchCommentResponse 批次评论
+type BatchCommentResponse struct {
+	ID           int64     `json:"id"`
+	BatchID      int64     `json:"batch_id"`
+	ReleaseAppID *int64    `json:"release_app_id"`
+	AppID        *int64    `json:"app_id"`
+	AppName      string    `json:"app_name,omitempty"`
+	Author       string    `json:"author"`
+	Content      string    `json:"content"`
+	Edited       bool      `json:"edited"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// BatchActivityItem 批次动态（评论、审批、状态变更、手动操作按时间顺序合并）
+type BatchActivityItem struct {
+	Type      string    `json:"type"` // comment / approval / transition / action
+	Time      time.Time `json:"time"`
+	Actor     string    `json:"actor"` // 操作人，引擎自动推进时为空
+	Summary   string    `json:"summary"`
+	Detail    *string   `json:"detail,omitempty"` // 评论内容、审批意见、变更原因等
+	AppID     *int64    `json:"app_id,omitempty"`
+	AppName   string    `json:"app_name,omitempty"`
+	RefID     int64     `json:"ref_id"` // 对应记录 ID（batch_comments / batch_approvals / batch_events / deployment_events）
+	Trigger   string    `json:"trigger,omitempty"`
+	FromState string    `json:"from_state,omitempty"`
+	ToState   string    `json:"to_state,omitempty"`
+}
@@ -1,6 +1,10 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"devops-cd/internal/model"
+)
 
 // BatchResponse 批次响应
 type BatchResponse struct {
@@ -18,6 +22,21 @@ type BatchResponse struct {
 	ApprovalStatus string `json:"approval_status"`
 	AppCount       int64  `json:"app_count"` // 应用数量
 
+	// 批次依赖
+	DependsOnBatchID *int64 `json:"depends_on_batch_id,omitempty"` // 前置批次ID
+	BlockedReason    string `json:"blocked_reason,omitempty"`      // 前置批次未完成时的阻塞原因
+
+	// 定时部署
+	ScheduledPreAt  *string `json:"scheduled_pre_at,omitempty"`
+	ScheduledPreBy  *string `json:"scheduled_pre_by,omitempty"`
+	ScheduledProdAt *string `json:"scheduled_prod_at,omitempty"`
+	ScheduledProdBy *string `json:"scheduled_prod_by,omitempty"`
+
+	// 镜像漏洞门禁豁免
+	VulnOverrideAt     *string `json:"vuln_override_at,omitempty"`
+	VulnOverrideBy     *string `json:"vuln_override_by,omitempty"`
+	VulnOverrideReason *string `json:"vuln_override_reason,omitempty"`
+
 	// 审批信息
 	ApprovedBy   *string `json:"approved_by,omitempty"`
 	ApprovedAt   *string `json:"approved_at,omitempty"`
@@ -39,6 +58,9 @@ type BatchResponse struct {
 	CancelledBy  *string `json:"cancelled_by,omitempty"`
 	CancelReason *string `json:"cancel_reason,omitempty"`
 
+	// 引擎暂停（项目/批次被暂停时返回生效中的暂停记录）
+	EnginePause *EnginePauseResponse `json:"engine_pause,omitempty"`
+
 	// 系统字段
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
@@ -49,6 +71,7 @@ type BatchDetailResponse struct {
 	BatchResponse
 	Apps           []ReleaseAppResponse         `json:"apps"`
 	TotalApps      int64                        `json:"total_apps"`    // 应用总数
+	PreOnlyApps    int64                        `json:"pre_only_apps"` // 仅预发布验证的应用数（不发布生产）
 	AppPage        int                          `json:"app_page"`      // 当前页码
 	AppPageSize    int                          `json:"app_page_size"` // 每页数量
 	AppTypeConfigs map[string]AppTypeConfigInfo `json:"app_type_configs,omitempty"`
@@ -72,9 +95,13 @@ type ReleaseAppResponse struct {
 	BuildID *int64 `json:"build_id,omitempty"` // 关联的构建ID
 
 	// 版本信息
-	LatestBuildID       *int64  `json:"latest_build_id"`                 // 最新检测到的构建ID（新tag到达时更新）
-	PreviousDeployedTag *string `json:"previous_deployed_tag,omitempty"` // 部署前的版本（封板时记录）
-	TargetTag           *string `json:"target_tag,omitempty"`            // 目标部署版本（封板时固定，部署期间代表期望版本，部署完成后代表已部署版本）
+	LatestBuildID       *int64              `json:"latest_build_id"`                 // 最新检测到的构建ID（新tag到达时更新）
+	PreviousDeployedTag *string             `json:"previous_deployed_tag,omitempty"` // 部署前的版本（封板时记录）
+	TargetTag           *string             `json:"target_tag,omitempty"`            // 目标部署版本（封板时固定，部署期间代表期望版本，部署完成后代表已部署版本）
+	ChartLock           *model.ChartLock    `json:"chart_lock,omitempty"`            // 封板时锁定的 chart 版本/digest（pre 与 prod 共用）
+	ManifestLock        *model.ManifestLock `json:"manifest_lock,omitempty"`         // 封板时锁定的代码库清单（含与平台配置的冲突项）
+	IssueKeys           []string            `json:"issue_keys"`                      // 关联 issue（封板后从本次发布的提交说明解析）
+	ValuesOverride      *string             `json:"values_override,omitempty"`       // values 覆盖（封板前设置，部署时最后合并）
 
 	// 应用信息
 	AppName     string  `json:"app_name"`
@@ -105,9 +132,16 @@ type ReleaseAppResponse struct {
 	ReleaseNotes *string  `json:"release_notes,omitempty"` // 应用级发布说明
 	IsLocked     bool     `json:"is_locked"`               // 是否已锁定（封板后为true）
 	SkipPreEnv   bool     `json:"skip_pre_env"`            // 是否跳过预发布环境（封板时从app_env_configs计算得出）
+	PreOnly      bool     `json:"pre_only"`                // 仅预发布验证（不发布生产）
+	SkipProdEnv  bool     `json:"skip_prod_env"`           // 是否跳过生产环境（pre_only 或未配置生产环境，封板时计算得出）
+	Wave         int      `json:"wave"`                    // 部署波次（封板时按依赖计算，封板前为 0）
 	Reasons      []string `json:"reasons,omitempty"`
 	Status       int8     `json:"status"`
 
+	// 生产灰度
+	RolloutStage  int `json:"rollout_stage,omitempty"`  // 当前已放行的阶段
+	RolloutStages int `json:"rollout_stages,omitempty"` // 总阶段数，<=1 表示不分阶段
+
 	// 时间信息
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
@@ -127,20 +161,36 @@ type ReleaseAppResponse struct {
 type DeploymentResponse struct {
 	ID int64 `json:"id"`
 
-	BatchID   int64 `json:"batch_id"`
-	ReleaseID int64 `json:"release_id"`
-	AppID     int64 `json:"app_id"`
+	BatchID   int64  `json:"batch_id"`
+	ReleaseID int64  `json:"release_id"`
+	AppID     int64  `json:"app_id"`
+	AdhocID   *int64 `json:"adhoc_id,omitempty"` // 临时部署ID（临时部署时 batch_id/release_id 为 0）
 
-	Env            string  `json:"env"` // pre/prod
+	Kind           string  `json:"kind"` // app/config
+	Env            string  `json:"env"`  // pre/prod
 	ClusterName    string  `json:"cluster_name"`
 	Namespace      string  `json:"namespace"`
 	DeploymentName string  `json:"deployment_name"`
 	DriverType     *string `json:"driver_type,omitempty"`
+	ChartVersion   *string `json:"chart_version,omitempty"`
 	Status         string  `json:"status"` // pending/running/success/failed
 	RetryCount     int     `json:"retry_count"`
 	MaxRetryCount  int     `json:"max_retry_count"`
+	LastFailedAt   *string `json:"last_failed_at,omitempty"` // 最近一次失败时间（重试退避起点）
+	RolloutStage   int     `json:"rollout_stage"`            // 灰度阶段
 	ErrorMessage   *string `json:"error_message,omitempty"`
 
+	// prod 部署前 server-side dry-run 结果
+	DryRunStatus  *string `json:"dry_run_status,omitempty"` // passed/failed
+	DryRunMessage *string `json:"dry_run_message,omitempty"`
+	DryRunAt      *string `json:"dry_run_at,omitempty"`
+
+	GitRevision *string `json:"git_revision,omitempty"` // gitops driver 提交的 commit
+	ChartDigest *string `json:"chart_digest,omitempty"` // 部署的 chart digest
+
+	SlowAlertedAt       *string `json:"slow_alerted_at,omitempty"`       // 运行时长异常告警时间（晚于 started_at 表示本次运行已告警）
+	ExpectedDurationSec *int    `json:"expected_duration_sec,omitempty"` // 告警时的历史 P95 耗时（秒）
+
 	StartedAt  *string `json:"started_at,omitempty"`
 	FinishedAt *string `json:"finished_at,omitempty"`
 	CreatedAt  string  `json:"created_at"`
@@ -174,6 +224,23 @@ type UpdateReleaseDependenciesRequest struct {
 	ReleaseAppID  int64   `json:"-"`
 }
 
+// UpdateReleaseValuesOverrideRequest 设置发布应用 values 覆盖请求
+type UpdateReleaseValuesOverrideRequest struct {
+	BatchID        int64  `json:"batch_id" binding:"required"`
+	ValuesOverride string `json:"values_override" example:"replicaCount: 3"` // YAML/JSON object，为空表示清除覆盖
+	ReleaseAppID   int64  `json:"-"`
+	Operator       string `json:"-"`
+}
+
+// ReleaseValuesOverrideResponse 发布应用 values 覆盖响应
+type ReleaseValuesOverrideResponse struct {
+	BatchID        int64   `json:"batch_id"`
+	ReleaseAppID   int64   `json:"release_app_id"`
+	AppID          int64   `json:"app_id"`
+	ValuesOverride *string `json:"values_override"`
+	UpdatedAt      string  `json:"updated_at"`
+}
+
 // ReleaseDependenciesResponse 发布应用依赖响应
 type ReleaseDependenciesResponse struct {
 	BatchID          int64   `json:"batch_id"`
@@ -225,6 +292,10 @@ type BatchStatusResponse struct {
 	StatusName     string `json:"status_name"`
 	ApprovalStatus string `json:"approval_status"`
 
+	// 批次依赖
+	DependsOnBatchID *int64 `json:"depends_on_batch_id,omitempty"` // 前置批次ID
+	BlockedReason    string `json:"blocked_reason,omitempty"`      // 前置批次未完成时的阻塞原因
+
 	// Batch 时间节点
 	SealedAt             *string `json:"sealed_at,omitempty"`
 	PreDeployStartedAt   *string `json:"pre_deploy_started_at,omitempty"`
@@ -235,9 +306,19 @@ type BatchStatusResponse struct {
 	CancelledAt          *string `json:"cancelled_at,omitempty"`
 	UpdatedAt            string  `json:"updated_at"`
 
+	// 引擎暂停（项目/批次被暂停时返回生效中的暂停记录）
+	EnginePause *EnginePauseResponse `json:"engine_pause,omitempty"`
+
+	// 部署波次（封板时按批次内依赖拓扑分层，同一波次的应用可并行部署）
+	Waves       []BatchWaveProgress `json:"waves,omitempty"`
+	WaveStage   string              `json:"wave_stage,omitempty"` // 波次进度统计的阶段：pre / prod
+	CurrentWave int                 `json:"current_wave"`         // 进行中的波次，0 表示未开始或已全部完成
+	TotalWaves  int                 `json:"total_waves"`
+
 	// Release Apps 状态列表（不关联其他表）
 	Apps        []ReleaseAppStatusResponse `json:"apps"`
 	TotalApps   int64                      `json:"total_apps"`
+	PreOnlyApps int64                      `json:"pre_only_apps"` // 仅预发布验证的应用数（不发布生产）
 	AppPage     int                        `json:"app_page"`
 	AppPageSize int                        `json:"app_page_size"`
 }
@@ -249,11 +330,25 @@ type ReleaseAppStatusResponse struct {
 	Status        int8           `json:"status"`                    // 应用发布状态
 	IsLocked      bool           `json:"is_locked"`                 // 是否已锁定
 	SkipPreEnv    bool           `json:"skip_pre_env"`              // 是否跳过预发布环境
+	PreOnly       bool           `json:"pre_only"`                  // 仅预发布验证（不发布生产）
+	SkipProdEnv   bool           `json:"skip_prod_env"`             // 是否跳过生产环境
+	Wave          int            `json:"wave"`                      // 部署波次（封板前为 0）
 	BuildID       *int64         `json:"build_id,omitempty"`        // 构建 ID
 	LatestBuildID *int64         `json:"latest_build_id,omitempty"` // 最新构建 ID
 	RecentBuilds  []BuildSummary `json:"recent_builds,omitempty"`   // 最近的构建记录
 }
 
+// BatchWaveProgress 部署波次进度
+type BatchWaveProgress struct {
+	Wave      int     `json:"wave"`
+	Status    string  `json:"status"` // pending/running/completed/failed
+	Total     int     `json:"total"`
+	Completed int     `json:"completed"`
+	Running   int     `json:"running"`
+	Failed    int     `json:"failed"`
+	AppIDs    []int64 `json:"app_ids"`
+}
+
 // GetReleaseAppRequest 获取发布应用详情请求
 type GetReleaseAppRequest struct {
 	ID int64 `form:"id" binding:"required"` // 发布应用ID
@@ -267,3 +362,47 @@ func FormatTime(t *time.Time) *string {
 	formatted := t.Format(time.RFC3339)
 	return &formatted
 }
+
+// BatchApprovalResponse 批次审批进度
+type BatchApprovalResponse struct {
+	BatchID        int64                 `json:"batch_id"`
+	ApprovalStatus string                `json:"approval_status"`
+	MultiStage     bool                  `json:"multi_stage"`   // 项目是否配置了多人/分阶段审批
+	CurrentStage   *int                  `json:"current_stage"` // 当前审批阶段序号，审批结束时为空
+	Stages         []BatchApprovalStage  `json:"stages"`
+	Records        []BatchApprovalRecord `json:"records"`
+}
+
+// BatchApprovalStage 审批阶段进度
+type BatchApprovalStage struct {
+	Stage      int      `json:"stage"`
+	Name       string   `json:"name"`
+	Approvers  []string `json:"approvers"`
+	Required   int      `json:"required"`
+	ApprovedBy []string `json:"approved_by"`
+	Status     string   `json:"status"` // waiting/pending/approved/rejected
+}
+
+// BatchApprovalRecord 审批记录
+type BatchApprovalRecord struct {
+	Stage     int     `json:"stage"`
+	StageName string  `json:"stage_name"`
+	Approver  string  `json:"approver"`
+	Decision  string  `json:"decision"` // approved/rejected
+	Comment   *string `json:"comment"`
+	CreatedAt string  `json:"created_at"`
+}
+
+// PendingApprovalItem 等待当前用户审批的批次
+type PendingApprovalItem struct {
+	BatchID     int64  `json:"batch_id"`
+	BatchNumber string `json:"batch_number"`
+	ProjectID   int64  `json:"project_id"`
+	ProjectName string `json:"project_name"`
+	Initiator   string `json:"initiator"`
+	Stage       int    `json:"stage"`
+	StageName   string `json:"stage_name"`
+	Approved    int    `json:"approved"` // 当前阶段已通过人数
+	Required    int    `json:"required"`
+	CreatedAt   string `json:"created_at"`
+}
@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"time"
+
+	"devops-cd/internal/model"
+)
+
+// BatchImpactItem 单个应用在某环境集群上的部署影响
+type BatchImpactItem struct {
+	AppID       int64                `json:"app_id"`
+	AppName     string               `json:"app_name"`
+	Env         string               `json:"env"`
+	ClusterName string               `json:"cluster_name"`
+	Namespace   string               `json:"namespace"`
+	ReleaseName string               `json:"release_name"`
+	Before      *model.ResourceUsage `json:"before"`
+	After       *model.ResourceUsage `json:"after"`
+	Delta       *model.ResourceUsage `json:"delta"` // before/after 均已采集时才有值
+	Error       *string              `json:"error,omitempty"`
+	CollectedAt *time.Time           `json:"collected_at"` // after 采集时间
+}
+
+// BatchImpactClusterSummary 按环境集群汇总的部署影响
+type BatchImpactClusterSummary struct {
+	Env         string              `json:"env"`
+	ClusterName string              `json:"cluster_name"`
+	Apps        int                 `json:"apps"`
+	Before      model.ResourceUsage `json:"before"`
+	After       model.ResourceUsage `json:"after"`
+	Delta       model.ResourceUsage `json:"delta"`
+}
+
+// BatchImpactResponse 批次部署影响
+type BatchImpactResponse struct {
+	BatchID     int64                        `json:"batch_id"`
+	BatchNumber string                       `json:"batch_number"`
+	Status      string                       `json:"status"` // pending: 批次未完成或尚未采集完；collected: 已采集完成
+	Items       []*BatchImpactItem           `json:"items"`
+	Clusters    []*BatchImpactClusterSummary `json:"clusters"` // 仅统计 before/after 均已采集的记录
+	Total       model.ResourceUsage          `json:"total_delta"`
+}
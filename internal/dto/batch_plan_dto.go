@@ -0,0 +1,42 @@
+package dto
+
+// BatchPlanResponse 批次部署计划预览（不修改任何状态）
+type BatchPlanResponse struct {
+	BatchID     int64          `json:"batch_id"`
+	BatchNumber string         `json:"batch_number"`
+	Valid       bool           `json:"valid"`  // 所有应用/集群的计划均解析成功且依赖无循环
+	Apps        []BatchPlanApp `json:"apps"`   // 按发布应用 ID 排序
+	Order       [][]int64      `json:"order"`  // 依赖顺序（app_id 分层，同层可并行）
+	Errors      []string       `json:"errors"` // 批次级错误（如依赖循环）
+}
+
+// BatchPlanApp 单个应用的部署计划
+type BatchPlanApp struct {
+	ReleaseAppID int64             `json:"release_app_id"`
+	AppID        int64             `json:"app_id"`
+	AppName      string            `json:"app_name"`
+	AppType      string            `json:"app_type"`
+	BuildID      *int64            `json:"build_id"`
+	BuildNumber  int               `json:"build_number,omitempty"`
+	ImageTag     *string           `json:"image_tag"`
+	PreOnly      bool              `json:"pre_only"`
+	SkipPreEnv   bool              `json:"skip_pre_env"`
+	SkipProdEnv  bool              `json:"skip_prod_env"`
+	DependsOn    []int64           `json:"depends_on"` // 批次内生效的依赖（默认依赖 + 临时依赖）
+	Targets      []BatchPlanTarget `json:"targets"`
+	Errors       []string          `json:"errors"`
+}
+
+// BatchPlanTarget 应用在单个环境/集群上的部署计划
+type BatchPlanTarget struct {
+	Env            string                 `json:"env"`
+	Cluster        string                 `json:"cluster"`
+	RolloutStage   int                    `json:"rollout_stage,omitempty"` // prod 灰度阶段
+	Kind           string                 `json:"kind"`                    // app / config
+	DriverType     string                 `json:"driver_type"`
+	Namespace      string                 `json:"namespace"`
+	DeploymentName string                 `json:"deployment_name"`
+	ChartName      string                 `json:"chart_name,omitempty"`
+	ChartVersion   string                 `json:"chart_version,omitempty"`
+	Values         map[string]interface{} `json:"values,omitempty"` // 合并后的 values，敏感字段已脱敏
+}
@@ -28,6 +28,7 @@ type BatchListParam struct {
 	CreatedAtStart *time.Time
 	CreatedAtEnd   *time.Time
 	Keyword        *string
+	ProjectIDs     []int64 // 非空时只返回这些项目的批次（API Token 限定项目）
 }
 
 func (q *BatchListQuery) ToParam() BatchListParam {
@@ -60,18 +61,21 @@ func (q *BatchListQuery) ToParam() BatchListParam {
 type CreateBatchApp struct {
 	AppID        int64   `json:"app_id" binding:"required"` // 应用ID
 	ReleaseNotes *string `json:"release_notes"`             // 应用级发布说明（可选）
+	PreOnly      bool    `json:"pre_only"`                  // 仅在预发布环境验证，Pre 验收后即完成（可选）
 }
 
 // CreateBatchRequest 创建批次请求
 type CreateBatchRequest struct {
-	BatchNumber  string  `json:"batch_number" binding:"required"` // 批次编号/标题，用户填写
-	ProjectID    int64   `json:"project_id" binding:"required"`   // 关联的项目ID
-	ReleaseNotes *string `json:"release_notes"`                   // 批次级发布说明（可选）
+	BatchNumber      string  `json:"batch_number" binding:"required"` // 批次编号/标题，用户填写
+	ProjectID        int64   `json:"project_id" binding:"required"`   // 关联的项目ID
+	ReleaseNotes     *string `json:"release_notes"`                   // 批次级发布说明（可选）
+	DependsOnBatchID *int64  `json:"depends_on_batch_id"`             // 前置批次ID（可选），前置批次完成后才能开始生产部署
 }
 
 type CreateBatchParam struct {
-	BatchNumber  string
-	ReleaseNotes *string
+	BatchNumber      string
+	ReleaseNotes     *string
+	DependsOnBatchID *int64
 
 	ProjectID int64
 	Operator  string
@@ -80,9 +84,10 @@ type CreateBatchParam struct {
 
 func (q *CreateBatchRequest) ToParam() CreateBatchParam {
 	return CreateBatchParam{
-		BatchNumber:  q.BatchNumber,
-		ReleaseNotes: q.ReleaseNotes,
-		ProjectID:    q.ProjectID,
+		BatchNumber:      q.BatchNumber,
+		ReleaseNotes:     q.ReleaseNotes,
+		DependsOnBatchID: q.DependsOnBatchID,
+		ProjectID:        q.ProjectID,
 	}
 }
 
@@ -92,18 +97,22 @@ func (q *CreateBatchRequest) ToParam() CreateBatchParam {
 type UpdateBatchRequest struct {
 	BatchID int64 `json:"batch_id" binding:"required"`
 	// Operator     string           `json:"operator" binding:"required"`
-	BatchNumber  *string          `json:"batch_number"`
-	ReleaseNotes *string          `json:"release_notes"`
-	AddApps      []CreateBatchApp `json:"add_apps"` // 新增应用
-	RemoveAppIDs []int64          `json:"remove_app_ids"`
+	BatchNumber      *string          `json:"batch_number"`
+	ReleaseNotes     *string          `json:"release_notes"`
+	AddApps          []CreateBatchApp `json:"add_apps"` // 新增应用
+	RemoveAppIDs     []int64          `json:"remove_app_ids"`
+	PreOnlyApps      map[int64]bool   `json:"pre_only_apps"`       // 修改已有应用的 pre_only 标记, key: app_id
+	DependsOnBatchID *int64           `json:"depends_on_batch_id"` // 修改前置批次，传 0 表示移除
 }
 
 type UpdateBatchParam struct {
-	BatchID      int64
-	BatchNumber  *string
-	ReleaseNotes *string
-	AddApps      []CreateBatchApp
-	RemoveAppIDs []int64
+	BatchID          int64
+	BatchNumber      *string
+	ReleaseNotes     *string
+	AddApps          []CreateBatchApp
+	RemoveAppIDs     []int64
+	PreOnlyApps      map[int64]bool
+	DependsOnBatchID *int64
 
 	Operator  string
 	CanUpdate func(username string, projectId int64) bool
@@ -111,11 +120,13 @@ type UpdateBatchParam struct {
 
 func (q *UpdateBatchRequest) ToParam() UpdateBatchParam {
 	return UpdateBatchParam{
-		BatchID:      q.BatchID,
-		BatchNumber:  q.BatchNumber,
-		ReleaseNotes: q.ReleaseNotes,
-		AddApps:      q.AddApps,
-		RemoveAppIDs: q.RemoveAppIDs,
+		BatchID:          q.BatchID,
+		BatchNumber:      q.BatchNumber,
+		ReleaseNotes:     q.ReleaseNotes,
+		AddApps:          q.AddApps,
+		RemoveAppIDs:     q.RemoveAppIDs,
+		PreOnlyApps:      q.PreOnlyApps,
+		DependsOnBatchID: q.DependsOnBatchID,
 	}
 }
 
@@ -0,0 +1,109 @@
+package dto
+
+import (
+	"time"
+)
+
+// CreateBatchIncidentRequest 记录批次发布后故障
+type CreateBatchIncidentRequest struct {
+	Severity        string     `json:"severity" binding:"required,oneof=sev1 sev2 sev3 sev4"`
+	Title           string     `json:"title" binding:"required,max=200"`
+	Description     *string    `json:"description"`
+	StartedAt       *time.Time `json:"started_at"`                                  // 为空时取当前时间
+	DurationMinutes int        `json:"duration_minutes" binding:"min=0,max=525600"` // 故障持续时长（分钟）
+	AppIDs          []int64    `json:"app_ids"`                                     // 相关应用，需在批次内
+}
+
+// CreateBatchRollbackRequest 记录批次发布后回滚
+type CreateBatchRollbackRequest struct {
+	AppID        int64      `json:"app_id" binding:"required"`
+	Env          string     `json:"env" binding:"required,oneof=pre prod"`
+	FromTag      *string    `json:"from_tag"` // 为空时取批次目标版本
+	ToTag        *string    `json:"to_tag"`   // 为空时取批次发布前版本
+	Reason       *string    `json:"reason"`
+	RolledBackAt *time.Time `json:"rolled_back_at"` // 为空时取当前时间
+}
+
+// BatchIncidentResponse 故障记录
+type BatchIncidentResponse struct {
+	ID              int64     `json:"id"`
+	BatchID         int64     `json:"batch_id"`
+	Severity        string    `json:"severity"`
+	Title           string    `json:"title"`
+	Description     *string   `json:"description"`
+	StartedAt       time.Time `json:"started_at"`
+	DurationMinutes int       `json:"duration_minutes"`
+	AppIDs          []int64   `json:"app_ids"`
+	CreatedBy       string    `json:"created_by"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// BatchRollbackResponse 回滚记录
+type BatchRollbackResponse struct {
+	ID           int64     `json:"id"`
+	BatchID      int64     `json:"batch_id"`
+	AppID        int64     `json:"app_id"`
+	AppName      string    `json:"app_name"`
+	Env          string    `json:"env"`
+	FromTag      *string   `json:"from_tag"`
+	ToTag        *string   `json:"to_tag"`
+	Reason       *string   `json:"reason"`
+	RolledBackAt time.Time `json:"rolled_back_at"`
+	CreatedBy    string    `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// 平台执行的回滚
+	ReleaseID    *int64     `json:"release_id,omitempty"`
+	Status       string     `json:"status"` // recorded/running/success/failed
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	ErrorMessage *string    `json:"error_message,omitempty"`
+}
+
+// BatchRetroResponse 批次复盘数据
+type BatchRetroResponse struct {
+	BatchID   int64                    `json:"batch_id"`
+	Failed    bool                     `json:"failed"` // 有故障或回滚即视为变更失败
+	Incidents []*BatchIncidentResponse `json:"incidents"`
+	Rollbacks []*BatchRollbackResponse `json:"rollbacks"`
+}
+
+// QualityReportQuery 发布质量报表查询
+type QualityReportQuery struct {
+	ProjectID *int64     `form:"project_id"`                                    // 为空时返回有权限的所有项目
+	Start     *time.Time `form:"start" time_format:"2006-01-02"`                // 默认 90 天前
+	End       *time.Time `form:"end" time_format:"2006-01-02"`                  // 默认今天（含）
+	Interval  string     `form:"interval" binding:"omitempty,oneof=week month"` // 趋势粒度，默认 week
+}
+
+// QualityStats 发布质量指标
+type QualityStats struct {
+	Batches           int            `json:"batches"`             // 已完成批次数
+	FailedBatches     int            `json:"failed_batches"`      // 有故障或回滚的批次数
+	ChangeFailureRate float64        `json:"change_failure_rate"` // failed_batches / batches
+	Incidents         int            `json:"incidents"`
+	IncidentsBySev    map[string]int `json:"incidents_by_severity"`
+	Rollbacks         int            `json:"rollbacks"`
+	MTTRMinutes       float64        `json:"mttr_minutes"` // 平均故障恢复时长
+}
+
+// QualityTrendPoint 趋势数据点
+type QualityTrendPoint struct {
+	Period string `json:"period"` // 周期起始日期（周一 / 月初）
+	QualityStats
+}
+
+// ProjectQualityReport 单个项目的发布质量
+type ProjectQualityReport struct {
+	ProjectID   int64  `json:"project_id"`
+	ProjectName string `json:"project_name"`
+	QualityStats
+	Trend []*QualityTrendPoint `json:"trend"`
+}
+
+// QualityReportResponse 发布质量报表
+type QualityReportResponse struct {
+	Start    string                  `json:"start"`
+	End      string                  `json:"end"`
+	Interval string                  `json:"interval"`
+	Projects []*ProjectQualityReport `json:"projects"`
+}
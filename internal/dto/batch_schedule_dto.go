@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+// SetBatchScheduleRequest 设置批次定时部署
+type SetBatchScheduleRequest struct {
+	Env         string    `json:"env" binding:"required,oneof=pre prod"` // pre: 定时开始预发布; prod: 定时开始生产部署
+	ScheduledAt time.Time `json:"scheduled_at" binding:"required"`       // 计划触发时间（RFC3339），需晚于当前时间
+}
+
+// CancelBatchScheduleQuery 取消批次定时部署
+type CancelBatchScheduleQuery struct {
+	Env string `form:"env" binding:"required,oneof=pre prod"`
+}
+
+// BatchScheduleResponse 批次定时部署
+type BatchScheduleResponse struct {
+	BatchID         int64   `json:"batch_id"`
+	Status          int8    `json:"status"`
+	ScheduledPreAt  *string `json:"scheduled_pre_at,omitempty"`
+	ScheduledPreBy  *string `json:"scheduled_pre_by,omitempty"`
+	ScheduledProdAt *string `json:"scheduled_prod_at,omitempty"`
+	ScheduledProdBy *string `json:"scheduled_prod_by,omitempty"`
+}
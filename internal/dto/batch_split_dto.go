@@ -0,0 +1,29 @@
+package dto
+
+// SplitBatchRequest 拆分批次：将选中的发布应用移入新建的草稿批次
+type SplitBatchRequest struct {
+	AppIDs           []int64 `json:"app_ids" binding:"required,min=1"` // 移出的应用ID
+	BatchNumber      string  `json:"batch_number" binding:"required"`  // 新批次编号
+	ReleaseNotes     *string `json:"release_notes"`                    // 新批次发布说明（可选）
+	DependsOnBatchID *int64  `json:"depends_on_batch_id"`              // 新批次前置批次ID（可选）
+}
+
+// SplitBatchResponse 拆分批次结果
+type SplitBatchResponse struct {
+	BatchID       int64   `json:"batch_id"` // 新批次ID
+	BatchNumber   string  `json:"batch_number"`
+	SourceBatchID int64   `json:"source_batch_id"`
+	AppIDs        []int64 `json:"app_ids"` // 移入新批次的应用ID
+}
+
+// MergeBatchRequest 合并批次：将另一个草稿批次的发布应用并入当前批次
+type MergeBatchRequest struct {
+	SourceBatchID int64 `json:"source_batch_id" binding:"required"` // 被合并的批次ID，合并后删除
+}
+
+// MergeBatchResponse 合并批次结果
+type MergeBatchResponse struct {
+	BatchID       int64   `json:"batch_id"`
+	SourceBatchID int64   `json:"source_batch_id"`
+	AppIDs        []int64 `json:"app_ids"` // 并入的应用ID
+}
@@ -0,0 +1,70 @@
+package dto
+
+import "time"
+
+// BatchTemplateAppItem 模板中的应用
+type BatchTemplateAppItem struct {
+	AppID         int64   `json:"app_id" binding:"required"`
+	PreOnly       bool    `json:"pre_only"`        // 仅在预发布环境验证
+	ReleaseNotes  *string `json:"release_notes"`   // 应用级发布说明脚手架（Go template）
+	TempDependsOn []int64 `json:"temp_depends_on"` // 批次内临时依赖，只能引用模板内应用
+}
+
+// CreateBatchTemplateRequest 创建批次模板
+//
+// batch_number / release_notes 为 Go template，可用变量：
+// {{.Project}} 项目名、{{.Template}} 模板名、{{.Date}} 当天日期（2006-01-02）、{{.Apps}} 应用名列表
+type CreateBatchTemplateRequest struct {
+	ProjectID    int64                  `json:"project_id" binding:"required"`
+	Name         string                 `json:"name" binding:"required,max=100"`
+	Description  *string                `json:"description"`
+	BatchNumber  *string                `json:"batch_number" binding:"omitempty,max=200"` // 批次编号模板，为空时为「模板名-日期」
+	ReleaseNotes *string                `json:"release_notes"`                            // 批次级发布说明脚手架
+	Apps         []BatchTemplateAppItem `json:"apps" binding:"required,min=1,dive"`
+}
+
+// UpdateBatchTemplateRequest 更新批次模板（字段为空表示不修改）
+type UpdateBatchTemplateRequest struct {
+	Name         *string                `json:"name" binding:"omitempty,max=100"`
+	Description  *string                `json:"description"`
+	BatchNumber  *string                `json:"batch_number" binding:"omitempty,max=200"`
+	ReleaseNotes *string                `json:"release_notes"`
+	Apps         []BatchTemplateAppItem `json:"apps" binding:"omitempty,min=1,dive"` // 整体替换
+}
+
+// BatchTemplateQuery 批次模板列表查询
+type BatchTemplateQuery struct {
+	ProjectID int64 `form:"project_id" binding:"required"`
+}
+
+// BatchTemplateResponse 批次模板
+type BatchTemplateResponse struct {
+	ID           int64                  `json:"id"`
+	ProjectID    int64                  `json:"project_id"`
+	Name         string                 `json:"name"`
+	Description  *string                `json:"description"`
+	BatchNumber  *string                `json:"batch_number"`
+	ReleaseNotes *string                `json:"release_notes"`
+	Apps         []BatchTemplateAppItem `json:"apps"`
+	CreatedBy    string                 `json:"created_by"`
+	UpdatedBy    string                 `json:"updated_by"`
+	CreatedAt    time.Time              `json:"created_at"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+}
+
+// CreateBatchFromTemplateRequest 根据模板创建草稿批次
+type CreateBatchFromTemplateRequest struct {
+	TemplateID       int64   `json:"template_id" binding:"required"`
+	BatchNumber      *string `json:"batch_number"`        // 为空时按模板渲染
+	ReleaseNotes     *string `json:"release_notes"`       // 为空时按模板渲染
+	DependsOnBatchID *int64  `json:"depends_on_batch_id"` // 前置批次ID（可选）
+	ExcludeAppIDs    []int64 `json:"exclude_app_ids"`     // 本次不发布的模板应用
+}
+
+// CreateBatchFromTemplateResponse 根据模板创建批次结果
+type CreateBatchFromTemplateResponse struct {
+	BatchID     int64   `json:"batch_id"`
+	BatchNumber string  `json:"batch_number"`
+	TemplateID  int64   `json:"template_id"`
+	AppIDs      []int64 `json:"app_ids"`
+}
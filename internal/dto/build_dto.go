@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 // BuildNotifyRequest 构建通知请求（来自 Drone webhook）
 type BuildNotifyRequest struct {
 	// ========== 仓库信息 ==========
@@ -34,7 +36,13 @@ type BuildNotifyRequest struct {
 	CommitLink    string  `json:"commit_link" binding:"omitempty,url"` // 提交链接（可选）
 
 	// ========== 应用列表 ==========
-	Apps []BuildNotifyApp `json:"apps" binding:"required,min=1,dive"` // 至少一个应用
+	// 不为空时按列表创建构建记录；为空时按应用路径映射（path_filter）将本次构建分发到代码库下变更路径匹配的应用
+	Apps []BuildNotifyApp `json:"apps" binding:"omitempty,dive"`
+
+	// ========== 按路径分发（apps 为空时使用） ==========
+	ImageTag     string   `json:"image_tag" binding:"omitempty,max=100"`      // 各应用的镜像 tag（apps 为空时必填）
+	Image        string   `json:"image"`                                      // 可选：镜像地址，{app} 替换为应用名
+	ChangedFiles []string `json:"changed_files" binding:"omitempty,max=5000"` // 可选：变更文件，为空时按 commit_before...commit_id 查询代码库
 }
 
 // BuildNotifyApp 构建通知中的应用信息
@@ -45,6 +53,28 @@ type BuildNotifyApp struct {
 	BuildSuccess *bool   `json:"build_success"`                // 可选：该应用是否构建成功
 }
 
+// ImagePushRequest 镜像仓库推送通知（由 Harbor 等适配器转换）
+type ImagePushRequest struct {
+	Source       string              // 来源，如 harbor
+	Artifacts    []ImagePushArtifact // 推送的镜像
+	CreateBuilds bool                // 没有对应构建时是否按镜像地址历史构建创建新构建
+}
+
+// ImagePushArtifact 推送的单个镜像
+type ImagePushArtifact struct {
+	Image    string    // 镜像地址（不含 tag），如 harbor.example.com/project/app
+	Tag      string    // 镜像 tag
+	Digest   string    // manifest digest
+	PushedAt time.Time // 推送时间
+}
+
+// ImagePushResult 镜像推送通知处理结果
+type ImagePushResult struct {
+	UpdatedBuilds []int64  `json:"updated_builds"` // 标记为镜像可用的已有构建
+	CreatedBuilds []int64  `json:"created_builds"` // 新创建的构建
+	Ignored       []string `json:"ignored"`        // 无法关联应用的镜像
+}
+
 // BuildResponse 构建记录响应
 type BuildResponse struct {
 	ID       int64   `json:"id"`
@@ -75,6 +105,14 @@ type BuildResponse struct {
 	AppBuildSuccess bool   `json:"app_build_success"`
 	Environment     string `json:"environment"`
 
+	ImageDigest   *string `json:"image_digest"`    // 镜像仓库确认的 digest
+	ImagePushedAt *string `json:"image_pushed_at"` // 镜像推送时间（RFC3339），为空表示未收到镜像仓库通知
+
+	ProvenanceStatus  *string `json:"provenance_status"`  // 来源校验结果: verified/mismatch/unverified，未校验为空
+	ProvenanceMessage *string `json:"provenance_message"` // 校验说明
+
+	ArchivedAt *string `json:"archived_at,omitempty"` // 应用下线时归档的时间（RFC3339）
+
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
 }
@@ -82,13 +120,13 @@ type BuildResponse struct {
 // BuildListQuery 构建列表查询参数
 type BuildListQuery struct {
 	PageQuery           // 分页参数
-	RepoID      *int64  `form:"repo_id"`                                                                      // 按仓库筛选
-	AppID       *int64  `form:"app_id"`                                                                       // 按应用筛选
-	BuildStatus *string `form:"build_status" binding:"omitempty,oneof=success failure error killed"`          // 按状态筛选
-	BuildEvent  *string `form:"build_event" binding:"omitempty,oneof=push tag pull_request promote rollback"` // 按事件筛选
-	ImageTag    *string `form:"image_tag"`                                                                    // 按镜像标签筛选
-	CommitSHA   *string `form:"commit_sha"`                                                                   // 按 commit 查询
-	Environment *string `form:"environment"`                                                                  // 按环境筛选
+	RepoID      *int64  `form:"repo_id"`                                                                               // 按仓库筛选
+	AppID       *int64  `form:"app_id"`                                                                                // 按应用筛选
+	BuildStatus *string `form:"build_status" binding:"omitempty,oneof=success failure error killed"`                   // 按状态筛选
+	BuildEvent  *string `form:"build_event" binding:"omitempty,oneof=push tag pull_request promote rollback registry"` // 按事件筛选
+	ImageTag    *string `form:"image_tag"`                                                                             // 按镜像标签筛选
+	CommitSHA   *string `form:"commit_sha"`                                                                            // 按 commit 查询
+	Environment *string `form:"environment"`                                                                           // 按环境筛选
 }
 
 // GetBuildRequest 获取单个构建记录请求
@@ -1,10 +1,21 @@
 package dto
 
+import "devops-cd/internal/model"
+
 // ClusterCreateRequest 创建集群请求
 type ClusterCreateRequest struct {
 	Name        string  `json:"name" binding:"required,max=50" example:"cluster-prod-01"`
 	Description *string `json:"description" example:"华东区域生产集群"`
 	Region      *string `json:"region" binding:"omitempty,max=50" example:"cn-east-1"`
+
+	// 连接配置（kubeconfig/token 只写不读）
+	AuthType              string                   `json:"auth_type" binding:"omitempty,oneof=kubeconfig token exec" example:"token"`
+	Kubeconfig            string                   `json:"kubeconfig"`
+	Server                string                   `json:"server" binding:"omitempty,max=255" example:"https://10.0.0.1:6443"`
+	CAData                string                   `json:"ca_data"`
+	InsecureSkipTLSVerify bool                     `json:"insecure_skip_tls_verify"`
+	Token                 string                   `json:"token"`
+	Exec                  *model.ClusterExecConfig `json:"exec_config"`
 }
 
 // ClusterUpdateRequest 更新集群请求
@@ -13,6 +24,15 @@ type ClusterUpdateRequest struct {
 	Description *string `json:"description" example:"华东区域生产集群"`
 	Region      *string `json:"region" binding:"omitempty,max=50" example:"cn-east-1"`
 	//Status      *int8   `json:"status" binding:"omitempty,oneof=0 1" example:"1"`
+
+	// 连接配置：未传字段保持不变（kubeconfig/token 传空串表示清空）
+	AuthType              *string                  `json:"auth_type" binding:"omitempty,oneof=kubeconfig token exec" example:"token"`
+	Kubeconfig            *string                  `json:"kubeconfig"`
+	Server                *string                  `json:"server" binding:"omitempty,max=255" example:"https://10.0.0.1:6443"`
+	CAData                *string                  `json:"ca_data"`
+	InsecureSkipTLSVerify *bool                    `json:"insecure_skip_tls_verify"`
+	Token                 *string                  `json:"token"`
+	Exec                  *model.ClusterExecConfig `json:"exec_config"`
 }
 
 // ClusterResponse 集群响应
@@ -22,8 +42,15 @@ type ClusterResponse struct {
 	Description *string `json:"description"`
 	Region      *string `json:"region"`
 	//Status      int8    `json:"status"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	AuthType              string                   `json:"auth_type"`
+	Server                string                   `json:"server,omitempty"`
+	CAData                string                   `json:"ca_data,omitempty"`
+	InsecureSkipTLSVerify bool                     `json:"insecure_skip_tls_verify"`
+	Exec                  *model.ClusterExecConfig `json:"exec_config,omitempty"`
+	HasKubeconfig         bool                     `json:"has_kubeconfig"`
+	HasToken              bool                     `json:"has_token"`
+	CreatedAt             string                   `json:"created_at"`
+	UpdatedAt             string                   `json:"updated_at"`
 }
 
 // ClusterListRequest 集群列表请求
@@ -33,3 +60,28 @@ type ClusterListRequest struct {
 	Page     int `form:"page" example:"1"`
 	PageSize int `form:"page_size" example:"10"`
 }
+
+// ClusterPreflightRequest 集群预检请求（可选项为空时跳过对应检查）
+type ClusterPreflightRequest struct {
+	Namespace     string `json:"namespace" binding:"omitempty,max=63" example:"app-prod"`
+	ChartRepoURL  string `json:"chart_repo_url" example:"https://charts.example.com"`
+	CredentialRef string `json:"credential_ref" example:"id:1"`
+	Image         string `json:"image" example:"registry.example.com/demo/app:v1.0.0"`
+}
+
+// ClusterPreflightResponse 集群预检报告
+type ClusterPreflightResponse struct {
+	ClusterID   int64                   `json:"cluster_id"`
+	ClusterName string                  `json:"cluster_name"`
+	Passed      bool                    `json:"passed"`
+	Checks      []ClusterPreflightCheck `json:"checks"`
+}
+
+// ClusterPreflightCheck 单项检查结果
+type ClusterPreflightCheck struct {
+	Name       string `json:"name"` // kubeconfig_auth/namespace/chart_repo/registry_pull
+	Passed     bool   `json:"passed"`
+	Skipped    bool   `json:"skipped"`
+	Message    string `json:"message,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
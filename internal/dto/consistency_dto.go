@@ -0,0 +1,37 @@
+package dto
+
+// ConsistencyIssue 单条不一致记录
+type ConsistencyIssue struct {
+	Check      string `json:"check"`              // 检查项
+	Entity     string `json:"entity"`             // 表名
+	EntityID   int64  `json:"entity_id"`          // 记录ID
+	BatchID    *int64 `json:"batch_id,omitempty"` // 关联批次
+	Detail     string `json:"detail"`             // 说明
+	Repairable bool   `json:"repairable"`         // 是否支持自动修复
+}
+
+// ConsistencyReport 一致性检查报告
+type ConsistencyReport struct {
+	CheckedAt string             `json:"checked_at"`
+	Total     int                `json:"total"`
+	Counts    map[string]int     `json:"counts"` // key: 检查项
+	Issues    []ConsistencyIssue `json:"issues"`
+}
+
+// ConsistencyRepairRequest 一致性修复请求
+type ConsistencyRepairRequest struct {
+	Checks []string `json:"checks" binding:"required,min=1,dive,oneof=orphan_release_apps orphan_deployments release_status" example:"orphan_release_apps"`
+	DryRun *bool    `json:"dry_run" example:"true"` // 默认 true，仅返回将要修复的记录
+}
+
+// IsDryRun 未显式传 dry_run=false 时只预览不修改
+func (r *ConsistencyRepairRequest) IsDryRun() bool {
+	return r.DryRun == nil || *r.DryRun
+}
+
+// ConsistencyRepairResult 一致性修复结果
+type ConsistencyRepairResult struct {
+	DryRun   bool               `json:"dry_run"`
+	Repaired map[string]int     `json:"repaired"` // key: 检查项, value: 修复(或将修复)的记录数
+	Issues   []ConsistencyIssue `json:"issues"`
+}
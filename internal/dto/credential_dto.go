@@ -29,3 +29,12 @@ type CredentialResponse struct {
 	CreatedAt string          `json:"created_at"`
 	UpdatedAt string          `json:"updated_at"`
 }
+
+// CredentialReencryptResult 凭据重新加密迁移结果
+type CredentialReencryptResult struct {
+	TargetKeyID string  `json:"target_key_id"`
+	Scanned     int     `json:"scanned"`
+	Migrated    int     `json:"migrated"`
+	Skipped     int     `json:"skipped"` // 迁移期间被并发修改，跳过（下次执行会重新处理）
+	FailedIDs   []int64 `json:"failed_ids,omitempty"`
+}
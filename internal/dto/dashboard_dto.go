@@ -0,0 +1,43 @@
+package dto
+
+import "time"
+
+// DashboardBatchStatusCount 各状态的进行中批次数
+type DashboardBatchStatusCount struct {
+	Status     int8   `json:"status"`
+	StatusName string `json:"status_name"`
+	Count      int64  `json:"count"`
+}
+
+// DashboardClusterDeployments 单个集群进行中的部署数
+type DashboardClusterDeployments struct {
+	Cluster string `json:"cluster"`
+	Pending int64  `json:"pending"`
+	Running int64  `json:"running"`
+}
+
+// DashboardFailure 最近失败的部署
+type DashboardFailure struct {
+	DeploymentID int64      `json:"deployment_id"`
+	BatchID      int64      `json:"batch_id"`
+	BatchNumber  string     `json:"batch_number"`
+	ProjectID    int64      `json:"project_id"`
+	AppID        int64      `json:"app_id"`
+	AppName      string     `json:"app_name"`
+	Env          string     `json:"env"`
+	Cluster      string     `json:"cluster"`
+	Status       string     `json:"status"`
+	ErrorMessage string     `json:"error_message"`
+	FinishedAt   *time.Time `json:"finished_at"`
+}
+
+// DashboardResponse 首页汇总（只统计当前用户有权限的项目）
+type DashboardResponse struct {
+	ActiveBatches    int64                          `json:"active_batches"`
+	BatchesByStatus  []*DashboardBatchStatusCount   `json:"batches_by_status"`
+	InFlightDeploys  int64                          `json:"in_flight_deployments"`
+	DeploysByCluster []*DashboardClusterDeployments `json:"deployments_by_cluster"`
+	RecentFailures   []*DashboardFailure            `json:"recent_failures"`
+	PendingApprovals []PendingApprovalItem          `json:"pending_approvals"`
+	GeneratedAt      time.Time                      `json:"generated_at"`
+}
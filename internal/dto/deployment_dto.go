@@ -2,6 +2,195 @@ package dto
 
 // RetryDeploymentRequest 手动重试部署请求
 type RetryDeploymentRequest struct {
+	Operator string   `json:"operator" binding:"required"` // 操作人
+	Reason   string   `json:"reason"`                      // 重试原因（可选）
+	Clusters []string `json:"clusters"`                    // 仅重试同一发布应用、同环境下这些集群的 failed deployment（可选，为空只重试当前 deployment）
+}
+
+// RetryDeploymentResponse 手动重试部署结果
+type RetryDeploymentResponse struct {
+	DeploymentIDs []int64 `json:"deployment_ids"` // 已置回 pending 的 deployment
+	Message       string  `json:"message"`
+}
+
+// RedeployConfigChartRequest 单独重新部署 config chart 请求
+type RedeployConfigChartRequest struct {
 	Operator string `json:"operator" binding:"required"` // 操作人
-	Reason   string `json:"reason"`                      // 重试原因（可选）
+	Reason   string `json:"reason"`                      // 重新部署原因（可选）
+}
+
+// ConfigChartDriftQuery config chart 版本核对查询参数
+type ConfigChartDriftQuery struct {
+	AppID int64  `form:"app_id" binding:"required,gt=0"`
+	Env   string `form:"env" binding:"required,oneof=pre prod"`
+}
+
+// ConfigChartDriftResponse config chart 版本核对结果
+type ConfigChartDriftResponse struct {
+	AppID    int64                  `json:"app_id"`
+	Env      string                 `json:"env"`
+	Status   string                 `json:"status"` // 汇总：存在 drift 时为 drift，否则取第一个非 ok 的状态
+	Clusters []ConfigChartDriftItem `json:"clusters"`
+}
+
+// ConfigChartDriftItem 单个集群的 config chart 版本核对结果
+type ConfigChartDriftItem struct {
+	ClusterName        string `json:"cluster_name"`
+	Namespace          string `json:"namespace"`
+	ReleaseName        string `json:"release_name"`
+	AppDeploymentID    int64  `json:"app_deployment_id"`
+	ConfigDeploymentID int64  `json:"config_deployment_id,omitempty"`
+	ExpectedVersion    string `json:"expected_version"`
+	RecordedVersion    string `json:"recorded_version"`
+	LiveVersion        string `json:"live_version"`
+	Status             string `json:"status"` // ok/drift/missing/unknown
+	Message            string `json:"message,omitempty"`
+}
+
+// AckDeploymentDiffRequest 确认 prod 部署 diff 请求
+type AckDeploymentDiffRequest struct {
+	Digest string `json:"digest" binding:"required"` // 确认的 diff 摘要（需与当前 diff 一致）
+}
+
+// DeploymentDiffResponse prod 部署前 manifest diff
+type DeploymentDiffResponse struct {
+	DeploymentID   int64                  `json:"deployment_id"`
+	Env            string                 `json:"env"`
+	ClusterName    string                 `json:"cluster_name"`
+	Namespace      string                 `json:"namespace"`
+	ReleaseName    string                 `json:"release_name"`
+	BaseRevision   int                    `json:"base_revision"` // 对比的 release revision，0 表示首次安装
+	Status         string                 `json:"status"`        // ready/failed
+	Digest         string                 `json:"digest"`
+	Changes        []DeploymentDiffChange `json:"changes"`
+	ProtectedKinds []string               `json:"protected_kinds"` // 变更涉及的受保护资源类型
+	Diff           string                 `json:"diff"`
+	RequiresAck    bool                   `json:"requires_ack"`
+	Acked          bool                   `json:"acked"`
+	AckedBy        *string                `json:"acked_by"`
+	AckedAt        *string                `json:"acked_at"`
+	ErrorMessage   *string                `json:"error_message"`
+	UpdatedAt      string                 `json:"updated_at"`
+}
+
+// DeploymentDiffPreviewResponse 按需计算的部署 diff（不保存，不影响 diff 确认）
+type DeploymentDiffPreviewResponse struct {
+	DeploymentID   int64                  `json:"deployment_id"`
+	Env            string                 `json:"env"`
+	ClusterName    string                 `json:"cluster_name"`
+	Namespace      string                 `json:"namespace"`
+	ReleaseName    string                 `json:"release_name"`
+	BaseRevision   int                    `json:"base_revision"` // 对比的 release revision，0 表示首次安装
+	Digest         string                 `json:"digest"`
+	Summary        DeploymentDiffSummary  `json:"summary"`
+	Changes        []DeploymentDiffChange `json:"changes"`
+	ProtectedKinds []string               `json:"protected_kinds"`
+	Diff           string                 `json:"diff"`
+	GeneratedAt    string                 `json:"generated_at"`
+}
+
+// DeploymentDiffSummary 变更资源数统计
+type DeploymentDiffSummary struct {
+	Added   int `json:"added"`
+	Changed int `json:"changed"`
+	Removed int `json:"removed"`
+}
+
+// DeploymentDiffChange 单个资源的变更
+type DeploymentDiffChange struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Action    string `json:"action"` // added/removed/changed
+	Protected bool   `json:"protected"`
+}
+
+// DeploymentLogsQuery 部署 pod 日志查询参数
+type DeploymentLogsQuery struct {
+	Pod          string `form:"pod"`                                          // 指定 pod（为空时返回全部 pod）
+	Container    string `form:"container"`                                    // 指定容器（为空时返回 pod 内全部容器）
+	TailLines    int64  `form:"tail_lines" binding:"omitempty,gt=0,lte=5000"` // 每个容器最多返回的行数，默认 200
+	SinceSeconds int64  `form:"since_seconds" binding:"omitempty,gt=0"`       // 仅返回最近 N 秒的日志
+	Previous     bool   `form:"previous"`                                     // 读取上一次（已重启）容器的日志
+	Follow       bool   `form:"follow"`                                       // 以 SSE 持续推送（需指定 pod）
+}
+
+// DeploymentEventsQuery 部署 Kubernetes 事件查询参数
+type DeploymentEventsQuery struct {
+	Follow bool `form:"follow"` // 以 SSE 持续推送新事件
+}
+
+// DeploymentWorkload 部署 manifest 中的 workload
+type DeploymentWorkload struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// DeploymentLogsResponse 部署 pod 日志
+type DeploymentLogsResponse struct {
+	DeploymentID int64                `json:"deployment_id"`
+	ClusterName  string               `json:"cluster_name"`
+	Namespace    string               `json:"namespace"`
+	Workloads    []DeploymentWorkload `json:"workloads"`
+	Pods         []DeploymentPodLogs  `json:"pods"`
+}
+
+// DeploymentPodLogs 单个 pod 的日志
+type DeploymentPodLogs struct {
+	Pod        string                    `json:"pod"`
+	Phase      string                    `json:"phase"`
+	Containers []DeploymentContainerLogs `json:"containers"`
+}
+
+// DeploymentContainerLogs 单个容器的日志
+type DeploymentContainerLogs struct {
+	Container string `json:"container"`
+	Logs      string `json:"logs"`
+	Error     string `json:"error,omitempty"` // 读取失败原因（如容器尚未启动）
+}
+
+// DeploymentLogLine SSE 推送的单行日志
+type DeploymentLogLine struct {
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Line      string `json:"line"`
+}
+
+// DeploymentEventsResponse 部署相关 Kubernetes 事件
+type DeploymentEventsResponse struct {
+	DeploymentID int64                `json:"deployment_id"`
+	ClusterName  string               `json:"cluster_name"`
+	Namespace    string               `json:"namespace"`
+	Workloads    []DeploymentWorkload `json:"workloads"`
+	Events       []DeploymentEvent    `json:"events"`
+}
+
+// DeploymentEvent Kubernetes 事件
+type DeploymentEvent struct {
+	Type      string `json:"type"` // Normal/Warning
+	Reason    string `json:"reason"`
+	Message   string `json:"message"`
+	Kind      string `json:"kind"` // 事件关联对象
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Count     int32  `json:"count"`
+	Time      string `json:"time"`
+}
+
+// DeploymentTimelineResponse Deployment 执行时间线
+type DeploymentTimelineResponse struct {
+	Deployment DeploymentResponse        `json:"deployment"`
+	Events     []DeploymentTimelineEvent `json:"events"` // 按时间正序
+}
+
+// DeploymentTimelineEvent 时间线中的一步
+type DeploymentTimelineEvent struct {
+	ID         int64   `json:"id"`
+	Type       string  `json:"type"` // status_changed/chart_rendered/deploy_started/readiness
+	FromStatus *string `json:"from_status,omitempty"`
+	ToStatus   string  `json:"to_status"`
+	Operator   *string `json:"operator"` // 为空表示引擎自动推进
+	Message    *string `json:"message"`
+	CreatedAt  string  `json:"created_at"`
 }
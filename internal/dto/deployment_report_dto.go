@@ -0,0 +1,55 @@
+package dto
+
+import "time"
+
+// DeploymentReportQuery 部署统计报表查询
+type DeploymentReportQuery struct {
+	ProjectID *int64     `form:"project_id"`                                          // 为空时返回有权限的所有项目
+	TeamID    *int64     `form:"team_id"`                                             // 只统计该团队的应用
+	AppID     *int64     `form:"app_id"`                                              // 只统计该应用
+	Start     *time.Time `form:"start" time_format:"2006-01-02"`                      // 默认 90 天前
+	End       *time.Time `form:"end" time_format:"2006-01-02"`                        // 默认今天（含）
+	GroupBy   string     `form:"group_by" binding:"omitempty,oneof=project team app"` // 分组维度，默认 app
+	Format    string     `form:"format" binding:"omitempty,oneof=json csv"`           // csv 时导出分组明细
+}
+
+// DeploymentStats DORA 部署指标（只统计生产环境 app Deployment）
+type DeploymentStats struct {
+	Deployments         int     `json:"deployments"`          // 区间内结束的部署数（成功 + 失败）
+	Successful          int     `json:"successful"`           // 成功部署数
+	Failed              int     `json:"failed"`               // 失败部署数（含部署后验证失败）
+	SuccessRate         float64 `json:"success_rate"`         // successful / deployments
+	DeploymentFrequency float64 `json:"deployment_frequency"` // 平均每天成功部署数
+	ActiveDays          int     `json:"active_days"`          // 有成功部署的天数
+
+	// 交付时长：批次封板 -> 应用在该批次最后一个生产部署成功（不含回滚部署）
+	LeadTimes          int     `json:"lead_times"` // 样本数（批次 × 应用）
+	LeadTimeAvgMinutes float64 `json:"lead_time_avg_minutes"`
+	LeadTimeP50Minutes float64 `json:"lead_time_p50_minutes"`
+	LeadTimeP90Minutes float64 `json:"lead_time_p90_minutes"`
+
+	// 恢复时长：同一应用同一集群部署失败 -> 之后第一次部署成功
+	Recoveries  int     `json:"recoveries"`
+	MTTRMinutes float64 `json:"mttr_minutes"`
+}
+
+// DeploymentReportItem 分组统计（按 group_by 填充项目/团队/应用）
+type DeploymentReportItem struct {
+	ProjectID   int64  `json:"project_id"`
+	ProjectName string `json:"project_name"`
+	TeamID      *int64 `json:"team_id,omitempty"`
+	TeamName    string `json:"team_name,omitempty"`
+	AppID       int64  `json:"app_id,omitempty"`
+	AppName     string `json:"app_name,omitempty"`
+	DeploymentStats
+}
+
+// DeploymentReportResponse 部署统计报表
+type DeploymentReportResponse struct {
+	Start   string                  `json:"start"`
+	End     string                  `json:"end"`
+	Days    int                     `json:"days"`
+	GroupBy string                  `json:"group_by"`
+	Summary DeploymentStats         `json:"summary"`
+	Items   []*DeploymentReportItem `json:"items"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+// CreateEnginePauseRequest 暂停核心引擎处理请求
+type CreateEnginePauseRequest struct {
+	Scope   string `json:"scope" binding:"required,oneof=project batch cluster" example:"batch"`
+	ScopeID int64  `json:"scope_id" binding:"required,gt=0" example:"1"`
+	Reason  string `json:"reason" binding:"required,max=500" example:"线上事故处理中，暂停发布推进"`
+}
+
+// EnginePauseListRequest 暂停记录列表请求
+type EnginePauseListRequest struct {
+	ActiveOnly bool `form:"active_only" example:"true"`
+	Page       int  `form:"page" example:"1"`
+	PageSize   int  `form:"page_size" example:"10"`
+}
+
+// EnginePauseResponse 暂停记录响应
+type EnginePauseResponse struct {
+	ID        int64   `json:"id"`
+	Scope     string  `json:"scope"`
+	ScopeID   int64   `json:"scope_id"`
+	Reason    string  `json:"reason"`
+	Active    bool    `json:"active"`
+	PausedBy  string  `json:"paused_by"`
+	PausedAt  string  `json:"paused_at"`
+	ResumedBy *string `json:"resumed_by,omitempty"`
+	ResumedAt *string `json:"resumed_at,omitempty"`
+}
@@ -0,0 +1,62 @@
+package dto
+
+import "time"
+
+// ImageScanReport 镜像漏洞扫描结果（由 Harbor / Trivy 适配器转换）
+type ImageScanReport struct {
+	Image     string    // 镜像地址（不含 tag）
+	Tag       string    // 镜像 tag
+	Digest    string    // manifest digest
+	Scanner   string    // 扫描器名称及版本
+	Status    string    // success / failed
+	Critical  int       // 严重漏洞数
+	High      int       // 高危漏洞数
+	Medium    int       // 中危漏洞数
+	Low       int       // 低危漏洞数
+	Unknown   int       // 未知级别漏洞数
+	ScannedAt time.Time // 扫描完成时间
+}
+
+// ImageScanResult 扫描结果上报处理结果
+type ImageScanResult struct {
+	Recorded []string `json:"recorded"` // 已记录的镜像（image:tag）
+}
+
+// ImageScanListQuery 镜像扫描结果列表查询参数
+type ImageScanListQuery struct {
+	PageQuery
+	BuildID *int64 `form:"build_id"` // 按构建查询其镜像的扫描结果（优先于 image/tag）
+	Image   string `form:"image"`    // 镜像地址（不含 tag）
+	Tag     string `form:"tag"`
+}
+
+// ImageScanResponse 镜像扫描结果
+type ImageScanResponse struct {
+	ID        int64   `json:"id"`
+	Image     string  `json:"image"`
+	Tag       string  `json:"tag"`
+	Digest    *string `json:"digest"`
+	Source    string  `json:"source"`
+	Scanner   string  `json:"scanner"`
+	Status    string  `json:"status"`
+	Critical  int     `json:"critical"`
+	High      int     `json:"high"`
+	Medium    int     `json:"medium"`
+	Low       int     `json:"low"`
+	Unknown   int     `json:"unknown"`
+	ScannedAt string  `json:"scanned_at"`
+	UpdatedAt string  `json:"updated_at"`
+}
+
+// BatchVulnOverrideRequest 批次漏洞门禁豁免
+type BatchVulnOverrideRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"` // 豁免原因
+}
+
+// BatchVulnOverrideResponse 批次漏洞门禁豁免状态
+type BatchVulnOverrideResponse struct {
+	BatchID            int64   `json:"batch_id"`
+	VulnOverrideAt     *string `json:"vuln_override_at"`
+	VulnOverrideBy     *string `json:"vuln_override_by"`
+	VulnOverrideReason *string `json:"vuln_override_reason"`
+}
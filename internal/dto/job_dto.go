@@ -0,0 +1,34 @@
+package dto
+
+import "encoding/json"
+
+// JobListRequest 异步任务列表请求
+type JobListRequest struct {
+	Status       string `form:"status" binding:"omitempty,oneof=pending running succeeded dead" example:"dead"`
+	Type         string `form:"type" example:"deployment.execute"`
+	BatchID      int64  `form:"batch_id" example:"1"`
+	DeploymentID int64  `form:"deployment_id" example:"1"`
+	Page         int    `form:"page" example:"1"`
+	PageSize     int    `form:"page_size" example:"10"`
+}
+
+// JobResponse 异步任务
+type JobResponse struct {
+	ID           int64           `json:"id"`
+	Type         string          `json:"type"`
+	DedupKey     string          `json:"dedup_key"`
+	Payload      json.RawMessage `json:"payload"`
+	Status       string          `json:"status"`
+	Attempts     int             `json:"attempts"`
+	MaxAttempts  int             `json:"max_attempts"`
+	RunAt        string          `json:"run_at"`
+	LockedBy     string          `json:"locked_by,omitempty"`
+	LockedUntil  *string         `json:"locked_until,omitempty"`
+	LastError    string          `json:"last_error,omitempty"`
+	BatchID      *int64          `json:"batch_id,omitempty"`
+	DeploymentID *int64          `json:"deployment_id,omitempty"`
+	StartedAt    *string         `json:"started_at,omitempty"`
+	FinishedAt   *string         `json:"finished_at,omitempty"`
+	CreatedAt    string          `json:"created_at"`
+	UpdatedAt    string          `json:"updated_at"`
+}
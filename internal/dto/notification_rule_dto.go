@@ -0,0 +1,71 @@
+package dto
+
+import "devops-cd/internal/model"
+
+// CreateNotificationRuleRequest 创建通知路由规则请求
+type CreateNotificationRuleRequest struct {
+	Name            string            `json:"name" binding:"required,max=100" example:"prod-failed"`
+	ProjectID       *int64            `json:"project_id"`                      // 为空表示所有项目
+	TeamID          *int64            `json:"team_id"`                         // 为空表示所有团队，非空时只匹配应用级通知
+	Events          []string          `json:"events" binding:"required,min=1"` // 通知类型列表，["*"] 表示全部
+	MinSeverity     string            `json:"min_severity" binding:"omitempty,oneof=info warning error" example:"info"`
+	Webhook         string            `json:"webhook" binding:"required,max=500" example:"https://open.feishu.cn/open-apis/bot/v2/hook/xxx"`
+	TitleTemplate   string            `json:"title_template"`   // Go text/template，为空使用默认标题
+	ContentTemplate string            `json:"content_template"` // Go text/template，为空使用默认正文
+	QuietHours      *model.QuietHours `json:"quiet_hours"`
+	MentionLeader   bool              `json:"mention_leader"`
+	Enabled         *bool             `json:"enabled" example:"true"`
+	Description     string            `json:"description" binding:"max=500"`
+}
+
+// UpdateNotificationRuleRequest 更新通知路由规则请求
+type UpdateNotificationRuleRequest struct {
+	Name            *string           `json:"name" binding:"omitempty,max=100"`
+	ProjectID       *int64            `json:"project_id"` // 传 0 表示所有项目
+	TeamID          *int64            `json:"team_id"`    // 传 0 表示所有团队
+	Events          []string          `json:"events"`
+	MinSeverity     *string           `json:"min_severity" binding:"omitempty,oneof=info warning error"`
+	Webhook         *string           `json:"webhook" binding:"omitempty,max=500"`
+	TitleTemplate   *string           `json:"title_template"`
+	ContentTemplate *string           `json:"content_template"`
+	QuietHours      *model.QuietHours `json:"quiet_hours"` // 传 start/end 均为空表示取消免打扰
+	MentionLeader   *bool             `json:"mention_leader"`
+	Enabled         *bool             `json:"enabled"`
+	Description     *string           `json:"description" binding:"omitempty,max=500"`
+}
+
+// NotificationRuleListRequest 规则列表请求
+type NotificationRuleListRequest struct {
+	ProjectID *int64 `form:"project_id"`
+	TeamID    *int64 `form:"team_id"`
+	Page      int    `form:"page" example:"1"`
+	PageSize  int    `form:"page_size" example:"10"`
+}
+
+// NotificationRuleResponse 通知路由规则响应
+type NotificationRuleResponse struct {
+	ID              int64             `json:"id"`
+	Name            string            `json:"name"`
+	ProjectID       *int64            `json:"project_id"`
+	TeamID          *int64            `json:"team_id"`
+	Events          []string          `json:"events"`
+	MinSeverity     string            `json:"min_severity"`
+	Webhook         string            `json:"webhook"`
+	TitleTemplate   string            `json:"title_template"`
+	ContentTemplate string            `json:"content_template"`
+	QuietHours      *model.QuietHours `json:"quiet_hours"`
+	MentionLeader   bool              `json:"mention_leader"`
+	Enabled         bool              `json:"enabled"`
+	Description     string            `json:"description"`
+	CreatedBy       string            `json:"created_by"`
+	UpdatedBy       string            `json:"updated_by"`
+	CreatedAt       string            `json:"created_at"`
+	UpdatedAt       string            `json:"updated_at"`
+}
+
+// NotificationRuleOptionsResponse 规则可选项
+type NotificationRuleOptionsResponse struct {
+	Events     []string `json:"events"`
+	Severities []string `json:"severities"`
+	Fields     []string `json:"fields"` // 模板可用字段
+}
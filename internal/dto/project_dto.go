@@ -1,23 +1,45 @@
 package dto
 
+import "devops-cd/internal/model"
+
 // CreateProjectRequest 创建项目请求
 type CreateProjectRequest struct {
-	Name               string               `json:"name" binding:"required,max=100"`
-	Description        *string              `json:"description"`
-	OwnerName          *string              `json:"owner_name" binding:"omitempty,max=100"`
-	CreateDefaultTeam  *bool                `json:"create_default_team" binding:"omitempty"`
-	AllowedEnvClusters *map[string][]string `json:"allowed_env_clusters"` // 允许的环境集群配置: {"pre": ["cluster-a"], "prod": ["cluster-b"]}
-	DefaultEnvClusters *map[string][]string `json:"default_env_clusters"` // 项目默认环境集群配置(必须是 allowed_env_clusters 的子集)
+	Name               string                `json:"name" binding:"required,max=100"`
+	Description        *string               `json:"description"`
+	OwnerName          *string               `json:"owner_name" binding:"omitempty,max=100"`
+	CreateDefaultTeam  *bool                 `json:"create_default_team" binding:"omitempty"`
+	AllowedEnvClusters *map[string][]string  `json:"allowed_env_clusters"` // 允许的环境集群配置: {"pre": ["cluster-a"], "prod": ["cluster-b"]}
+	DefaultEnvClusters *map[string][]string  `json:"default_env_clusters"` // 项目默认环境集群配置(必须是 allowed_env_clusters 的子集)
+	ApprovalPolicy     *model.ApprovalPolicy `json:"approval_policy"`      // 批次审批策略（多人/分阶段），为空表示单人审批
+	AutoBatchRule      *model.AutoBatchRule  `json:"auto_batch_rule"`      // 按 Git tag 自动建批规则，为空表示不自动建批
+	AutoRollback       *bool                 `json:"auto_rollback"`        // 生产就绪/验证失败时自动回滚失败集群，默认关闭
+	VulnPolicy         *model.VulnPolicy     `json:"vuln_policy"`          // 镜像漏洞门禁，为空表示不拦截
+	JiraConfig         *model.JiraConfig     `json:"jira_config"`          // Jira 关联，为空表示不流转 issue
+	ProdTwoPersonRule  *bool                 `json:"prod_two_person_rule"` // 生产部署双人原则：触发人不能是批次发起人或审批人，默认关闭
+	NotifyDigest       *bool                 `json:"notify_digest"`        // 应用部署结果按批次阶段汇总通知，默认关闭（逐个应用通知）
 }
 
 // UpdateProjectRequest 更新项目请求
 type UpdateProjectRequest struct {
-	ID                 int64                `json:"id" binding:"required"`
-	Name               *string              `json:"name" binding:"omitempty,max=100"`
-	Description        *string              `json:"description"`
-	OwnerName          *string              `json:"owner_name" binding:"omitempty,max=100"`
-	AllowedEnvClusters *map[string][]string `json:"allowed_env_clusters"` // 允许的环境集群配置
-	DefaultEnvClusters *map[string][]string `json:"default_env_clusters"` // 项目默认环境集群配置(必须是 allowed_env_clusters 的子集)
+	ID                 int64                 `json:"id" binding:"required"`
+	Name               *string               `json:"name" binding:"omitempty,max=100"`
+	Description        *string               `json:"description"`
+	OwnerName          *string               `json:"owner_name" binding:"omitempty,max=100"`
+	AllowedEnvClusters *map[string][]string  `json:"allowed_env_clusters"` // 允许的环境集群配置
+	DefaultEnvClusters *map[string][]string  `json:"default_env_clusters"` // 项目默认环境集群配置(必须是 allowed_env_clusters 的子集)
+	ApprovalPolicy     *model.ApprovalPolicy `json:"approval_policy"`      // 批次审批策略，传 {"stages": []} 表示恢复单人审批
+	AutoBatchRule      *model.AutoBatchRule  `json:"auto_batch_rule"`      // 自动建批规则，传 {"tag_pattern": ""} 表示关闭
+	AutoRollback       *bool                 `json:"auto_rollback"`        // 生产就绪/验证失败时自动回滚失败集群
+	VulnPolicy         *model.VulnPolicy     `json:"vuln_policy"`          // 镜像漏洞门禁，传 {} 表示关闭
+	JiraConfig         *model.JiraConfig     `json:"jira_config"`          // Jira 关联，传 {} 表示关闭
+	ProdTwoPersonRule  *bool                 `json:"prod_two_person_rule"` // 生产部署双人原则
+	NotifyDigest       *bool                 `json:"notify_digest"`        // 应用部署结果按批次阶段汇总通知
+}
+
+// ChangesPolicy 是否修改项目策略字段（需要 project:policy:update 权限）
+func (q *UpdateProjectRequest) ChangesPolicy() bool {
+	return q.ApprovalPolicy != nil || q.AutoBatchRule != nil || q.AutoRollback != nil ||
+		q.VulnPolicy != nil || q.JiraConfig != nil || q.ProdTwoPersonRule != nil
 }
 
 // DeleteProjectRequest 删除项目请求
@@ -33,15 +55,22 @@ type GetProjectRequest struct {
 
 // ProjectResponse 项目响应
 type ProjectResponse struct {
-	ID                 int64                `json:"id"`
-	Name               string               `json:"name"`
-	Description        *string              `json:"description"`
-	OwnerName          *string              `json:"owner_name"`
-	AllowedEnvClusters *map[string][]string `json:"allowed_env_clusters"` // 允许的环境集群配置
-	DefaultEnvClusters *map[string][]string `json:"default_env_clusters"` // 项目默认环境集群配置(必须是 allowed_env_clusters 的子集)
-	CreatedAt          string               `json:"created_at"`
-	UpdatedAt          string               `json:"updated_at"`
-	Teams              []*TeamResponse      `json:"teams,omitempty"`
+	ID                 int64                 `json:"id"`
+	Name               string                `json:"name"`
+	Description        *string               `json:"description"`
+	OwnerName          *string               `json:"owner_name"`
+	AllowedEnvClusters *map[string][]string  `json:"allowed_env_clusters"` // 允许的环境集群配置
+	DefaultEnvClusters *map[string][]string  `json:"default_env_clusters"` // 项目默认环境集群配置(必须是 allowed_env_clusters 的子集)
+	ApprovalPolicy     *model.ApprovalPolicy `json:"approval_policy"`      // 批次审批策略
+	AutoBatchRule      *model.AutoBatchRule  `json:"auto_batch_rule"`      // 自动建批规则
+	AutoRollback       bool                  `json:"auto_rollback"`        // 生产就绪/验证失败时自动回滚失败集群
+	VulnPolicy         *model.VulnPolicy     `json:"vuln_policy"`          // 镜像漏洞门禁
+	JiraConfig         *model.JiraConfig     `json:"jira_config"`          // Jira 关联
+	ProdTwoPersonRule  bool                  `json:"prod_two_person_rule"` // 生产部署双人原则：触发人不能是批次发起人或审批人
+	NotifyDigest       bool                  `json:"notify_digest"`        // 应用部署结果按批次阶段汇总通知
+	CreatedAt          string                `json:"created_at"`
+	UpdatedAt          string                `json:"updated_at"`
+	Teams              []*TeamResponse       `json:"teams,omitempty"`
 }
 
 // ProjectListQuery 项目列表查询参数
@@ -0,0 +1,32 @@
+package dto
+
+import "devops-cd/internal/model"
+
+// ReleaseDriftListRequest 配置漂移列表请求
+type ReleaseDriftListRequest struct {
+	ProjectID int64  `form:"project_id" binding:"required,gt=0" example:"1"`
+	AppID     int64  `form:"app_id" example:"1"`
+	Env       string `form:"env" binding:"omitempty,oneof=pre prod"`
+	Status    string `form:"status" binding:"omitempty,oneof=ok drift missing error"`
+	Page      int    `form:"page" example:"1"`
+	PageSize  int    `form:"page_size" example:"10"`
+}
+
+// ReleaseDriftResponse 配置漂移检测结果
+type ReleaseDriftResponse struct {
+	ID           int64               `json:"id"`
+	ProjectID    int64               `json:"project_id"`
+	AppID        int64               `json:"app_id"`
+	AppName      string              `json:"app_name"`
+	Env          string              `json:"env"`
+	ClusterName  string              `json:"cluster_name"`
+	Kind         string              `json:"kind"`          // app/config
+	DeploymentID int64               `json:"deployment_id"` // 作为基准的 Deployment
+	Namespace    string              `json:"namespace"`
+	ReleaseName  string              `json:"release_name"`
+	Status       string              `json:"status"` // ok/drift/missing/error
+	Findings     model.DriftFindings `json:"findings"`
+	ErrorMessage *string             `json:"error_message,omitempty"`
+	DetectedAt   *string             `json:"detected_at,omitempty"` // 本次漂移首次发现时间
+	CheckedAt    string              `json:"checked_at"`
+}
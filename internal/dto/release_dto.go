@@ -17,3 +17,48 @@ type ManualDeployRequest struct {
 	Operator     string `json:"operator" binding:"required"`       // 操作人
 	Reason       string `json:"reason"`                            // 触发原因（可选）
 }
+
+// RollbackBatchRequest 批次回滚请求（回滚批次内所有已部署生产的应用）
+type RollbackBatchRequest struct {
+	BatchID  int64  `json:"batch_id" binding:"required"` // 批次ID
+	Operator string `json:"-"`                           // 操作人（取当前登录用户）
+	Reason   string `json:"reason"`                      // 回滚原因（可选）
+}
+
+// RollbackReleaseAppRequest 单个发布应用回滚请求
+type RollbackReleaseAppRequest struct {
+	ReleaseAppID int64  `json:"release_app_id" binding:"required"` // 发布应用ID
+	Operator     string `json:"-"`                                 // 操作人（取当前登录用户）
+	Reason       string `json:"reason"`                            // 回滚原因（可选）
+}
+
+// ReleaseAppRolloutRequest 生产灰度 promote/abort 请求
+type ReleaseAppRolloutRequest struct {
+	ReleaseAppID int64  `json:"release_app_id" binding:"required"`             // 发布应用ID
+	Action       string `json:"action" binding:"required,oneof=promote abort"` // promote: 放行下一阶段; abort: 中止后续阶段
+	Operator     string `json:"-"`                                             // 操作人（取当前登录用户）
+	Reason       string `json:"reason"`                                        // 操作说明（可选）
+}
+
+// ReleaseAppRedeployRequest 按集群重新部署请求
+type ReleaseAppRedeployRequest struct {
+	ReleaseAppID int64    `json:"release_app_id" binding:"required"`      // 发布应用ID
+	Env          string   `json:"env" binding:"required,oneof=pre prod"`  // 环境
+	Clusters     []string `json:"clusters" binding:"required,min=1,dive"` // 重新部署的集群
+	Operator     string   `json:"-"`                                      // 操作人（取当前登录用户）
+	Reason       string   `json:"reason"`                                 // 操作说明（可选）
+}
+
+// RollbackResponse 回滚触发结果
+type RollbackResponse struct {
+	BatchID   int64             `json:"batch_id"`
+	Triggered []int64           `json:"triggered"` // 已触发回滚的发布应用ID
+	Skipped   []RollbackSkipped `json:"skipped"`   // 未回滚的应用及原因
+}
+
+// RollbackSkipped 未回滚的应用
+type RollbackSkipped struct {
+	ReleaseAppID int64  `json:"release_app_id"`
+	AppID        int64  `json:"app_id"`
+	Reason       string `json:"reason"`
+}
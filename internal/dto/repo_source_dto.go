@@ -12,6 +12,8 @@ type RepoSyncSourceResponse struct {
 	DefaultProjectName *string    `json:"default_project_name,omitempty"`
 	DefaultTeamID      *int64     `json:"default_team_id"`
 	DefaultTeamName    *string    `json:"default_team_name,omitempty"`
+	DiscoveryFiles     []string   `json:"discovery_files"`
+	DiscoveryAppType   *string    `json:"discovery_app_type"`
 	LastSyncedAt       *time.Time `json:"last_synced_at"`
 	LastStatus         *string    `json:"last_status"`
 	LastMessage        *string    `json:"last_message"`
@@ -29,24 +31,28 @@ type RepoSyncSourceListQuery struct {
 }
 
 type CreateRepoSyncSourceRequest struct {
-	Platform         string  `json:"platform" binding:"required,oneof=gitea gitlab github"`
-	BaseURL          string  `json:"base_url" binding:"required,url"`
-	Namespace        string  `json:"namespace" binding:"required"`
-	Token            string  `json:"token" binding:"required"`
-	Enabled          *bool   `json:"enabled"`
-	DefaultProjectID *int64  `json:"default_project_id"` // 默认项目ID
-	DefaultTeamID    *int64  `json:"default_team_id"`    // 默认团队ID
-	CreatedBy        *string `json:"created_by"`
+	Platform         string   `json:"platform" binding:"required,oneof=gitea gitlab github"`
+	BaseURL          string   `json:"base_url" binding:"required,url"`
+	Namespace        string   `json:"namespace" binding:"required"`
+	Token            string   `json:"token" binding:"required"`
+	Enabled          *bool    `json:"enabled"`
+	DefaultProjectID *int64   `json:"default_project_id"`                                                  // 默认项目ID
+	DefaultTeamID    *int64   `json:"default_team_id"`                                                     // 默认团队ID
+	DiscoveryFiles   []string `json:"discovery_files" binding:"omitempty,max=10,dive,required,max=255"`    // 发现规则：根目录存在其中任一文件时按代码库创建应用（如 Dockerfile）
+	DiscoveryAppType *string  `json:"discovery_app_type" binding:"omitempty,oneof=static node java go py"` // 发现规则创建应用的 app_type，为空时按代码库语言推断
+	CreatedBy        *string  `json:"created_by"`
 }
 
 type UpdateRepoSyncSourceRequest struct {
-	ID               int64   `json:"id" binding:"required"`
-	Platform         string  `json:"platform" binding:"required,oneof=gitea gitlab github"`
-	BaseURL          string  `json:"base_url" binding:"required,url"`
-	Namespace        string  `json:"namespace" binding:"required"`
-	Token            *string `json:"token"`
-	Enabled          *bool   `json:"enabled"`
-	DefaultProjectID *int64  `json:"default_project_id"` // 默认项目ID
-	DefaultTeamID    *int64  `json:"default_team_id"`    // 默认团队ID
-	UpdatedBy        *string `json:"updated_by"`
+	ID               int64    `json:"id" binding:"required"`
+	Platform         string   `json:"platform" binding:"required,oneof=gitea gitlab github"`
+	BaseURL          string   `json:"base_url" binding:"required,url"`
+	Namespace        string   `json:"namespace" binding:"required"`
+	Token            *string  `json:"token"`
+	Enabled          *bool    `json:"enabled"`
+	DefaultProjectID *int64   `json:"default_project_id"`                                                  // 默认项目ID
+	DefaultTeamID    *int64   `json:"default_team_id"`                                                     // 默认团队ID
+	DiscoveryFiles   []string `json:"discovery_files" binding:"omitempty,max=10,dive,required,max=255"`    // 发现规则：根目录存在其中任一文件时按代码库创建应用（如 Dockerfile）
+	DiscoveryAppType *string  `json:"discovery_app_type" binding:"omitempty,oneof=static node java go py"` // 发现规则创建应用的 app_type，为空时按代码库语言推断
+	UpdatedBy        *string  `json:"updated_by"`
 }
@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 // CreateRepositoryRequest 创建代码库请求
 type CreateRepositoryRequest struct {
 	Namespace   string  `json:"namespace" binding:"required,max=100"`
@@ -10,6 +12,7 @@ type CreateRepositoryRequest struct {
 	Language    *string `json:"language"`
 	TeamID      *int64  `json:"team_id"`
 	ProjectID   *int64  `json:"project_id"`
+	CIToken     *string `json:"ci_token" binding:"omitempty,max=200"` // Jenkins 构建通知 token
 }
 
 // UpdateRepositoryRequest 更新代码库请求
@@ -24,6 +27,7 @@ type UpdateRepositoryRequest struct {
 	TeamID      *int64  `json:"team_id"`
 	ProjectID   *int64  `json:"project_id"`
 	Status      *int8   `json:"status" binding:"omitempty,oneof=0 1"`
+	CIToken     *string `json:"ci_token" binding:"omitempty,max=200"` // Jenkins 构建通知 token，空字符串表示清除
 }
 
 // DeleteRepositoryRequest 删除代码库请求（软删除）
@@ -38,22 +42,24 @@ type GetRepositoryRequest struct {
 
 // RepositoryResponse 代码库响应
 type RepositoryResponse struct {
-	ID           int64                  `json:"id"`
-	Namespace    string                 `json:"namespace"`
-	Name         string                 `json:"name"`
-	FullName     string                 `json:"full_name"` // namespace/name
-	Description  *string                `json:"description"`
-	GitURL       string                 `json:"git_url"`
-	GitType      string                 `json:"git_type"`
-	Language     *string                `json:"language"`
-	TeamID       *int64                 `json:"team_id"`
-	TeamName     *string                `json:"team_name,omitempty"`
-	ProjectID    *int64                 `json:"project_id"`
-	ProjectName  *string                `json:"project_name,omitempty"`
-	Status       int8                   `json:"status"`
-	Applications []*ApplicationResponse `json:"applications,omitempty"` // 关联的应用列表
-	CreatedAt    string                 `json:"created_at"`
-	UpdatedAt    string                 `json:"updated_at"`
+	ID             int64                  `json:"id"`
+	Namespace      string                 `json:"namespace"`
+	Name           string                 `json:"name"`
+	FullName       string                 `json:"full_name"` // namespace/name
+	Description    *string                `json:"description"`
+	GitURL         string                 `json:"git_url"`
+	GitType        string                 `json:"git_type"`
+	Language       *string                `json:"language"`
+	TeamID         *int64                 `json:"team_id"`
+	TeamName       *string                `json:"team_name,omitempty"`
+	ProjectID      *int64                 `json:"project_id"`
+	ProjectName    *string                `json:"project_name,omitempty"`
+	Status         int8                   `json:"status"`
+	ManifestStatus *string                `json:"manifest_status"`        // devops-cd.yaml 同步状态，nil 表示尚未同步
+	HasCIToken     bool                   `json:"has_ci_token"`           // 是否已配置 Jenkins 构建通知 token
+	Applications   []*ApplicationResponse `json:"applications,omitempty"` // 关联的应用列表
+	CreatedAt      string                 `json:"created_at"`
+	UpdatedAt      string                 `json:"updated_at"`
 }
 
 // RepositoryListQuery 代码库列表查询参数
@@ -66,3 +72,38 @@ type RepositoryListQuery struct {
 	GitType          *string `form:"git_type" binding:"omitempty,oneof=gitea gitlab github"` // 可选：按Git类型过滤
 	WithApplications *bool   `form:"with_applications"`                                      // 可选：是否包含应用列表，默认false
 }
+
+// RepositoryManifestSyncRequest 按 devops-cd.yaml 同步代码库应用配置
+type RepositoryManifestSyncRequest struct {
+	ID     int64 `json:"id" binding:"required"` // 必填：代码库ID
+	DryRun bool  `json:"dry_run"`               // true 时只计算漂移，不落库
+}
+
+// RepositoryManifestValidateRequest 校验 devops-cd.yaml（只计算漂移，不落库、不保存报告）
+type RepositoryManifestValidateRequest struct {
+	ID      int64  `json:"id" binding:"required"`           // 必填：代码库ID
+	Ref     string `json:"ref" binding:"omitempty,max=255"` // 可选：分支/tag/提交，为空时为默认分支
+	Content string `json:"content" binding:"max=1048576"`   // 可选：清单内容，不为空时直接校验该内容（不读取仓库）
+}
+
+// ManifestDriftItem 清单与平台配置的差异
+type ManifestDriftItem struct {
+	App      string      `json:"app"`
+	Env      string      `json:"env,omitempty"`
+	Cluster  string      `json:"cluster,omitempty"`
+	Field    string      `json:"field"`              // app / app_type / description / depends_on / env_cluster / replicas / deployment_name / values
+	Platform interface{} `json:"platform,omitempty"` // 平台当前值
+	Manifest interface{} `json:"manifest,omitempty"` // 清单期望值
+	Action   string      `json:"action"`             // create / update / orphan / conflict
+}
+
+// ManifestSyncReport 清单同步报告
+type ManifestSyncReport struct {
+	RepoID   int64               `json:"repo_id"`
+	Ref      string              `json:"ref,omitempty"`
+	Status   string              `json:"status"` // none / synced / invalid / error / skipped / discovered
+	Message  string              `json:"message,omitempty"`
+	DryRun   bool                `json:"dry_run"`
+	Drifts   []ManifestDriftItem `json:"drifts"`
+	SyncedAt time.Time           `json:"synced_at"`
+}
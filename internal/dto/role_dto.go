@@ -0,0 +1,49 @@
+package dto
+
+// CreateRoleRequest 创建自定义角色请求
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=50" example:"release_manager"`
+	DisplayName string   `json:"display_name" binding:"max=100" example:"发布经理"`
+	Description string   `json:"description" binding:"max=500"`
+	Permissions []string `json:"permissions" binding:"required,min=1,dive,max=100" example:"batch:*,env:prod:operate"`
+}
+
+// UpdateRoleRequest 更新自定义角色请求（permissions 非空时整体替换）
+type UpdateRoleRequest struct {
+	DisplayName *string  `json:"display_name" binding:"omitempty,max=100"`
+	Description *string  `json:"description" binding:"omitempty,max=500"`
+	Permissions []string `json:"permissions" binding:"omitempty,min=1,dive,max=100"`
+}
+
+// RoleResponse 角色（内置角色 id 为 0 且不可修改）
+type RoleResponse struct {
+	ID          int64    `json:"id"`
+	Name        string   `json:"name"`
+	DisplayName string   `json:"display_name"`
+	Description string   `json:"description"`
+	Builtin     bool     `json:"builtin"`
+	Permissions []string `json:"permissions"`
+	CreatedBy   string   `json:"created_by,omitempty"`
+	UpdatedBy   string   `json:"updated_by,omitempty"`
+	CreatedAt   string   `json:"created_at,omitempty"`
+	UpdatedAt   string   `json:"updated_at,omitempty"`
+}
+
+// AssignRolesRequest 分配角色（整体替换，传空数组表示清空）
+type AssignRolesRequest struct {
+	Roles []string `json:"roles" binding:"required,dive,max=50" example:"release_manager"`
+}
+
+// UserRolesResponse 用户系统角色
+type UserRolesResponse struct {
+	UserID   int64    `json:"user_id"`
+	Username string   `json:"username"`
+	Roles    []string `json:"roles"`
+}
+
+// TeamRolesResponse 团队角色（团队所有成员额外拥有）
+type TeamRolesResponse struct {
+	TeamID int64    `json:"team_id"`
+	Name   string   `json:"name"`
+	Roles  []string `json:"roles"`
+}
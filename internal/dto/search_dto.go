@@ -0,0 +1,46 @@
+package dto
+
+import "time"
+
+// SearchRequest 统一搜索（构建、批次、部署）
+type SearchRequest struct {
+	Q         string     `form:"q"`          // 全文: 批次号、commit SHA（前缀）/message、镜像 tag、deployment 名、错误信息
+	Types     string     `form:"types"`      // build,batch,deployment（逗号分隔），默认全部
+	ProjectID *int64     `form:"project_id"` // 为空时搜索有权限的所有项目
+	AppID     *int64     `form:"app_id"`
+	Commit    string     `form:"commit"`                                             // commit SHA 前缀：构建、包含该构建的批次、部署该构建的 Deployment
+	ImageTag  string     `form:"image_tag"`                                          // 镜像 tag（精确匹配），关联方式同 commit
+	Env       string     `form:"env" binding:"omitempty,oneof=pre prod"`             // 只作用于部署
+	Since     *time.Time `form:"since" time_format:"2006-01-02"`                     // 创建时间起（含）
+	Until     *time.Time `form:"until" time_format:"2006-01-02"`                     // 创建时间止（含当天）
+	Cursor    string     `form:"cursor"`                                             // 上一页返回的 next_cursor
+	Limit     int        `form:"limit,default=20" binding:"omitempty,min=1,max=100"` // 默认 20
+}
+
+// SearchResultItem 搜索结果，按 type 填充对应字段
+type SearchResultItem struct {
+	Type           string    `json:"type"` // build / batch / deployment
+	ID             int64     `json:"id"`
+	ProjectID      int64     `json:"project_id"`
+	AppID          int64     `json:"app_id,omitempty"`
+	AppName        string    `json:"app_name,omitempty"`
+	BatchID        int64     `json:"batch_id,omitempty"`
+	BatchNumber    string    `json:"batch_number,omitempty"`
+	Status         string    `json:"status"`
+	Env            string    `json:"env,omitempty"`
+	Cluster        string    `json:"cluster,omitempty"`
+	DeploymentName string    `json:"deployment_name,omitempty"`
+	CommitSHA      string    `json:"commit_sha,omitempty"`
+	CommitMessage  string    `json:"commit_message,omitempty"`
+	ImageTag       string    `json:"image_tag,omitempty"`
+	ErrorMessage   string    `json:"error_message,omitempty"`
+	Matched        []string  `json:"matched"` // q 命中的字段
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// SearchResponse 搜索结果（按创建时间倒序，游标分页）
+type SearchResponse struct {
+	Items      []*SearchResultItem `json:"items"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+	HasMore    bool                `json:"has_more"`
+}
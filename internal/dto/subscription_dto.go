@@ -0,0 +1,26 @@
+package dto
+
+// CreateSubscriptionRequest 订阅批次/应用请求（已订阅时更新通知渠道）
+type CreateSubscriptionRequest struct {
+	TargetType string   `json:"target_type" binding:"required,oneof=batch app" example:"batch"`
+	TargetID   int64    `json:"target_id" binding:"required" example:"1"`
+	Channels   []string `json:"channels"` // lark / email，为空表示所有已配置渠道
+}
+
+// SubscriptionListRequest 我的订阅列表请求
+type SubscriptionListRequest struct {
+	TargetType string `form:"target_type" binding:"omitempty,oneof=batch app"`
+	Page       int    `form:"page" example:"1"`
+	PageSize   int    `form:"page_size" example:"10"`
+}
+
+// SubscriptionResponse 订阅响应
+type SubscriptionResponse struct {
+	ID         int64    `json:"id"`
+	TargetType string   `json:"target_type"`
+	TargetID   int64    `json:"target_id"`
+	TargetName string   `json:"target_name"` // 批次编号 / 应用名
+	ProjectID  int64    `json:"project_id"`
+	Channels   []string `json:"channels"`
+	CreatedAt  string   `json:"created_at"`
+}
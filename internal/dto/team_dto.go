@@ -29,13 +29,14 @@ type GetTeamRequest struct {
 
 // TeamResponse 团队响应
 type TeamResponse struct {
-	ID          int64   `json:"id"`
-	Name        string  `json:"name"`
-	ProjectID   int64   `json:"project_id"`
-	Description *string `json:"description"`
-	LeaderName  *string `json:"leader_name"`
-	CreatedAt   string  `json:"created_at"`
-	UpdatedAt   string  `json:"updated_at"`
+	ID          int64    `json:"id"`
+	Name        string   `json:"name"`
+	ProjectID   int64    `json:"project_id"`
+	Description *string  `json:"description"`
+	LeaderName  *string  `json:"leader_name"`
+	Roles       []string `json:"roles"` // 团队角色（团队所有成员额外拥有）
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
 }
 
 // TeamSimpleResponse 团队简单响应（用于下拉选择）
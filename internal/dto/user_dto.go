@@ -12,3 +12,16 @@ type UserSimpleResponse struct {
 	DisplayName *string `json:"display_name,omitempty"`
 	Email       *string `json:"email,omitempty"`
 }
+
+// PermissionsQuery 当前用户权限查询
+type PermissionsQuery struct {
+	ProjectID int64 `form:"project_id" binding:"required"`
+}
+
+// PermissionsResponse 当前用户在项目下的有效角色与权限
+type PermissionsResponse struct {
+	ProjectID    int64    `json:"project_id"`
+	Roles        []string `json:"roles"`
+	Permissions  []string `json:"permissions"`
+	ProdOperator bool     `json:"prod_operator"` // 是否可执行 prod 操作（env:prod:operate）
+}
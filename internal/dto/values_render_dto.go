@@ -0,0 +1,42 @@
+package dto
+
+import "devops-cd/internal/model"
+
+// RenderValuesRequest values 渲染预览请求
+type RenderValuesRequest struct {
+	AppID    int64  `json:"app_id" binding:"required" example:"1"`
+	BuildID  *int64 `json:"build_id" example:"100"`                                       // 与 image_tag 二选一
+	ImageTag string `json:"image_tag" binding:"omitempty,max=100" example:"v1.2.0"`       // 无对应构建时仅以该 tag 渲染模板
+	Cluster  string `json:"cluster" binding:"omitempty,max=50" example:"cluster-prod-01"` // 为空时取应用在该环境的第一个集群
+	Kind     string `json:"kind" binding:"omitempty,oneof=app config" example:"app"`      // app(app_chart，默认) / config(config_chart)
+
+	ReleaseAppID *int64 `json:"release_app_id" example:"10"` // 指定时按该发布应用合并 values 覆盖与 chart lock
+}
+
+// RenderValuesResponse values 渲染预览结果（敏感字段已脱敏）
+type RenderValuesResponse struct {
+	ProjectID   int64                  `json:"project_id"`
+	AppID       int64                  `json:"app_id"`
+	AppName     string                 `json:"app_name"`
+	Env         string                 `json:"env"`
+	Cluster     string                 `json:"cluster"`
+	Kind        string                 `json:"kind"`
+	BuildID     *int64                 `json:"build_id,omitempty"` // 为空表示仅按 image_tag 渲染
+	ImageTag    string                 `json:"image_tag,omitempty"`
+	DriverType  string                 `json:"driver_type"`
+	Namespace   string                 `json:"namespace"`
+	ReleaseName string                 `json:"release_name"`
+	Layers      []RenderValuesLayer    `json:"layers"`           // 按合并顺序，后者覆盖前者
+	MergeOrder  []string               `json:"merge_order"`      // 如 artifacts[0]:git、app_env[2]:inline_yaml、runtime:image.tag、override:values_override
+	Values      map[string]interface{} `json:"values,omitempty"` // 最终合并结果，error 非空时为空
+	Error       string                 `json:"error,omitempty"`  // 某层加载/解析失败的错误
+}
+
+// RenderValuesLayer 单层 values
+type RenderValuesLayer struct {
+	Index  int                    `json:"index"`
+	Source string                 `json:"source"`          // artifacts / app_env / manifest / runtime / override
+	Layer  *model.ValuesLayer     `json:"layer,omitempty"` // 层配置（inline_yaml 内容不回传）
+	Empty  bool                   `json:"empty"`           // 内容为空，未参与合并
+	Values map[string]interface{} `json:"values,omitempty"`
+}
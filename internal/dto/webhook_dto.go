@@ -0,0 +1,72 @@
+package dto
+
+import "encoding/json"
+
+// CreateWebhookRequest 创建出站 Webhook 订阅请求
+type CreateWebhookRequest struct {
+	Name        string   `json:"name" binding:"required,max=100" example:"deploy-bot"`
+	ProjectID   *int64   `json:"project_id"` // 为空表示订阅所有项目
+	URL         string   `json:"url" binding:"required,max=500" example:"https://example.com/hooks/devops-cd"`
+	Events      []string `json:"events" binding:"required,min=1"` // 事件列表，["*"] 表示全部
+	Secret      *string  `json:"secret" binding:"omitempty,max=200"`
+	Enabled     *bool    `json:"enabled" example:"true"`
+	Description string   `json:"description" binding:"max=500"`
+}
+
+// UpdateWebhookRequest 更新出站 Webhook 订阅请求
+type UpdateWebhookRequest struct {
+	Name        *string  `json:"name" binding:"omitempty,max=100"`
+	ProjectID   *int64   `json:"project_id"` // 传 0 表示订阅所有项目
+	URL         *string  `json:"url" binding:"omitempty,max=500"`
+	Events      []string `json:"events"`
+	Secret      *string  `json:"secret" binding:"omitempty,max=200"` // 传空字符串表示取消签名
+	Enabled     *bool    `json:"enabled"`
+	Description *string  `json:"description" binding:"omitempty,max=500"`
+}
+
+// WebhookListRequest 订阅列表请求
+type WebhookListRequest struct {
+	ProjectID *int64 `form:"project_id"`
+	Page      int    `form:"page" example:"1"`
+	PageSize  int    `form:"page_size" example:"10"`
+}
+
+// WebhookResponse 出站 Webhook 订阅响应（不返回 secret）
+type WebhookResponse struct {
+	ID          int64    `json:"id"`
+	Name        string   `json:"name"`
+	ProjectID   *int64   `json:"project_id"`
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	HasSecret   bool     `json:"has_secret"`
+	Enabled     bool     `json:"enabled"`
+	Description string   `json:"description"`
+	CreatedBy   string   `json:"created_by"`
+	UpdatedBy   string   `json:"updated_by"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+}
+
+// WebhookDeliveryListRequest 投递记录列表请求
+type WebhookDeliveryListRequest struct {
+	Status   string `form:"status" binding:"omitempty,oneof=pending success failed"`
+	Event    string `form:"event"`
+	Page     int    `form:"page" example:"1"`
+	PageSize int    `form:"page_size" example:"10"`
+}
+
+// WebhookDeliveryResponse 投递记录
+type WebhookDeliveryResponse struct {
+	ID            int64           `json:"id"`
+	WebhookID     int64           `json:"webhook_id"`
+	Event         string          `json:"event"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt *string         `json:"next_attempt_at,omitempty"`
+	ResponseCode  int             `json:"response_code"`
+	ResponseBody  string          `json:"response_body,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	DeliveredAt   *string         `json:"delivered_at,omitempty"`
+	CreatedAt     string          `json:"created_at"`
+}
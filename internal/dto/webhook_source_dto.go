@@ -0,0 +1,79 @@
+package dto
+
+import (
+	"encoding/json"
+
+	"devops-cd/internal/model"
+)
+
+// CreateWebhookSourceRequest 创建通用 CI 构建通知来源请求
+type CreateWebhookSourceRequest struct {
+	Name          string               `json:"name" binding:"required,max=50" example:"jenkins"`
+	Description   string               `json:"description" binding:"max=500"`
+	Enabled       *bool                `json:"enabled" example:"true"`
+	Token         *string              `json:"token" binding:"omitempty,max=200"` // 可选：请求头 X-Webhook-Token 校验
+	Repos         []string             `json:"repos"`                             // 可选：允许通知的代码库（namespace/name，支持 * 通配）
+	Mapping       model.WebhookMapping `json:"mapping" binding:"required"`
+	SamplePayload json.RawMessage      `json:"sample_payload"` // 可选：测试 payload，保存前校验映射结果
+}
+
+// UpdateWebhookSourceRequest 更新通用 CI 构建通知来源请求
+type UpdateWebhookSourceRequest struct {
+	Description   *string               `json:"description" binding:"omitempty,max=500"`
+	Enabled       *bool                 `json:"enabled"`
+	Token         *string               `json:"token" binding:"omitempty,max=200"` // 传空字符串表示取消 token 校验
+	Repos         *[]string             `json:"repos"`                             // 传空数组表示不限制代码库
+	Mapping       *model.WebhookMapping `json:"mapping"`
+	SamplePayload json.RawMessage       `json:"sample_payload"`
+}
+
+// WebhookSourceListRequest 来源列表请求
+type WebhookSourceListRequest struct {
+	Page     int `form:"page" example:"1"`
+	PageSize int `form:"page_size" example:"10"`
+}
+
+// WebhookSourceResponse 通用 CI 构建通知来源响应（不返回 token）
+type WebhookSourceResponse struct {
+	ID            int64                `json:"id"`
+	Name          string               `json:"name"`
+	Description   string               `json:"description"`
+	Enabled       bool                 `json:"enabled"`
+	HasToken      bool                 `json:"has_token"`
+	Repos         []string             `json:"repos"`
+	NotifyPath    string               `json:"notify_path"` // 外部 CI 调用路径
+	Mapping       model.WebhookMapping `json:"mapping"`
+	SamplePayload json.RawMessage      `json:"sample_payload,omitempty"`
+	CreatedBy     string               `json:"created_by"`
+	UpdatedBy     string               `json:"updated_by"`
+	CreatedAt     string               `json:"created_at"`
+	UpdatedAt     string               `json:"updated_at"`
+}
+
+// WebhookPreviewRequest 使用已保存映射预览（payload 为空时使用 sample_payload）
+type WebhookPreviewRequest struct {
+	Payload json.RawMessage `json:"payload"`
+}
+
+// WebhookMappingPreviewRequest 未保存的映射预览
+type WebhookMappingPreviewRequest struct {
+	Mapping model.WebhookMapping `json:"mapping" binding:"required"`
+	Payload json.RawMessage      `json:"payload" binding:"required"`
+}
+
+// WebhookPreviewResponse 映射预览结果
+type WebhookPreviewResponse struct {
+	Valid   bool                 `json:"valid"`   // 映射结果是否为合法的构建通知
+	Request *BuildNotifyRequest  `json:"request"` // 映射后的构建通知
+	Fields  []WebhookFieldResult `json:"fields"`  // 各字段解析明细
+	Errors  []string             `json:"errors,omitempty"`
+}
+
+// WebhookFieldResult 单个字段解析结果
+type WebhookFieldResult struct {
+	Field  string      `json:"field"` // 字段名，应用字段形如 apps[0].name
+	Path   string      `json:"path,omitempty"`
+	Value  interface{} `json:"value,omitempty"`
+	Source string      `json:"source"` // path/default/fallback/missing
+	Error  string      `json:"error,omitempty"`
+}
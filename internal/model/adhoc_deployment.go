@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+const AdhocDeploymentTableName = "adhoc_deployments"
+
+// AdhocDeployment 临时部署：不组建批次，直接将应用的指定构建部署到指定环境的集群（紧急修复）
+//
+// 每个集群创建一条 Deployment（adhoc_id 关联，batch_id/release_id 为 0），由核心引擎按同一 Deployment 状态机推进；
+// Deployment 全部结束后汇总为 success/failed（见 constants.AdhocDeploymentStatus*）
+type AdhocDeployment struct {
+	BaseModel
+
+	ProjectID int64      `gorm:"not null;index" json:"project_id"`
+	AppID     int64      `gorm:"not null;index" json:"app_id"`
+	Env       string     `gorm:"size:20;not null" json:"env"`
+	BuildID   int64      `gorm:"not null" json:"build_id"`
+	ImageTag  string     `gorm:"size:100;not null" json:"image_tag"`
+	Clusters  StringList `gorm:"type:json" json:"clusters"`
+
+	Status       string     `gorm:"size:20;not null;default:running" json:"status"`
+	Reason       string     `gorm:"type:text;not null" json:"reason"` // 发起原因（必填）
+	ErrorMessage *string    `gorm:"type:text" json:"error_message"`
+	CreatedBy    string     `gorm:"size:50;not null" json:"created_by"`
+	FinishedAt   *time.Time `json:"finished_at"`
+
+	Application *Application `gorm:"foreignKey:AppID" json:"application,omitempty"`
+}
+
+func (AdhocDeployment) TableName() string {
+	return AdhocDeploymentTableName
+}
@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+const AnnouncementTableName = "announcements"
+
+// Announcement 全局公告（平台维护、发布冻结等）
+//
+// 说明：
+// - enabled=true 且处于 [starts_at, ends_at) 时间窗口内视为生效；starts_at/ends_at 为空表示不限制
+// - level=freeze 为发布冻结通知，仅做提示，不拦截发布流程
+type Announcement struct {
+	BaseModelWithSoftDelete
+
+	Level   string `gorm:"size:16;not null;default:info" json:"level"` // info/warning/freeze
+	Title   string `gorm:"size:200;not null" json:"title"`
+	Content string `gorm:"type:text" json:"content"`
+	Enabled bool   `gorm:"not null;default:true" json:"enabled"`
+
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+
+	CreatedBy string `gorm:"size:50" json:"created_by"`
+	UpdatedBy string `gorm:"size:50" json:"updated_by"`
+}
+
+func (Announcement) TableName() string {
+	return AnnouncementTableName
+}
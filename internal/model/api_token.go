@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+const APITokenTableName = "api_tokens"
+
+// APIToken 集成方使用的只读 API Token
+//
+// 明文 token 只在创建时返回一次，库中保存 sha256 摘要；scopes 为授予的只读权限，project_ids 为空表示不限项目
+type APIToken struct {
+	BaseModel
+
+	Name        string     `gorm:"size:100;not null" json:"name"`
+	Description string     `gorm:"size:500" json:"description"`
+	TokenPrefix string     `gorm:"size:16;not null" json:"token_prefix"`
+	TokenHash   string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Scopes      StringList `gorm:"type:json" json:"scopes"`
+	ProjectIDs  Int64List  `gorm:"column:project_ids;type:json" json:"project_ids"`
+
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `gorm:"column:last_used_ip;size:64" json:"last_used_ip"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	RevokedBy  string     `gorm:"size:50" json:"revoked_by"`
+	CreatedBy  string     `gorm:"size:50" json:"created_by"`
+}
+
+func (APIToken) TableName() string {
+	return APITokenTableName
+}
+
+// Expired 是否已过期
+func (t *APIToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// HasProject 是否可访问项目（未限定项目时均可访问）
+func (t *APIToken) HasProject(projectID int64) bool {
+	if len(t.ProjectIDs) == 0 {
+		return true
+	}
+	for _, id := range t.ProjectIDs {
+		if id == projectID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const AppDecommissionTableName = "app_decommissions"
+
+// DecommissionTarget 应用下线时需要卸载的 release（各环境/集群/部署类型最近一次部署写入的 release）
+type DecommissionTarget struct {
+	Env          string `json:"env"`
+	ClusterName  string `json:"cluster_name"`
+	Kind         string `json:"kind"` // app/config
+	Namespace    string `json:"namespace"`
+	ReleaseName  string `json:"release_name"`
+	DriverType   string `json:"driver_type"`
+	DeploymentID int64  `json:"deployment_id"`
+	Status       string `json:"status"` // pending/uninstalled/not_found/skipped/failed，见 constants.DecommissionTarget*
+	Message      string `json:"message,omitempty"`
+}
+
+// DecommissionTargets 卸载目标列表
+type DecommissionTargets []DecommissionTarget
+
+// Scan 实现 sql.Scanner
+func (l *DecommissionTargets) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*l = DecommissionTargets{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return fmt.Errorf("cannot scan %T into DecommissionTargets", value)
+	}
+}
+
+// Value 实现 driver.Valuer
+func (l DecommissionTargets) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(l)
+}
+
+// AppDecommission 应用下线：（可选）卸载应用在各环境/集群的 release，归档构建记录并删除应用
+//
+// 由核心引擎扫描推进：逐个卸载 targets，全部成功（或无需卸载）后在同一事务内归档构建、软删除应用并置为 success；
+// 任一卸载失败置为 failed，应用保留，可再次发起（见 constants.AppDecommissionStatus*）
+type AppDecommission struct {
+	BaseModel
+
+	ProjectID int64               `gorm:"not null;index" json:"project_id"`
+	AppID     int64               `gorm:"not null;index" json:"app_id"`
+	AppName   string              `gorm:"size:100;not null" json:"app_name"` // 应用删除后仍可展示
+	Uninstall bool                `gorm:"not null;default:false" json:"uninstall"`
+	Targets   DecommissionTargets `gorm:"type:json" json:"targets"`
+
+	Status         string     `gorm:"size:20;not null;default:running" json:"status"`
+	ArchivedBuilds int64      `gorm:"not null;default:0" json:"archived_builds"`
+	Reason         string     `gorm:"type:text;not null" json:"reason"`
+	ErrorMessage   *string    `gorm:"type:text" json:"error_message"`
+	CreatedBy      string     `gorm:"size:50;not null" json:"created_by"`
+	FinishedAt     *time.Time `json:"finished_at"`
+}
+
+func (AppDecommission) TableName() string {
+	return AppDecommissionTableName
+}
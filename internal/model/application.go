@@ -14,11 +14,16 @@ type Application struct {
 	ProjectID int64  `gorm:"column:project_id;not null;uniqueIndex:uk_project_app_name;index" json:"project_id"`
 	TeamID    *int64 `gorm:"index" json:"team_id"`
 
-	Name             string    `gorm:"size:100;not null;uniqueIndex:uk_project_app_name" json:"name"`
-	Description      *string   `gorm:"type:text" json:"description"`
-	AppType          string    `gorm:"size:50;not null;index" json:"app_type"`
-	DeployedTag      *string   `gorm:"column:deployed_tag;size:100" json:"deployed_tag"`              // 当前部署的镜像标签
-	DefaultDependsOn Int64List `gorm:"column:default_depends_on;type:json" json:"default_depends_on"` // DefaultDependsOn 配置级依赖（JSON 数组，记录应用 ID）
+	Name             string         `gorm:"size:100;not null;uniqueIndex:uk_project_app_name" json:"name"`
+	Description      *string        `gorm:"type:text" json:"description"`
+	AppType          string         `gorm:"size:50;not null;index" json:"app_type"`
+	DeployedTag      *string        `gorm:"column:deployed_tag;size:100" json:"deployed_tag"`              // 当前部署的镜像标签
+	DefaultDependsOn Int64List      `gorm:"column:default_depends_on;type:json" json:"default_depends_on"` // DefaultDependsOn 配置级依赖（JSON 数组，记录应用 ID）
+	TagFilter        *TagFilter     `gorm:"column:tag_filter;type:json" json:"tag_filter"`                 // 构建过滤规则，为空表示接受所有成功构建
+	PathFilter       *PathFilter    `gorm:"column:path_filter;type:json" json:"path_filter"`               // 单仓多应用的路径映射，按变更路径分发构建通知，为空表示任意变更都匹配
+	RegionRollout    *RegionRollout `gorm:"column:region_rollout;type:json" json:"region_rollout"`         // 多 region 部署协调，为空表示所有集群并发部署
+	DeployVerify     *DeployVerify  `gorm:"column:deploy_verify;type:json" json:"deploy_verify"`           // 部署后验证（HTTP 探测 / Prometheus 指标），为空表示就绪即成功
+	AutoRollback     *bool          `gorm:"column:auto_rollback" json:"auto_rollback"`                     // 生产就绪/验证失败自动回滚失败集群，为空继承项目配置
 
 	// Relations
 	Repository *Repository    `gorm:"foreignKey:RepoID" json:"repository,omitempty"`
@@ -31,6 +36,14 @@ func (Application) TableName() string {
 	return ApplicationTableName
 }
 
+// AutoRollbackEnabled 是否开启生产自动回滚：应用未配置时继承项目（project 需已加载）
+func (a *Application) AutoRollbackEnabled() bool {
+	if a.AutoRollback != nil {
+		return *a.AutoRollback
+	}
+	return a.Project != nil && a.Project.AutoRollback
+}
+
 // ApplicationWithBuild 应用及其最新构建信息（用于搜索和列表展示）
 type ApplicationWithBuild struct {
 	Application // 嵌入 Application，继承所有字段
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const BatchApprovalTableName = "batch_approvals"
+
+// ApprovalPolicy 项目批次审批策略（多人、分阶段审批）
+//
+// 阶段按顺序进行：前一阶段达到 required 个审批人通过后才进入下一阶段，全部阶段通过后批次 approval_status 才变为 approved。
+// 任一阶段审批人拒绝，批次直接 rejected。未配置阶段时沿用单人审批。
+//
+// 示例：QA 任意一人 → 发布经理两人中的两人：
+//
+//	{"stages": [{"name": "QA", "approvers": ["alice", "bob"], "required": 1},
+//	            {"name": "release manager", "approvers": ["carol", "dave"], "required": 2}]}
+type ApprovalPolicy struct {
+	Stages []ApprovalStage `json:"stages"`
+}
+
+// ApprovalStage 审批阶段（N-of-M）
+type ApprovalStage struct {
+	Name      string   `json:"name"`
+	Approvers []string `json:"approvers"` // 可审批用户名
+	Required  int      `json:"required"`  // 需要通过的人数，默认 1
+}
+
+// MaxApprovalStages 审批阶段数上限
+const MaxApprovalStages = 10
+
+// IsEnabled 是否配置了多人审批
+func (p *ApprovalPolicy) IsEnabled() bool {
+	return p != nil && len(p.Stages) > 0
+}
+
+// Normalize 去除空白/重复审批人，required 缺省为 1
+func (p *ApprovalPolicy) Normalize() {
+	if p == nil {
+		return
+	}
+	for i := range p.Stages {
+		stage := &p.Stages[i]
+		stage.Name = strings.TrimSpace(stage.Name)
+		seen := make(map[string]bool, len(stage.Approvers))
+		approvers := stage.Approvers[:0]
+		for _, a := range stage.Approvers {
+			a = strings.TrimSpace(a)
+			if a == "" || seen[a] {
+				continue
+			}
+			seen[a] = true
+			approvers = append(approvers, a)
+		}
+		stage.Approvers = approvers
+		if stage.Required <= 0 {
+			stage.Required = 1
+		}
+	}
+}
+
+// Validate 校验策略
+func (p *ApprovalPolicy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	if len(p.Stages) > MaxApprovalStages {
+		return fmt.Errorf("审批阶段不能超过 %d 个", MaxApprovalStages)
+	}
+	for i, stage := range p.Stages {
+		if stage.Name == "" {
+			return fmt.Errorf("第 %d 个审批阶段名称不能为空", i+1)
+		}
+		if len(stage.Approvers) == 0 {
+			return fmt.Errorf("审批阶段 %s 未配置审批人", stage.Name)
+		}
+		if stage.Required > len(stage.Approvers) {
+			return fmt.Errorf("审批阶段 %s 需要 %d 人通过，但只配置了 %d 个审批人", stage.Name, stage.Required, len(stage.Approvers))
+		}
+	}
+	return nil
+}
+
+// IsApprover 用户是否为阶段审批人
+func (s *ApprovalStage) IsApprover(username string) bool {
+	for _, a := range s.Approvers {
+		if a == username {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan 实现 sql.Scanner
+func (p *ApprovalPolicy) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*p = ApprovalPolicy{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	default:
+		return fmt.Errorf("cannot scan %T into ApprovalPolicy", value)
+	}
+}
+
+// Value 实现 driver.Valuer
+func (p ApprovalPolicy) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+// BatchApproval 批次审批记录（每个阶段每个审批人一条）
+type BatchApproval struct {
+	BaseModel
+
+	BatchID   int64   `gorm:"not null;uniqueIndex:uk_batch_stage_approver" json:"batch_id"`
+	Stage     int     `gorm:"not null;uniqueIndex:uk_batch_stage_approver" json:"stage"` // 阶段序号（从 0 开始）
+	StageName string  `gorm:"size:100;not null" json:"stage_name"`
+	Approver  string  `gorm:"size:50;not null;uniqueIndex:uk_batch_stage_approver" json:"approver"`
+	Decision  string  `gorm:"size:20;not null" json:"decision"` // approved/rejected，见 constants.ApprovalStatus*
+	Comment   *string `gorm:"type:text" json:"comment"`
+}
+
+// TableName 指定表名
+func (BatchApproval) TableName() string {
+	return BatchApprovalTableName
+}
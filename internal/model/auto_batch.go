@@ -0,0 +1,95 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// AutoBatchRule 项目自动建批规则：成功构建的 Git tag 匹配 tag_pattern 时，自动把应用加入（或创建）草稿批次
+//
+// 同一项目内按渲染出的批次编号归组，即同一发布约定下多个应用的构建会进入同一个批次。
+//
+// 示例：release/2024.06.* 的 tag 按月份归入同一批次：
+//
+//	{"tag_pattern": "release/2024.06.*", "batch_number": "{{.Project}}-2024.06"}
+type AutoBatchRule struct {
+	TagPattern  string `json:"tag_pattern"`            // tag 通配符（path.Match 语法，* 不匹配 /）
+	BatchNumber string `json:"batch_number,omitempty"` // 批次编号模板（Go template，变量 .Project .Tag .Date），默认 {{.Tag}}
+}
+
+// DefaultAutoBatchNumber 未配置批次编号模板时使用 tag 作为批次编号
+const DefaultAutoBatchNumber = "{{.Tag}}"
+
+// IsEnabled 是否配置了自动建批
+func (r *AutoBatchRule) IsEnabled() bool {
+	return r != nil && r.TagPattern != ""
+}
+
+// Normalize 去除空白
+func (r *AutoBatchRule) Normalize() {
+	if r == nil {
+		return
+	}
+	r.TagPattern = strings.TrimSpace(r.TagPattern)
+	r.BatchNumber = strings.TrimSpace(r.BatchNumber)
+}
+
+// Validate 校验通配符与批次编号模板
+func (r *AutoBatchRule) Validate() error {
+	if !r.IsEnabled() {
+		return nil
+	}
+	if _, err := path.Match(r.TagPattern, ""); err != nil {
+		return fmt.Errorf("tag_pattern 不合法 %q: %w", r.TagPattern, err)
+	}
+	if r.BatchNumber != "" {
+		if _, err := template.New("batch_number").Parse(r.BatchNumber); err != nil {
+			return fmt.Errorf("batch_number 模板解析失败: %w", err)
+		}
+	}
+	return nil
+}
+
+// Match 判断 tag 是否匹配规则
+func (r *AutoBatchRule) Match(tag string) bool {
+	if !r.IsEnabled() || tag == "" {
+		return false
+	}
+	ok, err := path.Match(r.TagPattern, tag)
+	return err == nil && ok
+}
+
+// Scan 实现 sql.Scanner
+func (r *AutoBatchRule) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*r = AutoBatchRule{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, r)
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	default:
+		return fmt.Errorf("cannot scan %T into AutoBatchRule", value)
+	}
+}
+
+// Value 实现 driver.Valuer
+func (r AutoBatchRule) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// GitTag 构建对应的 Git tag（refs/tags/ 前缀的 commit_ref），非 tag 构建返回空
+func (b *Build) GitTag() string {
+	if tag, ok := strings.CutPrefix(b.CommitRef, "refs/tags/"); ok {
+		return tag
+	}
+	if b.BuildEvent == "tag" {
+		return b.CommitRef
+	}
+	return ""
+}
@@ -0,0 +1,21 @@
+package model
+
+const BatchCommentTableName = "batch_comments"
+
+// BatchComment 批次评论（release_app_id 非空时为对发布应用的评论），作者可编辑/删除
+type BatchComment struct {
+	BaseModel
+
+	BatchID      int64  `gorm:"not null;index" json:"batch_id"`
+	ProjectID    int64  `gorm:"not null" json:"project_id"`
+	ReleaseAppID *int64 `gorm:"index" json:"release_app_id"`
+	AppID        *int64 `json:"app_id"`
+	Author       string `gorm:"size:50;not null" json:"author"`
+	Content      string `gorm:"type:text;not null" json:"content"`
+
+	Application *Application `gorm:"foreignKey:AppID" json:"application,omitempty"`
+}
+
+func (BatchComment) TableName() string {
+	return BatchCommentTableName
+}
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+const BatchEventTableName = "batch_events"
+
+// BatchEvent 批次状态变更审计事件（只增不改），由批次状态机在状态变更事务内写入
+type BatchEvent struct {
+	ID         int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	BatchID    int64     `gorm:"not null;index" json:"batch_id"`
+	ProjectID  int64     `gorm:"not null" json:"project_id"`
+	FromStatus int8      `gorm:"not null" json:"from_status"`
+	ToStatus   int8      `gorm:"not null" json:"to_status"`
+	Trigger    string    `gorm:"size:20;not null" json:"trigger"` // manual / scheduled / auto
+	Operator   *string   `gorm:"size:50" json:"operator"`
+	Reason     *string   `gorm:"type:text" json:"reason"`
+	CreatedAt  time.Time `gorm:"not null;autoCreateTime" json:"created_at"`
+}
+
+func (BatchEvent) TableName() string {
+	return BatchEventTableName
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const BatchAppImpactTableName = "batch_app_impacts"
+
+// ResourceUsage 某个 release 在集群中的资源声明（按副本数汇总 requests/limits）
+type ResourceUsage struct {
+	Workloads          int   `json:"workloads"`            // 工作负载数（Deployment/StatefulSet/DaemonSet）
+	Replicas           int64 `json:"replicas"`             // 期望副本数合计
+	CPURequestMilli    int64 `json:"cpu_request_milli"`    // CPU requests（毫核）
+	CPULimitMilli      int64 `json:"cpu_limit_milli"`      // CPU limits（毫核）
+	MemoryRequestBytes int64 `json:"memory_request_bytes"` // 内存 requests（字节）
+	MemoryLimitBytes   int64 `json:"memory_limit_bytes"`   // 内存 limits（字节）
+}
+
+// Sub 返回 u - o
+func (u ResourceUsage) Sub(o ResourceUsage) ResourceUsage {
+	return ResourceUsage{
+		Workloads:          u.Workloads - o.Workloads,
+		Replicas:           u.Replicas - o.Replicas,
+		CPURequestMilli:    u.CPURequestMilli - o.CPURequestMilli,
+		CPULimitMilli:      u.CPULimitMilli - o.CPULimitMilli,
+		MemoryRequestBytes: u.MemoryRequestBytes - o.MemoryRequestBytes,
+		MemoryLimitBytes:   u.MemoryLimitBytes - o.MemoryLimitBytes,
+	}
+}
+
+// Add 返回 u + o
+func (u ResourceUsage) Add(o ResourceUsage) ResourceUsage {
+	return ResourceUsage{
+		Workloads:          u.Workloads + o.Workloads,
+		Replicas:           u.Replicas + o.Replicas,
+		CPURequestMilli:    u.CPURequestMilli + o.CPURequestMilli,
+		CPULimitMilli:      u.CPULimitMilli + o.CPULimitMilli,
+		MemoryRequestBytes: u.MemoryRequestBytes + o.MemoryRequestBytes,
+		MemoryLimitBytes:   u.MemoryLimitBytes + o.MemoryLimitBytes,
+	}
+}
+
+// Scan 实现 sql.Scanner
+func (u *ResourceUsage) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*u = ResourceUsage{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, u)
+	case string:
+		return json.Unmarshal([]byte(v), u)
+	default:
+		return fmt.Errorf("cannot scan %T into ResourceUsage", value)
+	}
+}
+
+// Value 实现 driver.Valuer
+func (u ResourceUsage) Value() (driver.Value, error) {
+	return json.Marshal(u)
+}
+
+// BatchAppImpact 批次内应用在某环境集群上的部署影响（部署前 vs 批次完成后）
+//
+// - before：该 env/cluster 首次执行 main 阶段前采集（重试/切换版本不覆盖）
+// - after：批次完成（FinalAccepted → Completed）后采集
+type BatchAppImpact struct {
+	BaseModel
+
+	BatchID     int64  `gorm:"column:batch_id;not null;uniqueIndex:uk_batch_app_env_cluster" json:"batch_id"`
+	AppID       int64  `gorm:"column:app_id;not null;uniqueIndex:uk_batch_app_env_cluster" json:"app_id"`
+	Env         string `gorm:"column:env;size:20;not null;uniqueIndex:uk_batch_app_env_cluster" json:"env"`
+	ClusterName string `gorm:"column:cluster;size:63;not null;uniqueIndex:uk_batch_app_env_cluster" json:"cluster_name"`
+	Namespace   string `gorm:"size:63;not null" json:"namespace"`
+	ReleaseName string `gorm:"column:release_name;size:63;not null" json:"release_name"`
+
+	Before            *ResourceUsage `gorm:"type:json" json:"before"`
+	BeforeCollectedAt *time.Time     `json:"before_collected_at"`
+	After             *ResourceUsage `gorm:"type:json" json:"after"`
+	AfterCollectedAt  *time.Time     `json:"after_collected_at"`
+
+	ErrorMessage *string `gorm:"type:text" json:"error_message"`
+
+	// Relations
+	Application *Application `gorm:"foreignKey:AppID" json:"application,omitempty"`
+}
+
+func (BatchAppImpact) TableName() string {
+	return BatchAppImpactTableName
+}
@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+const BatchIncidentTableName = "batch_incidents"
+const BatchRollbackTableName = "batch_rollbacks"
+
+// BatchIncident 批次发布后的故障记录（复盘数据，用于变更失败率统计）
+type BatchIncident struct {
+	BaseModel
+
+	BatchID         int64     `gorm:"index;not null" json:"batch_id"`
+	ProjectID       int64     `gorm:"index;not null" json:"project_id"` // 冗余批次所属项目，便于按项目统计
+	Severity        string    `gorm:"size:10;not null" json:"severity"` // sev1~sev4，见 constants.IncidentSeverity*
+	Title           string    `gorm:"size:200;not null" json:"title"`
+	Description     *string   `gorm:"type:text" json:"description"`
+	StartedAt       time.Time `gorm:"not null" json:"started_at"`
+	DurationMinutes int       `gorm:"not null;default:0" json:"duration_minutes"`         // 故障持续时长（分钟），用于 MTTR
+	AppIDs          Int64List `gorm:"column:app_ids;type:json;default:[]" json:"app_ids"` // 相关应用（需在批次内）
+	CreatedBy       string    `gorm:"size:50" json:"created_by"`
+}
+
+// TableName 指定表名
+func (BatchIncident) TableName() string {
+	return BatchIncidentTableName
+}
+
+// BatchRollback 批次发布后执行的回滚记录
+type BatchRollback struct {
+	BaseModel
+
+	BatchID      int64     `gorm:"index;not null" json:"batch_id"`
+	ProjectID    int64     `gorm:"index;not null" json:"project_id"`
+	AppID        int64     `gorm:"index;not null" json:"app_id"`
+	Env          string    `gorm:"size:20;not null" json:"env"`
+	FromTag      *string   `gorm:"size:100" json:"from_tag"` // 回滚前版本（默认取批次目标版本）
+	ToTag        *string   `gorm:"size:100" json:"to_tag"`   // 回滚后版本（默认取批次发布前版本）
+	Reason       *string   `gorm:"type:text" json:"reason"`
+	RolledBackAt time.Time `gorm:"not null" json:"rolled_back_at"`
+	CreatedBy    string    `gorm:"size:50" json:"created_by"`
+
+	// 平台执行的回滚（人工补录时为空 / recorded）
+	ReleaseID    *int64     `gorm:"column:release_id;index" json:"release_id"`
+	Status       string     `gorm:"size:20;not null;default:recorded" json:"status"` // 见 constants.BatchRollbackStatus*
+	FinishedAt   *time.Time `gorm:"column:finished_at" json:"finished_at"`
+	ErrorMessage *string    `gorm:"type:text" json:"error_message"`
+
+	Application *Application `gorm:"foreignKey:AppID" json:"application,omitempty"`
+}
+
+// TableName 指定表名
+func (BatchRollback) TableName() string {
+	return BatchRollbackTableName
+}
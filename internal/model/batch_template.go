@@ -0,0 +1,70 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+const BatchTemplateTableName = "batch_templates"
+
+// BatchTemplate 批次模板（固定周期发布的应用集合），可据此一键创建草稿批次
+type BatchTemplate struct {
+	BaseModel
+
+	ProjectID    int64             `gorm:"not null;uniqueIndex:uk_project_name" json:"project_id"`
+	Name         string            `gorm:"size:100;not null;uniqueIndex:uk_project_name" json:"name"`
+	Description  *string           `gorm:"type:text" json:"description"`
+	BatchNumber  *string           `gorm:"size:200" json:"batch_number"`   // 批次编号模板（Go template），创建时未指定批次编号时使用
+	ReleaseNotes *string           `gorm:"type:text" json:"release_notes"` // 发布说明脚手架（Go template）
+	Apps         BatchTemplateApps `gorm:"type:json;not null" json:"apps"`
+	CreatedBy    string            `gorm:"size:50" json:"created_by"`
+	UpdatedBy    string            `gorm:"size:50" json:"updated_by"`
+}
+
+// TableName 指定表名
+func (BatchTemplate) TableName() string {
+	return BatchTemplateTableName
+}
+
+// BatchTemplateApp 模板中的应用
+type BatchTemplateApp struct {
+	AppID         int64   `json:"app_id"`
+	PreOnly       bool    `json:"pre_only,omitempty"`
+	ReleaseNotes  *string `json:"release_notes,omitempty"`   // 应用级发布说明脚手架
+	TempDependsOn []int64 `json:"temp_depends_on,omitempty"` // 批次内临时依赖（需为模板内应用）
+}
+
+type BatchTemplateApps []BatchTemplateApp
+
+// AppIDs 模板内应用 ID
+func (a BatchTemplateApps) AppIDs() []int64 {
+	ids := make([]int64, len(a))
+	for i, app := range a {
+		ids[i] = app.AppID
+	}
+	return ids
+}
+
+// Scan 实现 sql.Scanner
+func (a *BatchTemplateApps) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*a = BatchTemplateApps{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	default:
+		return fmt.Errorf("cannot scan %T into BatchTemplateApps", value)
+	}
+}
+
+// Value 实现 driver.Valuer
+func (a BatchTemplateApps) Value() (driver.Value, error) {
+	if len(a) == 0 {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(a)
+}
@@ -1,6 +1,11 @@
 package model
 
-import "time"
+import (
+	"devops-cd/pkg/constants"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 const BuildTableName = "builds"
 
@@ -35,9 +40,20 @@ type Build struct {
 	ImageURL        string `gorm:"column:image_url;size:500" json:"image_url"`
 	AppBuildSuccess bool   `gorm:"not null;default:true" json:"app_build_success"`
 
+	// 镜像仓库推送通知（Harbor）确认的镜像，未收到通知时为空
+	ImageDigest   *string    `gorm:"column:image_digest;size:100" json:"image_digest"`
+	ImagePushedAt *time.Time `gorm:"column:image_pushed_at" json:"image_pushed_at"`
+
+	// 来源校验（tag/commit/分支与代码库是否一致），未校验时为空
+	ProvenanceStatus  *string `gorm:"size:20;index" json:"provenance_status"`
+	ProvenanceMessage *string `gorm:"size:500" json:"provenance_message"`
+
 	// 环境信息
 	Environment string `gorm:"size:50" json:"environment"`
 
+	// 应用下线时归档（构建记录保留，仅标记），为空表示未归档
+	ArchivedAt *time.Time `gorm:"column:archived_at" json:"archived_at,omitempty"`
+
 	// 时间戳
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -51,3 +67,13 @@ type Build struct {
 func (Build) TableName() string {
 	return BuildTableName
 }
+
+// IsProvenanceMismatch 来源校验是否不一致
+func (b *Build) IsProvenanceMismatch() bool {
+	return b.ProvenanceStatus != nil && *b.ProvenanceStatus == constants.BuildProvenanceMismatch
+}
+
+// TrustedBuilds gorm scope：排除来源校验不一致的构建
+func TrustedBuilds(db *gorm.DB) *gorm.DB {
+	return db.Where("provenance_status IS NULL OR provenance_status <> ?", constants.BuildProvenanceMismatch)
+}
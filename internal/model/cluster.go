@@ -1,10 +1,24 @@
 package model
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
 const ClusterTableName = "clusters"
 const AppEnvConfigTableName = "app_env_configs"
 
+// 集群认证方式
+const (
+	ClusterAuthKubeconfig = "kubeconfig" // 完整 kubeconfig（默认）
+	ClusterAuthToken      = "token"      // API Server 地址 + CA + Bearer token（如 ServiceAccount token）
+	ClusterAuthExec       = "exec"       // API Server 地址 + CA + exec 凭据插件（aws eks get-token、gke-gcloud-auth-plugin、kubelogin 等 OIDC 方式）
+)
+
 // Cluster 集群元数据模型
-// 用途: 管理集群基本信息,不包含连接配置
+// 用途: 管理集群基本信息与连接配置，部署时由 Helm driver 按认证方式生成 RESTClientGetter
 type Cluster struct {
 	BaseModel
 
@@ -12,13 +26,103 @@ type Cluster struct {
 	Description *string `gorm:"type:text" json:"description"`
 	Region      *string `gorm:"size:50" json:"region"`
 
+	AuthType   string `gorm:"size:20;not null;default:kubeconfig" json:"auth_type"` // kubeconfig/token/exec，空值视为 kubeconfig
 	Kubeconfig string `gorm:"type:text" json:"kubeconfig,omitempty"`
+
+	// token / exec 方式的连接配置
+	Server                string             `gorm:"size:255" json:"server,omitempty"`                                                       // API Server 地址
+	CAData                string             `gorm:"column:ca_data;type:text" json:"ca_data,omitempty"`                                      // CA 证书（PEM），为空使用系统 CA
+	InsecureSkipTLSVerify bool               `gorm:"column:insecure_skip_tls_verify;not null;default:false" json:"insecure_skip_tls_verify"` // 跳过证书校验（仅测试集群）
+	Token                 string             `gorm:"type:text" json:"-"`                                                                     // Bearer token
+	Exec                  *ClusterExecConfig `gorm:"column:exec_config;type:json" json:"exec_config,omitempty"`                              // exec 凭据插件
 }
 
 func (Cluster) TableName() string {
 	return ClusterTableName
 }
 
+// ClusterExecConfig exec 凭据插件配置（对应 kubeconfig users[].user.exec），命令需在服务运行环境中可执行
+//
+// 示例（EKS）：
+//
+//	{"command": "aws", "args": ["eks", "get-token", "--cluster-name", "prod"], "env": {"AWS_REGION": "us-east-1"}}
+type ClusterExecConfig struct {
+	APIVersion string            `json:"api_version,omitempty"` // 默认 client.authentication.k8s.io/v1beta1
+	Command    string            `json:"command"`
+	Args       []string          `json:"args,omitempty"`
+	Env        map[string]string `json:"env,omitempty"`
+}
+
+// DefaultExecAPIVersion exec 凭据插件默认 API 版本
+const DefaultExecAPIVersion = "client.authentication.k8s.io/v1beta1"
+
+// Scan 实现 sql.Scanner
+func (e *ClusterExecConfig) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*e = ClusterExecConfig{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, e)
+	case string:
+		return json.Unmarshal([]byte(v), e)
+	default:
+		return fmt.Errorf("cannot scan %T into ClusterExecConfig", value)
+	}
+}
+
+// Value 实现 driver.Valuer
+func (e ClusterExecConfig) Value() (driver.Value, error) {
+	return json.Marshal(e)
+}
+
+// Auth 认证方式，未设置时为 kubeconfig
+func (c *Cluster) Auth() string {
+	if c.AuthType == "" {
+		return ClusterAuthKubeconfig
+	}
+	return c.AuthType
+}
+
+// HasCredentials 是否配置了连接凭据（不校验有效性）
+func (c *Cluster) HasCredentials() bool {
+	if c == nil {
+		return false
+	}
+	switch c.Auth() {
+	case ClusterAuthToken:
+		return c.Server != "" && c.Token != ""
+	case ClusterAuthExec:
+		return c.Server != "" && c.Exec != nil && c.Exec.Command != ""
+	default:
+		return strings.TrimSpace(c.Kubeconfig) != ""
+	}
+}
+
+// ValidateAuth 校验认证配置：kubeconfig 方式允许为空（未接入集群），token/exec 方式需要 server 与对应凭据
+func (c *Cluster) ValidateAuth() error {
+	switch c.Auth() {
+	case ClusterAuthKubeconfig:
+		return nil
+	case ClusterAuthToken, ClusterAuthExec:
+	default:
+		return fmt.Errorf("auth_type 仅支持 kubeconfig/token/exec: %s", c.AuthType)
+	}
+	if !strings.HasPrefix(c.Server, "https://") && !strings.HasPrefix(c.Server, "http://") {
+		return fmt.Errorf("%s 方式需配置 server（http/https 地址）", c.AuthType)
+	}
+	if c.CAData != "" && !strings.Contains(c.CAData, "-----BEGIN CERTIFICATE-----") {
+		return fmt.Errorf("ca_data 需为 PEM 格式证书")
+	}
+	if c.Auth() == ClusterAuthToken && c.Token == "" {
+		return fmt.Errorf("token 方式需配置 token")
+	}
+	if c.Auth() == ClusterAuthExec && (c.Exec == nil || strings.TrimSpace(c.Exec.Command) == "") {
+		return fmt.Errorf("exec 方式需配置 exec_config.command")
+	}
+	return nil
+}
+
 // AppEnvConfig 应用环境配置模型
 // 用途: 通过记录存在判断应用是否需要部署到某环境,每个集群一条记录
 //
@@ -40,7 +144,7 @@ type AppEnvConfig struct {
 
 	DeploymentNameOverride *string `gorm:"size:63" json:"deployment_name_override"` // 部署名称覆盖
 	Replicas               int     `gorm:"default:1" json:"replicas"`               // 副本数
-	ConfigData             *string `gorm:"type:json" json:"config_data,omitempty"`  // 扩展配置(JSON格式), 未来支持
+	ConfigData             *string `gorm:"type:json" json:"config_data,omitempty"`  // 扩展配置(JSON格式), 见 AppEnvConfigData
 
 	// Relations
 	Application *Application `gorm:"foreignKey:AppID" json:"application,omitempty"`
@@ -49,3 +153,21 @@ type AppEnvConfig struct {
 func (AppEnvConfig) TableName() string {
 	return AppEnvConfigTableName
 }
+
+// AppEnvConfigData AppEnvConfig.ConfigData 的结构
+type AppEnvConfigData struct {
+	Values   []ValuesLayer   `json:"values,omitempty"`   // 追加在项目 app_chart.values 之后（后者覆盖前者）
+	Strategy *DeployStrategy `json:"strategy,omitempty"` // 生产部署策略（仅 prod 生效），为空表示所有集群同时部署
+}
+
+// ParseConfigData 解析扩展配置，ConfigData 为空时返回空结构
+func (c *AppEnvConfig) ParseConfigData() (*AppEnvConfigData, error) {
+	data := &AppEnvConfigData{}
+	if c.ConfigData == nil || strings.TrimSpace(*c.ConfigData) == "" {
+		return data, nil
+	}
+	if err := json.Unmarshal([]byte(*c.ConfigData), data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -16,7 +16,8 @@ const (
 // Credential 凭据（敏感字段加密存储）
 //
 // 说明：
-// - encrypted_data: AES-GCM(base64) 密文（nonce 已包含在密文中）
+// - encrypted_data: AES-GCM(base64) 密文（nonce 已包含在密文中）；key_id 非空时为信封加密格式
+// - key_id: 加密所用 KEK（"<provider>:<key>"，如 local / vault:devops-cd），为空表示旧格式（aes_key 直接加密）
 // - meta_json: 非敏感字段（用于列表展示/筛选）
 type Credential struct {
 	BaseModelWithSoftDelete
@@ -27,6 +28,7 @@ type Credential struct {
 	Type      string `gorm:"size:32;not null" json:"type"`
 
 	EncryptedData string         `gorm:"column:encrypted_data;type:longtext;not null" json:"-"`
+	KeyID         string         `gorm:"column:key_id;size:255;not null;default:''" json:"-"`
 	MetaJSON      datatypes.JSON `gorm:"column:meta_json;type:json" json:"meta_json,omitempty"`
 }
 
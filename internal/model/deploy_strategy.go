@@ -0,0 +1,129 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+)
+
+// 生产部署策略类型
+const (
+	DeployStrategyAll    = "all"    // 所有集群同时部署（默认）
+	DeployStrategyCanary = "canary" // 金丝雀：按比例选取首批集群，promote 后部署其余集群
+	DeployStrategyStaged = "staged" // 分阶段：按集群配置的 stage 逐阶段部署
+)
+
+// DeployStrategy 生产部署策略，配置在 prod AppEnvConfig.config_data.strategy
+//
+// 同一应用的 prod 集群需使用相同的 type（canary_percent 取第一个配置了的集群）；
+// canary/staged 下每个阶段部署完成后暂停，通过 release_app promote 进入下一阶段，abort 中止后续阶段
+type DeployStrategy struct {
+	Type          string `json:"type"`
+	CanaryPercent int    `json:"canary_percent,omitempty"` // canary: 首批集群占比（1~99），按集群名排序选取，至少 1 个
+	Stage         int    `json:"stage,omitempty"`          // staged: 集群所属阶段（>=1，数值小的先部署），未设置视为 1
+}
+
+// Validate 校验配置
+func (s *DeployStrategy) Validate() error {
+	if s == nil {
+		return nil
+	}
+	switch s.Type {
+	case "", DeployStrategyAll:
+	case DeployStrategyCanary:
+		if s.CanaryPercent < 1 || s.CanaryPercent > 99 {
+			return fmt.Errorf("canary_percent 需在 1~99 之间")
+		}
+	case DeployStrategyStaged:
+		if s.Stage < 0 {
+			return fmt.Errorf("stage 不能为负数")
+		}
+	default:
+		return fmt.Errorf("不支持的部署策略: %s", s.Type)
+	}
+	return nil
+}
+
+func (s *DeployStrategy) strategyType() string {
+	if s == nil || s.Type == "" {
+		return DeployStrategyAll
+	}
+	return s.Type
+}
+
+// PlanRolloutStages 根据 prod 集群配置的部署策略计算每个集群的阶段（从 1 开始连续编号），返回 cluster -> stage 及总阶段数
+func PlanRolloutStages(configs []AppEnvConfig) (map[string]int, int, error) {
+	sorted := append([]AppEnvConfig(nil), configs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cluster < sorted[j].Cluster })
+
+	strategyType := DeployStrategyAll
+	canaryPercent := 0
+	strategies := make(map[string]*DeployStrategy, len(sorted))
+	for i := range sorted {
+		data, err := sorted[i].ParseConfigData()
+		if err != nil {
+			return nil, 0, fmt.Errorf("集群 %s 扩展配置解析失败: %w", sorted[i].Cluster, err)
+		}
+		strategies[sorted[i].Cluster] = data.Strategy
+		t := data.Strategy.strategyType()
+		if t == DeployStrategyAll {
+			continue
+		}
+		if strategyType != DeployStrategyAll && strategyType != t {
+			return nil, 0, fmt.Errorf("生产集群部署策略不一致: %s / %s", strategyType, t)
+		}
+		strategyType = t
+		if canaryPercent == 0 {
+			canaryPercent = data.Strategy.CanaryPercent
+		}
+	}
+
+	stages := make(map[string]int, len(sorted))
+	switch strategyType {
+	case DeployStrategyCanary:
+		if len(sorted) < 2 {
+			break
+		}
+		canary := (len(sorted)*canaryPercent + 99) / 100
+		if canary >= len(sorted) {
+			canary = len(sorted) - 1
+		}
+		for i, cfg := range sorted {
+			if i < canary {
+				stages[cfg.Cluster] = 1
+			} else {
+				stages[cfg.Cluster] = 2
+			}
+		}
+		return stages, 2, nil
+	case DeployStrategyStaged:
+		// 配置的 stage 可能不连续，压缩为 1..N
+		raw := make(map[string]int, len(sorted))
+		var distinct []int
+		seen := make(map[int]bool)
+		for _, cfg := range sorted {
+			stage := 1
+			if s := strategies[cfg.Cluster]; s != nil && s.Stage > 0 {
+				stage = s.Stage
+			}
+			raw[cfg.Cluster] = stage
+			if !seen[stage] {
+				seen[stage] = true
+				distinct = append(distinct, stage)
+			}
+		}
+		sort.Ints(distinct)
+		rank := make(map[int]int, len(distinct))
+		for i, stage := range distinct {
+			rank[stage] = i + 1
+		}
+		for cluster, stage := range raw {
+			stages[cluster] = rank[stage]
+		}
+		return stages, len(distinct), nil
+	}
+
+	for _, cfg := range sorted {
+		stages[cfg.Cluster] = 1
+	}
+	return stages, 1, nil
+}
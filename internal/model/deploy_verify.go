@@ -0,0 +1,176 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// DeployVerify 部署后验证配置（应用级）
+//
+// Deployment workload 就绪后依次执行 checks，全部通过才置为 success；
+// 超过 timeout_seconds 仍未通过则置为 verify_failed，发布应用随之进入 PreFailed/ProdFailed。
+// url / query 支持与 artifacts 相同的模板变量，另有 namespace、release_name：
+//
+//	{"enabled": true, "checks": [
+//	  {"name": "health", "type": "http", "url": "http://{{ .release_name }}.{{ .namespace }}.svc/healthz", "expect_body": "ok"},
+//	  {"name": "5xx", "type": "prometheus", "envs": ["prod"], "prometheus_url": "http://prometheus:9090",
+//	   "query": "sum(rate(http_requests_total{app=\"{{ .app_name }}\",code=~\"5..\"}[5m]))", "operator": "<", "threshold": 1}
+//	]}
+type DeployVerify struct {
+	Enabled        bool                `json:"enabled"`
+	TimeoutSeconds int                 `json:"timeout_seconds,omitempty"` // 验证窗口（从 workload 就绪起算），默认 300
+	Checks         []DeployVerifyCheck `json:"checks"`
+}
+
+// DeployVerifyCheck 单个验证项
+type DeployVerifyCheck struct {
+	Name string   `json:"name"`
+	Type string   `json:"type"`           // http / prometheus
+	Envs []string `json:"envs,omitempty"` // 生效环境，为空表示所有环境
+
+	// http
+	URL          string `json:"url,omitempty"`           // 探测地址（模板）
+	Method       string `json:"method,omitempty"`        // 默认 GET
+	ExpectStatus int    `json:"expect_status,omitempty"` // 期望状态码，默认 200
+	ExpectBody   string `json:"expect_body,omitempty"`   // 响应体需包含的内容，为空不校验
+
+	// prometheus
+	PrometheusURL string  `json:"prometheus_url,omitempty"` // Prometheus 地址（模板）
+	Query         string  `json:"query,omitempty"`          // PromQL（模板），取结果第一条样本值
+	Operator      string  `json:"operator,omitempty"`       // 比较符：< <= > >= == !=
+	Threshold     float64 `json:"threshold"`                // 阈值
+}
+
+// DeployVerifyType 验证类型
+const (
+	DeployVerifyTypeHTTP       = "http"
+	DeployVerifyTypePrometheus = "prometheus"
+)
+
+const (
+	// DefaultDeployVerifyTimeoutSeconds 默认验证窗口（5 分钟）
+	DefaultDeployVerifyTimeoutSeconds = 300
+	// MaxDeployVerifyTimeoutSeconds 验证窗口上限（1 小时）
+	MaxDeployVerifyTimeoutSeconds = 3600
+)
+
+var deployVerifyOperators = map[string]bool{"<": true, "<=": true, ">": true, ">=": true, "==": true, "!=": true}
+
+// IsEnabled 是否开启部署后验证
+func (v *DeployVerify) IsEnabled() bool {
+	return v != nil && v.Enabled && len(v.Checks) > 0
+}
+
+// Timeout 验证窗口（秒）
+func (v *DeployVerify) Timeout() int {
+	if v == nil || v.TimeoutSeconds <= 0 {
+		return DefaultDeployVerifyTimeoutSeconds
+	}
+	return v.TimeoutSeconds
+}
+
+// ChecksFor 返回在 env 生效的验证项
+func (v *DeployVerify) ChecksFor(env string) []DeployVerifyCheck {
+	if !v.IsEnabled() {
+		return nil
+	}
+	var out []DeployVerifyCheck
+	for _, c := range v.Checks {
+		if len(c.Envs) == 0 || slices.Contains(c.Envs, env) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Validate 校验配置
+func (v *DeployVerify) Validate() error {
+	if v == nil {
+		return nil
+	}
+	if v.TimeoutSeconds < 0 || v.TimeoutSeconds > MaxDeployVerifyTimeoutSeconds {
+		return fmt.Errorf("timeout_seconds 需在 0~%d 之间", MaxDeployVerifyTimeoutSeconds)
+	}
+	for i, c := range v.Checks {
+		label := c.Name
+		if label == "" {
+			label = fmt.Sprintf("checks[%d]", i)
+		}
+		switch c.Type {
+		case DeployVerifyTypeHTTP:
+			if strings.TrimSpace(c.URL) == "" {
+				return fmt.Errorf("%s: url 不能为空", label)
+			}
+			if err := validateTemplate(c.URL); err != nil {
+				return fmt.Errorf("%s: url 模板不合法: %w", label, err)
+			}
+			if c.ExpectStatus != 0 && (c.ExpectStatus < 100 || c.ExpectStatus > 599) {
+				return fmt.Errorf("%s: expect_status 不合法: %d", label, c.ExpectStatus)
+			}
+		case DeployVerifyTypePrometheus:
+			if strings.TrimSpace(c.PrometheusURL) == "" || strings.TrimSpace(c.Query) == "" {
+				return fmt.Errorf("%s: prometheus_url 与 query 不能为空", label)
+			}
+			if err := validateTemplate(c.PrometheusURL); err != nil {
+				return fmt.Errorf("%s: prometheus_url 模板不合法: %w", label, err)
+			}
+			if err := validateTemplate(c.Query); err != nil {
+				return fmt.Errorf("%s: query 模板不合法: %w", label, err)
+			}
+			if !deployVerifyOperators[c.Operator] {
+				return fmt.Errorf("%s: operator 不合法: %q", label, c.Operator)
+			}
+		default:
+			return fmt.Errorf("%s: 不支持的验证类型 %q", label, c.Type)
+		}
+	}
+	return nil
+}
+
+// Compare 按 operator 比较 value 与 threshold
+func (c *DeployVerifyCheck) Compare(value float64) bool {
+	switch c.Operator {
+	case "<":
+		return value < c.Threshold
+	case "<=":
+		return value <= c.Threshold
+	case ">":
+		return value > c.Threshold
+	case ">=":
+		return value >= c.Threshold
+	case "==":
+		return value == c.Threshold
+	case "!=":
+		return value != c.Threshold
+	}
+	return false
+}
+
+func validateTemplate(text string) error {
+	_, err := template.New("").Parse(text)
+	return err
+}
+
+// Scan 实现 sql.Scanner
+func (v *DeployVerify) Scan(value interface{}) error {
+	switch val := value.(type) {
+	case nil:
+		*v = DeployVerify{}
+		return nil
+	case []byte:
+		return json.Unmarshal(val, v)
+	case string:
+		return json.Unmarshal([]byte(val), v)
+	default:
+		return fmt.Errorf("cannot scan %T into DeployVerify", value)
+	}
+}
+
+// Value 实现 driver.Valuer
+func (v DeployVerify) Value() (driver.Value, error) {
+	return json.Marshal(v)
+}
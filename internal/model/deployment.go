@@ -15,6 +15,9 @@ type Deployment struct {
 	AppID     int64 `gorm:"column:app_id;not null" json:"app_id"`
 	ReleaseID int64 `gorm:"column:release_id;not null" json:"release_id"`
 
+	// 部署类型：app(app chart) / config(config chart)，见 constants.DeploymentKind*
+	Kind string `gorm:"size:20;not null;default:app" json:"kind"`
+
 	// 部署信息
 	Env            string            `gorm:"column:env;size:20;not null" json:"env"` // pre/prod
 	ClusterName    string            `gorm:"column:cluster;size:63;not null" json:"cluster_name"`
@@ -24,15 +27,47 @@ type Deployment struct {
 
 	// 状态追踪
 	DriverType    *string `gorm:"column:driver_type;size:32" json:"driver_type"`  // main 阶段 driver（如 helm）；为空表示尚未启动 main
-	Status        string  `gorm:"size:20;not null;default:pending" json:"status"` // pending/running/success/failed
+	ChartVersion  *string `gorm:"size:100" json:"chart_version"`                  // 部署时解析出的 chart 版本（未配置 chart_version_template 时为空）
+	Status        string  `gorm:"size:20;not null;default:pending" json:"status"` // pending/running/success/failed/verify_failed
 	RetryCount    int     `gorm:"default:0" json:"retry_count"`
 	MaxRetryCount int     `gorm:"default:3" json:"max_retry_count"`
+	// 最近一次失败时间：重试后保留，状态机据此按 retry_backoff 计算下次执行时间
+	LastFailedAt *time.Time `gorm:"column:last_failed_at" json:"last_failed_at"`
 	// superseded_by：被哪条新 deployment 替代（NULL=当前生效）
 	SupersededBy *int64 `gorm:"column:superseded_by" json:"superseded_by,omitempty"`
+	// 灰度阶段（从 1 开始）：大于发布应用当前阶段时保持 pending，promote 后执行
+	RolloutStage int `gorm:"column:rollout_stage;not null;default:1" json:"rollout_stage"`
+	// 自动回滚：部署该构建（发布前版本 previous_deployed_tag）而非发布应用当前构建，为空表示正常部署
+	RollbackBuildID *int64 `gorm:"column:rollback_build_id" json:"rollback_build_id,omitempty"`
+	// 临时部署（批次外手动部署）：不为空时 batch_id/release_id 为 0，部署临时部署记录中的构建
+	AdhocID *int64 `gorm:"column:adhoc_id" json:"adhoc_id,omitempty"`
 
 	// 错误信息
 	ErrorMessage *string `gorm:"type:text" json:"error_message"`
 
+	// prod 部署前 server-side dry-run apply 结果（passed/failed，见 constants.DeploymentDryRun*）
+	DryRunStatus  *string    `gorm:"column:dry_run_status;size:20" json:"dry_run_status"`
+	DryRunMessage *string    `gorm:"column:dry_run_message;type:text" json:"dry_run_message"`
+	DryRunAt      *time.Time `gorm:"column:dry_run_at" json:"dry_run_at"`
+
+	// gitops driver 提交到 Git 仓库的 commit（只读，仅由 gitops driver 按列更新，避免整行 Save 覆盖）
+	GitRevision *string `gorm:"column:git_revision;size:64;->" json:"git_revision"`
+
+	// 部署的 chart digest（OCI 为 manifest digest，http(s) 仓库为 chart 包 digest；只读，仅由 helm driver 按列更新）；重试时按该 digest 拉取/校验，保证部署内容可复现
+	ChartDigest *string `gorm:"column:chart_digest;size:100;->" json:"chart_digest"`
+
+	// 部署成功写入 release 时记录的 revision 与 values/manifest 摘要（只读，仅由 helm driver 按列更新），配置漂移检测以此为基准
+	ReleaseRevision *int    `gorm:"column:release_revision;->" json:"release_revision,omitempty"`
+	ValuesDigest    *string `gorm:"column:values_digest;size:64;->" json:"values_digest,omitempty"`
+	ManifestDigest  *string `gorm:"column:manifest_digest;size:64;->" json:"manifest_digest,omitempty"`
+
+	// 部署后验证开始时间（workload 就绪时写入），超过应用 deploy_verify.timeout_seconds 仍未通过置为 verify_failed
+	VerifyStartedAt *time.Time `gorm:"column:verify_started_at" json:"verify_started_at"`
+
+	// 运行时长异常告警（只读，仅由引擎按列更新）：告警时间晚于 started_at 表示本次运行已告警，expected_duration_sec 为告警时的历史 P95 耗时
+	SlowAlertedAt       *time.Time `gorm:"column:slow_alerted_at;->" json:"slow_alerted_at,omitempty"`
+	ExpectedDurationSec *int       `gorm:"column:expected_duration_sec;->" json:"expected_duration_sec,omitempty"`
+
 	// 时间追踪
 	StartedAt  *time.Time `json:"started_at"`
 	FinishedAt *time.Time `json:"finished_at"`
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const DeploymentDiffTableName = "deployment_diffs"
+
+// ManifestChange 单个资源的变更
+type ManifestChange struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Action    string `json:"action"`    // added/removed/changed，见 constants.ManifestChange*
+	Protected bool   `json:"protected"` // 受保护资源类型（PDB/PVC/CRD）
+}
+
+// ManifestChanges 资源变更列表
+type ManifestChanges []ManifestChange
+
+// Scan 实现 sql.Scanner
+func (l *ManifestChanges) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*l = ManifestChanges{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return fmt.Errorf("cannot scan %T into ManifestChanges", value)
+	}
+}
+
+// Value 实现 driver.Valuer
+func (l ManifestChanges) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(l)
+}
+
+// DeploymentDiff prod 部署前渲染的 manifest 与集群中当前 release revision 的差异
+//
+// - 每条 deployment 一条记录，重试时重新计算并覆盖
+// - 变更涉及受保护资源且开启确认时，需确认当前 digest 后才会执行部署；diff 变化后需重新确认
+type DeploymentDiff struct {
+	BaseModel
+
+	DeploymentID int64  `gorm:"column:deployment_id;not null;uniqueIndex" json:"deployment_id"`
+	Namespace    string `gorm:"size:63;not null" json:"namespace"`
+	ReleaseName  string `gorm:"column:release_name;size:63;not null" json:"release_name"`
+	BaseRevision int    `gorm:"column:base_revision;not null;default:0" json:"base_revision"` // 对比的 release revision，0 表示首次安装
+
+	Status       string          `gorm:"size:20;not null" json:"status"` // ready/failed，见 constants.DeploymentDiffStatus*
+	Digest       string          `gorm:"size:64" json:"digest"`          // diff 内容摘要，用于判断确认是否仍然有效
+	Changes      ManifestChanges `gorm:"type:json" json:"changes"`
+	Diff         string          `gorm:"type:longtext" json:"diff"` // unified diff（不含 hooks）
+	RequiresAck  bool            `gorm:"column:requires_ack;not null;default:false" json:"requires_ack"`
+	AckedDigest  *string         `gorm:"column:acked_digest;size:64" json:"acked_digest"`
+	AckedBy      *string         `gorm:"column:acked_by;size:50" json:"acked_by"`
+	AckedAt      *time.Time      `gorm:"column:acked_at" json:"acked_at"`
+	ErrorMessage *string         `gorm:"type:text" json:"error_message"`
+}
+
+func (DeploymentDiff) TableName() string {
+	return DeploymentDiffTableName
+}
+
+// Acked 当前 diff 是否已被确认
+func (d *DeploymentDiff) Acked() bool {
+	return d.AckedDigest != nil && *d.AckedDigest == d.Digest
+}
+
+// PendingAck 需要确认但尚未确认
+func (d *DeploymentDiff) PendingAck() bool {
+	return d.RequiresAck && !d.Acked()
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"devops-cd/pkg/constants"
+)
+
+const DeploymentEventTableName = "deployment_events"
+
+// DeploymentEvent Deployment 时间线事件（只增不改）：状态变更、chart 渲染、开始部署、就绪检查原因变化等
+type DeploymentEvent struct {
+	ID           int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	DeploymentID int64     `gorm:"not null;index" json:"deployment_id"`
+	BatchID      int64     `gorm:"not null" json:"batch_id"`
+	Type         string    `gorm:"size:30;not null" json:"type"` // 见 constants.DeploymentEvent*
+	FromStatus   *string   `gorm:"size:20" json:"from_status"`   // 仅 status_changed
+	ToStatus     string    `gorm:"size:20;not null" json:"to_status"`
+	Operator     *string   `gorm:"size:50" json:"operator"` // 为空表示引擎自动推进
+	Message      *string   `gorm:"type:text" json:"message"`
+	CreatedAt    time.Time `gorm:"not null;autoCreateTime" json:"created_at"`
+}
+
+func (DeploymentEvent) TableName() string {
+	return DeploymentEventTableName
+}
+
+// NewDeploymentEvent 以 dep 当前状态构造时间线事件
+func NewDeploymentEvent(dep *Deployment, eventType, message string) *DeploymentEvent {
+	e := &DeploymentEvent{
+		DeploymentID: dep.ID,
+		BatchID:      dep.BatchID,
+		Type:         eventType,
+		ToStatus:     dep.Status,
+	}
+	if message != "" {
+		e.Message = &message
+	}
+	return e
+}
+
+// NewDeploymentStatusEvent 构造状态变更事件，operator 为空表示引擎自动推进
+func NewDeploymentStatusEvent(dep *Deployment, from, to, operator, message string) *DeploymentEvent {
+	e := NewDeploymentEvent(dep, constants.DeploymentEventStatusChanged, message)
+	e.FromStatus = &from
+	e.ToStatus = to
+	if operator != "" {
+		e.Operator = &operator
+	}
+	return e
+}
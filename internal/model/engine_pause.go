@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"devops-cd/pkg/constants"
+
+	"gorm.io/gorm"
+)
+
+const EnginePauseTableName = "engine_pauses"
+
+// EnginePause 核心引擎按项目/批次/集群暂停处理（如事故处理期间），不影响其他批次
+//
+// 说明：
+// - resumed_at 为空表示暂停生效中；恢复后保留记录用于审计
+// - 项目/批次暂停期间批次、发布应用、部署状态均不推进，已触发的 helm 操作不会被中断
+// - 集群暂停只冻结该集群上的 Deployment（不触发、不检查状态），批次的其他集群照常推进
+type EnginePause struct {
+	BaseModel
+
+	Scope    string `gorm:"size:20;not null;index:idx_scope" json:"scope"` // project/batch/cluster，见 constants.EnginePauseScope*
+	ScopeID  int64  `gorm:"not null;index:idx_scope" json:"scope_id"`
+	Reason   string `gorm:"size:500" json:"reason"`
+	PausedBy string `gorm:"size:50" json:"paused_by"`
+
+	ResumedBy *string    `gorm:"size:50" json:"resumed_by"`
+	ResumedAt *time.Time `json:"resumed_at"`
+}
+
+func (EnginePause) TableName() string {
+	return EnginePauseTableName
+}
+
+// ActiveEnginePause 仅查询生效中的暂停记录
+func ActiveEnginePause(db *gorm.DB) *gorm.DB {
+	return db.Where("resumed_at IS NULL")
+}
+
+// FindEnginePause 返回作用于批次的暂停记录（批次级优先），未暂停时返回 nil
+func FindEnginePause(pauses []*EnginePause, projectID, batchID int64) *EnginePause {
+	var projectPause *EnginePause
+	for _, p := range pauses {
+		switch {
+		case p.Scope == constants.EnginePauseScopeBatch && p.ScopeID == batchID:
+			return p
+		case p.Scope == constants.EnginePauseScopeProject && p.ScopeID == projectID && projectPause == nil:
+			projectPause = p
+		}
+	}
+	return projectPause
+}
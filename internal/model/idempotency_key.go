@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+const IdempotencyKeyTableName = "idempotency_keys"
+
+// 幂等键状态
+const (
+	IdempotencyStatusProcessing = "processing"
+	IdempotencyStatusCompleted  = "completed"
+)
+
+// IdempotencyKey 写接口的幂等记录：同一用户使用相同 Idempotency-Key 重试时返回首次请求的结果
+//
+// - fingerprint: 请求方法、路径与请求体的 SHA-256，同一个键用于不同请求时拒绝
+// - 仅保存成功响应；失败时删除记录，客户端可使用同一个键重试
+type IdempotencyKey struct {
+	BaseModel
+
+	Username     string    `gorm:"size:50;not null;uniqueIndex:uk_idempotency_key" json:"username"`
+	Key          string    `gorm:"column:idem_key;size:128;not null;uniqueIndex:uk_idempotency_key" json:"key"`
+	Method       string    `gorm:"size:10;not null" json:"method"`
+	Path         string    `gorm:"size:255;not null" json:"path"`
+	Fingerprint  string    `gorm:"size:64;not null" json:"fingerprint"`
+	Status       string    `gorm:"size:20;not null" json:"status"`
+	StatusCode   int       `gorm:"not null;default:0" json:"status_code"`
+	ContentType  string    `gorm:"size:100" json:"content_type"`
+	ResponseBody []byte    `gorm:"type:mediumblob" json:"-"`
+	ExpiresAt    time.Time `gorm:"not null;index" json:"expires_at"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return IdempotencyKeyTableName
+}
@@ -0,0 +1,151 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const ImageScanTableName = "image_scans"
+
+// 镜像扫描状态
+const (
+	ImageScanStatusSuccess = "success"
+	ImageScanStatusFailed  = "failed"
+)
+
+// ImageScan 镜像漏洞扫描结果（Harbor 扫描完成通知 / CI 上报的 Trivy 报告），同一镜像 tag 只保留最近一次
+type ImageScan struct {
+	BaseModel
+
+	Image     string    `gorm:"size:255;not null;uniqueIndex:uk_image_tag" json:"image"` // 镜像地址（不含 tag），如 harbor.example.com/proj/app
+	Tag       string    `gorm:"size:100;not null;uniqueIndex:uk_image_tag" json:"tag"`
+	Digest    *string   `gorm:"size:100" json:"digest"`
+	Source    string    `gorm:"size:50;not null" json:"source"` // 上报来源：harbor / trivy
+	Scanner   string    `gorm:"size:100" json:"scanner"`        // 扫描器，如 Trivy v0.50.1
+	Status    string    `gorm:"size:20;not null" json:"status"` // success / failed
+	Critical  int       `gorm:"not null;default:0" json:"critical"`
+	High      int       `gorm:"not null;default:0" json:"high"`
+	Medium    int       `gorm:"not null;default:0" json:"medium"`
+	Low       int       `gorm:"not null;default:0" json:"low"`
+	Unknown   int       `gorm:"not null;default:0" json:"unknown"`
+	ScannedAt time.Time `gorm:"not null" json:"scanned_at"`
+}
+
+func (ImageScan) TableName() string {
+	return ImageScanTableName
+}
+
+// ScanImage 构建镜像对应的扫描记录键（镜像地址不含 tag + tag），image_url 带 tag 时以其为准；未记录镜像地址时返回空
+func (b *Build) ScanImage() (image, tag string) {
+	image = strings.TrimSpace(b.ImageURL)
+	if image == "" {
+		return "", ""
+	}
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image[:i], image[i+1:]
+	}
+	return image, strings.TrimSpace(b.ImageTag)
+}
+
+// 漏洞门禁拦截阶段
+const (
+	VulnGateStageSeal = "seal" // 封板时拦截
+	VulnGateStageProd = "prod" // 触发生产部署时拦截
+)
+
+// VulnPolicy 项目镜像漏洞门禁：批次内构建镜像的漏洞数超过阈值时，在 stage 阶段拦截（封板失败 / 生产部署保持等待）
+//
+// 拥有 security:vuln:override 权限的用户可为批次豁免。
+//
+// 示例：存在严重漏洞或高危漏洞超过 5 个时禁止生产部署，未扫描的镜像同样拦截：
+//
+//	{"stage": "prod", "max_critical": 0, "max_high": 5, "require_scan": true}
+type VulnPolicy struct {
+	Stage       string `json:"stage"`                  // seal / prod，默认 prod
+	MaxCritical *int   `json:"max_critical,omitempty"` // 允许的严重漏洞数，为空不限制
+	MaxHigh     *int   `json:"max_high,omitempty"`     // 允许的高危漏洞数，为空不限制
+	RequireScan bool   `json:"require_scan"`           // 没有扫描结果或扫描失败时是否拦截
+}
+
+// IsEnabled 是否配置了漏洞门禁
+func (p *VulnPolicy) IsEnabled() bool {
+	return p != nil && (p.MaxCritical != nil || p.MaxHigh != nil || p.RequireScan)
+}
+
+// Normalize 统一阶段取值，未配置时为 prod
+func (p *VulnPolicy) Normalize() {
+	if p == nil {
+		return
+	}
+	p.Stage = strings.ToLower(strings.TrimSpace(p.Stage))
+	if p.Stage == "" {
+		p.Stage = VulnGateStageProd
+	}
+}
+
+// Validate 校验阶段与阈值
+func (p *VulnPolicy) Validate() error {
+	if !p.IsEnabled() {
+		return nil
+	}
+	if p.Stage != VulnGateStageSeal && p.Stage != VulnGateStageProd {
+		return fmt.Errorf("vuln_policy.stage 仅支持 seal/prod: %s", p.Stage)
+	}
+	if (p.MaxCritical != nil && *p.MaxCritical < 0) || (p.MaxHigh != nil && *p.MaxHigh < 0) {
+		return fmt.Errorf("vuln_policy 漏洞阈值不能为负数")
+	}
+	return nil
+}
+
+// Check 按扫描结果判断镜像是否放行，不放行时返回原因；scan 为空表示没有扫描结果
+func (p *VulnPolicy) Check(scan *ImageScan) string {
+	if !p.IsEnabled() {
+		return ""
+	}
+	if scan == nil {
+		if p.RequireScan {
+			return "没有漏洞扫描结果"
+		}
+		return ""
+	}
+	if scan.Status != ImageScanStatusSuccess {
+		if p.RequireScan {
+			return "漏洞扫描失败"
+		}
+		return ""
+	}
+	var exceeded []string
+	if p.MaxCritical != nil && scan.Critical > *p.MaxCritical {
+		exceeded = append(exceeded, fmt.Sprintf("严重漏洞 %d 个（上限 %d）", scan.Critical, *p.MaxCritical))
+	}
+	if p.MaxHigh != nil && scan.High > *p.MaxHigh {
+		exceeded = append(exceeded, fmt.Sprintf("高危漏洞 %d 个（上限 %d）", scan.High, *p.MaxHigh))
+	}
+	return strings.Join(exceeded, "，")
+}
+
+// Scan 实现 sql.Scanner
+func (p *VulnPolicy) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*p = VulnPolicy{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, p)
+	case string:
+		return json.Unmarshal([]byte(v), p)
+	default:
+		return fmt.Errorf("cannot scan %T into VulnPolicy", value)
+	}
+}
+
+// Value 实现 driver.Valuer
+func (p VulnPolicy) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// DefaultJiraTransition 批次完成后默认流转到的状态
+const DefaultJiraTransition = "Released"
+
+var jiraProjectKeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]+$`)
+
+// JiraConfig 项目 Jira 关联配置
+//
+// 封板后从 previous_deployed_tag → target_tag 之间的提交说明中解析 issue key（如 PROJ-123）记录到发布应用；
+// 开启 transition_on_complete 时，批次完成后将关联 issue 按 transition（流转名称或目标状态名）流转。
+//
+// 示例：
+//
+//	{"base_url": "https://jira.example.com", "credential_ref": "id:12", "project_keys": ["PROJ"], "transition_on_complete": true}
+type JiraConfig struct {
+	BaseURL              string   `json:"base_url"`
+	CredentialRef        string   `json:"credential_ref,omitempty"` // basic_auth（用户名/邮箱 + API token）或 token（PAT，Bearer）
+	ProjectKeys          []string `json:"project_keys,omitempty"`   // 只识别这些 Jira 项目的 issue key，为空识别全部（可能误识别 UTF-8 之类的文本）
+	TransitionOnComplete bool     `json:"transition_on_complete"`   // 批次完成后流转关联 issue
+	Transition           string   `json:"transition,omitempty"`     // 流转名称或目标状态名，默认 Released
+}
+
+// IsEnabled 是否配置了 Jira
+func (c *JiraConfig) IsEnabled() bool {
+	return c != nil && strings.TrimSpace(c.BaseURL) != ""
+}
+
+// Normalize 去除空白、项目 key 转大写，未配置流转时为 Released
+func (c *JiraConfig) Normalize() {
+	if c == nil {
+		return
+	}
+	c.BaseURL = strings.TrimRight(strings.TrimSpace(c.BaseURL), "/")
+	c.CredentialRef = strings.TrimSpace(c.CredentialRef)
+	c.Transition = strings.TrimSpace(c.Transition)
+	if c.Transition == "" {
+		c.Transition = DefaultJiraTransition
+	}
+	keys := make([]string, 0, len(c.ProjectKeys))
+	for _, k := range c.ProjectKeys {
+		if k = strings.ToUpper(strings.TrimSpace(k)); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	c.ProjectKeys = keys
+}
+
+// Validate 校验地址与项目 key
+func (c *JiraConfig) Validate() error {
+	if !c.IsEnabled() {
+		return nil
+	}
+	u, err := url.Parse(c.BaseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("jira_config.base_url 非法: %s", c.BaseURL)
+	}
+	for _, k := range c.ProjectKeys {
+		if !jiraProjectKeyPattern.MatchString(k) {
+			return fmt.Errorf("jira_config.project_keys 非法: %s", k)
+		}
+	}
+	return nil
+}
+
+// Scan 实现 sql.Scanner
+func (c *JiraConfig) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*c = JiraConfig{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	default:
+		return fmt.Errorf("cannot scan %T into JiraConfig", value)
+	}
+}
+
+// Value 实现 driver.Valuer
+func (c JiraConfig) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
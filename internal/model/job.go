@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+const JobTableName = "jobs"
+
+// Job 异步任务（Deployment 执行等耗时副作用），由核心引擎的 worker 池领取执行
+//
+//   - dedup_key: 业务去重键，active_key 仅在任务未结束时等于 dedup_key（结束后置空），唯一索引保证同一键同时只有一个未结束任务
+//   - 领取时写入 locked_by / locked_until 租约，worker 异常退出后租约过期由其他 worker 重新领取
+//   - 失败按退避重试，attempts 达到 max_attempts 后进入死信（dead）
+type Job struct {
+	BaseModel
+
+	Type         string         `gorm:"size:64;not null;index" json:"type"`
+	DedupKey     string         `gorm:"size:128;not null;default:''" json:"dedup_key"`
+	ActiveKey    *string        `gorm:"size:128;uniqueIndex:uk_jobs_active_key" json:"-"`
+	Payload      datatypes.JSON `gorm:"type:json" json:"payload"`
+	Status       string         `gorm:"size:20;not null;index:idx_jobs_status_run_at" json:"status"` // pkg/constants:JobStatus*
+	Attempts     int            `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts  int            `gorm:"not null;default:1" json:"max_attempts"`
+	RunAt        time.Time      `gorm:"not null;index:idx_jobs_status_run_at" json:"run_at"` // 最早可执行时间（重试退避）
+	LockedBy     string         `gorm:"size:128" json:"locked_by"`
+	LockedUntil  *time.Time     `json:"locked_until"`
+	LastError    string         `gorm:"type:text" json:"last_error"`
+	BatchID      *int64         `gorm:"index" json:"batch_id"`
+	DeploymentID *int64         `gorm:"index" json:"deployment_id"`
+	StartedAt    *time.Time     `json:"started_at"`
+	FinishedAt   *time.Time     `json:"finished_at"`
+}
+
+func (Job) TableName() string {
+	return JobTableName
+}
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const NotificationRuleTableName = "notification_rules"
+
+// NotificationRule 通知路由规则
+//
+// 按项目/团队、通知类型与严重级别将通知路由到不同的 Lark 群机器人 Webhook，
+// 标题/正文可使用 Go text/template 模板；匹配到规则的通知不再发送到 core.notification 配置的默认渠道
+type NotificationRule struct {
+	BaseModel
+
+	Name        string     `gorm:"size:100;not null" json:"name"`
+	ProjectID   *int64     `gorm:"index" json:"project_id"`                           // 为空表示所有项目
+	TeamID      *int64     `gorm:"index" json:"team_id"`                              // 为空表示所有团队；非空时只匹配应用级通知
+	Events      StringList `gorm:"type:json" json:"events"`                           // notification.NotificationType，包含 "*" 表示全部
+	MinSeverity string     `gorm:"size:20;not null;default:info" json:"min_severity"` // info / warning / error
+	Webhook     string     `gorm:"size:500;not null" json:"webhook"`                  // Lark 群机器人 Webhook
+
+	// 模板为空时使用默认标题/正文
+	TitleTemplate   string `gorm:"type:text" json:"title_template"`
+	ContentTemplate string `gorm:"type:text" json:"content_template"`
+
+	QuietHours    *QuietHours `gorm:"type:json" json:"quiet_hours"`                 // 免打扰时段内只发送 error 级别通知
+	MentionLeader bool        `gorm:"not null;default:false" json:"mention_leader"` // error 级别的应用通知 @ 应用所属团队负责人
+
+	Enabled     bool   `gorm:"not null;default:true" json:"enabled"`
+	Description string `gorm:"size:500" json:"description"`
+	CreatedBy   string `gorm:"size:50" json:"created_by"`
+	UpdatedBy   string `gorm:"size:50" json:"updated_by"`
+}
+
+func (NotificationRule) TableName() string {
+	return NotificationRuleTableName
+}
+
+// Subscribed 是否订阅了通知类型
+func (r *NotificationRule) Subscribed(typ string) bool {
+	for _, e := range r.Events {
+		if e == "*" || e == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// QuietHours 免打扰时段（HH:MM，end 早于 start 表示跨零点，如 22:00-08:00）
+type QuietHours struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Timezone string `json:"timezone,omitempty"` // IANA 时区，默认服务器本地时区
+}
+
+// Validate 校验时间格式与时区
+func (q *QuietHours) Validate() error {
+	if q == nil {
+		return nil
+	}
+	if _, err := time.Parse("15:04", q.Start); err != nil {
+		return fmt.Errorf("quiet_hours.start 格式应为 HH:MM: %s", q.Start)
+	}
+	if _, err := time.Parse("15:04", q.End); err != nil {
+		return fmt.Errorf("quiet_hours.end 格式应为 HH:MM: %s", q.End)
+	}
+	if q.Start == q.End {
+		return fmt.Errorf("quiet_hours.start 与 end 不能相同")
+	}
+	if q.Timezone != "" {
+		if _, err := time.LoadLocation(q.Timezone); err != nil {
+			return fmt.Errorf("quiet_hours.timezone 非法: %s", q.Timezone)
+		}
+	}
+	return nil
+}
+
+// Contains t 是否处于免打扰时段
+func (q *QuietHours) Contains(t time.Time) bool {
+	if q == nil {
+		return false
+	}
+	if q.Timezone != "" {
+		if loc, err := time.LoadLocation(q.Timezone); err == nil {
+			t = t.In(loc)
+		}
+	}
+	start, err1 := time.Parse("15:04", q.Start)
+	end, err2 := time.Parse("15:04", q.End)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	now := t.Hour()*60 + t.Minute()
+	from := start.Hour()*60 + start.Minute()
+	to := end.Hour()*60 + end.Minute()
+	if from < to {
+		return now >= from && now < to
+	}
+	return now >= from || now < to
+}
+
+// Scan 实现 sql.Scanner
+func (q *QuietHours) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*q = QuietHours{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, q)
+	case string:
+		return json.Unmarshal([]byte(v), q)
+	default:
+		return fmt.Errorf("cannot scan %T into QuietHours", value)
+	}
+}
+
+// Value 实现 driver.Valuer
+func (q QuietHours) Value() (driver.Value, error) {
+	return json.Marshal(q)
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuietHoursContains(t *testing.T) {
+	at := func(hhmm string) time.Time {
+		tm, _ := time.Parse("15:04", hhmm)
+		return time.Date(2026, 1, 2, tm.Hour(), tm.Minute(), 0, 0, time.UTC)
+	}
+	cases := []struct {
+		q    *QuietHours
+		at   time.Time
+		want bool
+	}{
+		{q: nil, at: at("23:00"), want: false},
+		{q: &QuietHours{Start: "12:00", End: "14:00", Timezone: "UTC"}, at: at("11:59"), want: false},
+		{q: &QuietHours{Start: "12:00", End: "14:00", Timezone: "UTC"}, at: at("12:00"), want: true},
+		{q: &QuietHours{Start: "12:00", End: "14:00", Timezone: "UTC"}, at: at("14:00"), want: false},
+		// 跨零点
+		{q: &QuietHours{Start: "22:00", End: "08:00", Timezone: "UTC"}, at: at("21:59"), want: false},
+		{q: &QuietHours{Start: "22:00", End: "08:00", Timezone: "UTC"}, at: at("23:30"), want: true},
+		{q: &QuietHours{Start: "22:00", End: "08:00", Timezone: "UTC"}, at: at("00:00"), want: true},
+		{q: &QuietHours{Start: "22:00", End: "08:00", Timezone: "UTC"}, at: at("07:59"), want: true},
+		{q: &QuietHours{Start: "22:00", End: "08:00", Timezone: "UTC"}, at: at("08:00"), want: false},
+		// 按时区换算：UTC 15:00 为上海 23:00
+		{q: &QuietHours{Start: "22:00", End: "08:00", Timezone: "Asia/Shanghai"}, at: at("15:00"), want: true},
+		{q: &QuietHours{Start: "22:00", End: "08:00", Timezone: "Asia/Shanghai"}, at: at("01:00"), want: false}, // 上海 09:00,
+		{q: &QuietHours{Start: "25:00", End: "08:00", Timezone: "UTC"}, at: at("23:00"), want: false},
+	}
+	for _, c := range cases {
+		if got := c.q.Contains(c.at); got != c.want {
+			t.Errorf("%+v Contains(%s) = %v, want %v", c.q, c.at.Format("15:04"), got, c.want)
+		}
+	}
+}
+
+func TestQuietHoursValidate(t *testing.T) {
+	cases := []struct {
+		q       *QuietHours
+		wantErr string
+	}{
+		{q: nil},
+		{q: &QuietHours{Start: "22:00", End: "08:00"}},
+		{q: &QuietHours{Start: "22:00", End: "08:00", Timezone: "Asia/Shanghai"}},
+		{q: &QuietHours{Start: "10pm", End: "08:00"}, wantErr: "quiet_hours.start"},
+		{q: &QuietHours{Start: "22:00", End: "24:00"}, wantErr: "quiet_hours.end"},
+		{q: &QuietHours{Start: "08:00", End: "08:00"}, wantErr: "不能相同"},
+		{q: &QuietHours{Start: "22:00", End: "08:00", Timezone: "Mars/Olympus"}, wantErr: "quiet_hours.timezone"},
+	}
+	for _, c := range cases {
+		err := c.q.Validate()
+		if c.wantErr == "" {
+			if err != nil {
+				t.Errorf("%+v Validate: %v", c.q, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+			t.Errorf("%+v Validate = %v, want %q", c.q, err, c.wantErr)
+		}
+	}
+}
+
+func TestNotificationRuleSubscribed(t *testing.T) {
+	r := &NotificationRule{Events: StringList{"app_deploy_failed", "batch_failed"}}
+	if !r.Subscribed("batch_failed") || r.Subscribed("batch_start") {
+		t.Error("按类型订阅错误")
+	}
+	if !(&NotificationRule{Events: StringList{"*"}}).Subscribed("batch_start") {
+		t.Error("* 应订阅全部类型")
+	}
+	if (&NotificationRule{}).Subscribed("batch_start") {
+		t.Error("未配置 events 不应订阅")
+	}
+}
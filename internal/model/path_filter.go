@@ -0,0 +1,131 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// PathFilter 单仓多应用（monorepo）的应用路径映射，决定哪些代码变更会为该应用生成构建记录
+//
+// 匹配规则（glob，路径相对仓库根目录）：
+//   - * 匹配单级目录内的任意字符，** 匹配任意多级目录，以 / 结尾表示该目录下的全部文件
+//   - include 为空表示不限制，否则至少匹配一条
+//   - exclude 任意一条匹配即排除
+//
+// 示例：payments 服务及其依赖的公共库，忽略文档：
+//
+//	{"include": ["services/payments/**", "libs/common/**"], "exclude": ["**/*.md"]}
+type PathFilter struct {
+	Include []string `json:"include,omitempty"` // 路径白名单
+	Exclude []string `json:"exclude,omitempty"` // 路径黑名单
+}
+
+// IsEmpty 是否未配置任何规则
+func (f *PathFilter) IsEmpty() bool {
+	return f == nil || len(f.Include)+len(f.Exclude) == 0
+}
+
+// Validate 校验所有 glob 是否合法
+func (f *PathFilter) Validate() error {
+	if f == nil {
+		return nil
+	}
+	for name, patterns := range map[string][]string{"include": f.Include, "exclude": f.Exclude} {
+		for _, p := range patterns {
+			if strings.TrimSpace(p) == "" {
+				return fmt.Errorf("path_filter.%s 不能包含空路径", name)
+			}
+			for _, seg := range strings.Split(strings.Trim(p, "/"), "/") {
+				if _, err := path.Match(seg, ""); err != nil {
+					return fmt.Errorf("path_filter.%s 路径不合法 %q: %w", name, p, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Match 判断单个文件路径是否满足规则；非法 glob 视为不匹配
+func (f *PathFilter) Match(file string) bool {
+	if f.IsEmpty() {
+		return true
+	}
+	file = strings.TrimPrefix(file, "/")
+	for _, p := range f.Exclude {
+		if matchPathGlob(p, file) {
+			return false
+		}
+	}
+	if len(f.Include) == 0 {
+		return true
+	}
+	for _, p := range f.Include {
+		if matchPathGlob(p, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchAny 任一变更文件满足规则即匹配
+func (f *PathFilter) MatchAny(files []string) bool {
+	for _, file := range files {
+		if f.Match(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPathGlob 按目录层级匹配 glob，** 匹配零到多级目录
+func matchPathGlob(pattern, file string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	return matchPathSegments(strings.Split(pattern, "/"), strings.Split(file, "/"))
+}
+
+func matchPathSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchPathSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], parts[0]); err != nil || !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
+
+// Scan 实现 sql.Scanner
+func (f *PathFilter) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*f = PathFilter{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, f)
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	default:
+		return fmt.Errorf("cannot scan %T into PathFilter", value)
+	}
+}
+
+// Value 实现 driver.Valuer
+func (f PathFilter) Value() (driver.Value, error) {
+	return json.Marshal(f)
+}
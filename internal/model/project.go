@@ -9,6 +9,14 @@ type Project struct {
 	Name        string  `gorm:"size:100;not null;uniqueIndex" json:"name"`
 	Description *string `gorm:"type:text" json:"description"`
 	OwnerName   *string `gorm:"size:100" json:"owner_name"`
+
+	ApprovalPolicy    *ApprovalPolicy `gorm:"column:approval_policy;type:json" json:"approval_policy"`                        // 批次审批策略，为空表示单人审批
+	AutoBatchRule     *AutoBatchRule  `gorm:"column:auto_batch_rule;type:json" json:"auto_batch_rule"`                        // 按 Git tag 自动建批规则，为空表示不自动建批
+	AutoRollback      bool            `gorm:"column:auto_rollback;not null;default:false" json:"auto_rollback"`               // 生产就绪/验证失败时自动回滚失败集群（应用可单独覆盖）
+	VulnPolicy        *VulnPolicy     `gorm:"column:vuln_policy;type:json" json:"vuln_policy"`                                // 镜像漏洞门禁，为空表示不拦截
+	JiraConfig        *JiraConfig     `gorm:"column:jira_config;type:json" json:"jira_config"`                                // Jira 关联（issue key 解析与完成后流转），为空表示不流转
+	ProdTwoPersonRule bool            `gorm:"column:prod_two_person_rule;not null;default:false" json:"prod_two_person_rule"` // 生产部署双人原则：触发人不能是批次发起人或审批人
+	NotifyDigest      bool            `gorm:"column:notify_digest;not null;default:false" json:"notify_digest"`               // 应用部署结果按批次阶段汇总为一条通知，代替逐个应用通知
 }
 
 func (Project) TableName() string {
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// RegionRollout 多 region 部署协调配置
+//
+// 开启后同一应用同一环境的集群按 cluster.region 分组、逐个 region 部署：
+//   - 前一个 region 全部部署成功（含就绪检查）并等待 stagger_seconds 后，才开始下一个 region
+//   - 前一个 region 有部署失败时，后续 region 自动暂停，失败部署重试成功后继续
+type RegionRollout struct {
+	Enabled        bool     `json:"enabled"`
+	Order          []string `json:"order,omitempty"`           // region 顺序，未列出的 region 按名称排在其后
+	StaggerSeconds int      `json:"stagger_seconds,omitempty"` // region 之间的间隔（秒）
+}
+
+// MaxRegionStaggerSeconds region 间隔上限（24h）
+const MaxRegionStaggerSeconds = 24 * 3600
+
+// IsEnabled 是否开启多 region 协调
+func (r *RegionRollout) IsEnabled() bool {
+	return r != nil && r.Enabled
+}
+
+// Validate 校验配置
+func (r *RegionRollout) Validate() error {
+	if r == nil {
+		return nil
+	}
+	if r.StaggerSeconds < 0 || r.StaggerSeconds > MaxRegionStaggerSeconds {
+		return fmt.Errorf("stagger_seconds 需在 0~%d 之间", MaxRegionStaggerSeconds)
+	}
+	seen := make(map[string]bool, len(r.Order))
+	for _, region := range r.Order {
+		if seen[region] {
+			return fmt.Errorf("order 中 region 重复: %s", region)
+		}
+		seen[region] = true
+	}
+	return nil
+}
+
+// SortRegions 按配置顺序排列 regions（未列出的按名称排在其后）
+func (r *RegionRollout) SortRegions(regions []string) []string {
+	rank := make(map[string]int)
+	if r != nil {
+		for i, region := range r.Order {
+			rank[region] = i
+		}
+	}
+	out := append([]string(nil), regions...)
+	sort.SliceStable(out, func(i, j int) bool {
+		ri, iok := rank[out[i]]
+		rj, jok := rank[out[j]]
+		switch {
+		case iok && jok:
+			return ri < rj
+		case iok != jok:
+			return iok
+		default:
+			return out[i] < out[j]
+		}
+	})
+	return out
+}
+
+// Scan 实现 sql.Scanner
+func (r *RegionRollout) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*r = RegionRollout{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, r)
+	case string:
+		return json.Unmarshal([]byte(v), r)
+	default:
+		return fmt.Errorf("cannot scan %T into RegionRollout", value)
+	}
+}
+
+// Value 实现 driver.Valuer
+func (r RegionRollout) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
@@ -1,10 +1,15 @@
 package model
 
 import (
+	"database/sql/driver"
 	"devops-cd/pkg/constants"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 const BatchTableName = "release_batches"
@@ -19,6 +24,24 @@ type Batch struct {
 	Initiator    string  `gorm:"size:50" json:"initiator"`
 	ReleaseNotes *string `gorm:"type:text" json:"release_notes"` // 批次级发布说明
 
+	// 前置批次：需达到已完成后本批次才能开始生产部署（如基础设施批次先于应用批次）
+	DependsOnBatchID *int64 `gorm:"column:depends_on_batch_id;index" json:"depends_on_batch_id"`
+
+	// Lark 话题根消息 ID：批次后续通知以话题回复发送（只读，仅由通知器写入，避免整行 Save 覆盖）
+	LarkThreadID *string `gorm:"column:lark_thread_id;size:100;->" json:"lark_thread_id"`
+
+	// 定时部署：到达计划时间后由调度任务自动触发 start_pre_deploy / start_prod_deploy，触发后清空
+	// 只读，仅由定时部署接口与调度任务按列更新，避免整行 Save 覆盖
+	ScheduledPreAt  *time.Time `gorm:"column:scheduled_pre_at;->" json:"scheduled_pre_at"`
+	ScheduledPreBy  *string    `gorm:"column:scheduled_pre_by;size:50;->" json:"scheduled_pre_by"`
+	ScheduledProdAt *time.Time `gorm:"column:scheduled_prod_at;->" json:"scheduled_prod_at"`
+	ScheduledProdBy *string    `gorm:"column:scheduled_prod_by;size:50;->" json:"scheduled_prod_by"`
+
+	// 镜像漏洞门禁豁免：设置后封板/生产部署不再按项目漏洞策略拦截（只读，仅由豁免接口按列更新）
+	VulnOverrideAt     *time.Time `gorm:"column:vuln_override_at;->" json:"vuln_override_at"`
+	VulnOverrideBy     *string    `gorm:"column:vuln_override_by;size:50;->" json:"vuln_override_by"`
+	VulnOverrideReason *string    `gorm:"column:vuln_override_reason;type:text;->" json:"vuln_override_reason"`
+
 	// 审批信息（独立于部署流程）
 	ApprovalStatus string     `gorm:"size:20;index;not null;default:pending" json:"approval_status"` // pending/approved/rejected/skipped
 	ApprovedBy     *string    `gorm:"size:50" json:"approved_by"`
@@ -53,6 +76,20 @@ func (Batch) TableName() string {
 	return BatchTableName
 }
 
+// ActiveBatches gorm scope：未结束的批次（含草稿与回滚失败），应用在其中时不允许下线或删除
+func ActiveBatches(db *gorm.DB) *gorm.DB {
+	return db.Where("status NOT IN ?", []int8{constants.BatchStatusCompleted, constants.BatchStatusRolledBack, constants.BatchStatusCancelled})
+}
+
+// AppActiveBatchNumbers 应用所在的未结束批次编号
+func AppActiveBatchNumbers(db *gorm.DB, appID int64) ([]string, error) {
+	var numbers []string
+	err := db.Model(&Batch{}).Scopes(ActiveBatches).
+		Where("id IN (?)", db.Model(&ReleaseApp{}).Select("batch_id").Where("app_id = ?", appID)).
+		Order("id").Pluck("batch_number", &numbers).Error
+	return numbers, err
+}
+
 // ReleaseApp 批次中的应用发布记录
 type ReleaseApp struct {
 	BaseModel
@@ -69,13 +106,34 @@ type ReleaseApp struct {
 	LatestBuildID       *int64  `gorm:"column:latest_build_id" json:"latest_build_id"`                      // 最新检测到的构建ID（新tag到达时更新）
 
 	// 业务字段
-	ReleaseNotes  *string   `gorm:"type:text" json:"release_notes"`    // 应用级发布说明（可选）
-	IsLocked      bool      `gorm:"default:false" json:"is_locked"`    // 是否已锁定（封板后为true）
-	SkipPreEnv    bool      `gorm:"default:false" json:"skip_pre_env"` // 是否跳过预发布环境(封板时从 app_env_configs 计算得出)
+	ReleaseNotes  *string   `gorm:"type:text" json:"release_notes"`     // 应用级发布说明（可选）
+	IsLocked      bool      `gorm:"default:false" json:"is_locked"`     // 是否已锁定（封板后为true）
+	SkipPreEnv    bool      `gorm:"default:false" json:"skip_pre_env"`  // 是否跳过预发布环境(封板时从 app_env_configs 计算得出)
+	PreOnly       bool      `gorm:"default:false" json:"pre_only"`      // 仅在预发布环境验证(Pre 验收后即完成，不参与生产发布及生产依赖)
+	SkipProdEnv   bool      `gorm:"default:false" json:"skip_prod_env"` // 是否跳过生产环境(封板时计算: pre_only 或未配置生产环境)，状态机与批次聚合据此跳过生产阶段
 	Status        int8      `gorm:"index;not null;default:0" json:"status"`
 	Reason        string    `gorm:"type:text" json:"reason"`
 	TempDependsOn Int64List `gorm:"column:temp_depends_on;type:json;default:[]" json:"temp_depends_on"` // 批次内临时依赖（JSON 数组，记录应用 ID）
 
+	// 关联 issue：封板后从本次发布的提交说明中解析（只读，仅由 issue 关联任务写入，避免整行 Save 覆盖）
+	IssueKeys StringList `gorm:"column:issue_keys;type:json;->" json:"issue_keys"`
+
+	// 部署波次：封板时按批次内依赖拓扑分层（从 1 开始），同一波次的应用之间无依赖
+	Wave int `gorm:"column:wave;not null;default:0" json:"wave"`
+
+	// 生产灰度（部署策略为 canary/staged 时有多个阶段）
+	RolloutStage  int `gorm:"column:rollout_stage;not null;default:0" json:"rollout_stage"`   // 当前已放行的阶段，触发生产部署时置为 1
+	RolloutStages int `gorm:"column:rollout_stages;not null;default:0" json:"rollout_stages"` // 总阶段数，<=1 表示不分阶段
+
+	// chart lock：封板时解析并固化的 chart 版本/digest，pre 与 prod 部署均使用该版本
+	ChartLock *ChartLock `gorm:"column:chart_lock;type:json" json:"chart_lock,omitempty"`
+
+	// manifest lock：封板时从构建对应提交读取的代码库清单（devops-cd.yaml）中该应用的定义，部署时优先于平台配置
+	ManifestLock *ManifestLock `gorm:"column:manifest_lock;type:json" json:"manifest_lock,omitempty"`
+
+	// values 覆盖：封板前设置的一次性 values（YAML/JSON），部署时在 app_chart values 最后合并（只读，仅由覆盖接口按列更新）
+	ValuesOverride *string `gorm:"column:values_override;type:text;->" json:"values_override"`
+
 	// 关联关系（用于 JOIN 查询时获取完整构建信息）
 	Batch       *Batch       `gorm:"foreignKey:BatchID" json:"batch,omitempty"`
 	Application *Application `gorm:"foreignKey:AppID" json:"application,omitempty"`
@@ -87,6 +145,157 @@ func (ReleaseApp) TableName() string {
 	return BatchReleaseAppTableName
 }
 
+// ChartLock 发布应用的 chart lock（charts 按阶段：app_chart / config_chart）
+type ChartLock struct {
+	BuildID  int64                      `json:"build_id"` // 解析时的构建，发布应用切换构建后不再生效
+	Env      string                     `json:"env"`      // 解析所用环境（有预发布环境时为 pre）
+	LockedAt time.Time                  `json:"locked_at"`
+	Charts   map[string]*ChartLockEntry `json:"charts"`
+}
+
+// ChartLockEntry 单个阶段锁定的 chart
+type ChartLockEntry struct {
+	RepoURL   string `json:"repo_url"`
+	ChartName string `json:"chart_name"`
+	Version   string `json:"version"`
+	Digest    string `json:"digest,omitempty"` // OCI 为 manifest digest，http(s) 仓库为 index 中的 chart 包 digest
+}
+
+// Entry 返回构建 buildID 在 stage 阶段锁定的 chart，lock 不存在或构建已变更时返回 nil
+func (l *ChartLock) Entry(buildID int64, stage string) *ChartLockEntry {
+	if l == nil || l.BuildID != buildID {
+		return nil
+	}
+	return l.Charts[stage]
+}
+
+// Matches 部署时解析出的仓库与 chart 名称与锁定时一致（环境间 chart 配置不同时不使用 lock）
+func (e *ChartLockEntry) Matches(repoURL, chartName string) bool {
+	return e != nil && e.RepoURL == repoURL && e.ChartName == chartName
+}
+
+// Scan 实现 sql.Scanner
+func (l *ChartLock) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*l = ChartLock{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return fmt.Errorf("cannot scan %T into ChartLock", value)
+	}
+}
+
+// Value 实现 driver.Valuer
+func (l ChartLock) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
+
+// ManifestLock 发布应用的 manifest lock：封板时构建对应提交中清单文件声明的应用配置
+//
+// 部署环境/集群、app_chart 的应用 values 层与依赖以清单为准；app_type/replicas/deployment_name 与平台不一致时只记录在 Conflicts 中
+type ManifestLock struct {
+	BuildID   int64                            `json:"build_id"` // 解析时的构建，发布应用切换构建后不再生效
+	Ref       string                           `json:"ref"`      // 读取清单的提交
+	File      string                           `json:"file"`     // 清单文件名
+	LockedAt  time.Time                        `json:"locked_at"`
+	AppType   string                           `json:"app_type"`
+	DependsOn []string                         `json:"depends_on"`
+	DependIDs Int64List                        `json:"depend_ids"` // depends_on 按项目内应用名解析出的应用 ID
+	Envs      map[string][]ManifestLockCluster `json:"envs"`       // env -> 集群
+	Conflicts []ManifestConflict               `json:"conflicts,omitempty"`
+}
+
+// ManifestLockCluster 清单中应用在某环境某集群的配置
+type ManifestLockCluster struct {
+	Cluster        string        `json:"cluster"`
+	Replicas       int           `json:"replicas,omitempty"`
+	DeploymentName string        `json:"deployment_name,omitempty"`
+	Values         []ValuesLayer `json:"values,omitempty"`
+}
+
+// ManifestConflict 清单与平台配置不一致的项
+type ManifestConflict struct {
+	Env      string `json:"env,omitempty"`
+	Cluster  string `json:"cluster,omitempty"`
+	Field    string `json:"field"` // app_type / depends_on / env_cluster / replicas / deployment_name / values
+	Platform string `json:"platform"`
+	Manifest string `json:"manifest"`
+}
+
+// Active 构建 buildID 的 manifest lock 是否生效（lock 不存在或构建已变更时不生效）
+func (l *ManifestLock) Active(buildID int64) bool {
+	return l != nil && l.BuildID == buildID
+}
+
+// Clusters 清单声明的 env 集群（按集群名排序）
+func (l *ManifestLock) Clusters(env string) []string {
+	clusters := make([]string, 0, len(l.Envs[env]))
+	for _, c := range l.Envs[env] {
+		clusters = append(clusters, c.Cluster)
+	}
+	sort.Strings(clusters)
+	return clusters
+}
+
+// Cluster 清单中 env/cluster 的配置，lock 为空或未声明时返回 nil
+func (l *ManifestLock) Cluster(env, cluster string) *ManifestLockCluster {
+	if l == nil {
+		return nil
+	}
+	for i := range l.Envs[env] {
+		if l.Envs[env][i].Cluster == cluster {
+			return &l.Envs[env][i]
+		}
+	}
+	return nil
+}
+
+// Scan 实现 sql.Scanner
+func (l *ManifestLock) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*l = ManifestLock{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return fmt.Errorf("cannot scan %T into ManifestLock", value)
+	}
+}
+
+// Value 实现 driver.Valuer
+func (l ManifestLock) Value() (driver.Value, error) {
+	return json.Marshal(l)
+}
+
+// DependsOn 发布应用的默认依赖：manifest lock 生效时以清单为准，否则为应用的 default_depends_on
+func (r *ReleaseApp) DependsOn(app *Application) []int64 {
+	if r.BuildID != nil && r.ManifestLock.Active(*r.BuildID) {
+		return r.ManifestLock.DependIDs
+	}
+	return app.DefaultDependsOn
+}
+
+// EnvClusters 发布应用在 env 的部署集群：manifest lock 生效时为清单声明的集群与平台启用配置的交集（封板时已校验清单集群均已配置），否则为平台启用配置
+func (r *ReleaseApp) EnvClusters(configs []AppEnvConfig, env string) []AppEnvConfig {
+	if r.BuildID == nil || !r.ManifestLock.Active(*r.BuildID) {
+		return configs
+	}
+	result := make([]AppEnvConfig, 0, len(configs))
+	for _, cfg := range configs {
+		if cfg.Env == env && r.ManifestLock.Cluster(env, cfg.Cluster) != nil {
+			result = append(result, cfg)
+		}
+	}
+	return result
+}
+
 const ReasonMaxLine = 100
 
 func (r *ReleaseApp) AppendReasonf(format string, args ...interface{}) {
@@ -122,6 +331,8 @@ func ToFailed(cur int8) int8 {
 		return constants.ReleaseAppStatusPreFailed
 	} else if cur >= constants.ReleaseAppStatusProdWaiting && cur < constants.BatchStatusCompleted {
 		return constants.ReleaseAppStatusProdFailed
+	} else if cur >= constants.ReleaseAppStatusRollbackCanTrigger && cur < constants.ReleaseAppStatusRolledBack {
+		return constants.ReleaseAppStatusRollbackFailed
 	}
 	return cur
 }
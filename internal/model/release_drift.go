@@ -0,0 +1,76 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const ReleaseDriftTableName = "release_drifts"
+
+// DriftFinding 单个漂移项
+type DriftFinding struct {
+	Type     string `json:"type"`               // revision/values/manifest/live，见 constants.ReleaseDriftFinding*
+	Resource string `json:"resource,omitempty"` // 资源（Kind/namespace/name），values/revision 漂移为空
+	Path     string `json:"path,omitempty"`     // 字段路径（如 spec.template.spec.containers[0].image）
+	Expected string `json:"expected,omitempty"` // 部署时的值（values 不回传具体值）
+	Actual   string `json:"actual,omitempty"`   // 集群中当前的值
+}
+
+// DriftFindings 漂移项列表
+type DriftFindings []DriftFinding
+
+// Scan 实现 sql.Scanner
+func (l *DriftFindings) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*l = DriftFindings{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return fmt.Errorf("cannot scan %T into DriftFindings", value)
+	}
+}
+
+// Value 实现 driver.Valuer
+func (l DriftFindings) Value() (driver.Value, error) {
+	if len(l) == 0 {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(l)
+}
+
+// ReleaseDrift 配置漂移检测结果：应用在 env/cluster 上最近一次写入 release 的 Deployment 与集群中当前状态的对比
+//
+// - 每个应用/环境/集群/部署类型一条记录，定时检测时覆盖
+// - detected_at 为本次漂移首次发现的时间，恢复为 ok 后清空；漂移内容变化（digest）后重新通知
+type ReleaseDrift struct {
+	BaseModel
+
+	ProjectID    int64  `gorm:"column:project_id;not null;index" json:"project_id"`
+	AppID        int64  `gorm:"column:app_id;not null" json:"app_id"`
+	Env          string `gorm:"size:20;not null" json:"env"`
+	ClusterName  string `gorm:"column:cluster;size:63;not null" json:"cluster_name"`
+	Kind         string `gorm:"size:20;not null" json:"kind"`
+	DeploymentID int64  `gorm:"column:deployment_id;not null" json:"deployment_id"` // 作为基准的 Deployment
+	Namespace    string `gorm:"size:63;not null" json:"namespace"`
+	ReleaseName  string `gorm:"column:release_name;size:63;not null" json:"release_name"`
+
+	Status         string        `gorm:"size:20;not null" json:"status"` // ok/drift/missing/error，见 constants.ReleaseDriftStatus*
+	Findings       DriftFindings `gorm:"type:json" json:"findings"`
+	Digest         string        `gorm:"size:64" json:"digest"` // 漂移内容摘要，用于判断是否需要重新通知
+	DetectedAt     *time.Time    `gorm:"column:detected_at" json:"detected_at"`
+	NotifiedDigest *string       `gorm:"column:notified_digest;size:64" json:"notified_digest"`
+	ErrorMessage   *string       `gorm:"type:text" json:"error_message"`
+	CheckedAt      time.Time     `gorm:"column:checked_at;not null" json:"checked_at"`
+
+	Application *Application `gorm:"foreignKey:AppID" json:"application,omitempty"`
+}
+
+func (ReleaseDrift) TableName() string {
+	return ReleaseDriftTableName
+}
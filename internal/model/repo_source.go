@@ -16,9 +16,12 @@ type RepoSource struct {
 	BaseURL          string            `gorm:"size:255;not null;index:idx_repo_source_base_namespace,priority:1" json:"base_url"`
 	Namespace        string            `gorm:"size:255;not null;index:idx_repo_source_base_namespace,priority:2" json:"namespace"`
 	AuthTokenEnc     string            `gorm:"type:text;not null" json:"-"`
+	AuthTokenKeyID   string            `gorm:"column:auth_token_key_id;size:255;not null;default:''" json:"-"` // 信封加密 key_id，为空表示旧格式（crypto.aes_key 直接加密）
 	Enabled          bool              `gorm:"not null;default:true;index" json:"enabled"`
-	DefaultProjectID *int64            `gorm:"index" json:"default_project_id"` // 默认项目ID
-	DefaultTeamID    *int64            `gorm:"index" json:"default_team_id"`    // 默认团队ID
+	DefaultProjectID *int64            `gorm:"index" json:"default_project_id"`   // 默认项目ID
+	DefaultTeamID    *int64            `gorm:"index" json:"default_team_id"`      // 默认团队ID
+	DiscoveryFiles   StringList        `gorm:"type:json" json:"discovery_files"`  // 发现规则：代码库无清单但根目录存在其中任一文件时按代码库创建应用，为空表示不启用
+	DiscoveryAppType *string           `gorm:"size:20" json:"discovery_app_type"` // 发现规则创建应用的 app_type，为空时按代码库语言推断
 	LastSyncedAt     *time.Time        `json:"last_synced_at"`
 	LastStatus       *string           `gorm:"size:20" json:"last_status"`
 	LastMessage      *string           `gorm:"type:text" json:"last_message"`
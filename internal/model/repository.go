@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 const RepositoryTableName = "repositories"
 
 // Repository 代码库模型
@@ -14,6 +16,14 @@ type Repository struct {
 	ProjectID   *int64  `gorm:"column:project_id;index" json:"project_id"`
 	TeamID      *int64  `gorm:"column:team_id" json:"team_id"`
 
+	// devops-cd.yaml 清单同步结果
+	ManifestStatus   *string    `gorm:"column:manifest_status;size:20" json:"manifest_status"`
+	ManifestReport   *string    `gorm:"column:manifest_report;type:json" json:"-"` // 最近一次同步报告（dto.ManifestSyncReport）
+	ManifestSyncedAt *time.Time `gorm:"column:manifest_synced_at" json:"manifest_synced_at"`
+
+	// Jenkins 构建通知共享 token 的 SHA-256（十六进制，不保存明文；/build/notify/jenkins 按代码库校验），为空时拒绝该代码库的 Jenkins 通知
+	CIToken *string `gorm:"column:ci_token;size:200" json:"-"`
+
 	// Relations
 	Team    *Team    `gorm:"foreignKey:TeamID" json:"team,omitempty"`
 	Project *Project `gorm:"foreignKey:ProjectID" json:"project,omitempty"`
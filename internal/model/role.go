@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+const (
+	RoleTableName           = "roles"
+	RolePermissionTableName = "role_permissions"
+)
+
+// Role 自定义角色（内置角色见 internal/pkg/auth，二者通过角色名分配给用户/团队成员/团队）
+type Role struct {
+	BaseModel
+
+	Name        string `gorm:"size:50;not null;uniqueIndex" json:"name"` // 角色标识，写入 users.system_roles / team_members.roles / teams.roles
+	DisplayName string `gorm:"size:100" json:"display_name"`
+	Description string `gorm:"size:500" json:"description"`
+
+	CreatedBy string `gorm:"size:50" json:"created_by"`
+	UpdatedBy string `gorm:"size:50" json:"updated_by"`
+
+	Permissions []RolePermission `gorm:"foreignKey:RoleID" json:"permissions,omitempty"`
+}
+
+func (Role) TableName() string {
+	return RoleTableName
+}
+
+// RolePermission 角色-权限绑定，permission 支持通配符（如 batch:*、*:view）
+type RolePermission struct {
+	ID         int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	RoleID     int64     `gorm:"not null;uniqueIndex:uk_role_permission" json:"role_id"`
+	Permission string    `gorm:"size:100;not null;uniqueIndex:uk_role_permission" json:"permission"`
+	CreatedAt  time.Time `gorm:"not null;autoCreateTime" json:"created_at"`
+}
+
+func (RolePermission) TableName() string {
+	return RolePermissionTableName
+}
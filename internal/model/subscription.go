@@ -0,0 +1,27 @@
+package model
+
+const SubscriptionTableName = "subscriptions"
+
+// 订阅对象类型
+const (
+	SubscriptionTargetBatch = "batch"
+	SubscriptionTargetApp   = "app"
+)
+
+// Subscription 用户订阅（关注）批次或应用，状态变更时私聊/邮件通知订阅人
+//
+// - batch: 批次状态变更（与群通知的批次状态一致）
+// - app: 该应用在任意批次中的发布状态变更（部署成功/失败）
+type Subscription struct {
+	BaseModel
+
+	Username   string     `gorm:"size:50;not null;uniqueIndex:uk_subscription" json:"username"`
+	TargetType string     `gorm:"size:20;not null;uniqueIndex:uk_subscription;index:idx_target" json:"target_type"`
+	TargetID   int64      `gorm:"not null;uniqueIndex:uk_subscription;index:idx_target" json:"target_id"`
+	ProjectID  int64      `gorm:"not null;index" json:"project_id"`
+	Channels   StringList `gorm:"type:json" json:"channels"` // notification.ChannelLark / ChannelEmail，为空表示所有已配置渠道
+}
+
+func (Subscription) TableName() string {
+	return SubscriptionTableName
+}
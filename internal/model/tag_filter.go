@@ -0,0 +1,103 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// TagFilter 应用构建过滤规则（正则），决定哪些成功构建会更新草稿批次、作为候选版本展示
+//
+// 匹配规则：
+//   - include_* 为空表示不限制，否则至少匹配一条
+//   - exclude_* 任意一条匹配即排除
+//
+// 示例：只接受 v 开头的 tag，排除 -rc：
+//
+//	{"include_tags": ["^v"], "exclude_tags": ["-rc"]}
+type TagFilter struct {
+	IncludeTags     []string `json:"include_tags,omitempty"`     // 镜像 tag 白名单
+	ExcludeTags     []string `json:"exclude_tags,omitempty"`     // 镜像 tag 黑名单
+	IncludeBranches []string `json:"include_branches,omitempty"` // 分支白名单
+	ExcludeBranches []string `json:"exclude_branches,omitempty"` // 分支黑名单
+}
+
+// IsEmpty 是否未配置任何规则
+func (f *TagFilter) IsEmpty() bool {
+	return f == nil || len(f.IncludeTags)+len(f.ExcludeTags)+len(f.IncludeBranches)+len(f.ExcludeBranches) == 0
+}
+
+// Validate 校验所有正则是否合法
+func (f *TagFilter) Validate() error {
+	if f == nil {
+		return nil
+	}
+	groups := map[string][]string{
+		"include_tags":     f.IncludeTags,
+		"exclude_tags":     f.ExcludeTags,
+		"include_branches": f.IncludeBranches,
+		"exclude_branches": f.ExcludeBranches,
+	}
+	for name, patterns := range groups {
+		for _, p := range patterns {
+			if _, err := regexp.Compile(p); err != nil {
+				return fmt.Errorf("%s 正则不合法 %q: %w", name, p, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Match 判断构建的 tag/分支 是否满足规则；非法正则视为不匹配
+func (f *TagFilter) Match(tag, branch string) bool {
+	if f.IsEmpty() {
+		return true
+	}
+	return matchRules(f.IncludeTags, f.ExcludeTags, tag) && matchRules(f.IncludeBranches, f.ExcludeBranches, branch)
+}
+
+// MatchBuild 判断构建是否满足规则
+func (f *TagFilter) MatchBuild(build *Build) bool {
+	if build == nil {
+		return false
+	}
+	return f.Match(build.ImageTag, build.CommitBranch)
+}
+
+func matchRules(include, exclude []string, value string) bool {
+	for _, p := range exclude {
+		if re, err := regexp.Compile(p); err != nil || re.MatchString(value) {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, p := range include {
+		if re, err := regexp.Compile(p); err == nil && re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan 实现 sql.Scanner
+func (f *TagFilter) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*f = TagFilter{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, f)
+	case string:
+		return json.Unmarshal([]byte(v), f)
+	default:
+		return fmt.Errorf("cannot scan %T into TagFilter", value)
+	}
+}
+
+// Value 实现 driver.Valuer
+func (f TagFilter) Value() (driver.Value, error) {
+	return json.Marshal(f)
+}
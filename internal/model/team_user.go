@@ -35,6 +35,9 @@ type Team struct {
 	ProjectID   int64   `gorm:"not null;index" json:"project_id"`
 	Description *string `gorm:"type:text" json:"description"`
 	LeaderName  *string `gorm:"size:100" json:"leader_name"`
+
+	// 团队角色：团队所有成员额外拥有的角色（内置或自定义角色名）
+	Roles StringList `gorm:"column:roles;type:json" json:"roles"`
 }
 
 func (Team) TableName() string {
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+const (
+	WebhookTableName         = "webhooks"
+	WebhookDeliveryTableName = "webhook_deliveries"
+)
+
+// Webhook 出站事件订阅（批次封板、部署成功/失败等事件推送到用户注册的 URL）
+//
+// 引擎按事件过滤匹配订阅并写入投递记录，由扫描器异步 POST 签名 JSON：
+// 请求头 X-DevOps-CD-Signature = "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))
+type Webhook struct {
+	BaseModel
+
+	Name        string     `gorm:"size:100;not null" json:"name"`
+	ProjectID   *int64     `gorm:"index" json:"project_id"` // 为空表示订阅所有项目
+	URL         string     `gorm:"column:url;size:500;not null" json:"url"`
+	Events      StringList `gorm:"type:json" json:"events"` // pkg/constants:WebhookEvent*，包含 "*" 表示全部事件
+	Enabled     bool       `gorm:"not null;default:true" json:"enabled"`
+	Description string     `gorm:"size:500" json:"description"`
+
+	// 签名密钥（信封加密存储，不对外返回）
+	EncryptedSecret *string `gorm:"column:encrypted_secret;type:text" json:"-"`
+	SecretKeyID     string  `gorm:"column:secret_key_id;size:255" json:"-"`
+
+	CreatedBy string `gorm:"size:50" json:"created_by"`
+	UpdatedBy string `gorm:"size:50" json:"updated_by"`
+}
+
+func (Webhook) TableName() string {
+	return WebhookTableName
+}
+
+// Subscribed 是否订阅了事件
+func (w *Webhook) Subscribed(event string) bool {
+	for _, e := range w.Events {
+		if e == "*" || e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery 投递记录：每个 (订阅, 事件) 一条，重试时原地更新
+type WebhookDelivery struct {
+	BaseModel
+
+	WebhookID     int64          `gorm:"index;not null" json:"webhook_id"`
+	Event         string         `gorm:"size:50;not null" json:"event"`
+	Payload       datatypes.JSON `gorm:"type:json" json:"payload"`
+	Status        string         `gorm:"size:20;index;not null" json:"status"` // pkg/constants:WebhookDelivery*
+	Attempts      int            `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt *time.Time     `gorm:"index" json:"next_attempt_at"`
+	ResponseCode  int            `json:"response_code"`
+	ResponseBody  string         `gorm:"type:text" json:"response_body"` // 截断保存
+	Error         string         `gorm:"type:text" json:"error"`
+	DeliveredAt   *time.Time     `json:"delivered_at"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return WebhookDeliveryTableName
+}
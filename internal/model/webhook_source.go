@@ -0,0 +1,73 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/datatypes"
+)
+
+const WebhookSourceTableName = "webhook_sources"
+
+// WebhookSource 通用 CI 构建通知来源（Jenkins、TeamCity 等）
+//
+// 外部 CI 调用 POST /api/v1/build/notify/:name，payload 按 mapping 转换为标准构建通知后
+// 走与 /build/notify 相同的处理流程；配置了 token 时需携带请求头 X-Webhook-Token
+type WebhookSource struct {
+	BaseModel
+
+	Name        string  `gorm:"size:50;not null;uniqueIndex" json:"name"` // URL 中的来源标识
+	Description string  `gorm:"size:500" json:"description"`
+	Enabled     bool    `gorm:"not null;default:true" json:"enabled"`
+	Token       *string `gorm:"size:200" json:"-"`
+
+	// Repos 允许通知的代码库（namespace/name，支持 * 通配，如 platform/*），为空不限制
+	Repos StringList `gorm:"type:json" json:"repos"`
+
+	Mapping       WebhookMapping `gorm:"type:json" json:"mapping"`
+	SamplePayload datatypes.JSON `gorm:"type:json" json:"sample_payload"` // 测试 payload，保存时校验能映射出合法的构建通知
+
+	CreatedBy string `gorm:"size:50" json:"created_by"`
+	UpdatedBy string `gorm:"size:50" json:"updated_by"`
+}
+
+func (WebhookSource) TableName() string {
+	return WebhookSourceTableName
+}
+
+// WebhookMapping payload → 构建通知的字段映射
+//
+// JSONPath 使用 kubectl 语法（如 {.build.number}），也可省略花括号（.build.number / $.build.number）
+//   - fields: 构建通知字段 → JSONPath，字段名与 /build/notify 请求体一致（repo、build_number、commit_id 等）
+//   - apps_path: 应用数组的 JSONPath；为空时整个 payload 视为单个应用
+//   - app_fields: 应用字段（name、image_tag、image、build_success）→ 相对于应用元素的 JSONPath
+//   - defaults: JSONPath 无结果时的缺省值，应用字段以 "apps." 为前缀
+//   - value_maps: 取值映射，如 build_status: {"SUCCESS": "success", "FAILURE": "failure"}
+type WebhookMapping struct {
+	Fields    map[string]string            `json:"fields"`
+	AppsPath  string                       `json:"apps_path,omitempty"`
+	AppFields map[string]string            `json:"app_fields"`
+	Defaults  map[string]string            `json:"defaults,omitempty"`
+	ValueMaps map[string]map[string]string `json:"value_maps,omitempty"`
+}
+
+// Scan 实现 sql.Scanner
+func (m *WebhookMapping) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*m = WebhookMapping{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, m)
+	case string:
+		return json.Unmarshal([]byte(v), m)
+	default:
+		return fmt.Errorf("cannot scan %T into WebhookMapping", value)
+	}
+}
+
+// Value 实现 driver.Valuer
+func (m WebhookMapping) Value() (driver.Value, error) {
+	return json.Marshal(m)
+}
@@ -0,0 +1,266 @@
+package appquery
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 应用搜索查询语言
+//
+// 示例:
+//
+//	team:payments type:java,go deployed_tag~1.4 status:enabled -project:legacy user-api
+//
+// 语法:
+//   - 条件之间以空格分隔，全部为 AND
+//   - field:value 精确匹配，逗号分隔多个值表示任一匹配（IN）
+//   - field~value 模糊匹配（LIKE %value%）
+//   - 条件前加 - 表示取反
+//   - 值包含空格时用双引号包裹，如 description~"user center"
+//   - 不带字段的词按应用名模糊匹配
+
+// MaxTerms 单个查询的条件数量上限
+const MaxTerms = 20
+
+// Op 匹配方式
+type Op string
+
+const (
+	OpEqual    Op = ":" // 精确匹配
+	OpContains Op = "~" // 模糊匹配
+)
+
+// Term 单个查询条件
+type Term struct {
+	Field  string   // 字段名（已转小写），空表示按应用名模糊匹配
+	Op     Op       // 匹配方式
+	Values []string // OpEqual 时可有多个值
+	Negate bool     // 取反
+}
+
+// Query 解析后的查询
+type Query struct {
+	Raw   string
+	Terms []Term
+}
+
+// fieldDef 字段定义：column 为 applications 表（别名 a）上的表达式，lookup 非空时先按名称查关联表 ID
+type fieldDef struct {
+	column   string
+	lookup   string // 关联表子查询，如 "SELECT id FROM teams WHERE %s"
+	lookupOn string // 关联表中匹配的列
+	contains bool   // 是否支持模糊匹配
+	convert  func(string) (interface{}, error)
+}
+
+var fields = map[string]fieldDef{
+	"name":         {column: "a.name", contains: true},
+	"description":  {column: "a.description", contains: true},
+	"type":         {column: "a.app_type", contains: false},
+	"deployed_tag": {column: "a.deployed_tag", contains: true},
+	"status":       {column: "a.status", convert: convertStatus},
+	"team":         {column: "a.team_id", lookup: "SELECT id FROM teams WHERE deleted_at IS NULL AND %s", lookupOn: "name", contains: true},
+	"project":      {column: "a.project_id", lookup: "SELECT id FROM projects WHERE deleted_at IS NULL AND %s", lookupOn: "name", contains: true},
+	"repo":         {column: "a.repo_id", lookup: "SELECT id FROM repositories WHERE deleted_at IS NULL AND %s", lookupOn: "CONCAT(namespace, '/', name)", contains: true},
+}
+
+// 字段别名
+var aliases = map[string]string{
+	"app":      "name",
+	"app_type": "type",
+	"tag":      "deployed_tag",
+}
+
+// Fields 支持的字段（含别名），用于错误提示
+func Fields() []string {
+	names := make([]string, 0, len(fields)+len(aliases))
+	for name := range fields {
+		names = append(names, name)
+	}
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parse 解析查询字符串，字段/取值不合法时返回错误
+func Parse(raw string) (*Query, error) {
+	tokens, err := tokenize(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) > MaxTerms {
+		return nil, fmt.Errorf("查询条件过多（最多 %d 个）", MaxTerms)
+	}
+
+	q := &Query{Raw: raw, Terms: make([]Term, 0, len(tokens))}
+	for _, tok := range tokens {
+		term, err := parseTerm(tok)
+		if err != nil {
+			return nil, err
+		}
+		q.Terms = append(q.Terms, term)
+	}
+	return q, nil
+}
+
+// tokenize 按空白切分，双引号内的空白保留
+func tokenize(raw string) ([]string, error) {
+	var tokens []string
+	var cur strings.Builder
+	inQuote := false
+	for _, r := range raw {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			cur.WriteRune(r)
+		case !inQuote && (r == ' ' || r == '\t' || r == '\n'):
+			if cur.Len() > 0 {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("查询语法错误: 引号未闭合")
+	}
+	if cur.Len() > 0 {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens, nil
+}
+
+func parseTerm(tok string) (Term, error) {
+	term := Term{Op: OpContains}
+	if strings.HasPrefix(tok, "-") && len(tok) > 1 {
+		term.Negate = true
+		tok = tok[1:]
+	}
+
+	// 第一个 : 或 ~ 之前为字段名（引号内的不算）
+	idx := strings.IndexAny(tok, ":~")
+	if q := strings.IndexByte(tok, '"'); idx < 0 || (q >= 0 && q < idx) {
+		value := unquote(tok)
+		if value == "" {
+			return term, fmt.Errorf("查询语法错误: 空条件")
+		}
+		term.Values = []string{value}
+		return term, nil
+	}
+
+	field := strings.ToLower(tok[:idx])
+	if alias, ok := aliases[field]; ok {
+		field = alias
+	}
+	def, ok := fields[field]
+	if !ok {
+		return term, fmt.Errorf("未知的查询字段: %s（支持: %s）", tok[:idx], strings.Join(Fields(), ", "))
+	}
+	term.Field = field
+	term.Op = Op(tok[idx : idx+1])
+
+	value := unquote(tok[idx+1:])
+	if value == "" {
+		return term, fmt.Errorf("查询字段 %s 缺少取值", field)
+	}
+	if term.Op == OpContains {
+		if !def.contains {
+			return term, fmt.Errorf("查询字段 %s 不支持模糊匹配（~），请使用 %s:", field, field)
+		}
+		term.Values = []string{value}
+		return term, nil
+	}
+
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		if def.convert != nil {
+			if _, err := def.convert(v); err != nil {
+				return term, fmt.Errorf("查询字段 %s 取值无效: %v", field, err)
+			}
+		}
+		term.Values = append(term.Values, v)
+	}
+	if len(term.Values) == 0 {
+		return term, fmt.Errorf("查询字段 %s 缺少取值", field)
+	}
+	return term, nil
+}
+
+func unquote(s string) string {
+	return strings.TrimSpace(strings.ReplaceAll(s, `"`, ""))
+}
+
+// SQL 编译为 applications（别名 a）上的 WHERE 条件及参数
+func (q *Query) SQL() (string, []interface{}) {
+	if q == nil || len(q.Terms) == 0 {
+		return "", nil
+	}
+	conds := make([]string, 0, len(q.Terms))
+	var args []interface{}
+	for _, term := range q.Terms {
+		cond, termArgs := term.sql()
+		if term.Negate {
+			// 字段为 NULL 时也应命中，如 -deployed_tag:1.0 包含未部署的应用
+			cond = "NOT COALESCE(" + cond + ", FALSE)"
+		}
+		conds = append(conds, cond)
+		args = append(args, termArgs...)
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+func (t Term) sql() (string, []interface{}) {
+	if t.Field == "" {
+		return "a.name LIKE ?", []interface{}{likePattern(t.Values[0])}
+	}
+
+	def := fields[t.Field]
+	var match string
+	var args []interface{}
+	target := def.column
+	if def.lookup != "" {
+		target = def.lookupOn
+	}
+	if t.Op == OpContains {
+		match = target + " LIKE ?"
+		args = []interface{}{likePattern(t.Values[0])}
+	} else {
+		values := make([]interface{}, len(t.Values))
+		for i, v := range t.Values {
+			values[i] = v
+			if def.convert != nil {
+				values[i], _ = def.convert(v)
+			}
+		}
+		match = target + " IN ?"
+		args = []interface{}{values}
+	}
+
+	if def.lookup != "" {
+		return def.column + " IN (" + fmt.Sprintf(def.lookup, match) + ")", args
+	}
+	return match, args
+}
+
+// likePattern 转义 LIKE 通配符后两侧加 %
+func likePattern(v string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + r.Replace(v) + "%"
+}
+
+func convertStatus(v string) (interface{}, error) {
+	switch strings.ToLower(v) {
+	case "enabled", "1":
+		return int8(1), nil
+	case "disabled", "0":
+		return int8(0), nil
+	default:
+		return nil, fmt.Errorf("status 仅支持 enabled/disabled")
+	}
+}
@@ -0,0 +1,111 @@
+package appquery
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		raw     string
+		want    []Term
+		wantErr string
+	}{
+		{raw: "", want: []Term{}},
+		{raw: "user-api", want: []Term{{Op: OpContains, Values: []string{"user-api"}}}},
+		{raw: `"user center"`, want: []Term{{Op: OpContains, Values: []string{"user center"}}}},
+		{raw: "TEAM:payments", want: []Term{{Field: "team", Op: OpEqual, Values: []string{"payments"}}}},
+		{raw: "type:java,go,", want: []Term{{Field: "type", Op: OpEqual, Values: []string{"java", "go"}}}},
+		{raw: "tag~1.4", want: []Term{{Field: "deployed_tag", Op: OpContains, Values: []string{"1.4"}}}},
+		{raw: "-project:legacy", want: []Term{{Field: "project", Op: OpEqual, Values: []string{"legacy"}, Negate: true}}},
+		{raw: `description~"user center"`, want: []Term{{Field: "description", Op: OpContains, Values: []string{"user center"}}}},
+		{raw: `"a:b"`, want: []Term{{Op: OpContains, Values: []string{"a:b"}}}},
+		{raw: "status:enabled,0", want: []Term{{Field: "status", Op: OpEqual, Values: []string{"enabled", "0"}}}},
+
+		{raw: `name~"x`, wantErr: "引号未闭合"},
+		{raw: "owner:bob", wantErr: "未知的查询字段"},
+		{raw: "team:", wantErr: "缺少取值"},
+		{raw: "type:,", wantErr: "缺少取值"},
+		{raw: "type~java", wantErr: "不支持模糊匹配"},
+		{raw: "status:archived", wantErr: "取值无效"},
+		{raw: `""`, wantErr: "空条件"},
+		{raw: strings.Repeat("a ", MaxTerms+1), wantErr: "查询条件过多"},
+	}
+	for _, c := range cases {
+		q, err := Parse(c.raw)
+		if c.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Errorf("Parse(%q) err = %v, want %q", c.raw, err, c.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q): %v", c.raw, err)
+			continue
+		}
+		if !reflect.DeepEqual(q.Terms, c.want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", c.raw, q.Terms, c.want)
+		}
+	}
+}
+
+func TestQuerySQL(t *testing.T) {
+	cases := []struct {
+		raw      string
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{raw: "", wantSQL: ""},
+		{
+			raw:      "user_api",
+			wantSQL:  "a.name LIKE ?",
+			wantArgs: []interface{}{`%user\_api%`},
+		},
+		{
+			raw:      `description~"50% off"`,
+			wantSQL:  "a.description LIKE ?",
+			wantArgs: []interface{}{`%50\% off%`},
+		},
+		{
+			raw:      "type:java,go status:disabled",
+			wantSQL:  "a.app_type IN ? AND a.status IN ?",
+			wantArgs: []interface{}{[]interface{}{"java", "go"}, []interface{}{int8(0)}},
+		},
+		{
+			raw:      "team:payments",
+			wantSQL:  "a.team_id IN (SELECT id FROM teams WHERE deleted_at IS NULL AND name IN ?)",
+			wantArgs: []interface{}{[]interface{}{"payments"}},
+		},
+		{
+			raw:      "repo~org/user",
+			wantSQL:  "a.repo_id IN (SELECT id FROM repositories WHERE deleted_at IS NULL AND CONCAT(namespace, '/', name) LIKE ?)",
+			wantArgs: []interface{}{"%org/user%"},
+		},
+		{
+			// 取反时 NULL 也命中
+			raw:      "-deployed_tag:1.0 -project~legacy",
+			wantSQL:  "NOT COALESCE(a.deployed_tag IN ?, FALSE) AND NOT COALESCE(a.project_id IN (SELECT id FROM projects WHERE deleted_at IS NULL AND name LIKE ?), FALSE)",
+			wantArgs: []interface{}{[]interface{}{"1.0"}, "%legacy%"},
+		},
+		{
+			// 取值只作为参数，不拼入 SQL
+			raw:      `name:"x' OR 1=1 --"`,
+			wantSQL:  "a.name IN ?",
+			wantArgs: []interface{}{[]interface{}{"x' OR 1=1 --"}},
+		},
+	}
+	for _, c := range cases {
+		q, err := Parse(c.raw)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", c.raw, err)
+		}
+		sql, args := q.SQL()
+		if sql != c.wantSQL {
+			t.Errorf("SQL(%q) = %q, want %q", c.raw, sql, c.wantSQL)
+		}
+		if !reflect.DeepEqual(args, c.wantArgs) {
+			t.Errorf("SQL(%q) args = %#v, want %#v", c.raw, args, c.wantArgs)
+		}
+	}
+}
@@ -12,6 +12,9 @@ const (
 	RoleProjectViewer Role = "project_viewer"
 	RoleTeamAdmin     Role = "team_admin"
 	RoleMember        Role = "team_member"
+
+	// RoleProdOperator 生产环境操作员：可触发生产部署、生产切换版本/手动部署
+	RoleProdOperator Role = "prod_operator"
 )
 
 // Permission 内置权限
@@ -22,6 +25,10 @@ const (
 	PermProjectDelete Permission = "project:delete"
 	PermProjectUpdate Permission = "project:update"
 
+	// PermProjectPolicy 修改项目策略（审批策略、双人原则、漏洞门禁、自动回滚、自动建批、Jira 关联）
+	// 只按用户在该项目下所属团队的角色计算，内置角色中仅 project_admin 与 system_admin 具备
+	PermProjectPolicy Permission = "project:policy:update"
+
 	PermBatchCreate  Permission = "batch:create"
 	PermBatchUpdate  Permission = "batch:update"
 	PermBatchDelete  Permission = "batch:delete"
@@ -32,8 +39,83 @@ const (
 	PermReleaseAppCreate Permission = "batch:release_app:create"
 	PermReleaseAppUpdate Permission = "batch:release_app:update"
 	PermReleaseAppDelete Permission = "batch:release_app:delete"
+
+	// PermProdOperate 生产环境操作（start_prod_deploy、prod 切换版本、prod 手动部署）
+	// 独立于 batch:*，团队成员默认不具备
+	PermProdOperate Permission = "env:prod:operate"
+
+	// PermVulnOverride 豁免批次的镜像漏洞门禁（项目 vuln_policy）
+	// 不属于任何内置项目角色，需系统管理员或自定义角色授予
+	PermVulnOverride Permission = "security:vuln:override"
+
+	PermAnnouncementManage Permission = "system:announcement:manage"
+	PermConsistencyManage  Permission = "system:consistency:manage"
+	PermEngineManage       Permission = "system:engine:manage"
+	PermWebhookManage      Permission = "system:webhook:manage"
+	PermNotificationManage Permission = "system:notification:manage"
+	PermRoleManage         Permission = "system:role:manage"
+	PermJobManage          Permission = "system:job:manage"
+	PermAPITokenManage     Permission = "system:api_token:manage"
 )
 
+// BuiltinRoles 内置角色（固定顺序），自定义角色不能与之重名
+var BuiltinRoles = []Role{
+	RoleSystemAdmin,
+	RoleSystemViewer,
+	RoleProjectAdmin,
+	RoleProjectViewer,
+	RoleTeamAdmin,
+	RoleMember,
+	RoleProdOperator,
+}
+
+// AllPermissions 已知的权限点（自定义角色配置时供选择，也可使用通配符）
+var AllPermissions = []Permission{
+	PermProjectCreate,
+	PermProjectDelete,
+	PermProjectUpdate,
+	PermProjectPolicy,
+	PermBatchCreate,
+	PermBatchUpdate,
+	PermBatchDelete,
+	PermBatchFlow,
+	PermBatchView,
+	PermBatchApprove,
+	PermReleaseAppCreate,
+	PermReleaseAppUpdate,
+	PermReleaseAppDelete,
+	PermProdOperate,
+	PermVulnOverride,
+	PermAnnouncementManage,
+	PermConsistencyManage,
+	PermEngineManage,
+	PermWebhookManage,
+	PermNotificationManage,
+	PermRoleManage,
+	PermJobManage,
+	PermAPITokenManage,
+}
+
+// APITokenScopes API Token 可授予的权限（只读）
+var APITokenScopes = []Permission{
+	PermBatchView,
+}
+
+// ProjectPermissions 项目范围内的权限（权限查询接口返回）
+var ProjectPermissions = []Permission{
+	PermBatchCreate,
+	PermBatchUpdate,
+	PermBatchDelete,
+	PermBatchFlow,
+	PermBatchView,
+	PermBatchApprove,
+	PermReleaseAppCreate,
+	PermReleaseAppUpdate,
+	PermReleaseAppDelete,
+	PermProdOperate,
+	PermVulnOverride,
+}
+
 // RolePermissions 每个角色拥有的权限集合
 var RolePermissions = map[Role][]Permission{
 	RoleSystemAdmin: {
@@ -46,6 +128,7 @@ var RolePermissions = map[Role][]Permission{
 		"project:*",
 		"batch:*",
 		"team:*",
+		"env:*",
 	},
 	RoleProjectViewer: {
 		"project:view",
@@ -59,6 +142,9 @@ var RolePermissions = map[Role][]Permission{
 	RoleMember: {
 		"batch:*",
 	},
+	RoleProdOperator: {
+		"env:prod:*",
+	},
 }
 
 // Allow 判断一组角色是否包含所需权限，支持通配符
@@ -68,6 +154,23 @@ func Allow(roles []string, need Permission) bool {
 	return len(permissions) > 0 && allow(permissions, need)
 }
 
+// AllowPermissions 判断权限集合是否包含所需权限（自定义角色使用），支持通配符
+func AllowPermissions(have []Permission, need Permission) bool {
+	return allow(have, need)
+}
+
+// ProjectScoped 环境权限（env:*）与项目策略权限只按用户在该项目下所属团队的角色计算，
+// 其他项目权限沿用所有团队角色的并集；系统级角色始终生效
+func ProjectScoped(perm Permission) bool {
+	return strings.HasPrefix(string(perm), "env:") || perm == PermProjectPolicy
+}
+
+// IsBuiltin 是否为内置角色
+func IsBuiltin(role string) bool {
+	_, ok := RolePermissions[Role(role)]
+	return ok
+}
+
 func collectPermissions(roles []string) []Permission {
 	perms := make([]Permission, 0)
 	for _, r := range roles {
@@ -80,39 +183,34 @@ func collectPermissions(roles []string) []Permission {
 
 func allow(have []Permission, need Permission) bool {
 	for _, p := range have {
-		if p == need {
+		if match(p, need) {
 			return true
 		}
+	}
+	return false
+}
 
-		if p == "*" {
-			return true
-		}
+// match 判断单个权限是否覆盖所需权限
+//   - 末段为 * 时匹配剩余所有段（类似 RESTful 的 /**），如 batch:* 覆盖 batch:release_app:create
+//   - 中间段为 * 时匹配任意单段，如 *:view 覆盖 batch:view
+func match(p, need Permission) bool {
+	if p == need || p == "*" {
+		return true
+	}
 
-		reqParts := strings.Split(string(need), ":")
-		allParts := strings.Split(string(p), ":")
+	reqParts := strings.Split(string(need), ":")
+	allParts := strings.Split(string(p), ":")
 
-		// 必须段数一致或 allowed 更短（支持前缀通配）
-		if len(allParts) > len(reqParts)+1 || len(allParts) < 1 {
-			return false
+	for i, part := range allParts {
+		if i >= len(reqParts) {
+			return false // required 已经结束，但 allowed 还有更多段
 		}
-
-		for i := 0; i < len(allParts); i++ {
-			if i >= len(reqParts) {
-				return false // required 已经结束，但 allowed 还有更多段
-			}
-
-			if allParts[i] == "*" {
-				// * 可以匹配剩余所有段（类似 RESTful 的 /**）
-				return true
-			}
-
-			if allParts[i] != reqParts[i] {
-				return false
-			}
+		if part == "*" && i == len(allParts)-1 {
+			return true
+		}
+		if part != "*" && part != reqParts[i] {
+			return false
 		}
-
-		// allowed 已经匹配完，required 可能还有剩余 → 允许以 * 结尾
-		return len(allParts) > 0 && allParts[len(allParts)-1] == "*" && len(reqParts) >= len(allParts)-1
 	}
-	return false
+	return len(allParts) == len(reqParts)
 }
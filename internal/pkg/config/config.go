@@ -2,7 +2,9 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"sort"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -11,14 +13,18 @@ var GlobalConfig *Config
 
 // Config 全局配置
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Auth     AuthConfig     `mapstructure:"auth"`
-	Crypto   CryptoConfig   `mapstructure:"crypto"`
-	Log      LogConfig      `mapstructure:"log"`
-	Core     CoreConfig     `mapstructure:"core"`
-	Repo     RepoConfig     `mapstructure:"repo"`
-	DB       interface{}    // 数据库连接,运行时注入
+	Server      ServerConfig      `mapstructure:"server"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	Auth        AuthConfig        `mapstructure:"auth"`
+	Crypto      CryptoConfig      `mapstructure:"crypto"`
+	Secrets     SecretsConfig     `mapstructure:"secrets"`
+	Log         LogConfig         `mapstructure:"log"`
+	Core        CoreConfig        `mapstructure:"core"`
+	Repo        RepoConfig        `mapstructure:"repo"`
+	CI          CIConfig          `mapstructure:"ci"`
+	Consistency ConsistencyConfig `mapstructure:"consistency"`
+	Tracing     TracingConfig     `mapstructure:"tracing"`
+	DB          interface{}       // 数据库连接,运行时注入
 }
 
 // ServerConfig 服务配置
@@ -27,11 +33,14 @@ type ServerConfig struct {
 	Host string `mapstructure:"host"`
 	Port int    `mapstructure:"port"`
 	Mode string `mapstructure:"mode"` // debug, release
+
+	V1Sunset       string `mapstructure:"v1_sunset"`       // v1 废弃接口计划下线日期（YYYY-MM-DD），配置后通过 Sunset 头告知客户端
+	IdempotencyTTL int    `mapstructure:"idempotency_ttl"` // Idempotency-Key 幂等记录保留时间（秒），默认 86400
 }
 
 // DatabaseConfig 数据库配置
 type DatabaseConfig struct {
-	Driver          string `mapstructure:"driver"`
+	Driver          string `mapstructure:"driver"` // 数据库驱动: mysql(默认)/postgres
 	Host            string `mapstructure:"host"`
 	Port            int    `mapstructure:"port"`
 	Database        string `mapstructure:"database"`
@@ -41,13 +50,31 @@ type DatabaseConfig struct {
 	MaxOpenConns    int    `mapstructure:"max_open_conns"`
 	ConnMaxLifetime int    `mapstructure:"conn_max_lifetime"` // 秒
 	LogLevel        string `mapstructure:"log_level"`         // SQL日志级别: silent/error/warn/info
+	SSLMode         string `mapstructure:"sslmode"`           // 仅 postgres: disable/require/verify-ca/verify-full，默认 disable
+
+	Replicas []DatabaseReplicaConfig `mapstructure:"replicas"` // 只读副本，配置后列表/搜索/报表查询读副本，其余读写走主库
 }
 
+// DatabaseReplicaConfig 只读副本配置，未配置的字段沿用主库
+type DatabaseReplicaConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+// 数据库驱动
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+)
+
 // AuthConfig 认证配置
 type AuthConfig struct {
 	JWT   JWTConfig   `mapstructure:"jwt"`
 	LDAP  LDAPConfig  `mapstructure:"ldap"`
 	Local LocalConfig `mapstructure:"local"`
+	OIDC  OIDCConfig  `mapstructure:"oidc"`
 }
 
 // JWTConfig JWT配置
@@ -88,9 +115,103 @@ type LocalConfig struct {
 	Enabled bool `mapstructure:"enabled"`
 }
 
+// OIDCConfig OIDC 单点登录配置（授权码模式，如 Keycloak）
+type OIDCConfig struct {
+	Enabled        bool               `mapstructure:"enabled"`
+	Issuer         string             `mapstructure:"issuer"`          // 通过 {issuer}/.well-known/openid-configuration 发现端点
+	ClientID       string             `mapstructure:"client_id"`       // 机密客户端
+	ClientSecret   string             `mapstructure:"client_secret"`   // 换取 token 时以 HTTP Basic 方式提交
+	RedirectURL    string             `mapstructure:"redirect_url"`    // 授权回调地址（前端页面，取到 code/state 后调用 /api/v1/auth/oidc/callback）
+	Scopes         []string           `mapstructure:"scopes"`          // 默认 openid profile email
+	TimeoutSeconds int                `mapstructure:"timeout_seconds"` // 请求 IdP 的超时，默认 10
+	Claims         OIDCClaims         `mapstructure:"claims"`
+	GroupMappings  []OIDCGroupMapping `mapstructure:"group_mappings"`
+	MigrateFrom    string             `mapstructure:"migrate_from"` // 迁移期使用：首次 OIDC 登录时从该来源（如 ldap）的同名用户复制系统角色与团队成员关系
+}
+
+// OIDCClaims ID Token claim 映射，支持以 . 访问嵌套字段（如 realm_access.roles）
+type OIDCClaims struct {
+	Username    string `mapstructure:"username"`     // 默认 preferred_username
+	Email       string `mapstructure:"email"`        // 默认 email
+	DisplayName string `mapstructure:"display_name"` // 默认 name
+	Phone       string `mapstructure:"phone"`        // 默认 phone_number
+	Groups      string `mapstructure:"groups"`       // 默认 groups
+}
+
+// OIDCGroupMapping IdP 组 -> 团队映射：登录时按组同步团队成员关系
+//
+// 出现在映射中的团队由 OIDC 管理：用户不再属于对应组时移出团队；未出现在映射中的团队不受影响
+type OIDCGroupMapping struct {
+	Group string   `mapstructure:"group"` // 组名，与 groups claim 的取值精确匹配（Keycloak 开启 Full group path 时为 /a/b）
+	Team  string   `mapstructure:"team"`  // 团队名称
+	Roles []string `mapstructure:"roles"` // 成员角色，默认 team_member
+}
+
 // CryptoConfig 加密配置
 type CryptoConfig struct {
-	AESKey string `mapstructure:"aes_key"` // 32字节
+	AESKey   string             `mapstructure:"aes_key"`  // 32字节
+	Provider string             `mapstructure:"provider"` // 新数据使用的 KEK: local(默认)/vault/aws_kms/gcp_kms
+	Vault    VaultTransitConfig `mapstructure:"vault"`
+	AWSKMS   AWSKMSConfig       `mapstructure:"aws_kms"`
+	GCPKMS   GCPKMSConfig       `mapstructure:"gcp_kms"`
+}
+
+// VaultTransitConfig Vault transit 引擎配置
+type VaultTransitConfig struct {
+	Addr      string `mapstructure:"addr"`       // 例如 https://vault.example.com:8200
+	Token     string `mapstructure:"token"`      // 为空时读取环境变量 VAULT_TOKEN
+	Namespace string `mapstructure:"namespace"`  // Vault Enterprise namespace（可选）
+	MountPath string `mapstructure:"mount_path"` // 默认 transit
+	KeyName   string `mapstructure:"key_name"`
+}
+
+// AWSKMSConfig AWS KMS 配置（凭证为空时按默认凭据链解析：AWS_* 环境变量 / IRSA / ECS 容器凭据 / EC2 实例角色）
+type AWSKMSConfig struct {
+	Region          string `mapstructure:"region"`
+	KeyID           string `mapstructure:"key_id"`   // key id / alias / arn
+	Endpoint        string `mapstructure:"endpoint"` // 可选，自定义 endpoint
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	SessionToken    string `mapstructure:"session_token"`
+}
+
+// GCPKMSConfig GCP Cloud KMS 配置
+type GCPKMSConfig struct {
+	KeyName     string `mapstructure:"key_name"`     // projects/*/locations/*/keyRings/*/cryptoKeys/*
+	AccessToken string `mapstructure:"access_token"` // 可选，为空时依次读取 GOOGLE_OAUTH_ACCESS_TOKEN / metadata server
+}
+
+// SecretsConfig 外部密钥后端配置（credential_ref 为 vault:<path> / aws_sm:<secret-id> 时从外部读取，不落平台数据库）
+type SecretsConfig struct {
+	CacheTTL string                  `mapstructure:"cache_ttl"` // 读取结果缓存时间，默认 5m；"0" 表示不缓存
+	Vault    VaultSecretsConfig      `mapstructure:"vault"`
+	AWSSM    AWSSecretsManagerConfig `mapstructure:"aws_sm"`
+}
+
+// VaultSecretsConfig Vault 密钥读取配置（KV v1/v2 及动态密钥）
+type VaultSecretsConfig struct {
+	Addr       string `mapstructure:"addr"`        // 例如 https://vault.example.com:8200，为空时读取 VAULT_ADDR
+	Namespace  string `mapstructure:"namespace"`   // Vault Enterprise namespace（可选）
+	AuthMethod string `mapstructure:"auth_method"` // token(默认)/kubernetes/approle
+	AuthMount  string `mapstructure:"auth_mount"`  // 认证挂载路径，默认与 auth_method 相同
+	Token      string `mapstructure:"token"`       // token 方式，为空时读取环境变量 VAULT_TOKEN
+	Role       string `mapstructure:"role"`        // kubernetes 方式的 Vault role
+	JWTPath    string `mapstructure:"jwt_path"`    // kubernetes 方式的 ServiceAccount token 路径，默认 /var/run/secrets/kubernetes.io/serviceaccount/token
+	RoleID     string `mapstructure:"role_id"`     // approle 方式
+	SecretID   string `mapstructure:"secret_id"`   // approle 方式，为空时读取环境变量 VAULT_SECRET_ID
+
+	AllowedPrefixes []string `mapstructure:"allowed_prefixes"` // 允许 credential_ref 引用的路径前缀（按路径段匹配），为空时不允许引用 vault:
+}
+
+// AWSSecretsManagerConfig AWS Secrets Manager 配置（凭证为空时按默认凭据链解析，同 AWSKMSConfig）
+type AWSSecretsManagerConfig struct {
+	Region          string `mapstructure:"region"`
+	Endpoint        string `mapstructure:"endpoint"` // 可选，自定义 endpoint
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	SessionToken    string `mapstructure:"session_token"`
+
+	AllowedPrefixes []string `mapstructure:"allowed_prefixes"` // 允许 credential_ref 引用的 secret name / ARN 前缀，为空时不允许引用 aws_sm:
 }
 
 // LogConfig 日志配置
@@ -104,22 +225,91 @@ type LogConfig struct {
 	MaxAge     int    `mapstructure:"max_age"` // days
 }
 
+// TracingConfig OpenTelemetry 链路追踪配置（OTLP/HTTP 导出）
+type TracingConfig struct {
+	Enabled     bool              `mapstructure:"enabled"`
+	Endpoint    string            `mapstructure:"endpoint"`     // collector 地址 host:port，为空时读取 OTEL_EXPORTER_OTLP_* 环境变量
+	URLPath     string            `mapstructure:"url_path"`     // 默认 /v1/traces
+	Insecure    bool              `mapstructure:"insecure"`     // 使用 http 而非 https
+	Headers     map[string]string `mapstructure:"headers"`      // 附加请求头（如鉴权 token）
+	ServiceName string            `mapstructure:"service_name"` // 为空时使用 server.name
+	SampleRatio float64           `mapstructure:"sample_ratio"` // 采样比例 (0, 1]，默认 1；上游已采样的请求始终跟随
+}
+
 // CoreConfig Core模块配置
 type CoreConfig struct {
-	ScanInterval string                   `mapstructure:"scan_interval"` // 扫描间隔
-	Deploy       DeployConfig             `mapstructure:"deploy"`
-	Notification NotificationConfig       `mapstructure:"notification"`
-	AppTypes     map[string]AppTypeConfig `mapstructure:"app_types"`
+	ScanInterval  string                   `mapstructure:"scan_interval"`  // 扫描间隔
+	SweepInterval string                   `mapstructure:"sweep_interval"` // 进行中批次未收到状态变更触发时的兜底处理间隔，默认 10s
+	DrainTimeout  string                   `mapstructure:"drain_timeout"`  // 停止时等待进行中的处理完成的上限，默认 30s
+	Deploy        DeployConfig             `mapstructure:"deploy"`
+	Notification  NotificationConfig       `mapstructure:"notification"`
+	Webhook       WebhookConfig            `mapstructure:"webhook"`
+	ArtifactCache ArtifactCacheConfig      `mapstructure:"artifact_cache"`
+	Registry      RegistryConfig           `mapstructure:"registry"`
+	AppTypes      map[string]AppTypeConfig `mapstructure:"app_types"`
+	HA            HAConfig                 `mapstructure:"ha"`
+	Jobs          JobsConfig               `mapstructure:"jobs"`
+	Reconcile     ReconcileConfig          `mapstructure:"reconcile"`
+	MetaCache     MetaCacheConfig          `mapstructure:"meta_cache"`
+	DriftCheck    DriftCheckConfig         `mapstructure:"drift_check"`
+}
+
+// DriftCheckConfig 配置漂移检测：定时对比集群中 helm release（及其资源）与最近一次部署写入的 release
+type DriftCheckConfig struct {
+	Cron        string `mapstructure:"cron"`         // 检测频率（秒 分 时 日 月 周），为空不启用
+	LiveObjects bool   `mapstructure:"live_objects"` // 同时对比集群中的资源对象（发现 kubectl edit 等绕过 helm 的修改），每个资源一次 GET
+}
+
+// ReconcileConfig 启动恢复：引擎启动（或成为主节点）时检查重启前中断、已无人处理的部署与发布应用
+type ReconcileConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	StaleAfter string `mapstructure:"stale_after"` // 发布应用停留在已触发状态超过该时长才检查，默认 30m
+}
+
+// MetaCacheConfig 元数据缓存：引擎每次扫描重复读取的应用、项目环境配置、集群记录缓存在进程内，写入时失效
+type MetaCacheConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	TTL     string `mapstructure:"ttl"` // 命中有效期（也是多副本时其他副本写入的最长生效延迟），默认 30s
+}
+
+// JobsConfig 异步任务队列：启用后 Deployment 的执行（Chart/制品拉取、Helm install/upgrade）由 worker 池完成，状态机只负责入队
+type JobsConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	Workers      int    `mapstructure:"workers"`       // 每个副本的 worker 数，默认 4
+	PollInterval string `mapstructure:"poll_interval"` // 空闲 worker 轮询间隔，默认 2s
+	MaxAttempts  int    `mapstructure:"max_attempts"`  // 最大执行次数，超过后进入死信，默认 3
+	Timeout      string `mapstructure:"timeout"`       // 单次执行超时，默认 30m
+}
+
+// HAConfig 多副本部署配置：启用后通过数据库会话级锁（MySQL GET_LOCK / PostgreSQL advisory lock）选举主节点，只有主节点运行引擎扫描与定时任务
+type HAConfig struct {
+	Enabled       bool   `mapstructure:"enabled"`
+	LockName      string `mapstructure:"lock_name"`      // 锁名，默认 devops-cd:leader（同一数据库上的多套部署需区分）
+	RenewInterval string `mapstructure:"renew_interval"` // 续期/竞选间隔，默认 5s
 }
 
 // DeployConfig 部署配置
 type DeployConfig struct {
-	ConcurrentApps   int    `mapstructure:"concurrent_apps"`    // 并发部署数
-	SingleAppTimeout string `mapstructure:"single_app_timeout"` // 单应用超时
-	BatchTimeout     string `mapstructure:"batch_timeout"`      // 批次超时
-	RetryCount       int    `mapstructure:"retry_count"`        // 重试次数
-	RetryBackoff     string `mapstructure:"retry_backoff"`      // 重试策略
-	PollInterval     string `mapstructure:"poll_interval"`      // 轮询间隔
+	ConcurrentApps     int    `mapstructure:"concurrent_apps"`      // 并发部署数
+	ConcurrentClusters int    `mapstructure:"concurrent_clusters"`  // 单应用多集群并发部署数
+	SingleAppTimeout   string `mapstructure:"single_app_timeout"`   // 单应用超时
+	BatchTimeout       string `mapstructure:"batch_timeout"`        // 批次超时
+	BatchTimeoutAction string `mapstructure:"batch_timeout_action"` // 批次超时处理：notify（默认）/cancel
+	RetryCount         int    `mapstructure:"retry_count"`          // 重试次数
+	RetryBackoff       string `mapstructure:"retry_backoff"`        // 重试策略
+	RetryBackoffBase   string `mapstructure:"retry_backoff_base"`   // 重试退避基础间隔
+	PollInterval       string `mapstructure:"poll_interval"`        // 轮询间隔
+	DiffAckProtected   bool   `mapstructure:"diff_ack_protected"`   // prod diff 涉及 PDB/PVC/CRD 时需人工确认后部署
+
+	// 运行时长异常告警：Running 超过应用历史耗时 P95 的 slow_factor 倍时通知并标记 Deployment（早于 single_app_timeout 发现卡住的部署）
+	SlowFactor      float64 `mapstructure:"slow_factor"`       // 0 不检查
+	SlowMinSamples  int     `mapstructure:"slow_min_samples"`  // 历史成功部署少于该次数不检查，默认 5
+	SlowMinDuration string  `mapstructure:"slow_min_duration"` // 告警阈值下限（避免耗时很短的应用误报），默认 2m
+
+	// 同时进行（running + 正在触发）的 Deployment 上限，0 表示不限制；额度不足时按批次公平排队
+	MaxDeployments           int `mapstructure:"max_deployments"`             // 全局
+	MaxDeploymentsPerProject int `mapstructure:"max_deployments_per_project"` // 单项目
+	MaxDeploymentsPerCluster int `mapstructure:"max_deployments_per_cluster"` // 单集群
 }
 
 // AppTypeConfig 应用类型配置
@@ -144,14 +334,125 @@ type AppTypeMetadata struct {
 // NotificationConfig 通知配置
 type NotificationConfig struct {
 	Enabled     bool   `mapstructure:"enabled"`      // 是否启用
-	Provider    string `mapstructure:"provider"`     // 通知渠道
+	Provider    string `mapstructure:"provider"`     // 通知渠道: lark/lark_thread/slack/dingtalk/email/log，多个渠道用逗号分隔（如 "lark,email"）
 	LarkWebhook string `mapstructure:"lark_webhook"` // Lark Webhook
+
+	// provider=lark_thread: 使用 Lark 应用机器人，每个批次一个话题
+	LarkAPIBase   string `mapstructure:"lark_api_base"` // 默认 https://open.feishu.cn
+	LarkAppID     string `mapstructure:"lark_app_id"`
+	LarkAppSecret string `mapstructure:"lark_app_secret"`
+	LarkChatID    string `mapstructure:"lark_chat_id"` // 通知群 chat_id
+
+	// provider=slack: Incoming Webhook
+	SlackWebhook string `mapstructure:"slack_webhook"`
+
+	// provider=dingtalk: 自定义机器人
+	DingTalkWebhook string `mapstructure:"dingtalk_webhook"`
+	DingTalkSecret  string `mapstructure:"dingtalk_secret"` // 加签密钥，未开启加签时留空
+
+	// provider=email: SMTP
+	SMTPHost     string   `mapstructure:"smtp_host"`
+	SMTPPort     int      `mapstructure:"smtp_port"` // 默认 587（STARTTLS），smtp_tls=true 时默认 465
+	SMTPUsername string   `mapstructure:"smtp_username"`
+	SMTPPassword string   `mapstructure:"smtp_password"`
+	SMTPFrom     string   `mapstructure:"smtp_from"` // 默认 smtp_username
+	SMTPTLS      bool     `mapstructure:"smtp_tls"`  // 隐式 TLS（SMTPS）
+	EmailTo      []string `mapstructure:"email_to"`  // 收件人
+
+	// 批次完成通知附带变更日志（各应用本次发布的提交，按团队分组，从仓库源 Git API 拉取）
+	AttachChangelog bool `mapstructure:"attach_changelog"`
+
+	// 开启 notify_digest 的项目：应用部署结果汇总的最长等待时间（阶段未结束也发送已收集的结果），默认 30m
+	DigestMaxWait string `mapstructure:"digest_max_wait"`
+}
+
+// WebhookConfig 出站 Webhook 投递配置
+type WebhookConfig struct {
+	MaxAttempts int    `mapstructure:"max_attempts"` // 最大投递次数（含首次），默认 6
+	Timeout     string `mapstructure:"timeout"`      // 单次请求超时，默认 10s
+}
+
+// ArtifactCacheConfig 远端制品（values 层、chart 仓库）共享缓存配置
+type ArtifactCacheConfig struct {
+	Dir           string `mapstructure:"dir"`             // 缓存目录，为空时使用用户缓存目录下 devops-cd/artifact-cache
+	TTL           string `mapstructure:"ttl"`             // 命中有效期，过期后条件请求校验，默认 30m
+	Timeout       string `mapstructure:"timeout"`         // 单次回源超时（HTTP 请求 / git 操作），默认 60s
+	MaxObjectSize int    `mapstructure:"max_object_size"` // 单个制品大小上限（MB），超过时拒绝缓存并报错，默认 64
+	MaxTotalSize  int    `mapstructure:"max_total_size"`  // 缓存总大小上限（MB），超过时淘汰最久未回源的条目，0 不限制
+	MaxConcurrent int    `mapstructure:"max_concurrent"`  // 同时回源的最大数量，0 不限制
+	// 回源主机访问控制（SSRF 防护）：主机名 / *.域名 / IP / CIDR；deny 优先，allow 非空时只允许命中的主机
+	AllowHosts   []string `mapstructure:"allow_hosts"`
+	DenyHosts    []string `mapstructure:"deny_hosts"`
+	BlockPrivate bool     `mapstructure:"block_private"` // 拒绝解析到内网/回环/链路本地地址的主机（allow_hosts 中的主机除外）
+}
+
+// RegistryConfig 镜像仓库配置（部署前校验镜像存在）
+type RegistryConfig struct {
+	VerifyImage bool                 `mapstructure:"verify_image"` // 创建 Deployment 前校验镜像 tag 存在，不存在时发布应用直接失败
+	Timeout     string               `mapstructure:"timeout"`      // 单次请求超时，默认 10s
+	Hosts       []RegistryHostConfig `mapstructure:"hosts"`        // 需要认证或使用 http 的 registry，未配置的 registry 匿名访问
+}
+
+// RegistryHostConfig 单个镜像仓库（Harbor / Docker Registry v2）的访问配置
+type RegistryHostConfig struct {
+	Host          string `mapstructure:"host"`           // registry 地址（含端口），如 harbor.example.com
+	CredentialRef string `mapstructure:"credential_ref"` // 凭据（basic_auth 或 token 类型），id 或 id:123
+	Insecure      bool   `mapstructure:"insecure"`       // 使用 http 访问
 }
 
 // RepoConfig 代码库同步配置
 type RepoConfig struct {
-	Cron    string             `mapstructure:"cron"` // Cron表达式，定义同步执行时间
-	Sources []RepoSourceConfig `mapstructure:"sources"`
+	Cron            string             `mapstructure:"cron"`             // Cron表达式，定义同步执行时间
+	BuildProvenance string             `mapstructure:"build_provenance"` // 构建来源校验模式: off(默认)/flag/reject
+	Sources         []RepoSourceConfig `mapstructure:"sources"`
+}
+
+// CIConfig 外部 CI 构建通知适配配置（GitLab CI 等，Drone 使用 /build/notify）
+type CIConfig struct {
+	RulesFile string          `mapstructure:"rules_file"` // 代码库 → 应用/镜像 tag 的映射规则文件（YAML），为空时代码库视为同名单应用
+	GitLab    GitLabCIConfig  `mapstructure:"gitlab"`
+	GitHub    GitHubCIConfig  `mapstructure:"github"`
+	Jenkins   JenkinsCIConfig `mapstructure:"jenkins"`
+	Harbor    HarborCIConfig  `mapstructure:"harbor"`
+	Trivy     TrivyCIConfig   `mapstructure:"trivy"`
+}
+
+// GitLabCIConfig GitLab CI Webhook 配置
+type GitLabCIConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Token   string `mapstructure:"token"` // Webhook Secret token，校验请求头 X-Gitlab-Token（启用时必填）
+}
+
+// GitHubCIConfig GitHub Actions Webhook 配置
+type GitHubCIConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	Secret     string `mapstructure:"secret"`      // Webhook secret，校验请求头 X-Hub-Signature-256（必填）
+	TagPattern string `mapstructure:"tag_pattern"` // head_branch 匹配该正则时视为 tag 触发，默认 ^v?[0-9]+(\.[0-9]+)+
+}
+
+// JenkinsCIConfig Jenkins Notification 插件配置（token 按代码库配置）
+type JenkinsCIConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Phase   string `mapstructure:"phase"` // 处理的通知阶段，默认 FINALIZED
+}
+
+// HarborCIConfig Harbor 镜像推送 Webhook 配置
+type HarborCIConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	AuthHeader   string `mapstructure:"auth_header"`   // Webhook 策略的 Auth Header，校验请求头 Authorization（启用时必填）
+	CreateBuilds bool   `mapstructure:"create_builds"` // 推送的 tag 没有对应构建时，按该镜像地址的历史构建创建新构建
+}
+
+// TrivyCIConfig CI 上报 Trivy 镜像扫描报告配置
+type TrivyCIConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Token   string `mapstructure:"token"` // 上报 token，校验请求头 X-Webhook-Token（启用时必填）
+}
+
+// ConsistencyConfig 数据一致性检查配置
+type ConsistencyConfig struct {
+	Cron       string   `mapstructure:"cron"`        // Cron表达式，为空时不启用定时检查
+	AutoRepair []string `mapstructure:"auto_repair"` // 定时检查后自动修复的检查项（orphan_release_apps/orphan_deployments/release_status），为空只报告
 }
 
 // RepoSourceConfig 代码库源配置
@@ -199,8 +500,34 @@ func Load(configPath string) (*Config, error) {
 	return config, nil
 }
 
+// GetDriver 规范化后的数据库驱动，未配置时为 mysql；不支持的驱动原样返回，由数据库初始化时报错
+func (c *DatabaseConfig) GetDriver() string {
+	switch driver := strings.ToLower(strings.TrimSpace(c.Driver)); driver {
+	case "", DriverMySQL:
+		return DriverMySQL
+	case DriverPostgres, "postgresql", "pgsql":
+		return DriverPostgres
+	default:
+		return driver
+	}
+}
+
 // GetDSN 获取数据库DSN
 func (c *DatabaseConfig) GetDSN() string {
+	if c.GetDriver() == DriverPostgres {
+		sslMode := c.SSLMode
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		dsn := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(c.Username, c.Password),
+			Host:     fmt.Sprintf("%s:%d", c.Host, c.Port),
+			Path:     "/" + c.Database,
+			RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+		}
+		return dsn.String()
+	}
 	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
 		c.Username,
 		c.Password,
@@ -210,6 +537,24 @@ func (c *DatabaseConfig) GetDSN() string {
 	)
 }
 
+// GetReplicaDSN 获取只读副本DSN（驱动、库名、sslmode 与主库一致）
+func (c *DatabaseConfig) GetReplicaDSN(replica DatabaseReplicaConfig) string {
+	dsn := *c
+	if replica.Host != "" {
+		dsn.Host = replica.Host
+	}
+	if replica.Port != 0 {
+		dsn.Port = replica.Port
+	}
+	if replica.Username != "" {
+		dsn.Username = replica.Username
+	}
+	if replica.Password != "" {
+		dsn.Password = replica.Password
+	}
+	return dsn.GetDSN()
+}
+
 // GetAppTypeConfigs 返回应用类型配置快照
 func GetAppTypeConfigs() map[string]AppTypeConfig {
 	if GlobalConfig == nil {
@@ -0,0 +1,250 @@
+package crypto
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AWS 默认凭据链（与 AWS SDK 的查找顺序一致，覆盖常见部署方式）:
+//
+//  1. 配置中的 access_key_id/secret_access_key
+//  2. 环境变量 AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY（AWS_SESSION_TOKEN）
+//  3. IRSA / Web Identity：AWS_WEB_IDENTITY_TOKEN_FILE + AWS_ROLE_ARN，调用 STS AssumeRoleWithWebIdentity
+//  4. ECS/EKS Pod Identity 容器凭据：AWS_CONTAINER_CREDENTIALS_RELATIVE_URI / AWS_CONTAINER_CREDENTIALS_FULL_URI
+//  5. EC2 实例角色（IMDSv2），AWS_EC2_METADATA_DISABLED=true 时跳过
+//
+// 临时凭据缓存到过期前 5 分钟；不支持 ~/.aws/credentials、SSO 与 credential_process
+
+var (
+	awsSTSEndpoint  = "" // 为空时使用 https://sts.<region>.amazonaws.com/
+	awsECSEndpoint  = "http://169.254.170.2"
+	awsIMDSEndpoint = "http://169.254.169.254"
+)
+
+const awsCredentialsRefreshWindow = 5 * time.Minute
+
+// 实例元数据仅在 EC2 上可达，缩短超时避免非 EC2 环境长时间等待
+var awsMetadataClient = &http.Client{Timeout: 2 * time.Second}
+
+// AWSCredentials AWS 访问凭据；Expires 为零值表示长期凭据
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expires         time.Time
+}
+
+// AWSCredentialChain 按默认凭据链解析 AWS 凭据，并缓存临时凭据
+type AWSCredentialChain struct {
+	static AWSCredentials
+
+	mu     sync.Mutex
+	cached *AWSCredentials
+}
+
+// NewAWSCredentialChain 创建凭据链；accessKey/secretKey 为配置中的静态凭据（可为空）
+func NewAWSCredentialChain(accessKey, secretKey, sessionToken string) *AWSCredentialChain {
+	return &AWSCredentialChain{static: AWSCredentials{AccessKeyID: accessKey, SecretAccessKey: secretKey, SessionToken: sessionToken}}
+}
+
+// Retrieve 返回可用于签名的凭据；region 用于 STS 区域 endpoint
+func (c *AWSCredentialChain) Retrieve(ctx context.Context, region string) (AWSCredentials, error) {
+	if c.static.AccessKeyID != "" && c.static.SecretAccessKey != "" {
+		return c.static, nil
+	}
+	if ak, sk := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); ak != "" && sk != "" {
+		return AWSCredentials{AccessKeyID: ak, SecretAccessKey: sk, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cached != nil && (c.cached.Expires.IsZero() || time.Now().Add(awsCredentialsRefreshWindow).Before(c.cached.Expires)) {
+		return *c.cached, nil
+	}
+
+	var (
+		creds *AWSCredentials
+		err   error
+	)
+	switch {
+	case os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE") != "" && os.Getenv("AWS_ROLE_ARN") != "":
+		creds, err = webIdentityCredentials(ctx, region)
+	case os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI") != "" || os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI") != "":
+		creds, err = containerCredentials(ctx)
+	case strings.EqualFold(os.Getenv("AWS_EC2_METADATA_DISABLED"), "true"):
+		return AWSCredentials{}, fmt.Errorf("未找到 AWS 凭据（配置、环境变量、Web Identity、容器凭据均未设置，实例元数据已禁用）")
+	default:
+		creds, err = instanceProfileCredentials(ctx)
+	}
+	if err != nil {
+		return AWSCredentials{}, err
+	}
+	c.cached = creds
+	return *creds, nil
+}
+
+// webIdentityCredentials IRSA：使用 ServiceAccount token 换取角色临时凭据（STS 请求无需签名）
+func webIdentityCredentials(ctx context.Context, region string) (*AWSCredentials, error) {
+	token, err := os.ReadFile(os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"))
+	if err != nil {
+		return nil, fmt.Errorf("读取 web identity token 失败: %w", err)
+	}
+	endpoint := awsSTSEndpoint
+	if endpoint == "" {
+		endpoint = "https://sts.amazonaws.com/"
+		if region != "" {
+			endpoint = fmt.Sprintf("https://sts.%s.amazonaws.com/", region)
+		}
+	}
+	sessionName := firstNonEmpty(os.Getenv("AWS_ROLE_SESSION_NAME"), fmt.Sprintf("devops-cd-%d", time.Now().Unix()))
+	form := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {os.Getenv("AWS_ROLE_ARN")},
+		"RoleSessionName":  {sessionName},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := kmsHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sts AssumeRoleWithWebIdentity 失败: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("sts AssumeRoleWithWebIdentity HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var out struct {
+		Result struct {
+			Credentials struct {
+				AccessKeyID     string    `xml:"AccessKeyId"`
+				SecretAccessKey string    `xml:"SecretAccessKey"`
+				SessionToken    string    `xml:"SessionToken"`
+				Expiration      time.Time `xml:"Expiration"`
+			} `xml:"Credentials"`
+		} `xml:"AssumeRoleWithWebIdentityResult"`
+	}
+	if err := xml.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("解析 sts 响应失败: %w", err)
+	}
+	c := out.Result.Credentials
+	if c.AccessKeyID == "" {
+		return nil, fmt.Errorf("sts 响应缺少凭据")
+	}
+	return &AWSCredentials{AccessKeyID: c.AccessKeyID, SecretAccessKey: c.SecretAccessKey, SessionToken: c.SessionToken, Expires: c.Expiration}, nil
+}
+
+// metadataCredentials ECS 容器凭据与 EC2 实例角色的响应格式
+type metadataCredentials struct {
+	AccessKeyID     string    `json:"AccessKeyId"`
+	SecretAccessKey string    `json:"SecretAccessKey"`
+	Token           string    `json:"Token"`
+	Expiration      time.Time `json:"Expiration"`
+}
+
+func (m *metadataCredentials) credentials() (*AWSCredentials, error) {
+	if m.AccessKeyID == "" {
+		return nil, fmt.Errorf("元数据响应缺少凭据")
+	}
+	return &AWSCredentials{AccessKeyID: m.AccessKeyID, SecretAccessKey: m.SecretAccessKey, SessionToken: m.Token, Expires: m.Expiration}, nil
+}
+
+// containerCredentials ECS 任务角色 / EKS Pod Identity
+func containerCredentials(ctx context.Context) (*AWSCredentials, error) {
+	endpoint := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if rel := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); rel != "" {
+		endpoint = awsECSEndpoint + rel
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	authToken := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN")
+	if file := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); file != "" {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("读取容器凭据 token 失败: %w", err)
+		}
+		authToken = strings.TrimSpace(string(b))
+	}
+	if authToken != "" {
+		req.Header.Set("Authorization", authToken)
+	}
+	var out metadataCredentials
+	if err := doJSONWith(awsMetadataClient, req, &out); err != nil {
+		return nil, fmt.Errorf("获取容器凭据失败: %w", err)
+	}
+	return out.credentials()
+}
+
+// instanceProfileCredentials EC2 实例角色（IMDSv2：先 PUT 获取会话 token）
+func instanceProfileCredentials(ctx context.Context) (*AWSCredentials, error) {
+	get := func(path, token string) (string, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, awsIMDSEndpoint+path, nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("X-aws-ec2-metadata-token", token)
+		return metadataText(req)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, awsIMDSEndpoint+"/latest/api/token", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "21600")
+	token, err := metadataText(req)
+	if err != nil {
+		return nil, fmt.Errorf("未找到 AWS 凭据，且无法访问 EC2 实例元数据: %w", err)
+	}
+	roles, err := get("/latest/meta-data/iam/security-credentials/", token)
+	if err != nil {
+		return nil, fmt.Errorf("查询实例角色失败: %w", err)
+	}
+	role := strings.TrimSpace(strings.SplitN(roles, "\n", 2)[0])
+	if role == "" {
+		return nil, fmt.Errorf("EC2 实例未绑定 IAM 角色")
+	}
+	raw, err := get("/latest/meta-data/iam/security-credentials/"+role, token)
+	if err != nil {
+		return nil, fmt.Errorf("获取实例角色凭据失败: %w", err)
+	}
+	var out metadataCredentials
+	if err := json.Unmarshal([]byte(raw), &out); err != nil {
+		return nil, fmt.Errorf("解析实例角色凭据失败: %w", err)
+	}
+	return out.credentials()
+}
+
+func metadataText(req *http.Request) (string, error) {
+	resp, err := awsMetadataClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return string(body), nil
+}
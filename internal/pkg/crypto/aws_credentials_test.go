@@ -0,0 +1,175 @@
+package crypto
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// clearAWSEnv 清空凭据链相关环境变量，避免受运行环境影响
+func clearAWSEnv(t *testing.T) {
+	t.Helper()
+	for _, k := range []string{
+		"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN",
+		"AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_ROLE_ARN", "AWS_ROLE_SESSION_NAME",
+		"AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "AWS_CONTAINER_CREDENTIALS_FULL_URI",
+		"AWS_CONTAINER_AUTHORIZATION_TOKEN", "AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE",
+		"AWS_EC2_METADATA_DISABLED",
+	} {
+		t.Setenv(k, "")
+	}
+}
+
+func TestAWSCredentialChainStaticAndEnv(t *testing.T) {
+	clearAWSEnv(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIAENV")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "env-secret")
+	t.Setenv("AWS_SESSION_TOKEN", "env-session")
+
+	creds, err := NewAWSCredentialChain("AKIACFG", "cfg-secret", "").Retrieve(context.Background(), "us-east-1")
+	if err != nil || creds.AccessKeyID != "AKIACFG" || creds.SecretAccessKey != "cfg-secret" {
+		t.Fatalf("配置中的凭据优先, got %+v, %v", creds, err)
+	}
+	creds, err = NewAWSCredentialChain("", "", "").Retrieve(context.Background(), "us-east-1")
+	if err != nil || creds.AccessKeyID != "AKIAENV" || creds.SessionToken != "env-session" {
+		t.Fatalf("环境变量凭据, got %+v, %v", creds, err)
+	}
+}
+
+func TestAWSCredentialChainWebIdentity(t *testing.T) {
+	clearAWSEnv(t)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("sa-jwt\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", tokenFile)
+	t.Setenv("AWS_ROLE_ARN", "arn:aws:iam::123456789012:role/devops-cd")
+	t.Setenv("AWS_ROLE_SESSION_NAME", "it")
+
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		for k, want := range map[string]string{
+			"Action":           "AssumeRoleWithWebIdentity",
+			"RoleArn":          "arn:aws:iam::123456789012:role/devops-cd",
+			"RoleSessionName":  "it",
+			"WebIdentityToken": "sa-jwt",
+		} {
+			if got := r.PostForm.Get(k); got != want {
+				t.Errorf("%s = %q, want %q", k, got, want)
+			}
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Error("AssumeRoleWithWebIdentity 不应签名")
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = w.Write([]byte(`<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>ASIAWEB</AccessKeyId>
+      <SecretAccessKey>web-secret</SecretAccessKey>
+      <SessionToken>web-session</SessionToken>
+      <Expiration>` + expires.Format(time.RFC3339) + `</Expiration>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+</AssumeRoleWithWebIdentityResponse>`))
+	}))
+	defer srv.Close()
+	setVar(t, &awsSTSEndpoint, srv.URL+"/")
+
+	chain := NewAWSCredentialChain("", "", "")
+	for i := 0; i < 2; i++ {
+		creds, err := chain.Retrieve(context.Background(), "us-east-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := AWSCredentials{AccessKeyID: "ASIAWEB", SecretAccessKey: "web-secret", SessionToken: "web-session", Expires: expires}
+		if creds != want {
+			t.Fatalf("got %+v, want %+v", creds, want)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("sts 调用 %d 次, want 1（未过期的临时凭据应缓存）", calls)
+	}
+}
+
+func TestAWSCredentialChainContainer(t *testing.T) {
+	clearAWSEnv(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/credentials/abc" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "pod-token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		writeJSON(w, map[string]string{
+			"AccessKeyId":     "ASIAECS",
+			"SecretAccessKey": "ecs-secret",
+			"Token":           "ecs-session",
+			"Expiration":      time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+		})
+	}))
+	defer srv.Close()
+	setVar(t, &awsECSEndpoint, srv.URL)
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "/v2/credentials/abc")
+	t.Setenv("AWS_CONTAINER_AUTHORIZATION_TOKEN", "pod-token")
+
+	creds, err := NewAWSCredentialChain("", "", "").Retrieve(context.Background(), "")
+	if err != nil || creds.AccessKeyID != "ASIAECS" || creds.SessionToken != "ecs-session" {
+		t.Fatalf("got %+v, %v", creds, err)
+	}
+}
+
+func TestAWSCredentialChainInstanceProfile(t *testing.T) {
+	clearAWSEnv(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/latest/api/token" {
+			if r.Method != http.MethodPut || r.Header.Get("X-aws-ec2-metadata-token-ttl-seconds") == "" {
+				t.Errorf("IMDSv2 token 请求不正确: %s", r.Method)
+			}
+			_, _ = w.Write([]byte("imds-token"))
+			return
+		}
+		if got := r.Header.Get("X-aws-ec2-metadata-token"); got != "imds-token" {
+			t.Errorf("X-aws-ec2-metadata-token = %q", got)
+		}
+		switch r.URL.Path {
+		case "/latest/meta-data/iam/security-credentials/":
+			_, _ = w.Write([]byte("devops-cd-role\n"))
+		case "/latest/meta-data/iam/security-credentials/devops-cd-role":
+			writeJSON(w, map[string]string{
+				"Code":            "Success",
+				"AccessKeyId":     "ASIAEC2",
+				"SecretAccessKey": "ec2-secret",
+				"Token":           "ec2-session",
+				"Expiration":      time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+			})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	setVar(t, &awsIMDSEndpoint, srv.URL)
+
+	creds, err := NewAWSCredentialChain("", "", "").Retrieve(context.Background(), "")
+	if err != nil || creds.AccessKeyID != "ASIAEC2" || creds.SessionToken != "ec2-session" {
+		t.Fatalf("got %+v, %v", creds, err)
+	}
+}
+
+func TestAWSCredentialChainNoCredentials(t *testing.T) {
+	clearAWSEnv(t)
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	if _, err := NewAWSCredentialChain("", "", "").Retrieve(context.Background(), ""); err == nil {
+		t.Fatal("未找到凭据时应返回错误")
+	}
+}
@@ -0,0 +1,245 @@
+package crypto
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"devops-cd/internal/pkg/config"
+)
+
+// 信封加密（envelope encryption）
+//
+// - 每条记录随机生成 32 字节 DEK，使用 AES-GCM 加密明文
+// - DEK 由 KeyProvider（local/vault/aws_kms/gcp_kms）包裹（wrap）后与密文一起存储
+// - key_id（"<provider>:<key>"）随记录落库，解密时按 key_id 选择 provider；key_id 为空表示旧格式（直接使用 aes_key 加密）
+
+const (
+	ProviderLocal  = "local"
+	ProviderVault  = "vault"
+	ProviderAWSKMS = "aws_kms"
+	ProviderGCPKMS = "gcp_kms"
+
+	envelopePrefix = "env1:"
+	dekSize        = 32
+	kmsTimeout     = 10 * time.Second
+)
+
+// KeyProvider KEK 提供方：负责包裹/解包 DEK
+type KeyProvider interface {
+	Name() string
+	// KeyID 当前用于加密的 key（不含 provider 前缀）
+	KeyID() string
+	WrapKey(ctx context.Context, dek []byte) ([]byte, error)
+	UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   map[string]KeyProvider
+	active      string
+)
+
+// Init 按配置初始化 KeyProvider；未显式调用时首次使用会从 config.GlobalConfig 懒加载
+func Init(cfg *config.CryptoConfig) error {
+	m := make(map[string]KeyProvider)
+	if cfg.AESKey != "" {
+		m[ProviderLocal] = &localProvider{key: []byte(cfg.AESKey)}
+	}
+	if cfg.Vault.Addr != "" {
+		m[ProviderVault] = newVaultProvider(&cfg.Vault)
+	}
+	if cfg.AWSKMS.KeyID != "" {
+		m[ProviderAWSKMS] = newAWSKMSProvider(&cfg.AWSKMS)
+	}
+	if cfg.GCPKMS.KeyName != "" {
+		m[ProviderGCPKMS] = newGCPKMSProvider(&cfg.GCPKMS)
+	}
+
+	name := strings.TrimSpace(cfg.Provider)
+	if name == "" {
+		name = ProviderLocal
+	}
+	if _, ok := m[name]; !ok {
+		return fmt.Errorf("crypto.provider=%s 未配置或配置不完整", name)
+	}
+
+	providersMu.Lock()
+	providers, active = m, name
+	providersMu.Unlock()
+	return nil
+}
+
+func getProviders() (map[string]KeyProvider, string, error) {
+	providersMu.RLock()
+	m, name := providers, active
+	providersMu.RUnlock()
+	if m != nil {
+		return m, name, nil
+	}
+	if config.GlobalConfig == nil {
+		return nil, "", fmt.Errorf("crypto 未初始化")
+	}
+	if err := Init(&config.GlobalConfig.Crypto); err != nil {
+		return nil, "", err
+	}
+	return getProviders()
+}
+
+// ActiveKeyID 当前加密使用的 key_id（"<provider>:<key>"）
+func ActiveKeyID() (string, error) {
+	m, name, err := getProviders()
+	if err != nil {
+		return "", err
+	}
+	return formatKeyID(m[name]), nil
+}
+
+// EncryptEnvelope 使用当前 provider 信封加密，返回密文及需随记录保存的 key_id
+func EncryptEnvelope(plaintext string) (ciphertext string, keyID string, err error) {
+	m, name, err := getProviders()
+	if err != nil {
+		return "", "", err
+	}
+	p := m[name]
+
+	dek := make([]byte, dekSize)
+	if _, err := io.ReadFull(rand.Reader, dek); err != nil {
+		return "", "", err
+	}
+	sealed, err := sealGCM(dek, []byte(plaintext))
+	if err != nil {
+		return "", "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), kmsTimeout)
+	defer cancel()
+	wrapped, err := p.WrapKey(ctx, dek)
+	if err != nil {
+		return "", "", fmt.Errorf("%s wrap key 失败: %w", p.Name(), err)
+	}
+
+	// 格式：2 字节 wrapped 长度 | wrapped DEK | nonce+密文
+	if len(wrapped) > math.MaxUint16 {
+		return "", "", fmt.Errorf("%s wrap key 结果过长: %d 字节（上限 %d）", p.Name(), len(wrapped), math.MaxUint16)
+	}
+	buf := make([]byte, 2, 2+len(wrapped)+len(sealed))
+	binary.BigEndian.PutUint16(buf, uint16(len(wrapped)))
+	buf = append(buf, wrapped...)
+	buf = append(buf, sealed...)
+	return envelopePrefix + base64.StdEncoding.EncodeToString(buf), formatKeyID(p), nil
+}
+
+// DecryptWithKeyID 按记录上的 key_id 解密；key_id 为空时按旧格式（aes_key 直接加密）解密
+func DecryptWithKeyID(ciphertext string, keyID string) (string, error) {
+	if strings.TrimSpace(keyID) == "" {
+		return Decrypt(ciphertext)
+	}
+	if !strings.HasPrefix(ciphertext, envelopePrefix) {
+		return "", fmt.Errorf("密文格式错误: 缺少信封前缀")
+	}
+
+	m, _, err := getProviders()
+	if err != nil {
+		return "", err
+	}
+	name, key := parseKeyID(keyID)
+	p, ok := m[name]
+	if !ok {
+		return "", fmt.Errorf("未配置 crypto provider: %s", name)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(ciphertext, envelopePrefix))
+	if err != nil {
+		return "", err
+	}
+	if len(raw) < 2 {
+		return "", fmt.Errorf("密文太短")
+	}
+	n := int(binary.BigEndian.Uint16(raw[:2]))
+	if len(raw) < 2+n {
+		return "", fmt.Errorf("密文太短")
+	}
+	wrapped, sealed := raw[2:2+n], raw[2+n:]
+
+	ctx, cancel := context.WithTimeout(context.Background(), kmsTimeout)
+	defer cancel()
+	dek, err := p.UnwrapKey(ctx, key, wrapped)
+	if err != nil {
+		return "", fmt.Errorf("%s unwrap key 失败: %w", name, err)
+	}
+	plain, err := openGCM(dek, sealed)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+func formatKeyID(p KeyProvider) string {
+	if p.KeyID() == "" {
+		return p.Name()
+	}
+	return p.Name() + ":" + p.KeyID()
+}
+
+func parseKeyID(keyID string) (provider string, key string) {
+	provider, key, _ = strings.Cut(strings.TrimSpace(keyID), ":")
+	return provider, key
+}
+
+func sealGCM(key, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aesGCM.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aesGCM.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func openGCM(key, sealed []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonceSize := aesGCM.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, fmt.Errorf("密文太短")
+	}
+	return aesGCM.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+}
+
+// localProvider 使用配置中的静态 aes_key 作为 KEK
+type localProvider struct {
+	key []byte
+}
+
+func (p *localProvider) Name() string  { return ProviderLocal }
+func (p *localProvider) KeyID() string { return "" }
+
+func (p *localProvider) WrapKey(_ context.Context, dek []byte) ([]byte, error) {
+	return sealGCM(p.key, dek)
+}
+
+func (p *localProvider) UnwrapKey(_ context.Context, _ string, wrapped []byte) ([]byte, error) {
+	return openGCM(p.key, wrapped)
+}
@@ -0,0 +1,152 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"devops-cd/internal/pkg/config"
+)
+
+const testAESKey = "12345678901234567890123456789012"
+
+// useProviders 测试期间替换已初始化的 provider
+func useProviders(t *testing.T, m map[string]KeyProvider, name string) {
+	t.Helper()
+	providersMu.Lock()
+	oldProviders, oldActive := providers, active
+	providers, active = m, name
+	providersMu.Unlock()
+	t.Cleanup(func() {
+		providersMu.Lock()
+		providers, active = oldProviders, oldActive
+		providersMu.Unlock()
+	})
+}
+
+// useGlobalConfig 旧格式 Encrypt/Decrypt 直接读取 config.GlobalConfig
+func useGlobalConfig(t *testing.T, aesKey string) {
+	t.Helper()
+	old := config.GlobalConfig
+	config.GlobalConfig = &config.Config{Crypto: config.CryptoConfig{AESKey: aesKey}}
+	t.Cleanup(func() { config.GlobalConfig = old })
+}
+
+func TestEnvelopeRoundTripLocal(t *testing.T) {
+	useGlobalConfig(t, testAESKey)
+	useProviders(t, nil, "")
+	if err := Init(&config.CryptoConfig{AESKey: testAESKey}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, plain := range []string{"", "password", strings.Repeat("长文本", 10000)} {
+		ciphertext, keyID, err := EncryptEnvelope(plain)
+		if err != nil {
+			t.Fatalf("EncryptEnvelope: %v", err)
+		}
+		if keyID != ProviderLocal || !strings.HasPrefix(ciphertext, envelopePrefix) {
+			t.Fatalf("key_id=%q ciphertext=%q", keyID, ciphertext)
+		}
+		got, err := DecryptWithKeyID(ciphertext, keyID)
+		if err != nil {
+			t.Fatalf("DecryptWithKeyID: %v", err)
+		}
+		if got != plain {
+			t.Errorf("DecryptWithKeyID = %q, want %q", got, plain)
+		}
+	}
+
+	// 每次加密使用不同的 DEK 与 nonce
+	a, _, _ := EncryptEnvelope("same")
+	b, _, _ := EncryptEnvelope("same")
+	if a == b {
+		t.Error("相同明文两次加密结果相同")
+	}
+}
+
+// TestDecryptWithKeyIDLegacy key_id 为空的旧数据（aes_key 直接加密）在切换 provider 后仍可解密
+func TestDecryptWithKeyIDLegacy(t *testing.T) {
+	useGlobalConfig(t, testAESKey)
+	legacy, err := Encrypt("legacy-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	useProviders(t, map[string]KeyProvider{ProviderVault: &stubProvider{name: ProviderVault}}, ProviderVault)
+
+	for _, keyID := range []string{"", "  "} {
+		got, err := DecryptWithKeyID(legacy, keyID)
+		if err != nil {
+			t.Fatalf("DecryptWithKeyID(%q): %v", keyID, err)
+		}
+		if got != "legacy-secret" {
+			t.Errorf("DecryptWithKeyID(%q) = %q", keyID, got)
+		}
+	}
+	// 有 key_id 但密文不是信封格式
+	if _, err := DecryptWithKeyID(legacy, ProviderVault+":k"); err == nil {
+		t.Error("非信封密文带 key_id 应返回错误")
+	}
+}
+
+// TestDecryptWithKeyIDSelectsProvider 按记录上的 key_id 选择 provider，而不是当前激活的 provider
+func TestDecryptWithKeyIDSelectsProvider(t *testing.T) {
+	old := &stubProvider{name: ProviderVault, key: "old"}
+	useProviders(t, map[string]KeyProvider{ProviderVault: old}, ProviderVault)
+	ciphertext, keyID, err := EncryptEnvelope("rotated")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyID != "vault:old" {
+		t.Fatalf("key_id = %q", keyID)
+	}
+
+	useProviders(t, map[string]KeyProvider{
+		ProviderVault: old,
+		ProviderLocal: &localProvider{key: []byte(testAESKey)},
+	}, ProviderLocal)
+	got, err := DecryptWithKeyID(ciphertext, keyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "rotated" || old.unwrapKeyID != "old" {
+		t.Errorf("got %q, unwrap key_id %q", got, old.unwrapKeyID)
+	}
+}
+
+func TestEncryptEnvelopeWrappedKeyTooLong(t *testing.T) {
+	useProviders(t, map[string]KeyProvider{ProviderVault: &stubProvider{name: ProviderVault, pad: 1 << 16}}, ProviderVault)
+	if _, _, err := EncryptEnvelope("x"); err == nil || !strings.Contains(err.Error(), "过长") {
+		t.Fatalf("wrapped DEK 超过 65535 字节应返回错误, got %v", err)
+	}
+
+	// 恰好 65535 字节仍可用
+	useProviders(t, map[string]KeyProvider{ProviderVault: &stubProvider{name: ProviderVault, pad: 1<<16 - 1 - dekSize}}, ProviderVault)
+	ciphertext, keyID, err := EncryptEnvelope("x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := DecryptWithKeyID(ciphertext, keyID); err != nil || got != "x" {
+		t.Fatalf("DecryptWithKeyID = %q, %v", got, err)
+	}
+}
+
+// stubProvider 以明文 DEK（前补 pad 个 0）作为 wrap 结果
+type stubProvider struct {
+	name        string
+	key         string
+	pad         int
+	unwrapKeyID string
+}
+
+func (p *stubProvider) Name() string  { return p.name }
+func (p *stubProvider) KeyID() string { return p.key }
+
+func (p *stubProvider) WrapKey(_ context.Context, dek []byte) ([]byte, error) {
+	return append(make([]byte, p.pad), dek...), nil
+}
+
+func (p *stubProvider) UnwrapKey(_ context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	p.unwrapKeyID = keyID
+	return bytes.Clone(wrapped[len(wrapped)-dekSize:]), nil
+}
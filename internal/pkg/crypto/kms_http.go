@@ -0,0 +1,320 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"devops-cd/internal/pkg/config"
+)
+
+var kmsHTTPClient = &http.Client{Timeout: kmsTimeout}
+
+// doJSON 发送 JSON 请求并解析响应
+func doJSON(req *http.Request, out interface{}) error {
+	return doJSONWith(kmsHTTPClient, req, out)
+}
+
+func doJSONWith(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, out)
+}
+
+// ============ Vault transit
+
+type vaultProvider struct {
+	cfg *config.VaultTransitConfig
+}
+
+func newVaultProvider(cfg *config.VaultTransitConfig) *vaultProvider {
+	return &vaultProvider{cfg: cfg}
+}
+
+func (p *vaultProvider) Name() string  { return ProviderVault }
+func (p *vaultProvider) KeyID() string { return p.cfg.KeyName }
+
+func (p *vaultProvider) WrapKey(ctx context.Context, dek []byte) ([]byte, error) {
+	var out struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	in := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(dek)}
+	if err := p.call(ctx, "encrypt", p.cfg.KeyName, in, &out); err != nil {
+		return nil, err
+	}
+	return []byte(out.Data.Ciphertext), nil
+}
+
+func (p *vaultProvider) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	var out struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	in := map[string]string{"ciphertext": string(wrapped)}
+	if err := p.call(ctx, "decrypt", keyID, in, &out); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(out.Data.Plaintext)
+}
+
+func (p *vaultProvider) call(ctx context.Context, op, key string, in interface{}, out interface{}) error {
+	mount := strings.Trim(p.cfg.MountPath, "/")
+	if mount == "" {
+		mount = "transit"
+	}
+	url := fmt.Sprintf("%s/v1/%s/%s/%s", strings.TrimRight(p.cfg.Addr, "/"), mount, op, key)
+	body, _ := json.Marshal(in)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	token := p.cfg.Token
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if p.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.cfg.Namespace)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doJSON(req, out)
+}
+
+// ============ AWS KMS（JSON API + SigV4 签名，凭据按默认凭据链解析，见 aws_credentials.go）
+
+type awsKMSProvider struct {
+	cfg   *config.AWSKMSConfig
+	creds *AWSCredentialChain
+}
+
+func newAWSKMSProvider(cfg *config.AWSKMSConfig) *awsKMSProvider {
+	return &awsKMSProvider{cfg: cfg, creds: NewAWSCredentialChain(cfg.AccessKeyID, cfg.SecretAccessKey, cfg.SessionToken)}
+}
+
+func (p *awsKMSProvider) Name() string  { return ProviderAWSKMS }
+func (p *awsKMSProvider) KeyID() string { return p.cfg.KeyID }
+
+func (p *awsKMSProvider) WrapKey(ctx context.Context, dek []byte) ([]byte, error) {
+	var out struct {
+		CiphertextBlob string `json:"CiphertextBlob"`
+	}
+	in := map[string]string{"KeyId": p.cfg.KeyID, "Plaintext": base64.StdEncoding.EncodeToString(dek)}
+	if err := p.call(ctx, "TrentService.Encrypt", in, &out); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(out.CiphertextBlob)
+}
+
+func (p *awsKMSProvider) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	var out struct {
+		Plaintext string `json:"Plaintext"`
+	}
+	in := map[string]string{"KeyId": keyID, "CiphertextBlob": base64.StdEncoding.EncodeToString(wrapped)}
+	if err := p.call(ctx, "TrentService.Decrypt", in, &out); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(out.Plaintext)
+}
+
+func (p *awsKMSProvider) call(ctx context.Context, target string, in interface{}, out interface{}) error {
+	region := firstNonEmpty(p.cfg.Region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"))
+	if region == "" {
+		return fmt.Errorf("aws_kms.region 未配置")
+	}
+	endpoint := p.cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://kms.%s.amazonaws.com/", region)
+	}
+	creds, err := p.creds.Retrieve(ctx, region)
+	if err != nil {
+		return err
+	}
+	body, _ := json.Marshal(in)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", target)
+
+	SignAWSV4(req, body, region, "kms", creds.AccessKeyID, creds.SecretAccessKey, creds.SessionToken, time.Now().UTC())
+	return doJSON(req, out)
+}
+
+// SignAWSV4 AWS Signature Version 4 签名（KMS / Secrets Manager 等 JSON API 共用）
+func SignAWSV4(req *http.Request, body []byte, region, service, accessKey, secretKey, sessionToken string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payloadHash := sha256Hex(body)
+	canonicalRequest := strings.Join([]string{req.Method, path, req.URL.RawQuery, canonicalHeaders.String(), signedHeaders, payloadHash}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// ============ GCP Cloud KMS（REST API）
+
+var (
+	gcpKMSEndpoint      = "https://cloudkms.googleapis.com/v1/"
+	gcpMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+type gcpKMSProvider struct {
+	cfg *config.GCPKMSConfig
+
+	mu          sync.Mutex
+	token       string
+	tokenExpire time.Time
+}
+
+func newGCPKMSProvider(cfg *config.GCPKMSConfig) *gcpKMSProvider {
+	return &gcpKMSProvider{cfg: cfg}
+}
+
+func (p *gcpKMSProvider) Name() string  { return ProviderGCPKMS }
+func (p *gcpKMSProvider) KeyID() string { return p.cfg.KeyName }
+
+func (p *gcpKMSProvider) WrapKey(ctx context.Context, dek []byte) ([]byte, error) {
+	var out struct {
+		Ciphertext string `json:"ciphertext"`
+	}
+	in := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(dek)}
+	if err := p.call(ctx, p.cfg.KeyName+":encrypt", in, &out); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(out.Ciphertext)
+}
+
+func (p *gcpKMSProvider) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	var out struct {
+		Plaintext string `json:"plaintext"`
+	}
+	in := map[string]string{"ciphertext": base64.StdEncoding.EncodeToString(wrapped)}
+	if err := p.call(ctx, keyID+":decrypt", in, &out); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(out.Plaintext)
+}
+
+func (p *gcpKMSProvider) call(ctx context.Context, resource string, in interface{}, out interface{}) error {
+	token, err := p.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+	body, _ := json.Marshal(in)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, gcpKMSEndpoint+resource, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	return doJSON(req, out)
+}
+
+// accessToken 优先使用配置/环境变量中的 token，否则从 GCE/GKE metadata server 获取
+func (p *gcpKMSProvider) accessToken(ctx context.Context) (string, error) {
+	if t := firstNonEmpty(p.cfg.AccessToken, os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")); t != "" {
+		return t, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.token != "" && time.Now().Before(p.tokenExpire) {
+		return p.token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcpMetadataTokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	var out struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := doJSON(req, &out); err != nil {
+		return "", fmt.Errorf("获取 GCP access token 失败: %w", err)
+	}
+	p.token = out.AccessToken
+	// 提前 1 分钟过期，避免临界点失效
+	p.tokenExpire = time.Now().Add(time.Duration(out.ExpiresIn)*time.Second - time.Minute)
+	return p.token, nil
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,301 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"devops-cd/internal/pkg/config"
+)
+
+// TestSignAWSV4 AWS 官方 SigV4 测试向量（aws-sig-v4-test-suite 与 IAM 文档示例）
+func TestSignAWSV4(t *testing.T) {
+	const (
+		accessKey = "AKIDEXAMPLE"
+		secretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+		stsToken  = "AQoDYXdzEPT//////////wEXAMPLEtc764bNrC9SAPBSM22wDOk4x4HIZ8j4FZTwdQWLWsKWHGBuFqwAeMicRXmxfpSPfIeoIYRqTflfKD8YUuwthAx7mSEI/qkPpKPi/kMcGdQrmGdeehM4IC1NtBmUpp2wUE8phUZampKsburEDy0KPkyQDYwT7WZ0wq5VSXDvp75YU9HFvlRd8Tx6q6fE8YQcHNVXAkiY9q6d+xo0rKwT38xVqr7ZD0u0iPPkUL64lIZbqBAz+scqKmlzm8FDrypNC9Yjc8fPOLn9FX9KSYvKTr4rvx3iSIlTJabIQwj2ICCR/oLxBA=="
+	)
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	cases := []struct {
+		name        string
+		method      string
+		url         string
+		contentType string
+		body        string
+		service     string
+		token       string
+		want        string
+	}{
+		{
+			name:    "get-vanilla",
+			method:  http.MethodGet,
+			url:     "https://example.amazonaws.com/",
+			service: "service",
+			want:    "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:    "post-vanilla",
+			method:  http.MethodPost,
+			url:     "https://example.amazonaws.com/",
+			service: "service",
+			want:    "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name:        "post-x-www-form-urlencoded",
+			method:      http.MethodPost,
+			url:         "https://example.amazonaws.com/",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "Param1=value1",
+			service:     "service",
+			want:        "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+		{
+			name:    "post-sts-header-before",
+			method:  http.MethodPost,
+			url:     "https://example.amazonaws.com/",
+			service: "service",
+			token:   stsToken,
+			want:    "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date;x-amz-security-token, Signature=85d96828115b5dc0cfc3bd16ad9e210dd772bbebba041836c64533a82be05ead",
+		},
+		{
+			name:        "iam-list-users",
+			method:      http.MethodGet,
+			url:         "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08",
+			contentType: "application/x-www-form-urlencoded; charset=utf-8",
+			service:     "iam",
+			want:        "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req, err := http.NewRequest(c.method, c.url, strings.NewReader(c.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.contentType != "" {
+				req.Header.Set("Content-Type", c.contentType)
+			}
+			SignAWSV4(req, []byte(c.body), "us-east-1", c.service, accessKey, secretKey, c.token, now)
+			if got := req.Header.Get("Authorization"); got != c.want {
+				t.Errorf("Authorization:\n got %s\nwant %s", got, c.want)
+			}
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("X-Amz-Date = %s", got)
+			}
+			if got := req.Header.Get("X-Amz-Security-Token"); got != c.token {
+				t.Errorf("X-Amz-Security-Token = %q, want %q", got, c.token)
+			}
+		})
+	}
+}
+
+// fakeWrap 模拟 KMS 的 wrap：在 DEK 前加上标记，unwrap 时校验并去掉
+func fakeWrap(dek []byte) []byte { return append([]byte("wrapped:"), dek...) }
+
+func fakeUnwrap(t *testing.T, wrapped []byte) []byte {
+	if !bytes.HasPrefix(wrapped, []byte("wrapped:")) {
+		t.Errorf("unwrap 收到的不是 wrap 的结果: %q", wrapped)
+	}
+	return bytes.TrimPrefix(wrapped, []byte("wrapped:"))
+}
+
+func decodeBody(t *testing.T, r *http.Request) map[string]string {
+	t.Helper()
+	var in map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		t.Errorf("解析请求体失败: %v", err)
+	}
+	return in
+}
+
+func mustB64(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		t.Errorf("base64 解码失败: %v", err)
+	}
+	return b
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// assertRoundTrip wrap 后 unwrap 得到原 DEK，并通过信封加解密
+func assertRoundTrip(t *testing.T, p KeyProvider) {
+	t.Helper()
+	dek := bytes.Repeat([]byte{0xab}, dekSize)
+	wrapped, err := p.WrapKey(context.Background(), dek)
+	if err != nil {
+		t.Fatalf("WrapKey: %v", err)
+	}
+	got, err := p.UnwrapKey(context.Background(), p.KeyID(), wrapped)
+	if err != nil {
+		t.Fatalf("UnwrapKey: %v", err)
+	}
+	if !bytes.Equal(got, dek) {
+		t.Fatalf("UnwrapKey = %x, want %x", got, dek)
+	}
+
+	useProviders(t, map[string]KeyProvider{p.Name(): p}, p.Name())
+	ciphertext, keyID, err := EncryptEnvelope("s3cr3t")
+	if err != nil {
+		t.Fatalf("EncryptEnvelope: %v", err)
+	}
+	if keyID != formatKeyID(p) {
+		t.Errorf("key_id = %s, want %s", keyID, formatKeyID(p))
+	}
+	plain, err := DecryptWithKeyID(ciphertext, keyID)
+	if err != nil {
+		t.Fatalf("DecryptWithKeyID: %v", err)
+	}
+	if plain != "s3cr3t" {
+		t.Errorf("DecryptWithKeyID = %q", plain)
+	}
+}
+
+func TestVaultProviderRoundTrip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Vault-Token"); got != "vault-token" {
+			t.Errorf("X-Vault-Token = %q", got)
+		}
+		if got := r.Header.Get("X-Vault-Namespace"); got != "ns1" {
+			t.Errorf("X-Vault-Namespace = %q", got)
+		}
+		in := decodeBody(t, r)
+		switch r.URL.Path {
+		case "/v1/kv-transit/encrypt/devops-cd":
+			dek := mustB64(t, in["plaintext"])
+			writeJSON(w, map[string]interface{}{"data": map[string]string{
+				"ciphertext": "vault:v1:" + base64.StdEncoding.EncodeToString(fakeWrap(dek)),
+			}})
+		case "/v1/kv-transit/decrypt/devops-cd":
+			wrapped := mustB64(t, strings.TrimPrefix(in["ciphertext"], "vault:v1:"))
+			writeJSON(w, map[string]interface{}{"data": map[string]string{
+				"plaintext": base64.StdEncoding.EncodeToString(fakeUnwrap(t, wrapped)),
+			}})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	assertRoundTrip(t, newVaultProvider(&config.VaultTransitConfig{
+		Addr:      srv.URL + "/",
+		Token:     "vault-token",
+		Namespace: "ns1",
+		MountPath: "/kv-transit/",
+		KeyName:   "devops-cd",
+	}))
+}
+
+func TestAWSKMSProviderRoundTrip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIATEST/") || !strings.Contains(auth, "/ap-southeast-1/kms/aws4_request") {
+			t.Errorf("Authorization = %q", auth)
+		}
+		if got := r.Header.Get("X-Amz-Security-Token"); got != "session" {
+			t.Errorf("X-Amz-Security-Token = %q", got)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/x-amz-json-1.1" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		in := decodeBody(t, r)
+		if in["KeyId"] != "alias/devops-cd" {
+			t.Errorf("KeyId = %q", in["KeyId"])
+		}
+		switch r.Header.Get("X-Amz-Target") {
+		case "TrentService.Encrypt":
+			dek := mustB64(t, in["Plaintext"])
+			writeJSON(w, map[string]string{"CiphertextBlob": base64.StdEncoding.EncodeToString(fakeWrap(dek)), "KeyId": in["KeyId"]})
+		case "TrentService.Decrypt":
+			wrapped := mustB64(t, in["CiphertextBlob"])
+			writeJSON(w, map[string]string{"Plaintext": base64.StdEncoding.EncodeToString(fakeUnwrap(t, wrapped))})
+		default:
+			t.Errorf("unexpected X-Amz-Target %q", r.Header.Get("X-Amz-Target"))
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	assertRoundTrip(t, newAWSKMSProvider(&config.AWSKMSConfig{
+		Region:          "ap-southeast-1",
+		KeyID:           "alias/devops-cd",
+		Endpoint:        srv.URL + "/",
+		AccessKeyID:     "AKIATEST",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+	}))
+}
+
+func TestGCPKMSProviderRoundTrip(t *testing.T) {
+	const keyName = "projects/p/locations/global/keyRings/r/cryptoKeys/devops-cd"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer gcp-token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		in := decodeBody(t, r)
+		switch r.URL.Path {
+		case "/v1/" + keyName + ":encrypt":
+			dek := mustB64(t, in["plaintext"])
+			writeJSON(w, map[string]string{"name": keyName, "ciphertext": base64.StdEncoding.EncodeToString(fakeWrap(dek))})
+		case "/v1/" + keyName + ":decrypt":
+			wrapped := mustB64(t, in["ciphertext"])
+			writeJSON(w, map[string]string{"plaintext": base64.StdEncoding.EncodeToString(fakeUnwrap(t, wrapped))})
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	setVar(t, &gcpKMSEndpoint, srv.URL+"/v1/")
+
+	assertRoundTrip(t, newGCPKMSProvider(&config.GCPKMSConfig{KeyName: keyName, AccessToken: "gcp-token"}))
+}
+
+// TestGCPKMSMetadataToken 未配置 access_token 时从 metadata server 获取并缓存
+func TestGCPKMSMetadataToken(t *testing.T) {
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "")
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if got := r.Header.Get("Metadata-Flavor"); got != "Google" {
+			t.Errorf("Metadata-Flavor = %q", got)
+		}
+		writeJSON(w, map[string]interface{}{"access_token": "meta-token", "expires_in": 3600})
+	}))
+	defer srv.Close()
+	setVar(t, &gcpMetadataTokenURL, srv.URL)
+
+	p := newGCPKMSProvider(&config.GCPKMSConfig{KeyName: "k"})
+	for i := 0; i < 2; i++ {
+		token, err := p.accessToken(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "meta-token" {
+			t.Errorf("token = %q", token)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("metadata server 调用 %d 次, want 1（token 应缓存）", calls)
+	}
+}
+
+// setVar 测试期间替换包级变量（endpoint 等）
+func setVar(t *testing.T, v *string, value string) {
+	t.Helper()
+	old := *v
+	*v = value
+	t.Cleanup(func() { *v = old })
+}
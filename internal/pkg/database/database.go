@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 
@@ -31,14 +32,27 @@ func Init(cfg *config.DatabaseConfig) error {
 		NowFunc: func() time.Time {
 			return time.Now().Local()
 		},
+		// 将唯一键冲突等驱动错误转换为 gorm.ErrDuplicatedKey，业务代码不依赖具体数据库的错误信息
+		TranslateError: true,
+	}
+
+	// 按驱动选择方言
+	dialector, err := openDialector(cfg.GetDriver(), cfg.GetDSN())
+	if err != nil {
+		return err
 	}
 
 	// 连接数据库
-	DB, err = gorm.Open(mysql.Open(cfg.GetDSN()), gormConfig)
+	DB, err = gorm.Open(dialector, gormConfig)
 	if err != nil {
 		return fmt.Errorf("连接数据库失败: %w", err)
 	}
 
+	// 读写分离（可选）
+	if err := registerReplicas(DB, cfg); err != nil {
+		return err
+	}
+
 	// 获取底层sqlDB
 	sqlDB, err := DB.DB()
 	if err != nil {
@@ -58,6 +72,18 @@ func Init(cfg *config.DatabaseConfig) error {
 	return nil
 }
 
+// openDialector 按驱动创建 GORM 方言
+func openDialector(driver, dsn string) (gorm.Dialector, error) {
+	switch driver {
+	case config.DriverMySQL:
+		return mysql.Open(dsn), nil
+	case config.DriverPostgres:
+		return postgres.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("不支持的数据库驱动: %s", driver)
+	}
+}
+
 // Close 关闭数据库连接
 func Close() error {
 	if DB != nil {
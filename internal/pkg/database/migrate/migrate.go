@@ -0,0 +1,74 @@
+// Package migrate 数据库迁移脚本
+//
+// scripts/*.sql 为 MySQL DDL（唯一维护的版本），scripts/postgres/*.sql 由 ToPostgres 按同名文件生成:
+//
+//	go run ./cmd/pgschema
+//
+// 新增迁移只需写 MySQL 脚本并重新生成；翻译器只支持 scripts 中实际使用的语法（CREATE TABLE、
+// ALTER TABLE ADD/MODIFY/DROP COLUMN、ADD INDEX、RENAME、简单的 INSERT/UPDATE），遇到不支持的语法直接报错而不是生成错误的 DDL。
+package migrate
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PostgresDir PostgreSQL 迁移脚本目录（相对 scripts）
+const PostgresDir = "postgres"
+
+// Script 迁移脚本
+type Script struct {
+	Name string // 文件名，如 001_init_user_schema.sql
+	SQL  string
+}
+
+// Load 按文件名顺序读取 dir 下的 *.sql（不递归）
+func Load(dir string) ([]Script, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	scripts := make([]Script, 0, len(files))
+	for _, f := range files {
+		content, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		scripts = append(scripts, Script{Name: filepath.Base(f), SQL: string(content)})
+	}
+	return scripts, nil
+}
+
+// Apply 按顺序执行迁移脚本，每个脚本一次 Exec（MySQL 连接需开启 multiStatements；PostgreSQL 无参数时走简单协议，支持多语句）
+func Apply(db *sql.DB, scripts []Script) error {
+	for _, s := range scripts {
+		if strings.TrimSpace(s.SQL) == "" {
+			continue
+		}
+		if _, err := db.Exec(s.SQL); err != nil {
+			return fmt.Errorf("%s: %w", s.Name, err)
+		}
+	}
+	return nil
+}
+
+// PostgresScripts 将 MySQL 迁移脚本逐个翻译为 PostgreSQL 脚本（文件名不变）
+func PostgresScripts(scripts []Script) ([]Script, error) {
+	out := make([]Script, 0, len(scripts))
+	for _, s := range scripts {
+		pg, err := ToPostgres(s.SQL)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s.Name, err)
+		}
+		out = append(out, Script{
+			Name: s.Name,
+			SQL:  "-- 由 scripts/" + s.Name + " 生成（go run ./cmd/pgschema），请勿手工修改\n\n" + pg,
+		})
+	}
+	return out, nil
+}
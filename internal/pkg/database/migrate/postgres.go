@@ -0,0 +1,832 @@
+package migrate
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// ToPostgres 将 MySQL 迁移脚本翻译为 PostgreSQL 语法
+//
+//   - 标识符统一加双引号；COMMENT 转为 COMMENT ON TABLE/COLUMN
+//   - AUTO_INCREMENT → GENERATED BY DEFAULT AS IDENTITY（表选项 AUTO_INCREMENT=N 转为 START WITH N）
+//   - TINYINT(1)/BOOLEAN → BOOLEAN（默认值 0/1 → FALSE/TRUE），TINYINT → SMALLINT，JSON → JSONB，
+//     DATETIME → TIMESTAMP，*TEXT → TEXT，*BLOB → BYTEA，ENUM → VARCHAR + CHECK，去掉 UNSIGNED
+//   - 表内 INDEX/KEY/UNIQUE KEY 与 ALTER TABLE ADD INDEX 转为 CREATE [UNIQUE] INDEX IF NOT EXISTS，
+//     索引名在 PostgreSQL 中全库唯一，不含表名时加表名前缀；前缀索引长度被忽略
+//   - ON UPDATE CURRENT_TIMESTAMP 不翻译（updated_at 由 GORM 维护）；ENGINE/CHARSET/COLLATE/AFTER 等被丢弃
+//   - INSERT ... ON DUPLICATE KEY UPDATE 只支持无操作形式（a = a），转为 ON CONFLICT DO NOTHING
+//   - ALTER TABLE 另支持 MODIFY COLUMN、DROP COLUMN、RENAME COLUMN/INDEX/TO（见 alterTable）
+//   - DML 中的 SHA2(x, 256) 转为 encode(sha256(convert_to(x, 'UTF8')), 'hex')，FROM_UNIXTIME(x) 转为 to_timestamp(x)
+func ToPostgres(src string) (string, error) {
+	toks, err := tokenize(src)
+	if err != nil {
+		return "", err
+	}
+	var out []string
+	for _, stmt := range splitTop(toks, ";") {
+		if len(stmt) == 0 {
+			continue
+		}
+		var pg []string
+		switch {
+		case isKw(stmt, 0, "CREATE") && isKw(stmt, 1, "TABLE"):
+			pg, err = createTable(stmt)
+		case isKw(stmt, 0, "ALTER") && isKw(stmt, 1, "TABLE"):
+			pg, err = alterTable(stmt)
+		case isKw(stmt, 0, "INSERT"), isKw(stmt, 0, "UPDATE"), isKw(stmt, 0, "DELETE"):
+			pg, err = dml(stmt)
+		default:
+			err = fmt.Errorf("不支持的语句: %s", clip(render(stmt)))
+		}
+		if err != nil {
+			return "", err
+		}
+		out = append(out, pg...)
+	}
+	if len(out) == 0 {
+		return "", nil
+	}
+	return strings.Join(out, ";\n\n") + ";\n", nil
+}
+
+// createTable CREATE TABLE：列与外键保留在建表语句中，索引与注释拆为独立语句
+func createTable(stmt []token) ([]string, error) {
+	i := 2
+	ifNotExists := ""
+	if isKw(stmt, i, "IF") && isKw(stmt, i+1, "NOT") && isKw(stmt, i+2, "EXISTS") {
+		ifNotExists, i = "IF NOT EXISTS ", i+3
+	}
+	if i+1 >= len(stmt) || !stmt[i].isName() || stmt[i+1].kind != tokGroup {
+		return nil, fmt.Errorf("无法解析的建表语句: %s", clip(render(stmt)))
+	}
+	table := stmt[i].text
+	body := stmt[i+1].sub
+	tableComment, start, err := tableOptions(stmt[i+2:])
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", table, err)
+	}
+
+	var defs, indexes, comments []string
+	if tableComment != "" {
+		comments = append(comments, fmt.Sprintf("COMMENT ON TABLE %s IS %s", quoteIdent(table), quoteString(tableComment)))
+	}
+	for _, item := range splitTop(body, ",") {
+		if len(item) == 0 {
+			continue
+		}
+		switch {
+		case isKw(item, 0, "PRIMARY") && isKw(item, 1, "KEY"):
+			cols, err := indexColumns(item, 2)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", table, err)
+			}
+			defs = append(defs, "PRIMARY KEY ("+strings.Join(cols, ", ")+")")
+		case isKw(item, 0, "UNIQUE", "KEY", "INDEX"):
+			idx, err := index(table, item)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", table, err)
+			}
+			indexes = append(indexes, idx)
+		case isKw(item, 0, "CONSTRAINT", "FOREIGN"):
+			fk, err := foreignKey(item)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", table, err)
+			}
+			defs = append(defs, fk)
+		default:
+			col, err := column(item, start)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", table, err)
+			}
+			defs = append(defs, col.def)
+			if col.comment != "" {
+				comments = append(comments, columnComment(table, col))
+			}
+		}
+	}
+
+	out := []string{"CREATE TABLE " + ifNotExists + quoteIdent(table) + " (\n  " + strings.Join(defs, ",\n  ") + "\n)"}
+	out = append(out, indexes...)
+	return append(out, comments...), nil
+}
+
+// tableOptions 解析表选项，只保留表注释与自增起始值
+func tableOptions(opts []token) (comment, start string, err error) {
+	for i := 0; i < len(opts); i++ {
+		switch {
+		case isKw(opts, i, "DEFAULT"):
+			continue
+		case isKw(opts, i, "CHARACTER") && isKw(opts, i+1, "SET"):
+			i++
+		}
+		name := opts[i]
+		if name.kind != tokWord {
+			return "", "", fmt.Errorf("无法解析的表选项: %s", clip(render(opts)))
+		}
+		if i+1 < len(opts) && opts[i+1].is("=") {
+			i++
+		}
+		if i+1 >= len(opts) {
+			return "", "", fmt.Errorf("表选项 %s 缺少值", name.text)
+		}
+		i++
+		switch strings.ToUpper(name.text) {
+		case "COMMENT":
+			comment = opts[i].text
+		case "AUTO_INCREMENT":
+			start = opts[i].text
+		}
+	}
+	return comment, start, nil
+}
+
+// pgColumn 翻译后的列定义
+type pgColumn struct {
+	name    string
+	def     string
+	comment string
+}
+
+// column 列定义；identityStart 为表选项 AUTO_INCREMENT 的起始值
+func column(item []token, identityStart string) (*pgColumn, error) {
+	if len(item) < 2 || !item[0].isName() || item[1].kind != tokWord {
+		return nil, fmt.Errorf("无法解析的列定义: %s", clip(render(item)))
+	}
+	col := &pgColumn{name: item[0].text}
+	typ := strings.ToUpper(item[1].text)
+	i := 2
+	var args []token
+	if i < len(item) && item[i].kind == tokGroup {
+		args = item[i].sub
+		i++
+	}
+	unsigned := false
+	for isKw(item, i, "UNSIGNED", "SIGNED", "ZEROFILL", "PRECISION") {
+		unsigned = unsigned || isKw(item, i, "UNSIGNED")
+		i++
+	}
+	pgType, isBool, check, err := columnType(col.name, typ, args, unsigned)
+	if err != nil {
+		return nil, fmt.Errorf("列 %s: %w", col.name, err)
+	}
+
+	parts := []string{quoteIdent(col.name), pgType}
+	for ; i < len(item); i++ {
+		switch {
+		case isKw(item, i, "AUTO_INCREMENT"):
+			identity := "GENERATED BY DEFAULT AS IDENTITY"
+			if identityStart != "" {
+				identity += " (START WITH " + identityStart + ")"
+			}
+			parts = append(parts, identity)
+		case isKw(item, i, "COMMENT") && i+1 < len(item):
+			i++
+			col.comment = item[i].text
+		case isKw(item, i, "ON") && isKw(item, i+1, "UPDATE") && i+2 < len(item):
+			i += 2
+			if i+1 < len(item) && item[i+1].kind == tokGroup {
+				i++
+			}
+		case isKw(item, i, "CHARACTER") && isKw(item, i+1, "SET"):
+			i += 2
+		case isKw(item, i, "CHARSET", "COLLATE", "AFTER"):
+			i++
+		case isKw(item, i, "FIRST"):
+		case isKw(item, i, "DEFAULT") && i+1 < len(item):
+			v, n, err := defaultValue(item[i+1:], isBool)
+			if err != nil {
+				return nil, fmt.Errorf("列 %s: %w", col.name, err)
+			}
+			parts = append(parts, "DEFAULT "+v)
+			i += n
+		case isKw(item, i, "PRIMARY") && isKw(item, i+1, "KEY"):
+			parts = append(parts, "PRIMARY KEY")
+			i++
+		case isKw(item, i, "UNIQUE"):
+			parts = append(parts, "UNIQUE")
+			if isKw(item, i+1, "KEY") {
+				i++
+			}
+		case isKw(item, i, "NOT") && isKw(item, i+1, "NULL"):
+			parts = append(parts, "NOT NULL")
+			i++
+		case isKw(item, i, "NULL"):
+			parts = append(parts, "NULL")
+		default:
+			return nil, fmt.Errorf("列 %s: 不支持的列属性 %s", col.name, item[i].text)
+		}
+	}
+	if check != "" {
+		parts = append(parts, check)
+	}
+	col.def = strings.Join(parts, " ")
+	return col, nil
+}
+
+// columnType MySQL 列类型 → PostgreSQL 列类型；isBool 表示 BOOLEAN 列（默认值需转换），check 为 ENUM 对应的 CHECK 约束
+func columnType(name, typ string, args []token, unsigned bool) (pgType string, isBool bool, check string, err error) {
+	withArgs := func(t string) string {
+		if len(args) == 0 {
+			return t
+		}
+		return t + "(" + render(args) + ")"
+	}
+	switch typ {
+	case "TINYINT":
+		if render(args) == "1" {
+			return "BOOLEAN", true, "", nil
+		}
+		return "SMALLINT", false, "", nil
+	case "BOOL", "BOOLEAN":
+		return "BOOLEAN", true, "", nil
+	case "SMALLINT":
+		if unsigned {
+			return "INTEGER", false, "", nil
+		}
+		return "SMALLINT", false, "", nil
+	case "MEDIUMINT", "INT", "INTEGER":
+		if unsigned {
+			return "BIGINT", false, "", nil
+		}
+		return "INTEGER", false, "", nil
+	case "BIGINT":
+		return "BIGINT", false, "", nil
+	case "DECIMAL", "NUMERIC":
+		return withArgs("NUMERIC"), false, "", nil
+	case "FLOAT":
+		return "REAL", false, "", nil
+	case "DOUBLE", "REAL":
+		return "DOUBLE PRECISION", false, "", nil
+	case "CHAR", "VARCHAR":
+		return withArgs(typ), false, "", nil
+	case "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT":
+		return "TEXT", false, "", nil
+	case "JSON":
+		return "JSONB", false, "", nil
+	case "DATETIME", "TIMESTAMP":
+		return withArgs("TIMESTAMP"), false, "", nil
+	case "DATE", "TIME":
+		return typ, false, "", nil
+	case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB":
+		return "BYTEA", false, "", nil
+	case "ENUM":
+		values := make([]string, 0, len(args))
+		size := 1
+		for _, v := range splitTop(args, ",") {
+			if len(v) != 1 || v[0].kind != tokString {
+				return "", false, "", fmt.Errorf("无法解析的 ENUM 取值: %s", render(args))
+			}
+			values = append(values, quoteString(v[0].text))
+			size = max(size, len([]rune(v[0].text)))
+		}
+		return fmt.Sprintf("VARCHAR(%d)", size), false,
+			fmt.Sprintf("CHECK (%s IN (%s))", quoteIdent(name), strings.Join(values, ", ")), nil
+	}
+	return "", false, "", fmt.Errorf("不支持的列类型 %s", typ)
+}
+
+// defaultValue 翻译 DEFAULT 之后的值，返回消耗的 token 数
+func defaultValue(toks []token, isBool bool) (string, int, error) {
+	v := toks[0]
+	switch {
+	case v.kind == tokGroup:
+		// 表达式默认值，如 (_utf8mb4'[]')
+		inner := v.sub
+		if len(inner) == 2 && inner[0].kind == tokWord && strings.HasPrefix(inner[0].text, "_") && inner[1].kind == tokString {
+			inner = inner[1:]
+		}
+		if len(inner) != 1 || (inner[0].kind != tokString && inner[0].kind != tokWord) {
+			return "", 0, fmt.Errorf("不支持的默认值表达式 %s", render(v.sub))
+		}
+		s, _, err := defaultValue(inner, isBool)
+		return s, 1, err
+	case v.kind == tokWord && strings.HasPrefix(v.text, "_") && len(toks) > 1 && toks[1].kind == tokString:
+		return quoteString(toks[1].text), 2, nil
+	case v.kind == tokString:
+		if isBool && (v.text == "0" || v.text == "1") {
+			return boolLiteral(v.text), 1, nil
+		}
+		return quoteString(v.text), 1, nil
+	case v.kind == tokWord:
+		if isBool && (v.text == "0" || v.text == "1") {
+			return boolLiteral(v.text), 1, nil
+		}
+		if strings.EqualFold(v.text, "CURRENT_TIMESTAMP") && len(toks) > 1 && toks[1].kind == tokGroup {
+			return "CURRENT_TIMESTAMP", 2, nil
+		}
+		return strings.ToUpper(v.text), 1, nil
+	}
+	return "", 0, fmt.Errorf("不支持的默认值 %s", v.text)
+}
+
+func boolLiteral(v string) string {
+	if v == "1" {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// index [UNIQUE] [KEY|INDEX] [name] (cols) [USING BTREE] → CREATE [UNIQUE] INDEX
+func index(table string, item []token) (string, error) {
+	i := 0
+	unique := isKw(item, i, "UNIQUE")
+	if unique {
+		i++
+	}
+	if isKw(item, i, "KEY", "INDEX") {
+		i++
+	}
+	name := ""
+	if i < len(item) && item[i].isName() {
+		name = item[i].text
+		i++
+	}
+	cols, err := indexColumns(item, i)
+	if err != nil {
+		return "", err
+	}
+	// 索引选项（USING BTREE、COMMENT）不翻译
+	for j := i + 1; j < len(item); j += 2 {
+		if !isKw(item, j, "USING", "COMMENT") || j+1 >= len(item) {
+			return "", fmt.Errorf("不支持的索引定义: %s", clip(render(item)))
+		}
+	}
+	if name == "" {
+		name = strings.Join(append(unquoted(cols), "idx"), "_")
+	}
+	kind := "INDEX"
+	if unique {
+		kind = "UNIQUE INDEX"
+	}
+	return fmt.Sprintf("CREATE %s IF NOT EXISTS %s ON %s (%s)", kind, quoteIdent(indexName(table, name)), quoteIdent(table), strings.Join(cols, ", ")), nil
+}
+
+// indexColumns 解析 item[i] 处的列列表：忽略前缀索引长度，保留 ASC/DESC
+func indexColumns(item []token, i int) ([]string, error) {
+	if i >= len(item) || item[i].kind != tokGroup {
+		return nil, fmt.Errorf("缺少索引列: %s", clip(render(item)))
+	}
+	var cols []string
+	for _, part := range splitTop(item[i].sub, ",") {
+		if len(part) == 0 || !part[0].isName() {
+			return nil, fmt.Errorf("不支持的索引列: %s", render(part))
+		}
+		col := quoteIdent(part[0].text)
+		for _, t := range part[1:] {
+			switch {
+			case t.kind == tokGroup:
+			case t.kind == tokWord && (strings.EqualFold(t.text, "ASC") || strings.EqualFold(t.text, "DESC")):
+				col += " " + strings.ToUpper(t.text)
+			default:
+				return nil, fmt.Errorf("不支持的索引列: %s", render(part))
+			}
+		}
+		cols = append(cols, col)
+	}
+	return cols, nil
+}
+
+// indexName PostgreSQL 索引名全库唯一：不含表名时加表名前缀，超过 63 字节时截断并附加摘要
+func indexName(table, name string) string {
+	if !strings.Contains(name, table) {
+		name = table + "_" + name
+	}
+	if len(name) <= 63 {
+		return name
+	}
+	sum := sha1.Sum([]byte(name))
+	return name[:54] + "_" + hex.EncodeToString(sum[:4])
+}
+
+// foreignKey [CONSTRAINT name] FOREIGN KEY (...) REFERENCES t (...) [ON DELETE ...]
+func foreignKey(item []token) (string, error) {
+	i := 0
+	if isKw(item, 0, "CONSTRAINT") && len(item) > 1 && item[1].isName() {
+		i = 2
+	}
+	if !isKw(item, i, "FOREIGN") {
+		return "", fmt.Errorf("不支持的约束: %s", clip(render(item)))
+	}
+	return render(item), nil
+}
+
+// alterTable ALTER TABLE：ADD COLUMN 合并为一条 ALTER TABLE（IF NOT EXISTS），ADD INDEX/KEY 转为 CREATE INDEX；
+// MODIFY COLUMN 转为 ALTER COLUMN TYPE ... USING 列::类型 并按新定义设置 NOT NULL 与默认值；
+// RENAME COLUMN/INDEX/TO 各自拆为独立语句，在同一条 ALTER TABLE 的其他子句之后执行
+func alterTable(stmt []token) ([]string, error) {
+	if len(stmt) < 4 || !stmt[2].isName() {
+		return nil, fmt.Errorf("无法解析的 ALTER TABLE: %s", clip(render(stmt)))
+	}
+	table := stmt[2].text
+	var adds, constraints, indexes, renames, comments []string
+	for _, clause := range splitTop(stmt[3:], ",") {
+		switch {
+		case isKw(clause, 0, "ADD"):
+		case isKw(clause, 0, "MODIFY"):
+			alters, comment, err := modifyColumn(table, clause[1:])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", table, err)
+			}
+			adds = append(adds, alters...)
+			comments = append(comments, comment...)
+			continue
+		case isKw(clause, 0, "DROP") && isKw(clause, 1, "COLUMN") && len(clause) == 3 && clause[2].isName():
+			adds = append(adds, "DROP COLUMN IF EXISTS "+quoteIdent(clause[2].text))
+			continue
+		case isKw(clause, 0, "RENAME"):
+			r, err := rename(table, clause[1:])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", table, err)
+			}
+			renames = append(renames, r)
+			continue
+		default:
+			return nil, fmt.Errorf("%s: 不支持的 ALTER TABLE 子句: %s", table, clip(render(clause)))
+		}
+		clause = clause[1:]
+		switch {
+		case isKw(clause, 0, "UNIQUE", "KEY", "INDEX"):
+			idx, err := index(table, clause)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", table, err)
+			}
+			indexes = append(indexes, idx)
+		case isKw(clause, 0, "CONSTRAINT", "FOREIGN"):
+			fk, err := foreignKey(clause)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", table, err)
+			}
+			constraints = append(constraints, "ALTER TABLE "+quoteIdent(table)+" ADD "+fk)
+		case isKw(clause, 0, "PRIMARY", "FULLTEXT", "SPATIAL", "CHECK"):
+			return nil, fmt.Errorf("%s: 不支持的 ALTER TABLE 子句: ADD %s", table, clip(render(clause)))
+		default:
+			if isKw(clause, 0, "COLUMN") {
+				clause = clause[1:]
+			}
+			col, err := column(clause, "")
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", table, err)
+			}
+			adds = append(adds, "ADD COLUMN IF NOT EXISTS "+col.def)
+			if col.comment != "" {
+				comments = append(comments, columnComment(table, col))
+			}
+		}
+	}
+
+	var out []string
+	if len(adds) > 0 {
+		out = append(out, "ALTER TABLE "+quoteIdent(table)+"\n  "+strings.Join(adds, ",\n  "))
+	}
+	out = append(out, constraints...)
+	out = append(out, indexes...)
+	out = append(out, renames...)
+	return append(out, comments...), nil
+}
+
+// modifyColumn MODIFY [COLUMN] 列定义：MySQL 以新定义整体替换，未声明 NOT NULL/DEFAULT 即取消
+func modifyColumn(table string, clause []token) (alters, comments []string, err error) {
+	if isKw(clause, 0, "COLUMN") {
+		clause = clause[1:]
+	}
+	col, err := column(clause, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	if strings.Contains(col.def, "IDENTITY") || strings.Contains(col.def, "PRIMARY KEY") || strings.Contains(col.def, "UNIQUE") || strings.Contains(col.def, "CHECK") {
+		return nil, nil, fmt.Errorf("MODIFY COLUMN 不支持自增、主键、唯一与 ENUM 列: %s", clip(render(clause)))
+	}
+	// col.def 形如 "name" TYPE [NOT NULL|NULL] [DEFAULT v]
+	name := quoteIdent(col.name)
+	rest := strings.TrimPrefix(col.def, name+" ")
+	typ, notNull, def := rest, false, ""
+	if i := strings.Index(typ, " DEFAULT "); i >= 0 {
+		typ, def = typ[:i], typ[i+len(" DEFAULT "):]
+	}
+	switch {
+	case strings.HasSuffix(typ, " NOT NULL"):
+		typ, notNull = strings.TrimSuffix(typ, " NOT NULL"), true
+	case strings.HasSuffix(typ, " NULL"):
+		typ = strings.TrimSuffix(typ, " NULL")
+	}
+	if strings.Contains(def, " ") {
+		return nil, nil, fmt.Errorf("无法解析的 MODIFY COLUMN: %s", clip(render(clause)))
+	}
+
+	alters = append(alters, fmt.Sprintf("ALTER COLUMN %s TYPE %s USING %s::%s", name, typ, name, typ))
+	if def != "" {
+		alters = append(alters, fmt.Sprintf("ALTER COLUMN %s SET DEFAULT %s", name, def))
+	} else {
+		alters = append(alters, fmt.Sprintf("ALTER COLUMN %s DROP DEFAULT", name))
+	}
+	if notNull {
+		alters = append(alters, fmt.Sprintf("ALTER COLUMN %s SET NOT NULL", name))
+	} else {
+		alters = append(alters, fmt.Sprintf("ALTER COLUMN %s DROP NOT NULL", name))
+	}
+	if col.comment != "" {
+		comments = append(comments, columnComment(table, col))
+	}
+	return alters, comments, nil
+}
+
+// rename RENAME COLUMN a TO b / RENAME INDEX a TO b / RENAME [TO] t
+func rename(table string, clause []token) (string, error) {
+	switch {
+	case isKw(clause, 0, "COLUMN") && len(clause) == 4 && clause[1].isName() && isKw(clause, 2, "TO") && clause[3].isName():
+		return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", quoteIdent(table), quoteIdent(clause[1].text), quoteIdent(clause[3].text)), nil
+	case isKw(clause, 0, "INDEX", "KEY") && len(clause) == 4 && clause[1].isName() && isKw(clause, 2, "TO") && clause[3].isName():
+		return fmt.Sprintf("ALTER INDEX IF EXISTS %s RENAME TO %s", quoteIdent(indexName(table, clause[1].text)), quoteIdent(indexName(table, clause[3].text))), nil
+	case isKw(clause, 0, "TO", "AS") && len(clause) == 2 && clause[1].isName():
+		clause = clause[1:]
+		fallthrough
+	case len(clause) == 1 && clause[0].isName():
+		return fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quoteIdent(table), quoteIdent(clause[0].text)), nil
+	}
+	return "", fmt.Errorf("不支持的 RENAME 子句: RENAME %s", clip(render(clause)))
+}
+
+// dml INSERT/UPDATE/DELETE：只转换标识符引号与 SHA2 函数，ON DUPLICATE KEY UPDATE a = a 转为 ON CONFLICT DO NOTHING
+func dml(stmt []token) ([]string, error) {
+	stmt, err := functions(stmt)
+	if err != nil {
+		return nil, err
+	}
+	for i := range stmt {
+		if !(isKw(stmt, i, "ON") && isKw(stmt, i+1, "DUPLICATE") && isKw(stmt, i+2, "KEY") && isKw(stmt, i+3, "UPDATE")) {
+			continue
+		}
+		for _, set := range splitTop(stmt[i+4:], ",") {
+			if len(set) != 3 || !set[1].is("=") || !set[0].isName() || !set[2].isName() || set[0].text != set[2].text {
+				return nil, fmt.Errorf("只支持无操作的 ON DUPLICATE KEY UPDATE（a = a）: %s", clip(render(set)))
+			}
+		}
+		return []string{render(stmt[:i]) + " ON CONFLICT DO NOTHING"}, nil
+	}
+	return []string{render(stmt)}, nil
+}
+
+// functions 翻译 MySQL 专有函数（递归处理括号内的表达式）
+func functions(toks []token) ([]token, error) {
+	out := make([]token, 0, len(toks))
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		if t.kind == tokGroup {
+			sub, err := functions(t.sub)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, token{kind: tokGroup, sub: sub})
+			continue
+		}
+		if isKw(toks, i, "FROM_UNIXTIME") && i+1 < len(toks) && toks[i+1].kind == tokGroup {
+			if len(splitTop(toks[i+1].sub, ",")) != 1 {
+				return nil, fmt.Errorf("只支持 FROM_UNIXTIME(x): %s", clip(render(toks[i:i+2])))
+			}
+			arg, err := functions(toks[i+1].sub)
+			if err != nil {
+				return nil, err
+			}
+			// to_timestamp 返回 timestamptz，写入 TIMESTAMP 列时按会话时区转换，与 FROM_UNIXTIME 一致
+			out = append(out, token{kind: tokWord, text: "to_timestamp"}, token{kind: tokGroup, sub: arg})
+			i++
+			continue
+		}
+		if !isKw(toks, i, "SHA2") || i+1 >= len(toks) || toks[i+1].kind != tokGroup {
+			out = append(out, t)
+			continue
+		}
+		args := splitTop(toks[i+1].sub, ",")
+		if len(args) != 2 || len(args[1]) != 1 || args[1][0].text != "256" {
+			return nil, fmt.Errorf("只支持 SHA2(x, 256): %s", clip(render(toks[i:i+2])))
+		}
+		expr, err := functions(args[0])
+		if err != nil {
+			return nil, err
+		}
+		convert := token{kind: tokGroup, sub: append(expr, token{kind: tokPunct, text: ","}, token{kind: tokString, text: "UTF8"})}
+		digest := token{kind: tokGroup, sub: []token{{kind: tokWord, text: "convert_to"}, convert}}
+		out = append(out, token{kind: tokWord, text: "encode"}, token{kind: tokGroup, sub: []token{
+			{kind: tokWord, text: "sha256"}, digest, {kind: tokPunct, text: ","}, {kind: tokString, text: "hex"},
+		}})
+		i++
+	}
+	return out, nil
+}
+
+func columnComment(table string, col *pgColumn) string {
+	return fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s", quoteIdent(table), quoteIdent(col.name), quoteString(col.comment))
+}
+
+func quoteIdent(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// quoteString PostgreSQL 字符串字面量（standard_conforming_strings=on，反斜杠无需转义）
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+func unquoted(cols []string) []string {
+	out := make([]string, len(cols))
+	for i, c := range cols {
+		out[i] = strings.Trim(strings.Fields(c)[0], `"`)
+	}
+	return out
+}
+
+func clip(s string) string {
+	if r := []rune(s); len(r) > 80 {
+		return string(r[:80]) + "..."
+	}
+	return s
+}
+
+// ============== 词法 ==============
+
+type tokenKind int
+
+const (
+	tokWord   tokenKind = iota // 关键字、未加引号的标识符、数字
+	tokIdent                   // `x` 或 "x"（text 为去掉引号后的名字）
+	tokString                  // 'x'（text 为反转义后的内容）
+	tokGroup                   // (...)，内容在 sub
+	tokPunct                   // 其他单个符号：, ; = . 等
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	sub  []token
+}
+
+func (t token) isName() bool {
+	return t.kind == tokIdent || t.kind == tokWord
+}
+
+func (t token) is(punct string) bool {
+	return t.kind == tokPunct && t.text == punct
+}
+
+func isKw(toks []token, i int, words ...string) bool {
+	if i < 0 || i >= len(toks) || toks[i].kind != tokWord {
+		return false
+	}
+	for _, w := range words {
+		if strings.EqualFold(toks[i].text, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitTop 按顶层分隔符切分（括号内的分隔符已在 tokGroup 中）
+func splitTop(toks []token, sep string) [][]token {
+	var out [][]token
+	start := 0
+	for i, t := range toks {
+		if t.is(sep) {
+			out = append(out, toks[start:i])
+			start = i + 1
+		}
+	}
+	return append(out, toks[start:])
+}
+
+// render 输出 PostgreSQL 语法：标识符加双引号，字符串按 PostgreSQL 规则转义
+func render(toks []token) string {
+	var sb strings.Builder
+	for i, t := range toks {
+		if i > 0 && !t.is(",") && !t.is(".") && !toks[i-1].is(".") {
+			sb.WriteByte(' ')
+		}
+		switch t.kind {
+		case tokIdent:
+			sb.WriteString(quoteIdent(t.text))
+		case tokString:
+			sb.WriteString(quoteString(t.text))
+		case tokGroup:
+			sb.WriteString("(" + render(t.sub) + ")")
+		default:
+			sb.WriteString(t.text)
+		}
+	}
+	return sb.String()
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func tokenize(src string) ([]token, error) {
+	toks, rest, err := lex(src, false)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("多余的右括号")
+	}
+	return toks, nil
+}
+
+// lex 词法分析；inGroup 时遇到 ')' 返回剩余输入
+func lex(s string, inGroup bool) ([]token, string, error) {
+	var toks []token
+	for len(s) > 0 {
+		c := s[0]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			s = s[1:]
+		case strings.HasPrefix(s, "--") || c == '#':
+			if i := strings.IndexByte(s, '\n'); i >= 0 {
+				s = s[i+1:]
+			} else {
+				s = ""
+			}
+		case strings.HasPrefix(s, "/*"):
+			i := strings.Index(s, "*/")
+			if i < 0 {
+				return nil, "", fmt.Errorf("注释未结束")
+			}
+			s = s[i+2:]
+		case c == '(':
+			sub, rest, err := lex(s[1:], true)
+			if err != nil {
+				return nil, "", err
+			}
+			toks = append(toks, token{kind: tokGroup, sub: sub})
+			s = rest
+		case c == ')':
+			if !inGroup {
+				return toks, s, nil
+			}
+			return toks, s[1:], nil
+		case c == '\'':
+			v, rest, err := lexString(s)
+			if err != nil {
+				return nil, "", err
+			}
+			toks = append(toks, token{kind: tokString, text: v})
+			s = rest
+		case c == '`' || c == '"':
+			i := strings.IndexByte(s[1:], c)
+			if i < 0 {
+				return nil, "", fmt.Errorf("标识符引号未结束")
+			}
+			toks = append(toks, token{kind: tokIdent, text: s[1 : i+1]})
+			s = s[i+2:]
+		case isWordChar(c):
+			i := 1
+			for i < len(s) && isWordChar(s[i]) {
+				i++
+			}
+			toks = append(toks, token{kind: tokWord, text: s[:i]})
+			s = s[i:]
+		default:
+			toks = append(toks, token{kind: tokPunct, text: s[:1]})
+			s = s[1:]
+		}
+	}
+	if inGroup {
+		return nil, "", fmt.Errorf("括号未闭合")
+	}
+	return toks, "", nil
+}
+
+// lexString MySQL 字符串字面量（支持连续两个单引号与反斜杠转义）
+func lexString(s string) (string, string, error) {
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 >= len(s) {
+				break
+			}
+			i++
+			switch s[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case '0':
+				sb.WriteByte(0)
+			default:
+				sb.WriteByte(s[i])
+			}
+		case '\'':
+			if i+1 < len(s) && s[i+1] == '\'' {
+				sb.WriteByte('\'')
+				i++
+				continue
+			}
+			return sb.String(), s[i+1:], nil
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return "", "", fmt.Errorf("字符串未结束")
+}
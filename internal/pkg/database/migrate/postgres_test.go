@@ -0,0 +1,147 @@
+package migrate
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestToPostgres(t *testing.T) {
+	cases := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "create table",
+			src: "CREATE TABLE IF NOT EXISTS `t` (\n" +
+				"  `id` BIGINT PRIMARY KEY AUTO_INCREMENT COMMENT '主键',\n" +
+				"  `ok` TINYINT(1) NOT NULL DEFAULT 1,\n" +
+				"  `kind` ENUM('a','b') NOT NULL DEFAULT 'a',\n" +
+				"  `data` JSON NULL,\n" +
+				"  `at` DATETIME NULL,\n" +
+				"  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,\n" +
+				"  UNIQUE KEY `uk_kind` (`kind`, `at`),\n" +
+				"  INDEX `idx_t_at` (`at`)\n" +
+				") ENGINE=InnoDB AUTO_INCREMENT=100 DEFAULT CHARSET=utf8mb4 COMMENT='测试';",
+			want: `CREATE TABLE IF NOT EXISTS "t" (
+  "id" BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY (START WITH 100),
+  "ok" BOOLEAN NOT NULL DEFAULT TRUE,
+  "kind" VARCHAR(1) NOT NULL DEFAULT 'a' CHECK ("kind" IN ('a', 'b')),
+  "data" JSONB NULL,
+  "at" TIMESTAMP NULL,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "t_uk_kind" ON "t" ("kind", "at");
+
+CREATE INDEX IF NOT EXISTS "idx_t_at" ON "t" ("at");
+
+COMMENT ON TABLE "t" IS '测试';
+
+COMMENT ON COLUMN "t"."id" IS '主键';
+`,
+		},
+		{
+			name: "alter table",
+			src:  "ALTER TABLE `t` ADD COLUMN `x` varchar(20) NULL COMMENT 'x' AFTER `id`, ADD INDEX `idx_x` (`x`);",
+			want: `ALTER TABLE "t"
+  ADD COLUMN IF NOT EXISTS "x" VARCHAR(20) NULL;
+
+CREATE INDEX IF NOT EXISTS "t_idx_x" ON "t" ("x");
+
+COMMENT ON COLUMN "t"."x" IS 'x';
+`,
+		},
+		{
+			name: "modify and drop column",
+			src:  "ALTER TABLE `t` MODIFY COLUMN `x` BIGINT NOT NULL DEFAULT 0 COMMENT 'x' AFTER `id`, MODIFY `y` VARCHAR(100) NULL, DROP COLUMN `z`;",
+			want: `ALTER TABLE "t"
+  ALTER COLUMN "x" TYPE BIGINT USING "x"::BIGINT,
+  ALTER COLUMN "x" SET DEFAULT 0,
+  ALTER COLUMN "x" SET NOT NULL,
+  ALTER COLUMN "y" TYPE VARCHAR(100) USING "y"::VARCHAR(100),
+  ALTER COLUMN "y" DROP DEFAULT,
+  ALTER COLUMN "y" DROP NOT NULL,
+  DROP COLUMN IF EXISTS "z";
+
+COMMENT ON COLUMN "t"."x" IS 'x';
+`,
+		},
+		{
+			name: "rename",
+			src:  "ALTER TABLE `t` RENAME COLUMN `a` TO `b`, RENAME INDEX `idx_a` TO `idx_b`; ALTER TABLE `t` RENAME TO `t2`;",
+			want: `ALTER TABLE "t" RENAME COLUMN "a" TO "b";
+
+ALTER INDEX IF EXISTS "t_idx_a" RENAME TO "t_idx_b";
+
+ALTER TABLE "t" RENAME TO "t2";
+`,
+		},
+		{
+			name: "update from_unixtime",
+			src:  "UPDATE `t` SET `at` = FROM_UNIXTIME(`ts`);",
+			want: `UPDATE "t" SET "at" = to_timestamp ("ts");
+`,
+		},
+		{
+			name: "update sha2",
+			src:  "UPDATE `t` SET `x` = SHA2(`x`, 256) WHERE `x` IS NOT NULL;",
+			want: `UPDATE "t" SET "x" = encode (sha256 (convert_to ("x", 'UTF8')), 'hex') WHERE "x" IS NOT NULL;
+`,
+		},
+		{
+			name: "insert ignore duplicate",
+			src:  "INSERT INTO `t` (`kind`) VALUES ('a') ON DUPLICATE KEY UPDATE `kind` = `kind`;",
+			want: `INSERT INTO "t" ("kind") VALUES ('a') ON CONFLICT DO NOTHING;
+`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ToPostgres(c.src)
+			if err != nil {
+				t.Fatalf("ToPostgres: %v", err)
+			}
+			if got != c.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestToPostgresUnsupported(t *testing.T) {
+	for _, src := range []string{
+		"DROP TABLE `t`;",
+		"ALTER TABLE `t` MODIFY COLUMN `id` BIGINT AUTO_INCREMENT;",
+		"ALTER TABLE `t` DROP INDEX `idx_a`;",
+	} {
+		if _, err := ToPostgres(src); err == nil {
+			t.Errorf("不支持的语句应返回错误: %s", src)
+		}
+	}
+}
+
+// TestPostgresScriptsUpToDate scripts/postgres 必须与 MySQL 脚本同步（修改脚本后执行 go run ./cmd/pgschema）
+func TestPostgresScriptsUpToDate(t *testing.T) {
+	dir := filepath.Join("..", "..", "..", "..", "scripts")
+	scripts, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := PostgresScripts(scripts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Load(filepath.Join(dir, PostgresDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("scripts/postgres 有 %d 个脚本，期望 %d 个，请执行 go run ./cmd/pgschema", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || got[i].SQL != want[i].SQL {
+			t.Errorf("%s 已过期，请执行 go run ./cmd/pgschema", want[i].Name)
+		}
+	}
+}
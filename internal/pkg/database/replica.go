@@ -0,0 +1,69 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+
+	"devops-cd/internal/pkg/config"
+)
+
+// 读写分离
+//
+// - 配置 database.replicas 后通过 GORM dbresolver 注册只读副本（随机选择）
+// - 与 dbresolver 默认行为（所有查询读副本）不同，查询默认仍走主库：状态机写入后立即读取，不能容忍副本延迟
+// - 只有通过 ReadReplica 显式标记的查询（列表、搜索、报表）读副本；事务内的查询始终走主库
+
+const replicaSetting = "devops-cd:read_replica"
+
+// registerReplicas 注册只读副本，未配置副本时不做任何事
+func registerReplicas(db *gorm.DB, cfg *config.DatabaseConfig) error {
+	if len(cfg.Replicas) == 0 {
+		return nil
+	}
+	replicas := make([]gorm.Dialector, 0, len(cfg.Replicas))
+	for _, replica := range cfg.Replicas {
+		dialector, err := openDialector(cfg.GetDriver(), cfg.GetReplicaDSN(replica))
+		if err != nil {
+			return err
+		}
+		replicas = append(replicas, dialector)
+	}
+
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	}).
+		SetMaxIdleConns(cfg.MaxIdleConns).
+		SetMaxOpenConns(cfg.MaxOpenConns).
+		SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
+	if err := db.Use(resolver); err != nil {
+		return fmt.Errorf("注册只读副本失败: %w", err)
+	}
+
+	// 未标记读副本的查询固定走主库：与 dbresolver 的路由回调同为 Before("*")，后注册的排在前面，先于其执行
+	const name = "devops-cd:primary_by_default"
+	if err := db.Callback().Query().Before("*").Register(name, usePrimaryByDefault); err != nil {
+		return fmt.Errorf("注册读写分离回调失败: %w", err)
+	}
+	if err := db.Callback().Row().Before("*").Register(name, usePrimaryByDefault); err != nil {
+		return fmt.Errorf("注册读写分离回调失败: %w", err)
+	}
+	if err := db.Callback().Raw().Before("*").Register(name, usePrimaryByDefault); err != nil {
+		return fmt.Errorf("注册读写分离回调失败: %w", err)
+	}
+	return nil
+}
+
+func usePrimaryByDefault(db *gorm.DB) {
+	if _, ok := db.Get(replicaSetting); !ok {
+		dbresolver.Write.ModifyStatement(db.Statement)
+	}
+}
+
+// ReadReplica 返回读副本的会话，用于可以容忍复制延迟的列表、搜索、报表查询；未配置副本时等同于主库
+func ReadReplica(db *gorm.DB) *gorm.DB {
+	return db.Set(replicaSetting, true).Clauses(dbresolver.Read).Session(&gorm.Session{})
+}
@@ -15,6 +15,23 @@ type GitProvider interface {
 	// ListAllAccessibleRepositories 获取所有可访问的仓库（用于 sync_mode=all）
 	ListAllAccessibleRepositories() ([]RepositoryInfo, error)
 
+	// GetFileContent 获取仓库中指定 ref 下文件的原始内容，文件不存在时返回 ErrFileNotFound
+	// ref 为空时使用默认分支
+	GetFileContent(owner, repo, ref, path string) ([]byte, error)
+
+	// GetTagCommit 获取 tag 指向的提交 SHA（附注 tag 解引用到提交），tag 不存在时返回 ErrRefNotFound
+	GetTagCommit(owner, repo, tag string) (string, error)
+
+	// BranchContainsCommit 判断提交是否在分支历史中，分支或提交不存在时返回 ErrRefNotFound
+	BranchContainsCommit(owner, repo, branch, sha string) (bool, error)
+
+	// CompareCommits 获取 base..head 之间的提交（head 可达而 base 不可达，按时间正序），ref 不存在时返回 ErrRefNotFound
+	// base/head 可为 tag、分支或提交 SHA
+	CompareCommits(owner, repo, base, head string) ([]CommitInfo, error)
+
+	// ChangedFiles 获取 base...head 之间变更的文件路径（重命名时包含新旧路径），ref 不存在时返回 ErrRefNotFound
+	ChangedFiles(owner, repo, base, head string) ([]string, error)
+
 	// GetPlatformType 获取平台类型
 	GetPlatformType() PlatformType
 }
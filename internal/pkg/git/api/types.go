@@ -1,5 +1,17 @@
 package api
 
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrFileNotFound 仓库中文件不存在
+var ErrFileNotFound = errors.New("file not found")
+
+// ErrRefNotFound 仓库中 tag/分支/提交不存在
+var ErrRefNotFound = errors.New("ref not found")
+
 // PlatformType 平台类型
 type PlatformType string
 
@@ -40,6 +52,23 @@ type UserInfo struct {
 	Name     string `json:"name"`
 }
 
+// CommitInfo 提交信息
+type CommitInfo struct {
+	SHA         string    `json:"sha"`
+	Title       string    `json:"title"`   // 提交说明首行
+	Message     string    `json:"message"` // 完整提交说明
+	Author      string    `json:"author"`
+	AuthorEmail string    `json:"author_email"`
+	Date        time.Time `json:"date"`
+	URL         string    `json:"url"`
+}
+
+// CommitTitle 提交说明首行
+func CommitTitle(message string) string {
+	title, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return strings.TrimSpace(title)
+}
+
 // ProviderConfig 通用平台配置
 type ProviderConfig struct {
 	BaseURL string // 平台基础URL
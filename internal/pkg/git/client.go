@@ -73,6 +73,31 @@ func (c *Client) ListAllAccessibleRepositories() ([]api.RepositoryInfo, error) {
 	return c.provider.ListAllAccessibleRepositories()
 }
 
+// GetFileContent 获取仓库文件内容
+func (c *Client) GetFileContent(owner, repo, ref, path string) ([]byte, error) {
+	return c.provider.GetFileContent(owner, repo, ref, path)
+}
+
+// GetTagCommit 获取 tag 指向的提交 SHA
+func (c *Client) GetTagCommit(owner, repo, tag string) (string, error) {
+	return c.provider.GetTagCommit(owner, repo, tag)
+}
+
+// BranchContainsCommit 判断提交是否在分支历史中
+func (c *Client) BranchContainsCommit(owner, repo, branch, sha string) (bool, error) {
+	return c.provider.BranchContainsCommit(owner, repo, branch, sha)
+}
+
+// CompareCommits 获取 base..head 之间的提交
+func (c *Client) CompareCommits(owner, repo, base, head string) ([]api.CommitInfo, error) {
+	return c.provider.CompareCommits(owner, repo, base, head)
+}
+
+// ChangedFiles 获取 base...head 之间变更的文件路径
+func (c *Client) ChangedFiles(owner, repo, base, head string) ([]string, error) {
+	return c.provider.ChangedFiles(owner, repo, base, head)
+}
+
 // GetProvider 获取底层提供者（供高级使用）
 func (c *Client) GetProvider() api.GitProvider {
 	return c.provider
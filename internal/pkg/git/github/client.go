@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
 	"strings"
 	"time"
 
@@ -274,6 +275,158 @@ func (p *Provider) ListAllAccessibleRepositories() ([]api.RepositoryInfo, error)
 	return repos, nil
 }
 
+// GetFileContent 获取仓库文件原始内容
+func (p *Provider) GetFileContent(owner, repo, ref, path string) ([]byte, error) {
+	baseURL := strings.TrimSuffix(p.config.BaseURL, "/")
+	url := fmt.Sprintf("%s/repos/%s/%s/contents/%s", baseURL, owner, repo, strings.TrimPrefix(path, "/"))
+	if ref != "" {
+		url += "?ref=" + neturl.QueryEscape(ref)
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	p.setAuthHeader(req)
+	req.Header.Set("Accept", "application/vnd.github.raw")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, api.ErrFileNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("获取文件失败 (状态码: %d): %s", resp.StatusCode, string(body))
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// GetTagCommit 获取 tag 指向的提交 SHA
+func (p *Provider) GetTagCommit(owner, repo, tag string) (string, error) {
+	baseURL := strings.TrimSuffix(p.config.BaseURL, "/")
+	url := fmt.Sprintf("%s/repos/%s/%s/commits/%s", baseURL, owner, repo, neturl.PathEscape("refs/tags/"+tag))
+
+	var out struct {
+		SHA string `json:"sha"`
+	}
+	if err := p.getJSON(url, &out); err != nil {
+		return "", err
+	}
+	return out.SHA, nil
+}
+
+// BranchContainsCommit 判断提交是否在分支历史中（compare branch...sha 无领先提交即包含）
+func (p *Provider) BranchContainsCommit(owner, repo, branch, sha string) (bool, error) {
+	baseURL := strings.TrimSuffix(p.config.BaseURL, "/")
+	url := fmt.Sprintf("%s/repos/%s/%s/compare/%s...%s?per_page=1", baseURL, owner, repo, neturl.PathEscape(branch), neturl.PathEscape(sha))
+
+	var out struct {
+		AheadBy int `json:"ahead_by"`
+	}
+	if err := p.getJSON(url, &out); err != nil {
+		return false, err
+	}
+	return out.AheadBy == 0, nil
+}
+
+// CompareCommits 获取 base...head 之间的提交（GitHub compare 接口最多返回 250 个提交）
+func (p *Provider) CompareCommits(owner, repo, base, head string) ([]api.CommitInfo, error) {
+	baseURL := strings.TrimSuffix(p.config.BaseURL, "/")
+	url := fmt.Sprintf("%s/repos/%s/%s/compare/%s...%s?per_page=250", baseURL, owner, repo, neturl.PathEscape(base), neturl.PathEscape(head))
+
+	var out struct {
+		Commits []struct {
+			SHA     string `json:"sha"`
+			HTMLURL string `json:"html_url"`
+			Commit  struct {
+				Message string `json:"message"`
+				Author  struct {
+					Name  string    `json:"name"`
+					Email string    `json:"email"`
+					Date  time.Time `json:"date"`
+				} `json:"author"`
+			} `json:"commit"`
+		} `json:"commits"`
+	}
+	if err := p.getJSON(url, &out); err != nil {
+		return nil, err
+	}
+
+	commits := make([]api.CommitInfo, 0, len(out.Commits))
+	for _, c := range out.Commits {
+		commits = append(commits, api.CommitInfo{
+			SHA:         c.SHA,
+			Title:       api.CommitTitle(c.Commit.Message),
+			Message:     c.Commit.Message,
+			Author:      c.Commit.Author.Name,
+			AuthorEmail: c.Commit.Author.Email,
+			Date:        c.Commit.Author.Date,
+			URL:         c.HTMLURL,
+		})
+	}
+	return commits, nil
+}
+
+// ChangedFiles 获取 base...head 之间变更的文件（GitHub compare 接口最多返回 300 个文件）
+func (p *Provider) ChangedFiles(owner, repo, base, head string) ([]string, error) {
+	baseURL := strings.TrimSuffix(p.config.BaseURL, "/")
+	url := fmt.Sprintf("%s/repos/%s/%s/compare/%s...%s?per_page=1", baseURL, owner, repo, neturl.PathEscape(base), neturl.PathEscape(head))
+
+	var out struct {
+		Files []struct {
+			Filename         string `json:"filename"`
+			PreviousFilename string `json:"previous_filename"`
+		} `json:"files"`
+	}
+	if err := p.getJSON(url, &out); err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(out.Files))
+	for _, f := range out.Files {
+		files = append(files, f.Filename)
+		if f.PreviousFilename != "" {
+			files = append(files, f.PreviousFilename)
+		}
+	}
+	return files, nil
+}
+
+// getJSON GET 请求并解析 JSON，404/422 返回 ErrRefNotFound
+func (p *Provider) getJSON(url string, out interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	p.setAuthHeader(req)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// 提交不存在时 GitHub 返回 422
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnprocessableEntity {
+		return api.ErrRefNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("请求失败 (状态码: %d): %s", resp.StatusCode, string(body))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
 // setAuthHeader 设置认证头
 func (p *Provider) setAuthHeader(req *http.Request) {
 	if p.config.Token != "" {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
 	"strings"
 	"time"
 
@@ -279,6 +280,175 @@ func (p *Provider) ListAllAccessibleRepositories() ([]api.RepositoryInfo, error)
 	return repos, nil
 }
 
+// GetFileContent 获取仓库文件原始内容
+func (p *Provider) GetFileContent(owner, repo, ref, path string) ([]byte, error) {
+	baseURL := strings.TrimSuffix(p.config.BaseURL, "/")
+	projectPath := neturl.PathEscape(owner + "/" + repo)
+	filePath := neturl.PathEscape(strings.TrimPrefix(path, "/"))
+	if ref == "" {
+		ref = "HEAD"
+	}
+	url := fmt.Sprintf("%s/api/v4/projects/%s/repository/files/%s/raw?ref=%s", baseURL, projectPath, filePath, neturl.QueryEscape(ref))
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	p.setAuthHeader(req)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, api.ErrFileNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("获取文件失败 (状态码: %d): %s", resp.StatusCode, string(body))
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// GetTagCommit 获取 tag 指向的提交 SHA
+func (p *Provider) GetTagCommit(owner, repo, tag string) (string, error) {
+	baseURL := strings.TrimSuffix(p.config.BaseURL, "/")
+	projectPath := neturl.PathEscape(owner + "/" + repo)
+	url := fmt.Sprintf("%s/api/v4/projects/%s/repository/tags/%s", baseURL, projectPath, neturl.PathEscape(tag))
+
+	var out struct {
+		Commit struct {
+			ID string `json:"id"`
+		} `json:"commit"`
+	}
+	if err := p.getJSON(url, &out); err != nil {
+		return "", err
+	}
+	return out.Commit.ID, nil
+}
+
+// BranchContainsCommit 判断提交是否在分支历史中（查询包含该提交的分支列表）
+func (p *Provider) BranchContainsCommit(owner, repo, branch, sha string) (bool, error) {
+	baseURL := strings.TrimSuffix(p.config.BaseURL, "/")
+	projectPath := neturl.PathEscape(owner + "/" + repo)
+
+	// 最多翻 10 页，避免分支极多的仓库无限请求
+	for page := 1; page <= 10; page++ {
+		url := fmt.Sprintf("%s/api/v4/projects/%s/repository/commits/%s/refs?type=branch&per_page=100&page=%d",
+			baseURL, projectPath, neturl.PathEscape(sha), page)
+
+		var refs []struct {
+			Name string `json:"name"`
+		}
+		if err := p.getJSON(url, &refs); err != nil {
+			return false, err
+		}
+		for _, ref := range refs {
+			if ref.Name == branch {
+				return true, nil
+			}
+		}
+		if len(refs) < 100 {
+			break
+		}
+	}
+	return false, nil
+}
+
+// CompareCommits 获取 base...head 之间的提交
+func (p *Provider) CompareCommits(owner, repo, base, head string) ([]api.CommitInfo, error) {
+	baseURL := strings.TrimSuffix(p.config.BaseURL, "/")
+	projectPath := neturl.PathEscape(owner + "/" + repo)
+	url := fmt.Sprintf("%s/api/v4/projects/%s/repository/compare?from=%s&to=%s&straight=false",
+		baseURL, projectPath, neturl.QueryEscape(base), neturl.QueryEscape(head))
+
+	var out struct {
+		Commits []struct {
+			ID          string    `json:"id"`
+			Title       string    `json:"title"`
+			Message     string    `json:"message"`
+			AuthorName  string    `json:"author_name"`
+			AuthorEmail string    `json:"author_email"`
+			CreatedAt   time.Time `json:"created_at"`
+			WebURL      string    `json:"web_url"`
+		} `json:"commits"`
+	}
+	if err := p.getJSON(url, &out); err != nil {
+		return nil, err
+	}
+
+	commits := make([]api.CommitInfo, 0, len(out.Commits))
+	for _, c := range out.Commits {
+		commits = append(commits, api.CommitInfo{
+			SHA:         c.ID,
+			Title:       c.Title,
+			Message:     c.Message,
+			Author:      c.AuthorName,
+			AuthorEmail: c.AuthorEmail,
+			Date:        c.CreatedAt,
+			URL:         c.WebURL,
+		})
+	}
+	return commits, nil
+}
+
+// ChangedFiles 获取 base...head 之间变更的文件
+func (p *Provider) ChangedFiles(owner, repo, base, head string) ([]string, error) {
+	baseURL := strings.TrimSuffix(p.config.BaseURL, "/")
+	projectPath := neturl.PathEscape(owner + "/" + repo)
+	url := fmt.Sprintf("%s/api/v4/projects/%s/repository/compare?from=%s&to=%s&straight=false",
+		baseURL, projectPath, neturl.QueryEscape(base), neturl.QueryEscape(head))
+
+	var out struct {
+		Diffs []struct {
+			OldPath string `json:"old_path"`
+			NewPath string `json:"new_path"`
+		} `json:"diffs"`
+	}
+	if err := p.getJSON(url, &out); err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(out.Diffs))
+	for _, d := range out.Diffs {
+		files = append(files, d.NewPath)
+		if d.OldPath != "" && d.OldPath != d.NewPath {
+			files = append(files, d.OldPath)
+		}
+	}
+	return files, nil
+}
+
+// getJSON GET 请求并解析 JSON，404 返回 ErrRefNotFound
+func (p *Provider) getJSON(url string, out interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	p.setAuthHeader(req)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return api.ErrRefNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("请求失败 (状态码: %d): %s", resp.StatusCode, string(body))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
 // setAuthHeader 设置认证头
 func (p *Provider) setAuthHeader(req *http.Request) {
 	if p.config.Token != "" {
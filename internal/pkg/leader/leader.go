@@ -0,0 +1,168 @@
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// 主节点选举
+//
+// - 多副本部署时只有主节点运行核心引擎扫描（批次推进、部署触发、Webhook 投递）与定时任务，避免重复触发部署
+// - 基于数据库会话级锁（MySQL 命名锁 GET_LOCK / PostgreSQL advisory lock）：主节点持有一个专用连接，锁随连接存在；进程退出或连接断开时数据库自动释放，其他副本在下一次续期时接管
+// - API 请求仍由所有副本处理，只修改数据库状态，由主节点的引擎推进
+
+// DefaultLockName 默认锁名（同一数据库上的多个部署需配置不同锁名）
+const DefaultLockName = "devops-cd:leader"
+
+// DefaultRenewInterval 默认续期（检查锁 / 竞选）间隔
+const DefaultRenewInterval = 5 * time.Second
+
+// Leader 当前副本是否为主节点；为 nil 时视为单实例部署，始终是主节点
+type Leader interface {
+	IsLeader() bool
+}
+
+// IsLeader l 为 nil 时返回 true
+func IsLeader(l Leader) bool {
+	return l == nil || l.IsLeader()
+}
+
+// lockSQL 会话级锁语句，查询结果均为 1 表示成功
+type lockSQL struct {
+	held    string // 当前连接是否仍持有锁
+	acquire string // 非阻塞获取锁
+	release string // 释放锁
+}
+
+var lockSQLs = map[string]lockSQL{
+	"mysql": {
+		held:    "SELECT IS_USED_LOCK(?) = CONNECTION_ID()",
+		acquire: "SELECT GET_LOCK(?, 0)",
+		release: "DO RELEASE_LOCK(?)",
+	},
+	// advisory lock 以锁名的 hash 为 key，bigint key 在 pg_locks 中拆分为 classid（高 32 位）与 objid（低 32 位）
+	"postgres": {
+		held: "SELECT COUNT(*) FROM pg_locks WHERE locktype = 'advisory' AND granted AND pid = pg_backend_pid()" +
+			" AND objsubid = 1 AND ((classid::bigint << 32) | objid::bigint) = hashtext($1)::bigint",
+		acquire: "SELECT CASE WHEN pg_try_advisory_lock(hashtext($1)) THEN 1 ELSE 0 END",
+		release: "SELECT pg_advisory_unlock(hashtext($1))",
+	},
+}
+
+// Elector 基于数据库会话级锁的主节点选举
+type Elector struct {
+	db       *sql.DB
+	sql      lockSQL
+	name     string
+	interval time.Duration
+	logger   *zap.Logger
+
+	leader atomic.Bool
+	conn   *sql.Conn // 持有锁的连接，仅 Run 所在 goroutine 访问
+}
+
+// NewElector 创建选举器，driver 为 gorm 方言名（mysql/postgres），name 为空时使用 DefaultLockName，interval <= 0 时使用 DefaultRenewInterval
+func NewElector(db *sql.DB, driver, name string, interval time.Duration, logger *zap.Logger) (*Elector, error) {
+	stmts, ok := lockSQLs[driver]
+	if !ok {
+		return nil, fmt.Errorf("主节点选举不支持数据库驱动: %s", driver)
+	}
+	if name == "" {
+		name = DefaultLockName
+	}
+	if interval <= 0 {
+		interval = DefaultRenewInterval
+	}
+	return &Elector{db: db, sql: stmts, name: name, interval: interval, logger: logger.With(zap.String("lock", name))}, nil
+}
+
+// IsLeader 当前副本是否持有主节点锁
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Run 竞选并定期确认锁仍然持有，直到 ctx 结束后释放锁
+func (e *Elector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		e.tick(ctx)
+		select {
+		case <-ctx.Done():
+			e.release()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Elector) tick(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, e.interval)
+	defer cancel()
+
+	if e.conn != nil {
+		var held sql.NullInt64
+		err := e.conn.QueryRowContext(ctx, e.sql.held, e.name).Scan(&held)
+		if err == nil && held.Valid && held.Int64 == 1 {
+			return
+		}
+		e.logger.Warn("[Leader] 主节点锁已丢失", zap.Error(err))
+		e.stepDown()
+		return
+	}
+
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		e.logger.Warn("[Leader] 获取数据库连接失败", zap.Error(err))
+		return
+	}
+	var got sql.NullInt64
+	if err := conn.QueryRowContext(ctx, e.sql.acquire, e.name).Scan(&got); err != nil || !got.Valid || got.Int64 != 1 {
+		if err != nil {
+			// 出错时无法确认锁是否已在服务端获得，丢弃连接
+			e.logger.Warn("[Leader] 竞选主节点失败", zap.Error(err))
+			discard(conn)
+			return
+		}
+		_ = conn.Close()
+		return
+	}
+	e.conn = conn
+	e.leader.Store(true)
+	e.logger.Info("[Leader] 当前副本成为主节点")
+}
+
+// stepDown 放弃主节点身份并丢弃持锁连接（连接断开后数据库释放锁）
+func (e *Elector) stepDown() {
+	e.leader.Store(false)
+	if e.conn != nil {
+		discard(e.conn)
+		e.conn = nil
+	}
+}
+
+// discard 关闭连接且不放回连接池，避免仍持有锁的连接被其他查询复用
+func discard(conn *sql.Conn) {
+	_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	_ = conn.Close()
+}
+
+func (e *Elector) release() {
+	if e.conn == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), e.interval)
+	defer cancel()
+	if _, err := e.conn.ExecContext(ctx, e.sql.release, e.name); err != nil {
+		e.logger.Warn("[Leader] 释放主节点锁失败", zap.Error(err))
+	}
+	e.stepDown()
+	e.logger.Info("[Leader] 已释放主节点锁")
+}
@@ -0,0 +1,244 @@
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// fakeLockServer 模拟 MySQL 命名锁：锁属于会话（连接），连接关闭时自动释放
+type fakeLockServer struct {
+	mu          sync.Mutex
+	owner       map[string]int // 锁名 → 持有连接 ID
+	seq         int
+	open        int
+	failAcquire bool
+	killed      map[int]bool
+}
+
+func newFakeLockServer() *fakeLockServer {
+	return &fakeLockServer{owner: map[string]int{}, killed: map[int]bool{}}
+}
+
+func (s *fakeLockServer) Connect(context.Context) (driver.Conn, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	s.open++
+	return &fakeLockConn{srv: s, id: s.seq}, nil
+}
+
+func (s *fakeLockServer) Driver() driver.Driver { return nil }
+
+// kill 断开锁持有者的会话（模拟网络中断 / KILL CONNECTION），锁随之释放
+func (s *fakeLockServer) kill(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id, ok := s.owner[name]; ok {
+		s.killed[id] = true
+		delete(s.owner, name)
+	}
+}
+
+func (s *fakeLockServer) holder(name string) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, ok := s.owner[name]
+	return id, ok
+}
+
+type fakeLockConn struct {
+	srv *fakeLockServer
+	id  int
+}
+
+func (c *fakeLockConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeLockConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *fakeLockConn) Close() error {
+	c.srv.mu.Lock()
+	defer c.srv.mu.Unlock()
+	c.srv.open--
+	for name, id := range c.srv.owner {
+		if id == c.id {
+			delete(c.srv.owner, name)
+		}
+	}
+	return nil
+}
+
+func (c *fakeLockConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	s := c.srv
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.killed[c.id] {
+		return nil, driver.ErrBadConn
+	}
+	name := args[0].Value.(string)
+	stmts := lockSQLs["mysql"]
+	switch query {
+	case stmts.acquire:
+		if s.failAcquire {
+			return nil, errors.New("lock wait timeout")
+		}
+		if id, ok := s.owner[name]; ok && id != c.id {
+			return &fakeRows{v: 0}, nil
+		}
+		s.owner[name] = c.id
+		return &fakeRows{v: 1}, nil
+	case stmts.held:
+		if s.owner[name] == c.id {
+			return &fakeRows{v: 1}, nil
+		}
+		return &fakeRows{v: 0}, nil
+	}
+	return nil, errors.New("unexpected query: " + query)
+}
+
+func (c *fakeLockConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	s := c.srv
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if query != lockSQLs["mysql"].release {
+		return nil, errors.New("unexpected exec: " + query)
+	}
+	if name := args[0].Value.(string); s.owner[name] == c.id {
+		delete(s.owner, name)
+	}
+	return driver.RowsAffected(0), nil
+}
+
+type fakeRows struct {
+	v    int64
+	done bool
+}
+
+func (r *fakeRows) Columns() []string { return []string{"v"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.v
+	return nil
+}
+
+func newTestElector(t *testing.T, db *sql.DB) *Elector {
+	t.Helper()
+	e, err := NewElector(db, "mysql", "", time.Second, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewElector: %v", err)
+	}
+	return e
+}
+
+func TestElector(t *testing.T) {
+	srv := newFakeLockServer()
+	db := sql.OpenDB(srv)
+	defer db.Close()
+	a, b := newTestElector(t, db), newTestElector(t, db)
+	ctx := context.Background()
+
+	steps := []struct {
+		name       string
+		do         func()
+		wantA      bool
+		wantB      bool
+		wantLocked bool
+	}{
+		{name: "a 竞选成功", do: func() { a.tick(ctx) }, wantA: true, wantLocked: true},
+		{name: "b 竞选失败", do: func() { b.tick(ctx) }, wantA: true, wantLocked: true},
+		{name: "a 续期", do: func() { a.tick(ctx) }, wantA: true, wantLocked: true},
+		{name: "a 会话断开", do: func() { srv.kill(DefaultLockName) }, wantA: true},
+		{name: "a 发现锁丢失", do: func() { a.tick(ctx) }},
+		{name: "b 接管", do: func() { b.tick(ctx) }, wantB: true, wantLocked: true},
+		{name: "a 再次竞选失败", do: func() { a.tick(ctx) }, wantB: true, wantLocked: true},
+		{name: "b 释放锁", do: func() { b.release() }},
+		{name: "a 接管", do: func() { a.tick(ctx) }, wantA: true, wantLocked: true},
+	}
+	for _, s := range steps {
+		s.do()
+		if a.IsLeader() != s.wantA || b.IsLeader() != s.wantB {
+			t.Fatalf("%s: a=%v b=%v, want a=%v b=%v", s.name, a.IsLeader(), b.IsLeader(), s.wantA, s.wantB)
+		}
+		if _, locked := srv.holder(DefaultLockName); locked != s.wantLocked {
+			t.Fatalf("%s: locked=%v, want %v", s.name, locked, s.wantLocked)
+		}
+	}
+}
+
+func TestElectorAcquireErrorDiscardsConn(t *testing.T) {
+	srv := newFakeLockServer()
+	db := sql.OpenDB(srv)
+	defer db.Close()
+	e := newTestElector(t, db)
+
+	srv.failAcquire = true
+	e.tick(context.Background())
+	if e.IsLeader() || e.conn != nil {
+		t.Fatalf("竞选出错时不应成为主节点")
+	}
+	// 出错的连接可能已在服务端持锁，不能放回连接池
+	if srv.open != 0 {
+		t.Fatalf("出错的连接未关闭: open=%d", srv.open)
+	}
+
+	srv.failAcquire = false
+	e.tick(context.Background())
+	if !e.IsLeader() {
+		t.Fatalf("恢复后应成为主节点")
+	}
+}
+
+func TestElectorRunReleasesOnStop(t *testing.T) {
+	srv := newFakeLockServer()
+	db := sql.OpenDB(srv)
+	defer db.Close()
+	e := newTestElector(t, db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.Run(ctx)
+		close(done)
+	}()
+	deadline := time.Now().Add(2 * time.Second)
+	for !e.IsLeader() {
+		if time.Now().After(deadline) {
+			t.Fatal("未成为主节点")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+	if e.IsLeader() {
+		t.Error("停止后仍为主节点")
+	}
+	if _, locked := srv.holder(DefaultLockName); locked {
+		t.Error("停止后锁未释放")
+	}
+}
+
+func TestNewElector(t *testing.T) {
+	if _, err := NewElector(nil, "sqlite", "", 0, zap.NewNop()); err == nil {
+		t.Error("不支持的驱动应返回错误")
+	}
+	e, err := NewElector(nil, "postgres", "", 0, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewElector: %v", err)
+	}
+	if e.name != DefaultLockName || e.interval != DefaultRenewInterval {
+		t.Errorf("缺省值错误: name=%s interval=%s", e.name, e.interval)
+	}
+	if !IsLeader(nil) {
+		t.Error("未配置选举时应视为主节点")
+	}
+}
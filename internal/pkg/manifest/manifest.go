@@ -0,0 +1,214 @@
+package manifest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"devops-cd/internal/model"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 代码库内声明式应用配置（devops-cd.yaml）
+//
+// 示例:
+//
+//	version: 1
+//	apps:
+//	  - name: user-api
+//	    app_type: go
+//	    description: 用户服务
+//	    depends_on: [user-migrator]
+//	    envs:
+//	      pre:
+//	        - cluster: cluster-a
+//	      prod:
+//	        - cluster: cluster-a
+//	          replicas: 3
+//	          values:
+//	            - type: inline_yaml
+//	              content: |
+//	                resources:
+//	                  limits: {cpu: "1"}
+
+const (
+	// FileName 仓库根目录下的清单文件名
+	FileName = "devops-cd.yaml"
+	// HiddenFileName 清单文件的隐藏文件形式，FileName 不存在时读取
+	HiddenFileName = ".devops-cd.yaml"
+
+	// Version 当前支持的清单版本
+	Version = 1
+)
+
+var (
+	appNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+	validAppTypes   = map[string]bool{"static": true, "node": true, "java": true, "go": true, "py": true}
+	validEnvs       = map[string]bool{"pre": true, "prod": true, "dev": true, "test": true, "uat": true}
+	validValueTypes = map[string]bool{"git": true, "http_file": true, "inline_yaml": true, "file": true}
+)
+
+// FileNames 按顺序查找的清单文件名
+var FileNames = []string{FileName, HiddenFileName}
+
+// languageAppTypes 代码库主语言（小写）对应的 app_type，用于按发现规则创建应用
+var languageAppTypes = map[string]string{
+	"go":         "go",
+	"java":       "java",
+	"kotlin":     "java",
+	"python":     "py",
+	"javascript": "node",
+	"typescript": "node",
+	"html":       "static",
+	"vue":        "static",
+}
+
+// Manifest 清单文件
+type Manifest struct {
+	Version int   `yaml:"version"`
+	Apps    []App `yaml:"apps"`
+}
+
+// App 应用定义
+type App struct {
+	Name        string                  `yaml:"name"`
+	AppType     string                  `yaml:"app_type"`
+	Description string                  `yaml:"description,omitempty"`
+	DependsOn   []string                `yaml:"depends_on,omitempty"` // 依赖的应用名（同项目内）
+	Envs        map[string][]EnvCluster `yaml:"envs,omitempty"`       // env -> 集群列表
+}
+
+// EnvCluster 应用在某环境某集群的配置
+type EnvCluster struct {
+	Cluster        string        `yaml:"cluster"`
+	Replicas       int           `yaml:"replicas,omitempty"` // 0 表示使用平台默认值
+	DeploymentName string        `yaml:"deployment_name,omitempty"`
+	Values         []ValuesLayer `yaml:"values,omitempty"` // 追加在项目 app_chart.values 之后
+}
+
+// ValuesLayer 与 model.ValuesLayer 对应的 YAML 形式
+type ValuesLayer struct {
+	Type            string `yaml:"type"`
+	CredentialRef   string `yaml:"credential_ref,omitempty"`
+	RepoURL         string `yaml:"repo_url,omitempty"`
+	RefTemplate     string `yaml:"ref_template,omitempty"`
+	PathTemplate    string `yaml:"path_template,omitempty"`
+	BaseURLTemplate string `yaml:"base_url_template,omitempty"`
+	Content         string `yaml:"content,omitempty"`
+}
+
+// ToModel 转换为 model.ValuesLayer
+func (l ValuesLayer) ToModel() model.ValuesLayer {
+	return model.ValuesLayer{
+		Type:            l.Type,
+		CredentialRef:   l.CredentialRef,
+		RepoURL:         l.RepoURL,
+		RefTemplate:     l.RefTemplate,
+		PathTemplate:    l.PathTemplate,
+		BaseURLTemplate: l.BaseURLTemplate,
+		Content:         l.Content,
+	}
+}
+
+// Parse 解析并校验清单内容
+func Parse(data []byte) (*Manifest, error) {
+	var m Manifest
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("%s 解析失败: %w", FileName, err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// App 按名称查找清单中的应用，未声明时返回 nil
+func (m *Manifest) App(name string) *App {
+	for i := range m.Apps {
+		if m.Apps[i].Name == name {
+			return &m.Apps[i]
+		}
+	}
+	return nil
+}
+
+// Validate 校验清单结构（不涉及平台数据）
+func (m *Manifest) Validate() error {
+	if m.Version != Version {
+		return fmt.Errorf("不支持的 version: %d（当前仅支持 %d）", m.Version, Version)
+	}
+
+	names := make(map[string]bool, len(m.Apps))
+	for i, app := range m.Apps {
+		if !appNamePattern.MatchString(app.Name) || len(app.Name) > 100 {
+			return fmt.Errorf("apps[%d].name 不合法: %q", i, app.Name)
+		}
+		if names[app.Name] {
+			return fmt.Errorf("应用名重复: %s", app.Name)
+		}
+		names[app.Name] = true
+
+		if !validAppTypes[app.AppType] {
+			return fmt.Errorf("%s: app_type 不合法: %q", app.Name, app.AppType)
+		}
+		for _, dep := range app.DependsOn {
+			if dep == app.Name {
+				return fmt.Errorf("%s: 不能依赖自身", app.Name)
+			}
+		}
+
+		for env, clusters := range app.Envs {
+			if !validEnvs[env] {
+				return fmt.Errorf("%s: env 不合法: %q", app.Name, env)
+			}
+			seen := make(map[string]bool, len(clusters))
+			for j, c := range clusters {
+				if strings.TrimSpace(c.Cluster) == "" {
+					return fmt.Errorf("%s: envs.%s[%d].cluster 不能为空", app.Name, env, j)
+				}
+				if seen[c.Cluster] {
+					return fmt.Errorf("%s: envs.%s 集群重复: %s", app.Name, env, c.Cluster)
+				}
+				seen[c.Cluster] = true
+				if c.Replicas < 0 {
+					return fmt.Errorf("%s: envs.%s.%s replicas 不能为负数", app.Name, env, c.Cluster)
+				}
+				if len(c.DeploymentName) > 63 {
+					return fmt.Errorf("%s: envs.%s.%s deployment_name 超过 63 个字符", app.Name, env, c.Cluster)
+				}
+				for k, layer := range c.Values {
+					if !validValueTypes[layer.Type] {
+						return fmt.Errorf("%s: envs.%s.%s values[%d].type 不合法: %q", app.Name, env, c.Cluster, k, layer.Type)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ValidAppType app_type 是否合法
+func ValidAppType(appType string) bool {
+	return validAppTypes[appType]
+}
+
+// ScaffoldApp 代码库无清单但命中发现规则时生成的应用定义（应用名取代码库名，不含环境集群与依赖）
+//
+// appType 为空时按代码库主语言推断
+func ScaffoldApp(repoName, appType, language string) (App, error) {
+	name := strings.ToLower(repoName)
+	if !appNamePattern.MatchString(name) || len(name) > 100 {
+		return App{}, fmt.Errorf("代码库名不能作为应用名: %q", repoName)
+	}
+	if appType == "" {
+		appType = languageAppTypes[strings.ToLower(language)]
+	}
+	if !validAppTypes[appType] {
+		return App{}, fmt.Errorf("无法确定 app_type（代码库语言: %q），请在仓库源发现规则中指定", language)
+	}
+	return App{Name: name, AppType: appType}, nil
+}
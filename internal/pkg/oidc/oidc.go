@@ -0,0 +1,391 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"devops-cd/internal/pkg/config"
+)
+
+// oidc OpenID Connect 授权码模式客户端
+//
+// 流程：
+//  1. AuthCodeURL 生成 IdP 授权地址（state、nonce 由调用方生成）
+//  2. 用户在 IdP 登录后携带 code/state 回调，Exchange 用 code 换取 ID Token
+//  3. VerifyIDToken 按 JWKS 校验签名及 iss/aud/exp/nonce，返回 claims
+//
+// 端点通过 {issuer}/.well-known/openid-configuration 发现并缓存；JWKS 遇到未知 kid 时刷新（IdP 轮换密钥）
+
+const (
+	discoveryTTL       = time.Hour
+	jwksRefreshMinWait = time.Minute
+	defaultTimeout     = 10 * time.Second
+	clockSkew          = time.Minute
+)
+
+var defaultScopes = []string{"openid", "profile", "email"}
+
+// 签名算法白名单（不接受 none 与 HMAC）
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// Discovery OpenID Provider 元数据
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider OIDC 提供方，并发安全
+type Provider struct {
+	cfg    *config.OIDCConfig
+	client *http.Client
+
+	mu           sync.Mutex
+	discovery    *Discovery
+	discoveredAt time.Time
+	keys         map[string]any // kid -> 公钥
+	keysAt       time.Time
+}
+
+// NewProvider 创建 Provider，首次使用时才请求 IdP
+func NewProvider(cfg *config.OIDCConfig) *Provider {
+	timeout := defaultTimeout
+	if cfg.TimeoutSeconds > 0 {
+		timeout = time.Duration(cfg.TimeoutSeconds) * time.Second
+	}
+	return &Provider{cfg: cfg, client: &http.Client{Timeout: timeout}}
+}
+
+// AuthCodeURL IdP 授权地址
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce string) (string, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	scopes := p.cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = defaultScopes
+	}
+	q := url.Values{
+		"response_type": {"code"},
+		"client_id":     {p.cfg.ClientID},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"scope":         {strings.Join(scopes, " ")},
+		"state":         {state},
+		"nonce":         {nonce},
+	}
+	sep := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return d.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange 授权码换取 ID Token（原始 JWT）
+func (p *Provider) Exchange(ctx context.Context, code string) (string, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {p.cfg.RedirectURL},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	// RFC 6749 2.3.1: client_secret_basic，凭据需先做 form 编码
+	req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+
+	var token struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	status, err := p.doJSON(req, &token)
+	if err != nil {
+		return "", fmt.Errorf("token endpoint: %w", err)
+	}
+	if token.Error != "" {
+		return "", fmt.Errorf("token endpoint: %s: %s", token.Error, token.ErrorDescription)
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("token endpoint: HTTP %d", status)
+	}
+	if token.IDToken == "" {
+		return "", errors.New("token endpoint: 响应缺少 id_token（scope 需包含 openid）")
+	}
+	return token.IDToken, nil
+}
+
+// VerifyIDToken 校验 ID Token 签名、iss、aud、exp 与 nonce
+func (p *Provider) VerifyIDToken(ctx context.Context, raw, nonce string) (Claims, error) {
+	d, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, d, kid)
+	},
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(d.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("id_token 校验失败: %w", err)
+	}
+
+	got, _ := claims["nonce"].(string)
+	if nonce == "" || got != nonce {
+		return nil, errors.New("id_token nonce 不匹配")
+	}
+	// 多个 aud 时 azp 必须为本客户端
+	if aud, _ := claims.GetAudience(); len(aud) > 1 {
+		if azp, _ := claims["azp"].(string); azp != p.cfg.ClientID {
+			return nil, errors.New("id_token azp 不匹配")
+		}
+	}
+	return Claims(claims), nil
+}
+
+// discover 读取（缓存的）Provider 元数据
+func (p *Provider) discover(ctx context.Context) (*Discovery, error) {
+	p.mu.Lock()
+	if p.discovery != nil && time.Since(p.discoveredAt) < discoveryTTL {
+		d := p.discovery
+		p.mu.Unlock()
+		return d, nil
+	}
+	p.mu.Unlock()
+
+	issuer := strings.TrimSuffix(p.cfg.Issuer, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var d Discovery
+	status, err := p.doJSON(req, &d)
+	if err != nil {
+		return nil, fmt.Errorf("OIDC discovery: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("OIDC discovery: HTTP %d", status)
+	}
+	if strings.TrimSuffix(d.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("OIDC discovery: issuer 不一致（配置 %s，IdP 返回 %s）", p.cfg.Issuer, d.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, errors.New("OIDC discovery: 缺少 authorization/token/jwks 端点")
+	}
+
+	p.mu.Lock()
+	p.discovery = &d
+	p.discoveredAt = time.Now()
+	p.mu.Unlock()
+	return &d, nil
+}
+
+// key 按 kid 取公钥；未命中时刷新 JWKS（两次刷新至少间隔 jwksRefreshMinWait）
+func (p *Provider) key(ctx context.Context, d *Discovery, kid string) (any, error) {
+	p.mu.Lock()
+	keys, keysAt := p.keys, p.keysAt
+	p.mu.Unlock()
+
+	if k := pickKey(keys, kid); k != nil {
+		return k, nil
+	}
+	if keys != nil && time.Since(keysAt) < jwksRefreshMinWait {
+		return nil, fmt.Errorf("未知的签名密钥 kid=%q", kid)
+	}
+
+	keys, err := p.fetchJWKS(ctx, d.JWKSURI)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	p.keys, p.keysAt = keys, time.Now()
+	p.mu.Unlock()
+
+	if k := pickKey(keys, kid); k != nil {
+		return k, nil
+	}
+	return nil, fmt.Errorf("未知的签名密钥 kid=%q", kid)
+}
+
+// pickKey kid 为空且只有一把密钥时直接使用
+func pickKey(keys map[string]any, kid string) any {
+	if k, ok := keys[kid]; ok {
+		return k
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, k := range keys {
+			return k
+		}
+	}
+	return nil
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (p *Provider) fetchJWKS(ctx context.Context, uri string) (map[string]any, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	status, err := p.doJSON(req, &set)
+	if err != nil {
+		return nil, fmt.Errorf("JWKS: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("JWKS: HTTP %d", status)
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			// 不支持的密钥类型（如 Keycloak 的加密密钥）忽略
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported kty %s", k.Kty)
+	}
+}
+
+// doJSON 发送请求并解析 JSON 响应体（限制 1MB）
+func (p *Provider) doJSON(req *http.Request, out any) (int, error) {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if err := json.Unmarshal(body, out); err != nil && resp.StatusCode == http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return resp.StatusCode, nil
+}
+
+// Claims ID Token claims
+type Claims map[string]any
+
+// lookup 按 . 分隔的路径取值（如 realm_access.roles）
+func (c Claims) lookup(path string) any {
+	if v, ok := c[path]; ok {
+		return v
+	}
+	var cur any = map[string]any(c)
+	for _, part := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+		cur = m[part]
+	}
+	return cur
+}
+
+// String 字符串 claim，不存在或类型不符时返回空串
+func (c Claims) String(path string) string {
+	s, _ := c.lookup(path).(string)
+	return s
+}
+
+// Strings 字符串数组 claim，单个字符串视为一个元素
+func (c Claims) Strings(path string) []string {
+	switch v := c.lookup(path).(type) {
+	case string:
+		return []string{v}
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,100 @@
+package oidc
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// state 授权请求的 state 参数：以 HS256 签名的短期 JWT，携带 nonce
+//
+// 无需服务端存储，多副本部署时回调可落到任意实例；nonce 随 ID Token 返回，用于防重放
+// 签名密钥由 JWT secret 派生，避免 state 被当作登录 Token 使用
+// 签名只能证明 state 由服务端签发，不能证明回调来自发起登录的浏览器：授权时另下发 HttpOnly Cookie 保存 state 的摘要，
+// 回调时比对，防止攻击者把自己账号的 code/state 注入受害者浏览器完成登录（login CSRF）
+
+// StateTTL 从发起授权到回调的最长时间
+const StateTTL = 10 * time.Minute
+
+const stateAudience = "oidc_state"
+
+// StateCookieName 保存 state 摘要的 Cookie，有效期与 StateTTL 一致
+const StateCookieName = "devops_cd_oidc_state"
+
+// StateCookiePath Cookie 只随 OIDC 授权/回调接口发送
+const StateCookiePath = "/api/v1/auth/oidc"
+
+type stateClaims struct {
+	Nonce string `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// NewState 生成 state 与 nonce
+func NewState(secret []byte) (state, nonce string, err error) {
+	nonce, err = randomString(24)
+	if err != nil {
+		return "", "", err
+	}
+	now := time.Now()
+	claims := stateClaims{
+		Nonce: nonce,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{stateAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(StateTTL)),
+		},
+	}
+	state, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(stateKey(secret))
+	if err != nil {
+		return "", "", err
+	}
+	return state, nonce, nil
+}
+
+// ParseState 校验 state 并返回 nonce
+func ParseState(secret []byte, state string) (string, error) {
+	var claims stateClaims
+	_, err := jwt.ParseWithClaims(state, &claims, func(*jwt.Token) (any, error) { return stateKey(secret), nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(stateAudience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return "", err
+	}
+	if claims.Nonce == "" {
+		return "", errors.New("state 缺少 nonce")
+	}
+	return claims.Nonce, nil
+}
+
+// StateDigest state 的摘要（Cookie 值）
+func StateDigest(state string) string {
+	sum := sha256.Sum256([]byte(state))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// VerifyStateDigest 回调的 state 是否与发起登录的浏览器 Cookie 中的摘要一致
+func VerifyStateDigest(state, digest string) bool {
+	return digest != "" && subtle.ConstantTimeCompare([]byte(StateDigest(state)), []byte(digest)) == 1
+}
+
+func stateKey(secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(stateAudience))
+	return mac.Sum(nil)
+}
+
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,87 @@
+package oidc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestParseState(t *testing.T) {
+	secret := []byte("jwt-secret")
+	state, nonce, err := NewState(secret)
+	if err != nil {
+		t.Fatalf("NewState: %v", err)
+	}
+	expired, err := jwt.NewWithClaims(jwt.SigningMethodHS256, stateClaims{
+		Nonce: "n",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{stateAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+		},
+	}).SignedString(stateKey(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 直接以 JWT secret 签名（登录 Token 的签名方式）不能作为 state
+	loginToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, stateClaims{
+		Nonce: "n",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{stateAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}).SignedString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name      string
+		secret    []byte
+		state     string
+		wantNonce string
+		wantErr   bool
+	}{
+		{name: "合法", secret: secret, state: state, wantNonce: nonce},
+		{name: "密钥不一致", secret: []byte("other"), state: state, wantErr: true},
+		{name: "已过期", secret: secret, state: expired, wantErr: true},
+		{name: "JWT secret 直接签名", secret: secret, state: loginToken, wantErr: true},
+		{name: "被篡改", secret: secret, state: state + "x", wantErr: true},
+		{name: "为空", secret: secret, state: "", wantErr: true},
+	}
+	for _, c := range cases {
+		got, err := ParseState(c.secret, c.state)
+		if (err != nil) != c.wantErr || got != c.wantNonce {
+			t.Errorf("%s: ParseState = %q, %v", c.name, got, err)
+		}
+	}
+}
+
+func TestVerifyStateDigest(t *testing.T) {
+	state, _, err := NewState([]byte("jwt-secret"))
+	if err != nil {
+		t.Fatalf("NewState: %v", err)
+	}
+	other, _, err := NewState([]byte("jwt-secret"))
+	if err != nil {
+		t.Fatalf("NewState: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		state  string
+		cookie string
+		want   bool
+	}{
+		{name: "同一浏览器", state: state, cookie: StateDigest(state), want: true},
+		{name: "缺少 Cookie", state: state, cookie: "", want: false},
+		{name: "注入他人的 state", state: other, cookie: StateDigest(state), want: false},
+		{name: "Cookie 为 state 原文", state: state, cookie: state, want: false},
+		{name: "空 state 与空 Cookie", state: "", cookie: "", want: false},
+	}
+	for _, c := range cases {
+		if got := VerifyStateDigest(c.state, c.cookie); got != c.want {
+			t.Errorf("%s: VerifyStateDigest = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
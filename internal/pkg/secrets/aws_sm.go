@@ -0,0 +1,81 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"devops-cd/internal/pkg/config"
+	"devops-cd/internal/pkg/crypto"
+)
+
+// awsSMBackend 读取 AWS Secrets Manager 密钥（path 为 secret name / ARN，读取 AWSCURRENT 版本）；
+// SecretString 为 JSON 对象时按字段返回，否则作为 token
+type awsSMBackend struct {
+	cfg   *config.AWSSecretsManagerConfig
+	creds *crypto.AWSCredentialChain
+}
+
+func newAWSSMBackend(cfg *config.AWSSecretsManagerConfig) *awsSMBackend {
+	return &awsSMBackend{cfg: cfg, creds: crypto.NewAWSCredentialChain(cfg.AccessKeyID, cfg.SecretAccessKey, cfg.SessionToken)}
+}
+
+func (b *awsSMBackend) Name() string { return BackendAWSSM }
+
+func (b *awsSMBackend) Read(ctx context.Context, path string) (*Secret, error) {
+	region := firstNonEmpty(b.cfg.Region, os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"))
+	if region == "" {
+		return nil, fmt.Errorf("secrets.aws_sm.region 未配置")
+	}
+	endpoint := b.cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com/", region)
+	}
+	creds, err := b.creds.Retrieve(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+
+	body, _ := json.Marshal(map[string]string{"SecretId": path})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	crypto.SignAWSV4(req, body, region, "secretsmanager", creds.AccessKeyID, creds.SecretAccessKey, creds.SessionToken, time.Now().UTC())
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("aws secretsmanager HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	var out struct {
+		SecretString *string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	if out.SecretString == nil {
+		return nil, fmt.Errorf("仅支持 SecretString 类型的密钥")
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(*out.SecretString), &obj); err != nil {
+		return &Secret{Data: map[string]string{"token": *out.SecretString}}, nil
+	}
+	return &Secret{Data: stringMap(obj)}, nil
+}
@@ -0,0 +1,291 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"time"
+
+	"devops-cd/internal/pkg/config"
+)
+
+// 外部密钥后端
+//
+// - credential_ref 写作 "<backend>:<path>"（如 vault:secret/data/ci/harbor、aws_sm:prod/registry）时，由对应后端读取凭据
+// - 读取结果为字符串 map，与 config_credentials 解密后的结构一致；_type 未给出时按字段推断（token/basic_auth/ssh_key/tls_client_cert）
+// - 结果按 cache_ttl 缓存；带 lease 的动态密钥到期前优先续租，续租失败再重新读取
+// - path 必须落在该后端配置的 allowed_prefixes 内，未配置时该后端不可引用，避免任意能编辑 credential_ref 的用户读取平台身份可访问的全部密钥
+
+const (
+	BackendVault = "vault"
+	BackendAWSSM = "aws_sm"
+
+	defaultCacheTTL = 5 * time.Minute
+	backendTimeout  = 10 * time.Second
+)
+
+// Secret 后端读取结果
+type Secret struct {
+	Data          map[string]string
+	LeaseID       string        // 动态密钥 lease（KV 为空）
+	LeaseDuration time.Duration // lease 有效期，0 表示不过期
+	Renewable     bool
+}
+
+// Backend 外部密钥后端
+type Backend interface {
+	Name() string
+	Read(ctx context.Context, path string) (*Secret, error)
+}
+
+// LeaseRenewer 支持 lease 续租的后端
+type LeaseRenewer interface {
+	Renew(ctx context.Context, leaseID string) (time.Duration, error)
+}
+
+type cacheEntry struct {
+	secret   *Secret
+	expireAt time.Time
+}
+
+var (
+	mu       sync.RWMutex
+	backends map[string]Backend
+	allowed  map[string][]string // backend → allowed_prefixes
+	cacheTTL time.Duration
+	cache    = map[string]*cacheEntry{}
+	cacheMu  sync.Mutex
+)
+
+// Init 按配置初始化后端；未显式调用时首次使用会从 config.GlobalConfig 懒加载
+func Init(cfg *config.SecretsConfig) error {
+	ttl := defaultCacheTTL
+	if s := strings.TrimSpace(cfg.CacheTTL); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return fmt.Errorf("secrets.cache_ttl 非法: %s", cfg.CacheTTL)
+		}
+		ttl = d
+	}
+
+	m := map[string]Backend{
+		BackendVault: newVaultBackend(&cfg.Vault),
+		BackendAWSSM: newAWSSMBackend(&cfg.AWSSM),
+	}
+	a := map[string][]string{
+		BackendVault: normalizePrefixes(cfg.Vault.AllowedPrefixes),
+		BackendAWSSM: normalizePrefixes(cfg.AWSSM.AllowedPrefixes),
+	}
+
+	mu.Lock()
+	backends, allowed, cacheTTL = m, a, ttl
+	mu.Unlock()
+
+	cacheMu.Lock()
+	cache = map[string]*cacheEntry{}
+	cacheMu.Unlock()
+	return nil
+}
+
+func getBackends() (map[string]Backend, time.Duration, error) {
+	mu.RLock()
+	m, ttl := backends, cacheTTL
+	mu.RUnlock()
+	if m != nil {
+		return m, ttl, nil
+	}
+	if config.GlobalConfig == nil {
+		return nil, 0, fmt.Errorf("secrets 未初始化")
+	}
+	if err := Init(&config.GlobalConfig.Secrets); err != nil {
+		return nil, 0, err
+	}
+	return getBackends()
+}
+
+// ParseRef 拆分 "<backend>:<path>"；非外部引用（如 "id:123"、"123"）或 path 不在该后端 allowed_prefixes 内时返回 ok=false
+func ParseRef(ref string) (backend, path string, ok bool) {
+	backend, path, ok = splitRef(ref)
+	if !ok || checkAllowed(backend, path) != nil {
+		return "", "", false
+	}
+	return backend, path, true
+}
+
+// IsExternalRef 是否为外部密钥引用（只看前缀；path 不被允许时由 Resolve 返回明确的错误，而不是回退为 id 查询）
+func IsExternalRef(ref string) bool {
+	_, _, ok := splitRef(ref)
+	return ok
+}
+
+func splitRef(ref string) (backend, path string, ok bool) {
+	backend, path, found := strings.Cut(strings.TrimSpace(ref), ":")
+	if !found {
+		return "", "", false
+	}
+	switch backend {
+	case BackendVault, BackendAWSSM:
+		return backend, strings.Trim(strings.TrimSpace(path), "/"), true
+	}
+	return "", "", false
+}
+
+// checkAllowed 校验 path 是否落在后端的 allowed_prefixes 内
+func checkAllowed(backend, path string) error {
+	if path == "" {
+		return fmt.Errorf("缺少路径")
+	}
+	if strings.ContainsAny(path, "?#%\\") {
+		return fmt.Errorf("路径包含非法字符: %s", path)
+	}
+	for _, seg := range strings.Split(path, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return fmt.Errorf("路径非法: %s", path)
+		}
+	}
+	if _, _, err := getBackends(); err != nil {
+		return err
+	}
+	mu.RLock()
+	prefixes := allowed[backend]
+	mu.RUnlock()
+	for _, p := range prefixes {
+		if path == strings.TrimSuffix(p, "/") || strings.HasPrefix(path, p) {
+			return nil
+		}
+	}
+	if len(prefixes) == 0 {
+		return fmt.Errorf("secrets.%s.allowed_prefixes 未配置，不允许引用 %s", backend, backend)
+	}
+	return fmt.Errorf("路径 %s 不在 secrets.%s.allowed_prefixes 内", path, backend)
+}
+
+// normalizePrefixes 去掉首尾空白与开头的 /；不以 / 或 : 结尾的前缀补 /，按路径段匹配（secret/data/ci 不匹配 secret/data/ci-admin）
+func normalizePrefixes(in []string) []string {
+	out := make([]string, 0, len(in))
+	for _, p := range in {
+		p = strings.TrimLeft(strings.TrimSpace(p), "/")
+		if p == "" {
+			continue
+		}
+		if !strings.HasSuffix(p, "/") && !strings.HasSuffix(p, ":") {
+			p += "/"
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// Resolve 读取外部密钥引用，返回凭据明文（含 _type）的副本
+func Resolve(ctx context.Context, ref string) (map[string]string, error) {
+	backend, path, ok := splitRef(ref)
+	if !ok {
+		return nil, fmt.Errorf("credential_ref 不是外部密钥引用: %s", ref)
+	}
+	if err := checkAllowed(backend, path); err != nil {
+		return nil, fmt.Errorf("credential_ref=%s 被拒绝: %w", ref, err)
+	}
+	m, ttl, err := getBackends()
+	if err != nil {
+		return nil, err
+	}
+	b := m[backend]
+
+	ref = backend + ":" + path
+	cacheMu.Lock()
+	entry := cache[ref]
+	cacheMu.Unlock()
+	now := time.Now()
+	if entry != nil && now.Before(entry.expireAt) {
+		return maps.Clone(entry.secret.Data), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, backendTimeout)
+	defer cancel()
+
+	// 动态密钥优先续租，避免每次重新签发凭据
+	if entry != nil && entry.secret.Renewable && entry.secret.LeaseID != "" {
+		if r, ok := b.(LeaseRenewer); ok {
+			if d, err := r.Renew(ctx, entry.secret.LeaseID); err == nil {
+				renewed := *entry.secret
+				renewed.LeaseDuration = d
+				store(ref, &renewed, ttl)
+				return maps.Clone(renewed.Data), nil
+			}
+		}
+	}
+
+	secret, err := b.Read(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("credential_ref=%s 读取失败: %w", ref, err)
+	}
+	secret.Data = withType(secret.Data)
+	store(ref, secret, ttl)
+	return maps.Clone(secret.Data), nil
+}
+
+// store 写入缓存，有效期取 cache_ttl 与 lease 剩余时间（提前 10% 到期）的较小值
+func store(ref string, secret *Secret, ttl time.Duration) {
+	if secret.LeaseDuration > 0 {
+		if d := secret.LeaseDuration * 9 / 10; d < ttl {
+			ttl = d
+		}
+	}
+	if ttl <= 0 {
+		return
+	}
+	cacheMu.Lock()
+	cache[ref] = &cacheEntry{secret: secret, expireAt: time.Now().Add(ttl)}
+	cacheMu.Unlock()
+}
+
+// withType 补全 _type：优先使用密钥中的 _type / type 字段，否则按字段推断
+func withType(data map[string]string) map[string]string {
+	if data == nil {
+		data = map[string]string{}
+	}
+	if data["_type"] != "" {
+		return data
+	}
+	switch t := data["type"]; {
+	case t == "basic_auth" || t == "token" || t == "ssh_key" || t == "tls_client_cert":
+		data["_type"] = t
+	case data["private_key"] != "":
+		data["_type"] = "ssh_key"
+	case data["token"] != "":
+		data["_type"] = "token"
+	case data["username"] != "" || data["password"] != "":
+		data["_type"] = "basic_auth"
+	case data["cert"] != "" && data["key"] != "":
+		data["_type"] = "tls_client_cert"
+	}
+	return data
+}
+
+// stringMap 将 JSON 对象转换为字符串 map，非字符串值保留 JSON 文本
+func stringMap(obj map[string]interface{}) map[string]string {
+	m := make(map[string]string, len(obj))
+	for k, v := range obj {
+		switch val := v.(type) {
+		case string:
+			m[k] = val
+		case nil:
+		default:
+			raw, _ := json.Marshal(val)
+			m[k] = string(raw)
+		}
+	}
+	return m
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
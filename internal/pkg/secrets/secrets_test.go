@@ -0,0 +1,69 @@
+package secrets
+
+import (
+	"testing"
+
+	"devops-cd/internal/pkg/config"
+)
+
+func TestParseRefAllowedPrefixes(t *testing.T) {
+	cfg := &config.SecretsConfig{
+		Vault: config.VaultSecretsConfig{AllowedPrefixes: []string{"secret/data/devops-cd", " /database/creds/app/ "}},
+		AWSSM: config.AWSSecretsManagerConfig{AllowedPrefixes: []string{"devops-cd/", "arn:aws:secretsmanager:us-east-1:123456789012:secret:devops-cd/"}},
+	}
+	if err := Init(cfg); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		mu.Lock()
+		backends, allowed = nil, nil
+		mu.Unlock()
+	})
+
+	cases := []struct {
+		ref      string
+		external bool
+		ok       bool
+		path     string
+	}{
+		{"vault:secret/data/devops-cd/harbor", true, true, "secret/data/devops-cd/harbor"},
+		{"vault:/secret/data/devops-cd", true, true, "secret/data/devops-cd"},
+		{"vault:database/creds/app/readonly", true, true, "database/creds/app/readonly"},
+		{"vault:secret/data/devops-cd-admin/root", true, false, ""},
+		{"vault:secret/data/devops-cd/../admin", true, false, ""},
+		{"vault:secret/data/devops-cd//x", true, false, ""},
+		{"vault:secret/data/devops-cd/x?version=1", true, false, ""},
+		{"vault:auth/token/create", true, false, ""},
+		{"vault:", true, false, ""},
+		{"aws_sm:devops-cd/registry", true, true, "devops-cd/registry"},
+		{"aws_sm:arn:aws:secretsmanager:us-east-1:123456789012:secret:devops-cd/registry-AbCdEf", true, true, "arn:aws:secretsmanager:us-east-1:123456789012:secret:devops-cd/registry-AbCdEf"},
+		{"aws_sm:prod/db", true, false, ""},
+		{"id:12", false, false, ""},
+		{"12", false, false, ""},
+	}
+	for _, c := range cases {
+		if got := IsExternalRef(c.ref); got != c.external {
+			t.Errorf("IsExternalRef(%q) = %v, want %v", c.ref, got, c.external)
+		}
+		_, path, ok := ParseRef(c.ref)
+		if ok != c.ok || path != c.path {
+			t.Errorf("ParseRef(%q) = %q, %v, want %q, %v", c.ref, path, ok, c.path, c.ok)
+		}
+	}
+}
+
+func TestParseRefNoPrefixesDenies(t *testing.T) {
+	if err := Init(&config.SecretsConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		mu.Lock()
+		backends, allowed = nil, nil
+		mu.Unlock()
+	})
+	for _, ref := range []string{"vault:secret/data/x", "aws_sm:x"} {
+		if _, _, ok := ParseRef(ref); ok {
+			t.Errorf("未配置 allowed_prefixes 时 ParseRef(%q) 应拒绝", ref)
+		}
+	}
+}
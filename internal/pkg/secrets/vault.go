@@ -0,0 +1,247 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"devops-cd/internal/pkg/config"
+)
+
+const (
+	VaultAuthToken      = "token"
+	VaultAuthKubernetes = "kubernetes"
+	VaultAuthAppRole    = "approle"
+
+	defaultVaultJWTPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+var httpClient = &http.Client{Timeout: backendTimeout}
+
+// errVaultForbidden token 失效或无权限（403），登录类认证方式会重新登录后重试一次
+var errVaultForbidden = fmt.Errorf("vault permission denied")
+
+type vaultResponse struct {
+	LeaseID       string                 `json:"lease_id"`
+	LeaseDuration int                    `json:"lease_duration"`
+	Renewable     bool                   `json:"renewable"`
+	Data          map[string]interface{} `json:"data"`
+	Auth          *struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+}
+
+// vaultBackend 读取 Vault 密钥（path 为 API 路径，KV v2 需包含 data/，如 secret/data/ci/harbor）；
+// 认证 token 到期前（剩余 1/3 有效期）自动 renew-self，续期失败或不可续期时重新登录
+type vaultBackend struct {
+	cfg *config.VaultSecretsConfig
+
+	mu          sync.Mutex
+	token       string
+	tokenRenew  time.Time // 到达后尝试续期；零值表示不过期
+	tokenExpire time.Time
+	renewable   bool
+}
+
+func newVaultBackend(cfg *config.VaultSecretsConfig) *vaultBackend {
+	return &vaultBackend{cfg: cfg}
+}
+
+func (b *vaultBackend) Name() string { return BackendVault }
+
+func (b *vaultBackend) Read(ctx context.Context, path string) (*Secret, error) {
+	var out vaultResponse
+	if err := b.call(ctx, http.MethodGet, strings.Trim(path, "/"), nil, &out); err != nil {
+		return nil, err
+	}
+	if out.Data == nil {
+		return nil, fmt.Errorf("vault 路径 %s 不存在或无数据", path)
+	}
+
+	data := out.Data
+	// KV v2：data.data 为密钥内容，data.metadata 为版本信息
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, hasMeta := data["metadata"]; hasMeta {
+			data = inner
+		}
+	}
+	return &Secret{
+		Data:          stringMap(data),
+		LeaseID:       out.LeaseID,
+		LeaseDuration: time.Duration(out.LeaseDuration) * time.Second,
+		Renewable:     out.Renewable,
+	}, nil
+}
+
+// Renew 续租动态密钥 lease
+func (b *vaultBackend) Renew(ctx context.Context, leaseID string) (time.Duration, error) {
+	var out vaultResponse
+	if err := b.call(ctx, http.MethodPut, "sys/leases/renew", map[string]string{"lease_id": leaseID}, &out); err != nil {
+		return 0, err
+	}
+	return time.Duration(out.LeaseDuration) * time.Second, nil
+}
+
+// call 携带认证 token 调用 Vault API；403 时丢弃 token 重新认证后重试一次
+func (b *vaultBackend) call(ctx context.Context, method, path string, in interface{}, out interface{}) error {
+	token, err := b.authToken(ctx)
+	if err != nil {
+		return err
+	}
+	err = b.do(ctx, method, path, token, in, out)
+	if err == errVaultForbidden && b.authMethod() != VaultAuthToken {
+		b.mu.Lock()
+		b.token = ""
+		b.mu.Unlock()
+		if token, err = b.authToken(ctx); err != nil {
+			return err
+		}
+		err = b.do(ctx, method, path, token, in, out)
+	}
+	return err
+}
+
+func (b *vaultBackend) do(ctx context.Context, method, path, token string, in interface{}, out interface{}) error {
+	addr := strings.TrimRight(firstNonEmpty(b.cfg.Addr, os.Getenv("VAULT_ADDR")), "/")
+	if addr == "" {
+		return fmt.Errorf("secrets.vault.addr 未配置")
+	}
+	var body io.Reader
+	if in != nil {
+		raw, _ := json.Marshal(in)
+		body = bytes.NewReader(raw)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, addr+"/v1/"+path, body)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if b.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", b.cfg.Namespace)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusForbidden {
+		return errVaultForbidden
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("vault HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	if out == nil || len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, out)
+}
+
+func (b *vaultBackend) authMethod() string {
+	if m := strings.TrimSpace(b.cfg.AuthMethod); m != "" {
+		return m
+	}
+	return VaultAuthToken
+}
+
+// authToken 返回可用 token：未到续期时间直接使用，到期前尝试 renew-self，失败则重新登录
+func (b *vaultBackend) authToken(ctx context.Context) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if b.token != "" && (b.tokenRenew.IsZero() || now.Before(b.tokenRenew)) {
+		return b.token, nil
+	}
+	if b.token != "" && b.renewable && now.Before(b.tokenExpire) {
+		var out vaultResponse
+		if err := b.do(ctx, http.MethodPost, "auth/token/renew-self", b.token, map[string]string{}, &out); err == nil && out.Auth != nil {
+			b.setToken(b.token, out.Auth.LeaseDuration, out.Auth.Renewable)
+			return b.token, nil
+		}
+	}
+	if err := b.login(ctx); err != nil {
+		return "", err
+	}
+	return b.token, nil
+}
+
+func (b *vaultBackend) login(ctx context.Context) error {
+	method := b.authMethod()
+	mount := strings.Trim(b.cfg.AuthMount, "/")
+	if mount == "" {
+		mount = method
+	}
+
+	var in map[string]string
+	switch method {
+	case VaultAuthToken:
+		token := firstNonEmpty(b.cfg.Token, os.Getenv("VAULT_TOKEN"))
+		if token == "" {
+			return fmt.Errorf("secrets.vault.token 未配置")
+		}
+		// 静态 token：查询 ttl 以便到期前续期，查询失败时按不过期处理
+		var out struct {
+			Data struct {
+				TTL       int  `json:"ttl"`
+				Renewable bool `json:"renewable"`
+			} `json:"data"`
+		}
+		if err := b.do(ctx, http.MethodGet, "auth/token/lookup-self", token, nil, &out); err != nil {
+			b.setToken(token, 0, false)
+			return nil
+		}
+		b.setToken(token, out.Data.TTL, out.Data.Renewable)
+		return nil
+	case VaultAuthKubernetes:
+		jwt, err := os.ReadFile(firstNonEmpty(b.cfg.JWTPath, defaultVaultJWTPath))
+		if err != nil {
+			return fmt.Errorf("读取 ServiceAccount token 失败: %w", err)
+		}
+		in = map[string]string{"role": b.cfg.Role, "jwt": strings.TrimSpace(string(jwt))}
+	case VaultAuthAppRole:
+		in = map[string]string{"role_id": b.cfg.RoleID, "secret_id": firstNonEmpty(b.cfg.SecretID, os.Getenv("VAULT_SECRET_ID"))}
+	default:
+		return fmt.Errorf("secrets.vault.auth_method 不支持: %s", method)
+	}
+
+	var out vaultResponse
+	if err := b.do(ctx, http.MethodPost, "auth/"+mount+"/login", "", in, &out); err != nil {
+		return fmt.Errorf("vault %s 登录失败: %w", method, err)
+	}
+	if out.Auth == nil || out.Auth.ClientToken == "" {
+		return fmt.Errorf("vault %s 登录未返回 token", method)
+	}
+	b.setToken(out.Auth.ClientToken, out.Auth.LeaseDuration, out.Auth.Renewable)
+	return nil
+}
+
+// setToken 记录 token 及续期时间（有效期过去 2/3 时续期）
+func (b *vaultBackend) setToken(token string, ttlSeconds int, renewable bool) {
+	b.token, b.renewable = token, renewable
+	b.tokenRenew, b.tokenExpire = time.Time{}, time.Time{}
+	if ttlSeconds > 0 {
+		ttl := time.Duration(ttlSeconds) * time.Second
+		now := time.Now()
+		b.tokenRenew = now.Add(ttl * 2 / 3)
+		b.tokenExpire = now.Add(ttl)
+	}
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"devops-cd/internal/model"
+	pkgErrors "devops-cd/pkg/responses"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AnnouncementRepository struct {
+	db *gorm.DB
+}
+
+func NewAnnouncementRepository(db *gorm.DB) *AnnouncementRepository {
+	return &AnnouncementRepository{db: db}
+}
+
+func (r *AnnouncementRepository) Create(a *model.Announcement) error {
+	if err := r.db.Create(a).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "创建公告失败", err)
+	}
+	return nil
+}
+
+func (r *AnnouncementRepository) GetByID(id int64) (*model.Announcement, error) {
+	var a model.Announcement
+	if err := r.db.First(&a, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, pkgErrors.ErrRecordNotFound
+		}
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询公告失败", err)
+	}
+	return &a, nil
+}
+
+func (r *AnnouncementRepository) Update(a *model.Announcement) error {
+	if err := r.db.Save(a).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "更新公告失败", err)
+	}
+	return nil
+}
+
+func (r *AnnouncementRepository) Delete(id int64) error {
+	if err := r.db.Delete(&model.Announcement{}, id).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "删除公告失败", err)
+	}
+	return nil
+}
+
+// List 分页查询全部公告（管理端）
+func (r *AnnouncementRepository) List(page, pageSize int) ([]*model.Announcement, int64, error) {
+	var list []*model.Announcement
+	var total int64
+	q := r.db.Model(&model.Announcement{})
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询公告列表失败", err)
+	}
+	if err := q.Order("id DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&list).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询公告列表失败", err)
+	}
+	return list, total, nil
+}
+
+// ListActive 查询当前生效的公告
+func (r *AnnouncementRepository) ListActive(now time.Time) ([]*model.Announcement, error) {
+	var list []*model.Announcement
+	err := r.db.Model(&model.Announcement{}).
+		Where("enabled = ?", true).
+		Where("starts_at IS NULL OR starts_at <= ?", now).
+		Where("ends_at IS NULL OR ends_at > ?", now).
+		Order("id DESC").
+		Find(&list).Error
+	if err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询生效公告失败", err)
+	}
+	return list, nil
+}
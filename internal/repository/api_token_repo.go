@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"devops-cd/internal/model"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"gorm.io/gorm"
+)
+
+type APITokenRepository struct {
+	db *gorm.DB
+}
+
+func NewAPITokenRepository(db *gorm.DB) *APITokenRepository {
+	return &APITokenRepository{db: db}
+}
+
+func (r *APITokenRepository) Create(token *model.APIToken) error {
+	if err := r.db.Create(token).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "创建 API Token 失败", err)
+	}
+	return nil
+}
+
+func (r *APITokenRepository) GetByID(id int64) (*model.APIToken, error) {
+	var token model.APIToken
+	if err := r.db.First(&token, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgErrors.ErrRecordNotFound
+		}
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询 API Token 失败", err)
+	}
+	return &token, nil
+}
+
+// GetByHash 按 token 摘要查询，不存在时返回 ErrRecordNotFound
+func (r *APITokenRepository) GetByHash(hash string) (*model.APIToken, error) {
+	var token model.APIToken
+	if err := r.db.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgErrors.ErrRecordNotFound
+		}
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询 API Token 失败", err)
+	}
+	return &token, nil
+}
+
+func (r *APITokenRepository) List(includeRevoked bool, page, pageSize int) ([]*model.APIToken, int64, error) {
+	var list []*model.APIToken
+	var total int64
+	q := r.db.Model(&model.APIToken{})
+	if !includeRevoked {
+		q = q.Where("revoked_at IS NULL")
+	}
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询 API Token 列表失败", err)
+	}
+	if err := q.Order("id DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&list).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询 API Token 列表失败", err)
+	}
+	return list, total, nil
+}
+
+// Revoke 吊销（已吊销时不重复更新），返回是否更新
+func (r *APITokenRepository) Revoke(id int64, operator string, at time.Time) (bool, error) {
+	res := r.db.Model(&model.APIToken{}).Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": at, "revoked_by": operator})
+	if res.Error != nil {
+		return false, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "吊销 API Token 失败", res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// TouchLastUsed 记录最近使用时间与来源（不更新 updated_at）
+func (r *APITokenRepository) TouchLastUsed(id int64, at time.Time, ip string) error {
+	return r.db.Model(&model.APIToken{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"last_used_at": at, "last_used_ip": ip}).Error
+}
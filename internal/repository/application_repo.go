@@ -3,11 +3,14 @@ package repository
 import (
 	"devops-cd/internal/dto"
 	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/appquery"
+	"devops-cd/internal/pkg/database"
 	pkgErrors "devops-cd/pkg/responses"
 	"encoding/json"
 	"fmt"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
+	"strings"
 )
 
 type ApplicationRepository struct {
@@ -77,7 +80,7 @@ func (r *ApplicationRepository) List(page, pageSize int, projectID *int64, repoI
 	var apps []*model.Application
 	var total int64
 
-	query := r.db.Model(&model.Application{}).
+	query := database.ReadReplica(r.db).Model(&model.Application{}).
 		Preload("Project").
 		Preload("Repository").
 		Preload("Team").
@@ -230,13 +233,26 @@ func (r *ApplicationRepository) SearchWithBuilds(param *dto.ApplicationSearchPar
 	}
 
 	if param.Keyword != "" {
-		appCond += " AND a.name LIKE ?"
+		appCond += " AND a.name " + likeOp(r.db) + " ?"
 		appCondArgs = append(appCondArgs, "%"+param.Keyword+"%")
 	}
+	if strings.TrimSpace(param.Query) != "" {
+		q, err := appquery.Parse(param.Query)
+		if err != nil {
+			return nil, 0, pkgErrors.New(pkgErrors.CodeBadRequest, err.Error())
+		}
+		if cond, args := q.SQL(); cond != "" {
+			appCond += " AND " + cond
+			appCondArgs = append(appCondArgs, args...)
+		}
+	}
+
+	// 列表查询读副本
+	db := database.ReadReplica(r.db)
 
 	// COUNT 查询
 	var total int64
-	if err := r.db.Table("applications a").Where(appCond, appCondArgs...).Count(&total).Error; err != nil {
+	if err := db.Table("applications a").Where(appCond, appCondArgs...).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -280,12 +296,12 @@ LEFT JOIN projects p ON a.project_id = p.id
 LEFT JOIN teams t ON a.team_id = t.id
 LEFT JOIN repositories r ON a.repo_id = r.id
 WHERE %s
-ORDER BY c.build_created DESC
+ORDER BY %s
 LIMIT ? OFFSET ?
-	`, appCond, appCond)
+	`, appCond, appCond, descNullsLast(r.db, "c.build_created"))
 
 	var apps []*model.ApplicationWithBuild
-	if err := r.db.Raw(sql, append(appCondArgs, append(appCondArgs, param.PageSize, offset)...)...).Scan(&apps).Error; err != nil {
+	if err := db.Raw(sql, append(appCondArgs, append(appCondArgs, param.PageSize, offset)...)...).Scan(&apps).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -297,3 +313,21 @@ func WithPreloadEnvConfigs() QueryOption {
 		return db.Preload("EnvConfigs")
 	}
 }
+
+// SyncDeployedTagsByBatch 批次生产部署完成后将 applications.deployed_tag 更新为发布应用的 target_tag（跳过生产的应用不更新）
+func (r *ApplicationRepository) SyncDeployedTagsByBatch(batchID int64) error {
+	if isPostgres(r.db) {
+		return r.db.Exec(`
+			UPDATE applications a
+			SET deployed_tag = ra.target_tag
+			FROM release_apps ra
+			WHERE a.id = ra.app_id AND ra.batch_id = ? AND ra.target_tag IS NOT NULL AND ra.skip_prod_env = false
+		`, batchID).Error
+	}
+	return r.db.Exec(`
+		UPDATE applications a
+		JOIN release_apps ra ON a.id = ra.app_id
+		SET a.deployed_tag = ra.target_tag
+		WHERE ra.batch_id = ? AND ra.target_tag IS NOT NULL AND ra.skip_prod_env = false
+	`, batchID).Error
+}
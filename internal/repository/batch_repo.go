@@ -9,6 +9,7 @@ import (
 	"gorm.io/gorm"
 
 	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/database"
 	"devops-cd/internal/pkg/logger"
 	"devops-cd/pkg/constants"
 )
@@ -74,6 +75,9 @@ func (r *BatchRepository) List(req dto.BatchListParam) ([]*model.Batch, int64, e
 		if req.Initiator != nil && *req.Initiator != "" {
 			query = query.Where("initiator = ?", *req.Initiator)
 		}
+		if len(req.ProjectIDs) > 0 {
+			query = query.Where("release_batches.project_id IN ?", req.ProjectIDs)
+		}
 
 		// 新增：审批状态过滤
 		if req.ApprovalStatus != nil && *req.ApprovalStatus != "" {
@@ -98,13 +102,14 @@ func (r *BatchRepository) List(req dto.BatchListParam) ([]*model.Batch, int64, e
 		return query
 	}
 
-	// 统计总数// 统计总数
-	if err := applyFilters(r.db.Model(&model.Batch{})).Count(&total).Error; err != nil {
+	// 统计总数（列表查询读副本）
+	db := database.ReadReplica(r.db)
+	if err := applyFilters(db.Model(&model.Batch{})).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 	// 分页查询
 	offset := (req.Page - 1) * req.PageSize
-	err := applyFilters(r.db.Model(&model.Batch{})).
+	err := applyFilters(db.Model(&model.Batch{})).
 		Select(`release_batches.*, COALESCE(COUNT(release_apps.id), 0) AS apps_count`).
 		Joins(`LEFT JOIN release_apps ON release_apps.batch_id = release_batches.id`).
 		Group(`release_batches.created_at, release_batches.id`).
@@ -162,10 +167,12 @@ func (r *BatchRepository) GetReleaseAppsByBatchID(batchID int64, page, pageSize
 	return apps, total, err
 }
 
+const filteredBuildScanPages = 5
+
 // GetBuildByAppIDAndTag 根据应用ID和镜像标签查询构建记录
 func (r *BatchRepository) GetBuildByAppIDAndTag(appID int64, imageTag string) (*model.Build, error) {
 	var build model.Build
-	if err := r.db.Where("app_id = ? AND image_tag = ? AND build_status = ?", appID, imageTag, "success").Order("build_created DESC").First(&build).Error; err != nil {
+	if err := r.db.Scopes(model.TrustedBuilds).Where("app_id = ? AND image_tag = ? AND build_status = ?", appID, imageTag, "success").Order("build_created DESC").First(&build).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil // 未找到，返回 nil 而不是错误
 		}
@@ -174,31 +181,121 @@ func (r *BatchRepository) GetBuildByAppIDAndTag(appID int64, imageTag string) (*
 	return &build, nil
 }
 
-// GetBuildsSinceTime 获取应用在指定时间之后的成功构建记录
-func (r *BatchRepository) GetBuildsSinceTime(appID int64, sinceTime time.Time, limit int) ([]*model.Build, error) {
-	var builds []*model.Build
-	query := r.db.Where("app_id = ?", appID).
+// GetBuildsSinceTime 获取应用在指定时间之后的成功构建记录（按应用构建过滤规则过滤）
+func (r *BatchRepository) GetBuildsSinceTime(appID int64, sinceTime time.Time, limit int, filter *model.TagFilter) ([]*model.Build, error) {
+	query := r.db.Scopes(model.TrustedBuilds).Where("app_id = ?", appID).
 		Where("build_created > ?", sinceTime).
 		Where("build_status = ?", "success"). // 只返回成功的构建
 		Order("build_created DESC")           // 时间倒序（最新在前）
 
-	if limit > 0 {
-		query = query.Limit(limit)
-	}
+	return findFilteredBuilds(query, limit, filter)
+}
 
-	err := query.Find(&builds).Error
-	return builds, err
+// GetRecentBuilds 获取应用最近的成功构建记录（用于新应用，按应用构建过滤规则过滤）
+func (r *BatchRepository) GetRecentBuilds(appID int64, limit int, filter *model.TagFilter) ([]*model.Build, error) {
+	query := r.db.Scopes(model.TrustedBuilds).Where("app_id = ?", appID).
+		Where("build_status = ?", "success"). // 只返回成功的构建
+		Order("build_created DESC")           // 时间倒序（最新在前）
+
+	return findFilteredBuilds(query, limit, filter)
 }
 
-// GetRecentBuilds 获取应用最近的成功构建记录（用于新应用）
-func (r *BatchRepository) GetRecentBuilds(appID int64, limit int) ([]*model.Build, error) {
+// GetRecentBuildsByApps 批量获取应用自上次部署以来的成功构建（每个应用最多 limit 条，按应用构建过滤规则过滤）
+//
+// 与 GetBuildByAppIDAndTag + GetBuildsSinceTime / GetRecentBuilds 逐个查询结果一致，但只发一条窗口查询：
+// deployed_tag 对应的构建存在时取其之后的构建，否则（新应用或构建不存在）取最近的构建；
+// 配置了过滤规则的应用多取 filteredBuildScanPages 页候选后在内存中过滤
+func (r *BatchRepository) GetRecentBuildsByApps(apps []*model.Application, limit int) (map[int64][]*model.Build, error) {
+	result := make(map[int64][]*model.Build, len(apps))
+	if len(apps) == 0 || limit <= 0 {
+		return result, nil
+	}
+
+	appIDs := make([]int64, 0, len(apps))
+	filters := make(map[int64]*model.TagFilter)
+	var deployedTags [][]interface{}
+	for _, app := range apps {
+		if _, ok := result[app.ID]; ok {
+			continue
+		}
+		result[app.ID] = nil
+		appIDs = append(appIDs, app.ID)
+		if !app.TagFilter.IsEmpty() {
+			filters[app.ID] = app.TagFilter
+		}
+		if app.DeployedTag != nil {
+			deployedTags = append(deployedTags, []interface{}{app.ID, *app.DeployedTag})
+		}
+	}
+
+	candidates := r.db.Model(&model.Build{}).Scopes(model.TrustedBuilds).
+		Select("builds.*, ROW_NUMBER() OVER (PARTITION BY builds.app_id ORDER BY builds.build_created DESC) AS rn").
+		Where("builds.app_id IN ? AND builds.build_status = ?", appIDs, "success")
+	if len(deployedTags) > 0 {
+		// 当前部署的构建（同 tag 取最新）
+		deployed := r.db.Model(&model.Build{}).Scopes(model.TrustedBuilds).
+			Select("app_id, MAX(build_created) AS deployed_time").
+			Where("build_status = ? AND (app_id, image_tag) IN ?", "success", deployedTags).
+			Group("app_id")
+		candidates = candidates.Joins("LEFT JOIN (?) AS d ON d.app_id = builds.app_id", deployed).
+			Where("d.deployed_time IS NULL OR builds.build_created > d.deployed_time")
+	}
+
+	query := r.db.Table("(?) AS c", candidates)
+	if len(filters) > 0 {
+		filteredIDs := make([]int64, 0, len(filters))
+		for id := range filters {
+			filteredIDs = append(filteredIDs, id)
+		}
+		query = query.Where("c.rn <= ? OR (c.app_id IN ? AND c.rn <= ?)", limit, filteredIDs, limit*4*filteredBuildScanPages)
+	} else {
+		query = query.Where("c.rn <= ?", limit)
+	}
+
 	var builds []*model.Build
-	err := r.db.Where("app_id = ?", appID).
-		Where("build_status = ?", "success"). // 只返回成功的构建
-		Order("build_created DESC").          // 时间倒序（最新在前）
-		Limit(limit).
-		Find(&builds).Error
-	return builds, err
+	if err := query.Order("c.app_id, c.rn").Find(&builds).Error; err != nil {
+		return nil, err
+	}
+	for _, b := range builds {
+		if filter, ok := filters[b.AppID]; ok && (len(result[b.AppID]) >= limit || !filter.MatchBuild(b)) {
+			continue
+		}
+		result[b.AppID] = append(result[b.AppID], b)
+	}
+	return result, nil
+}
+
+// findFilteredBuilds 分页扫描直到凑够 limit 条满足过滤规则的构建（最多扫描 filteredBuildScanPages 页）
+func findFilteredBuilds(query *gorm.DB, limit int, filter *model.TagFilter) ([]*model.Build, error) {
+	if filter.IsEmpty() || limit <= 0 {
+		var builds []*model.Build
+		if limit > 0 {
+			query = query.Limit(limit)
+		}
+		err := query.Find(&builds).Error
+		return builds, err
+	}
+
+	pageSize := limit * 4
+	result := make([]*model.Build, 0, limit)
+	for page := 0; page < filteredBuildScanPages; page++ {
+		var builds []*model.Build
+		if err := query.Session(&gorm.Session{}).Offset(page * pageSize).Limit(pageSize).Find(&builds).Error; err != nil {
+			return nil, err
+		}
+		for _, b := range builds {
+			if filter.MatchBuild(b) {
+				result = append(result, b)
+				if len(result) == limit {
+					return result, nil
+				}
+			}
+		}
+		if len(builds) < pageSize {
+			break
+		}
+	}
+	return result, nil
 }
 
 // DeleteReleaseApp 删除发布应用记录
@@ -246,7 +343,7 @@ func (r *BatchRepository) CheckAppConflict(tx *gorm.DB, appIDs []int64, excludeB
 		Select("release_apps.app_id, release_batches.id as batch_id, release_batches.batch_number, release_batches.status").
 		Joins("JOIN release_batches ON release_apps.batch_id = release_batches.id").
 		Where("release_apps.app_id IN ?", appIDs).
-		Where("release_batches.status < ?", constants.BatchStatusCompleted).
+		Where("(release_batches.status < ? OR release_batches.status = ?)", constants.BatchStatusCompleted, constants.BatchStatusRollingBack).
 		Where("release_batches.status NOT IN ?", []int8{constants.BatchStatusCancelled})
 
 	if excludeBatchID != nil {
@@ -303,7 +400,7 @@ func (r *BatchRepository) GetLatestBuildsAfterDeployment(tx *gorm.DB, appIDs []i
 			} else if deployedBuild != nil {
 				// 2.2 查找该构建之后的最新成功构建
 				var buildsAfter []*model.Build
-				if err := r.db.Where("app_id = ?", app.ID).
+				if err := r.db.Scopes(model.TrustedBuilds).Where("app_id = ?", app.ID).
 					Where("build_created > ?", deployedBuild.BuildCreated).
 					Where("build_status = ?", "success").
 					Order("build_created DESC").
@@ -324,7 +421,7 @@ func (r *BatchRepository) GetLatestBuildsAfterDeployment(tx *gorm.DB, appIDs []i
 		// 3. Fallback：如果还没有找到构建，使用最新成功构建
 		if build == nil {
 			var latestBuilds []*model.Build
-			err := r.db.Where("app_id = ?", app.ID).
+			err := r.db.Scopes(model.TrustedBuilds).Where("app_id = ?", app.ID).
 				Where("build_status = ?", "success").
 				Order("build_created DESC").
 				Limit(1).
@@ -0,0 +1,172 @@
+//go:build integration
+
+package repository_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"devops-cd/internal/model"
+	"devops-cd/internal/repository"
+	"devops-cd/internal/testutil/enginetest"
+	"devops-cd/pkg/constants"
+)
+
+const recentBuildLimit = 15
+
+// seedBuilds 为应用写入 n 条构建，build_created 按分钟递增（tag 为 v1..vn），每 7 条有一条失败构建
+func seedBuilds(tb testing.TB, h *enginetest.Harness, app *model.Application, n int) {
+	tb.Helper()
+	if n == 0 {
+		return
+	}
+	base := time.Now().Add(-time.Duration(n) * time.Hour).Truncate(time.Second)
+	builds := make([]*model.Build, 0, n)
+	for i := 1; i <= n; i++ {
+		at := base.Add(time.Duration(i) * time.Minute)
+		branch := "main"
+		if i%3 == 0 {
+			branch = "feature/x"
+		}
+		status := "success"
+		if i%7 == 0 {
+			status = "failure"
+		}
+		builds = append(builds, &model.Build{
+			RepoID:          app.RepoID,
+			AppID:           app.ID,
+			BuildNumber:     i,
+			BuildStatus:     status,
+			BuildEvent:      "tag",
+			CommitSHA:       fmt.Sprintf("%040d", i),
+			CommitBranch:    branch,
+			BuildCreated:    at,
+			BuildStarted:    at,
+			BuildFinished:   at,
+			ImageTag:        fmt.Sprintf("v%d", i),
+			AppBuildSuccess: true,
+		})
+	}
+	if err := h.DB.CreateInBatches(builds, 100).Error; err != nil {
+		tb.Fatalf("写入构建失败: %v", err)
+	}
+}
+
+// recentBuildsPerApp 原逐个应用查询的实现（GetBuildByAppIDAndTag + GetBuildsSinceTime / GetRecentBuilds），作为对照
+func recentBuildsPerApp(repo *repository.BatchRepository, apps []*model.Application, limit int) (map[int64][]*model.Build, error) {
+	result := make(map[int64][]*model.Build, len(apps))
+	for _, app := range apps {
+		var builds []*model.Build
+		var err error
+		var deployed *model.Build
+		if app.DeployedTag != nil {
+			if deployed, err = repo.GetBuildByAppIDAndTag(app.ID, *app.DeployedTag); err != nil {
+				return nil, err
+			}
+		}
+		if deployed != nil {
+			builds, err = repo.GetBuildsSinceTime(app.ID, deployed.BuildCreated, limit, app.TagFilter)
+		} else {
+			builds, err = repo.GetRecentBuilds(app.ID, limit, app.TagFilter)
+		}
+		if err != nil {
+			return nil, err
+		}
+		result[app.ID] = builds
+	}
+	return result, nil
+}
+
+func buildIDs(builds []*model.Build) []int64 {
+	ids := make([]int64, 0, len(builds))
+	for _, b := range builds {
+		ids = append(ids, b.ID)
+	}
+	return ids
+}
+
+// TestGetRecentBuildsByAppsMatchesPerApp 窗口查询与逐个应用查询的结果一致
+func TestGetRecentBuildsByAppsMatchesPerApp(t *testing.T) {
+	h := enginetest.New(t)
+	project := h.SeedProject()
+
+	strPtr := func(s string) *string { return &s }
+	newApp := func(builds int, deployedTag *string, filter *model.TagFilter) *model.Application {
+		app := h.SeedApp(project.ID, nil)
+		seedBuilds(t, h, app, builds)
+		app.DeployedTag = deployedTag
+		app.TagFilter = filter
+		return app
+	}
+	apps := []*model.Application{
+		newApp(40, nil, nil),               // 新应用：最近 15 条
+		newApp(40, strPtr("v20"), nil),     // 部署版本之后的构建
+		newApp(40, strPtr("v38"), nil),     // 部署版本之后不足 15 条
+		newApp(40, strPtr("v7"), nil),      // 部署版本对应失败构建：取最近 15 条
+		newApp(40, strPtr("missing"), nil), // 部署版本不存在：取最近 15 条
+		newApp(40, strPtr("v40"), nil),     // 已是最新：无构建
+		newApp(0, nil, nil),                // 无构建
+		newApp(120, nil, &model.TagFilter{ExcludeBranches: []string{"^feature/"}}),
+		newApp(120, strPtr("v30"), &model.TagFilter{IncludeTags: []string{"5$"}}),
+	}
+	// 来源校验不一致的构建不参与（包括作为部署基准）
+	if err := h.DB.Model(&model.Build{}).
+		Where("app_id = ? AND image_tag IN ?", apps[1].ID, []string{"v20", "v40"}).
+		Update("provenance_status", constants.BuildProvenanceMismatch).Error; err != nil {
+		t.Fatal(err)
+	}
+	// 重复传入的应用只查询一次
+	apps = append(apps, apps[0])
+
+	repo := repository.NewBatchRepository(h.DB)
+	got, err := repo.GetRecentBuildsByApps(apps, recentBuildLimit)
+	if err != nil {
+		t.Fatalf("GetRecentBuildsByApps: %v", err)
+	}
+	want, err := recentBuildsPerApp(repo, apps, recentBuildLimit)
+	if err != nil {
+		t.Fatalf("逐个查询失败: %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("返回 %d 个应用, want %d", len(got), len(want))
+	}
+	for i, app := range apps {
+		gotIDs, wantIDs := buildIDs(got[app.ID]), buildIDs(want[app.ID])
+		if fmt.Sprint(gotIDs) != fmt.Sprint(wantIDs) {
+			t.Errorf("apps[%d] (%s): got %v, want %v", i, app.Name, gotIDs, wantIDs)
+		}
+	}
+}
+
+// BenchmarkGetRecentBuildsByApps 批次详情一页 60 个应用：窗口查询 vs 逐个应用查询
+func BenchmarkGetRecentBuildsByApps(b *testing.B) {
+	h := enginetest.New(b)
+	project := h.SeedProject()
+	apps := make([]*model.Application, 0, 60)
+	for i := 0; i < 60; i++ {
+		app := h.SeedApp(project.ID, nil)
+		seedBuilds(b, h, app, 50)
+		if i%2 == 0 {
+			tag := "v25"
+			app.DeployedTag = &tag
+		}
+		apps = append(apps, app)
+	}
+	repo := repository.NewBatchRepository(h.DB)
+
+	b.Run("windowed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := repo.GetRecentBuildsByApps(apps, recentBuildLimit); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("per_app", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := recentBuildsPerApp(repo, apps, recentBuildLimit); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -2,7 +2,10 @@ package repository
 
 import (
 	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/database"
 	pkgErrors "devops-cd/pkg/responses"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -17,6 +20,12 @@ type BuildRepository interface {
 	ListByAppID(appID int64, limit int) ([]*model.Build, error)
 	Update(build *model.Build) error
 	Delete(id int64) error
+	// ListByImage 查询镜像地址（不含 tag，兼容记录中带 tag 的地址）与 tag 对应的构建
+	ListByImage(image, tag string) ([]*model.Build, error)
+	// FindLatestByImage 查询该镜像地址最近一次构建（用于确定镜像所属应用）
+	FindLatestByImage(image string) (*model.Build, error)
+	// MarkImagePushed 记录镜像仓库确认的 digest 与推送时间
+	MarkImagePushed(ids []int64, digest string, pushedAt time.Time) error
 }
 
 type buildRepository struct {
@@ -72,7 +81,7 @@ func (r *buildRepository) List(page, pageSize int, repoID, appID *int64,
 	var builds []*model.Build
 	var total int64
 
-	query := r.db.Model(&model.Build{}).Preload("Repository").Preload("Application")
+	query := database.ReadReplica(r.db).Model(&model.Build{}).Preload("Repository").Preload("Application")
 
 	// 按仓库筛选
 	if repoID != nil {
@@ -173,3 +182,50 @@ func (r *buildRepository) Delete(id int64) error {
 	}
 	return nil
 }
+
+// ListByImage 查询镜像地址与 tag 对应的构建
+func (r *buildRepository) ListByImage(image, tag string) ([]*model.Build, error) {
+	var builds []*model.Build
+	err := r.db.Where("image_tag = ? AND image_url IN ?", tag, []string{image, image + ":" + tag}).
+		Order("id DESC").
+		Find(&builds).Error
+	if err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "按镜像查询构建记录失败", err)
+	}
+	return builds, nil
+}
+
+// FindLatestByImage 查询该镜像地址最近一次构建，image_url 带 tag 的记录也会匹配
+func (r *buildRepository) FindLatestByImage(image string) (*model.Build, error) {
+	var build model.Build
+	err := r.db.Where("image_url = ? OR image_url LIKE ?", image, escapeLike(image)+":%").
+		Order("id DESC").
+		First(&build).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, pkgErrors.ErrRecordNotFound
+		}
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "按镜像查询构建记录失败", err)
+	}
+	return &build, nil
+}
+
+// MarkImagePushed 记录镜像仓库确认的 digest 与推送时间
+func (r *buildRepository) MarkImagePushed(ids []int64, digest string, pushedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	updates := map[string]interface{}{"image_pushed_at": pushedAt}
+	if digest != "" {
+		updates["image_digest"] = digest
+	}
+	if err := r.db.Model(&model.Build{}).Where("id IN ?", ids).Updates(updates).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "更新构建镜像状态失败", err)
+	}
+	return nil
+}
+
+// escapeLike 转义 LIKE 通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
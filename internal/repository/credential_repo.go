@@ -60,3 +60,26 @@ func (r *CredentialRepository) List(scope string, projectID *int64) ([]*model.Cr
 	}
 	return list, nil
 }
+
+// ListForReencrypt 按 id 游标查询 key_id 与目标不一致的凭据（含已软删除记录）
+func (r *CredentialRepository) ListForReencrypt(targetKeyID string, afterID int64, limit int) ([]*model.Credential, error) {
+	var list []*model.Credential
+	if err := r.db.Unscoped().
+		Where("id > ? AND key_id <> ?", afterID, targetKeyID).
+		Order("id ASC").Limit(limit).
+		Find(&list).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询待迁移凭据失败", err)
+	}
+	return list, nil
+}
+
+// SwapEncryptedData 仅当密文未被并发修改时替换（在线迁移使用），返回是否更新成功
+func (r *CredentialRepository) SwapEncryptedData(c *model.Credential, encryptedData, keyID string) (bool, error) {
+	res := r.db.Unscoped().Model(&model.Credential{}).
+		Where("id = ? AND key_id = ? AND encrypted_data = ?", c.ID, c.KeyID, c.EncryptedData).
+		Updates(map[string]interface{}{"encrypted_data": encryptedData, "key_id": keyID})
+	if res.Error != nil {
+		return false, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "更新凭据密文失败", res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
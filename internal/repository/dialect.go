@@ -0,0 +1,39 @@
+package repository
+
+import "gorm.io/gorm"
+
+// 方言差异：MySQL 与 PostgreSQL 语法不兼容的 SQL 按 db.Dialector.Name() 分别生成
+//   - 多表更新：MySQL UPDATE ... JOIN ... SET，PostgreSQL UPDATE ... SET ... FROM ... WHERE（SET 列不能带表别名）
+//   - JSON 数组包含：MySQL JSON_CONTAINS，PostgreSQL jsonb @>
+//   - 排序 NULL 位置：MySQL DESC 时 NULL 在最后，PostgreSQL 在最前
+//   - LIKE：MySQL 默认排序规则不区分大小写，PostgreSQL 区分，需要 ILIKE
+
+const dialectPostgres = "postgres"
+
+func isPostgres(db *gorm.DB) bool {
+	return db.Dialector.Name() == dialectPostgres
+}
+
+// likeOp 不区分大小写的模糊匹配运算符
+func likeOp(db *gorm.DB) string {
+	if isPostgres(db) {
+		return "ILIKE"
+	}
+	return "LIKE"
+}
+
+// descNullsLast 降序排序且 NULL 排在最后（与 MySQL 默认行为一致）
+func descNullsLast(db *gorm.DB, column string) string {
+	if isPostgres(db) {
+		return column + " DESC NULLS LAST"
+	}
+	return column + " DESC"
+}
+
+// jsonArrayContains JSON 字符串数组列包含指定值的条件，参数为一个字符串
+func jsonArrayContains(db *gorm.DB, column string) string {
+	if isPostgres(db) {
+		return column + "::jsonb @> jsonb_build_array(?::text)"
+	}
+	return "JSON_CONTAINS(" + column + ", JSON_QUOTE(?))"
+}
@@ -0,0 +1,151 @@
+//go:build integration
+
+package repository_test
+
+import (
+	"strings"
+	"testing"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/repository"
+	"devops-cd/internal/testutil/enginetest"
+)
+
+// TestDialectSQL 方言相关 SQL（dialect.go）在每个已配置的数据库上执行
+func TestDialectSQL(t *testing.T) {
+	drivers := enginetest.Drivers()
+	if len(drivers) == 0 {
+		t.Skipf("未设置 %s 或 %s，跳过集成测试", enginetest.DSNEnv, enginetest.PostgresDSNEnv)
+	}
+	for _, driver := range drivers {
+		t.Run(driver, func(t *testing.T) {
+			h := enginetest.NewWithDriver(t, driver)
+			testSealAndSyncTags(t, h)
+			testSearchWithBuilds(t, h)
+			testCountAssignments(t, h)
+		})
+	}
+}
+
+// testSealAndSyncTags 多表更新：封板快照/固定版本，生产完成后回写 applications.deployed_tag
+func testSealAndSyncTags(t *testing.T, h *enginetest.Harness) {
+	project := h.SeedProject()
+	deployed := h.SeedApp(project.ID, nil)
+	fresh := h.SeedApp(project.ID, nil)
+	preOnly := h.SeedApp(project.ID, nil)
+	if err := h.DB.Model(deployed).Update("deployed_tag", "v0").Error; err != nil {
+		t.Fatal(err)
+	}
+	batch := h.CreateBatch(project.ID, deployed, fresh, preOnly)
+	for _, app := range []*model.Application{deployed, fresh, preOnly} {
+		build := h.SeedBuild(app, "v1-"+app.Name)
+		if err := h.DB.Model(&model.ReleaseApp{}).
+			Where("batch_id = ? AND app_id = ?", batch.ID, app.ID).
+			Updates(map[string]interface{}{"build_id": build.ID, "skip_prod_env": app.ID == preOnly.ID}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	releaseApps := repository.NewReleaseAppRepository(h.DB)
+	if err := releaseApps.SnapshotPreviousDeployedTags(batch.ID); err != nil {
+		t.Fatalf("SnapshotPreviousDeployedTags: %v", err)
+	}
+	if err := releaseApps.PinTargetTags(batch.ID); err != nil {
+		t.Fatalf("PinTargetTags: %v", err)
+	}
+	for _, app := range []*model.Application{deployed, fresh, preOnly} {
+		var ra model.ReleaseApp
+		if err := h.DB.Where("batch_id = ? AND app_id = ?", batch.ID, app.ID).First(&ra).Error; err != nil {
+			t.Fatal(err)
+		}
+		wantPrevious := ""
+		if app.ID == deployed.ID {
+			wantPrevious = "v0"
+		}
+		if ra.PreviousDeployedTag == nil || *ra.PreviousDeployedTag != wantPrevious {
+			t.Errorf("%s previous_deployed_tag = %v, want %q", app.Name, ra.PreviousDeployedTag, wantPrevious)
+		}
+		if ra.TargetTag == nil || *ra.TargetTag != "v1-"+app.Name {
+			t.Errorf("%s target_tag = %v, want %q", app.Name, ra.TargetTag, "v1-"+app.Name)
+		}
+	}
+
+	if err := repository.NewApplicationRepository(h.DB).SyncDeployedTagsByBatch(batch.ID); err != nil {
+		t.Fatalf("SyncDeployedTagsByBatch: %v", err)
+	}
+	for _, app := range []*model.Application{deployed, fresh, preOnly} {
+		var got model.Application
+		if err := h.DB.First(&got, app.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		want := "v1-" + app.Name
+		if app.ID == preOnly.ID {
+			want = "" // 跳过生产的应用不回写
+		}
+		if tag := stringValue(got.DeployedTag); tag != want {
+			t.Errorf("%s deployed_tag = %q, want %q", app.Name, tag, want)
+		}
+	}
+}
+
+// testSearchWithBuilds 关键字不区分大小写；没有待发布构建的应用排在最后
+func testSearchWithBuilds(t *testing.T, h *enginetest.Harness) {
+	project := h.SeedProject()
+	withBuild := h.SeedApp(project.ID, nil)
+	noBuild := h.SeedApp(project.ID, nil)
+	h.SeedBuild(withBuild, "v1")
+
+	apps, total, err := repository.NewApplicationRepository(h.DB).SearchWithBuilds(&dto.ApplicationSearchParam{
+		Page:      1,
+		PageSize:  10,
+		Keyword:   strings.ToUpper("app"),
+		ProjectID: &project.ID,
+	})
+	if err != nil {
+		t.Fatalf("SearchWithBuilds: %v", err)
+	}
+	if total != 2 || len(apps) != 2 {
+		t.Fatalf("SearchWithBuilds 返回 %d/%d 个应用, want 2", len(apps), total)
+	}
+	if apps[0].ID != withBuild.ID || apps[0].LatestImageTag == nil || *apps[0].LatestImageTag != "v1" {
+		t.Errorf("第一个应用应为有待发布构建的 %s, got %s (latest_image_tag=%v)", withBuild.Name, apps[0].Name, apps[0].LatestImageTag)
+	}
+	if apps[1].ID != noBuild.ID || apps[1].LatestBuildID != nil {
+		t.Errorf("没有构建的 %s 应排在最后, got %s", noBuild.Name, apps[1].Name)
+	}
+}
+
+// testCountAssignments JSON 数组包含：用户系统角色、团队成员角色、团队角色各计一次
+func testCountAssignments(t *testing.T, h *enginetest.Harness) {
+	project := h.SeedProject()
+	user := &model.User{Username: "it-role-user", Password: "x", SystemRoles: model.StringList{"it-role"}}
+	if err := h.DB.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	team := &model.Team{Name: "it-role-team", ProjectID: project.ID, Roles: model.StringList{"it-role", "it-other"}}
+	if err := h.DB.Create(team).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := h.DB.Create(&model.TeamMember{TeamID: team.ID, UserID: user.ID, Roles: model.StringList{"it-role"}}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	roles := repository.NewRoleRepository(h.DB)
+	for name, want := range map[string]int64{"it-role": 3, "it-other": 1, "it-none": 0} {
+		got, err := roles.CountAssignments(name)
+		if err != nil {
+			t.Fatalf("CountAssignments(%s): %v", name, err)
+		}
+		if got != want {
+			t.Errorf("CountAssignments(%s) = %d, want %d", name, got, want)
+		}
+	}
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
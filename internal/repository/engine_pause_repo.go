@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"devops-cd/internal/model"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"gorm.io/gorm"
+)
+
+type EnginePauseRepository struct {
+	db *gorm.DB
+}
+
+func NewEnginePauseRepository(db *gorm.DB) *EnginePauseRepository {
+	return &EnginePauseRepository{db: db}
+}
+
+func (r *EnginePauseRepository) Create(p *model.EnginePause) error {
+	if err := r.db.Create(p).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "创建暂停记录失败", err)
+	}
+	return nil
+}
+
+func (r *EnginePauseRepository) GetByID(id int64) (*model.EnginePause, error) {
+	var p model.EnginePause
+	if err := r.db.First(&p, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, pkgErrors.ErrRecordNotFound
+		}
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询暂停记录失败", err)
+	}
+	return &p, nil
+}
+
+func (r *EnginePauseRepository) Update(p *model.EnginePause) error {
+	if err := r.db.Save(p).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "更新暂停记录失败", err)
+	}
+	return nil
+}
+
+// FindActive 查询指定范围生效中的暂停记录，不存在时返回 nil
+func (r *EnginePauseRepository) FindActive(scope string, scopeID int64) (*model.EnginePause, error) {
+	var p model.EnginePause
+	if err := r.db.Scopes(model.ActiveEnginePause).
+		Where("scope = ? AND scope_id = ?", scope, scopeID).
+		Limit(1).Find(&p).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询暂停记录失败", err)
+	}
+	if p.ID == 0 {
+		return nil, nil
+	}
+	return &p, nil
+}
+
+// List 分页查询暂停记录（activeOnly=true 时仅返回生效中的记录）
+func (r *EnginePauseRepository) List(activeOnly bool, page, pageSize int) ([]*model.EnginePause, int64, error) {
+	var list []*model.EnginePause
+	var total int64
+	q := r.db.Model(&model.EnginePause{})
+	if activeOnly {
+		q = q.Scopes(model.ActiveEnginePause)
+	}
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询暂停记录失败", err)
+	}
+	if err := q.Order("id DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&list).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询暂停记录失败", err)
+	}
+	return list, total, nil
+}
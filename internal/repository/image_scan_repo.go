@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"devops-cd/internal/model"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ImageScanRepository struct {
+	db *gorm.DB
+}
+
+func NewImageScanRepository(db *gorm.DB) *ImageScanRepository {
+	return &ImageScanRepository{db: db}
+}
+
+// Upsert 写入扫描结果，同一镜像 tag 覆盖为最近一次
+func (r *ImageScanRepository) Upsert(s *model.ImageScan) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "image"}, {Name: "tag"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"digest", "source", "scanner", "status", "critical", "high", "medium", "low", "unknown",
+			"scanned_at", "updated_at",
+		}),
+	}).Create(s).Error
+	if err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "保存镜像扫描结果失败", err)
+	}
+	return nil
+}
+
+func (r *ImageScanRepository) List(image, tag string, page, pageSize int) ([]*model.ImageScan, int64, error) {
+	var list []*model.ImageScan
+	var total int64
+	q := r.db.Model(&model.ImageScan{})
+	if image != "" {
+		q = q.Where("image = ?", image)
+	}
+	if tag != "" {
+		q = q.Where("tag = ?", tag)
+	}
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询镜像扫描结果失败", err)
+	}
+	if err := q.Order("scanned_at DESC, id DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&list).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询镜像扫描结果失败", err)
+	}
+	return list, total, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"devops-cd/internal/model"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRuleRepository(db *gorm.DB) *NotificationRuleRepository {
+	return &NotificationRuleRepository{db: db}
+}
+
+func (r *NotificationRuleRepository) Create(rule *model.NotificationRule) error {
+	if err := r.db.Create(rule).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "创建通知路由规则失败", err)
+	}
+	return nil
+}
+
+func (r *NotificationRuleRepository) GetByID(id int64) (*model.NotificationRule, error) {
+	var rule model.NotificationRule
+	if err := r.db.First(&rule, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, pkgErrors.ErrRecordNotFound
+		}
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询通知路由规则失败", err)
+	}
+	return &rule, nil
+}
+
+func (r *NotificationRuleRepository) Update(rule *model.NotificationRule) error {
+	if err := r.db.Save(rule).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "更新通知路由规则失败", err)
+	}
+	return nil
+}
+
+func (r *NotificationRuleRepository) Delete(id int64) error {
+	if err := r.db.Delete(&model.NotificationRule{}, id).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "删除通知路由规则失败", err)
+	}
+	return nil
+}
+
+func (r *NotificationRuleRepository) List(projectID, teamID *int64, page, pageSize int) ([]*model.NotificationRule, int64, error) {
+	var list []*model.NotificationRule
+	var total int64
+	q := r.db.Model(&model.NotificationRule{})
+	if projectID != nil {
+		q = q.Where("project_id = ?", *projectID)
+	}
+	if teamID != nil {
+		q = q.Where("team_id = ?", *teamID)
+	}
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询通知路由规则列表失败", err)
+	}
+	if err := q.Order("id DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&list).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询通知路由规则列表失败", err)
+	}
+	return list, total, nil
+}
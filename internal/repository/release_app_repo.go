@@ -22,3 +22,39 @@ func (r *ReleaseAppRepository) Create(app *model.ReleaseApp) error {
 func (r *ReleaseAppRepository) DeleteByAppIDs(tx *gorm.DB, batchID int64, ids []int64) error {
 	return tx.Where("batch_id = ? AND app_id IN ?", batchID, ids).Delete(&model.ReleaseApp{}).Error
 }
+
+// SnapshotPreviousDeployedTags 封板时记录未锁定发布应用的部署前版本（applications.deployed_tag）
+func (r *ReleaseAppRepository) SnapshotPreviousDeployedTags(batchID int64) error {
+	if isPostgres(r.db) {
+		return r.db.Exec(`
+			UPDATE release_apps ra
+			SET previous_deployed_tag = COALESCE(a.deployed_tag, '')
+			FROM applications a
+			WHERE ra.app_id = a.id AND ra.batch_id = ? AND ra.is_locked = false
+		`, batchID).Error
+	}
+	return r.db.Exec(`
+		UPDATE release_apps ra
+		JOIN applications a ON ra.app_id = a.id
+		SET ra.previous_deployed_tag = COALESCE(a.deployed_tag, '')
+		WHERE ra.batch_id = ? AND ra.is_locked = false
+	`, batchID).Error
+}
+
+// PinTargetTags 封板时将发布应用的目标版本固定为所选构建的镜像 tag
+func (r *ReleaseAppRepository) PinTargetTags(batchID int64) error {
+	if isPostgres(r.db) {
+		return r.db.Exec(`
+			UPDATE release_apps ra
+			SET target_tag = b.image_tag
+			FROM builds b
+			WHERE ra.build_id = b.id AND ra.batch_id = ? AND ra.build_id IS NOT NULL
+		`, batchID).Error
+	}
+	return r.db.Exec(`
+		UPDATE release_apps ra
+		JOIN builds b ON ra.build_id = b.id
+		SET ra.target_tag = b.image_tag
+		WHERE ra.batch_id = ? AND ra.build_id IS NOT NULL
+	`, batchID).Error
+}
@@ -16,6 +16,10 @@ type RepoSyncSourceRepository interface {
 	List(page, pageSize int, keyword, platform, baseURL, namespace string, enabled *bool) ([]*model.RepoSource, int64, error)
 	ListEnabled() ([]*model.RepoSource, error)
 	UpdateSyncResult(id int64, status string, message *string) error
+	// ListForReencrypt 按 id 游标查询 token key_id 与目标不一致的仓库源（含已软删除记录）
+	ListForReencrypt(targetKeyID string, afterID int64, limit int) ([]*model.RepoSource, error)
+	// SwapAuthToken 仅当 token 密文未被并发修改时替换（在线迁移使用），返回是否更新成功
+	SwapAuthToken(source *model.RepoSource, authTokenEnc, keyID string) (bool, error)
 }
 
 type repoSyncSourceRepository struct {
@@ -120,3 +124,24 @@ func (r *repoSyncSourceRepository) UpdateSyncResult(id int64, status string, mes
 	}
 	return nil
 }
+
+func (r *repoSyncSourceRepository) ListForReencrypt(targetKeyID string, afterID int64, limit int) ([]*model.RepoSource, error) {
+	var list []*model.RepoSource
+	if err := r.db.Unscoped().
+		Where("id > ? AND auth_token_key_id <> ?", afterID, targetKeyID).
+		Order("id ASC").Limit(limit).
+		Find(&list).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询待迁移仓库源失败", err)
+	}
+	return list, nil
+}
+
+func (r *repoSyncSourceRepository) SwapAuthToken(source *model.RepoSource, authTokenEnc, keyID string) (bool, error) {
+	res := r.db.Unscoped().Model(&model.RepoSource{}).
+		Where("id = ? AND auth_token_key_id = ? AND auth_token_enc = ?", source.ID, source.AuthTokenKeyID, source.AuthTokenEnc).
+		Updates(map[string]interface{}{"auth_token_enc": authTokenEnc, "auth_token_key_id": keyID})
+	if res.Error != nil {
+		return false, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "更新仓库源 token 失败", res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"devops-cd/internal/model"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"gorm.io/gorm"
+)
+
+type RoleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+// Create 创建角色及权限绑定
+func (r *RoleRepository) Create(role *model.Role) error {
+	if err := r.db.Create(role).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "创建角色失败", err)
+	}
+	return nil
+}
+
+func (r *RoleRepository) GetByID(id int64) (*model.Role, error) {
+	var role model.Role
+	if err := r.db.Preload("Permissions").First(&role, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, pkgErrors.ErrRecordNotFound
+		}
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询角色失败", err)
+	}
+	return &role, nil
+}
+
+func (r *RoleRepository) ExistsByName(name string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.Role{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		return false, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询角色失败", err)
+	}
+	return count > 0, nil
+}
+
+// Update 更新角色基本信息并整体替换权限绑定
+func (r *RoleRepository) Update(role *model.Role) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Permissions").Save(role).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", role.ID).Delete(&model.RolePermission{}).Error; err != nil {
+			return err
+		}
+		for i := range role.Permissions {
+			role.Permissions[i].ID = 0
+			role.Permissions[i].RoleID = role.ID
+		}
+		if len(role.Permissions) == 0 {
+			return nil
+		}
+		return tx.Create(&role.Permissions).Error
+	})
+	if err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "更新角色失败", err)
+	}
+	return nil
+}
+
+// Delete 删除角色及权限绑定
+func (r *RoleRepository) Delete(id int64) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", id).Delete(&model.RolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Role{}, id).Error
+	})
+	if err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "删除角色失败", err)
+	}
+	return nil
+}
+
+// ListAll 所有自定义角色（含权限绑定）
+func (r *RoleRepository) ListAll() ([]*model.Role, error) {
+	var list []*model.Role
+	if err := r.db.Preload("Permissions").Order("name").Find(&list).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询角色列表失败", err)
+	}
+	return list, nil
+}
+
+// CountAssignments 角色被分配的次数（用户系统角色、团队成员角色、团队角色）
+func (r *RoleRepository) CountAssignments(name string) (int64, error) {
+	var total int64
+	for _, q := range []struct {
+		table  string
+		column string
+	}{
+		{model.UserTableName, "system_roles"},
+		{model.TeamMemberTableName, "roles"},
+		{model.TeamTableName, "roles"},
+	} {
+		var count int64
+		if err := r.db.Table(q.table).Where(jsonArrayContains(r.db, q.column), name).Count(&count).Error; err != nil {
+			return 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询角色分配失败", err)
+		}
+		total += count
+	}
+	return total, nil
+}
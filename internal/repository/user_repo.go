@@ -40,7 +40,7 @@ func (r *UserRepository) FindByUsername(username, authProvider string) (*model.U
 
 func (r *UserRepository) FindWithTeams(username, authProvider string) (*model.User, error) {
 	var user model.User
-	if err := r.queryByUsername(username, authProvider).Preload("TeamMembers").First(&user).Error; err != nil {
+	if err := r.queryByUsername(username, authProvider).Preload("TeamMembers.Team").First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, pkgErrors.ErrRecordNotFound
 		}
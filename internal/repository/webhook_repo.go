@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"time"
+
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"gorm.io/gorm"
+)
+
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) Create(w *model.Webhook) error {
+	if err := r.db.Create(w).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "创建 Webhook 失败", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) GetByID(id int64) (*model.Webhook, error) {
+	var w model.Webhook
+	if err := r.db.First(&w, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, pkgErrors.ErrRecordNotFound
+		}
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询 Webhook 失败", err)
+	}
+	return &w, nil
+}
+
+func (r *WebhookRepository) Update(w *model.Webhook) error {
+	if err := r.db.Save(w).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "更新 Webhook 失败", err)
+	}
+	return nil
+}
+
+// ListSecretsForReencrypt 按 id 游标查询签名密钥 key_id 与目标不一致的订阅
+func (r *WebhookRepository) ListSecretsForReencrypt(targetKeyID string, afterID int64, limit int) ([]*model.Webhook, error) {
+	var list []*model.Webhook
+	if err := r.db.
+		Where("id > ? AND encrypted_secret IS NOT NULL AND (secret_key_id IS NULL OR secret_key_id <> ?)", afterID, targetKeyID).
+		Order("id ASC").Limit(limit).
+		Find(&list).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询待迁移 Webhook 签名密钥失败", err)
+	}
+	return list, nil
+}
+
+// SwapSecret 仅当签名密钥未被并发修改时替换（在线迁移使用），返回是否更新成功
+func (r *WebhookRepository) SwapSecret(w *model.Webhook, encryptedSecret, keyID string) (bool, error) {
+	q := r.db.Model(&model.Webhook{}).Where("id = ? AND encrypted_secret = ?", w.ID, *w.EncryptedSecret)
+	if w.SecretKeyID == "" {
+		q = q.Where("(secret_key_id IS NULL OR secret_key_id = '')")
+	} else {
+		q = q.Where("secret_key_id = ?", w.SecretKeyID)
+	}
+	res := q.Updates(map[string]interface{}{"encrypted_secret": encryptedSecret, "secret_key_id": keyID})
+	if res.Error != nil {
+		return false, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "更新 Webhook 签名密钥失败", res.Error)
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// Delete 删除订阅及其投递记录
+func (r *WebhookRepository) Delete(id int64) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&model.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Webhook{}, id).Error
+	})
+	if err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "删除 Webhook 失败", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) List(projectID *int64, page, pageSize int) ([]*model.Webhook, int64, error) {
+	var list []*model.Webhook
+	var total int64
+	q := r.db.Model(&model.Webhook{})
+	if projectID != nil {
+		q = q.Where("project_id = ?", *projectID)
+	}
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询 Webhook 列表失败", err)
+	}
+	if err := q.Order("id DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&list).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询 Webhook 列表失败", err)
+	}
+	return list, total, nil
+}
+
+func (r *WebhookRepository) CreateDelivery(d *model.WebhookDelivery) error {
+	if err := r.db.Create(d).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "创建投递记录失败", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) GetDelivery(webhookID, id int64) (*model.WebhookDelivery, error) {
+	var d model.WebhookDelivery
+	if err := r.db.Where("webhook_id = ?", webhookID).First(&d, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, pkgErrors.ErrRecordNotFound
+		}
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询投递记录失败", err)
+	}
+	return &d, nil
+}
+
+func (r *WebhookRepository) ListDeliveries(webhookID int64, status, event string, page, pageSize int) ([]*model.WebhookDelivery, int64, error) {
+	var list []*model.WebhookDelivery
+	var total int64
+	q := r.db.Model(&model.WebhookDelivery{}).Where("webhook_id = ?", webhookID)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if event != "" {
+		q = q.Where("event = ?", event)
+	}
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询投递记录失败", err)
+	}
+	if err := q.Order("id DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&list).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询投递记录失败", err)
+	}
+	return list, total, nil
+}
+
+// ResetDelivery 重新投递：清零次数并立即进入待投递
+func (r *WebhookRepository) ResetDelivery(d *model.WebhookDelivery) error {
+	now := time.Now()
+	d.Status = constants.WebhookDeliveryPending
+	d.Attempts = 0
+	d.NextAttemptAt = &now
+	d.Error = ""
+	if err := r.db.Save(d).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "更新投递记录失败", err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"devops-cd/internal/model"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"gorm.io/gorm"
+)
+
+type WebhookSourceRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookSourceRepository(db *gorm.DB) *WebhookSourceRepository {
+	return &WebhookSourceRepository{db: db}
+}
+
+func (r *WebhookSourceRepository) Create(s *model.WebhookSource) error {
+	if err := r.db.Create(s).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "创建构建通知来源失败", err)
+	}
+	return nil
+}
+
+func (r *WebhookSourceRepository) GetByID(id int64) (*model.WebhookSource, error) {
+	var s model.WebhookSource
+	if err := r.db.First(&s, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, pkgErrors.ErrRecordNotFound
+		}
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询构建通知来源失败", err)
+	}
+	return &s, nil
+}
+
+func (r *WebhookSourceRepository) GetByName(name string) (*model.WebhookSource, error) {
+	var s model.WebhookSource
+	if err := r.db.Where("name = ?", name).First(&s).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, pkgErrors.ErrRecordNotFound
+		}
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询构建通知来源失败", err)
+	}
+	return &s, nil
+}
+
+func (r *WebhookSourceRepository) ExistsByName(name string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.WebhookSource{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		return false, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询构建通知来源失败", err)
+	}
+	return count > 0, nil
+}
+
+func (r *WebhookSourceRepository) Update(s *model.WebhookSource) error {
+	if err := r.db.Save(s).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "更新构建通知来源失败", err)
+	}
+	return nil
+}
+
+func (r *WebhookSourceRepository) Delete(id int64) error {
+	if err := r.db.Delete(&model.WebhookSource{}, id).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "删除构建通知来源失败", err)
+	}
+	return nil
+}
+
+func (r *WebhookSourceRepository) List(page, pageSize int) ([]*model.WebhookSource, int64, error) {
+	var list []*model.WebhookSource
+	var total int64
+	q := r.db.Model(&model.WebhookSource{})
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询构建通知来源列表失败", err)
+	}
+	if err := q.Order("id DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&list).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询构建通知来源列表失败", err)
+	}
+	return list, total, nil
+}
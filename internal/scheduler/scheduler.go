@@ -1,7 +1,12 @@
 package scheduler
 
 import (
+	"context"
+	"time"
+
+	"devops-cd/internal/dto"
 	"devops-cd/internal/pkg/config"
+	"devops-cd/internal/pkg/leader"
 	"devops-cd/internal/service"
 	"github.com/robfig/cron/v3"
 	"go.uber.org/zap"
@@ -13,18 +18,36 @@ type Scheduler struct {
 	cron          *cron.Cron
 	logger        *zap.Logger
 	repoSyncSvc   *service.RepoSyncService
+	consistency   service.ConsistencyService
+	batchSvc      *service.BatchService
+	batchEvents   service.BatchEventProcessor
+	driftChecker  service.ReleaseDriftChecker
+	idempotency   *service.IdempotencyService
+	elector       leader.Leader           // 多副本部署时只有主节点执行任务，为 nil 时始终执行
 	cronSchedules map[string]cron.EntryID // 存储任务ID，便于管理
 }
 
-// NewScheduler 创建调度器
-func NewScheduler(db *gorm.DB, logger *zap.Logger, cfg *config.Config) *Scheduler {
+// batchScheduleCron 定时部署检查频率（每 30 秒）
+const batchScheduleCron = "*/30 * * * * *"
+
+// idempotencyPurgeCron 过期幂等记录清理频率（每小时）
+const idempotencyPurgeCron = "0 0 * * * *"
+
+// NewScheduler 创建调度器；batchEvents 用于定时部署到点后触发批次状态流转，driftChecker 执行配置漂移检测，elector 为 nil 表示单实例部署
+func NewScheduler(db *gorm.DB, logger *zap.Logger, cfg *config.Config, batchEvents service.BatchEventProcessor, driftChecker service.ReleaseDriftChecker, elector leader.Leader) *Scheduler {
 	// 创建 cron 实例（带秒级支持）
 	c := cron.New(cron.WithSeconds())
 
 	return &Scheduler{
 		cron:          c,
 		logger:        logger,
-		repoSyncSvc:   service.NewRepoSyncService(db, logger, cfg.Crypto.AESKey),
+		repoSyncSvc:   service.NewRepoSyncService(db, logger),
+		consistency:   service.NewConsistencyService(db),
+		batchSvc:      service.NewBatchService(db),
+		batchEvents:   batchEvents,
+		driftChecker:  driftChecker,
+		elector:       elector,
+		idempotency:   service.NewIdempotencyService(db, time.Duration(cfg.Server.IdempotencyTTL)*time.Second),
 		cronSchedules: make(map[string]cron.EntryID),
 	}
 }
@@ -43,12 +66,12 @@ func (s *Scheduler) Start(cfg *config.Config) error {
 		log.Warn("未配置repo.cron，使用默认值", zap.String("cron", cronExpr))
 	}
 
-	entryID, err := s.cron.AddFunc(cronExpr, func() {
+	entryID, err := s.cron.AddFunc(cronExpr, s.leaderOnly(func() {
 		log.Info("执行定时任务: 代码库同步")
 		if err := s.repoSyncSvc.SyncAllSources(); err != nil {
 			log.Errorf("代码库同步任务执行失败: %v", err)
 		}
-	})
+	}))
 
 	if err != nil {
 		log.Errorf("注册代码库: %v 同步任务失败: %v", cronExpr, err)
@@ -58,6 +81,51 @@ func (s *Scheduler) Start(cfg *config.Config) error {
 	s.cronSchedules["repo_sync"] = entryID
 	log.Infof("代码库同步任务已注册: %s entry_id=%d", cronExpr, entryID)
 
+	// 数据一致性检查（未配置 cron 时不启用）
+	if cfg.Consistency.Cron != "" {
+		autoRepair := cfg.Consistency.AutoRepair
+		entryID, err := s.cron.AddFunc(cfg.Consistency.Cron, s.leaderOnly(func() {
+			s.runConsistencyCheck(autoRepair)
+		}))
+		if err != nil {
+			log.Errorf("注册一致性检查: %v 任务失败: %v", cfg.Consistency.Cron, err)
+			return err
+		}
+		s.cronSchedules["consistency_check"] = entryID
+		log.Infof("一致性检查任务已注册: %s entry_id=%d auto_repair=%v", cfg.Consistency.Cron, entryID, autoRepair)
+	}
+
+	// 批次定时部署：到达计划时间后触发 start_pre_deploy / start_prod_deploy
+	if s.batchEvents != nil {
+		entryID, err := s.cron.AddFunc(batchScheduleCron, s.leaderOnly(s.runBatchSchedules))
+		if err != nil {
+			log.Errorf("注册批次定时部署任务失败: %v", err)
+			return err
+		}
+		s.cronSchedules["batch_schedule"] = entryID
+		log.Infof("批次定时部署任务已注册: %s entry_id=%d", batchScheduleCron, entryID)
+	}
+
+	// 配置漂移检测（未配置 core.drift_check.cron 时不启用）
+	if driftCron := cfg.Core.DriftCheck.Cron; driftCron != "" && s.driftChecker != nil {
+		entryID, err := s.cron.AddFunc(driftCron, s.leaderOnly(s.driftChecker.CheckReleaseDrifts))
+		if err != nil {
+			log.Errorf("注册配置漂移检测: %v 任务失败: %v", driftCron, err)
+			return err
+		}
+		s.cronSchedules["release_drift"] = entryID
+		log.Infof("配置漂移检测任务已注册: %s entry_id=%d live_objects=%v", driftCron, entryID, cfg.Core.DriftCheck.LiveObjects)
+	}
+
+	// 过期幂等记录清理（Idempotency-Key）
+	entryID, err = s.cron.AddFunc(idempotencyPurgeCron, s.leaderOnly(s.purgeIdempotencyKeys))
+	if err != nil {
+		log.Errorf("注册幂等记录清理任务失败: %v", err)
+		return err
+	}
+	s.cronSchedules["idempotency_purge"] = entryID
+	log.Infof("幂等记录清理任务已注册: %s entry_id=%d", idempotencyPurgeCron, entryID)
+
 	// 启动 cron
 	s.cron.Start()
 	log.Info("定时任务调度器启动成功")
@@ -76,8 +144,69 @@ func (s *Scheduler) Stop() {
 	s.logger.Info("定时任务调度器已停止")
 }
 
+// leaderOnly 非主节点副本跳过任务
+func (s *Scheduler) leaderOnly(job func()) func() {
+	return func() {
+		if leader.IsLeader(s.elector) {
+			job()
+		}
+	}
+}
+
 // TriggerRepoSync 手动触发代码库同步（用于测试或手动触发）
 func (s *Scheduler) TriggerRepoSync() error {
 	s.logger.Info("手动触发代码库同步")
 	return s.repoSyncSvc.SyncAllSources()
 }
+
+// runConsistencyCheck 执行一致性检查，并按配置自动修复
+func (s *Scheduler) runConsistencyCheck(autoRepair []string) {
+	log := s.logger.Sugar()
+	log.Info("执行定时任务: 数据一致性检查")
+
+	ctx := context.Background()
+	report, err := s.consistency.Check(ctx)
+	if err != nil {
+		log.Errorf("数据一致性检查失败: %v", err)
+		return
+	}
+	if report.Total == 0 {
+		log.Info("数据一致性检查完成，未发现问题")
+		return
+	}
+	log.Warnf("数据一致性检查发现 %d 条问题: %v", report.Total, report.Counts)
+
+	if len(autoRepair) == 0 {
+		return
+	}
+	dryRun := false
+	result, err := s.consistency.Repair(ctx, &dto.ConsistencyRepairRequest{Checks: autoRepair, DryRun: &dryRun}, "scheduler")
+	if err != nil {
+		log.Errorf("数据一致性自动修复失败: %v", err)
+		return
+	}
+	log.Infof("数据一致性自动修复完成: %v", result.Repaired)
+}
+
+// runBatchSchedules 触发已到计划时间的批次定时部署
+func (s *Scheduler) runBatchSchedules() {
+	fired, err := s.batchSvc.FireDueBatchSchedules(s.batchEvents)
+	if err != nil {
+		s.logger.Sugar().Errorf("批次定时部署检查失败: %v", err)
+	}
+	if fired > 0 {
+		s.logger.Sugar().Infof("批次定时部署已触发 %d 个", fired)
+	}
+}
+
+// purgeIdempotencyKeys 清理过期的幂等记录
+func (s *Scheduler) purgeIdempotencyKeys() {
+	purged, err := s.idempotency.PurgeExpired()
+	if err != nil {
+		s.logger.Sugar().Errorf("清理过期幂等记录失败: %v", err)
+		return
+	}
+	if purged > 0 {
+		s.logger.Sugar().Infof("已清理过期幂等记录 %d 条", purged)
+	}
+}
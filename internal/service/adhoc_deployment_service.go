@@ -0,0 +1,247 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AdhocDeploymentService 临时部署：不组建批次，直接将应用的指定构建部署到 pre/prod 的指定集群（紧急修复）
+//
+// 只创建临时部署记录与各集群的 Deployment，由核心引擎按 Deployment 状态机推进并汇总结果
+type AdhocDeploymentService struct {
+	db *gorm.DB
+}
+
+func NewAdhocDeploymentService(db *gorm.DB) *AdhocDeploymentService {
+	return &AdhocDeploymentService{db: db}
+}
+
+// Create 发起临时部署，canAccess 按目标环境校验应用所属项目的权限
+//
+// 集群必须是应用在该环境启用的集群，且没有进行中的部署；项目开启生产部署双人原则时不允许生产临时部署
+func (s *AdhocDeploymentService) Create(req *dto.CreateAdhocDeploymentRequest, operator string, canAccess func(projectID int64, env string) bool) (*dto.AdhocDeploymentResponse, error) {
+	var app model.Application
+	if err := s.db.Preload("Project").Limit(1).Find(&app, req.AppID).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用失败", err)
+	}
+	if app.ID == 0 {
+		return nil, pkgErrors.New(pkgErrors.CodeNotFound, "应用不存在")
+	}
+	if !canAccess(app.ProjectID, req.Env) {
+		return nil, pkgErrors.ErrForbidden
+	}
+	if req.Env == constants.EnvTypeProd && app.Project != nil && app.Project.ProdTwoPersonRule {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "项目已开启生产部署双人原则，不支持生产临时部署，请通过批次发布")
+	}
+
+	build, err := s.findBuild(app.ID, req.BuildID, req.ImageTag)
+	if err != nil {
+		return nil, err
+	}
+	clusters, err := s.resolveClusters(app.ID, req.Env, req.Clusters)
+	if err != nil {
+		return nil, err
+	}
+
+	adhoc := &model.AdhocDeployment{
+		ProjectID: app.ProjectID,
+		AppID:     app.ID,
+		Env:       req.Env,
+		BuildID:   build.ID,
+		ImageTag:  build.ImageTag,
+		Clusters:  model.StringList(clusters),
+		Status:    constants.AdhocDeploymentStatusRunning,
+		Reason:    req.Reason,
+		CreatedBy: operator,
+	}
+	var deps []*model.Deployment
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// 同一应用/环境/集群存在进行中的部署（批次或其他临时部署）时不允许发起
+		var busy []string
+		if err := tx.Model(&model.Deployment{}).
+			Where("app_id = ? AND env = ? AND cluster IN ? AND superseded_by IS NULL AND status IN ?",
+				app.ID, req.Env, clusters, []string{constants.DeploymentStatusPending, constants.DeploymentStatusRunning}).
+			Distinct().Pluck("cluster", &busy).Error; err != nil {
+			return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询进行中的部署失败", err)
+		}
+		if len(busy) > 0 {
+			return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("集群 %s 存在进行中的部署，请等待完成后再发起", strings.Join(busy, ", ")))
+		}
+
+		if err := tx.Create(adhoc).Error; err != nil {
+			return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "创建临时部署失败", err)
+		}
+		msg := fmt.Sprintf("临时部署(%d): %s 原因: %s", adhoc.ID, build.ImageTag, req.Reason)
+		for _, cluster := range clusters {
+			dep := &model.Deployment{
+				AppID:   app.ID,
+				AdhocID: &adhoc.ID,
+
+				Kind:        constants.DeploymentKindApp,
+				Env:         req.Env,
+				ClusterName: cluster,
+
+				// namespace/deployment_name 由 deployment 层在 Pending 阶段计算并回填
+				Namespace:      "default",
+				DeploymentName: app.Name,
+
+				Status:       constants.DeploymentStatusPending,
+				RolloutStage: 1,
+			}
+			if err := tx.Create(dep).Error; err != nil {
+				return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "创建 Deployment 失败", err)
+			}
+			event := model.NewDeploymentEvent(dep, constants.DeploymentEventAdhoc, msg)
+			event.Operator = &operator
+			if err := tx.Create(event).Error; err != nil {
+				return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "记录部署事件失败", err)
+			}
+			deps = append(deps, dep)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Warn("发起临时部署",
+		zap.Int64("adhoc_id", adhoc.ID),
+		zap.Int64("app_id", app.ID),
+		zap.String("env", req.Env),
+		zap.Strings("clusters", clusters),
+		zap.String("image_tag", build.ImageTag),
+		zap.String("operator", operator),
+		zap.String("reason", req.Reason))
+	return toAdhocDeploymentResponse(adhoc, app.Name, deps), nil
+}
+
+// Get 临时部署详情（含各集群 Deployment）
+func (s *AdhocDeploymentService) Get(id int64, canAccess func(projectID int64) bool) (*dto.AdhocDeploymentResponse, error) {
+	var adhoc model.AdhocDeployment
+	if err := s.db.Preload("Application").Limit(1).Find(&adhoc, id).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询临时部署失败", err)
+	}
+	if adhoc.ID == 0 {
+		return nil, pkgErrors.New(pkgErrors.CodeNotFound, "临时部署不存在")
+	}
+	if !canAccess(adhoc.ProjectID) {
+		return nil, pkgErrors.ErrForbidden
+	}
+	var deps []*model.Deployment
+	if err := s.db.Where("adhoc_id = ?", adhoc.ID).Order("id").Find(&deps).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询 Deployment 失败", err)
+	}
+	return toAdhocDeploymentResponse(&adhoc, adhocAppName(&adhoc), deps), nil
+}
+
+// List 项目的临时部署列表
+func (s *AdhocDeploymentService) List(req *dto.AdhocDeploymentListRequest, canAccess func(projectID int64) bool) ([]*dto.AdhocDeploymentResponse, int64, error) {
+	if !canAccess(req.ProjectID) {
+		return nil, 0, pkgErrors.ErrForbidden
+	}
+	q := s.db.Model(&model.AdhocDeployment{}).Where("project_id = ?", req.ProjectID)
+	if req.AppID > 0 {
+		q = q.Where("app_id = ?", req.AppID)
+	}
+	if req.Env != "" {
+		q = q.Where("env = ?", req.Env)
+	}
+	if req.Status != "" {
+		q = q.Where("status = ?", req.Status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询临时部署失败", err)
+	}
+	var list []*model.AdhocDeployment
+	if err := q.Preload("Application").Order("id DESC").Limit(req.PageSize).Offset((req.Page - 1) * req.PageSize).Find(&list).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询临时部署失败", err)
+	}
+	return lo.Map(list, func(a *model.AdhocDeployment, _ int) *dto.AdhocDeploymentResponse {
+		return toAdhocDeploymentResponse(a, adhocAppName(a), nil)
+	}), total, nil
+}
+
+// findBuild 按 build_id 或 image_tag 查询应用的成功构建（排除来源校验不一致的构建）
+func (s *AdhocDeploymentService) findBuild(appID, buildID int64, imageTag string) (*model.Build, error) {
+	imageTag = strings.TrimSpace(imageTag)
+	q := s.db.Scopes(model.TrustedBuilds).Where("app_id = ? AND build_status = ?", appID, constants.BuildStatusSuccess)
+	switch {
+	case buildID > 0:
+		q = q.Where("id = ?", buildID)
+	case imageTag != "":
+		q = q.Where("image_tag = ?", imageTag)
+	default:
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "build_id 与 image_tag 需指定其一")
+	}
+	var build model.Build
+	if err := q.Order("id DESC").Limit(1).Find(&build).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询构建失败", err)
+	}
+	if build.ID == 0 {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "未找到该应用可部署的成功构建")
+	}
+	return &build, nil
+}
+
+// resolveClusters 校验集群为应用在 env 启用的集群，未指定时返回全部启用集群
+func (s *AdhocDeploymentService) resolveClusters(appID int64, env string, clusters []string) ([]string, error) {
+	var enabled []string
+	if err := s.db.Model(&model.AppEnvConfig{}).Where("app_id = ? AND env = ? AND status = 1", appID, env).
+		Order("id").Pluck("cluster", &enabled).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用环境配置失败", err)
+	}
+	if len(enabled) == 0 {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("应用未配置 %s 环境集群", env))
+	}
+
+	clusters = lo.Uniq(lo.Compact(lo.Map(clusters, func(c string, _ int) string { return strings.TrimSpace(c) })))
+	if len(clusters) == 0 {
+		return enabled, nil
+	}
+	if invalid, _ := lo.Difference(clusters, enabled); len(invalid) > 0 {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest,
+			fmt.Sprintf("集群 %s 未在应用 %s 环境启用（可选: %s）", strings.Join(invalid, ", "), env, strings.Join(enabled, ", ")))
+	}
+	return clusters, nil
+}
+
+func adhocAppName(a *model.AdhocDeployment) string {
+	if a.Application == nil {
+		return ""
+	}
+	return a.Application.Name
+}
+
+func toAdhocDeploymentResponse(a *model.AdhocDeployment, appName string, deps []*model.Deployment) *dto.AdhocDeploymentResponse {
+	return &dto.AdhocDeploymentResponse{
+		ID:           a.ID,
+		ProjectID:    a.ProjectID,
+		AppID:        a.AppID,
+		AppName:      appName,
+		Env:          a.Env,
+		BuildID:      a.BuildID,
+		ImageTag:     a.ImageTag,
+		Clusters:     a.Clusters,
+		Status:       a.Status,
+		Reason:       a.Reason,
+		ErrorMessage: a.ErrorMessage,
+		CreatedBy:    a.CreatedBy,
+		CreatedAt:    a.CreatedAt.Format(time.RFC3339),
+		FinishedAt:   dto.FormatTime(a.FinishedAt),
+		Deployments: lo.Map(deps, func(dep *model.Deployment, _ int) dto.DeploymentResponse {
+			return toDeploymentResponse(dep)
+		}),
+	}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/repository"
+	pkgErrors "devops-cd/pkg/responses"
+	"time"
+)
+
+type AnnouncementService interface {
+	Create(req *dto.CreateAnnouncementRequest, operator string) (*dto.AnnouncementResponse, error)
+	Update(id int64, req *dto.UpdateAnnouncementRequest, operator string) (*dto.AnnouncementResponse, error)
+	Delete(id int64) error
+	List(req *dto.AnnouncementListRequest) ([]*dto.AnnouncementResponse, int64, error)
+	// ListActive 当前生效的公告（用于 UI banner、批次创建响应）
+	ListActive() ([]*dto.AnnouncementResponse, error)
+}
+
+type announcementService struct {
+	repo *repository.AnnouncementRepository
+}
+
+func NewAnnouncementService(repo *repository.AnnouncementRepository) AnnouncementService {
+	return &announcementService{repo: repo}
+}
+
+func (s *announcementService) Create(req *dto.CreateAnnouncementRequest, operator string) (*dto.AnnouncementResponse, error) {
+	if err := checkAnnouncementWindow(req.StartsAt, req.EndsAt); err != nil {
+		return nil, err
+	}
+	a := &model.Announcement{
+		Level:     req.Level,
+		Title:     req.Title,
+		Content:   req.Content,
+		Enabled:   true,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		CreatedBy: operator,
+		UpdatedBy: operator,
+	}
+	if req.Enabled != nil {
+		a.Enabled = *req.Enabled
+	}
+	if err := s.repo.Create(a); err != nil {
+		return nil, err
+	}
+	return toAnnouncementResponse(a), nil
+}
+
+func (s *announcementService) Update(id int64, req *dto.UpdateAnnouncementRequest, operator string) (*dto.AnnouncementResponse, error) {
+	a, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Level != nil {
+		a.Level = *req.Level
+	}
+	if req.Title != nil {
+		a.Title = *req.Title
+	}
+	if req.Content != nil {
+		a.Content = *req.Content
+	}
+	if req.Enabled != nil {
+		a.Enabled = *req.Enabled
+	}
+	if req.StartsAt != nil {
+		a.StartsAt = req.StartsAt
+	}
+	if req.EndsAt != nil {
+		a.EndsAt = req.EndsAt
+	}
+	if err := checkAnnouncementWindow(a.StartsAt, a.EndsAt); err != nil {
+		return nil, err
+	}
+	a.UpdatedBy = operator
+	if err := s.repo.Update(a); err != nil {
+		return nil, err
+	}
+	return toAnnouncementResponse(a), nil
+}
+
+func (s *announcementService) Delete(id int64) error {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *announcementService) List(req *dto.AnnouncementListRequest) ([]*dto.AnnouncementResponse, int64, error) {
+	list, total, err := s.repo.List(req.Page, req.PageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	out := make([]*dto.AnnouncementResponse, 0, len(list))
+	for _, a := range list {
+		out = append(out, toAnnouncementResponse(a))
+	}
+	return out, total, nil
+}
+
+func (s *announcementService) ListActive() ([]*dto.AnnouncementResponse, error) {
+	list, err := s.repo.ListActive(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*dto.AnnouncementResponse, 0, len(list))
+	for _, a := range list {
+		out = append(out, toAnnouncementResponse(a))
+	}
+	return out, nil
+}
+
+func checkAnnouncementWindow(startsAt, endsAt *time.Time) error {
+	if startsAt != nil && endsAt != nil && !endsAt.After(*startsAt) {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, "ends_at 必须晚于 starts_at")
+	}
+	return nil
+}
+
+func toAnnouncementResponse(a *model.Announcement) *dto.AnnouncementResponse {
+	return &dto.AnnouncementResponse{
+		ID:        a.ID,
+		Level:     a.Level,
+		Title:     a.Title,
+		Content:   a.Content,
+		Enabled:   a.Enabled,
+		StartsAt:  a.StartsAt,
+		EndsAt:    a.EndsAt,
+		CreatedBy: a.CreatedBy,
+		CreatedAt: a.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt: a.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/auth"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/internal/repository"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+)
+
+const (
+	// apiTokenBytes 明文 token 随机部分字节数
+	apiTokenBytes = 32
+	// apiTokenTouchInterval 最近使用时间的最小更新间隔，避免每个请求都写库
+	apiTokenTouchInterval = time.Minute
+)
+
+// API Token 状态
+const (
+	APITokenStatusActive  = "active"
+	APITokenStatusExpired = "expired"
+	APITokenStatusRevoked = "revoked"
+)
+
+type APITokenService interface {
+	Create(req *dto.CreateAPITokenRequest, operator string) (*dto.CreateAPITokenResponse, error)
+	Revoke(id int64, operator string) (*dto.APITokenResponse, error)
+	List(req *dto.APITokenListRequest) ([]*dto.APITokenResponse, int64, error)
+	// Authenticate 校验明文 token（AuthMiddleware 使用），并记录最近使用
+	Authenticate(token, clientIP string) (*model.APIToken, error)
+}
+
+type apiTokenService struct {
+	repo        *repository.APITokenRepository
+	projectRepo repository.ProjectRepository
+}
+
+func NewAPITokenService(repo *repository.APITokenRepository, projectRepo repository.ProjectRepository) APITokenService {
+	return &apiTokenService{repo: repo, projectRepo: projectRepo}
+}
+
+func (s *apiTokenService) Create(req *dto.CreateAPITokenRequest, operator string) (*dto.CreateAPITokenResponse, error) {
+	token := &model.APIToken{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Scopes:      model.StringList(lo.Uniq(req.Scopes)),
+		ProjectIDs:  model.Int64List(lo.Uniq(req.ProjectIDs)),
+		CreatedBy:   operator,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+	if err := s.check(token); err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, apiTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeInternalError, "生成 API Token 失败", err)
+	}
+	plain := constants.APITokenPrefix + hex.EncodeToString(raw)
+	token.TokenHash = hashAPIToken(plain)
+	token.TokenPrefix = plain[:len(constants.APITokenPrefix)+8]
+
+	if err := s.repo.Create(token); err != nil {
+		return nil, err
+	}
+	logger.Info("API Token 已创建", zap.Int64("id", token.ID), zap.String("name", token.Name),
+		zap.Strings("scopes", token.Scopes), zap.String("operator", operator))
+	return &dto.CreateAPITokenResponse{APITokenResponse: *toAPITokenResponse(token), Token: plain}, nil
+}
+
+func (s *apiTokenService) Revoke(id int64, operator string) (*dto.APITokenResponse, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, err
+	}
+	revoked, err := s.repo.Revoke(id, operator, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		logger.Info("API Token 已吊销", zap.Int64("id", id), zap.String("operator", operator))
+	}
+	token, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return toAPITokenResponse(token), nil
+}
+
+func (s *apiTokenService) List(req *dto.APITokenListRequest) ([]*dto.APITokenResponse, int64, error) {
+	list, total, err := s.repo.List(req.IncludeRevoked, req.Page, req.PageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	return lo.Map(list, func(t *model.APIToken, _ int) *dto.APITokenResponse { return toAPITokenResponse(t) }), total, nil
+}
+
+func (s *apiTokenService) Authenticate(plain, clientIP string) (*model.APIToken, error) {
+	token, err := s.repo.GetByHash(hashAPIToken(plain))
+	if err != nil {
+		if err == pkgErrors.ErrRecordNotFound {
+			return nil, pkgErrors.ErrInvalidToken
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if token.RevokedAt != nil {
+		return nil, pkgErrors.New(pkgErrors.CodeUnauthorized, "API Token 已吊销")
+	}
+	if token.Expired(now) {
+		return nil, pkgErrors.ErrTokenExpired
+	}
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= apiTokenTouchInterval {
+		if err := s.repo.TouchLastUsed(token.ID, now, clientIP); err != nil {
+			logger.Warn("更新 API Token 最近使用时间失败", zap.Int64("id", token.ID), zap.Error(err))
+		}
+	}
+	return token, nil
+}
+
+func (s *apiTokenService) check(t *model.APIToken) error {
+	if t.Name == "" {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, "name 不能为空")
+	}
+	for _, scope := range t.Scopes {
+		if !lo.Contains(auth.APITokenScopes, auth.Permission(scope)) {
+			return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("不支持的 scope: %s（可选: %s）", scope,
+				strings.Join(lo.Map(auth.APITokenScopes, func(p auth.Permission, _ int) string { return string(p) }), ", ")))
+		}
+	}
+	for _, projectID := range t.ProjectIDs {
+		if _, err := s.projectRepo.FindByID(projectID); err != nil {
+			if err == pkgErrors.ErrRecordNotFound {
+				return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("项目不存在: %d", projectID))
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func hashAPIToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+func toAPITokenResponse(t *model.APIToken) *dto.APITokenResponse {
+	status := APITokenStatusActive
+	switch {
+	case t.RevokedAt != nil:
+		status = APITokenStatusRevoked
+	case t.Expired(time.Now()):
+		status = APITokenStatusExpired
+	}
+	return &dto.APITokenResponse{
+		ID:          t.ID,
+		Name:        t.Name,
+		Description: t.Description,
+		TokenPrefix: t.TokenPrefix,
+		Scopes:      lo.Ternary(t.Scopes == nil, []string{}, []string(t.Scopes)),
+		ProjectIDs:  lo.Ternary(t.ProjectIDs == nil, []int64{}, []int64(t.ProjectIDs)),
+		Status:      status,
+		ExpiresAt:   t.ExpiresAt,
+		LastUsedAt:  t.LastUsedAt,
+		LastUsedIP:  t.LastUsedIP,
+		RevokedAt:   t.RevokedAt,
+		RevokedBy:   t.RevokedBy,
+		CreatedBy:   t.CreatedBy,
+		CreatedAt:   t.CreatedAt,
+	}
+}
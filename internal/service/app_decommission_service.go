@@ -0,0 +1,219 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AppDecommissionService 应用下线：（可选）卸载应用在各环境/集群的 release，归档构建记录并删除应用
+//
+// 只创建下线记录，release 卸载、构建归档与应用删除由核心引擎扫描执行
+type AppDecommissionService struct {
+	db *gorm.DB
+}
+
+func NewAppDecommissionService(db *gorm.DB) *AppDecommissionService {
+	return &AppDecommissionService{db: db}
+}
+
+// Create 发起应用下线，canAccess 按应用部署过的环境校验权限（涉及 prod 时需要 prod_operator）
+//
+// 应用在未结束的批次中、存在进行中的部署或下线时不允许发起
+func (s *AppDecommissionService) Create(req *dto.CreateAppDecommissionRequest, operator string, canAccess func(projectID int64, env string) bool) (*dto.AppDecommissionResponse, error) {
+	var app model.Application
+	if err := s.db.Limit(1).Find(&app, req.AppID).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用失败", err)
+	}
+	if app.ID == 0 {
+		return nil, pkgErrors.New(pkgErrors.CodeNotFound, "应用不存在")
+	}
+
+	targets, err := s.resolveTargets(app.ID)
+	if err != nil {
+		return nil, err
+	}
+	var envs []string
+	if err := s.db.Model(&model.AppEnvConfig{}).Where("app_id = ?", app.ID).Distinct().Pluck("env", &envs).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用环境配置失败", err)
+	}
+	envs = append(envs, constants.EnvTypePre)
+	for _, t := range targets {
+		envs = append(envs, t.Env)
+	}
+	for _, env := range lo.Uniq(envs) {
+		if !canAccess(app.ProjectID, env) {
+			return nil, pkgErrors.ErrForbidden
+		}
+	}
+	if !req.Uninstall {
+		targets = model.DecommissionTargets{}
+	}
+
+	decommission := &model.AppDecommission{
+		ProjectID: app.ProjectID,
+		AppID:     app.ID,
+		AppName:   app.Name,
+		Uninstall: req.Uninstall,
+		Targets:   targets,
+		Status:    constants.AppDecommissionStatusRunning,
+		Reason:    req.Reason,
+		CreatedBy: operator,
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkAppRemovable(tx, app.ID); err != nil {
+			return err
+		}
+		var running int64
+		if err := tx.Model(&model.AppDecommission{}).
+			Where("app_id = ? AND status = ?", app.ID, constants.AppDecommissionStatusRunning).
+			Count(&running).Error; err != nil {
+			return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用下线记录失败", err)
+		}
+		if running > 0 {
+			return pkgErrors.New(pkgErrors.CodeBadRequest, "应用正在下线中")
+		}
+		if err := tx.Create(decommission).Error; err != nil {
+			return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "创建应用下线记录失败", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Warn("发起应用下线",
+		zap.Int64("decommission_id", decommission.ID),
+		zap.Int64("app_id", app.ID),
+		zap.String("app_name", app.Name),
+		zap.Bool("uninstall", req.Uninstall),
+		zap.Int("targets", len(targets)),
+		zap.String("operator", operator),
+		zap.String("reason", req.Reason))
+	return toAppDecommissionResponse(decommission), nil
+}
+
+// Get 应用下线详情（含各 release 卸载结果）
+func (s *AppDecommissionService) Get(id int64, canAccess func(projectID int64) bool) (*dto.AppDecommissionResponse, error) {
+	var decommission model.AppDecommission
+	if err := s.db.Limit(1).Find(&decommission, id).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用下线记录失败", err)
+	}
+	if decommission.ID == 0 {
+		return nil, pkgErrors.New(pkgErrors.CodeNotFound, "应用下线记录不存在")
+	}
+	if !canAccess(decommission.ProjectID) {
+		return nil, pkgErrors.ErrForbidden
+	}
+	return toAppDecommissionResponse(&decommission), nil
+}
+
+// List 项目的应用下线记录
+func (s *AppDecommissionService) List(req *dto.AppDecommissionListRequest, canAccess func(projectID int64) bool) ([]*dto.AppDecommissionResponse, int64, error) {
+	if !canAccess(req.ProjectID) {
+		return nil, 0, pkgErrors.ErrForbidden
+	}
+	q := s.db.Model(&model.AppDecommission{}).Where("project_id = ?", req.ProjectID)
+	if req.AppID > 0 {
+		q = q.Where("app_id = ?", req.AppID)
+	}
+	if req.Status != "" {
+		q = q.Where("status = ?", req.Status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用下线记录失败", err)
+	}
+	var list []*model.AppDecommission
+	if err := q.Order("id DESC").Limit(req.PageSize).Offset((req.Page - 1) * req.PageSize).Find(&list).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用下线记录失败", err)
+	}
+	return lo.Map(list, func(d *model.AppDecommission, _ int) *dto.AppDecommissionResponse {
+		return toAppDecommissionResponse(d)
+	}), total, nil
+}
+
+// resolveTargets 应用在各环境/集群/部署类型最近一次已执行（记录了 driver_type）的 Deployment 写入的 release
+func (s *AppDecommissionService) resolveTargets(appID int64) (model.DecommissionTargets, error) {
+	latest := s.db.Model(&model.Deployment{}).
+		Select("MAX(id)").
+		Where("app_id = ? AND driver_type IS NOT NULL", appID).
+		Group("env, cluster, kind")
+	var deps []*model.Deployment
+	if err := s.db.Where("id IN (?)", latest).Order("env, cluster, kind").Find(&deps).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用部署记录失败", err)
+	}
+	targets := make(model.DecommissionTargets, 0, len(deps))
+	for _, dep := range deps {
+		t := model.DecommissionTarget{
+			Env:          dep.Env,
+			ClusterName:  dep.ClusterName,
+			Kind:         dep.Kind,
+			Namespace:    dep.Namespace,
+			ReleaseName:  dep.DeploymentName,
+			DriverType:   strings.TrimSpace(*dep.DriverType),
+			DeploymentID: dep.ID,
+			Status:       constants.DecommissionTargetPending,
+		}
+		if t.DriverType != "helm" {
+			t.Status = constants.DecommissionTargetSkipped
+			t.Message = fmt.Sprintf("%s driver 部署的资源不支持自动卸载，请手动清理", t.DriverType)
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// checkAppRemovable 应用在未结束的批次中或存在进行中的部署时不允许删除/下线
+func checkAppRemovable(db *gorm.DB, appID int64) error {
+	batches, err := model.AppActiveBatchNumbers(db, appID)
+	if err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用所在批次失败", err)
+	}
+	if len(batches) > 0 {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("应用在未结束的批次中（%s），请先完成或移出批次", strings.Join(batches, ", ")))
+	}
+	var active int64
+	if err := db.Model(&model.Deployment{}).
+		Where("app_id = ? AND superseded_by IS NULL AND status IN ?",
+			appID, []string{constants.DeploymentStatusPending, constants.DeploymentStatusRunning}).
+		Count(&active).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询进行中的部署失败", err)
+	}
+	if active > 0 {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, "应用存在进行中的部署，请等待完成后再操作")
+	}
+	return nil
+}
+
+func toAppDecommissionResponse(d *model.AppDecommission) *dto.AppDecommissionResponse {
+	targets := d.Targets
+	if targets == nil {
+		targets = model.DecommissionTargets{}
+	}
+	return &dto.AppDecommissionResponse{
+		ID:             d.ID,
+		ProjectID:      d.ProjectID,
+		AppID:          d.AppID,
+		AppName:        d.AppName,
+		Uninstall:      d.Uninstall,
+		Targets:        targets,
+		Status:         d.Status,
+		ArchivedBuilds: d.ArchivedBuilds,
+		Reason:         d.Reason,
+		ErrorMessage:   d.ErrorMessage,
+		CreatedBy:      d.CreatedBy,
+		CreatedAt:      d.CreatedAt.Format(time.RFC3339),
+		FinishedAt:     dto.FormatTime(d.FinishedAt),
+	}
+}
@@ -65,6 +65,10 @@ func (s *appEnvConfigService) Create(req *dto.CreateAppEnvConfigRequest) (*dto.A
 		return nil, err
 	}
 
+	if err := validateAppEnvConfigData(req.Env, req.ConfigData); err != nil {
+		return nil, err
+	}
+
 	// 3. 检查是否已存在相同配置
 	exists, err := s.repo.CheckExists(req.AppID, req.Env, req.Cluster)
 	if err != nil {
@@ -125,6 +129,9 @@ func (s *appEnvConfigService) Update(id int64, req *dto.UpdateAppEnvConfigReques
 	}
 
 	if req.ConfigData != nil {
+		if err := validateAppEnvConfigData(config.Env, req.ConfigData); err != nil {
+			return nil, err
+		}
 		config.ConfigData = req.ConfigData
 	}
 
@@ -197,6 +204,10 @@ func (s *appEnvConfigService) BatchCreate(req *dto.BatchCreateAppEnvConfigsReque
 			return nil, pkgErrors.Wrap(pkgErrors.CodeBadRequest,
 				fmt.Sprintf("第 %d 项配置校验失败: %s", i+1, err.Error()), nil)
 		}
+		if err := validateAppEnvConfigData(item.Env, item.ConfigData); err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeBadRequest,
+				fmt.Sprintf("第 %d 项配置校验失败: %s", i+1, err.Error()), nil)
+		}
 	}
 
 	// 3. 检查是否有重复配置
@@ -308,3 +319,22 @@ func (s *appEnvConfigService) validateEnvCluster(projectID int64, env string, cl
 
 	return nil
 }
+
+// validateAppEnvConfigData 校验扩展配置：JSON 格式及部署策略（部署策略仅 prod 环境可配置）
+func validateAppEnvConfigData(env string, configData *string) error {
+	cfg := model.AppEnvConfig{Env: env, ConfigData: configData}
+	data, err := cfg.ParseConfigData()
+	if err != nil {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("config_data 格式错误: %v", err))
+	}
+	if data.Strategy == nil {
+		return nil
+	}
+	if env != constants.EnvTypeProd {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, "部署策略 strategy 仅支持 prod 环境")
+	}
+	if err := data.Strategy.Validate(); err != nil {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("部署策略配置错误: %v", err))
+	}
+	return nil
+}
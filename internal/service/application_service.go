@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	pkgErrors "devops-cd/pkg/responses"
 	"fmt"
 	"go.uber.org/zap"
@@ -8,6 +9,7 @@ import (
 
 	"gorm.io/gorm"
 
+	"devops-cd/internal/core/deployment"
 	"devops-cd/internal/dto"
 	"devops-cd/internal/model"
 	"devops-cd/internal/pkg/config"
@@ -27,6 +29,8 @@ type ApplicationService interface {
 	SearchWithBuilds(query *dto.ApplicationSearchParam) ([]*dto.ApplicationBuildResponse, int64, error)
 	GetDefaultDependencies(appID int64) (*dto.ApplicationDependenciesResponse, error)
 	UpdateDefaultDependencies(appID int64, req *dto.UpdateAppDependenciesRequest) (*dto.ApplicationDependenciesResponse, error)
+	// GetDeployDurations 应用各环境历史部署耗时（平均/P95，部署耗时异常告警的基准）
+	GetDeployDurations(appID int64) (*dto.AppDeployDurationResponse, error)
 }
 
 type applicationService struct {
@@ -74,13 +78,34 @@ func (s *applicationService) Create(req *dto.CreateApplicationRequest) (*dto.App
 	}
 
 	// 4. 创建应用
+	tagFilter, err := normalizeTagFilter(req.TagFilter)
+	if err != nil {
+		return nil, err
+	}
+	pathFilter, err := normalizePathFilter(req.PathFilter)
+	if err != nil {
+		return nil, err
+	}
+	regionRollout, err := normalizeRegionRollout(req.RegionRollout)
+	if err != nil {
+		return nil, err
+	}
+	deployVerify, err := normalizeDeployVerify(req.DeployVerify)
+	if err != nil {
+		return nil, err
+	}
 	app := &model.Application{
-		Name:        req.Name,
-		ProjectID:   projectID,
-		Description: req.Description,
-		RepoID:      req.RepoID,
-		AppType:     req.AppType,
-		TeamID:      req.TeamID,
+		Name:          req.Name,
+		ProjectID:     projectID,
+		Description:   req.Description,
+		RepoID:        req.RepoID,
+		AppType:       req.AppType,
+		TeamID:        req.TeamID,
+		TagFilter:     tagFilter,
+		PathFilter:    pathFilter,
+		RegionRollout: regionRollout,
+		DeployVerify:  deployVerify,
+		AutoRollback:  req.AutoRollback,
 		BaseStatus: model.BaseStatus{
 			Status: constants.StatusEnabled,
 		},
@@ -207,6 +232,34 @@ func (s *applicationService) Update(id int64, req *dto.UpdateApplicationRequest)
 	if req.Status != nil {
 		app.Status = *req.Status
 	}
+	if req.TagFilter != nil {
+		if app.TagFilter, err = normalizeTagFilter(req.TagFilter); err != nil {
+			return nil, err
+		}
+	}
+	if req.PathFilter != nil {
+		if app.PathFilter, err = normalizePathFilter(req.PathFilter); err != nil {
+			return nil, err
+		}
+	}
+	if req.RegionRollout != nil {
+		if app.RegionRollout, err = normalizeRegionRollout(req.RegionRollout); err != nil {
+			return nil, err
+		}
+	}
+	if req.DeployVerify != nil {
+		if app.DeployVerify, err = normalizeDeployVerify(req.DeployVerify); err != nil {
+			return nil, err
+		}
+	}
+	if req.AutoRollback != nil {
+		// inherit 清空应用级配置，继承项目
+		app.AutoRollback = nil
+		if *req.AutoRollback != "inherit" {
+			enabled := *req.AutoRollback == "enabled"
+			app.AutoRollback = &enabled
+		}
+	}
 
 	// 保存更新
 	if err = s.appRepo.Update(app); err != nil {
@@ -237,6 +290,20 @@ func (s *applicationService) Delete(id int64) error {
 		return err
 	}
 
+	// 应用在未结束的批次中或存在进行中的部署/下线时不允许删除；需要卸载集群中的 release 时使用应用下线
+	if err := checkAppRemovable(s.db, id); err != nil {
+		return err
+	}
+	var decommissioning int64
+	if err := s.db.Model(&model.AppDecommission{}).
+		Where("app_id = ? AND status = ?", id, constants.AppDecommissionStatusRunning).
+		Count(&decommissioning).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用下线记录失败", err)
+	}
+	if decommissioning > 0 {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, "应用正在下线中")
+	}
+
 	// 软删除应用（不级联删除Build记录）
 	return s.appRepo.Delete(id)
 }
@@ -299,6 +366,27 @@ func (s *applicationService) GetDefaultDependencies(appID int64) (*dto.Applicati
 	return s.buildDependenciesResponse(app)
 }
 
+func (s *applicationService) GetDeployDurations(appID int64) (*dto.AppDeployDurationResponse, error) {
+	app, err := s.appRepo.FindByID(appID)
+	if err != nil {
+		return nil, err
+	}
+	resp := &dto.AppDeployDurationResponse{AppID: app.ID, AppName: app.Name, History: deployment.DurationHistory}
+	for _, env := range []string{constants.EnvTypePre, constants.EnvTypeProd} {
+		stats, err := deployment.DurationBaseline(context.Background(), s.db, app.ID, env, deployment.DurationHistory)
+		if err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "统计历史部署耗时失败", err)
+		}
+		resp.Envs = append(resp.Envs, dto.AppDeployDurationItem{
+			Env:        env,
+			Samples:    stats.Samples,
+			AvgSeconds: int(stats.Avg.Seconds()),
+			P95Seconds: int(stats.P95.Seconds()),
+		})
+	}
+	return resp, nil
+}
+
 func (s *applicationService) UpdateDefaultDependencies(appID int64, req *dto.UpdateAppDependenciesRequest) (*dto.ApplicationDependenciesResponse, error) {
 	if _, err := s.appRepo.FindByID(appID); err != nil {
 		return nil, err
@@ -369,17 +457,22 @@ func (s *applicationService) UpdateDefaultDependencies(appID int64, req *dto.Upd
 // toResponse 转换为响应对象
 func (s *applicationService) toResponse(app *model.Application) *dto.ApplicationResponse {
 	resp := &dto.ApplicationResponse{
-		ID:          app.ID,
-		Name:        app.Name,
-		Description: app.Description,
-		ProjectID:   app.ProjectID,
-		RepoID:      app.RepoID,
-		AppType:     app.AppType,
-		TeamID:      app.TeamID,
-		DeployedTag: app.DeployedTag,
-		Status:      app.Status,
-		CreatedAt:   app.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   app.UpdatedAt.Format(time.RFC3339),
+		ID:            app.ID,
+		Name:          app.Name,
+		Description:   app.Description,
+		ProjectID:     app.ProjectID,
+		RepoID:        app.RepoID,
+		AppType:       app.AppType,
+		TeamID:        app.TeamID,
+		DeployedTag:   app.DeployedTag,
+		TagFilter:     app.TagFilter,
+		PathFilter:    app.PathFilter,
+		RegionRollout: app.RegionRollout,
+		DeployVerify:  app.DeployVerify,
+		AutoRollback:  app.AutoRollback,
+		Status:        app.Status,
+		CreatedAt:     app.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     app.UpdatedAt.Format(time.RFC3339),
 	}
 
 	resp.DefaultDependsOn = app.DefaultDependsOn
@@ -603,3 +696,47 @@ func getDefaultReplicas(env string) int {
 		return 1
 	}
 }
+
+// normalizeTagFilter 校验构建过滤规则，未配置任何规则时返回 nil
+func normalizeTagFilter(filter *model.TagFilter) (*model.TagFilter, error) {
+	if filter.IsEmpty() {
+		return nil, nil
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeBadRequest, err.Error(), nil)
+	}
+	return filter, nil
+}
+
+// normalizePathFilter 校验单仓多应用路径映射，未配置任何规则时返回 nil
+func normalizePathFilter(filter *model.PathFilter) (*model.PathFilter, error) {
+	if filter.IsEmpty() {
+		return nil, nil
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeBadRequest, err.Error(), nil)
+	}
+	return filter, nil
+}
+
+// normalizeRegionRollout 校验多 region 协调配置，未开启时返回 nil
+func normalizeRegionRollout(rollout *model.RegionRollout) (*model.RegionRollout, error) {
+	if !rollout.IsEnabled() {
+		return nil, nil
+	}
+	if err := rollout.Validate(); err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeBadRequest, err.Error(), nil)
+	}
+	return rollout, nil
+}
+
+// normalizeDeployVerify 校验部署后验证配置，未开启时返回 nil
+func normalizeDeployVerify(verify *model.DeployVerify) (*model.DeployVerify, error) {
+	if !verify.IsEnabled() {
+		return nil, nil
+	}
+	if err := verify.Validate(); err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeBadRequest, err.Error(), nil)
+	}
+	return verify, nil
+}
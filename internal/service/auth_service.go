@@ -1,11 +1,18 @@
 package service
 
 import (
+	"context"
+	"errors"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+
 	"devops-cd/internal/dto"
 	"devops-cd/internal/model"
 	"devops-cd/internal/pkg/config"
 	"devops-cd/internal/pkg/crypto"
 	"devops-cd/internal/pkg/jwt"
+	"devops-cd/internal/pkg/logger"
 	"devops-cd/internal/repository"
 	"devops-cd/pkg/constants"
 	pkgErrors "devops-cd/pkg/responses"
@@ -16,19 +23,30 @@ type AuthService interface {
 	Login(req *dto.LoginRequest) (*dto.LoginResponse, error)
 	RefreshToken(refreshToken string) (*dto.LoginResponse, error)
 	VerifyToken(token string) (*dto.UserInfo, error)
+	// OIDCAuthorize 生成 OIDC 授权地址（授权码模式第一步）
+	OIDCAuthorize(ctx context.Context) (*dto.OIDCAuthorizeResponse, error)
+	// OIDCLogin 用回调的 code/state 完成登录：同步用户与团队映射后签发 Token
+	OIDCLogin(ctx context.Context, req *dto.OIDCCallbackRequest) (*dto.LoginResponse, error)
 }
 
 type authService struct {
-	cfg         *config.AuthConfig
-	userRepo    *repository.UserRepository
-	ldapService LDAPService
+	cfg            *config.AuthConfig
+	userRepo       *repository.UserRepository
+	teamRepo       repository.TeamRepository
+	teamMemberRepo *repository.TeamMemberRepository
+	ldapService    LDAPService
+	oidcService    OIDCService
 }
 
-func NewAuthService(cfg *config.AuthConfig, userRepo *repository.UserRepository, ldapService LDAPService) AuthService {
+func NewAuthService(cfg *config.AuthConfig, userRepo *repository.UserRepository, teamRepo repository.TeamRepository,
+	teamMemberRepo *repository.TeamMemberRepository, ldapService LDAPService, oidcService OIDCService) AuthService {
 	return &authService{
-		cfg:         cfg,
-		userRepo:    userRepo,
-		ldapService: ldapService,
+		cfg:            cfg,
+		userRepo:       userRepo,
+		teamRepo:       teamRepo,
+		teamMemberRepo: teamMemberRepo,
+		ldapService:    ldapService,
+		oidcService:    oidcService,
 	}
 }
 
@@ -62,7 +80,11 @@ func (s *authService) Login(req *dto.LoginRequest) (*dto.LoginResponse, error) {
 		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "不支持的认证类型")
 	}
 
-	// 生成Token
+	return s.issueTokens(userInfo)
+}
+
+// issueTokens 为已认证用户签发 AccessToken / RefreshToken
+func (s *authService) issueTokens(userInfo *dto.UserInfo) (*dto.LoginResponse, error) {
 	accessToken, err := jwt.GenerateAccessToken(
 		userInfo.Username,
 		userInfo.Email,
@@ -171,6 +193,170 @@ func (s *authService) syncLDAPUser(userInfo *dto.UserInfo) error {
 	return s.userRepo.UpdateLastLogin(user.ID)
 }
 
+func (s *authService) OIDCAuthorize(ctx context.Context) (*dto.OIDCAuthorizeResponse, error) {
+	if s.oidcService == nil {
+		return nil, pkgErrors.New(pkgErrors.CodeAuthError, "OIDC认证未启用")
+	}
+	return s.oidcService.AuthorizeURL(ctx)
+}
+
+func (s *authService) OIDCLogin(ctx context.Context, req *dto.OIDCCallbackRequest) (*dto.LoginResponse, error) {
+	if s.oidcService == nil {
+		return nil, pkgErrors.New(pkgErrors.CodeAuthError, "OIDC认证未启用")
+	}
+	identity, err := s.oidcService.Authenticate(ctx, req.Code, req.State)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.syncOIDCUser(identity); err != nil {
+		return nil, err
+	}
+	return s.issueTokens(identity.User)
+}
+
+// syncOIDCUser 同步 OIDC 用户：首次登录时创建（可按 migrate_from 复制旧来源的角色与团队），
+// 之后以 IdP 为准更新资料，并按 group_mappings 同步团队成员关系
+func (s *authService) syncOIDCUser(identity *OIDCIdentity) error {
+	userInfo := identity.User
+	user, err := s.userRepo.FindByUsername(userInfo.Username, constants.AuthTypeOIDC)
+	switch {
+	case errors.Is(err, pkgErrors.ErrRecordNotFound):
+		user = &model.User{
+			AuthProvider: constants.AuthTypeOIDC,
+			Username:     userInfo.Username,
+			Password:     "",
+			DisplayName:  strings.StringPtr(userInfo.DisplayName),
+			Email:        strings.StringPtr(userInfo.Email),
+			Phone:        strings.StringPtr(userInfo.Phone),
+			ExternalUID:  strings.StringPtr(userInfo.UID),
+			BaseStatus:   model.BaseStatus{Status: constants.StatusEnabled},
+		}
+		legacy := s.findMigrateSource(userInfo.Username)
+		if legacy != nil {
+			user.SystemRoles = append(model.StringList{}, legacy.SystemRoles...)
+		}
+		if err := s.userRepo.Create(user); err != nil {
+			return err
+		}
+		if legacy != nil {
+			s.copyTeamMembers(legacy, user)
+		}
+	case err != nil:
+		return err
+	default:
+		if user.Status != constants.StatusEnabled {
+			return pkgErrors.ErrUserDisabled
+		}
+		user.DisplayName = strings.StringPtr(userInfo.DisplayName)
+		user.Email = strings.StringPtr(userInfo.Email)
+		user.Phone = strings.StringPtr(userInfo.Phone)
+		user.ExternalUID = strings.StringPtr(userInfo.UID)
+		if err := s.userRepo.Update(user); err != nil {
+			return err
+		}
+	}
+
+	if err := s.syncOIDCTeams(user, identity.Groups); err != nil {
+		return err
+	}
+	return s.userRepo.UpdateLastLogin(user.ID)
+}
+
+// findMigrateSource migrate_from 来源下的同名用户，未配置或不存在时返回 nil
+func (s *authService) findMigrateSource(username string) *model.User {
+	from := s.cfg.OIDC.MigrateFrom
+	if from == "" || from == constants.AuthTypeOIDC {
+		return nil
+	}
+	legacy, err := s.userRepo.FindWithTeams(username, from)
+	if err != nil {
+		if !errors.Is(err, pkgErrors.ErrRecordNotFound) {
+			logger.Warn("查询待迁移用户失败", zap.String("username", username), zap.String("from", from), zap.Error(err))
+		}
+		return nil
+	}
+	return legacy
+}
+
+// copyTeamMembers 复制旧用户的团队成员关系（失败只记录日志，不阻断登录）
+func (s *authService) copyTeamMembers(from, to *model.User) {
+	for _, m := range from.TeamMembers {
+		member := &model.TeamMember{TeamID: m.TeamID, UserID: to.ID, Roles: append(model.StringList{}, m.Roles...)}
+		if err := s.teamMemberRepo.Create(member); err != nil {
+			logger.Warn("迁移团队成员关系失败", zap.String("username", to.Username), zap.Int64("team_id", m.TeamID), zap.Error(err))
+		}
+	}
+	logger.Info("OIDC用户已从旧认证来源迁移",
+		zap.String("username", to.Username),
+		zap.String("from", from.AuthProvider),
+		zap.Int("teams", len(from.TeamMembers)))
+}
+
+// syncOIDCTeams 按 group_mappings 同步团队成员关系
+//
+// 只处理映射中出现的团队：属于映射组时加入（或更新角色），否则移出；其他团队的成员关系保持不变
+func (s *authService) syncOIDCTeams(user *model.User, groups []string) error {
+	if len(s.cfg.OIDC.GroupMappings) == 0 {
+		return nil
+	}
+	inGroup := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		inGroup[g] = true
+	}
+
+	// 团队 -> 应有角色（多个组映射到同一团队时取并集）；nil 表示不应加入
+	desired := make(map[string][]string)
+	teams := make([]string, 0, len(s.cfg.OIDC.GroupMappings))
+	for _, m := range s.cfg.OIDC.GroupMappings {
+		if _, ok := desired[m.Team]; !ok {
+			desired[m.Team] = nil
+			teams = append(teams, m.Team)
+		}
+		if inGroup[m.Group] {
+			desired[m.Team] = append(desired[m.Team], normalizeRoles(m.Roles, defaultTeamMemberRole)...)
+		}
+	}
+
+	for _, name := range teams {
+		team, err := s.teamRepo.FindByName(name)
+		if err != nil {
+			if errors.Is(err, pkgErrors.ErrRecordNotFound) {
+				logger.Warn("OIDC组映射的团队不存在", zap.String("team", name))
+				continue
+			}
+			return err
+		}
+		member, err := s.teamMemberRepo.FindByTeamAndUser(team.ID, user.ID)
+		if err != nil && !errors.Is(err, pkgErrors.ErrRecordNotFound) {
+			return err
+		}
+
+		roles := desired[name]
+		switch {
+		case roles == nil && member != nil:
+			if err := s.teamMemberRepo.Delete(member.ID); err != nil {
+				return err
+			}
+			logger.Info("OIDC组映射移出团队", zap.String("username", user.Username), zap.String("team", name))
+		case roles != nil && member == nil:
+			member = &model.TeamMember{TeamID: team.ID, UserID: user.ID, Roles: normalizeRoles(roles, defaultTeamMemberRole)}
+			if err := s.teamMemberRepo.Create(member); err != nil {
+				return err
+			}
+			logger.Info("OIDC组映射加入团队", zap.String("username", user.Username), zap.String("team", name))
+		case roles != nil:
+			roles = normalizeRoles(roles, defaultTeamMemberRole)
+			if !lo.ElementsMatch(roles, []string(member.Roles)) {
+				member.Roles = roles
+				if err := s.teamMemberRepo.Update(member); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func (s *authService) RefreshToken(refreshToken string) (*dto.LoginResponse, error) {
 	// 验证RefreshToken
 	claims, err := jwt.ParseToken(refreshToken)
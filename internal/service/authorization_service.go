@@ -11,61 +11,105 @@ import (
 	"github.com/samber/lo"
 )
 
-// AuthorizationService 负责基于内置角色/权限做简单的团队权限判断
+// AuthorizationService 负责基于角色/权限做简单的团队权限判断
 // 当前实现逻辑：
 //  1. 先检查用户的系统级角色（users.system_roles）是否已拥有该权限
-//  2. 再检查 team_members 表中该用户在指定 team 下的角色是否拥有该权限
-//  3. 角色 -> 权限 的关系写死在 internal/model/auth.go 的 RolePermissions 中
-//  4. 权限匹配使用 model.allow，支持通配符（如 view:*、resource:*）
+//  2. 再检查该用户在指定 team 下的成员角色（team_members.roles）与团队角色（teams.roles）是否拥有该权限
+//  3. 内置角色 -> 权限 的关系写死在 internal/pkg/auth 的 RolePermissions 中，自定义角色存储在 roles/role_permissions 表
+//  4. 权限匹配使用 auth.Allow / auth.AllowPermissions，支持通配符（如 view:*、resource:*）
+//  5. 项目内的环境权限（env:*，如 env:prod:operate）只看该项目下团队的角色，在 A 项目团队中的 prod_operator 不作用于 B 项目
 type AuthorizationService interface {
 	CanAccessProject(username, authProvider string, projectId int64, perm auth.Permission) bool
 	// HasTeamPermission 判断某个用户在指定 team 下是否拥有某个权限
 	HasTeamPermission(username, authProvider string, teamID int64, perm auth.Permission) (bool, error)
+	// HasSystemPermission 仅基于系统级角色（users.system_roles）判断权限
+	HasSystemPermission(username, authProvider string, perm auth.Permission) bool
+	// ProjectPermissions 用户在项目范围内的有效角色与权限（auth.ProjectPermissions 中已授予的部分）
+	ProjectPermissions(username, authProvider string, projectId int64) (roles []string, perms []auth.Permission)
 }
 
 type authorizationService struct {
-	userRepo       *repository.UserRepository
-	teamMemberRepo *repository.TeamMemberRepository
+	userRepo  *repository.UserRepository
+	roleCache *RoleCache
 }
 
 // NewAuthorizationService 创建 AuthorizationService
-func NewAuthorizationService(userRepo *repository.UserRepository, teamMemberRepo *repository.TeamMemberRepository) AuthorizationService {
+func NewAuthorizationService(userRepo *repository.UserRepository, roleCache *RoleCache) AuthorizationService {
 	return &authorizationService{
-		userRepo:       userRepo,
-		teamMemberRepo: teamMemberRepo,
+		userRepo:  userRepo,
+		roleCache: roleCache,
 	}
 }
 
 func (s *authorizationService) CanAccessProject(username, authProvider string, projectId int64, perm auth.Permission) bool {
+	user := s.findUserWithTeams(username, authProvider)
+	if user == nil {
+		return false
+	}
+	return s.allow(projectRoles(user, projectId, perm), perm)
+}
+
+func (s *authorizationService) ProjectPermissions(username, authProvider string, projectId int64) ([]string, []auth.Permission) {
+	user := s.findUserWithTeams(username, authProvider)
+	if user == nil {
+		return []string{}, []auth.Permission{}
+	}
+	perms := lo.Filter(auth.ProjectPermissions, func(p auth.Permission, _ int) bool {
+		return s.allow(projectRoles(user, projectId, p), p)
+	})
+	return projectRoles(user, projectId, ""), perms
+}
+
+// allow 内置角色与自定义角色合并判断
+func (s *authorizationService) allow(roles []string, perm auth.Permission) bool {
+	return auth.Allow(roles, perm) || auth.AllowPermissions(s.roleCache.Permissions(roles), perm)
+}
 
+// memberRoles 团队成员角色 + 团队角色
+func memberRoles(m model.TeamMember) []string {
+	roles := append([]string{}, m.Roles...)
+	if m.Team != nil {
+		roles = append(roles, m.Team.Roles...)
+	}
+	return roles
+}
+
+// findUserWithTeams 查询用户及其团队成员关系，不存在或查询失败时返回 nil（视为无权限）
+func (s *authorizationService) findUserWithTeams(username, authProvider string) *model.User {
 	user, err := s.userRepo.FindWithTeams(username, normalizeProvider(authProvider))
 	if err != nil {
-		if errors.Is(err, pkgErrors.ErrRecordNotFound) {
-			// 用户不存在，视为无权限
-			return false
+		if !errors.Is(err, pkgErrors.ErrRecordNotFound) {
+			logger.Sugar().Warnf("find user error: %v", err)
 		}
-		logger.Sugar().Warnf("find user error: %v", err)
-		return false
+		return nil
 	}
+	return user
+}
 
-	// 检查系统级角色权限
-	if auth.Allow(user.SystemRoles, perm) {
-		return true
+// projectRoles 用户在项目范围内判断 perm 时生效的角色：系统级角色 + 团队成员角色与团队角色
+//
+// 环境权限与项目策略权限（见 auth.ProjectScoped）只计入该项目下的团队，其他权限计入所有团队
+func projectRoles(user *model.User, projectID int64, perm auth.Permission) []string {
+	scoped := auth.ProjectScoped(perm)
+	roles := append([]string{}, user.SystemRoles...)
+	for _, m := range user.TeamMembers {
+		if scoped && (m.Team == nil || m.Team.ProjectID != projectID) {
+			continue
+		}
+		roles = append(roles, memberRoles(m)...)
 	}
-
-	roles := lo.Uniq(lo.FlatMap(user.TeamMembers, func(t model.TeamMember, _ int) []string { return t.Roles }))
-	return auth.Allow(roles, perm)
+	return lo.Uniq(roles)
 }
 
 // HasTeamPermission 权限判断核心逻辑
 //
-// 1. 按 username 查询用户信息（本地 users 表）
+// 1. 按 username 查询用户信息（本地 users 表，含团队成员关系与团队）
 // 2. 基于用户的 SystemRoles 计算系统级权限，如果已满足则直接放行
-// 3. 否则查询 team_members 中该用户在指定 team 下的记录，基于成员角色计算权限
-// 4. 最终使用 model.allow 进行权限匹配
+// 3. 否则基于该用户在指定 team 下的成员角色与团队角色计算权限
+// 4. 最终使用 auth.Allow / auth.AllowPermissions 进行权限匹配
 func (s *authorizationService) HasTeamPermission(username, authProvider string, teamID int64, perm auth.Permission) (bool, error) {
 	// 1. 查询用户
-	user, err := s.userRepo.FindByUsername(username, normalizeProvider(authProvider))
+	user, err := s.userRepo.FindWithTeams(username, normalizeProvider(authProvider))
 	if err != nil {
 		if errors.Is(err, pkgErrors.ErrRecordNotFound) {
 			// 用户不存在，视为无权限
@@ -76,21 +120,29 @@ func (s *authorizationService) HasTeamPermission(username, authProvider string,
 	}
 
 	// 2. 系统级角色权限检查（users.system_roles）
-	if auth.Allow(user.SystemRoles, perm) {
+	if s.allow(user.SystemRoles, perm) {
 		return true, nil
 	}
 
-	// 3. team 内角色权限检查（team_members.roles）
-	member, err := s.teamMemberRepo.FindByTeamAndUser(teamID, user.ID)
+	// 3. team 内角色权限检查（team_members.roles + teams.roles）
+	member, ok := lo.Find(user.TeamMembers, func(m model.TeamMember) bool { return m.TeamID == teamID })
+	if !ok {
+		// 未加入该团队，视为无权限
+		return false, nil
+	}
+
+	return s.allow(memberRoles(member), perm), nil
+}
+
+func (s *authorizationService) HasSystemPermission(username, authProvider string, perm auth.Permission) bool {
+	user, err := s.userRepo.FindByUsername(username, normalizeProvider(authProvider))
 	if err != nil {
-		if err == pkgErrors.ErrRecordNotFound {
-			// 未加入该团队，视为无权限
-			return false, nil
+		if !errors.Is(err, pkgErrors.ErrRecordNotFound) {
+			logger.Sugar().Warnf("find user error: %v", err)
 		}
-		return false, err
+		return false
 	}
-
-	return auth.Allow(member.Roles, perm), nil
+	return s.allow(user.SystemRoles, perm)
 }
 
 func normalizeProvider(provider string) string {
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/database"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+)
+
+// AdoptionReport 按项目/团队汇总平台使用情况（批次、应用接入、手动/自动化动作、审批时延）
+// 动作统计来自 batch_events 审计；团队维度按批次包含的应用所属团队归属
+func (s *BatchService) AdoptionReport(query *dto.AdoptionReportQuery, canView func(projectID int64) bool) (*dto.AdoptionReportResponse, error) {
+	// 报表查询读副本
+	db := database.ReadReplica(s.db)
+
+	end := time.Now()
+	if query.End != nil {
+		end = *query.End
+	}
+	end = truncateDay(end).AddDate(0, 0, 1) // 含结束当天
+	start := end.AddDate(0, 0, -qualityDefaultDays)
+	if query.Start != nil {
+		start = truncateDay(*query.Start)
+	}
+	if !start.Before(end) {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "start 必须早于 end")
+	}
+
+	// 1. 项目与团队范围
+	projectID := query.ProjectID
+	projectQuery := db.Select("id", "name")
+	if query.TeamID != nil {
+		var team model.Team
+		if err := db.Select("id", "project_id").First(&team, *query.TeamID).Error; err != nil {
+			return nil, pkgErrors.New(pkgErrors.CodeNotFound, "团队不存在")
+		}
+		if projectID != nil && *projectID != team.ProjectID {
+			return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "团队不属于该项目")
+		}
+		projectID = &team.ProjectID
+	}
+	if projectID != nil {
+		if !canView(*projectID) {
+			return nil, pkgErrors.ErrForbidden
+		}
+		projectQuery = projectQuery.Where("id = ?", *projectID)
+	}
+	var allProjects []*model.Project
+	if err := projectQuery.Find(&allProjects).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询项目失败", err)
+	}
+	projects := make([]*model.Project, 0, len(allProjects))
+	projectIDs := make([]int64, 0, len(allProjects))
+	for _, project := range allProjects {
+		if projectID == nil && !canView(project.ID) {
+			continue
+		}
+		projects = append(projects, project)
+		projectIDs = append(projectIDs, project.ID)
+	}
+
+	resp := &dto.AdoptionReportResponse{
+		Start:    start.Format("2006-01-02"),
+		End:      end.AddDate(0, 0, -1).Format("2006-01-02"),
+		Projects: make([]*dto.ProjectAdoptionReport, 0, len(projects)),
+	}
+	if len(projectIDs) == 0 {
+		return resp, nil
+	}
+
+	var teams []*model.Team
+	teamQuery := db.Select("id", "name", "project_id").Where("project_id IN ?", projectIDs)
+	if query.TeamID != nil {
+		teamQuery = teamQuery.Where("id = ?", *query.TeamID)
+	}
+	if err := teamQuery.Find(&teams).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询团队失败", err)
+	}
+
+	// 2. 原始数据：应用、区间内创建的批次、区间内的状态变更事件
+	var apps []*model.Application
+	if err := db.Select("id", "project_id", "team_id", "created_at").
+		Where("project_id IN ?", projectIDs).Find(&apps).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用失败", err)
+	}
+
+	var batches []*model.Batch
+	if err := db.Select("id", "project_id", "initiator", "created_at", "approval_status", "approved_at").
+		Where("project_id IN ? AND created_at >= ? AND created_at < ?", projectIDs, start, end).
+		Find(&batches).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询批次失败", err)
+	}
+
+	var events []*model.BatchEvent
+	if err := db.Where("project_id IN ? AND created_at >= ? AND created_at < ?", projectIDs, start, end).
+		Find(&events).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询批次事件失败", err)
+	}
+
+	// 3. 批次 -> 团队归属
+	appTeam := make(map[int64]int64, len(apps))
+	for _, app := range apps {
+		if app.TeamID != nil {
+			appTeam[app.ID] = *app.TeamID
+		}
+	}
+	batchIDs := make([]int64, 0, len(batches)+len(events))
+	seenBatch := make(map[int64]bool)
+	for _, batch := range batches {
+		if !seenBatch[batch.ID] {
+			seenBatch[batch.ID] = true
+			batchIDs = append(batchIDs, batch.ID)
+		}
+	}
+	for _, event := range events {
+		if !seenBatch[event.BatchID] {
+			seenBatch[event.BatchID] = true
+			batchIDs = append(batchIDs, event.BatchID)
+		}
+	}
+	batchTeams := make(map[int64][]int64)
+	if len(batchIDs) > 0 {
+		var releaseApps []*model.ReleaseApp
+		if err := db.Select("batch_id", "app_id").Where("batch_id IN ?", batchIDs).Find(&releaseApps).Error; err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询批次应用失败", err)
+		}
+		seen := make(map[[2]int64]bool)
+		for _, ra := range releaseApps {
+			teamID, ok := appTeam[ra.AppID]
+			if !ok || seen[[2]int64{ra.BatchID, teamID}] {
+				continue
+			}
+			seen[[2]int64{ra.BatchID, teamID}] = true
+			batchTeams[ra.BatchID] = append(batchTeams[ra.BatchID], teamID)
+		}
+	}
+
+	// 4. 汇总
+	projectAccs := make(map[int64]*adoptionAccumulator, len(projects))
+	for _, project := range projects {
+		projectAccs[project.ID] = newAdoptionAccumulator()
+	}
+	teamAccs := make(map[int64]*adoptionAccumulator, len(teams))
+	for _, team := range teams {
+		teamAccs[team.ID] = newAdoptionAccumulator()
+	}
+	// accsFor 批次所属项目 + 其应用所属团队（团队需在统计范围内）
+	accsFor := func(projectID, batchID int64) []*adoptionAccumulator {
+		accs := make([]*adoptionAccumulator, 0, 2)
+		if acc, ok := projectAccs[projectID]; ok {
+			accs = append(accs, acc)
+		}
+		for _, teamID := range batchTeams[batchID] {
+			if acc, ok := teamAccs[teamID]; ok {
+				accs = append(accs, acc)
+			}
+		}
+		return accs
+	}
+
+	for _, app := range apps {
+		onboarded := !app.CreatedAt.Before(start) && app.CreatedAt.Before(end)
+		accs := []*adoptionAccumulator{projectAccs[app.ProjectID]}
+		if app.TeamID != nil {
+			if acc, ok := teamAccs[*app.TeamID]; ok {
+				accs = append(accs, acc)
+			}
+		}
+		for _, acc := range accs {
+			if acc != nil {
+				acc.addApp(onboarded)
+			}
+		}
+	}
+	for _, batch := range batches {
+		for _, acc := range accsFor(batch.ProjectID, batch.ID) {
+			acc.addBatch(batch)
+		}
+	}
+	for _, event := range events {
+		for _, acc := range accsFor(event.ProjectID, event.BatchID) {
+			acc.addEvent(event)
+		}
+	}
+
+	teamsByProject := make(map[int64][]*dto.TeamAdoptionReport)
+	for _, team := range teams {
+		teamsByProject[team.ProjectID] = append(teamsByProject[team.ProjectID], &dto.TeamAdoptionReport{
+			TeamID:        team.ID,
+			TeamName:      team.Name,
+			AdoptionStats: teamAccs[team.ID].stats(),
+		})
+	}
+	for _, project := range projects {
+		projectTeams := teamsByProject[project.ID]
+		sort.Slice(projectTeams, func(i, j int) bool { return projectTeams[i].TeamID < projectTeams[j].TeamID })
+		if projectTeams == nil {
+			projectTeams = []*dto.TeamAdoptionReport{}
+		}
+		resp.Projects = append(resp.Projects, &dto.ProjectAdoptionReport{
+			ProjectID:     project.ID,
+			ProjectName:   project.Name,
+			AdoptionStats: projectAccs[project.ID].stats(),
+			Teams:         projectTeams,
+		})
+	}
+	sort.Slice(resp.Projects, func(i, j int) bool {
+		return resp.Projects[i].ProjectID < resp.Projects[j].ProjectID
+	})
+	return resp, nil
+}
+
+type adoptionAccumulator struct {
+	batchesCreated, batchesCompleted, appsOnboarded, appsTotal int
+	manual, scheduled, auto                                    int
+	users                                                      map[string]bool
+	lastActivity                                               *time.Time
+	approvalMinutes                                            []float64
+}
+
+func newAdoptionAccumulator() *adoptionAccumulator {
+	return &adoptionAccumulator{users: make(map[string]bool)}
+}
+
+func (a *adoptionAccumulator) addApp(onboarded bool) {
+	a.appsTotal++
+	if onboarded {
+		a.appsOnboarded++
+	}
+}
+
+func (a *adoptionAccumulator) addBatch(batch *model.Batch) {
+	a.batchesCreated++
+	if batch.Initiator != "" {
+		a.users[batch.Initiator] = true
+	}
+	a.touch(batch.CreatedAt)
+	if batch.ApprovalStatus == constants.ApprovalStatusApproved && batch.ApprovedAt != nil {
+		a.approvalMinutes = append(a.approvalMinutes, batch.ApprovedAt.Sub(batch.CreatedAt).Minutes())
+	}
+}
+
+func (a *adoptionAccumulator) addEvent(event *model.BatchEvent) {
+	switch event.Trigger {
+	case constants.BatchEventTriggerManual:
+		a.manual++
+		if event.Operator != nil && *event.Operator != "" {
+			a.users[*event.Operator] = true
+		}
+	case constants.BatchEventTriggerScheduled:
+		a.scheduled++
+	default:
+		a.auto++
+	}
+	if event.ToStatus == constants.BatchStatusCompleted {
+		a.batchesCompleted++
+	}
+	a.touch(event.CreatedAt)
+}
+
+func (a *adoptionAccumulator) touch(t time.Time) {
+	if a.lastActivity == nil || t.After(*a.lastActivity) {
+		a.lastActivity = &t
+	}
+}
+
+func (a *adoptionAccumulator) stats() dto.AdoptionStats {
+	stats := dto.AdoptionStats{
+		BatchesCreated:   a.batchesCreated,
+		BatchesCompleted: a.batchesCompleted,
+		AppsOnboarded:    a.appsOnboarded,
+		AppsTotal:        a.appsTotal,
+		ManualActions:    a.manual,
+		ScheduledActions: a.scheduled,
+		AutoActions:      a.auto,
+		ActiveUsers:      len(a.users),
+		LastActivityAt:   a.lastActivity,
+		Approvals:        len(a.approvalMinutes),
+	}
+	if total := a.manual + a.scheduled + a.auto; total > 0 {
+		stats.AutomationRate = float64(a.scheduled+a.auto) / float64(total)
+	}
+	if len(a.approvalMinutes) > 0 {
+		sorted := append([]float64(nil), a.approvalMinutes...)
+		sort.Float64s(sorted)
+		var sum float64
+		for _, m := range sorted {
+			sum += m
+		}
+		stats.ApprovalLatencyAvgMinutes = sum / float64(len(sorted))
+		stats.ApprovalLatencyP50Minutes = percentile(sorted, 0.5)
+		stats.ApprovalLatencyP90Minutes = percentile(sorted, 0.9)
+	}
+	return stats
+}
+
+// percentile 最近秩法，sorted 需已升序
+func percentile(sorted []float64, p float64) float64 {
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
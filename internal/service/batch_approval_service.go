@@ -0,0 +1,285 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/pkg/constants"
+)
+
+// 审批阶段状态
+const (
+	ApprovalStageWaiting  = "waiting"  // 前序阶段未通过
+	ApprovalStagePending  = "pending"  // 当前审批阶段
+	ApprovalStageApproved = "approved" // 已通过
+	ApprovalStageRejected = "rejected" // 已拒绝
+)
+
+// approvalProgress 批次在审批策略下的进度
+type approvalProgress struct {
+	policy  *model.ApprovalPolicy
+	records []*model.BatchApproval
+	// 各阶段通过人
+	approvedBy [][]string
+	// 当前审批阶段序号；全部通过时为 len(stages)
+	current int
+}
+
+func newApprovalProgress(policy *model.ApprovalPolicy, records []*model.BatchApproval) *approvalProgress {
+	p := &approvalProgress{policy: policy, records: records, approvedBy: make([][]string, len(policy.Stages))}
+	for _, r := range records {
+		if r.Decision != constants.ApprovalStatusApproved || r.Stage < 0 || r.Stage >= len(policy.Stages) {
+			continue
+		}
+		// 只统计阶段审批人名单内的用户，同一人多次通过（如并发请求）只计一票
+		if !policy.Stages[r.Stage].IsApprover(r.Approver) || slices.Contains(p.approvedBy[r.Stage], r.Approver) {
+			continue
+		}
+		p.approvedBy[r.Stage] = append(p.approvedBy[r.Stage], r.Approver)
+	}
+	for p.current < len(policy.Stages) && len(p.approvedBy[p.current]) >= policy.Stages[p.current].Required {
+		p.current++
+	}
+	return p
+}
+
+func (p *approvalProgress) done() bool {
+	return p.current >= len(p.policy.Stages)
+}
+
+func (p *approvalProgress) hasDecided(stage int, username string) bool {
+	for _, r := range p.records {
+		if r.Stage == stage && r.Approver == username {
+			return true
+		}
+	}
+	return false
+}
+
+// loadApprovalPolicy 查询批次所属项目的审批策略，未配置时返回 nil
+func (s *BatchService) loadApprovalPolicy(tx *gorm.DB, projectID int64) (*model.ApprovalPolicy, error) {
+	var project model.Project
+	if err := tx.Select("id", "approval_policy").First(&project, projectID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("查询项目审批策略失败: %w", err)
+	}
+	if !project.ApprovalPolicy.IsEnabled() {
+		return nil, nil
+	}
+	return project.ApprovalPolicy, nil
+}
+
+func (s *BatchService) loadApprovalProgress(tx *gorm.DB, batchID int64, policy *model.ApprovalPolicy) (*approvalProgress, error) {
+	var records []*model.BatchApproval
+	if err := tx.Where("batch_id = ?", batchID).Order("id").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("查询审批记录失败: %w", err)
+	}
+	return newApprovalProgress(policy, records), nil
+}
+
+// decideStage 多人审批：operator 在当前阶段审批通过/拒绝，全部阶段通过后批次 approved，任一拒绝批次 rejected
+func (s *BatchService) decideStage(batch *model.Batch, policy *model.ApprovalPolicy, operator, decision, comment string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		progress, err := s.loadApprovalProgress(tx, batch.ID, policy)
+		if err != nil {
+			return err
+		}
+		if progress.done() {
+			return fmt.Errorf("批次审批阶段均已通过")
+		}
+		stage := policy.Stages[progress.current]
+		if !stage.IsApprover(operator) {
+			return fmt.Errorf("当前审批阶段「%s」的审批人不包含 %s", stage.Name, operator)
+		}
+		if progress.hasDecided(progress.current, operator) {
+			return fmt.Errorf("已审批过当前阶段「%s」", stage.Name)
+		}
+
+		record := &model.BatchApproval{
+			BatchID:   batch.ID,
+			Stage:     progress.current,
+			StageName: stage.Name,
+			Approver:  operator,
+			Decision:  decision,
+		}
+		if comment != "" {
+			record.Comment = &comment
+		}
+		if err := tx.Create(record).Error; err != nil {
+			return fmt.Errorf("记录审批失败: %w", err)
+		}
+
+		updates := map[string]interface{}{}
+		switch {
+		case decision == constants.ApprovalStatusRejected:
+			updates["approval_status"] = constants.ApprovalStatusRejected
+			updates["reject_reason"] = fmt.Sprintf("[%s] %s: %s", stage.Name, operator, comment)
+		case len(progress.approvedBy[progress.current])+1 >= stage.Required && progress.current == len(policy.Stages)-1:
+			updates["approval_status"] = constants.ApprovalStatusApproved
+			updates["approved_by"] = operator
+			updates["approved_at"] = time.Now()
+		default:
+			return nil
+		}
+
+		// 以 pending 为条件更新，避免并发审批重复流转
+		result := tx.Model(&model.Batch{}).
+			Where("id = ? AND approval_status = ?", batch.ID, constants.ApprovalStatusPending).
+			Updates(updates)
+		if result.Error != nil {
+			return fmt.Errorf("更新审批状态失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("批次审批状态已变化，请刷新后重试")
+		}
+		return nil
+	})
+}
+
+// GetBatchApprovals 查询批次审批进度（各阶段通过人数与审批记录）
+func (s *BatchService) GetBatchApprovals(batchID int64) (*dto.BatchApprovalResponse, error) {
+	batch, err := s.findBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := s.loadApprovalPolicy(s.db, batch.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.BatchApprovalResponse{
+		BatchID:        batch.ID,
+		ApprovalStatus: batch.ApprovalStatus,
+		Stages:         []dto.BatchApprovalStage{},
+		Records:        []dto.BatchApprovalRecord{},
+	}
+	if policy == nil {
+		return resp, nil
+	}
+	progress, err := s.loadApprovalProgress(s.db, batchID, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.MultiStage = true
+	for i, stage := range policy.Stages {
+		item := dto.BatchApprovalStage{
+			Stage:      i,
+			Name:       stage.Name,
+			Approvers:  stage.Approvers,
+			Required:   stage.Required,
+			ApprovedBy: progress.approvedBy[i],
+		}
+		if item.ApprovedBy == nil {
+			item.ApprovedBy = []string{}
+		}
+		switch {
+		case i < progress.current:
+			item.Status = ApprovalStageApproved
+		case i == progress.current && batch.ApprovalStatus == constants.ApprovalStatusRejected:
+			item.Status = ApprovalStageRejected
+		case i == progress.current && batch.ApprovalStatus == constants.ApprovalStatusPending:
+			item.Status = ApprovalStagePending
+		default:
+			item.Status = ApprovalStageWaiting
+		}
+		resp.Stages = append(resp.Stages, item)
+	}
+	if batch.ApprovalStatus == constants.ApprovalStatusPending && !progress.done() {
+		current := progress.current
+		resp.CurrentStage = &current
+	}
+	for _, r := range progress.records {
+		resp.Records = append(resp.Records, dto.BatchApprovalRecord{
+			Stage:     r.Stage,
+			StageName: r.StageName,
+			Approver:  r.Approver,
+			Decision:  r.Decision,
+			Comment:   r.Comment,
+			CreatedAt: r.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return resp, nil
+}
+
+// ListPendingApprovals 查询等待 username 审批的批次（username 是批次当前审批阶段的审批人且尚未审批）
+func (s *BatchService) ListPendingApprovals(username string) ([]dto.PendingApprovalItem, error) {
+	var projects []model.Project
+	if err := s.db.Select("id", "name", "approval_policy").Where("approval_policy IS NOT NULL").Find(&projects).Error; err != nil {
+		return nil, fmt.Errorf("查询项目审批策略失败: %w", err)
+	}
+	policies := make(map[int64]*model.ApprovalPolicy)
+	projectNames := make(map[int64]string)
+	var projectIDs []int64
+	for _, p := range projects {
+		if !p.ApprovalPolicy.IsEnabled() {
+			continue
+		}
+		for _, stage := range p.ApprovalPolicy.Stages {
+			if stage.IsApprover(username) {
+				policies[p.ID] = p.ApprovalPolicy
+				projectNames[p.ID] = p.Name
+				projectIDs = append(projectIDs, p.ID)
+				break
+			}
+		}
+	}
+
+	items := []dto.PendingApprovalItem{}
+	if len(projectIDs) == 0 {
+		return items, nil
+	}
+
+	var batches []model.Batch
+	if err := s.db.Where("project_id IN ? AND approval_status = ? AND status < ?",
+		projectIDs, constants.ApprovalStatusPending, constants.BatchStatusCompleted).
+		Order("id DESC").Find(&batches).Error; err != nil {
+		return nil, fmt.Errorf("查询待审批批次失败: %w", err)
+	}
+	for i := range batches {
+		batch := &batches[i]
+		policy := policies[batch.ProjectID]
+		progress, err := s.loadApprovalProgress(s.db, batch.ID, policy)
+		if err != nil {
+			return nil, err
+		}
+		if progress.done() {
+			continue
+		}
+		stage := policy.Stages[progress.current]
+		if !stage.IsApprover(username) || progress.hasDecided(progress.current, username) {
+			continue
+		}
+		items = append(items, dto.PendingApprovalItem{
+			BatchID:     batch.ID,
+			BatchNumber: batch.BatchNumber,
+			ProjectID:   batch.ProjectID,
+			ProjectName: projectNames[batch.ProjectID],
+			Initiator:   batch.Initiator,
+			Stage:       progress.current,
+			StageName:   stage.Name,
+			Approved:    len(progress.approvedBy[progress.current]),
+			Required:    stage.Required,
+			CreatedAt:   batch.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return items, nil
+}
+
+func logApprovalDecision(batch *model.Batch, operator, decision string) {
+	logger.Info("批次阶段审批",
+		zap.Int64("batch_id", batch.ID),
+		zap.String("batch_number", batch.BatchNumber),
+		zap.String("operator", operator),
+		zap.String("decision", decision))
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/pkg/constants"
+)
+
+// AutoBatchOperator 自动建批的批次发起人
+const AutoBatchOperator = "auto-batch"
+
+// autoBatchData 自动建批批次编号模板变量
+type autoBatchData struct {
+	Project string // 项目名
+	Tag     string // Git tag
+	Date    string // 当天日期（2006-01-02）
+}
+
+// AutoBatchForBuild 按项目自动建批规则处理新构建：Git tag 匹配时把应用加入同编号的草稿批次，批次不存在则创建
+//
+// 应用已在其他未完成批次中、同编号批次已封板时跳过；返回加入的批次 ID，未处理时返回 0
+func (s *BatchService) AutoBatchForBuild(appID int64, build *model.Build) (int64, error) {
+	if build.BuildStatus != constants.BuildStatusSuccess || !build.AppBuildSuccess || build.IsProvenanceMismatch() {
+		return 0, nil
+	}
+	tag := build.GitTag()
+	if tag == "" {
+		return 0, nil
+	}
+
+	var app model.Application
+	if err := s.db.Select("id", "name", "project_id", "tag_filter").First(&app, appID).Error; err != nil {
+		return 0, fmt.Errorf("查询应用失败: %w", err)
+	}
+	if !app.TagFilter.MatchBuild(build) {
+		return 0, nil
+	}
+	var project model.Project
+	if err := s.db.Select("id", "name", "auto_batch_rule").First(&project, app.ProjectID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("查询项目失败: %w", err)
+	}
+	rule := project.AutoBatchRule
+	if !rule.Match(tag) {
+		return 0, nil
+	}
+
+	batchNumber, err := renderAutoBatchNumber(rule, &autoBatchData{
+		Project: project.Name,
+		Tag:     tag,
+		Date:    time.Now().Format("2006-01-02"),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	log := logger.Log.With(zap.Int64("project_id", project.ID), zap.Int64("app_id", app.ID),
+		zap.String("tag", tag), zap.String("batch_number", batchNumber))
+
+	s.autoBatchMu.Lock()
+	defer s.autoBatchMu.Unlock()
+
+	var batch *model.Batch
+	created := false
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var exist model.Batch
+		err := tx.Where("project_id = ? AND batch_number = ?", project.ID, batchNumber).First(&exist).Error
+		switch {
+		case err == nil:
+			batch = &exist
+		case errors.Is(err, gorm.ErrRecordNotFound):
+		default:
+			return fmt.Errorf("查询批次失败: %w", err)
+		}
+
+		if batch != nil {
+			if batch.Status >= constants.BatchStatusSealed {
+				log.Info("自动建批: 同编号批次已封板，跳过", zap.Int64("batch_id", batch.ID))
+				batch = nil
+				return nil
+			}
+			var count int64
+			if err := tx.Model(&model.ReleaseApp{}).Where("batch_id = ? AND app_id = ?", batch.ID, app.ID).Count(&count).Error; err != nil {
+				return fmt.Errorf("查询批次应用失败: %w", err)
+			}
+			if count > 0 {
+				// 已在批次中，构建版本由 NewTag 更新
+				return nil
+			}
+		} else {
+			if batch, err = s.createBatch(tx, &dto.CreateBatchParam{
+				BatchNumber: batchNumber,
+				ProjectID:   project.ID,
+				Operator:    AutoBatchOperator,
+			}); err != nil {
+				return err
+			}
+			created = true
+		}
+
+		_, err = s.addReleaseApps(tx, batch, []dto.CreateBatchApp{{AppID: app.ID}})
+		return err
+	})
+	if err != nil {
+		var conflictErr *AppConflictError
+		if errors.As(err, &conflictErr) {
+			log.Info("自动建批: 应用已在其他未完成批次中，跳过")
+			return 0, nil
+		}
+		return 0, err
+	}
+	if batch == nil {
+		return 0, nil
+	}
+
+	log.Info("自动建批: 应用已加入草稿批次", zap.Int64("batch_id", batch.ID), zap.Bool("created", created))
+	return batch.ID, nil
+}
+
+func renderAutoBatchNumber(rule *model.AutoBatchRule, data *autoBatchData) (string, error) {
+	text := rule.BatchNumber
+	if text == "" {
+		text = model.DefaultAutoBatchNumber
+	}
+	t, err := template.New("batch_number").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("自动建批批次编号模板解析失败: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("自动建批批次编号渲染失败: %w", err)
+	}
+	batchNumber := strings.TrimSpace(buf.String())
+	if batchNumber == "" {
+		return "", fmt.Errorf("自动建批批次编号为空")
+	}
+	return batchNumber, nil
+}
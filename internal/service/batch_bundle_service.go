@@ -0,0 +1,352 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+)
+
+// 批次导出/导入（灾备站点、平台副本）
+//
+// - 导出已封板的批次：应用按名称引用，包含所选构建、临时依赖、values 覆盖与 chart/清单锁定引用
+// - 导入为目标项目的草稿批次：应用按名称映射，构建按构建号/镜像 tag/commit 匹配（可选按导出包创建），随后走正常封板流程
+// - chart lock 与 manifest lock 不直接导入，封板时在目标实例重新解析
+
+// ExportBatch 导出已封板批次
+func (s *BatchService) ExportBatch(batchID int64, operator string, canAccess func(projectID int64) bool) (*dto.BatchBundle, error) {
+	batch, err := s.findBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+	if !canAccess(batch.ProjectID) {
+		return nil, pkgErrors.ErrForbidden
+	}
+	if batch.Status == constants.BatchStatusDraft || batch.Status == constants.BatchStatusCancelled || batch.SealedAt == nil {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "仅已封板的批次可导出")
+	}
+
+	var project model.Project
+	if err := s.db.Select("id", "name").First(&project, batch.ProjectID).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询项目失败", err)
+	}
+	var releases []*model.ReleaseApp
+	if err := s.db.Preload("Build").Preload("Application.Repository").
+		Where("batch_id = ?", batch.ID).Order("id").Find(&releases).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询发布应用失败", err)
+	}
+
+	// 依赖按应用名导出
+	var depIDs []int64
+	for _, r := range releases {
+		if r.Application != nil {
+			depIDs = append(depIDs, r.Application.DefaultDependsOn...)
+		}
+		depIDs = append(depIDs, r.TempDependsOn...)
+	}
+	names, err := s.appNames(depIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &dto.BatchBundle{
+		Version:      dto.BatchBundleVersion,
+		ExportedAt:   time.Now(),
+		ExportedBy:   operator,
+		Project:      project.Name,
+		BatchID:      batch.ID,
+		BatchNumber:  batch.BatchNumber,
+		ReleaseNotes: batch.ReleaseNotes,
+		SealedAt:     batch.SealedAt,
+		Apps:         make([]dto.BatchBundleApp, 0, len(releases)),
+	}
+	for _, r := range releases {
+		if r.Application == nil || r.Build == nil {
+			return nil, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("发布应用 %d 缺少应用或构建记录，无法导出", r.ID))
+		}
+		item := dto.BatchBundleApp{
+			Name:             r.Application.Name,
+			ReleaseNotes:     r.ReleaseNotes,
+			PreOnly:          r.PreOnly,
+			TempDependsOn:    lookupNames(names, r.TempDependsOn),
+			DefaultDependsOn: lookupNames(names, r.Application.DefaultDependsOn),
+			Build:            toBatchBundleBuild(r.Build),
+			ValuesOverride:   r.ValuesOverride,
+		}
+		if repo := r.Application.Repository; repo != nil {
+			item.Repo = repo.Namespace + "/" + repo.Name
+		}
+		if r.ChartLock != nil && r.ChartLock.BuildID == r.Build.ID {
+			item.Charts = r.ChartLock.Charts
+		}
+		if r.ManifestLock.Active(r.Build.ID) {
+			item.ManifestRef = &dto.BatchBundleManifestRef{Ref: r.ManifestLock.Ref, File: r.ManifestLock.File}
+		}
+		bundle.Apps = append(bundle.Apps, item)
+	}
+
+	logger.Info("批次导出",
+		zap.Int64("batch_id", batch.ID),
+		zap.String("batch_number", batch.BatchNumber),
+		zap.Int("apps", len(bundle.Apps)),
+		zap.String("operator", operator))
+	return bundle, nil
+}
+
+// ImportBatch 将批次导出包导入为目标项目的草稿批次，应用按名称映射
+func (s *BatchService) ImportBatch(req *dto.ImportBatchRequest, operator string, canAccess func(projectID int64) bool) (*dto.ImportBatchResponse, error) {
+	if !canAccess(req.ProjectID) {
+		return nil, pkgErrors.ErrForbidden
+	}
+	bundle := &req.Bundle
+	batchNumber := strings.TrimSpace(renderOverride(req.BatchNumber))
+	if batchNumber == "" {
+		batchNumber = bundle.BatchNumber
+	}
+
+	// 应用按名称映射到目标项目
+	appNames := make([]string, 0, len(bundle.Apps))
+	for _, item := range bundle.Apps {
+		if slices.Contains(appNames, item.Name) {
+			return nil, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("导出包中应用 %s 重复", item.Name))
+		}
+		appNames = append(appNames, item.Name)
+		if item.ValuesOverride != nil && strings.TrimSpace(*item.ValuesOverride) != "" {
+			if err := validateValuesOverride(*item.ValuesOverride); err != nil {
+				return nil, err
+			}
+		}
+	}
+	var apps []*model.Application
+	if err := s.db.Where("project_id = ? AND name IN ?", req.ProjectID, appNames).Find(&apps).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用失败", err)
+	}
+	byName := make(map[string]*model.Application, len(apps))
+	for _, app := range apps {
+		byName[app.Name] = app
+	}
+	var missing []string
+	for _, name := range appNames {
+		if byName[name] == nil {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("目标项目中不存在应用: %s", strings.Join(missing, ", ")))
+	}
+
+	resp := &dto.ImportBatchResponse{Apps: make([]dto.ImportBatchApp, 0, len(bundle.Apps)), Warnings: []string{}}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		batch, err := s.createBatch(tx, &dto.CreateBatchParam{
+			BatchNumber:      batchNumber,
+			ReleaseNotes:     bundle.ReleaseNotes,
+			DependsOnBatchID: req.DependsOnBatchID,
+			ProjectID:        req.ProjectID,
+			Operator:         operator,
+		})
+		if err != nil {
+			return err
+		}
+		resp.BatchID, resp.BatchNumber = batch.ID, batch.BatchNumber
+
+		batchApps := make([]dto.CreateBatchApp, 0, len(bundle.Apps))
+		for _, item := range bundle.Apps {
+			batchApps = append(batchApps, dto.CreateBatchApp{AppID: byName[item.Name].ID, ReleaseNotes: item.ReleaseNotes, PreOnly: item.PreOnly})
+		}
+		if _, err := s.addReleaseApps(tx, batch, batchApps); err != nil {
+			return err
+		}
+
+		for _, item := range bundle.Apps {
+			app := byName[item.Name]
+			build, created, err := s.importBundleBuild(tx, app, item.Build, req.CreateBuilds)
+			if err != nil {
+				return err
+			}
+			updates := map[string]interface{}{
+				"build_id":        build.ID,
+				"target_tag":      build.ImageTag,
+				"latest_build_id": build.ID,
+				"values_override": item.ValuesOverride,
+			}
+			var deps model.Int64List
+			for _, name := range item.TempDependsOn {
+				if dep := byName[name]; dep != nil {
+					deps = append(deps, dep.ID)
+				}
+			}
+			if len(deps) > 0 {
+				updates["temp_depends_on"] = deps
+			}
+			if err := tx.Model(&model.ReleaseApp{}).Where("batch_id = ? AND app_id = ?", batch.ID, app.ID).
+				Updates(updates).Error; err != nil {
+				return fmt.Errorf("更新发布应用失败: %w", err)
+			}
+
+			resp.Apps = append(resp.Apps, dto.ImportBatchApp{
+				Name: app.Name, AppID: app.ID, BuildID: build.ID, ImageTag: build.ImageTag, BuildCreated: created,
+			})
+			if warning, err := s.compareDefaultDepends(tx, app, item.DefaultDependsOn); err != nil {
+				return err
+			} else if warning != "" {
+				resp.Warnings = append(resp.Warnings, warning)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("批次导入成功",
+		zap.Int64("batch_id", resp.BatchID),
+		zap.String("batch_number", resp.BatchNumber),
+		zap.Int64("project_id", req.ProjectID),
+		zap.Int64("source_batch_id", bundle.BatchID),
+		zap.String("source_project", bundle.Project),
+		zap.Int("apps", len(resp.Apps)),
+		zap.Int("warnings", len(resp.Warnings)),
+		zap.String("operator", operator))
+	return resp, nil
+}
+
+// importBundleBuild 在目标实例中匹配导出包的构建：优先同构建号，其次同镜像 tag 与 commit；都不存在时按 create 决定是否创建
+func (s *BatchService) importBundleBuild(tx *gorm.DB, app *model.Application, b *dto.BatchBundleBuild, create bool) (*model.Build, bool, error) {
+	var build model.Build
+	err := tx.Where("app_id = ? AND build_number = ?", app.ID, b.BuildNumber).First(&build).Error
+	if err == nil {
+		if build.ImageTag != b.ImageTag || !sameCommit(build.CommitSHA, b.CommitSHA) {
+			return nil, false, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf(
+				"应用 %s 构建 #%d 与导出包不一致（目标 %s@%s，导出包 %s@%s）",
+				app.Name, b.BuildNumber, build.ImageTag, shortSHA(build.CommitSHA), b.ImageTag, shortSHA(b.CommitSHA)))
+		}
+		return &build, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, fmt.Errorf("查询构建失败: %w", err)
+	}
+
+	err = tx.Where("app_id = ? AND image_tag = ? AND commit_sha = ? AND archived_at IS NULL", app.ID, b.ImageTag, b.CommitSHA).
+		Order("id DESC").First(&build).Error
+	if err == nil {
+		return &build, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, fmt.Errorf("查询构建失败: %w", err)
+	}
+	if !create {
+		return nil, false, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf(
+			"应用 %s 在目标实例中没有构建 #%d（%s），可设置 create_builds 按导出包创建", app.Name, b.BuildNumber, b.ImageTag))
+	}
+
+	build = model.Build{
+		RepoID:          app.RepoID,
+		AppID:           app.ID,
+		BuildNumber:     b.BuildNumber,
+		BuildStatus:     b.BuildStatus,
+		BuildEvent:      b.BuildEvent,
+		BuildLink:       b.BuildLink,
+		CommitSHA:       b.CommitSHA,
+		CommitRef:       b.CommitRef,
+		CommitBranch:    b.CommitBranch,
+		CommitMessage:   b.CommitMessage,
+		CommitLink:      b.CommitLink,
+		CommitAuthor:    b.CommitAuthor,
+		BuildCreated:    b.BuildCreated,
+		BuildStarted:    b.BuildStarted,
+		BuildFinished:   b.BuildFinished,
+		BuildDuration:   int(b.BuildFinished.Sub(b.BuildStarted).Seconds()),
+		ImageTag:        b.ImageTag,
+		ImageURL:        b.ImageURL,
+		AppBuildSuccess: true,
+		ImageDigest:     b.ImageDigest,
+		ImagePushedAt:   b.ImagePushedAt,
+	}
+	if build.BuildStatus == "" {
+		build.BuildStatus = "success"
+	}
+	if build.BuildEvent == "" {
+		build.BuildEvent = "tag"
+	}
+	if err := tx.Create(&build).Error; err != nil {
+		return nil, false, fmt.Errorf("创建应用 %s 的构建记录失败: %w", app.Name, err)
+	}
+	return &build, true, nil
+}
+
+// compareDefaultDepends 目标应用的配置级依赖与导出包不同时返回提示
+func (s *BatchService) compareDefaultDepends(tx *gorm.DB, app *model.Application, exported []string) (string, error) {
+	var current []string
+	if len(app.DefaultDependsOn) > 0 {
+		if err := tx.Model(&model.Application{}).Where("id IN ?", []int64(app.DefaultDependsOn)).
+			Pluck("name", &current).Error; err != nil {
+			return "", fmt.Errorf("查询依赖应用失败: %w", err)
+		}
+	}
+	slices.Sort(current)
+	want := slices.Sorted(slices.Values(exported))
+	if slices.Equal(current, want) {
+		return "", nil
+	}
+	return fmt.Sprintf("应用 %s 的配置级依赖与导出包不同（目标: [%s]，导出包: [%s]）",
+		app.Name, strings.Join(current, ", "), strings.Join(want, ", ")), nil
+}
+
+// appNames 按应用 ID 查询名称（含已删除的应用）
+func (s *BatchService) appNames(ids []int64) (map[int64]string, error) {
+	names := make(map[int64]string, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+	var apps []model.Application
+	if err := s.db.Unscoped().Select("id", "name").Where("id IN ?", ids).Find(&apps).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询依赖应用失败", err)
+	}
+	for _, app := range apps {
+		names[app.ID] = app.Name
+	}
+	return names, nil
+}
+
+// lookupNames 将应用 ID 列表转换为名称（找不到的应用忽略）
+func lookupNames(names map[int64]string, ids []int64) []string {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if name, ok := names[id]; ok {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+func toBatchBundleBuild(b *model.Build) *dto.BatchBundleBuild {
+	return &dto.BatchBundleBuild{
+		BuildNumber:   b.BuildNumber,
+		BuildStatus:   b.BuildStatus,
+		BuildEvent:    b.BuildEvent,
+		BuildLink:     b.BuildLink,
+		CommitSHA:     b.CommitSHA,
+		CommitRef:     b.CommitRef,
+		CommitBranch:  b.CommitBranch,
+		CommitMessage: b.CommitMessage,
+		CommitLink:    b.CommitLink,
+		CommitAuthor:  b.CommitAuthor,
+		BuildCreated:  b.BuildCreated,
+		BuildStarted:  b.BuildStarted,
+		BuildFinished: b.BuildFinished,
+		ImageTag:      b.ImageTag,
+		ImageURL:      b.ImageURL,
+		ImageDigest:   b.ImageDigest,
+		ImagePushedAt: b.ImagePushedAt,
+	}
+}
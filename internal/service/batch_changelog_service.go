@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"devops-cd/internal/core/changelog"
+	"devops-cd/internal/model"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"gorm.io/gorm"
+)
+
+// BatchChangelogService 批次变更日志（各应用 previous_deployed_tag → target_tag 之间的提交，按团队分组）
+type BatchChangelogService struct {
+	db        *gorm.DB
+	generator *changelog.Generator
+}
+
+func NewBatchChangelogService(db *gorm.DB) *BatchChangelogService {
+	return &BatchChangelogService{db: db, generator: changelog.NewGenerator(db)}
+}
+
+// Generate 生成批次变更日志；单个应用拉取提交失败时记录在该应用的 error 中，不影响整体结果
+func (s *BatchChangelogService) Generate(ctx context.Context, batchID int64, canAccess func(projectID int64) bool) (*changelog.Changelog, error) {
+	var batch model.Batch
+	if err := s.db.WithContext(ctx).Select("id", "project_id").Limit(1).Find(&batch, batchID).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询批次失败", err)
+	}
+	if batch.ID == 0 {
+		return nil, pkgErrors.New(pkgErrors.CodeNotFound, "批次不存在")
+	}
+	if !canAccess(batch.ProjectID) {
+		return nil, pkgErrors.ErrForbidden
+	}
+
+	out, err := s.generator.Generate(ctx, batchID)
+	if errors.Is(err, changelog.ErrBatchNotFound) {
+		return nil, pkgErrors.New(pkgErrors.CodeNotFound, "批次不存在")
+	}
+	if err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "生成变更日志失败", err)
+	}
+	return out, nil
+}
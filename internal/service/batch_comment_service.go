@@ -0,0 +1,294 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	pkgErrors "devops-cd/pkg/responses"
+)
+
+// 批次动态类型
+const (
+	BatchActivityComment    = "comment"
+	BatchActivityApproval   = "approval"
+	BatchActivityTransition = "transition"
+	BatchActivityAction     = "action"
+)
+
+// CreateBatchComment 发表批次/发布应用评论
+func (s *BatchService) CreateBatchComment(batchID int64, req *dto.CreateBatchCommentRequest, author string, canAccess func(projectID int64) bool) (*dto.BatchCommentResponse, error) {
+	batch, err := s.findAccessibleBatch(batchID, canAccess)
+	if err != nil {
+		return nil, err
+	}
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "评论内容不能为空")
+	}
+
+	comment := &model.BatchComment{
+		BatchID:   batch.ID,
+		ProjectID: batch.ProjectID,
+		Author:    author,
+		Content:   content,
+	}
+	if req.ReleaseAppID != nil {
+		var releaseApp model.ReleaseApp
+		if err := s.db.Select("id", "batch_id", "app_id").Preload("Application").
+			Where("id = ? AND batch_id = ?", *req.ReleaseAppID, batch.ID).First(&releaseApp).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "发布应用不在该批次内")
+			}
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询发布应用失败", err)
+		}
+		comment.ReleaseAppID = &releaseApp.ID
+		comment.AppID = &releaseApp.AppID
+		comment.Application = releaseApp.Application
+	}
+	if err := s.db.Omit("Application").Create(comment).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "保存评论失败", err)
+	}
+
+	logger.Info("批次评论",
+		zap.Int64("batch_id", batch.ID),
+		zap.Int64("comment_id", comment.ID),
+		zap.String("author", author))
+	return toBatchCommentResponse(comment), nil
+}
+
+// UpdateBatchComment 编辑评论（仅作者）
+func (s *BatchService) UpdateBatchComment(batchID, commentID int64, req *dto.UpdateBatchCommentRequest, operator string, canAccess func(projectID int64) bool) (*dto.BatchCommentResponse, error) {
+	comment, err := s.findOwnComment(batchID, commentID, operator, canAccess)
+	if err != nil {
+		return nil, err
+	}
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "评论内容不能为空")
+	}
+	comment.Content = content
+	if err := s.db.Omit("Application").Save(comment).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "保存评论失败", err)
+	}
+	return toBatchCommentResponse(comment), nil
+}
+
+// DeleteBatchComment 删除评论（仅作者）
+func (s *BatchService) DeleteBatchComment(batchID, commentID int64, operator string, canAccess func(projectID int64) bool) error {
+	comment, err := s.findOwnComment(batchID, commentID, operator, canAccess)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Delete(&model.BatchComment{}, comment.ID).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "删除评论失败", err)
+	}
+	return nil
+}
+
+// ListBatchComments 批次评论（按时间正序），可按发布应用过滤
+func (s *BatchService) ListBatchComments(batchID int64, req *dto.BatchCommentListRequest, canAccess func(projectID int64) bool) ([]*dto.BatchCommentResponse, error) {
+	if _, err := s.findAccessibleBatch(batchID, canAccess); err != nil {
+		return nil, err
+	}
+	q := s.db.Where("batch_id = ?", batchID).Preload("Application")
+	if req.ReleaseAppID != nil {
+		q = q.Where("release_app_id = ?", *req.ReleaseAppID)
+	}
+	var comments []*model.BatchComment
+	if err := q.Order("id").Find(&comments).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询评论失败", err)
+	}
+	return lo.Map(comments, func(c *model.BatchComment, _ int) *dto.BatchCommentResponse {
+		return toBatchCommentResponse(c)
+	}), nil
+}
+
+// GetBatchActivity 批次动态：评论、审批、状态变更（batch_events）、手动操作（暂停/恢复、手动部署操作）按时间正序合并
+func (s *BatchService) GetBatchActivity(batchID int64, canAccess func(projectID int64) bool) ([]*dto.BatchActivityItem, error) {
+	if _, err := s.findAccessibleBatch(batchID, canAccess); err != nil {
+		return nil, err
+	}
+
+	var comments []*model.BatchComment
+	if err := s.db.Where("batch_id = ?", batchID).Preload("Application").Find(&comments).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询评论失败", err)
+	}
+	var approvals []*model.BatchApproval
+	if err := s.db.Where("batch_id = ?", batchID).Find(&approvals).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询审批记录失败", err)
+	}
+	var events []*model.BatchEvent
+	if err := s.db.Where("batch_id = ?", batchID).Find(&events).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询状态变更记录失败", err)
+	}
+	// 引擎自动推进的部署事件数量大且已体现在批次状态中，只取手动操作
+	var depEvents []*model.DeploymentEvent
+	if err := s.db.Where("batch_id = ? AND operator IS NOT NULL AND operator <> ''", batchID).Find(&depEvents).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询部署事件失败", err)
+	}
+	deployments, err := s.activityDeployments(depEvents)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*dto.BatchActivityItem, 0, len(comments)+len(approvals)+len(events)+len(depEvents))
+	for _, c := range comments {
+		item := &dto.BatchActivityItem{
+			Type:    BatchActivityComment,
+			Time:    c.CreatedAt,
+			Actor:   c.Author,
+			Summary: "发表评论",
+			Detail:  lo.ToPtr(c.Content),
+			AppID:   c.AppID,
+			RefID:   c.ID,
+		}
+		if c.Application != nil {
+			item.AppName = c.Application.Name
+			item.Summary = fmt.Sprintf("评论应用 %s", c.Application.Name)
+		}
+		items = append(items, item)
+	}
+	for _, a := range approvals {
+		decision := "审批通过"
+		if a.Decision != "approved" {
+			decision = "审批拒绝"
+		}
+		items = append(items, &dto.BatchActivityItem{
+			Type:    BatchActivityApproval,
+			Time:    a.CreatedAt,
+			Actor:   a.Approver,
+			Summary: fmt.Sprintf("%s（%s）", decision, a.StageName),
+			Detail:  a.Comment,
+			RefID:   a.ID,
+		})
+	}
+	for _, e := range events {
+		item := &dto.BatchActivityItem{
+			Type:    BatchActivityTransition,
+			Time:    e.CreatedAt,
+			Actor:   lo.FromPtr(e.Operator),
+			Detail:  e.Reason,
+			RefID:   e.ID,
+			Trigger: e.Trigger,
+		}
+		if e.FromStatus == e.ToStatus {
+			// 状态不变的事件为暂停/恢复等手动操作，原因中记录了操作名
+			item.Type = BatchActivityAction
+			item.Summary = "批次操作"
+			if e.Reason != nil {
+				item.Summary, _, _ = strings.Cut(*e.Reason, ":")
+			}
+		} else {
+			item.FromState = getStatusName(e.FromStatus)
+			item.ToState = getStatusName(e.ToStatus)
+			item.Summary = fmt.Sprintf("%s → %s", item.FromState, item.ToState)
+		}
+		items = append(items, item)
+	}
+	for _, e := range depEvents {
+		item := &dto.BatchActivityItem{
+			Type:    BatchActivityAction,
+			Time:    e.CreatedAt,
+			Actor:   lo.FromPtr(e.Operator),
+			Detail:  e.Message,
+			RefID:   e.ID,
+			ToState: e.ToStatus,
+		}
+		target := fmt.Sprintf("部署 %d", e.DeploymentID)
+		if dep, ok := deployments[e.DeploymentID]; ok {
+			item.AppID = &dep.AppID
+			if dep.Application != nil {
+				item.AppName = dep.Application.Name
+			}
+			target = fmt.Sprintf("%s [%s/%s]", item.AppName, dep.Env, dep.ClusterName)
+		}
+		if e.FromStatus != nil {
+			item.FromState = *e.FromStatus
+			item.Summary = fmt.Sprintf("%s %s → %s", target, *e.FromStatus, e.ToStatus)
+		} else {
+			item.Summary = fmt.Sprintf("%s %s", target, e.Type)
+		}
+		items = append(items, item)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Time.Before(items[j].Time)
+	})
+	return items, nil
+}
+
+// activityDeployments 部署事件对应的部署（含应用名）
+func (s *BatchService) activityDeployments(events []*model.DeploymentEvent) (map[int64]*model.Deployment, error) {
+	ids := lo.Uniq(lo.Map(events, func(e *model.DeploymentEvent, _ int) int64 { return e.DeploymentID }))
+	out := make(map[int64]*model.Deployment, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	var deployments []*model.Deployment
+	if err := s.db.Select("id", "app_id", "env", "cluster").Where("id IN ?", ids).
+		Preload("Application", func(db *gorm.DB) *gorm.DB { return db.Select("id", "name") }).
+		Find(&deployments).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询部署失败", err)
+	}
+	for _, d := range deployments {
+		out[d.ID] = d
+	}
+	return out, nil
+}
+
+// findAccessibleBatch 查询批次并校验项目权限
+func (s *BatchService) findAccessibleBatch(batchID int64, canAccess func(projectID int64) bool) (*model.Batch, error) {
+	batch, err := s.findBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+	if !canAccess(batch.ProjectID) {
+		return nil, pkgErrors.ErrForbidden
+	}
+	return batch, nil
+}
+
+// findOwnComment 查询批次下的评论，只有作者可以修改/删除
+func (s *BatchService) findOwnComment(batchID, commentID int64, operator string, canAccess func(projectID int64) bool) (*model.BatchComment, error) {
+	if _, err := s.findAccessibleBatch(batchID, canAccess); err != nil {
+		return nil, err
+	}
+	var comment model.BatchComment
+	if err := s.db.Where("batch_id = ?", batchID).Preload("Application").First(&comment, commentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgErrors.New(pkgErrors.CodeNotFound, "评论不存在")
+		}
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询评论失败", err)
+	}
+	if comment.Author != operator {
+		return nil, pkgErrors.New(pkgErrors.CodeForbidden, "只能修改自己的评论")
+	}
+	return &comment, nil
+}
+
+func toBatchCommentResponse(c *model.BatchComment) *dto.BatchCommentResponse {
+	resp := &dto.BatchCommentResponse{
+		ID:           c.ID,
+		BatchID:      c.BatchID,
+		ReleaseAppID: c.ReleaseAppID,
+		AppID:        c.AppID,
+		Author:       c.Author,
+		Content:      c.Content,
+		Edited:       c.UpdatedAt.Sub(c.CreatedAt) > 0,
+		CreatedAt:    c.CreatedAt,
+		UpdatedAt:    c.UpdatedAt,
+	}
+	if c.Application != nil {
+		resp.AppName = c.Application.Name
+	}
+	return resp
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+)
+
+const (
+	BatchImpactStatusPending   = "pending"
+	BatchImpactStatusCollected = "collected"
+)
+
+// GetBatchImpact 获取批次部署影响（部署前 vs 批次完成后的副本数与资源声明差值）
+func (s *BatchService) GetBatchImpact(batchID int64) (*dto.BatchImpactResponse, error) {
+	var batch model.Batch
+	if err := s.db.First(&batch, batchID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("批次不存在")
+		}
+		return nil, fmt.Errorf("查询批次失败: %w", err)
+	}
+
+	var impacts []*model.BatchAppImpact
+	if err := s.db.Where("batch_id = ?", batchID).Preload("Application").
+		Order("env, cluster, app_id").Find(&impacts).Error; err != nil {
+		return nil, fmt.Errorf("查询部署影响失败: %w", err)
+	}
+
+	resp := &dto.BatchImpactResponse{
+		BatchID:     batch.ID,
+		BatchNumber: batch.BatchNumber,
+		Status:      BatchImpactStatusCollected,
+		Items:       make([]*dto.BatchImpactItem, 0, len(impacts)),
+		Clusters:    []*dto.BatchImpactClusterSummary{},
+	}
+	if batch.Status != constants.BatchStatusCompleted {
+		resp.Status = BatchImpactStatusPending
+	}
+
+	clusters := make(map[string]*dto.BatchImpactClusterSummary)
+	for _, impact := range impacts {
+		item := &dto.BatchImpactItem{
+			AppID:       impact.AppID,
+			Env:         impact.Env,
+			ClusterName: impact.ClusterName,
+			Namespace:   impact.Namespace,
+			ReleaseName: impact.ReleaseName,
+			Before:      impact.Before,
+			After:       impact.After,
+			Error:       impact.ErrorMessage,
+			CollectedAt: impact.AfterCollectedAt,
+		}
+		if impact.Application != nil {
+			item.AppName = impact.Application.Name
+		}
+		if impact.AfterCollectedAt == nil && impact.ErrorMessage == nil {
+			resp.Status = BatchImpactStatusPending
+		}
+		resp.Items = append(resp.Items, item)
+
+		if impact.Before == nil || impact.After == nil {
+			continue
+		}
+		delta := impact.After.Sub(*impact.Before)
+		item.Delta = &delta
+
+		key := impact.Env + "/" + impact.ClusterName
+		summary, ok := clusters[key]
+		if !ok {
+			summary = &dto.BatchImpactClusterSummary{Env: impact.Env, ClusterName: impact.ClusterName}
+			clusters[key] = summary
+			resp.Clusters = append(resp.Clusters, summary)
+		}
+		summary.Apps++
+		summary.Before = summary.Before.Add(*impact.Before)
+		summary.After = summary.After.Add(*impact.After)
+		summary.Delta = summary.Delta.Add(delta)
+		resp.Total = resp.Total.Add(delta)
+	}
+
+	sort.Slice(resp.Clusters, func(i, j int) bool {
+		if resp.Clusters[i].Env != resp.Clusters[j].Env {
+			return resp.Clusters[i].Env < resp.Clusters[j].Env
+		}
+		return resp.Clusters[i].ClusterName < resp.Clusters[j].ClusterName
+	})
+	return resp, nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"devops-cd/internal/core/deployment"
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+)
+
+// PlanBatch 预览批次部署计划：各应用解析后的构建、目标集群、deployment 名称、合并后的 values（脱敏）及依赖顺序
+//
+// 与 PreCanTrigger / ProdCanTrigger 使用相同的解析规则，但不创建 Deployment、不访问集群，用于封板前发现模板/配置错误
+func (s *BatchService) PlanBatch(ctx context.Context, batchID int64, canAccess func(projectID int64) bool) (*dto.BatchPlanResponse, error) {
+	batch, err := s.findBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+	if !canAccess(batch.ProjectID) {
+		return nil, pkgErrors.ErrForbidden
+	}
+
+	var releases []*model.ReleaseApp
+	if err := s.db.WithContext(ctx).Preload("Application").Preload("Build").
+		Where("batch_id = ?", batch.ID).Order("id").Find(&releases).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询发布应用失败", err)
+	}
+
+	appIDs := make([]int64, 0, len(releases))
+	appIDSet := make(map[int64]struct{}, len(releases))
+	for _, r := range releases {
+		appIDs = append(appIDs, r.AppID)
+		appIDSet[r.AppID] = struct{}{}
+	}
+	var envConfigs []model.AppEnvConfig
+	if len(appIDs) > 0 {
+		if err := s.db.WithContext(ctx).Where("app_id IN ? AND status = 1", appIDs).
+			Order("id").Find(&envConfigs).Error; err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用环境配置失败", err)
+		}
+	}
+	configsByApp := make(map[int64]map[string][]model.AppEnvConfig)
+	for _, cfg := range envConfigs {
+		if configsByApp[cfg.AppID] == nil {
+			configsByApp[cfg.AppID] = make(map[string][]model.AppEnvConfig)
+		}
+		configsByApp[cfg.AppID][cfg.Env] = append(configsByApp[cfg.AppID][cfg.Env], cfg)
+	}
+
+	resp := &dto.BatchPlanResponse{
+		BatchID:     batch.ID,
+		BatchNumber: batch.BatchNumber,
+		Valid:       true,
+		Apps:        make([]dto.BatchPlanApp, 0, len(releases)),
+		Errors:      []string{},
+	}
+	graph := make(map[int64][]int64, len(releases))
+	for _, r := range releases {
+		item := s.planReleaseApp(ctx, r, configsByApp[r.AppID])
+		var defaultDeps []int64
+		if r.Application != nil {
+			defaultDeps = r.Application.DefaultDependsOn
+		}
+		item.DependsOn = filterDependenciesForBatch(defaultDeps, r.TempDependsOn, appIDSet)
+		graph[r.AppID] = item.DependsOn
+		if len(item.Errors) > 0 {
+			resp.Valid = false
+		}
+		resp.Apps = append(resp.Apps, item)
+	}
+
+	order, ok := dependencyLevels(graph)
+	if !ok {
+		resp.Valid = false
+		resp.Errors = append(resp.Errors, "依赖配置存在循环，请调整")
+	}
+	resp.Order = order
+	if resp.Order == nil {
+		resp.Order = [][]int64{}
+	}
+	return resp, nil
+}
+
+// planReleaseApp 解析单个应用在 pre/prod 各集群上的部署计划，错误记录在结果中而不中断
+func (s *BatchService) planReleaseApp(ctx context.Context, r *model.ReleaseApp, configs map[string][]model.AppEnvConfig) dto.BatchPlanApp {
+	item := dto.BatchPlanApp{
+		ReleaseAppID: r.ID,
+		AppID:        r.AppID,
+		BuildID:      r.BuildID,
+		ImageTag:     r.TargetTag,
+		PreOnly:      r.PreOnly,
+		SkipProdEnv:  r.SkipProdEnv,
+		SkipPreEnv:   r.SkipPreEnv,
+		Targets:      []dto.BatchPlanTarget{},
+		Errors:       []string{},
+	}
+	if r.Application != nil {
+		item.AppName = r.Application.Name
+		item.AppType = r.Application.AppType
+	}
+
+	build := r.Build
+	switch {
+	case r.BuildID == nil || build == nil:
+		item.Errors = append(item.Errors, "未选择构建版本")
+		return item
+	case build.BuildStatus != constants.BuildStatusSuccess:
+		item.Errors = append(item.Errors, fmt.Sprintf("构建状态为 %s", build.BuildStatus))
+		return item
+	}
+	item.BuildNumber = build.BuildNumber
+	item.ImageTag = &build.ImageTag
+
+	var envs []string
+	if !r.SkipPreEnv {
+		envs = append(envs, constants.EnvTypePre)
+	}
+	if !r.PreOnly && !r.SkipProdEnv {
+		envs = append(envs, constants.EnvTypeProd)
+	}
+	for _, env := range envs {
+		envConfigs := configs[env]
+		if len(envConfigs) == 0 {
+			item.Errors = append(item.Errors, fmt.Sprintf("应用未配置 %s 环境", env))
+			continue
+		}
+		stages := map[string]int{}
+		if env == constants.EnvTypeProd {
+			var err error
+			if stages, _, err = model.PlanRolloutStages(envConfigs); err != nil {
+				item.Errors = append(item.Errors, fmt.Sprintf("prod 部署策略: %v", err))
+			}
+		}
+		for _, cfg := range envConfigs {
+			dep := &model.Deployment{
+				BatchID:     r.BatchID,
+				AppID:       r.AppID,
+				ReleaseID:   r.ID,
+				Env:         env,
+				ClusterName: cfg.Cluster,
+			}
+			plans, err := deployment.PlanDeployment(ctx, s.db, dep, build)
+			if err != nil {
+				item.Errors = append(item.Errors, fmt.Sprintf("%s/%s: %v", env, cfg.Cluster, err))
+				continue
+			}
+			for _, p := range plans {
+				item.Targets = append(item.Targets, dto.BatchPlanTarget{
+					Env:            env,
+					Cluster:        cfg.Cluster,
+					RolloutStage:   stages[cfg.Cluster],
+					Kind:           p.Kind,
+					DriverType:     p.DriverType,
+					Namespace:      p.Namespace,
+					DeploymentName: p.ReleaseName,
+					ChartName:      p.ChartName,
+					ChartVersion:   p.ChartVersion,
+					Values:         p.Values,
+				})
+			}
+		}
+	}
+	return item
+}
@@ -0,0 +1,455 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/database"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+)
+
+const (
+	QualityIntervalWeek  = "week"
+	QualityIntervalMonth = "month"
+
+	// qualityDefaultDays 质量报表默认统计天数
+	qualityDefaultDays = 90
+)
+
+// GetBatchRetro 获取批次复盘数据（故障与回滚记录）
+func (s *BatchService) GetBatchRetro(batchID int64) (*dto.BatchRetroResponse, error) {
+	if _, err := s.findBatch(batchID); err != nil {
+		return nil, err
+	}
+
+	var incidents []*model.BatchIncident
+	if err := s.db.Where("batch_id = ?", batchID).Order("started_at").Find(&incidents).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询故障记录失败", err)
+	}
+	var rollbacks []*model.BatchRollback
+	if err := s.db.Where("batch_id = ?", batchID).Preload("Application").Order("rolled_back_at").Find(&rollbacks).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询回滚记录失败", err)
+	}
+
+	resp := &dto.BatchRetroResponse{
+		BatchID:   batchID,
+		Failed:    len(incidents) > 0 || len(rollbacks) > 0,
+		Incidents: make([]*dto.BatchIncidentResponse, 0, len(incidents)),
+		Rollbacks: make([]*dto.BatchRollbackResponse, 0, len(rollbacks)),
+	}
+	for _, incident := range incidents {
+		resp.Incidents = append(resp.Incidents, toBatchIncidentResponse(incident))
+	}
+	for _, rollback := range rollbacks {
+		resp.Rollbacks = append(resp.Rollbacks, toBatchRollbackResponse(rollback))
+	}
+	return resp, nil
+}
+
+// CreateBatchIncident 为已完成批次记录发布后故障
+func (s *BatchService) CreateBatchIncident(batchID int64, req *dto.CreateBatchIncidentRequest, operator string, canAccess func(projectID int64) bool) (*dto.BatchIncidentResponse, error) {
+	batch, err := s.findCompletedBatch(batchID, canAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	appIDs := uniqueInt64s(req.AppIDs)
+	if len(appIDs) > 0 {
+		var count int64
+		if err := s.db.Model(&model.ReleaseApp{}).Where("batch_id = ? AND app_id IN ?", batchID, appIDs).Count(&count).Error; err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询批次应用失败", err)
+		}
+		if int(count) != len(appIDs) {
+			return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "相关应用必须在该批次内")
+		}
+	}
+
+	startedAt := time.Now()
+	if req.StartedAt != nil {
+		startedAt = *req.StartedAt
+	}
+	incident := &model.BatchIncident{
+		BatchID:         batch.ID,
+		ProjectID:       batch.ProjectID,
+		Severity:        req.Severity,
+		Title:           req.Title,
+		Description:     req.Description,
+		StartedAt:       startedAt,
+		DurationMinutes: req.DurationMinutes,
+		AppIDs:          appIDs,
+		CreatedBy:       operator,
+	}
+	if err := s.db.Create(incident).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "保存故障记录失败", err)
+	}
+	return toBatchIncidentResponse(incident), nil
+}
+
+// CreateBatchRollback 为已完成批次记录发布后回滚
+func (s *BatchService) CreateBatchRollback(batchID int64, req *dto.CreateBatchRollbackRequest, operator string, canAccess func(projectID int64) bool) (*dto.BatchRollbackResponse, error) {
+	batch, err := s.findCompletedBatch(batchID, canAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	var releaseApp model.ReleaseApp
+	if err := s.db.Where("batch_id = ? AND app_id = ?", batchID, req.AppID).Preload("Application").First(&releaseApp).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "回滚应用必须在该批次内")
+		}
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询批次应用失败", err)
+	}
+
+	rollback := &model.BatchRollback{
+		BatchID:      batch.ID,
+		ProjectID:    batch.ProjectID,
+		AppID:        req.AppID,
+		Env:          req.Env,
+		FromTag:      req.FromTag,
+		ToTag:        req.ToTag,
+		Reason:       req.Reason,
+		RolledBackAt: time.Now(),
+		CreatedBy:    operator,
+		Application:  releaseApp.Application,
+	}
+	if rollback.FromTag == nil {
+		rollback.FromTag = releaseApp.TargetTag
+	}
+	if rollback.ToTag == nil {
+		rollback.ToTag = releaseApp.PreviousDeployedTag
+	}
+	if req.RolledBackAt != nil {
+		rollback.RolledBackAt = *req.RolledBackAt
+	}
+	if err := s.db.Omit("Application").Create(rollback).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "保存回滚记录失败", err)
+	}
+	return toBatchRollbackResponse(rollback), nil
+}
+
+// DeleteBatchIncident 删除故障记录（录入错误时使用）
+func (s *BatchService) DeleteBatchIncident(batchID, incidentID int64, canAccess func(projectID int64) bool) error {
+	return s.deleteBatchRetroRecord(batchID, incidentID, &model.BatchIncident{}, canAccess)
+}
+
+// DeleteBatchRollback 删除回滚记录（录入错误时使用）
+func (s *BatchService) DeleteBatchRollback(batchID, rollbackID int64, canAccess func(projectID int64) bool) error {
+	return s.deleteBatchRetroRecord(batchID, rollbackID, &model.BatchRollback{}, canAccess)
+}
+
+func (s *BatchService) deleteBatchRetroRecord(batchID, id int64, record interface{}, canAccess func(projectID int64) bool) error {
+	if _, err := s.findCompletedBatch(batchID, canAccess); err != nil {
+		return err
+	}
+	result := s.db.Where("id = ? AND batch_id = ?", id, batchID).Delete(record)
+	if result.Error != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "删除记录失败", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return pkgErrors.ErrRecordNotFound
+	}
+	return nil
+}
+
+// QualityReport 按项目汇总发布质量（变更失败率、故障、回滚、MTTR）及趋势
+//
+// 统计口径: 以批次最终验收时间落在 [start, end] 内的已完成批次为分母，有故障或回滚记录的批次计为失败
+func (s *BatchService) QualityReport(query *dto.QualityReportQuery, canView func(projectID int64) bool) (*dto.QualityReportResponse, error) {
+	// 报表查询读副本
+	db := database.ReadReplica(s.db)
+
+	end := time.Now()
+	if query.End != nil {
+		end = *query.End
+	}
+	end = truncateDay(end).AddDate(0, 0, 1) // 含结束当天
+	start := end.AddDate(0, 0, -qualityDefaultDays)
+	if query.Start != nil {
+		start = truncateDay(*query.Start)
+	}
+	if !start.Before(end) {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "start 必须早于 end")
+	}
+	interval := query.Interval
+	if interval == "" {
+		interval = QualityIntervalWeek
+	}
+
+	if query.ProjectID != nil && !canView(*query.ProjectID) {
+		return nil, pkgErrors.ErrForbidden
+	}
+
+	batchQuery := db.Model(&model.Batch{}).
+		Select("id", "project_id", "final_accepted_at").
+		Where("status IN ? AND final_accepted_at >= ? AND final_accepted_at < ?", finalAcceptedStatuses, start, end)
+	if query.ProjectID != nil {
+		batchQuery = batchQuery.Where("project_id = ?", *query.ProjectID)
+	}
+	var batches []*model.Batch
+	if err := batchQuery.Find(&batches).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询批次失败", err)
+	}
+
+	batchIDs := make([]int64, 0, len(batches))
+	projectIDs := make([]int64, 0)
+	seenProject := make(map[int64]bool)
+	for _, batch := range batches {
+		if !seenProject[batch.ProjectID] {
+			seenProject[batch.ProjectID] = true
+			if query.ProjectID == nil && !canView(batch.ProjectID) {
+				continue
+			}
+			projectIDs = append(projectIDs, batch.ProjectID)
+		}
+		batchIDs = append(batchIDs, batch.ID)
+	}
+	if query.ProjectID != nil && len(projectIDs) == 0 {
+		projectIDs = append(projectIDs, *query.ProjectID)
+	}
+
+	var incidents []*model.BatchIncident
+	var rollbacks []*model.BatchRollback
+	if len(batchIDs) > 0 {
+		if err := db.Where("batch_id IN ?", batchIDs).Find(&incidents).Error; err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询故障记录失败", err)
+		}
+		if err := db.Where("batch_id IN ?", batchIDs).Find(&rollbacks).Error; err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询回滚记录失败", err)
+		}
+	}
+	incidentsByBatch := make(map[int64][]*model.BatchIncident)
+	for _, incident := range incidents {
+		incidentsByBatch[incident.BatchID] = append(incidentsByBatch[incident.BatchID], incident)
+	}
+	rollbacksByBatch := make(map[int64]int)
+	for _, rollback := range rollbacks {
+		rollbacksByBatch[rollback.BatchID]++
+	}
+
+	projectNames := make(map[int64]string)
+	if len(projectIDs) > 0 {
+		var projects []*model.Project
+		if err := db.Unscoped().Select("id", "name").Where("id IN ?", projectIDs).Find(&projects).Error; err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询项目失败", err)
+		}
+		for _, project := range projects {
+			projectNames[project.ID] = project.Name
+		}
+	}
+
+	periods := qualityPeriods(start, end, interval)
+	reports := make(map[int64]*dto.ProjectQualityReport, len(projectIDs))
+	accumulators := make(map[int64]*qualityAccumulator, len(projectIDs))
+	trendAccumulators := make(map[int64][]*qualityAccumulator, len(projectIDs))
+	resp := &dto.QualityReportResponse{
+		Start:    start.Format("2006-01-02"),
+		End:      end.AddDate(0, 0, -1).Format("2006-01-02"),
+		Interval: interval,
+		Projects: make([]*dto.ProjectQualityReport, 0, len(projectIDs)),
+	}
+	for _, projectID := range projectIDs {
+		report := &dto.ProjectQualityReport{ProjectID: projectID, ProjectName: projectNames[projectID]}
+		reports[projectID] = report
+		accumulators[projectID] = &qualityAccumulator{}
+		trend := make([]*qualityAccumulator, len(periods))
+		for i := range trend {
+			trend[i] = &qualityAccumulator{}
+		}
+		trendAccumulators[projectID] = trend
+		resp.Projects = append(resp.Projects, report)
+	}
+
+	for _, batch := range batches {
+		total, ok := accumulators[batch.ProjectID]
+		if !ok {
+			continue
+		}
+		idx := periodIndex(periods, *batch.FinalAcceptedAt)
+		for _, acc := range []*qualityAccumulator{total, trendAccumulators[batch.ProjectID][idx]} {
+			acc.add(incidentsByBatch[batch.ID], rollbacksByBatch[batch.ID])
+		}
+	}
+
+	for projectID, report := range reports {
+		report.QualityStats = accumulators[projectID].stats()
+		report.Trend = make([]*dto.QualityTrendPoint, len(periods))
+		for i, acc := range trendAccumulators[projectID] {
+			report.Trend[i] = &dto.QualityTrendPoint{Period: periods[i].Format("2006-01-02"), QualityStats: acc.stats()}
+		}
+	}
+	sort.Slice(resp.Projects, func(i, j int) bool {
+		return resp.Projects[i].ProjectID < resp.Projects[j].ProjectID
+	})
+	return resp, nil
+}
+
+// findBatch 查询批次
+func (s *BatchService) findBatch(batchID int64) (*model.Batch, error) {
+	var batch model.Batch
+	if err := s.db.First(&batch, batchID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, pkgErrors.New(pkgErrors.CodeNotFound, "批次不存在")
+		}
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询批次失败", err)
+	}
+	return &batch, nil
+}
+
+// finalAcceptedStatuses 已完成批次的状态（含完成后又被回滚的批次，以 final_accepted_at 区分）
+var finalAcceptedStatuses = []int8{
+	constants.BatchStatusCompleted,
+	constants.BatchStatusRollingBack,
+	constants.BatchStatusRolledBack,
+	constants.BatchStatusRollbackFailed,
+}
+
+// findCompletedBatch 查询已完成批次并校验项目权限（复盘数据只能记录在已完成批次上）
+func (s *BatchService) findCompletedBatch(batchID int64, canAccess func(projectID int64) bool) (*model.Batch, error) {
+	batch, err := s.findBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+	if !canAccess(batch.ProjectID) {
+		return nil, pkgErrors.ErrForbidden
+	}
+	if batch.FinalAcceptedAt == nil || !lo.Contains(finalAcceptedStatuses, batch.Status) {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("批次当前状态为 %s，仅已完成批次可记录故障/回滚", constants.BatchStatusToString(batch.Status)))
+	}
+	return batch, nil
+}
+
+// qualityAccumulator 质量指标累加器
+type qualityAccumulator struct {
+	batches, failed, incidents, rollbacks, durationMinutes int
+	bySeverity                                             map[string]int
+}
+
+func (a *qualityAccumulator) add(incidents []*model.BatchIncident, rollbacks int) {
+	a.batches++
+	if len(incidents) > 0 || rollbacks > 0 {
+		a.failed++
+	}
+	a.rollbacks += rollbacks
+	for _, incident := range incidents {
+		a.incidents++
+		a.durationMinutes += incident.DurationMinutes
+		if a.bySeverity == nil {
+			a.bySeverity = make(map[string]int)
+		}
+		a.bySeverity[incident.Severity]++
+	}
+}
+
+func (a *qualityAccumulator) stats() dto.QualityStats {
+	stats := dto.QualityStats{
+		Batches:        a.batches,
+		FailedBatches:  a.failed,
+		Incidents:      a.incidents,
+		IncidentsBySev: map[string]int{},
+		Rollbacks:      a.rollbacks,
+	}
+	for severity, count := range a.bySeverity {
+		stats.IncidentsBySev[severity] = count
+	}
+	if a.batches > 0 {
+		stats.ChangeFailureRate = float64(a.failed) / float64(a.batches)
+	}
+	if a.incidents > 0 {
+		stats.MTTRMinutes = float64(a.durationMinutes) / float64(a.incidents)
+	}
+	return stats
+}
+
+// qualityPeriods 生成 [start, end) 内各周期的起始时间（周一 / 月初）
+func qualityPeriods(start, end time.Time, interval string) []time.Time {
+	var periods []time.Time
+	cur := truncateDay(start)
+	if interval == QualityIntervalMonth {
+		cur = time.Date(cur.Year(), cur.Month(), 1, 0, 0, 0, 0, cur.Location())
+	} else {
+		cur = cur.AddDate(0, 0, -((int(cur.Weekday()) + 6) % 7))
+	}
+	for cur.Before(end) {
+		periods = append(periods, cur)
+		if interval == QualityIntervalMonth {
+			cur = cur.AddDate(0, 1, 0)
+		} else {
+			cur = cur.AddDate(0, 0, 7)
+		}
+	}
+	return periods
+}
+
+// periodIndex 返回时间所在周期下标
+func periodIndex(periods []time.Time, t time.Time) int {
+	idx := sort.Search(len(periods), func(i int) bool { return periods[i].After(t) }) - 1
+	if idx < 0 {
+		return 0
+	}
+	return idx
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func uniqueInt64s(ids []int64) model.Int64List {
+	seen := make(map[int64]bool, len(ids))
+	out := make(model.Int64List, 0, len(ids))
+	for _, id := range ids {
+		if id > 0 && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func toBatchIncidentResponse(incident *model.BatchIncident) *dto.BatchIncidentResponse {
+	appIDs := []int64(incident.AppIDs)
+	if appIDs == nil {
+		appIDs = []int64{}
+	}
+	return &dto.BatchIncidentResponse{
+		ID:              incident.ID,
+		BatchID:         incident.BatchID,
+		Severity:        incident.Severity,
+		Title:           incident.Title,
+		Description:     incident.Description,
+		StartedAt:       incident.StartedAt,
+		DurationMinutes: incident.DurationMinutes,
+		AppIDs:          appIDs,
+		CreatedBy:       incident.CreatedBy,
+		CreatedAt:       incident.CreatedAt,
+	}
+}
+
+func toBatchRollbackResponse(rollback *model.BatchRollback) *dto.BatchRollbackResponse {
+	resp := &dto.BatchRollbackResponse{
+		ID:           rollback.ID,
+		BatchID:      rollback.BatchID,
+		AppID:        rollback.AppID,
+		Env:          rollback.Env,
+		FromTag:      rollback.FromTag,
+		ToTag:        rollback.ToTag,
+		Reason:       rollback.Reason,
+		RolledBackAt: rollback.RolledBackAt,
+		CreatedBy:    rollback.CreatedBy,
+		CreatedAt:    rollback.CreatedAt,
+		ReleaseID:    rollback.ReleaseID,
+		Status:       rollback.Status,
+		FinishedAt:   rollback.FinishedAt,
+		ErrorMessage: rollback.ErrorMessage,
+	}
+	if rollback.Application != nil {
+		resp.AppName = rollback.Application.Name
+	}
+	return resp
+}
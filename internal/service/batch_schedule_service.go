@@ -0,0 +1,182 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"devops-cd/internal/core/batch/transitions"
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+)
+
+// BatchEventProcessor 批次状态流转（由 core 引擎实现），定时部署到点后调用
+type BatchEventProcessor interface {
+	ProcessScheduledBatchEvent(batchID int64, event string, operator, reason string) error
+}
+
+// batchScheduleSpec 定时部署环境对应的列与触发动作
+type batchScheduleSpec struct {
+	env    string
+	label  string
+	atCol  string
+	byCol  string
+	action string
+}
+
+var batchScheduleSpecs = []batchScheduleSpec{
+	{env: constants.EnvTypePre, label: "预发布", atCol: "scheduled_pre_at", byCol: "scheduled_pre_by", action: constants.BatchActionStartPre},
+	{env: constants.EnvTypeProd, label: "生产部署", atCol: "scheduled_prod_at", byCol: "scheduled_prod_by", action: constants.BatchActionStartProd},
+}
+
+func scheduleSpec(env string) batchScheduleSpec {
+	if env == constants.EnvTypeProd {
+		return batchScheduleSpecs[1]
+	}
+	return batchScheduleSpecs[0]
+}
+
+func (spec batchScheduleSpec) of(batch *model.Batch) (*time.Time, *string) {
+	if spec.env == constants.EnvTypeProd {
+		return batch.ScheduledProdAt, batch.ScheduledProdBy
+	}
+	return batch.ScheduledPreAt, batch.ScheduledPreBy
+}
+
+// SetBatchSchedule 设置批次定时开始预发布/生产部署（生产需 prod 操作权限）
+func (s *BatchService) SetBatchSchedule(batchID int64, req *dto.SetBatchScheduleRequest, operator string, canProdOperate func(projectID int64) bool) (*dto.BatchScheduleResponse, error) {
+	batch, err := s.findBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+	spec := scheduleSpec(req.Env)
+	if spec.env == constants.EnvTypeProd && !canProdOperate(batch.ProjectID) {
+		return nil, pkgErrors.ErrForbidden
+	}
+	// 定时生产部署以设置人作为触发人，提前按双人原则校验（审批在设置之后发生时，到点触发仍会校验）
+	if spec.env == constants.EnvTypeProd {
+		if reason, err := transitions.TwoPersonBlockReason(s.db, batch, operator); err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "校验双人原则失败", err)
+		} else if reason != "" {
+			return nil, pkgErrors.New(pkgErrors.CodeForbidden, reason)
+		}
+	}
+
+	at := req.ScheduledAt.Local()
+	if !at.After(time.Now()) {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "计划时间需晚于当前时间")
+	}
+	// 只能为尚未开始的阶段设置定时
+	startedStatus := constants.BatchStatusPreWaiting
+	if spec.env == constants.EnvTypeProd {
+		startedStatus = constants.BatchStatusProdWaiting
+	}
+	if batch.Status >= startedStatus {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("批次当前状态为 %s，%s已开始或已结束，无法设置定时", constants.BatchStatusToString(batch.Status), spec.label))
+	}
+	if spec.env == constants.EnvTypeProd && batch.ScheduledPreAt != nil && !at.After(*batch.ScheduledPreAt) {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "定时生产部署时间需晚于定时预发布时间")
+	}
+	if spec.env == constants.EnvTypePre && batch.ScheduledProdAt != nil && !at.Before(*batch.ScheduledProdAt) {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "定时预发布时间需早于定时生产部署时间")
+	}
+
+	if err := s.updateSchedule(batch.ID, spec, &at, &operator); err != nil {
+		return nil, err
+	}
+	logger.Info("设置批次定时部署", zap.Int64("batch_id", batch.ID), zap.String("env", spec.env),
+		zap.Time("scheduled_at", at), zap.String("operator", operator))
+	return s.batchSchedule(batch.ID)
+}
+
+// CancelBatchSchedule 取消批次定时部署
+func (s *BatchService) CancelBatchSchedule(batchID int64, env string, operator string, canProdOperate func(projectID int64) bool) (*dto.BatchScheduleResponse, error) {
+	batch, err := s.findBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+	spec := scheduleSpec(env)
+	if spec.env == constants.EnvTypeProd && !canProdOperate(batch.ProjectID) {
+		return nil, pkgErrors.ErrForbidden
+	}
+	if at, _ := spec.of(batch); at == nil {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("批次未设置定时%s", spec.label))
+	}
+
+	if err := s.updateSchedule(batch.ID, spec, nil, nil); err != nil {
+		return nil, err
+	}
+	logger.Info("取消批次定时部署", zap.Int64("batch_id", batch.ID), zap.String("env", spec.env), zap.String("operator", operator))
+	return s.batchSchedule(batch.ID)
+}
+
+// FireDueBatchSchedules 触发已到计划时间的定时部署，返回成功触发数
+// 先按计划时间 CAS 清空定时再触发：多实例同时调度时只有一个实例触发；触发失败（如批次状态不满足）仅记录日志，不再重试
+func (s *BatchService) FireDueBatchSchedules(processor BatchEventProcessor) (int, error) {
+	now := time.Now()
+	fired := 0
+	for _, spec := range batchScheduleSpecs {
+		var batches []model.Batch
+		if err := s.db.Where(spec.atCol+" IS NOT NULL AND "+spec.atCol+" <= ?", now).Find(&batches).Error; err != nil {
+			return fired, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询定时部署批次失败", err)
+		}
+		for i := range batches {
+			batch := &batches[i]
+			at, by := spec.of(batch)
+			if at == nil {
+				continue
+			}
+			res := s.db.Table(model.BatchTableName).
+				Where("id = ? AND "+spec.atCol+" = ?", batch.ID, *at).
+				Updates(map[string]interface{}{spec.atCol: nil, spec.byCol: nil})
+			if res.Error != nil {
+				return fired, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "清除批次定时部署失败", res.Error)
+			}
+			if res.RowsAffected == 0 {
+				continue
+			}
+
+			operator := "scheduler"
+			if by != nil && *by != "" {
+				operator = *by
+			}
+			reason := fmt.Sprintf("定时%s（计划时间 %s）", spec.label, at.Format(time.RFC3339))
+			if err := processor.ProcessScheduledBatchEvent(batch.ID, spec.action, operator, reason); err != nil {
+				logger.Warn("定时部署触发失败", zap.Int64("batch_id", batch.ID), zap.String("env", spec.env),
+					zap.String("status", constants.BatchStatusToString(batch.Status)), zap.Error(err))
+				continue
+			}
+			logger.Info("定时部署已触发", zap.Int64("batch_id", batch.ID), zap.String("env", spec.env), zap.String("operator", operator))
+			fired++
+		}
+	}
+	return fired, nil
+}
+
+func (s *BatchService) updateSchedule(batchID int64, spec batchScheduleSpec, at *time.Time, by *string) error {
+	err := s.db.Table(model.BatchTableName).Where("id = ?", batchID).
+		Updates(map[string]interface{}{spec.atCol: at, spec.byCol: by}).Error
+	if err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "更新批次定时部署失败", err)
+	}
+	return nil
+}
+
+func (s *BatchService) batchSchedule(batchID int64) (*dto.BatchScheduleResponse, error) {
+	batch, err := s.findBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.BatchScheduleResponse{
+		BatchID:         batch.ID,
+		Status:          batch.Status,
+		ScheduledPreAt:  dto.FormatTime(batch.ScheduledPreAt),
+		ScheduledPreBy:  batch.ScheduledPreBy,
+		ScheduledProdAt: dto.FormatTime(batch.ScheduledProdAt),
+		ScheduledProdBy: batch.ScheduledProdBy,
+	}, nil
+}
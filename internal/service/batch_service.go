@@ -3,12 +3,14 @@ package service
 import (
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
 
+	"devops-cd/internal/core/batch/transitions"
 	"devops-cd/internal/dto"
 	"devops-cd/internal/model"
 	"devops-cd/internal/pkg/config"
@@ -25,6 +27,28 @@ type BatchService struct {
 	buildRepo      repository.BuildRepository
 
 	db *gorm.DB
+
+	// 自动建批串行执行，避免同一 tag 的并发构建重复创建批次
+	autoBatchMu sync.Mutex
+
+	// 直接修改数据库的操作（审批、重试部署等）完成后通知引擎立即处理批次，未设置时等待引擎扫描
+	trigger BatchTrigger
+}
+
+// BatchTrigger 通知引擎立即处理批次（由 core 引擎实现）
+type BatchTrigger interface {
+	TriggerBatch(batchID int64)
+}
+
+// SetBatchTrigger 设置批次触发器
+func (s *BatchService) SetBatchTrigger(t BatchTrigger) {
+	s.trigger = t
+}
+
+func (s *BatchService) triggerBatch(batchID int64) {
+	if s.trigger != nil {
+		s.trigger.TriggerBatch(batchID)
+	}
 }
 
 // NewBatchService 创建批次服务
@@ -41,40 +65,9 @@ func NewBatchService(db *gorm.DB) *BatchService {
 func (s *BatchService) CreateBatch(req *dto.CreateBatchParam) (*model.Batch, error) {
 	var batch *model.Batch
 	err := s.db.Transaction(func(tx *gorm.DB) error {
-		// 1. 验证项目是否存在
-		var project model.Project
-		if err := tx.First(&project, req.ProjectID).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return fmt.Errorf("项目 ID %d 不存在", req.ProjectID)
-			}
-			return fmt.Errorf("查询项目失败: %w", err)
-		}
-
-		// 2. 检查批次编号在项目内是否重复
-		var existBatch model.Batch
-		err := tx.Where("batch_number = ? AND project_id = ?", req.BatchNumber, req.ProjectID).
-			First(&existBatch).Error
-		if err == nil {
-			return fmt.Errorf("批次编号 %s 在该项目下已存在", req.BatchNumber)
-		}
-		if err != gorm.ErrRecordNotFound {
-			return fmt.Errorf("查询批次失败: %w", err)
-		}
-
-		// 3. 创建批次
-		batch = &model.Batch{
-			BatchNumber:    req.BatchNumber,
-			ProjectID:      req.ProjectID,
-			Initiator:      req.Operator,
-			ReleaseNotes:   req.ReleaseNotes,
-			Status:         constants.BatchStatusDraft,      // 草稿状态
-			ApprovalStatus: constants.ApprovalStatusPending, // 待审批
-		}
-		if err := tx.Create(batch).Error; err != nil {
-			return fmt.Errorf("创建批次失败: %w", err)
-		}
-
-		return nil
+		var err error
+		batch, err = s.createBatch(tx, req)
+		return err
 	})
 
 	if err != nil {
@@ -86,6 +79,52 @@ func (s *BatchService) CreateBatch(req *dto.CreateBatchParam) (*model.Batch, err
 	return batch, nil
 }
 
+// createBatch 在事务内创建草稿批次
+func (s *BatchService) createBatch(tx *gorm.DB, req *dto.CreateBatchParam) (*model.Batch, error) {
+	// 1. 验证项目是否存在
+	var project model.Project
+	if err := tx.First(&project, req.ProjectID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("项目 ID %d 不存在", req.ProjectID)
+		}
+		return nil, fmt.Errorf("查询项目失败: %w", err)
+	}
+
+	// 2. 检查批次编号在项目内是否重复
+	var existBatch model.Batch
+	err := tx.Where("batch_number = ? AND project_id = ?", req.BatchNumber, req.ProjectID).
+		First(&existBatch).Error
+	if err == nil {
+		return nil, fmt.Errorf("批次编号 %s 在该项目下已存在", req.BatchNumber)
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("查询批次失败: %w", err)
+	}
+
+	// 3. 校验前置批次
+	if req.DependsOnBatchID != nil {
+		if err := validatePredecessor(tx, 0, *req.DependsOnBatchID); err != nil {
+			return nil, err
+		}
+	}
+
+	// 4. 创建批次
+	batch := &model.Batch{
+		BatchNumber:      req.BatchNumber,
+		ProjectID:        req.ProjectID,
+		Initiator:        req.Operator,
+		ReleaseNotes:     req.ReleaseNotes,
+		DependsOnBatchID: req.DependsOnBatchID,
+		Status:           constants.BatchStatusDraft,      // 草稿状态
+		ApprovalStatus:   constants.ApprovalStatusPending, // 待审批
+	}
+	if err := tx.Create(batch).Error; err != nil {
+		return nil, fmt.Errorf("创建批次失败: %w", err)
+	}
+
+	return batch, nil
+}
+
 // UpdateBatch 更新批次
 func (s *BatchService) UpdateBatch(req *dto.UpdateBatchParam) (*model.Batch, map[string]interface{}, error) {
 	// 1. 获取批次
@@ -122,6 +161,18 @@ func (s *BatchService) UpdateBatch(req *dto.UpdateBatchParam) (*model.Batch, map
 			updatedFields["release_notes"] = *req.ReleaseNotes
 		}
 
+		if req.DependsOnBatchID != nil {
+			if *req.DependsOnBatchID == 0 {
+				batch.DependsOnBatchID = nil
+			} else {
+				if err := validatePredecessor(tx, batch.ID, *req.DependsOnBatchID); err != nil {
+					return err
+				}
+				batch.DependsOnBatchID = req.DependsOnBatchID
+			}
+			updatedFields["depends_on_batch_id"] = *req.DependsOnBatchID
+		}
+
 		// 4. 删除应用
 		if len(req.RemoveAppIDs) > 0 {
 			if err = s.releaseAppRepo.DeleteByAppIDs(tx, batch.ID, req.RemoveAppIDs); err != nil {
@@ -132,87 +183,41 @@ func (s *BatchService) UpdateBatch(req *dto.UpdateBatchParam) (*model.Batch, map
 
 		// 5. 添加应用
 		if len(req.AddApps) > 0 {
-			// 提取应用ID列表
-			addAppIDs := make([]int64, len(req.AddApps))
-			for i, app := range req.AddApps {
-				addAppIDs[i] = app.AppID
-			}
-
-			// 查询应用
-			var addApps map[int64]*model.Application
-			if addApps, err = s.appRepo.FindByIDs(tx, addAppIDs, repository.WithPreloadEnvConfigs()); err != nil {
-				return fmt.Errorf("查询应用失败: %w", err)
-			}
-			if len(addApps) != len(addAppIDs) {
-				return fmt.Errorf("部分应用不存在")
-			}
-			for _, app := range addApps {
-				if app.ProjectID != batch.ProjectID {
-					return fmt.Errorf("应用 %s (ID: %d) 不属于项目 (ID: %d)", app.Name, app.ID, batch.ProjectID)
-				}
-			}
-
-			// 检查应用冲突
-			var conflicts map[int64]*model.Batch
-			if conflicts, err = s.batchRepo.CheckAppConflict(tx, addAppIDs, &batch.ID); err != nil {
-				return fmt.Errorf("检查应用冲突失败: %w", err)
-			} else if len(conflicts) > 0 {
-				appMap, _ := s.appRepo.FindByIDs(tx, addAppIDs)
-				return &AppConflictError{Conflicts: conflicts, AppMap: appMap}
-			}
-
-			// 获取build信息
-			buildMap, err := s.batchRepo.GetLatestBuildsAfterDeployment(tx, addAppIDs)
+			addAppIDs, err := s.addReleaseApps(tx, batch, req.AddApps)
 			if err != nil {
-				return fmt.Errorf("查询部署后最新构建失败: %w", err)
+				return err
 			}
 
-			releaseApps := make([]*model.ReleaseApp, 0, len(req.AddApps))
-			for _, app := range req.AddApps {
-				build, hasBuild := buildMap[app.AppID]
+			updatedFields["add_apps"] = addAppIDs
+		}
 
-				releaseApp := &model.ReleaseApp{
-					BatchID:      batch.ID,
-					AppID:        app.AppID,
-					ReleaseNotes: app.ReleaseNotes,
-					IsLocked:     false,
+		// 6. 修改 pre_only 标记（封板时校验预发布环境），同步 skip_prod_env（未配置生产环境的应用始终跳过生产）
+		if len(req.PreOnlyApps) > 0 {
+			for appID, preOnly := range req.PreOnlyApps {
+				result := tx.Model(&model.ReleaseApp{}).
+					Where("batch_id = ? AND app_id = ?", batch.ID, appID).
+					Updates(map[string]interface{}{
+						"pre_only": preOnly,
+						"skip_prod_env": gorm.Expr(`? OR NOT EXISTS(
+							SELECT 1 FROM app_env_configs
+							WHERE app_id = release_apps.app_id AND env = 'prod' AND status = 1 AND deleted_at IS NULL
+						)`, preOnly),
+					})
+				if result.Error != nil {
+					return fmt.Errorf("更新 pre_only 失败: %w", result.Error)
 				}
-
-				var hasPre bool
-				envConfigs := addApps[app.AppID].EnvConfigs
-				if envConfigs != nil {
-					for _, envConfig := range envConfigs {
-						if envConfig.Env == constants.EnvTypePre && envConfig.Cluster != "" {
-							hasPre = true
-							break
-						}
+				if result.RowsAffected == 0 {
+					var exists int64
+					tx.Model(&model.ReleaseApp{}).Where("batch_id = ? AND app_id = ?", batch.ID, appID).Count(&exists)
+					if exists == 0 {
+						return fmt.Errorf("应用 %d 不在当前批次中", appID)
 					}
-					releaseApp.SkipPreEnv = !hasPre
-				} else {
-					logger.Sugar().Warnf("应用 %s (ID: %d) 没有配置环境", addApps[app.AppID].Name, addApps[app.AppID].ID)
 				}
-
-				// 如果有构建记录，填充构建信息
-				if hasBuild {
-					releaseApp.BuildID = &build.ID
-					releaseApp.TargetTag = &build.ImageTag
-					releaseApp.LatestBuildID = &build.ID
-				} else {
-					// 无构建记录，留空
-					releaseApp.BuildID = nil
-				}
-
-				releaseApps = append(releaseApps, releaseApp)
 			}
-
-			if err := tx.Create(&releaseApps).Error; err != nil {
-				return fmt.Errorf("创建应用发布记录失败: %w", err)
-			}
-
-			updatedFields["add_apps"] = addAppIDs
+			updatedFields["pre_only_apps"] = req.PreOnlyApps
 		}
 
-		// 6. 检查批次是否还有应用
+		// 7. 检查批次是否还有应用
 		var appCount int64
 		if err := tx.Model(&model.ReleaseApp{}).Where("batch_id = ?", batch.ID).Count(&appCount).Error; err != nil {
 			return err
@@ -221,7 +226,7 @@ func (s *BatchService) UpdateBatch(req *dto.UpdateBatchParam) (*model.Batch, map
 			return fmt.Errorf("批次至少需要包含一个应用")
 		}
 
-		// 7. 保存批次更新
+		// 8. 保存批次更新
 		if err := tx.Save(batch).Error; err != nil {
 			return fmt.Errorf("更新批次失败: %w", err)
 		}
@@ -238,6 +243,97 @@ func (s *BatchService) UpdateBatch(req *dto.UpdateBatchParam) (*model.Batch, map
 	return batch, updatedFields, nil
 }
 
+// addReleaseApps 在事务内向草稿批次添加应用（校验项目归属、应用冲突，填充部署后最新构建）
+func (s *BatchService) addReleaseApps(tx *gorm.DB, batch *model.Batch, apps []dto.CreateBatchApp) ([]int64, error) {
+	// 提取应用ID列表
+	addAppIDs := make([]int64, len(apps))
+	for i, app := range apps {
+		addAppIDs[i] = app.AppID
+	}
+
+	// 查询应用
+	addApps, err := s.appRepo.FindByIDs(tx, addAppIDs, repository.WithPreloadEnvConfigs())
+	if err != nil {
+		return nil, fmt.Errorf("查询应用失败: %w", err)
+	}
+	if len(addApps) != len(addAppIDs) {
+		return nil, fmt.Errorf("部分应用不存在")
+	}
+	for _, app := range addApps {
+		if app.ProjectID != batch.ProjectID {
+			return nil, fmt.Errorf("应用 %s (ID: %d) 不属于项目 (ID: %d)", app.Name, app.ID, batch.ProjectID)
+		}
+	}
+
+	// 检查应用冲突
+	if conflicts, err := s.batchRepo.CheckAppConflict(tx, addAppIDs, &batch.ID); err != nil {
+		return nil, fmt.Errorf("检查应用冲突失败: %w", err)
+	} else if len(conflicts) > 0 {
+		appMap, _ := s.appRepo.FindByIDs(tx, addAppIDs)
+		return nil, &AppConflictError{Conflicts: conflicts, AppMap: appMap}
+	}
+
+	// 获取build信息
+	buildMap, err := s.batchRepo.GetLatestBuildsAfterDeployment(tx, addAppIDs)
+	if err != nil {
+		return nil, fmt.Errorf("查询部署后最新构建失败: %w", err)
+	}
+
+	releaseApps := make([]*model.ReleaseApp, 0, len(apps))
+	for _, app := range apps {
+		build, hasBuild := buildMap[app.AppID]
+
+		releaseApp := &model.ReleaseApp{
+			BatchID:      batch.ID,
+			AppID:        app.AppID,
+			ReleaseNotes: app.ReleaseNotes,
+			IsLocked:     false,
+			PreOnly:      app.PreOnly,
+		}
+
+		var hasPre, hasProd bool
+		envConfigs := addApps[app.AppID].EnvConfigs
+		if envConfigs != nil {
+			for _, envConfig := range envConfigs {
+				if envConfig.Cluster == "" {
+					continue
+				}
+				switch envConfig.Env {
+				case constants.EnvTypePre:
+					hasPre = true
+				case constants.EnvTypeProd:
+					hasProd = true
+				}
+			}
+			releaseApp.SkipPreEnv = !hasPre
+			releaseApp.SkipProdEnv = app.PreOnly || !hasProd
+		} else {
+			logger.Sugar().Warnf("应用 %s (ID: %d) 没有配置环境", addApps[app.AppID].Name, addApps[app.AppID].ID)
+		}
+		if app.PreOnly && !hasPre {
+			return nil, fmt.Errorf("应用 %s (ID: %d) 未配置预发布环境，不能设置为仅预发布(pre_only)", addApps[app.AppID].Name, app.AppID)
+		}
+
+		// 如果有构建记录，填充构建信息
+		if hasBuild {
+			releaseApp.BuildID = &build.ID
+			releaseApp.TargetTag = &build.ImageTag
+			releaseApp.LatestBuildID = &build.ID
+		} else {
+			// 无构建记录，留空
+			releaseApp.BuildID = nil
+		}
+
+		releaseApps = append(releaseApps, releaseApp)
+	}
+
+	if err := tx.Create(&releaseApps).Error; err != nil {
+		return nil, fmt.Errorf("创建应用发布记录失败: %w", err)
+	}
+
+	return addAppIDs, nil
+}
+
 // UpdateReleaseDependencies 更新批次应用的临时依赖配置
 func (s *BatchService) UpdateReleaseDependencies(req *dto.UpdateReleaseDependenciesRequest) (*dto.ReleaseDependenciesResponse, error) {
 	release, err := s.batchRepo.GetReleaseAppByID(req.ReleaseAppID)
@@ -413,14 +509,21 @@ func (s *BatchService) GetBatch(batchID int64, appPage, appPageSize int, withRec
 	// 3. 转换为响应格式（包含构建记录）
 	appResponses := s.toReleaseAppResponses(apps, withRecentBuilds)
 
+	preOnlyApps, err := s.countPreOnlyApps(batchID)
+	if err != nil {
+		return nil, err
+	}
+
 	// 4. 构建详情响应
 	response := &dto.BatchDetailResponse{
 		BatchResponse: s.toBatchResponse(batch, totalApps),
 		Apps:          appResponses,
 		TotalApps:     totalApps,
+		PreOnlyApps:   preOnlyApps,
 		AppPage:       appPage,
 		AppPageSize:   appPageSize,
 	}
+	response.EnginePause = affectingEnginePause(s.loadEnginePauses(), batch)
 
 	appTypeConfigs := config.GetAppTypeConfigs()
 	if len(appTypeConfigs) > 0 {
@@ -451,6 +554,11 @@ func (s *BatchService) GetBatch(batchID int64, appPage, appPageSize int, withRec
 func (s *BatchService) toReleaseAppResponses(releases []*model.ReleaseApp, withRecentBuilds bool) []dto.ReleaseAppResponse {
 	responses := make([]dto.ReleaseAppResponse, len(releases))
 
+	var recentBuilds map[int64][]*model.Build
+	if withRecentBuilds {
+		recentBuilds = s.getRecentBuilds(releases)
+	}
+
 	for i, release := range releases {
 		releaseResp := dto.ReleaseAppResponse{
 			// ReleaseApp 基本信息
@@ -462,15 +570,25 @@ func (s *BatchService) toReleaseAppResponses(releases []*model.ReleaseApp, withR
 			// 版本信息
 			PreviousDeployedTag: release.PreviousDeployedTag,
 			TargetTag:           release.TargetTag,
+			ChartLock:           release.ChartLock,
+			ManifestLock:        release.ManifestLock,
+			IssueKeys:           []string(release.IssueKeys),
+			ValuesOverride:      release.ValuesOverride,
 			LatestBuildID:       release.LatestBuildID,
 
 			// 发布信息
 			ReleaseNotes: release.ReleaseNotes,
 			IsLocked:     release.IsLocked,
 			SkipPreEnv:   release.SkipPreEnv,
+			PreOnly:      release.PreOnly,
+			SkipProdEnv:  release.SkipProdEnv,
+			Wave:         release.Wave,
 			Reasons:      release.GetRecentReason(10),
 			Status:       release.Status,
 
+			RolloutStage:  release.RolloutStage,
+			RolloutStages: release.RolloutStages,
+
 			// 时间信息
 			CreatedAt: release.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 			UpdatedAt: release.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
@@ -502,7 +620,7 @@ func (s *BatchService) toReleaseAppResponses(releases []*model.ReleaseApp, withR
 
 			// 【可选】填充最近的构建记录
 			if withRecentBuilds {
-				releaseResp.RecentBuilds = s.getRecentBuilds(release.AppID, release.Application.DeployedTag)
+				releaseResp.RecentBuilds = s.toBuildSummaries(recentBuilds[release.AppID])
 			}
 		}
 
@@ -524,54 +642,25 @@ func (s *BatchService) toReleaseAppResponses(releases []*model.ReleaseApp, withR
 	return responses
 }
 
-// getRecentBuilds 获取应用最近的构建记录（方案A：基于 deployed_tag，自上次部署以来）
-func (s *BatchService) getRecentBuilds(appId int64, afterDeployedTag *string) []dto.BuildSummary {
-	log := logger.Log.With(zap.Int64("app_id", appId)).Sugar()
+// recentBuildLimit 批次详情中每个应用返回的构建记录条数
+const recentBuildLimit = 15
 
-	const buildLimit = 15 // 固定返回15条
-
-	var builds []*model.Build
-	var err error
-
-	// 1. 尝试基于 Application.DeployedTag 查找基准时间
-	if afterDeployedTag != nil {
-		// 查找 deployed_tag 对应的构建记录
-		deployedBuild, err := s.batchRepo.GetBuildByAppIDAndTag(appId, *afterDeployedTag)
-		if err != nil {
-			log.Errorf("查询deployed_tag对应的构建失败: %v", err)
-			// 出错则返回空数组
-			return []dto.BuildSummary{}
-		}
-
-		if deployedBuild != nil {
-			// 找到了基准构建，查询该时间之后的构建
-			builds, err = s.batchRepo.GetBuildsSinceTime(appId, deployedBuild.BuildCreated, buildLimit)
-			if err != nil {
-				log.Errorf("查询时间后的构建失败: %v", err)
-				return []dto.BuildSummary{}
-			}
-		} else {
-			// deployed_tag 对应的构建不存在，fallback 到最近15条
-			log.Warn("deployed_tag对应的构建不存在，返回最近15条", zap.String("deployed_tag", *afterDeployedTag))
-			builds, err = s.batchRepo.GetRecentBuilds(appId, buildLimit)
-			if err != nil {
-				log.Errorf("查询最近构建失败: %v", err)
-				return []dto.BuildSummary{}
-			}
-		}
-	} else {
-		// 2. 没有 deployed_tag（新应用），返回最近15条
-		builds, err = s.batchRepo.GetRecentBuilds(appId, buildLimit)
-		if err != nil {
-			log.Errorf("查询最近构建失败（新应用）: %v", err)
-			return []dto.BuildSummary{}
+// getRecentBuilds 批量获取应用最近的构建记录（基于 deployed_tag，自上次部署以来；按应用构建过滤规则过滤）
+//
+// 整页应用只查询一次，出错时所有应用返回空列表
+func (s *BatchService) getRecentBuilds(releases []*model.ReleaseApp) map[int64][]*model.Build {
+	apps := make([]*model.Application, 0, len(releases))
+	for _, release := range releases {
+		if release.Application != nil {
+			apps = append(apps, release.Application)
 		}
 	}
-
-	// 3. 确保当前选中的构建也在列表中（如果存在build_id）
-
-	// 4. 转换为 DTO
-	return s.toBuildSummaries(builds)
+	builds, err := s.batchRepo.GetRecentBuildsByApps(apps, recentBuildLimit)
+	if err != nil {
+		logger.Error("批量查询最近构建失败", zap.Int("apps", len(apps)), zap.Error(err))
+		return nil
+	}
+	return builds
 }
 
 // toBuildSummaries 转换构建记录为摘要格式
@@ -620,6 +709,17 @@ func filterDependenciesForBatch(defaultDeps, tempDeps []int64, allowed map[int64
 	return normalizeDependencyIDs(combined)
 }
 
+// countPreOnlyApps 统计批次中不发布生产的应用数（pre_only 或未配置生产环境，即 skip_prod_env）
+func (s *BatchService) countPreOnlyApps(batchID int64) (int64, error) {
+	var count int64
+	if err := s.db.Model(&model.ReleaseApp{}).
+		Where("batch_id = ? AND skip_prod_env = ?", batchID, true).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("查询跳过生产的应用数失败: %w", err)
+	}
+	return count, nil
+}
+
 // toBatchResponse 转换 Batch 模型为 BatchResponse DTO
 func (s *BatchService) toBatchResponse(batch *model.Batch, appCount int64) dto.BatchResponse {
 	response := dto.BatchResponse{
@@ -663,6 +763,16 @@ func (s *BatchService) toBatchResponse(batch *model.Batch, appCount int64) dto.B
 		UpdatedAt: batch.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
 
+	response.DependsOnBatchID = batch.DependsOnBatchID
+	response.ScheduledPreAt = dto.FormatTime(batch.ScheduledPreAt)
+	response.ScheduledPreBy = batch.ScheduledPreBy
+	response.ScheduledProdAt = dto.FormatTime(batch.ScheduledProdAt)
+	response.ScheduledProdBy = batch.ScheduledProdBy
+	response.VulnOverrideAt = dto.FormatTime(batch.VulnOverrideAt)
+	response.VulnOverrideBy = batch.VulnOverrideBy
+	response.VulnOverrideReason = batch.VulnOverrideReason
+	response.BlockedReason = s.blockedReason(batch)
+
 	// 添加项目名称（如果需要）
 	// 注意：这里需要 Preload("Project") 才能获取项目信息
 	// 如果需要项目名称，应该在查询时预加载
@@ -670,6 +780,76 @@ func (s *BatchService) toBatchResponse(batch *model.Batch, appCount int64) dto.B
 	return response
 }
 
+// maxPredecessorDepth 前置批次链最大深度（防止异常数据导致死循环）
+const maxPredecessorDepth = 20
+
+// validatePredecessor 校验前置批次：存在、非自身、未取消，且不形成循环依赖
+func validatePredecessor(tx *gorm.DB, batchID, predecessorID int64) error {
+	if predecessorID == batchID {
+		return fmt.Errorf("前置批次不能是批次自身")
+	}
+
+	var pred model.Batch
+	if err := tx.Select("id", "status", "depends_on_batch_id").First(&pred, predecessorID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("前置批次 %d 不存在", predecessorID)
+		}
+		return fmt.Errorf("查询前置批次失败: %w", err)
+	}
+	if pred.Status == constants.BatchStatusCancelled {
+		return fmt.Errorf("前置批次 %d 已取消", predecessorID)
+	}
+
+	// 新建批次无 ID，不可能被依赖，无需检查循环
+	if batchID == 0 {
+		return nil
+	}
+	next := pred.DependsOnBatchID
+	for depth := 0; next != nil; depth++ {
+		if *next == batchID {
+			return fmt.Errorf("前置批次 %d 形成循环依赖", predecessorID)
+		}
+		if depth >= maxPredecessorDepth {
+			return fmt.Errorf("前置批次依赖链过长（超过 %d 层）", maxPredecessorDepth)
+		}
+		var b model.Batch
+		if err := tx.Select("id", "depends_on_batch_id").Limit(1).Find(&b, *next).Error; err != nil {
+			return fmt.Errorf("查询前置批次失败: %w", err)
+		}
+		next = b.DependsOnBatchID
+	}
+	return nil
+}
+
+// blockedReason 批次尚未开始生产部署时，返回前置批次造成的阻塞原因（查询失败仅记录日志）
+func (s *BatchService) blockedReason(batch *model.Batch) string {
+	if batch.DependsOnBatchID == nil || batch.Status >= constants.BatchStatusProdWaiting {
+		return ""
+	}
+	reason, err := transitions.PredecessorBlockReason(s.db, batch)
+	if err != nil {
+		logger.Warn("查询前置批次状态失败", zap.Int64("batch_id", batch.ID), zap.Error(err))
+	}
+	return reason
+}
+
+// loadEnginePauses 查询生效中的引擎暂停记录（查询失败仅记录日志，不影响批次查询）
+func (s *BatchService) loadEnginePauses() []*model.EnginePause {
+	var pauses []*model.EnginePause
+	if err := s.db.Scopes(model.ActiveEnginePause).Find(&pauses).Error; err != nil {
+		logger.Warn("查询引擎暂停记录失败", zap.Error(err))
+	}
+	return pauses
+}
+
+// affectingEnginePause 作用于批次的暂停记录；已完成/已取消/回滚结束的批次不受影响
+func affectingEnginePause(pauses []*model.EnginePause, batch *model.Batch) *dto.EnginePauseResponse {
+	if batch.Status >= constants.BatchStatusCompleted && batch.Status != constants.BatchStatusRollingBack {
+		return nil
+	}
+	return toEnginePauseResponse(model.FindEnginePause(pauses, batch.ProjectID, batch.ID))
+}
+
 // getStatusName 获取状态名称
 func getStatusName(status int8) string {
 	switch status {
@@ -687,6 +867,12 @@ func getStatusName(status int8) string {
 		return "生产已部署"
 	case constants.BatchStatusCompleted:
 		return "已完成"
+	case constants.BatchStatusRollingBack:
+		return "回滚中"
+	case constants.BatchStatusRolledBack:
+		return "已回滚"
+	case constants.BatchStatusRollbackFailed:
+		return "回滚失败"
 	case constants.BatchStatusCancelled:
 		return "已取消"
 	default:
@@ -710,8 +896,10 @@ func (s *BatchService) ListBatches(req dto.BatchListParam) ([]dto.BatchResponse,
 
 	// 为每个批次查询应用数量并转换为 DTO
 	responses := make([]dto.BatchResponse, len(batches))
+	pauses := s.loadEnginePauses()
 	for i, batch := range batches {
 		responses[i] = s.toBatchResponse(batch, 0)
+		responses[i].EnginePause = affectingEnginePause(pauses, batch)
 	}
 
 	return responses, total, nil
@@ -725,7 +913,17 @@ func (s *BatchService) HandleBuildNotify(req *BuildNotifyRequest) error {
 		return fmt.Errorf("处理构建记录失败: %w", err)
 	}
 
-	// 2. 查找该应用在草稿/未封板批次中的记录
+	// 2. 按应用构建过滤规则过滤
+	app, err := s.appRepo.FindByID(req.AppID)
+	if err != nil {
+		return fmt.Errorf("查询应用失败: %w", err)
+	}
+	if !app.TagFilter.MatchBuild(build) {
+		logger.Info("构建不满足应用构建过滤规则，忽略", zap.Int64("app_id", req.AppID), zap.String("tag", build.ImageTag))
+		return nil
+	}
+
+	// 3. 查找该应用在草稿/未封板批次中的记录
 	releaseApps, err := s.batchRepo.GetReleaseAppsByAppIDAndNotSealed(req.AppID)
 	if err != nil {
 		return fmt.Errorf("查询发布应用记录失败: %w", err)
@@ -736,7 +934,7 @@ func (s *BatchService) HandleBuildNotify(req *BuildNotifyRequest) error {
 		return nil
 	}
 
-	// 3. 更新所有未封板批次中的该应用记录（仅更新 build_id）
+	// 4. 更新所有未封板批次中的该应用记录（仅更新 build_id）
 	for _, app := range releaseApps {
 		updates := map[string]interface{}{
 			"build_id":   build.ID,
@@ -872,6 +1070,7 @@ func (e *BatchSealedError) Error() string {
 }
 
 // ApproveBatch 审批通过批次（独立于 status 流转）
+// 项目配置了审批策略时，operator 需为当前审批阶段的审批人，全部阶段通过后批次才 approved
 func (s *BatchService) ApproveBatch(batchID int64, operator string, reason string) error {
 	var batch model.Batch
 	if err := s.db.First(&batch, batchID).Error; err != nil {
@@ -886,6 +1085,19 @@ func (s *BatchService) ApproveBatch(batchID int64, operator string, reason strin
 		return fmt.Errorf("批次已被拒绝，不能再次审批")
 	}
 
+	policy, err := s.loadApprovalPolicy(s.db, batch.ProjectID)
+	if err != nil {
+		return err
+	}
+	if policy != nil {
+		if err := s.decideStage(&batch, policy, operator, constants.ApprovalStatusApproved, reason); err != nil {
+			return err
+		}
+		logApprovalDecision(&batch, operator, constants.ApprovalStatusApproved)
+		s.triggerBatch(batch.ID)
+		return nil
+	}
+
 	// 更新审批状态
 	now := time.Now()
 	updates := map[string]interface{}{
@@ -903,10 +1115,12 @@ func (s *BatchService) ApproveBatch(batchID int64, operator string, reason strin
 		zap.String("batch_number", batch.BatchNumber),
 		zap.String("operator", operator))
 
+	s.triggerBatch(batch.ID)
 	return nil
 }
 
 // RejectBatch 拒绝批次（独立于 status 流转）
+// 项目配置了审批策略时，仅当前审批阶段的审批人可以拒绝
 func (s *BatchService) RejectBatch(batchID int64, operator string, reason string) error {
 	var batch model.Batch
 	if err := s.db.First(&batch, batchID).Error; err != nil {
@@ -921,6 +1135,18 @@ func (s *BatchService) RejectBatch(batchID int64, operator string, reason string
 		return fmt.Errorf("批次已被拒绝")
 	}
 
+	policy, err := s.loadApprovalPolicy(s.db, batch.ProjectID)
+	if err != nil {
+		return err
+	}
+	if policy != nil {
+		if err := s.decideStage(&batch, policy, operator, constants.ApprovalStatusRejected, reason); err != nil {
+			return err
+		}
+		logApprovalDecision(&batch, operator, constants.ApprovalStatusRejected)
+		return nil
+	}
+
 	// 更新审批状态
 	updates := map[string]interface{}{
 		"approval_status": constants.ApprovalStatusRejected,
@@ -957,6 +1183,11 @@ func (s *BatchService) GetBatchStatus(batchID int64, appPage, appPageSize int) (
 		return nil, fmt.Errorf("查询应用总数失败: %w", err)
 	}
 
+	preOnlyApps, err := s.countPreOnlyApps(batchID)
+	if err != nil {
+		return nil, err
+	}
+
 	// 3. 查询应用状态列表（分页）
 	var releaseApps []model.ReleaseApp
 	offset := (appPage - 1) * appPageSize
@@ -979,9 +1210,17 @@ func (s *BatchService) GetBatchStatus(batchID int64, appPage, appPageSize int) (
 			LatestBuildID: app.LatestBuildID,
 			IsLocked:      app.IsLocked,
 			SkipPreEnv:    app.SkipPreEnv,
+			PreOnly:       app.PreOnly,
+			SkipProdEnv:   app.SkipProdEnv,
+			Wave:          app.Wave,
 		}
 	}
 
+	waves, currentWave, waveStage, err := s.loadWaveProgress(&batch)
+	if err != nil {
+		return nil, err
+	}
+
 	// 5. 获取状态名称
 	statusName := getStatusName(batch.Status)
 
@@ -993,6 +1232,9 @@ func (s *BatchService) GetBatchStatus(batchID int64, appPage, appPageSize int) (
 		StatusName:     statusName,
 		ApprovalStatus: batch.ApprovalStatus,
 
+		DependsOnBatchID: batch.DependsOnBatchID,
+		BlockedReason:    s.blockedReason(&batch),
+
 		SealedAt:             dto.FormatTime(batch.SealedAt),
 		PreDeployStartedAt:   dto.FormatTime(batch.PreStartedAt),
 		PreDeployFinishedAt:  dto.FormatTime(batch.PreFinishedAt),
@@ -1001,9 +1243,16 @@ func (s *BatchService) GetBatchStatus(batchID int64, appPage, appPageSize int) (
 		FinalAcceptedAt:      dto.FormatTime(batch.FinalAcceptedAt),
 		CancelledAt:          dto.FormatTime(batch.CancelledAt),
 		UpdatedAt:            batch.UpdatedAt.Format(time.RFC3339),
+		EnginePause:          affectingEnginePause(s.loadEnginePauses(), &batch),
+
+		Waves:       waves,
+		WaveStage:   waveStage,
+		CurrentWave: currentWave,
+		TotalWaves:  len(waves),
 
 		Apps:        apps,
 		TotalApps:   totalApps,
+		PreOnlyApps: preOnlyApps,
 		AppPage:     appPage,
 		AppPageSize: appPageSize,
 	}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+)
+
+// 批次拆分/合并
+//
+// - 仅草稿批次可拆分/合并，发布应用整行改挂到目标批次，应用级发布说明、临时依赖、所选构建、pre_only 均保持不变
+// - 临时依赖按批次读取时过滤，拆分后跨批次的依赖自动失效，合并回同一批次后重新生效
+
+// SplitBatch 将批次中选中的发布应用移入新建的草稿批次（同项目），原批次至少保留一个应用
+func (s *BatchService) SplitBatch(batchID int64, req *dto.SplitBatchRequest, operator string, canAccess func(projectID int64) bool) (*dto.SplitBatchResponse, error) {
+	src, err := s.findDraftBatch(batchID, canAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	appIDs := make([]int64, 0, len(req.AppIDs))
+	seen := make(map[int64]struct{}, len(req.AppIDs))
+	for _, id := range req.AppIDs {
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			appIDs = append(appIDs, id)
+		}
+	}
+
+	var batch *model.Batch
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var existing []int64
+		if err := tx.Model(&model.ReleaseApp{}).Where("batch_id = ?", src.ID).
+			Pluck("app_id", &existing).Error; err != nil {
+			return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询发布应用失败", err)
+		}
+		inBatch := make(map[int64]struct{}, len(existing))
+		for _, id := range existing {
+			inBatch[id] = struct{}{}
+		}
+		for _, id := range appIDs {
+			if _, ok := inBatch[id]; !ok {
+				return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("应用 %d 不在批次中", id))
+			}
+		}
+		if len(appIDs) >= len(existing) {
+			return pkgErrors.New(pkgErrors.CodeBadRequest, "原批次至少需要保留一个应用")
+		}
+
+		batch, err = s.createBatch(tx, &dto.CreateBatchParam{
+			BatchNumber:      req.BatchNumber,
+			ReleaseNotes:     req.ReleaseNotes,
+			DependsOnBatchID: req.DependsOnBatchID,
+			ProjectID:        src.ProjectID,
+			Operator:         operator,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.ReleaseApp{}).
+			Where("batch_id = ? AND app_id IN ?", src.ID, appIDs).
+			Update("batch_id", batch.ID).Error; err != nil {
+			return fmt.Errorf("移动发布应用失败: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("批次拆分成功",
+		zap.Int64("source_batch_id", src.ID),
+		zap.Int64("batch_id", batch.ID),
+		zap.String("batch_number", batch.BatchNumber),
+		zap.Int64s("app_ids", appIDs),
+		zap.String("operator", operator))
+
+	return &dto.SplitBatchResponse{
+		BatchID:       batch.ID,
+		BatchNumber:   batch.BatchNumber,
+		SourceBatchID: src.ID,
+		AppIDs:        appIDs,
+	}, nil
+}
+
+// MergeBatch 将同项目的另一个草稿批次的发布应用并入当前批次，并删除被合并批次
+// 两个批次存在相同应用时拒绝合并；原先依赖被合并批次的批次改为依赖当前批次
+func (s *BatchService) MergeBatch(batchID int64, req *dto.MergeBatchRequest, operator string, canAccess func(projectID int64) bool) (*dto.MergeBatchResponse, error) {
+	if req.SourceBatchID == batchID {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "不能合并批次自身")
+	}
+	target, err := s.findDraftBatch(batchID, canAccess)
+	if err != nil {
+		return nil, err
+	}
+	src, err := s.findDraftBatch(req.SourceBatchID, canAccess)
+	if err != nil {
+		return nil, err
+	}
+	if src.ProjectID != target.ProjectID {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "只能合并同一项目的批次")
+	}
+
+	var appIDs []int64
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.ReleaseApp{}).Where("batch_id = ?", src.ID).
+			Order("id").Pluck("app_id", &appIDs).Error; err != nil {
+			return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询发布应用失败", err)
+		}
+		if len(appIDs) > 0 {
+			var dup []int64
+			if err := tx.Model(&model.ReleaseApp{}).Where("batch_id = ? AND app_id IN ?", target.ID, appIDs).
+				Pluck("app_id", &dup).Error; err != nil {
+				return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询发布应用失败", err)
+			}
+			if len(dup) > 0 {
+				return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("两个批次包含相同应用: %v", dup))
+			}
+		}
+
+		if err := tx.Model(&model.ReleaseApp{}).Where("batch_id = ?", src.ID).
+			Update("batch_id", target.ID).Error; err != nil {
+			return fmt.Errorf("移动发布应用失败: %w", err)
+		}
+
+		// 当前批次依赖被合并批次时移除依赖，其余依赖被合并批次的批次改为依赖当前批次
+		if target.DependsOnBatchID != nil && *target.DependsOnBatchID == src.ID {
+			if err := tx.Model(&model.Batch{}).Where("id = ?", target.ID).
+				Update("depends_on_batch_id", nil).Error; err != nil {
+				return fmt.Errorf("更新前置批次失败: %w", err)
+			}
+		}
+		if err := tx.Model(&model.Batch{}).Where("depends_on_batch_id = ? AND id <> ?", src.ID, target.ID).
+			Update("depends_on_batch_id", target.ID).Error; err != nil {
+			return fmt.Errorf("更新前置批次失败: %w", err)
+		}
+
+		if err := tx.Delete(&model.Batch{}, src.ID).Error; err != nil {
+			return fmt.Errorf("删除被合并批次失败: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("批次合并成功",
+		zap.Int64("batch_id", target.ID),
+		zap.Int64("source_batch_id", src.ID),
+		zap.Int64s("app_ids", appIDs),
+		zap.String("operator", operator))
+
+	if appIDs == nil {
+		appIDs = []int64{}
+	}
+	return &dto.MergeBatchResponse{
+		BatchID:       target.ID,
+		SourceBatchID: src.ID,
+		AppIDs:        appIDs,
+	}, nil
+}
+
+// findDraftBatch 查询批次并校验项目权限，非草稿批次返回 BatchSealedError
+func (s *BatchService) findDraftBatch(batchID int64, canAccess func(projectID int64) bool) (*model.Batch, error) {
+	batch, err := s.findBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+	if !canAccess(batch.ProjectID) {
+		return nil, pkgErrors.ErrForbidden
+	}
+	if batch.Status != constants.BatchStatusDraft {
+		return nil, &BatchSealedError{BatchID: batch.ID, Status: batch.Status}
+	}
+	return batch, nil
+}
@@ -0,0 +1,392 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	pkgErrors "devops-cd/pkg/responses"
+)
+
+// batchTemplateData 批次模板渲染变量
+type batchTemplateData struct {
+	Project  string   // 项目名
+	Template string   // 模板名
+	Date     string   // 当天日期（2006-01-02）
+	Apps     []string // 应用名
+}
+
+// defaultTemplateBatchNumber 模板未配置批次编号时使用
+const defaultTemplateBatchNumber = "{{.Template}}-{{.Date}}"
+
+// CreateBatchTemplate 创建批次模板
+func (s *BatchService) CreateBatchTemplate(req *dto.CreateBatchTemplateRequest, operator string) (*dto.BatchTemplateResponse, error) {
+	tpl := &model.BatchTemplate{
+		ProjectID:    req.ProjectID,
+		Name:         strings.TrimSpace(req.Name),
+		Description:  req.Description,
+		BatchNumber:  req.BatchNumber,
+		ReleaseNotes: req.ReleaseNotes,
+		Apps:         toBatchTemplateApps(req.Apps),
+		CreatedBy:    operator,
+		UpdatedBy:    operator,
+	}
+	if err := s.validateBatchTemplate(tpl); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(tpl).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "Duplicate entry") {
+			return nil, pkgErrors.New(pkgErrors.CodeConflict, fmt.Sprintf("模板 %s 在该项目下已存在", tpl.Name))
+		}
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "保存批次模板失败", err)
+	}
+	return toBatchTemplateResponse(tpl), nil
+}
+
+// UpdateBatchTemplate 更新批次模板
+func (s *BatchService) UpdateBatchTemplate(id int64, req *dto.UpdateBatchTemplateRequest, operator string, canAccess func(projectID int64) bool) (*dto.BatchTemplateResponse, error) {
+	tpl, err := s.findBatchTemplate(id, canAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		tpl.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		tpl.Description = req.Description
+	}
+	if req.BatchNumber != nil {
+		tpl.BatchNumber = req.BatchNumber
+	}
+	if req.ReleaseNotes != nil {
+		tpl.ReleaseNotes = req.ReleaseNotes
+	}
+	if req.Apps != nil {
+		tpl.Apps = toBatchTemplateApps(req.Apps)
+	}
+	tpl.UpdatedBy = operator
+
+	if err := s.validateBatchTemplate(tpl); err != nil {
+		return nil, err
+	}
+	if err := s.db.Save(tpl).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || strings.Contains(err.Error(), "Duplicate entry") {
+			return nil, pkgErrors.New(pkgErrors.CodeConflict, fmt.Sprintf("模板 %s 在该项目下已存在", tpl.Name))
+		}
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "更新批次模板失败", err)
+	}
+	return toBatchTemplateResponse(tpl), nil
+}
+
+// DeleteBatchTemplate 删除批次模板（已创建的批次不受影响）
+func (s *BatchService) DeleteBatchTemplate(id int64, canAccess func(projectID int64) bool) error {
+	tpl, err := s.findBatchTemplate(id, canAccess)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Delete(&model.BatchTemplate{}, tpl.ID).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "删除批次模板失败", err)
+	}
+	return nil
+}
+
+// GetBatchTemplate 查询批次模板
+func (s *BatchService) GetBatchTemplate(id int64, canAccess func(projectID int64) bool) (*dto.BatchTemplateResponse, error) {
+	tpl, err := s.findBatchTemplate(id, canAccess)
+	if err != nil {
+		return nil, err
+	}
+	return toBatchTemplateResponse(tpl), nil
+}
+
+// ListBatchTemplates 查询项目下的批次模板
+func (s *BatchService) ListBatchTemplates(projectID int64) ([]*dto.BatchTemplateResponse, error) {
+	var templates []*model.BatchTemplate
+	if err := s.db.Where("project_id = ?", projectID).Order("name").Find(&templates).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询批次模板失败", err)
+	}
+	items := make([]*dto.BatchTemplateResponse, 0, len(templates))
+	for _, tpl := range templates {
+		items = append(items, toBatchTemplateResponse(tpl))
+	}
+	return items, nil
+}
+
+// CreateBatchFromTemplate 根据模板创建草稿批次：添加模板应用（填充部署后最新构建）并设置模板中的临时依赖
+func (s *BatchService) CreateBatchFromTemplate(req *dto.CreateBatchFromTemplateRequest, operator string, canAccess func(projectID int64) bool) (*dto.CreateBatchFromTemplateResponse, error) {
+	tpl, err := s.findBatchTemplate(req.TemplateID, canAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := make(map[int64]bool, len(req.ExcludeAppIDs))
+	for _, id := range req.ExcludeAppIDs {
+		excluded[id] = true
+	}
+	apps := make(model.BatchTemplateApps, 0, len(tpl.Apps))
+	for _, app := range tpl.Apps {
+		if !excluded[app.AppID] {
+			apps = append(apps, app)
+		}
+	}
+	if len(apps) == 0 {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "批次至少需要包含一个应用")
+	}
+
+	var batch *model.Batch
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		data, err := s.batchTemplateData(tx, tpl, apps.AppIDs())
+		if err != nil {
+			return err
+		}
+
+		param := &dto.CreateBatchParam{
+			BatchNumber:      renderOverride(req.BatchNumber),
+			ReleaseNotes:     req.ReleaseNotes,
+			DependsOnBatchID: req.DependsOnBatchID,
+			ProjectID:        tpl.ProjectID,
+			Operator:         operator,
+		}
+		if param.BatchNumber == "" {
+			text := defaultTemplateBatchNumber
+			if tpl.BatchNumber != nil && strings.TrimSpace(*tpl.BatchNumber) != "" {
+				text = *tpl.BatchNumber
+			}
+			if param.BatchNumber, err = renderBatchTemplate("batch_number", text, data); err != nil {
+				return err
+			}
+			param.BatchNumber = strings.TrimSpace(param.BatchNumber)
+		}
+		if param.ReleaseNotes == nil && tpl.ReleaseNotes != nil {
+			notes, err := renderBatchTemplate("release_notes", *tpl.ReleaseNotes, data)
+			if err != nil {
+				return err
+			}
+			param.ReleaseNotes = &notes
+		}
+
+		if batch, err = s.createBatch(tx, param); err != nil {
+			return err
+		}
+
+		batchApps := make([]dto.CreateBatchApp, 0, len(apps))
+		for _, app := range apps {
+			item := dto.CreateBatchApp{AppID: app.AppID, PreOnly: app.PreOnly}
+			if app.ReleaseNotes != nil {
+				notes, err := renderBatchTemplate("release_notes", *app.ReleaseNotes, data)
+				if err != nil {
+					return err
+				}
+				item.ReleaseNotes = &notes
+			}
+			batchApps = append(batchApps, item)
+		}
+		if _, err := s.addReleaseApps(tx, batch, batchApps); err != nil {
+			return err
+		}
+
+		// 临时依赖只保留本次仍在批次内的应用
+		for _, app := range apps {
+			deps := make(model.Int64List, 0, len(app.TempDependsOn))
+			for _, dep := range app.TempDependsOn {
+				if !excluded[dep] {
+					deps = append(deps, dep)
+				}
+			}
+			if len(deps) == 0 {
+				continue
+			}
+			if err := tx.Model(&model.ReleaseApp{}).
+				Where("batch_id = ? AND app_id = ?", batch.ID, app.AppID).
+				Update("temp_depends_on", deps).Error; err != nil {
+				return fmt.Errorf("设置临时依赖失败: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("根据模板创建批次成功",
+		zap.Int64("template_id", tpl.ID),
+		zap.String("template", tpl.Name),
+		zap.Int64("batch_id", batch.ID),
+		zap.String("batch_number", batch.BatchNumber),
+		zap.String("operator", operator))
+
+	return &dto.CreateBatchFromTemplateResponse{
+		BatchID:     batch.ID,
+		BatchNumber: batch.BatchNumber,
+		TemplateID:  tpl.ID,
+		AppIDs:      apps.AppIDs(),
+	}, nil
+}
+
+func (s *BatchService) findBatchTemplate(id int64, canAccess func(projectID int64) bool) (*model.BatchTemplate, error) {
+	var tpl model.BatchTemplate
+	if err := s.db.First(&tpl, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgErrors.New(pkgErrors.CodeNotFound, "批次模板不存在")
+		}
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询批次模板失败", err)
+	}
+	if !canAccess(tpl.ProjectID) {
+		return nil, pkgErrors.ErrForbidden
+	}
+	return &tpl, nil
+}
+
+// validateBatchTemplate 校验模板：应用属于项目且不重复，临时依赖只引用模板内应用且无循环，Go template 可解析
+func (s *BatchService) validateBatchTemplate(tpl *model.BatchTemplate) error {
+	if tpl.Name == "" {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, "模板名称不能为空")
+	}
+	if len(tpl.Apps) == 0 {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, "模板至少需要包含一个应用")
+	}
+
+	appIDSet := make(map[int64]struct{}, len(tpl.Apps))
+	for _, app := range tpl.Apps {
+		if _, ok := appIDSet[app.AppID]; ok {
+			return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("应用 %d 重复", app.AppID))
+		}
+		appIDSet[app.AppID] = struct{}{}
+	}
+
+	var apps []*model.Application
+	if err := s.db.Select("id", "name", "project_id", "default_depends_on").
+		Where("id IN ?", tpl.Apps.AppIDs()).Find(&apps).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用失败", err)
+	}
+	if len(apps) != len(tpl.Apps) {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, "部分应用不存在")
+	}
+	defaultDeps := make(map[int64][]int64, len(apps))
+	for _, app := range apps {
+		if app.ProjectID != tpl.ProjectID {
+			return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("应用 %s (ID: %d) 不属于项目 (ID: %d)", app.Name, app.ID, tpl.ProjectID))
+		}
+		defaultDeps[app.ID] = app.DefaultDependsOn
+	}
+
+	graph := make(map[int64][]int64, len(tpl.Apps))
+	for _, app := range tpl.Apps {
+		for _, dep := range app.TempDependsOn {
+			if dep == app.AppID {
+				return pkgErrors.New(pkgErrors.CodeBadRequest, "应用不能依赖自身")
+			}
+			if _, ok := appIDSet[dep]; !ok {
+				return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("依赖的应用 %d 不在模板中", dep))
+			}
+		}
+		graph[app.AppID] = filterDependenciesForBatch(defaultDeps[app.AppID], app.TempDependsOn, appIDSet)
+	}
+	if hasDependencyCycle(graph) {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, "依赖配置存在循环，请调整")
+	}
+
+	texts := map[string]*string{"batch_number": tpl.BatchNumber, "release_notes": tpl.ReleaseNotes}
+	for _, app := range tpl.Apps {
+		if app.ReleaseNotes != nil {
+			texts[fmt.Sprintf("apps[%d].release_notes", app.AppID)] = app.ReleaseNotes
+		}
+	}
+	for name, text := range texts {
+		if text == nil {
+			continue
+		}
+		if _, err := template.New(name).Option("missingkey=error").Parse(*text); err != nil {
+			return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("%s 模板解析失败: %v", name, err))
+		}
+	}
+	return nil
+}
+
+func (s *BatchService) batchTemplateData(tx *gorm.DB, tpl *model.BatchTemplate, appIDs []int64) (*batchTemplateData, error) {
+	var project model.Project
+	if err := tx.Select("id", "name").First(&project, tpl.ProjectID).Error; err != nil {
+		return nil, fmt.Errorf("查询项目失败: %w", err)
+	}
+	var appNames []string
+	if err := tx.Model(&model.Application{}).Where("id IN ?", appIDs).Order("name").Pluck("name", &appNames).Error; err != nil {
+		return nil, fmt.Errorf("查询应用失败: %w", err)
+	}
+	return &batchTemplateData{
+		Project:  project.Name,
+		Template: tpl.Name,
+		Date:     time.Now().Format("2006-01-02"),
+		Apps:     appNames,
+	}, nil
+}
+
+func renderBatchTemplate(name, text string, data *batchTemplateData) (string, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%s 模板解析失败: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("%s 模板渲染失败: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+func renderOverride(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return strings.TrimSpace(*value)
+}
+
+func toBatchTemplateApps(items []dto.BatchTemplateAppItem) model.BatchTemplateApps {
+	apps := make(model.BatchTemplateApps, 0, len(items))
+	for _, item := range items {
+		apps = append(apps, model.BatchTemplateApp{
+			AppID:         item.AppID,
+			PreOnly:       item.PreOnly,
+			ReleaseNotes:  item.ReleaseNotes,
+			TempDependsOn: normalizeDependencyIDs(item.TempDependsOn),
+		})
+	}
+	return apps
+}
+
+func toBatchTemplateResponse(tpl *model.BatchTemplate) *dto.BatchTemplateResponse {
+	apps := make([]dto.BatchTemplateAppItem, 0, len(tpl.Apps))
+	for _, app := range tpl.Apps {
+		deps := app.TempDependsOn
+		if deps == nil {
+			deps = []int64{}
+		}
+		apps = append(apps, dto.BatchTemplateAppItem{
+			AppID:         app.AppID,
+			PreOnly:       app.PreOnly,
+			ReleaseNotes:  app.ReleaseNotes,
+			TempDependsOn: deps,
+		})
+	}
+	return &dto.BatchTemplateResponse{
+		ID:           tpl.ID,
+		ProjectID:    tpl.ProjectID,
+		Name:         tpl.Name,
+		Description:  tpl.Description,
+		BatchNumber:  tpl.BatchNumber,
+		ReleaseNotes: tpl.ReleaseNotes,
+		Apps:         apps,
+		CreatedBy:    tpl.CreatedBy,
+		UpdatedBy:    tpl.UpdatedBy,
+		CreatedAt:    tpl.CreatedAt,
+		UpdatedAt:    tpl.UpdatedAt,
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+)
+
+// SetBatchVulnOverride 豁免批次的镜像漏洞门禁（需 security:vuln:override 权限），豁免后封板/生产部署不再按项目漏洞策略拦截
+func (s *BatchService) SetBatchVulnOverride(batchID int64, req *dto.BatchVulnOverrideRequest, operator string, canOverride func(projectID int64) bool) (*dto.BatchVulnOverrideResponse, error) {
+	batch, err := s.findVulnOverrideBatch(batchID, canOverride)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.updateVulnOverride(batch.ID, &now, &operator, &req.Reason); err != nil {
+		return nil, err
+	}
+	logger.Info("豁免批次漏洞门禁", zap.Int64("batch_id", batch.ID), zap.String("operator", operator), zap.String("reason", req.Reason))
+	return s.batchVulnOverride(batch.ID)
+}
+
+// CancelBatchVulnOverride 取消批次漏洞门禁豁免
+func (s *BatchService) CancelBatchVulnOverride(batchID int64, operator string, canOverride func(projectID int64) bool) (*dto.BatchVulnOverrideResponse, error) {
+	batch, err := s.findVulnOverrideBatch(batchID, canOverride)
+	if err != nil {
+		return nil, err
+	}
+	if batch.VulnOverrideAt == nil {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "批次未豁免漏洞门禁")
+	}
+
+	if err := s.updateVulnOverride(batch.ID, nil, nil, nil); err != nil {
+		return nil, err
+	}
+	logger.Info("取消批次漏洞门禁豁免", zap.Int64("batch_id", batch.ID), zap.String("operator", operator))
+	return s.batchVulnOverride(batch.ID)
+}
+
+// findVulnOverrideBatch 查询批次并校验豁免权限，已结束的批次不允许变更
+func (s *BatchService) findVulnOverrideBatch(batchID int64, canOverride func(projectID int64) bool) (*model.Batch, error) {
+	batch, err := s.findBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+	if !canOverride(batch.ProjectID) {
+		return nil, pkgErrors.ErrForbidden
+	}
+	if batch.Status >= constants.BatchStatusCompleted {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("批次当前状态为 %s，无法变更漏洞门禁豁免", constants.BatchStatusToString(batch.Status)))
+	}
+	return batch, nil
+}
+
+func (s *BatchService) updateVulnOverride(batchID int64, at *time.Time, by, reason *string) error {
+	err := s.db.Table(model.BatchTableName).Where("id = ?", batchID).
+		Updates(map[string]interface{}{"vuln_override_at": at, "vuln_override_by": by, "vuln_override_reason": reason}).Error
+	if err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "更新批次漏洞门禁豁免失败", err)
+	}
+	return nil
+}
+
+func (s *BatchService) batchVulnOverride(batchID int64) (*dto.BatchVulnOverrideResponse, error) {
+	batch, err := s.findBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.BatchVulnOverrideResponse{
+		BatchID:            batch.ID,
+		VulnOverrideAt:     dto.FormatTime(batch.VulnOverrideAt),
+		VulnOverrideBy:     batch.VulnOverrideBy,
+		VulnOverrideReason: batch.VulnOverrideReason,
+	}, nil
+}
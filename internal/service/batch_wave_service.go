@@ -0,0 +1,122 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+)
+
+// 部署波次状态
+const (
+	WaveStatusPending   = "pending"   // 尚未开始
+	WaveStatusRunning   = "running"   // 部署中（含部分完成）
+	WaveStatusCompleted = "completed" // 全部完成
+	WaveStatusFailed    = "failed"    // 存在失败的应用
+)
+
+// waveStage 批次当前所处的部署阶段（pre / prod），决定波次按哪个环境的完成情况统计
+func waveStage(batch *model.Batch) string {
+	if batch.Status >= constants.BatchStatusProdWaiting {
+		return constants.EnvTypeProd
+	}
+	return constants.EnvTypePre
+}
+
+// waveAppStatus 按阶段判断单个发布应用在波次中的状态
+func waveAppStatus(stage string, status int8) string {
+	if stage == constants.EnvTypeProd {
+		switch {
+		case status == constants.ReleaseAppStatusProdFailed:
+			return WaveStatusFailed
+		case status >= constants.ReleaseAppStatusProdDeployed:
+			return WaveStatusCompleted
+		case status == constants.ReleaseAppStatusProdCanTrigger || status == constants.ReleaseAppStatusProdTriggered:
+			return WaveStatusRunning
+		}
+		return WaveStatusPending
+	}
+
+	switch {
+	case status == constants.ReleaseAppStatusPreFailed:
+		return WaveStatusFailed
+	case status >= constants.ReleaseAppStatusPreDeployed:
+		return WaveStatusCompleted
+	case status == constants.ReleaseAppStatusPreCanTrigger || status == constants.ReleaseAppStatusPreTriggered:
+		return WaveStatusRunning
+	}
+	return WaveStatusPending
+}
+
+// loadWaveProgress 统计批次各部署波次的进度，返回波次列表、当前波次（0 表示未开始或已全部完成）与阶段
+//
+// 未封板或封板时未计算波次（历史批次）返回空；生产阶段不统计跳过生产的应用
+func (s *BatchService) loadWaveProgress(batch *model.Batch) ([]dto.BatchWaveProgress, int, string, error) {
+	if batch.Status < constants.BatchStatusSealed || batch.Status == constants.BatchStatusCancelled {
+		return nil, 0, "", nil
+	}
+
+	var releases []model.ReleaseApp
+	if err := s.db.Select("app_id", "status", "skip_prod_env", "wave").
+		Where("batch_id = ? AND wave > 0", batch.ID).
+		Find(&releases).Error; err != nil {
+		return nil, 0, "", fmt.Errorf("查询部署波次失败: %w", err)
+	}
+	if len(releases) == 0 {
+		return nil, 0, "", nil
+	}
+
+	stage := waveStage(batch)
+	byWave := make(map[int]*dto.BatchWaveProgress)
+	for _, rel := range releases {
+		if stage == constants.EnvTypeProd && rel.SkipProdEnv {
+			continue
+		}
+		wave, ok := byWave[rel.Wave]
+		if !ok {
+			wave = &dto.BatchWaveProgress{Wave: rel.Wave}
+			byWave[rel.Wave] = wave
+		}
+		wave.Total++
+		wave.AppIDs = append(wave.AppIDs, rel.AppID)
+		switch waveAppStatus(stage, rel.Status) {
+		case WaveStatusCompleted:
+			wave.Completed++
+		case WaveStatusRunning:
+			wave.Running++
+		case WaveStatusFailed:
+			wave.Failed++
+		}
+	}
+
+	waves := make([]dto.BatchWaveProgress, 0, len(byWave))
+	for _, wave := range byWave {
+		switch {
+		case wave.Failed > 0:
+			wave.Status = WaveStatusFailed
+		case wave.Completed == wave.Total:
+			wave.Status = WaveStatusCompleted
+		case wave.Running > 0 || wave.Completed > 0:
+			wave.Status = WaveStatusRunning
+		default:
+			wave.Status = WaveStatusPending
+		}
+		sort.Slice(wave.AppIDs, func(i, j int) bool { return wave.AppIDs[i] < wave.AppIDs[j] })
+		waves = append(waves, *wave)
+	}
+	sort.Slice(waves, func(i, j int) bool { return waves[i].Wave < waves[j].Wave })
+
+	current := 0
+	for _, wave := range waves {
+		if wave.Status == WaveStatusCompleted {
+			continue
+		}
+		if wave.Status != WaveStatusPending {
+			current = wave.Wave
+		}
+		break
+	}
+	return waves, current, stage, nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"fmt"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"go.uber.org/zap"
+)
+
+// ProcessImagePush 处理镜像仓库推送通知
+//
+//  1. 已有构建（镜像地址 + tag 一致）：记录 digest 与推送时间，标记镜像可用
+//  2. 没有对应构建且开启 CreateBuilds：按该镜像地址最近一次构建确定应用，创建 build_event=registry 的构建，
+//     与 CI 通知一样触发新 tag 检测与自动建批
+//  3. 无法确定应用的镜像忽略
+func (s *buildService) ProcessImagePush(req *dto.ImagePushRequest) (*dto.ImagePushResult, error) {
+	log := logger.Log.With(zap.String("handler", "BuildService.ProcessImagePush"), zap.String("source", req.Source)).Sugar()
+	result := &dto.ImagePushResult{UpdatedBuilds: []int64{}, CreatedBuilds: []int64{}, Ignored: []string{}}
+
+	for _, artifact := range req.Artifacts {
+		ref := artifact.Image + ":" + artifact.Tag
+		builds, err := s.buildRepo.ListByImage(artifact.Image, artifact.Tag)
+		if err != nil {
+			return result, err
+		}
+		if len(builds) > 0 {
+			ids := make([]int64, 0, len(builds))
+			for _, b := range builds {
+				ids = append(ids, b.ID)
+			}
+			if err := s.buildRepo.MarkImagePushed(ids, artifact.Digest, artifact.PushedAt); err != nil {
+				return result, err
+			}
+			result.UpdatedBuilds = append(result.UpdatedBuilds, ids...)
+			log.Infof("镜像已推送，标记构建镜像可用: %s, builds: %v", ref, ids)
+			continue
+		}
+
+		if !req.CreateBuilds {
+			result.Ignored = append(result.Ignored, ref)
+			continue
+		}
+		build, err := s.createRegistryBuild(artifact)
+		if err == pkgErrors.ErrRecordNotFound {
+			log.Infof("镜像无法关联应用（该镜像地址没有历史构建），忽略: %s", ref)
+			result.Ignored = append(result.Ignored, ref)
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		result.CreatedBuilds = append(result.CreatedBuilds, build.ID)
+		log.Infof("按镜像推送创建构建: %s, build: %d, app: %d", ref, build.ID, build.AppID)
+	}
+	return result, nil
+}
+
+// createRegistryBuild 按镜像地址最近一次构建确定代码库/应用，创建镜像推送对应的构建
+func (s *buildService) createRegistryBuild(artifact dto.ImagePushArtifact) (*model.Build, error) {
+	latest, err := s.buildRepo.FindLatestByImage(artifact.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	// 沿用历史构建的镜像地址格式（部分 CI 记录的 image_url 带 tag）
+	imageURL := artifact.Image
+	if latest.ImageURL != artifact.Image {
+		imageURL = fmt.Sprintf("%s:%s", artifact.Image, artifact.Tag)
+	}
+	pushedAt := artifact.PushedAt
+	build := &model.Build{
+		RepoID:          latest.RepoID,
+		AppID:           latest.AppID,
+		BuildStatus:     constants.BuildStatusSuccess,
+		BuildEvent:      constants.BuildEventRegistry,
+		BuildCreated:    pushedAt,
+		BuildStarted:    pushedAt,
+		BuildFinished:   pushedAt,
+		ImageTag:        artifact.Tag,
+		ImageURL:        imageURL,
+		AppBuildSuccess: true,
+		ImagePushedAt:   &pushedAt,
+	}
+	if artifact.Digest != "" {
+		digest := artifact.Digest
+		build.ImageDigest = &digest
+	}
+	if err := s.buildRepo.Create(build); err != nil {
+		return nil, err
+	}
+
+	s.afterBuildCreated(build.AppID, build)
+	return build, nil
+}
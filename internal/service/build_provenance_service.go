@@ -0,0 +1,82 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/git/api"
+	"devops-cd/pkg/constants"
+
+	"go.uber.org/zap"
+)
+
+// VerifyBuildProvenance 通过仓库源 API 核对构建通知中的 tag/commit/分支是否真实存在于该代码库
+//
+// 返回校验结果（constants.BuildProvenance*）及说明:
+//   - tag 构建: tag 必须存在且指向构建的 commit
+//   - 分支构建: commit 必须在该分支历史中
+//   - 未找到仓库源、平台接口异常等无法判断的情况返回 unverified，不阻断构建
+func (s *RepoSyncService) VerifyBuildProvenance(repo *model.Repository, event, ref, branch, sha string) (string, string) {
+	if strings.TrimSpace(sha) == "" {
+		return constants.BuildProvenanceMismatch, "构建通知缺少 commit"
+	}
+
+	source, err := s.findSourceForRepo(repo)
+	if err != nil {
+		return constants.BuildProvenanceUnverified, "未找到代码库对应的启用仓库源"
+	}
+	gitClient, err := s.buildGitClient(source)
+	if err != nil {
+		s.logger.Warn("创建 Git 客户端失败", zap.Int64("source_id", source.ID), zap.Error(err))
+		return constants.BuildProvenanceUnverified, "创建 Git 客户端失败"
+	}
+
+	if tag := strings.TrimPrefix(ref, "refs/tags/"); tag != ref || (event == "tag" && ref != "") {
+		tagSHA, err := gitClient.GetTagCommit(repo.Namespace, repo.Name, tag)
+		if errors.Is(err, api.ErrRefNotFound) {
+			return constants.BuildProvenanceMismatch, fmt.Sprintf("tag %s 在代码库 %s/%s 中不存在", tag, repo.Namespace, repo.Name)
+		}
+		if err != nil {
+			s.logger.Warn("查询 tag 失败", zap.Int64("repo_id", repo.ID), zap.String("tag", tag), zap.Error(err))
+			return constants.BuildProvenanceUnverified, fmt.Sprintf("查询 tag %s 失败", tag)
+		}
+		if !sameCommit(tagSHA, sha) {
+			return constants.BuildProvenanceMismatch, fmt.Sprintf("tag %s 指向 %s，与构建 commit %s 不一致", tag, shortSHA(tagSHA), shortSHA(sha))
+		}
+		return constants.BuildProvenanceVerified, fmt.Sprintf("tag %s -> %s", tag, shortSHA(sha))
+	}
+
+	if branch == "" {
+		return constants.BuildProvenanceUnverified, "构建通知缺少 tag/分支信息"
+	}
+	contains, err := gitClient.BranchContainsCommit(repo.Namespace, repo.Name, branch, sha)
+	if errors.Is(err, api.ErrRefNotFound) {
+		return constants.BuildProvenanceMismatch, fmt.Sprintf("分支 %s 或 commit %s 在代码库 %s/%s 中不存在", branch, shortSHA(sha), repo.Namespace, repo.Name)
+	}
+	if err != nil {
+		s.logger.Warn("查询分支提交失败", zap.Int64("repo_id", repo.ID), zap.String("branch", branch), zap.Error(err))
+		return constants.BuildProvenanceUnverified, fmt.Sprintf("查询分支 %s 失败", branch)
+	}
+	if !contains {
+		return constants.BuildProvenanceMismatch, fmt.Sprintf("commit %s 不在分支 %s 中", shortSHA(sha), branch)
+	}
+	return constants.BuildProvenanceVerified, fmt.Sprintf("%s -> %s", branch, shortSHA(sha))
+}
+
+// sameCommit 比较两个 commit SHA，允许一方为短 SHA（至少 7 位）
+func sameCommit(a, b string) bool {
+	a, b = strings.ToLower(strings.TrimSpace(a)), strings.ToLower(strings.TrimSpace(b))
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	return len(a) >= 7 && strings.HasPrefix(b, a)
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
@@ -12,6 +12,7 @@ import (
 	"devops-cd/internal/model"
 	"devops-cd/internal/pkg/logger"
 	"devops-cd/internal/repository"
+	"devops-cd/pkg/constants"
 	"go.uber.org/zap"
 )
 
@@ -23,22 +24,31 @@ type BuildService interface {
 	List(query *dto.BuildListQuery) ([]*dto.BuildResponse, int64, error)
 	ListByRepoID(repoID int64, limit int) ([]*dto.BuildResponse, error)
 	ListByAppID(appID int64, limit int) ([]*dto.BuildResponse, error)
+	// ProcessImagePush 处理镜像仓库推送通知：标记已有构建镜像可用，按配置为推送的新 tag 创建构建
+	ProcessImagePush(req *dto.ImagePushRequest) (*dto.ImagePushResult, error)
 }
 
 type buildService struct {
-	buildRepo  repository.BuildRepository
-	repoRepo   repository.RepositoryRepository
-	appRepo    *repository.ApplicationRepository
-	coreEngine *core.CoreEngine
+	buildRepo      repository.BuildRepository
+	repoRepo       repository.RepositoryRepository
+	appRepo        *repository.ApplicationRepository
+	coreEngine     *core.CoreEngine
+	repoSync       *RepoSyncService
+	batchService   *BatchService
+	provenanceMode string // 构建来源校验模式: off/flag/reject
 }
 
 // NewBuildService 创建构建服务实例
-func NewBuildService(buildRepo repository.BuildRepository, repoRepo repository.RepositoryRepository, appRepo *repository.ApplicationRepository, coreEngine *core.CoreEngine) BuildService {
+func NewBuildService(buildRepo repository.BuildRepository, repoRepo repository.RepositoryRepository, appRepo *repository.ApplicationRepository, coreEngine *core.CoreEngine,
+	repoSync *RepoSyncService, batchService *BatchService, provenanceMode string) BuildService {
 	return &buildService{
-		buildRepo:  buildRepo,
-		repoRepo:   repoRepo,
-		appRepo:    appRepo,
-		coreEngine: coreEngine,
+		buildRepo:      buildRepo,
+		repoRepo:       repoRepo,
+		appRepo:        appRepo,
+		coreEngine:     coreEngine,
+		repoSync:       repoSync,
+		batchService:   batchService,
+		provenanceMode: provenanceMode,
 	}
 }
 
@@ -62,6 +72,15 @@ func (s *buildService) ProcessNotify(req *dto.BuildNotifyRequest) error {
 		return err
 	}
 
+	// 2.1 来源校验：tag/commit/分支需真实存在于该代码库，防止伪造或错配的构建进入批次
+	provenanceStatus, provenanceMsg := s.verifyProvenance(repo, req)
+	if provenanceStatus == constants.BuildProvenanceMismatch {
+		log.Warnf("构建来源校验不一致: %s", provenanceMsg)
+		if s.provenanceMode == constants.BuildProvenanceModeReject {
+			return pkgErrors.Wrap(pkgErrors.CodeBadRequest, fmt.Sprintf("构建来源校验失败: %s", provenanceMsg), nil)
+		}
+	}
+
 	// 3. 计算构建耗时
 	duration := int(req.BuildFinished - req.BuildStarted)
 
@@ -79,11 +98,23 @@ func (s *buildService) ProcessNotify(req *dto.BuildNotifyRequest) error {
 		commitAuthor = req.GitAuthorName
 	}
 
+	// 5. 未给出应用列表时按路径映射分发到代码库下的应用
+	apps := req.Apps
+	if len(apps) == 0 {
+		if apps, err = s.fanOutApps(repo, req); err != nil {
+			return err
+		}
+		if len(apps) == 0 {
+			log.Infof("构建通知没有匹配变更路径的应用, build_number: %v", req.BuildNumber)
+			return nil
+		}
+	}
+
 	// 6. 逐个处理应用
 	successCount := 0
 	var failedApps []string
 
-	for _, appReq := range req.Apps {
+	for _, appReq := range apps {
 		if err := s.processAppBuild(repo, appReq, func(build *model.Build) {
 			build.BuildStatus = req.BuildStatus
 			build.BuildEvent = req.BuildEvent
@@ -99,6 +130,10 @@ func (s *buildService) ProcessNotify(req *dto.BuildNotifyRequest) error {
 			build.BuildStarted = buildStartedTime
 			build.BuildFinished = buildFinishedTime
 			build.BuildDuration = duration
+			if provenanceStatus != "" {
+				build.ProvenanceStatus = &provenanceStatus
+				build.ProvenanceMessage = &provenanceMsg
+			}
 		}); err != nil {
 			logger.Error("处理应用构建失败", zap.String("app", appReq.Name), zap.Error(err))
 			failedApps = append(failedApps, appReq.Name)
@@ -116,6 +151,59 @@ func (s *buildService) ProcessNotify(req *dto.BuildNotifyRequest) error {
 	return nil
 }
 
+// fanOutApps 单仓多应用：按应用路径映射（path_filter）从变更文件匹配代码库下启用的应用，生成各应用的构建信息
+//
+// 变更文件取通知中的 changed_files，为空时按 commit_before...commit_id 查询代码库；
+// 无法确定变更文件（新分支、tag 构建或查询失败）时分发到全部应用
+func (s *buildService) fanOutApps(repo *model.Repository, req *dto.BuildNotifyRequest) ([]dto.BuildNotifyApp, error) {
+	if req.ImageTag == "" {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeBadRequest, "apps 为空时需要提供 image_tag", nil)
+	}
+	all, err := s.appRepo.ListByRepoID(repo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	files, known := req.ChangedFiles, len(req.ChangedFiles) > 0
+	if before := derefString(req.CommitBefore); !known && strings.Trim(before, "0") != "" {
+		if files, err = s.repoSync.ChangedFiles(repo, before, req.CommitID); err != nil {
+			logger.Warn("查询变更文件失败，分发到代码库全部应用", zap.String("repo", req.Repo), zap.String("base", before), zap.String("head", req.CommitID), zap.Error(err))
+		} else {
+			known = true
+		}
+	}
+
+	var apps []dto.BuildNotifyApp
+	for _, app := range all {
+		if app.Status != constants.StatusEnabled || (known && !app.PathFilter.MatchAny(files)) {
+			continue
+		}
+		item := dto.BuildNotifyApp{Name: app.Name, ImageTag: req.ImageTag}
+		if req.Image != "" {
+			image := strings.ReplaceAll(req.Image, "{app}", app.Name)
+			item.Image = &image
+		}
+		apps = append(apps, item)
+	}
+	logger.Info("构建通知按路径分发",
+		zap.String("repo", req.Repo),
+		zap.Int64("build_number", req.BuildNumber),
+		zap.Bool("files_known", known),
+		zap.Int("changed_files", len(files)),
+		zap.Int("apps", len(apps)))
+	return apps, nil
+}
+
+// verifyProvenance 按配置的模式校验构建来源，未开启时返回空
+func (s *buildService) verifyProvenance(repo *model.Repository, req *dto.BuildNotifyRequest) (string, string) {
+	switch s.provenanceMode {
+	case constants.BuildProvenanceModeFlag, constants.BuildProvenanceModeReject:
+	default:
+		return "", ""
+	}
+	return s.repoSync.VerifyBuildProvenance(repo, req.BuildEvent, req.CommitRef, req.CommitBranch, req.CommitID)
+}
+
 // processAppBuild 处理单个应用的构建记录
 func (s *buildService) processAppBuild(repo *model.Repository, appReq dto.BuildNotifyApp, updateFunc func(build *model.Build)) error {
 	// 1. 查询应用（按 repo_id + name 查询，确保唯一性）
@@ -159,14 +247,23 @@ func (s *buildService) processAppBuild(repo *model.Repository, appReq dto.BuildN
 		return err
 	}
 
-	// 6. 通知New Tag事件
-	s.coreEngine.NewTag(app.ID, build)
+	// 6. 通知New Tag事件、自动建批
+	s.afterBuildCreated(app.ID, build)
 
 	logger.Info("应用构建记录已创建", zap.Int64("build_id", build.ID), zap.Int64("app_id", app.ID), zap.String("app_name", app.Name), zap.String("tag", appReq.ImageTag))
 
 	return nil
 }
 
+// afterBuildCreated 通知 New Tag 事件，并按项目自动建批规则加入（或创建）草稿批次，失败不影响构建记录
+func (s *buildService) afterBuildCreated(appID int64, build *model.Build) {
+	s.coreEngine.NewTag(appID, build)
+
+	if _, err := s.batchService.AutoBatchForBuild(appID, build); err != nil {
+		logger.Warn("自动建批失败", zap.Int64("build_id", build.ID), zap.Int64("app_id", appID), zap.Error(err))
+	}
+}
+
 // GetByID 根据ID获取构建记录
 func (s *buildService) GetByID(id int64) (*dto.BuildResponse, error) {
 	build, err := s.buildRepo.FindByID(id)
@@ -244,29 +341,38 @@ func (s *buildService) ListByAppID(appID int64, limit int) ([]*dto.BuildResponse
 // toResponse 转换为响应对象
 func (s *buildService) toResponse(build *model.Build) *dto.BuildResponse {
 	resp := &dto.BuildResponse{
-		ID:              build.ID,
-		RepoID:          build.RepoID,
-		AppID:           build.AppID,
-		BuildNumber:     build.BuildNumber,
-		BuildStatus:     build.BuildStatus,
-		BuildEvent:      build.BuildEvent,
-		BuildLink:       build.BuildLink,
-		CommitSHA:       build.CommitSHA,
-		CommitRef:       build.CommitRef,
-		CommitBranch:    build.CommitBranch,
-		CommitMessage:   build.CommitMessage,
-		CommitLink:      build.CommitLink,
-		CommitAuthor:    build.CommitAuthor,
-		BuildCreated:    build.BuildCreated.Format(time.RFC3339),
-		BuildStarted:    build.BuildStarted.Format(time.RFC3339),
-		BuildFinished:   build.BuildFinished.Format(time.RFC3339),
-		BuildDuration:   build.BuildDuration,
-		ImageTag:        build.ImageTag,
-		ImageURL:        build.ImageURL,
-		AppBuildSuccess: build.AppBuildSuccess,
-		Environment:     build.Environment,
-		CreatedAt:       build.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:       build.UpdatedAt.Format(time.RFC3339),
+		ID:                build.ID,
+		RepoID:            build.RepoID,
+		AppID:             build.AppID,
+		BuildNumber:       build.BuildNumber,
+		BuildStatus:       build.BuildStatus,
+		BuildEvent:        build.BuildEvent,
+		BuildLink:         build.BuildLink,
+		CommitSHA:         build.CommitSHA,
+		CommitRef:         build.CommitRef,
+		CommitBranch:      build.CommitBranch,
+		CommitMessage:     build.CommitMessage,
+		CommitLink:        build.CommitLink,
+		CommitAuthor:      build.CommitAuthor,
+		BuildCreated:      build.BuildCreated.Format(time.RFC3339),
+		BuildStarted:      build.BuildStarted.Format(time.RFC3339),
+		BuildFinished:     build.BuildFinished.Format(time.RFC3339),
+		BuildDuration:     build.BuildDuration,
+		ImageTag:          build.ImageTag,
+		ImageURL:          build.ImageURL,
+		AppBuildSuccess:   build.AppBuildSuccess,
+		Environment:       build.Environment,
+		ProvenanceStatus:  build.ProvenanceStatus,
+		ProvenanceMessage: build.ProvenanceMessage,
+		ImageDigest:       build.ImageDigest,
+		ArchivedAt:        dto.FormatTime(build.ArchivedAt),
+		CreatedAt:         build.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:         build.UpdatedAt.Format(time.RFC3339),
+	}
+
+	if build.ImagePushedAt != nil {
+		pushedAt := build.ImagePushedAt.Format(time.RFC3339)
+		resp.ImagePushedAt = &pushedAt
 	}
 
 	// 关联的仓库名称
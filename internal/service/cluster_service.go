@@ -1,11 +1,14 @@
 package service
 
 import (
+	"context"
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
 	"devops-cd/internal/dto"
 	"devops-cd/internal/model"
 	"devops-cd/internal/repository"
 	"devops-cd/pkg/responses"
 	"fmt"
+	"strings"
 
 	"gorm.io/gorm"
 )
@@ -35,9 +38,19 @@ func (s *ClusterService) Create(req *dto.ClusterCreateRequest) (*dto.ClusterResp
 
 	// 2. 创建集群
 	cluster := &model.Cluster{
-		Name:        req.Name,
-		Description: req.Description,
-		Region:      req.Region,
+		Name:                  req.Name,
+		Description:           req.Description,
+		Region:                req.Region,
+		AuthType:              req.AuthType,
+		Kubeconfig:            req.Kubeconfig,
+		Server:                req.Server,
+		CAData:                req.CAData,
+		InsecureSkipTLSVerify: req.InsecureSkipTLSVerify,
+		Token:                 req.Token,
+		Exec:                  req.Exec,
+	}
+	if err := normalizeClusterAuth(cluster); err != nil {
+		return nil, err
 	}
 
 	if err := s.clusterRepo.Create(cluster); err != nil {
@@ -77,6 +90,30 @@ func (s *ClusterService) Update(id int64, req *dto.ClusterUpdateRequest) (*dto.C
 	if req.Region != nil {
 		cluster.Region = req.Region
 	}
+	if req.AuthType != nil {
+		cluster.AuthType = *req.AuthType
+	}
+	if req.Kubeconfig != nil {
+		cluster.Kubeconfig = *req.Kubeconfig
+	}
+	if req.Server != nil {
+		cluster.Server = *req.Server
+	}
+	if req.CAData != nil {
+		cluster.CAData = *req.CAData
+	}
+	if req.InsecureSkipTLSVerify != nil {
+		cluster.InsecureSkipTLSVerify = *req.InsecureSkipTLSVerify
+	}
+	if req.Token != nil {
+		cluster.Token = *req.Token
+	}
+	if req.Exec != nil {
+		cluster.Exec = req.Exec
+	}
+	if err := normalizeClusterAuth(cluster); err != nil {
+		return nil, err
+	}
 
 	// 4. 保存更新
 	if err := s.clusterRepo.Update(cluster); err != nil {
@@ -145,6 +182,72 @@ func (s *ClusterService) Delete(id int64) error {
 	return nil
 }
 
+// Preflight 集群连通性预检：kubeconfig 认证、namespace、chart repo、镜像拉取（dry-run）
+func (s *ClusterService) Preflight(ctx context.Context, id int64, req *dto.ClusterPreflightRequest) (*dto.ClusterPreflightResponse, error) {
+	cluster, err := s.clusterRepo.FindByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, responses.Wrap(responses.CodeNotFound, "集群不存在", err)
+		}
+		return nil, responses.Wrap(responses.CodeInternalError, "查询集群失败", err)
+	}
+
+	kubeconfig, err := helmDriver.ClusterKubeconfig(cluster)
+	if err != nil {
+		return nil, responses.Wrap(responses.CodeBadRequest, "集群认证配置无效", err)
+	}
+
+	param := &helmDriver.PreflightParam{
+		Kubeconfig:   kubeconfig,
+		Namespace:    req.Namespace,
+		ChartRepoURL: req.ChartRepoURL,
+		Image:        req.Image,
+	}
+	if req.CredentialRef != "" {
+		u, p, err := helmDriver.New(s.db).ResolveBasicAuth(req.CredentialRef)
+		if err != nil {
+			return nil, responses.Wrap(responses.CodeBadRequest, "解析 credential_ref 失败", err)
+		}
+		param.ChartUsername, param.ChartPassword = u, p
+	}
+
+	report := helmDriver.Preflight(ctx, param)
+	resp := &dto.ClusterPreflightResponse{
+		ClusterID:   cluster.ID,
+		ClusterName: cluster.Name,
+		Passed:      report.Passed,
+		Checks:      make([]dto.ClusterPreflightCheck, 0, len(report.Checks)),
+	}
+	for _, c := range report.Checks {
+		resp.Checks = append(resp.Checks, dto.ClusterPreflightCheck{
+			Name:       c.Name,
+			Passed:     c.Passed,
+			Skipped:    c.Skipped,
+			Message:    c.Message,
+			DurationMs: c.DurationMs,
+		})
+	}
+	return resp, nil
+}
+
+// normalizeClusterAuth 校验集群认证配置，并清理与认证方式无关的 exec 配置
+func normalizeClusterAuth(cluster *model.Cluster) error {
+	cluster.AuthType = cluster.Auth()
+	cluster.Server = strings.TrimRight(strings.TrimSpace(cluster.Server), "/")
+	cluster.CAData = strings.TrimSpace(cluster.CAData)
+	cluster.Token = strings.TrimSpace(cluster.Token)
+	if cluster.Exec != nil {
+		cluster.Exec.Command = strings.TrimSpace(cluster.Exec.Command)
+		if cluster.AuthType != model.ClusterAuthExec || cluster.Exec.Command == "" {
+			cluster.Exec = nil
+		}
+	}
+	if err := cluster.ValidateAuth(); err != nil {
+		return responses.Wrap(responses.CodeBadRequest, "集群认证配置无效", err)
+	}
+	return nil
+}
+
 // toClusterResponse 转换为响应DTO
 func (s *ClusterService) toClusterResponse(cluster *model.Cluster) *dto.ClusterResponse {
 	return &dto.ClusterResponse{
@@ -152,7 +255,16 @@ func (s *ClusterService) toClusterResponse(cluster *model.Cluster) *dto.ClusterR
 		Name:        cluster.Name,
 		Description: cluster.Description,
 		Region:      cluster.Region,
-		CreatedAt:   cluster.CreatedAt.Format("2006-01-02 15:04:05"),
-		UpdatedAt:   cluster.UpdatedAt.Format("2006-01-02 15:04:05"),
+
+		AuthType:              cluster.Auth(),
+		Server:                cluster.Server,
+		CAData:                cluster.CAData,
+		InsecureSkipTLSVerify: cluster.InsecureSkipTLSVerify,
+		Exec:                  cluster.Exec,
+		HasKubeconfig:         strings.TrimSpace(cluster.Kubeconfig) != "",
+		HasToken:              cluster.Token != "",
+
+		CreatedAt: cluster.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt: cluster.UpdatedAt.Format("2006-01-02 15:04:05"),
 	}
 }
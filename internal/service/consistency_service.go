@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 单个检查项最多返回的记录数
+const consistencyIssueLimit = 1000
+
+type ConsistencyService interface {
+	// Check 执行全部一致性检查（只读）
+	Check(ctx context.Context) (*dto.ConsistencyReport, error)
+	// Repair 修复指定检查项；dry_run 时只返回将要修复的记录
+	Repair(ctx context.Context, req *dto.ConsistencyRepairRequest, operator string) (*dto.ConsistencyRepairResult, error)
+}
+
+type consistencyService struct {
+	db *gorm.DB
+}
+
+func NewConsistencyService(db *gorm.DB) ConsistencyService {
+	return &consistencyService{db: db}
+}
+
+func (s *consistencyService) Check(ctx context.Context) (*dto.ConsistencyReport, error) {
+	report := &dto.ConsistencyReport{
+		CheckedAt: time.Now().Format(time.RFC3339),
+		Counts:    make(map[string]int),
+		Issues:    []dto.ConsistencyIssue{},
+	}
+
+	checks := []struct {
+		name string
+		fn   func(ctx context.Context) ([]dto.ConsistencyIssue, error)
+	}{
+		{constants.ConsistencyOrphanReleaseApps, s.checkOrphanReleaseApps},
+		{constants.ConsistencyOrphanDeployments, s.checkOrphanDeployments},
+		{constants.ConsistencyDanglingDeployedTag, s.checkDanglingDeployedTag},
+		{constants.ConsistencyReleaseStatus, s.checkReleaseStatus},
+	}
+	for _, c := range checks {
+		issues, err := c.fn(ctx)
+		if err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, fmt.Sprintf("一致性检查 %s 失败", c.name), err)
+		}
+		report.Counts[c.name] = len(issues)
+		report.Issues = append(report.Issues, issues...)
+	}
+	report.Total = len(report.Issues)
+	return report, nil
+}
+
+func (s *consistencyService) Repair(ctx context.Context, req *dto.ConsistencyRepairRequest, operator string) (*dto.ConsistencyRepairResult, error) {
+	result := &dto.ConsistencyRepairResult{
+		DryRun:   req.IsDryRun(),
+		Repaired: make(map[string]int),
+		Issues:   []dto.ConsistencyIssue{},
+	}
+
+	for _, check := range req.Checks {
+		var (
+			issues []dto.ConsistencyIssue
+			repair func(ctx context.Context, issues []dto.ConsistencyIssue) (int, error)
+			err    error
+		)
+		switch check {
+		case constants.ConsistencyOrphanReleaseApps:
+			issues, err = s.checkOrphanReleaseApps(ctx)
+			repair = s.repairOrphanReleaseApps
+		case constants.ConsistencyOrphanDeployments:
+			issues, err = s.checkOrphanDeployments(ctx)
+			repair = s.repairOrphanDeployments
+		case constants.ConsistencyReleaseStatus:
+			issues, err = s.checkReleaseStatus(ctx)
+			repair = s.repairReleaseStatus
+		default:
+			return nil, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("检查项 %s 不支持自动修复", check))
+		}
+		if err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, fmt.Sprintf("一致性检查 %s 失败", check), err)
+		}
+
+		repairable := make([]dto.ConsistencyIssue, 0, len(issues))
+		for _, issue := range issues {
+			if issue.Repairable {
+				repairable = append(repairable, issue)
+			}
+		}
+		result.Issues = append(result.Issues, repairable...)
+
+		if result.DryRun || len(repairable) == 0 {
+			result.Repaired[check] = len(repairable)
+			continue
+		}
+
+		n, err := repair(ctx, repairable)
+		if err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, fmt.Sprintf("一致性修复 %s 失败", check), err)
+		}
+		result.Repaired[check] = n
+		logger.Info("一致性修复完成", zap.String("check", check), zap.Int("repaired", n), zap.String("operator", operator))
+	}
+
+	return result, nil
+}
+
+// ============ checks
+
+func (s *consistencyService) checkOrphanReleaseApps(ctx context.Context) ([]dto.ConsistencyIssue, error) {
+	var rows []struct {
+		ID      int64
+		BatchID int64
+		AppID   int64
+	}
+	if err := s.db.WithContext(ctx).Raw(`
+		SELECT ra.id, ra.batch_id, ra.app_id
+		FROM release_apps ra
+		LEFT JOIN release_batches b ON b.id = ra.batch_id
+		WHERE b.id IS NULL
+		LIMIT ?
+	`, consistencyIssueLimit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	issues := make([]dto.ConsistencyIssue, 0, len(rows))
+	for _, r := range rows {
+		batchID := r.BatchID
+		issues = append(issues, dto.ConsistencyIssue{
+			Check:      constants.ConsistencyOrphanReleaseApps,
+			Entity:     model.BatchReleaseAppTableName,
+			EntityID:   r.ID,
+			BatchID:    &batchID,
+			Detail:     fmt.Sprintf("应用 %d 所属批次 %d 不存在", r.AppID, r.BatchID),
+			Repairable: true,
+		})
+	}
+	return issues, nil
+}
+
+func (s *consistencyService) checkOrphanDeployments(ctx context.Context) ([]dto.ConsistencyIssue, error) {
+	var rows []struct {
+		ID        int64
+		BatchID   int64
+		ReleaseID int64
+		Cluster   string
+		Status    string
+	}
+	if err := s.db.WithContext(ctx).Raw(`
+		SELECT d.id, d.batch_id, d.release_id, d.cluster, d.status
+		FROM deployments d
+		LEFT JOIN release_apps ra ON ra.id = d.release_id
+		WHERE ra.id IS NULL
+		LIMIT ?
+	`, consistencyIssueLimit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	issues := make([]dto.ConsistencyIssue, 0, len(rows))
+	for _, r := range rows {
+		batchID := r.BatchID
+		issues = append(issues, dto.ConsistencyIssue{
+			Check:      constants.ConsistencyOrphanDeployments,
+			Entity:     model.DeploymentTableName,
+			EntityID:   r.ID,
+			BatchID:    &batchID,
+			Detail:     fmt.Sprintf("集群 %s 的部署(%s)关联的 release_app %d 不存在", r.Cluster, r.Status, r.ReleaseID),
+			Repairable: true,
+		})
+	}
+	return issues, nil
+}
+
+// checkDanglingDeployedTag deployed_tag 无对应构建时无法判断正确版本，仅报告不自动修复
+func (s *consistencyService) checkDanglingDeployedTag(ctx context.Context) ([]dto.ConsistencyIssue, error) {
+	var rows []struct {
+		ID          int64
+		Name        string
+		DeployedTag string
+	}
+	if err := s.db.WithContext(ctx).Raw(`
+		SELECT a.id, a.name, a.deployed_tag
+		FROM applications a
+		WHERE a.deleted_at IS NULL
+		AND a.deployed_tag IS NOT NULL AND a.deployed_tag != ''
+		AND NOT EXISTS(
+			SELECT 1 FROM builds b WHERE b.app_id = a.id AND b.image_tag = a.deployed_tag
+		)
+		LIMIT ?
+	`, consistencyIssueLimit).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	issues := make([]dto.ConsistencyIssue, 0, len(rows))
+	for _, r := range rows {
+		issues = append(issues, dto.ConsistencyIssue{
+			Check:    constants.ConsistencyDanglingDeployedTag,
+			Entity:   model.ApplicationTableName,
+			EntityID: r.ID,
+			Detail:   fmt.Sprintf("应用 %s 的 deployed_tag=%s 无对应构建记录", r.Name, r.DeployedTag),
+		})
+	}
+	return issues, nil
+}
+
+// releaseStatusRepairTarget 状态不一致时的回退目标，返回 0 表示不支持自动修复
+//   - Triggered 但没有 deployment: 回退到 CanTrigger，重新生成 deployment
+//   - Deployed 但 deployment 未全部成功: 回退到 Triggered，由状态机重新汇总
+func releaseStatusRepairTarget(status int8, total, success int64) int8 {
+	switch status {
+	case constants.ReleaseAppStatusPreTriggered:
+		if total == 0 {
+			return constants.ReleaseAppStatusPreCanTrigger
+		}
+	case constants.ReleaseAppStatusProdTriggered:
+		if total == 0 {
+			return constants.ReleaseAppStatusProdCanTrigger
+		}
+	case constants.ReleaseAppStatusPreDeployed:
+		if total == 0 {
+			return constants.ReleaseAppStatusPreCanTrigger
+		}
+		return constants.ReleaseAppStatusPreTriggered
+	case constants.ReleaseAppStatusProdDeployed:
+		if total == 0 {
+			return constants.ReleaseAppStatusProdCanTrigger
+		}
+		return constants.ReleaseAppStatusProdTriggered
+	}
+	return 0
+}
+
+type releaseStatusRow struct {
+	ID      int64
+	BatchID int64
+	Status  int8
+	Total   int64
+	Success int64
+}
+
+func (s *consistencyService) queryReleaseStatusRows(ctx context.Context, db *gorm.DB) ([]releaseStatusRow, error) {
+	statuses := []int8{
+		constants.ReleaseAppStatusPreTriggered, constants.ReleaseAppStatusPreDeployed, constants.ReleaseAppStatusPreAccepted,
+		constants.ReleaseAppStatusProdTriggered, constants.ReleaseAppStatusProdDeployed, constants.ReleaseAppStatusProdAccepted,
+	}
+	var rows []releaseStatusRow
+	err := db.WithContext(ctx).Raw(`
+		SELECT ra.id, ra.batch_id, ra.status,
+			COUNT(d.id) AS total,
+			COALESCE(SUM(CASE WHEN d.status = ? THEN 1 ELSE 0 END), 0) AS success
+		FROM release_apps ra
+		JOIN release_batches b ON b.id = ra.batch_id
+		LEFT JOIN deployments d ON d.release_id = ra.id AND d.superseded_by IS NULL
+			AND d.env = CASE WHEN ra.status < ? THEN ? ELSE ? END
+		WHERE ra.status IN ? AND b.status > ? AND b.status < ?
+		GROUP BY ra.id, ra.batch_id, ra.status
+	`, constants.DeploymentStatusSuccess,
+		constants.ReleaseAppStatusProdWaiting, constants.EnvTypePre, constants.EnvTypeProd,
+		statuses, constants.BatchStatusDraft, constants.BatchStatusCompleted).Scan(&rows).Error
+	return rows, err
+}
+
+// checkReleaseStatus 仅检查进行中批次（封板后、完成前）的 release_app
+func (s *consistencyService) checkReleaseStatus(ctx context.Context) ([]dto.ConsistencyIssue, error) {
+	rows, err := s.queryReleaseStatusRows(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := make([]dto.ConsistencyIssue, 0)
+	for _, r := range rows {
+		var detail string
+		switch r.Status {
+		case constants.ReleaseAppStatusPreTriggered, constants.ReleaseAppStatusProdTriggered:
+			if r.Total > 0 {
+				continue
+			}
+			detail = fmt.Sprintf("状态 %d 已触发但没有生效的 deployment", r.Status)
+		default:
+			if r.Total > 0 && r.Success == r.Total {
+				continue
+			}
+			detail = fmt.Sprintf("状态 %d 已部署/验收，但 deployment 成功 %d/%d", r.Status, r.Success, r.Total)
+		}
+
+		batchID := r.BatchID
+		issues = append(issues, dto.ConsistencyIssue{
+			Check:      constants.ConsistencyReleaseStatus,
+			Entity:     model.BatchReleaseAppTableName,
+			EntityID:   r.ID,
+			BatchID:    &batchID,
+			Detail:     detail,
+			Repairable: releaseStatusRepairTarget(r.Status, r.Total, r.Success) != 0,
+		})
+		if len(issues) >= consistencyIssueLimit {
+			break
+		}
+	}
+	return issues, nil
+}
+
+// ============ repairs
+
+func issueIDs(issues []dto.ConsistencyIssue) []int64 {
+	ids := make([]int64, 0, len(issues))
+	for _, issue := range issues {
+		ids = append(ids, issue.EntityID)
+	}
+	return ids
+}
+
+func (s *consistencyService) repairOrphanReleaseApps(ctx context.Context, issues []dto.ConsistencyIssue) (int, error) {
+	// 删除时再次校验批次不存在，避免检查与修复之间的并发变更
+	result := s.db.WithContext(ctx).Exec(`
+		DELETE FROM release_apps
+		WHERE id IN ? AND NOT EXISTS(SELECT 1 FROM release_batches b WHERE b.id = release_apps.batch_id)
+	`, issueIDs(issues))
+	return int(result.RowsAffected), result.Error
+}
+
+func (s *consistencyService) repairOrphanDeployments(ctx context.Context, issues []dto.ConsistencyIssue) (int, error) {
+	result := s.db.WithContext(ctx).Exec(`
+		DELETE FROM deployments
+		WHERE id IN ? AND NOT EXISTS(SELECT 1 FROM release_apps ra WHERE ra.id = deployments.release_id)
+	`, issueIDs(issues))
+	return int(result.RowsAffected), result.Error
+}
+
+func (s *consistencyService) repairReleaseStatus(ctx context.Context, issues []dto.ConsistencyIssue) (int, error) {
+	wanted := make(map[int64]struct{}, len(issues))
+	for _, issue := range issues {
+		wanted[issue.EntityID] = struct{}{}
+	}
+
+	repaired := 0
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 事务内重新统计，只修复仍然不一致的记录
+		rows, err := s.queryReleaseStatusRows(ctx, tx)
+		if err != nil {
+			return err
+		}
+		for _, r := range rows {
+			if _, ok := wanted[r.ID]; !ok {
+				continue
+			}
+			to := releaseStatusRepairTarget(r.Status, r.Total, r.Success)
+			if to == 0 || (r.Total > 0 && r.Success == r.Total) {
+				continue
+			}
+			result := tx.Model(&model.ReleaseApp{}).
+				Where("id = ? AND status = ?", r.ID, r.Status).
+				Update("status", to)
+			if result.Error != nil {
+				return result.Error
+			}
+			repaired += int(result.RowsAffected)
+		}
+		return nil
+	})
+	return repaired, err
+}
@@ -18,6 +18,8 @@ type CredentialService interface {
 	List(scope string, projectID *int64) ([]*dto.CredentialResponse, error)
 	Update(id int64, req *dto.UpdateCredentialRequest) (*dto.CredentialResponse, error)
 	Delete(id int64) error
+	// Reencrypt 将存量凭据迁移到当前 crypto.provider（在线执行，逐条 CAS 更新）
+	Reencrypt(batchSize int, dryRun bool) (*dto.CredentialReencryptResult, error)
 }
 
 type credentialService struct {
@@ -36,9 +38,9 @@ func (s *credentialService) Create(req *dto.CreateCredentialRequest) (*dto.Crede
 		req.ProjectID = nil
 	}
 
-	enc, err := crypto.Encrypt(string(req.Data))
+	enc, keyID, err := crypto.EncryptEnvelope(string(req.Data))
 	if err != nil {
-		return nil, pkgErrors.Wrap(pkgErrors.CodeBadRequest, "凭据加密失败，请检查 crypto.aes_key 配置", err)
+		return nil, pkgErrors.Wrap(pkgErrors.CodeBadRequest, "凭据加密失败，请检查 crypto 配置", err)
 	}
 
 	c := &model.Credential{
@@ -47,6 +49,7 @@ func (s *credentialService) Create(req *dto.CreateCredentialRequest) (*dto.Crede
 		Name:          req.Name,
 		Type:          req.Type,
 		EncryptedData: enc,
+		KeyID:         keyID,
 	}
 	if req.Meta != nil && len(req.Meta) > 0 {
 		c.MetaJSON = datatypes.JSON(req.Meta)
@@ -86,11 +89,12 @@ func (s *credentialService) Update(id int64, req *dto.UpdateCredentialRequest) (
 
 	c.Name = req.Name
 	if req.Data != nil && len(req.Data) > 0 {
-		enc, err := crypto.Encrypt(string(req.Data))
+		enc, keyID, err := crypto.EncryptEnvelope(string(req.Data))
 		if err != nil {
-			return nil, pkgErrors.Wrap(pkgErrors.CodeBadRequest, "凭据加密失败，请检查 crypto.aes_key 配置", err)
+			return nil, pkgErrors.Wrap(pkgErrors.CodeBadRequest, "凭据加密失败，请检查 crypto 配置", err)
 		}
 		c.EncryptedData = enc
+		c.KeyID = keyID
 	}
 	if req.Meta != nil {
 		c.MetaJSON = datatypes.JSON(req.Meta)
@@ -106,6 +110,23 @@ func (s *credentialService) Delete(id int64) error {
 	return s.repo.Delete(id)
 }
 
+func (s *credentialService) Reencrypt(batchSize int, dryRun bool) (*dto.CredentialReencryptResult, error) {
+	return reencrypt("credential", batchSize, dryRun, func(target string, afterID int64, limit int) ([]reencryptRecord, error) {
+		list, err := s.repo.ListForReencrypt(target, afterID, limit)
+		if err != nil {
+			return nil, err
+		}
+		records := make([]reencryptRecord, 0, len(list))
+		for _, c := range list {
+			records = append(records, reencryptRecord{
+				ID: c.ID, Ciphertext: c.EncryptedData, KeyID: c.KeyID,
+				swap: func(enc, keyID string) (bool, error) { return s.repo.SwapEncryptedData(c, enc, keyID) },
+			})
+		}
+		return records, nil
+	})
+}
+
 func toCredentialResponse(c *model.Credential) *dto.CredentialResponse {
 	if c == nil {
 		return nil
@@ -130,7 +151,7 @@ func DecryptCredentialData(c *model.Credential) (json.RawMessage, error) {
 	if c == nil {
 		return nil, fmt.Errorf("credential is nil")
 	}
-	plain, err := crypto.Decrypt(c.EncryptedData)
+	plain, err := crypto.DecryptWithKeyID(c.EncryptedData, c.KeyID)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/database"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// dashboardFailureWindow 最近失败部署的时间范围
+	dashboardFailureWindow = 7 * 24 * time.Hour
+	// dashboardFailureLimit 最近失败部署的返回条数
+	dashboardFailureLimit = 10
+)
+
+// Dashboard 首页汇总：进行中的批次（按状态）、进行中的部署（按集群）、最近失败的部署、待当前用户审批的批次
+//
+// 批次与部署只统计 canView 的项目；待审批按审批策略中的审批人判断（同 ListPendingApprovals）
+func (s *BatchService) Dashboard(username string, canView func(projectID int64) bool) (*dto.DashboardResponse, error) {
+	// 看板查询读副本
+	db := database.ReadReplica(s.db)
+
+	resp := &dto.DashboardResponse{
+		BatchesByStatus:  []*dto.DashboardBatchStatusCount{},
+		DeploysByCluster: []*dto.DashboardClusterDeployments{},
+		RecentFailures:   []*dto.DashboardFailure{},
+		GeneratedAt:      time.Now(),
+	}
+
+	approvals, err := s.ListPendingApprovals(username)
+	if err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询待审批批次失败", err)
+	}
+	resp.PendingApprovals = approvals
+
+	var projectIDs []int64
+	if err := db.Model(&model.Project{}).Pluck("id", &projectIDs).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询项目失败", err)
+	}
+	visible := make([]int64, 0, len(projectIDs))
+	for _, id := range projectIDs {
+		if canView(id) {
+			visible = append(visible, id)
+		}
+	}
+	if len(visible) == 0 {
+		return resp, nil
+	}
+
+	// 1. 进行中的批次（已封板未完成及回滚中）
+	activeBatches := db.Model(&model.Batch{}).
+		Where("project_id IN ?", visible).
+		Where("(status > ? AND status < ?) OR status = ?", constants.BatchStatusDraft, constants.BatchStatusCompleted, constants.BatchStatusRollingBack)
+	var statusRows []struct {
+		Status int8
+		Count  int64
+	}
+	if err := activeBatches.Session(&gorm.Session{}).Select("status, COUNT(*) AS count").Group("status").Scan(&statusRows).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "统计进行中的批次失败", err)
+	}
+	sort.Slice(statusRows, func(i, j int) bool { return statusRows[i].Status < statusRows[j].Status })
+	for _, row := range statusRows {
+		resp.ActiveBatches += row.Count
+		resp.BatchesByStatus = append(resp.BatchesByStatus, &dto.DashboardBatchStatusCount{
+			Status:     row.Status,
+			StatusName: constants.BatchStatusToString(row.Status),
+			Count:      row.Count,
+		})
+	}
+
+	// 2. 进行中批次内 pending/running 的当前 Deployment，按集群
+	var clusterRows []struct {
+		Cluster string
+		Status  string
+		Count   int64
+	}
+	if err := db.Model(&model.Deployment{}).
+		Select("deployments.cluster AS cluster, deployments.status AS status, COUNT(*) AS count").
+		Where("deployments.batch_id IN (?)", activeBatches.Session(&gorm.Session{}).Select("id")).
+		Where("deployments.superseded_by IS NULL AND deployments.status IN ?",
+			[]string{constants.DeploymentStatusPending, constants.DeploymentStatusRunning}).
+		Group("deployments.cluster, deployments.status").Scan(&clusterRows).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "统计进行中的部署失败", err)
+	}
+	byCluster := make(map[string]*dto.DashboardClusterDeployments)
+	for _, row := range clusterRows {
+		item, ok := byCluster[row.Cluster]
+		if !ok {
+			item = &dto.DashboardClusterDeployments{Cluster: row.Cluster}
+			byCluster[row.Cluster] = item
+			resp.DeploysByCluster = append(resp.DeploysByCluster, item)
+		}
+		if row.Status == constants.DeploymentStatusRunning {
+			item.Running += row.Count
+		} else {
+			item.Pending += row.Count
+		}
+		resp.InFlightDeploys += row.Count
+	}
+	sort.Slice(resp.DeploysByCluster, func(i, j int) bool {
+		a, b := resp.DeploysByCluster[i], resp.DeploysByCluster[j]
+		if a.Running+a.Pending != b.Running+b.Pending {
+			return a.Running+a.Pending > b.Running+b.Pending
+		}
+		return a.Cluster < b.Cluster
+	})
+
+	// 3. 最近失败的部署
+	var failures []*model.Deployment
+	if err := db.Select("deployments.id", "deployments.batch_id", "deployments.app_id", "deployments.env", "deployments.cluster",
+		"deployments.status", "deployments.error_message", "deployments.finished_at").
+		Joins("JOIN release_batches ON release_batches.id = deployments.batch_id").
+		Where("release_batches.project_id IN ?", visible).
+		Where("deployments.status IN ? AND deployments.finished_at >= ?",
+			[]string{constants.DeploymentStatusFailed, constants.DeploymentStatusVerifyFailed}, time.Now().Add(-dashboardFailureWindow)).
+		Order("deployments.finished_at DESC").Limit(dashboardFailureLimit).Find(&failures).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询最近失败的部署失败", err)
+	}
+	if len(failures) == 0 {
+		return resp, nil
+	}
+	batchIDs := make([]int64, 0, len(failures))
+	appIDs := make([]int64, 0, len(failures))
+	for _, dep := range failures {
+		batchIDs = append(batchIDs, dep.BatchID)
+		appIDs = append(appIDs, dep.AppID)
+	}
+	var batches []*model.Batch
+	if err := db.Select("id", "batch_number", "project_id").Where("id IN ?", uniqueInt64s(batchIDs)).Find(&batches).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询批次失败", err)
+	}
+	batchByID := make(map[int64]*model.Batch, len(batches))
+	for _, batch := range batches {
+		batchByID[batch.ID] = batch
+	}
+	var apps []*model.Application
+	if err := db.Unscoped().Select("id", "name").Where("id IN ?", uniqueInt64s(appIDs)).Find(&apps).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用失败", err)
+	}
+	appNames := make(map[int64]string, len(apps))
+	for _, app := range apps {
+		appNames[app.ID] = app.Name
+	}
+	for _, dep := range failures {
+		item := &dto.DashboardFailure{
+			DeploymentID: dep.ID,
+			BatchID:      dep.BatchID,
+			AppID:        dep.AppID,
+			AppName:      appNames[dep.AppID],
+			Env:          dep.Env,
+			Cluster:      dep.ClusterName,
+			Status:       dep.Status,
+			FinishedAt:   dep.FinishedAt,
+		}
+		if batch, ok := batchByID[dep.BatchID]; ok {
+			item.BatchNumber = batch.BatchNumber
+			item.ProjectID = batch.ProjectID
+		}
+		if dep.ErrorMessage != nil {
+			item.ErrorMessage = *dep.ErrorMessage
+		}
+		resp.RecentFailures = append(resp.RecentFailures, item)
+	}
+	return resp, nil
+}
@@ -74,3 +74,45 @@ func hasDependencyCycle(graph map[int64][]int64) bool {
 
 	return false
 }
+
+// dependencyLevels 按依赖拓扑分层（同层应用之间无依赖，可并行），存在循环时返回 false
+func dependencyLevels(graph map[int64][]int64) ([][]int64, bool) {
+	indegree := make(map[int64]int, len(graph))
+	dependents := make(map[int64][]int64, len(graph))
+	for node, deps := range graph {
+		indegree[node] += 0
+		for _, dep := range deps {
+			if _, ok := graph[dep]; !ok {
+				continue
+			}
+			indegree[node]++
+			dependents[dep] = append(dependents[dep], node)
+		}
+	}
+
+	var levels [][]int64
+	var current []int64
+	for node, degree := range indegree {
+		if degree == 0 {
+			current = append(current, node)
+		}
+	}
+	visited := 0
+	for len(current) > 0 {
+		sort.Slice(current, func(i, j int) bool { return current[i] < current[j] })
+		levels = append(levels, current)
+		visited += len(current)
+
+		var next []int64
+		for _, node := range current {
+			for _, dependent := range dependents[node] {
+				indegree[dependent]--
+				if indegree[dependent] == 0 {
+					next = append(next, dependent)
+				}
+			}
+		}
+		current = next
+	}
+	return levels, visited == len(indegree)
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"devops-cd/internal/core/deployment"
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	pkgErrors "devops-cd/pkg/responses"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	defaultDeploymentLogTailLines int64 = 200
+	deploymentObserveTimeout            = 30 * time.Second
+)
+
+// DeploymentScope 查询 Deployment 所属项目与环境（用于项目/生产环境权限校验）
+func (s *BatchService) DeploymentScope(deploymentID int64) (projectID int64, env string, err error) {
+	var scope struct {
+		ProjectID int64
+		Env       string
+	}
+	res := s.db.Model(&model.Deployment{}).
+		Select("applications.project_id", "deployments.env").
+		Joins("JOIN applications ON applications.id = deployments.app_id").
+		Where("deployments.id = ?", deploymentID).
+		Limit(1).Scan(&scope)
+	if res.Error != nil {
+		return 0, "", pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询 Deployment 失败", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return 0, "", pkgErrors.New(pkgErrors.CodeNotFound, "Deployment 不存在")
+	}
+	return scope.ProjectID, scope.Env, nil
+}
+
+// GetDeploymentLogs 读取部署 workload 下各 pod/容器的日志
+func (s *BatchService) GetDeploymentLogs(ctx context.Context, deploymentID int64, query *dto.DeploymentLogsQuery) (*dto.DeploymentLogsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, deploymentObserveTimeout)
+	defer cancel()
+
+	w, err := deployment.LoadWorkloads(ctx, s.db, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := deploymentPods(ctx, w, query.Pod)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.DeploymentLogsResponse{
+		DeploymentID: w.Deployment.ID,
+		ClusterName:  w.Deployment.ClusterName,
+		Namespace:    w.Deployment.Namespace,
+		Workloads:    toDeploymentWorkloads(w),
+		Pods:         make([]dto.DeploymentPodLogs, 0, len(pods)),
+	}
+	for i := range pods {
+		pod := &pods[i]
+		item := dto.DeploymentPodLogs{Pod: pod.Name, Phase: string(pod.Status.Phase)}
+		for _, container := range podContainers(pod, query.Container) {
+			logs, err := w.PodLogs(ctx, pod, podLogOptions(query, container))
+			entry := dto.DeploymentContainerLogs{Container: container, Logs: logs}
+			if err != nil {
+				entry.Error = err.Error()
+			}
+			item.Containers = append(item.Containers, entry)
+		}
+		resp.Pods = append(resp.Pods, item)
+	}
+	return resp, nil
+}
+
+// StreamDeploymentLogs 持续推送部署 workload 的容器日志，直到 ctx 取消或所有容器日志流结束
+// 各容器日志流并发读取，fn 串行调用
+func (s *BatchService) StreamDeploymentLogs(ctx context.Context, deploymentID int64, query *dto.DeploymentLogsQuery, fn func(line *dto.DeploymentLogLine)) error {
+	loadCtx, cancel := context.WithTimeout(ctx, deploymentObserveTimeout)
+	w, err := deployment.LoadWorkloads(loadCtx, s.db, deploymentID)
+	if err != nil {
+		cancel()
+		return err
+	}
+	pods, err := deploymentPods(loadCtx, w, query.Pod)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	for i := range pods {
+		pod := &pods[i]
+		for _, container := range podContainers(pod, query.Container) {
+			opts := podLogOptions(query, container)
+			opts.Follow = true
+			wg.Add(1)
+			go func(container string) {
+				defer wg.Done()
+				err := w.FollowPodLogs(ctx, pod, opts, func(line string) {
+					mu.Lock()
+					defer mu.Unlock()
+					fn(&dto.DeploymentLogLine{Pod: pod.Name, Container: container, Line: line})
+				})
+				if err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("读取 %s/%s 日志失败: %w", pod.Name, container, err)
+					}
+					mu.Unlock()
+				}
+			}(container)
+		}
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// GetDeploymentEvents 查询部署 workload 相关的 Kubernetes 事件
+func (s *BatchService) GetDeploymentEvents(ctx context.Context, deploymentID int64) (*dto.DeploymentEventsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, deploymentObserveTimeout)
+	defer cancel()
+
+	w, err := deployment.LoadWorkloads(ctx, s.db, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	events, err := w.Events(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.DeploymentEventsResponse{
+		DeploymentID: w.Deployment.ID,
+		ClusterName:  w.Deployment.ClusterName,
+		Namespace:    w.Deployment.Namespace,
+		Workloads:    toDeploymentWorkloads(w),
+		Events:       make([]dto.DeploymentEvent, 0, len(events)),
+	}
+	for i := range events {
+		resp.Events = append(resp.Events, toDeploymentEvent(&events[i]))
+	}
+	return resp, nil
+}
+
+// StreamDeploymentEvents 持续推送部署 workload 相关的 Kubernetes 事件（先推送已有事件），直到 ctx 取消
+func (s *BatchService) StreamDeploymentEvents(ctx context.Context, deploymentID int64, fn func(event *dto.DeploymentEvent)) error {
+	loadCtx, cancel := context.WithTimeout(ctx, deploymentObserveTimeout)
+	w, err := deployment.LoadWorkloads(loadCtx, s.db, deploymentID)
+	cancel()
+	if err != nil {
+		return err
+	}
+	return w.WatchEvents(ctx, func(event *corev1.Event) {
+		e := toDeploymentEvent(event)
+		fn(&e)
+	})
+}
+
+func deploymentPods(ctx context.Context, w *deployment.Workloads, podName string) ([]corev1.Pod, error) {
+	pods, err := w.Pods(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if podName == "" {
+		return pods, nil
+	}
+	for _, pod := range pods {
+		if pod.Name == podName {
+			return []corev1.Pod{pod}, nil
+		}
+	}
+	return nil, fmt.Errorf("pod %s 不属于该 deployment", podName)
+}
+
+// podContainers pod 的容器列表（含 init 容器），指定 container 时仅返回该容器
+func podContainers(pod *corev1.Pod, container string) []string {
+	var names []string
+	for _, c := range pod.Spec.InitContainers {
+		names = append(names, c.Name)
+	}
+	for _, c := range pod.Spec.Containers {
+		names = append(names, c.Name)
+	}
+	if container == "" {
+		return names
+	}
+	for _, name := range names {
+		if name == container {
+			return []string{name}
+		}
+	}
+	return nil
+}
+
+func podLogOptions(query *dto.DeploymentLogsQuery, container string) *corev1.PodLogOptions {
+	tailLines := query.TailLines
+	if tailLines <= 0 {
+		tailLines = defaultDeploymentLogTailLines
+	}
+	opts := &corev1.PodLogOptions{
+		Container: container,
+		TailLines: &tailLines,
+		Previous:  query.Previous,
+	}
+	if query.SinceSeconds > 0 {
+		opts.SinceSeconds = &query.SinceSeconds
+	}
+	return opts
+}
+
+func toDeploymentWorkloads(w *deployment.Workloads) []dto.DeploymentWorkload {
+	items := make([]dto.DeploymentWorkload, 0, len(w.Refs))
+	for _, ref := range w.Refs {
+		items = append(items, dto.DeploymentWorkload{Kind: ref.Kind, Namespace: ref.Namespace, Name: ref.Name})
+	}
+	return items
+}
+
+func toDeploymentEvent(event *corev1.Event) dto.DeploymentEvent {
+	return dto.DeploymentEvent{
+		Type:      event.Type,
+		Reason:    event.Reason,
+		Message:   event.Message,
+		Kind:      event.InvolvedObject.Kind,
+		Namespace: event.InvolvedObject.Namespace,
+		Name:      event.InvolvedObject.Name,
+		Count:     event.Count,
+		Time:      deployment.EventTime(event).Format(time.RFC3339),
+	}
+}
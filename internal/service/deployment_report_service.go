@@ -0,0 +1,335 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/database"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+)
+
+// 部署统计报表分组维度
+const (
+	DeploymentReportGroupProject = "project"
+	DeploymentReportGroupTeam    = "team"
+	DeploymentReportGroupApp     = "app"
+)
+
+// DeploymentReport 按项目/团队/应用汇总 DORA 部署指标（部署频率、交付时长、成功率、MTTR）
+//
+// 统计口径: 生产环境 app Deployment，finished_at 落在 [start, end] 内；
+// 交付时长为批次封板到应用在该批次最后一个生产部署成功的时长，MTTR 为同一应用同一集群部署失败到之后第一次成功的时长
+func (s *BatchService) DeploymentReport(query *dto.DeploymentReportQuery, canView func(projectID int64) bool) (*dto.DeploymentReportResponse, error) {
+	// 报表查询读副本
+	db := database.ReadReplica(s.db)
+
+	end := time.Now()
+	if query.End != nil {
+		end = *query.End
+	}
+	end = truncateDay(end).AddDate(0, 0, 1) // 含结束当天
+	start := end.AddDate(0, 0, -qualityDefaultDays)
+	if query.Start != nil {
+		start = truncateDay(*query.Start)
+	}
+	if !start.Before(end) {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "start 必须早于 end")
+	}
+	groupBy := query.GroupBy
+	if groupBy == "" {
+		groupBy = DeploymentReportGroupApp
+	}
+
+	// 1. 项目与应用范围
+	projectID := query.ProjectID
+	if query.AppID != nil {
+		var app model.Application
+		if err := db.Select("id", "project_id").First(&app, *query.AppID).Error; err != nil {
+			return nil, pkgErrors.New(pkgErrors.CodeNotFound, "应用不存在")
+		}
+		if projectID != nil && *projectID != app.ProjectID {
+			return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "应用不属于该项目")
+		}
+		projectID = &app.ProjectID
+	}
+	if query.TeamID != nil {
+		var team model.Team
+		if err := db.Select("id", "project_id").First(&team, *query.TeamID).Error; err != nil {
+			return nil, pkgErrors.New(pkgErrors.CodeNotFound, "团队不存在")
+		}
+		if projectID != nil && *projectID != team.ProjectID {
+			return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "团队不属于该项目")
+		}
+		projectID = &team.ProjectID
+	}
+	if projectID != nil && !canView(*projectID) {
+		return nil, pkgErrors.ErrForbidden
+	}
+
+	projectQuery := db.Unscoped().Select("id", "name")
+	if projectID != nil {
+		projectQuery = projectQuery.Where("id = ?", *projectID)
+	}
+	var allProjects []*model.Project
+	if err := projectQuery.Find(&allProjects).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询项目失败", err)
+	}
+	projectNames := make(map[int64]string, len(allProjects))
+	projectIDs := make([]int64, 0, len(allProjects))
+	for _, project := range allProjects {
+		if projectID == nil && !canView(project.ID) {
+			continue
+		}
+		projectNames[project.ID] = project.Name
+		projectIDs = append(projectIDs, project.ID)
+	}
+
+	days := int(end.Sub(start).Hours() / 24)
+	resp := &dto.DeploymentReportResponse{
+		Start:   start.Format("2006-01-02"),
+		End:     end.AddDate(0, 0, -1).Format("2006-01-02"),
+		Days:    days,
+		GroupBy: groupBy,
+		Items:   []*dto.DeploymentReportItem{},
+	}
+	if len(projectIDs) == 0 {
+		resp.Summary = newDeploymentAccumulator().stats(days)
+		return resp, nil
+	}
+
+	// 已删除的应用保留历史统计
+	appQuery := db.Unscoped().Select("id", "name", "project_id", "team_id").Where("project_id IN ?", projectIDs)
+	if query.TeamID != nil {
+		appQuery = appQuery.Where("team_id = ?", *query.TeamID)
+	}
+	if query.AppID != nil {
+		appQuery = appQuery.Where("id = ?", *query.AppID)
+	}
+	var apps []*model.Application
+	if err := appQuery.Find(&apps).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用失败", err)
+	}
+	if len(apps) == 0 {
+		resp.Summary = newDeploymentAccumulator().stats(days)
+		return resp, nil
+	}
+	appByID := make(map[int64]*model.Application, len(apps))
+	appIDs := make([]int64, 0, len(apps))
+	teamIDs := make([]int64, 0)
+	for _, app := range apps {
+		appByID[app.ID] = app
+		appIDs = append(appIDs, app.ID)
+		if app.TeamID != nil {
+			teamIDs = append(teamIDs, *app.TeamID)
+		}
+	}
+	teamNames := make(map[int64]string)
+	if teamIDs = uniqueInt64s(teamIDs); len(teamIDs) > 0 {
+		var teams []*model.Team
+		if err := db.Unscoped().Select("id", "name").Where("id IN ?", teamIDs).Find(&teams).Error; err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询团队失败", err)
+		}
+		for _, team := range teams {
+			teamNames[team.ID] = team.Name
+		}
+	}
+
+	// 2. 区间内结束的生产部署
+	var deployments []*model.Deployment
+	if err := db.Select("id", "batch_id", "app_id", "release_id", "cluster", "status", "rollback_build_id", "finished_at").
+		Where("env = ? AND kind = ? AND app_id IN ?", constants.EnvTypeProd, constants.DeploymentKindApp, appIDs).
+		Where("status IN ? AND finished_at >= ? AND finished_at < ?", deploymentReportFinished, start, end).
+		Order("finished_at").Find(&deployments).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询部署记录失败", err)
+	}
+
+	batchIDs := make([]int64, 0)
+	for _, dep := range deployments {
+		if dep.Status == constants.DeploymentStatusSuccess && dep.RollbackBuildID == nil {
+			batchIDs = append(batchIDs, dep.BatchID)
+		}
+	}
+	sealedAt := make(map[int64]time.Time)
+	if batchIDs = uniqueInt64s(batchIDs); len(batchIDs) > 0 {
+		var batches []*model.Batch
+		if err := db.Select("id", "sealed_at").Where("id IN ?", batchIDs).Find(&batches).Error; err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询批次失败", err)
+		}
+		for _, batch := range batches {
+			if batch.SealedAt != nil {
+				sealedAt[batch.ID] = *batch.SealedAt
+			}
+		}
+	}
+
+	// 3. 汇总
+	summary := newDeploymentAccumulator()
+	accs := make(map[int64]*deploymentAccumulator)
+	items := make(map[int64]*dto.DeploymentReportItem)
+	accsFor := func(app *model.Application) []*deploymentAccumulator {
+		key := app.ID
+		switch groupBy {
+		case DeploymentReportGroupProject:
+			key = app.ProjectID
+		case DeploymentReportGroupTeam:
+			key = 0 // 未设置团队的应用
+			if app.TeamID != nil {
+				key = *app.TeamID
+			}
+		}
+		acc, ok := accs[key]
+		if !ok {
+			acc = newDeploymentAccumulator()
+			accs[key] = acc
+			item := &dto.DeploymentReportItem{ProjectID: app.ProjectID, ProjectName: projectNames[app.ProjectID]}
+			switch groupBy {
+			case DeploymentReportGroupTeam:
+				if app.TeamID != nil {
+					item.TeamID = app.TeamID
+					item.TeamName = teamNames[*app.TeamID]
+				}
+			case DeploymentReportGroupApp:
+				item.TeamID = app.TeamID
+				if app.TeamID != nil {
+					item.TeamName = teamNames[*app.TeamID]
+				}
+				item.AppID = app.ID
+				item.AppName = app.Name
+			}
+			items[key] = item
+		}
+		return []*deploymentAccumulator{summary, acc}
+	}
+
+	// 交付时长：(批次, 应用) 最后一个成功的生产部署
+	leadFinished := make(map[[2]int64]time.Time)
+	// MTTR：(应用, 集群) 当前未恢复的首次失败时间
+	type target struct {
+		appID   int64
+		cluster string
+	}
+	failingSince := make(map[target]time.Time)
+	for _, dep := range deployments {
+		app := appByID[dep.AppID]
+		finishedAt := *dep.FinishedAt
+		t := target{dep.AppID, dep.ClusterName}
+		succeeded := dep.Status == constants.DeploymentStatusSuccess
+
+		var recovery *time.Duration
+		if succeeded {
+			if since, ok := failingSince[t]; ok {
+				d := finishedAt.Sub(since)
+				recovery = &d
+				delete(failingSince, t)
+			}
+			if _, ok := sealedAt[dep.BatchID]; ok && dep.RollbackBuildID == nil {
+				key := [2]int64{dep.BatchID, dep.AppID}
+				if finishedAt.After(leadFinished[key]) {
+					leadFinished[key] = finishedAt
+				}
+			}
+		} else if _, ok := failingSince[t]; !ok {
+			failingSince[t] = finishedAt
+		}
+
+		for _, acc := range accsFor(app) {
+			acc.addDeployment(succeeded, finishedAt)
+			if recovery != nil {
+				acc.recoveryMinutes = append(acc.recoveryMinutes, recovery.Minutes())
+			}
+		}
+	}
+	for key, finishedAt := range leadFinished {
+		lead := finishedAt.Sub(sealedAt[key[0]])
+		if lead < 0 {
+			continue
+		}
+		for _, acc := range accsFor(appByID[key[1]]) {
+			acc.leadMinutes = append(acc.leadMinutes, lead.Minutes())
+		}
+	}
+
+	resp.Summary = summary.stats(days)
+	for key, item := range items {
+		item.DeploymentStats = accs[key].stats(days)
+		resp.Items = append(resp.Items, item)
+	}
+	sort.Slice(resp.Items, func(i, j int) bool {
+		a, b := resp.Items[i], resp.Items[j]
+		if a.ProjectID != b.ProjectID {
+			return a.ProjectID < b.ProjectID
+		}
+		if a.TeamName != b.TeamName {
+			return a.TeamName < b.TeamName
+		}
+		return a.AppID < b.AppID
+	})
+	return resp, nil
+}
+
+// deploymentReportFinished 已结束的部署状态
+var deploymentReportFinished = []string{
+	constants.DeploymentStatusSuccess,
+	constants.DeploymentStatusFailed,
+	constants.DeploymentStatusVerifyFailed,
+}
+
+// deploymentAccumulator 部署指标累加器
+type deploymentAccumulator struct {
+	successful, failed int
+	activeDays         map[string]bool
+	leadMinutes        []float64
+	recoveryMinutes    []float64
+}
+
+func newDeploymentAccumulator() *deploymentAccumulator {
+	return &deploymentAccumulator{activeDays: make(map[string]bool)}
+}
+
+func (a *deploymentAccumulator) addDeployment(succeeded bool, finishedAt time.Time) {
+	if !succeeded {
+		a.failed++
+		return
+	}
+	a.successful++
+	a.activeDays[finishedAt.Format("2006-01-02")] = true
+}
+
+func (a *deploymentAccumulator) stats(days int) dto.DeploymentStats {
+	stats := dto.DeploymentStats{
+		Deployments: a.successful + a.failed,
+		Successful:  a.successful,
+		Failed:      a.failed,
+		ActiveDays:  len(a.activeDays),
+		LeadTimes:   len(a.leadMinutes),
+		Recoveries:  len(a.recoveryMinutes),
+	}
+	if stats.Deployments > 0 {
+		stats.SuccessRate = float64(a.successful) / float64(stats.Deployments)
+	}
+	if days > 0 {
+		stats.DeploymentFrequency = float64(a.successful) / float64(days)
+	}
+	if len(a.leadMinutes) > 0 {
+		sorted := append([]float64(nil), a.leadMinutes...)
+		sort.Float64s(sorted)
+		stats.LeadTimeAvgMinutes = average(sorted)
+		stats.LeadTimeP50Minutes = percentile(sorted, 0.5)
+		stats.LeadTimeP90Minutes = percentile(sorted, 0.9)
+	}
+	if len(a.recoveryMinutes) > 0 {
+		stats.MTTRMinutes = average(a.recoveryMinutes)
+	}
+	return stats
+}
+
+func average(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
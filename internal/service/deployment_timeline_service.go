@@ -0,0 +1,44 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// GetDeploymentTimeline 查询 Deployment 执行时间线（状态变更、chart 渲染、开始部署、就绪检查原因变化）
+func (s *BatchService) GetDeploymentTimeline(deploymentID int64) (*dto.DeploymentTimelineResponse, error) {
+	var dep model.Deployment
+	if err := s.db.First(&dep, deploymentID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("deployment 不存在")
+		}
+		return nil, err
+	}
+
+	var events []model.DeploymentEvent
+	if err := s.db.Where("deployment_id = ?", deploymentID).Order("id ASC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	resp := &dto.DeploymentTimelineResponse{
+		Deployment: toDeploymentResponse(&dep),
+		Events:     make([]dto.DeploymentTimelineEvent, 0, len(events)),
+	}
+	for _, e := range events {
+		resp.Events = append(resp.Events, dto.DeploymentTimelineEvent{
+			ID:         e.ID,
+			Type:       e.Type,
+			FromStatus: e.FromStatus,
+			ToStatus:   e.ToStatus,
+			Operator:   e.Operator,
+			Message:    e.Message,
+			CreatedAt:  e.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return resp, nil
+}
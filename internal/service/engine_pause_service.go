@@ -0,0 +1,128 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/internal/repository"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type EnginePauseService interface {
+	// Pause 暂停项目/批次/集群的引擎处理（同一范围同时只允许一条生效记录）
+	Pause(req *dto.CreateEnginePauseRequest, operator string) (*dto.EnginePauseResponse, error)
+	// Resume 恢复处理
+	Resume(id int64, operator string) (*dto.EnginePauseResponse, error)
+	List(req *dto.EnginePauseListRequest) ([]*dto.EnginePauseResponse, int64, error)
+}
+
+type enginePauseService struct {
+	db   *gorm.DB
+	repo *repository.EnginePauseRepository
+}
+
+func NewEnginePauseService(db *gorm.DB, repo *repository.EnginePauseRepository) EnginePauseService {
+	return &enginePauseService{db: db, repo: repo}
+}
+
+func (s *enginePauseService) Pause(req *dto.CreateEnginePauseRequest, operator string) (*dto.EnginePauseResponse, error) {
+	var target interface{}
+	switch req.Scope {
+	case constants.EnginePauseScopeProject:
+		target = &model.Project{}
+	case constants.EnginePauseScopeBatch:
+		target = &model.Batch{}
+	case constants.EnginePauseScopeCluster:
+		target = &model.Cluster{}
+	default:
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("不支持的暂停范围: %s", req.Scope))
+	}
+	var count int64
+	if err := s.db.Model(target).Where("id = ?", req.ScopeID).Count(&count).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询暂停对象失败", err)
+	}
+	if count == 0 {
+		return nil, pkgErrors.New(pkgErrors.CodeNotFound, fmt.Sprintf("%s %d 不存在", req.Scope, req.ScopeID))
+	}
+
+	existing, err := s.repo.FindActive(req.Scope, req.ScopeID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("%s %d 已处于暂停状态（暂停记录 %d）", req.Scope, req.ScopeID, existing.ID))
+	}
+
+	p := &model.EnginePause{
+		Scope:    req.Scope,
+		ScopeID:  req.ScopeID,
+		Reason:   req.Reason,
+		PausedBy: operator,
+	}
+	if err := s.repo.Create(p); err != nil {
+		return nil, err
+	}
+	logger.Warn("暂停核心引擎处理",
+		zap.String("scope", p.Scope),
+		zap.Int64("scope_id", p.ScopeID),
+		zap.String("operator", operator),
+		zap.String("reason", p.Reason))
+	return toEnginePauseResponse(p), nil
+}
+
+func (s *enginePauseService) Resume(id int64, operator string) (*dto.EnginePauseResponse, error) {
+	p, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if p.ResumedAt != nil {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "该暂停记录已恢复")
+	}
+	now := time.Now()
+	p.ResumedAt = &now
+	p.ResumedBy = &operator
+	if err := s.repo.Update(p); err != nil {
+		return nil, err
+	}
+	logger.Info("恢复核心引擎处理",
+		zap.String("scope", p.Scope),
+		zap.Int64("scope_id", p.ScopeID),
+		zap.String("operator", operator))
+	return toEnginePauseResponse(p), nil
+}
+
+func (s *enginePauseService) List(req *dto.EnginePauseListRequest) ([]*dto.EnginePauseResponse, int64, error) {
+	list, total, err := s.repo.List(req.ActiveOnly, req.Page, req.PageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	out := make([]*dto.EnginePauseResponse, 0, len(list))
+	for _, p := range list {
+		out = append(out, toEnginePauseResponse(p))
+	}
+	return out, total, nil
+}
+
+func toEnginePauseResponse(p *model.EnginePause) *dto.EnginePauseResponse {
+	if p == nil {
+		return nil
+	}
+	return &dto.EnginePauseResponse{
+		ID:        p.ID,
+		Scope:     p.Scope,
+		ScopeID:   p.ScopeID,
+		Reason:    p.Reason,
+		Active:    p.ResumedAt == nil,
+		PausedBy:  p.PausedBy,
+		PausedAt:  p.CreatedAt.Format(time.RFC3339),
+		ResumedBy: p.ResumedBy,
+		ResumedAt: dto.FormatTime(p.ResumedAt),
+	}
+}
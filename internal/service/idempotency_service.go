@@ -0,0 +1,101 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"devops-cd/internal/model"
+	pkgErrors "devops-cd/pkg/responses"
+)
+
+// DefaultIdempotencyTTL 幂等记录默认保留时间
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// idempotencyProcessingTimeout 处理中的记录超过该时间视为请求已中断（进程重启等），允许重新占用
+const idempotencyProcessingTimeout = 10 * time.Minute
+
+// IdempotencyService 写接口幂等记录存储（idempotency_keys），供 middleware.Idempotency 使用
+type IdempotencyService struct {
+	db  *gorm.DB
+	ttl time.Duration
+}
+
+// NewIdempotencyService ttl <= 0 时使用 DefaultIdempotencyTTL
+func NewIdempotencyService(db *gorm.DB, ttl time.Duration) *IdempotencyService {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	return &IdempotencyService{db: db, ttl: ttl}
+}
+
+// Acquire 占用幂等键：占用成功返回新记录与 true；键已被占用时返回已有记录与 false
+// 已过期或处理超时的记录会被删除后重新占用
+func (s *IdempotencyService) Acquire(username, key, method, path, fingerprint string) (*model.IdempotencyKey, bool, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		rec := &model.IdempotencyKey{
+			Username:    username,
+			Key:         key,
+			Method:      method,
+			Path:        path,
+			Fingerprint: fingerprint,
+			Status:      model.IdempotencyStatusProcessing,
+			ExpiresAt:   time.Now().Add(s.ttl),
+		}
+		err := s.db.Create(rec).Error
+		if err == nil {
+			return rec, true, nil
+		}
+		if !errors.Is(err, gorm.ErrDuplicatedKey) && !strings.Contains(err.Error(), "Duplicate entry") {
+			return nil, false, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "保存幂等记录失败", err)
+		}
+
+		var existing model.IdempotencyKey
+		if err := s.db.Where("username = ? AND idem_key = ?", username, key).First(&existing).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue // 并发删除，重新占用
+			}
+			return nil, false, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询幂等记录失败", err)
+		}
+		if !s.stale(&existing) {
+			return &existing, false, nil
+		}
+		// 按 updated_at 条件删除，避免删掉并发请求刚刚重新占用的记录
+		if err := s.db.Where("id = ? AND updated_at = ?", existing.ID, existing.UpdatedAt).
+			Delete(&model.IdempotencyKey{}).Error; err != nil {
+			return nil, false, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "删除过期幂等记录失败", err)
+		}
+	}
+	return nil, false, pkgErrors.New(pkgErrors.CodeConflict, "相同 Idempotency-Key 的请求正在处理中")
+}
+
+// Complete 保存成功响应，之后相同键的请求直接返回该响应
+func (s *IdempotencyService) Complete(id int64, statusCode int, contentType string, body []byte) error {
+	return s.db.Model(&model.IdempotencyKey{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":        model.IdempotencyStatusCompleted,
+		"status_code":   statusCode,
+		"content_type":  contentType,
+		"response_body": body,
+	}).Error
+}
+
+// Release 删除记录（请求失败），客户端可使用同一个键重试
+func (s *IdempotencyService) Release(id int64) error {
+	return s.db.Delete(&model.IdempotencyKey{}, id).Error
+}
+
+// PurgeExpired 清理过期记录，返回删除条数
+func (s *IdempotencyService) PurgeExpired() (int64, error) {
+	result := s.db.Where("expires_at < ?", time.Now()).Delete(&model.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
+
+func (s *IdempotencyService) stale(rec *model.IdempotencyKey) bool {
+	now := time.Now()
+	if now.After(rec.ExpiresAt) {
+		return true
+	}
+	return rec.Status == model.IdempotencyStatusProcessing && now.Sub(rec.UpdatedAt) > idempotencyProcessingTimeout
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"time"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// ImageScanService 镜像漏洞扫描结果（项目漏洞门禁的数据来源）
+type ImageScanService interface {
+	// Record 记录扫描结果，同一镜像 tag 覆盖为最近一次
+	Record(source string, reports []dto.ImageScanReport) (*dto.ImageScanResult, error)
+	List(query *dto.ImageScanListQuery) ([]*dto.ImageScanResponse, int64, error)
+}
+
+type imageScanService struct {
+	repo      *repository.ImageScanRepository
+	buildRepo repository.BuildRepository
+}
+
+func NewImageScanService(repo *repository.ImageScanRepository, buildRepo repository.BuildRepository) ImageScanService {
+	return &imageScanService{repo: repo, buildRepo: buildRepo}
+}
+
+func (s *imageScanService) Record(source string, reports []dto.ImageScanReport) (*dto.ImageScanResult, error) {
+	result := &dto.ImageScanResult{Recorded: []string{}}
+	for _, report := range reports {
+		scan := &model.ImageScan{
+			Image:     report.Image,
+			Tag:       report.Tag,
+			Source:    source,
+			Scanner:   report.Scanner,
+			Status:    report.Status,
+			Critical:  report.Critical,
+			High:      report.High,
+			Medium:    report.Medium,
+			Low:       report.Low,
+			Unknown:   report.Unknown,
+			ScannedAt: report.ScannedAt,
+		}
+		if report.Digest != "" {
+			digest := report.Digest
+			scan.Digest = &digest
+		}
+		if scan.ScannedAt.IsZero() {
+			scan.ScannedAt = time.Now()
+		}
+		if err := s.repo.Upsert(scan); err != nil {
+			return result, err
+		}
+		ref := report.Image + ":" + report.Tag
+		result.Recorded = append(result.Recorded, ref)
+		logger.Info("记录镜像扫描结果", zap.String("source", source), zap.String("image", ref), zap.String("status", report.Status),
+			zap.Int("critical", report.Critical), zap.Int("high", report.High))
+	}
+	return result, nil
+}
+
+func (s *imageScanService) List(query *dto.ImageScanListQuery) ([]*dto.ImageScanResponse, int64, error) {
+	image, tag := query.Image, query.Tag
+	if query.BuildID != nil {
+		build, err := s.buildRepo.FindByID(*query.BuildID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if image, tag = build.ScanImage(); image == "" {
+			return []*dto.ImageScanResponse{}, 0, nil
+		}
+	}
+
+	list, total, err := s.repo.List(image, tag, query.GetPage(), query.GetPageSize())
+	if err != nil {
+		return nil, 0, err
+	}
+	items := make([]*dto.ImageScanResponse, 0, len(list))
+	for _, scan := range list {
+		items = append(items, &dto.ImageScanResponse{
+			ID:        scan.ID,
+			Image:     scan.Image,
+			Tag:       scan.Tag,
+			Digest:    scan.Digest,
+			Source:    scan.Source,
+			Scanner:   scan.Scanner,
+			Status:    scan.Status,
+			Critical:  scan.Critical,
+			High:      scan.High,
+			Medium:    scan.Medium,
+			Low:       scan.Low,
+			Unknown:   scan.Unknown,
+			ScannedAt: scan.ScannedAt.Format(time.RFC3339),
+			UpdatedAt: scan.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+	return items, total, nil
+}
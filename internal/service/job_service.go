@@ -0,0 +1,151 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// JobNotifier 唤醒任务队列 worker（core.CoreEngine.Jobs()）
+type JobNotifier interface {
+	Notify()
+}
+
+// JobService 异步任务管理：查看任务、将死信任务重新入队
+type JobService struct {
+	db       *gorm.DB
+	notifier JobNotifier
+}
+
+func NewJobService(db *gorm.DB) *JobService {
+	return &JobService{db: db}
+}
+
+// SetNotifier 设置重新入队后唤醒 worker 的通知器（任务队列未启用时不设置）
+func (s *JobService) SetNotifier(n JobNotifier) {
+	s.notifier = n
+}
+
+func (s *JobService) List(req *dto.JobListRequest) ([]*dto.JobResponse, int64, error) {
+	query := s.db.Model(&model.Job{})
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+	if req.Type != "" {
+		query = query.Where("type = ?", req.Type)
+	}
+	if req.BatchID > 0 {
+		query = query.Where("batch_id = ?", req.BatchID)
+	}
+	if req.DeploymentID > 0 {
+		query = query.Where("deployment_id = ?", req.DeploymentID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询任务总数失败", err)
+	}
+	var list []*model.Job
+	if err := query.Order("id DESC").Offset((req.Page - 1) * req.PageSize).Limit(req.PageSize).Find(&list).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询任务列表失败", err)
+	}
+	out := make([]*dto.JobResponse, 0, len(list))
+	for _, job := range list {
+		out = append(out, toJobResponse(job))
+	}
+	return out, total, nil
+}
+
+func (s *JobService) Get(id int64) (*dto.JobResponse, error) {
+	job, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	return toJobResponse(job), nil
+}
+
+// Retry 将死信任务重新入队（重置执行次数）；同一去重键已有未结束的任务时拒绝
+func (s *JobService) Retry(id int64, operator string) (*dto.JobResponse, error) {
+	job, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != constants.JobStatusDead {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("仅死信任务可重新入队，当前状态: %s", job.Status))
+	}
+
+	updates := map[string]interface{}{
+		"status":       constants.JobStatusPending,
+		"attempts":     0,
+		"run_at":       time.Now(),
+		"locked_by":    "",
+		"locked_until": nil,
+		"finished_at":  nil,
+	}
+	if job.DedupKey != "" {
+		updates["active_key"] = job.DedupKey
+	}
+	result := s.db.Model(&model.Job{}).Where("id = ? AND status = ?", job.ID, constants.JobStatusDead).Updates(updates)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) || strings.Contains(result.Error.Error(), "Duplicate entry") {
+			return nil, pkgErrors.New(pkgErrors.CodeConflict, fmt.Sprintf("去重键 %s 已有未结束的任务", job.DedupKey))
+		}
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "重新入队失败", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, pkgErrors.New(pkgErrors.CodeConflict, "任务状态已变化，请刷新后重试")
+	}
+	if s.notifier != nil {
+		s.notifier.Notify()
+	}
+
+	logger.Info("死信任务重新入队",
+		zap.Int64("job_id", job.ID),
+		zap.String("type", job.Type),
+		zap.String("operator", operator))
+	return s.Get(job.ID)
+}
+
+func (s *JobService) find(id int64) (*model.Job, error) {
+	var job model.Job
+	if err := s.db.First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgErrors.New(pkgErrors.CodeNotFound, fmt.Sprintf("任务 %d 不存在", id))
+		}
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询任务失败", err)
+	}
+	return &job, nil
+}
+
+func toJobResponse(job *model.Job) *dto.JobResponse {
+	return &dto.JobResponse{
+		ID:           job.ID,
+		Type:         job.Type,
+		DedupKey:     job.DedupKey,
+		Payload:      json.RawMessage(job.Payload),
+		Status:       job.Status,
+		Attempts:     job.Attempts,
+		MaxAttempts:  job.MaxAttempts,
+		RunAt:        job.RunAt.Format(time.RFC3339),
+		LockedBy:     job.LockedBy,
+		LockedUntil:  dto.FormatTime(job.LockedUntil),
+		LastError:    job.LastError,
+		BatchID:      job.BatchID,
+		DeploymentID: job.DeploymentID,
+		StartedAt:    dto.FormatTime(job.StartedAt),
+		FinishedAt:   dto.FormatTime(job.FinishedAt),
+		CreatedAt:    job.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    job.UpdatedAt.Format(time.RFC3339),
+	}
+}
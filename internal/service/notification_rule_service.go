@@ -0,0 +1,261 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"devops-cd/internal/adapter/notification"
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/repository"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"github.com/samber/lo"
+)
+
+type NotificationRuleService interface {
+	Create(req *dto.CreateNotificationRuleRequest, operator string) (*dto.NotificationRuleResponse, error)
+	Update(id int64, req *dto.UpdateNotificationRuleRequest, operator string) (*dto.NotificationRuleResponse, error)
+	Delete(id int64) error
+	Get(id int64) (*dto.NotificationRuleResponse, error)
+	List(req *dto.NotificationRuleListRequest) ([]*dto.NotificationRuleResponse, int64, error)
+}
+
+type notificationRuleService struct {
+	repo        *repository.NotificationRuleRepository
+	projectRepo repository.ProjectRepository
+	teamRepo    repository.TeamRepository
+}
+
+func NewNotificationRuleService(repo *repository.NotificationRuleRepository, projectRepo repository.ProjectRepository, teamRepo repository.TeamRepository) NotificationRuleService {
+	return &notificationRuleService{repo: repo, projectRepo: projectRepo, teamRepo: teamRepo}
+}
+
+// NotificationRuleOptions 可选通知类型、严重级别与模板字段
+func NotificationRuleOptions() *dto.NotificationRuleOptionsResponse {
+	return &dto.NotificationRuleOptionsResponse{
+		Events: lo.Map(notification.AllTypes, func(t notification.NotificationType, _ int) string {
+			return string(t)
+		}),
+		Severities: notification.Severities,
+		Fields: []string{
+			"Type", "Severity", "Title", "Content", "Message", "Timestamp", "ProjectID",
+			"BatchID", "BatchNumber", "Initiator", "AppID", "AppName", "TeamName", "Leader", "Extra",
+		},
+	}
+}
+
+func (s *notificationRuleService) Create(req *dto.CreateNotificationRuleRequest, operator string) (*dto.NotificationRuleResponse, error) {
+	rule := &model.NotificationRule{
+		Name:            strings.TrimSpace(req.Name),
+		ProjectID:       req.ProjectID,
+		TeamID:          req.TeamID,
+		Events:          model.StringList(lo.Uniq(req.Events)),
+		MinSeverity:     req.MinSeverity,
+		Webhook:         strings.TrimSpace(req.Webhook),
+		TitleTemplate:   req.TitleTemplate,
+		ContentTemplate: req.ContentTemplate,
+		QuietHours:      normalizeQuietHours(req.QuietHours),
+		MentionLeader:   req.MentionLeader,
+		Enabled:         true,
+		Description:     req.Description,
+		CreatedBy:       operator,
+		UpdatedBy:       operator,
+	}
+	if rule.MinSeverity == "" {
+		rule.MinSeverity = notification.SeverityInfo
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if err := s.check(rule); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(rule); err != nil {
+		return nil, err
+	}
+	return toNotificationRuleResponse(rule), nil
+}
+
+func (s *notificationRuleService) Update(id int64, req *dto.UpdateNotificationRuleRequest, operator string) (*dto.NotificationRuleResponse, error) {
+	rule, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		rule.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.ProjectID != nil {
+		rule.ProjectID = req.ProjectID
+		if *req.ProjectID == 0 {
+			rule.ProjectID = nil
+		}
+	}
+	if req.TeamID != nil {
+		rule.TeamID = req.TeamID
+		if *req.TeamID == 0 {
+			rule.TeamID = nil
+		}
+	}
+	if req.Events != nil {
+		rule.Events = model.StringList(lo.Uniq(req.Events))
+	}
+	if req.MinSeverity != nil {
+		rule.MinSeverity = *req.MinSeverity
+	}
+	if req.Webhook != nil {
+		rule.Webhook = strings.TrimSpace(*req.Webhook)
+	}
+	if req.TitleTemplate != nil {
+		rule.TitleTemplate = *req.TitleTemplate
+	}
+	if req.ContentTemplate != nil {
+		rule.ContentTemplate = *req.ContentTemplate
+	}
+	if req.QuietHours != nil {
+		rule.QuietHours = normalizeQuietHours(req.QuietHours)
+	}
+	if req.MentionLeader != nil {
+		rule.MentionLeader = *req.MentionLeader
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	if req.Description != nil {
+		rule.Description = *req.Description
+	}
+	if err := s.check(rule); err != nil {
+		return nil, err
+	}
+	rule.UpdatedBy = operator
+	if err := s.repo.Update(rule); err != nil {
+		return nil, err
+	}
+	return toNotificationRuleResponse(rule), nil
+}
+
+func (s *notificationRuleService) Delete(id int64) error {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *notificationRuleService) Get(id int64) (*dto.NotificationRuleResponse, error) {
+	rule, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return toNotificationRuleResponse(rule), nil
+}
+
+func (s *notificationRuleService) List(req *dto.NotificationRuleListRequest) ([]*dto.NotificationRuleResponse, int64, error) {
+	list, total, err := s.repo.List(req.ProjectID, req.TeamID, req.Page, req.PageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	return lo.Map(list, func(rule *model.NotificationRule, _ int) *dto.NotificationRuleResponse {
+		return toNotificationRuleResponse(rule)
+	}), total, nil
+}
+
+// check 校验 Webhook、通知类型、模板、免打扰时段与项目/团队
+func (s *notificationRuleService) check(rule *model.NotificationRule) error {
+	if rule.Name == "" {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, "name 不能为空")
+	}
+	u, err := url.Parse(rule.Webhook)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("webhook 必须为 http(s) 地址: %s", rule.Webhook))
+	}
+	if len(rule.Events) == 0 {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, "events 不能为空")
+	}
+	options := NotificationRuleOptions()
+	for _, e := range rule.Events {
+		if e != "*" && !lo.Contains(options.Events, e) {
+			return pkgErrors.New(pkgErrors.CodeBadRequest,
+				fmt.Sprintf("不支持的通知类型: %s（可选: *, %s）", e, strings.Join(options.Events, ", ")))
+		}
+	}
+	if !lo.Contains(notification.Severities, rule.MinSeverity) {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("不支持的严重级别: %s", rule.MinSeverity))
+	}
+	// 使用示例数据试渲染，提前发现字段名错误
+	for _, tpl := range []struct{ field, text string }{
+		{"title_template", rule.TitleTemplate},
+		{"content_template", rule.ContentTemplate},
+	} {
+		if tpl.text == "" {
+			continue
+		}
+		if _, err := notification.RenderTemplate(tpl.text, notification.SampleTemplateData()); err != nil {
+			return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("%s 无效: %v", tpl.field, err))
+		}
+	}
+	if err := rule.QuietHours.Validate(); err != nil {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, err.Error())
+	}
+
+	if rule.ProjectID != nil {
+		if _, err := s.projectRepo.FindByID(*rule.ProjectID); err != nil {
+			if err == pkgErrors.ErrRecordNotFound {
+				return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("项目不存在: %d", *rule.ProjectID))
+			}
+			return err
+		}
+	}
+	if rule.TeamID != nil {
+		team, err := s.teamRepo.FindByID(*rule.TeamID)
+		if err != nil {
+			if err == pkgErrors.ErrRecordNotFound {
+				return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("团队不存在: %d", *rule.TeamID))
+			}
+			return err
+		}
+		if rule.ProjectID != nil && team.ProjectID != *rule.ProjectID {
+			return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("团队 %d 不属于项目 %d", team.ID, *rule.ProjectID))
+		}
+	}
+	return nil
+}
+
+// normalizeQuietHours 去除空白，start/end 均为空表示不设置免打扰
+func normalizeQuietHours(q *model.QuietHours) *model.QuietHours {
+	if q == nil {
+		return nil
+	}
+	out := &model.QuietHours{
+		Start:    strings.TrimSpace(q.Start),
+		End:      strings.TrimSpace(q.End),
+		Timezone: strings.TrimSpace(q.Timezone),
+	}
+	if out.Start == "" && out.End == "" {
+		return nil
+	}
+	return out
+}
+
+func toNotificationRuleResponse(rule *model.NotificationRule) *dto.NotificationRuleResponse {
+	return &dto.NotificationRuleResponse{
+		ID:              rule.ID,
+		Name:            rule.Name,
+		ProjectID:       rule.ProjectID,
+		TeamID:          rule.TeamID,
+		Events:          []string(rule.Events),
+		MinSeverity:     rule.MinSeverity,
+		Webhook:         rule.Webhook,
+		TitleTemplate:   rule.TitleTemplate,
+		ContentTemplate: rule.ContentTemplate,
+		QuietHours:      rule.QuietHours,
+		MentionLeader:   rule.MentionLeader,
+		Enabled:         rule.Enabled,
+		Description:     rule.Description,
+		CreatedBy:       rule.CreatedBy,
+		UpdatedBy:       rule.UpdatedBy,
+		CreatedAt:       rule.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:       rule.UpdatedAt.Format(time.RFC3339),
+	}
+}
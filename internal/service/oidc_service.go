@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/pkg/config"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/internal/pkg/oidc"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+)
+
+// OIDCIdentity OIDC 登录得到的身份
+type OIDCIdentity struct {
+	User   *dto.UserInfo
+	Groups []string // groups claim，用于团队映射
+}
+
+type OIDCService interface {
+	// AuthorizeURL 生成 IdP 授权地址
+	AuthorizeURL(ctx context.Context) (*dto.OIDCAuthorizeResponse, error)
+	// Authenticate 校验 state，用授权码换取并校验 ID Token
+	Authenticate(ctx context.Context, code, state string) (*OIDCIdentity, error)
+}
+
+type oidcService struct {
+	cfg         *config.OIDCConfig
+	stateSecret []byte
+	provider    *oidc.Provider
+}
+
+// NewOIDCService 未启用时返回 nil；stateSecret 使用 JWT secret
+func NewOIDCService(cfg *config.OIDCConfig, stateSecret string) OIDCService {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &oidcService{
+		cfg:         cfg,
+		stateSecret: []byte(stateSecret),
+		provider:    oidc.NewProvider(cfg),
+	}
+}
+
+func (s *oidcService) AuthorizeURL(ctx context.Context) (*dto.OIDCAuthorizeResponse, error) {
+	state, nonce, err := oidc.NewState(s.stateSecret)
+	if err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeInternalError, "生成state失败", err)
+	}
+	authURL, err := s.provider.AuthCodeURL(ctx, state, nonce)
+	if err != nil {
+		logger.Error("OIDC discovery失败", zap.Error(err))
+		return nil, pkgErrors.Wrap(pkgErrors.CodeAuthError, "OIDC服务不可用", err)
+	}
+	return &dto.OIDCAuthorizeResponse{AuthURL: authURL, State: state}, nil
+}
+
+func (s *oidcService) Authenticate(ctx context.Context, code, state string) (*OIDCIdentity, error) {
+	nonce, err := oidc.ParseState(s.stateSecret, state)
+	if err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeAuthError, "无效或已过期的state，请重新登录", err)
+	}
+
+	rawIDToken, err := s.provider.Exchange(ctx, code)
+	if err != nil {
+		logger.Warn("OIDC授权码换取Token失败", zap.Error(err))
+		return nil, pkgErrors.Wrap(pkgErrors.CodeAuthError, "OIDC授权码无效或已使用", err)
+	}
+	claims, err := s.provider.VerifyIDToken(ctx, rawIDToken, nonce)
+	if err != nil {
+		logger.Warn("OIDC ID Token校验失败", zap.Error(err))
+		return nil, pkgErrors.Wrap(pkgErrors.CodeAuthError, "OIDC身份校验失败", err)
+	}
+
+	attrs := s.cfg.Claims
+	username := claims.String(orDefault(attrs.Username, "preferred_username"))
+	if username == "" {
+		return nil, pkgErrors.New(pkgErrors.CodeAuthError, "ID Token缺少用户名claim")
+	}
+	displayName := claims.String(orDefault(attrs.DisplayName, "name"))
+	if displayName == "" {
+		displayName = username
+	}
+
+	return &OIDCIdentity{
+		User: &dto.UserInfo{
+			Username:    username,
+			Email:       claims.String(orDefault(attrs.Email, "email")),
+			DisplayName: displayName,
+			AuthType:    constants.AuthTypeOIDC,
+			UID:         claims.String("sub"),
+			Phone:       claims.String(orDefault(attrs.Phone, "phone_number")),
+		},
+		Groups: claims.Strings(orDefault(attrs.Groups, "groups")),
+	}, nil
+}
+
+func orDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
@@ -53,11 +53,41 @@ func (s *projectService) Create(req *dto.CreateProjectRequest) (*dto.ProjectResp
 		return nil, err
 	}
 
+	approvalPolicy, err := normalizeApprovalPolicy(req.ApprovalPolicy)
+	if err != nil {
+		return nil, err
+	}
+	autoBatchRule, err := normalizeAutoBatchRule(req.AutoBatchRule)
+	if err != nil {
+		return nil, err
+	}
+	vulnPolicy, err := normalizeVulnPolicy(req.VulnPolicy)
+	if err != nil {
+		return nil, err
+	}
+	jiraConfig, err := normalizeJiraConfig(req.JiraConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	// 创建项目
 	project := &model.Project{
-		Name:        req.Name,
-		Description: req.Description,
-		OwnerName:   req.OwnerName,
+		Name:           req.Name,
+		Description:    req.Description,
+		OwnerName:      req.OwnerName,
+		ApprovalPolicy: approvalPolicy,
+		AutoBatchRule:  autoBatchRule,
+		VulnPolicy:     vulnPolicy,
+		JiraConfig:     jiraConfig,
+	}
+	if req.AutoRollback != nil {
+		project.AutoRollback = *req.AutoRollback
+	}
+	if req.ProdTwoPersonRule != nil {
+		project.ProdTwoPersonRule = *req.ProdTwoPersonRule
+	}
+	if req.NotifyDigest != nil {
+		project.NotifyDigest = *req.NotifyDigest
 	}
 
 	if err := s.repo.Create(project); err != nil {
@@ -205,6 +235,43 @@ func (s *projectService) Update(id int64, req *dto.UpdateProjectRequest) (*dto.P
 	if req.OwnerName != nil {
 		project.OwnerName = req.OwnerName
 	}
+	if req.ApprovalPolicy != nil {
+		approvalPolicy, err := normalizeApprovalPolicy(req.ApprovalPolicy)
+		if err != nil {
+			return nil, err
+		}
+		project.ApprovalPolicy = approvalPolicy
+	}
+	if req.AutoBatchRule != nil {
+		autoBatchRule, err := normalizeAutoBatchRule(req.AutoBatchRule)
+		if err != nil {
+			return nil, err
+		}
+		project.AutoBatchRule = autoBatchRule
+	}
+	if req.VulnPolicy != nil {
+		vulnPolicy, err := normalizeVulnPolicy(req.VulnPolicy)
+		if err != nil {
+			return nil, err
+		}
+		project.VulnPolicy = vulnPolicy
+	}
+	if req.JiraConfig != nil {
+		jiraConfig, err := normalizeJiraConfig(req.JiraConfig)
+		if err != nil {
+			return nil, err
+		}
+		project.JiraConfig = jiraConfig
+	}
+	if req.AutoRollback != nil {
+		project.AutoRollback = *req.AutoRollback
+	}
+	if req.ProdTwoPersonRule != nil {
+		project.ProdTwoPersonRule = *req.ProdTwoPersonRule
+	}
+	if req.NotifyDigest != nil {
+		project.NotifyDigest = *req.NotifyDigest
+	}
 
 	// 保存项目基本信息
 	if err := s.repo.Update(project); err != nil {
@@ -249,12 +316,19 @@ func (s *projectService) Delete(id int64) error {
 // toResponse 转换为响应对象
 func (s *projectService) toResponse(project *model.Project) *dto.ProjectResponse {
 	resp := &dto.ProjectResponse{
-		ID:          project.ID,
-		Name:        project.Name,
-		Description: project.Description,
-		OwnerName:   project.OwnerName,
-		CreatedAt:   project.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   project.UpdatedAt.Format(time.RFC3339),
+		ID:                project.ID,
+		Name:              project.Name,
+		Description:       project.Description,
+		OwnerName:         project.OwnerName,
+		ApprovalPolicy:    project.ApprovalPolicy,
+		AutoBatchRule:     project.AutoBatchRule,
+		AutoRollback:      project.AutoRollback,
+		VulnPolicy:        project.VulnPolicy,
+		JiraConfig:        project.JiraConfig,
+		ProdTwoPersonRule: project.ProdTwoPersonRule,
+		NotifyDigest:      project.NotifyDigest,
+		CreatedAt:         project.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:         project.UpdatedAt.Format(time.RFC3339),
 	}
 
 	// 从 project_env_configs 表读取环境配置并转换为 map 格式
@@ -273,6 +347,54 @@ func (s *projectService) toResponse(project *model.Project) *dto.ProjectResponse
 	return resp
 }
 
+// normalizeApprovalPolicy 校验审批策略，未配置阶段时返回 nil（单人审批）
+func normalizeApprovalPolicy(policy *model.ApprovalPolicy) (*model.ApprovalPolicy, error) {
+	if !policy.IsEnabled() {
+		return nil, nil
+	}
+	policy.Normalize()
+	if err := policy.Validate(); err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeBadRequest, err.Error(), nil)
+	}
+	return policy, nil
+}
+
+// normalizeAutoBatchRule 校验自动建批规则，未配置 tag_pattern 时返回 nil（不自动建批）
+func normalizeAutoBatchRule(rule *model.AutoBatchRule) (*model.AutoBatchRule, error) {
+	rule.Normalize()
+	if !rule.IsEnabled() {
+		return nil, nil
+	}
+	if err := rule.Validate(); err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeBadRequest, err.Error(), nil)
+	}
+	return rule, nil
+}
+
+// normalizeVulnPolicy 校验漏洞门禁，未配置阈值且不要求扫描时返回 nil（不拦截）
+func normalizeVulnPolicy(policy *model.VulnPolicy) (*model.VulnPolicy, error) {
+	if !policy.IsEnabled() {
+		return nil, nil
+	}
+	policy.Normalize()
+	if err := policy.Validate(); err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeBadRequest, err.Error(), nil)
+	}
+	return policy, nil
+}
+
+// normalizeJiraConfig 校验 Jira 关联配置，未配置 base_url 时返回 nil（不关联）
+func normalizeJiraConfig(cfg *model.JiraConfig) (*model.JiraConfig, error) {
+	if !cfg.IsEnabled() {
+		return nil, nil
+	}
+	cfg.Normalize()
+	if err := cfg.Validate(); err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeBadRequest, err.Error(), nil)
+	}
+	return cfg, nil
+}
+
 func (s *projectService) toTeamResponse(team *model.Team) *dto.TeamResponse {
 	return &dto.TeamResponse{
 		ID:          team.ID,
@@ -280,6 +402,7 @@ func (s *projectService) toTeamResponse(team *model.Team) *dto.TeamResponse {
 		ProjectID:   team.ProjectID,
 		Description: team.Description,
 		LeaderName:  team.LeaderName,
+		Roles:       []string(team.Roles),
 		CreatedAt:   team.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:   team.UpdatedAt.Format(time.RFC3339),
 	}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"devops-cd/internal/dto"
+	"devops-cd/internal/pkg/crypto"
+	"devops-cd/internal/pkg/logger"
+)
+
+// reencryptRecord 待迁移到当前 crypto.provider 的一条密文
+type reencryptRecord struct {
+	ID         int64
+	Ciphertext string
+	KeyID      string
+	swap       func(ciphertext, keyID string) (bool, error) // CAS 替换，密文已被并发修改时返回 false
+}
+
+// reencrypt 按 id 游标分批解密并用当前 provider 重新信封加密；list 返回 key_id 与目标不一致、id 大于 afterID 的记录
+func reencrypt(kind string, batchSize int, dryRun bool, list func(target string, afterID int64, limit int) ([]reencryptRecord, error)) (*dto.CredentialReencryptResult, error) {
+	target, err := crypto.ActiveKeyID()
+	if err != nil {
+		return nil, err
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	result := &dto.CredentialReencryptResult{TargetKeyID: target}
+	var afterID int64
+	for {
+		records, err := list(target, afterID, batchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(records) == 0 {
+			return result, nil
+		}
+		for _, r := range records {
+			afterID = r.ID
+			result.Scanned++
+
+			plain, err := crypto.DecryptWithKeyID(r.Ciphertext, r.KeyID)
+			if err != nil {
+				logger.Sugar().Warnf("%s %d 解密失败(key_id=%s): %v", kind, r.ID, r.KeyID, err)
+				result.FailedIDs = append(result.FailedIDs, r.ID)
+				continue
+			}
+			if dryRun {
+				result.Migrated++
+				continue
+			}
+			enc, keyID, err := crypto.EncryptEnvelope(plain)
+			if err != nil {
+				logger.Sugar().Warnf("%s %d 加密失败: %v", kind, r.ID, err)
+				result.FailedIDs = append(result.FailedIDs, r.ID)
+				continue
+			}
+			ok, err := r.swap(enc, keyID)
+			if err != nil {
+				return result, err
+			}
+			if ok {
+				result.Migrated++
+			} else {
+				result.Skipped++
+			}
+		}
+	}
+}
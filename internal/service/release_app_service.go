@@ -1,16 +1,45 @@
 package service
 
 import (
+	"context"
+	"devops-cd/internal/core/deployment"
 	"devops-cd/internal/dto"
 	"devops-cd/internal/model"
 	"devops-cd/internal/pkg/logger"
 	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
 	"fmt"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"strings"
 	"time"
 )
 
+// BatchProjectID 查询批次所属项目（用于项目级权限校验）
+func (s *BatchService) BatchProjectID(batchID int64) (int64, error) {
+	batch, err := s.findBatch(batchID)
+	if err != nil {
+		return 0, err
+	}
+	return batch.ProjectID, nil
+}
+
+// SwitchVersionScope 查询发布应用所属项目，以及切换版本是否直接触发 Prod（跳过 Pre 的应用）
+func (s *BatchService) SwitchVersionScope(releaseAppID int64) (projectID int64, prod bool, err error) {
+	var release model.ReleaseApp
+	if err := s.db.Select("id", "batch_id", "skip_pre_env").First(&release, releaseAppID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, false, pkgErrors.New(pkgErrors.CodeNotFound, "发布应用不存在")
+		}
+		return 0, false, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询发布应用失败", err)
+	}
+	projectID, err = s.BatchProjectID(release.BatchID)
+	if err != nil {
+		return 0, false, err
+	}
+	return projectID, release.SkipPreEnv, nil
+}
+
 // GetReleaseApp 获取单个发布应用详情
 func (s *BatchService) GetReleaseApp(releaseAppID int64) (*dto.ReleaseAppResponse, error) {
 	log := logger.Log.With(zap.Int64("release_app_id", releaseAppID)).Sugar()
@@ -30,15 +59,25 @@ func (s *BatchService) GetReleaseApp(releaseAppID int64) (*dto.ReleaseAppRespons
 		// 版本信息
 		PreviousDeployedTag: release.PreviousDeployedTag,
 		TargetTag:           release.TargetTag,
+		ChartLock:           release.ChartLock,
+		ManifestLock:        release.ManifestLock,
+		IssueKeys:           []string(release.IssueKeys),
+		ValuesOverride:      release.ValuesOverride,
 		LatestBuildID:       release.LatestBuildID,
 
 		// 发布信息
 		ReleaseNotes: release.ReleaseNotes,
 		IsLocked:     release.IsLocked,
 		SkipPreEnv:   release.SkipPreEnv,
+		PreOnly:      release.PreOnly,
+		SkipProdEnv:  release.SkipProdEnv,
+		Wave:         release.Wave,
 		Reasons:      release.GetRecentReason(10),
 		Status:       release.Status,
 
+		RolloutStage:  release.RolloutStage,
+		RolloutStages: release.RolloutStages,
+
 		CreatedAt: release.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt: release.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
@@ -83,7 +122,11 @@ func (s *BatchService) GetReleaseApp(releaseAppID int64) (*dto.ReleaseAppRespons
 			releaseResp.CommitBranch = &build.CommitBranch
 
 			// 2.1 加载最新的构建记录
-			if builds, err := s.batchRepo.GetBuildsSinceTime(release.AppID, build.CreatedAt, 10); err != nil {
+			var filter *model.TagFilter
+			if release.Application != nil {
+				filter = release.Application.TagFilter
+			}
+			if builds, err := s.batchRepo.GetBuildsSinceTime(release.AppID, build.CreatedAt, 10, filter); err != nil {
 				log.Errorf("查询最近构建失败: %v", err)
 			} else {
 				releaseResp.RecentBuilds = s.toBuildSummaries(builds)
@@ -108,40 +151,8 @@ func (s *BatchService) GetReleaseApp(releaseAppID int64) (*dto.ReleaseAppRespons
 		log.Errorf("查询 deployments 失败: %v", err)
 	} else if len(deployments) > 0 {
 		resp := make([]dto.DeploymentResponse, 0, len(deployments))
-		for _, dep := range deployments {
-			var startedAt *string
-			if dep.StartedAt != nil {
-				s := dep.StartedAt.Format(time.RFC3339)
-				startedAt = &s
-			}
-			var finishedAt *string
-			if dep.FinishedAt != nil {
-				s := dep.FinishedAt.Format(time.RFC3339)
-				finishedAt = &s
-			}
-
-			resp = append(resp, dto.DeploymentResponse{
-				ID: dep.ID,
-
-				BatchID:   dep.BatchID,
-				ReleaseID: dep.ReleaseID,
-				AppID:     dep.AppID,
-
-				Env:            dep.Env,
-				ClusterName:    dep.ClusterName,
-				Namespace:      dep.Namespace,
-				DeploymentName: dep.DeploymentName,
-				DriverType:     dep.DriverType,
-				Status:         dep.Status,
-				RetryCount:     dep.RetryCount,
-				MaxRetryCount:  dep.MaxRetryCount,
-				ErrorMessage:   dep.ErrorMessage,
-
-				StartedAt:  startedAt,
-				FinishedAt: finishedAt,
-				CreatedAt:  dep.CreatedAt.Format(time.RFC3339),
-				UpdatedAt:  dep.UpdatedAt.Format(time.RFC3339),
-			})
+		for i := range deployments {
+			resp = append(resp, toDeploymentResponse(&deployments[i]))
 		}
 		releaseResp.Deployments = resp
 	}
@@ -149,6 +160,57 @@ func (s *BatchService) GetReleaseApp(releaseAppID int64) (*dto.ReleaseAppRespons
 	return releaseResp, nil
 }
 
+func toDeploymentResponse(dep *model.Deployment) dto.DeploymentResponse {
+	var startedAt *string
+	if dep.StartedAt != nil {
+		s := dep.StartedAt.Format(time.RFC3339)
+		startedAt = &s
+	}
+	var finishedAt *string
+	if dep.FinishedAt != nil {
+		s := dep.FinishedAt.Format(time.RFC3339)
+		finishedAt = &s
+	}
+
+	return dto.DeploymentResponse{
+		ID: dep.ID,
+
+		BatchID:   dep.BatchID,
+		ReleaseID: dep.ReleaseID,
+		AppID:     dep.AppID,
+		AdhocID:   dep.AdhocID,
+
+		Kind:           dep.Kind,
+		Env:            dep.Env,
+		ClusterName:    dep.ClusterName,
+		Namespace:      dep.Namespace,
+		DeploymentName: dep.DeploymentName,
+		DriverType:     dep.DriverType,
+		ChartVersion:   dep.ChartVersion,
+		Status:         dep.Status,
+		RetryCount:     dep.RetryCount,
+		MaxRetryCount:  dep.MaxRetryCount,
+		LastFailedAt:   dto.FormatTime(dep.LastFailedAt),
+		RolloutStage:   dep.RolloutStage,
+		ErrorMessage:   dep.ErrorMessage,
+
+		DryRunStatus:  dep.DryRunStatus,
+		DryRunMessage: dep.DryRunMessage,
+		DryRunAt:      dto.FormatTime(dep.DryRunAt),
+
+		GitRevision: dep.GitRevision,
+		ChartDigest: dep.ChartDigest,
+
+		SlowAlertedAt:       dto.FormatTime(dep.SlowAlertedAt),
+		ExpectedDurationSec: dep.ExpectedDurationSec,
+
+		StartedAt:  startedAt,
+		FinishedAt: finishedAt,
+		CreatedAt:  dep.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  dep.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
 // UpdateBuilds 更新批次应用的构建版本
 func (s *BatchService) UpdateBuilds(req *dto.UpdateBuildsRequest) error {
 	// 1. 获取批次
@@ -224,13 +286,18 @@ func (s *BatchService) UpdateBuilds(req *dto.UpdateBuildsRequest) error {
 	return nil
 }
 
-// RetryDeployment 手动重试 deployment（仅 failed 可重试）
-func (s *BatchService) RetryDeployment(deploymentID int64, operator string, reason string) error {
+// RetryDeployment 手动重试 deployment（仅 failed / verify_failed 可重试）
+//
+// clusters 为空时只重试 deploymentID；否则重试同一发布应用、同环境、同类型下指定集群中当前生效的 failed / verify_failed deployment。
+// 重试后保留 last_failed_at，由状态机按 retry_backoff 退避后再执行
+func (s *BatchService) RetryDeployment(deploymentID int64, clusters []string, operator string, reason string) ([]int64, error) {
 	if deploymentID <= 0 {
-		return fmt.Errorf("deployment_id 无效")
+		return nil, fmt.Errorf("deployment_id 无效")
 	}
 
-	return s.db.Transaction(func(tx *gorm.DB) error {
+	var retried []int64
+	var batchID int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
 		var dep model.Deployment
 		if err := tx.Where("id = ?", deploymentID).First(&dep).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
@@ -242,30 +309,386 @@ func (s *BatchService) RetryDeployment(deploymentID int64, operator string, reas
 		if dep.SupersededBy != nil {
 			return fmt.Errorf("deployment 已被替代，禁止重试")
 		}
-		if dep.Status != constants.DeploymentStatusFailed {
-			return fmt.Errorf("仅 failed 状态允许重试，当前状态=%s", dep.Status)
+		if dep.AdhocID != nil {
+			return fmt.Errorf("临时部署不支持重试，请重新发起临时部署")
 		}
+		batchID = dep.BatchID
 
-		updates := map[string]any{
-			"status":        constants.DeploymentStatusPending,
-			"retry_count":   dep.RetryCount + 1,
-			"error_message": nil,
-			"started_at":    nil,
-			"finished_at":   nil,
+		targets := []model.Deployment{dep}
+		if len(clusters) > 0 {
+			var err error
+			if targets, err = retryTargetsByCluster(tx, &dep, clusters); err != nil {
+				return err
+			}
 		}
 
-		if err := tx.Model(&model.Deployment{}).Where("id = ? AND status = ?", dep.ID, constants.DeploymentStatusFailed).Updates(updates).Error; err != nil {
-			return err
+		for _, target := range targets {
+			if !constants.IsDeploymentFailed(target.Status) {
+				return fmt.Errorf("仅 failed/verify_failed 状态允许重试，deployment=%d cluster=%s 当前状态=%s", target.ID, target.ClusterName, target.Status)
+			}
+		}
+
+		for _, target := range targets {
+			updates := map[string]any{
+				"status":            constants.DeploymentStatusPending,
+				"retry_count":       target.RetryCount + 1,
+				"error_message":     nil,
+				"started_at":        nil,
+				"finished_at":       nil,
+				"verify_started_at": nil,
+			}
+
+			res := tx.Model(&model.Deployment{}).Where("id = ? AND status = ?", target.ID, target.Status).Updates(updates)
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return fmt.Errorf("deployment=%d 状态已变化，请刷新后重试", target.ID)
+			}
+			msg := "手动重试"
+			if reason != "" {
+				msg += ": " + reason
+			}
+			if err := tx.Create(model.NewDeploymentStatusEvent(&target, target.Status, constants.DeploymentStatusPending, operator, msg)).Error; err != nil {
+				return err
+			}
+			retried = append(retried, target.ID)
 		}
 
 		logger.Info("手动重试 deployment",
 			zap.Int64("deployment_id", dep.ID),
+			zap.Int64s("retried", retried),
+			zap.Int64("batch_id", dep.BatchID),
+			zap.Int64("release_id", dep.ReleaseID),
+			zap.Int64("app_id", dep.AppID),
+			zap.Strings("clusters", clusters),
+			zap.String("operator", operator),
+			zap.String("reason", reason))
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.triggerBatch(batchID)
+	return retried, nil
+}
+
+// retryTargetsByCluster 按集群筛选与 dep 同一发布应用、同环境、同类型的当前生效 deployment，集群不存在时报错
+func retryTargetsByCluster(tx *gorm.DB, dep *model.Deployment, clusters []string) ([]model.Deployment, error) {
+	names := make([]string, 0, len(clusters))
+	seen := make(map[string]struct{}, len(clusters))
+	for _, name := range clusters {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("clusters 不能为空")
+	}
+
+	var deps []model.Deployment
+	if err := tx.Where("release_id = ? AND env = ? AND kind = ? AND superseded_by IS NULL AND cluster IN ?",
+		dep.ReleaseID, dep.Env, dep.Kind, names).
+		Order("id ASC").Find(&deps).Error; err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]struct{}, len(deps))
+	for _, d := range deps {
+		found[d.ClusterName] = struct{}{}
+	}
+	for _, name := range names {
+		if _, ok := found[name]; !ok {
+			return nil, fmt.Errorf("集群 %s 下不存在该发布应用的 %s deployment", name, dep.Env)
+		}
+	}
+	return deps, nil
+}
+
+// RedeployConfigChart 单独重新部署 config chart（不触发 app chart）
+// 仅当前生效且已结束（success/failed）的 config Deployment 可重新部署，新记录替代旧记录
+func (s *BatchService) RedeployConfigChart(deploymentID int64, operator string, reason string) (*dto.DeploymentResponse, error) {
+	if deploymentID <= 0 {
+		return nil, fmt.Errorf("deployment_id 无效")
+	}
+
+	var created model.Deployment
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var dep model.Deployment
+		if err := tx.Where("id = ?", deploymentID).First(&dep).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("deployment 不存在")
+			}
+			return err
+		}
+
+		if dep.Kind != constants.DeploymentKindConfig {
+			return fmt.Errorf("仅 config chart 部署允许单独重新部署")
+		}
+		if dep.SupersededBy != nil {
+			return fmt.Errorf("deployment 已被替代，禁止重新部署")
+		}
+		if dep.Status != constants.DeploymentStatusSuccess && dep.Status != constants.DeploymentStatusFailed {
+			return fmt.Errorf("仅 success/failed 状态允许重新部署，当前状态=%s", dep.Status)
+		}
+
+		var batch model.Batch
+		if err := tx.Select("id", "status").Where("id = ?", dep.BatchID).First(&batch).Error; err != nil {
+			return fmt.Errorf("批次不存在: %w", err)
+		}
+		if batch.Status == constants.BatchStatusCancelled {
+			return fmt.Errorf("批次已取消，禁止重新部署")
+		}
+
+		created = model.Deployment{
+			BatchID:        dep.BatchID,
+			AppID:          dep.AppID,
+			ReleaseID:      dep.ReleaseID,
+			Kind:           constants.DeploymentKindConfig,
+			Env:            dep.Env,
+			ClusterName:    dep.ClusterName,
+			Namespace:      dep.Namespace,
+			DeploymentName: dep.DeploymentName,
+			Status:         constants.DeploymentStatusPending,
+		}
+		if err := tx.Create(&created).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Deployment{}).
+			Where("id = ? AND superseded_by IS NULL", dep.ID).
+			Update("superseded_by", created.ID).Error; err != nil {
+			return err
+		}
+
+		logger.Info("重新部署 config chart",
+			zap.Int64("deployment_id", dep.ID),
+			zap.Int64("new_deployment_id", created.ID),
 			zap.Int64("batch_id", dep.BatchID),
 			zap.Int64("release_id", dep.ReleaseID),
 			zap.Int64("app_id", dep.AppID),
 			zap.String("operator", operator),
 			zap.String("reason", reason))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.triggerBatch(created.BatchID)
 
+	resp := toDeploymentResponse(&created)
+	return &resp, nil
+}
+
+// CheckConfigChartDrift 核对应用在 env 下各集群 config chart 版本与 app chart 期望是否一致
+func (s *BatchService) CheckConfigChartDrift(ctx context.Context, appID int64, env string) (*dto.ConfigChartDriftResponse, error) {
+	items, err := deployment.CheckConfigChartDrift(ctx, s.db, appID, env)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &dto.ConfigChartDriftResponse{
+		AppID:    appID,
+		Env:      env,
+		Status:   constants.ConfigChartDriftOK,
+		Clusters: make([]dto.ConfigChartDriftItem, 0, len(items)),
+	}
+	for _, item := range items {
+		if item.Status != constants.ConfigChartDriftOK && resp.Status == constants.ConfigChartDriftOK {
+			resp.Status = item.Status
+		}
+		if item.Status == constants.ConfigChartDriftDrift {
+			resp.Status = constants.ConfigChartDriftDrift
+		}
+		resp.Clusters = append(resp.Clusters, dto.ConfigChartDriftItem{
+			ClusterName:        item.ClusterName,
+			Namespace:          item.Namespace,
+			ReleaseName:        item.ReleaseName,
+			AppDeploymentID:    item.AppDeploymentID,
+			ConfigDeploymentID: item.ConfigDeploymentID,
+			ExpectedVersion:    item.ExpectedVersion,
+			RecordedVersion:    item.RecordedVersion,
+			LiveVersion:        item.LiveVersion,
+			Status:             item.Status,
+			Message:            item.Message,
+		})
+	}
+	return resp, nil
+}
+
+// GetDeploymentDiff 查询 prod 部署前计算的 manifest diff
+func (s *BatchService) GetDeploymentDiff(deploymentID int64) (*dto.DeploymentDiffResponse, error) {
+	dep, diff, err := s.loadDeploymentDiff(s.db, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	return toDeploymentDiffResponse(dep, diff), nil
+}
+
+// AckDeploymentDiff 确认 prod 部署 diff（diff 涉及受保护资源时部署前需确认）
+// digest 必须与当前 diff 一致，diff 重新计算后发生变化需再次确认
+func (s *BatchService) AckDeploymentDiff(deploymentID int64, digest, operator string) (*dto.DeploymentDiffResponse, error) {
+	var resp *dto.DeploymentDiffResponse
+	var batchID int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		dep, diff, err := s.loadDeploymentDiff(tx, deploymentID)
+		if err != nil {
+			return err
+		}
+		batchID = dep.BatchID
+		if dep.SupersededBy != nil || dep.Status != constants.DeploymentStatusPending {
+			return fmt.Errorf("仅待部署的 deployment 允许确认 diff，当前状态=%s", dep.Status)
+		}
+		if !diff.RequiresAck {
+			return fmt.Errorf("当前 diff 无需确认")
+		}
+		if diff.Digest != digest {
+			return fmt.Errorf("diff 已变化，请刷新后重新确认")
+		}
+
+		if !diff.Acked() {
+			now := time.Now()
+			diff.AckedDigest = &diff.Digest
+			diff.AckedBy = &operator
+			diff.AckedAt = &now
+			if err := tx.Model(diff).Select("acked_digest", "acked_by", "acked_at").Updates(diff).Error; err != nil {
+				return err
+			}
+			logger.Info("确认 prod 部署 diff",
+				zap.Int64("deployment_id", dep.ID),
+				zap.Int64("batch_id", dep.BatchID),
+				zap.Int64("release_id", dep.ReleaseID),
+				zap.String("digest", digest),
+				zap.String("operator", operator))
+		}
+		resp = toDeploymentDiffResponse(dep, diff)
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	s.triggerBatch(batchID)
+	return resp, nil
+}
+
+// deploymentDiffPreviewTimeout diff 预览需拉取 chart 并 dry-run 渲染
+const deploymentDiffPreviewTimeout = 60 * time.Second
+
+// PreviewDeploymentDiff 按需渲染 deployment 待部署的 chart+values 并与集群当前 release 对比（不保存）
+func (s *BatchService) PreviewDeploymentDiff(ctx context.Context, deploymentID int64) (*dto.DeploymentDiffPreviewResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, deploymentDiffPreviewTimeout)
+	defer cancel()
+
+	preview, err := deployment.PreviewDiff(ctx, s.db, deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	var dep model.Deployment
+	if err := s.db.Select("id", "env", "cluster").First(&dep, deploymentID).Error; err != nil {
+		return nil, err
+	}
+
+	resp := &dto.DeploymentDiffPreviewResponse{
+		DeploymentID:   dep.ID,
+		Env:            dep.Env,
+		ClusterName:    dep.ClusterName,
+		Namespace:      preview.Namespace,
+		ReleaseName:    preview.ReleaseName,
+		BaseRevision:   preview.BaseRevision,
+		Digest:         preview.Digest(),
+		Changes:        make([]dto.DeploymentDiffChange, 0, len(preview.Changes)),
+		ProtectedKinds: []string{},
+		Diff:           preview.Diff,
+		GeneratedAt:    time.Now().Format(time.RFC3339),
+	}
+	seen := make(map[string]bool)
+	for _, c := range preview.Changes {
+		resp.Changes = append(resp.Changes, dto.DeploymentDiffChange{
+			Kind:      c.Kind,
+			Namespace: c.Namespace,
+			Name:      c.Name,
+			Action:    c.Action,
+			Protected: c.Protected,
+		})
+		switch c.Action {
+		case constants.ManifestChangeAdded:
+			resp.Summary.Added++
+		case constants.ManifestChangeRemoved:
+			resp.Summary.Removed++
+		default:
+			resp.Summary.Changed++
+		}
+		if c.Protected && !seen[c.Kind] {
+			seen[c.Kind] = true
+			resp.ProtectedKinds = append(resp.ProtectedKinds, c.Kind)
+		}
+	}
+	return resp, nil
+}
+
+func (s *BatchService) loadDeploymentDiff(db *gorm.DB, deploymentID int64) (*model.Deployment, *model.DeploymentDiff, error) {
+	if deploymentID <= 0 {
+		return nil, nil, fmt.Errorf("deployment_id 无效")
+	}
+	var dep model.Deployment
+	if err := db.Where("id = ?", deploymentID).First(&dep).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, fmt.Errorf("deployment 不存在")
+		}
+		return nil, nil, err
+	}
+	var diff model.DeploymentDiff
+	if err := db.Where("deployment_id = ?", deploymentID).First(&diff).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, fmt.Errorf("diff 不存在（仅 prod 环境 helm 部署在执行前生成）")
+		}
+		return nil, nil, err
+	}
+	return &dep, &diff, nil
+}
+
+func toDeploymentDiffResponse(dep *model.Deployment, diff *model.DeploymentDiff) *dto.DeploymentDiffResponse {
+	resp := &dto.DeploymentDiffResponse{
+		DeploymentID:   dep.ID,
+		Env:            dep.Env,
+		ClusterName:    dep.ClusterName,
+		Namespace:      diff.Namespace,
+		ReleaseName:    diff.ReleaseName,
+		BaseRevision:   diff.BaseRevision,
+		Status:         diff.Status,
+		Digest:         diff.Digest,
+		Changes:        make([]dto.DeploymentDiffChange, 0, len(diff.Changes)),
+		ProtectedKinds: []string{},
+		Diff:           diff.Diff,
+		RequiresAck:    diff.RequiresAck,
+		Acked:          diff.Acked(),
+		AckedBy:        diff.AckedBy,
+		ErrorMessage:   diff.ErrorMessage,
+		UpdatedAt:      diff.UpdatedAt.Format(time.RFC3339),
+	}
+	if diff.AckedAt != nil {
+		t := diff.AckedAt.Format(time.RFC3339)
+		resp.AckedAt = &t
+	}
+	seen := make(map[string]bool)
+	for _, c := range diff.Changes {
+		resp.Changes = append(resp.Changes, dto.DeploymentDiffChange{
+			Kind:      c.Kind,
+			Namespace: c.Namespace,
+			Name:      c.Name,
+			Action:    c.Action,
+			Protected: c.Protected,
+		})
+		if c.Protected && !seen[c.Kind] {
+			seen[c.Kind] = true
+			resp.ProtectedKinds = append(resp.ProtectedKinds, c.Kind)
+		}
+	}
+	return resp
 }
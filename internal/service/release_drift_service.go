@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"devops-cd/internal/core/deployment"
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ReleaseDriftChecker 配置漂移检测（由 core 引擎实现），调度器按 core.drift_check.cron 调用
+type ReleaseDriftChecker interface {
+	CheckReleaseDrifts()
+}
+
+// ReleaseDriftService 配置漂移：查询定时检测结果，按需重新检测单个 release
+type ReleaseDriftService struct {
+	db          *gorm.DB
+	liveObjects bool // 与定时检测一致，同时对比集群中的资源对象
+}
+
+func NewReleaseDriftService(db *gorm.DB, liveObjects bool) *ReleaseDriftService {
+	return &ReleaseDriftService{db: db, liveObjects: liveObjects}
+}
+
+// List 项目的配置漂移检测结果
+func (s *ReleaseDriftService) List(req *dto.ReleaseDriftListRequest, canAccess func(projectID int64) bool) ([]*dto.ReleaseDriftResponse, int64, error) {
+	if !canAccess(req.ProjectID) {
+		return nil, 0, pkgErrors.ErrForbidden
+	}
+	q := s.db.Model(&model.ReleaseDrift{}).Where("project_id = ?", req.ProjectID)
+	if req.AppID > 0 {
+		q = q.Where("app_id = ?", req.AppID)
+	}
+	if req.Env != "" {
+		q = q.Where("env = ?", req.Env)
+	}
+	if req.Status != "" {
+		q = q.Where("status = ?", req.Status)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询配置漂移失败", err)
+	}
+	var list []*model.ReleaseDrift
+	if err := q.Preload("Application").Order("id DESC").Limit(req.PageSize).Offset((req.Page - 1) * req.PageSize).Find(&list).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询配置漂移失败", err)
+	}
+	return lo.Map(list, func(d *model.ReleaseDrift, _ int) *dto.ReleaseDriftResponse {
+		return toReleaseDriftResponse(d)
+	}), total, nil
+}
+
+// Get 配置漂移检测结果详情
+func (s *ReleaseDriftService) Get(id int64, canAccess func(projectID int64) bool) (*dto.ReleaseDriftResponse, error) {
+	drift, err := s.find(id, canAccess)
+	if err != nil {
+		return nil, err
+	}
+	return toReleaseDriftResponse(drift), nil
+}
+
+// Check 立即重新检测（如修复漂移后确认），以该应用/环境/集群最近一次写入 release 的 Deployment 为基准
+func (s *ReleaseDriftService) Check(ctx context.Context, id int64, operator string, canAccess func(projectID int64) bool) (*dto.ReleaseDriftResponse, error) {
+	drift, err := s.find(id, canAccess)
+	if err != nil {
+		return nil, err
+	}
+
+	var dep model.Deployment
+	if err := s.db.WithContext(ctx).
+		Where("app_id = ? AND env = ? AND cluster = ? AND kind = ? AND release_revision IS NOT NULL",
+			drift.AppID, drift.Env, drift.ClusterName, drift.Kind).
+		Order("id DESC").Limit(1).Find(&dep).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询 Deployment 失败", err)
+	}
+	if dep.ID == 0 {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "未找到写入 release 的 Deployment，无法检测")
+	}
+	var busy int64
+	if err := s.db.WithContext(ctx).Model(&model.Deployment{}).
+		Where("app_id = ? AND env = ? AND cluster = ? AND kind = ? AND id > ? AND superseded_by IS NULL AND status IN ?",
+			dep.AppID, dep.Env, dep.ClusterName, dep.Kind, dep.ID,
+			[]string{constants.DeploymentStatusPending, constants.DeploymentStatusRunning}).
+		Count(&busy).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询进行中的部署失败", err)
+	}
+	if busy > 0 {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "该集群存在进行中的部署，请等待完成后再检测")
+	}
+
+	result, err := deployment.RecordReleaseDrift(ctx, s.db, &dep, s.liveObjects)
+	if err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeInternalError, "配置漂移检测失败", err)
+	}
+	result.Application = drift.Application
+
+	logger.Info("手动检测配置漂移",
+		zap.Int64("release_drift_id", result.ID),
+		zap.Int64("deployment_id", dep.ID),
+		zap.String("status", result.Status),
+		zap.Int("findings", len(result.Findings)),
+		zap.String("operator", operator))
+	return toReleaseDriftResponse(result), nil
+}
+
+func (s *ReleaseDriftService) find(id int64, canAccess func(projectID int64) bool) (*model.ReleaseDrift, error) {
+	var drift model.ReleaseDrift
+	if err := s.db.Preload("Application").Limit(1).Find(&drift, id).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询配置漂移失败", err)
+	}
+	if drift.ID == 0 {
+		return nil, pkgErrors.New(pkgErrors.CodeNotFound, "配置漂移记录不存在")
+	}
+	if !canAccess(drift.ProjectID) {
+		return nil, pkgErrors.ErrForbidden
+	}
+	return &drift, nil
+}
+
+func toReleaseDriftResponse(d *model.ReleaseDrift) *dto.ReleaseDriftResponse {
+	appName := ""
+	if d.Application != nil {
+		appName = d.Application.Name
+	}
+	findings := d.Findings
+	if findings == nil {
+		findings = model.DriftFindings{}
+	}
+	return &dto.ReleaseDriftResponse{
+		ID:           d.ID,
+		ProjectID:    d.ProjectID,
+		AppID:        d.AppID,
+		AppName:      appName,
+		Env:          d.Env,
+		ClusterName:  d.ClusterName,
+		Kind:         d.Kind,
+		DeploymentID: d.DeploymentID,
+		Namespace:    d.Namespace,
+		ReleaseName:  d.ReleaseName,
+		Status:       d.Status,
+		Findings:     findings,
+		ErrorMessage: d.ErrorMessage,
+		DetectedAt:   dto.FormatTime(d.DetectedAt),
+		CheckedAt:    d.CheckedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	helmDriver "devops-cd/internal/core/deployment/plan/drivers/helm"
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+)
+
+// maxValuesOverrideSize values 覆盖内容上限：仅用于一次性的小改动，大段配置应提交到 values 仓库
+const maxValuesOverrideSize = 16 << 10
+
+// UpdateReleaseValuesOverride 设置发布应用的 values 覆盖（仅封板前），部署时在 app_chart values 最后合并；内容为空表示清除
+func (s *BatchService) UpdateReleaseValuesOverride(req *dto.UpdateReleaseValuesOverrideRequest, canAccess func(projectID int64) bool) (*dto.ReleaseValuesOverrideResponse, error) {
+	var release model.ReleaseApp
+	if err := s.db.Select("id", "batch_id", "app_id", "is_locked").Limit(1).Find(&release, req.ReleaseAppID).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询发布应用失败", err)
+	}
+	if release.ID == 0 {
+		return nil, pkgErrors.New(pkgErrors.CodeNotFound, "发布应用不存在")
+	}
+	if release.BatchID != req.BatchID {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "发布应用不属于指定批次")
+	}
+	batch, err := s.findBatch(req.BatchID)
+	if err != nil {
+		return nil, err
+	}
+	if !canAccess(batch.ProjectID) {
+		return nil, pkgErrors.ErrForbidden
+	}
+	if batch.Status >= constants.BatchStatusSealed || release.IsLocked {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "批次已封板或发布记录已锁定，无法修改 values 覆盖")
+	}
+
+	var override *string
+	if content := strings.TrimSpace(req.ValuesOverride); content != "" {
+		if err := validateValuesOverride(content); err != nil {
+			return nil, err
+		}
+		override = &content
+	}
+
+	// 按 is_locked 条件更新，避免与封板并发时写入已锁定的记录
+	now := time.Now()
+	res := s.db.Model(&model.ReleaseApp{}).
+		Where("id = ? AND is_locked = ?", release.ID, false).
+		Updates(map[string]any{"values_override": override, "updated_at": now})
+	if res.Error != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "更新 values 覆盖失败", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "发布记录已锁定，无法修改 values 覆盖")
+	}
+
+	logger.Info("更新发布应用 values 覆盖",
+		zap.Int64("batch_id", req.BatchID),
+		zap.Int64("release_app_id", release.ID),
+		zap.Int64("app_id", release.AppID),
+		zap.Bool("cleared", override == nil),
+		zap.String("operator", req.Operator))
+
+	return &dto.ReleaseValuesOverrideResponse{
+		BatchID:        req.BatchID,
+		ReleaseAppID:   release.ID,
+		AppID:          release.AppID,
+		ValuesOverride: override,
+		UpdatedAt:      now.Format(time.RFC3339),
+	}, nil
+}
+
+// validateValuesOverride 校验 values 覆盖：大小受限、YAML/JSON 顶层为 object，且不允许覆盖封板固定的 image.tag
+func validateValuesOverride(content string) error {
+	if len(content) > maxValuesOverrideSize {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("values 覆盖不能超过 %d KB，较大的配置请提交到 values 仓库", maxValuesOverrideSize>>10))
+	}
+	values, err := helmDriver.ParseValuesOverride(content)
+	if err != nil {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("values 覆盖格式错误: %v", err))
+	}
+	if image, ok := values["image"].(map[string]interface{}); ok {
+		if _, ok := image["tag"]; ok {
+			return pkgErrors.New(pkgErrors.CodeBadRequest, "values 覆盖不允许设置 image.tag，镜像版本以发布应用的构建为准")
+		}
+	}
+	return nil
+}
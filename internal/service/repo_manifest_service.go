@@ -0,0 +1,634 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/git"
+	"devops-cd/internal/pkg/git/api"
+	"devops-cd/internal/pkg/manifest"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// errManifestDryRun dry-run 时用于回滚事务
+var errManifestDryRun = errors.New("manifest dry run")
+
+// SyncRepositoryManifest 手动按 devops-cd.yaml 同步某个代码库（默认分支）
+func (s *RepoSyncService) SyncRepositoryManifest(repoID int64, dryRun bool) (*dto.ManifestSyncReport, error) {
+	repo, err := s.repoRepo.FindByID(repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := s.findSourceForRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	gitClient, err := s.buildGitClient(source)
+	if err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeInternalError, "创建 Git 客户端失败", err)
+	}
+
+	return s.syncManifest(gitClient, source, repo, "", dryRun), nil
+}
+
+// ValidateRepositoryManifest 校验代码库清单并计算与平台配置的差异（始终为 dry-run，不保存报告）
+//
+// content 不为空时校验该内容（如合并前在 CI 中校验），否则读取 ref（为空时为默认分支）下的清单文件
+func (s *RepoSyncService) ValidateRepositoryManifest(req *dto.RepositoryManifestValidateRequest) (*dto.ManifestSyncReport, error) {
+	repo, err := s.repoRepo.FindByID(req.ID)
+	if err != nil {
+		return nil, err
+	}
+	report := &dto.ManifestSyncReport{
+		RepoID:   repo.ID,
+		Ref:      req.Ref,
+		DryRun:   true,
+		Drifts:   []dto.ManifestDriftItem{},
+		SyncedAt: time.Now(),
+	}
+
+	content := []byte(req.Content)
+	if req.Content == "" {
+		source, err := s.findSourceForRepo(repo)
+		if err != nil {
+			return nil, err
+		}
+		gitClient, err := s.buildGitClient(source)
+		if err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeInternalError, "创建 Git 客户端失败", err)
+		}
+		var fileName string
+		if content, fileName, err = readRepoFile(gitClient, repo, req.Ref, manifest.FileNames); err != nil {
+			report.Status = constants.ManifestStatusError
+			report.Message = fmt.Sprintf("读取 %s 失败: %v", fileName, err)
+			return report, nil
+		}
+		if fileName == "" {
+			report.Status = constants.ManifestStatusNone
+			return report, nil
+		}
+	}
+
+	s.applyManifest(repo, content, true, report)
+	return report, nil
+}
+
+// GetRepositoryManifestReport 获取最近一次清单同步报告
+func (s *RepoSyncService) GetRepositoryManifestReport(repoID int64) (*dto.ManifestSyncReport, error) {
+	repo, err := s.repoRepo.FindByID(repoID)
+	if err != nil {
+		return nil, err
+	}
+	if repo.ManifestReport == nil {
+		return &dto.ManifestSyncReport{RepoID: repo.ID, Status: constants.ManifestStatusNone, Drifts: []dto.ManifestDriftItem{}}, nil
+	}
+
+	var report dto.ManifestSyncReport
+	if err := json.Unmarshal([]byte(*repo.ManifestReport), &report); err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeInternalError, "解析清单同步报告失败", err)
+	}
+	return &report, nil
+}
+
+// findSourceForRepo 查找代码库所属的启用仓库源（平台与命名空间一致）
+func (s *RepoSyncService) findSourceForRepo(repo *model.Repository) (*model.RepoSource, error) {
+	sources, err := s.sourceRepo.ListEnabled()
+	if err != nil {
+		return nil, err
+	}
+	for _, source := range sources {
+		if source.Platform == repo.GitType && source.Namespace == repo.Namespace {
+			return source, nil
+		}
+	}
+	return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "未找到代码库对应的启用仓库源")
+}
+
+// syncManifest 读取仓库中的 devops-cd.yaml（或 .devops-cd.yaml），与平台配置比对并（非 dry-run 时）按清单更新
+//
+// 约定:
+//   - 仅创建/更新清单中声明的应用及其环境集群配置，平台多出的部分只报告为 orphan，不做删除
+//   - 同项目下同名但属于其他代码库的应用报告为 conflict，不做修改
+//   - 没有清单文件时按仓库源的发现规则处理（见 discoverApp）
+//   - 非 dry-run 的结果写回 repositories.manifest_*，供 GET /repository/manifest 查询
+func (s *RepoSyncService) syncManifest(gitClient *git.Client, source *model.RepoSource, repo *model.Repository, ref string, dryRun bool) *dto.ManifestSyncReport {
+	report := &dto.ManifestSyncReport{
+		RepoID:   repo.ID,
+		Ref:      ref,
+		DryRun:   dryRun,
+		Drifts:   []dto.ManifestDriftItem{},
+		SyncedAt: time.Now(),
+	}
+
+	defer func() {
+		if dryRun {
+			return
+		}
+		if err := s.saveManifestReport(repo.ID, report); err != nil {
+			s.logger.Warn("保存清单同步报告失败", zap.Int64("repo_id", repo.ID), zap.Error(err))
+		}
+	}()
+
+	content, fileName, err := readRepoFile(gitClient, repo, ref, manifest.FileNames)
+	if err != nil {
+		report.Status = constants.ManifestStatusError
+		report.Message = fmt.Sprintf("读取 %s 失败: %v", fileName, err)
+		return report
+	}
+	if fileName == "" {
+		s.discoverApp(gitClient, source, repo, ref, dryRun, report)
+		return report
+	}
+
+	s.applyManifest(repo, content, dryRun, report)
+	return report
+}
+
+// applyManifest 解析清单内容并与平台配置比对，非 dry-run 时按清单更新；结果写入 report
+func (s *RepoSyncService) applyManifest(repo *model.Repository, content []byte, dryRun bool, report *dto.ManifestSyncReport) {
+	m, err := manifest.Parse(content)
+	if err != nil {
+		report.Status = constants.ManifestStatusInvalid
+		report.Message = err.Error()
+		return
+	}
+
+	if repo.ProjectID == nil || *repo.ProjectID == 0 {
+		report.Status = constants.ManifestStatusSkipped
+		report.Message = "代码库未归属项目，跳过清单同步"
+		return
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		r := &manifestReconciler{tx: tx, repo: repo, projectID: *repo.ProjectID, report: report}
+		if err := r.reconcile(m); err != nil {
+			return err
+		}
+		if dryRun {
+			return errManifestDryRun
+		}
+		return nil
+	})
+
+	switch {
+	case err == nil || errors.Is(err, errManifestDryRun):
+		report.Status = constants.ManifestStatusSynced
+	case errors.As(err, new(*manifestInvalidError)):
+		report.Status = constants.ManifestStatusInvalid
+		report.Message = err.Error()
+	default:
+		report.Status = constants.ManifestStatusError
+		report.Message = err.Error()
+	}
+	if report.Status != constants.ManifestStatusSynced {
+		// 事务已回滚，部分计算出的漂移没有参考意义
+		report.Drifts = []dto.ManifestDriftItem{}
+	}
+
+	if len(report.Drifts) > 0 {
+		s.logger.Info("代码库清单存在漂移",
+			zap.Int64("repo_id", repo.ID),
+			zap.String("repo", repo.Namespace+"/"+repo.Name),
+			zap.Bool("dry_run", dryRun),
+			zap.Int("drifts", len(report.Drifts)),
+			zap.String("status", report.Status))
+	}
+}
+
+// discoverApp 代码库没有清单文件时按仓库源发现规则创建应用
+//
+// 约定:
+//   - 根目录存在 discovery_files 中任一文件时，以代码库名为应用名创建应用（app_type 取规则配置或按代码库语言推断），不创建环境集群配置与依赖
+//   - 只在代码库从未关联过应用时创建（含已删除/已下线的应用），已有应用不做任何修改
+//   - 同项目下同名应用属于其他代码库时报告为 conflict
+func (s *RepoSyncService) discoverApp(gitClient *git.Client, source *model.RepoSource, repo *model.Repository, ref string, dryRun bool, report *dto.ManifestSyncReport) {
+	report.Status = constants.ManifestStatusNone
+	if source == nil || len(source.DiscoveryFiles) == 0 {
+		return
+	}
+	_, fileName, err := readRepoFile(gitClient, repo, ref, source.DiscoveryFiles)
+	if err != nil {
+		report.Status = constants.ManifestStatusError
+		report.Message = fmt.Sprintf("读取 %s 失败: %v", fileName, err)
+		return
+	}
+	if fileName == "" {
+		return
+	}
+
+	report.Status = constants.ManifestStatusDiscovered
+	report.Message = fmt.Sprintf("无清单文件，存在 %s", fileName)
+	if repo.ProjectID == nil || *repo.ProjectID == 0 {
+		report.Status = constants.ManifestStatusSkipped
+		report.Message = "代码库未归属项目，跳过发现规则"
+		return
+	}
+	var existing int64
+	if err := s.db.Unscoped().Model(&model.Application{}).Where("repo_id = ?", repo.ID).Count(&existing).Error; err != nil {
+		report.Status = constants.ManifestStatusError
+		report.Message = fmt.Sprintf("查询代码库应用失败: %v", err)
+		return
+	}
+	if existing > 0 {
+		return
+	}
+	item, err := manifest.ScaffoldApp(repo.Name, derefString(source.DiscoveryAppType), derefString(repo.Language))
+	if err != nil {
+		report.Status = constants.ManifestStatusInvalid
+		report.Message = err.Error()
+		return
+	}
+
+	var conflict model.Application
+	if err := s.db.Unscoped().Where("project_id = ? AND name = ?", *repo.ProjectID, item.Name).Limit(1).Find(&conflict).Error; err != nil {
+		report.Status = constants.ManifestStatusError
+		report.Message = fmt.Sprintf("查询项目应用失败: %v", err)
+		return
+	}
+	if conflict.ID > 0 {
+		report.Drifts = append(report.Drifts, dto.ManifestDriftItem{App: item.Name, Field: "app", Platform: fmt.Sprintf("repo_id=%d", conflict.RepoID), Manifest: fmt.Sprintf("repo_id=%d", repo.ID), Action: constants.ManifestDriftConflict})
+		return
+	}
+
+	report.Drifts = append(report.Drifts, dto.ManifestDriftItem{App: item.Name, Field: "app", Manifest: item.AppType, Action: constants.ManifestDriftCreate})
+	if dryRun {
+		return
+	}
+	app := &model.Application{
+		RepoID:           repo.ID,
+		ProjectID:        *repo.ProjectID,
+		TeamID:           repo.TeamID,
+		Name:             item.Name,
+		AppType:          item.AppType,
+		DefaultDependsOn: model.Int64List{},
+		BaseStatus:       model.BaseStatus{Status: constants.StatusEnabled},
+	}
+	if err := s.db.Create(app).Error; err != nil {
+		report.Status = constants.ManifestStatusError
+		report.Message = fmt.Sprintf("创建应用失败: %v", err)
+		report.Drifts = []dto.ManifestDriftItem{}
+		return
+	}
+	s.logger.Info("按发现规则创建应用",
+		zap.Int64("repo_id", repo.ID),
+		zap.String("repo", repo.Namespace+"/"+repo.Name),
+		zap.Int64("app_id", app.ID),
+		zap.String("app_name", app.Name),
+		zap.String("app_type", app.AppType),
+		zap.String("matched_file", fileName))
+}
+
+// readRepoFile 按顺序读取代码库根目录下第一个存在的文件，返回文件内容与文件名；都不存在时文件名为空
+func readRepoFile(gitClient *git.Client, repo *model.Repository, ref string, fileNames []string) ([]byte, string, error) {
+	for _, name := range fileNames {
+		content, err := gitClient.GetFileContent(repo.Namespace, repo.Name, ref, name)
+		if errors.Is(err, api.ErrFileNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, name, err
+		}
+		return content, name, nil
+	}
+	return nil, "", nil
+}
+
+func (s *RepoSyncService) saveManifestReport(repoID int64, report *dto.ManifestSyncReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	return s.repoRepo.Update(repoID, map[string]interface{}{
+		"manifest_status":    report.Status,
+		"manifest_report":    string(data),
+		"manifest_synced_at": report.SyncedAt,
+	})
+}
+
+// manifestInvalidError 清单内容与平台数据不兼容（如依赖不存在、依赖成环）
+type manifestInvalidError struct {
+	msg string
+}
+
+func (e *manifestInvalidError) Error() string { return e.msg }
+
+type manifestReconciler struct {
+	tx        *gorm.DB
+	repo      *model.Repository
+	projectID int64
+	report    *dto.ManifestSyncReport
+
+	appsByName map[string]*model.Application
+	appsByID   map[int64]*model.Application
+}
+
+func (r *manifestReconciler) drift(item dto.ManifestDriftItem) {
+	r.report.Drifts = append(r.report.Drifts, item)
+}
+
+func (r *manifestReconciler) reconcile(m *manifest.Manifest) error {
+	var projectApps []*model.Application
+	if err := r.tx.Where("project_id = ? AND deleted_at IS NULL", r.projectID).Find(&projectApps).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询项目应用失败", err)
+	}
+	r.appsByName = make(map[string]*model.Application, len(projectApps))
+	r.appsByID = make(map[int64]*model.Application, len(projectApps))
+	for _, app := range projectApps {
+		r.appsByName[app.Name] = app
+		r.appsByID[app.ID] = app
+	}
+
+	// 1. 依赖名需存在于清单或项目中
+	declared := make(map[string]bool, len(m.Apps))
+	for _, app := range m.Apps {
+		declared[app.Name] = true
+	}
+	for _, app := range m.Apps {
+		for _, dep := range app.DependsOn {
+			if !declared[dep] && r.appsByName[dep] == nil {
+				return &manifestInvalidError{msg: fmt.Sprintf("%s: 依赖的应用不存在: %s", app.Name, dep)}
+			}
+		}
+	}
+
+	// 2. 应用基础信息
+	managed := make(map[string]*model.Application, len(m.Apps))
+	for _, item := range m.Apps {
+		app, err := r.reconcileApp(item)
+		if err != nil {
+			return err
+		}
+		if app != nil {
+			managed[item.Name] = app
+		}
+	}
+
+	// 3. 依赖（应用全部就绪后按名称解析）
+	for _, item := range m.Apps {
+		if app := managed[item.Name]; app != nil {
+			if err := r.reconcileDependsOn(app, item); err != nil {
+				return err
+			}
+		}
+	}
+	if err := r.checkDependencyCycle(); err != nil {
+		return err
+	}
+
+	// 4. 环境集群配置
+	for _, item := range m.Apps {
+		if app := managed[item.Name]; app != nil {
+			if err := r.reconcileEnvConfigs(app, item); err != nil {
+				return err
+			}
+		}
+	}
+
+	// 5. 代码库下未在清单中声明的应用
+	for _, app := range r.appsByName {
+		if app.RepoID == r.repo.ID && !declared[app.Name] {
+			r.drift(dto.ManifestDriftItem{App: app.Name, Field: "app", Platform: app.AppType, Action: constants.ManifestDriftOrphan})
+		}
+	}
+
+	sort.SliceStable(r.report.Drifts, func(i, j int) bool { return r.report.Drifts[i].App < r.report.Drifts[j].App })
+	return nil
+}
+
+// reconcileApp 创建或更新应用；返回 nil 表示该应用不由本代码库管理
+func (r *manifestReconciler) reconcileApp(item manifest.App) (*model.Application, error) {
+	desc := item.Description
+
+	app := r.appsByName[item.Name]
+	if app == nil {
+		app = &model.Application{
+			RepoID:           r.repo.ID,
+			ProjectID:        r.projectID,
+			TeamID:           r.repo.TeamID,
+			Name:             item.Name,
+			AppType:          item.AppType,
+			DefaultDependsOn: model.Int64List{},
+			BaseStatus:       model.BaseStatus{Status: constants.StatusEnabled},
+		}
+		if desc != "" {
+			app.Description = &desc
+		}
+		if err := r.tx.Create(app).Error; err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "创建应用失败", err)
+		}
+		r.appsByName[app.Name] = app
+		r.appsByID[app.ID] = app
+		r.drift(dto.ManifestDriftItem{App: item.Name, Field: "app", Manifest: item.AppType, Action: constants.ManifestDriftCreate})
+		return app, nil
+	}
+
+	if app.RepoID != r.repo.ID {
+		r.drift(dto.ManifestDriftItem{App: item.Name, Field: "app", Platform: fmt.Sprintf("repo_id=%d", app.RepoID), Manifest: fmt.Sprintf("repo_id=%d", r.repo.ID), Action: constants.ManifestDriftConflict})
+		return nil, nil
+	}
+
+	updates := map[string]interface{}{}
+	if app.AppType != item.AppType {
+		r.drift(dto.ManifestDriftItem{App: item.Name, Field: "app_type", Platform: app.AppType, Manifest: item.AppType, Action: constants.ManifestDriftUpdate})
+		updates["app_type"] = item.AppType
+		app.AppType = item.AppType
+	}
+	current := ""
+	if app.Description != nil {
+		current = *app.Description
+	}
+	if desc != "" && current != desc {
+		r.drift(dto.ManifestDriftItem{App: item.Name, Field: "description", Platform: current, Manifest: desc, Action: constants.ManifestDriftUpdate})
+		updates["description"] = desc
+		app.Description = &desc
+	}
+	if len(updates) > 0 {
+		if err := r.tx.Model(&model.Application{}).Where("id = ?", app.ID).Updates(updates).Error; err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "更新应用失败", err)
+		}
+	}
+	return app, nil
+}
+
+func (r *manifestReconciler) reconcileDependsOn(app *model.Application, item manifest.App) error {
+	ids := make([]int64, 0, len(item.DependsOn))
+	for _, dep := range item.DependsOn {
+		ids = append(ids, r.appsByName[dep].ID)
+	}
+	want := normalizeDependencyIDs(ids)
+	have := normalizeDependencyIDs(app.DefaultDependsOn)
+	if reflect.DeepEqual(want, have) {
+		return nil
+	}
+
+	r.drift(dto.ManifestDriftItem{App: app.Name, Field: "depends_on", Platform: r.appNames(have), Manifest: r.appNames(want), Action: constants.ManifestDriftUpdate})
+	if err := r.tx.Model(&model.Application{}).Where("id = ?", app.ID).Update("default_depends_on", model.Int64List(want)).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "更新应用默认依赖失败", err)
+	}
+	app.DefaultDependsOn = want
+	return nil
+}
+
+func (r *manifestReconciler) checkDependencyCycle() error {
+	var apps []*model.Application
+	if err := r.tx.Select("id", "default_depends_on").Where("deleted_at IS NULL").Find(&apps).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用依赖失败", err)
+	}
+	graph := make(map[int64][]int64, len(apps))
+	for _, app := range apps {
+		graph[app.ID] = app.DefaultDependsOn
+	}
+	if hasDependencyCycle(graph) {
+		return &manifestInvalidError{msg: "depends_on 存在循环依赖"}
+	}
+	return nil
+}
+
+func (r *manifestReconciler) reconcileEnvConfigs(app *model.Application, item manifest.App) error {
+	var existing []*model.AppEnvConfig
+	if err := r.tx.Where("app_id = ? AND deleted_at IS NULL", app.ID).Find(&existing).Error; err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用环境配置失败", err)
+	}
+	existingMap := make(map[string]*model.AppEnvConfig, len(existing))
+	for _, cfg := range existing {
+		existingMap[cfg.Env+":"+cfg.Cluster] = cfg
+	}
+
+	declared := make(map[string]bool)
+	for _, env := range sortedKeys(item.Envs) {
+		for _, c := range item.Envs[env] {
+			key := env + ":" + c.Cluster
+			declared[key] = true
+
+			configData, err := manifestConfigData(c)
+			if err != nil {
+				return err
+			}
+			var deploymentName *string
+			if c.DeploymentName != "" {
+				name := c.DeploymentName
+				deploymentName = &name
+			}
+
+			cfg := existingMap[key]
+			if cfg == nil {
+				replicas := c.Replicas
+				if replicas == 0 {
+					replicas = getDefaultReplicas(env)
+				}
+				cfg = &model.AppEnvConfig{
+					AppID:                  app.ID,
+					Env:                    env,
+					Cluster:                c.Cluster,
+					DeploymentNameOverride: deploymentName,
+					Replicas:               replicas,
+					ConfigData:             configData,
+					BaseStatus:             model.BaseStatus{Status: constants.StatusEnabled},
+				}
+				if err := r.tx.Create(cfg).Error; err != nil {
+					return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "创建应用环境配置失败", err)
+				}
+				r.drift(dto.ManifestDriftItem{App: app.Name, Env: env, Cluster: c.Cluster, Field: "env_cluster", Manifest: replicas, Action: constants.ManifestDriftCreate})
+				continue
+			}
+
+			updates := map[string]interface{}{}
+			if c.Replicas > 0 && cfg.Replicas != c.Replicas {
+				r.drift(dto.ManifestDriftItem{App: app.Name, Env: env, Cluster: c.Cluster, Field: "replicas", Platform: cfg.Replicas, Manifest: c.Replicas, Action: constants.ManifestDriftUpdate})
+				updates["replicas"] = c.Replicas
+			}
+			if derefString(cfg.DeploymentNameOverride) != c.DeploymentName {
+				r.drift(dto.ManifestDriftItem{App: app.Name, Env: env, Cluster: c.Cluster, Field: "deployment_name", Platform: derefString(cfg.DeploymentNameOverride), Manifest: c.DeploymentName, Action: constants.ManifestDriftUpdate})
+				updates["deployment_name_override"] = deploymentName
+			}
+			if !sameConfigData(cfg.ConfigData, configData) {
+				r.drift(dto.ManifestDriftItem{App: app.Name, Env: env, Cluster: c.Cluster, Field: "values", Platform: derefString(cfg.ConfigData), Manifest: derefString(configData), Action: constants.ManifestDriftUpdate})
+				updates["config_data"] = configData
+			}
+			if len(updates) > 0 {
+				if err := r.tx.Model(&model.AppEnvConfig{}).Where("id = ?", cfg.ID).Updates(updates).Error; err != nil {
+					return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "更新应用环境配置失败", err)
+				}
+			}
+		}
+	}
+
+	for _, cfg := range existing {
+		if !declared[cfg.Env+":"+cfg.Cluster] {
+			r.drift(dto.ManifestDriftItem{App: app.Name, Env: cfg.Env, Cluster: cfg.Cluster, Field: "env_cluster", Platform: cfg.Replicas, Action: constants.ManifestDriftOrphan})
+		}
+	}
+	return nil
+}
+
+func (r *manifestReconciler) appNames(ids []int64) []string {
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if app := r.appsByID[id]; app != nil {
+			names = append(names, app.Name)
+		} else {
+			names = append(names, fmt.Sprintf("#%d", id))
+		}
+	}
+	return names
+}
+
+// manifestConfigData 清单中的 values 层转换为 app_env_configs.config_data，无 values 时返回 nil
+func manifestConfigData(c manifest.EnvCluster) (*string, error) {
+	if len(c.Values) == 0 {
+		return nil, nil
+	}
+	data := model.AppEnvConfigData{Values: make([]model.ValuesLayer, 0, len(c.Values))}
+	for _, layer := range c.Values {
+		data.Values = append(data.Values, layer.ToModel())
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeInternalError, "序列化环境配置失败", err)
+	}
+	s := string(raw)
+	return &s, nil
+}
+
+// sameConfigData 按 JSON 语义比较（忽略数据库返回的格式差异）
+func sameConfigData(a, b *string) bool {
+	var va, vb interface{}
+	if a != nil && *a != "" {
+		if err := json.Unmarshal([]byte(*a), &va); err != nil {
+			return false
+		}
+	}
+	if b != nil && *b != "" {
+		if err := json.Unmarshal([]byte(*b), &vb); err != nil {
+			return false
+		}
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+func sortedKeys(m map[string][]manifest.EnvCluster) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
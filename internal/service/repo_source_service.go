@@ -6,21 +6,19 @@ import (
 
 	"devops-cd/internal/dto"
 	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/crypto"
 	"devops-cd/internal/repository"
-	"devops-cd/pkg/utils"
 )
 
 type RepoSourceService struct {
 	repo     repository.RepoSyncSourceRepository
 	teamRepo repository.TeamRepository
-	aesKey   string
 }
 
-func NewRepoSourceService(repo repository.RepoSyncSourceRepository, teamRepo repository.TeamRepository, aesKey string) *RepoSourceService {
+func NewRepoSourceService(repo repository.RepoSyncSourceRepository, teamRepo repository.TeamRepository) *RepoSourceService {
 	return &RepoSourceService{
 		repo:     repo,
 		teamRepo: teamRepo,
-		aesKey:   aesKey,
 	}
 }
 
@@ -47,7 +45,7 @@ func (s *RepoSourceService) Create(req *dto.CreateRepoSyncSourceRequest) (*dto.R
 		return nil, err
 	}
 
-	enc, err := utils.EncryptSecret(s.aesKey, req.Token)
+	enc, keyID, err := crypto.EncryptEnvelope(req.Token)
 	if err != nil {
 		return nil, pkgErrors.Wrap(pkgErrors.CodeInternalError, "加密 Token 失败", err)
 	}
@@ -62,9 +60,12 @@ func (s *RepoSourceService) Create(req *dto.CreateRepoSyncSourceRequest) (*dto.R
 		BaseURL:          req.BaseURL,
 		Namespace:        req.Namespace,
 		AuthTokenEnc:     enc,
+		AuthTokenKeyID:   keyID,
 		Enabled:          enabled,
 		DefaultProjectID: req.DefaultProjectID,
 		DefaultTeamID:    req.DefaultTeamID,
+		DiscoveryFiles:   model.StringList(req.DiscoveryFiles),
+		DiscoveryAppType: req.DiscoveryAppType,
 		CreatedBy:        req.CreatedBy,
 	}
 
@@ -92,6 +93,8 @@ func (s *RepoSourceService) Update(req *dto.UpdateRepoSyncSourceRequest) (*dto.R
 	source.UpdatedBy = req.UpdatedBy
 	source.DefaultProjectID = req.DefaultProjectID
 	source.DefaultTeamID = req.DefaultTeamID
+	source.DiscoveryFiles = model.StringList(req.DiscoveryFiles)
+	source.DiscoveryAppType = req.DiscoveryAppType
 
 	if req.Enabled != nil {
 		source.Enabled = *req.Enabled
@@ -102,11 +105,12 @@ func (s *RepoSourceService) Update(req *dto.UpdateRepoSyncSourceRequest) (*dto.R
 			return nil, pkgErrors.Wrap(pkgErrors.CodeBadRequest, "Token 不能为空", nil)
 		}
 
-		enc, err := utils.EncryptSecret(s.aesKey, *req.Token)
+		enc, keyID, err := crypto.EncryptEnvelope(*req.Token)
 		if err != nil {
 			return nil, pkgErrors.Wrap(pkgErrors.CodeInternalError, "加密 Token 失败", err)
 		}
 		source.AuthTokenEnc = enc
+		source.AuthTokenKeyID = keyID
 	}
 
 	if err := s.repo.Update(source); err != nil {
@@ -120,6 +124,24 @@ func (s *RepoSourceService) Delete(id int64) error {
 	return s.repo.Delete(id)
 }
 
+// Reencrypt 将存量仓库源 token 迁移到当前 crypto.provider（在线执行，逐条 CAS 更新）
+func (s *RepoSourceService) Reencrypt(batchSize int, dryRun bool) (*dto.CredentialReencryptResult, error) {
+	return reencrypt("repo_source", batchSize, dryRun, func(target string, afterID int64, limit int) ([]reencryptRecord, error) {
+		list, err := s.repo.ListForReencrypt(target, afterID, limit)
+		if err != nil {
+			return nil, err
+		}
+		records := make([]reencryptRecord, 0, len(list))
+		for _, source := range list {
+			records = append(records, reencryptRecord{
+				ID: source.ID, Ciphertext: source.AuthTokenEnc, KeyID: source.AuthTokenKeyID,
+				swap: func(enc, keyID string) (bool, error) { return s.repo.SwapAuthToken(source, enc, keyID) },
+			})
+		}
+		return records, nil
+	})
+}
+
 func (s *RepoSourceService) toResponse(source *model.RepoSource) *dto.RepoSyncSourceResponse {
 	hasToken := source.AuthTokenEnc != ""
 
@@ -131,6 +153,8 @@ func (s *RepoSourceService) toResponse(source *model.RepoSource) *dto.RepoSyncSo
 		Enabled:          source.Enabled,
 		DefaultProjectID: source.DefaultProjectID,
 		DefaultTeamID:    source.DefaultTeamID,
+		DiscoveryFiles:   source.DiscoveryFiles,
+		DiscoveryAppType: source.DiscoveryAppType,
 		LastSyncedAt:     source.LastSyncedAt,
 		LastStatus:       source.LastStatus,
 		LastMessage:      source.LastMessage,
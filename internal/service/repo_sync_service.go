@@ -4,10 +4,10 @@ import (
 	"fmt"
 
 	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/crypto"
 	"devops-cd/internal/pkg/git"
 	"devops-cd/internal/pkg/git/api"
 	"devops-cd/internal/repository"
-	"devops-cd/pkg/utils"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -18,18 +18,18 @@ type RepoSyncService struct {
 	repoRepo   repository.RepositoryRepository
 	sourceRepo repository.RepoSyncSourceRepository
 	teamRepo   repository.TeamRepository
+	db         *gorm.DB
 	logger     *zap.Logger
-	aesKey     string
 }
 
 // NewRepoSyncService 创建代码库同步服务
-func NewRepoSyncService(db *gorm.DB, logger *zap.Logger, aesKey string) *RepoSyncService {
+func NewRepoSyncService(db *gorm.DB, logger *zap.Logger) *RepoSyncService {
 	return &RepoSyncService{
 		repoRepo:   repository.NewRepositoryRepository(db),
 		sourceRepo: repository.NewRepoSyncSourceRepository(db),
 		teamRepo:   repository.NewTeamRepository(db),
+		db:         db,
 		logger:     logger,
-		aesKey:     aesKey,
 	}
 }
 
@@ -88,7 +88,7 @@ func (s *RepoSyncService) SyncFromSource(source *model.RepoSource) (int, int, er
 	failedCount := 0
 
 	for _, repoInfo := range repos {
-		if err := s.syncRepository(&repoInfo, source); err != nil {
+		if err := s.syncRepository(gitClient, &repoInfo, source); err != nil {
 			s.logger.Error("同步仓库失败", zap.String("repo", repoInfo.FullName), zap.Error(err))
 			failedCount++
 			continue
@@ -99,7 +99,7 @@ func (s *RepoSyncService) SyncFromSource(source *model.RepoSource) (int, int, er
 	return successCount, failedCount, nil
 }
 
-func (s *RepoSyncService) syncRepository(repoInfo *api.RepositoryInfo, source *model.RepoSource) error {
+func (s *RepoSyncService) syncRepository(gitClient *git.Client, repoInfo *api.RepositoryInfo, source *model.RepoSource) error {
 	repo := &model.Repository{
 		Namespace:   repoInfo.Owner,
 		Name:        repoInfo.Name,
@@ -138,7 +138,17 @@ func (s *RepoSyncService) syncRepository(repoInfo *api.RepositoryInfo, source *m
 			zap.String("repo", repoInfo.FullName))
 	}
 
-	return s.repoRepo.Upsert(repo)
+	if err := s.repoRepo.Upsert(repo); err != nil {
+		return err
+	}
+
+	// 按仓库内 devops-cd.yaml 同步应用配置，没有清单时按发现规则创建应用（失败只记录在报告中，不影响代码库同步结果）
+	saved, err := s.repoRepo.FindByNamespaceAndName(repo.Namespace, repo.Name)
+	if err != nil {
+		return err
+	}
+	s.syncManifest(gitClient, source, saved, repoInfo.DefaultBranch, false)
+	return nil
 }
 
 // SyncSourceByID 手动同步某个源
@@ -190,10 +200,23 @@ func (s *RepoSyncService) TestSourceConnection(id int64) error {
 }
 
 func (s *RepoSyncService) buildGitClient(source *model.RepoSource) (*git.Client, error) {
-	token, err := utils.DecryptSecret(s.aesKey, source.AuthTokenEnc)
+	token, err := crypto.DecryptWithKeyID(source.AuthTokenEnc, source.AuthTokenKeyID)
 	if err != nil {
 		return nil, err
 	}
 
 	return git.NewClient(source.BaseURL, token, source.Platform)
 }
+
+// ChangedFiles 查询代码库 base...head 之间变更的文件（构建通知按路径分发应用时使用）
+func (s *RepoSyncService) ChangedFiles(repo *model.Repository, base, head string) ([]string, error) {
+	source, err := s.findSourceForRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	gitClient, err := s.buildGitClient(source)
+	if err != nil {
+		return nil, err
+	}
+	return gitClient.ChangedFiles(repo.Namespace, repo.Name, base, head)
+}
@@ -1,8 +1,12 @@
 package service
 
 import (
+	"crypto/sha256"
+	"crypto/subtle"
 	pkgErrors "devops-cd/pkg/responses"
+	"encoding/hex"
 	"fmt"
+	"strings"
 	"time"
 
 	"devops-cd/internal/dto"
@@ -17,6 +21,8 @@ type RepositoryService interface {
 	List(query *dto.RepositoryListQuery) ([]*dto.RepositoryResponse, int64, error)
 	Update(id int64, req *dto.UpdateRepositoryRequest) (*dto.RepositoryResponse, error)
 	Delete(id int64) error
+	// AuthenticateCI 校验代码库的 Jenkins 构建通知 token
+	AuthenticateCI(namespace, name, token string) error
 }
 
 type repositoryService struct {
@@ -53,6 +59,10 @@ func (s *repositoryService) Create(req *dto.CreateRepositoryRequest) (*dto.Repos
 			Status: constants.StatusEnabled,
 		},
 	}
+	if req.CIToken != nil && strings.TrimSpace(*req.CIToken) != "" {
+		hash := hashCIToken(strings.TrimSpace(*req.CIToken))
+		repo.CIToken = &hash
+	}
 
 	// 保存到数据库
 	if err := s.repo.Create(repo); err != nil {
@@ -165,6 +175,12 @@ func (s *repositoryService) Update(id int64, req *dto.UpdateRepositoryRequest) (
 	if req.Status != nil {
 		updates["status"] = *req.Status
 	}
+	if req.CIToken != nil {
+		updates["ci_token"] = nil
+		if token := strings.TrimSpace(*req.CIToken); token != "" {
+			updates["ci_token"] = hashCIToken(token)
+		}
+	}
 
 	// 保存更新
 	if err := s.repo.Update(id, updates); err != nil {
@@ -185,22 +201,44 @@ func (s *repositoryService) Delete(id int64) error {
 	return s.repo.Delete(id)
 }
 
+func (s *repositoryService) AuthenticateCI(namespace, name, token string) error {
+	repo, err := s.repo.FindByNamespaceAndName(namespace, name)
+	if err != nil {
+		if err == pkgErrors.ErrRecordNotFound {
+			return pkgErrors.New(pkgErrors.CodeNotFound, fmt.Sprintf("代码库不存在: %s/%s", namespace, name))
+		}
+		return err
+	}
+	if repo.CIToken == nil || token == "" || subtle.ConstantTimeCompare([]byte(*repo.CIToken), []byte(hashCIToken(token))) != 1 {
+		return pkgErrors.ErrForbidden
+	}
+	return nil
+}
+
+// hashCIToken 代码库 ci_token 只保存 SHA-256（十六进制），不保存明文
+func hashCIToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
 // toResponse 转换为响应对象
 func (s *repositoryService) toResponse(repo *model.Repository, apps []*model.Application) *dto.RepositoryResponse {
 	resp := &dto.RepositoryResponse{
-		ID:          repo.ID,
-		Namespace:   repo.Namespace,
-		Name:        repo.Name,
-		FullName:    fmt.Sprintf("%s/%s", repo.Namespace, repo.Name),
-		Description: repo.Description,
-		GitURL:      repo.GitURL,
-		GitType:     repo.GitType,
-		Language:    repo.Language,
-		TeamID:      repo.TeamID,
-		ProjectID:   repo.ProjectID,
-		Status:      repo.Status,
-		CreatedAt:   repo.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   repo.UpdatedAt.Format(time.RFC3339),
+		ID:             repo.ID,
+		Namespace:      repo.Namespace,
+		Name:           repo.Name,
+		FullName:       fmt.Sprintf("%s/%s", repo.Namespace, repo.Name),
+		Description:    repo.Description,
+		GitURL:         repo.GitURL,
+		GitType:        repo.GitType,
+		Language:       repo.Language,
+		TeamID:         repo.TeamID,
+		ProjectID:      repo.ProjectID,
+		Status:         repo.Status,
+		ManifestStatus: repo.ManifestStatus,
+		HasCIToken:     repo.CIToken != nil,
+		CreatedAt:      repo.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      repo.UpdatedAt.Format(time.RFC3339),
 	}
 
 	// 添加团队名称
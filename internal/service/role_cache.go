@@ -0,0 +1,87 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"devops-cd/internal/pkg/auth"
+	"devops-cd/internal/pkg/logger"
+	"devops-cd/internal/repository"
+)
+
+// roleCacheTTL 自定义角色缓存有效期（多实例部署时其他实例的角色变更最迟在该时间后生效）
+const roleCacheTTL = 30 * time.Second
+
+// RoleCache 自定义角色 → 权限缓存，供鉴权使用；角色变更后本实例立即失效
+type RoleCache struct {
+	repo *repository.RoleRepository
+
+	mu       sync.RWMutex
+	perms    map[string][]auth.Permission
+	loadedAt time.Time
+}
+
+func NewRoleCache(repo *repository.RoleRepository) *RoleCache {
+	return &RoleCache{repo: repo}
+}
+
+// Permissions 一组角色中自定义角色的权限（内置角色由 auth.Allow 处理）
+func (c *RoleCache) Permissions(roles []string) []auth.Permission {
+	all := c.load()
+	var perms []auth.Permission
+	for _, r := range roles {
+		perms = append(perms, all[r]...)
+	}
+	return perms
+}
+
+// Exists 角色是否存在（内置或自定义）
+func (c *RoleCache) Exists(role string) bool {
+	if auth.IsBuiltin(role) {
+		return true
+	}
+	_, ok := c.load()[role]
+	return ok
+}
+
+// Invalidate 使缓存失效
+func (c *RoleCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.perms = nil
+}
+
+func (c *RoleCache) load() map[string][]auth.Permission {
+	c.mu.RLock()
+	if c.perms != nil && time.Since(c.loadedAt) < roleCacheTTL {
+		defer c.mu.RUnlock()
+		return c.perms
+	}
+	c.mu.RUnlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.perms != nil && time.Since(c.loadedAt) < roleCacheTTL {
+		return c.perms
+	}
+	roles, err := c.repo.ListAll()
+	if err != nil {
+		logger.Sugar().Warnf("load custom roles error: %v", err)
+		// 加载失败时沿用旧缓存，无缓存时视为没有自定义角色
+		if c.perms != nil {
+			return c.perms
+		}
+		return map[string][]auth.Permission{}
+	}
+	perms := make(map[string][]auth.Permission, len(roles))
+	for _, r := range roles {
+		ps := make([]auth.Permission, 0, len(r.Permissions))
+		for _, p := range r.Permissions {
+			ps = append(ps, auth.Permission(p.Permission))
+		}
+		perms[r.Name] = ps
+	}
+	c.perms = perms
+	c.loadedAt = time.Now()
+	return perms
+}
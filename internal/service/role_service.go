@@ -0,0 +1,232 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/auth"
+	"devops-cd/internal/repository"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"github.com/samber/lo"
+)
+
+var (
+	roleNamePattern   = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	permissionPattern = regexp.MustCompile(`^(\*|[a-z_]+)(:(\*|[a-z_]+))*$`)
+)
+
+type RoleService interface {
+	// List 内置角色 + 自定义角色
+	List() ([]*dto.RoleResponse, error)
+	Get(id int64) (*dto.RoleResponse, error)
+	Create(req *dto.CreateRoleRequest, operator string) (*dto.RoleResponse, error)
+	Update(id int64, req *dto.UpdateRoleRequest, operator string) (*dto.RoleResponse, error)
+	// Delete 删除自定义角色，仍被分配时拒绝
+	Delete(id int64) error
+	// Permissions 已知权限点
+	Permissions() []string
+	// AssignUserRoles 设置用户系统角色（users.system_roles）
+	AssignUserRoles(userID int64, roles []string) (*dto.UserRolesResponse, error)
+	// AssignTeamRoles 设置团队角色（teams.roles）
+	AssignTeamRoles(teamID int64, roles []string) (*dto.TeamRolesResponse, error)
+}
+
+type roleService struct {
+	repo     *repository.RoleRepository
+	userRepo *repository.UserRepository
+	teamRepo repository.TeamRepository
+	cache    *RoleCache
+}
+
+func NewRoleService(repo *repository.RoleRepository, userRepo *repository.UserRepository, teamRepo repository.TeamRepository, cache *RoleCache) RoleService {
+	return &roleService{repo: repo, userRepo: userRepo, teamRepo: teamRepo, cache: cache}
+}
+
+func (s *roleService) List() ([]*dto.RoleResponse, error) {
+	out := make([]*dto.RoleResponse, 0, len(auth.BuiltinRoles))
+	for _, r := range auth.BuiltinRoles {
+		out = append(out, &dto.RoleResponse{
+			Name:        string(r),
+			DisplayName: string(r),
+			Builtin:     true,
+			Permissions: lo.Map(auth.RolePermissions[r], func(p auth.Permission, _ int) string { return string(p) }),
+		})
+	}
+	roles, err := s.repo.ListAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range roles {
+		out = append(out, toRoleResponse(r))
+	}
+	return out, nil
+}
+
+func (s *roleService) Get(id int64) (*dto.RoleResponse, error) {
+	role, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return toRoleResponse(role), nil
+}
+
+func (s *roleService) Create(req *dto.CreateRoleRequest, operator string) (*dto.RoleResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if !roleNamePattern.MatchString(name) {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "name 仅支持小写字母开头的小写字母、数字和 _")
+	}
+	if auth.IsBuiltin(name) {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("不能与内置角色重名: %s", name))
+	}
+	exists, err := s.repo.ExistsByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("角色已存在: %s", name))
+	}
+	perms, err := normalizePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &model.Role{
+		Name:        name,
+		DisplayName: req.DisplayName,
+		Description: req.Description,
+		CreatedBy:   operator,
+		UpdatedBy:   operator,
+		Permissions: perms,
+	}
+	if err := s.repo.Create(role); err != nil {
+		return nil, err
+	}
+	s.cache.Invalidate()
+	return toRoleResponse(role), nil
+}
+
+func (s *roleService) Update(id int64, req *dto.UpdateRoleRequest, operator string) (*dto.RoleResponse, error) {
+	role, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.DisplayName != nil {
+		role.DisplayName = *req.DisplayName
+	}
+	if req.Description != nil {
+		role.Description = *req.Description
+	}
+	if len(req.Permissions) > 0 {
+		perms, err := normalizePermissions(req.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		role.Permissions = perms
+	}
+	role.UpdatedBy = operator
+	if err := s.repo.Update(role); err != nil {
+		return nil, err
+	}
+	s.cache.Invalidate()
+	return toRoleResponse(role), nil
+}
+
+func (s *roleService) Delete(id int64) error {
+	role, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	count, err := s.repo.CountAssignments(role.Name)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("角色 %s 仍被分配 %d 次，请先移除分配", role.Name, count))
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.cache.Invalidate()
+	return nil
+}
+
+func (s *roleService) Permissions() []string {
+	return lo.Map(auth.AllPermissions, func(p auth.Permission, _ int) string { return string(p) })
+}
+
+func (s *roleService) AssignUserRoles(userID int64, roles []string) (*dto.UserRolesResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	normalized, err := s.checkRoles(roles)
+	if err != nil {
+		return nil, err
+	}
+	user.SystemRoles = normalized
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, err
+	}
+	return &dto.UserRolesResponse{UserID: user.ID, Username: user.Username, Roles: []string(user.SystemRoles)}, nil
+}
+
+func (s *roleService) AssignTeamRoles(teamID int64, roles []string) (*dto.TeamRolesResponse, error) {
+	team, err := s.teamRepo.FindByID(teamID)
+	if err != nil {
+		return nil, err
+	}
+	normalized, err := s.checkRoles(roles)
+	if err != nil {
+		return nil, err
+	}
+	team.Roles = normalized
+	if err := s.teamRepo.Update(team); err != nil {
+		return nil, err
+	}
+	return &dto.TeamRolesResponse{TeamID: team.ID, Name: team.Name, Roles: []string(team.Roles)}, nil
+}
+
+// checkRoles 去重并校验角色存在（内置或自定义）
+func (s *roleService) checkRoles(roles []string) (model.StringList, error) {
+	normalized := normalizeRoles(roles, "")
+	for _, r := range normalized {
+		if !s.cache.Exists(r) {
+			return nil, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("角色不存在: %s", r))
+		}
+	}
+	return normalized, nil
+}
+
+// normalizePermissions 校验权限格式（段为小写字母/_ 或通配符 *）并去重
+func normalizePermissions(input []string) ([]model.RolePermission, error) {
+	perms := make([]model.RolePermission, 0, len(input))
+	for _, p := range lo.Uniq(lo.Map(input, func(p string, _ int) string { return strings.TrimSpace(p) })) {
+		if !permissionPattern.MatchString(p) {
+			return nil, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("权限格式错误: %s（如 batch:create、batch:*、*:view）", p))
+		}
+		perms = append(perms, model.RolePermission{Permission: p})
+	}
+	if len(perms) == 0 {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "permissions 不能为空")
+	}
+	return perms, nil
+}
+
+func toRoleResponse(r *model.Role) *dto.RoleResponse {
+	return &dto.RoleResponse{
+		ID:          r.ID,
+		Name:        r.Name,
+		DisplayName: r.DisplayName,
+		Description: r.Description,
+		Permissions: lo.Map(r.Permissions, func(p model.RolePermission, _ int) string { return p.Permission }),
+		CreatedBy:   r.CreatedBy,
+		UpdatedBy:   r.UpdatedBy,
+		CreatedAt:   r.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   r.UpdatedAt.Format(time.RFC3339),
+	}
+}
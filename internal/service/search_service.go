@@ -0,0 +1,467 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/database"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+// 搜索结果类型（同一创建时间内按该顺序排列）
+const (
+	SearchTypeBatch      = "batch"
+	SearchTypeBuild      = "build"
+	SearchTypeDeployment = "deployment"
+)
+
+var searchTypes = []string{SearchTypeBatch, SearchTypeBuild, SearchTypeDeployment}
+
+// SearchService 跨构建、批次、部署的统一搜索
+type SearchService struct {
+	db *gorm.DB
+}
+
+func NewSearchService(db *gorm.DB) *SearchService {
+	// 只读查询，走读副本
+	return &SearchService{db: database.ReadReplica(db)}
+}
+
+// searchCursor 游标：上一页最后一条结果的排序键 (created_at DESC, type, id DESC)
+type searchCursor struct {
+	createdAt time.Time
+	rank      int
+	id        int64
+}
+
+func (c *searchCursor) encode() string {
+	raw := fmt.Sprintf("%d:%d:%d", c.createdAt.UnixNano(), c.rank, c.id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeSearchCursor(s string) (*searchCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	nanos, err1 := strconv.ParseInt(parts[0], 10, 64)
+	rank, err2 := strconv.Atoi(parts[1])
+	id, err3 := strconv.ParseInt(parts[2], 10, 64)
+	if err1 != nil || err2 != nil || err3 != nil || rank < 0 || rank >= len(searchTypes) {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &searchCursor{createdAt: time.Unix(0, nanos), rank: rank, id: id}, nil
+}
+
+// searchQuery 解析后的搜索条件
+type searchQuery struct {
+	req      *dto.SearchRequest
+	q        string
+	projects []int64 // 可搜索的项目
+	start    *time.Time
+	end      *time.Time
+	cursor   *searchCursor
+	limit    int
+}
+
+// Search 按 q 与结构化条件搜索构建、批次、部署，结果按创建时间倒序合并后游标分页
+//
+// 部署通过发布应用的构建（及回滚构建）关联 commit / 镜像 tag，可直接回答「某个 commit 部署到了哪里」
+func (s *SearchService) Search(req *dto.SearchRequest, canView func(projectID int64) bool) (*dto.SearchResponse, error) {
+	types := searchTypes
+	if strings.TrimSpace(req.Types) != "" {
+		types = nil
+		for _, t := range strings.Split(req.Types, ",") {
+			t = strings.TrimSpace(t)
+			if !lo.Contains(searchTypes, t) {
+				return nil, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("不支持的搜索类型: %s（可选: %s）", t, strings.Join(searchTypes, ", ")))
+			}
+			if !lo.Contains(types, t) {
+				types = append(types, t)
+			}
+		}
+	}
+
+	query := &searchQuery{req: req, q: strings.TrimSpace(req.Q), limit: req.Limit}
+	if query.limit <= 0 {
+		query.limit = 20
+	}
+	if req.Since != nil {
+		start := truncateDay(*req.Since)
+		query.start = &start
+	}
+	if req.Until != nil {
+		end := truncateDay(*req.Until).AddDate(0, 0, 1) // 含结束当天
+		query.end = &end
+	}
+	if req.Cursor != "" {
+		cursor, err := decodeSearchCursor(req.Cursor)
+		if err != nil {
+			return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "cursor 无效")
+		}
+		query.cursor = cursor
+	}
+
+	if req.ProjectID != nil {
+		if !canView(*req.ProjectID) {
+			return nil, pkgErrors.ErrForbidden
+		}
+		query.projects = []int64{*req.ProjectID}
+	} else {
+		var projectIDs []int64
+		if err := s.db.Model(&model.Project{}).Pluck("id", &projectIDs).Error; err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询项目失败", err)
+		}
+		for _, id := range projectIDs {
+			if canView(id) {
+				query.projects = append(query.projects, id)
+			}
+		}
+	}
+
+	resp := &dto.SearchResponse{Items: []*dto.SearchResultItem{}}
+	if len(query.projects) == 0 {
+		return resp, nil
+	}
+
+	// 各类型取 limit+1 条后合并，截取 limit 条
+	var items []*dto.SearchResultItem
+	for _, t := range types {
+		var found []*dto.SearchResultItem
+		var err error
+		switch t {
+		case SearchTypeBatch:
+			found, err = s.searchBatches(query)
+		case SearchTypeBuild:
+			found, err = s.searchBuilds(query)
+		case SearchTypeDeployment:
+			found, err = s.searchDeployments(query)
+		}
+		if err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "搜索失败", err)
+		}
+		items = append(items, found...)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		if ra, rb := searchRank(a.Type), searchRank(b.Type); ra != rb {
+			return ra < rb
+		}
+		return a.ID > b.ID
+	})
+	if len(items) > query.limit {
+		items = items[:query.limit]
+		last := items[len(items)-1]
+		resp.HasMore = true
+		resp.NextCursor = (&searchCursor{createdAt: last.CreatedAt, rank: searchRank(last.Type), id: last.ID}).encode()
+	}
+	resp.Items = items
+	return resp, nil
+}
+
+func searchRank(t string) int {
+	for i, st := range searchTypes {
+		if st == t {
+			return i
+		}
+	}
+	return len(searchTypes)
+}
+
+// page 时间范围、游标与排序（table 为带表名前缀的列所属表）
+func (q *searchQuery) page(db *gorm.DB, table, t string) *gorm.DB {
+	col := func(name string) string { return table + "." + name }
+	if q.start != nil {
+		db = db.Where(col("created_at")+" >= ?", *q.start)
+	}
+	if q.end != nil {
+		db = db.Where(col("created_at")+" < ?", *q.end)
+	}
+	if c := q.cursor; c != nil {
+		switch rank := searchRank(t); {
+		case rank < c.rank:
+			db = db.Where(col("created_at")+" < ?", c.createdAt)
+		case rank == c.rank:
+			db = db.Where(fmt.Sprintf("%s < ? OR (%s = ? AND %s < ?)", col("created_at"), col("created_at"), col("id")),
+				c.createdAt, c.createdAt, c.id)
+		default:
+			db = db.Where(col("created_at")+" <= ?", c.createdAt)
+		}
+	}
+	return db.Order(col("created_at") + " DESC").Order(col("id") + " DESC").Limit(q.limit + 1)
+}
+
+// matchedBuilds commit / image_tag 条件及 q（commit SHA 前缀或镜像 tag）匹配的构建，用于关联批次与部署
+func (s *SearchService) matchedBuilds(q *searchQuery, withText bool) *gorm.DB {
+	builds := s.db.Model(&model.Build{}).Select("id")
+	if q.req.Commit != "" {
+		builds = builds.Where("commit_sha LIKE ?", escapeLike(q.req.Commit)+"%")
+	}
+	if q.req.ImageTag != "" {
+		builds = builds.Where("image_tag = ?", q.req.ImageTag)
+	}
+	if withText {
+		builds = builds.Where("commit_sha LIKE ? OR image_tag = ?", escapeLike(q.q)+"%", q.q)
+	}
+	return builds
+}
+
+func (s *SearchService) searchBatches(q *searchQuery) ([]*dto.SearchResultItem, error) {
+	db := s.db.Model(&model.Batch{}).
+		Select("id", "batch_number", "project_id", "status", "created_at").
+		Where("release_batches.project_id IN ?", q.projects)
+	if q.req.AppID != nil {
+		db = db.Where("release_batches.id IN (?)", s.db.Model(&model.ReleaseApp{}).Select("batch_id").Where("app_id = ?", *q.req.AppID))
+	}
+	if q.req.Commit != "" || q.req.ImageTag != "" {
+		db = db.Where("release_batches.id IN (?)", s.db.Model(&model.ReleaseApp{}).Select("batch_id").
+			Where("build_id IN (?)", s.matchedBuilds(q, false)))
+	}
+	if q.q != "" {
+		db = db.Where("release_batches.batch_number LIKE ? OR release_batches.id IN (?)", "%"+escapeLike(q.q)+"%",
+			s.db.Model(&model.ReleaseApp{}).Select("batch_id").Where("build_id IN (?)", s.matchedBuilds(q, true)))
+	}
+	var batches []*model.Batch
+	if err := q.page(db, model.BatchTableName, SearchTypeBatch).Find(&batches).Error; err != nil {
+		return nil, err
+	}
+
+	items := make([]*dto.SearchResultItem, 0, len(batches))
+	for _, batch := range batches {
+		items = append(items, &dto.SearchResultItem{
+			Type:        SearchTypeBatch,
+			ID:          batch.ID,
+			ProjectID:   batch.ProjectID,
+			BatchID:     batch.ID,
+			BatchNumber: batch.BatchNumber,
+			Status:      constants.BatchStatusToString(batch.Status),
+			Matched:     matchedFields(q.q, "batch_number", batch.BatchNumber),
+			CreatedAt:   batch.CreatedAt,
+		})
+	}
+	return items, nil
+}
+
+func (s *SearchService) searchBuilds(q *searchQuery) ([]*dto.SearchResultItem, error) {
+	db := s.db.Model(&model.Build{}).
+		Select("id", "app_id", "build_status", "commit_sha", "commit_message", "image_tag", "created_at").
+		Where("builds.app_id IN (?)", s.db.Unscoped().Model(&model.Application{}).Select("id").Where("project_id IN ?", q.projects))
+	if q.req.AppID != nil {
+		db = db.Where("builds.app_id = ?", *q.req.AppID)
+	}
+	if q.req.Commit != "" {
+		db = db.Where("builds.commit_sha LIKE ?", escapeLike(q.req.Commit)+"%")
+	}
+	if q.req.ImageTag != "" {
+		db = db.Where("builds.image_tag = ?", q.req.ImageTag)
+	}
+	if q.q != "" {
+		like := "%" + escapeLike(q.q) + "%"
+		db = db.Where("builds.commit_sha LIKE ? OR builds.image_tag LIKE ? OR builds.commit_message LIKE ?",
+			escapeLike(q.q)+"%", like, like)
+	}
+	var builds []*model.Build
+	if err := q.page(db, model.BuildTableName, SearchTypeBuild).Find(&builds).Error; err != nil {
+		return nil, err
+	}
+
+	appIDs := make([]int64, 0, len(builds))
+	for _, build := range builds {
+		appIDs = append(appIDs, build.AppID)
+	}
+	apps, err := s.searchApps(appIDs)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*dto.SearchResultItem, 0, len(builds))
+	for _, build := range builds {
+		item := &dto.SearchResultItem{
+			Type:          SearchTypeBuild,
+			ID:            build.ID,
+			AppID:         build.AppID,
+			Status:        build.BuildStatus,
+			CommitSHA:     build.CommitSHA,
+			CommitMessage: build.CommitMessage,
+			ImageTag:      build.ImageTag,
+			Matched: matchedFields(q.q, "commit_sha", build.CommitSHA, "image_tag", build.ImageTag,
+				"commit_message", build.CommitMessage),
+			CreatedAt: build.CreatedAt,
+		}
+		if app, ok := apps[build.AppID]; ok {
+			item.AppName = app.Name
+			item.ProjectID = app.ProjectID
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (s *SearchService) searchDeployments(q *searchQuery) ([]*dto.SearchResultItem, error) {
+	db := s.db.Model(&model.Deployment{}).
+		Select("id", "batch_id", "app_id", "release_id", "env", "cluster", "deployment_name", "status",
+			"rollback_build_id", "error_message", "created_at").
+		Where("deployments.app_id IN (?)", s.db.Unscoped().Model(&model.Application{}).Select("id").Where("project_id IN ?", q.projects))
+	if q.req.AppID != nil {
+		db = db.Where("deployments.app_id = ?", *q.req.AppID)
+	}
+	if q.req.Env != "" {
+		db = db.Where("deployments.env = ?", q.req.Env)
+	}
+	// 部署的构建：回滚部署为 rollback_build_id，否则为发布应用的 build_id
+	deployedBuild := func(withText bool) *gorm.DB {
+		return s.db.Where("deployments.rollback_build_id IN (?)", s.matchedBuilds(q, withText)).
+			Or("deployments.rollback_build_id IS NULL AND deployments.release_id IN (?)",
+				s.db.Model(&model.ReleaseApp{}).Select("id").Where("build_id IN (?)", s.matchedBuilds(q, withText)))
+	}
+	if q.req.Commit != "" || q.req.ImageTag != "" {
+		db = db.Where(deployedBuild(false))
+	}
+	if q.q != "" {
+		like := "%" + escapeLike(q.q) + "%"
+		db = db.Where(s.db.Where("deployments.deployment_name LIKE ? OR deployments.error_message LIKE ?", like, like).
+			Or(deployedBuild(true)))
+	}
+	var deps []*model.Deployment
+	if err := q.page(db, "deployments", SearchTypeDeployment).Find(&deps).Error; err != nil {
+		return nil, err
+	}
+	if len(deps) == 0 {
+		return nil, nil
+	}
+
+	// 关联批次号、应用名与部署的构建
+	var batchIDs, appIDs, releaseIDs, buildIDs []int64
+	for _, dep := range deps {
+		batchIDs = append(batchIDs, dep.BatchID)
+		appIDs = append(appIDs, dep.AppID)
+		releaseIDs = append(releaseIDs, dep.ReleaseID)
+		if dep.RollbackBuildID != nil {
+			buildIDs = append(buildIDs, *dep.RollbackBuildID)
+		}
+	}
+	var batches []*model.Batch
+	if err := s.db.Select("id", "batch_number", "project_id").Where("id IN ?", uniqueInt64s(batchIDs)).Find(&batches).Error; err != nil {
+		return nil, err
+	}
+	batchByID := make(map[int64]*model.Batch, len(batches))
+	for _, batch := range batches {
+		batchByID[batch.ID] = batch
+	}
+	var releases []*model.ReleaseApp
+	if err := s.db.Select("id", "build_id").Where("id IN ?", uniqueInt64s(releaseIDs)).Find(&releases).Error; err != nil {
+		return nil, err
+	}
+	releaseBuild := make(map[int64]int64, len(releases))
+	for _, release := range releases {
+		if release.BuildID != nil {
+			releaseBuild[release.ID] = *release.BuildID
+			buildIDs = append(buildIDs, *release.BuildID)
+		}
+	}
+	buildByID := make(map[int64]*model.Build)
+	if ids := uniqueInt64s(buildIDs); len(ids) > 0 {
+		var builds []*model.Build
+		if err := s.db.Select("id", "commit_sha", "commit_message", "image_tag").Where("id IN ?", ids).Find(&builds).Error; err != nil {
+			return nil, err
+		}
+		for _, build := range builds {
+			buildByID[build.ID] = build
+		}
+	}
+	apps, err := s.searchApps(appIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*dto.SearchResultItem, 0, len(deps))
+	for _, dep := range deps {
+		item := &dto.SearchResultItem{
+			Type:           SearchTypeDeployment,
+			ID:             dep.ID,
+			AppID:          dep.AppID,
+			BatchID:        dep.BatchID,
+			Status:         dep.Status,
+			Env:            dep.Env,
+			Cluster:        dep.ClusterName,
+			DeploymentName: dep.DeploymentName,
+			CreatedAt:      dep.CreatedAt,
+		}
+		if batch, ok := batchByID[dep.BatchID]; ok {
+			item.BatchNumber = batch.BatchNumber
+			item.ProjectID = batch.ProjectID
+		}
+		if app, ok := apps[dep.AppID]; ok {
+			item.AppName = app.Name
+		}
+		if dep.ErrorMessage != nil {
+			item.ErrorMessage = *dep.ErrorMessage
+		}
+		buildID := releaseBuild[dep.ReleaseID]
+		if dep.RollbackBuildID != nil {
+			buildID = *dep.RollbackBuildID
+		}
+		if build, ok := buildByID[buildID]; ok {
+			item.CommitSHA = build.CommitSHA
+			item.CommitMessage = build.CommitMessage
+			item.ImageTag = build.ImageTag
+		}
+		item.Matched = matchedFields(q.q, "deployment_name", item.DeploymentName, "error_message", item.ErrorMessage,
+			"commit_sha", item.CommitSHA, "image_tag", item.ImageTag)
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// searchApps 查询应用名与所属项目（含已删除的应用）
+func (s *SearchService) searchApps(appIDs []int64) (map[int64]*model.Application, error) {
+	apps := make(map[int64]*model.Application)
+	ids := uniqueInt64s(appIDs)
+	if len(ids) == 0 {
+		return apps, nil
+	}
+	var list []*model.Application
+	if err := s.db.Unscoped().Select("id", "name", "project_id").Where("id IN ?", ids).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	for _, app := range list {
+		apps[app.ID] = app
+	}
+	return apps, nil
+}
+
+// matchedFields 返回包含 q 的字段名（不区分大小写），fields 为 name, value 交替
+func matchedFields(q string, fields ...string) []string {
+	matched := []string{}
+	if q == "" {
+		return matched
+	}
+	lower := strings.ToLower(q)
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fields[i+1] != "" && strings.Contains(strings.ToLower(fields[i+1]), lower) {
+			matched = append(matched, fields[i])
+		}
+	}
+	return matched
+}
+
+// escapeLike 转义 LIKE 通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"devops-cd/internal/adapter/notification"
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/logger"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"github.com/samber/lo"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SubscriptionService 用户订阅批次/应用，状态变更时由引擎私聊/邮件通知订阅人
+type SubscriptionService struct {
+	db *gorm.DB
+}
+
+func NewSubscriptionService(db *gorm.DB) *SubscriptionService {
+	return &SubscriptionService{db: db}
+}
+
+// Subscribe 订阅批次/应用（需有对象所属项目的查看权限），已订阅时更新通知渠道
+func (s *SubscriptionService) Subscribe(req *dto.CreateSubscriptionRequest, username string, canAccess func(projectID int64) bool) (*dto.SubscriptionResponse, error) {
+	channels := lo.Uniq(lo.Map(req.Channels, func(c string, _ int) string { return strings.TrimSpace(c) }))
+	for _, c := range channels {
+		if !lo.Contains(notification.DirectChannels, c) {
+			return nil, pkgErrors.New(pkgErrors.CodeBadRequest,
+				fmt.Sprintf("不支持的通知渠道: %s（可选: %s）", c, strings.Join(notification.DirectChannels, ", ")))
+		}
+	}
+
+	projectID, name, err := s.target(req.TargetType, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	if !canAccess(projectID) {
+		return nil, pkgErrors.ErrForbidden
+	}
+
+	var sub model.Subscription
+	if err := s.db.Where("username = ? AND target_type = ? AND target_id = ?", username, req.TargetType, req.TargetID).
+		Limit(1).Find(&sub).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询订阅失败", err)
+	}
+	sub.Username = username
+	sub.TargetType = req.TargetType
+	sub.TargetID = req.TargetID
+	sub.ProjectID = projectID
+	sub.Channels = model.StringList(channels)
+	if err := s.db.Save(&sub).Error; err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "保存订阅失败", err)
+	}
+
+	logger.Info("订阅成功",
+		zap.String("username", username),
+		zap.String("target_type", sub.TargetType),
+		zap.Int64("target_id", sub.TargetID))
+	return toSubscriptionResponse(&sub, name), nil
+}
+
+// Unsubscribe 取消订阅（只能取消自己的订阅）
+func (s *SubscriptionService) Unsubscribe(id int64, username string) error {
+	result := s.db.Where("id = ? AND username = ?", id, username).Delete(&model.Subscription{})
+	if result.Error != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "取消订阅失败", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return pkgErrors.New(pkgErrors.CodeNotFound, "订阅不存在")
+	}
+	return nil
+}
+
+// List 当前用户的订阅
+func (s *SubscriptionService) List(req *dto.SubscriptionListRequest, username string) ([]*dto.SubscriptionResponse, int64, error) {
+	q := s.db.Model(&model.Subscription{}).Where("username = ?", username)
+	if req.TargetType != "" {
+		q = q.Where("target_type = ?", req.TargetType)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询订阅失败", err)
+	}
+	var subs []*model.Subscription
+	if err := q.Order("id DESC").Limit(req.PageSize).Offset((req.Page - 1) * req.PageSize).Find(&subs).Error; err != nil {
+		return nil, 0, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询订阅失败", err)
+	}
+
+	names, err := s.targetNames(subs)
+	if err != nil {
+		return nil, 0, err
+	}
+	return lo.Map(subs, func(sub *model.Subscription, _ int) *dto.SubscriptionResponse {
+		return toSubscriptionResponse(sub, names[sub.TargetType][sub.TargetID])
+	}), total, nil
+}
+
+// target 查询订阅对象所属项目与名称
+func (s *SubscriptionService) target(targetType string, targetID int64) (int64, string, error) {
+	switch targetType {
+	case model.SubscriptionTargetBatch:
+		var batch model.Batch
+		if err := s.db.Select("id", "project_id", "batch_number").Limit(1).Find(&batch, targetID).Error; err != nil {
+			return 0, "", pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询批次失败", err)
+		}
+		if batch.ID == 0 {
+			return 0, "", pkgErrors.New(pkgErrors.CodeNotFound, "批次不存在")
+		}
+		return batch.ProjectID, batch.BatchNumber, nil
+	case model.SubscriptionTargetApp:
+		var app model.Application
+		if err := s.db.Select("id", "project_id", "name").Limit(1).Find(&app, targetID).Error; err != nil {
+			return 0, "", pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用失败", err)
+		}
+		if app.ID == 0 {
+			return 0, "", pkgErrors.New(pkgErrors.CodeNotFound, "应用不存在")
+		}
+		return app.ProjectID, app.Name, nil
+	}
+	return 0, "", pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("不支持的订阅类型: %s", targetType))
+}
+
+// targetNames 批量查询订阅对象名称（target_type → id → 名称）
+func (s *SubscriptionService) targetNames(subs []*model.Subscription) (map[string]map[int64]string, error) {
+	names := map[string]map[int64]string{
+		model.SubscriptionTargetBatch: {},
+		model.SubscriptionTargetApp:   {},
+	}
+	var batchIDs, appIDs []int64
+	for _, sub := range subs {
+		switch sub.TargetType {
+		case model.SubscriptionTargetBatch:
+			batchIDs = append(batchIDs, sub.TargetID)
+		case model.SubscriptionTargetApp:
+			appIDs = append(appIDs, sub.TargetID)
+		}
+	}
+	if len(batchIDs) > 0 {
+		var batches []model.Batch
+		if err := s.db.Select("id", "batch_number").Where("id IN ?", batchIDs).Find(&batches).Error; err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询批次失败", err)
+		}
+		for _, b := range batches {
+			names[model.SubscriptionTargetBatch][b.ID] = b.BatchNumber
+		}
+	}
+	if len(appIDs) > 0 {
+		var apps []model.Application
+		if err := s.db.Select("id", "name").Where("id IN ?", appIDs).Find(&apps).Error; err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用失败", err)
+		}
+		for _, a := range apps {
+			names[model.SubscriptionTargetApp][a.ID] = a.Name
+		}
+	}
+	return names, nil
+}
+
+func toSubscriptionResponse(sub *model.Subscription, name string) *dto.SubscriptionResponse {
+	channels := []string(sub.Channels)
+	if channels == nil {
+		channels = []string{}
+	}
+	return &dto.SubscriptionResponse{
+		ID:         sub.ID,
+		TargetType: sub.TargetType,
+		TargetID:   sub.TargetID,
+		TargetName: name,
+		ProjectID:  sub.ProjectID,
+		Channels:   channels,
+		CreatedAt:  sub.CreatedAt.Format(time.RFC3339),
+	}
+}
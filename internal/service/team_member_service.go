@@ -37,32 +37,37 @@ func normalizeRoles(input []string, defaultRole string) model.StringList {
 const defaultTeamMemberRole = string(auth.RoleMember)
 
 type TeamMemberService interface {
-	Add(req *dto.TeamMemberAddRequest) (*dto.TeamMemberResponse, error)
+	// Add 添加成员；角色包含生产环境操作权限时需 canGrantProd(团队所属项目) 通过
+	Add(req *dto.TeamMemberAddRequest, canGrantProd func(projectID int64) bool) (*dto.TeamMemberResponse, error)
 	List(req *dto.TeamMemberListQuery) ([]*dto.TeamMemberResponse, int64, error)
-	UpdateRole(id int64, req *dto.TeamMemberUpdateRoleRequest) (*dto.TeamMemberResponse, error)
+	// UpdateRole 更新成员角色；授予生产环境操作权限的校验同 Add
+	UpdateRole(id int64, req *dto.TeamMemberUpdateRoleRequest, canGrantProd func(projectID int64) bool) (*dto.TeamMemberResponse, error)
 	Remove(id int64) error
 }
 
 type teamMemberService struct {
-	repo     *repository.TeamMemberRepository
-	teamRepo repository.TeamRepository
-	userRepo *repository.UserRepository
+	repo      *repository.TeamMemberRepository
+	teamRepo  repository.TeamRepository
+	userRepo  *repository.UserRepository
+	roleCache *RoleCache
 
 	log *zap.SugaredLogger
 }
 
-func NewTeamMemberService(log *zap.Logger, repo *repository.TeamMemberRepository, teamRepo repository.TeamRepository, userRepo *repository.UserRepository) TeamMemberService {
+func NewTeamMemberService(log *zap.Logger, repo *repository.TeamMemberRepository, teamRepo repository.TeamRepository, userRepo *repository.UserRepository, roleCache *RoleCache) TeamMemberService {
 	return &teamMemberService{
-		repo:     repo,
-		teamRepo: teamRepo,
-		userRepo: userRepo,
+		repo:      repo,
+		teamRepo:  teamRepo,
+		userRepo:  userRepo,
+		roleCache: roleCache,
 
 		log: log.With(zap.String("service", "team_member_service")).Sugar(),
 	}
 }
 
-func (s *teamMemberService) Add(req *dto.TeamMemberAddRequest) (*dto.TeamMemberResponse, error) {
-	if _, err := s.teamRepo.FindByID(req.TeamID); err != nil {
+func (s *teamMemberService) Add(req *dto.TeamMemberAddRequest, canGrantProd func(projectID int64) bool) (*dto.TeamMemberResponse, error) {
+	team, err := s.teamRepo.FindByID(req.TeamID)
+	if err != nil {
 		return nil, err
 	}
 	user, err := s.userRepo.FindByID(req.UserID)
@@ -77,6 +82,9 @@ func (s *teamMemberService) Add(req *dto.TeamMemberAddRequest) (*dto.TeamMemberR
 	}
 
 	roles := normalizeRoles(req.Roles, defaultTeamMemberRole)
+	if err := s.checkProdGrant(team, roles, canGrantProd); err != nil {
+		return nil, err
+	}
 
 	member := &model.TeamMember{
 		TeamID: req.TeamID,
@@ -106,13 +114,21 @@ func (s *teamMemberService) List(req *dto.TeamMemberListQuery) ([]*dto.TeamMembe
 	return responses, total, nil
 }
 
-func (s *teamMemberService) UpdateRole(id int64, req *dto.TeamMemberUpdateRoleRequest) (*dto.TeamMemberResponse, error) {
+func (s *teamMemberService) UpdateRole(id int64, req *dto.TeamMemberUpdateRoleRequest, canGrantProd func(projectID int64) bool) (*dto.TeamMemberResponse, error) {
 	member, err := s.repo.FindByID(id)
 	if err != nil {
 		return nil, err
 	}
+	team, err := s.teamRepo.FindByID(member.TeamID)
+	if err != nil {
+		return nil, err
+	}
 
-	member.Roles = normalizeRoles(req.Roles, defaultTeamMemberRole)
+	roles := normalizeRoles(req.Roles, defaultTeamMemberRole)
+	if err := s.checkProdGrant(team, roles, canGrantProd); err != nil {
+		return nil, err
+	}
+	member.Roles = roles
 
 	if err := s.repo.Update(member); err != nil {
 		return nil, err
@@ -121,6 +137,16 @@ func (s *teamMemberService) UpdateRole(id int64, req *dto.TeamMemberUpdateRoleRe
 	return s.toResponse(member), nil
 }
 
+// checkProdGrant 角色（内置或自定义）包含生产环境操作权限时，授予人需在团队所属项目下拥有该权限，
+// 避免项目成员自行授予 prod_operator
+func (s *teamMemberService) checkProdGrant(team *model.Team, roles []string, canGrantProd func(projectID int64) bool) error {
+	grants := auth.Allow(roles, auth.PermProdOperate) || auth.AllowPermissions(s.roleCache.Permissions(roles), auth.PermProdOperate)
+	if !grants || canGrantProd(team.ProjectID) {
+		return nil
+	}
+	return pkgErrors.New(pkgErrors.CodeForbidden, "授予生产环境操作角色需要该项目的生产环境操作权限")
+}
+
 func (s *teamMemberService) Remove(id int64) error {
 	_, err := s.repo.FindByID(id)
 	if err != nil {
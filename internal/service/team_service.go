@@ -150,6 +150,7 @@ func (s *teamService) toResponse(team *model.Team) *dto.TeamResponse {
 		ProjectID:   team.ProjectID,
 		Description: team.Description,
 		LeaderName:  team.LeaderName,
+		Roles:       []string(team.Roles),
 		CreatedAt:   team.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:   team.UpdatedAt.Format(time.RFC3339),
 	}
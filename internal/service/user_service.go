@@ -3,6 +3,7 @@ package service
 import (
 	"devops-cd/internal/dto"
 	"devops-cd/internal/pkg/auth"
+	"devops-cd/internal/pkg/logger"
 	"devops-cd/internal/repository"
 )
 
@@ -13,10 +14,11 @@ type UserService interface {
 
 type userService struct {
 	userRepo *repository.UserRepository
+	roleRepo *repository.RoleRepository
 }
 
-func NewUserService(userRepo *repository.UserRepository) UserService {
-	return &userService{userRepo: userRepo}
+func NewUserService(userRepo *repository.UserRepository, roleRepo *repository.RoleRepository) UserService {
+	return &userService{userRepo: userRepo, roleRepo: roleRepo}
 }
 
 func (s *userService) Search(req *dto.UserSearchQuery) ([]*dto.UserSimpleResponse, int64, error) {
@@ -49,13 +51,18 @@ func (s *userService) Search(req *dto.UserSearchQuery) ([]*dto.UserSimpleRespons
 }
 
 func (s *userService) ListRoles() []string {
-	// 按固定顺序返回
-	return []string{
-		string(auth.RoleSystemAdmin),
-		string(auth.RoleSystemViewer),
-		string(auth.RoleProjectAdmin),
-		string(auth.RoleProjectViewer),
-		string(auth.RoleTeamAdmin),
-		string(auth.RoleMember),
+	// 内置角色按固定顺序在前，自定义角色按名称排序在后
+	roles := make([]string, 0, len(auth.BuiltinRoles))
+	for _, r := range auth.BuiltinRoles {
+		roles = append(roles, string(r))
 	}
+	custom, err := s.roleRepo.ListAll()
+	if err != nil {
+		logger.Sugar().Warnf("list custom roles error: %v", err)
+		return roles
+	}
+	for _, r := range custom {
+		roles = append(roles, r.Name)
+	}
+	return roles
 }
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"devops-cd/internal/core/deployment"
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"gorm.io/gorm"
+)
+
+// ValuesRenderService values 渲染预览（排查合并后的 values，无需直接查库）
+type ValuesRenderService struct {
+	db *gorm.DB
+}
+
+func NewValuesRenderService(db *gorm.DB) *ValuesRenderService {
+	return &ValuesRenderService{db: db}
+}
+
+// Render 按部署时相同的规则渲染应用在 project/env 下的 values，返回各层内容、合并顺序与最终结果（敏感字段脱敏）
+func (s *ValuesRenderService) Render(ctx context.Context, projectID int64, env string, req *dto.RenderValuesRequest, canAccess func(projectID int64) bool) (*dto.RenderValuesResponse, error) {
+	if !canAccess(projectID) {
+		return nil, pkgErrors.ErrForbidden
+	}
+	var app model.Application
+	if err := s.db.WithContext(ctx).First(&app, req.AppID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgErrors.New(pkgErrors.CodeNotFound, "应用不存在")
+		}
+		return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用失败", err)
+	}
+	if app.ProjectID != projectID {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "应用不属于该项目")
+	}
+
+	build, err := s.renderBuild(ctx, &app, req)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := strings.TrimSpace(req.Cluster)
+	if cluster == "" {
+		var cfg model.AppEnvConfig
+		if err := s.db.WithContext(ctx).Where("app_id = ? AND env = ? AND status = 1", app.ID, env).
+			Order("id").Limit(1).Find(&cfg).Error; err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询应用环境配置失败", err)
+		}
+		if cfg.ID == 0 {
+			return nil, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("应用未配置 %s 环境，请指定 cluster", env))
+		}
+		cluster = cfg.Cluster
+	}
+
+	kind := req.Kind
+	if kind == "" {
+		kind = constants.DeploymentKindApp
+	}
+	dep := &model.Deployment{AppID: app.ID, Env: env, ClusterName: cluster}
+	if req.ReleaseAppID != nil {
+		var rel model.ReleaseApp
+		if err := s.db.WithContext(ctx).Select("id", "app_id").Limit(1).Find(&rel, *req.ReleaseAppID).Error; err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询发布应用失败", err)
+		}
+		if rel.ID == 0 || rel.AppID != app.ID {
+			return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "发布应用不存在或不属于该应用")
+		}
+		dep.ReleaseID = rel.ID
+	}
+	render, err := deployment.RenderValues(ctx, s.db, dep, build, kind)
+	if err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeBadRequest, "渲染 values 失败", err)
+	}
+
+	resp := &dto.RenderValuesResponse{
+		ProjectID:   projectID,
+		AppID:       app.ID,
+		AppName:     app.Name,
+		Env:         env,
+		Cluster:     cluster,
+		Kind:        kind,
+		ImageTag:    build.ImageTag,
+		DriverType:  render.DriverType,
+		Namespace:   render.Namespace,
+		ReleaseName: render.ReleaseName,
+		Layers:      make([]dto.RenderValuesLayer, 0, len(render.Layers)),
+		MergeOrder:  make([]string, 0, len(render.Layers)),
+		Values:      render.Values,
+		Error:       render.Error,
+	}
+	if build.ID > 0 {
+		resp.BuildID = &build.ID
+	}
+	for _, l := range render.Layers {
+		resp.Layers = append(resp.Layers, dto.RenderValuesLayer{
+			Index:  l.Index,
+			Source: l.Source,
+			Layer:  l.Layer,
+			Empty:  l.Empty,
+			Values: l.Values,
+		})
+		switch {
+		case l.Source == deployment.ValuesSourceOverride:
+			resp.MergeOrder = append(resp.MergeOrder, l.Source+":values_override")
+			continue
+		case l.Layer == nil:
+			resp.MergeOrder = append(resp.MergeOrder, l.Source+":image.tag")
+			continue
+		}
+		resp.MergeOrder = append(resp.MergeOrder, fmt.Sprintf("%s[%d]:%s", l.Source, l.Index, l.Layer.Type))
+	}
+	return resp, nil
+}
+
+// renderBuild 渲染使用的构建：build_id 优先；按 image_tag 查找该应用最近的构建，找不到时仅以 tag 渲染模板
+func (s *ValuesRenderService) renderBuild(ctx context.Context, app *model.Application, req *dto.RenderValuesRequest) (*model.Build, error) {
+	var build model.Build
+	switch {
+	case req.BuildID != nil:
+		if err := s.db.WithContext(ctx).First(&build, *req.BuildID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, pkgErrors.New(pkgErrors.CodeNotFound, "构建不存在")
+			}
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询构建失败", err)
+		}
+		if build.AppID != app.ID {
+			return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "构建不属于该应用")
+		}
+		return &build, nil
+	case strings.TrimSpace(req.ImageTag) != "":
+		tag := strings.TrimSpace(req.ImageTag)
+		if err := s.db.WithContext(ctx).Where("app_id = ? AND image_tag = ?", app.ID, tag).
+			Order("id DESC").Limit(1).Find(&build).Error; err != nil {
+			return nil, pkgErrors.Wrap(pkgErrors.CodeDatabaseError, "查询构建失败", err)
+		}
+		if build.ID == 0 {
+			return &model.Build{AppID: app.ID, RepoID: app.RepoID, ImageTag: tag}, nil
+		}
+		return &build, nil
+	default:
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "build_id 与 image_tag 需指定其一")
+	}
+}
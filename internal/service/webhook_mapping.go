@@ -0,0 +1,406 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/pkg/utils"
+
+	"github.com/gin-gonic/gin/binding"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+type webhookFieldKind int
+
+const (
+	webhookFieldString webhookFieldKind = iota
+	webhookFieldInt
+	webhookFieldTime // Unix 秒；兼容毫秒时间戳与 RFC3339 字符串
+	webhookFieldBool
+)
+
+// 字段解析来源
+const (
+	webhookSourcePath     = "path"
+	webhookSourceDefault  = "default"
+	webhookSourceFallback = "fallback"
+	webhookSourceMissing  = "missing"
+)
+
+const webhookAppDefaultPrefix = "apps."
+
+// webhookNotifyFields 可映射的构建通知字段（与 dto.BuildNotifyRequest 的 json 字段一致）
+var webhookNotifyFields = map[string]webhookFieldKind{
+	"repo":                webhookFieldString,
+	"repo_namespace":      webhookFieldString,
+	"repo_owner":          webhookFieldString,
+	"repo_name":           webhookFieldString,
+	"build_number":        webhookFieldInt,
+	"build_status":        webhookFieldString,
+	"build_created":       webhookFieldTime,
+	"build_started":       webhookFieldTime,
+	"build_finished":      webhookFieldTime,
+	"build_link":          webhookFieldString,
+	"build_event":         webhookFieldString,
+	"git_author_name":     webhookFieldString,
+	"git_author_email":    webhookFieldString,
+	"commit_author":       webhookFieldString,
+	"commit_author_name":  webhookFieldString,
+	"commit_author_email": webhookFieldString,
+	"commit_ref":          webhookFieldString,
+	"commit_id":           webhookFieldString,
+	"commit_branch":       webhookFieldString,
+	"commit_before":       webhookFieldString,
+	"commit_after":        webhookFieldString,
+	"commit_message":      webhookFieldString,
+	"commit_link":         webhookFieldString,
+	"image_tag":           webhookFieldString,
+	"image":               webhookFieldString,
+}
+
+// webhookAppFields 可映射的应用字段（与 dto.BuildNotifyApp 的 json 字段一致）
+var webhookAppFields = map[string]webhookFieldKind{
+	"name":          webhookFieldString,
+	"image_tag":     webhookFieldString,
+	"image":         webhookFieldString,
+	"build_success": webhookFieldBool,
+}
+
+// validateWebhookMapping 校验映射配置：字段名合法、JSONPath 可解析
+func validateWebhookMapping(m *model.WebhookMapping) error {
+	for field, path := range m.Fields {
+		if _, ok := webhookNotifyFields[field]; !ok {
+			return fmt.Errorf("fields 不支持的字段: %s", field)
+		}
+		if _, err := parseWebhookPath(path); err != nil {
+			return fmt.Errorf("fields.%s JSONPath 无效: %w", field, err)
+		}
+	}
+	if strings.TrimSpace(m.AppsPath) != "" {
+		if _, err := parseWebhookPath(m.AppsPath); err != nil {
+			return fmt.Errorf("apps_path JSONPath 无效: %w", err)
+		}
+	}
+	for field, path := range m.AppFields {
+		if _, ok := webhookAppFields[field]; !ok {
+			return fmt.Errorf("app_fields 不支持的字段: %s", field)
+		}
+		if _, err := parseWebhookPath(path); err != nil {
+			return fmt.Errorf("app_fields.%s JSONPath 无效: %w", field, err)
+		}
+	}
+	for field := range m.Defaults {
+		if appField, ok := strings.CutPrefix(field, webhookAppDefaultPrefix); ok {
+			if _, ok := webhookAppFields[appField]; !ok {
+				return fmt.Errorf("defaults 不支持的应用字段: %s", field)
+			}
+			continue
+		}
+		if _, ok := webhookNotifyFields[field]; !ok {
+			return fmt.Errorf("defaults 不支持的字段: %s", field)
+		}
+	}
+	for field := range m.ValueMaps {
+		_, notifyOK := webhookNotifyFields[field]
+		appField, isApp := strings.CutPrefix(field, webhookAppDefaultPrefix)
+		_, appOK := webhookAppFields[appField]
+		if !notifyOK && !(isApp && appOK) {
+			return fmt.Errorf("value_maps 不支持的字段: %s", field)
+		}
+	}
+	for _, field := range []string{"name", "image_tag"} {
+		if strings.TrimSpace(m.AppFields[field]) == "" && m.Defaults[webhookAppDefaultPrefix+field] == "" {
+			return fmt.Errorf("app_fields.%s 未配置", field)
+		}
+	}
+	return nil
+}
+
+// mapWebhookPayload 按映射将 payload 转换为构建通知，并校验结果是否满足 /build/notify 的参数要求
+func mapWebhookPayload(m *model.WebhookMapping, payload []byte) *dto.WebhookPreviewResponse {
+	resp := &dto.WebhookPreviewResponse{Fields: make([]dto.WebhookFieldResult, 0)}
+
+	var root interface{}
+	if err := json.Unmarshal(payload, &root); err != nil {
+		resp.Errors = append(resp.Errors, "payload 不是合法的 JSON: "+err.Error())
+		return resp
+	}
+
+	out := make(map[string]interface{})
+	for _, field := range webhookFieldNames(m.Fields, m.Defaults, "", webhookNotifyFields) {
+		res := resolveWebhookField(m, field, field, m.Fields[field], root, webhookNotifyFields[field])
+		resp.Fields = append(resp.Fields, res)
+		if res.Error != "" {
+			resp.Errors = append(resp.Errors, fmt.Sprintf("%s: %s", res.Field, res.Error))
+		}
+		if res.Source != webhookSourceMissing && res.Error == "" {
+			out[field] = res.Value
+		}
+	}
+	resp.Fields = append(resp.Fields, applyWebhookFallbacks(out)...)
+
+	// 应用列表；未映射任何应用字段时不生成，按应用路径映射分发（见 BuildNotifyRequest.Apps）
+	items := []interface{}{root}
+	if strings.TrimSpace(m.AppsPath) == "" && len(webhookFieldNames(m.AppFields, m.Defaults, webhookAppDefaultPrefix, webhookAppFields)) == 0 {
+		items = nil
+	} else if strings.TrimSpace(m.AppsPath) != "" {
+		values, err := evalWebhookPath(m.AppsPath, root)
+		if err != nil {
+			resp.Errors = append(resp.Errors, "apps_path: "+err.Error())
+			values = nil
+		}
+		items = flattenWebhookValues(values)
+	}
+	apps := make([]map[string]interface{}, 0, len(items))
+	for i, item := range items {
+		app := make(map[string]interface{})
+		for _, field := range webhookFieldNames(m.AppFields, m.Defaults, webhookAppDefaultPrefix, webhookAppFields) {
+			name := fmt.Sprintf("apps[%d].%s", i, field)
+			res := resolveWebhookField(m, name, webhookAppDefaultPrefix+field, m.AppFields[field], item, webhookAppFields[field])
+			resp.Fields = append(resp.Fields, res)
+			if res.Error != "" {
+				resp.Errors = append(resp.Errors, fmt.Sprintf("%s: %s", res.Field, res.Error))
+			}
+			if res.Source != webhookSourceMissing && res.Error == "" {
+				app[field] = res.Value
+			}
+		}
+		apps = append(apps, app)
+	}
+	out["apps"] = apps
+
+	// 转换为构建通知并按 /build/notify 的规则校验
+	raw, err := json.Marshal(out)
+	if err != nil {
+		resp.Errors = append(resp.Errors, err.Error())
+		return resp
+	}
+	var req dto.BuildNotifyRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		resp.Errors = append(resp.Errors, utils.FormatValidationError(err))
+		return resp
+	}
+	resp.Request = &req
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		resp.Errors = append(resp.Errors, utils.FormatValidationError(err))
+	}
+	resp.Valid = len(resp.Errors) == 0
+	return resp
+}
+
+// webhookFieldNames 需要解析的字段：映射了 JSONPath 或配置了缺省值的字段（按名称排序）
+func webhookFieldNames(paths, defaults map[string]string, defaultPrefix string, allowed map[string]webhookFieldKind) []string {
+	seen := make(map[string]bool)
+	for field := range paths {
+		seen[field] = true
+	}
+	for key := range defaults {
+		field, ok := strings.CutPrefix(key, defaultPrefix)
+		if !ok || (defaultPrefix == "" && strings.HasPrefix(key, webhookAppDefaultPrefix)) {
+			continue
+		}
+		seen[field] = true
+	}
+	names := make([]string, 0, len(seen))
+	for field := range seen {
+		if _, ok := allowed[field]; ok {
+			names = append(names, field)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveWebhookField 解析单个字段：JSONPath → 缺省值 → 值映射 → 类型转换
+func resolveWebhookField(m *model.WebhookMapping, name, key, path string, data interface{}, kind webhookFieldKind) dto.WebhookFieldResult {
+	res := dto.WebhookFieldResult{Field: name, Path: path, Source: webhookSourceMissing}
+
+	var raw interface{}
+	if strings.TrimSpace(path) != "" {
+		values, err := evalWebhookPath(path, data)
+		if err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		if len(values) > 0 && webhookString(values[0]) != "" {
+			raw = values[0]
+			res.Source = webhookSourcePath
+		}
+	}
+	if raw == nil {
+		def, ok := m.Defaults[key]
+		if !ok {
+			return res
+		}
+		raw = def
+		res.Source = webhookSourceDefault
+	}
+
+	if mapped, ok := m.ValueMaps[key][webhookString(raw)]; ok {
+		raw = mapped
+	}
+
+	value, err := convertWebhookValue(raw, kind)
+	if err != nil {
+		res.Value = raw
+		res.Error = err.Error()
+		return res
+	}
+	res.Value = value
+	return res
+}
+
+// applyWebhookFallbacks 常见 CI 缺失字段的推导：repo_name/repo_namespace 取自 repo，commit_after 取 commit_id，
+// build_created/build_started 缺失时取已有的构建时间
+func applyWebhookFallbacks(out map[string]interface{}) []dto.WebhookFieldResult {
+	var results []dto.WebhookFieldResult
+	fallback := func(field string, value interface{}) {
+		if _, ok := out[field]; ok || value == nil {
+			return
+		}
+		out[field] = value
+		results = append(results, dto.WebhookFieldResult{Field: field, Value: value, Source: webhookSourceFallback})
+	}
+
+	if repo, ok := out["repo"].(string); ok {
+		if ns, name, found := strings.Cut(repo, "/"); found {
+			fallback("repo_namespace", ns)
+			fallback("repo_name", name)
+		}
+	}
+	fallback("commit_after", out["commit_id"])
+	fallback("build_started", firstPresent(out, "build_created", "build_finished"))
+	fallback("build_created", firstPresent(out, "build_started", "build_finished"))
+	fallback("build_finished", firstPresent(out, "build_started", "build_created"))
+	return results
+}
+
+func firstPresent(out map[string]interface{}, fields ...string) interface{} {
+	for _, f := range fields {
+		if v, ok := out[f]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// convertWebhookValue 将 JSONPath 结果转换为构建通知字段类型
+func convertWebhookValue(raw interface{}, kind webhookFieldKind) (interface{}, error) {
+	switch kind {
+	case webhookFieldInt:
+		n, err := webhookInt(raw)
+		if err != nil {
+			return nil, fmt.Errorf("无法转换为整数: %v", raw)
+		}
+		return n, nil
+	case webhookFieldTime:
+		if n, err := webhookInt(raw); err == nil {
+			// 毫秒时间戳（Jenkins timestamp 等）
+			if n > 1e12 {
+				n /= 1000
+			}
+			return n, nil
+		}
+		if s, ok := raw.(string); ok {
+			if t, err := time.Parse(time.RFC3339, strings.TrimSpace(s)); err == nil {
+				return t.Unix(), nil
+			}
+		}
+		return nil, fmt.Errorf("无法转换为时间（支持 Unix 秒/毫秒、RFC3339）: %v", raw)
+	case webhookFieldBool:
+		if b, ok := raw.(bool); ok {
+			return b, nil
+		}
+		b, err := strconv.ParseBool(strings.TrimSpace(webhookString(raw)))
+		if err != nil {
+			return nil, fmt.Errorf("无法转换为布尔值: %v", raw)
+		}
+		return b, nil
+	default:
+		return webhookString(raw), nil
+	}
+}
+
+func webhookInt(raw interface{}) (int64, error) {
+	switch v := raw.(type) {
+	case float64:
+		return int64(v), nil
+	case string:
+		return strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	default:
+		return 0, fmt.Errorf("not a number")
+	}
+}
+
+func webhookString(raw interface{}) string {
+	switch v := raw.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+// parseWebhookPath 解析 JSONPath，兼容 {.a.b}、.a.b、$.a.b 三种写法
+func parseWebhookPath(expr string) (*jsonpath.JSONPath, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("JSONPath 为空")
+	}
+	if !strings.HasPrefix(expr, "{") {
+		expr = strings.TrimPrefix(expr, "$")
+		if !strings.HasPrefix(expr, ".") && !strings.HasPrefix(expr, "[") {
+			expr = "." + expr
+		}
+		expr = "{" + expr + "}"
+	}
+	jp := jsonpath.New("webhook").AllowMissingKeys(true)
+	if err := jp.Parse(expr); err != nil {
+		return nil, err
+	}
+	return jp, nil
+}
+
+// evalWebhookPath 执行 JSONPath，返回所有匹配值
+func evalWebhookPath(expr string, data interface{}) ([]interface{}, error) {
+	jp, err := parseWebhookPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	results, err := jp.FindResults(data)
+	if err != nil {
+		return nil, err
+	}
+	var values []interface{}
+	for _, group := range results {
+		for _, v := range group {
+			if v.IsValid() && v.CanInterface() {
+				values = append(values, v.Interface())
+			}
+		}
+	}
+	return values, nil
+}
+
+// flattenWebhookValues apps_path 既可指向数组本身（{.apps}），也可指向数组元素（{.apps[*]}）
+func flattenWebhookValues(values []interface{}) []interface{} {
+	if len(values) == 1 {
+		if list, ok := values[0].([]interface{}); ok {
+			return list
+		}
+	}
+	return values
+}
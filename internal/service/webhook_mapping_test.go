@@ -0,0 +1,238 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"devops-cd/internal/model"
+)
+
+func TestEvalWebhookPath(t *testing.T) {
+	data := map[string]interface{}{
+		"build": map[string]interface{}{"number": float64(42), "url": "https://ci/job/42"},
+		"apps": []interface{}{
+			map[string]interface{}{"name": "a"},
+			map[string]interface{}{"name": "b"},
+		},
+	}
+	cases := []struct {
+		expr    string
+		want    []interface{}
+		wantErr bool
+	}{
+		{expr: "{.build.number}", want: []interface{}{float64(42)}},
+		{expr: ".build.number", want: []interface{}{float64(42)}},
+		{expr: "$.build.number", want: []interface{}{float64(42)}},
+		{expr: "build.url", want: []interface{}{"https://ci/job/42"}},
+		{expr: "{.apps[*].name}", want: []interface{}{"a", "b"}},
+		{expr: "$.apps[1].name", want: []interface{}{"b"}},
+		{expr: ".build.missing", want: nil},
+		{expr: "  ", wantErr: true},
+		{expr: "{.build[}", wantErr: true},
+	}
+	for _, c := range cases {
+		got, err := evalWebhookPath(c.expr, data)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("evalWebhookPath(%q) 应返回错误", c.expr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("evalWebhookPath(%q): %v", c.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("evalWebhookPath(%q) = %#v, want %#v", c.expr, got, c.want)
+		}
+	}
+}
+
+func TestConvertWebhookValue(t *testing.T) {
+	cases := []struct {
+		raw     interface{}
+		kind    webhookFieldKind
+		want    interface{}
+		wantErr bool
+	}{
+		{raw: float64(12), kind: webhookFieldInt, want: int64(12)},
+		{raw: " 12 ", kind: webhookFieldInt, want: int64(12)},
+		{raw: "x", kind: webhookFieldInt, wantErr: true},
+		{raw: float64(1700000000), kind: webhookFieldTime, want: int64(1700000000)},
+		{raw: float64(1700000000123), kind: webhookFieldTime, want: int64(1700000000)}, // 毫秒
+		{raw: "2023-11-14T22:13:20Z", kind: webhookFieldTime, want: int64(1700000000)},
+		{raw: "yesterday", kind: webhookFieldTime, wantErr: true},
+		{raw: true, kind: webhookFieldBool, want: true},
+		{raw: "false", kind: webhookFieldBool, want: false},
+		{raw: "maybe", kind: webhookFieldBool, wantErr: true},
+		{raw: float64(7), kind: webhookFieldString, want: "7"},
+		{raw: map[string]interface{}{"a": "b"}, kind: webhookFieldString, want: `{"a":"b"}`},
+	}
+	for _, c := range cases {
+		got, err := convertWebhookValue(c.raw, c.kind)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("convertWebhookValue(%#v, %d) 应返回错误", c.raw, c.kind)
+			}
+			continue
+		}
+		if err != nil || got != c.want {
+			t.Errorf("convertWebhookValue(%#v, %d) = %#v, %v, want %#v", c.raw, c.kind, got, err, c.want)
+		}
+	}
+}
+
+// jenkinsMapping Jenkins Notification 插件 payload 的映射
+func jenkinsMapping() model.WebhookMapping {
+	return model.WebhookMapping{
+		Fields: map[string]string{
+			"repo":           "{.build.scm.repo}",
+			"build_number":   "{.build.number}",
+			"build_status":   "{.build.status}",
+			"build_finished": "{.build.timestamp}",
+			"build_link":     "{.build.full_url}",
+			"commit_ref":     "{.build.scm.branch}",
+			"commit_id":      "{.build.scm.commit}",
+		},
+		AppsPath:  "{.build.parameters.apps}",
+		AppFields: map[string]string{"name": ".name", "image_tag": ".tag"},
+		Defaults:  map[string]string{"build_event": "push", "apps.image_tag": "latest"},
+		ValueMaps: map[string]map[string]string{"build_status": {"SUCCESS": "success", "FAILURE": "failure"}},
+	}
+}
+
+const jenkinsPayload = `{
+  "build": {
+    "number": 42,
+    "status": "SUCCESS",
+    "timestamp": 1700000000123,
+    "full_url": "https://jenkins.example.com/job/user-api/42/",
+    "scm": {"repo": "platform/user-api", "branch": "main", "commit": "e8c7e9b"},
+    "parameters": {"apps": [{"name": "user-api", "tag": "v1.2.0"}, {"name": "user-worker"}]}
+  }
+}`
+
+func TestMapWebhookPayload(t *testing.T) {
+	t.Run("Jenkins 映射为合法构建通知", func(t *testing.T) {
+		m := jenkinsMapping()
+		resp := mapWebhookPayload(&m, []byte(jenkinsPayload))
+		if !resp.Valid {
+			t.Fatalf("映射结果不合法: %v", resp.Errors)
+		}
+		req := resp.Request
+		if req.Repo != "platform/user-api" || req.RepoNamespace != "platform" || req.RepoName != "user-api" {
+			t.Errorf("repo 推导错误: %s %s %s", req.Repo, req.RepoNamespace, req.RepoName)
+		}
+		if req.BuildNumber != 42 || req.BuildStatus != "success" || req.BuildEvent != "push" {
+			t.Errorf("构建字段错误: %d %s %s", req.BuildNumber, req.BuildStatus, req.BuildEvent)
+		}
+		// 毫秒时间戳转为秒，created/started 取 finished
+		if req.BuildFinished != 1700000000 || req.BuildStarted != 1700000000 || req.BuildCreated != 1700000000 {
+			t.Errorf("构建时间错误: %d %d %d", req.BuildCreated, req.BuildStarted, req.BuildFinished)
+		}
+		if req.CommitAfter != "e8c7e9b" {
+			t.Errorf("commit_after 应取 commit_id, got %q", req.CommitAfter)
+		}
+		if len(req.Apps) != 2 || req.Apps[0].Name != "user-api" || req.Apps[0].ImageTag != "v1.2.0" ||
+			req.Apps[1].Name != "user-worker" || req.Apps[1].ImageTag != "latest" {
+			t.Errorf("apps 映射错误: %+v", req.Apps)
+		}
+	})
+
+	cases := []struct {
+		name    string
+		mutate  func(m *model.WebhookMapping)
+		payload string
+		wantErr string
+	}{
+		{
+			name:    "payload 不是 JSON",
+			payload: "not json",
+			wantErr: "payload 不是合法的 JSON",
+		},
+		{
+			name:    "未映射的状态值不满足校验",
+			mutate:  func(m *model.WebhookMapping) { m.ValueMaps = nil },
+			payload: jenkinsPayload,
+			wantErr: "'BuildStatus' must be one of",
+		},
+		{
+			name:    "类型转换失败",
+			mutate:  func(m *model.WebhookMapping) { m.Fields["build_number"] = "{.build.status}" },
+			payload: jenkinsPayload,
+			wantErr: "build_number: 无法转换为整数",
+		},
+		{
+			name:    "缺少必填字段",
+			mutate:  func(m *model.WebhookMapping) { delete(m.Fields, "commit_id") },
+			payload: jenkinsPayload,
+			wantErr: "'CommitID' is required",
+		},
+		{
+			name:    "应用缺少名称",
+			mutate:  func(m *model.WebhookMapping) { m.AppFields["name"] = ".app" },
+			payload: jenkinsPayload,
+			wantErr: "'Name' is required",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := jenkinsMapping()
+			if c.mutate != nil {
+				c.mutate(&m)
+			}
+			resp := mapWebhookPayload(&m, []byte(c.payload))
+			if resp.Valid {
+				t.Fatalf("映射结果应不合法: %+v", resp.Request)
+			}
+			if joined := strings.Join(resp.Errors, "; "); !strings.Contains(joined, c.wantErr) {
+				t.Errorf("errors = %s, want 包含 %q", joined, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateWebhookMapping(t *testing.T) {
+	cases := []struct {
+		name    string
+		mutate  func(m *model.WebhookMapping)
+		wantErr string
+	}{
+		{name: "合法"},
+		{name: "未知字段", mutate: func(m *model.WebhookMapping) { m.Fields["branch"] = ".x" }, wantErr: "fields 不支持的字段: branch"},
+		{name: "字段 JSONPath 无效", mutate: func(m *model.WebhookMapping) { m.Fields["repo"] = "{.a[}" }, wantErr: "fields.repo JSONPath 无效"},
+		{name: "apps_path 无效", mutate: func(m *model.WebhookMapping) { m.AppsPath = "{.a[}" }, wantErr: "apps_path JSONPath 无效"},
+		{name: "未知应用字段", mutate: func(m *model.WebhookMapping) { m.AppFields["tag"] = ".t" }, wantErr: "app_fields 不支持的字段: tag"},
+		{name: "未知应用缺省值", mutate: func(m *model.WebhookMapping) { m.Defaults["apps.tag"] = "x" }, wantErr: "defaults 不支持的应用字段: apps.tag"},
+		{name: "未知缺省值", mutate: func(m *model.WebhookMapping) { m.Defaults["branch"] = "x" }, wantErr: "defaults 不支持的字段: branch"},
+		{name: "未知取值映射", mutate: func(m *model.WebhookMapping) { m.ValueMaps["apps.tag"] = nil }, wantErr: "value_maps 不支持的字段: apps.tag"},
+		{name: "image_tag 仅有缺省值", mutate: func(m *model.WebhookMapping) { delete(m.AppFields, "image_tag") }},
+		{
+			name: "未配置 image_tag",
+			mutate: func(m *model.WebhookMapping) {
+				delete(m.AppFields, "image_tag")
+				delete(m.Defaults, "apps.image_tag")
+			},
+			wantErr: "app_fields.image_tag 未配置",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := jenkinsMapping()
+			if c.mutate != nil {
+				c.mutate(&m)
+			}
+			err := validateWebhookMapping(&m)
+			if c.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateWebhookMapping: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Errorf("err = %v, want %q", err, c.wantErr)
+			}
+		})
+	}
+}
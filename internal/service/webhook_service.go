@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"devops-cd/internal/core/webhook"
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/pkg/crypto"
+	"devops-cd/internal/repository"
+	"devops-cd/pkg/constants"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"github.com/samber/lo"
+	"gorm.io/datatypes"
+)
+
+type WebhookService interface {
+	Create(req *dto.CreateWebhookRequest, operator string) (*dto.WebhookResponse, error)
+	Update(id int64, req *dto.UpdateWebhookRequest, operator string) (*dto.WebhookResponse, error)
+	Delete(id int64) error
+	Get(id int64) (*dto.WebhookResponse, error)
+	List(req *dto.WebhookListRequest) ([]*dto.WebhookResponse, int64, error)
+	// Test 同步发送一次 ping 事件并返回投递记录
+	Test(id int64, operator string) (*dto.WebhookDeliveryResponse, error)
+	ListDeliveries(id int64, req *dto.WebhookDeliveryListRequest) ([]*dto.WebhookDeliveryResponse, int64, error)
+	// Redeliver 将投递记录重置为待投递，由引擎下一轮扫描发送
+	Redeliver(id, deliveryID int64) (*dto.WebhookDeliveryResponse, error)
+	// Reencrypt 将存量签名密钥迁移到当前 crypto.provider（在线执行，逐条 CAS 更新）
+	Reencrypt(batchSize int, dryRun bool) (*dto.CredentialReencryptResult, error)
+}
+
+type webhookService struct {
+	repo        *repository.WebhookRepository
+	projectRepo repository.ProjectRepository
+	dispatcher  *webhook.Dispatcher
+}
+
+func NewWebhookService(repo *repository.WebhookRepository, projectRepo repository.ProjectRepository, dispatcher *webhook.Dispatcher) WebhookService {
+	return &webhookService{repo: repo, projectRepo: projectRepo, dispatcher: dispatcher}
+}
+
+func (s *webhookService) Create(req *dto.CreateWebhookRequest, operator string) (*dto.WebhookResponse, error) {
+	w := &model.Webhook{
+		Name:        strings.TrimSpace(req.Name),
+		ProjectID:   req.ProjectID,
+		URL:         strings.TrimSpace(req.URL),
+		Events:      model.StringList(lo.Uniq(req.Events)),
+		Enabled:     true,
+		Description: req.Description,
+		CreatedBy:   operator,
+		UpdatedBy:   operator,
+	}
+	if req.Enabled != nil {
+		w.Enabled = *req.Enabled
+	}
+	if req.Secret != nil {
+		if err := setWebhookSecret(w, *req.Secret); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.check(w); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(w); err != nil {
+		return nil, err
+	}
+	return toWebhookResponse(w), nil
+}
+
+func (s *webhookService) Update(id int64, req *dto.UpdateWebhookRequest, operator string) (*dto.WebhookResponse, error) {
+	w, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != nil {
+		w.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.ProjectID != nil {
+		w.ProjectID = req.ProjectID
+		if *req.ProjectID == 0 {
+			w.ProjectID = nil
+		}
+	}
+	if req.URL != nil {
+		w.URL = strings.TrimSpace(*req.URL)
+	}
+	if req.Events != nil {
+		w.Events = model.StringList(lo.Uniq(req.Events))
+	}
+	if req.Secret != nil {
+		if err := setWebhookSecret(w, *req.Secret); err != nil {
+			return nil, err
+		}
+	}
+	if req.Enabled != nil {
+		w.Enabled = *req.Enabled
+	}
+	if req.Description != nil {
+		w.Description = *req.Description
+	}
+	if err := s.check(w); err != nil {
+		return nil, err
+	}
+	w.UpdatedBy = operator
+	if err := s.repo.Update(w); err != nil {
+		return nil, err
+	}
+	return toWebhookResponse(w), nil
+}
+
+func (s *webhookService) Delete(id int64) error {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *webhookService) Get(id int64) (*dto.WebhookResponse, error) {
+	w, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return toWebhookResponse(w), nil
+}
+
+func (s *webhookService) List(req *dto.WebhookListRequest) ([]*dto.WebhookResponse, int64, error) {
+	list, total, err := s.repo.List(req.ProjectID, req.Page, req.PageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	return lo.Map(list, func(w *model.Webhook, _ int) *dto.WebhookResponse {
+		return toWebhookResponse(w)
+	}), total, nil
+}
+
+func (s *webhookService) Test(id int64, operator string) (*dto.WebhookDeliveryResponse, error) {
+	w, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	var projectID int64
+	if w.ProjectID != nil {
+		projectID = *w.ProjectID
+	}
+	body, err := json.Marshal(webhook.Payload{
+		Event:      constants.WebhookEventPing,
+		ProjectID:  projectID,
+		OccurredAt: time.Now(),
+		Data:       map[string]interface{}{"webhook_id": w.ID, "operator": operator},
+	})
+	if err != nil {
+		return nil, pkgErrors.Wrap(pkgErrors.CodeInternalError, "序列化测试事件失败", err)
+	}
+
+	delivery := &model.WebhookDelivery{
+		WebhookID: w.ID,
+		Event:     constants.WebhookEventPing,
+		Payload:   datatypes.JSON(body),
+		Status:    constants.WebhookDeliveryPending,
+	}
+	if err := s.repo.CreateDelivery(delivery); err != nil {
+		return nil, err
+	}
+	// 测试投递失败不抛错也不重试，结果记录在投递记录中
+	_ = s.dispatcher.DeliverOnce(context.TODO(), w, delivery)
+	return toWebhookDeliveryResponse(delivery), nil
+}
+
+func (s *webhookService) ListDeliveries(id int64, req *dto.WebhookDeliveryListRequest) ([]*dto.WebhookDeliveryResponse, int64, error) {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return nil, 0, err
+	}
+	list, total, err := s.repo.ListDeliveries(id, req.Status, req.Event, req.Page, req.PageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	return lo.Map(list, func(d *model.WebhookDelivery, _ int) *dto.WebhookDeliveryResponse {
+		return toWebhookDeliveryResponse(d)
+	}), total, nil
+}
+
+func (s *webhookService) Redeliver(id, deliveryID int64) (*dto.WebhookDeliveryResponse, error) {
+	d, err := s.repo.GetDelivery(id, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ResetDelivery(d); err != nil {
+		return nil, err
+	}
+	return toWebhookDeliveryResponse(d), nil
+}
+
+// check 校验 URL、事件与项目
+func (s *webhookService) check(w *model.Webhook) error {
+	if w.Name == "" {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, "name 不能为空")
+	}
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("url 必须为 http(s) 地址: %s", w.URL))
+	}
+	if len(w.Events) == 0 {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, "events 不能为空")
+	}
+	for _, e := range w.Events {
+		if e != constants.WebhookEventAll && !lo.Contains(constants.WebhookEvents, e) {
+			return pkgErrors.New(pkgErrors.CodeBadRequest,
+				fmt.Sprintf("不支持的事件: %s（可选: *, %s）", e, strings.Join(constants.WebhookEvents, ", ")))
+		}
+	}
+	if w.ProjectID != nil {
+		if _, err := s.projectRepo.FindByID(*w.ProjectID); err != nil {
+			if err == pkgErrors.ErrRecordNotFound {
+				return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("项目不存在: %d", *w.ProjectID))
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *webhookService) Reencrypt(batchSize int, dryRun bool) (*dto.CredentialReencryptResult, error) {
+	return reencrypt("webhook", batchSize, dryRun, func(target string, afterID int64, limit int) ([]reencryptRecord, error) {
+		list, err := s.repo.ListSecretsForReencrypt(target, afterID, limit)
+		if err != nil {
+			return nil, err
+		}
+		records := make([]reencryptRecord, 0, len(list))
+		for _, w := range list {
+			records = append(records, reencryptRecord{
+				ID: w.ID, Ciphertext: *w.EncryptedSecret, KeyID: w.SecretKeyID,
+				swap: func(enc, keyID string) (bool, error) { return s.repo.SwapSecret(w, enc, keyID) },
+			})
+		}
+		return records, nil
+	})
+}
+
+// setWebhookSecret 信封加密保存签名密钥，空字符串表示取消签名
+func setWebhookSecret(w *model.Webhook, secret string) error {
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		w.EncryptedSecret = nil
+		w.SecretKeyID = ""
+		return nil
+	}
+	enc, keyID, err := crypto.EncryptEnvelope(secret)
+	if err != nil {
+		return pkgErrors.Wrap(pkgErrors.CodeInternalError, "加密签名密钥失败", err)
+	}
+	w.EncryptedSecret = &enc
+	w.SecretKeyID = keyID
+	return nil
+}
+
+func toWebhookResponse(w *model.Webhook) *dto.WebhookResponse {
+	return &dto.WebhookResponse{
+		ID:          w.ID,
+		Name:        w.Name,
+		ProjectID:   w.ProjectID,
+		URL:         w.URL,
+		Events:      []string(w.Events),
+		HasSecret:   w.EncryptedSecret != nil,
+		Enabled:     w.Enabled,
+		Description: w.Description,
+		CreatedBy:   w.CreatedBy,
+		UpdatedBy:   w.UpdatedBy,
+		CreatedAt:   w.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   w.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func toWebhookDeliveryResponse(d *model.WebhookDelivery) *dto.WebhookDeliveryResponse {
+	return &dto.WebhookDeliveryResponse{
+		ID:            d.ID,
+		WebhookID:     d.WebhookID,
+		Event:         d.Event,
+		Payload:       json.RawMessage(d.Payload),
+		Status:        d.Status,
+		Attempts:      d.Attempts,
+		NextAttemptAt: dto.FormatTime(d.NextAttemptAt),
+		ResponseCode:  d.ResponseCode,
+		ResponseBody:  d.ResponseBody,
+		Error:         d.Error,
+		DeliveredAt:   dto.FormatTime(d.DeliveredAt),
+		CreatedAt:     d.CreatedAt.Format(time.RFC3339),
+	}
+}
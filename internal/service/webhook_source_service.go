@@ -0,0 +1,248 @@
+package service
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"devops-cd/internal/dto"
+	"devops-cd/internal/model"
+	"devops-cd/internal/repository"
+	pkgErrors "devops-cd/pkg/responses"
+
+	"gorm.io/datatypes"
+)
+
+var webhookSourceNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// reservedWebhookSourceNames 内置 CI 适配器占用的来源标识（/build/notify/gitlab 等）
+var reservedWebhookSourceNames = map[string]bool{"gitlab": true, "github": true, "jenkins": true, "harbor": true, "trivy": true}
+
+type WebhookSourceService interface {
+	Create(req *dto.CreateWebhookSourceRequest, operator string) (*dto.WebhookSourceResponse, error)
+	Update(id int64, req *dto.UpdateWebhookSourceRequest, operator string) (*dto.WebhookSourceResponse, error)
+	Delete(id int64) error
+	Get(id int64) (*dto.WebhookSourceResponse, error)
+	List(req *dto.WebhookSourceListRequest) ([]*dto.WebhookSourceResponse, int64, error)
+	// Preview 使用已保存的映射转换 payload（payload 为空时使用 sample_payload）
+	Preview(id int64, payload json.RawMessage) (*dto.WebhookPreviewResponse, error)
+	// PreviewMapping 预览未保存的映射
+	PreviewMapping(req *dto.WebhookMappingPreviewRequest) (*dto.WebhookPreviewResponse, error)
+	// Resolve 校验来源与 token，并将外部 CI 的 payload 转换为构建通知
+	Resolve(name, token string, payload []byte) (*dto.BuildNotifyRequest, error)
+}
+
+type webhookSourceService struct {
+	repo *repository.WebhookSourceRepository
+}
+
+func NewWebhookSourceService(repo *repository.WebhookSourceRepository) WebhookSourceService {
+	return &webhookSourceService{repo: repo}
+}
+
+func (s *webhookSourceService) Create(req *dto.CreateWebhookSourceRequest, operator string) (*dto.WebhookSourceResponse, error) {
+	if !webhookSourceNamePattern.MatchString(req.Name) {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "name 仅支持小写字母、数字、- 和 _")
+	}
+	if reservedWebhookSourceNames[req.Name] {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("name 为内置 CI 适配器保留: %s", req.Name))
+	}
+	exists, err := s.repo.ExistsByName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("构建通知来源已存在: %s", req.Name))
+	}
+
+	src := &model.WebhookSource{
+		Name:          req.Name,
+		Description:   req.Description,
+		Enabled:       true,
+		Repos:         model.StringList(req.Repos),
+		Mapping:       req.Mapping,
+		SamplePayload: datatypes.JSON(req.SamplePayload),
+		CreatedBy:     operator,
+		UpdatedBy:     operator,
+	}
+	if req.Enabled != nil {
+		src.Enabled = *req.Enabled
+	}
+	if req.Token != nil && strings.TrimSpace(*req.Token) != "" {
+		token := strings.TrimSpace(*req.Token)
+		src.Token = &token
+	}
+	if err := checkWebhookSource(src); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(src); err != nil {
+		return nil, err
+	}
+	return toWebhookSourceResponse(src), nil
+}
+
+func (s *webhookSourceService) Update(id int64, req *dto.UpdateWebhookSourceRequest, operator string) (*dto.WebhookSourceResponse, error) {
+	src, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Description != nil {
+		src.Description = *req.Description
+	}
+	if req.Enabled != nil {
+		src.Enabled = *req.Enabled
+	}
+	if req.Token != nil {
+		src.Token = nil
+		if token := strings.TrimSpace(*req.Token); token != "" {
+			src.Token = &token
+		}
+	}
+	if req.Repos != nil {
+		src.Repos = model.StringList(*req.Repos)
+	}
+	if req.Mapping != nil {
+		src.Mapping = *req.Mapping
+	}
+	if len(req.SamplePayload) > 0 {
+		src.SamplePayload = datatypes.JSON(req.SamplePayload)
+	}
+	if err := checkWebhookSource(src); err != nil {
+		return nil, err
+	}
+	src.UpdatedBy = operator
+	if err := s.repo.Update(src); err != nil {
+		return nil, err
+	}
+	return toWebhookSourceResponse(src), nil
+}
+
+func (s *webhookSourceService) Delete(id int64) error {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *webhookSourceService) Get(id int64) (*dto.WebhookSourceResponse, error) {
+	src, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return toWebhookSourceResponse(src), nil
+}
+
+func (s *webhookSourceService) List(req *dto.WebhookSourceListRequest) ([]*dto.WebhookSourceResponse, int64, error) {
+	list, total, err := s.repo.List(req.Page, req.PageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	out := make([]*dto.WebhookSourceResponse, 0, len(list))
+	for _, src := range list {
+		out = append(out, toWebhookSourceResponse(src))
+	}
+	return out, total, nil
+}
+
+func (s *webhookSourceService) Preview(id int64, payload json.RawMessage) (*dto.WebhookPreviewResponse, error) {
+	src, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) == 0 {
+		payload = json.RawMessage(src.SamplePayload)
+	}
+	if len(payload) == 0 {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "payload 为空且未配置 sample_payload")
+	}
+	return mapWebhookPayload(&src.Mapping, payload), nil
+}
+
+func (s *webhookSourceService) PreviewMapping(req *dto.WebhookMappingPreviewRequest) (*dto.WebhookPreviewResponse, error) {
+	if err := validateWebhookMapping(&req.Mapping); err != nil {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, err.Error())
+	}
+	return mapWebhookPayload(&req.Mapping, req.Payload), nil
+}
+
+func (s *webhookSourceService) Resolve(name, token string, payload []byte) (*dto.BuildNotifyRequest, error) {
+	src, err := s.repo.GetByName(name)
+	if err != nil {
+		if err == pkgErrors.ErrRecordNotFound {
+			return nil, pkgErrors.New(pkgErrors.CodeNotFound, fmt.Sprintf("构建通知来源不存在: %s", name))
+		}
+		return nil, err
+	}
+	if !src.Enabled {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("构建通知来源已禁用: %s", name))
+	}
+	if src.Token != nil && subtle.ConstantTimeCompare([]byte(*src.Token), []byte(token)) != 1 {
+		return nil, pkgErrors.ErrForbidden
+	}
+
+	result := mapWebhookPayload(&src.Mapping, payload)
+	if !result.Valid {
+		return nil, pkgErrors.New(pkgErrors.CodeBadRequest, "构建通知映射失败: "+strings.Join(result.Errors, "; "))
+	}
+	if !webhookSourceAllowsRepo(src, result.Request.Repo) {
+		return nil, pkgErrors.New(pkgErrors.CodeForbidden, fmt.Sprintf("构建通知来源 %s 不允许代码库: %s", name, result.Request.Repo))
+	}
+	return result.Request, nil
+}
+
+// webhookSourceAllowsRepo 代码库是否在来源允许范围内（未配置 repos 时不限制）
+func webhookSourceAllowsRepo(src *model.WebhookSource, repo string) bool {
+	if len(src.Repos) == 0 {
+		return true
+	}
+	for _, pattern := range src.Repos {
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(repo)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkWebhookSource 校验映射配置与代码库范围；配置了 sample_payload 时要求能映射出合法的构建通知
+func checkWebhookSource(src *model.WebhookSource) error {
+	if err := validateWebhookMapping(&src.Mapping); err != nil {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, err.Error())
+	}
+	for i, pattern := range src.Repos {
+		pattern = strings.TrimSpace(pattern)
+		if _, err := path.Match(pattern, ""); err != nil || !strings.Contains(pattern, "/") {
+			return pkgErrors.New(pkgErrors.CodeBadRequest, fmt.Sprintf("repos[%d] 不合法（应为 namespace/name，支持 * 通配）: %s", i, pattern))
+		}
+		src.Repos[i] = pattern
+	}
+	if len(src.SamplePayload) == 0 {
+		return nil
+	}
+	result := mapWebhookPayload(&src.Mapping, src.SamplePayload)
+	if !result.Valid {
+		return pkgErrors.New(pkgErrors.CodeBadRequest, "sample_payload 映射校验失败: "+strings.Join(result.Errors, "; "))
+	}
+	return nil
+}
+
+func toWebhookSourceResponse(src *model.WebhookSource) *dto.WebhookSourceResponse {
+	return &dto.WebhookSourceResponse{
+		ID:            src.ID,
+		Name:          src.Name,
+		Description:   src.Description,
+		Enabled:       src.Enabled,
+		HasToken:      src.Token != nil,
+		Repos:         src.Repos,
+		NotifyPath:    "/api/v1/build/notify/" + src.Name,
+		Mapping:       src.Mapping,
+		SamplePayload: json.RawMessage(src.SamplePayload),
+		CreatedBy:     src.CreatedBy,
+		UpdatedBy:     src.UpdatedBy,
+		CreatedAt:     src.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:     src.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"devops-cd/internal/model"
+)
+
+func TestWebhookSourceAllowsRepo(t *testing.T) {
+	cases := []struct {
+		repos []string
+		repo  string
+		want  bool
+	}{
+		{repos: nil, repo: "any/repo", want: true},
+		{repos: []string{"platform/user-api"}, repo: "platform/user-api", want: true},
+		{repos: []string{"platform/user-api"}, repo: "Platform/User-API", want: true},
+		{repos: []string{"platform/user-api"}, repo: "platform/user-worker", want: false},
+		{repos: []string{"platform/*"}, repo: "platform/user-worker", want: true},
+		{repos: []string{"platform/*"}, repo: "platform/sub/user-api", want: false},
+		{repos: []string{"platform/*"}, repo: "payments/gateway", want: false},
+		{repos: []string{"platform/*", "payments/gateway"}, repo: "payments/gateway", want: true},
+		{repos: []string{"platform/user-?pi"}, repo: "platform/user-api", want: true},
+		{repos: []string{"platform/*"}, repo: "", want: false},
+	}
+	for _, c := range cases {
+		src := &model.WebhookSource{Repos: model.StringList(c.repos)}
+		if got := webhookSourceAllowsRepo(src, c.repo); got != c.want {
+			t.Errorf("webhookSourceAllowsRepo(%v, %q) = %v, want %v", c.repos, c.repo, got, c.want)
+		}
+	}
+}
+
+func TestCheckWebhookSourceRepos(t *testing.T) {
+	cases := []struct {
+		repos   []string
+		want    []string
+		wantErr bool
+	}{
+		{repos: nil, want: nil},
+		{repos: []string{" platform/* ", "payments/gateway"}, want: []string{"platform/*", "payments/gateway"}},
+		{repos: []string{"platform"}, wantErr: true},
+		{repos: []string{"platform/[a-"}, wantErr: true},
+		{repos: []string{"platform/*", ""}, wantErr: true},
+	}
+	for _, c := range cases {
+		src := &model.WebhookSource{Repos: model.StringList(append([]string(nil), c.repos...)), Mapping: jenkinsMapping()}
+		err := checkWebhookSource(src)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("checkWebhookSource(repos=%q) 应返回错误", c.repos)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("checkWebhookSource(repos=%q): %v", c.repos, err)
+			continue
+		}
+		if len(c.want) > 0 && !reflect.DeepEqual([]string(src.Repos), c.want) {
+			t.Errorf("repos = %q, want %q", src.Repos, c.want)
+		}
+	}
+}
@@ -21,25 +21,34 @@ const (
 
 	BatchStatusCompleted     int8 = 40 // 已完成
 	BatchStatusFinalAccepted int8 = 40
-	BatchStatusCancelled     int8 = 90 // 已取消
+
+	// 回滚：生产部署开始后可将批次内应用回滚到 previous_deployed_tag
+	BatchStatusRollingBack    int8 = 51 // 回滚中
+	BatchStatusRolledBack     int8 = 53 // 已回滚
+	BatchStatusRollbackFailed int8 = 54 // 回滚失败（可再次触发回滚）
+
+	BatchStatusCancelled int8 = 90 // 已取消
 )
 
 // int8 → string
 var batchStatusName = map[int8]string{
-	BatchStatusDraft:         "Draft",
-	BatchStatusSealed:        "Sealed",
-	BatchStatusPreWaiting:    "PreWaiting",
-	BatchStatusPreDeploying:  "PreDeploying",
-	BatchStatusPreDeployed:   "PreDeployed",
-	BatchStatusPreFailed:     "PreFailed",
-	BatchStatusPreAccepted:   "PreAccepted",
-	BatchStatusProdWaiting:   "ProdWaiting",
-	BatchStatusProdDeploying: "ProdDeploying",
-	BatchStatusProdDeployed:  "ProdDeployed",
-	BatchStatusProdFailed:    "ProdFailed",
-	BatchStatusProdAccepted:  "ProdAccepted",
-	BatchStatusCompleted:     "Completed",
-	BatchStatusCancelled:     "Cancelled",
+	BatchStatusDraft:          "Draft",
+	BatchStatusSealed:         "Sealed",
+	BatchStatusPreWaiting:     "PreWaiting",
+	BatchStatusPreDeploying:   "PreDeploying",
+	BatchStatusPreDeployed:    "PreDeployed",
+	BatchStatusPreFailed:      "PreFailed",
+	BatchStatusPreAccepted:    "PreAccepted",
+	BatchStatusProdWaiting:    "ProdWaiting",
+	BatchStatusProdDeploying:  "ProdDeploying",
+	BatchStatusProdDeployed:   "ProdDeployed",
+	BatchStatusProdFailed:     "ProdFailed",
+	BatchStatusProdAccepted:   "ProdAccepted",
+	BatchStatusCompleted:      "Completed",
+	BatchStatusRollingBack:    "RollingBack",
+	BatchStatusRolledBack:     "RolledBack",
+	BatchStatusRollbackFailed: "RollbackFailed",
+	BatchStatusCancelled:      "Cancelled",
 }
 
 // BatchStatusToString int8 → string
@@ -62,4 +71,30 @@ const (
 	BatchActionAcceptProd = "accept_prod"
 
 	BatchActionComplete = "complete"
+
+	// 暂停/恢复：批次状态不变，引擎暂停期间不推进批次、发布应用与部署
+	BatchActionPause  = "pause"
+	BatchActionResume = "resume"
+)
+
+// 批次超时处理（core.deploy.batch_timeout_action）
+const (
+	BatchTimeoutActionNotify = "notify" // 仅通知
+	BatchTimeoutActionCancel = "cancel" // 通知并自动取消批次
+
+	BatchTimeoutOperator = "timeout" // 自动取消的操作人
+)
+
+// BatchEventTrigger 批次状态变更的触发方式（batch_events.trigger）
+const (
+	BatchEventTriggerManual    = "manual"    // 用户通过 API 操作
+	BatchEventTriggerScheduled = "scheduled" // 定时部署触发
+	BatchEventTriggerAuto      = "auto"      // 引擎自动推进
+)
+
+// EnginePauseScope 核心引擎暂停范围
+const (
+	EnginePauseScopeProject = "project" // 暂停项目下所有批次
+	EnginePauseScopeBatch   = "batch"   // 暂停单个批次
+	EnginePauseScopeCluster = "cluster" // 暂停集群上的所有部署（scope_id 为集群ID）
 )
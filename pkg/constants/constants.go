@@ -2,6 +2,8 @@ package constants
 
 const (
 	BuildStatusSuccess = "success"
+
+	BuildEventRegistry = "registry" // 镜像仓库推送通知（Harbor）创建的构建
 )
 
 // ApprovalStatus 审批状态（独立于部署流程）
@@ -14,8 +16,10 @@ const (
 
 // 认证类型
 const (
-	AuthTypeLDAP  = "ldap"
-	AuthTypeLocal = "local"
+	AuthTypeLDAP     = "ldap"
+	AuthTypeLocal    = "local"
+	AuthTypeOIDC     = "oidc"
+	AuthTypeAPIToken = "api_token" // 集成方 API Token（只读）
 )
 
 // 状态
@@ -59,4 +63,63 @@ const (
 const (
 	HeaderAuthorization = "Authorization"
 	HeaderBearerPrefix  = "Bearer "
+
+	// APITokenPrefix API Token 明文前缀，AuthMiddleware 据此区分 API Token 与用户 JWT
+	APITokenPrefix = "dcd_"
+)
+
+// 公告级别
+const (
+	AnnouncementLevelInfo    = "info"
+	AnnouncementLevelWarning = "warning"
+	AnnouncementLevelFreeze  = "freeze" // 发布冻结通知
+)
+
+// 数据一致性检查项
+const (
+	ConsistencyOrphanReleaseApps   = "orphan_release_apps"   // release_app 所属批次已删除
+	ConsistencyOrphanDeployments   = "orphan_deployments"    // deployment 关联的 release_app 不存在
+	ConsistencyDanglingDeployedTag = "dangling_deployed_tag" // applications.deployed_tag 对应的构建不存在
+	ConsistencyReleaseStatus       = "release_status"        // release_app 状态与 deployment 状态不一致
+)
+
+// 代码库清单（devops-cd.yaml）同步状态
+const (
+	ManifestStatusNone    = "none"    // 仓库中无清单文件
+	ManifestStatusSynced  = "synced"  // 已按清单同步
+	ManifestStatusInvalid = "invalid" // 清单解析/校验失败
+	ManifestStatusError   = "error"   // 读取或同步过程出错
+	ManifestStatusSkipped = "skipped" // 代码库未归属项目，跳过同步
+
+	ManifestStatusDiscovered = "discovered" // 无清单文件，命中仓库源发现规则（按代码库创建应用）
+)
+
+// 构建来源校验结果（tag/commit 是否真实存在于声明的代码库/分支）
+const (
+	BuildProvenanceVerified   = "verified"   // 校验通过
+	BuildProvenanceMismatch   = "mismatch"   // 不一致（tag/commit/分支不存在或不匹配），不会进入批次
+	BuildProvenanceUnverified = "unverified" // 无法校验（未找到仓库源、接口异常等），按正常构建处理
+)
+
+// 构建来源校验模式（repo.build_provenance）
+const (
+	BuildProvenanceModeOff    = "off"    // 不校验（默认）
+	BuildProvenanceModeFlag   = "flag"   // 校验不一致时记录构建但标记为 mismatch
+	BuildProvenanceModeReject = "reject" // 校验不一致时拒绝写入构建记录
+)
+
+// 发布故障级别（sev1 最严重）
+const (
+	IncidentSeveritySev1 = "sev1"
+	IncidentSeveritySev2 = "sev2"
+	IncidentSeveritySev3 = "sev3"
+	IncidentSeveritySev4 = "sev4"
+)
+
+// 清单漂移动作
+const (
+	ManifestDriftCreate   = "create"   // 平台缺失，按清单创建
+	ManifestDriftUpdate   = "update"   // 平台与清单不一致，按清单更新
+	ManifestDriftOrphan   = "orphan"   // 平台存在但清单未声明（仅报告，不删除）
+	ManifestDriftConflict = "conflict" // 同项目同名应用属于其他代码库（仅报告，不修改）
 )
@@ -0,0 +1,12 @@
+package constants
+
+// 异步任务状态
+const (
+	JobStatusPending   = "pending"   // 待执行/等待重试
+	JobStatusRunning   = "running"   // 已被 worker 领取
+	JobStatusSucceeded = "succeeded" // 执行成功
+	JobStatusDead      = "dead"      // 超过最大重试次数（死信），可通过管理接口重新入队
+)
+
+// JobActiveStatuses 未结束的任务状态
+var JobActiveStatuses = []string{JobStatusPending, JobStatusRunning}
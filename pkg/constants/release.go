@@ -16,6 +16,35 @@ const (
 	ReleaseAppStatusProdDeployed   int8 = 33 // Prod 均部署完成
 	ReleaseAppStatusProdFailed     int8 = 34
 	ReleaseAppStatusProdAccepted   int8 = 35
+
+	ReleaseAppStatusPreOnlyCompleted int8 = 40 // pre_only 应用 Pre 验收后直接完成，不进入 Prod
+
+	ReleaseAppStatusRollbackCanTrigger int8 = 51 // 回滚可以触发（目标版本已切换为 previous_deployed_tag）
+	ReleaseAppStatusRollbackTriggered  int8 = 52 // 回滚 Deployment 已创建
+	ReleaseAppStatusRolledBack         int8 = 53 // 回滚完成
+	ReleaseAppStatusRollbackFailed     int8 = 54
+)
+
+// BatchRollbackStatus 回滚记录状态
+//   - recorded: 人工补录（复盘数据），未经平台执行
+//   - running/success/failed: 平台执行的回滚
+const (
+	BatchRollbackStatusRecorded = "recorded"
+	BatchRollbackStatusRunning  = "running"
+	BatchRollbackStatusSuccess  = "success"
+	BatchRollbackStatusFailed   = "failed"
+)
+
+// ReleaseAppAction 发布应用手动部署动作
+const (
+	ReleaseAppActionManualTriggerPre  = "manual_trigger_pre"
+	ReleaseAppActionManualTriggerProd = "manual_trigger_prod"
+)
+
+// ReleaseAppRolloutAction 生产灰度动作
+const (
+	ReleaseAppRolloutActionPromote = "promote" // 放行下一阶段
+	ReleaseAppRolloutActionAbort   = "abort"   // 中止后续阶段
 )
 
 func Range10(status int8) (start, end int8) {
@@ -30,4 +59,98 @@ const (
 	DeploymentStatusRunning = "running"
 	DeploymentStatusSuccess = "success"
 	DeploymentStatusFailed  = "failed"
+
+	// DeploymentStatusVerifyFailed workload 已就绪，但部署后验证（HTTP 探测 / Prometheus 指标）在验证窗口内未通过
+	DeploymentStatusVerifyFailed = "verify_failed"
+)
+
+// IsDeploymentFailed 是否为失败终态（failed / verify_failed）
+func IsDeploymentFailed(status string) bool {
+	return status == DeploymentStatusFailed || status == DeploymentStatusVerifyFailed
+}
+
+// DeploymentEvent Deployment 时间线事件类型
+const (
+	DeploymentEventStatusChanged = "status_changed" // 状态变更
+	DeploymentEventChartRendered = "chart_rendered" // chart / 清单渲染完成（values 已合并）
+	DeploymentEventDeployStarted = "deploy_started" // 开始 helm install/upgrade、清单 apply 或 gitops 提交
+	DeploymentEventReadiness     = "readiness"      // 就绪检查原因变化
+	DeploymentEventVerification  = "verification"   // 部署后验证结果变化
+	DeploymentEventAutoRollback  = "auto_rollback"  // 生产就绪/验证失败后自动创建的回滚部署
+	DeploymentEventAdhoc         = "adhoc"          // 临时部署创建（批次外手动部署）
+	DeploymentEventSlow          = "slow"           // 运行时长超过历史 P95 的 slow_factor 倍（可能卡住）
+)
+
+// AdhocDeploymentStatus 临时部署状态：Deployment 全部成功为 success，任一失败为 failed
+const (
+	AdhocDeploymentStatusRunning = "running"
+	AdhocDeploymentStatusSuccess = "success"
+	AdhocDeploymentStatusFailed  = "failed"
+)
+
+// AppDecommissionStatus 应用下线状态：release 全部卸载（或无需卸载）后归档构建并删除应用为 success，任一卸载失败为 failed
+const (
+	AppDecommissionStatusRunning = "running"
+	AppDecommissionStatusSuccess = "success"
+	AppDecommissionStatusFailed  = "failed"
+)
+
+// DecommissionTargetStatus 应用下线时单个 release 的卸载状态
+const (
+	DecommissionTargetPending     = "pending"
+	DecommissionTargetUninstalled = "uninstalled"
+	DecommissionTargetNotFound    = "not_found" // 集群中已不存在该 release
+	DecommissionTargetSkipped     = "skipped"   // 非 helm driver 部署，需手动清理
+	DecommissionTargetFailed      = "failed"
+)
+
+// DeploymentKind 部署类型
+//   - app: app chart（main 阶段）
+//   - config: config chart（pre 阶段），项目环境启用 config_chart 时与 app 独立发布，app 等待其成功后再部署
+const (
+	DeploymentKindApp    = "app"
+	DeploymentKindConfig = "config"
+)
+
+// ConfigChartDrift config chart 版本核对结果
+const (
+	ConfigChartDriftOK      = "ok"      // 集群中 config chart 版本与期望一致
+	ConfigChartDriftDrift   = "drift"   // 版本不一致
+	ConfigChartDriftMissing = "missing" // 集群中不存在 config chart release
+	ConfigChartDriftUnknown = "unknown" // 无法核对（集群不可达、模板解析失败等）
+)
+
+// ReleaseDriftStatus 配置漂移检测结果：部署时记录的 release 快照与集群中当前 release / 资源对比
+const (
+	ReleaseDriftStatusOK      = "ok"      // 无漂移
+	ReleaseDriftStatusDrift   = "drift"   // 存在漂移
+	ReleaseDriftStatusMissing = "missing" // 集群中不存在该 release
+	ReleaseDriftStatusError   = "error"   // 无法检测（集群不可达等）
+)
+
+// ReleaseDriftFinding 漂移项类型
+const (
+	ReleaseDriftFindingRevision = "revision" // release revision 与部署时不一致（平台外 helm upgrade/rollback）
+	ReleaseDriftFindingValues   = "values"   // release values 与部署时不一致
+	ReleaseDriftFindingManifest = "manifest" // release manifest 与部署时不一致
+	ReleaseDriftFindingLive     = "live"     // 集群中资源与 release manifest 不一致（kubectl edit/scale 等）
+)
+
+// DeploymentDiffStatus prod 部署前 manifest diff 计算状态
+const (
+	DeploymentDiffStatusReady  = "ready"
+	DeploymentDiffStatusFailed = "failed"
+)
+
+// DeploymentDryRun prod 部署前 server-side dry-run apply 结果
+const (
+	DeploymentDryRunPassed = "passed"
+	DeploymentDryRunFailed = "failed"
+)
+
+// ManifestChange 资源变更类型
+const (
+	ManifestChangeAdded   = "added"
+	ManifestChangeRemoved = "removed"
+	ManifestChangeChanged = "changed"
 )
@@ -0,0 +1,41 @@
+package constants
+
+// Webhook 订阅事件
+const (
+	WebhookEventAll = "*"
+
+	WebhookEventBatchSealed       = "batch.sealed"        // 批次封板
+	WebhookEventBatchPreDeployed  = "batch.pre_deployed"  // 预发布部署完成
+	WebhookEventBatchProdStarted  = "batch.prod_started"  // 生产部署开始
+	WebhookEventBatchProdDeployed = "batch.prod_deployed" // 生产部署完成
+	WebhookEventBatchCompleted    = "batch.completed"     // 批次完成
+	WebhookEventBatchCancelled    = "batch.cancelled"     // 批次取消
+	WebhookEventBatchRolledBack   = "batch.rolled_back"   // 批次回滚完成
+	WebhookEventBatchFailed       = "batch.failed"        // 预发布/生产/回滚失败
+
+	WebhookEventDeploySuccess = "deploy.success" // 应用部署成功（预发布/生产/回滚）
+	WebhookEventDeployFailed  = "deploy.failed"  // 应用部署失败（预发布/生产/回滚）
+
+	WebhookEventPing = "ping" // 测试投递
+)
+
+// WebhookEvents 可订阅的事件
+var WebhookEvents = []string{
+	WebhookEventBatchSealed,
+	WebhookEventBatchPreDeployed,
+	WebhookEventBatchProdStarted,
+	WebhookEventBatchProdDeployed,
+	WebhookEventBatchCompleted,
+	WebhookEventBatchCancelled,
+	WebhookEventBatchRolledBack,
+	WebhookEventBatchFailed,
+	WebhookEventDeploySuccess,
+	WebhookEventDeployFailed,
+}
+
+// Webhook 投递状态
+const (
+	WebhookDeliveryPending = "pending" // 待投递/等待重试
+	WebhookDeliverySuccess = "success"
+	WebhookDeliveryFailed  = "failed" // 超过最大重试次数
+)
@@ -33,35 +33,32 @@ func SuccessWithMessage(c *gin.Context, message string, data interface{}) {
 // Error 错误响应
 func Error(c *gin.Context, err error) {
 	if appErr, ok := err.(*AppError); ok {
-		// 统一返回HTTP 200，业务错误码在response.code中
-		c.JSON(200, Response{
-			Code:    appErr.Code,
-			Message: appErr.Message,
-		})
+		// v1 统一返回HTTP 200，业务错误码在response.code中
+		writeError(c, appErr.Code, appErr.Message, "", nil)
 		return
 	}
 
-	// 未知错误也返回HTTP 200
-	c.JSON(200, Response{
-		Code:    CodeInternalError,
-		Message: err.Error(),
-	})
+	// 未知错误 v1 也返回HTTP 200
+	writeError(c, CodeInternalError, err.Error(), "", nil)
 }
 
 // ErrorWithCode 自定义错误响应
 func ErrorWithCode(c *gin.Context, code int, message string) {
-	// 统一返回HTTP 200，业务错误码在response.code中
-	c.JSON(200, Response{
-		Code:    code,
-		Message: message,
-	})
+	writeError(c, code, message, "", nil)
 }
 
 // ErrorWithDetail 带详细信息的错误响应
 func ErrorWithDetail(c *gin.Context, code int, message, detail string) {
-	c.JSON(200, Response{
-		Code:    code,
-		Message: message,
-		Detail:  detail,
-	})
+	writeError(c, code, message, detail, nil)
+}
+
+// ErrorWithData 带附加数据的错误响应（如冲突明细）
+//
+// v1 沿用历史结构：HTTP 状态码与 code 均为 status；v2 与其它错误一致
+func ErrorWithData(c *gin.Context, status int, message string, data interface{}) {
+	if APIVersion(c) < APIV2 {
+		c.JSON(status, Response{Code: status, Message: message, Data: data})
+		return
+	}
+	writeError(c, status, message, "", data)
 }
@@ -0,0 +1,76 @@
+package responses
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// API 版本
+const (
+	APIV1 = 1
+	APIV2 = 2
+
+	LatestAPIVersion = APIV2
+
+	// APIVersionKey gin.Context 中协商后的 API 版本
+	APIVersionKey = "api_version"
+)
+
+// APIVersion 当前请求协商后的 API 版本，未协商时视为 v1
+func APIVersion(c *gin.Context) int {
+	if v := c.GetInt(APIVersionKey); v > 0 {
+		return v
+	}
+	return APIV1
+}
+
+// writeError 按 API 版本输出错误
+//   - v1：保持历史行为（HTTP 200，业务码原样返回，可能是 HTTP 状态码）
+//   - v2：业务码统一为 7 位错误码，HTTP 状态码与业务码一致
+func writeError(c *gin.Context, code int, message, detail string, data interface{}) {
+	if APIVersion(c) < APIV2 {
+		c.JSON(http.StatusOK, Response{Code: code, Message: message, Detail: detail, Data: data})
+		return
+	}
+	code = normalizeCode(code)
+	c.JSON(httpStatus(code), Response{Code: code, Message: message, Detail: detail, Data: data})
+}
+
+// normalizeCode v1 中部分接口直接以 HTTP 状态码作为业务码，v2 统一转换为 7 位错误码
+func normalizeCode(code int) int {
+	if code >= 1000 {
+		return code
+	}
+	switch code {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	default:
+		return CodeInternalError
+	}
+}
+
+// httpStatus 7 位错误码对应的 HTTP 状态码
+func httpStatus(code int) int {
+	switch code {
+	case CodeConflict:
+		return http.StatusConflict
+	case CodeAuthError: // 历史错误码归在 5xxxxxx 段，语义上属于客户端错误
+		return http.StatusUnauthorized
+	case CodeValidationError:
+		return http.StatusUnprocessableEntity
+	}
+	status := code / 10000
+	if status < 400 || status > 599 || http.StatusText(status) == "" {
+		return http.StatusInternalServerError
+	}
+	return status
+}
@@ -0,0 +1,102 @@
+-- DevOps CD 工具 - 初始表结构与模型对齐
+-- 版本: v5.1
+-- 数据库: MySQL 8.0+
+--
+-- 说明:
+--   - 001-005 为已发布的初始脚本，保持原样；与当前模型不一致的列名、类型与缺失的列在此前向迁移
+--   - 文件名排在 005 之后、006 之前：006 起的脚本依赖这里的列名（如 repo_sources、sealed_at、temp_depends_on）
+--   - 已执行过 001-005 的库按顺序执行本脚本即可，无需重建
+
+
+-- =====================================================
+-- 1. users / teams
+-- =====================================================
+ALTER TABLE `users`
+  ADD COLUMN `system_roles` JSON DEFAULT NULL COMMENT '系统级角色列表' AFTER `phone`;
+
+ALTER TABLE `teams`
+  ADD COLUMN `project_id` BIGINT NOT NULL COMMENT '所属项目ID' AFTER `name`,
+  ADD INDEX `idx_project_id` (`project_id`);
+
+
+-- =====================================================
+-- 2. repositories：project 更名为 namespace，增加所属项目
+-- =====================================================
+ALTER TABLE `repositories` RENAME COLUMN `project` TO `namespace`;
+ALTER TABLE `repositories` RENAME INDEX `idx_project_name` TO `idx_namespace_name`;
+ALTER TABLE `repositories`
+  MODIFY COLUMN `namespace` VARCHAR(100) NOT NULL COMMENT 'user/org 如(my_org)';
+ALTER TABLE `repositories`
+  ADD COLUMN `project_id` BIGINT DEFAULT NULL COMMENT '所属项目ID' AFTER `language`,
+  ADD INDEX `idx_project_id` (`project_id`);
+
+
+-- =====================================================
+-- 3. applications：project_id 改为项目主键，增加配置级依赖
+-- =====================================================
+ALTER TABLE `applications`
+  MODIFY COLUMN `project_id` BIGINT NOT NULL COMMENT '所属项目ID';
+ALTER TABLE `applications`
+  ADD COLUMN `default_depends_on` JSON DEFAULT NULL COMMENT '配置级依赖（应用ID数组）' AFTER `deployed_tag`;
+
+
+-- =====================================================
+-- 4. builds：构建时间由秒级时间戳改为 DATETIME
+-- 说明: 先写入新列再替换旧列，存量数据按 FROM_UNIXTIME 转换
+-- =====================================================
+ALTER TABLE `builds`
+  ADD COLUMN `build_created_at`  DATETIME NULL COMMENT '构建创建时间',
+  ADD COLUMN `build_started_at`  DATETIME NULL COMMENT '构建开始时间',
+  ADD COLUMN `build_finished_at` DATETIME NULL COMMENT '构建完成时间';
+
+UPDATE `builds` SET
+  `build_created_at`  = FROM_UNIXTIME(`build_created`),
+  `build_started_at`  = FROM_UNIXTIME(`build_started`),
+  `build_finished_at` = FROM_UNIXTIME(`build_finished`);
+
+ALTER TABLE `builds`
+  DROP COLUMN `build_created`,
+  DROP COLUMN `build_started`,
+  DROP COLUMN `build_finished`;
+ALTER TABLE `builds` RENAME COLUMN `build_created_at` TO `build_created`;
+ALTER TABLE `builds` RENAME COLUMN `build_started_at` TO `build_started`;
+ALTER TABLE `builds` RENAME COLUMN `build_finished_at` TO `build_finished`;
+ALTER TABLE `builds`
+  MODIFY COLUMN `build_created`  DATETIME NOT NULL COMMENT '构建创建时间' AFTER `commit_author`,
+  MODIFY COLUMN `build_started`  DATETIME NOT NULL COMMENT '构建开始时间' AFTER `build_created`,
+  MODIFY COLUMN `build_finished` DATETIME NOT NULL COMMENT '构建完成时间' AFTER `build_started`;
+
+
+-- =====================================================
+-- 5. release_batches：阶段时间列更名，增加各阶段操作人
+-- =====================================================
+ALTER TABLE `release_batches` RENAME COLUMN `tagged_at` TO `sealed_at`;
+ALTER TABLE `release_batches` RENAME COLUMN `pre_deploy_started_at` TO `pre_started_at`;
+ALTER TABLE `release_batches` RENAME COLUMN `pre_deploy_finished_at` TO `pre_finished_at`;
+ALTER TABLE `release_batches` RENAME COLUMN `prod_deploy_started_at` TO `prod_started_at`;
+ALTER TABLE `release_batches` RENAME COLUMN `prod_deploy_finished_at` TO `prod_finished_at`;
+ALTER TABLE `release_batches`
+  ADD COLUMN `sealed_by`         VARCHAR(50) DEFAULT NULL COMMENT '封板人' AFTER `status`,
+  ADD COLUMN `pre_triggered_by`  VARCHAR(50) DEFAULT NULL COMMENT '预发布触发人' AFTER `sealed_at`,
+  ADD COLUMN `prod_triggered_by` VARCHAR(50) DEFAULT NULL COMMENT '生产部署触发人' AFTER `pre_finished_at`;
+
+
+-- =====================================================
+-- 6. release_apps：failed_reason 更名为 reason，增加批次内临时依赖
+-- =====================================================
+ALTER TABLE `release_apps` RENAME COLUMN `failed_reason` TO `reason`;
+ALTER TABLE `release_apps`
+  ADD COLUMN `temp_depends_on` JSON DEFAULT NULL COMMENT '批次内临时依赖（应用ID数组）' AFTER `reason`;
+
+
+-- =====================================================
+-- 7. repo_sync_sources 更名为 repo_sources
+-- =====================================================
+ALTER TABLE `repo_sync_sources` RENAME TO `repo_sources`;
+
+
+-- =====================================================
+-- 8. deployments：记录被哪个部署取代
+-- =====================================================
+ALTER TABLE `deployments`
+  ADD COLUMN `superseded_by` BIGINT DEFAULT NULL COMMENT '被哪个部署取代' AFTER `max_retry_count`;
@@ -0,0 +1,28 @@
+-- DevOps CD 工具 - 公告表
+-- 版本: v6.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. 全局公告表 (announcements)
+-- 用途: 平台维护、发布冻结等全局通知，生效项用于 UI banner 及批次创建响应
+-- =====================================================
+CREATE TABLE IF NOT EXISTS `announcements` (
+  `id`         BIGINT       NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `level`      VARCHAR(16)  NOT NULL DEFAULT 'info' COMMENT '级别: info/warning/freeze',
+  `title`      VARCHAR(200) NOT NULL COMMENT '标题',
+  `content`    TEXT                  DEFAULT NULL COMMENT '内容',
+  `enabled`    TINYINT(1)   NOT NULL DEFAULT 1 COMMENT '是否启用',
+  `starts_at`  TIMESTAMP    NULL     DEFAULT NULL COMMENT '生效开始时间（为空不限制）',
+  `ends_at`    TIMESTAMP    NULL     DEFAULT NULL COMMENT '生效结束时间（为空不限制）',
+  `created_by` VARCHAR(50)           DEFAULT NULL COMMENT '创建人',
+  `updated_by` VARCHAR(50)           DEFAULT NULL COMMENT '更新人',
+  `created_at` TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  `deleted_at` TIMESTAMP    NULL     DEFAULT NULL COMMENT '软删除时间',
+
+  PRIMARY KEY (`id`),
+  INDEX `idx_enabled_window` (`enabled`, `starts_at`, `ends_at`),
+  INDEX `idx_deleted_at` (`deleted_at`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4 COMMENT ='全局公告表';
@@ -0,0 +1,14 @@
+-- DevOps CD 工具 - 凭据信封加密
+-- 版本: v7.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. config_credentials 增加 key_id
+-- 说明:
+--   - key_id 为空: 旧格式（crypto.aes_key 直接 AES-GCM 加密）
+--   - key_id 非空: 信封加密，格式 "<provider>:<key>"（local / vault:<key> / aws_kms:<key> / gcp_kms:<key>）
+--   - 切换 crypto.provider 后执行 `go run ./cmd/reencrypt` 在线迁移存量数据
+-- =====================================================
+ALTER TABLE `config_credentials`
+  ADD COLUMN `key_id` VARCHAR(255) NOT NULL DEFAULT '' COMMENT '加密所用 KEK（为空表示旧格式）' AFTER `encrypted_data`;
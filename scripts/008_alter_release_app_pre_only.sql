@@ -0,0 +1,13 @@
+-- DevOps CD 工具 - 仅预发布验证的发布应用
+-- 版本: v8.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. release_apps 增加 pre_only
+-- 说明:
+--   - pre_only=1 的应用只在预发布环境验证，Pre 验收后状态置为 40(PreOnlyCompleted)
+--   - 不参与生产部署、生产依赖检查，也不回写 applications.deployed_tag
+-- =====================================================
+ALTER TABLE `release_apps`
+  ADD COLUMN `pre_only` TINYINT(1) NOT NULL DEFAULT 0 COMMENT '仅预发布验证（不发布生产）' AFTER `skip_pre_env`;
@@ -0,0 +1,22 @@
+-- DevOps CD 工具 - 代码库声明式应用配置（devops-cd.yaml）
+-- 版本: v9.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. repositories 增加清单同步结果
+-- 说明:
+--   - 代码库同步任务读取仓库默认分支根目录下的 devops-cd.yaml，按清单创建/更新应用及环境集群配置
+--   - manifest_status: none/synced/invalid/error/skipped
+--   - manifest_report: 最近一次同步报告（含漂移明细）
+-- =====================================================
+ALTER TABLE `repositories`
+  ADD COLUMN `manifest_status` VARCHAR(20) NULL COMMENT '清单同步状态' AFTER `team_id`,
+  ADD COLUMN `manifest_report` JSON NULL COMMENT '最近一次清单同步报告' AFTER `manifest_status`,
+  ADD COLUMN `manifest_synced_at` DATETIME NULL COMMENT '最近一次清单同步时间' AFTER `manifest_report`;
+
+-- =====================================================
+-- 2. app_env_configs.config_data 约定
+-- 说明:
+--   - {"values": [ValuesLayer...]}，部署 app_chart 时追加在项目 values 层之后
+-- =====================================================
@@ -0,0 +1,34 @@
+-- DevOps CD 工具 - 批次部署影响统计
+-- 版本: v10.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. 批次部署影响表 (batch_app_impacts)
+-- 用途: 记录批次内每个应用在各环境集群部署前后的副本数与资源声明
+-- 设计:
+--   - 粒度: batch+app+env+cluster
+--   - before: main 阶段首次执行前采集（重试/切换版本不覆盖）
+--   - after: 批次完成后采集
+--   - before/after 为 JSON: {workloads, replicas, cpu_request_milli, cpu_limit_milli, memory_request_bytes, memory_limit_bytes}
+-- =====================================================
+CREATE TABLE `batch_app_impacts` (
+  `id`                  bigint      NOT NULL AUTO_INCREMENT,
+  `batch_id`            bigint      NOT NULL,
+  `app_id`              bigint      NOT NULL,
+  `env`                 varchar(20) NOT NULL COMMENT 'pre 或 prod',
+  `cluster`             varchar(63) NOT NULL COMMENT '集群名称',
+  `namespace`           varchar(63) NOT NULL COMMENT 'K8s 命名空间',
+  `release_name`        varchar(63) NOT NULL COMMENT 'helm release 名称',
+  `before`              json                 DEFAULT NULL COMMENT '部署前资源声明',
+  `before_collected_at` timestamp   NULL     DEFAULT NULL,
+  `after`               json                 DEFAULT NULL COMMENT '批次完成后资源声明',
+  `after_collected_at`  timestamp   NULL     DEFAULT NULL,
+  `error_message`       text,
+  `created_at`          timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`          timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_batch_app_env_cluster` (`batch_id`, `app_id`, `env`, `cluster`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='批次部署影响表';
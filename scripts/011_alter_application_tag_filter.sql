@@ -0,0 +1,14 @@
+-- DevOps CD 工具 - 应用构建过滤规则
+-- 版本: v11.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. applications 增加 tag_filter
+-- 说明:
+--   - JSON: {include_tags, exclude_tags, include_branches, exclude_branches}，均为正则数组
+--   - include_* 为空表示不限制；exclude_* 任一匹配即排除
+--   - 不满足规则的构建仍会记录，但不更新草稿批次，也不出现在候选版本列表中
+-- =====================================================
+ALTER TABLE `applications`
+  ADD COLUMN `tag_filter` JSON NULL COMMENT '构建过滤规则' AFTER `default_depends_on`;
@@ -0,0 +1,14 @@
+-- DevOps CD 工具 - 多 region 部署协调
+-- 版本: v12.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. applications 增加 region_rollout
+-- 说明:
+--   - JSON: {enabled, order, stagger_seconds}
+--   - 开启后同一环境的集群按 clusters.region 分组逐个 region 部署，region 顺序由 order 决定（未列出的按名称排在其后）
+--   - 前一 region 全部成功并等待 stagger_seconds 后开始下一 region；前一 region 有失败则后续 region 暂停
+-- =====================================================
+ALTER TABLE `applications`
+  ADD COLUMN `region_rollout` JSON NULL COMMENT '多 region 部署协调' AFTER `tag_filter`;
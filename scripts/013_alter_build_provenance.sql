@@ -0,0 +1,16 @@
+-- DevOps CD 工具 - 构建来源校验
+-- 版本: v13.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. builds 增加来源校验结果
+-- 说明:
+--   - repo.build_provenance=flag/reject 时，构建通知会通过仓库源 API 核对 tag/commit/分支
+--   - provenance_status: verified / mismatch / unverified，未校验为 NULL
+--   - mismatch 的构建不会进入批次，也不会出现在候选版本列表中（reject 模式下不写入）
+-- =====================================================
+ALTER TABLE `builds`
+  ADD COLUMN `provenance_status` VARCHAR(20) NULL COMMENT '来源校验结果: verified/mismatch/unverified' AFTER `app_build_success`,
+  ADD COLUMN `provenance_message` VARCHAR(500) NULL COMMENT '来源校验说明' AFTER `provenance_status`,
+  ADD INDEX `idx_provenance_status` (`provenance_status`);
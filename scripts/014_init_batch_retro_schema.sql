@@ -0,0 +1,60 @@
+-- DevOps CD 工具 - 批次复盘数据（发布后故障/回滚）
+-- 版本: v14.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. 批次故障记录表 (batch_incidents)
+-- 用途: 记录已完成批次发布后的故障，用于变更失败率 / MTTR 统计
+-- 设计:
+--   - project_id 冗余批次所属项目，便于按项目统计
+--   - severity: sev1~sev4（sev1 最严重）
+--   - app_ids: 相关应用 ID 数组（需在批次内）
+-- =====================================================
+CREATE TABLE `batch_incidents` (
+  `id`               bigint       NOT NULL AUTO_INCREMENT,
+  `batch_id`         bigint       NOT NULL,
+  `project_id`       bigint       NOT NULL,
+  `severity`         varchar(10)  NOT NULL COMMENT 'sev1~sev4',
+  `title`            varchar(200) NOT NULL,
+  `description`      text,
+  `started_at`       timestamp    NOT NULL COMMENT '故障开始时间',
+  `duration_minutes` int          NOT NULL DEFAULT 0 COMMENT '故障持续时长（分钟）',
+  `app_ids`          json                  DEFAULT NULL COMMENT '相关应用 ID',
+  `created_by`       varchar(50)           DEFAULT NULL,
+  `created_at`       timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`       timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_batch_id` (`batch_id`),
+  KEY `idx_project_id` (`project_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='批次发布后故障记录';
+
+
+-- =====================================================
+-- 2. 批次回滚记录表 (batch_rollbacks)
+-- 用途: 记录已完成批次发布后执行的回滚
+-- 设计:
+--   - from_tag/to_tag 未填写时分别取 release_apps.target_tag / previous_deployed_tag
+-- =====================================================
+CREATE TABLE `batch_rollbacks` (
+  `id`             bigint       NOT NULL AUTO_INCREMENT,
+  `batch_id`       bigint       NOT NULL,
+  `project_id`     bigint       NOT NULL,
+  `app_id`         bigint       NOT NULL,
+  `env`            varchar(20)  NOT NULL COMMENT 'pre 或 prod',
+  `from_tag`       varchar(100)          DEFAULT NULL COMMENT '回滚前版本',
+  `to_tag`         varchar(100)          DEFAULT NULL COMMENT '回滚后版本',
+  `reason`         text,
+  `rolled_back_at` timestamp    NOT NULL COMMENT '回滚时间',
+  `created_by`     varchar(50)           DEFAULT NULL,
+  `created_at`     timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`     timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_batch_id` (`batch_id`),
+  KEY `idx_project_id` (`project_id`),
+  KEY `idx_app_id` (`app_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='批次发布后回滚记录';
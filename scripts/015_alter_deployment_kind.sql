@@ -0,0 +1,17 @@
+-- DevOps CD 工具 - config chart 独立部署
+-- 版本: v15.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. deployments 增加部署类型与 chart 版本
+-- 说明:
+--   - kind: app(app chart) / config(config chart)；启用 config_chart 时每个集群各一条 config 记录
+--   - app 记录等待同 release/env/cluster 的 config 记录成功后再部署
+--   - config 记录可通过 POST /deployment/:id/redeploy 单独重新部署
+--   - chart_version: 部署时按 chart_version_template 解析出的版本，用于 config chart 版本核对
+-- =====================================================
+ALTER TABLE `deployments`
+  ADD COLUMN `kind` VARCHAR(20) NOT NULL DEFAULT 'app' COMMENT '部署类型: app/config' AFTER `release_id`,
+  ADD COLUMN `chart_version` VARCHAR(100) NULL COMMENT '部署的 chart 版本' AFTER `driver_type`,
+  ADD INDEX `idx_release_env_cluster_kind` (`release_id`, `env`, `cluster`, `kind`);
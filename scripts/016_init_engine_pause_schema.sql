@@ -0,0 +1,27 @@
+-- DevOps CD 工具 - 核心引擎按项目/批次暂停
+-- 版本: v16.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. 引擎暂停记录表 (engine_pauses)
+-- 用途: 事故处理期间暂停指定项目/批次的自动推进，不影响其他批次
+-- 设计:
+--   - scope: project / batch；同一范围同时只允许一条生效记录（由服务层校验）
+--   - resumed_at 为空表示暂停生效中；恢复后保留记录用于审计
+-- =====================================================
+CREATE TABLE `engine_pauses` (
+  `id`         bigint       NOT NULL AUTO_INCREMENT,
+  `scope`      varchar(20)  NOT NULL COMMENT 'project/batch',
+  `scope_id`   bigint       NOT NULL COMMENT '项目ID或批次ID',
+  `reason`     varchar(500)          DEFAULT NULL,
+  `paused_by`  varchar(50)           DEFAULT NULL,
+  `resumed_by` varchar(50)           DEFAULT NULL,
+  `resumed_at` timestamp    NULL     DEFAULT NULL,
+  `created_at` timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_scope` (`scope`, `scope_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='核心引擎暂停记录';
@@ -0,0 +1,30 @@
+-- DevOps CD 工具 - 通用 CI 构建通知来源（字段映射）
+-- 版本: v17.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. 构建通知来源表 (webhook_sources)
+-- 用途: Jenkins、TeamCity 等 CI 通过 POST /api/v1/build/notify/:name 推送构建结果，无需改代码
+-- 设计:
+--   - mapping: JSONPath 字段映射（fields / apps_path / app_fields / defaults / value_maps）
+--   - sample_payload: 测试 payload，保存时校验能映射出合法的构建通知
+--   - token: 可选，非空时校验请求头 X-Webhook-Token
+-- =====================================================
+CREATE TABLE `webhook_sources` (
+  `id`             bigint       NOT NULL AUTO_INCREMENT,
+  `name`           varchar(50)  NOT NULL COMMENT '来源标识（URL 路径）',
+  `description`    varchar(500)          DEFAULT NULL,
+  `enabled`        tinyint(1)   NOT NULL DEFAULT 1,
+  `token`          varchar(200)          DEFAULT NULL COMMENT '请求头 X-Webhook-Token',
+  `mapping`        json                  DEFAULT NULL COMMENT '字段映射配置',
+  `sample_payload` json                  DEFAULT NULL COMMENT '测试 payload',
+  `created_by`     varchar(50)           DEFAULT NULL,
+  `updated_by`     varchar(50)           DEFAULT NULL,
+  `created_at`     timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`     timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_name` (`name`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='通用 CI 构建通知来源';
@@ -0,0 +1,36 @@
+-- DevOps CD 工具 - prod 部署前 manifest diff
+-- 版本: v18.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. 部署 diff 表 (deployment_diffs)
+-- 用途: prod 环境 main 阶段执行前，以 dry-run 渲染 app chart 并与集群中当前 release revision 对比
+-- 设计:
+--   - 粒度: deployment（重试时重新计算并覆盖）
+--   - changes 为 JSON: [{kind, namespace, name, action(added/removed/changed), protected}]
+--   - 开启 core.deploy.diff_ack_protected 且涉及 PDB/PVC/CRD 时 requires_ack=1，
+--     确认（acked_digest = digest）后才执行部署；diff 变化后需重新确认
+-- =====================================================
+CREATE TABLE `deployment_diffs` (
+  `id`            bigint      NOT NULL AUTO_INCREMENT,
+  `deployment_id` bigint      NOT NULL,
+  `namespace`     varchar(63) NOT NULL COMMENT 'K8s 命名空间',
+  `release_name`  varchar(63) NOT NULL COMMENT 'helm release 名称',
+  `base_revision` int         NOT NULL DEFAULT 0 COMMENT '对比的 release revision，0 表示首次安装',
+  `status`        varchar(20) NOT NULL COMMENT 'ready/failed',
+  `digest`        varchar(64)          DEFAULT NULL COMMENT 'diff 内容摘要',
+  `changes`       json                 DEFAULT NULL COMMENT '资源变更列表',
+  `diff`          longtext COMMENT 'unified diff（不含 hooks）',
+  `requires_ack`  tinyint(1)  NOT NULL DEFAULT 0,
+  `acked_digest`  varchar(64)          DEFAULT NULL,
+  `acked_by`      varchar(50)          DEFAULT NULL,
+  `acked_at`      timestamp   NULL     DEFAULT NULL,
+  `error_message` text,
+  `created_at`    timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`    timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_deployment_id` (`deployment_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='prod 部署 manifest diff';
@@ -0,0 +1,20 @@
+-- DevOps CD 工具 - 批次/应用回滚执行
+-- 版本: v19.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. batch_rollbacks 增加平台执行回滚的字段
+-- 说明:
+--   - POST /batch/rollback、/release_app/rollback 将应用回滚到 release_apps.previous_deployed_tag，
+--     经现有 Deployment/Helm 流程重新部署生产环境，每次触发写入一条记录
+--   - release_id: 执行回滚的发布应用，人工补录的记录为空
+--   - status: recorded(人工补录) / running / success / failed
+--   - 批次状态新增 51 回滚中 / 53 已回滚 / 54 回滚失败；发布应用状态新增 51~54（含义同批次）
+-- =====================================================
+ALTER TABLE `batch_rollbacks`
+  ADD COLUMN `release_id` bigint NULL COMMENT '执行回滚的发布应用ID' AFTER `app_id`,
+  ADD COLUMN `status` varchar(20) NOT NULL DEFAULT 'recorded' COMMENT 'recorded/running/success/failed' AFTER `created_by`,
+  ADD COLUMN `finished_at` timestamp NULL DEFAULT NULL COMMENT '回滚结束时间' AFTER `status`,
+  ADD COLUMN `error_message` text COMMENT '回滚失败原因' AFTER `finished_at`,
+  ADD INDEX `idx_release_id` (`release_id`);
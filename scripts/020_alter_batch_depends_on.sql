@@ -0,0 +1,16 @@
+-- DevOps CD 工具 - 批次间依赖（前置批次）
+-- 版本: v20.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. release_batches 增加前置批次字段
+-- 说明:
+--   - depends_on_batch_id: 前置批次（如基础设施批次先于应用批次），创建/未封板时可修改，传 0 移除
+--   - 前置批次达到已完成(40)后，本批次才能开始生产部署（批次状态机 TriggerProdDeploy 校验）
+--   - 前置批次不存在/未完成/已取消时，批次详情与状态接口返回 blocked_reason
+--   - 不允许依赖自身或形成循环依赖
+-- =====================================================
+ALTER TABLE `release_batches`
+  ADD COLUMN `depends_on_batch_id` bigint NULL COMMENT '前置批次ID' AFTER `release_notes`,
+  ADD INDEX `idx_depends_on_batch_id` (`depends_on_batch_id`);
@@ -0,0 +1,35 @@
+-- DevOps CD 工具 - 生产灰度/分阶段部署
+-- 版本: v21.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. 部署策略配置（无表结构变更）
+-- 说明:
+--   - 配置在 prod app_env_configs.config_data.strategy:
+--       {"strategy": {"type": "canary", "canary_percent": 20}}  金丝雀：按集群名排序选取首批集群（至少 1 个）
+--       {"strategy": {"type": "staged", "stage": 2}}            分阶段：按集群 stage 逐阶段部署，未设置视为 1
+--   - 同一应用的 prod 集群需使用相同 type，未配置或 type=all 表示所有集群同时部署
+--   - 每个阶段完成后暂停，POST /release_app/rollout（action=promote/abort）放行下一阶段或中止后续阶段
+--   - 回滚不分阶段，所有集群同时部署
+-- =====================================================
+
+
+-- =====================================================
+-- 2. deployments 增加灰度阶段
+-- 说明:
+--   - rollout_stage: Deployment 所属阶段（从 1 开始），大于发布应用当前阶段时保持 pending
+-- =====================================================
+ALTER TABLE `deployments`
+  ADD COLUMN `rollout_stage` int NOT NULL DEFAULT 1 COMMENT '灰度阶段' AFTER `superseded_by`;
+
+
+-- =====================================================
+-- 3. release_apps 增加灰度进度
+-- 说明:
+--   - rollout_stage: 当前已放行的阶段，触发生产部署时置为 1，promote 后加 1
+--   - rollout_stages: 总阶段数，<=1 表示不分阶段
+-- =====================================================
+ALTER TABLE `release_apps`
+  ADD COLUMN `rollout_stage` int NOT NULL DEFAULT 0 COMMENT '当前已放行的灰度阶段' AFTER `temp_depends_on`,
+  ADD COLUMN `rollout_stages` int NOT NULL DEFAULT 0 COMMENT '灰度总阶段数' AFTER `rollout_stage`;
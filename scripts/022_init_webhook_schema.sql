@@ -0,0 +1,62 @@
+-- DevOps CD 工具 - 出站 Webhook 事件订阅
+-- 版本: v22.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. 订阅表 (webhooks)
+-- 用途: 批次封板、部署成功/失败等事件发生后，引擎向注册的 URL POST 签名 JSON
+-- 设计:
+--   - project_id: 为空表示订阅所有项目
+--   - events: 事件列表（JSON 数组），包含 "*" 表示全部事件
+--   - encrypted_secret/secret_key_id: 签名密钥信封加密存储，
+--     签名 X-DevOps-CD-Signature = sha256=hex(HMAC-SHA256(secret, timestamp + "." + body))
+-- =====================================================
+CREATE TABLE `webhooks` (
+  `id`               bigint       NOT NULL AUTO_INCREMENT,
+  `name`             varchar(100) NOT NULL,
+  `project_id`       bigint                DEFAULT NULL COMMENT '为空表示所有项目',
+  `url`              varchar(500) NOT NULL,
+  `events`           json                  DEFAULT NULL COMMENT '订阅事件列表',
+  `enabled`          tinyint(1)   NOT NULL DEFAULT 1,
+  `description`      varchar(500)          DEFAULT NULL,
+  `encrypted_secret` text                  DEFAULT NULL COMMENT '签名密钥（加密）',
+  `secret_key_id`    varchar(64)           DEFAULT NULL COMMENT '加密密钥ID',
+  `created_by`       varchar(50)           DEFAULT NULL,
+  `updated_by`       varchar(50)           DEFAULT NULL,
+  `created_at`       timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`       timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_project_id` (`project_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='出站 Webhook 订阅';
+
+
+-- =====================================================
+-- 2. 投递记录表 (webhook_deliveries)
+-- 设计:
+--   - 每个 (订阅, 事件) 一条记录，重试时原地更新 attempts/next_attempt_at
+--   - status: pending（待投递/等待重试）/ success / failed（超过最大次数）
+--   - 失败按 30s·2^(n-1) 退避，上限 1h；response_body 截断保存
+-- =====================================================
+CREATE TABLE `webhook_deliveries` (
+  `id`              bigint      NOT NULL AUTO_INCREMENT,
+  `webhook_id`      bigint      NOT NULL,
+  `event`           varchar(50) NOT NULL,
+  `payload`         json                 DEFAULT NULL,
+  `status`          varchar(20) NOT NULL,
+  `attempts`        int         NOT NULL DEFAULT 0,
+  `next_attempt_at` timestamp   NULL     DEFAULT NULL,
+  `response_code`   int                  DEFAULT NULL,
+  `response_body`   text                 DEFAULT NULL,
+  `error`           text                 DEFAULT NULL,
+  `delivered_at`    timestamp   NULL     DEFAULT NULL,
+  `created_at`      timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`      timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_webhook_id` (`webhook_id`),
+  KEY `idx_status_next_attempt` (`status`, `next_attempt_at`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='出站 Webhook 投递记录';
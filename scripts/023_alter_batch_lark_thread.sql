@@ -0,0 +1,15 @@
+-- DevOps CD 工具 - 批次 Lark 话题
+-- 版本: v23.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. release_batches 增加 Lark 话题根消息
+-- 说明:
+--   - core.notification.provider=lark_thread 时使用 Lark 应用机器人发送通知
+--   - 批次首条通知作为话题根消息发送到 lark_chat_id，message_id 记录在 lark_thread_id
+--   - 之后的批次状态、应用部署成功/失败通知均以话题回复（reply_in_thread）发送
+--   - 根消息被删除/撤回导致回复失败时重新创建话题并更新该字段
+-- =====================================================
+ALTER TABLE `release_batches`
+  ADD COLUMN `lark_thread_id` varchar(100) NULL COMMENT 'Lark 话题根消息ID' AFTER `depends_on_batch_id`;
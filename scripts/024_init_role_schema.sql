@@ -0,0 +1,53 @@
+-- DevOps CD 工具 - 自定义角色与权限绑定
+-- 版本: v24.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. 角色表 (roles)
+-- 用途: 管理员无需改代码即可定义角色（如 release_manager、viewer）
+-- 设计:
+--   - 内置角色（system_admin、project_admin、team_member 等）仍定义在代码中，自定义角色不能与之重名
+--   - 角色按 name 分配到 users.system_roles / team_members.roles / teams.roles，仍被分配时不允许删除
+-- =====================================================
+CREATE TABLE `roles` (
+  `id`           bigint       NOT NULL AUTO_INCREMENT,
+  `name`         varchar(50)  NOT NULL COMMENT '角色标识',
+  `display_name` varchar(100)          DEFAULT NULL,
+  `description`  varchar(500)          DEFAULT NULL,
+  `created_by`   varchar(50)           DEFAULT NULL,
+  `updated_by`   varchar(50)           DEFAULT NULL,
+  `created_at`   timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`   timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_name` (`name`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='自定义角色';
+
+
+-- =====================================================
+-- 2. 角色-权限绑定表 (role_permissions)
+-- 设计:
+--   - permission 与代码中的权限点一致（如 batch:create、env:prod:operate），支持通配符（batch:*、*:view）
+--   - 更新角色权限时整体替换
+-- =====================================================
+CREATE TABLE `role_permissions` (
+  `id`         bigint       NOT NULL AUTO_INCREMENT,
+  `role_id`    bigint       NOT NULL,
+  `permission` varchar(100) NOT NULL,
+  `created_at` timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_role_permission` (`role_id`, `permission`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='角色权限绑定';
+
+
+-- =====================================================
+-- 3. teams 增加团队角色
+-- 说明:
+--   - roles: 团队所有成员额外拥有的角色（内置或自定义），与成员自身的 team_members.roles 合并生效
+-- =====================================================
+ALTER TABLE `teams`
+  ADD COLUMN `roles` json DEFAULT NULL COMMENT '团队角色' AFTER `leader_name`;
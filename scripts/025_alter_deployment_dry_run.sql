@@ -0,0 +1,17 @@
+-- DevOps CD 工具 - 部署前 server-side dry-run
+-- 版本: v25.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. deployments 增加 server dry-run 结果
+-- 说明:
+--   - prod 环境 app chart 在 helm install/upgrade 前，先渲染 manifest 并逐个资源以 dryRun=All 做 server-side apply
+--   - 经过完整准入链（mutating/validating webhook、策略引擎）与字段校验，提前发现 webhook 拒绝、不可变字段冲突
+--   - 未通过时 deployment 直接 failed，error_message 为 apiserver/webhook 返回的原因
+--   - 集群中尚无对应 API 的资源（如同 chart 内新增 CRD 的实例）跳过并记录在 dry_run_message
+-- =====================================================
+ALTER TABLE `deployments`
+  ADD COLUMN `dry_run_status`  varchar(20) NULL COMMENT 'server dry-run 结果: passed/failed' AFTER `error_message`,
+  ADD COLUMN `dry_run_message` text COMMENT 'server dry-run 结果说明/拒绝原因' AFTER `dry_run_status`,
+  ADD COLUMN `dry_run_at`      timestamp NULL DEFAULT NULL COMMENT 'server dry-run 执行时间' AFTER `dry_run_message`;
@@ -0,0 +1,20 @@
+-- DevOps CD 工具 - 批次定时部署
+-- 版本: v26.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. release_batches 增加定时部署时间
+-- 说明:
+--   - PUT /api/v1/batch/:id/schedule 设置定时开始预发布(pre)/生产部署(prod)，DELETE 取消
+--   - 调度任务每 30 秒检查到期的定时，先按计划时间 CAS 清空（多实例只触发一次），
+--     再以设置人身份触发 start_pre_deploy / start_prod_deploy
+--   - 触发失败（批次状态不满足、审批未通过等）只记录日志，不再重试
+-- =====================================================
+ALTER TABLE `release_batches`
+  ADD COLUMN `scheduled_pre_at`  timestamp NULL DEFAULT NULL COMMENT '定时开始预发布时间' AFTER `lark_thread_id`,
+  ADD COLUMN `scheduled_pre_by`  varchar(50) NULL COMMENT '定时预发布设置人' AFTER `scheduled_pre_at`,
+  ADD COLUMN `scheduled_prod_at` timestamp NULL DEFAULT NULL COMMENT '定时开始生产部署时间' AFTER `scheduled_pre_by`,
+  ADD COLUMN `scheduled_prod_by` varchar(50) NULL COMMENT '定时生产部署设置人' AFTER `scheduled_prod_at`,
+  ADD INDEX `idx_scheduled_pre_at` (`scheduled_pre_at`),
+  ADD INDEX `idx_scheduled_prod_at` (`scheduled_prod_at`);
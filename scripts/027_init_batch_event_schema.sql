@@ -0,0 +1,51 @@
+-- DevOps CD 工具 - 批次状态变更审计事件
+-- 版本: v27.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. 批次事件表 (batch_events)
+-- 用途: 记录批次每一次状态变更，供平台使用情况报表（GET /api/v1/reports/adoption）统计
+-- 设计:
+--   - 由批次状态机在状态变更的同一事务内写入，只增不改
+--   - trigger: manual（用户通过 API 操作）/ scheduled（定时部署触发）/ auto（引擎自动推进）
+-- =====================================================
+CREATE TABLE `batch_events` (
+  `id`          bigint      NOT NULL AUTO_INCREMENT,
+  `batch_id`    bigint      NOT NULL,
+  `project_id`  bigint      NOT NULL,
+  `from_status` tinyint     NOT NULL,
+  `to_status`   tinyint     NOT NULL,
+  `trigger`     varchar(20) NOT NULL COMMENT 'manual/scheduled/auto',
+  `operator`    varchar(50)          DEFAULT NULL,
+  `reason`      text,
+  `created_at`  timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_batch_id` (`batch_id`),
+  KEY `idx_project_created` (`project_id`, `created_at`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='批次状态变更审计事件';
+
+
+-- =====================================================
+-- 2. 历史数据回填
+-- 说明:
+--   - 仅能从 release_batches 上的时间/操作人字段还原用户手动操作（封板、开始预发布、开始生产部署、最终验收、取消）
+--   - 引擎自动推进的历史事件无法还原，上线前的自动化率会偏低
+-- =====================================================
+INSERT INTO `batch_events` (`batch_id`, `project_id`, `from_status`, `to_status`, `trigger`, `operator`, `reason`, `created_at`)
+SELECT `id`, `project_id`, 0, 10, 'manual', `sealed_by`, '历史数据回填', `sealed_at`
+FROM `release_batches` WHERE `sealed_at` IS NOT NULL
+UNION ALL
+SELECT `id`, `project_id`, 10, 20, 'manual', `pre_triggered_by`, '历史数据回填', `pre_started_at`
+FROM `release_batches` WHERE `pre_started_at` IS NOT NULL
+UNION ALL
+SELECT `id`, `project_id`, 25, 30, 'manual', `prod_triggered_by`, '历史数据回填', `prod_started_at`
+FROM `release_batches` WHERE `prod_started_at` IS NOT NULL
+UNION ALL
+SELECT `id`, `project_id`, 35, 40, 'manual', `final_accepted_by`, '历史数据回填', `final_accepted_at`
+FROM `release_batches` WHERE `final_accepted_at` IS NOT NULL
+UNION ALL
+SELECT `id`, `project_id`, `status`, 90, 'manual', `cancelled_by`, '历史数据回填', `cancelled_at`
+FROM `release_batches` WHERE `cancelled_at` IS NOT NULL;
@@ -0,0 +1,15 @@
+-- DevOps CD 工具 - GitOps driver
+-- 版本: v28.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. deployments 增加 gitops 提交记录
+-- 说明:
+--   - artifacts_json 中 *_chart.type=gitops 时，不直接 helm install，而是将合并后的 values.yaml
+--     （或 mode=manifests 时 helm template 渲染结果）提交到 data.git 配置的仓库目录
+--   - git_revision 记录本次提交（内容无变化时为当前 HEAD），Running 阶段据此查询 Argo Application
+--     或 commit status 判断同步结果
+-- =====================================================
+ALTER TABLE `deployments`
+  ADD COLUMN `git_revision` varchar(64) NULL COMMENT 'gitops driver 提交的 commit' AFTER `dry_run_at`;
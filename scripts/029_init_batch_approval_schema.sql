@@ -0,0 +1,38 @@
+-- DevOps CD 工具 - 多人分阶段审批
+-- 版本: v29.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. projects 增加审批策略
+-- 说明:
+--   - approval_policy 为空或 stages 为空时沿用单人审批
+--   - stages 按顺序审批，每个阶段 approvers 中 required 人通过后进入下一阶段，
+--     全部阶段通过后批次 approval_status=approved（预发布部署以此为前置条件）
+-- =====================================================
+ALTER TABLE `projects`
+  ADD COLUMN `approval_policy` json NULL COMMENT '批次审批策略' AFTER `owner_name`;
+
+
+-- =====================================================
+-- 2. 批次审批记录表 (batch_approvals)
+-- 用途: 记录多人审批每个阶段每个审批人的决定
+-- 设计:
+--   - 同一批次同一阶段每人只能审批一次（uk_batch_stage_approver）
+--   - decision: approved / rejected；任一审批人拒绝批次即 rejected
+-- =====================================================
+CREATE TABLE `batch_approvals` (
+  `id`         bigint       NOT NULL AUTO_INCREMENT,
+  `batch_id`   bigint       NOT NULL,
+  `stage`      int          NOT NULL COMMENT '阶段序号（从 0 开始）',
+  `stage_name` varchar(100) NOT NULL,
+  `approver`   varchar(50)  NOT NULL,
+  `decision`   varchar(20)  NOT NULL COMMENT 'approved/rejected',
+  `comment`    text                  DEFAULT NULL,
+  `created_at` timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_batch_stage_approver` (`batch_id`, `stage`, `approver`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='批次审批记录';
@@ -0,0 +1,31 @@
+-- DevOps CD 工具 - 批次模板
+-- 版本: v30.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. 批次模板表 (batch_templates)
+-- 用途: 固定周期发布的应用集合（含默认依赖、发布说明脚手架），
+--       POST /api/v1/batch/from-template 据此创建草稿批次
+-- 设计:
+--   - 同一项目内模板名称唯一
+--   - apps: [{"app_id", "pre_only", "release_notes", "temp_depends_on"}]，temp_depends_on 只能引用模板内应用
+--   - batch_number / release_notes 为 Go template，变量: .Project .Template .Date .Apps
+-- =====================================================
+CREATE TABLE `batch_templates` (
+  `id`            bigint       NOT NULL AUTO_INCREMENT,
+  `project_id`    bigint       NOT NULL,
+  `name`          varchar(100) NOT NULL,
+  `description`   text                  DEFAULT NULL,
+  `batch_number`  varchar(200)          DEFAULT NULL COMMENT '批次编号模板',
+  `release_notes` text                  DEFAULT NULL COMMENT '发布说明脚手架',
+  `apps`          json         NOT NULL,
+  `created_by`    varchar(50)           DEFAULT NULL,
+  `updated_by`    varchar(50)           DEFAULT NULL,
+  `created_at`    timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`    timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_project_name` (`project_id`, `name`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='批次模板';
@@ -0,0 +1,14 @@
+-- DevOps CD 工具 - 按 Git tag 自动建批
+-- 版本: v31.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. projects 增加自动建批规则
+-- 说明:
+--   - auto_batch_rule 为空或 tag_pattern 为空时不自动建批
+--   - {"tag_pattern": "release/2024.06.*", "batch_number": "{{.Project}}-2024.06"}
+--   - 成功构建的 Git tag 匹配 tag_pattern 时，应用加入按 batch_number 渲染出的草稿批次（不存在则创建，发起人 auto-batch）
+-- =====================================================
+ALTER TABLE `projects`
+  ADD COLUMN `auto_batch_rule` json NULL COMMENT '自动建批规则' AFTER `approval_policy`;
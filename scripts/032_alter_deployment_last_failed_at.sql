@@ -0,0 +1,13 @@
+-- DevOps CD 工具 - 部署失败重试退避
+-- 版本: v32.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. deployments 增加最近失败时间
+-- 说明:
+--   - Deployment 进入 failed 时写入，手动重试（置回 pending）后保留
+--   - 状态机按 core.deploy.retry_backoff 计算退避时间：last_failed_at + 退避间隔之前保持 pending
+-- =====================================================
+ALTER TABLE `deployments`
+  ADD COLUMN `last_failed_at` timestamp NULL DEFAULT NULL COMMENT '最近一次失败时间' AFTER `max_retry_count`;
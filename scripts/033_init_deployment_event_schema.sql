@@ -0,0 +1,29 @@
+-- DevOps CD 工具 - Deployment 时间线事件
+-- 版本: v33.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. Deployment 事件表 (deployment_events)
+-- 用途: 按时间顺序记录单个 Deployment 的执行过程，供 GET /api/v1/deployment/:id/timeline 展示
+-- 设计:
+--   - 由 Deployment 状态机写入（状态变更与 Deployment 更新同一事务），只增不改
+--   - type: status_changed / chart_rendered / deploy_started / readiness
+--   - readiness 仅在就绪检查原因变化时写入，轮询期间不会重复记录
+-- =====================================================
+CREATE TABLE `deployment_events` (
+  `id`            bigint      NOT NULL AUTO_INCREMENT,
+  `deployment_id` bigint      NOT NULL,
+  `batch_id`      bigint      NOT NULL,
+  `type`          varchar(30) NOT NULL COMMENT 'status_changed/chart_rendered/deploy_started/readiness',
+  `from_status`   varchar(20)          DEFAULT NULL,
+  `to_status`     varchar(20) NOT NULL,
+  `operator`      varchar(50)          DEFAULT NULL COMMENT '为空表示引擎自动推进',
+  `message`       text,
+  `created_at`    timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_deployment_id` (`deployment_id`),
+  KEY `idx_batch_id` (`batch_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='Deployment 时间线事件';
@@ -0,0 +1,25 @@
+-- DevOps CD 工具 - 部署后验证
+-- 版本: v34.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. applications 增加 deploy_verify
+-- 说明:
+--   - JSON: {enabled, timeout_seconds, checks: [{name, type, envs, url, method, expect_status, expect_body, prometheus_url, query, operator, threshold}]}
+--   - type=http：探测 url，校验状态码（默认 200）与响应体包含 expect_body
+--   - type=prometheus：即时查询 query，取第一条样本值与 threshold 按 operator 比较
+--   - url / prometheus_url / query 支持模板变量（app_name、env、cluster、namespace、release_name、build.image_tag 等）
+-- =====================================================
+ALTER TABLE `applications`
+  ADD COLUMN `deploy_verify` JSON NULL COMMENT '部署后验证' AFTER `region_rollout`;
+
+
+-- =====================================================
+-- 2. deployments 增加验证开始时间
+-- 说明:
+--   - workload 就绪、开始部署后验证时写入；超过 deploy_verify.timeout_seconds 仍未通过置为 verify_failed
+--   - 手动重试（置回 pending）时清空
+-- =====================================================
+ALTER TABLE `deployments`
+  ADD COLUMN `verify_started_at` timestamp NULL DEFAULT NULL COMMENT '部署后验证开始时间' AFTER `git_revision`;
@@ -0,0 +1,28 @@
+-- DevOps CD 工具 - 生产就绪/验证失败自动回滚
+-- 版本: v35.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. projects / applications 增加自动回滚策略
+-- 说明:
+--   - projects.auto_rollback：项目默认策略，默认关闭
+--   - applications.auto_rollback：应用级覆盖，NULL 表示继承项目
+--   - 开启后生产 app Deployment 由 running 进入 failed/verify_failed（就绪检查失败、部署超时、部署后验证未通过）时，
+--     只为该集群创建部署 previous_deployed_tag 的 Deployment，并发送自动回滚通知
+-- =====================================================
+ALTER TABLE `projects`
+  ADD COLUMN `auto_rollback` tinyint(1) NOT NULL DEFAULT 0 COMMENT '生产就绪/验证失败自动回滚失败集群' AFTER `auto_batch_rule`;
+
+ALTER TABLE `applications`
+  ADD COLUMN `auto_rollback` tinyint(1) NULL DEFAULT NULL COMMENT '自动回滚（NULL 继承项目）' AFTER `deploy_verify`;
+
+
+-- =====================================================
+-- 2. deployments 增加回滚构建
+-- 说明:
+--   - 自动回滚创建的 Deployment 部署该构建（发布前版本），为空表示部署发布应用当前构建
+--   - 发布应用存在自动回滚的集群时，全部完成后进入 ProdFailed，不更新应用 deployed_tag
+-- =====================================================
+ALTER TABLE `deployments`
+  ADD COLUMN `rollback_build_id` bigint NULL DEFAULT NULL COMMENT '自动回滚部署的构建' AFTER `rollout_stage`;
@@ -0,0 +1,14 @@
+-- DevOps CD 工具 - 部署波次
+-- 版本: v36.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. release_apps 增加部署波次
+-- 说明:
+--   - 封板时按批次内依赖（default / temporary / app_type）拓扑分层写入，从 1 开始
+--   - 无依赖的应用为第 1 波，其余为依赖的最大波次 + 1；存在循环依赖时拒绝封板
+--   - 0 表示未封板或升级前已封板的批次（不展示波次进度）
+-- =====================================================
+ALTER TABLE `release_apps`
+  ADD COLUMN `wave` int NOT NULL DEFAULT 0 COMMENT '部署波次（封板时计算）' AFTER `temp_depends_on`;
@@ -0,0 +1,13 @@
+-- DevOps CD 工具 - Jenkins 构建通知 token
+-- 版本: v37.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. repositories 增加 Jenkins 构建通知 token
+-- 说明:
+--   - /api/v1/build/notify/jenkins 按构建所属代码库校验 token（?token= 或请求头 X-Webhook-Token）
+--   - 为空时拒绝该代码库的 Jenkins 构建通知；通过更新代码库接口的 ci_token 设置，空字符串清除
+-- =====================================================
+ALTER TABLE `repositories`
+  ADD COLUMN `ci_token` varchar(200) NULL DEFAULT NULL COMMENT 'Jenkins 构建通知 token' AFTER `manifest_synced_at`;
@@ -0,0 +1,13 @@
+-- DevOps CD 工具 - 通用 CI 构建通知来源限定代码库
+-- 版本: v38.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. webhook_sources 增加允许通知的代码库
+-- 说明:
+--   - JSON 字符串数组，元素为 namespace/name，支持 * 通配（如 platform/*），不区分大小写
+--   - 为空不限制；映射出的 repo 不在范围内时拒绝通知（403），避免一个来源的 token 可以为任意代码库写入构建
+-- =====================================================
+ALTER TABLE `webhook_sources`
+  ADD COLUMN `repos` json DEFAULT NULL COMMENT '允许通知的代码库（namespace/name，支持 * 通配）' AFTER `token`;
@@ -0,0 +1,15 @@
+-- DevOps CD 工具 - 镜像仓库推送通知
+-- 版本: v39.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. builds 增加镜像仓库确认信息
+-- 说明:
+--   - /api/v1/build/notify/harbor 收到 PUSH_ARTIFACT 后，镜像地址 + tag 一致的构建写入 digest 与推送时间
+--   - image_pushed_at 为空表示未收到镜像仓库通知（不代表镜像不存在）
+--   - ci.harbor.create_builds 开启时，没有对应构建的 tag 会创建 build_event=registry 的构建
+-- =====================================================
+ALTER TABLE `builds`
+  ADD COLUMN `image_digest`    varchar(100) NULL DEFAULT NULL COMMENT '镜像仓库确认的 manifest digest' AFTER `app_build_success`,
+  ADD COLUMN `image_pushed_at` datetime     NULL DEFAULT NULL COMMENT '镜像推送时间' AFTER `image_digest`;
@@ -0,0 +1,58 @@
+-- DevOps CD 工具 - 镜像漏洞门禁
+-- 版本: v40.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. 镜像扫描结果表 (image_scans)
+-- 用途: 记录镜像 tag 最近一次漏洞扫描结果，作为项目漏洞门禁的数据来源
+-- 设计:
+--   - 来源: Harbor Webhook SCANNING_COMPLETED/SCANNING_FAILED（/api/v1/build/notify/harbor），
+--     CI 上报的 Trivy JSON 报告（/api/v1/build/notify/trivy）
+--   - image 为不含 tag 的镜像地址，与构建 image_url（去掉 tag）一致；同一 image + tag 覆盖为最近一次
+--   - status: success / failed，failed 时漏洞数无意义
+-- =====================================================
+CREATE TABLE `image_scans` (
+  `id`         bigint       NOT NULL AUTO_INCREMENT,
+  `image`      varchar(255) NOT NULL COMMENT '镜像地址（不含 tag）',
+  `tag`        varchar(100) NOT NULL,
+  `digest`     varchar(100)          DEFAULT NULL,
+  `source`     varchar(50)  NOT NULL COMMENT 'harbor/trivy',
+  `scanner`    varchar(100)          DEFAULT NULL COMMENT '扫描器及版本',
+  `status`     varchar(20)  NOT NULL COMMENT 'success/failed',
+  `critical`   int          NOT NULL DEFAULT 0,
+  `high`       int          NOT NULL DEFAULT 0,
+  `medium`     int          NOT NULL DEFAULT 0,
+  `low`        int          NOT NULL DEFAULT 0,
+  `unknown`    int          NOT NULL DEFAULT 0,
+  `scanned_at` timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '扫描完成时间',
+  `created_at` timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_image_tag` (`image`, `tag`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='镜像漏洞扫描结果';
+
+
+-- =====================================================
+-- 2. projects 增加漏洞门禁策略
+-- 说明:
+--   - {"stage": "seal|prod", "max_critical": 0, "max_high": 5, "require_scan": true}，NULL 表示不拦截
+--   - stage=seal：封板时批次内任一构建镜像未通过则封板失败
+--   - stage=prod：发布应用触发生产部署前检查，未通过时保持等待并记录原因，重新扫描通过后自动继续
+-- =====================================================
+ALTER TABLE `projects`
+  ADD COLUMN `vuln_policy` json NULL COMMENT '镜像漏洞门禁策略' AFTER `auto_rollback`;
+
+
+-- =====================================================
+-- 3. release_batches 增加漏洞门禁豁免
+-- 说明:
+--   - 由具备 security:vuln:override 权限的用户通过 POST /api/v1/batch/:id/vuln_override 设置
+--   - 豁免后该批次封板/生产部署不再按项目漏洞策略拦截，DELETE 同一接口取消
+-- =====================================================
+ALTER TABLE `release_batches`
+  ADD COLUMN `vuln_override_at`     timestamp   NULL DEFAULT NULL COMMENT '漏洞门禁豁免时间' AFTER `scheduled_prod_by`,
+  ADD COLUMN `vuln_override_by`     varchar(50) NULL COMMENT '漏洞门禁豁免人' AFTER `vuln_override_at`,
+  ADD COLUMN `vuln_override_reason` text        NULL COMMENT '漏洞门禁豁免原因' AFTER `vuln_override_by`;
@@ -0,0 +1,20 @@
+-- DevOps CD 工具 - 集群免 kubeconfig 接入
+-- 版本: v41.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. clusters 增加认证方式与连接配置
+-- 说明:
+--   - auth_type=kubeconfig（默认）：沿用 kubeconfig 列，已有集群无需调整
+--   - auth_type=token：server + ca_data + token（如 ServiceAccount token）
+--   - auth_type=exec：server + ca_data + exec_config（exec 凭据插件，如 aws eks get-token / gke-gcloud-auth-plugin / kubelogin）
+--   - token / exec 方式下 kubeconfig 列保持空串，部署时由 Helm driver 按连接配置生成
+-- =====================================================
+ALTER TABLE `clusters`
+  ADD COLUMN `auth_type`                varchar(20)  NOT NULL DEFAULT 'kubeconfig' COMMENT '认证方式: kubeconfig/token/exec' AFTER `region`,
+  ADD COLUMN `server`                   varchar(255) NULL DEFAULT NULL COMMENT 'API Server 地址（token/exec 方式）' AFTER `kubeconfig`,
+  ADD COLUMN `ca_data`                  text         NULL COMMENT 'API Server CA 证书（PEM），为空使用系统 CA' AFTER `server`,
+  ADD COLUMN `insecure_skip_tls_verify` tinyint(1)   NOT NULL DEFAULT 0 COMMENT '是否跳过证书校验' AFTER `ca_data`,
+  ADD COLUMN `token`                    text         NULL COMMENT 'Bearer token（token 方式）' AFTER `insecure_skip_tls_verify`,
+  ADD COLUMN `exec_config`              json         NULL COMMENT 'exec 凭据插件配置（exec 方式）' AFTER `token`;
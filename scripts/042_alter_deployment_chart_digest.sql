@@ -0,0 +1,14 @@
+-- DevOps CD 工具 - OCI chart 来源
+-- 版本: v42.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. deployments 增加 chart digest 记录
+-- 说明:
+--   - artifacts_json 中 *_chart.data.chart_source_type=oci（或 repo_url 为 oci:// 前缀）时，
+--     helm driver 从 OCI registry 拉取 chart，并记录 manifest digest
+--   - 同一 deployment 重试时按 chart_digest 拉取，tag 被覆盖推送也不影响部署内容
+-- =====================================================
+ALTER TABLE `deployments`
+  ADD COLUMN `chart_digest` varchar(100) NULL COMMENT 'OCI chart manifest digest' AFTER `git_revision`;
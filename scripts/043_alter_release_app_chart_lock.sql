@@ -0,0 +1,15 @@
+-- DevOps CD 工具 - chart lock
+-- 版本: v43.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. release_apps 增加 chart lock
+-- 说明:
+--   - 封板时按首个部署环境（有预发布环境时为 pre）解析 app_chart / config_chart 的 chart，
+--     查询仓库得到确切版本与 digest 后写入 chart_lock；仓库不可达或版本不存在时拒绝封板
+--   - pre 与 prod 部署均使用锁定的版本（仓库与 chart 名称一致时），并校验 digest
+--   - 发布应用切换构建后 lock 不再生效；自动回滚的部署不使用 lock
+-- =====================================================
+ALTER TABLE `release_apps`
+  ADD COLUMN `chart_lock` json NULL COMMENT '封板时锁定的 chart 版本/digest' AFTER `target_tag`;
@@ -0,0 +1,18 @@
+-- DevOps CD 工具 - 跳过生产环境
+-- 版本: v44.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. release_apps 增加 skip_prod_env
+-- 说明:
+--   - 与 skip_pre_env 对应：封板时按 pre_only 或未配置生产环境（app_env_configs env=prod）计算并固化
+--   - 状态机与批次聚合（ProdWaiting 分流、生产验收、最终验收、deployed_tag 同步、回滚、生产依赖）
+--     按 skip_prod_env 跳过生产阶段，应用在 Pre 验收后进入 PreOnlyCompleted
+--   - 同时跳过预发布与生产的应用（未配置任何部署环境）不允许封板
+-- =====================================================
+ALTER TABLE `release_apps`
+  ADD COLUMN `skip_prod_env` tinyint(1) NOT NULL DEFAULT 0 COMMENT '是否跳过生产环境（pre_only 或未配置生产环境）' AFTER `pre_only`;
+
+-- 历史数据：pre_only 应用即跳过生产
+UPDATE `release_apps` SET `skip_prod_env` = `pre_only`;
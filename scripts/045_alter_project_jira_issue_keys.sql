@@ -0,0 +1,23 @@
+-- DevOps CD 工具 - Jira / issue 关联
+-- 版本: v45.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. projects 增加 jira_config
+-- 说明:
+--   - JSON: {"base_url", "credential_ref", "project_keys", "transition_on_complete", "transition"}
+--   - 为空表示不流转 issue；project_keys 限定解析的 Jira 项目
+-- =====================================================
+ALTER TABLE `projects`
+  ADD COLUMN `jira_config` json NULL COMMENT 'Jira 关联配置' AFTER `vuln_policy`;
+
+
+-- =====================================================
+-- 2. release_apps 增加 issue_keys
+-- 说明:
+--   - 封板后异步从 previous_deployed_tag → target_tag 之间的提交说明解析（如 PROJ-123）
+--   - 批次完成后按项目 jira_config 流转这些 issue
+-- =====================================================
+ALTER TABLE `release_apps`
+  ADD COLUMN `issue_keys` json NULL COMMENT '关联 issue（JSON 数组）' AFTER `temp_depends_on`;
@@ -0,0 +1,41 @@
+-- DevOps CD 工具 - 通知路由规则
+-- 版本: v46.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. 通知路由规则表 (notification_rules)
+-- 用途: 按项目/团队、通知类型与严重级别将通知路由到不同的 Lark 群机器人 Webhook
+-- 设计:
+--   - project_id / team_id: 为空表示不限；team_id 非空时只匹配应用级通知（应用团队，未设置时取代码库团队）
+--   - events: 通知类型列表（JSON 数组），包含 "*" 表示全部
+--   - min_severity: info / warning / error（失败为 error，自动回滚为 warning，其余为 info）
+--   - title_template / content_template: Go text/template，为空使用默认标题/正文
+--   - quiet_hours: {"start": "22:00", "end": "08:00", "timezone": "Asia/Shanghai"}，时段内只发送 error 级别
+--   - mention_leader: error 级别的应用通知 @ 团队负责人（teams.leader_name 对应用户邮箱）
+--   - 匹配到规则的通知不再发送到 core.notification 配置的默认渠道
+-- =====================================================
+CREATE TABLE `notification_rules` (
+  `id`               bigint       NOT NULL AUTO_INCREMENT,
+  `name`             varchar(100) NOT NULL,
+  `project_id`       bigint                DEFAULT NULL COMMENT '为空表示所有项目',
+  `team_id`          bigint                DEFAULT NULL COMMENT '为空表示所有团队',
+  `events`           json                  DEFAULT NULL COMMENT '通知类型列表',
+  `min_severity`     varchar(20)  NOT NULL DEFAULT 'info' COMMENT '最低严重级别',
+  `webhook`          varchar(500) NOT NULL COMMENT 'Lark 群机器人 Webhook',
+  `title_template`   text                  DEFAULT NULL COMMENT '标题模板',
+  `content_template` text                  DEFAULT NULL COMMENT '正文模板',
+  `quiet_hours`      json                  DEFAULT NULL COMMENT '免打扰时段',
+  `mention_leader`   tinyint(1)   NOT NULL DEFAULT 0 COMMENT '失败时 @ 团队负责人',
+  `enabled`          tinyint(1)   NOT NULL DEFAULT 1,
+  `description`      varchar(500)          DEFAULT NULL,
+  `created_by`       varchar(50)           DEFAULT NULL,
+  `updated_by`       varchar(50)           DEFAULT NULL,
+  `created_at`       timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`       timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_project_id` (`project_id`),
+  KEY `idx_team_id` (`team_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='通知路由规则';
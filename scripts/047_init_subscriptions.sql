@@ -0,0 +1,30 @@
+-- DevOps CD 工具 - 批次/应用订阅
+-- 版本: v47.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. 订阅表 (subscriptions)
+-- 用途: 用户订阅批次或应用，状态变更时通过 Lark 私聊 / 邮件只通知订阅人
+-- 设计:
+--   - target_type: batch（批次状态变更）/ app（该应用在任意批次中的部署成功/失败）
+--   - project_id: 订阅对象所属项目（订阅时校验查看权限）
+--   - channels: 通知渠道 JSON 数组（lark / email），为空表示所有已配置渠道
+--   - 同一用户对同一对象只有一条订阅，重复订阅时更新渠道
+-- =====================================================
+CREATE TABLE `subscriptions` (
+  `id`          bigint      NOT NULL AUTO_INCREMENT,
+  `username`    varchar(50) NOT NULL,
+  `target_type` varchar(20) NOT NULL COMMENT 'batch / app',
+  `target_id`   bigint      NOT NULL,
+  `project_id`  bigint      NOT NULL,
+  `channels`    json                 DEFAULT NULL COMMENT '通知渠道',
+  `created_at`  timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`  timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_subscription` (`username`, `target_type`, `target_id`),
+  KEY `idx_target` (`target_type`, `target_id`),
+  KEY `idx_project_id` (`project_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='批次/应用订阅';
@@ -0,0 +1,28 @@
+-- DevOps CD 工具 - 批次评论
+-- 版本: v48.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. 批次评论表 (batch_comments)
+-- 用途: 评审人在批次或发布应用上留言（如"payments-api 因迁移暂缓"），与审批、状态变更一起组成批次动态
+-- 设计:
+--   - release_app_id 为空表示批次级评论，非空时 app_id 冗余记录应用（发布应用被拆分/合并后仍可显示应用名）
+--   - 仅作者可编辑/删除，删除为物理删除
+-- =====================================================
+CREATE TABLE `batch_comments` (
+  `id`             bigint      NOT NULL AUTO_INCREMENT,
+  `batch_id`       bigint      NOT NULL,
+  `project_id`     bigint      NOT NULL,
+  `release_app_id` bigint               DEFAULT NULL,
+  `app_id`         bigint               DEFAULT NULL,
+  `author`         varchar(50) NOT NULL,
+  `content`        text        NOT NULL,
+  `created_at`     timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`     timestamp   NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_batch_id` (`batch_id`),
+  KEY `idx_release_app_id` (`release_app_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='批次评论';
@@ -0,0 +1,34 @@
+-- DevOps CD 工具 - 写接口幂等键
+-- 版本: v49.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. 幂等记录表 (idempotency_keys)
+-- 用途: POST 请求携带 Idempotency-Key 头时按「用户 + 键」去重，重试请求返回首次请求的结果（避免重复创建批次、重复触发部署）
+-- 设计:
+--   - fingerprint: 请求方法、路径与请求体的 SHA-256，同一个键用于不同请求时拒绝
+--   - status: processing（处理中，并发重试返回冲突）/ completed（已保存成功响应）
+--   - 请求失败时删除记录，客户端可使用同一个键重试
+--   - expires_at: 过期时间（server.idempotency_ttl，默认 24 小时），定时任务每小时清理
+-- =====================================================
+CREATE TABLE `idempotency_keys` (
+  `id`            bigint       NOT NULL AUTO_INCREMENT,
+  `username`      varchar(50)  NOT NULL,
+  `idem_key`      varchar(128) NOT NULL COMMENT 'Idempotency-Key',
+  `method`        varchar(10)  NOT NULL,
+  `path`          varchar(255) NOT NULL,
+  `fingerprint`   varchar(64)  NOT NULL COMMENT '请求指纹 SHA-256',
+  `status`        varchar(20)  NOT NULL COMMENT 'processing / completed',
+  `status_code`   int          NOT NULL DEFAULT 0 COMMENT '响应 HTTP 状态码',
+  `content_type`  varchar(100)          DEFAULT NULL,
+  `response_body` mediumblob            DEFAULT NULL,
+  `expires_at`    timestamp    NOT NULL,
+  `created_at`    timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`    timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_idempotency_key` (`username`, `idem_key`),
+  KEY `idx_expires_at` (`expires_at`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='写接口幂等记录';
@@ -0,0 +1,42 @@
+-- DevOps CD 工具 - 异步任务队列
+-- 版本: v50.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. 异步任务表 (jobs)
+-- 用途: Deployment 执行（preflight、Chart/制品拉取、Git 检出、Helm install/upgrade）等耗时副作用由 worker 池异步执行，状态机只负责入队
+-- 设计:
+--   - status: pending（待执行/等待重试）/ running（已领取）/ succeeded / dead（超过最大重试次数，死信）
+--   - active_key: 未结束时等于 dedup_key、结束后置空，唯一索引保证同一去重键同时只有一个未结束任务
+--   - locked_by / locked_until: 领取租约，worker 异常退出后租约过期由其他 worker 重新领取
+--   - run_at: 最早可执行时间，失败按退避推迟
+-- =====================================================
+CREATE TABLE `jobs` (
+  `id`            bigint       NOT NULL AUTO_INCREMENT,
+  `type`          varchar(64)  NOT NULL COMMENT '任务类型，如 deployment.execute',
+  `dedup_key`     varchar(128) NOT NULL DEFAULT '' COMMENT '去重键',
+  `active_key`    varchar(128)          DEFAULT NULL COMMENT '未结束时等于 dedup_key',
+  `payload`       json                  DEFAULT NULL,
+  `status`        varchar(20)  NOT NULL COMMENT 'pending / running / succeeded / dead',
+  `attempts`      int          NOT NULL DEFAULT 0,
+  `max_attempts`  int          NOT NULL DEFAULT 1,
+  `run_at`        timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '最早可执行时间',
+  `locked_by`     varchar(128)          DEFAULT NULL COMMENT '领取的 worker（hostname:pid）',
+  `locked_until`  timestamp    NULL     DEFAULT NULL COMMENT '租约到期时间',
+  `last_error`    text                  DEFAULT NULL,
+  `batch_id`      bigint                DEFAULT NULL,
+  `deployment_id` bigint                DEFAULT NULL,
+  `started_at`    timestamp    NULL     DEFAULT NULL,
+  `finished_at`   timestamp    NULL     DEFAULT NULL,
+  `created_at`    timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`    timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_jobs_active_key` (`active_key`),
+  KEY `idx_jobs_status_run_at` (`status`, `run_at`),
+  KEY `idx_type` (`type`),
+  KEY `idx_batch_id` (`batch_id`),
+  KEY `idx_deployment_id` (`deployment_id`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='异步任务队列';
@@ -0,0 +1,36 @@
+-- DevOps CD 工具 - API Token（集成方只读访问）
+-- 版本: v51.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. API Token 表 (api_tokens)
+-- 用途: 其他内部系统以 Bearer Token 只读查询批次/部署状态，不依赖 LDAP 用户 JWT
+-- 设计:
+--   - 明文 token（dcd_ 前缀）只在创建时返回一次，库中只保存 sha256 摘要与前缀（用于识别）
+--   - scopes: 授予的只读权限（如 batch:view）；project_ids 为空表示不限项目
+--   - expires_at 为空表示不过期；revoked_at 非空表示已吊销
+--   - last_used_at / last_used_ip: 最近使用时间与来源（最多每分钟更新一次）
+-- =====================================================
+CREATE TABLE `api_tokens` (
+  `id`           bigint       NOT NULL AUTO_INCREMENT,
+  `name`         varchar(100) NOT NULL COMMENT '名称（集成方）',
+  `description`  varchar(500) NOT NULL DEFAULT '',
+  `token_prefix` varchar(16)  NOT NULL COMMENT 'token 前缀，用于识别',
+  `token_hash`   char(64)     NOT NULL COMMENT 'sha256(token) hex',
+  `scopes`       json                  DEFAULT NULL COMMENT '授予的只读权限',
+  `project_ids`  json                  DEFAULT NULL COMMENT '限定项目，为空表示不限',
+  `expires_at`   timestamp    NULL     DEFAULT NULL COMMENT '过期时间，为空表示不过期',
+  `last_used_at` timestamp    NULL     DEFAULT NULL,
+  `last_used_ip` varchar(64)  NOT NULL DEFAULT '',
+  `revoked_at`   timestamp    NULL     DEFAULT NULL,
+  `revoked_by`   varchar(50)  NOT NULL DEFAULT '',
+  `created_by`   varchar(50)  NOT NULL DEFAULT '',
+  `created_at`   timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`   timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_api_tokens_hash` (`token_hash`),
+  KEY `idx_api_tokens_name` (`name`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='API Token';
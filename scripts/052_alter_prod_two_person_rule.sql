@@ -0,0 +1,14 @@
+-- DevOps CD 工具 - 生产部署双人原则
+-- 版本: v52.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. projects 增加生产部署双人原则
+-- 说明:
+--   - 开启后触发生产部署（start_prod_deploy，含定时生产部署）的操作人不能是批次发起人，
+--     也不能是批次审批人（单人审批的 approved_by 及多人审批中已通过的审批人）
+--   - 默认关闭
+-- =====================================================
+ALTER TABLE `projects`
+  ADD COLUMN `prod_two_person_rule` tinyint(1) NOT NULL DEFAULT 0 COMMENT '生产部署双人原则' AFTER `jira_config`;
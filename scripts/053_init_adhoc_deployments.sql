@@ -0,0 +1,46 @@
+-- DevOps CD 工具 - 临时部署（批次外手动部署）
+-- 版本: v53.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. 临时部署表 (adhoc_deployments)
+-- 用途: 紧急修复时不组建批次，直接将应用的指定构建部署到 pre/prod 的指定集群
+-- 设计:
+--   - 每个集群创建一条 Deployment（deployments.adhoc_id 关联），由核心引擎按 Deployment 状态机推进
+--   - status: running / success / failed，Deployment 全部结束后汇总，并发送应用部署通知
+--   - reason 必填，与 created_by 一起作为审计记录
+-- =====================================================
+CREATE TABLE `adhoc_deployments` (
+  `id`            bigint       NOT NULL AUTO_INCREMENT,
+  `project_id`    bigint       NOT NULL,
+  `app_id`        bigint       NOT NULL,
+  `env`           varchar(20)  NOT NULL COMMENT 'pre/prod',
+  `build_id`      bigint       NOT NULL,
+  `image_tag`     varchar(100) NOT NULL,
+  `clusters`      json                  DEFAULT NULL COMMENT '部署的集群',
+  `status`        varchar(20)  NOT NULL DEFAULT 'running' COMMENT 'running/success/failed',
+  `reason`        text         NOT NULL COMMENT '发起原因',
+  `error_message` text                  DEFAULT NULL,
+  `created_by`    varchar(50)  NOT NULL,
+  `finished_at`   timestamp    NULL     DEFAULT NULL,
+  `created_at`    timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`    timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_adhoc_deployments_project_id` (`project_id`),
+  KEY `idx_adhoc_deployments_app_id` (`app_id`),
+  KEY `idx_adhoc_deployments_status` (`status`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='临时部署';
+
+
+-- =====================================================
+-- 2. deployments 增加临时部署关联
+-- 说明:
+--   - 临时部署的 Deployment batch_id/release_id 为 0，部署 adhoc_deployments.build_id
+--   - 不参与批次处理，由核心引擎单独扫描推进
+-- =====================================================
+ALTER TABLE `deployments`
+  ADD COLUMN `adhoc_id` bigint NULL DEFAULT NULL COMMENT '临时部署ID' AFTER `rollback_build_id`,
+  ADD KEY `idx_deployments_adhoc_id` (`adhoc_id`);
@@ -0,0 +1,14 @@
+-- DevOps CD 工具 - 发布应用 values 覆盖
+-- 版本: v54.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. release_apps 增加 values 覆盖
+-- 用途: 封板前为发布应用设置一次性的 values（YAML/JSON object），无需修改 values 仓库
+-- 设计:
+--   - 部署时在 app_chart values 的最后合并（运行时注入的 image.tag 之后），不允许覆盖 image.tag
+--   - 仅封板前可修改，封板后随发布记录锁定；NULL 表示无覆盖
+-- =====================================================
+ALTER TABLE `release_apps`
+  ADD COLUMN `values_override` text NULL DEFAULT NULL COMMENT 'values 覆盖（YAML/JSON）' AFTER `chart_lock`;
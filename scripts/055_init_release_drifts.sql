@@ -0,0 +1,53 @@
+-- DevOps CD 工具 - 配置漂移检测
+-- 版本: v55.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. deployments 增加 release 快照
+-- 用途: helm driver 部署成功时记录写入的 release revision 与 values/manifest 摘要，作为配置漂移检测的基准
+-- 说明:
+--   - 仅由 helm driver 按列更新；历史 Deployment 为 NULL，不参与检测（下次部署后生效）
+--   - values_digest 为 release user-supplied values 的 sha256，manifest_digest 为渲染后 manifest 的 sha256
+-- =====================================================
+ALTER TABLE `deployments`
+  ADD COLUMN `release_revision` int         NULL DEFAULT NULL COMMENT '写入的 helm release revision' AFTER `chart_digest`,
+  ADD COLUMN `values_digest`    varchar(64) NULL DEFAULT NULL COMMENT 'release values 摘要' AFTER `release_revision`,
+  ADD COLUMN `manifest_digest`  varchar(64) NULL DEFAULT NULL COMMENT 'release manifest 摘要' AFTER `values_digest`;
+
+
+-- =====================================================
+-- 2. 配置漂移检测结果表 (release_drifts)
+-- 用途: 定时（core.drift_check.cron）对比集群中当前的 helm release 与最近一次部署写入的 release
+-- 设计:
+--   - 每个应用/环境/集群/部署类型一条记录，每次检测覆盖
+--   - status: ok / drift / missing（release 不存在）/ error（检测失败，保留上次的漂移项）
+--   - findings: 漂移项（revision / values 路径 / manifest 资源 / 集群资源对象字段），values 只记录路径不记录值
+--   - digest 为漂移内容摘要，与 notified_digest 不同时发送通知；恢复为 ok 后清空
+-- =====================================================
+CREATE TABLE `release_drifts` (
+  `id`              bigint       NOT NULL AUTO_INCREMENT,
+  `project_id`      bigint       NOT NULL,
+  `app_id`          bigint       NOT NULL,
+  `env`             varchar(20)  NOT NULL COMMENT 'pre/prod',
+  `cluster`         varchar(63)  NOT NULL,
+  `kind`            varchar(20)  NOT NULL COMMENT 'app/config',
+  `deployment_id`   bigint       NOT NULL COMMENT '作为基准的 Deployment',
+  `namespace`       varchar(63)  NOT NULL,
+  `release_name`    varchar(63)  NOT NULL,
+  `status`          varchar(20)  NOT NULL COMMENT 'ok/drift/missing/error',
+  `findings`        json                  DEFAULT NULL COMMENT '漂移项',
+  `digest`          varchar(64)  NOT NULL DEFAULT '' COMMENT '漂移内容摘要',
+  `detected_at`     timestamp    NULL     DEFAULT NULL COMMENT '本次漂移首次发现时间',
+  `notified_digest` varchar(64)           DEFAULT NULL COMMENT '已通知的漂移内容摘要',
+  `error_message`   text                  DEFAULT NULL,
+  `checked_at`      timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '最近检测时间',
+  `created_at`      timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`      timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_release_drifts_target` (`app_id`, `env`, `cluster`, `kind`),
+  KEY `idx_release_drifts_project_id` (`project_id`),
+  KEY `idx_release_drifts_status` (`status`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='配置漂移检测结果';
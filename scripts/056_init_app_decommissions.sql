@@ -0,0 +1,47 @@
+-- DevOps CD 工具 - 应用下线
+-- 版本: v56.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. builds 增加归档时间
+-- 说明:
+--   - 应用下线完成时写入，构建记录保留用于审计
+--   - archived_at 为空表示未归档
+-- =====================================================
+ALTER TABLE `builds`
+  ADD COLUMN `archived_at` datetime NULL DEFAULT NULL COMMENT '应用下线归档时间' AFTER `environment`;
+
+
+-- =====================================================
+-- 2. 应用下线表 (app_decommissions)
+-- 用途: （可选）卸载应用在各环境/集群的 helm release，归档构建记录并删除应用
+-- 设计:
+--   - 接口只创建记录（status=running），由核心引擎扫描执行卸载与归档
+--   - targets: 各环境/集群/部署类型最近一次部署写入的 release 及卸载结果
+--     pending / uninstalled / not_found（集群中已不存在）/ skipped（非 helm driver，需手动清理）/ failed
+--   - 全部卸载成功后在同一事务内归档构建、清理配置漂移记录、软删除应用，status=success
+--   - 任一卸载失败或应用被加入未结束的批次时 status=failed，应用保留，可再次发起
+-- =====================================================
+CREATE TABLE `app_decommissions` (
+  `id`              bigint       NOT NULL AUTO_INCREMENT,
+  `project_id`      bigint       NOT NULL,
+  `app_id`          bigint       NOT NULL,
+  `app_name`        varchar(100) NOT NULL COMMENT '应用删除后仍可展示',
+  `uninstall`       tinyint(1)   NOT NULL DEFAULT 0 COMMENT '是否卸载 release',
+  `targets`         json                  DEFAULT NULL COMMENT '卸载目标及结果',
+  `status`          varchar(20)  NOT NULL DEFAULT 'running' COMMENT 'running/success/failed',
+  `archived_builds` bigint       NOT NULL DEFAULT 0 COMMENT '归档的构建数',
+  `reason`          text         NOT NULL COMMENT '下线原因',
+  `error_message`   text                  DEFAULT NULL,
+  `created_by`      varchar(50)  NOT NULL,
+  `finished_at`     timestamp    NULL     DEFAULT NULL,
+  `created_at`      timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated_at`      timestamp    NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx_app_decommissions_project_id` (`project_id`),
+  KEY `idx_app_decommissions_app_id` (`app_id`),
+  KEY `idx_app_decommissions_status` (`status`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET = utf8mb4
+  COLLATE = utf8mb4_general_ci COMMENT ='应用下线';
@@ -0,0 +1,17 @@
+-- DevOps CD 工具 - 仓库源应用发现规则
+-- 版本: v57.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. repo_sources 增加应用发现规则
+-- 说明:
+--   - 清单文件按 devops-cd.yaml、.devops-cd.yaml 顺序读取
+--   - 代码库没有清单文件、但根目录存在 discovery_files 中任一文件（如 Dockerfile）时，以代码库名为应用名创建应用
+--   - discovery_app_type 为空时按代码库语言推断（go/java/kotlin/python/javascript/typescript/html/vue）
+--   - 只在代码库从未关联过应用时创建，manifest_status 记为 discovered
+--   - discovery_files 为空表示不启用
+-- =====================================================
+ALTER TABLE `repo_sources`
+  ADD COLUMN `discovery_files`    JSON        NULL COMMENT '发现规则：根目录存在其中任一文件时创建应用' AFTER `default_team_id`,
+  ADD COLUMN `discovery_app_type` VARCHAR(20) NULL COMMENT '发现规则创建应用的 app_type，为空时按语言推断' AFTER `discovery_files`;
@@ -0,0 +1,15 @@
+-- DevOps CD 工具 - 发布应用清单锁定
+-- 版本: v58.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. release_apps 增加 manifest_lock
+-- 说明:
+--   - 封板时读取构建提交中的代码库清单（devops-cd.yaml / .devops-cd.yaml），清单声明了该应用时写入
+--   - 部署环境/集群、app_chart 应用 values 层与依赖以 lock 为准；与平台配置不一致的项记录在 conflicts 中
+--   - 清单读取失败、不合法、依赖不存在或声明的集群未在平台配置/启用时拒绝封板
+--   - 重新构建（build_id 变化）后 lock 失效，回退到平台配置
+-- =====================================================
+ALTER TABLE `release_apps`
+  ADD COLUMN `manifest_lock` json NULL COMMENT '封板时锁定的代码库清单配置' AFTER `chart_lock`;
@@ -0,0 +1,14 @@
+-- DevOps CD 工具 - 单仓多应用路径映射
+-- 版本: v59.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. applications 增加 path_filter
+-- 说明:
+--   - JSON: {include, exclude}，均为相对仓库根目录的 glob 数组（* 单级、** 多级，以 / 结尾表示目录）
+--   - include 为空表示不限制；exclude 任一匹配即排除；整体为空表示任意变更都匹配
+--   - 构建通知未给出 apps 时，按变更文件将一次构建分发为代码库下各匹配应用的构建记录
+-- =====================================================
+ALTER TABLE `applications`
+  ADD COLUMN `path_filter` JSON NULL COMMENT '单仓多应用路径映射' AFTER `tag_filter`;
@@ -0,0 +1,15 @@
+-- DevOps CD 工具 - 批次部署结果汇总通知
+-- 版本: v60.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. projects 增加部署结果汇总通知开关
+-- 说明:
+--   - 开启后应用部署成功/失败不再逐个发送通知，按批次与阶段（pre/prod/rollback）收集，
+--     阶段结束（部署完成/失败、回滚完成/失败、批次取消/完成）时发送一条汇总，失败应用置顶
+--   - 阶段超过 core.notification.digest_max_wait 仍未结束时先发送已收集的结果
+--   - 默认关闭
+-- =====================================================
+ALTER TABLE `projects`
+  ADD COLUMN `notify_digest` tinyint(1) NOT NULL DEFAULT 0 COMMENT '应用部署结果按批次阶段汇总通知' AFTER `prod_two_person_rule`;
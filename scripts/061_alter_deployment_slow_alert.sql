@@ -0,0 +1,16 @@
+-- DevOps CD 工具 - 部署运行时长异常告警
+-- 版本: v61.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. deployments 增加运行时长异常标记
+-- 说明:
+--   - core.deploy.slow_factor 大于 0 时，引擎扫描 running 的 app Deployment，运行时长超过该应用同环境
+--     最近 20 次成功部署耗时 P95 的 slow_factor 倍（且不低于 slow_min_duration）时写入告警时间并发送 deploy_slow 通知
+--   - slow_alerted_at 晚于 started_at 表示本次运行已告警；重试后 started_at 更新，重新计时
+--   - expected_duration_sec 为告警时的历史 P95 耗时（秒）
+-- =====================================================
+ALTER TABLE `deployments`
+  ADD COLUMN `slow_alerted_at` timestamp NULL DEFAULT NULL COMMENT '运行时长异常告警时间' AFTER `verify_started_at`,
+  ADD COLUMN `expected_duration_sec` int NULL DEFAULT NULL COMMENT '告警时的历史 P95 部署耗时（秒）' AFTER `slow_alerted_at`;
@@ -0,0 +1,13 @@
+-- DevOps CD 工具 - Jenkins 构建通知 token 改为哈希存储
+-- 版本: v62.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. repositories.ci_token 改存 token 的 SHA-256（十六进制）
+-- 说明:
+--   - 校验时对请求中的 token 取 SHA-256 后常量时间比较，数据库中不再保存明文
+--   - 存量明文 token 在此一次性转换，CI 侧配置的 token 不变
+--   - token 只能通过请求头 X-Webhook-Token 传入（不再接受 ?token=，避免写入访问日志）
+-- =====================================================
+UPDATE `repositories` SET `ci_token` = SHA2(`ci_token`, 256) WHERE `ci_token` IS NOT NULL;
@@ -0,0 +1,21 @@
+-- DevOps CD 工具 - 仓库源 token 与 Webhook 签名密钥信封加密
+-- 版本: v63.0
+-- 数据库: MySQL 8.0+
+
+
+-- =====================================================
+-- 1. repo_sources 增加 auth_token_key_id
+-- 说明:
+--   - 与 config_credentials.key_id 相同：为空表示旧格式（crypto.aes_key 直接 AES-GCM 加密），非空为信封加密
+--   - 新建/修改 token 使用当前 crypto.provider；存量数据执行 `go run ./cmd/reencrypt` 在线迁移
+-- =====================================================
+ALTER TABLE `repo_sources`
+  ADD COLUMN `auth_token_key_id` VARCHAR(255) NOT NULL DEFAULT '' COMMENT '加密所用 KEK（为空表示旧格式）' AFTER `auth_token_enc`;
+
+
+-- =====================================================
+-- 2. webhooks.secret_key_id 放宽到 255
+-- 说明: KMS key_id（如 aws_kms:<key ARN>）可能超过 64 个字符
+-- =====================================================
+ALTER TABLE `webhooks`
+  MODIFY COLUMN `secret_key_id` VARCHAR(255) DEFAULT NULL COMMENT '加密密钥ID';
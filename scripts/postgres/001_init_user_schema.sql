@@ -0,0 +1,134 @@
+-- 由 scripts/001_init_user_schema.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE IF NOT EXISTS "users" (
+  "id" BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY (START WITH 1000),
+  "auth_provider" VARCHAR(20) NOT NULL DEFAULT 'local',
+  "username" VARCHAR(63) NOT NULL,
+  "password" VARCHAR(255) NOT NULL,
+  "external_uid" VARCHAR(191) DEFAULT NULL,
+  "email" VARCHAR(255) DEFAULT NULL,
+  "display_name" VARCHAR(63) DEFAULT NULL,
+  "phone" VARCHAR(32) DEFAULT NULL,
+  "status" SMALLINT NOT NULL DEFAULT 1,
+  "last_login_at" TIMESTAMP NULL DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "deleted_at" TIMESTAMP NULL DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "users_uk_auth_provider_username" ON "users" ("auth_provider", "username");
+
+CREATE INDEX IF NOT EXISTS "users_idx_username" ON "users" ("username");
+
+COMMENT ON TABLE "users" IS '用户表';
+
+COMMENT ON COLUMN "users"."id" IS '主键ID';
+
+COMMENT ON COLUMN "users"."auth_provider" IS '认证来源(local/ldap)';
+
+COMMENT ON COLUMN "users"."username" IS '用户名';
+
+COMMENT ON COLUMN "users"."password" IS '密码(bcrypt加密, LDAP账号可为空字符串)';
+
+COMMENT ON COLUMN "users"."external_uid" IS '外部目录唯一ID(entryUUID/uid)';
+
+COMMENT ON COLUMN "users"."email" IS '邮箱';
+
+COMMENT ON COLUMN "users"."display_name" IS '显示名称';
+
+COMMENT ON COLUMN "users"."phone" IS '手机号';
+
+COMMENT ON COLUMN "users"."status" IS '状态(1:启用 0:禁用)';
+
+COMMENT ON COLUMN "users"."last_login_at" IS '最后登录时间';
+
+COMMENT ON COLUMN "users"."created_at" IS '创建时间';
+
+COMMENT ON COLUMN "users"."updated_at" IS '更新时间';
+
+COMMENT ON COLUMN "users"."deleted_at" IS '软删除时间';
+
+CREATE TABLE IF NOT EXISTS "teams" (
+  "id" BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY (START WITH 1),
+  "name" VARCHAR(63) NOT NULL UNIQUE,
+  "display_name" VARCHAR(100) DEFAULT NULL,
+  "description" TEXT DEFAULT NULL,
+  "leader_name" VARCHAR(63) DEFAULT NULL,
+  "status" SMALLINT NOT NULL DEFAULT 1,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "deleted_at" TIMESTAMP NULL DEFAULT NULL
+);
+
+COMMENT ON TABLE "teams" IS '团队表';
+
+COMMENT ON COLUMN "teams"."id" IS '主键ID';
+
+COMMENT ON COLUMN "teams"."name" IS '团队名称';
+
+COMMENT ON COLUMN "teams"."display_name" IS '团队展示名称';
+
+COMMENT ON COLUMN "teams"."description" IS '团队描述';
+
+COMMENT ON COLUMN "teams"."leader_name" IS '团队负责人名称';
+
+COMMENT ON COLUMN "teams"."status" IS '状态(1:启用 0:禁用)';
+
+COMMENT ON COLUMN "teams"."created_at" IS '创建时间';
+
+COMMENT ON COLUMN "teams"."updated_at" IS '更新时间';
+
+COMMENT ON COLUMN "teams"."deleted_at" IS '软删除时间';
+
+CREATE TABLE "team_members" (
+  "id" BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+  "team_id" BIGINT NOT NULL,
+  "user_id" BIGINT NOT NULL,
+  "roles" JSONB NOT NULL DEFAULT '[]',
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP DEFAULT NULL,
+  CONSTRAINT "fk_team_members_team_id" FOREIGN KEY ("team_id") REFERENCES "teams" ("id") ON DELETE CASCADE ON UPDATE RESTRICT,
+  CONSTRAINT "fk_team_members_user_id" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE CASCADE ON UPDATE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS "team_members_idx_team_id" ON "team_members" ("team_id");
+
+CREATE INDEX IF NOT EXISTS "team_members_idx_user_id" ON "team_members" ("user_id");
+
+COMMENT ON TABLE "team_members" IS '团队成员关系表（支持一人多团队、多角色）';
+
+COMMENT ON COLUMN "team_members"."id" IS '主键ID';
+
+COMMENT ON COLUMN "team_members"."team_id" IS '团队ID';
+
+COMMENT ON COLUMN "team_members"."user_id" IS '用户ID';
+
+COMMENT ON COLUMN "team_members"."roles" IS '该用户在当前团队拥有的角色列表';
+
+COMMENT ON COLUMN "team_members"."created_at" IS '创建时间';
+
+COMMENT ON COLUMN "team_members"."updated_at" IS '最后更新时间';
+
+CREATE TABLE IF NOT EXISTS "projects" (
+  "id" BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+  "name" VARCHAR(100) NOT NULL,
+  "description" TEXT,
+  "owner_name" VARCHAR(100) DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "deleted_at" TIMESTAMP NULL DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "projects_uk_name_deleted_at" ON "projects" ("name", "deleted_at");
+
+CREATE INDEX IF NOT EXISTS "projects_idx_deleted_at" ON "projects" ("deleted_at");
+
+COMMENT ON TABLE "projects" IS '项目表';
+
+COMMENT ON COLUMN "projects"."name" IS '项目名称（英文标识）';
+
+COMMENT ON COLUMN "projects"."description" IS '项目描述';
+
+COMMENT ON COLUMN "projects"."owner_name" IS '项目负责人';
+
+INSERT INTO "users" ("auth_provider", "username", "password", "email", "display_name", "status") VALUES ('local', 'admin', '$2a$10$N9qo8u1K5PJXh3x9Y7u6J.eqw6Xb5nBxw5TqKJ1x9Y7u6J.eqw6Xb', 'admin@example.com', '系统管理员', 1) ON CONFLICT DO NOTHING;
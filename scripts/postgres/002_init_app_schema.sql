@@ -0,0 +1,88 @@
+-- 由 scripts/002_init_app_schema.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE IF NOT EXISTS "repositories" (
+  "id" BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+  "project" VARCHAR(63) NOT NULL,
+  "name" VARCHAR(63) NOT NULL,
+  "description" TEXT DEFAULT NULL,
+  "git_url" VARCHAR(255) NOT NULL,
+  "git_type" VARCHAR(63) NOT NULL,
+  "language" VARCHAR(255) DEFAULT NULL,
+  "team_id" BIGINT DEFAULT NULL,
+  "status" SMALLINT NOT NULL DEFAULT 1,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "deleted_at" TIMESTAMP NULL DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "repositories_idx_project_name" ON "repositories" ("project", "name");
+
+COMMENT ON TABLE "repositories" IS '代码库表';
+
+COMMENT ON COLUMN "repositories"."id" IS 'ID';
+
+COMMENT ON COLUMN "repositories"."project" IS 'user/org 如(my_org)';
+
+COMMENT ON COLUMN "repositories"."name" IS '仓库名称(如: my_repo)';
+
+COMMENT ON COLUMN "repositories"."description" IS '代码库描述';
+
+COMMENT ON COLUMN "repositories"."git_url" IS 'Git仓库地址';
+
+COMMENT ON COLUMN "repositories"."git_type" IS 'Git类型(gitlab/github/gitea等)';
+
+COMMENT ON COLUMN "repositories"."language" IS '主要编程语言';
+
+COMMENT ON COLUMN "repositories"."team_id" IS '所属团队ID';
+
+COMMENT ON COLUMN "repositories"."status" IS '状态(1:启用 0:禁用)';
+
+COMMENT ON COLUMN "repositories"."created_at" IS '创建时间';
+
+COMMENT ON COLUMN "repositories"."updated_at" IS '更新时间';
+
+COMMENT ON COLUMN "repositories"."deleted_at" IS '软删除时间';
+
+CREATE TABLE IF NOT EXISTS "applications" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "name" VARCHAR(63) NOT NULL,
+  "project_id" VARCHAR(63) NOT NULL,
+  "description" TEXT,
+  "repo_id" BIGINT NOT NULL,
+  "app_type" VARCHAR(63) NOT NULL,
+  "team_id" BIGINT DEFAULT NULL,
+  "status" SMALLINT NOT NULL DEFAULT 1,
+  "deployed_tag" VARCHAR(63) DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "deleted_at" TIMESTAMP NULL DEFAULT NULL,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "applications_uk_project_name" ON "applications" ("project_id", "name");
+
+COMMENT ON TABLE "applications" IS '应用表';
+
+COMMENT ON COLUMN "applications"."id" IS '主键ID';
+
+COMMENT ON COLUMN "applications"."name" IS '应用名称';
+
+COMMENT ON COLUMN "applications"."project_id" IS '项目名称(继承自repository.project)';
+
+COMMENT ON COLUMN "applications"."description" IS '应用描述';
+
+COMMENT ON COLUMN "applications"."repo_id" IS '关联的代码库ID';
+
+COMMENT ON COLUMN "applications"."app_type" IS '应用类型(web/api/job/microservice等)';
+
+COMMENT ON COLUMN "applications"."team_id" IS '所属团队ID';
+
+COMMENT ON COLUMN "applications"."status" IS '状态(1:启用 0:禁用)';
+
+COMMENT ON COLUMN "applications"."deployed_tag" IS '当前线上部署的tag（ProdDeployed时更新）';
+
+COMMENT ON COLUMN "applications"."created_at" IS '创建时间';
+
+COMMENT ON COLUMN "applications"."updated_at" IS '更新时间';
+
+COMMENT ON COLUMN "applications"."deleted_at" IS '软删除时间';
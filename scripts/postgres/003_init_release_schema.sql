@@ -0,0 +1,212 @@
+-- 由 scripts/003_init_release_schema.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE IF NOT EXISTS "builds" (
+  "id" BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY (START WITH 1000000),
+  "repo_id" BIGINT NOT NULL,
+  "app_id" BIGINT NOT NULL,
+  "build_number" INTEGER NOT NULL,
+  "build_status" VARCHAR(20) NOT NULL,
+  "build_event" VARCHAR(20) NOT NULL,
+  "build_link" VARCHAR(255) DEFAULT NULL,
+  "commit_sha" VARCHAR(64) NOT NULL,
+  "commit_ref" VARCHAR(255) DEFAULT NULL,
+  "commit_branch" VARCHAR(100) DEFAULT NULL,
+  "commit_message" TEXT DEFAULT NULL,
+  "commit_link" VARCHAR(255) DEFAULT NULL,
+  "commit_author" VARCHAR(100) DEFAULT NULL,
+  "build_created" BIGINT NOT NULL,
+  "build_started" BIGINT NOT NULL,
+  "build_finished" BIGINT NOT NULL,
+  "build_duration" INTEGER DEFAULT NULL,
+  "image_tag" VARCHAR(100) NOT NULL,
+  "image_url" VARCHAR(500) DEFAULT NULL,
+  "app_build_success" BOOLEAN NOT NULL DEFAULT TRUE,
+  "environment" VARCHAR(50) DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT "fk_builds_repo" FOREIGN KEY ("repo_id") REFERENCES "repositories" ("id") ON DELETE CASCADE,
+  CONSTRAINT "fk_builds_app" FOREIGN KEY ("app_id") REFERENCES "applications" ("id") ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS "builds_idx_repo_build" ON "builds" ("repo_id" ASC, "build_number" ASC);
+
+CREATE INDEX IF NOT EXISTS "builds_idx_app_status_created" ON "builds" ("app_id" ASC, "build_status" ASC, "build_created" ASC);
+
+COMMENT ON TABLE "builds" IS '应用构建记录表';
+
+COMMENT ON COLUMN "builds"."id" IS '主键ID';
+
+COMMENT ON COLUMN "builds"."repo_id" IS '代码库ID';
+
+COMMENT ON COLUMN "builds"."app_id" IS '应用ID';
+
+COMMENT ON COLUMN "builds"."build_number" IS 'CI构建编号（仓库维度）';
+
+COMMENT ON COLUMN "builds"."build_status" IS '构建状态(success/failure/error/killed)';
+
+COMMENT ON COLUMN "builds"."build_event" IS '触发事件(push/tag/pull_request/promote/rollback)';
+
+COMMENT ON COLUMN "builds"."build_link" IS '构建链接';
+
+COMMENT ON COLUMN "builds"."commit_sha" IS 'Git commit SHA';
+
+COMMENT ON COLUMN "builds"."commit_ref" IS 'Git ref (refs/tags/xxx or refs/heads/xxx)';
+
+COMMENT ON COLUMN "builds"."commit_branch" IS '分支名';
+
+COMMENT ON COLUMN "builds"."commit_message" IS '提交信息';
+
+COMMENT ON COLUMN "builds"."commit_link" IS '提交链接';
+
+COMMENT ON COLUMN "builds"."commit_author" IS '提交者';
+
+COMMENT ON COLUMN "builds"."build_created" IS '创建时间戳(秒)';
+
+COMMENT ON COLUMN "builds"."build_started" IS '开始时间戳(秒)';
+
+COMMENT ON COLUMN "builds"."build_finished" IS '完成时间戳(秒)';
+
+COMMENT ON COLUMN "builds"."build_duration" IS '构建耗时(秒)';
+
+COMMENT ON COLUMN "builds"."image_tag" IS '镜像标签';
+
+COMMENT ON COLUMN "builds"."image_url" IS '完整镜像地址';
+
+COMMENT ON COLUMN "builds"."app_build_success" IS '该应用构建是否成功';
+
+COMMENT ON COLUMN "builds"."environment" IS '目标环境(production/staging/testing等)';
+
+COMMENT ON COLUMN "builds"."created_at" IS '创建时间';
+
+COMMENT ON COLUMN "builds"."updated_at" IS '更新时间';
+
+CREATE TABLE IF NOT EXISTS "release_batches" (
+  "id" BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY (START WITH 100),
+  "batch_number" VARCHAR(200) NOT NULL UNIQUE,
+  "project_id" BIGINT NOT NULL,
+  "initiator" VARCHAR(50) DEFAULT NULL,
+  "release_notes" TEXT DEFAULT NULL,
+  "approval_status" VARCHAR(20) NOT NULL DEFAULT 'pending',
+  "approved_by" VARCHAR(50) DEFAULT NULL,
+  "approved_at" TIMESTAMP NULL DEFAULT NULL,
+  "reject_reason" TEXT DEFAULT NULL,
+  "status" SMALLINT NOT NULL DEFAULT 0,
+  "tagged_at" TIMESTAMP NULL DEFAULT NULL,
+  "pre_deploy_started_at" TIMESTAMP NULL DEFAULT NULL,
+  "pre_deploy_finished_at" TIMESTAMP NULL DEFAULT NULL,
+  "prod_deploy_started_at" TIMESTAMP NULL DEFAULT NULL,
+  "prod_deploy_finished_at" TIMESTAMP NULL DEFAULT NULL,
+  "final_accepted_at" TIMESTAMP NULL DEFAULT NULL,
+  "final_accepted_by" VARCHAR(50) DEFAULT NULL,
+  "cancelled_at" TIMESTAMP NULL DEFAULT NULL,
+  "cancelled_by" VARCHAR(50) DEFAULT NULL,
+  "cancel_reason" TEXT DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "release_batches_uk_batch_number" ON "release_batches" ("batch_number", "project_id");
+
+CREATE INDEX IF NOT EXISTS "release_batches_idx_status" ON "release_batches" ("status");
+
+CREATE INDEX IF NOT EXISTS "release_batches_idx_approval_status" ON "release_batches" ("approval_status");
+
+CREATE INDEX IF NOT EXISTS "release_batches_idx_initiator" ON "release_batches" ("initiator");
+
+CREATE INDEX IF NOT EXISTS "release_batches_idx_created_at" ON "release_batches" ("created_at");
+
+CREATE INDEX IF NOT EXISTS "release_batches_idx_project_id" ON "release_batches" ("project_id");
+
+COMMENT ON TABLE "release_batches" IS '发布批次表';
+
+COMMENT ON COLUMN "release_batches"."id" IS '主键ID';
+
+COMMENT ON COLUMN "release_batches"."batch_number" IS '批次编号/标题(用户填写,如:2025 1010 zkme项目日常更新)';
+
+COMMENT ON COLUMN "release_batches"."project_id" IS '关联的项目ID';
+
+COMMENT ON COLUMN "release_batches"."initiator" IS '发起人';
+
+COMMENT ON COLUMN "release_batches"."release_notes" IS '批次发布说明';
+
+COMMENT ON COLUMN "release_batches"."approval_status" IS '审批状态(pending/approved/rejected/skipped)';
+
+COMMENT ON COLUMN "release_batches"."approved_by" IS '审批人';
+
+COMMENT ON COLUMN "release_batches"."approved_at" IS '审批时间';
+
+COMMENT ON COLUMN "release_batches"."reject_reason" IS '拒绝原因';
+
+COMMENT ON COLUMN "release_batches"."status" IS '部署流程状态(0:草稿 10:已封板 21:预发布中 22:预发布完成 31:生产部署中 32:生产部署完成 40:已完成 90:已取消)';
+
+COMMENT ON COLUMN "release_batches"."tagged_at" IS '封板时间';
+
+COMMENT ON COLUMN "release_batches"."pre_deploy_started_at" IS '预发布开始时间';
+
+COMMENT ON COLUMN "release_batches"."pre_deploy_finished_at" IS '预发布完成时间';
+
+COMMENT ON COLUMN "release_batches"."prod_deploy_started_at" IS '生产部署开始时间';
+
+COMMENT ON COLUMN "release_batches"."prod_deploy_finished_at" IS '生产部署完成时间';
+
+COMMENT ON COLUMN "release_batches"."final_accepted_at" IS '最终验收时间';
+
+COMMENT ON COLUMN "release_batches"."final_accepted_by" IS '验收人';
+
+COMMENT ON COLUMN "release_batches"."cancelled_at" IS '取消时间';
+
+COMMENT ON COLUMN "release_batches"."cancelled_by" IS '取消人';
+
+COMMENT ON COLUMN "release_batches"."cancel_reason" IS '取消原因';
+
+COMMENT ON COLUMN "release_batches"."created_at" IS '创建时间';
+
+COMMENT ON COLUMN "release_batches"."updated_at" IS '更新时间';
+
+CREATE TABLE IF NOT EXISTS "release_apps" (
+  "id" BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY (START WITH 10000),
+  "batch_id" BIGINT NOT NULL,
+  "app_id" BIGINT NOT NULL,
+  "build_id" BIGINT DEFAULT NULL,
+  "previous_deployed_tag" VARCHAR(100) DEFAULT NULL,
+  "target_tag" VARCHAR(100) DEFAULT NULL,
+  "latest_build_id" BIGINT DEFAULT NULL,
+  "release_notes" TEXT DEFAULT NULL,
+  "is_locked" BOOLEAN NOT NULL DEFAULT FALSE,
+  "skip_pre_env" BOOLEAN NOT NULL DEFAULT FALSE,
+  "status" SMALLINT NOT NULL DEFAULT 0,
+  "failed_reason" TEXT DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  CONSTRAINT "fk_release_apps_build" FOREIGN KEY ("build_id") REFERENCES "builds" ("id") ON DELETE RESTRICT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "release_apps_uk_batch_app" ON "release_apps" ("batch_id", "app_id");
+
+COMMENT ON TABLE "release_apps" IS '批次应用关联表';
+
+COMMENT ON COLUMN "release_apps"."id" IS '主键ID';
+
+COMMENT ON COLUMN "release_apps"."batch_id" IS '批次ID';
+
+COMMENT ON COLUMN "release_apps"."app_id" IS '应用ID';
+
+COMMENT ON COLUMN "release_apps"."build_id" IS '关联的构建ID（封板时固定，不可再变更）';
+
+COMMENT ON COLUMN "release_apps"."previous_deployed_tag" IS '部署前的版本（封板时从 applications.deployed_tag 获取）';
+
+COMMENT ON COLUMN "release_apps"."target_tag" IS '目标部署版本（封板时从 build.image_tag 获取并固定，部署期间代表期望版本，部署完成后代表已部署版本）';
+
+COMMENT ON COLUMN "release_apps"."latest_build_id" IS '最新检测到的构建ID（新tag到达时更新）';
+
+COMMENT ON COLUMN "release_apps"."release_notes" IS '应用级发布说明（可选）';
+
+COMMENT ON COLUMN "release_apps"."is_locked" IS '是否已锁定（封板后为true，不可再修改）';
+
+COMMENT ON COLUMN "release_apps"."status" IS '应用级发布状态(0:待发布)';
+
+COMMENT ON COLUMN "release_apps"."failed_reason" IS '应用级发布失败原因（可选）';
+
+COMMENT ON COLUMN "release_apps"."created_at" IS '创建时间';
+
+COMMENT ON COLUMN "release_apps"."updated_at" IS '更新时间';
@@ -0,0 +1,140 @@
+-- 由 scripts/004_init_admin_schema.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE "config_items" (
+  "id" BIGINT PRIMARY KEY GENERATED BY DEFAULT AS IDENTITY,
+  "scope" VARCHAR(7) NOT NULL CHECK ("scope" IN ('global', 'project')),
+  "project_id" BIGINT NULL,
+  "config_key" VARCHAR(255) NOT NULL,
+  "config_value" TEXT NOT NULL,
+  "value_type" VARCHAR(6) NOT NULL DEFAULT 'string' CHECK ("value_type" IN ('string', 'number', 'json', 'secret')),
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "config_items_uniq_scope_key" ON "config_items" ("scope", "project_id", "config_key");
+
+COMMENT ON COLUMN "config_items"."created_at" IS '创建时间';
+
+COMMENT ON COLUMN "config_items"."updated_at" IS '更新时间';
+
+CREATE TABLE IF NOT EXISTS "repo_sync_sources" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "platform" VARCHAR(20) NOT NULL,
+  "base_url" VARCHAR(255) NOT NULL,
+  "namespace" VARCHAR(255) NOT NULL,
+  "auth_token_enc" TEXT NOT NULL,
+  "enabled" BOOLEAN NOT NULL DEFAULT TRUE,
+  "default_project_id" BIGINT NULL,
+  "default_team_id" BIGINT NULL,
+  "last_synced_at" TIMESTAMP NULL,
+  "last_status" VARCHAR(20) NULL,
+  "last_message" TEXT NULL,
+  "ext" JSONB NULL,
+  "created_by" VARCHAR(50) NULL,
+  "updated_by" VARCHAR(50) NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "deleted_at" TIMESTAMP NULL,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "repo_sync_sources_uk_repo_source_base_namespace" ON "repo_sync_sources" ("base_url", "namespace");
+
+CREATE INDEX IF NOT EXISTS "repo_sync_sources_idx_repo_source_platform" ON "repo_sync_sources" ("platform");
+
+CREATE INDEX IF NOT EXISTS "repo_sync_sources_idx_repo_source_enabled" ON "repo_sync_sources" ("enabled");
+
+COMMENT ON TABLE "repo_sync_sources" IS '仓库同步源配置';
+
+COMMENT ON COLUMN "repo_sync_sources"."id" IS '主键';
+
+COMMENT ON COLUMN "repo_sync_sources"."platform" IS '平台类型 gitea/gitlab/github';
+
+COMMENT ON COLUMN "repo_sync_sources"."base_url" IS 'Git 平台基础 URL';
+
+COMMENT ON COLUMN "repo_sync_sources"."namespace" IS '命名空间/组织/用户';
+
+COMMENT ON COLUMN "repo_sync_sources"."auth_token_enc" IS '加密后的访问令牌';
+
+COMMENT ON COLUMN "repo_sync_sources"."enabled" IS '是否启用';
+
+COMMENT ON COLUMN "repo_sync_sources"."default_project_id" IS '默认项目ID（扫描时自动设置）';
+
+COMMENT ON COLUMN "repo_sync_sources"."default_team_id" IS '默认团队ID（扫描时自动设置）';
+
+COMMENT ON COLUMN "repo_sync_sources"."last_synced_at" IS '最近同步时间';
+
+COMMENT ON COLUMN "repo_sync_sources"."last_status" IS '最近同步状态 success/failed';
+
+COMMENT ON COLUMN "repo_sync_sources"."last_message" IS '最近同步结果信息';
+
+COMMENT ON COLUMN "repo_sync_sources"."ext" IS '扩展参数';
+
+COMMENT ON COLUMN "repo_sync_sources"."created_by" IS '创建人';
+
+COMMENT ON COLUMN "repo_sync_sources"."updated_by" IS '更新人';
+
+COMMENT ON COLUMN "repo_sync_sources"."created_at" IS '创建时间';
+
+COMMENT ON COLUMN "repo_sync_sources"."updated_at" IS '更新时间';
+
+COMMENT ON COLUMN "repo_sync_sources"."deleted_at" IS '删除时间';
+
+CREATE TABLE "clusters" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "name" VARCHAR(50) NOT NULL,
+  "description" TEXT DEFAULT NULL,
+  "region" VARCHAR(50) DEFAULT NULL,
+  "kubeconfig" TEXT NOT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "clusters_uk_name" ON "clusters" ("name");
+
+COMMENT ON TABLE "clusters" IS '集群元数据表';
+
+COMMENT ON COLUMN "clusters"."name" IS '集群名称(唯一标识,业务主键)';
+
+COMMENT ON COLUMN "clusters"."description" IS '集群描述';
+
+COMMENT ON COLUMN "clusters"."region" IS '地域/区域';
+
+COMMENT ON COLUMN "clusters"."kubeconfig" IS 'Kubernetes 集群配置文件';
+
+COMMENT ON COLUMN "clusters"."created_at" IS '创建时间';
+
+COMMENT ON COLUMN "clusters"."updated_at" IS '更新时间';
+
+CREATE TABLE IF NOT EXISTS "config_credentials" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "scope" VARCHAR(16) NOT NULL,
+  "project_id" BIGINT DEFAULT NULL,
+  "name" VARCHAR(128) NOT NULL,
+  "type" VARCHAR(32) NOT NULL,
+  "encrypted_data" TEXT NOT NULL,
+  "meta_json" JSONB DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "deleted_at" TIMESTAMP DEFAULT NULL,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "config_credentials_uniq_scope_project_name" ON "config_credentials" ("scope", "project_id", "name");
+
+CREATE INDEX IF NOT EXISTS "config_credentials_idx_project_id" ON "config_credentials" ("project_id");
+
+CREATE INDEX IF NOT EXISTS "config_credentials_idx_deleted_at" ON "config_credentials" ("deleted_at");
+
+COMMENT ON COLUMN "config_credentials"."scope" IS 'global/project';
+
+COMMENT ON COLUMN "config_credentials"."project_id" IS 'scope=project 时必填';
+
+COMMENT ON COLUMN "config_credentials"."name" IS '凭据名称(展示用)';
+
+COMMENT ON COLUMN "config_credentials"."type" IS 'basic_auth/token/ssh_key/tls_client_cert';
+
+COMMENT ON COLUMN "config_credentials"."encrypted_data" IS 'AES-GCM(base64) 密文(包含 nonce)';
+
+COMMENT ON COLUMN "config_credentials"."meta_json" IS '非敏感元信息';
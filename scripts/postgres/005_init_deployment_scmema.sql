@@ -0,0 +1,112 @@
+-- 由 scripts/005_init_deployment_scmema.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE "deployments" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY (START WITH 1000000),
+  "batch_id" BIGINT NOT NULL,
+  "release_id" BIGINT NOT NULL,
+  "app_id" BIGINT NOT NULL,
+  "env" VARCHAR(20) NOT NULL,
+  "cluster" VARCHAR(63) NOT NULL,
+  "namespace" VARCHAR(63) NOT NULL,
+  "deployment_name" VARCHAR(63) NOT NULL,
+  "values" JSONB,
+  "driver_type" VARCHAR(32) DEFAULT NULL,
+  "status" VARCHAR(20) NOT NULL DEFAULT 'pending',
+  "retry_count" INTEGER DEFAULT '0',
+  "max_retry_count" INTEGER DEFAULT '3',
+  "error_message" TEXT,
+  "started_at" TIMESTAMP NULL DEFAULT NULL,
+  "finished_at" TIMESTAMP NULL DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+COMMENT ON TABLE "deployments" IS '部署任务表';
+
+COMMENT ON COLUMN "deployments"."env" IS 'pre 或 prod';
+
+COMMENT ON COLUMN "deployments"."cluster" IS '集群名称';
+
+COMMENT ON COLUMN "deployments"."namespace" IS 'K8s 命名空间';
+
+COMMENT ON COLUMN "deployments"."deployment_name" IS '部署名称';
+
+COMMENT ON COLUMN "deployments"."values" IS '合并后的helm values';
+
+COMMENT ON COLUMN "deployments"."driver_type" IS 'main 阶段 driver（如 helm）';
+
+COMMENT ON COLUMN "deployments"."status" IS 'pending/running/success/failed';
+
+CREATE TABLE "project_env_configs" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "project_id" BIGINT NOT NULL,
+  "env" VARCHAR(32) NOT NULL,
+  "allow_clusters" JSONB NOT NULL,
+  "default_clusters" JSONB NOT NULL,
+  "schema_version" INTEGER NOT NULL DEFAULT 1,
+  "artifacts_json" JSONB DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "project_env_configs_uniq_project_env" ON "project_env_configs" ("project_id", "env");
+
+COMMENT ON COLUMN "project_env_configs"."env" IS '环境，如 pre/prod';
+
+COMMENT ON COLUMN "project_env_configs"."allow_clusters" IS '允许的集群列表';
+
+COMMENT ON COLUMN "project_env_configs"."default_clusters" IS '默认集群列表';
+
+COMMENT ON COLUMN "project_env_configs"."schema_version" IS 'artifacts_json schema version';
+
+COMMENT ON COLUMN "project_env_configs"."artifacts_json" IS '统一配置(JSON): namespace_template + config_chart/app_chart + values[]';
+
+CREATE TABLE IF NOT EXISTS "app_env_configs" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "app_id" BIGINT NOT NULL,
+  "env" VARCHAR(20) NOT NULL,
+  "cluster" VARCHAR(50) NOT NULL DEFAULT 'default',
+  "deployment_name_override" VARCHAR(63) DEFAULT NULL,
+  "replicas" INTEGER DEFAULT 1,
+  "config_data" JSONB DEFAULT NULL,
+  "status" SMALLINT NOT NULL DEFAULT 1,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "deleted_at" TIMESTAMP NULL DEFAULT NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_app_env_configs_cluster" FOREIGN KEY ("cluster") REFERENCES "clusters" ("name") ON DELETE RESTRICT ON UPDATE CASCADE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "app_env_configs_uk_app_env_cluster" ON "app_env_configs" ("app_id", "env", "cluster");
+
+CREATE INDEX IF NOT EXISTS "app_env_configs_idx_app_env" ON "app_env_configs" ("app_id", "env");
+
+CREATE INDEX IF NOT EXISTS "app_env_configs_idx_status" ON "app_env_configs" ("status");
+
+CREATE INDEX IF NOT EXISTS "app_env_configs_idx_deleted_at" ON "app_env_configs" ("deleted_at");
+
+COMMENT ON TABLE "app_env_configs" IS '应用环境配置表';
+
+COMMENT ON COLUMN "app_env_configs"."id" IS '主键ID';
+
+COMMENT ON COLUMN "app_env_configs"."app_id" IS '应用ID,关联 applications.id';
+
+COMMENT ON COLUMN "app_env_configs"."env" IS '环境名称: pre/prod/dev/test/uat 等';
+
+COMMENT ON COLUMN "app_env_configs"."cluster" IS '集群名称';
+
+COMMENT ON COLUMN "app_env_configs"."deployment_name_override" IS '部署名称覆盖,为空则使用默认模板';
+
+COMMENT ON COLUMN "app_env_configs"."replicas" IS '副本数量';
+
+COMMENT ON COLUMN "app_env_configs"."config_data" IS '环境专属配置(JSON格式,用于存储扩展配置)';
+
+COMMENT ON COLUMN "app_env_configs"."status" IS '状态(1:启用 0:禁用,用于临时禁用配置)';
+
+COMMENT ON COLUMN "app_env_configs"."created_at" IS '创建时间';
+
+COMMENT ON COLUMN "app_env_configs"."updated_at" IS '更新时间';
+
+COMMENT ON COLUMN "app_env_configs"."deleted_at" IS '软删除时间';
@@ -0,0 +1,119 @@
+-- 由 scripts/005a_alter_align_baseline_schema.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "users"
+  ADD COLUMN IF NOT EXISTS "system_roles" JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN "users"."system_roles" IS '系统级角色列表';
+
+ALTER TABLE "teams"
+  ADD COLUMN IF NOT EXISTS "project_id" BIGINT NOT NULL;
+
+CREATE INDEX IF NOT EXISTS "teams_idx_project_id" ON "teams" ("project_id");
+
+COMMENT ON COLUMN "teams"."project_id" IS '所属项目ID';
+
+ALTER TABLE "repositories" RENAME COLUMN "project" TO "namespace";
+
+ALTER INDEX IF EXISTS "repositories_idx_project_name" RENAME TO "repositories_idx_namespace_name";
+
+ALTER TABLE "repositories"
+  ALTER COLUMN "namespace" TYPE VARCHAR(100) USING "namespace"::VARCHAR(100),
+  ALTER COLUMN "namespace" DROP DEFAULT,
+  ALTER COLUMN "namespace" SET NOT NULL;
+
+COMMENT ON COLUMN "repositories"."namespace" IS 'user/org 如(my_org)';
+
+ALTER TABLE "repositories"
+  ADD COLUMN IF NOT EXISTS "project_id" BIGINT DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS "repositories_idx_project_id" ON "repositories" ("project_id");
+
+COMMENT ON COLUMN "repositories"."project_id" IS '所属项目ID';
+
+ALTER TABLE "applications"
+  ALTER COLUMN "project_id" TYPE BIGINT USING "project_id"::BIGINT,
+  ALTER COLUMN "project_id" DROP DEFAULT,
+  ALTER COLUMN "project_id" SET NOT NULL;
+
+COMMENT ON COLUMN "applications"."project_id" IS '所属项目ID';
+
+ALTER TABLE "applications"
+  ADD COLUMN IF NOT EXISTS "default_depends_on" JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN "applications"."default_depends_on" IS '配置级依赖（应用ID数组）';
+
+ALTER TABLE "builds"
+  ADD COLUMN IF NOT EXISTS "build_created_at" TIMESTAMP NULL,
+  ADD COLUMN IF NOT EXISTS "build_started_at" TIMESTAMP NULL,
+  ADD COLUMN IF NOT EXISTS "build_finished_at" TIMESTAMP NULL;
+
+COMMENT ON COLUMN "builds"."build_created_at" IS '构建创建时间';
+
+COMMENT ON COLUMN "builds"."build_started_at" IS '构建开始时间';
+
+COMMENT ON COLUMN "builds"."build_finished_at" IS '构建完成时间';
+
+UPDATE "builds" SET "build_created_at" = to_timestamp ("build_created"), "build_started_at" = to_timestamp ("build_started"), "build_finished_at" = to_timestamp ("build_finished");
+
+ALTER TABLE "builds"
+  DROP COLUMN IF EXISTS "build_created",
+  DROP COLUMN IF EXISTS "build_started",
+  DROP COLUMN IF EXISTS "build_finished";
+
+ALTER TABLE "builds" RENAME COLUMN "build_created_at" TO "build_created";
+
+ALTER TABLE "builds" RENAME COLUMN "build_started_at" TO "build_started";
+
+ALTER TABLE "builds" RENAME COLUMN "build_finished_at" TO "build_finished";
+
+ALTER TABLE "builds"
+  ALTER COLUMN "build_created" TYPE TIMESTAMP USING "build_created"::TIMESTAMP,
+  ALTER COLUMN "build_created" DROP DEFAULT,
+  ALTER COLUMN "build_created" SET NOT NULL,
+  ALTER COLUMN "build_started" TYPE TIMESTAMP USING "build_started"::TIMESTAMP,
+  ALTER COLUMN "build_started" DROP DEFAULT,
+  ALTER COLUMN "build_started" SET NOT NULL,
+  ALTER COLUMN "build_finished" TYPE TIMESTAMP USING "build_finished"::TIMESTAMP,
+  ALTER COLUMN "build_finished" DROP DEFAULT,
+  ALTER COLUMN "build_finished" SET NOT NULL;
+
+COMMENT ON COLUMN "builds"."build_created" IS '构建创建时间';
+
+COMMENT ON COLUMN "builds"."build_started" IS '构建开始时间';
+
+COMMENT ON COLUMN "builds"."build_finished" IS '构建完成时间';
+
+ALTER TABLE "release_batches" RENAME COLUMN "tagged_at" TO "sealed_at";
+
+ALTER TABLE "release_batches" RENAME COLUMN "pre_deploy_started_at" TO "pre_started_at";
+
+ALTER TABLE "release_batches" RENAME COLUMN "pre_deploy_finished_at" TO "pre_finished_at";
+
+ALTER TABLE "release_batches" RENAME COLUMN "prod_deploy_started_at" TO "prod_started_at";
+
+ALTER TABLE "release_batches" RENAME COLUMN "prod_deploy_finished_at" TO "prod_finished_at";
+
+ALTER TABLE "release_batches"
+  ADD COLUMN IF NOT EXISTS "sealed_by" VARCHAR(50) DEFAULT NULL,
+  ADD COLUMN IF NOT EXISTS "pre_triggered_by" VARCHAR(50) DEFAULT NULL,
+  ADD COLUMN IF NOT EXISTS "prod_triggered_by" VARCHAR(50) DEFAULT NULL;
+
+COMMENT ON COLUMN "release_batches"."sealed_by" IS '封板人';
+
+COMMENT ON COLUMN "release_batches"."pre_triggered_by" IS '预发布触发人';
+
+COMMENT ON COLUMN "release_batches"."prod_triggered_by" IS '生产部署触发人';
+
+ALTER TABLE "release_apps" RENAME COLUMN "failed_reason" TO "reason";
+
+ALTER TABLE "release_apps"
+  ADD COLUMN IF NOT EXISTS "temp_depends_on" JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN "release_apps"."temp_depends_on" IS '批次内临时依赖（应用ID数组）';
+
+ALTER TABLE "repo_sync_sources" RENAME TO "repo_sources";
+
+ALTER TABLE "deployments"
+  ADD COLUMN IF NOT EXISTS "superseded_by" BIGINT DEFAULT NULL;
+
+COMMENT ON COLUMN "deployments"."superseded_by" IS '被哪个部署取代';
@@ -0,0 +1,47 @@
+-- 由 scripts/006_init_announcement_schema.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE IF NOT EXISTS "announcements" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "level" VARCHAR(16) NOT NULL DEFAULT 'info',
+  "title" VARCHAR(200) NOT NULL,
+  "content" TEXT DEFAULT NULL,
+  "enabled" BOOLEAN NOT NULL DEFAULT TRUE,
+  "starts_at" TIMESTAMP NULL DEFAULT NULL,
+  "ends_at" TIMESTAMP NULL DEFAULT NULL,
+  "created_by" VARCHAR(50) DEFAULT NULL,
+  "updated_by" VARCHAR(50) DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "deleted_at" TIMESTAMP NULL DEFAULT NULL,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "announcements_idx_enabled_window" ON "announcements" ("enabled", "starts_at", "ends_at");
+
+CREATE INDEX IF NOT EXISTS "announcements_idx_deleted_at" ON "announcements" ("deleted_at");
+
+COMMENT ON TABLE "announcements" IS '全局公告表';
+
+COMMENT ON COLUMN "announcements"."id" IS '主键ID';
+
+COMMENT ON COLUMN "announcements"."level" IS '级别: info/warning/freeze';
+
+COMMENT ON COLUMN "announcements"."title" IS '标题';
+
+COMMENT ON COLUMN "announcements"."content" IS '内容';
+
+COMMENT ON COLUMN "announcements"."enabled" IS '是否启用';
+
+COMMENT ON COLUMN "announcements"."starts_at" IS '生效开始时间（为空不限制）';
+
+COMMENT ON COLUMN "announcements"."ends_at" IS '生效结束时间（为空不限制）';
+
+COMMENT ON COLUMN "announcements"."created_by" IS '创建人';
+
+COMMENT ON COLUMN "announcements"."updated_by" IS '更新人';
+
+COMMENT ON COLUMN "announcements"."created_at" IS '创建时间';
+
+COMMENT ON COLUMN "announcements"."updated_at" IS '更新时间';
+
+COMMENT ON COLUMN "announcements"."deleted_at" IS '软删除时间';
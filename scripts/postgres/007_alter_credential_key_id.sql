@@ -0,0 +1,6 @@
+-- 由 scripts/007_alter_credential_key_id.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "config_credentials"
+  ADD COLUMN IF NOT EXISTS "key_id" VARCHAR(255) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN "config_credentials"."key_id" IS '加密所用 KEK（为空表示旧格式）';
@@ -0,0 +1,6 @@
+-- 由 scripts/008_alter_release_app_pre_only.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "release_apps"
+  ADD COLUMN IF NOT EXISTS "pre_only" BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN "release_apps"."pre_only" IS '仅预发布验证（不发布生产）';
@@ -0,0 +1,12 @@
+-- 由 scripts/009_alter_repository_manifest.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "repositories"
+  ADD COLUMN IF NOT EXISTS "manifest_status" VARCHAR(20) NULL,
+  ADD COLUMN IF NOT EXISTS "manifest_report" JSONB NULL,
+  ADD COLUMN IF NOT EXISTS "manifest_synced_at" TIMESTAMP NULL;
+
+COMMENT ON COLUMN "repositories"."manifest_status" IS '清单同步状态';
+
+COMMENT ON COLUMN "repositories"."manifest_report" IS '最近一次清单同步报告';
+
+COMMENT ON COLUMN "repositories"."manifest_synced_at" IS '最近一次清单同步时间';
@@ -0,0 +1,35 @@
+-- 由 scripts/010_init_batch_impact_schema.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE "batch_app_impacts" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "batch_id" BIGINT NOT NULL,
+  "app_id" BIGINT NOT NULL,
+  "env" VARCHAR(20) NOT NULL,
+  "cluster" VARCHAR(63) NOT NULL,
+  "namespace" VARCHAR(63) NOT NULL,
+  "release_name" VARCHAR(63) NOT NULL,
+  "before" JSONB DEFAULT NULL,
+  "before_collected_at" TIMESTAMP NULL DEFAULT NULL,
+  "after" JSONB DEFAULT NULL,
+  "after_collected_at" TIMESTAMP NULL DEFAULT NULL,
+  "error_message" TEXT,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "batch_app_impacts_uk_batch_app_env_cluster" ON "batch_app_impacts" ("batch_id", "app_id", "env", "cluster");
+
+COMMENT ON TABLE "batch_app_impacts" IS '批次部署影响表';
+
+COMMENT ON COLUMN "batch_app_impacts"."env" IS 'pre 或 prod';
+
+COMMENT ON COLUMN "batch_app_impacts"."cluster" IS '集群名称';
+
+COMMENT ON COLUMN "batch_app_impacts"."namespace" IS 'K8s 命名空间';
+
+COMMENT ON COLUMN "batch_app_impacts"."release_name" IS 'helm release 名称';
+
+COMMENT ON COLUMN "batch_app_impacts"."before" IS '部署前资源声明';
+
+COMMENT ON COLUMN "batch_app_impacts"."after" IS '批次完成后资源声明';
@@ -0,0 +1,6 @@
+-- 由 scripts/011_alter_application_tag_filter.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "applications"
+  ADD COLUMN IF NOT EXISTS "tag_filter" JSONB NULL;
+
+COMMENT ON COLUMN "applications"."tag_filter" IS '构建过滤规则';
@@ -0,0 +1,6 @@
+-- 由 scripts/012_alter_application_region_rollout.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "applications"
+  ADD COLUMN IF NOT EXISTS "region_rollout" JSONB NULL;
+
+COMMENT ON COLUMN "applications"."region_rollout" IS '多 region 部署协调';
@@ -0,0 +1,11 @@
+-- 由 scripts/013_alter_build_provenance.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "builds"
+  ADD COLUMN IF NOT EXISTS "provenance_status" VARCHAR(20) NULL,
+  ADD COLUMN IF NOT EXISTS "provenance_message" VARCHAR(500) NULL;
+
+CREATE INDEX IF NOT EXISTS "builds_idx_provenance_status" ON "builds" ("provenance_status");
+
+COMMENT ON COLUMN "builds"."provenance_status" IS '来源校验结果: verified/mismatch/unverified';
+
+COMMENT ON COLUMN "builds"."provenance_message" IS '来源校验说明';
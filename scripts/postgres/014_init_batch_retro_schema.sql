@@ -0,0 +1,63 @@
+-- 由 scripts/014_init_batch_retro_schema.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE "batch_incidents" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "batch_id" BIGINT NOT NULL,
+  "project_id" BIGINT NOT NULL,
+  "severity" VARCHAR(10) NOT NULL,
+  "title" VARCHAR(200) NOT NULL,
+  "description" TEXT,
+  "started_at" TIMESTAMP NOT NULL,
+  "duration_minutes" INTEGER NOT NULL DEFAULT 0,
+  "app_ids" JSONB DEFAULT NULL,
+  "created_by" VARCHAR(50) DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "batch_incidents_idx_batch_id" ON "batch_incidents" ("batch_id");
+
+CREATE INDEX IF NOT EXISTS "batch_incidents_idx_project_id" ON "batch_incidents" ("project_id");
+
+COMMENT ON TABLE "batch_incidents" IS '批次发布后故障记录';
+
+COMMENT ON COLUMN "batch_incidents"."severity" IS 'sev1~sev4';
+
+COMMENT ON COLUMN "batch_incidents"."started_at" IS '故障开始时间';
+
+COMMENT ON COLUMN "batch_incidents"."duration_minutes" IS '故障持续时长（分钟）';
+
+COMMENT ON COLUMN "batch_incidents"."app_ids" IS '相关应用 ID';
+
+CREATE TABLE "batch_rollbacks" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "batch_id" BIGINT NOT NULL,
+  "project_id" BIGINT NOT NULL,
+  "app_id" BIGINT NOT NULL,
+  "env" VARCHAR(20) NOT NULL,
+  "from_tag" VARCHAR(100) DEFAULT NULL,
+  "to_tag" VARCHAR(100) DEFAULT NULL,
+  "reason" TEXT,
+  "rolled_back_at" TIMESTAMP NOT NULL,
+  "created_by" VARCHAR(50) DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "batch_rollbacks_idx_batch_id" ON "batch_rollbacks" ("batch_id");
+
+CREATE INDEX IF NOT EXISTS "batch_rollbacks_idx_project_id" ON "batch_rollbacks" ("project_id");
+
+CREATE INDEX IF NOT EXISTS "batch_rollbacks_idx_app_id" ON "batch_rollbacks" ("app_id");
+
+COMMENT ON TABLE "batch_rollbacks" IS '批次发布后回滚记录';
+
+COMMENT ON COLUMN "batch_rollbacks"."env" IS 'pre 或 prod';
+
+COMMENT ON COLUMN "batch_rollbacks"."from_tag" IS '回滚前版本';
+
+COMMENT ON COLUMN "batch_rollbacks"."to_tag" IS '回滚后版本';
+
+COMMENT ON COLUMN "batch_rollbacks"."rolled_back_at" IS '回滚时间';
@@ -0,0 +1,11 @@
+-- 由 scripts/015_alter_deployment_kind.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "deployments"
+  ADD COLUMN IF NOT EXISTS "kind" VARCHAR(20) NOT NULL DEFAULT 'app',
+  ADD COLUMN IF NOT EXISTS "chart_version" VARCHAR(100) NULL;
+
+CREATE INDEX IF NOT EXISTS "deployments_idx_release_env_cluster_kind" ON "deployments" ("release_id", "env", "cluster", "kind");
+
+COMMENT ON COLUMN "deployments"."kind" IS '部署类型: app/config';
+
+COMMENT ON COLUMN "deployments"."chart_version" IS '部署的 chart 版本';
@@ -0,0 +1,22 @@
+-- 由 scripts/016_init_engine_pause_schema.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE "engine_pauses" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "scope" VARCHAR(20) NOT NULL,
+  "scope_id" BIGINT NOT NULL,
+  "reason" VARCHAR(500) DEFAULT NULL,
+  "paused_by" VARCHAR(50) DEFAULT NULL,
+  "resumed_by" VARCHAR(50) DEFAULT NULL,
+  "resumed_at" TIMESTAMP NULL DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "engine_pauses_idx_scope" ON "engine_pauses" ("scope", "scope_id");
+
+COMMENT ON TABLE "engine_pauses" IS '核心引擎暂停记录';
+
+COMMENT ON COLUMN "engine_pauses"."scope" IS 'project/batch';
+
+COMMENT ON COLUMN "engine_pauses"."scope_id" IS '项目ID或批次ID';
@@ -0,0 +1,28 @@
+-- 由 scripts/017_init_webhook_source_schema.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE "webhook_sources" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "name" VARCHAR(50) NOT NULL,
+  "description" VARCHAR(500) DEFAULT NULL,
+  "enabled" BOOLEAN NOT NULL DEFAULT TRUE,
+  "token" VARCHAR(200) DEFAULT NULL,
+  "mapping" JSONB DEFAULT NULL,
+  "sample_payload" JSONB DEFAULT NULL,
+  "created_by" VARCHAR(50) DEFAULT NULL,
+  "updated_by" VARCHAR(50) DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "webhook_sources_uk_name" ON "webhook_sources" ("name");
+
+COMMENT ON TABLE "webhook_sources" IS '通用 CI 构建通知来源';
+
+COMMENT ON COLUMN "webhook_sources"."name" IS '来源标识（URL 路径）';
+
+COMMENT ON COLUMN "webhook_sources"."token" IS '请求头 X-Webhook-Token';
+
+COMMENT ON COLUMN "webhook_sources"."mapping" IS '字段映射配置';
+
+COMMENT ON COLUMN "webhook_sources"."sample_payload" IS '测试 payload';
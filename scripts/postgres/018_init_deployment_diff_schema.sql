@@ -0,0 +1,39 @@
+-- 由 scripts/018_init_deployment_diff_schema.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE "deployment_diffs" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "deployment_id" BIGINT NOT NULL,
+  "namespace" VARCHAR(63) NOT NULL,
+  "release_name" VARCHAR(63) NOT NULL,
+  "base_revision" INTEGER NOT NULL DEFAULT 0,
+  "status" VARCHAR(20) NOT NULL,
+  "digest" VARCHAR(64) DEFAULT NULL,
+  "changes" JSONB DEFAULT NULL,
+  "diff" TEXT,
+  "requires_ack" BOOLEAN NOT NULL DEFAULT FALSE,
+  "acked_digest" VARCHAR(64) DEFAULT NULL,
+  "acked_by" VARCHAR(50) DEFAULT NULL,
+  "acked_at" TIMESTAMP NULL DEFAULT NULL,
+  "error_message" TEXT,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "deployment_diffs_uk_deployment_id" ON "deployment_diffs" ("deployment_id");
+
+COMMENT ON TABLE "deployment_diffs" IS 'prod 部署 manifest diff';
+
+COMMENT ON COLUMN "deployment_diffs"."namespace" IS 'K8s 命名空间';
+
+COMMENT ON COLUMN "deployment_diffs"."release_name" IS 'helm release 名称';
+
+COMMENT ON COLUMN "deployment_diffs"."base_revision" IS '对比的 release revision，0 表示首次安装';
+
+COMMENT ON COLUMN "deployment_diffs"."status" IS 'ready/failed';
+
+COMMENT ON COLUMN "deployment_diffs"."digest" IS 'diff 内容摘要';
+
+COMMENT ON COLUMN "deployment_diffs"."changes" IS '资源变更列表';
+
+COMMENT ON COLUMN "deployment_diffs"."diff" IS 'unified diff（不含 hooks）';
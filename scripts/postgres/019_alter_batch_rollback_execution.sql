@@ -0,0 +1,17 @@
+-- 由 scripts/019_alter_batch_rollback_execution.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "batch_rollbacks"
+  ADD COLUMN IF NOT EXISTS "release_id" BIGINT NULL,
+  ADD COLUMN IF NOT EXISTS "status" VARCHAR(20) NOT NULL DEFAULT 'recorded',
+  ADD COLUMN IF NOT EXISTS "finished_at" TIMESTAMP NULL DEFAULT NULL,
+  ADD COLUMN IF NOT EXISTS "error_message" TEXT;
+
+CREATE INDEX IF NOT EXISTS "batch_rollbacks_idx_release_id" ON "batch_rollbacks" ("release_id");
+
+COMMENT ON COLUMN "batch_rollbacks"."release_id" IS '执行回滚的发布应用ID';
+
+COMMENT ON COLUMN "batch_rollbacks"."status" IS 'recorded/running/success/failed';
+
+COMMENT ON COLUMN "batch_rollbacks"."finished_at" IS '回滚结束时间';
+
+COMMENT ON COLUMN "batch_rollbacks"."error_message" IS '回滚失败原因';
@@ -0,0 +1,8 @@
+-- 由 scripts/020_alter_batch_depends_on.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "release_batches"
+  ADD COLUMN IF NOT EXISTS "depends_on_batch_id" BIGINT NULL;
+
+CREATE INDEX IF NOT EXISTS "release_batches_idx_depends_on_batch_id" ON "release_batches" ("depends_on_batch_id");
+
+COMMENT ON COLUMN "release_batches"."depends_on_batch_id" IS '前置批次ID';
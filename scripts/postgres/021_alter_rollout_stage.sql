@@ -0,0 +1,14 @@
+-- 由 scripts/021_alter_rollout_stage.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "deployments"
+  ADD COLUMN IF NOT EXISTS "rollout_stage" INTEGER NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN "deployments"."rollout_stage" IS '灰度阶段';
+
+ALTER TABLE "release_apps"
+  ADD COLUMN IF NOT EXISTS "rollout_stage" INTEGER NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS "rollout_stages" INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "release_apps"."rollout_stage" IS '当前已放行的灰度阶段';
+
+COMMENT ON COLUMN "release_apps"."rollout_stages" IS '灰度总阶段数';
@@ -0,0 +1,53 @@
+-- 由 scripts/022_init_webhook_schema.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE "webhooks" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "name" VARCHAR(100) NOT NULL,
+  "project_id" BIGINT DEFAULT NULL,
+  "url" VARCHAR(500) NOT NULL,
+  "events" JSONB DEFAULT NULL,
+  "enabled" BOOLEAN NOT NULL DEFAULT TRUE,
+  "description" VARCHAR(500) DEFAULT NULL,
+  "encrypted_secret" TEXT DEFAULT NULL,
+  "secret_key_id" VARCHAR(64) DEFAULT NULL,
+  "created_by" VARCHAR(50) DEFAULT NULL,
+  "updated_by" VARCHAR(50) DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "webhooks_idx_project_id" ON "webhooks" ("project_id");
+
+COMMENT ON TABLE "webhooks" IS '出站 Webhook 订阅';
+
+COMMENT ON COLUMN "webhooks"."project_id" IS '为空表示所有项目';
+
+COMMENT ON COLUMN "webhooks"."events" IS '订阅事件列表';
+
+COMMENT ON COLUMN "webhooks"."encrypted_secret" IS '签名密钥（加密）';
+
+COMMENT ON COLUMN "webhooks"."secret_key_id" IS '加密密钥ID';
+
+CREATE TABLE "webhook_deliveries" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "webhook_id" BIGINT NOT NULL,
+  "event" VARCHAR(50) NOT NULL,
+  "payload" JSONB DEFAULT NULL,
+  "status" VARCHAR(20) NOT NULL,
+  "attempts" INTEGER NOT NULL DEFAULT 0,
+  "next_attempt_at" TIMESTAMP NULL DEFAULT NULL,
+  "response_code" INTEGER DEFAULT NULL,
+  "response_body" TEXT DEFAULT NULL,
+  "error" TEXT DEFAULT NULL,
+  "delivered_at" TIMESTAMP NULL DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "webhook_deliveries_idx_webhook_id" ON "webhook_deliveries" ("webhook_id");
+
+CREATE INDEX IF NOT EXISTS "webhook_deliveries_idx_status_next_attempt" ON "webhook_deliveries" ("status", "next_attempt_at");
+
+COMMENT ON TABLE "webhook_deliveries" IS '出站 Webhook 投递记录';
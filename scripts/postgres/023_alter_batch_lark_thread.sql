@@ -0,0 +1,6 @@
+-- 由 scripts/023_alter_batch_lark_thread.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "release_batches"
+  ADD COLUMN IF NOT EXISTS "lark_thread_id" VARCHAR(100) NULL;
+
+COMMENT ON COLUMN "release_batches"."lark_thread_id" IS 'Lark 话题根消息ID';
@@ -0,0 +1,36 @@
+-- 由 scripts/024_init_role_schema.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE "roles" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "name" VARCHAR(50) NOT NULL,
+  "display_name" VARCHAR(100) DEFAULT NULL,
+  "description" VARCHAR(500) DEFAULT NULL,
+  "created_by" VARCHAR(50) DEFAULT NULL,
+  "updated_by" VARCHAR(50) DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "roles_uk_name" ON "roles" ("name");
+
+COMMENT ON TABLE "roles" IS '自定义角色';
+
+COMMENT ON COLUMN "roles"."name" IS '角色标识';
+
+CREATE TABLE "role_permissions" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "role_id" BIGINT NOT NULL,
+  "permission" VARCHAR(100) NOT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "role_permissions_uk_role_permission" ON "role_permissions" ("role_id", "permission");
+
+COMMENT ON TABLE "role_permissions" IS '角色权限绑定';
+
+ALTER TABLE "teams"
+  ADD COLUMN IF NOT EXISTS "roles" JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN "teams"."roles" IS '团队角色';
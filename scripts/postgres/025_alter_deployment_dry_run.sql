@@ -0,0 +1,12 @@
+-- 由 scripts/025_alter_deployment_dry_run.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "deployments"
+  ADD COLUMN IF NOT EXISTS "dry_run_status" VARCHAR(20) NULL,
+  ADD COLUMN IF NOT EXISTS "dry_run_message" TEXT,
+  ADD COLUMN IF NOT EXISTS "dry_run_at" TIMESTAMP NULL DEFAULT NULL;
+
+COMMENT ON COLUMN "deployments"."dry_run_status" IS 'server dry-run 结果: passed/failed';
+
+COMMENT ON COLUMN "deployments"."dry_run_message" IS 'server dry-run 结果说明/拒绝原因';
+
+COMMENT ON COLUMN "deployments"."dry_run_at" IS 'server dry-run 执行时间';
@@ -0,0 +1,19 @@
+-- 由 scripts/026_alter_batch_schedule.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "release_batches"
+  ADD COLUMN IF NOT EXISTS "scheduled_pre_at" TIMESTAMP NULL DEFAULT NULL,
+  ADD COLUMN IF NOT EXISTS "scheduled_pre_by" VARCHAR(50) NULL,
+  ADD COLUMN IF NOT EXISTS "scheduled_prod_at" TIMESTAMP NULL DEFAULT NULL,
+  ADD COLUMN IF NOT EXISTS "scheduled_prod_by" VARCHAR(50) NULL;
+
+CREATE INDEX IF NOT EXISTS "release_batches_idx_scheduled_pre_at" ON "release_batches" ("scheduled_pre_at");
+
+CREATE INDEX IF NOT EXISTS "release_batches_idx_scheduled_prod_at" ON "release_batches" ("scheduled_prod_at");
+
+COMMENT ON COLUMN "release_batches"."scheduled_pre_at" IS '定时开始预发布时间';
+
+COMMENT ON COLUMN "release_batches"."scheduled_pre_by" IS '定时预发布设置人';
+
+COMMENT ON COLUMN "release_batches"."scheduled_prod_at" IS '定时开始生产部署时间';
+
+COMMENT ON COLUMN "release_batches"."scheduled_prod_by" IS '定时生产部署设置人';
@@ -0,0 +1,24 @@
+-- 由 scripts/027_init_batch_event_schema.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE "batch_events" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "batch_id" BIGINT NOT NULL,
+  "project_id" BIGINT NOT NULL,
+  "from_status" SMALLINT NOT NULL,
+  "to_status" SMALLINT NOT NULL,
+  "trigger" VARCHAR(20) NOT NULL,
+  "operator" VARCHAR(50) DEFAULT NULL,
+  "reason" TEXT,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "batch_events_idx_batch_id" ON "batch_events" ("batch_id");
+
+CREATE INDEX IF NOT EXISTS "batch_events_idx_project_created" ON "batch_events" ("project_id", "created_at");
+
+COMMENT ON TABLE "batch_events" IS '批次状态变更审计事件';
+
+COMMENT ON COLUMN "batch_events"."trigger" IS 'manual/scheduled/auto';
+
+INSERT INTO "batch_events" ("batch_id", "project_id", "from_status", "to_status", "trigger", "operator", "reason", "created_at") SELECT "id", "project_id", 0, 10, 'manual', "sealed_by", '历史数据回填', "sealed_at" FROM "release_batches" WHERE "sealed_at" IS NOT NULL UNION ALL SELECT "id", "project_id", 10, 20, 'manual', "pre_triggered_by", '历史数据回填', "pre_started_at" FROM "release_batches" WHERE "pre_started_at" IS NOT NULL UNION ALL SELECT "id", "project_id", 25, 30, 'manual', "prod_triggered_by", '历史数据回填', "prod_started_at" FROM "release_batches" WHERE "prod_started_at" IS NOT NULL UNION ALL SELECT "id", "project_id", 35, 40, 'manual', "final_accepted_by", '历史数据回填', "final_accepted_at" FROM "release_batches" WHERE "final_accepted_at" IS NOT NULL UNION ALL SELECT "id", "project_id", "status", 90, 'manual', "cancelled_by", '历史数据回填', "cancelled_at" FROM "release_batches" WHERE "cancelled_at" IS NOT NULL;
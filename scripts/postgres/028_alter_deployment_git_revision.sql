@@ -0,0 +1,6 @@
+-- 由 scripts/028_alter_deployment_git_revision.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "deployments"
+  ADD COLUMN IF NOT EXISTS "git_revision" VARCHAR(64) NULL;
+
+COMMENT ON COLUMN "deployments"."git_revision" IS 'gitops driver 提交的 commit';
@@ -0,0 +1,27 @@
+-- 由 scripts/029_init_batch_approval_schema.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "projects"
+  ADD COLUMN IF NOT EXISTS "approval_policy" JSONB NULL;
+
+COMMENT ON COLUMN "projects"."approval_policy" IS '批次审批策略';
+
+CREATE TABLE "batch_approvals" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "batch_id" BIGINT NOT NULL,
+  "stage" INTEGER NOT NULL,
+  "stage_name" VARCHAR(100) NOT NULL,
+  "approver" VARCHAR(50) NOT NULL,
+  "decision" VARCHAR(20) NOT NULL,
+  "comment" TEXT DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "batch_approvals_uk_batch_stage_approver" ON "batch_approvals" ("batch_id", "stage", "approver");
+
+COMMENT ON TABLE "batch_approvals" IS '批次审批记录';
+
+COMMENT ON COLUMN "batch_approvals"."stage" IS '阶段序号（从 0 开始）';
+
+COMMENT ON COLUMN "batch_approvals"."decision" IS 'approved/rejected';
@@ -0,0 +1,24 @@
+-- 由 scripts/030_init_batch_template_schema.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE "batch_templates" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "project_id" BIGINT NOT NULL,
+  "name" VARCHAR(100) NOT NULL,
+  "description" TEXT DEFAULT NULL,
+  "batch_number" VARCHAR(200) DEFAULT NULL,
+  "release_notes" TEXT DEFAULT NULL,
+  "apps" JSONB NOT NULL,
+  "created_by" VARCHAR(50) DEFAULT NULL,
+  "updated_by" VARCHAR(50) DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "batch_templates_uk_project_name" ON "batch_templates" ("project_id", "name");
+
+COMMENT ON TABLE "batch_templates" IS '批次模板';
+
+COMMENT ON COLUMN "batch_templates"."batch_number" IS '批次编号模板';
+
+COMMENT ON COLUMN "batch_templates"."release_notes" IS '发布说明脚手架';
@@ -0,0 +1,6 @@
+-- 由 scripts/031_alter_project_auto_batch_rule.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "projects"
+  ADD COLUMN IF NOT EXISTS "auto_batch_rule" JSONB NULL;
+
+COMMENT ON COLUMN "projects"."auto_batch_rule" IS '自动建批规则';
@@ -0,0 +1,6 @@
+-- 由 scripts/032_alter_deployment_last_failed_at.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "deployments"
+  ADD COLUMN IF NOT EXISTS "last_failed_at" TIMESTAMP NULL DEFAULT NULL;
+
+COMMENT ON COLUMN "deployments"."last_failed_at" IS '最近一次失败时间';
@@ -0,0 +1,24 @@
+-- 由 scripts/033_init_deployment_event_schema.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE "deployment_events" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "deployment_id" BIGINT NOT NULL,
+  "batch_id" BIGINT NOT NULL,
+  "type" VARCHAR(30) NOT NULL,
+  "from_status" VARCHAR(20) DEFAULT NULL,
+  "to_status" VARCHAR(20) NOT NULL,
+  "operator" VARCHAR(50) DEFAULT NULL,
+  "message" TEXT,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "deployment_events_idx_deployment_id" ON "deployment_events" ("deployment_id");
+
+CREATE INDEX IF NOT EXISTS "deployment_events_idx_batch_id" ON "deployment_events" ("batch_id");
+
+COMMENT ON TABLE "deployment_events" IS 'Deployment 时间线事件';
+
+COMMENT ON COLUMN "deployment_events"."type" IS 'status_changed/chart_rendered/deploy_started/readiness';
+
+COMMENT ON COLUMN "deployment_events"."operator" IS '为空表示引擎自动推进';
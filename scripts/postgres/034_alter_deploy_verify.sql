@@ -0,0 +1,11 @@
+-- 由 scripts/034_alter_deploy_verify.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "applications"
+  ADD COLUMN IF NOT EXISTS "deploy_verify" JSONB NULL;
+
+COMMENT ON COLUMN "applications"."deploy_verify" IS '部署后验证';
+
+ALTER TABLE "deployments"
+  ADD COLUMN IF NOT EXISTS "verify_started_at" TIMESTAMP NULL DEFAULT NULL;
+
+COMMENT ON COLUMN "deployments"."verify_started_at" IS '部署后验证开始时间';
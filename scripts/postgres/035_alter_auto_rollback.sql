@@ -0,0 +1,16 @@
+-- 由 scripts/035_alter_auto_rollback.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "projects"
+  ADD COLUMN IF NOT EXISTS "auto_rollback" BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN "projects"."auto_rollback" IS '生产就绪/验证失败自动回滚失败集群';
+
+ALTER TABLE "applications"
+  ADD COLUMN IF NOT EXISTS "auto_rollback" BOOLEAN NULL DEFAULT NULL;
+
+COMMENT ON COLUMN "applications"."auto_rollback" IS '自动回滚（NULL 继承项目）';
+
+ALTER TABLE "deployments"
+  ADD COLUMN IF NOT EXISTS "rollback_build_id" BIGINT NULL DEFAULT NULL;
+
+COMMENT ON COLUMN "deployments"."rollback_build_id" IS '自动回滚部署的构建';
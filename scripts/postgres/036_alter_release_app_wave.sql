@@ -0,0 +1,6 @@
+-- 由 scripts/036_alter_release_app_wave.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "release_apps"
+  ADD COLUMN IF NOT EXISTS "wave" INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "release_apps"."wave" IS '部署波次（封板时计算）';
@@ -0,0 +1,6 @@
+-- 由 scripts/037_alter_repository_ci_token.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "repositories"
+  ADD COLUMN IF NOT EXISTS "ci_token" VARCHAR(200) NULL DEFAULT NULL;
+
+COMMENT ON COLUMN "repositories"."ci_token" IS 'Jenkins 构建通知 token';
@@ -0,0 +1,6 @@
+-- 由 scripts/038_alter_webhook_source_repos.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "webhook_sources"
+  ADD COLUMN IF NOT EXISTS "repos" JSONB DEFAULT NULL;
+
+COMMENT ON COLUMN "webhook_sources"."repos" IS '允许通知的代码库（namespace/name，支持 * 通配）';
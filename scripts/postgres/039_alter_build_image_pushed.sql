@@ -0,0 +1,9 @@
+-- 由 scripts/039_alter_build_image_pushed.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "builds"
+  ADD COLUMN IF NOT EXISTS "image_digest" VARCHAR(100) NULL DEFAULT NULL,
+  ADD COLUMN IF NOT EXISTS "image_pushed_at" TIMESTAMP NULL DEFAULT NULL;
+
+COMMENT ON COLUMN "builds"."image_digest" IS '镜像仓库确认的 manifest digest';
+
+COMMENT ON COLUMN "builds"."image_pushed_at" IS '镜像推送时间';
@@ -0,0 +1,50 @@
+-- 由 scripts/040_init_image_scan_schema.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE "image_scans" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "image" VARCHAR(255) NOT NULL,
+  "tag" VARCHAR(100) NOT NULL,
+  "digest" VARCHAR(100) DEFAULT NULL,
+  "source" VARCHAR(50) NOT NULL,
+  "scanner" VARCHAR(100) DEFAULT NULL,
+  "status" VARCHAR(20) NOT NULL,
+  "critical" INTEGER NOT NULL DEFAULT 0,
+  "high" INTEGER NOT NULL DEFAULT 0,
+  "medium" INTEGER NOT NULL DEFAULT 0,
+  "low" INTEGER NOT NULL DEFAULT 0,
+  "unknown" INTEGER NOT NULL DEFAULT 0,
+  "scanned_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "image_scans_uk_image_tag" ON "image_scans" ("image", "tag");
+
+COMMENT ON TABLE "image_scans" IS '镜像漏洞扫描结果';
+
+COMMENT ON COLUMN "image_scans"."image" IS '镜像地址（不含 tag）';
+
+COMMENT ON COLUMN "image_scans"."source" IS 'harbor/trivy';
+
+COMMENT ON COLUMN "image_scans"."scanner" IS '扫描器及版本';
+
+COMMENT ON COLUMN "image_scans"."status" IS 'success/failed';
+
+COMMENT ON COLUMN "image_scans"."scanned_at" IS '扫描完成时间';
+
+ALTER TABLE "projects"
+  ADD COLUMN IF NOT EXISTS "vuln_policy" JSONB NULL;
+
+COMMENT ON COLUMN "projects"."vuln_policy" IS '镜像漏洞门禁策略';
+
+ALTER TABLE "release_batches"
+  ADD COLUMN IF NOT EXISTS "vuln_override_at" TIMESTAMP NULL DEFAULT NULL,
+  ADD COLUMN IF NOT EXISTS "vuln_override_by" VARCHAR(50) NULL,
+  ADD COLUMN IF NOT EXISTS "vuln_override_reason" TEXT NULL;
+
+COMMENT ON COLUMN "release_batches"."vuln_override_at" IS '漏洞门禁豁免时间';
+
+COMMENT ON COLUMN "release_batches"."vuln_override_by" IS '漏洞门禁豁免人';
+
+COMMENT ON COLUMN "release_batches"."vuln_override_reason" IS '漏洞门禁豁免原因';
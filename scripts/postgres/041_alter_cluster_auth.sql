@@ -0,0 +1,21 @@
+-- 由 scripts/041_alter_cluster_auth.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "clusters"
+  ADD COLUMN IF NOT EXISTS "auth_type" VARCHAR(20) NOT NULL DEFAULT 'kubeconfig',
+  ADD COLUMN IF NOT EXISTS "server" VARCHAR(255) NULL DEFAULT NULL,
+  ADD COLUMN IF NOT EXISTS "ca_data" TEXT NULL,
+  ADD COLUMN IF NOT EXISTS "insecure_skip_tls_verify" BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN IF NOT EXISTS "token" TEXT NULL,
+  ADD COLUMN IF NOT EXISTS "exec_config" JSONB NULL;
+
+COMMENT ON COLUMN "clusters"."auth_type" IS '认证方式: kubeconfig/token/exec';
+
+COMMENT ON COLUMN "clusters"."server" IS 'API Server 地址（token/exec 方式）';
+
+COMMENT ON COLUMN "clusters"."ca_data" IS 'API Server CA 证书（PEM），为空使用系统 CA';
+
+COMMENT ON COLUMN "clusters"."insecure_skip_tls_verify" IS '是否跳过证书校验';
+
+COMMENT ON COLUMN "clusters"."token" IS 'Bearer token（token 方式）';
+
+COMMENT ON COLUMN "clusters"."exec_config" IS 'exec 凭据插件配置（exec 方式）';
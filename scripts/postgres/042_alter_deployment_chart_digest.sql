@@ -0,0 +1,6 @@
+-- 由 scripts/042_alter_deployment_chart_digest.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "deployments"
+  ADD COLUMN IF NOT EXISTS "chart_digest" VARCHAR(100) NULL;
+
+COMMENT ON COLUMN "deployments"."chart_digest" IS 'OCI chart manifest digest';
@@ -0,0 +1,6 @@
+-- 由 scripts/043_alter_release_app_chart_lock.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "release_apps"
+  ADD COLUMN IF NOT EXISTS "chart_lock" JSONB NULL;
+
+COMMENT ON COLUMN "release_apps"."chart_lock" IS '封板时锁定的 chart 版本/digest';
@@ -0,0 +1,8 @@
+-- 由 scripts/044_alter_release_app_skip_prod_env.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "release_apps"
+  ADD COLUMN IF NOT EXISTS "skip_prod_env" BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN "release_apps"."skip_prod_env" IS '是否跳过生产环境（pre_only 或未配置生产环境）';
+
+UPDATE "release_apps" SET "skip_prod_env" = "pre_only";
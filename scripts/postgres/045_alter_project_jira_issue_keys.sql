@@ -0,0 +1,11 @@
+-- 由 scripts/045_alter_project_jira_issue_keys.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "projects"
+  ADD COLUMN IF NOT EXISTS "jira_config" JSONB NULL;
+
+COMMENT ON COLUMN "projects"."jira_config" IS 'Jira 关联配置';
+
+ALTER TABLE "release_apps"
+  ADD COLUMN IF NOT EXISTS "issue_keys" JSONB NULL;
+
+COMMENT ON COLUMN "release_apps"."issue_keys" IS '关联 issue（JSON 数组）';
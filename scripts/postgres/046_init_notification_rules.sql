@@ -0,0 +1,46 @@
+-- 由 scripts/046_init_notification_rules.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE "notification_rules" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "name" VARCHAR(100) NOT NULL,
+  "project_id" BIGINT DEFAULT NULL,
+  "team_id" BIGINT DEFAULT NULL,
+  "events" JSONB DEFAULT NULL,
+  "min_severity" VARCHAR(20) NOT NULL DEFAULT 'info',
+  "webhook" VARCHAR(500) NOT NULL,
+  "title_template" TEXT DEFAULT NULL,
+  "content_template" TEXT DEFAULT NULL,
+  "quiet_hours" JSONB DEFAULT NULL,
+  "mention_leader" BOOLEAN NOT NULL DEFAULT FALSE,
+  "enabled" BOOLEAN NOT NULL DEFAULT TRUE,
+  "description" VARCHAR(500) DEFAULT NULL,
+  "created_by" VARCHAR(50) DEFAULT NULL,
+  "updated_by" VARCHAR(50) DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "notification_rules_idx_project_id" ON "notification_rules" ("project_id");
+
+CREATE INDEX IF NOT EXISTS "notification_rules_idx_team_id" ON "notification_rules" ("team_id");
+
+COMMENT ON TABLE "notification_rules" IS '通知路由规则';
+
+COMMENT ON COLUMN "notification_rules"."project_id" IS '为空表示所有项目';
+
+COMMENT ON COLUMN "notification_rules"."team_id" IS '为空表示所有团队';
+
+COMMENT ON COLUMN "notification_rules"."events" IS '通知类型列表';
+
+COMMENT ON COLUMN "notification_rules"."min_severity" IS '最低严重级别';
+
+COMMENT ON COLUMN "notification_rules"."webhook" IS 'Lark 群机器人 Webhook';
+
+COMMENT ON COLUMN "notification_rules"."title_template" IS '标题模板';
+
+COMMENT ON COLUMN "notification_rules"."content_template" IS '正文模板';
+
+COMMENT ON COLUMN "notification_rules"."quiet_hours" IS '免打扰时段';
+
+COMMENT ON COLUMN "notification_rules"."mention_leader" IS '失败时 @ 团队负责人';
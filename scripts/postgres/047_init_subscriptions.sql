@@ -0,0 +1,25 @@
+-- 由 scripts/047_init_subscriptions.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE "subscriptions" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "username" VARCHAR(50) NOT NULL,
+  "target_type" VARCHAR(20) NOT NULL,
+  "target_id" BIGINT NOT NULL,
+  "project_id" BIGINT NOT NULL,
+  "channels" JSONB DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "subscriptions_uk_subscription" ON "subscriptions" ("username", "target_type", "target_id");
+
+CREATE INDEX IF NOT EXISTS "subscriptions_idx_target" ON "subscriptions" ("target_type", "target_id");
+
+CREATE INDEX IF NOT EXISTS "subscriptions_idx_project_id" ON "subscriptions" ("project_id");
+
+COMMENT ON TABLE "subscriptions" IS '批次/应用订阅';
+
+COMMENT ON COLUMN "subscriptions"."target_type" IS 'batch / app';
+
+COMMENT ON COLUMN "subscriptions"."channels" IS '通知渠道';
@@ -0,0 +1,20 @@
+-- 由 scripts/048_init_batch_comments.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE "batch_comments" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "batch_id" BIGINT NOT NULL,
+  "project_id" BIGINT NOT NULL,
+  "release_app_id" BIGINT DEFAULT NULL,
+  "app_id" BIGINT DEFAULT NULL,
+  "author" VARCHAR(50) NOT NULL,
+  "content" TEXT NOT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "batch_comments_idx_batch_id" ON "batch_comments" ("batch_id");
+
+CREATE INDEX IF NOT EXISTS "batch_comments_idx_release_app_id" ON "batch_comments" ("release_app_id");
+
+COMMENT ON TABLE "batch_comments" IS '批次评论';
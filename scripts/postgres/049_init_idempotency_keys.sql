@@ -0,0 +1,32 @@
+-- 由 scripts/049_init_idempotency_keys.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE "idempotency_keys" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "username" VARCHAR(50) NOT NULL,
+  "idem_key" VARCHAR(128) NOT NULL,
+  "method" VARCHAR(10) NOT NULL,
+  "path" VARCHAR(255) NOT NULL,
+  "fingerprint" VARCHAR(64) NOT NULL,
+  "status" VARCHAR(20) NOT NULL,
+  "status_code" INTEGER NOT NULL DEFAULT 0,
+  "content_type" VARCHAR(100) DEFAULT NULL,
+  "response_body" BYTEA DEFAULT NULL,
+  "expires_at" TIMESTAMP NOT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idempotency_keys_uk_idempotency_key" ON "idempotency_keys" ("username", "idem_key");
+
+CREATE INDEX IF NOT EXISTS "idempotency_keys_idx_expires_at" ON "idempotency_keys" ("expires_at");
+
+COMMENT ON TABLE "idempotency_keys" IS '写接口幂等记录';
+
+COMMENT ON COLUMN "idempotency_keys"."idem_key" IS 'Idempotency-Key';
+
+COMMENT ON COLUMN "idempotency_keys"."fingerprint" IS '请求指纹 SHA-256';
+
+COMMENT ON COLUMN "idempotency_keys"."status" IS 'processing / completed';
+
+COMMENT ON COLUMN "idempotency_keys"."status_code" IS '响应 HTTP 状态码';
@@ -0,0 +1,49 @@
+-- 由 scripts/050_init_jobs.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE "jobs" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "type" VARCHAR(64) NOT NULL,
+  "dedup_key" VARCHAR(128) NOT NULL DEFAULT '',
+  "active_key" VARCHAR(128) DEFAULT NULL,
+  "payload" JSONB DEFAULT NULL,
+  "status" VARCHAR(20) NOT NULL,
+  "attempts" INTEGER NOT NULL DEFAULT 0,
+  "max_attempts" INTEGER NOT NULL DEFAULT 1,
+  "run_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "locked_by" VARCHAR(128) DEFAULT NULL,
+  "locked_until" TIMESTAMP NULL DEFAULT NULL,
+  "last_error" TEXT DEFAULT NULL,
+  "batch_id" BIGINT DEFAULT NULL,
+  "deployment_id" BIGINT DEFAULT NULL,
+  "started_at" TIMESTAMP NULL DEFAULT NULL,
+  "finished_at" TIMESTAMP NULL DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "uk_jobs_active_key" ON "jobs" ("active_key");
+
+CREATE INDEX IF NOT EXISTS "idx_jobs_status_run_at" ON "jobs" ("status", "run_at");
+
+CREATE INDEX IF NOT EXISTS "jobs_idx_type" ON "jobs" ("type");
+
+CREATE INDEX IF NOT EXISTS "jobs_idx_batch_id" ON "jobs" ("batch_id");
+
+CREATE INDEX IF NOT EXISTS "jobs_idx_deployment_id" ON "jobs" ("deployment_id");
+
+COMMENT ON TABLE "jobs" IS '异步任务队列';
+
+COMMENT ON COLUMN "jobs"."type" IS '任务类型，如 deployment.execute';
+
+COMMENT ON COLUMN "jobs"."dedup_key" IS '去重键';
+
+COMMENT ON COLUMN "jobs"."active_key" IS '未结束时等于 dedup_key';
+
+COMMENT ON COLUMN "jobs"."status" IS 'pending / running / succeeded / dead';
+
+COMMENT ON COLUMN "jobs"."run_at" IS '最早可执行时间';
+
+COMMENT ON COLUMN "jobs"."locked_by" IS '领取的 worker（hostname:pid）';
+
+COMMENT ON COLUMN "jobs"."locked_until" IS '租约到期时间';
@@ -0,0 +1,38 @@
+-- 由 scripts/051_init_api_tokens.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE "api_tokens" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "name" VARCHAR(100) NOT NULL,
+  "description" VARCHAR(500) NOT NULL DEFAULT '',
+  "token_prefix" VARCHAR(16) NOT NULL,
+  "token_hash" CHAR(64) NOT NULL,
+  "scopes" JSONB DEFAULT NULL,
+  "project_ids" JSONB DEFAULT NULL,
+  "expires_at" TIMESTAMP NULL DEFAULT NULL,
+  "last_used_at" TIMESTAMP NULL DEFAULT NULL,
+  "last_used_ip" VARCHAR(64) NOT NULL DEFAULT '',
+  "revoked_at" TIMESTAMP NULL DEFAULT NULL,
+  "revoked_by" VARCHAR(50) NOT NULL DEFAULT '',
+  "created_by" VARCHAR(50) NOT NULL DEFAULT '',
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "uk_api_tokens_hash" ON "api_tokens" ("token_hash");
+
+CREATE INDEX IF NOT EXISTS "idx_api_tokens_name" ON "api_tokens" ("name");
+
+COMMENT ON TABLE "api_tokens" IS 'API Token';
+
+COMMENT ON COLUMN "api_tokens"."name" IS '名称（集成方）';
+
+COMMENT ON COLUMN "api_tokens"."token_prefix" IS 'token 前缀，用于识别';
+
+COMMENT ON COLUMN "api_tokens"."token_hash" IS 'sha256(token) hex';
+
+COMMENT ON COLUMN "api_tokens"."scopes" IS '授予的只读权限';
+
+COMMENT ON COLUMN "api_tokens"."project_ids" IS '限定项目，为空表示不限';
+
+COMMENT ON COLUMN "api_tokens"."expires_at" IS '过期时间，为空表示不过期';
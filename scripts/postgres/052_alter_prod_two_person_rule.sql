@@ -0,0 +1,6 @@
+-- 由 scripts/052_alter_prod_two_person_rule.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "projects"
+  ADD COLUMN IF NOT EXISTS "prod_two_person_rule" BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN "projects"."prod_two_person_rule" IS '生产部署双人原则';
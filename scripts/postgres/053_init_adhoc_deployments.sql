@@ -0,0 +1,42 @@
+-- 由 scripts/053_init_adhoc_deployments.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+CREATE TABLE "adhoc_deployments" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "project_id" BIGINT NOT NULL,
+  "app_id" BIGINT NOT NULL,
+  "env" VARCHAR(20) NOT NULL,
+  "build_id" BIGINT NOT NULL,
+  "image_tag" VARCHAR(100) NOT NULL,
+  "clusters" JSONB DEFAULT NULL,
+  "status" VARCHAR(20) NOT NULL DEFAULT 'running',
+  "reason" TEXT NOT NULL,
+  "error_message" TEXT DEFAULT NULL,
+  "created_by" VARCHAR(50) NOT NULL,
+  "finished_at" TIMESTAMP NULL DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_adhoc_deployments_project_id" ON "adhoc_deployments" ("project_id");
+
+CREATE INDEX IF NOT EXISTS "idx_adhoc_deployments_app_id" ON "adhoc_deployments" ("app_id");
+
+CREATE INDEX IF NOT EXISTS "idx_adhoc_deployments_status" ON "adhoc_deployments" ("status");
+
+COMMENT ON TABLE "adhoc_deployments" IS '临时部署';
+
+COMMENT ON COLUMN "adhoc_deployments"."env" IS 'pre/prod';
+
+COMMENT ON COLUMN "adhoc_deployments"."clusters" IS '部署的集群';
+
+COMMENT ON COLUMN "adhoc_deployments"."status" IS 'running/success/failed';
+
+COMMENT ON COLUMN "adhoc_deployments"."reason" IS '发起原因';
+
+ALTER TABLE "deployments"
+  ADD COLUMN IF NOT EXISTS "adhoc_id" BIGINT NULL DEFAULT NULL;
+
+CREATE INDEX IF NOT EXISTS "idx_deployments_adhoc_id" ON "deployments" ("adhoc_id");
+
+COMMENT ON COLUMN "deployments"."adhoc_id" IS '临时部署ID';
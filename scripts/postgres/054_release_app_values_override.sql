@@ -0,0 +1,6 @@
+-- 由 scripts/054_release_app_values_override.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "release_apps"
+  ADD COLUMN IF NOT EXISTS "values_override" TEXT NULL DEFAULT NULL;
+
+COMMENT ON COLUMN "release_apps"."values_override" IS 'values 覆盖（YAML/JSON）';
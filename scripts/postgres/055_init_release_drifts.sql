@@ -0,0 +1,60 @@
+-- 由 scripts/055_init_release_drifts.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "deployments"
+  ADD COLUMN IF NOT EXISTS "release_revision" INTEGER NULL DEFAULT NULL,
+  ADD COLUMN IF NOT EXISTS "values_digest" VARCHAR(64) NULL DEFAULT NULL,
+  ADD COLUMN IF NOT EXISTS "manifest_digest" VARCHAR(64) NULL DEFAULT NULL;
+
+COMMENT ON COLUMN "deployments"."release_revision" IS '写入的 helm release revision';
+
+COMMENT ON COLUMN "deployments"."values_digest" IS 'release values 摘要';
+
+COMMENT ON COLUMN "deployments"."manifest_digest" IS 'release manifest 摘要';
+
+CREATE TABLE "release_drifts" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "project_id" BIGINT NOT NULL,
+  "app_id" BIGINT NOT NULL,
+  "env" VARCHAR(20) NOT NULL,
+  "cluster" VARCHAR(63) NOT NULL,
+  "kind" VARCHAR(20) NOT NULL,
+  "deployment_id" BIGINT NOT NULL,
+  "namespace" VARCHAR(63) NOT NULL,
+  "release_name" VARCHAR(63) NOT NULL,
+  "status" VARCHAR(20) NOT NULL,
+  "findings" JSONB DEFAULT NULL,
+  "digest" VARCHAR(64) NOT NULL DEFAULT '',
+  "detected_at" TIMESTAMP NULL DEFAULT NULL,
+  "notified_digest" VARCHAR(64) DEFAULT NULL,
+  "error_message" TEXT DEFAULT NULL,
+  "checked_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "uk_release_drifts_target" ON "release_drifts" ("app_id", "env", "cluster", "kind");
+
+CREATE INDEX IF NOT EXISTS "idx_release_drifts_project_id" ON "release_drifts" ("project_id");
+
+CREATE INDEX IF NOT EXISTS "idx_release_drifts_status" ON "release_drifts" ("status");
+
+COMMENT ON TABLE "release_drifts" IS '配置漂移检测结果';
+
+COMMENT ON COLUMN "release_drifts"."env" IS 'pre/prod';
+
+COMMENT ON COLUMN "release_drifts"."kind" IS 'app/config';
+
+COMMENT ON COLUMN "release_drifts"."deployment_id" IS '作为基准的 Deployment';
+
+COMMENT ON COLUMN "release_drifts"."status" IS 'ok/drift/missing/error';
+
+COMMENT ON COLUMN "release_drifts"."findings" IS '漂移项';
+
+COMMENT ON COLUMN "release_drifts"."digest" IS '漂移内容摘要';
+
+COMMENT ON COLUMN "release_drifts"."detected_at" IS '本次漂移首次发现时间';
+
+COMMENT ON COLUMN "release_drifts"."notified_digest" IS '已通知的漂移内容摘要';
+
+COMMENT ON COLUMN "release_drifts"."checked_at" IS '最近检测时间';
@@ -0,0 +1,44 @@
+-- 由 scripts/056_init_app_decommissions.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "builds"
+  ADD COLUMN IF NOT EXISTS "archived_at" TIMESTAMP NULL DEFAULT NULL;
+
+COMMENT ON COLUMN "builds"."archived_at" IS '应用下线归档时间';
+
+CREATE TABLE "app_decommissions" (
+  "id" BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+  "project_id" BIGINT NOT NULL,
+  "app_id" BIGINT NOT NULL,
+  "app_name" VARCHAR(100) NOT NULL,
+  "uninstall" BOOLEAN NOT NULL DEFAULT FALSE,
+  "targets" JSONB DEFAULT NULL,
+  "status" VARCHAR(20) NOT NULL DEFAULT 'running',
+  "archived_builds" BIGINT NOT NULL DEFAULT 0,
+  "reason" TEXT NOT NULL,
+  "error_message" TEXT DEFAULT NULL,
+  "created_by" VARCHAR(50) NOT NULL,
+  "finished_at" TIMESTAMP NULL DEFAULT NULL,
+  "created_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_app_decommissions_project_id" ON "app_decommissions" ("project_id");
+
+CREATE INDEX IF NOT EXISTS "idx_app_decommissions_app_id" ON "app_decommissions" ("app_id");
+
+CREATE INDEX IF NOT EXISTS "idx_app_decommissions_status" ON "app_decommissions" ("status");
+
+COMMENT ON TABLE "app_decommissions" IS '应用下线';
+
+COMMENT ON COLUMN "app_decommissions"."app_name" IS '应用删除后仍可展示';
+
+COMMENT ON COLUMN "app_decommissions"."uninstall" IS '是否卸载 release';
+
+COMMENT ON COLUMN "app_decommissions"."targets" IS '卸载目标及结果';
+
+COMMENT ON COLUMN "app_decommissions"."status" IS 'running/success/failed';
+
+COMMENT ON COLUMN "app_decommissions"."archived_builds" IS '归档的构建数';
+
+COMMENT ON COLUMN "app_decommissions"."reason" IS '下线原因';
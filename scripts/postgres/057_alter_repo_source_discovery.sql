@@ -0,0 +1,9 @@
+-- 由 scripts/057_alter_repo_source_discovery.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "repo_sources"
+  ADD COLUMN IF NOT EXISTS "discovery_files" JSONB NULL,
+  ADD COLUMN IF NOT EXISTS "discovery_app_type" VARCHAR(20) NULL;
+
+COMMENT ON COLUMN "repo_sources"."discovery_files" IS '发现规则：根目录存在其中任一文件时创建应用';
+
+COMMENT ON COLUMN "repo_sources"."discovery_app_type" IS '发现规则创建应用的 app_type，为空时按语言推断';
@@ -0,0 +1,6 @@
+-- 由 scripts/058_alter_release_app_manifest_lock.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "release_apps"
+  ADD COLUMN IF NOT EXISTS "manifest_lock" JSONB NULL;
+
+COMMENT ON COLUMN "release_apps"."manifest_lock" IS '封板时锁定的代码库清单配置';
@@ -0,0 +1,6 @@
+-- 由 scripts/059_alter_application_path_filter.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "applications"
+  ADD COLUMN IF NOT EXISTS "path_filter" JSONB NULL;
+
+COMMENT ON COLUMN "applications"."path_filter" IS '单仓多应用路径映射';
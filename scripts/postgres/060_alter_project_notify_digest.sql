@@ -0,0 +1,6 @@
+-- 由 scripts/060_alter_project_notify_digest.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "projects"
+  ADD COLUMN IF NOT EXISTS "notify_digest" BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN "projects"."notify_digest" IS '应用部署结果按批次阶段汇总通知';
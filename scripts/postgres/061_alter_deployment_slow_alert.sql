@@ -0,0 +1,9 @@
+-- 由 scripts/061_alter_deployment_slow_alert.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "deployments"
+  ADD COLUMN IF NOT EXISTS "slow_alerted_at" TIMESTAMP NULL DEFAULT NULL,
+  ADD COLUMN IF NOT EXISTS "expected_duration_sec" INTEGER NULL DEFAULT NULL;
+
+COMMENT ON COLUMN "deployments"."slow_alerted_at" IS '运行时长异常告警时间';
+
+COMMENT ON COLUMN "deployments"."expected_duration_sec" IS '告警时的历史 P95 部署耗时（秒）';
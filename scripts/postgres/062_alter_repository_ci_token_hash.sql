@@ -0,0 +1,3 @@
+-- 由 scripts/062_alter_repository_ci_token_hash.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+UPDATE "repositories" SET "ci_token" = encode (sha256 (convert_to ("ci_token", 'UTF8')), 'hex') WHERE "ci_token" IS NOT NULL;
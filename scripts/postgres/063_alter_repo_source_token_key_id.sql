@@ -0,0 +1,13 @@
+-- 由 scripts/063_alter_repo_source_token_key_id.sql 生成（go run ./cmd/pgschema），请勿手工修改
+
+ALTER TABLE "repo_sources"
+  ADD COLUMN IF NOT EXISTS "auth_token_key_id" VARCHAR(255) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN "repo_sources"."auth_token_key_id" IS '加密所用 KEK（为空表示旧格式）';
+
+ALTER TABLE "webhooks"
+  ALTER COLUMN "secret_key_id" TYPE VARCHAR(255) USING "secret_key_id"::VARCHAR(255),
+  ALTER COLUMN "secret_key_id" SET DEFAULT NULL,
+  ALTER COLUMN "secret_key_id" DROP NOT NULL;
+
+COMMENT ON COLUMN "webhooks"."secret_key_id" IS '加密密钥ID';